@@ -0,0 +1,205 @@
+// Browser SDK for Mycelian Memory: read contexts and run searches directly
+// from agent UIs. Uses the platform fetch; the server must list the page's
+// origin in MEMORY_SERVER_CORS_ALLOWED_ORIGINS.
+
+export interface ClientOptions {
+  /** Service base URL, e.g. "http://localhost:11545". */
+  baseUrl: string;
+  /** API key sent as a Bearer token. */
+  apiKey: string;
+  /** Per-request timeout in milliseconds; also sent as X-Request-Timeout. Default 30000. */
+  timeoutMs?: number;
+  /** Custom fetch (tests, non-browser runtimes). Defaults to globalThis.fetch. */
+  fetch?: typeof fetch;
+}
+
+export interface Vault {
+  actorId: string;
+  vaultId: string;
+  title: string;
+  description?: string;
+  creationTime: string;
+  readOnly: boolean;
+}
+
+export interface Memory {
+  actorId: string;
+  vaultId: string;
+  memoryId: string;
+  title: string;
+  description?: string;
+  memoryType: string;
+  creationTime: string;
+}
+
+export interface MemoryContext {
+  contextId: string;
+  actorId: string;
+  vaultId: string;
+  memoryId: string;
+  context: string;
+  creationTime: string;
+}
+
+export interface ContextFetch {
+  /** Context text; empty when notModified is true. */
+  context: string;
+  /** Validator to pass back as ifNoneMatch on the next fetch. */
+  etag: string;
+  /** True when the server answered 304 for the supplied etag. */
+  notModified: boolean;
+}
+
+export interface SearchMustNot {
+  tags?: string[];
+  memoryIds?: string[];
+  /** E.g. entries already cited in the current turn. */
+  entryIds?: string[];
+}
+
+export interface SearchRequest {
+  memoryId: string;
+  query: string;
+  topK?: number;
+  /** Only search entries of this conversation session. */
+  sessionId?: string;
+  /** Results to exclude; applied before topK. */
+  mustNot?: SearchMustNot;
+}
+
+export interface SearchHit {
+  entryId: string;
+  actorId: string;
+  memoryId: string;
+  summary: string;
+  rawEntry: string;
+  score: number;
+  /** Entry quality signals; present when the server ranks by signals. */
+  usefulCount?: number;
+  incorrectCount?: number;
+  outdatedCount?: number;
+}
+
+export interface SearchResponse {
+  entries: SearchHit[];
+  count: number;
+  latestContext?: string;
+  contextTimestamp?: string;
+  bestContext?: string;
+  bestContextTimestamp?: string;
+  bestContextScore?: number;
+  /** Latest context of every memory present in entries, keyed by memoryId. */
+  contexts?: Record<string, MemoryContext>;
+  /** Present when the server query log is enabled; pass to searchFeedback. */
+  queryId?: string;
+}
+
+/** Error for any non-2xx response. */
+export class MycelianError extends Error {
+  constructor(
+    readonly status: number,
+    readonly body: string,
+    readonly requestId: string | null,
+  ) {
+    super(`mycelian: HTTP ${status}: ${body}`);
+    this.name = "MycelianError";
+  }
+
+  /** 409 raised by writes to a read-only vault. */
+  get isReadOnly(): boolean {
+    return this.status === 409 && this.body.includes("read-only");
+  }
+}
+
+export class MycelianClient {
+  private readonly baseUrl: string;
+  private readonly apiKey: string;
+  private readonly timeoutMs: number;
+  private readonly fetchImpl: typeof fetch;
+
+  constructor(opts: ClientOptions) {
+    if (!opts.baseUrl) throw new Error("baseUrl cannot be empty");
+    if (!opts.apiKey) throw new Error("apiKey cannot be empty");
+    this.baseUrl = opts.baseUrl.replace(/\/+$/, "");
+    this.apiKey = opts.apiKey;
+    this.timeoutMs = opts.timeoutMs ?? 30000;
+    this.fetchImpl = opts.fetch ?? globalThis.fetch.bind(globalThis);
+  }
+
+  async listVaults(): Promise<Vault[]> {
+    const res = await this.json<{ vaults: Vault[] | null }>("GET", "/v0/vaults");
+    return res.vaults ?? [];
+  }
+
+  getVault(vaultId: string): Promise<Vault> {
+    return this.json<Vault>("GET", `/v0/vaults/${enc(vaultId)}`);
+  }
+
+  async listMemories(vaultId: string): Promise<Memory[]> {
+    const res = await this.json<{ memories: Memory[] | null }>("GET", `/v0/vaults/${enc(vaultId)}/memories`);
+    return res.memories ?? [];
+  }
+
+  getMemory(vaultId: string, memoryId: string): Promise<Memory> {
+    return this.json<Memory>("GET", `/v0/vaults/${enc(vaultId)}/memories/${enc(memoryId)}`);
+  }
+
+  /**
+   * Fetches the latest context. Pass the etag from a previous call as
+   * ifNoneMatch to skip the download when nothing changed.
+   */
+  async getLatestContext(vaultId: string, memoryId: string, ifNoneMatch?: string): Promise<ContextFetch> {
+    const headers: Record<string, string> = {};
+    if (ifNoneMatch) headers["If-None-Match"] = ifNoneMatch;
+    const res = await this.send("GET", `/v0/vaults/${enc(vaultId)}/memories/${enc(memoryId)}/contexts`, undefined, headers);
+    const etag = res.headers.get("ETag") ?? "";
+    if (res.status === 304) {
+      return { context: "", etag: etag || ifNoneMatch || "", notModified: true };
+    }
+    return { context: await res.text(), etag, notModified: false };
+  }
+
+  search(req: SearchRequest): Promise<SearchResponse> {
+    return this.json<SearchResponse>("POST", "/v0/search", req);
+  }
+
+  /** Marks which results of a logged search were useful. */
+  async searchFeedback(queryId: string, usefulEntryIds: string[]): Promise<void> {
+    await this.send("POST", "/v0/search/feedback", { queryId, usefulEntryIds });
+  }
+
+  private async json<T>(method: string, path: string, body?: unknown): Promise<T> {
+    const res = await this.send(method, path, body);
+    return (await res.json()) as T;
+  }
+
+  private async send(method: string, path: string, body?: unknown, extra: Record<string, string> = {}): Promise<Response> {
+    const headers: Record<string, string> = {
+      Authorization: `Bearer ${this.apiKey}`,
+      "X-Request-Timeout": `${this.timeoutMs}ms`,
+      ...extra,
+    };
+    if (body !== undefined) headers["Content-Type"] = "application/json";
+
+    const controller = new AbortController();
+    const timer = setTimeout(() => controller.abort(), this.timeoutMs);
+    try {
+      const res = await this.fetchImpl(this.baseUrl + path, {
+        method,
+        headers,
+        body: body === undefined ? undefined : JSON.stringify(body),
+        signal: controller.signal,
+      });
+      if (!res.ok && res.status !== 304) {
+        throw new MycelianError(res.status, await res.text(), res.headers.get("X-Request-ID"));
+      }
+      return res;
+    } finally {
+      clearTimeout(timer);
+    }
+  }
+}
+
+function enc(segment: string): string {
+  return encodeURIComponent(segment);
+}
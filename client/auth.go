@@ -0,0 +1,143 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+
+	"github.com/mycelian/mycelian-memory/pkg/devauth"
+	"github.com/rs/zerolog/log"
+)
+
+// New requires exactly one credential option: WithAPIKey, WithDevMode or
+// WithOAuth. Nothing falls back to dev mode, so a client pointed at a
+// production endpoint cannot end up sending the shared dev key because a key
+// was missing from its configuration. New logs the mode it settled on.
+
+// ErrNoCredentials is returned by New when no credential option is given.
+var ErrNoCredentials = errors.New("no credentials configured: use WithAPIKey, WithDevMode or WithOAuth")
+
+// Environment variables read by tools that build a client with Credentials.
+const (
+	// EnvDevMode set to a true value ("1", "true") opts into dev mode when
+	// no API key or access token is configured.
+	EnvDevMode = "MYCELIAN_DEV_MODE"
+	// EnvAccessToken is an OAuth access token, used when no API key is set.
+	EnvAccessToken = "MYCELIAN_ACCESS_TOKEN"
+)
+
+// Authentication modes, as logged by New.
+const (
+	authAPIKey  = "apiKey"
+	authDevMode = "devMode"
+	authOAuth   = "oauth"
+)
+
+// TokenSource returns the OAuth access token to send with a request. It is
+// called for every request, so it should cache tokens until they expire.
+type TokenSource func(ctx context.Context) (string, error)
+
+// WithAPIKey authenticates every request with the actor's API key.
+func WithAPIKey(apiKey string) Option {
+	return func(c *Client) error {
+		if apiKey == "" {
+			return fmt.Errorf("apiKey cannot be empty")
+		}
+		if err := c.setAuthMode(authAPIKey); err != nil {
+			return err
+		}
+		c.apiKey = apiKey
+		return nil
+	}
+}
+
+// WithDevMode authenticates with the shared dev API key, which only a server
+// running in development mode (MockAuthorizer) accepts. For local
+// development; not for production use.
+func WithDevMode() Option {
+	return func(c *Client) error {
+		if err := c.setAuthMode(authDevMode); err != nil {
+			return err
+		}
+		c.apiKey = devauth.APIKey
+		return nil
+	}
+}
+
+// WithOAuth authenticates every request with a bearer access token from
+// tokens, for servers whose authorizer accepts OAuth tokens.
+func WithOAuth(tokens TokenSource) Option {
+	return func(c *Client) error {
+		if tokens == nil {
+			return fmt.Errorf("oauth token source cannot be nil")
+		}
+		if err := c.setAuthMode(authOAuth); err != nil {
+			return err
+		}
+		c.tokens = tokens
+		return nil
+	}
+}
+
+// StaticToken returns a TokenSource that always returns token, for tokens
+// obtained outside the client.
+func StaticToken(token string) TokenSource {
+	return func(context.Context) (string, error) { return token, nil }
+}
+
+// DevModeRequested reports whether $MYCELIAN_DEV_MODE is set to a true value.
+func DevModeRequested() bool {
+	on, _ := strconv.ParseBool(os.Getenv(EnvDevMode))
+	return on
+}
+
+// Credentials returns the credential option for a tool that resolved apiKey
+// from its configuration: WithAPIKey when it is set, otherwise WithOAuth
+// with $MYCELIAN_ACCESS_TOKEN, otherwise WithDevMode when devMode was asked
+// for explicitly. With none of them it returns ErrNoCredentials, so a
+// missing key never turns into dev mode.
+func Credentials(apiKey string, devMode bool) (Option, error) {
+	switch token := os.Getenv(EnvAccessToken); {
+	case apiKey != "":
+		return WithAPIKey(apiKey), nil
+	case token != "":
+		return WithOAuth(StaticToken(token)), nil
+	case devMode:
+		return WithDevMode(), nil
+	}
+	return nil, ErrNoCredentials
+}
+
+func (c *Client) setAuthMode(mode string) error {
+	if c.authMode != "" {
+		return fmt.Errorf("conflicting credential options: %s and %s", c.authMode, mode)
+	}
+	c.authMode = mode
+	return nil
+}
+
+// logAuthMode reports the credential strategy New settled on, warning when
+// dev mode targets a host other than the local machine.
+func (c *Client) logAuthMode() {
+	log.Info().Str("auth_mode", c.authMode).Str("base_url", c.baseURL).Msg("client credentials configured")
+	if c.authMode == authDevMode && !isLoopbackURL(c.baseURL) {
+		log.Warn().Str("base_url", c.baseURL).Msg("dev mode credentials sent to a non-local endpoint")
+	}
+}
+
+func isLoopbackURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	host := u.Hostname()
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
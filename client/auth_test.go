@@ -2,14 +2,20 @@ package client
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestDevModeAuth(t *testing.T) {
 	// Test dev mode authentication
-	c, err := NewWithDevMode("http://localhost:11545")
+	c, err := New("http://localhost:11545", WithDevMode())
 	if err != nil {
-		t.Fatalf("NewWithDevMode failed: %v", err)
+		t.Fatalf("New failed: %v", err)
 	}
 	defer func() { _ = c.Close() }()
 
@@ -35,3 +41,133 @@ func TestDevModeAuth(t *testing.T) {
 		t.Log("Consistency achieved")
 	}
 }
+
+func TestIsUnauthorized(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error":"Unauthorized"}`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, WithAPIKey("bad-key"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer func() { _ = c.Close() }()
+
+	_, err = c.ListVaults(context.Background())
+	if !IsUnauthorized(err) {
+		t.Fatalf("expected unauthorized error, got %v", err)
+	}
+	if IsUnauthorized(ErrBackPressure) {
+		t.Fatal("back-pressure must not be classified as unauthorized")
+	}
+}
+
+func TestTransportSendsRequestTimeoutFromDeadline(t *testing.T) {
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get("X-Request-Timeout"))
+		_, _ = w.Write([]byte(`{"vaults":[],"count":0}`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, WithAPIKey("k"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer func() { _ = c.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, _ = c.ListVaults(ctx)
+	_, _ = c.ListVaults(context.Background())
+
+	if len(got) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(got))
+	}
+	d, err := time.ParseDuration(got[0])
+	if err != nil || d <= 0 || d > 5*time.Second {
+		t.Fatalf("expected remaining deadline header, got %q", got[0])
+	}
+	// Without a caller deadline the http.Client timeout (30s) still bounds the wait.
+	if d, err := time.ParseDuration(got[1]); err != nil || d <= 5*time.Second || d > 30*time.Second {
+		t.Fatalf("expected client timeout as header, got %q", got[1])
+	}
+}
+
+func TestNewRequiresExactlyOneCredential(t *testing.T) {
+	if _, err := New("http://localhost:1"); !errors.Is(err, ErrNoCredentials) {
+		t.Fatalf("no credentials: want ErrNoCredentials, got %v", err)
+	}
+	if _, err := New("http://localhost:1", WithAPIKey("")); err == nil {
+		t.Fatal("empty API key must be rejected")
+	}
+	if _, err := New("http://localhost:1", WithAPIKey("k"), WithDevMode()); err == nil || !strings.Contains(err.Error(), "conflicting") {
+		t.Fatalf("two credential options: want conflict error, got %v", err)
+	}
+	if _, err := New("http://localhost:1", WithOAuth(nil)); err == nil {
+		t.Fatal("nil token source must be rejected")
+	}
+}
+
+func TestCredentials(t *testing.T) {
+	t.Setenv(EnvAccessToken, "")
+	mode := func(apiKey string, devMode bool) string {
+		t.Helper()
+		opt, err := Credentials(apiKey, devMode)
+		if err != nil {
+			return err.Error()
+		}
+		c := &Client{}
+		if err := opt(c); err != nil {
+			t.Fatalf("option: %v", err)
+		}
+		return c.authMode
+	}
+	if got := mode("", false); got != ErrNoCredentials.Error() {
+		t.Fatalf("nothing configured: got %q, want ErrNoCredentials", got)
+	}
+	if got := mode("", true); got != authDevMode {
+		t.Fatalf("dev mode opt-in: got %q", got)
+	}
+	if got := mode("k", true); got != authAPIKey {
+		t.Fatalf("API key: got %q", got)
+	}
+	t.Setenv(EnvAccessToken, "tok")
+	if got := mode("", true); got != authOAuth {
+		t.Fatalf("access token: got %q", got)
+	}
+}
+
+func TestOAuthSendsFreshTokenPerRequest(t *testing.T) {
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{"vaults":[],"count":0}`))
+	}))
+	defer srv.Close()
+
+	n := 0
+	c, err := New(srv.URL, WithOAuth(func(context.Context) (string, error) {
+		n++
+		if n == 3 {
+			return "", errors.New("refresh failed")
+		}
+		return fmt.Sprintf("tok-%d", n), nil
+	}))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer func() { _ = c.Close() }()
+
+	ctx := context.Background()
+	_, _ = c.ListVaults(ctx)
+	_, _ = c.ListVaults(ctx)
+	if _, err := c.ListVaults(ctx); err == nil || !strings.Contains(err.Error(), "refresh failed") {
+		t.Fatalf("token source failure: got %v", err)
+	}
+	if len(got) != 2 || got[0] != "Bearer tok-1" || got[1] != "Bearer tok-2" {
+		t.Fatalf("Authorization headers = %v", got)
+	}
+}
@@ -10,7 +10,7 @@ import (
 )
 
 func TestAwaitConsistency(t *testing.T) {
-	c, err := New("http://example.com", "test-api-key")
+	c, err := New("http://example.com", WithAPIKey("test-api-key"))
 	if err != nil {
 		t.Fatalf("New: %v", err)
 	}
@@ -0,0 +1,126 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/mycelian/mycelian-memory/client/internal/api"
+	clienterrors "github.com/mycelian/mycelian-memory/client/internal/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// Servers list their enabled features at GET /v0/capabilities so one SDK
+// version can work against servers of different versions during rollouts.
+// Once the client knows the capabilities (see WithCapabilityNegotiation and
+// Capabilities), calls needing a feature the server reports as disabled fail
+// with ErrUnsupported instead of sending a request. Until then, and against
+// servers that predate the endpoint, every call is attempted as before.
+
+// Feature names reported by GET /v0/capabilities.
+const (
+	FeatureSearch             = "search"
+	FeatureSearchExplain      = "searchExplain"
+	FeatureEntriesScan        = "entriesScan"
+	FeatureIngestionBatches   = "ingestionBatches"
+	FeatureConversations      = "conversations"
+	FeatureContextDocuments   = "contextDocuments"
+	FeatureAppendOnlyMemories = "appendOnlyMemories"
+	FeatureEntriesBatch       = "entriesBatch"
+	FeatureConversationTime   = "conversationTime"
+	FeatureVaultSearch        = "vaultSearch"
+	FeatureReranker           = "reranker"
+	FeatureEntityAliases      = "entityAliases"
+	FeatureSearchTimeWindows  = "searchTimeWindows"
+	FeatureActorDefaults      = "actorDefaults"
+	FeatureSummarize          = "summarize"
+	FeatureSearchBatch        = "searchBatch"
+	FeatureContextSections    = "contextSections"
+	FeatureEntryUsage         = "entryUsage"
+	FeatureTitleUpdates       = "titleUpdates"
+	FeatureEntryRoles         = "entryRoles"
+	FeatureRankingProfiles    = "rankingProfiles"
+	FeatureIndexStatus        = "indexStatus"
+	FeatureBulkTagUpdates     = "bulkTagUpdates"
+	FeatureContextCheck       = "contextCheck"
+	FeatureSimilarEntries     = "similarEntries"
+	FeatureVaultClone         = "vaultClone"
+	FeatureSearchTitleScopes  = "searchTitleScopes"
+	FeatureRecentSummaries    = "recentSummaries"
+	FeatureSearchGrouping     = "searchGrouping"
+	FeatureBootstrap          = "bootstrap"
+	FeatureWebhooks           = "webhooks"
+	FeatureEntriesPagination  = "entriesPagination"
+	FeatureTrash              = "trash"
+	FeatureSearchBoost        = "searchBoost"
+	FeatureJobs               = "jobs"
+	FeatureEntryExpiry        = "entryExpiry"
+	FeatureIdempotentEntries  = "idempotentEntries"
+	FeatureEntryCorrections   = "entryCorrections"
+	FeatureTagFilters         = "tagFilters"
+)
+
+// WithCapabilityNegotiation makes New fetch the server's capabilities,
+// waiting at most timeout. A failed fetch is logged and does not fail New;
+// the client then behaves as if negotiation was not requested.
+func WithCapabilityNegotiation(timeout time.Duration) Option {
+	return func(c *Client) error {
+		if timeout <= 0 {
+			return fmt.Errorf("capability negotiation timeout must be > 0")
+		}
+		c.negotiateTimeout = timeout
+		return nil
+	}
+}
+
+// Capabilities returns the server's enabled features, fetched once and then
+// cached for the client's lifetime. A server without the endpoint yields
+// Capabilities with Legacy set and no features.
+func (c *Client) Capabilities(ctx context.Context) (*Capabilities, error) {
+	if caps := c.caps.Load(); caps != nil {
+		return caps, nil
+	}
+	caps, err := api.GetCapabilities(ctx, c.http, c.baseURL)
+	if err != nil {
+		var ce *clienterrors.ClassifiedError
+		if !errors.As(err, &ce) || ce.StatusCode != http.StatusNotFound {
+			return nil, err
+		}
+		caps = &Capabilities{Legacy: true, Features: map[string]bool{}}
+	}
+	if caps.Features == nil {
+		caps.Features = map[string]bool{}
+	}
+	c.caps.Store(caps)
+	return caps, nil
+}
+
+// Supports reports whether the server lists feature as enabled. It is false
+// for servers that predate capability negotiation.
+func (c *Client) Supports(ctx context.Context, feature string) (bool, error) {
+	caps, err := c.Capabilities(ctx)
+	if err != nil {
+		return false, err
+	}
+	return caps.Features[feature], nil
+}
+
+// requireFeature returns ErrUnsupported when capabilities are already known
+// and the server reports feature as disabled. It never sends a request.
+func (c *Client) requireFeature(feature string) error {
+	caps := c.caps.Load()
+	if caps == nil || caps.Legacy || caps.Features[feature] {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrUnsupported, feature)
+}
+
+func (c *Client) negotiateCapabilities() {
+	ctx, cancel := context.WithTimeout(context.Background(), c.negotiateTimeout)
+	defer cancel()
+	if _, err := c.Capabilities(ctx); err != nil {
+		log.Warn().Err(err).Msg("capability negotiation failed; all calls will be attempted")
+	}
+}
@@ -0,0 +1,80 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCapabilityNegotiationGatesUnsupportedCalls(t *testing.T) {
+	var fetches, scans atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v0/capabilities":
+			fetches.Add(1)
+			_, _ = w.Write([]byte(`{"apiVersion":"v0","features":{"searchExplain":true,"entriesScan":false}}`))
+		default:
+			scans.Add(1)
+			_, _ = w.Write([]byte(`{"entries":[],"count":0}`))
+		}
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, WithAPIKey("k"), WithCapabilityNegotiation(time.Second))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer func() { _ = c.Close() }()
+	ctx := context.Background()
+
+	if ok, err := c.Supports(ctx, FeatureSearchExplain); err != nil || !ok {
+		t.Fatalf("Supports(searchExplain) = %v, %v", ok, err)
+	}
+	if _, err := c.ScanEntries(ctx, "v1", "m1", ScanEntriesRequest{Contains: "x"}); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("ScanEntries: expected ErrUnsupported, got %v", err)
+	}
+	if _, err := c.Search(ctx, SearchRequest{MemoryID: "m1", Query: "q", GroupBy: GroupBySession}); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("Search with GroupBy: expected ErrUnsupported, got %v", err)
+	}
+	if fetches.Load() != 1 || scans.Load() != 0 {
+		t.Fatalf("fetches=%d scans=%d", fetches.Load(), scans.Load())
+	}
+}
+
+func TestCapabilitiesLegacyServerAttemptsEveryCall(t *testing.T) {
+	var scans atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v0/capabilities" {
+			http.NotFound(w, r)
+			return
+		}
+		scans.Add(1)
+		_, _ = w.Write([]byte(`{"entries":[],"count":0}`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, WithAPIKey("k"), WithCapabilityNegotiation(time.Second))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer func() { _ = c.Close() }()
+	ctx := context.Background()
+
+	caps, err := c.Capabilities(ctx)
+	if err != nil || !caps.Legacy {
+		t.Fatalf("Capabilities = %+v, %v", caps, err)
+	}
+	if ok, _ := c.Supports(ctx, FeatureEntriesScan); ok {
+		t.Fatal("a legacy server lists no features")
+	}
+	if _, err := c.ScanEntries(ctx, "v1", "m1", ScanEntriesRequest{Contains: "x"}); err != nil {
+		t.Fatalf("ScanEntries against a legacy server: %v", err)
+	}
+	if scans.Load() != 1 {
+		t.Fatalf("scans = %d", scans.Load())
+	}
+}
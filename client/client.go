@@ -14,7 +14,6 @@ import (
 	"github.com/mycelian/mycelian-memory/client/internal/errors"
 	"github.com/mycelian/mycelian-memory/client/internal/shardqueue"
 	promptsinternal "github.com/mycelian/mycelian-memory/client/prompts"
-	"github.com/mycelian/mycelian-memory/pkg/devauth"
 	"github.com/rs/zerolog/log"
 )
 
@@ -48,6 +47,32 @@ type Client struct {
 	http    *http.Client
 	exec    executor
 	apiKey  string // API key for actor authentication (must be explicitly configured)
+	// authMode is the credential option given to New; tokens supplies the
+	// bearer token per request under WithOAuth. See auth.go.
+	authMode string
+	tokens   TokenSource
+
+	// Search resilience, see search_retry.go; zero values disable it.
+	searchRetries int
+	searchBackoff time.Duration
+	searchCache   *searchCache
+	// pending tracks unindexed writes for read-your-writes; nil disables it.
+	pending *pendingWrites
+	// syncWrites makes AddEntry wait for the created entry, see sync_writes.go.
+	syncWrites bool
+	// coalescer merges rapid PutContext calls, see context_coalesce.go; nil
+	// disables it. When set it also wraps exec.
+	coalescer *contextCoalescer
+	// journal records async writes for replay after a crash, see
+	// durable_queue.go; nil disables it. journalPath is where New opens it.
+	journal     *writeJournal
+	journalPath string
+	// caps caches the server's capabilities once known, see capabilities.go;
+	// negotiateTimeout > 0 fetches them in New.
+	caps             atomic.Pointer[Capabilities]
+	negotiateTimeout time.Duration
+	// metadata holds the typed metadata codec and types, see typed_metadata.go.
+	metadata *metadataTypes
 
 	closedOnce uint32 // ensures Close is idempotent
 }
@@ -55,32 +80,29 @@ type Client struct {
 // Verify Client implements io.Closer
 var _ io.Closer = (*Client)(nil)
 
-// New constructs a Client with the specified baseURL and apiKey.
+// New constructs a Client for baseURL. Exactly one credential option
+// (WithAPIKey, WithDevMode or WithOAuth) is required; see auth.go.
 // It returns an error for invalid inputs or option failures.
-// Additional options can be provided via functional arguments.
-func New(baseURL, apiKey string, opts ...Option) (*Client, error) {
+func New(baseURL string, opts ...Option) (*Client, error) {
 	if baseURL == "" {
 		return nil, fmt.Errorf("baseURL cannot be empty")
 	}
-	if apiKey == "" {
-		return nil, fmt.Errorf("apiKey cannot be empty")
-	}
 
 	// Normalize baseURL to avoid trailing-slash issues when composing URLs
 	baseURL = strings.TrimRight(baseURL, "/")
 
 	c := &Client{
 		baseURL: baseURL,
-		apiKey:  apiKey,
 		http: &http.Client{
 			Timeout:   30 * time.Second,
 			Transport: http.DefaultTransport, // Initialize transport early
 		},
+		metadata: newMetadataTypes(),
 	}
 
 	// Auto-enable debug via env variable without changing code.
 	if debugLoggingRequested() {
-		opts = append(opts, WithDebugLogging(true))
+		opts = append(opts[:len(opts):len(opts)], WithDebugLogging(true))
 	}
 
 	for _, opt := range opts {
@@ -88,22 +110,31 @@ func New(baseURL, apiKey string, opts ...Option) (*Client, error) {
 			return nil, err
 		}
 	}
+	if c.authMode == "" {
+		return nil, ErrNoCredentials
+	}
+	c.logAuthMode()
 	if c.exec == nil {
 		c.exec = newDefaultExecutor()
 	}
+	if c.coalescer != nil {
+		c.installCoalescer()
+	}
 
 	// Wrap HTTP transport to automatically add Authorization header
 	c.wrapTransportWithAPIKey()
 
-	return c, nil
-}
+	if c.negotiateTimeout > 0 {
+		c.negotiateCapabilities()
+	}
+	if c.journalPath != "" {
+		if err := c.openDurableQueue(); err != nil {
+			_, _ = c.exec.Shutdown(context.Background())
+			return nil, err
+		}
+	}
 
-// NewWithDevMode constructs a Client for development mode using the shared dev API key.
-// This only works when the server is running in development mode with MockAuthorizer.
-// Convenience constructor for local development; not for production use.
-func NewWithDevMode(baseURL string, opts ...Option) (*Client, error) {
-	// Use the shared dev API key that MockAuthorizer recognizes
-	return New(baseURL, devauth.APIKey, opts...)
+	return c, nil
 }
 
 // wrapTransportWithAPIKey wraps the HTTP client's transport so every request
@@ -114,40 +145,88 @@ func (c *Client) wrapTransportWithAPIKey() {
 	c.http.Transport = &apiKeyTransport{
 		base:   c.http.Transport,
 		apiKey: c.apiKey,
+		tokens: c.tokens,
 	}
 }
 
 // apiKeyTransport wraps an http.RoundTripper to automatically add the
-// Authorization header. A minimal default User-Agent is also added when absent
-// to aid observability during debugging.
+// Authorization header, with the API key or, when tokens is set, a fresh
+// OAuth access token. A minimal default User-Agent is also added when absent
+// to aid observability during debugging. When the request context carries a
+// deadline, the remaining time is sent as X-Request-Timeout so the server stops
+// work the caller will no longer wait for.
+// requestTimeoutHeader tells the server how long the caller will wait.
+const requestTimeoutHeader = "X-Request-Timeout"
+
 type apiKeyTransport struct {
 	base   http.RoundTripper
 	apiKey string
+	tokens TokenSource
 }
 
 func (t *apiKeyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	bearer := t.apiKey
+	if t.tokens != nil {
+		token, err := t.tokens(req.Context())
+		if err != nil {
+			return nil, fmt.Errorf("oauth token: %w", err)
+		}
+		bearer = token
+	}
 	// Clone the request to avoid modifying the original
 	cloned := req.Clone(req.Context())
 	// Set Authorization in one authoritative place
-	cloned.Header.Set("Authorization", "Bearer "+t.apiKey)
+	cloned.Header.Set("Authorization", "Bearer "+bearer)
 	// Add a default User-Agent only if caller didn't set one
 	if cloned.Header.Get("User-Agent") == "" {
 		cloned.Header.Set("User-Agent", defaultUserAgent)
 	}
+	if dl, ok := req.Context().Deadline(); ok && cloned.Header.Get(requestTimeoutHeader) == "" {
+		if remaining := time.Until(dl); remaining > 0 {
+			cloned.Header.Set(requestTimeoutHeader, remaining.Round(time.Millisecond).String())
+		}
+	}
 	return t.base.RoundTrip(cloned)
 }
 
-// Close stops the background executor (if any). Safe to call multiple times.
+// Close stops the background executor (if any), waiting for every enqueued
+// write to be attempted. Safe to call multiple times. Use Shutdown to bound
+// the wait and learn which writes reached the server.
 func (c *Client) Close() error {
 	if !atomic.CompareAndSwapUint32(&c.closedOnce, 0, 1) {
 		return nil
 	}
 	if c.exec != nil {
-		c.exec.Stop()
+		_, _ = c.exec.Shutdown(context.Background())
+	}
+	if c.journal != nil {
+		return c.journal.close()
 	}
 	return nil
 }
 
+// Shutdown stops accepting async writes and drains the queues until ctx is
+// done. Writes still queued or in flight at the deadline are abandoned. The
+// report counts, per memory, the writes submitted over the client's lifetime
+// and how many were flushed, failed, or dropped; FlushReport.Complete is true
+// only when every one reached the server. The error is ctx.Err() when the
+// deadline cut the drain short. Calling Shutdown again, or after Close,
+// returns the same report. With WithDurableQueue the abandoned writes stay
+// journaled for the next client.
+func (c *Client) Shutdown(ctx context.Context) (FlushReport, error) {
+	atomic.StoreUint32(&c.closedOnce, 1)
+	if c.exec == nil {
+		return FlushReport{}, nil
+	}
+	report, err := c.exec.Shutdown(ctx)
+	if c.journal != nil {
+		if jerr := c.journal.close(); err == nil {
+			err = jerr
+		}
+	}
+	return report, err
+}
+
 // AwaitConsistency blocks until all previously submitted jobs for memoryID
 // have been executed by the internal executor. It delegates to the executor's
 // Barrier so the client does not manipulate jobs directly.
@@ -155,6 +234,43 @@ func (c *Client) AwaitConsistency(ctx context.Context, memoryID string) error {
 	return c.exec.Barrier(ctx, memoryID)
 }
 
+// Flush waits, without stopping the client, until every async write that was
+// pending when it was called has been attempted. The report covers those
+// memories only; its counters span the client's lifetime, so Complete is false
+// if any earlier write to them failed. An empty report means nothing was
+// pending. The error is ctx.Err() when ctx ends before the queues drain.
+func (c *Client) Flush(ctx context.Context) (FlushReport, error) {
+	if c.exec == nil {
+		return FlushReport{}, nil
+	}
+	if c.coalescer != nil {
+		if err := c.coalescer.flushAll(ctx); err != nil {
+			return FlushReport{}, err
+		}
+	}
+	var pending []string
+	for memID, r := range c.exec.Report() {
+		if r.Pending() > 0 {
+			pending = append(pending, memID)
+		}
+	}
+	for _, memID := range pending {
+		if err := c.exec.Barrier(ctx, memID); err != nil {
+			return c.reportFor(pending), err
+		}
+	}
+	return c.reportFor(pending), nil
+}
+
+func (c *Client) reportFor(memoryIDs []string) FlushReport {
+	all := c.exec.Report()
+	out := make(FlushReport, len(memoryIDs))
+	for _, memID := range memoryIDs {
+		out[memID] = all[memID]
+	}
+	return out
+}
+
 // newDefaultExecutor constructs the shardqueue executor with sane defaults.
 func newDefaultExecutor() *shardqueue.ShardExecutor {
 	cfg := shardqueue.Config{
@@ -205,21 +321,175 @@ func (c *Client) CreateMemory(ctx context.Context, vaultID string, req CreateMem
 	return api.CreateMemory(ctx, c.http, c.baseURL, vaultID, req)
 }
 
-// ListMemories retrieves memories within a vault.
+// ListMemories retrieves memories within a vault. vaultID may also be the
+// vault's title or slug.
 func (c *Client) ListMemories(ctx context.Context, vaultID string) ([]Memory, error) {
 	return api.ListMemories(ctx, c.http, c.baseURL, vaultID)
 }
 
-// GetMemory retrieves a specific memory.
+// GetMemory retrieves a specific memory. vaultID and memoryID may also be
+// titles or slugs, e.g. GetMemory(ctx, "Travel Notes", "trip-plan").
 func (c *Client) GetMemory(ctx context.Context, vaultID, memoryID string) (*Memory, error) {
 	return api.GetMemory(ctx, c.http, c.baseURL, vaultID, memoryID)
 }
 
-// DeleteMemory deletes a specific memory.
+// SetMemoryAppendOnly makes a memory append-only for good. Afterwards the
+// service rejects tag updates and deletes of its entries with 409; see
+// IsAppendOnly.
+func (c *Client) SetMemoryAppendOnly(ctx context.Context, vaultID, memoryID string) (*Memory, error) {
+	if err := c.requireFeature(FeatureAppendOnlyMemories); err != nil {
+		return nil, err
+	}
+	return api.SetMemoryAppendOnly(ctx, c.http, c.baseURL, vaultID, memoryID)
+}
+
+// SetMemoryEntryRoles makes the service reject new entries of the memory
+// whose Metadata["role"] is not one of roles (user, assistant, system or
+// tool) with 400. Nil or empty roles lift the requirement.
+func (c *Client) SetMemoryEntryRoles(ctx context.Context, vaultID, memoryID string, roles []string) (*Memory, error) {
+	if err := c.requireFeature(FeatureEntryRoles); err != nil {
+		return nil, err
+	}
+	return api.SetMemoryEntryRoles(ctx, c.http, c.baseURL, vaultID, memoryID, roles)
+}
+
+// SetMemorySearchBoost sets how the memory's hits rank against the other
+// memories of a vault-scope search: Boost scales their scores and
+// FieldWeights weigh the keyword match per field. A zero SearchBoost clears
+// it.
+func (c *Client) SetMemorySearchBoost(ctx context.Context, vaultID, memoryID string, b SearchBoost) (*Memory, error) {
+	if err := c.requireFeature(FeatureSearchBoost); err != nil {
+		return nil, err
+	}
+	return api.SetMemorySearchBoost(ctx, c.http, c.baseURL, vaultID, memoryID, b)
+}
+
+// DeleteEntries queues a server job deleting the memory's entries selected
+// by req, into the trash on servers with FeatureTrash, and returns it at
+// once; poll GetJob for its progress. Requires FeatureJobs.
+func (c *Client) DeleteEntries(ctx context.Context, vaultID, memoryID string, req DeleteEntriesRequest) (*Job, error) {
+	if err := c.requireFeature(FeatureJobs); err != nil {
+		return nil, err
+	}
+	return api.DeleteEntries(ctx, c.http, c.baseURL, vaultID, memoryID, req)
+}
+
+// GetJob returns a background job started by the caller with its status
+// and progress. Requires FeatureJobs.
+func (c *Client) GetJob(ctx context.Context, jobID string) (*Job, error) {
+	if err := c.requireFeature(FeatureJobs); err != nil {
+		return nil, err
+	}
+	return api.GetJob(ctx, c.http, c.baseURL, jobID)
+}
+
+// CancelJob cancels a queued job; a running one stops at its next progress
+// report, and work it already did is not undone. Cancelling a finished job
+// fails with 409. Requires FeatureJobs.
+func (c *Client) CancelJob(ctx context.Context, jobID string) (*Job, error) {
+	if err := c.requireFeature(FeatureJobs); err != nil {
+		return nil, err
+	}
+	return api.CancelJob(ctx, c.http, c.baseURL, jobID)
+}
+
+// UpdateMemory renames the memory and/or changes its description. A new
+// title reaches the memory's search index objects asynchronously.
+func (c *Client) UpdateMemory(ctx context.Context, vaultID, memoryID string, req UpdateTitleRequest) (*Memory, error) {
+	if err := c.requireFeature(FeatureTitleUpdates); err != nil {
+		return nil, err
+	}
+	return api.UpdateMemory(ctx, c.http, c.baseURL, vaultID, memoryID, req)
+}
+
+// SummarizeMemory has the server rewrite the memory's context document from
+// its newest entries with the server's LLM, for out-of-band summary
+// refreshes. With req.Preview the proposed context is returned unsaved.
+func (c *Client) SummarizeMemory(ctx context.Context, vaultID, memoryID string, req SummarizeMemoryRequest) (*SummarizeMemoryResult, error) {
+	if err := c.requireFeature(FeatureSummarize); err != nil {
+		return nil, err
+	}
+	return api.SummarizeMemory(ctx, c.http, c.baseURL, vaultID, memoryID, req)
+}
+
+// ListEntityAliases returns the memory's entity aliases.
+func (c *Client) ListEntityAliases(ctx context.Context, vaultID, memoryID string) (*ListEntityAliasesResponse, error) {
+	if err := c.requireFeature(FeatureEntityAliases); err != nil {
+		return nil, err
+	}
+	return api.ListEntityAliases(ctx, c.http, c.baseURL, vaultID, memoryID)
+}
+
+// PutEntityAlias registers alias (e.g. "Bob") as another name of canonical
+// (e.g. "Robert Smith") in the memory. Searches over the memory that mention
+// either name also match the other, and new entries mentioning either are
+// annotated with the canonical name.
+func (c *Client) PutEntityAlias(ctx context.Context, vaultID, memoryID, alias, canonical string) (*EntityAlias, error) {
+	if err := c.requireFeature(FeatureEntityAliases); err != nil {
+		return nil, err
+	}
+	return api.PutEntityAlias(ctx, c.http, c.baseURL, vaultID, memoryID, alias, canonical)
+}
+
+// DeleteEntityAlias removes one alias from the memory.
+func (c *Client) DeleteEntityAlias(ctx context.Context, vaultID, memoryID, alias string) error {
+	if err := c.requireFeature(FeatureEntityAliases); err != nil {
+		return err
+	}
+	return api.DeleteEntityAlias(ctx, c.http, c.baseURL, vaultID, memoryID, alias)
+}
+
+// CreateWebhook sets up an inbound webhook that writes signed JSON payloads
+// POSTed to /v0/hooks/{webhookId} as entries of the memory. The response is
+// the only one carrying the webhook's secret. Requires FeatureWebhooks.
+func (c *Client) CreateWebhook(ctx context.Context, vaultID, memoryID string, req CreateWebhookRequest) (*Webhook, error) {
+	if err := c.requireFeature(FeatureWebhooks); err != nil {
+		return nil, err
+	}
+	return api.CreateWebhook(ctx, c.http, c.baseURL, vaultID, memoryID, req)
+}
+
+// ListWebhooks returns the memory's webhooks, without secrets.
+func (c *Client) ListWebhooks(ctx context.Context, vaultID, memoryID string) (*ListWebhooksResponse, error) {
+	if err := c.requireFeature(FeatureWebhooks); err != nil {
+		return nil, err
+	}
+	return api.ListWebhooks(ctx, c.http, c.baseURL, vaultID, memoryID)
+}
+
+// DeleteWebhook removes a webhook; later deliveries to it are rejected.
+func (c *Client) DeleteWebhook(ctx context.Context, vaultID, memoryID, webhookID string) error {
+	if err := c.requireFeature(FeatureWebhooks); err != nil {
+		return err
+	}
+	return api.DeleteWebhook(ctx, c.http, c.baseURL, vaultID, memoryID, webhookID)
+}
+
+// DeleteMemory deletes a specific memory. On servers with FeatureTrash the
+// memory goes to the trash and can be restored with RestoreMemory until it
+// is purged.
 func (c *Client) DeleteMemory(ctx context.Context, vaultID, memoryID string) error {
 	return api.DeleteMemory(ctx, c.http, c.baseURL, vaultID, memoryID)
 }
 
+// RestoreMemory takes a trashed memory, with its entries, out of the trash.
+// Requires FeatureTrash.
+func (c *Client) RestoreMemory(ctx context.Context, vaultID, memoryID string) (*Memory, error) {
+	if err := c.requireFeature(FeatureTrash); err != nil {
+		return nil, err
+	}
+	return api.RestoreMemory(ctx, c.http, c.baseURL, vaultID, memoryID)
+}
+
+// ListTrash lists the vault's trashed memories and the trashed entries of
+// its other memories, most recently deleted first. Requires FeatureTrash.
+func (c *Client) ListTrash(ctx context.Context, vaultID string) (*Trash, error) {
+	if err := c.requireFeature(FeatureTrash); err != nil {
+		return nil, err
+	}
+	return api.ListTrash(ctx, c.http, c.baseURL, vaultID)
+}
+
 // --------------------------------------------------------------------
 // Vault operations - delegated to internal/api
 // --------------------------------------------------------------------
@@ -229,6 +499,38 @@ func (c *Client) CreateVault(ctx context.Context, req CreateVaultRequest) (*Vaul
 	return api.CreateVault(ctx, c.http, c.baseURL, req)
 }
 
+// CreateVaultFromTemplate creates a vault pre-populated with the memories
+// and starting contexts of a server-side template (see ListVaultTemplates).
+func (c *Client) CreateVaultFromTemplate(ctx context.Context, req CreateVaultFromTemplateRequest) (*TemplatedVault, error) {
+	return api.CreateVaultFromTemplate(ctx, c.http, c.baseURL, req)
+}
+
+// CloneVault copies vaultID's memories, entries and latest contexts into a
+// new vault titled req.Title, with fresh IDs. Requires FeatureVaultClone.
+func (c *Client) CloneVault(ctx context.Context, vaultID string, req CloneVaultRequest) (*ClonedVault, error) {
+	if err := c.requireFeature(FeatureVaultClone); err != nil {
+		return nil, err
+	}
+	return api.CloneVault(ctx, c.http, c.baseURL, vaultID, req)
+}
+
+// Bootstrap creates a vault, a memory in it and the memory's first context
+// atomically, replacing the CreateVault, CreateMemory, PutContext sequence.
+// Set req.ClientToken so a retry after a lost response returns the first
+// result (with Replayed set) instead of failing on the taken title.
+// Requires FeatureBootstrap.
+func (c *Client) Bootstrap(ctx context.Context, req BootstrapRequest) (*BootstrapResponse, error) {
+	if err := c.requireFeature(FeatureBootstrap); err != nil {
+		return nil, err
+	}
+	return api.Bootstrap(ctx, c.http, c.baseURL, req)
+}
+
+// ListVaultTemplates returns the templates the server offers, sorted by name.
+func (c *Client) ListVaultTemplates(ctx context.Context) ([]VaultTemplate, error) {
+	return api.ListVaultTemplates(ctx, c.http, c.baseURL)
+}
+
 // ListVaults returns all vaults.
 func (c *Client) ListVaults(ctx context.Context) ([]Vault, error) {
 	return api.ListVaults(ctx, c.http, c.baseURL)
@@ -239,12 +541,54 @@ func (c *Client) GetVault(ctx context.Context, vaultID string) (*Vault, error) {
 	return api.GetVault(ctx, c.http, c.baseURL, vaultID)
 }
 
+// GetVaultStats reports, per memory, the stored entry and context counts next
+// to the search index's object counts, to spot indexing gaps.
+func (c *Client) GetVaultStats(ctx context.Context, vaultID string) (*VaultStats, error) {
+	return api.GetVaultStats(ctx, c.http, c.baseURL, vaultID)
+}
+
+// GetIndexStatus reports for each of ids, entry or context IDs of the
+// memory (at most 100), whether it is searchable yet. Requires
+// FeatureIndexStatus.
+func (c *Client) GetIndexStatus(ctx context.Context, vaultID, memoryID string, ids ...string) (map[string]IndexStatus, error) {
+	if err := c.requireFeature(FeatureIndexStatus); err != nil {
+		return nil, err
+	}
+	return api.GetIndexStatus(ctx, c.http, c.baseURL, vaultID, memoryID, ids)
+}
+
+// RetryIndexing re-enqueues the memory's entries and contexts whose
+// indexing failed, only those in ids when given, and returns how many.
+// Requires FeatureIndexStatus.
+func (c *Client) RetryIndexing(ctx context.Context, vaultID, memoryID string, ids ...string) (int, error) {
+	if err := c.requireFeature(FeatureIndexStatus); err != nil {
+		return 0, err
+	}
+	return api.RetryIndexing(ctx, c.http, c.baseURL, vaultID, memoryID, ids)
+}
+
 // DeleteVault deletes the vault. Backend returns 204 No Content on success.
 func (c *Client) DeleteVault(ctx context.Context, vaultID string) error {
 	return api.DeleteVault(ctx, c.http, c.baseURL, vaultID)
 }
 
-// GetVaultByTitle fetches a vault by its title.
+// SetVaultReadOnly marks a vault read-only (or clears the flag). While set,
+// the service rejects every write to the vault with 409; see IsReadOnly.
+func (c *Client) SetVaultReadOnly(ctx context.Context, vaultID string, readOnly bool) (*Vault, error) {
+	return api.SetVaultReadOnly(ctx, c.http, c.baseURL, vaultID, readOnly)
+}
+
+// UpdateVault renames the vault and/or changes its description. The service
+// answers 409 when another vault already has the new title.
+func (c *Client) UpdateVault(ctx context.Context, vaultID string, req UpdateTitleRequest) (*Vault, error) {
+	if err := c.requireFeature(FeatureTitleUpdates); err != nil {
+		return nil, err
+	}
+	return api.UpdateVault(ctx, c.http, c.baseURL, vaultID, req)
+}
+
+// GetVaultByTitle fetches a vault by its title or slug; titles may contain
+// spaces and non-ASCII letters.
 func (c *Client) GetVaultByTitle(ctx context.Context, vaultTitle string) (*Vault, error) {
 	return api.GetVaultByTitle(ctx, c.http, c.baseURL, vaultTitle)
 }
@@ -253,9 +597,153 @@ func (c *Client) GetVaultByTitle(ctx context.Context, vaultTitle string) (*Vault
 // Search operations - delegated to internal/api
 // --------------------------------------------------------------------
 
-// Search runs a search query against the backend.
+// Search runs a search query against the backend. See WithSearchRetries and
+// WithSearchCache for retrying transient failures and serving stale results,
+// and WithReadYourWrites for including this client's unindexed writes.
+// Window, Since and Until need a server with FeatureSearchTimeWindows;
+// MemoryTitles and MemoryPattern need FeatureSearchTitleScopes; GroupBy
+// needs FeatureSearchGrouping; IncludeSuperseded needs
+// FeatureEntryCorrections; Tags needs FeatureTagFilters.
 func (c *Client) Search(ctx context.Context, req SearchRequest) (*SearchResponse, error) {
-	return api.Search(ctx, c.http, c.baseURL, req)
+	if len(req.MemoryTitles) > 0 || req.MemoryPattern != "" {
+		if err := c.requireFeature(FeatureSearchTitleScopes); err != nil {
+			return nil, err
+		}
+	}
+	if req.Window != "" || req.Since != "" || req.Until != "" {
+		if err := c.requireFeature(FeatureSearchTimeWindows); err != nil {
+			return nil, err
+		}
+	}
+	if req.Profile != "" {
+		if err := c.requireFeature(FeatureRankingProfiles); err != nil {
+			return nil, err
+		}
+	}
+	if req.GroupBy != "" {
+		if err := c.requireFeature(FeatureSearchGrouping); err != nil {
+			return nil, err
+		}
+	}
+	if req.IncludeSuperseded {
+		if err := c.requireFeature(FeatureEntryCorrections); err != nil {
+			return nil, err
+		}
+	}
+	if len(req.Tags) > 0 {
+		if err := c.requireFeature(FeatureTagFilters); err != nil {
+			return nil, err
+		}
+	}
+	resp, err := c.searchWithFallback(ctx, req)
+	if err == nil && c.pending != nil {
+		c.pending.merge(req, resp)
+	}
+	return resp, err
+}
+
+// SearchBatch runs up to 50 queries against one memory with the same TopK,
+// filters and ranking in a single request; the server runs them
+// concurrently. A failed query has BatchSearchResult.Error set instead of
+// failing the batch. Unlike Search it is not retried or served from cache.
+// Requires FeatureSearchBatch.
+func (c *Client) SearchBatch(ctx context.Context, req BatchSearchRequest) (*BatchSearchResponse, error) {
+	if err := c.requireFeature(FeatureSearchBatch); err != nil {
+		return nil, err
+	}
+	if req.Window != "" || req.Since != "" || req.Until != "" {
+		if err := c.requireFeature(FeatureSearchTimeWindows); err != nil {
+			return nil, err
+		}
+	}
+	if req.Profile != "" {
+		if err := c.requireFeature(FeatureRankingProfiles); err != nil {
+			return nil, err
+		}
+	}
+	if len(req.Tags) > 0 {
+		if err := c.requireFeature(FeatureTagFilters); err != nil {
+			return nil, err
+		}
+	}
+	resp, err := api.SearchBatch(ctx, c.http, c.baseURL, req)
+	if err == nil && c.pending != nil {
+		for i := range resp.Results {
+			one := req.SearchRequest
+			one.Query = resp.Results[i].Query
+			c.pending.merge(one, &resp.Results[i].SearchResponse)
+		}
+	}
+	return resp, err
+}
+
+// SearchFeedback reports which results of a previous search were useful.
+// Requires the server's query log (SearchResponse.QueryID is empty otherwise).
+func (c *Client) SearchFeedback(ctx context.Context, req SearchFeedbackRequest) error {
+	return api.SubmitSearchFeedback(ctx, c.http, c.baseURL, req)
+}
+
+// ExplainSearch reports whether an entry would be returned for a query and
+// why: its rank, keyword term matches, vector similarity and filters.
+func (c *Client) ExplainSearch(ctx context.Context, req ExplainSearchRequest) (*SearchExplanation, error) {
+	if err := c.requireFeature(FeatureSearchExplain); err != nil {
+		return nil, err
+	}
+	return api.ExplainSearch(ctx, c.http, c.baseURL, req)
+}
+
+// SearchMetrics returns precision aggregated from search feedback.
+// memoryID and since are optional filters ("" and nil disable them).
+func (c *Client) SearchMetrics(ctx context.Context, memoryID string, since *time.Time) (*SearchMetrics, error) {
+	return api.GetSearchMetrics(ctx, c.http, c.baseURL, memoryID, since)
+}
+
+// GetUsage returns the language model tokens and cost reported with the
+// actor's entries (AddEntryRequest.Usage), in total, per memory and per
+// model. memoryID and since are optional filters ("" and nil disable them).
+func (c *Client) GetUsage(ctx context.Context, memoryID string, since *time.Time) (*UsageReport, error) {
+	if err := c.requireFeature(FeatureEntryUsage); err != nil {
+		return nil, err
+	}
+	return api.GetUsage(ctx, c.http, c.baseURL, memoryID, since)
+}
+
+// ExportSearchLog returns logged searches as a TREC run, qrels or topics
+// file, so retrieval quality can be scored with standard IR tooling such as
+// trec_eval. Requires the server's query log.
+func (c *Client) ExportSearchLog(ctx context.Context, req ExportSearchLogRequest) ([]byte, error) {
+	return api.ExportSearchLog(ctx, c.http, c.baseURL, req)
+}
+
+// --------------------------------------------------------------------
+// Ingestion batches - provenance registry with atomic rollback
+// --------------------------------------------------------------------
+
+// CreateIngestionBatch registers a batch; pass its BatchID as
+// AddEntryRequest.IngestionBatchID for every entry the import writes.
+func (c *Client) CreateIngestionBatch(ctx context.Context, req CreateIngestionBatchRequest) (*IngestionBatch, error) {
+	return api.CreateIngestionBatch(ctx, c.http, c.baseURL, req)
+}
+
+// ListIngestionBatches returns the caller's ingestion batches, newest first.
+func (c *Client) ListIngestionBatches(ctx context.Context) ([]IngestionBatch, error) {
+	return api.ListIngestionBatches(ctx, c.http, c.baseURL)
+}
+
+// GetIngestionBatch retrieves a batch with its current entry count.
+func (c *Client) GetIngestionBatch(ctx context.Context, batchID string) (*IngestionBatch, error) {
+	return api.GetIngestionBatch(ctx, c.http, c.baseURL, batchID)
+}
+
+// ListIngestionBatchEntries lists entries written by a batch (limit <= 0 means all).
+func (c *Client) ListIngestionBatchEntries(ctx context.Context, batchID string, limit int) (*ListEntriesResponse, error) {
+	return api.ListIngestionBatchEntries(ctx, c.http, c.baseURL, batchID, limit)
+}
+
+// RollbackIngestionBatch deletes every entry written by the batch in one
+// transaction and closes the batch to further writes.
+func (c *Client) RollbackIngestionBatch(ctx context.Context, batchID string) (*RollbackIngestionBatchResponse, error) {
+	return api.RollbackIngestionBatch(ctx, c.http, c.baseURL, batchID)
 }
 
 // --------------------------------------------------------------------
@@ -265,14 +753,72 @@ func (c *Client) Search(ctx context.Context, req SearchRequest) (*SearchResponse
 // AddEntry submits a new entry to a memory via the sharded executor.
 // This ensures FIFO ordering per memory and provides offline resilience.
 // CRITICAL: This MUST preserve the async executor pattern!
+// With WithSyncWrites it waits for the write as AddEntrySync does; with
+// WithDurableQueue it is journaled first.
+// Metadata naming a registered type is validated first (see WithMetadataType).
 func (c *Client) AddEntry(ctx context.Context, vaultID, memID string, req AddEntryRequest) (*EnqueueAck, error) {
+	if err := c.validateMetadata(req.Metadata); err != nil {
+		return nil, err
+	}
+	if c.syncWrites {
+		created, err := c.AddEntrySync(ctx, vaultID, memID, req)
+		if err != nil {
+			return nil, err
+		}
+		return &EnqueueAck{MemoryID: memID, Status: "created", Entry: created}, nil
+	}
+	if c.journal != nil {
+		return c.addEntryJournaled(ctx, vaultID, memID, req)
+	}
+	if c.pending != nil {
+		return c.addEntryTracked(ctx, vaultID, memID, req)
+	}
 	// CRITICAL: Pass the executor for async operation
 	return api.AddEntry(ctx, c.exec, c.http, c.baseURL, vaultID, memID, req)
 }
 
+// AddEntries writes entries to a memory in one server-side transaction, in
+// order, after pending writes to the memory complete (synchronous). It suits
+// backfills of past conversations: set each entry's ConversationTime to when
+// it was said. Either every entry is stored or none is. Requires
+// FeatureEntriesBatch; the server accepts at most 500 entries per call.
+func (c *Client) AddEntries(ctx context.Context, vaultID, memID string, entries []AddEntryRequest) (*AddEntriesResponse, error) {
+	if err := c.requireFeature(FeatureEntriesBatch); err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		if err := c.validateMetadata(entries[i].Metadata); err != nil {
+			return nil, fmt.Errorf("entries[%d]: %w", i, err)
+		}
+	}
+	return api.AddEntries(ctx, c.exec, c.http, c.baseURL, vaultID, memID, entries)
+}
+
 // ListEntries retrieves entries within a memory using the full prefix (synchronous).
+// params are query parameters such as "limit", "sessionId" and "orderBy";
+// "orderBy": "conversationTime" (FeatureConversationTime) sorts by when the
+// conversation took place instead of when the entry was written.
+// A full page carries NextPageToken; passing it back as "pageToken" returns
+// the following page, so a memory can be walked past the page limit
+// (FeatureEntriesPagination, creation time order only).
 func (c *Client) ListEntries(ctx context.Context, vaultID, memID string, params map[string]string) (*ListEntriesResponse, error) {
-	return api.ListEntries(ctx, c.http, c.baseURL, vaultID, memID, params)
+	return api.ListEntries(ctx, c.http, c.baseURL, vaultID, memID, params, nil)
+}
+
+// ListTaggedEntries is ListEntries restricted to entries whose tags hold
+// every key in tags with exactly its value. Requires FeatureTagFilters.
+func (c *Client) ListTaggedEntries(ctx context.Context, vaultID, memID string, tags, params map[string]string) (*ListEntriesResponse, error) {
+	if err := c.requireFeature(FeatureTagFilters); err != nil {
+		return nil, err
+	}
+	return api.ListEntries(ctx, c.http, c.baseURL, vaultID, memID, params, tags)
+}
+
+// ExportEntries returns every entry of a memory, oldest first (synchronous).
+// withEmbeddings adds each indexed entry's stored vector, model and dimension
+// so the export can be loaded into another vector store without re-embedding.
+func (c *Client) ExportEntries(ctx context.Context, vaultID, memID string, withEmbeddings bool) ([]ExportedEntry, error) {
+	return api.ExportEntries(ctx, c.http, c.baseURL, vaultID, memID, withEmbeddings)
 }
 
 // GetEntry retrieves a single entry by entryId within a memory (synchronous).
@@ -280,32 +826,209 @@ func (c *Client) GetEntry(ctx context.Context, vaultID, memID, entryID string) (
 	return api.GetEntry(ctx, c.http, c.baseURL, vaultID, memID, entryID)
 }
 
+// ScanEntries finds entries whose text contains a substring or matches a
+// regular expression, newest first, one page per call (synchronous). It scans
+// the database rather than the search index, so it suits exact strings such
+// as IDs and error codes that hybrid search ranks poorly.
+func (c *Client) ScanEntries(ctx context.Context, vaultID, memID string, req ScanEntriesRequest) (*ScanEntriesResponse, error) {
+	if err := c.requireFeature(FeatureEntriesScan); err != nil {
+		return nil, err
+	}
+	return api.ScanEntries(ctx, c.http, c.baseURL, vaultID, memID, req)
+}
+
+// ListSessions summarises the memory's conversation sessions, oldest first.
+func (c *Client) ListSessions(ctx context.Context, vaultID, memID string) (*ListSessionsResponse, error) {
+	return api.ListSessions(ctx, c.http, c.baseURL, vaultID, memID)
+}
+
+// ListSessionEntries returns a session's entries oldest first, i.e. in
+// conversation order. limit <= 0 uses the server default.
+func (c *Client) ListSessionEntries(ctx context.Context, vaultID, memID, sessionID string, limit int) (*ListEntriesResponse, error) {
+	return api.ListSessionEntries(ctx, c.http, c.baseURL, vaultID, memID, sessionID, limit)
+}
+
+// RecordEntrySignal records a quality signal (SignalUseful, SignalIncorrect,
+// SignalOutdated) against an entry. The server can use these to boost or
+// demote the entry in search ranking.
+func (c *Client) RecordEntrySignal(ctx context.Context, vaultID, memID, entryID, signal string) (*Entry, error) {
+	return api.RecordEntrySignal(ctx, c.http, c.baseURL, vaultID, memID, entryID, signal)
+}
+
+// SimilarEntries lists up to topK entries most similar to entryID in its
+// memory, or across its vault with scope "vault" (zero topK and empty scope
+// use the server defaults: 10 entries, memory scope). Requires
+// FeatureSimilarEntries.
+func (c *Client) SimilarEntries(ctx context.Context, vaultID, memID, entryID, scope string, topK int) (*SimilarEntries, error) {
+	if err := c.requireFeature(FeatureSimilarEntries); err != nil {
+		return nil, err
+	}
+	return api.SimilarEntries(ctx, c.http, c.baseURL, vaultID, memID, entryID, scope, topK)
+}
+
+// PatchEntryTags sets and unsets tag keys on every entry of the memory that
+// req selects, in one server-side transaction, after pending writes to the
+// memory complete. Requires FeatureBulkTagUpdates.
+func (c *Client) PatchEntryTags(ctx context.Context, vaultID, memID string, req PatchEntryTagsRequest) (*PatchEntryTagsResponse, error) {
+	if err := c.requireFeature(FeatureBulkTagUpdates); err != nil {
+		return nil, err
+	}
+	return api.PatchEntryTags(ctx, c.exec, c.http, c.baseURL, vaultID, memID, req)
+}
+
 // DeleteEntry removes an entry by ID from a memory synchronously via HTTP.
 // It first awaits consistency to ensure all pending writes complete, then performs the deletion.
 func (c *Client) DeleteEntry(ctx context.Context, vaultID, memID, entryID string) error {
 	return api.DeleteEntry(ctx, c.exec, c.http, c.baseURL, vaultID, memID, entryID)
 }
 
+// RestoreEntry takes an entry deleted on a server with FeatureTrash out of
+// the trash. Requires FeatureTrash.
+func (c *Client) RestoreEntry(ctx context.Context, vaultID, memID, entryID string) (*Entry, error) {
+	if err := c.requireFeature(FeatureTrash); err != nil {
+		return nil, err
+	}
+	return api.RestoreEntry(ctx, c.http, c.baseURL, vaultID, memID, entryID)
+}
+
+// CorrectEntry writes req as a new entry of the memory that corrects
+// entryID; searches then return the correction in its place. It fails with
+// a conflict if entryID was already corrected: correct its correction
+// instead. Requires FeatureEntryCorrections.
+func (c *Client) CorrectEntry(ctx context.Context, vaultID, memID, entryID string, req CorrectEntryRequest) (*Entry, error) {
+	if err := c.requireFeature(FeatureEntryCorrections); err != nil {
+		return nil, err
+	}
+	return api.CorrectEntry(ctx, c.http, c.baseURL, vaultID, memID, entryID, req)
+}
+
 // --------------------------------------------------------------------
 // Context operations - delegated to internal/api (CRITICAL: mixed sync/async)
 // --------------------------------------------------------------------
 
 // PutContext stores the plain-text context document via the sharded executor.
+// With WithContextCoalescing the write is held briefly, and the ack's Status
+// is "coalesced" when it replaced a document still held for the memory.
+// With WithDurableQueue the write is journaled first.
 func (c *Client) PutContext(ctx context.Context, vaultID, memID string, doc string) (*EnqueueAck, error) {
+	if c.coalescer != nil {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return c.putContextCoalesced(vaultID, memID, doc)
+	}
+	if c.journal != nil {
+		return c.putContextJournaled(ctx, c.exec, vaultID, memID, doc)
+	}
 	return api.PutContext(ctx, c.exec, c.http, c.baseURL, vaultID, memID, doc)
 }
 
+// PutContextChecked stores the plain-text context like PutContext, but
+// synchronously and with a server-side check listing recent entries the
+// document does not reflect, so facts dropped in a rewrite can be restored.
+// checkEntries <= 0 checks the server's default number of entries. Requires
+// FeatureContextCheck.
+func (c *Client) PutContextChecked(ctx context.Context, vaultID, memID, doc string, checkEntries int) (*CheckedContext, error) {
+	if err := c.requireFeature(FeatureContextCheck); err != nil {
+		return nil, err
+	}
+	return api.PutContextChecked(ctx, c.exec, c.http, c.baseURL, vaultID, memID, doc, checkEntries)
+}
+
 // GetLatestContext fetches the latest context document as plain text.
 func (c *Client) GetLatestContext(ctx context.Context, vaultID, memID string) (string, error) {
 	return api.GetLatestContext(ctx, c.http, c.baseURL, vaultID, memID)
 }
 
+// GetLatestContextIfChanged is a conditional GetLatestContext for agents that
+// poll every turn: pass the ETag from the previous result and, if the context
+// is unchanged, the server skips the body and NotModified is set.
+func (c *Client) GetLatestContextIfChanged(ctx context.Context, vaultID, memID, etag string) (*ContextFetch, error) {
+	return api.GetLatestContextIfChanged(ctx, c.http, c.baseURL, vaultID, memID, etag)
+}
+
+// PutContextSections replaces the context with named sections, recording
+// opts.UpdatedBy on each section whose content changed. Unlike PutContext it
+// is synchronous, after the memory's queued writes.
+func (c *Client) PutContextSections(ctx context.Context, vaultID, memID string, sections []ContextSection, opts ContextSectionsOptions) (*StructuredContext, error) {
+	if err := c.requireFeature(FeatureContextSections); err != nil {
+		return nil, err
+	}
+	return api.PutContextSections(ctx, c.exec, c.http, c.baseURL, vaultID, memID, sections, opts)
+}
+
+// PutContextSection sets one section of the structured context, appending it
+// when new, and leaves the other sections and their provenance unchanged.
+// The server answers 409 when the latest context is plain text.
+func (c *Client) PutContextSection(ctx context.Context, vaultID, memID, name, content string, opts ContextSectionsOptions) (*StructuredContext, error) {
+	if err := c.requireFeature(FeatureContextSections); err != nil {
+		return nil, err
+	}
+	return api.PutContextSection(ctx, c.exec, c.http, c.baseURL, vaultID, memID, name, content, opts)
+}
+
+// GetStructuredContext fetches the latest context with its sections. Pass its
+// ContextID as opts.IfMatch to make a following section write conditional.
+func (c *Client) GetStructuredContext(ctx context.Context, vaultID, memID string) (*StructuredContext, error) {
+	if err := c.requireFeature(FeatureContextSections); err != nil {
+		return nil, err
+	}
+	return api.GetStructuredContext(ctx, c.http, c.baseURL, vaultID, memID)
+}
+
+// GetContextWithRecentSummaries fetches the latest context and the summaries
+// of the memory's n newest entries (1 to 50) in one call, for reading at
+// session start. Requires FeatureRecentSummaries.
+func (c *Client) GetContextWithRecentSummaries(ctx context.Context, vaultID, memID string, n int) (*StructuredContext, error) {
+	if err := c.requireFeature(FeatureRecentSummaries); err != nil {
+		return nil, err
+	}
+	return api.GetContextWithRecentSummaries(ctx, c.http, c.baseURL, vaultID, memID, n)
+}
+
 // DeleteContext removes a context snapshot by ID synchronously via HTTP.
 // It first awaits consistency to ensure all pending writes complete, then performs the deletion.
 func (c *Client) DeleteContext(ctx context.Context, vaultID, memID, contextID string) error {
 	return api.DeleteContext(ctx, c.exec, c.http, c.baseURL, vaultID, memID, contextID)
 }
 
+// --------------------------------------------------------------------
+// Actor settings - delegated to internal/api
+// --------------------------------------------------------------------
+
+// GetActorSettings returns the caller's settings, including the default time zone.
+func (c *Client) GetActorSettings(ctx context.Context) (*ActorSettings, error) {
+	return api.GetActorSettings(ctx, c.http, c.baseURL)
+}
+
+// SetActorTimeZone sets the caller's default IANA time zone. Entry
+// timestamps are returned in this zone and date filters such as
+// after=yesterday resolve against it unless a request passes tz explicitly.
+func (c *Client) SetActorTimeZone(ctx context.Context, timeZone string) (*ActorSettings, error) {
+	return api.SetActorTimeZone(ctx, c.http, c.baseURL, timeZone)
+}
+
+// SetActorDefaults sets the caller's default vault and memory. The server
+// then accepts /v0/entries, /v0/contexts and searches without a memoryId,
+// which suits agents that only ever write to one memory. Empty IDs clear
+// the defaults; a memory requires its vault.
+func (c *Client) SetActorDefaults(ctx context.Context, vaultID, memoryID string) (*ActorSettings, error) {
+	if err := c.requireFeature(FeatureActorDefaults); err != nil {
+		return nil, err
+	}
+	return api.SetActorDefaults(ctx, c.http, c.baseURL, vaultID, memoryID)
+}
+
+// --------------------------------------------------------------------
+// Health - delegated to internal/api
+// --------------------------------------------------------------------
+
+// Health returns the service's structured health report (overall status,
+// per-dependency status, schema version and server time).
+func (c *Client) Health(ctx context.Context) (*HealthResponse, error) {
+	return api.GetHealth(ctx, c.http, c.baseURL)
+}
+
 // --------------------------------------------------------------------
 // Prompts operations - embedded (sync-only, no network)
 // --------------------------------------------------------------------
@@ -318,3 +1041,12 @@ func (c *Client) LoadDefaultPrompts(ctx context.Context, memoryType string) (*De
 	}
 	return promptsinternal.LoadDefaultPrompts(memoryType)
 }
+
+// RenderDefaultPrompts is LoadDefaultPrompts with the prompt variables
+// ({{.MemoryTitle}}, {{.Today}}, {{.ActorTimeZone}}, ...) filled from vars.
+func (c *Client) RenderDefaultPrompts(ctx context.Context, memoryType string, vars PromptVars) (*DefaultPromptResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return promptsinternal.RenderDefaultPrompts(memoryType, vars)
+}
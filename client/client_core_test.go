@@ -8,11 +8,27 @@ import (
 	"github.com/mycelian/mycelian-memory/client/internal/shardqueue"
 )
 
-type stubExec struct{ stops int }
+type stubExec struct {
+	stops    int
+	report   shardqueue.Report
+	err      error
+	barriers []string
+}
 
 func (s *stubExec) Submit(context.Context, string, shardqueue.Job) error { return nil }
-func (s *stubExec) Barrier(context.Context, string) error                { return nil }
-func (s *stubExec) Stop()                                                { s.stops++ }
+func (s *stubExec) Barrier(_ context.Context, key string) error {
+	s.barriers = append(s.barriers, key)
+	if r, ok := s.report[key]; ok {
+		r.Flushed += r.Pending()
+		s.report[key] = r
+	}
+	return nil
+}
+func (s *stubExec) Report() shardqueue.Report { return s.report }
+func (s *stubExec) Shutdown(context.Context) (shardqueue.Report, error) {
+	s.stops++
+	return s.report, s.err
+}
 
 func TestIsBackPressure(t *testing.T) {
 	if !IsBackPressure(ErrBackPressure) {
@@ -37,8 +53,48 @@ func TestCloseIdempotent(t *testing.T) {
 	}
 }
 
+func TestShutdownReturnsReport(t *testing.T) {
+	s := &stubExec{
+		report: shardqueue.Report{"m1": {Submitted: 2, Flushed: 1, Dropped: 1}},
+		err:    context.DeadlineExceeded,
+	}
+	c := &Client{exec: s}
+	rep, err := c.Shutdown(context.Background())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline error, got %v", err)
+	}
+	if rep.Complete() || rep["m1"].Dropped != 1 {
+		t.Fatalf("unexpected report: %+v", rep)
+	}
+	// Close after Shutdown does not stop the executor again.
+	if err := c.Close(); err != nil || s.stops != 1 {
+		t.Fatalf("close after shutdown: err=%v stops=%d", err, s.stops)
+	}
+}
+
+func TestFlushWaitsForPendingMemories(t *testing.T) {
+	s := &stubExec{report: shardqueue.Report{
+		"m1": {Submitted: 3, Flushed: 1},
+		"m2": {Submitted: 1, Flushed: 1},
+	}}
+	c := &Client{exec: s}
+	rep, err := c.Flush(context.Background())
+	if err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	if len(s.barriers) != 1 || s.barriers[0] != "m1" {
+		t.Fatalf("expected a barrier on m1 only, got %v", s.barriers)
+	}
+	if len(rep) != 1 || !rep.Complete() || rep["m1"].Flushed != 3 {
+		t.Fatalf("unexpected report: %+v", rep)
+	}
+	if s.stops != 0 {
+		t.Fatalf("flush must not stop the executor")
+	}
+}
+
 func TestNew(t *testing.T) {
-	c, err := New("http://example.com", "test-api-key")
+	c, err := New("http://example.com", WithAPIKey("test-api-key"))
 	if err != nil || c == nil {
 		t.Fatalf("expected client, got err=%v", err)
 	}
@@ -0,0 +1,44 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPutContextChecked(t *testing.T) {
+	var query, body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/v0/vaults/v1/memories/m1/contexts" {
+			t.Fatalf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+		query = r.URL.RawQuery
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"contextId":"c1","context":"Deadline moved to March.","check":{"entriesChecked":2,
+			"suggestions":[{"entryId":"e2","text":"Budget approved","coverage":0,"missingTerms":["approved","budget"],"reason":"dropped"}],
+			"warnings":["1 entries reflected in the previous context are missing from this one"]}}`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, WithAPIKey("k"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer func() { _ = c.Close() }()
+
+	out, err := c.PutContextChecked(context.Background(), "v1", "m1", "Deadline moved to March.", 20)
+	if err != nil {
+		t.Fatalf("PutContextChecked: %v", err)
+	}
+	if query != "check=true&checkEntries=20" || body != "Deadline moved to March." {
+		t.Fatalf("unexpected request: query=%s body=%s", query, body)
+	}
+	if out.ContextID != "c1" || out.Check.EntriesChecked != 2 || len(out.Check.Suggestions) != 1 ||
+		out.Check.Suggestions[0].Reason != "dropped" || len(out.Check.Warnings) != 1 {
+		t.Fatalf("unexpected result: %+v", out)
+	}
+}
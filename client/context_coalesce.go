@@ -0,0 +1,166 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mycelian/mycelian-memory/client/internal/api"
+	"github.com/mycelian/mycelian-memory/client/internal/shardqueue"
+	"github.com/rs/zerolog/log"
+)
+
+// Agents often rewrite their context several times within one turn. With
+// WithContextCoalescing, PutContext calls for the same memory that arrive
+// within a window are merged and only the last document is written.
+//
+// Ordering is preserved: a held context write is submitted before any other
+// job for its memory (AddEntry, DeleteEntry, AwaitConsistency, Flush, ...),
+// so it keeps the place of the last PutContext it absorbed in the memory's
+// FIFO queue. Close and Shutdown submit held writes before draining.
+
+// WithContextCoalescing holds each PutContext for up to window and replaces
+// it with any later PutContext for the same memory issued meanwhile. The
+// window starts at the first held call, so a write is never delayed longer.
+func WithContextCoalescing(window time.Duration) Option {
+	return func(c *Client) error {
+		if window <= 0 {
+			return fmt.Errorf("context coalescing window must be > 0")
+		}
+		c.coalescer = &contextCoalescer{window: window, held: map[string]*heldContext{}}
+		return nil
+	}
+}
+
+// contextCoalescer wraps the client's executor, holding context writes until
+// their window ends or another job for the same memory is submitted.
+type contextCoalescer struct {
+	executor
+	window time.Duration
+	// put submits one context write to the wrapped executor.
+	put func(ctx context.Context, exec executor, vaultID, memID, doc string) error
+
+	// submitMu serialises submits to the wrapped executor, which must not
+	// see concurrent Submit calls for one memory (timer flushes race with
+	// the caller's own submits otherwise).
+	submitMu sync.Mutex
+
+	mu     sync.Mutex
+	held   map[string]*heldContext
+	closed bool
+}
+
+type heldContext struct {
+	vaultID string
+	doc     string
+	timer   *time.Timer
+}
+
+// hold records doc as the memory's pending context. coalesced is true when
+// it replaced a document held earlier.
+func (cc *contextCoalescer) hold(vaultID, memID, doc string) (coalesced bool, err error) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	if cc.closed {
+		return false, shardqueue.ErrExecutorClosed
+	}
+	if h, ok := cc.held[memID]; ok {
+		h.vaultID, h.doc = vaultID, doc
+		return true, nil
+	}
+	cc.held[memID] = &heldContext{vaultID: vaultID, doc: doc, timer: time.AfterFunc(cc.window, func() {
+		if err := cc.flush(context.Background(), memID); err != nil {
+			log.Error().Err(err).Str("memoryId", memID).Msg("coalesced context write could not be submitted")
+		}
+	})}
+	return false, nil
+}
+
+// flush submits the memory's held context, if any.
+func (cc *contextCoalescer) flush(ctx context.Context, memID string) error {
+	cc.submitMu.Lock()
+	defer cc.submitMu.Unlock()
+	return cc.flushLocked(ctx, memID)
+}
+
+func (cc *contextCoalescer) flushLocked(ctx context.Context, memID string) error {
+	cc.mu.Lock()
+	h, ok := cc.held[memID]
+	delete(cc.held, memID)
+	cc.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	h.timer.Stop()
+	return cc.put(ctx, cc.executor, h.vaultID, memID, h.doc)
+}
+
+// flushAll submits every held context; it stops at the first failure.
+func (cc *contextCoalescer) flushAll(ctx context.Context) error {
+	cc.mu.Lock()
+	memIDs := make([]string, 0, len(cc.held))
+	for memID := range cc.held {
+		memIDs = append(memIDs, memID)
+	}
+	cc.mu.Unlock()
+	for _, memID := range memIDs {
+		if err := cc.flush(ctx, memID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cc *contextCoalescer) Submit(ctx context.Context, memID string, job shardqueue.Job) error {
+	cc.submitMu.Lock()
+	defer cc.submitMu.Unlock()
+	if err := cc.flushLocked(ctx, memID); err != nil {
+		return err
+	}
+	return cc.executor.Submit(ctx, memID, job)
+}
+
+func (cc *contextCoalescer) Barrier(ctx context.Context, memID string) error {
+	if err := cc.flush(ctx, memID); err != nil {
+		return err
+	}
+	return cc.executor.Barrier(ctx, memID)
+}
+
+// Shutdown refuses further context writes, submits the held ones and then
+// shuts the wrapped executor down.
+func (cc *contextCoalescer) Shutdown(ctx context.Context) (shardqueue.Report, error) {
+	cc.mu.Lock()
+	cc.closed = true
+	cc.mu.Unlock()
+	if err := cc.flushAll(ctx); err != nil {
+		log.Error().Err(err).Msg("coalesced context writes could not be submitted before shutdown")
+	}
+	return cc.executor.Shutdown(ctx)
+}
+
+func (c *Client) putContextCoalesced(vaultID, memID, doc string) (*EnqueueAck, error) {
+	coalesced, err := c.coalescer.hold(vaultID, memID, doc)
+	if err != nil {
+		return nil, err
+	}
+	if coalesced {
+		return &EnqueueAck{MemoryID: memID, Status: "coalesced"}, nil
+	}
+	return &EnqueueAck{MemoryID: memID, Status: "enqueued"}, nil
+}
+
+// installCoalescer wraps the executor once New has settled on it.
+func (c *Client) installCoalescer() {
+	c.coalescer.executor = c.exec
+	c.coalescer.put = func(ctx context.Context, exec executor, vaultID, memID, doc string) error {
+		if c.journal != nil {
+			_, err := c.putContextJournaled(ctx, exec, vaultID, memID, doc)
+			return err
+		}
+		_, err := api.PutContext(ctx, exec, c.http, c.baseURL, vaultID, memID, doc)
+		return err
+	}
+	c.exec = c.coalescer
+}
@@ -0,0 +1,123 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// writeLog records the writes a test server receives, in order.
+type writeLog struct {
+	mu     sync.Mutex
+	writes []string
+}
+
+func (l *writeLog) add(s string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.writes = append(l.writes, s)
+}
+
+func (l *writeLog) get() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.writes...)
+}
+
+func newCoalescingClient(t *testing.T, window time.Duration) (*Client, *writeLog) {
+	t.Helper()
+	log := &writeLog{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		switch r.Method {
+		case http.MethodPut:
+			log.add("context:" + string(body))
+			w.WriteHeader(http.StatusCreated)
+		case http.MethodPost:
+			log.add("entry")
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"entryId":"e1"}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	c, err := New(srv.URL, WithAPIKey("k"), WithContextCoalescing(window))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	return c, log
+}
+
+func TestContextCoalescingKeepsLastWrite(t *testing.T) {
+	c, log := newCoalescingClient(t, time.Hour)
+	ctx := context.Background()
+
+	var statuses []string
+	for _, doc := range []string{"a", "b", "c"} {
+		ack, err := c.PutContext(ctx, "v1", "m1", doc)
+		if err != nil {
+			t.Fatalf("PutContext(%s): %v", doc, err)
+		}
+		statuses = append(statuses, ack.Status)
+	}
+	if want := []string{"enqueued", "coalesced", "coalesced"}; !reflect.DeepEqual(statuses, want) {
+		t.Fatalf("ack statuses = %v, want %v", statuses, want)
+	}
+	if err := c.AwaitConsistency(ctx, "m1"); err != nil {
+		t.Fatalf("AwaitConsistency: %v", err)
+	}
+	if got := log.get(); !reflect.DeepEqual(got, []string{"context:c"}) {
+		t.Fatalf("writes = %v", got)
+	}
+}
+
+func TestContextCoalescingPreservesOrder(t *testing.T) {
+	c, log := newCoalescingClient(t, time.Hour)
+	ctx := context.Background()
+
+	_, _ = c.PutContext(ctx, "v1", "m1", "a")
+	_, _ = c.PutContext(ctx, "v1", "m1", "b")
+	if _, err := c.AddEntry(ctx, "v1", "m1", AddEntryRequest{RawEntry: "x", Summary: "x"}); err != nil {
+		t.Fatalf("AddEntry: %v", err)
+	}
+	_, _ = c.PutContext(ctx, "v1", "m1", "c")
+	if _, err := c.Flush(ctx); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if got, want := log.get(), []string{"context:b", "entry", "context:c"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("writes = %v, want %v", got, want)
+	}
+}
+
+func TestContextCoalescingFlushesAfterWindow(t *testing.T) {
+	c, log := newCoalescingClient(t, 20*time.Millisecond)
+	if _, err := c.PutContext(context.Background(), "v1", "m1", "a"); err != nil {
+		t.Fatalf("PutContext: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for len(log.get()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := log.get(); !reflect.DeepEqual(got, []string{"context:a"}) {
+		t.Fatalf("writes = %v", got)
+	}
+}
+
+func TestContextCoalescingCloseSubmitsHeldWrites(t *testing.T) {
+	c, log := newCoalescingClient(t, time.Hour)
+	_, _ = c.PutContext(context.Background(), "v1", "m1", "a")
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if got := log.get(); !reflect.DeepEqual(got, []string{"context:a"}) {
+		t.Fatalf("writes = %v", got)
+	}
+	if _, err := c.PutContext(context.Background(), "v1", "m1", "b"); err == nil {
+		t.Fatal("PutContext after Close should fail")
+	}
+}
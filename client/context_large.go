@@ -0,0 +1,121 @@
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mycelian/mycelian-memory/client/internal/api"
+	"github.com/rs/zerolog/log"
+)
+
+// The server rejects active contexts over its MAX_CONTEXT_CHARS limit with
+// 413. PutContextLarge instead stores the full text as a context document,
+// uploaded gzip-compressed in parts sized to the server's limits, and puts an
+// abridged active context that names the document so an agent can fetch the
+// rest with GetContextDocument.
+
+// abridgedMarker separates the kept head and tail in AbridgeContext.
+const abridgedMarker = "\n[... abridged ...]\n"
+
+// AbridgeContext is the default abridger of PutContextLarge. It keeps the
+// first two thirds of the budget from the start of doc and the rest from the
+// end, cut at line boundaries where possible, so the result has at most
+// maxChars characters.
+func AbridgeContext(doc string, maxChars int) string {
+	runes := []rune(doc)
+	if len(runes) <= maxChars {
+		return doc
+	}
+	budget := maxChars - utf8.RuneCountInString(abridgedMarker)
+	if budget <= 0 {
+		return string(runes[:max(maxChars, 0)])
+	}
+	headN := budget * 2 / 3
+	head := string(runes[:headN])
+	if i := strings.LastIndexByte(head, '\n'); i >= len(head)/2 {
+		head = head[:i+1]
+	}
+	tail := string(runes[len(runes)-(budget-headN):])
+	if i := strings.IndexByte(tail, '\n'); i >= 0 && i < len(tail)/2 {
+		tail = tail[i+1:]
+	}
+	return head + abridgedMarker + tail
+}
+
+// PutContextLarge stores doc as the memory's context even when it exceeds the
+// server's context size limit. The full text is always uploaded as a context
+// document; the enqueued active context is doc itself when it fits, otherwise
+// a version produced by abridge (AbridgeContext when nil) under a header that
+// names the document. The upload is synchronous; the active context is put
+// through the executor like PutContext.
+func (c *Client) PutContextLarge(ctx context.Context, vaultID, memID, doc string, abridge func(doc string, maxChars int) string) (*PutContextLargeResult, error) {
+	if err := c.requireFeature(FeatureContextDocuments); err != nil {
+		return nil, err
+	}
+	if abridge == nil {
+		abridge = AbridgeContext
+	}
+	d, err := api.CreateContextDocument(ctx, c.http, c.baseURL, vaultID, memID)
+	if err != nil {
+		return nil, err
+	}
+	if d.Limits == nil || d.Limits.MaxPartBytes <= 0 {
+		return nil, fmt.Errorf("server did not report context document limits")
+	}
+	limits := *d.Limits
+	if limits.MaxDocumentBytes > 0 && int64(len(doc)) > limits.MaxDocumentBytes {
+		return nil, fmt.Errorf("context of %d bytes exceeds the server's %d byte document limit", len(doc), limits.MaxDocumentBytes)
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(doc)); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	data := buf.Bytes()
+	for n := 0; len(data) > 0; n++ {
+		part := data[:min(int64(len(data)), limits.MaxPartBytes)]
+		if err := api.PutContextDocumentPart(ctx, c.http, c.baseURL, vaultID, memID, d.DocumentID, n, part); err != nil {
+			return nil, err
+		}
+		data = data[len(part):]
+	}
+	done, err := api.CompleteContextDocument(ctx, c.http, c.baseURL, vaultID, memID, d.DocumentID)
+	if err != nil {
+		return nil, err
+	}
+	done.Limits = &limits
+
+	active, abridged := doc, false
+	chars := utf8.RuneCountInString(doc)
+	if limits.MaxContextChars > 0 && chars > limits.MaxContextChars {
+		header := fmt.Sprintf("[Abridged context. The full %d-character version is context document %s; fetch it with GetContextDocument.]\n\n", chars, d.DocumentID)
+		budget := limits.MaxContextChars - utf8.RuneCountInString(header)
+		if budget <= 0 {
+			return nil, fmt.Errorf("server context limit of %d characters is too small for an abridged context", limits.MaxContextChars)
+		}
+		body := abridge(doc, budget)
+		if r := []rune(body); len(r) > budget {
+			body = string(r[:budget])
+		}
+		active, abridged = header+body, true
+		log.Debug().Str("memory_id", memID).Str("document_id", d.DocumentID).Int("chars", chars).Int("max_chars", limits.MaxContextChars).Msg("abridging large context")
+	}
+	ack, err := api.PutContext(ctx, c.exec, c.http, c.baseURL, vaultID, memID, active)
+	if err != nil {
+		return nil, err
+	}
+	return &PutContextLargeResult{Document: done, Abridged: abridged, Ack: ack}, nil
+}
+
+// GetContextDocument fetches the full text stored by PutContextLarge.
+func (c *Client) GetContextDocument(ctx context.Context, vaultID, memID, documentID string) (string, error) {
+	return api.GetContextDocument(ctx, c.http, c.baseURL, vaultID, memID, documentID)
+}
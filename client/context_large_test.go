@@ -0,0 +1,107 @@
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestPutContextLargeAbridgesOverLimit(t *testing.T) {
+	var mu sync.Mutex
+	var parts [][]byte
+	var active string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		base := "/v0/vaults/v1/memories/m1/contexts"
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == base+"/documents":
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"documentId":"d1","status":"uploading","limits":{"maxContextChars":300,"maxPartBytes":128,"maxDocumentBytes":1048576}}`))
+		case r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, base+"/documents/d1/parts/"):
+			b, _ := io.ReadAll(r.Body)
+			if len(b) > 128 || r.URL.Path != fmt.Sprintf("%s/documents/d1/parts/%d", base, len(parts)) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			parts = append(parts, b)
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodPost && r.URL.Path == base+"/documents/d1/complete":
+			_, _ = w.Write([]byte(`{"documentId":"d1","status":"complete"}`))
+		case r.Method == http.MethodPut && r.URL.Path == base:
+			b, _ := io.ReadAll(r.Body)
+			active = string(b)
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, WithAPIKey("k"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer func() { _ = c.Close() }()
+	ctx := context.Background()
+
+	var sb strings.Builder
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(&sb, "fact %d: value %d\n", i, i*i*31)
+	}
+	doc := sb.String()
+	res, err := c.PutContextLarge(ctx, "v1", "m1", doc, nil)
+	if err != nil {
+		t.Fatalf("PutContextLarge: %v", err)
+	}
+	if !res.Abridged || res.Document.DocumentID != "d1" || res.Document.Status != "complete" || res.Ack == nil {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if err := c.AwaitConsistency(ctx, "m1"); err != nil {
+		t.Fatalf("AwaitConsistency: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(parts) < 2 {
+		t.Fatalf("expected a multi-part upload, got %d parts", len(parts))
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(bytes.Join(parts, nil)))
+	if err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	if full, _ := io.ReadAll(zr); string(full) != doc {
+		t.Fatalf("uploaded document does not round-trip")
+	}
+	if n := utf8.RuneCountInString(active); n > 300 {
+		t.Fatalf("active context has %d characters, limit 300", n)
+	}
+	if !strings.Contains(active, "context document d1") || !strings.HasPrefix(strings.SplitN(active, "\n\n", 2)[1], "fact 0:") || !strings.HasSuffix(active, "\n") {
+		t.Fatalf("unexpected abridged context:\n%s", active)
+	}
+}
+
+func TestAbridgeContext(t *testing.T) {
+	if got := AbridgeContext("short", 10); got != "short" {
+		t.Fatalf("short doc changed: %q", got)
+	}
+	doc := strings.Repeat("äbcdefghi\n", 50)
+	got := AbridgeContext(doc, 100)
+	if n := utf8.RuneCountInString(got); n > 100 || !strings.Contains(got, abridgedMarker) {
+		t.Fatalf("abridged to %d chars: %q", n, got)
+	}
+	if !strings.HasPrefix(got, "äbcdefghi\n") || !strings.HasSuffix(got, "äbcdefghi\n") {
+		t.Fatalf("expected whole lines kept at both ends: %q", got)
+	}
+	if got := AbridgeContext(doc, 5); utf8.RuneCountInString(got) != 5 {
+		t.Fatalf("tiny budget: %q", got)
+	}
+}
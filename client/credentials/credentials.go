@@ -0,0 +1,352 @@
+// Package credentials stores Mycelian API keys for the CLI and the MCP server
+// under named profiles, so they need not sit in plaintext environment
+// variables or shell history.
+//
+// A profile's key goes to the OS keyring when one is available (macOS
+// Keychain through security(1), the Secret Service through secret-tool(1) on
+// Linux) and otherwise to an encrypted file. The profile list itself, with
+// service URLs and rotation times but no keys, is profiles.json in the
+// store's directory.
+package credentials
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultProfile is the profile used when none is named or set as default.
+const DefaultProfile = "default"
+
+// Environment variables read by Resolve.
+const (
+	EnvAPIKey  = "MYCELIAN_API_KEY"
+	EnvProfile = "MYCELIAN_PROFILE"
+	// EnvPassphrase, when set, encrypts the file fallback with a key derived
+	// from it instead of the random key stored next to the file.
+	EnvPassphrase = "MYCELIAN_CREDENTIALS_PASSPHRASE"
+	// EnvDir overrides the store's directory.
+	EnvDir = "MYCELIAN_CONFIG_DIR"
+	// EnvKeyring set to "file" keeps keys out of the OS keyring, for
+	// headless machines and tests.
+	EnvKeyring = "MYCELIAN_KEYRING"
+)
+
+// Backends a profile's key can be stored in.
+const (
+	BackendKeyring = "keyring"
+	BackendFile    = "file"
+)
+
+// ErrNotFound is returned for a profile that does not exist.
+var ErrNotFound = errors.New("credentials: profile not found")
+
+var profileNameRE = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// Profile is a named API key and the service it is for.
+type Profile struct {
+	Name       string `json:"name"`
+	ServiceURL string `json:"serviceUrl,omitempty"`
+	// APIKey is never written to profiles.json.
+	APIKey string `json:"-"`
+	// Backend is where the key is stored: BackendKeyring or BackendFile.
+	Backend   string    `json:"backend"`
+	CreatedAt time.Time `json:"createdAt"`
+	// RotatedAt is when the key was last replaced with Rotate.
+	RotatedAt *time.Time `json:"rotatedAt,omitempty"`
+}
+
+// index is the content of profiles.json.
+type index struct {
+	Default  string              `json:"default,omitempty"`
+	Profiles map[string]*Profile `json:"profiles"`
+}
+
+// Store keeps profiles in a directory, their keys in Keyring or the
+// encrypted file.
+type Store struct {
+	dir        string
+	keyring    Keyring
+	passphrase string
+	now        func() time.Time
+}
+
+// Option configures a Store.
+type Option func(*Store)
+
+// WithKeyring replaces the OS keyring; nil stores every key in the file.
+func WithKeyring(k Keyring) Option { return func(s *Store) { s.keyring = k } }
+
+// WithPassphrase encrypts the file fallback with a key derived from p.
+func WithPassphrase(p string) Option { return func(s *Store) { s.passphrase = p } }
+
+// DefaultDir is $MYCELIAN_CONFIG_DIR, or mycelian in the user's config
+// directory (~/.config/mycelian on Linux).
+func DefaultDir() (string, error) {
+	if dir := os.Getenv(EnvDir); dir != "" {
+		return dir, nil
+	}
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "mycelian"), nil
+}
+
+// Open returns the store in dir ("" for DefaultDir), using the OS keyring
+// when available and MYCELIAN_CREDENTIALS_PASSPHRASE for the file fallback.
+func Open(dir string, opts ...Option) (*Store, error) {
+	if dir == "" {
+		var err error
+		if dir, err = DefaultDir(); err != nil {
+			return nil, err
+		}
+	}
+	s := &Store{dir: dir, keyring: SystemKeyring(), passphrase: os.Getenv(EnvPassphrase), now: time.Now}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
+}
+
+// Dir is the store's directory.
+func (s *Store) Dir() string { return s.dir }
+
+// Save stores p, replacing a profile of the same name. The key goes to the
+// keyring when it accepts it and to the encrypted file otherwise. The first
+// profile saved becomes the default.
+func (s *Store) Save(p Profile) error {
+	if !profileNameRE.MatchString(p.Name) {
+		return fmt.Errorf("credentials: invalid profile name %q", p.Name)
+	}
+	if p.APIKey == "" {
+		return errors.New("credentials: API key is empty")
+	}
+	idx, err := s.readIndex()
+	if err != nil {
+		return err
+	}
+	old := idx.Profiles[p.Name]
+	if err := s.putKey(&p); err != nil {
+		return err
+	}
+	if old != nil && old.Backend != p.Backend {
+		_ = s.deleteKey(old)
+	}
+	if old != nil {
+		p.CreatedAt = old.CreatedAt
+	} else {
+		p.CreatedAt = s.now().UTC()
+	}
+	idx.Profiles[p.Name] = &p
+	if idx.Default == "" {
+		idx.Default = p.Name
+	}
+	return s.writeIndex(idx)
+}
+
+// Rotate replaces the key of an existing profile and records when.
+func (s *Store) Rotate(name, apiKey string) error {
+	p, err := s.Load(name)
+	if err != nil {
+		return err
+	}
+	p.APIKey = apiKey
+	now := s.now().UTC()
+	p.RotatedAt = &now
+	return s.Save(*p)
+}
+
+// Load returns the profile with its key; name "" is the default profile.
+func (s *Store) Load(name string) (*Profile, error) {
+	idx, err := s.readIndex()
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		name = idx.Default
+		if name == "" {
+			name = DefaultProfile
+		}
+	}
+	meta, ok := idx.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	p := *meta
+	if p.APIKey, err = s.getKey(&p); err != nil {
+		return nil, fmt.Errorf("credentials: read key of profile %s: %w", name, err)
+	}
+	return &p, nil
+}
+
+// List returns the profiles without their keys, sorted by name, and the
+// name of the default one.
+func (s *Store) List() ([]Profile, string, error) {
+	idx, err := s.readIndex()
+	if err != nil {
+		return nil, "", err
+	}
+	out := make([]Profile, 0, len(idx.Profiles))
+	for _, p := range idx.Profiles {
+		out = append(out, *p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, idx.Default, nil
+}
+
+// DefaultName returns the profile Load uses for name "": the one set with
+// SetDefault, or DefaultProfile.
+func (s *Store) DefaultName() (string, error) {
+	idx, err := s.readIndex()
+	if err != nil {
+		return "", err
+	}
+	if idx.Default != "" {
+		return idx.Default, nil
+	}
+	return DefaultProfile, nil
+}
+
+// ProfileName returns profile, or $MYCELIAN_PROFILE, or the store's
+// default profile, like Resolve picks them.
+func (s *Store) ProfileName(profile string) (string, error) {
+	if profile != "" {
+		return profile, nil
+	}
+	if env := os.Getenv(EnvProfile); env != "" {
+		return env, nil
+	}
+	return s.DefaultName()
+}
+
+// SetDefault makes name the profile Load and Resolve use when none is named.
+func (s *Store) SetDefault(name string) error {
+	idx, err := s.readIndex()
+	if err != nil {
+		return err
+	}
+	if _, ok := idx.Profiles[name]; !ok {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	idx.Default = name
+	return s.writeIndex(idx)
+}
+
+// Delete removes a profile and its key.
+func (s *Store) Delete(name string) error {
+	idx, err := s.readIndex()
+	if err != nil {
+		return err
+	}
+	p, ok := idx.Profiles[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	if err := s.deleteKey(p); err != nil {
+		return err
+	}
+	delete(idx.Profiles, name)
+	if idx.Default == name {
+		idx.Default = ""
+	}
+	return s.writeIndex(idx)
+}
+
+// Resolve returns the service URL and API key to use: $MYCELIAN_API_KEY when
+// set, otherwise the key of profile (or $MYCELIAN_PROFILE, or the default
+// profile). An empty key with a nil error means nothing is configured, and
+// callers try other credentials (see client.Credentials); a profile named
+// explicitly must exist.
+func Resolve(profile string) (serviceURL, apiKey string, err error) {
+	if key := os.Getenv(EnvAPIKey); key != "" {
+		return "", key, nil
+	}
+	named := profile != ""
+	if !named {
+		profile = os.Getenv(EnvProfile)
+		named = profile != ""
+	}
+	s, err := Open("")
+	if err != nil {
+		return "", "", err
+	}
+	p, err := s.Load(profile)
+	if errors.Is(err, ErrNotFound) && !named {
+		return "", "", nil
+	}
+	if err != nil {
+		return "", "", err
+	}
+	return p.ServiceURL, p.APIKey, nil
+}
+
+// CheckServiceURL refuses to send the key of a profile stored for
+// profileURL to serviceURL when they name different services, as when
+// --service-url or MEMORY_SERVICE_URL overrides the profile's URL. A
+// profile without a URL matches any service.
+func CheckServiceURL(profileURL, serviceURL string) error {
+	if profileURL == "" || strings.EqualFold(strings.TrimRight(profileURL, "/"), strings.TrimRight(serviceURL, "/")) {
+		return nil
+	}
+	return fmt.Errorf("credentials: the selected profile is for %s, not %s; select a profile for %s or set %s", profileURL, serviceURL, serviceURL, EnvAPIKey)
+}
+
+func (s *Store) indexPath() string { return filepath.Join(s.dir, "profiles.json") }
+
+func (s *Store) readIndex() (*index, error) {
+	idx := &index{Profiles: map[string]*Profile{}}
+	b, err := os.ReadFile(s.indexPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return idx, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, idx); err != nil {
+		return nil, fmt.Errorf("credentials: %s: %w", s.indexPath(), err)
+	}
+	if idx.Profiles == nil {
+		idx.Profiles = map[string]*Profile{}
+	}
+	return idx, nil
+}
+
+func (s *Store) writeIndex(idx *index) error {
+	b, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.indexPath(), b)
+}
+
+// writeFileAtomic writes b to path, readable by the owner only, through a
+// rename so readers never see a partial file.
+func writeFileAtomic(path string, b []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), ".tmp-"+filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(f.Name()) }()
+	if err := f.Chmod(0o600); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
@@ -0,0 +1,195 @@
+package credentials
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type fakeKeyring struct {
+	items map[string]string
+	fail  bool
+}
+
+func (f *fakeKeyring) Get(service, account string) (string, error) {
+	v, ok := f.items[service+"/"+account]
+	if !ok {
+		return "", errors.New("not found")
+	}
+	return v, nil
+}
+
+func (f *fakeKeyring) Set(service, account, secret string) error {
+	if f.fail {
+		return errors.New("keyring locked")
+	}
+	f.items[service+"/"+account] = secret
+	return nil
+}
+
+func (f *fakeKeyring) Delete(service, account string) error {
+	delete(f.items, service+"/"+account)
+	return nil
+}
+
+func TestStore_Keyring(t *testing.T) {
+	dir := t.TempDir()
+	kr := &fakeKeyring{items: map[string]string{}}
+	s, err := Open(dir, WithKeyring(kr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Save(Profile{Name: "work", ServiceURL: "https://memory.example", APIKey: "sk_one"}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if err := s.Save(Profile{Name: "home", APIKey: "sk_home"}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if kr.items["mycelian-memory/work"] != "sk_one" {
+		t.Fatalf("keyring items: %v", kr.items)
+	}
+	b, _ := os.ReadFile(filepath.Join(dir, "profiles.json"))
+	if strings.Contains(string(b), "sk_") {
+		t.Fatalf("profiles.json holds a key: %s", b)
+	}
+
+	// The first profile saved is the default.
+	p, err := s.Load("")
+	if err != nil || p.Name != "work" || p.APIKey != "sk_one" || p.Backend != BackendKeyring {
+		t.Fatalf("Load default: %+v %v", p, err)
+	}
+	if err := s.Rotate("work", "sk_two"); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	if p, _ = s.Load("work"); p.APIKey != "sk_two" || p.RotatedAt == nil || p.ServiceURL != "https://memory.example" {
+		t.Fatalf("after rotate: %+v", p)
+	}
+
+	if err := s.SetDefault("home"); err != nil {
+		t.Fatalf("SetDefault: %v", err)
+	}
+	list, def, err := s.List()
+	if err != nil || def != "home" || len(list) != 2 || list[0].Name != "home" || list[0].APIKey != "" {
+		t.Fatalf("List: %+v %q %v", list, def, err)
+	}
+	if err := s.Delete("home"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := s.Load("home"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Load deleted: %v", err)
+	}
+	if _, ok := kr.items["mycelian-memory/home"]; ok {
+		t.Fatal("deleted profile left its key in the keyring")
+	}
+	if err := s.Save(Profile{Name: "../etc", APIKey: "x"}); err == nil {
+		t.Fatal("expected an invalid profile name to fail")
+	}
+}
+
+func TestStore_FileFallback(t *testing.T) {
+	dir := t.TempDir()
+	s, _ := Open(dir, WithKeyring(&fakeKeyring{items: map[string]string{}, fail: true}))
+	if err := s.Save(Profile{Name: "default", APIKey: "sk_secret"}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	b, err := os.ReadFile(filepath.Join(dir, credentialsFile))
+	if err != nil || strings.Contains(string(b), "sk_secret") {
+		t.Fatalf("credentials file not encrypted: %s %v", b, err)
+	}
+	for _, name := range []string{credentialsFile, keyFile, "profiles.json"} {
+		if st, err := os.Stat(filepath.Join(dir, name)); err != nil || st.Mode().Perm() != 0o600 {
+			t.Fatalf("%s: mode %v %v", name, st.Mode(), err)
+		}
+	}
+	// A store without a keyring reads it back.
+	s2, _ := Open(dir, WithKeyring(nil))
+	if p, err := s2.Load("default"); err != nil || p.APIKey != "sk_secret" || p.Backend != BackendFile {
+		t.Fatalf("Load: %+v %v", p, err)
+	}
+}
+
+func TestStore_Passphrase(t *testing.T) {
+	dir := t.TempDir()
+	s, _ := Open(dir, WithKeyring(nil), WithPassphrase("correct horse"))
+	if err := s.Save(Profile{Name: "default", APIKey: "sk_secret"}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, keyFile)); !os.IsNotExist(err) {
+		t.Fatalf("passphrase store wrote a key file: %v", err)
+	}
+	if p, err := s.Load(""); err != nil || p.APIKey != "sk_secret" {
+		t.Fatalf("Load: %+v %v", p, err)
+	}
+	wrong, _ := Open(dir, WithKeyring(nil), WithPassphrase("battery staple"))
+	if _, err := wrong.Load(""); err == nil || !strings.Contains(err.Error(), "wrong passphrase") {
+		t.Fatalf("wrong passphrase: %v", err)
+	}
+	none, _ := Open(dir, WithKeyring(nil), WithPassphrase(""))
+	if _, err := none.Load(""); err == nil || !strings.Contains(err.Error(), EnvPassphrase) {
+		t.Fatalf("missing passphrase: %v", err)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(EnvDir, dir)
+	t.Setenv(EnvAPIKey, "")
+	t.Setenv(EnvProfile, "")
+	t.Setenv(EnvPassphrase, "")
+	t.Setenv(EnvKeyring, BackendFile)
+
+	// Nothing configured: no key and no error.
+	if url, key, err := Resolve(""); err != nil || url != "" || key != "" {
+		t.Fatalf("empty store: %q %q %v", url, key, err)
+	}
+	if _, _, err := Resolve("missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("named missing profile: %v", err)
+	}
+
+	s, _ := Open("", WithKeyring(nil))
+	_ = s.Save(Profile{Name: "prod", ServiceURL: "https://memory.example", APIKey: "sk_prod"})
+	_ = s.Save(Profile{Name: "staging", APIKey: "sk_staging"})
+	t.Setenv(EnvProfile, "staging")
+	if _, key, _ := Resolve(""); key != "sk_staging" {
+		t.Fatalf("MYCELIAN_PROFILE: %q", key)
+	}
+	if url, key, _ := Resolve("prod"); key != "sk_prod" || url != "https://memory.example" {
+		t.Fatalf("named profile: %q %q", url, key)
+	}
+	t.Setenv(EnvAPIKey, "sk_env")
+	if _, key, _ := Resolve("prod"); key != "sk_env" {
+		t.Fatalf("MYCELIAN_API_KEY should win: %q", key)
+	}
+
+	t.Setenv(EnvAPIKey, "")
+
+	t.Setenv(EnvProfile, "")
+	_ = s.SetDefault("staging")
+	if name, err := s.ProfileName(""); err != nil || name != "staging" {
+		t.Fatalf("ProfileName with a stored default: %q %v", name, err)
+	}
+	t.Setenv(EnvProfile, "prod")
+	if name, _ := s.ProfileName(""); name != "prod" {
+		t.Fatalf("ProfileName with MYCELIAN_PROFILE: %q", name)
+	}
+	if name, _ := s.ProfileName("other"); name != "other" {
+		t.Fatalf("ProfileName named: %q", name)
+	}
+}
+
+func TestCheckServiceURL(t *testing.T) {
+	for _, tc := range []struct {
+		profile, service string
+		ok               bool
+	}{
+		{"", "https://other.example", true},
+		{"https://memory.example", "https://memory.example/", true},
+		{"https://memory.example", "https://other.example", false},
+	} {
+		if err := CheckServiceURL(tc.profile, tc.service); (err == nil) != tc.ok {
+			t.Errorf("CheckServiceURL(%q, %q) = %v", tc.profile, tc.service, err)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package credentials
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// The file fallback keeps every key not in the keyring in credentials.enc:
+// a JSON map of profile name to key, sealed with AES-256-GCM. The AES key is
+// derived from MYCELIAN_CREDENTIALS_PASSPHRASE with PBKDF2 when that is set,
+// and is otherwise 32 random bytes in the key file beside it. Without a
+// passphrase this keeps keys out of plaintext config, backups of the
+// credentials file alone and casual reads; it does not protect against
+// someone who can read the whole directory as the same user.
+const (
+	credentialsFile = "credentials.enc"
+	keyFile         = "credentials.key"
+
+	kdfKeyFile    = "keyfile"
+	kdfPassphrase = "pbkdf2-sha256"
+	pbkdf2Rounds  = 600_000
+)
+
+// sealed is the content of credentials.enc.
+type sealed struct {
+	V     int    `json:"v"`
+	KDF   string `json:"kdf"`
+	Salt  []byte `json:"salt,omitempty"`
+	Nonce []byte `json:"nonce"`
+	Data  []byte `json:"data"`
+}
+
+// putKey stores p.APIKey in the keyring, or in the file when there is none
+// or it fails, and records which in p.Backend.
+func (s *Store) putKey(p *Profile) error {
+	if s.keyring != nil {
+		if err := s.keyring.Set(keyringService, p.Name, p.APIKey); err == nil {
+			p.Backend = BackendKeyring
+			return nil
+		}
+	}
+	keys, err := s.readFileKeys()
+	if err != nil {
+		return err
+	}
+	keys[p.Name] = p.APIKey
+	if err := s.writeFileKeys(keys); err != nil {
+		return err
+	}
+	p.Backend = BackendFile
+	return nil
+}
+
+func (s *Store) getKey(p *Profile) (string, error) {
+	if p.Backend == BackendKeyring {
+		if s.keyring == nil {
+			return "", errors.New("the OS keyring is not available")
+		}
+		return s.keyring.Get(keyringService, p.Name)
+	}
+	keys, err := s.readFileKeys()
+	if err != nil {
+		return "", err
+	}
+	key, ok := keys[p.Name]
+	if !ok {
+		return "", fmt.Errorf("no key in %s", credentialsFile)
+	}
+	return key, nil
+}
+
+func (s *Store) deleteKey(p *Profile) error {
+	if p.Backend == BackendKeyring {
+		if s.keyring == nil {
+			return nil
+		}
+		return s.keyring.Delete(keyringService, p.Name)
+	}
+	keys, err := s.readFileKeys()
+	if err != nil {
+		return err
+	}
+	if _, ok := keys[p.Name]; !ok {
+		return nil
+	}
+	delete(keys, p.Name)
+	return s.writeFileKeys(keys)
+}
+
+func (s *Store) readFileKeys() (map[string]string, error) {
+	keys := map[string]string{}
+	b, err := os.ReadFile(filepath.Join(s.dir, credentialsFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return keys, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var box sealed
+	if err := json.Unmarshal(b, &box); err != nil {
+		return nil, fmt.Errorf("credentials: %s: %w", credentialsFile, err)
+	}
+	aead, err := s.cipher(box.KDF, box.Salt)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := aead.Open(nil, box.Nonce, box.Data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("credentials: cannot decrypt %s (wrong passphrase?)", credentialsFile)
+	}
+	if err := json.Unmarshal(plain, &keys); err != nil {
+		return nil, fmt.Errorf("credentials: %s: %w", credentialsFile, err)
+	}
+	return keys, nil
+}
+
+func (s *Store) writeFileKeys(keys map[string]string) error {
+	plain, err := json.Marshal(keys)
+	if err != nil {
+		return err
+	}
+	box := sealed{V: 1, KDF: kdfKeyFile}
+	if s.passphrase != "" {
+		box.KDF = kdfPassphrase
+		box.Salt = make([]byte, 16)
+		if _, err := rand.Read(box.Salt); err != nil {
+			return err
+		}
+	}
+	aead, err := s.cipher(box.KDF, box.Salt)
+	if err != nil {
+		return err
+	}
+	box.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(box.Nonce); err != nil {
+		return err
+	}
+	box.Data = aead.Seal(nil, box.Nonce, plain, nil)
+	b, err := json.Marshal(box)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(s.dir, credentialsFile), b)
+}
+
+// cipher returns the AEAD for a credentials file sealed with kdf.
+func (s *Store) cipher(kdf string, salt []byte) (cipher.AEAD, error) {
+	var key []byte
+	var err error
+	switch kdf {
+	case kdfPassphrase:
+		if s.passphrase == "" {
+			return nil, fmt.Errorf("credentials: %s is encrypted with a passphrase; set %s", credentialsFile, EnvPassphrase)
+		}
+		key, err = pbkdf2.Key(sha256.New, s.passphrase, salt, pbkdf2Rounds, 32)
+	case kdfKeyFile:
+		key, err = s.fileKey()
+	default:
+		return nil, fmt.Errorf("credentials: %s: unknown kdf %q", credentialsFile, kdf)
+	}
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// fileKey reads the key file, creating it on first use.
+func (s *Store) fileKey() ([]byte, error) {
+	path := filepath.Join(s.dir, keyFile)
+	key, err := os.ReadFile(path)
+	if err == nil {
+		if len(key) != 32 {
+			return nil, fmt.Errorf("credentials: %s is not a 32-byte key", path)
+		}
+		return key, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	key = make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err := writeFileAtomic(path, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
@@ -0,0 +1,103 @@
+package credentials
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// keyringService names the keyring items holding profile keys; the account
+// is the profile name.
+const keyringService = "mycelian-memory"
+
+// Keyring stores secrets in an OS credential store.
+type Keyring interface {
+	Get(service, account string) (string, error)
+	Set(service, account, secret string) error
+	Delete(service, account string) error
+}
+
+// SystemKeyring returns the OS keyring: the macOS Keychain, or the Secret
+// Service on Linux when secret-tool (libsecret-tools) is installed. It is nil
+// elsewhere or when MYCELIAN_KEYRING=file, and keys are then kept in the
+// encrypted file.
+func SystemKeyring() Keyring {
+	if os.Getenv(EnvKeyring) == BackendFile {
+		return nil
+	}
+	switch runtime.GOOS {
+	case "darwin":
+		if _, err := exec.LookPath("security"); err == nil {
+			return macKeychain{}
+		}
+	case "linux":
+		if _, err := exec.LookPath("secret-tool"); err == nil {
+			return secretService{}
+		}
+	}
+	return nil
+}
+
+// macKeychain drives security(1). Writes go through its interactive mode on
+// stdin so the key never shows up in the process list.
+type macKeychain struct{}
+
+func (macKeychain) Get(service, account string) (string, error) {
+	out, err := run(nil, "security", "find-generic-password", "-s", service, "-a", account, "-w")
+	return strings.TrimRight(out, "\n"), err
+}
+
+func (macKeychain) Set(service, account, secret string) error {
+	cmd := fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n", quote(service), quote(account), quote(secret))
+	_, err := run(strings.NewReader(cmd), "security", "-i")
+	return err
+}
+
+func (macKeychain) Delete(service, account string) error {
+	_, err := run(nil, "security", "delete-generic-password", "-s", service, "-a", account)
+	return err
+}
+
+// secretService drives secret-tool(1), which reads the secret from stdin.
+type secretService struct{}
+
+func (secretService) Get(service, account string) (string, error) {
+	out, err := run(nil, "secret-tool", "lookup", "service", service, "account", account)
+	if err == nil && out == "" {
+		err = errors.New("secret-tool: no such secret")
+	}
+	return out, err
+}
+
+func (secretService) Set(service, account, secret string) error {
+	_, err := run(strings.NewReader(secret), "secret-tool", "store", "--label=Mycelian API key ("+account+")",
+		"service", service, "account", account)
+	return err
+}
+
+func (secretService) Delete(service, account string) error {
+	_, err := run(nil, "secret-tool", "clear", "service", service, "account", account)
+	return err
+}
+
+func run(stdin *strings.Reader, name string, args ...string) (string, error) {
+	cmd := exec.Command(name, args...)
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%s: %w: %s", name, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// quote makes s one word for security's interactive mode.
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
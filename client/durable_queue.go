@@ -0,0 +1,365 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/mycelian/mycelian-memory/client/internal/api"
+	"github.com/rs/zerolog/log"
+)
+
+// AddEntry and PutContext return once a write is queued in memory, so a
+// crash, or an outage outlasting the retries, loses what was still queued.
+// With WithDurableQueue every async write is first appended to a journal
+// file and marked done once the server has stored or permanently rejected
+// it; New replays the writes an earlier client left unfinished, in order,
+// before the client takes new ones.
+//
+// Entry replays are idempotent: each journaled AddEntry carries an
+// IdempotencyKey, so an entry the server stored just before the crash is
+// returned rather than written again. New refuses a durable queue when the
+// server reports FeatureIdempotentEntries disabled, since its replays would
+// duplicate entries. A replayed context is stored again as a new snapshot
+// of the same document.
+//
+// AddEntrySync and AddEntry under WithSyncWrites are not journaled; their
+// caller learns the outcome. A context held by WithContextCoalescing is
+// journaled when its window ends.
+
+// WithDurableQueue journals async AddEntry and PutContext writes in the file
+// at path, created if missing, and replays the unfinished ones in New. Only
+// one client at a time may use a journal file: New fails while another
+// holds the lock on path+".lock".
+func WithDurableQueue(path string) Option {
+	return func(c *Client) error {
+		if path == "" {
+			return fmt.Errorf("durable queue path cannot be empty")
+		}
+		c.journalPath = path
+		return nil
+	}
+}
+
+// errJournalClosed is returned for writes journaled after Close.
+var errJournalClosed = errors.New("durable queue is closed")
+
+// errJournalInUse is returned by New when another client holds the journal.
+var errJournalInUse = errors.New("durable queue is in use by another client")
+
+// journalCompactBytes is how large the journal may grow before settling a
+// write compacts it; it also compacts once it is twice its last compacted
+// size, so a large backlog is not rewritten on every settlement.
+const journalCompactBytes = 1 << 20
+
+const (
+	journalAddEntry   = "addEntry"
+	journalPutContext = "putContext"
+)
+
+// journalRecord is one line of the journal: a write, or with Done the
+// settlement of the write appended under the same Key.
+type journalRecord struct {
+	Key      string           `json:"key"`
+	Op       string           `json:"op,omitempty"`
+	VaultID  string           `json:"vaultId,omitempty"`
+	MemoryID string           `json:"memoryId,omitempty"`
+	Entry    *AddEntryRequest `json:"entry,omitempty"`
+	Context  string           `json:"context,omitempty"`
+	Done     bool             `json:"done,omitempty"`
+}
+
+// writeJournal is an append-only file of JSON lines. Writes are synced
+// before they are queued; settlements are not, since losing one only
+// repeats an idempotent write.
+type writeJournal struct {
+	path string
+	lock *os.File // holds the lock on path+".lock" until close
+
+	mu      sync.Mutex
+	f       *os.File
+	seq     uint64
+	pending map[string]journaledWrite
+	// size is the file's length and compacted its length after the last
+	// compaction.
+	size, compacted int64
+}
+
+type journaledWrite struct {
+	seq uint64
+	rec journalRecord
+}
+
+// openWriteJournal locks the journal at path, loads it and compacts it to
+// its unfinished writes, which it returns oldest first. A torn last line,
+// left by a crash mid-append, is dropped.
+func openWriteJournal(path string) (*writeJournal, []journalRecord, error) {
+	lock, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, nil, fmt.Errorf("lock durable queue: %w", err)
+	}
+	if err := lockFile(lock); err != nil {
+		_ = lock.Close()
+		return nil, nil, fmt.Errorf("%w: %s: %v", errJournalInUse, path, err)
+	}
+	j := &writeJournal{path: path, lock: lock, pending: map[string]journaledWrite{}}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		_ = lock.Close()
+		return nil, nil, fmt.Errorf("read durable queue: %w", err)
+	}
+	r := bufio.NewReader(bytes.NewReader(data))
+	for {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			break
+		}
+		var rec journalRecord
+		if json.Unmarshal(line, &rec) != nil || rec.Key == "" {
+			log.Warn().Str("path", path).Msg("skipping unreadable durable queue record")
+			continue
+		}
+		if rec.Done {
+			delete(j.pending, rec.Key)
+			continue
+		}
+		j.seq++
+		j.pending[rec.Key] = journaledWrite{seq: j.seq, rec: rec}
+	}
+	if err := j.compact(); err != nil {
+		_ = lock.Close()
+		return nil, nil, err
+	}
+	return j, j.unfinished(), nil
+}
+
+// unfinished returns the pending writes oldest first.
+func (j *writeJournal) unfinished() []journalRecord {
+	writes := make([]journaledWrite, 0, len(j.pending))
+	for _, w := range j.pending {
+		writes = append(writes, w)
+	}
+	sort.Slice(writes, func(a, b int) bool { return writes[a].seq < writes[b].seq })
+	out := make([]journalRecord, len(writes))
+	for i, w := range writes {
+		out[i] = w.rec
+	}
+	return out
+}
+
+// compact rewrites the file with the pending writes only and reopens it for
+// appending. The caller holds mu or owns j.
+func (j *writeJournal) compact() error {
+	tmp := j.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("compact durable queue: %w", err)
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, rec := range j.unfinished() {
+		if err = enc.Encode(rec); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	size, _ := f.Seek(0, io.SeekCurrent)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, j.path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("compact durable queue: %w", err)
+	}
+	if j.f != nil {
+		_ = j.f.Close()
+	}
+	j.f, err = os.OpenFile(j.path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("open durable queue: %w", err)
+	}
+	j.size, j.compacted = size, size
+	return nil
+}
+
+// record appends a write and syncs it to disk.
+func (j *writeJournal) record(rec journalRecord) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.f == nil {
+		return errJournalClosed
+	}
+	if _, err := j.f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("write durable queue: %w", err)
+	}
+	j.size += int64(len(line)) + 1
+	if err := j.f.Sync(); err != nil {
+		return fmt.Errorf("sync durable queue: %w", err)
+	}
+	j.seq++
+	j.pending[rec.Key] = journaledWrite{seq: j.seq, rec: rec}
+	return nil
+}
+
+// settle marks the write under key as finished.
+func (j *writeJournal) settle(key string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if _, ok := j.pending[key]; !ok {
+		return
+	}
+	delete(j.pending, key)
+	if j.f == nil {
+		return
+	}
+	line, _ := json.Marshal(journalRecord{Key: key, Done: true})
+	if _, err := j.f.Write(append(line, '\n')); err != nil {
+		log.Warn().Err(err).Str("path", j.path).Msg("could not mark journaled write done; it will be replayed")
+		return
+	}
+	j.size += int64(len(line)) + 1
+	if j.size >= journalCompactBytes && j.size >= 2*j.compacted {
+		if err := j.compact(); err != nil {
+			log.Warn().Err(err).Str("path", j.path).Msg("could not compact durable queue")
+		}
+	}
+}
+
+// close compacts the journal, keeping writes that never finished for the
+// next client, and releases its lock.
+func (j *writeJournal) close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.f == nil {
+		return nil
+	}
+	err := j.compact()
+	if j.f != nil {
+		if cerr := j.f.Close(); err == nil {
+			err = cerr
+		}
+		j.f = nil
+	}
+	_ = j.lock.Close()
+	return err
+}
+
+// openDurableQueue opens the journal and resubmits its unfinished writes.
+// Replay stops at the first write the executor refuses (e.g. a full queue);
+// the rest stay journaled for the next start.
+func (c *Client) openDurableQueue() error {
+	if err := c.requireFeature(FeatureIdempotentEntries); err != nil {
+		return fmt.Errorf("durable queue needs idempotent entries: %w", err)
+	}
+	j, unfinished, err := openWriteJournal(c.journalPath)
+	if err != nil {
+		return err
+	}
+	c.journal = j
+	if len(unfinished) == 0 {
+		return nil
+	}
+	log.Info().Str("path", c.journalPath).Int("writes", len(unfinished)).Msg("replaying journaled writes")
+	ctx := context.Background()
+	for i, rec := range unfinished {
+		switch rec.Op {
+		case journalAddEntry:
+			if rec.Entry == nil {
+				j.settle(rec.Key)
+				continue
+			}
+			err = c.submitJournaledEntry(ctx, rec.Key, rec.VaultID, rec.MemoryID, *rec.Entry)
+		case journalPutContext:
+			err = c.submitJournaledContext(ctx, c.exec, rec.Key, rec.VaultID, rec.MemoryID, rec.Context)
+		default:
+			j.settle(rec.Key)
+			continue
+		}
+		if err != nil {
+			log.Warn().Err(err).Int("left", len(unfinished)-i).Msg("journaled writes not replayed; retrying on next start")
+			return nil
+		}
+	}
+	return nil
+}
+
+// addEntryJournaled journals the entry under a new key, which also becomes
+// its IdempotencyKey unless the caller set one, then queues it.
+func (c *Client) addEntryJournaled(ctx context.Context, vaultID, memID string, req AddEntryRequest) (*EnqueueAck, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	key := uuid.NewString()
+	if req.IdempotencyKey == "" {
+		req.IdempotencyKey = key
+	}
+	if err := c.journal.record(journalRecord{Key: key, Op: journalAddEntry, VaultID: vaultID, MemoryID: memID, Entry: &req}); err != nil {
+		return nil, err
+	}
+	if err := c.submitJournaledEntry(ctx, key, vaultID, memID, req); err != nil {
+		// Never queued and the caller sees the error: nothing to replay.
+		c.journal.settle(key)
+		return nil, err
+	}
+	return &EnqueueAck{MemoryID: memID, Status: "enqueued"}, nil
+}
+
+func (c *Client) submitJournaledEntry(ctx context.Context, key, vaultID, memID string, req AddEntryRequest) error {
+	var p *pendingEntry
+	if c.pending != nil {
+		p = c.pending.track(vaultID, memID, req)
+	}
+	_, err := api.AddEntryNotify(ctx, c.exec, c.http, c.baseURL, vaultID, memID, req, func(created *Entry, err error) {
+		c.journal.settle(key)
+		if p != nil {
+			c.pending.settle(p, created, err)
+		}
+	})
+	if err != nil && p != nil {
+		c.pending.settle(p, nil, err)
+	}
+	return err
+}
+
+// putContextJournaled journals the context write, then queues it on exec.
+func (c *Client) putContextJournaled(ctx context.Context, exec executor, vaultID, memID, doc string) (*EnqueueAck, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	key := uuid.NewString()
+	if err := c.journal.record(journalRecord{Key: key, Op: journalPutContext, VaultID: vaultID, MemoryID: memID, Context: doc}); err != nil {
+		return nil, err
+	}
+	if err := c.submitJournaledContext(ctx, exec, key, vaultID, memID, doc); err != nil {
+		c.journal.settle(key)
+		return nil, err
+	}
+	return &EnqueueAck{MemoryID: memID, Status: "enqueued"}, nil
+}
+
+func (c *Client) submitJournaledContext(ctx context.Context, exec executor, key, vaultID, memID, doc string) error {
+	_, err := api.PutContextNotify(ctx, exec, c.http, c.baseURL, vaultID, memID, doc, func(error) {
+		c.journal.settle(key)
+	})
+	return err
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package client
+
+import "os"
+
+// lockFile does not lock on platforms without flock; one client per
+// journal file is then up to the caller.
+func lockFile(*os.File) error { return nil }
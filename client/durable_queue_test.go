@@ -0,0 +1,207 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// writeRecorder is a server accepting entries and contexts, recording the
+// bodies it receives per path.
+type writeRecorder struct {
+	mu     sync.Mutex
+	bodies map[string][]string
+	status int
+}
+
+func (wr *writeRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b, _ := io.ReadAll(r.Body)
+	wr.mu.Lock()
+	if wr.bodies == nil {
+		wr.bodies = map[string][]string{}
+	}
+	wr.bodies[r.URL.Path] = append(wr.bodies[r.URL.Path], string(b))
+	status := wr.status
+	wr.mu.Unlock()
+	if status == 0 {
+		status = http.StatusCreated
+	}
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(`{"entryId":"e1"}`))
+}
+
+func (wr *writeRecorder) got(path string) []string {
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+	return wr.bodies[path]
+}
+
+func TestDurableQueueReplaysUnfinishedWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.jsonl")
+	j, unfinished, err := openWriteJournal(path)
+	if err != nil || len(unfinished) != 0 {
+		t.Fatalf("open empty journal: %v %v", unfinished, err)
+	}
+	t.Cleanup(func() { _ = j.f.Close() }) // left open, as by a crashed process
+	_ = j.lock.Close()                    // whose exit released the lock
+	entry := AddEntryRequest{RawEntry: "lost in the crash", Summary: "s", IdempotencyKey: "k1"}
+	for _, rec := range []journalRecord{
+		{Key: "k1", Op: journalAddEntry, VaultID: "v1", MemoryID: "m1", Entry: &entry},
+		{Key: "k2", Op: journalAddEntry, VaultID: "v1", MemoryID: "m1", Entry: &AddEntryRequest{RawEntry: "stored", Summary: "s"}},
+		{Key: "k3", Op: journalPutContext, VaultID: "v1", MemoryID: "m1", Context: "ctx doc"},
+	} {
+		if err := j.record(rec); err != nil {
+			t.Fatalf("record: %v", err)
+		}
+	}
+	j.settle("k2")
+	// Simulate a crash mid-append: a torn final line.
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	_, _ = f.WriteString(`{"key":"k4","op":"addEn`)
+	_ = f.Close()
+
+	srv := &writeRecorder{}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+	c, err := New(ts.URL, WithAPIKey("k"), WithDurableQueue(path))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	entries := srv.got("/v0/vaults/v1/memories/m1/entries")
+	if len(entries) != 1 {
+		t.Fatalf("replayed entries = %v, want only k1", entries)
+	}
+	var body AddEntryRequest
+	if err := json.Unmarshal([]byte(entries[0]), &body); err != nil || body.RawEntry != "lost in the crash" || body.IdempotencyKey != "k1" {
+		t.Fatalf("replayed entry = %s (%v)", entries[0], err)
+	}
+	if ctxs := srv.got("/v0/vaults/v1/memories/m1/contexts"); len(ctxs) != 1 || ctxs[0] != "ctx doc" {
+		t.Fatalf("replayed contexts = %v", ctxs)
+	}
+
+	if _, unfinished, err := openWriteJournal(path); err != nil || len(unfinished) != 0 {
+		t.Fatalf("after replay: unfinished=%v err=%v", unfinished, err)
+	}
+}
+
+func TestDurableQueueJournalsAsyncWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.jsonl")
+	srv := &writeRecorder{}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+	c, err := New(ts.URL, WithAPIKey("k"), WithDurableQueue(path))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ctx := context.Background()
+	if _, err := c.AddEntry(ctx, "v1", "m1", AddEntryRequest{RawEntry: "hi", Summary: "s"}); err != nil {
+		t.Fatalf("AddEntry: %v", err)
+	}
+	if _, err := c.PutContext(ctx, "v1", "m1", "doc"); err != nil {
+		t.Fatalf("PutContext: %v", err)
+	}
+	// The journal holds both writes until they are attempted.
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), `"op":"addEntry"`) || !strings.Contains(string(data), `"op":"putContext"`) {
+		t.Fatalf("journal = %s", data)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	entries := srv.got("/v0/vaults/v1/memories/m1/entries")
+	if len(entries) != 1 || !strings.Contains(entries[0], `"idempotencyKey"`) {
+		t.Fatalf("entries = %v", entries)
+	}
+	if data, _ := os.ReadFile(path); len(data) != 0 {
+		t.Fatalf("journal after Close = %q, want empty", data)
+	}
+
+	// A permanently rejected write is settled too; it would fail again.
+	srv.mu.Lock()
+	srv.status = http.StatusBadRequest
+	srv.mu.Unlock()
+	c, err = New(ts.URL, WithAPIKey("k"), WithDurableQueue(path))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if _, err := c.AddEntry(ctx, "v1", "m1", AddEntryRequest{RawEntry: "bad", Summary: "s"}); err != nil {
+		t.Fatalf("AddEntry: %v", err)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if _, unfinished, err := openWriteJournal(path); err != nil || len(unfinished) != 0 {
+		t.Fatalf("after rejection: unfinished=%v err=%v", unfinished, err)
+	}
+}
+
+func TestDurableQueueIsSingleUse(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.jsonl")
+	j, _, err := openWriteJournal(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if _, _, err := openWriteJournal(path); !errors.Is(err, errJournalInUse) {
+		t.Fatalf("second open: %v, want errJournalInUse", err)
+	}
+	if err := j.close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	j, _, err = openWriteJournal(path)
+	if err != nil {
+		t.Fatalf("open after close: %v", err)
+	}
+	_ = j.close()
+}
+
+func TestDurableQueueCompactsAsWritesSettle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.jsonl")
+	j, _, err := openWriteJournal(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer func() { _ = j.close() }()
+	entry := &AddEntryRequest{RawEntry: strings.Repeat("x", 1000), Summary: "s"}
+	for i := 0; i < 3000; i++ {
+		key := fmt.Sprintf("k%d", i)
+		if err := j.record(journalRecord{Key: key, Op: journalAddEntry, VaultID: "v1", MemoryID: "m1", Entry: entry}); err != nil {
+			t.Fatalf("record: %v", err)
+		}
+		if i != 7 {
+			j.settle(key)
+		}
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() >= journalCompactBytes {
+		t.Fatalf("journal not compacted: %v %v", info.Size(), err)
+	}
+	_ = j.close()
+	j, unfinished, err := openWriteJournal(path)
+	if err != nil || len(unfinished) != 1 || unfinished[0].Key != "k7" {
+		t.Fatalf("after compaction: %v %v", unfinished, err)
+	}
+}
+
+func TestDurableQueueNeedsIdempotentEntries(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"apiVersion":"v0","features":{"idempotentEntries":false}}`))
+	}))
+	defer ts.Close()
+	path := filepath.Join(t.TempDir(), "queue.jsonl")
+	if _, err := New(ts.URL, WithAPIKey("k"), WithDurableQueue(path), WithCapabilityNegotiation(time.Second)); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("New: %v, want ErrUnsupported", err)
+	}
+}
@@ -3,9 +3,11 @@ package client
 import (
 	"context"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestListAndDeleteEntries(t *testing.T) {
@@ -28,9 +30,9 @@ func TestListAndDeleteEntries(t *testing.T) {
 	}))
 	defer srv.Close()
 
-	c, err := NewWithDevMode(srv.URL)
+	c, err := New(srv.URL, WithDevMode())
 	if err != nil {
-		t.Fatalf("NewWithDevMode: %v", err)
+		t.Fatalf("New: %v", err)
 	}
 	ctx := context.Background()
 	lr, err := c.ListEntries(ctx, vaultID, memID, map[string]string{"limit": "10"})
@@ -47,3 +49,97 @@ func TestListAndDeleteEntries(t *testing.T) {
 		t.Fatalf("DELETE not called")
 	}
 }
+
+func TestPatchEntryTags(t *testing.T) {
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch || r.URL.Path != "/v0/vaults/v1/memories/m1/entries:tags" {
+			t.Fatalf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		_, _ = w.Write([]byte(`{"updated":2,"entryIds":["e1","e2"]}`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, WithAPIKey("k"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer func() { _ = c.Close() }()
+
+	out, err := c.PatchEntryTags(context.Background(), "v1", "m1", PatchEntryTagsRequest{
+		SessionID: "s1",
+		Set:       map[string]string{"status": "resolved"},
+		Unset:     []string{"todo"},
+	})
+	if err != nil || out.Updated != 2 || len(out.EntryIDs) != 2 {
+		t.Fatalf("PatchEntryTags: out=%+v err=%v", out, err)
+	}
+	if want := `{"filter":{"sessionId":"s1"},"set":{"status":"resolved"},"unset":["todo"]}`; body != want {
+		t.Fatalf("body = %s, want %s", body, want)
+	}
+}
+
+func TestAddEntries(t *testing.T) {
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v0/vaults/v1/memories/m1/entries:batch" {
+			t.Fatalf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"entries":[{"entryId":"e1"},{"entryId":"e2"}],"count":2}`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, WithAPIKey("k"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer func() { _ = c.Close() }()
+
+	said := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	out, err := c.AddEntries(context.Background(), "v1", "m1", []AddEntryRequest{
+		{RawEntry: "first", ConversationTime: &said},
+		{RawEntry: "second"},
+	})
+	if err != nil || out.Count != 2 || out.Entries[1].ID != "e2" {
+		t.Fatalf("AddEntries: out=%+v err=%v", out, err)
+	}
+	if want := `{"entries":[{"rawEntry":"first","conversationTime":"2025-03-01T09:00:00Z"},{"rawEntry":"second"}]}`; body != want {
+		t.Fatalf("body = %s, want %s", body, want)
+	}
+}
+
+func TestCorrectEntry(t *testing.T) {
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v0/vaults/v1/memories/m1/entries/e1/corrections" {
+			t.Fatalf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"entryId":"e2","rawEntry":"lives in Lisbon"}`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, WithAPIKey("k"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer func() { _ = c.Close() }()
+
+	out, err := c.CorrectEntry(context.Background(), "v1", "m1", "e1", CorrectEntryRequest{
+		AddEntryRequest: AddEntryRequest{RawEntry: "lives in Lisbon"},
+		Reason:          "moved",
+	})
+	if err != nil || out.ID != "e2" {
+		t.Fatalf("CorrectEntry: out=%+v err=%v", out, err)
+	}
+	if want := `{"rawEntry":"lives in Lisbon","reason":"moved"}`; body != want {
+		t.Fatalf("body = %s, want %s", body, want)
+	}
+}
@@ -2,7 +2,10 @@ package client
 
 import (
 	"errors"
+	"net/http"
+	"strings"
 
+	clienterrors "github.com/mycelian/mycelian-memory/client/internal/errors"
 	"github.com/mycelian/mycelian-memory/client/internal/types"
 )
 
@@ -12,5 +15,47 @@ var ErrBackPressure = errors.New("back-pressure (queue full)")
 // IsBackPressure reports whether err is a back-pressure error.
 func IsBackPressure(err error) bool { return errors.Is(err, ErrBackPressure) }
 
+// ErrUnsupported is returned without a request when the server's
+// capabilities show it lacks the feature a call needs.
+var ErrUnsupported = errors.New("not supported by the server")
+
 // Re-export shared SDK error so callers compare against a single symbol.
 var ErrNotFound = types.ErrNotFound
+
+// IsUnauthorized reports whether err is an HTTP 401/403 returned by the service,
+// i.e. the API key is missing, invalid, or lacks permission.
+func IsUnauthorized(err error) bool {
+	var ce *clienterrors.ClassifiedError
+	if !errors.As(err, &ce) {
+		return false
+	}
+	return ce.StatusCode == http.StatusUnauthorized || ce.StatusCode == http.StatusForbidden
+}
+
+// IsReadOnly reports whether err is the service rejecting a write because the
+// target vault is marked read-only.
+func IsReadOnly(err error) bool {
+	var ce *clienterrors.ClassifiedError
+	if !errors.As(err, &ce) {
+		return false
+	}
+	return ce.StatusCode == http.StatusConflict && strings.Contains(ce.Body, "read-only")
+}
+
+// IsAppendOnly reports whether err is the service rejecting a change to an
+// existing entry because its memory is append-only.
+func IsAppendOnly(err error) bool {
+	var ce *clienterrors.ClassifiedError
+	if !errors.As(err, &ce) {
+		return false
+	}
+	return ce.StatusCode == http.StatusConflict && strings.Contains(ce.Body, "append-only")
+}
+
+// IsPreconditionFailed reports whether err is the service rejecting a
+// conditional write because the resource changed since the given ETag or
+// context ID was read.
+func IsPreconditionFailed(err error) bool {
+	var ce *clienterrors.ClassifiedError
+	return errors.As(err, &ce) && ce.StatusCode == http.StatusPreconditionFailed
+}
@@ -10,7 +10,8 @@ import (
 type executor interface {
 	Submit(context.Context, string, shardqueue.Job) error
 	Barrier(context.Context, string) error
-	Stop()
+	Shutdown(context.Context) (shardqueue.Report, error)
+	Report() shardqueue.Report
 }
 
 // Note: all clients include an executor by default; async methods require it.
@@ -99,9 +99,9 @@ func TestClient_AddEntry(t *testing.T) {
 			}))
 			defer srv.Close()
 
-			c, err := client.NewWithDevMode(srv.URL)
+			c, err := client.New(srv.URL, client.WithDevMode())
 			if err != nil {
-				t.Fatalf("NewWithDevMode: %v", err)
+				t.Fatalf("New: %v", err)
 			}
 			ctx := context.Background()
 			if tt.cancelCtx {
@@ -145,9 +145,9 @@ func TestClient_DeleteEntry_Success(t *testing.T) {
 	srv := httptest.NewServer(mux)
 	defer srv.Close()
 
-	c, err := client.NewWithDevMode(srv.URL)
+	c, err := client.New(srv.URL, client.WithDevMode())
 	if err != nil {
-		t.Fatalf("NewWithDevMode: %v", err)
+		t.Fatalf("New: %v", err)
 	}
 	t.Cleanup(func() { _ = c.Close() })
 	if err := c.DeleteEntry(context.Background(), vaultID, memID, entryID); err != nil {
@@ -176,9 +176,9 @@ func TestClient_GetEntry_Success(t *testing.T) {
 	srv := httptest.NewServer(mux)
 	defer srv.Close()
 
-	c, err := client.NewWithDevMode(srv.URL)
+	c, err := client.New(srv.URL, client.WithDevMode())
 	if err != nil {
-		t.Fatalf("NewWithDevMode: %v", err)
+		t.Fatalf("New: %v", err)
 	}
 	t.Cleanup(func() { _ = c.Close() })
 	got, err := c.GetEntry(context.Background(), vaultID, memID, entryID)
@@ -22,7 +22,7 @@ func TestClient_Search_Success(t *testing.T) {
 	}))
 	defer srv.Close()
 
-	c, err := client.New(srv.URL, "test-api-key")
+	c, err := client.New(srv.URL, client.WithAPIKey("test-api-key"))
 	if err != nil {
 		t.Fatalf("client.New error: %v", err)
 	}
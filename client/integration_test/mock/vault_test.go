@@ -50,9 +50,9 @@ func TestClient_VaultCRUD_AndGetByTitle(t *testing.T) {
 	}))
 	defer srv.Close()
 
-	c, err := client.NewWithDevMode(srv.URL)
+	c, err := client.New(srv.URL, client.WithDevMode())
 	if err != nil {
-		t.Fatalf("NewWithDevMode: %v", err)
+		t.Fatalf("New: %v", err)
 	}
 	t.Cleanup(func() { _ = c.Close() })
 	ctx := context.Background()
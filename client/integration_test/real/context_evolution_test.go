@@ -21,9 +21,9 @@ func TestContextEvolutionE2E(t *testing.T) {
 		baseURL = "http://localhost:11545"
 	}
 
-	c, err := client.NewWithDevMode(baseURL)
+	c, err := client.New(baseURL, client.WithDevMode())
 	if err != nil {
-		t.Fatalf("NewWithDevMode: %v", err)
+		t.Fatalf("New: %v", err)
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -81,9 +81,9 @@ func TestMultiAgentContextAccessE2E(t *testing.T) {
 	defer cancel()
 
 	// Agent A
-	agentA, err := client.NewWithDevMode(baseURL)
+	agentA, err := client.New(baseURL, client.WithDevMode())
 	if err != nil {
-		t.Fatalf("NewWithDevMode (A): %v", err)
+		t.Fatalf("New (A): %v", err)
 	}
 	defer agentA.Close()
 
@@ -112,9 +112,9 @@ func TestMultiAgentContextAccessE2E(t *testing.T) {
 	_ = agentA.AwaitConsistency(ctx, mem.ID)
 
 	// Agent B
-	agentB, err := client.NewWithDevMode(baseURL)
+	agentB, err := client.New(baseURL, client.WithDevMode())
 	if err != nil {
-		t.Fatalf("NewWithDevMode (B): %v", err)
+		t.Fatalf("New (B): %v", err)
 	}
 	defer agentB.Close()
 
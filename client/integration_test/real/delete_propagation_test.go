@@ -21,9 +21,9 @@ func TestDeleteEntryPropagationE2E(t *testing.T) {
 		baseURL = "http://localhost:11545"
 	}
 
-	c, err := client.NewWithDevMode(baseURL)
+	c, err := client.New(baseURL, client.WithDevMode())
 	if err != nil {
-		t.Fatalf("NewWithDevMode: %v", err)
+		t.Fatalf("New: %v", err)
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
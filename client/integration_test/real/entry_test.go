@@ -26,9 +26,9 @@ func TestAddEntryE2E(t *testing.T) {
 		baseURL = "http://localhost:11545"
 	}
 
-	c, err := client.NewWithDevMode(baseURL)
+	c, err := client.New(baseURL, client.WithDevMode())
 	if err != nil {
-		t.Fatalf("NewWithDevMode: %v", err)
+		t.Fatalf("New: %v", err)
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -22,9 +22,9 @@ func TestUserVaultMemoryCRUD(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	c, err := client.NewWithDevMode(baseURL)
+	c, err := client.New(baseURL, client.WithDevMode())
 	if err != nil {
-		t.Fatalf("NewWithDevMode: %v", err)
+		t.Fatalf("New: %v", err)
 	}
 	defer c.Close()
 
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/mycelian/mycelian-memory/client/internal/errors"
+	"github.com/mycelian/mycelian-memory/client/internal/types"
+)
+
+// GetActorSettings returns the calling actor's settings (default time zone).
+func GetActorSettings(ctx context.Context, httpClient *http.Client, baseURL string) (*types.ActorSettings, error) {
+	return doActorSettings(ctx, httpClient, baseURL, "/v0/actor/settings", http.MethodGet, nil, "get actor settings")
+}
+
+// SetActorTimeZone stores an IANA zone name (e.g. "Europe/Berlin") as the
+// actor's default; entry timestamps and date filters then resolve in it.
+func SetActorTimeZone(ctx context.Context, httpClient *http.Client, baseURL, timeZone string) (*types.ActorSettings, error) {
+	body, err := json.Marshal(map[string]string{"timeZone": timeZone})
+	if err != nil {
+		return nil, err
+	}
+	return doActorSettings(ctx, httpClient, baseURL, "/v0/actor/settings", http.MethodPut, body, "set actor time zone")
+}
+
+// SetActorDefaults stores the vault and memory the server uses when entry,
+// context and search calls omit them; empty IDs clear the defaults.
+func SetActorDefaults(ctx context.Context, httpClient *http.Client, baseURL, vaultID, memoryID string) (*types.ActorSettings, error) {
+	body, err := json.Marshal(map[string]string{"vaultId": vaultID, "memoryId": memoryID})
+	if err != nil {
+		return nil, err
+	}
+	return doActorSettings(ctx, httpClient, baseURL, "/v0/actor/defaults", http.MethodPut, body, "set actor defaults")
+}
+
+func doActorSettings(ctx context.Context, httpClient *http.Client, baseURL, path, method string, body []byte, op string) (*types.ActorSettings, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var rdr io.Reader
+	if body != nil {
+		rdr = bytes.NewReader(body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, method, baseURL+path, rdr)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, readErr := io.ReadAll(resp.Body)
+		if readErr != nil {
+			return nil, errors.NewHTTPError(resp.StatusCode, "", op)
+		}
+		return nil, errors.ClassifyHTTPError(resp.StatusCode, string(bodyBytes), fmt.Errorf("%s failed", op))
+	}
+	var out types.ActorSettings
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestActorSettings(t *testing.T) {
+	t.Parallel()
+	tz := "UTC"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v0/actor/settings" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if r.Method == http.MethodPut {
+			var body map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			if body["timeZone"] == "Mars/Olympus" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			tz = body["timeZone"]
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"actorId": "a1", "timeZone": tz})
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	if s, err := GetActorSettings(ctx, srv.Client(), srv.URL); err != nil || s.TimeZone != "UTC" {
+		t.Fatalf("GetActorSettings: s=%+v err=%v", s, err)
+	}
+	if s, err := SetActorTimeZone(ctx, srv.Client(), srv.URL, "Europe/Berlin"); err != nil || s.TimeZone != "Europe/Berlin" {
+		t.Fatalf("SetActorTimeZone: s=%+v err=%v", s, err)
+	}
+	if _, err := SetActorTimeZone(ctx, srv.Client(), srv.URL, "Mars/Olympus"); err == nil {
+		t.Fatalf("expected error for 400")
+	}
+}
+
+func TestSetActorDefaults(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/v0/actor/defaults" {
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"actorId": "a1", "timeZone": "UTC",
+			"defaultVaultId": body["vaultId"], "defaultMemoryId": body["memoryId"],
+		})
+	}))
+	defer srv.Close()
+
+	s, err := SetActorDefaults(context.Background(), srv.Client(), srv.URL, "v1", "m1")
+	if err != nil || s.DefaultVaultID != "v1" || s.DefaultMemoryID != "m1" {
+		t.Fatalf("SetActorDefaults: s=%+v err=%v", s, err)
+	}
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/mycelian/mycelian-memory/client/internal/errors"
+	"github.com/mycelian/mycelian-memory/client/internal/types"
+)
+
+// ListEntityAliases returns a memory's entity aliases ordered by canonical name.
+func ListEntityAliases(ctx context.Context, httpClient *http.Client, baseURL, vaultID, memID string) (*types.ListEntityAliasesResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	u := fmt.Sprintf("%s/v0/vaults/%s/memories/%s/aliases", baseURL, vaultID, memID)
+	var out types.ListEntityAliasesResponse
+	if err := getJSON(ctx, httpClient, u, "list entity aliases", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// PutEntityAlias maps alias to canonical in the memory, replacing an earlier
+// mapping of the same alias.
+func PutEntityAlias(ctx context.Context, httpClient *http.Client, baseURL, vaultID, memID, alias, canonical string) (*types.EntityAlias, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if alias == "" || canonical == "" {
+		return nil, fmt.Errorf("alias and canonical are required")
+	}
+	body, err := json.Marshal(map[string]string{"alias": alias, "canonical": canonical})
+	if err != nil {
+		return nil, err
+	}
+	u := fmt.Sprintf("%s/v0/vaults/%s/memories/%s/aliases", baseURL, vaultID, memID)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPut, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, readErr := io.ReadAll(resp.Body)
+		if readErr != nil {
+			return nil, errors.NewHTTPError(resp.StatusCode, "", "put entity alias")
+		}
+		return nil, errors.ClassifyHTTPError(resp.StatusCode, string(bodyBytes), fmt.Errorf("put entity alias failed"))
+	}
+
+	var out types.EntityAlias
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteEntityAlias removes one alias from the memory.
+func DeleteEntityAlias(ctx context.Context, httpClient *http.Client, baseURL, vaultID, memID, alias string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if alias == "" {
+		return fmt.Errorf("alias is required")
+	}
+	u := fmt.Sprintf("%s/v0/vaults/%s/memories/%s/aliases?alias=%s", baseURL, vaultID, memID, url.QueryEscape(alias))
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodDelete, u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusNoContent {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return errors.ClassifyHTTPError(resp.StatusCode, string(bodyBytes), fmt.Errorf("delete entity alias: status %d", resp.StatusCode))
+	}
+	return nil
+}
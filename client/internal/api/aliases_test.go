@@ -0,0 +1,55 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mycelian/mycelian-memory/client/internal/types"
+)
+
+func TestEntityAliases(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v0/vaults/v1/memories/m1/aliases" {
+			http.NotFound(w, r)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			_ = json.NewEncoder(w).Encode(types.ListEntityAliasesResponse{Aliases: []types.EntityAlias{{Alias: "Bob", Canonical: "Robert Smith"}}, Count: 1})
+		case http.MethodPut:
+			var body map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			_ = json.NewEncoder(w).Encode(types.EntityAlias{Alias: body["alias"], Canonical: body["canonical"]})
+		case http.MethodDelete:
+			if r.URL.Query().Get("alias") != "Bob & co" {
+				http.Error(w, `{"error":"alias not found"}`, http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	list, err := ListEntityAliases(ctx, srv.Client(), srv.URL, "v1", "m1")
+	if err != nil || list.Count != 1 || list.Aliases[0].Canonical != "Robert Smith" {
+		t.Fatalf("ListEntityAliases: %+v %v", list, err)
+	}
+	a, err := PutEntityAlias(ctx, srv.Client(), srv.URL, "v1", "m1", "Rob", "Robert Smith")
+	if err != nil || a.Alias != "Rob" || a.Canonical != "Robert Smith" {
+		t.Fatalf("PutEntityAlias: %+v %v", a, err)
+	}
+	if _, err := PutEntityAlias(ctx, srv.Client(), srv.URL, "v1", "m1", "", "Robert Smith"); err == nil {
+		t.Fatal("expected error for empty alias")
+	}
+	if err := DeleteEntityAlias(ctx, srv.Client(), srv.URL, "v1", "m1", "Bob & co"); err != nil {
+		t.Fatalf("DeleteEntityAlias: %v", err)
+	}
+	if err := DeleteEntityAlias(ctx, srv.Client(), srv.URL, "v1", "m1", "Ann"); err == nil {
+		t.Fatal("expected error for unknown alias")
+	}
+}
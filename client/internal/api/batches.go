@@ -0,0 +1,130 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/mycelian/mycelian-memory/client/internal/errors"
+	"github.com/mycelian/mycelian-memory/client/internal/types"
+)
+
+// CreateIngestionBatch registers a new ingestion batch.
+func CreateIngestionBatch(ctx context.Context, httpClient *http.Client, baseURL string, req types.CreateIngestionBatchRequest) (*types.IngestionBatch, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	u := fmt.Sprintf("%s/v0/ingestion-batches", baseURL)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	var out types.IngestionBatch
+	if err := doBatchRequest(httpClient, httpReq, http.StatusCreated, "create ingestion batch", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListIngestionBatches returns the caller's ingestion batches, newest first.
+func ListIngestionBatches(ctx context.Context, httpClient *http.Client, baseURL string) ([]types.IngestionBatch, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	u := fmt.Sprintf("%s/v0/ingestion-batches", baseURL)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var out types.ListIngestionBatchesResponse
+	if err := doBatchRequest(httpClient, httpReq, http.StatusOK, "list ingestion batches", &out); err != nil {
+		return nil, err
+	}
+	return out.Batches, nil
+}
+
+// GetIngestionBatch retrieves a batch with its current entry count.
+func GetIngestionBatch(ctx context.Context, httpClient *http.Client, baseURL, batchID string) (*types.IngestionBatch, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	u := fmt.Sprintf("%s/v0/ingestion-batches/%s", baseURL, url.PathEscape(batchID))
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var out types.IngestionBatch
+	if err := doBatchRequest(httpClient, httpReq, http.StatusOK, "get ingestion batch", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListIngestionBatchEntries lists entries written by a batch. limit <= 0 means no limit.
+func ListIngestionBatchEntries(ctx context.Context, httpClient *http.Client, baseURL, batchID string, limit int) (*types.ListEntriesResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	u := fmt.Sprintf("%s/v0/ingestion-batches/%s/entries", baseURL, url.PathEscape(batchID))
+	if limit > 0 {
+		u += fmt.Sprintf("?limit=%d", limit)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var out types.ListEntriesResponse
+	if err := doBatchRequest(httpClient, httpReq, http.StatusOK, "list ingestion batch entries", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RollbackIngestionBatch atomically deletes every entry written by the batch.
+func RollbackIngestionBatch(ctx context.Context, httpClient *http.Client, baseURL, batchID string) (*types.RollbackIngestionBatchResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	u := fmt.Sprintf("%s/v0/ingestion-batches/%s/rollback", baseURL, url.PathEscape(batchID))
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var out types.RollbackIngestionBatchResponse
+	if err := doBatchRequest(httpClient, httpReq, http.StatusOK, "rollback ingestion batch", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// doBatchRequest executes req, classifies non-want statuses and decodes the body into out.
+func doBatchRequest(httpClient *http.Client, req *http.Request, want int, op string, out any) error {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != want {
+		bodyBytes, readErr := io.ReadAll(resp.Body)
+		if readErr != nil {
+			return errors.NewHTTPError(resp.StatusCode, "", op)
+		}
+		return errors.ClassifyHTTPError(resp.StatusCode, string(bodyBytes), fmt.Errorf("%s failed", op))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mycelian/mycelian-memory/client/internal/types"
+)
+
+func TestIngestionBatches_Success(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v0/ingestion-batches":
+			var req types.CreateIngestionBatchRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(types.IngestionBatch{BatchID: "b1", SourceSystem: req.SourceSystem, Status: "open"})
+		case r.Method == http.MethodGet && r.URL.Path == "/v0/ingestion-batches":
+			_ = json.NewEncoder(w).Encode(types.ListIngestionBatchesResponse{Batches: []types.IngestionBatch{{BatchID: "b1"}}, Count: 1})
+		case r.Method == http.MethodGet && r.URL.Path == "/v0/ingestion-batches/b1":
+			_ = json.NewEncoder(w).Encode(types.IngestionBatch{BatchID: "b1", EntryCount: 2})
+		case r.Method == http.MethodGet && r.URL.Path == "/v0/ingestion-batches/b1/entries":
+			if r.URL.Query().Get("limit") != "5" {
+				t.Errorf("expected limit=5, got %q", r.URL.RawQuery)
+			}
+			_ = json.NewEncoder(w).Encode(types.ListEntriesResponse{Entries: []types.Entry{{ID: "e1", IngestionBatchID: "b1"}}, Count: 1})
+		case r.Method == http.MethodPost && r.URL.Path == "/v0/ingestion-batches/b1/rollback":
+			_ = json.NewEncoder(w).Encode(types.RollbackIngestionBatchResponse{BatchID: "b1", Status: "rolled_back", DeletedEntryIDs: []string{"e1"}, DeletedCount: 1})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+
+	b, err := CreateIngestionBatch(ctx, srv.Client(), srv.URL, types.CreateIngestionBatchRequest{SourceSystem: "mem0"})
+	if err != nil || b.BatchID != "b1" || b.SourceSystem != "mem0" {
+		t.Fatalf("CreateIngestionBatch: %+v, err=%v", b, err)
+	}
+	if lst, err := ListIngestionBatches(ctx, srv.Client(), srv.URL); err != nil || len(lst) != 1 {
+		t.Fatalf("ListIngestionBatches: %+v, err=%v", lst, err)
+	}
+	if got, err := GetIngestionBatch(ctx, srv.Client(), srv.URL, "b1"); err != nil || got.EntryCount != 2 {
+		t.Fatalf("GetIngestionBatch: %+v, err=%v", got, err)
+	}
+	if got, err := ListIngestionBatchEntries(ctx, srv.Client(), srv.URL, "b1", 5); err != nil || got.Count != 1 || got.Entries[0].IngestionBatchID != "b1" {
+		t.Fatalf("ListIngestionBatchEntries: %+v, err=%v", got, err)
+	}
+	if got, err := RollbackIngestionBatch(ctx, srv.Client(), srv.URL, "b1"); err != nil || got.DeletedCount != 1 {
+		t.Fatalf("RollbackIngestionBatch: %+v, err=%v", got, err)
+	}
+	if _, err := GetIngestionBatch(ctx, srv.Client(), srv.URL, "missing"); err == nil {
+		t.Fatal("expected error for missing batch")
+	}
+}
+
+func TestIngestionBatches_HTTPDoError(t *testing.T) {
+	t.Parallel()
+	hc := &http.Client{Transport: &errRT{}}
+	if _, err := RollbackIngestionBatch(context.Background(), hc, "http://example.com", "b1"); err == nil {
+		t.Fatal("expected Do error for RollbackIngestionBatch")
+	}
+}
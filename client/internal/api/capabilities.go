@@ -0,0 +1,39 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/mycelian/mycelian-memory/client/internal/errors"
+	"github.com/mycelian/mycelian-memory/client/internal/types"
+)
+
+// GetCapabilities fetches the features the server has enabled. Servers that
+// predate the endpoint answer 404, returned as a classified error.
+func GetCapabilities(ctx context.Context, httpClient *http.Client, baseURL string) (*types.Capabilities, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/v0/capabilities", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, errors.ClassifyHTTPError(resp.StatusCode, string(body), fmt.Errorf("capabilities: status %d", resp.StatusCode))
+	}
+	var out types.Capabilities
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
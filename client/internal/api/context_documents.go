@@ -0,0 +1,102 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/mycelian/mycelian-memory/client/internal/errors"
+	"github.com/mycelian/mycelian-memory/client/internal/types"
+)
+
+func contextDocumentsURL(baseURL, vaultID, memID string) string {
+	return fmt.Sprintf("%s/v0/vaults/%s/memories/%s/contexts/documents", baseURL, vaultID, memID)
+}
+
+// CreateContextDocument starts the upload of a full context document. The
+// response carries the server's size limits.
+func CreateContextDocument(ctx context.Context, httpClient *http.Client, baseURL, vaultID, memID string) (*types.ContextDocument, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, contextDocumentsURL(baseURL, vaultID, memID), nil)
+	if err != nil {
+		return nil, err
+	}
+	var out types.ContextDocument
+	if err := doBatchRequest(httpClient, httpReq, http.StatusCreated, "create context document", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// PutContextDocumentPart uploads part n of the document's gzip stream.
+func PutContextDocumentPart(ctx context.Context, httpClient *http.Client, baseURL, vaultID, memID, documentID string, n int, data []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/%s/parts/%d", contextDocumentsURL(baseURL, vaultID, memID), documentID, n)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/gzip")
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusNoContent {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return errors.ClassifyHTTPError(resp.StatusCode, string(bodyBytes), fmt.Errorf("upload context document part %d failed", n))
+	}
+	return nil
+}
+
+// CompleteContextDocument finishes an upload once all parts are stored.
+func CompleteContextDocument(ctx context.Context, httpClient *http.Client, baseURL, vaultID, memID, documentID string) (*types.ContextDocument, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	url := contextDocumentsURL(baseURL, vaultID, memID) + "/" + documentID + "/complete"
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	var out types.ContextDocument
+	if err := doBatchRequest(httpClient, httpReq, http.StatusOK, "complete context document", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetContextDocument fetches the full text of a completed context document.
+func GetContextDocument(ctx context.Context, httpClient *http.Client, baseURL, vaultID, memID, documentID string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, contextDocumentsURL(baseURL, vaultID, memID)+"/"+documentID, nil)
+	if err != nil {
+		return "", err
+	}
+	httpReq.Header.Set("Accept", "text/plain")
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return string(b), nil
+	case http.StatusNotFound:
+		return "", types.ErrNotFound
+	default:
+		return "", errors.ClassifyHTTPError(resp.StatusCode, string(b), fmt.Errorf("get context document failed"))
+	}
+}
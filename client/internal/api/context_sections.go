@@ -0,0 +1,112 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/mycelian/mycelian-memory/client/internal/types"
+)
+
+// Section writes are synchronous so a precondition failure reaches the
+// caller. They first wait for the memory's queued writes, which could
+// otherwise replace the context they were based on.
+
+// PutContextSections replaces the memory's context with sections.
+func PutContextSections(ctx context.Context, exec types.Executor, httpClient *http.Client, baseURL, vaultID, memID string, sections []types.ContextSection, opts types.ContextSectionsOptions) (*types.StructuredContext, error) {
+	if err := awaitConsistency(ctx, exec, memID); err != nil {
+		return nil, err
+	}
+	type section struct {
+		Name    string `json:"name"`
+		Content string `json:"content"`
+	}
+	body := struct {
+		Sections []section `json:"sections"`
+	}{make([]section, len(sections))}
+	for i, s := range sections {
+		body.Sections[i] = section{s.Name, s.Content}
+	}
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	u := fmt.Sprintf("%s/v0/vaults/%s/memories/%s/contexts", baseURL, vaultID, memID)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPut, u, bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	return putSections(httpClient, httpReq, opts, "put context sections")
+}
+
+// PutContextSection sets one section of the memory's structured context,
+// leaving the others unchanged.
+func PutContextSection(ctx context.Context, exec types.Executor, httpClient *http.Client, baseURL, vaultID, memID, name, content string, opts types.ContextSectionsOptions) (*types.StructuredContext, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, fmt.Errorf("section name is required")
+	}
+	if err := awaitConsistency(ctx, exec, memID); err != nil {
+		return nil, err
+	}
+	u := fmt.Sprintf("%s/v0/vaults/%s/memories/%s/contexts/sections/%s", baseURL, vaultID, memID, url.PathEscape(name))
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPut, u, strings.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	return putSections(httpClient, httpReq, opts, "put context section")
+}
+
+func putSections(httpClient *http.Client, httpReq *http.Request, opts types.ContextSectionsOptions, op string) (*types.StructuredContext, error) {
+	if opts.UpdatedBy != "" {
+		httpReq.Header.Set("X-Updated-By", opts.UpdatedBy)
+	}
+	if opts.IfMatch != "" {
+		httpReq.Header.Set("If-Match", `"`+opts.IfMatch+`"`)
+	}
+	var out types.StructuredContext
+	if err := doBatchRequest(httpClient, httpReq, http.StatusCreated, op, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetStructuredContext fetches the latest context with its sections.
+func GetStructuredContext(ctx context.Context, httpClient *http.Client, baseURL, vaultID, memID string) (*types.StructuredContext, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	u := fmt.Sprintf("%s/v0/vaults/%s/memories/%s/contexts?format=structured", baseURL, vaultID, memID)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	var out types.StructuredContext
+	if err := doBatchRequest(httpClient, httpReq, http.StatusOK, "get structured context", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetContextWithRecentSummaries fetches the latest context together with the
+// summaries of the memory's n newest entries.
+func GetContextWithRecentSummaries(ctx context.Context, httpClient *http.Client, baseURL, vaultID, memID string, n int) (*types.StructuredContext, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	u := fmt.Sprintf("%s/v0/vaults/%s/memories/%s/contexts?includeRecentSummaries=%d", baseURL, vaultID, memID, n)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	var out types.StructuredContext
+	if err := doBatchRequest(httpClient, httpReq, http.StatusOK, "get context with recent summaries", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mycelian/mycelian-memory/client/internal/types"
+)
+
+func TestContextSections(t *testing.T) {
+	t.Parallel()
+	var got struct {
+		path, contentType, ifMatch, updatedBy, body string
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			if r.URL.Query().Get("format") != "structured" {
+				t.Errorf("expected format=structured, got %q", r.URL.RawQuery)
+			}
+			_ = json.NewEncoder(w).Encode(types.StructuredContext{ContextID: "c2", Sections: []types.ContextSection{{Name: "Facts", LastUpdatedBy: "planner"}}})
+			return
+		}
+		b, _ := io.ReadAll(r.Body)
+		got.path, got.contentType, got.body = r.URL.EscapedPath(), r.Header.Get("Content-Type"), string(b)
+		got.ifMatch, got.updatedBy = r.Header.Get("If-Match"), r.Header.Get("X-Updated-By")
+		if got.ifMatch == `"stale"` {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(types.StructuredContext{ContextID: "c1"})
+	}))
+	defer srv.Close()
+	ctx, exec := context.Background(), &mockExec{}
+
+	out, err := PutContextSections(ctx, exec, srv.Client(), srv.URL, "v1", "m1", []types.ContextSection{{Name: "Facts", Content: "x", LastUpdatedBy: "ignored"}}, types.ContextSectionsOptions{UpdatedBy: "planner"})
+	if err != nil || out.ContextID != "c1" {
+		t.Fatalf("put sections: out=%+v err=%v", out, err)
+	}
+	if got.contentType != "application/json" || got.body != `{"sections":[{"name":"Facts","content":"x"}]}` || got.updatedBy != "planner" {
+		t.Fatalf("unexpected request: %+v", got)
+	}
+	if exec.n != 1 {
+		t.Fatalf("expected the write to await queued writes, got %d submits", exec.n)
+	}
+
+	if _, err := PutContextSection(ctx, exec, srv.Client(), srv.URL, "v1", "m1", "Open tasks", "ship", types.ContextSectionsOptions{IfMatch: "c1"}); err != nil {
+		t.Fatalf("put section: %v", err)
+	}
+	if got.path != "/v0/vaults/v1/memories/m1/contexts/sections/Open%20tasks" || got.ifMatch != `"c1"` || got.body != "ship" {
+		t.Fatalf("unexpected request: %+v", got)
+	}
+	if _, err := PutContextSection(ctx, exec, srv.Client(), srv.URL, "v1", "m1", "Facts", "y", types.ContextSectionsOptions{IfMatch: "stale"}); err == nil {
+		t.Fatal("expected an error for 412")
+	}
+
+	sc, err := GetStructuredContext(ctx, srv.Client(), srv.URL, "v1", "m1")
+	if err != nil || sc.ContextID != "c2" || len(sc.Sections) != 1 || sc.Sections[0].LastUpdatedBy != "planner" {
+		t.Fatalf("get structured: out=%+v err=%v", sc, err)
+	}
+}
+
+func TestGetContextWithRecentSummaries(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("includeRecentSummaries") != "5" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(types.StructuredContext{ContextID: "c1", Context: "notes", RecentEntries: []types.EntrySummary{{EntryID: "e2", Summary: "shipped"}}})
+	}))
+	defer srv.Close()
+	out, err := GetContextWithRecentSummaries(context.Background(), srv.Client(), srv.URL, "v1", "m1", 5)
+	if err != nil || out.Context != "notes" || len(out.RecentEntries) != 1 || out.RecentEntries[0].Summary != "shipped" {
+		t.Fatalf("GetContextWithRecentSummaries: %+v %v", out, err)
+	}
+}
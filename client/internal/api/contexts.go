@@ -6,7 +6,11 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 
+	"github.com/mycelian/mycelian-memory/client/internal/errors"
 	"github.com/mycelian/mycelian-memory/client/internal/job"
 	"github.com/mycelian/mycelian-memory/client/internal/types"
 )
@@ -19,10 +23,16 @@ import (
 // This ensures FIFO ordering per memory and provides offline resilience.
 // CRITICAL: This MUST preserve the async executor pattern!
 func PutContext(ctx context.Context, exec types.Executor, httpClient *http.Client, baseURL, vaultID, memID string, doc string) (*types.EnqueueAck, error) {
+	return PutContextNotify(ctx, exec, httpClient, baseURL, vaultID, memID, doc, nil)
+}
+
+// PutContextNotify is PutContext with a callback run once the write has
+// succeeded or failed permanently, like AddEntryNotify. onDone may be nil.
+func PutContextNotify(ctx context.Context, exec types.Executor, httpClient *http.Client, baseURL, vaultID, memID string, doc string, onDone func(err error)) (*types.EnqueueAck, error) {
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
-	putJob := job.New(func(jobCtx context.Context) error {
+	put := func(jobCtx context.Context) error {
 		url := fmt.Sprintf("%s/v0/vaults/%s/memories/%s/contexts", baseURL, vaultID, memID)
 		httpReq, err := http.NewRequestWithContext(jobCtx, http.MethodPut, url, bytes.NewBufferString(doc))
 		if err != nil {
@@ -35,9 +45,17 @@ func PutContext(ctx context.Context, exec types.Executor, httpClient *http.Clien
 		}
 		defer func() { _ = resp.Body.Close() }()
 		if resp.StatusCode != http.StatusCreated {
-			return fmt.Errorf("put context: status %d", resp.StatusCode)
+			bodyBytes, _ := io.ReadAll(resp.Body)
+			return errors.ClassifyHTTPError(resp.StatusCode, string(bodyBytes), fmt.Errorf("put context: status %d", resp.StatusCode))
 		}
 		return nil
+	}
+	putJob := job.New(func(jobCtx context.Context) error {
+		err := put(jobCtx)
+		if onDone != nil && (err == nil || errors.IsIrrecoverable(err)) {
+			onDone(err)
+		}
+		return err
 	})
 	if err := exec.Submit(ctx, memID, putJob); err != nil {
 		return nil, err
@@ -45,33 +63,74 @@ func PutContext(ctx context.Context, exec types.Executor, httpClient *http.Clien
 	return &types.EnqueueAck{MemoryID: memID, Status: "enqueued"}, nil
 }
 
+// PutContextChecked stores a plain-text context synchronously, after the
+// memory's queued writes, asking the server to first check it against the
+// memory's checkEntries newest entries (<= 0 for the server default).
+func PutContextChecked(ctx context.Context, exec types.Executor, httpClient *http.Client, baseURL, vaultID, memID, doc string, checkEntries int) (*types.CheckedContext, error) {
+	if err := awaitConsistency(ctx, exec, memID); err != nil {
+		return nil, err
+	}
+	q := url.Values{"check": {"true"}}
+	if checkEntries > 0 {
+		q.Set("checkEntries", strconv.Itoa(checkEntries))
+	}
+	u := fmt.Sprintf("%s/v0/vaults/%s/memories/%s/contexts?%s", baseURL, vaultID, memID, q.Encode())
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPut, u, strings.NewReader(doc))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	var out types.CheckedContext
+	if err := doBatchRequest(httpClient, httpReq, http.StatusCreated, "put context", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
 // GetLatestContext fetches the latest context as plain text.
 func GetLatestContext(ctx context.Context, httpClient *http.Client, baseURL, vaultID, memID string) (string, error) {
-	if err := ctx.Err(); err != nil {
+	res, err := GetLatestContextIfChanged(ctx, httpClient, baseURL, vaultID, memID, "")
+	if err != nil {
 		return "", err
 	}
+	return res.Context, nil
+}
+
+// GetLatestContextIfChanged fetches the latest context unless its ETag equals
+// etag, in which case the server answers 304 and the result has NotModified
+// set and an empty Context. An empty etag always fetches.
+func GetLatestContextIfChanged(ctx context.Context, httpClient *http.Client, baseURL, vaultID, memID, etag string) (*types.ContextFetch, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	url := fmt.Sprintf("%s/v0/vaults/%s/memories/%s/contexts", baseURL, vaultID, memID)
 	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	httpReq.Header.Set("Accept", "text/plain")
+	if etag != "" {
+		httpReq.Header.Set("If-None-Match", etag)
+	}
 	resp, err := httpClient.Do(httpReq)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	defer func() { _ = resp.Body.Close() }()
-	if resp.StatusCode == http.StatusNotFound {
-		return "", types.ErrNotFound
-	}
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("get context text: status %d", resp.StatusCode)
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return &types.ContextFetch{ETag: etag, NotModified: true}, nil
+	case http.StatusNotFound:
+		return nil, types.ErrNotFound
+	case http.StatusOK:
+	default:
+		return nil, fmt.Errorf("get context text: status %d", resp.StatusCode)
 	}
 	b, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	return string(b), nil
+	return &types.ContextFetch{Context: string(b), ETag: resp.Header.Get("ETag")}, nil
 }
 
 // DeleteContext removes a context snapshot by contextId synchronously.
@@ -118,3 +118,25 @@ func TestPutContext_InvalidIDs(t *testing.T) {
 		t.Fatal("expected validation error for empty memoryId")
 	}
 }
+
+func TestGetLatestContextIfChanged(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"c1"`)
+		if r.Header.Get("If-None-Match") == `"c1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer srv.Close()
+
+	first, err := GetLatestContextIfChanged(context.Background(), srv.Client(), srv.URL, "v1", "m1", "")
+	if err != nil || first.NotModified || first.Context != "hello" || first.ETag != `"c1"` {
+		t.Fatalf("first fetch: %+v err=%v", first, err)
+	}
+	second, err := GetLatestContextIfChanged(context.Background(), srv.Client(), srv.URL, "v1", "m1", first.ETag)
+	if err != nil || !second.NotModified || second.Context != "" || second.ETag != `"c1"` {
+		t.Fatalf("conditional fetch: %+v err=%v", second, err)
+	}
+}
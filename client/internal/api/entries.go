@@ -7,7 +7,10 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/mycelian/mycelian-memory/client/internal/errors"
 	"github.com/mycelian/mycelian-memory/client/internal/job"
@@ -20,26 +23,33 @@ import (
 // This ensures FIFO ordering per memory and provides offline resilience.
 // CRITICAL: This MUST preserve the async executor pattern!
 func AddEntry(ctx context.Context, exec types.Executor, httpClient *http.Client, baseURL, vaultID, memID string, req types.AddEntryRequest) (*types.EnqueueAck, error) {
+	return AddEntryNotify(ctx, exec, httpClient, baseURL, vaultID, memID, req, nil)
+}
+
+// AddEntryNotify is AddEntry with a callback run once the write has been
+// attempted: with the entry as created by the server on success, or the
+// error. onDone may be nil.
+func AddEntryNotify(ctx context.Context, exec types.Executor, httpClient *http.Client, baseURL, vaultID, memID string, req types.AddEntryRequest, onDone func(created *types.Entry, err error)) (*types.EnqueueAck, error) {
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
 
-	// Create job that makes the actual HTTP request
-	addJob := job.New(func(jobCtx context.Context) error {
+	// post makes the actual HTTP request and returns the created entry
+	post := func(jobCtx context.Context) (*types.Entry, error) {
 		// CRITICAL: Explicit logging to trace job execution
 		fmt.Fprintf(os.Stderr, "🚀 ADD_ENTRY JOB STARTING: url=%s\n", baseURL)
 
 		body, err := json.Marshal(req)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "❌ ADD_ENTRY JSON marshal error: %v\n", err)
-			return err
+			return nil, err
 		}
 
 		url := fmt.Sprintf("%s/v0/vaults/%s/memories/%s/entries", baseURL, vaultID, memID)
 		httpReq, err := http.NewRequestWithContext(jobCtx, http.MethodPost, url, bytes.NewBuffer(body))
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "❌ ADD_ENTRY request creation error: %v\n", err)
-			return err
+			return nil, err
 		}
 		httpReq.Header.Set("Content-Type", "application/json")
 
@@ -48,7 +58,7 @@ func AddEntry(ctx context.Context, exec types.Executor, httpClient *http.Client,
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "❌ ADD_ENTRY HTTP Do error: %v\n", err)
 			// Network errors are recoverable
-			return errors.NewNetworkError("add entry", err)
+			return nil, errors.NewNetworkError("add entry", err)
 		}
 		defer func() { _ = resp.Body.Close() }()
 
@@ -59,17 +69,29 @@ func AddEntry(ctx context.Context, exec types.Executor, httpClient *http.Client,
 			if readErr != nil {
 				fmt.Fprintf(os.Stderr, "❌ ADD_ENTRY error body read failed: %v\n", readErr)
 				// Still classify the HTTP error even if we can't read the body
-				return errors.NewHTTPError(resp.StatusCode, "", "add entry")
+				return nil, errors.NewHTTPError(resp.StatusCode, "", "add entry")
 			}
 
 			// Create classified error with full response details
 			errorMsg := fmt.Sprintf("add entry failed: status %d, body: %s", resp.StatusCode, string(bodyBytes))
 			fmt.Fprintf(os.Stderr, "❌ ADD_ENTRY CLASSIFIED ERROR: %s\n", errorMsg)
-			return errors.ClassifyHTTPError(resp.StatusCode, string(bodyBytes), fmt.Errorf("add entry failed"))
+			return nil, errors.ClassifyHTTPError(resp.StatusCode, string(bodyBytes), fmt.Errorf("add entry failed"))
 		}
 
 		fmt.Fprintf(os.Stderr, "✅ ADD_ENTRY HTTP job completed successfully\n")
-		return nil
+		var created types.Entry
+		_ = json.NewDecoder(resp.Body).Decode(&created)
+		return &created, nil
+	}
+
+	// Create job that makes the request. Recoverable failures are retried by
+	// the executor, so onDone only hears about success and permanent failure.
+	addJob := job.New(func(jobCtx context.Context) error {
+		created, err := post(jobCtx)
+		if onDone != nil && (err == nil || errors.IsIrrecoverable(err)) {
+			onDone(created, err)
+		}
+		return err
 	})
 
 	// Submit job to executor for FIFO ordering per memory
@@ -81,21 +103,22 @@ func AddEntry(ctx context.Context, exec types.Executor, httpClient *http.Client,
 	return &types.EnqueueAck{MemoryID: memID, Status: "enqueued"}, nil
 }
 
-// ListEntries retrieves entries within a memory using the full prefix (synchronous).
-func ListEntries(ctx context.Context, httpClient *http.Client, baseURL, vaultID, memID string, params map[string]string) (*types.ListEntriesResponse, error) {
+// ListEntries retrieves entries within a memory using the full prefix
+// (synchronous). Each tags pair is sent as a tag=key=value parameter.
+func ListEntries(ctx context.Context, httpClient *http.Client, baseURL, vaultID, memID string, params, tags map[string]string) (*types.ListEntriesResponse, error) {
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
-	query := ""
-	first := true
+	q := url.Values{}
 	for k, v := range params {
-		if first {
-			query += "?"
-			first = false
-		} else {
-			query += "&"
-		}
-		query += fmt.Sprintf("%s=%s", k, v)
+		q.Set(k, v)
+	}
+	for k, v := range tags {
+		q.Add("tag", k+"="+v)
+	}
+	query := ""
+	if len(q) > 0 {
+		query = "?" + q.Encode()
 	}
 	url := fmt.Sprintf("%s/v0/vaults/%s/memories/%s/entries%s", baseURL, vaultID, memID, query)
 	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
@@ -118,6 +141,39 @@ func ListEntries(ctx context.Context, httpClient *http.Client, baseURL, vaultID,
 	return &lr, nil
 }
 
+// ExportEntries reads every entry of a memory, oldest first, from the JSON
+// Lines export. withEmbeddings adds each indexed entry's stored vector.
+func ExportEntries(ctx context.Context, httpClient *http.Client, baseURL, vaultID, memID string, withEmbeddings bool) ([]types.ExportedEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/v0/vaults/%s/memories/%s/export?embeddings=%t", baseURL, vaultID, memID, withEmbeddings)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, errors.ClassifyHTTPError(resp.StatusCode, string(body), fmt.Errorf("export entries failed"))
+	}
+	var out []types.ExportedEntry
+	dec := json.NewDecoder(resp.Body)
+	for dec.More() {
+		var e types.ExportedEntry
+		if err := dec.Decode(&e); err != nil {
+			return nil, fmt.Errorf("export entries: line %d: %w", len(out)+1, err)
+		}
+		out = append(out, e)
+	}
+	return out, nil
+}
+
 // GetEntry retrieves a single entry by entryId within a memory (synchronous).
 func GetEntry(ctx context.Context, httpClient *http.Client, baseURL, vaultID, memID, entryID string) (*types.Entry, error) {
 	if err := ctx.Err(); err != nil {
@@ -145,6 +201,203 @@ func GetEntry(ctx context.Context, httpClient *http.Client, baseURL, vaultID, me
 	return &e, nil
 }
 
+// RecordEntrySignal marks an entry useful, incorrect or outdated and returns the
+// entry with its updated signal counters.
+func RecordEntrySignal(ctx context.Context, httpClient *http.Client, baseURL, vaultID, memID, entryID, signal string) (*types.Entry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(map[string]string{"signal": signal})
+	if err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/v0/vaults/%s/memories/%s/entries/%s/signals", baseURL, vaultID, memID, entryID)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, readErr := io.ReadAll(resp.Body)
+		if readErr != nil {
+			return nil, errors.NewHTTPError(resp.StatusCode, "", "record entry signal")
+		}
+		return nil, errors.ClassifyHTTPError(resp.StatusCode, string(bodyBytes), fmt.Errorf("record entry signal failed"))
+	}
+	var e types.Entry
+	if err := json.NewDecoder(resp.Body).Decode(&e); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// CorrectEntry writes req as the correction of entryID.
+func CorrectEntry(ctx context.Context, httpClient *http.Client, baseURL, vaultID, memID, entryID string, req types.CorrectEntryRequest) (*types.Entry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if entryID == "" {
+		return nil, fmt.Errorf("entryID is required")
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/v0/vaults/%s/memories/%s/entries/%s/corrections", baseURL, vaultID, memID, entryID)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	var out types.Entry
+	if err := doBatchRequest(httpClient, httpReq, http.StatusCreated, "correct entry", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SimilarEntries lists up to topK entries nearest to entryID within its
+// memory, or its whole vault when scope is "vault". Zero topK and empty
+// scope use the server defaults.
+func SimilarEntries(ctx context.Context, httpClient *http.Client, baseURL, vaultID, memID, entryID, scope string, topK int) (*types.SimilarEntries, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	q := url.Values{}
+	if scope != "" {
+		q.Set("scope", scope)
+	}
+	if topK > 0 {
+		q.Set("topK", strconv.Itoa(topK))
+	}
+	u := fmt.Sprintf("%s/v0/vaults/%s/memories/%s/entries/%s/similar", baseURL, vaultID, memID, entryID)
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, readErr := io.ReadAll(resp.Body)
+		if readErr != nil {
+			return nil, errors.NewHTTPError(resp.StatusCode, "", "similar entries")
+		}
+		return nil, errors.ClassifyHTTPError(resp.StatusCode, string(bodyBytes), fmt.Errorf("similar entries failed"))
+	}
+	var out types.SimilarEntries
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AddEntries creates entries in one request after pending writes to the
+// memory complete.
+func AddEntries(ctx context.Context, exec types.Executor, httpClient *http.Client, baseURL, vaultID, memID string, entries []types.AddEntryRequest) (*types.AddEntriesResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := awaitConsistency(ctx, exec, memID); err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(struct {
+		Entries []types.AddEntryRequest `json:"entries"`
+	}{entries})
+	if err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/v0/vaults/%s/memories/%s/entries:batch", baseURL, vaultID, memID)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusCreated {
+		bodyBytes, readErr := io.ReadAll(resp.Body)
+		if readErr != nil {
+			return nil, errors.NewHTTPError(resp.StatusCode, "", "add entries")
+		}
+		return nil, errors.ClassifyHTTPError(resp.StatusCode, string(bodyBytes), fmt.Errorf("add entries failed"))
+	}
+	var out types.AddEntriesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// PatchEntryTags applies one tag patch to the memory's entries selected by
+// req. It first awaits consistency so pending writes are patched too.
+func PatchEntryTags(ctx context.Context, exec types.Executor, httpClient *http.Client, baseURL, vaultID, memID string, req types.PatchEntryTagsRequest) (*types.PatchEntryTagsResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := awaitConsistency(ctx, exec, memID); err != nil {
+		return nil, err
+	}
+
+	var in struct {
+		Filter struct {
+			IDs       []string   `json:"ids,omitempty"`
+			SessionID string     `json:"sessionId,omitempty"`
+			Since     *time.Time `json:"since,omitempty"`
+			Until     *time.Time `json:"until,omitempty"`
+		} `json:"filter"`
+		Set   map[string]string `json:"set,omitempty"`
+		Unset []string          `json:"unset,omitempty"`
+	}
+	in.Filter.IDs, in.Filter.SessionID, in.Filter.Since, in.Filter.Until = req.IDs, req.SessionID, req.Since, req.Until
+	in.Set, in.Unset = req.Set, req.Unset
+	body, err := json.Marshal(in)
+	if err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/v0/vaults/%s/memories/%s/entries:tags", baseURL, vaultID, memID)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPatch, url, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, readErr := io.ReadAll(resp.Body)
+		if readErr != nil {
+			return nil, errors.NewHTTPError(resp.StatusCode, "", "patch entry tags")
+		}
+		return nil, errors.ClassifyHTTPError(resp.StatusCode, string(bodyBytes), fmt.Errorf("patch entry tags failed"))
+	}
+	var out types.PatchEntryTagsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
 // DeleteEntry removes an entry by ID from a memory synchronously.
 // It first awaits consistency to ensure all pending writes complete, then performs the HTTP DELETE.
 func DeleteEntry(ctx context.Context, exec types.Executor, httpClient *http.Client, baseURL, vaultID, memID, entryID string) error {
@@ -168,7 +421,8 @@ func DeleteEntry(ctx context.Context, exec types.Executor, httpClient *http.Clie
 	}
 	defer func() { _ = resp.Body.Close() }()
 	if resp.StatusCode != http.StatusNoContent {
-		return fmt.Errorf("delete entry: status %d", resp.StatusCode)
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return errors.ClassifyHTTPError(resp.StatusCode, string(bodyBytes), fmt.Errorf("delete entry: status %d", resp.StatusCode))
 	}
 	return nil
 }
@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -65,7 +66,7 @@ func TestEntries_NonOKStatuses(t *testing.T) {
 	if _, err := AddEntry(context.Background(), exec, srv.Client(), srv.URL, "v1", "m1", types.AddEntryRequest{RawEntry: "hi"}); err == nil {
 		t.Fatal("expected error for AddEntry non-201")
 	}
-	if _, err := ListEntries(context.Background(), srv.Client(), srv.URL, "v1", "m1", nil); err == nil {
+	if _, err := ListEntries(context.Background(), srv.Client(), srv.URL, "v1", "m1", nil, nil); err == nil {
 		t.Fatal("expected error for ListEntries non-200")
 	}
 	if err := DeleteEntry(context.Background(), exec, srv.Client(), srv.URL, "v1", "m1", "e1"); err == nil {
@@ -80,7 +81,7 @@ func TestListEntries_DecodeError(t *testing.T) {
 		_, _ = w.Write([]byte("{bad json"))
 	}))
 	defer srv.Close()
-	if _, err := ListEntries(context.Background(), srv.Client(), srv.URL, "v1", "m1", nil); err == nil {
+	if _, err := ListEntries(context.Background(), srv.Client(), srv.URL, "v1", "m1", nil, nil); err == nil {
 		t.Fatal("expected decode error for ListEntries")
 	}
 }
@@ -89,7 +90,7 @@ func TestListEntries_DecodeError(t *testing.T) {
 func TestListEntries_HTTPDoError(t *testing.T) {
 	t.Parallel()
 	hc := &http.Client{Transport: &errRT{}}
-	if _, err := ListEntries(context.Background(), hc, "http://example.com", "v1", "m1", nil); err == nil {
+	if _, err := ListEntries(context.Background(), hc, "http://example.com", "v1", "m1", nil, nil); err == nil {
 		t.Fatal("expected http Do error for ListEntries")
 	}
 }
@@ -100,7 +101,7 @@ func TestListEntries_CtxCanceled(t *testing.T) {
 	cancel()
 	dummy := httptest.NewServer(http.NotFoundHandler())
 	defer dummy.Close()
-	if _, err := ListEntries(ctx, dummy.Client(), dummy.URL, "v1", "m1", nil); err == nil {
+	if _, err := ListEntries(ctx, dummy.Client(), dummy.URL, "v1", "m1", nil, nil); err == nil {
 		t.Fatal("expected context canceled for ListEntries")
 	}
 }
@@ -112,11 +113,47 @@ func TestListEntries_WithParams(t *testing.T) {
 		_, _ = w.Write([]byte(`{"entries":[],"count":0}`))
 	}))
 	defer srv.Close()
-	if _, err := ListEntries(context.Background(), srv.Client(), srv.URL, "v1", "m1", map[string]string{"limit": "2", "offset": "1"}); err != nil {
+	if _, err := ListEntries(context.Background(), srv.Client(), srv.URL, "v1", "m1", map[string]string{"limit": "2", "offset": "1"}, nil); err != nil {
 		t.Fatalf("ListEntries with params: %v", err)
 	}
 }
 
+func TestListEntries_Tags(t *testing.T) {
+	t.Parallel()
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.Query()["tag"]
+		_, _ = w.Write([]byte(`{"entries":[],"count":0}`))
+	}))
+	defer srv.Close()
+	if _, err := ListEntries(context.Background(), srv.Client(), srv.URL, "v1", "m1", nil, map[string]string{"project": "a&b"}); err != nil {
+		t.Fatalf("ListEntries with tags: %v", err)
+	}
+	if len(got) != 1 || got[0] != "project=a&b" {
+		t.Fatalf("tag params = %q, want [project=a&b]", got)
+	}
+}
+
+func TestListEntries_PageToken(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("pageToken") == "" {
+			_, _ = w.Write([]byte(`{"entries":[{"entryId":"e2"}],"count":1,"nextPageToken":"tok"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"entries":[],"count":0}`))
+	}))
+	defer srv.Close()
+	first, err := ListEntries(context.Background(), srv.Client(), srv.URL, "v1", "m1", map[string]string{"limit": "1"}, nil)
+	if err != nil || first.NextPageToken != "tok" {
+		t.Fatalf("first page: %+v %v", first, err)
+	}
+	last, err := ListEntries(context.Background(), srv.Client(), srv.URL, "v1", "m1", map[string]string{"limit": "1", "pageToken": first.NextPageToken}, nil)
+	if err != nil || last.NextPageToken != "" {
+		t.Fatalf("last page: %+v %v", last, err)
+	}
+}
+
 func TestAddEntry_SubmitError(t *testing.T) {
 	t.Parallel()
 	// Server won't be called because Submit fails
@@ -160,3 +197,78 @@ func TestDeleteEntry_CtxCanceled(t *testing.T) {
 		t.Fatal("expected context canceled for DeleteEntry")
 	}
 }
+
+func TestRecordEntrySignal(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v0/vaults/v1/memories/m1/entries/e1/signals" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["signal"] == "bogus" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"entryId":"e1","usefulCount":2}`))
+	}))
+	defer srv.Close()
+
+	e, err := RecordEntrySignal(context.Background(), srv.Client(), srv.URL, "v1", "m1", "e1", types.SignalUseful)
+	if err != nil || e.ID != "e1" || e.UsefulCount != 2 {
+		t.Fatalf("RecordEntrySignal: e=%+v err=%v", e, err)
+	}
+	if _, err := RecordEntrySignal(context.Background(), srv.Client(), srv.URL, "v1", "m1", "e1", "bogus"); err == nil {
+		t.Fatalf("expected error for 400")
+	}
+}
+
+func TestSimilarEntries(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/v0/vaults/v1/memories/m1/entries/e1/similar" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if r.URL.Query().Get("topK") == "500" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.URL.Query().Get("scope") != "vault" || r.URL.Query().Get("topK") != "3" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		_, _ = w.Write([]byte(`{"entryId":"e1","memoryId":"m1","scope":"vault","topK":3,"vectorSource":"stored","scoring":"cosine","entries":[{"entryId":"e2","memoryId":"m2","score":0.8}]}`))
+	}))
+	defer srv.Close()
+
+	out, err := SimilarEntries(context.Background(), srv.Client(), srv.URL, "v1", "m1", "e1", "vault", 3)
+	if err != nil || out.Scoring != "cosine" || len(out.Entries) != 1 || out.Entries[0].ID != "e2" || out.Entries[0].Score != 0.8 {
+		t.Fatalf("SimilarEntries: out=%+v err=%v", out, err)
+	}
+	if _, err := SimilarEntries(context.Background(), srv.Client(), srv.URL, "v1", "m1", "e1", "", 500); err == nil {
+		t.Fatalf("expected error for 400")
+	}
+}
+
+func TestExportEntries_DecodesJSONLines(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v0/vaults/v1/memories/m1/export" || r.URL.Query().Get("embeddings") != "true" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		_, _ = w.Write([]byte(`{"entryId":"e1","rawEntry":"a","metadata":{"k":"v"},"embedding":{"model":"nomic-embed-text","dimension":2,"vector":[0.5,1]}}` + "\n" +
+			`{"entryId":"e2","rawEntry":"b"}` + "\n"))
+	}))
+	defer srv.Close()
+
+	out, err := ExportEntries(context.Background(), srv.Client(), srv.URL, "v1", "m1", true)
+	if err != nil || len(out) != 2 {
+		t.Fatalf("ExportEntries: %d %v", len(out), err)
+	}
+	if e := out[0]; e.ID != "e1" || e.Metadata["k"] != "v" || e.Embedding == nil || e.Embedding.Dimension != 2 || e.Embedding.Vector[1] != 1 {
+		t.Fatalf("unexpected first entry: %+v", e)
+	}
+	if out[1].Embedding != nil {
+		t.Fatalf("second entry should have no embedding")
+	}
+}
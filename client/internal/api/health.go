@@ -0,0 +1,39 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/mycelian/mycelian-memory/client/internal/types"
+)
+
+// GetHealth fetches the structured service health report.
+// The endpoint always answers 200; degraded dependencies are reported in the body.
+func GetHealth(ctx context.Context, httpClient *http.Client, baseURL string) (*types.HealthResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/v0/health", baseURL)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("health: status %d", resp.StatusCode)
+	}
+
+	var hr types.HealthResponse
+	if err := json.NewDecoder(resp.Body).Decode(&hr); err != nil {
+		return nil, err
+	}
+	return &hr, nil
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetHealth_Success(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v0/health" {
+			t.Fatalf("unexpected path %s", r.URL.Path)
+		}
+		_, _ = w.Write([]byte(`{"status":"healthy","timestamp":"2025-01-01T12:00:00Z","schemaVersion":"1","components":{"store":"healthy"}}`))
+	}))
+	defer srv.Close()
+	got, err := GetHealth(context.Background(), srv.Client(), srv.URL)
+	if err != nil {
+		t.Fatalf("GetHealth: %v", err)
+	}
+	if got.Status != "healthy" || got.SchemaVersion != "1" || got.Components["store"] != "healthy" || got.Timestamp.IsZero() {
+		t.Fatalf("unexpected health: %+v", got)
+	}
+}
+
+func TestGetHealth_Errors(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+	if _, err := GetHealth(context.Background(), srv.Client(), srv.URL); err == nil {
+		t.Fatal("expected error for non-OK status")
+	}
+	hc := &http.Client{Transport: &errRT{}}
+	if _, err := GetHealth(context.Background(), hc, "http://example.com"); err == nil {
+		t.Fatal("expected Do error")
+	}
+}
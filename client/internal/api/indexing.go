@@ -0,0 +1,73 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/mycelian/mycelian-memory/client/internal/errors"
+	"github.com/mycelian/mycelian-memory/client/internal/types"
+)
+
+// GetIndexStatus reads the index status of the memory's listed entries and
+// contexts.
+func GetIndexStatus(ctx context.Context, httpClient *http.Client, baseURL, vaultID, memoryID string, ids []string) (map[string]types.IndexStatus, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	q := url.Values{}
+	for _, id := range ids {
+		q.Add("id", id)
+	}
+	var out struct {
+		Statuses map[string]types.IndexStatus `json:"statuses"`
+	}
+	u := fmt.Sprintf("%s/v0/vaults/%s/memories/%s/index-status?%s", baseURL, vaultID, memoryID, q.Encode())
+	if err := getJSON(ctx, httpClient, u, "get index status", &out); err != nil {
+		return nil, err
+	}
+	return out.Statuses, nil
+}
+
+// RetryIndexing re-enqueues the memory's failed index writes, only of ids
+// when non-empty.
+func RetryIndexing(ctx context.Context, httpClient *http.Client, baseURL, vaultID, memoryID string, ids []string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	body, err := json.Marshal(map[string][]string{"ids": ids})
+	if err != nil {
+		return 0, err
+	}
+	u := fmt.Sprintf("%s/v0/vaults/%s/memories/%s/index:retry", baseURL, vaultID, memoryID)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, readErr := io.ReadAll(resp.Body)
+		if readErr != nil {
+			return 0, errors.NewHTTPError(resp.StatusCode, "", "retry indexing")
+		}
+		return 0, errors.ClassifyHTTPError(resp.StatusCode, string(bodyBytes), fmt.Errorf("retry indexing failed"))
+	}
+
+	var out struct {
+		Requeued int `json:"requeued"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return 0, err
+	}
+	return out.Requeued, nil
+}
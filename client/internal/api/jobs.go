@@ -0,0 +1,67 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/mycelian/mycelian-memory/client/internal/types"
+)
+
+// GetJob returns a background job with its progress.
+func GetJob(ctx context.Context, httpClient *http.Client, baseURL, jobID string) (*types.Job, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if jobID == "" {
+		return nil, fmt.Errorf("jobID is required")
+	}
+	var out types.Job
+	if err := getJSON(ctx, httpClient, fmt.Sprintf("%s/v0/jobs/%s", baseURL, jobID), "get job", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CancelJob cancels a queued job or asks a running one to stop.
+func CancelJob(ctx context.Context, httpClient *http.Client, baseURL, jobID string) (*types.Job, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if jobID == "" {
+		return nil, fmt.Errorf("jobID is required")
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/v0/jobs/%s:cancel", baseURL, jobID), nil)
+	if err != nil {
+		return nil, err
+	}
+	var out types.Job
+	if err := doBatchRequest(httpClient, httpReq, http.StatusOK, "cancel job", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteEntries queues a job deleting the memory's entries selected by req.
+func DeleteEntries(ctx context.Context, httpClient *http.Client, baseURL, vaultID, memoryID string, req types.DeleteEntriesRequest) (*types.Job, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	u := fmt.Sprintf("%s/v0/vaults/%s/memories/%s/entries:delete", baseURL, vaultID, memoryID)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	var out types.Job
+	if err := doBatchRequest(httpClient, httpReq, http.StatusAccepted, "delete entries", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
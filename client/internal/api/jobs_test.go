@@ -0,0 +1,48 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mycelian/mycelian-memory/client/internal/types"
+)
+
+func TestJobs(t *testing.T) {
+	t.Parallel()
+	var deleteBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v0/vaults/v1/memories/m1/entries:delete":
+			b, _ := io.ReadAll(r.Body)
+			deleteBody = string(b)
+			w.WriteHeader(http.StatusAccepted)
+			_ = json.NewEncoder(w).Encode(types.Job{ID: "j1", Kind: "deleteEntries", Status: types.JobQueued})
+		case r.Method == http.MethodGet && r.URL.Path == "/v0/jobs/j1":
+			_ = json.NewEncoder(w).Encode(types.Job{ID: "j1", Status: types.JobRunning, Done: 2, Total: 5})
+		case r.Method == http.MethodPost && r.URL.Path == "/v0/jobs/j1:cancel":
+			_ = json.NewEncoder(w).Encode(types.Job{ID: "j1", Status: types.JobRunning, CancelRequested: true})
+		default:
+			http.Error(w, `{"error":"job j2 already finished"}`, http.StatusConflict)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	job, err := DeleteEntries(ctx, srv.Client(), srv.URL, "v1", "m1", types.DeleteEntriesRequest{SessionID: "s1"})
+	if err != nil || job.ID != "j1" || job.Finished() || deleteBody != `{"sessionId":"s1"}` {
+		t.Fatalf("DeleteEntries: %+v body=%s err=%v", job, deleteBody, err)
+	}
+	if job, err := GetJob(ctx, srv.Client(), srv.URL, "j1"); err != nil || job.Done != 2 || job.Total != 5 {
+		t.Fatalf("GetJob: %+v %v", job, err)
+	}
+	if job, err := CancelJob(ctx, srv.Client(), srv.URL, "j1"); err != nil || !job.CancelRequested {
+		t.Fatalf("CancelJob: %+v %v", job, err)
+	}
+	if _, err := CancelJob(ctx, srv.Client(), srv.URL, "j2"); err == nil {
+		t.Fatal("expected error cancelling a finished job")
+	}
+}
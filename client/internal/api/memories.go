@@ -5,8 +5,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 
+	"github.com/mycelian/mycelian-memory/client/internal/errors"
 	"github.com/mycelian/mycelian-memory/client/internal/types"
 )
 
@@ -55,8 +58,8 @@ func ListMemories(ctx context.Context, httpClient *http.Client, baseURL, vaultID
 		return nil, err
 	}
 	// Client-side validation removed; server is the authority
-	url := fmt.Sprintf("%s/v0/vaults/%s/memories", baseURL, vaultID)
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	u := fmt.Sprintf("%s/v0/vaults/%s/memories", baseURL, url.PathEscape(vaultID))
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -83,8 +86,8 @@ func GetMemory(ctx context.Context, httpClient *http.Client, baseURL, vaultID, m
 		return nil, err
 	}
 	// Client-side validation removed; server is the authority
-	url := fmt.Sprintf("%s/v0/vaults/%s/memories/%s", baseURL, vaultID, memoryID)
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	u := fmt.Sprintf("%s/v0/vaults/%s/memories/%s", baseURL, url.PathEscape(vaultID), url.PathEscape(memoryID))
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -126,3 +129,170 @@ func DeleteMemory(ctx context.Context, httpClient *http.Client, baseURL, vaultID
 	}
 	return nil
 }
+
+// SetMemoryAppendOnly marks the memory append-only. The flag cannot be cleared.
+func SetMemoryAppendOnly(ctx context.Context, httpClient *http.Client, baseURL, vaultID, memoryID string) (*types.Memory, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/v0/vaults/%s/memories/%s/append-only", baseURL, vaultID, memoryID)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewBufferString(`{"appendOnly":true}`))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, readErr := io.ReadAll(resp.Body)
+		if readErr != nil {
+			return nil, errors.NewHTTPError(resp.StatusCode, "", "set memory append-only")
+		}
+		return nil, errors.ClassifyHTTPError(resp.StatusCode, string(bodyBytes), fmt.Errorf("set memory append-only failed"))
+	}
+
+	var mem types.Memory
+	if err := json.NewDecoder(resp.Body).Decode(&mem); err != nil {
+		return nil, err
+	}
+	return &mem, nil
+}
+
+// SetMemorySearchBoost replaces how the memory's hits are weighed in
+// vault-scope searches.
+func SetMemorySearchBoost(ctx context.Context, httpClient *http.Client, baseURL, vaultID, memoryID string, b types.SearchBoost) (*types.Memory, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/v0/vaults/%s/memories/%s/search-boost", baseURL, vaultID, memoryID)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	var mem types.Memory
+	if err := doBatchRequest(httpClient, httpReq, http.StatusOK, "set memory search boost", &mem); err != nil {
+		return nil, err
+	}
+	return &mem, nil
+}
+
+// SetMemoryEntryRoles replaces the roles the memory requires of new entries.
+func SetMemoryEntryRoles(ctx context.Context, httpClient *http.Client, baseURL, vaultID, memoryID string, roles []string) (*types.Memory, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if roles == nil {
+		roles = []string{}
+	}
+	body, err := json.Marshal(map[string][]string{"entryRoles": roles})
+	if err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/v0/vaults/%s/memories/%s/entry-roles", baseURL, vaultID, memoryID)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, readErr := io.ReadAll(resp.Body)
+		if readErr != nil {
+			return nil, errors.NewHTTPError(resp.StatusCode, "", "set memory entry roles")
+		}
+		return nil, errors.ClassifyHTTPError(resp.StatusCode, string(bodyBytes), fmt.Errorf("set memory entry roles failed"))
+	}
+
+	var mem types.Memory
+	if err := json.NewDecoder(resp.Body).Decode(&mem); err != nil {
+		return nil, err
+	}
+	return &mem, nil
+}
+
+// UpdateMemory renames the memory and/or changes its description.
+func UpdateMemory(ctx context.Context, httpClient *http.Client, baseURL, vaultID, memoryID string, req types.UpdateTitleRequest) (*types.Memory, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/v0/vaults/%s/memories/%s", baseURL, vaultID, memoryID)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPatch, url, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, readErr := io.ReadAll(resp.Body)
+		if readErr != nil {
+			return nil, errors.NewHTTPError(resp.StatusCode, "", "update memory")
+		}
+		return nil, errors.ClassifyHTTPError(resp.StatusCode, string(bodyBytes), fmt.Errorf("update memory failed"))
+	}
+
+	var mem types.Memory
+	if err := json.NewDecoder(resp.Body).Decode(&mem); err != nil {
+		return nil, err
+	}
+	return &mem, nil
+}
+
+// SummarizeMemory asks the server to regenerate the memory's context with
+// its configured LLM; see types.SummarizeMemoryRequest.
+func SummarizeMemory(ctx context.Context, httpClient *http.Client, baseURL, vaultID, memoryID string, req types.SummarizeMemoryRequest) (*types.SummarizeMemoryResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/v0/vaults/%s/memories/%s/summarize", baseURL, vaultID, memoryID)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		bodyBytes, readErr := io.ReadAll(resp.Body)
+		if readErr != nil {
+			return nil, errors.NewHTTPError(resp.StatusCode, "", "summarize memory")
+		}
+		return nil, errors.ClassifyHTTPError(resp.StatusCode, string(bodyBytes), fmt.Errorf("summarize memory failed"))
+	}
+
+	var out types.SummarizeMemoryResult
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
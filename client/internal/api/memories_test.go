@@ -158,3 +158,25 @@ func TestMemories_HTTPDoError(t *testing.T) {
 		t.Fatal("expected Do error for DeleteMemory")
 	}
 }
+
+func TestSummarizeMemory(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v0/vaults/v1/memories/m1/summarize" {
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+		var in types.SummarizeMemoryRequest
+		_ = json.NewDecoder(r.Body).Decode(&in)
+		if in.LastN != 10 || in.Preview {
+			t.Errorf("unexpected body %+v", in)
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"context":"# Facts","entryCount":3,"saved":true,"contextId":"c1"}`))
+	}))
+	defer srv.Close()
+
+	out, err := SummarizeMemory(context.Background(), srv.Client(), srv.URL, "v1", "m1", types.SummarizeMemoryRequest{LastN: 10})
+	if err != nil || !out.Saved || out.ContextID != "c1" || out.EntryCount != 3 {
+		t.Fatalf("SummarizeMemory: out=%+v err=%v", out, err)
+	}
+}
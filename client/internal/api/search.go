@@ -5,8 +5,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 
+	"github.com/mycelian/mycelian-memory/client/internal/errors"
 	"github.com/mycelian/mycelian-memory/client/internal/types"
 )
 
@@ -35,7 +40,8 @@ func Search(ctx context.Context, httpClient *http.Client, baseURL string, req ty
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("search: status %d", resp.StatusCode)
+		body, _ := io.ReadAll(resp.Body)
+		return nil, errors.ClassifyHTTPError(resp.StatusCode, string(body), fmt.Errorf("search: status %d", resp.StatusCode))
 	}
 
 	var sr types.SearchResponse
@@ -44,3 +50,182 @@ func Search(ctx context.Context, httpClient *http.Client, baseURL string, req ty
 	}
 	return &sr, nil
 }
+
+// SearchBatch runs several queries with the same scope in one request.
+func SearchBatch(ctx context.Context, httpClient *http.Client, baseURL string, req types.BatchSearchRequest) (*types.BatchSearchResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/v0/search:batch", baseURL)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(payload))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, errors.ClassifyHTTPError(resp.StatusCode, string(body), fmt.Errorf("search batch: status %d", resp.StatusCode))
+	}
+
+	var br types.BatchSearchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&br); err != nil {
+		return nil, err
+	}
+	return &br, nil
+}
+
+// SubmitSearchFeedback records which results of a logged search were useful.
+func SubmitSearchFeedback(ctx context.Context, httpClient *http.Client, baseURL string, req types.SearchFeedbackRequest) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if req.UsefulEntryIDs == nil {
+		req.UsefulEntryIDs = []string{}
+	}
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/v0/search/feedback", baseURL), bytes.NewBuffer(payload))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("search feedback: status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// ExplainSearch reports whether and why an entry is returned for a query.
+func ExplainSearch(ctx context.Context, httpClient *http.Client, baseURL string, req types.ExplainSearchRequest) (*types.SearchExplanation, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if req.VaultID == "" || req.MemoryID == "" || req.EntryID == "" || req.Query == "" {
+		return nil, fmt.Errorf("vaultId, memoryId, entryId and query are required")
+	}
+	q := url.Values{}
+	q.Set("vaultId", req.VaultID)
+	q.Set("memoryId", req.MemoryID)
+	q.Set("entryId", req.EntryID)
+	q.Set("query", req.Query)
+	if req.TopK > 0 {
+		q.Set("topK", strconv.Itoa(req.TopK))
+	}
+	if req.SessionID != "" {
+		q.Set("sessionId", req.SessionID)
+	}
+	if req.RankBy != "" {
+		q.Set("rankBy", req.RankBy)
+	}
+	if req.Profile != "" {
+		q.Set("profile", req.Profile)
+	}
+	var out types.SearchExplanation
+	if err := getJSON(ctx, httpClient, baseURL+"/v0/search/explain?"+q.Encode(), "explain search", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetSearchMetrics returns aggregated precision metrics. memoryID and since are optional filters.
+func GetSearchMetrics(ctx context.Context, httpClient *http.Client, baseURL, memoryID string, since *time.Time) (*types.SearchMetrics, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	q := url.Values{}
+	if memoryID != "" {
+		q.Set("memoryId", memoryID)
+	}
+	if since != nil {
+		q.Set("since", since.UTC().Format(time.RFC3339))
+	}
+	u := fmt.Sprintf("%s/v0/search/metrics", baseURL)
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("search metrics: status %d", resp.StatusCode)
+	}
+	var out types.SearchMetrics
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ExportSearchLog returns logged searches in a TREC text format; see
+// types.ExportSearchLogRequest.
+func ExportSearchLog(ctx context.Context, httpClient *http.Client, baseURL string, req types.ExportSearchLogRequest) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	q := url.Values{}
+	if req.Format != "" {
+		q.Set("format", req.Format)
+	}
+	if req.MemoryID != "" {
+		q.Set("memoryId", req.MemoryID)
+	}
+	if req.Since != nil {
+		q.Set("since", req.Since.UTC().Format(time.RFC3339))
+	}
+	if req.Limit > 0 {
+		q.Set("limit", strconv.Itoa(req.Limit))
+	}
+	if req.RunTag != "" {
+		q.Set("runTag", req.RunTag)
+	}
+	u := baseURL + "/v0/search/log:export"
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.ClassifyHTTPError(resp.StatusCode, string(body), fmt.Errorf("export search log failed"))
+	}
+	return body, nil
+}
@@ -6,13 +6,14 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/mycelian/mycelian-memory/client/internal/types"
 )
 
 func TestSearch_Success(t *testing.T) {
 	t.Parallel()
-	want := types.SearchResponse{Count: 1}
+	want := types.SearchResponse{Count: 1, Contexts: map[string]*types.Context{"m1": {ContextID: "c1", MemoryID: "m1"}}}
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		_ = json.NewEncoder(w).Encode(want)
@@ -22,6 +23,55 @@ func TestSearch_Success(t *testing.T) {
 	if err != nil || got == nil || got.Count != 1 {
 		t.Fatalf("Search unexpected: %+v, err=%v", got, err)
 	}
+	if c := got.Contexts["m1"]; c == nil || c.ContextID != "c1" {
+		t.Fatalf("expected prefetched context for m1, got %+v", got.Contexts)
+	}
+}
+
+func TestSearch_SendsMustNot(t *testing.T) {
+	t.Parallel()
+	var body map[string]json.RawMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		_ = json.NewEncoder(w).Encode(types.SearchResponse{})
+	}))
+	defer srv.Close()
+
+	if _, err := Search(context.Background(), srv.Client(), srv.URL, types.SearchRequest{MemoryID: "m1", Query: "q"}); err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if _, ok := body["mustNot"]; ok {
+		t.Fatalf("mustNot sent without exclusions: %s", body["mustNot"])
+	}
+
+	req := types.SearchRequest{MemoryID: "m1", Query: "q", MustNot: &types.SearchMustNot{EntryIDs: []string{"e1"}}}
+	if _, err := Search(context.Background(), srv.Client(), srv.URL, req); err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if got := string(body["mustNot"]); got != `{"entryIds":["e1"]}` {
+		t.Fatalf("mustNot = %s", got)
+	}
+}
+
+func TestSearch_TitleScope(t *testing.T) {
+	t.Parallel()
+	var body map[string]json.RawMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		_, _ = w.Write([]byte(`{"entries":[],"count":0,"vaultId":"v1","memories":[{"memoryId":"m1","title":"proj-x-notes"}]}`))
+	}))
+	defer srv.Close()
+
+	got, err := Search(context.Background(), srv.Client(), srv.URL, types.SearchRequest{VaultID: "v1", MemoryPattern: "proj-x-*", Query: "q"})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if string(body["memoryPattern"]) != `"proj-x-*"` || string(body["vaultId"]) != `"v1"` {
+		t.Fatalf("scope not sent: %v", body)
+	}
+	if len(got.Memories) != 1 || got.Memories[0].Title != "proj-x-notes" {
+		t.Fatalf("memories = %+v", got.Memories)
+	}
 }
 
 func TestSearch_NonOKAndDecodeError(t *testing.T) {
@@ -53,3 +103,99 @@ func TestSearch_HTTPDoError(t *testing.T) {
 		t.Fatal("expected Do error for Search")
 	}
 }
+
+func TestSearchFeedbackAndMetrics(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v0/search/feedback":
+			var req types.SearchFeedbackRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			if req.QueryID != "q1" || req.UsefulEntryIDs == nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		case "/v0/search/metrics":
+			if r.URL.Query().Get("memoryId") != "m1" || r.URL.Query().Get("since") == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(types.SearchMetrics{Queries: 3, Precision: 0.5})
+		}
+	}))
+	defer srv.Close()
+
+	if err := SubmitSearchFeedback(context.Background(), srv.Client(), srv.URL, types.SearchFeedbackRequest{QueryID: "q1"}); err != nil {
+		t.Fatalf("SubmitSearchFeedback: %v", err)
+	}
+	if err := SubmitSearchFeedback(context.Background(), srv.Client(), srv.URL, types.SearchFeedbackRequest{QueryID: "bad"}); err == nil {
+		t.Fatal("expected error for non-204 status")
+	}
+	since := time.Now().Add(-time.Hour)
+	m, err := GetSearchMetrics(context.Background(), srv.Client(), srv.URL, "m1", &since)
+	if err != nil || m.Queries != 3 || m.Precision != 0.5 {
+		t.Fatalf("GetSearchMetrics: m=%+v err=%v", m, err)
+	}
+}
+
+func TestExplainSearch(t *testing.T) {
+	t.Parallel()
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		_, _ = w.Write([]byte(`{"entryId":"e1","wouldMatch":true,"rank":2,"terms":{"matched":["lisbon"],"missing":[]},"reasons":["ranked"]}`))
+	}))
+	defer srv.Close()
+
+	got, err := ExplainSearch(context.Background(), srv.Client(), srv.URL, types.ExplainSearchRequest{VaultID: "v1", MemoryID: "m1", EntryID: "e1", Query: "in lisbon", TopK: 3})
+	if err != nil || !got.WouldMatch || got.Rank != 2 || got.Terms.Matched[0] != "lisbon" {
+		t.Fatalf("ExplainSearch: %+v err=%v", got, err)
+	}
+	if query != "entryId=e1&memoryId=m1&query=in+lisbon&topK=3&vaultId=v1" {
+		t.Fatalf("unexpected query: %s", query)
+	}
+	if _, err := ExplainSearch(context.Background(), srv.Client(), srv.URL, types.ExplainSearchRequest{MemoryID: "m1"}); err == nil {
+		t.Fatalf("expected error for missing fields")
+	}
+}
+
+func TestExportSearchLog(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v0/search/log:export" || r.URL.RawQuery != "format=qrels&limit=5&memoryId=m1" {
+			t.Errorf("unexpected request %s?%s", r.URL.Path, r.URL.RawQuery)
+		}
+		_, _ = w.Write([]byte("q1 0 e1 1\n"))
+	}))
+	defer srv.Close()
+
+	out, err := ExportSearchLog(context.Background(), srv.Client(), srv.URL, types.ExportSearchLogRequest{Format: "qrels", MemoryID: "m1", Limit: 5})
+	if err != nil || string(out) != "q1 0 e1 1\n" {
+		t.Fatalf("ExportSearchLog: out=%q err=%v", out, err)
+	}
+}
+
+func TestSearchBatch(t *testing.T) {
+	t.Parallel()
+	var path string
+	var body map[string]json.RawMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		_, _ = w.Write([]byte(`{"results":[{"query":"a","entries":[{"entryId":"e1","score":0.5}],"count":1},{"query":"b","error":"search service unavailable"}],"count":2,"latestContext":"ctx"}`))
+	}))
+	defer srv.Close()
+
+	req := types.BatchSearchRequest{SearchRequest: types.SearchRequest{MemoryID: "m1", TopK: 3}, Queries: []string{"a", "b"}}
+	got, err := SearchBatch(context.Background(), srv.Client(), srv.URL, req)
+	if err != nil {
+		t.Fatalf("SearchBatch: %v", err)
+	}
+	if path != "/v0/search:batch" || string(body["queries"]) != `["a","b"]` || string(body["memoryId"]) != `"m1"` {
+		t.Fatalf("path=%s body=%v", path, body)
+	}
+	if got.Count != 2 || got.Results[0].Entries[0].ID != "e1" || got.Results[1].Error == "" || string(got.LatestContext) != `"ctx"` {
+		t.Fatalf("unexpected response: %+v", got)
+	}
+}
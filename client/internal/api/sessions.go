@@ -0,0 +1,98 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/mycelian/mycelian-memory/client/internal/errors"
+	"github.com/mycelian/mycelian-memory/client/internal/types"
+)
+
+// ListSessions summarises the conversation sessions of a memory, oldest first.
+func ListSessions(ctx context.Context, httpClient *http.Client, baseURL, vaultID, memID string) (*types.ListSessionsResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	u := fmt.Sprintf("%s/v0/vaults/%s/memories/%s/sessions", baseURL, vaultID, memID)
+	var out types.ListSessionsResponse
+	if err := getJSON(ctx, httpClient, u, "list sessions", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListSessionEntries returns the entries of one session oldest first.
+// limit <= 0 uses the server default.
+func ListSessionEntries(ctx context.Context, httpClient *http.Client, baseURL, vaultID, memID, sessionID string, limit int) (*types.ListEntriesResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if sessionID == "" {
+		return nil, fmt.Errorf("sessionID is required")
+	}
+	u := fmt.Sprintf("%s/v0/vaults/%s/memories/%s/sessions/%s/entries", baseURL, vaultID, memID, url.PathEscape(sessionID))
+	if limit > 0 {
+		u += fmt.Sprintf("?limit=%d", limit)
+	}
+	var out types.ListEntriesResponse
+	if err := getJSON(ctx, httpClient, u, "list session entries", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ScanEntries finds a memory's entries by exact text, newest first, with a
+// database scan that bypasses the search index.
+func ScanEntries(ctx context.Context, httpClient *http.Client, baseURL, vaultID, memID string, req types.ScanEntriesRequest) (*types.ScanEntriesResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if req.Contains == "" && req.Regex == "" {
+		return nil, fmt.Errorf("contains or regex is required")
+	}
+	q := url.Values{}
+	if req.Contains != "" {
+		q.Set("contains", req.Contains)
+	}
+	if req.Regex != "" {
+		q.Set("regex", req.Regex)
+	}
+	if req.Limit > 0 {
+		q.Set("limit", strconv.Itoa(req.Limit))
+	}
+	if req.Cursor != "" {
+		q.Set("cursor", req.Cursor)
+	}
+	u := fmt.Sprintf("%s/v0/vaults/%s/memories/%s/entries:scan?%s", baseURL, vaultID, memID, q.Encode())
+	var out types.ScanEntriesResponse
+	if err := getJSON(ctx, httpClient, u, "scan entries", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func getJSON(ctx context.Context, httpClient *http.Client, u, op string, out interface{}) error {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, readErr := io.ReadAll(resp.Body)
+		if readErr != nil {
+			return errors.NewHTTPError(resp.StatusCode, "", op)
+		}
+		return errors.ClassifyHTTPError(resp.StatusCode, string(bodyBytes), fmt.Errorf("%s failed", op))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mycelian/mycelian-memory/client/internal/types"
+)
+
+func TestSessions_Success(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/v0/vaults/v1/memories/m1/sessions":
+			_ = json.NewEncoder(w).Encode(types.ListSessionsResponse{Sessions: []types.EntrySession{{SessionID: "chat 1", EntryCount: 2}}, Count: 1})
+		case "/v0/vaults/v1/memories/m1/sessions/chat%201/entries":
+			if r.URL.Query().Get("limit") != "5" {
+				t.Errorf("expected limit=5, got %q", r.URL.RawQuery)
+			}
+			_ = json.NewEncoder(w).Encode(types.ListEntriesResponse{Entries: []types.Entry{{ID: "e1", SessionID: "chat 1"}}, Count: 1})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	ss, err := ListSessions(ctx, srv.Client(), srv.URL, "v1", "m1")
+	if err != nil || ss.Count != 1 || ss.Sessions[0].EntryCount != 2 {
+		t.Fatalf("ListSessions: %+v %v", ss, err)
+	}
+	es, err := ListSessionEntries(ctx, srv.Client(), srv.URL, "v1", "m1", "chat 1", 5)
+	if err != nil || es.Count != 1 || es.Entries[0].SessionID != "chat 1" {
+		t.Fatalf("ListSessionEntries: %+v %v", es, err)
+	}
+	if _, err := ListSessionEntries(ctx, srv.Client(), srv.URL, "v1", "m1", "", 0); err == nil {
+		t.Fatal("expected error for empty sessionID")
+	}
+	if _, err := ListSessions(ctx, srv.Client(), srv.URL, "v1", "missing"); err == nil {
+		t.Fatal("expected error for 404")
+	}
+}
+
+func TestScanEntries(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/v0/vaults/v1/memories/m1/entries:scan" || q.Get("regex") != "ERR-[0-9]+ & co" || q.Get("limit") != "2" || q.Get("cursor") != "c1" {
+			t.Errorf("unexpected request: %s", r.URL.String())
+		}
+		_ = json.NewEncoder(w).Encode(types.ScanEntriesResponse{Entries: []types.Entry{{ID: "e1"}}, Count: 1, NextCursor: "c2"})
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	out, err := ScanEntries(ctx, srv.Client(), srv.URL, "v1", "m1", types.ScanEntriesRequest{Regex: "ERR-[0-9]+ & co", Limit: 2, Cursor: "c1"})
+	if err != nil || out.Count != 1 || out.NextCursor != "c2" {
+		t.Fatalf("ScanEntries: %+v %v", out, err)
+	}
+	if _, err := ScanEntries(ctx, srv.Client(), srv.URL, "v1", "m1", types.ScanEntriesRequest{}); err == nil {
+		t.Fatal("expected error without contains or regex")
+	}
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/mycelian/mycelian-memory/client/internal/types"
+)
+
+// ListTrash returns the vault's trashed memories and entries.
+func ListTrash(ctx context.Context, httpClient *http.Client, baseURL, vaultID string) (*types.Trash, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	u := fmt.Sprintf("%s/v0/vaults/%s/trash", baseURL, vaultID)
+	var out types.Trash
+	if err := getJSON(ctx, httpClient, u, "list trash", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RestoreMemory takes a trashed memory out of the trash.
+func RestoreMemory(ctx context.Context, httpClient *http.Client, baseURL, vaultID, memoryID string) (*types.Memory, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	u := fmt.Sprintf("%s/v0/vaults/%s/memories/%s:restore", baseURL, vaultID, memoryID)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	var out types.Memory
+	if err := doBatchRequest(httpClient, httpReq, http.StatusOK, "restore memory", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RestoreEntry takes a trashed entry out of the trash.
+func RestoreEntry(ctx context.Context, httpClient *http.Client, baseURL, vaultID, memoryID, entryID string) (*types.Entry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if entryID == "" {
+		return nil, fmt.Errorf("entryID is required")
+	}
+	u := fmt.Sprintf("%s/v0/vaults/%s/memories/%s/entries/%s:restore", baseURL, vaultID, memoryID, entryID)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	var out types.Entry
+	if err := doBatchRequest(httpClient, httpReq, http.StatusOK, "restore entry", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mycelian/mycelian-memory/client/internal/types"
+)
+
+func TestTrash(t *testing.T) {
+	t.Parallel()
+	deleted := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v0/vaults/v1/trash":
+			_ = json.NewEncoder(w).Encode(types.Trash{Memories: []types.Memory{}, Entries: []types.Entry{{ID: "e1", DeletionTime: &deleted}}})
+		case r.Method == http.MethodPost && r.URL.Path == "/v0/vaults/v1/memories/m1:restore":
+			_ = json.NewEncoder(w).Encode(types.Memory{ID: "m1", VaultID: "v1"})
+		case r.Method == http.MethodPost && r.URL.Path == "/v0/vaults/v1/memories/m1/entries/e1:restore":
+			_ = json.NewEncoder(w).Encode(types.Entry{ID: "e1", MemoryID: "m1"})
+		default:
+			http.Error(w, `{"error":"entry not in trash"}`, http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	trash, err := ListTrash(ctx, srv.Client(), srv.URL, "v1")
+	if err != nil || len(trash.Entries) != 1 || trash.Entries[0].DeletionTime == nil || !trash.Entries[0].DeletionTime.Equal(deleted) {
+		t.Fatalf("ListTrash: %+v %v", trash, err)
+	}
+	if m, err := RestoreMemory(ctx, srv.Client(), srv.URL, "v1", "m1"); err != nil || m.ID != "m1" {
+		t.Fatalf("RestoreMemory: %+v %v", m, err)
+	}
+	if e, err := RestoreEntry(ctx, srv.Client(), srv.URL, "v1", "m1", "e1"); err != nil || e.ID != "e1" {
+		t.Fatalf("RestoreEntry: %+v %v", e, err)
+	}
+	if _, err := RestoreEntry(ctx, srv.Client(), srv.URL, "v1", "m1", "e2"); err == nil {
+		t.Fatal("expected error for an entry not in the trash")
+	}
+}
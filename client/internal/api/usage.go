@@ -0,0 +1,31 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/mycelian/mycelian-memory/client/internal/types"
+)
+
+// GetUsage returns the language model usage reported with the actor's
+// entries. memoryID and since are optional filters.
+func GetUsage(ctx context.Context, httpClient *http.Client, baseURL, memoryID string, since *time.Time) (*types.UsageReport, error) {
+	q := url.Values{}
+	if memoryID != "" {
+		q.Set("memoryId", memoryID)
+	}
+	if since != nil {
+		q.Set("since", since.UTC().Format(time.RFC3339))
+	}
+	u := baseURL + "/v0/usage"
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	var out types.UsageReport
+	if err := getJSON(ctx, httpClient, u, "get usage", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,26 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGetUsage(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v0/usage" || r.URL.RawQuery != "memoryId=m1&since=2025-01-01T00%3A00%3A00Z" {
+			t.Errorf("unexpected request %s?%s", r.URL.Path, r.URL.RawQuery)
+		}
+		_, _ = w.Write([]byte(`{"total":{"entries":2,"inputTokens":300,"outputTokens":40,"costUsd":0.05},"byMemory":[{"memoryId":"m1","entries":2,"inputTokens":300,"outputTokens":40,"costUsd":0.05}],"byModel":[]}`))
+	}))
+	defer srv.Close()
+
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	got, err := GetUsage(context.Background(), srv.Client(), srv.URL, "m1", &since)
+	if err != nil || got.Total.Entries != 2 || got.Total.CostUSD != 0.05 || got.ByMemory[0].MemoryID != "m1" {
+		t.Fatalf("GetUsage: %+v err=%v", got, err)
+	}
+}
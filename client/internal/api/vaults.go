@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 
 	"github.com/mycelian/mycelian-memory/client/internal/errors"
 	"github.com/mycelian/mycelian-memory/client/internal/types"
@@ -54,6 +55,98 @@ func CreateVault(ctx context.Context, httpClient *http.Client, baseURL string, r
 	return &vault, nil
 }
 
+// CreateVaultFromTemplate creates a vault with the memories of a server-side template.
+func CreateVaultFromTemplate(ctx context.Context, httpClient *http.Client, baseURL string, req types.CreateVaultFromTemplateRequest) (*types.TemplatedVault, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/v0/vaults:fromTemplate", bytes.NewBuffer(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	var out types.TemplatedVault
+	if err := doBatchRequest(httpClient, httpReq, http.StatusCreated, "create vault from template", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CloneVault copies a vault's memories, entries and latest contexts into a new vault.
+func CloneVault(ctx context.Context, httpClient *http.Client, baseURL, vaultID string, req types.CloneVaultRequest) (*types.ClonedVault, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/v0/vaults/%s:clone", baseURL, vaultID), bytes.NewBuffer(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	var out types.ClonedVault
+	if err := doBatchRequest(httpClient, httpReq, http.StatusCreated, "clone vault", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Bootstrap creates a vault, a memory and its first context in one request.
+// The server answers 201 when it created them and 200 when it replayed the
+// result of an earlier request with the same client token.
+func Bootstrap(ctx context.Context, httpClient *http.Client, baseURL string, req types.BootstrapRequest) (*types.BootstrapResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/v0/bootstrap", bytes.NewBuffer(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		bodyBytes, readErr := io.ReadAll(resp.Body)
+		if readErr != nil {
+			return nil, errors.NewHTTPError(resp.StatusCode, "", "bootstrap")
+		}
+		return nil, errors.ClassifyHTTPError(resp.StatusCode, string(bodyBytes), fmt.Errorf("bootstrap failed"))
+	}
+	var out types.BootstrapResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListVaultTemplates returns the vault templates offered by the server.
+func ListVaultTemplates(ctx context.Context, httpClient *http.Client, baseURL string) ([]types.VaultTemplate, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var out types.ListVaultTemplatesResponse
+	if err := getJSON(ctx, httpClient, baseURL+"/v0/vault-templates", "list vault templates", &out); err != nil {
+		return nil, err
+	}
+	return out.Templates, nil
+}
+
 // ListVaults returns all vaults using API key authentication.
 func ListVaults(ctx context.Context, httpClient *http.Client, baseURL string) ([]types.Vault, error) {
 	if err := ctx.Err(); err != nil {
@@ -143,13 +236,85 @@ func DeleteVault(ctx context.Context, httpClient *http.Client, baseURL, vaultID
 	return nil
 }
 
+// SetVaultReadOnly sets or clears the vault's read-only flag.
+func SetVaultReadOnly(ctx context.Context, httpClient *http.Client, baseURL, vaultID string, readOnly bool) (*types.Vault, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(map[string]bool{"readOnly": readOnly})
+	if err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/v0/vaults/%s/read-only", baseURL, vaultID)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, readErr := io.ReadAll(resp.Body)
+		if readErr != nil {
+			return nil, errors.NewHTTPError(resp.StatusCode, "", "set vault read-only")
+		}
+		return nil, errors.ClassifyHTTPError(resp.StatusCode, string(bodyBytes), fmt.Errorf("set vault read-only failed"))
+	}
+
+	var vault types.Vault
+	if err := json.NewDecoder(resp.Body).Decode(&vault); err != nil {
+		return nil, err
+	}
+	return &vault, nil
+}
+
+// UpdateVault renames the vault and/or changes its description.
+func UpdateVault(ctx context.Context, httpClient *http.Client, baseURL, vaultID string, req types.UpdateTitleRequest) (*types.Vault, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/v0/vaults/%s", baseURL, vaultID)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPatch, url, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, readErr := io.ReadAll(resp.Body)
+		if readErr != nil {
+			return nil, errors.NewHTTPError(resp.StatusCode, "", "update vault")
+		}
+		return nil, errors.ClassifyHTTPError(resp.StatusCode, string(bodyBytes), fmt.Errorf("update vault failed"))
+	}
+
+	var vault types.Vault
+	if err := json.NewDecoder(resp.Body).Decode(&vault); err != nil {
+		return nil, err
+	}
+	return &vault, nil
+}
+
 // GetVaultByTitle fetches a vault by its title using API key authentication.
 func GetVaultByTitle(ctx context.Context, httpClient *http.Client, baseURL, vaultTitle string) (*types.Vault, error) {
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
-	url := fmt.Sprintf("%s/v0/vaults/%s", baseURL, vaultTitle)
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	u := fmt.Sprintf("%s/v0/vaults/%s", baseURL, url.PathEscape(vaultTitle))
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -169,3 +334,16 @@ func GetVaultByTitle(ctx context.Context, httpClient *http.Client, baseURL, vaul
 	}
 	return &v, nil
 }
+
+// GetVaultStats compares each memory's stored entry and context counts with
+// the search index's object counts.
+func GetVaultStats(ctx context.Context, httpClient *http.Client, baseURL, vaultID string) (*types.VaultStats, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var out types.VaultStats
+	if err := getJSON(ctx, httpClient, fmt.Sprintf("%s/v0/vaults/%s/stats", baseURL, vaultID), "get vault stats", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
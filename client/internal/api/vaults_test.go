@@ -24,6 +24,62 @@ func TestCreateVault_Success(t *testing.T) {
 	}
 }
 
+func TestCreateVaultFromTemplate(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req types.CreateVaultFromTemplateRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if r.Method != http.MethodPost || r.URL.Path != "/v0/vaults:fromTemplate" || req.Template != "project" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"unknown template"}`))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(types.TemplatedVault{
+			Vault:    types.Vault{VaultID: "v1", Title: req.Title},
+			Template: req.Template,
+			Memories: []types.Memory{{ID: "m1", Title: "decisions"}},
+		})
+	}))
+	defer srv.Close()
+	got, err := CreateVaultFromTemplate(context.Background(), srv.Client(), srv.URL, types.CreateVaultFromTemplateRequest{Title: "acme", Template: "project"})
+	if err != nil || got.VaultID != "v1" || got.Title != "acme" || len(got.Memories) != 1 {
+		t.Fatalf("CreateVaultFromTemplate unexpected: got=%+v err=%v", got, err)
+	}
+	if _, err := CreateVaultFromTemplate(context.Background(), srv.Client(), srv.URL, types.CreateVaultFromTemplateRequest{Title: "acme", Template: "nope"}); err == nil {
+		t.Fatalf("expected error for unknown template")
+	}
+}
+
+func TestCloneVault(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req types.CloneVaultRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if r.Method != http.MethodPost || r.URL.Path != "/v0/vaults/v1:clone" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"vault not found"}`))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(types.ClonedVault{
+			Vault:         types.Vault{VaultID: "v2", Title: req.Title},
+			SourceVaultID: "v1",
+			Memories:      []types.ClonedMemory{{Memory: types.Memory{ID: "m2"}, SourceMemoryID: "m1", EntryCount: 3}},
+			EntryCount:    3,
+			Reindex:       req.Reindex,
+		})
+	}))
+	defer srv.Close()
+	got, err := CloneVault(context.Background(), srv.Client(), srv.URL, "v1", types.CloneVaultRequest{Title: "acme-copy", Reindex: true})
+	if err != nil || got.VaultID != "v2" || got.Title != "acme-copy" || !got.Reindex || len(got.Memories) != 1 || got.Memories[0].SourceMemoryID != "m1" {
+		t.Fatalf("CloneVault unexpected: got=%+v err=%v", got, err)
+	}
+	if _, err := CloneVault(context.Background(), srv.Client(), srv.URL, "nope", types.CloneVaultRequest{Title: "x"}); err == nil {
+		t.Fatalf("expected error for unknown vault")
+	}
+}
+
 func TestListVaults_Success(t *testing.T) {
 	t.Parallel()
 	resp := types.ListVaultsResponse{Vaults: []types.Vault{{VaultID: "v1"}}, Count: 1}
@@ -119,6 +175,22 @@ func TestGetVaultByTitle_NonOK(t *testing.T) {
 	}
 }
 
+func TestGetVaultByTitle_EscapesTitle(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/v0/vaults/Notes%20de%20voyage%20%C3%A9t%C3%A9" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"vaultId":"v1","title":"Notes de voyage été","slug":"notes-de-voyage-été"}`))
+	}))
+	defer srv.Close()
+	v, err := GetVaultByTitle(context.Background(), srv.Client(), srv.URL, "Notes de voyage été")
+	if err != nil || v.VaultID != "v1" || v.Slug != "notes-de-voyage-été" {
+		t.Fatalf("GetVaultByTitle: %+v %v", v, err)
+	}
+}
+
 func TestVaults_DecodeErrors(t *testing.T) {
 	t.Parallel()
 	// CreateVault decode error
@@ -190,3 +262,23 @@ func TestCreateVault_InvalidTitle(t *testing.T) {
 		t.Fatal("expected validation error for long title")
 	}
 }
+
+func TestGetVaultStats(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v0/vaults/v1/stats" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"vaultId":"v1","memories":[{"memoryId":"m1","postgres":{"entries":2,"contexts":1},"index":{"entries":1,"contexts":1}}],"inSync":false}`))
+	}))
+	defer srv.Close()
+
+	st, err := GetVaultStats(context.Background(), srv.Client(), srv.URL, "v1")
+	if err != nil || st.InSync || st.Memories[0].Index == nil || st.Memories[0].Index.Entries != 1 {
+		t.Fatalf("GetVaultStats: %+v %v", st, err)
+	}
+	if _, err := GetVaultStats(context.Background(), srv.Client(), srv.URL, "missing"); err == nil {
+		t.Fatal("expected error for 404")
+	}
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/mycelian/mycelian-memory/client/internal/errors"
+	"github.com/mycelian/mycelian-memory/client/internal/types"
+)
+
+// CreateWebhook creates an inbound webhook of the memory; the response
+// carries its secret.
+func CreateWebhook(ctx context.Context, httpClient *http.Client, baseURL, vaultID, memID string, req types.CreateWebhookRequest) (*types.Webhook, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if req.Name == "" || req.Mapping.RawEntry == "" {
+		return nil, fmt.Errorf("name and mapping.rawEntry are required")
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	u := fmt.Sprintf("%s/v0/vaults/%s/memories/%s/webhooks", baseURL, vaultID, memID)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusCreated {
+		bodyBytes, readErr := io.ReadAll(resp.Body)
+		if readErr != nil {
+			return nil, errors.NewHTTPError(resp.StatusCode, "", "create webhook")
+		}
+		return nil, errors.ClassifyHTTPError(resp.StatusCode, string(bodyBytes), fmt.Errorf("create webhook failed"))
+	}
+
+	var out types.Webhook
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListWebhooks returns a memory's webhooks ordered by name.
+func ListWebhooks(ctx context.Context, httpClient *http.Client, baseURL, vaultID, memID string) (*types.ListWebhooksResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	u := fmt.Sprintf("%s/v0/vaults/%s/memories/%s/webhooks", baseURL, vaultID, memID)
+	var out types.ListWebhooksResponse
+	if err := getJSON(ctx, httpClient, u, "list webhooks", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteWebhook removes one webhook from the memory.
+func DeleteWebhook(ctx context.Context, httpClient *http.Client, baseURL, vaultID, memID, webhookID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if webhookID == "" {
+		return fmt.Errorf("webhookID is required")
+	}
+	u := fmt.Sprintf("%s/v0/vaults/%s/memories/%s/webhooks/%s", baseURL, vaultID, memID, webhookID)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodDelete, u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusNoContent {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return errors.ClassifyHTTPError(resp.StatusCode, string(bodyBytes), fmt.Errorf("delete webhook: status %d", resp.StatusCode))
+	}
+	return nil
+}
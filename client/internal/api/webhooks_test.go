@@ -0,0 +1,50 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mycelian/mycelian-memory/client/internal/types"
+)
+
+func TestWebhooks(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v0/vaults/v1/memories/m1/webhooks":
+			var req types.CreateWebhookRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(types.Webhook{WebhookID: "w1", Name: req.Name, Kind: "generic", Secret: "s", Mapping: req.Mapping})
+		case r.Method == http.MethodGet && r.URL.Path == "/v0/vaults/v1/memories/m1/webhooks":
+			_ = json.NewEncoder(w).Encode(types.ListWebhooksResponse{Webhooks: []types.Webhook{{WebhookID: "w1", Name: "ci"}}, Count: 1})
+		case r.Method == http.MethodDelete && r.URL.Path == "/v0/vaults/v1/memories/m1/webhooks/w1":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.Error(w, `{"error":"webhook not found"}`, http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	hook, err := CreateWebhook(ctx, srv.Client(), srv.URL, "v1", "m1", types.CreateWebhookRequest{Name: "ci", Mapping: types.WebhookMapping{RawEntry: "{{text}}"}})
+	if err != nil || hook.WebhookID != "w1" || hook.Secret != "s" || hook.Mapping.RawEntry != "{{text}}" {
+		t.Fatalf("CreateWebhook: %+v %v", hook, err)
+	}
+	if _, err := CreateWebhook(ctx, srv.Client(), srv.URL, "v1", "m1", types.CreateWebhookRequest{Name: "ci"}); err == nil {
+		t.Fatal("expected error for a mapping without rawEntry")
+	}
+	list, err := ListWebhooks(ctx, srv.Client(), srv.URL, "v1", "m1")
+	if err != nil || list.Count != 1 || list.Webhooks[0].Name != "ci" {
+		t.Fatalf("ListWebhooks: %+v %v", list, err)
+	}
+	if err := DeleteWebhook(ctx, srv.Client(), srv.URL, "v1", "m1", "w1"); err != nil {
+		t.Fatalf("DeleteWebhook: %v", err)
+	}
+	if err := DeleteWebhook(ctx, srv.Client(), srv.URL, "v1", "m1", "w2"); err == nil {
+		t.Fatal("expected error for unknown webhook")
+	}
+}
@@ -0,0 +1,92 @@
+package shardqueue
+
+import "sync"
+
+// KeyReport counts the outcome of the jobs submitted for one key. Barrier
+// jobs are not counted.
+type KeyReport struct {
+	Submitted int // accepted by Submit
+	Flushed   int // ran to success
+	Failed    int // ran but returned an error after retries
+	Dropped   int // never completed: cancelled, or abandoned at shutdown
+}
+
+// Pending is the number of submitted jobs without an outcome yet.
+func (r KeyReport) Pending() int {
+	return r.Submitted - r.Flushed - r.Failed - r.Dropped
+}
+
+// Report maps each key to the outcome of its jobs.
+type Report map[string]KeyReport
+
+// Complete reports whether every submitted job ran to success.
+func (r Report) Complete() bool {
+	for _, k := range r {
+		if k.Flushed != k.Submitted {
+			return false
+		}
+	}
+	return true
+}
+
+type outcome int
+
+const (
+	outcomeFlushed outcome = iota
+	outcomeFailed
+	outcomeDropped
+)
+
+// tally records per-key job outcomes; it is shared by all shard workers.
+type tally struct {
+	mu   sync.Mutex
+	keys map[string]*KeyReport
+}
+
+func (t *tally) submitted(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.get(key).Submitted++
+}
+
+func (t *tally) record(key string, o outcome) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	r := t.get(key)
+	switch o {
+	case outcomeFlushed:
+		r.Flushed++
+	case outcomeFailed:
+		r.Failed++
+	default:
+		r.Dropped++
+	}
+}
+
+func (t *tally) get(key string) *KeyReport {
+	if t.keys == nil {
+		t.keys = make(map[string]*KeyReport)
+	}
+	r, ok := t.keys[key]
+	if !ok {
+		r = &KeyReport{}
+		t.keys[key] = r
+	}
+	return r
+}
+
+// snapshot copies the counters; when final, jobs still pending are counted
+// as dropped because no worker will run them any more.
+func (t *tally) snapshot(final bool) Report {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make(Report, len(t.keys))
+	for k, r := range t.keys {
+		c := *r
+		if final {
+			c.Dropped += c.Pending()
+		}
+		out[k] = c
+	}
+	return out
+}
@@ -20,8 +20,10 @@ import (
 )
 
 type queuedJob struct {
-	ctx context.Context
-	job Job
+	ctx     context.Context
+	job     Job
+	key     string
+	counted bool // false for Barrier jobs, which are not reported
 }
 
 // ShardExecutor executes Jobs on worker goroutines partitioned by a stable hash
@@ -31,10 +33,17 @@ type ShardExecutor struct {
 	cfg    Config
 	queues []chan queuedJob // len == cfg.Shards
 
-	done   chan struct{} // closed in Stop()
-	closed uint32        // 0 → running, 1 → closed
+	done    chan struct{} // closed in Stop()
+	closed  uint32        // 0 → running, 1 → closed
+	stopped chan struct{} // closed once shutdown has finished
 
-	wg sync.WaitGroup
+	// abort is cancelled when a Shutdown deadline passes; running jobs see
+	// their context cancelled and queued jobs are dropped unrun.
+	abort       context.Context
+	cancelAbort context.CancelFunc
+
+	tally tally
+	wg    sync.WaitGroup
 }
 
 // NewShardExecutor constructs the executor and starts its shard workers.
@@ -60,10 +69,12 @@ func NewShardExecutor(cfg Config) *ShardExecutor {
 	}
 
 	p := &ShardExecutor{
-		cfg:    cfg,
-		queues: make([]chan queuedJob, cfg.Shards),
-		done:   make(chan struct{}),
+		cfg:     cfg,
+		queues:  make([]chan queuedJob, cfg.Shards),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
 	}
+	p.abort, p.cancelAbort = context.WithCancel(context.Background())
 	for i := 0; i < cfg.Shards; i++ {
 		ch := make(chan queuedJob, cfg.QueueSize)
 		p.queues[i] = ch
@@ -81,6 +92,10 @@ func NewShardExecutor(cfg Config) *ShardExecutor {
 //     after EnqueueTimeout elapses.
 //   - Returns ctx.Err() if the caller‑provided context is cancelled first.
 func (p *ShardExecutor) Submit(ctx context.Context, key string, job Job) error {
+	return p.submit(ctx, key, job, true)
+}
+
+func (p *ShardExecutor) submit(ctx context.Context, key string, job Job, counted bool) error {
 	// Fast checks to avoid accepting work after Stop().
 	// 1. If Stop() has set the flag but not yet closed p.done we still reject.
 	if atomic.LoadUint32(&p.closed) == 1 {
@@ -95,7 +110,7 @@ func (p *ShardExecutor) Submit(ctx context.Context, key string, job Job) error {
 	default:
 	}
 
-	qj := queuedJob{ctx: ctx, job: job}
+	qj := queuedJob{ctx: ctx, job: job, key: key, counted: counted}
 	shard := p.shardFor(key)
 	ch := p.queues[shard]
 
@@ -104,6 +119,9 @@ func (p *ShardExecutor) Submit(ctx context.Context, key string, job Job) error {
 
 	select {
 	case ch <- qj:
+		if counted {
+			p.tally.submitted(key)
+		}
 		submissionsTotal.WithLabelValues(labelFor(shard)).Inc()
 		return nil
 
@@ -132,7 +150,7 @@ func (p *ShardExecutor) Barrier(ctx context.Context, key string) error {
 		close(done)
 		return nil
 	})
-	if err := p.Submit(ctx, key, j); err != nil {
+	if err := p.submit(ctx, key, j, false); err != nil {
 		return err
 	}
 	select {
@@ -147,18 +165,55 @@ func (p *ShardExecutor) Barrier(ctx context.Context, key string) error {
 // them to terminate, and then returns.  It is idempotent and safe for
 // concurrent use.
 func (p *ShardExecutor) Stop() {
+	_, _ = p.Shutdown(context.Background())
+}
+
+// Shutdown stops accepting work and drains every queue like Stop, but gives
+// up when ctx is done: running jobs have their context cancelled and queued
+// jobs are dropped unrun. It returns the per-key outcome of every job
+// submitted over the executor's lifetime, and ctx.Err() if the deadline cut
+// the drain short. Later calls wait for the first to finish and return the
+// same report with a nil error.
+func (p *ShardExecutor) Shutdown(ctx context.Context) (Report, error) {
 	if !atomic.CompareAndSwapUint32(&p.closed, 0, 1) {
-		return // already closed
+		<-p.stopped
+		return p.tally.snapshot(true), nil
 	}
 
 	// Log start of graceful shutdown
 	log.Printf("shardqueue: stopping executor, draining %d shards", p.cfg.Shards)
 
 	close(p.done)
-	p.wg.Wait()
+	drained := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(drained)
+	}()
+
+	var err error
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		err = ctx.Err()
+		p.cancelAbort()
+		<-drained
+	}
+	p.cancelAbort()
+	p.sweep()
+	close(p.stopped)
 
 	// Log completion of graceful shutdown
-	log.Printf("shardqueue: executor stopped, all queues drained")
+	if err != nil {
+		log.Printf("shardqueue: executor stopped before queues drained: %v", err)
+	} else {
+		log.Printf("shardqueue: executor stopped, all queues drained")
+	}
+	return p.tally.snapshot(true), err
+}
+
+// Report returns the per-key outcome of the jobs submitted so far.
+func (p *ShardExecutor) Report() Report {
+	return p.tally.snapshot(false)
 }
 
 // Close lets ShardExecutor satisfy io.Closer.
@@ -187,84 +242,132 @@ func (p *ShardExecutor) runWorker(idx int, ch <-chan queuedJob) {
 			if qj.job == nil {
 				continue
 			}
-
-			// Honour caller context so a cancelled job doesn't stall the shard.
-			select {
-			case <-qj.ctx.Done():
-				p.safeHandleError(qj.ctx.Err())
-				// Do not record latency for a job we didn't run.
-			default:
-				attempts := 0
-				exp := backoff.NewExponentialBackOff()
-				exp.InitialInterval = p.cfg.BaseBackoff
-				exp.Multiplier = 2
-				exp.MaxInterval = p.cfg.MaxInterval
-				exp.Reset()
-
-				var err error
-				for {
-					start := time.Now()
-					err = qj.job.Run(qj.ctx)
-					runDuration.WithLabelValues(label).Observe(time.Since(start).Seconds())
-
-					if err == nil {
-						break // Success - exit retry loop
-					}
-
-					// Check if this is an irrecoverable error (fail fast)
-					if isIrrecoverableError(err) {
-						p.safeHandleError(err)
-						break // Don't retry irrecoverable errors
-					}
-
-					// Retry logic for recoverable errors
-					if attempts >= p.cfg.MaxAttempts-1 {
-						p.safeHandleError(err) // Max retries exceeded
-						break
-					}
-
-					attempts++
-					wait := exp.NextBackOff()
-					select {
-					case <-time.After(wait):
-					case <-p.done:
-						return
-					case <-qj.ctx.Done():
-						p.safeHandleError(qj.ctx.Err())
-						attempts = p.cfg.MaxAttempts // force exit loop
-					}
-				}
+			o, stopped := p.runWithRetry(qj, label)
+			p.record(qj, o)
+			if stopped {
+				p.drain(idx, ch, label)
+				return
 			}
-
 			queueDepth.WithLabelValues(label).Set(float64(len(ch)))
 
 		case <-p.done:
-			// Drain remaining jobs, preserving FIFO, then exit.
-			remainingJobs := len(ch)
-			if remainingJobs > 0 {
-				log.Printf("shardqueue: worker %d draining %d remaining jobs", idx, remainingJobs)
-			}
+			p.drain(idx, ch, label)
+			return
+		}
+	}
+}
 
-			drained := 0
-			for {
-				select {
-				case qj := <-ch:
-					if qj.job != nil {
-						_ = qj.job.Run(qj.ctx)
-						drained++
-					}
-				default:
-					if drained > 0 {
-						log.Printf("shardqueue: worker %d drained %d jobs", idx, drained)
-					}
-					queueDepth.WithLabelValues(label).Set(0)
-					return
-				}
+// runWithRetry runs qj, retrying recoverable errors with exponential backoff.
+// stopped reports that Stop interrupted a backoff wait, abandoning the job.
+func (p *ShardExecutor) runWithRetry(qj queuedJob, label string) (o outcome, stopped bool) {
+	// Honour caller context so a cancelled job doesn't stall the shard.
+	if err := qj.ctx.Err(); err != nil {
+		p.safeHandleError(err)
+		return outcomeDropped, false // do not record latency for a job we didn't run
+	}
+	if p.abort.Err() != nil {
+		return outcomeDropped, false
+	}
+
+	exp := backoff.NewExponentialBackOff()
+	exp.InitialInterval = p.cfg.BaseBackoff
+	exp.Multiplier = 2
+	exp.MaxInterval = p.cfg.MaxInterval
+	exp.Reset()
+
+	for attempts := 0; ; attempts++ {
+		start := time.Now()
+		err := p.run(qj)
+		runDuration.WithLabelValues(label).Observe(time.Since(start).Seconds())
+
+		switch {
+		case err == nil:
+			return outcomeFlushed, false
+		case p.abort.Err() != nil:
+			return outcomeDropped, false
+		case isIrrecoverableError(err):
+			// Fail fast: retrying will not help.
+			p.safeHandleError(err)
+			return outcomeFailed, false
+		case attempts >= p.cfg.MaxAttempts-1:
+			p.safeHandleError(err) // Max retries exceeded
+			return outcomeFailed, false
+		}
+
+		select {
+		case <-time.After(exp.NextBackOff()):
+		case <-p.done:
+			return outcomeDropped, true
+		case <-qj.ctx.Done():
+			p.safeHandleError(qj.ctx.Err())
+			return outcomeDropped, false
+		}
+	}
+}
+
+// drain runs the jobs left in ch once each, preserving FIFO. After a
+// Shutdown deadline the remaining jobs are dropped unrun.
+func (p *ShardExecutor) drain(idx int, ch <-chan queuedJob, label string) {
+	if remainingJobs := len(ch); remainingJobs > 0 {
+		log.Printf("shardqueue: worker %d draining %d remaining jobs", idx, remainingJobs)
+	}
+
+	drained := 0
+	for {
+		select {
+		case qj := <-ch:
+			if qj.job == nil {
+				continue
+			}
+			if p.abort.Err() != nil {
+				p.record(qj, outcomeDropped)
+				continue
+			}
+			err := p.run(qj)
+			switch {
+			case err == nil:
+				p.record(qj, outcomeFlushed)
+			case p.abort.Err() != nil:
+				p.record(qj, outcomeDropped)
+			default:
+				p.safeHandleError(err)
+				p.record(qj, outcomeFailed)
+			}
+			drained++
+		default:
+			if drained > 0 {
+				log.Printf("shardqueue: worker %d drained %d jobs", idx, drained)
 			}
+			queueDepth.WithLabelValues(label).Set(0)
+			return
 		}
 	}
 }
 
+// run executes qj with a context that is also cancelled by a Shutdown deadline.
+func (p *ShardExecutor) run(qj queuedJob) error {
+	ctx, cancel := context.WithCancel(qj.ctx)
+	defer cancel()
+	stop := context.AfterFunc(p.abort, cancel)
+	defer stop()
+	return qj.job.Run(ctx)
+}
+
+// sweep drops jobs that a racing Submit enqueued after the workers exited.
+func (p *ShardExecutor) sweep() {
+	for _, ch := range p.queues {
+		for len(ch) > 0 {
+			p.record(<-ch, outcomeDropped)
+		}
+	}
+}
+
+func (p *ShardExecutor) record(qj queuedJob, o outcome) {
+	if qj.counted {
+		p.tally.record(qj.key, o)
+	}
+}
+
 func (p *ShardExecutor) safeHandleError(err error) {
 	if err == nil || p.cfg.ErrorHandler == nil {
 		return
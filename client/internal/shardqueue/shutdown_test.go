@@ -0,0 +1,81 @@
+package shardqueue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestShutdown_ReportsOutcomesPerKey(t *testing.T) {
+	ex := NewShardExecutor(Config{Shards: 2, MaxAttempts: 1})
+
+	fail := errors.New("boom")
+	for i := 0; i < 3; i++ {
+		if err := ex.Submit(context.Background(), "a", noopJob{}); err != nil {
+			t.Fatalf("submit: %v", err)
+		}
+	}
+	if err := ex.Submit(context.Background(), "b", JobFunc(func(context.Context) error { return fail })); err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	if err := ex.Barrier(context.Background(), "a"); err != nil {
+		t.Fatalf("barrier: %v", err)
+	}
+
+	rep, err := ex.Shutdown(context.Background())
+	if err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	if got := rep["a"]; got != (KeyReport{Submitted: 3, Flushed: 3}) {
+		t.Fatalf("key a: %+v", got)
+	}
+	if got := rep["b"]; got != (KeyReport{Submitted: 1, Failed: 1}) {
+		t.Fatalf("key b: %+v", got)
+	}
+	if rep.Complete() {
+		t.Fatal("report with a failed job must not be complete")
+	}
+
+	// A second call returns the same report.
+	again, err := ex.Shutdown(context.Background())
+	if err != nil || again["a"] != rep["a"] || again["b"] != rep["b"] {
+		t.Fatalf("second shutdown: %+v, %v", again, err)
+	}
+}
+
+func TestShutdown_DeadlineDropsQueuedJobs(t *testing.T) {
+	ex := NewShardExecutor(Config{Shards: 1, QueueSize: 8})
+
+	started := make(chan struct{})
+	if err := ex.Submit(context.Background(), "k", JobFunc(func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done() // a write stuck on the network until the deadline cancels it
+		return ctx.Err()
+	})); err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	<-started
+	for i := 0; i < 2; i++ {
+		if err := ex.Submit(context.Background(), "k", noopJob{}); err != nil {
+			t.Fatalf("submit: %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	rep, err := ex.Shutdown(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("shutdown took %v", elapsed)
+	}
+	if got := rep["k"]; got != (KeyReport{Submitted: 3, Dropped: 3}) {
+		t.Fatalf("unexpected report: %+v", got)
+	}
+	if err := ex.Submit(context.Background(), "k", noopJob{}); !errors.Is(err, ErrExecutorClosed) {
+		t.Fatalf("submit after shutdown: %v", err)
+	}
+}
@@ -23,6 +23,62 @@ type Vault struct {
 	Title        string    `json:"title"`
 	Description  string    `json:"description,omitempty"`
 	CreationTime time.Time `json:"creationTime"`
+	ReadOnly     bool      `json:"readOnly"`
+	// Slug is the URL form of Title, accepted wherever the title is.
+	Slug string `json:"slug,omitempty"`
+}
+
+// VaultTemplate describes the memories, and their starting contexts, a vault
+// created from it begins with.
+type VaultTemplate struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Memories    []TemplateMemory `json:"memories"`
+}
+
+// TemplateMemory is one memory of a VaultTemplate.
+type TemplateMemory struct {
+	Title       string `json:"title"`
+	MemoryType  string `json:"memoryType"`
+	Description string `json:"description,omitempty"`
+	Context     string `json:"context,omitempty"`
+}
+
+// TemplatedVault is returned by CreateVaultFromTemplate: the new vault and
+// the memories created in it.
+type TemplatedVault struct {
+	Vault
+	Template string   `json:"template"`
+	Memories []Memory `json:"memories"`
+}
+
+// ClonedVault is returned by CloneVault: the new vault and the copies of
+// the source vault's memories.
+type ClonedVault struct {
+	Vault
+	SourceVaultID string         `json:"sourceVaultId"`
+	Memories      []ClonedMemory `json:"memories"`
+	EntryCount    int            `json:"entryCount"`
+	Reindex       bool           `json:"reindex"`
+}
+
+// ClonedMemory is the copy of SourceMemoryID. ReindexJobID is set when the
+// clone enqueued index upserts.
+type ClonedMemory struct {
+	Memory
+	SourceMemoryID string `json:"sourceMemoryId"`
+	EntryCount     int    `json:"entryCount"`
+	ContextCopied  bool   `json:"contextCopied"`
+	ReindexJobID   string `json:"reindexJobId,omitempty"`
+}
+
+// BootstrapResponse is returned by Bootstrap. Replayed is set when the
+// client token had already been used, so nothing new was created.
+type BootstrapResponse struct {
+	Vault     Vault  `json:"vault"`
+	Memory    Memory `json:"memory"`
+	ContextID string `json:"contextId"`
+	Replayed  bool   `json:"replayed"`
 }
 
 // Memory represents a memory
@@ -35,6 +91,112 @@ type Memory struct {
 	MemoryType  string    `json:"memoryType"`
 	CreatedAt   time.Time `json:"creationTime"`
 	UpdatedAt   time.Time `json:"updated_at"`
+	AppendOnly  bool      `json:"appendOnly"`
+	// EntryRoles, when set, are the roles new entries must name in
+	// Metadata["role"].
+	EntryRoles []string `json:"entryRoles,omitempty"`
+	// Slug is the URL form of Title, accepted wherever the title is.
+	Slug string `json:"slug,omitempty"`
+	// DeletionTime is when the memory was moved to the trash; set only in
+	// trash listings.
+	DeletionTime *time.Time `json:"deletionTime,omitempty"`
+	// SearchBoost, when set, weighs the memory's hits in vault-scope searches.
+	SearchBoost *SearchBoost `json:"searchBoost,omitempty"`
+	// EntryTTLSeconds, when positive, is how long new entries written
+	// without an ExpirationTime are kept.
+	EntryTTLSeconds int `json:"entryTtlSeconds,omitempty"`
+}
+
+// Job states. Queued and running jobs are open; the others are final.
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+	JobCancelled = "cancelled"
+)
+
+// Job is a long-running operation the server runs in the background, such
+// as a bulk entry delete. Done of Total units of work are complete; Total
+// is 0 until known.
+type Job struct {
+	ID       string                 `json:"jobId"`
+	Kind     string                 `json:"kind"`
+	VaultID  string                 `json:"vaultId,omitempty"`
+	MemoryID string                 `json:"memoryId,omitempty"`
+	Params   map[string]interface{} `json:"params,omitempty"`
+	Status   string                 `json:"status"`
+	Done     int                    `json:"done"`
+	Total    int                    `json:"total"`
+	// Error says why a failed job failed.
+	Error string `json:"error,omitempty"`
+	// CancelRequested is set on a running job asked to stop.
+	CancelRequested bool       `json:"cancelRequested,omitempty"`
+	CreatedAt       time.Time  `json:"creationTime"`
+	UpdatedAt       time.Time  `json:"updateTime"`
+	FinishedAt      *time.Time `json:"finishTime,omitempty"`
+}
+
+// Finished reports whether the job reached a final state.
+func (j *Job) Finished() bool {
+	return j.Status != JobQueued && j.Status != JobRunning
+}
+
+// Entry fields a SearchBoost can weigh.
+const (
+	SearchFieldSummary  = "summary"
+	SearchFieldRawEntry = "rawEntry"
+	SearchFieldTags     = "tags"
+)
+
+// SearchBoost ranks one memory's hits against the other memories of a
+// vault-scope search, e.g. to put "user-preferences" above chat logs.
+type SearchBoost struct {
+	// Boost multiplies the scores of the memory's hits, up to 10; 0 means 1.
+	Boost float64 `json:"boost,omitempty"`
+	// FieldWeights weigh the keyword match per field (SearchField*), each
+	// up to 10; a field left out or at 0 is not keyword-matched. Empty
+	// matches summary and rawEntry at weight 1.
+	FieldWeights map[string]float64 `json:"fieldWeights,omitempty"`
+}
+
+// EntityAlias maps another name of an entity to its canonical name within
+// one memory; searches over the memory that mention either name match both.
+type EntityAlias struct {
+	MemoryID  string    `json:"memoryId"`
+	VaultID   string    `json:"vaultId"`
+	Alias     string    `json:"alias"`
+	Canonical string    `json:"canonical"`
+	CreatedAt time.Time `json:"creationTime"`
+}
+
+// WebhookMapping turns an inbound payload into entries. Each field is a
+// template where {{path}} stands for the payload value at that dotted path
+// ("event.text"). When Items names an array, each element becomes one entry
+// and paths resolve against it; "$." reaches the whole payload.
+type WebhookMapping struct {
+	Items            string            `json:"items,omitempty"`
+	RawEntry         string            `json:"rawEntry"`
+	Summary          string            `json:"summary,omitempty"`
+	SessionID        string            `json:"sessionId,omitempty"`
+	SourceID         string            `json:"sourceId,omitempty"`
+	ConversationTime string            `json:"conversationTime,omitempty"`
+	Tags             map[string]string `json:"tags,omitempty"`
+}
+
+// Webhook is an inbound integration of a memory. Secret is only set in the
+// response to its creation.
+type Webhook struct {
+	WebhookID        string         `json:"webhookId"`
+	MemoryID         string         `json:"memoryId"`
+	VaultID          string         `json:"vaultId"`
+	Name             string         `json:"name"`
+	Kind             string         `json:"kind"`
+	Secret           string         `json:"secret,omitempty"`
+	Mapping          WebhookMapping `json:"mapping"`
+	CreationTime     time.Time      `json:"creationTime"`
+	LastDeliveryTime *time.Time     `json:"lastDeliveryTime,omitempty"`
+	DeliveryCount    int64          `json:"deliveryCount"`
 }
 
 // Entry represents an entry
@@ -48,6 +210,155 @@ type Entry struct {
 	Summary        string            `json:"summary,omitempty"`
 	Tags           map[string]string `json:"tags,omitempty"`
 	ExpirationTime *time.Time        `json:"expirationTime,omitempty"`
+	// Metadata is the free-form JSON object stored with the entry.
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	// LastAccessedTime is when a get or search last returned the entry.
+	LastAccessedTime *time.Time `json:"lastAccessedTime,omitempty"`
+	// Provenance
+	SourceSystem     string `json:"sourceSystem,omitempty"`
+	SourceID         string `json:"sourceId,omitempty"`
+	IngestionBatchID string `json:"ingestionBatchId,omitempty"`
+	SessionID        string `json:"sessionId,omitempty"`
+	// Quality signals recorded by agents (see Client.RecordEntrySignal)
+	UsefulCount    int `json:"usefulCount,omitempty"`
+	IncorrectCount int `json:"incorrectCount,omitempty"`
+	OutdatedCount  int `json:"outdatedCount,omitempty"`
+	// Usage is the language model usage reported when the entry was added.
+	Usage *EntryUsage `json:"usage,omitempty"`
+	// ConversationTime is when the conversation behind the entry took place,
+	// if it was given when the entry was added.
+	ConversationTime *time.Time `json:"conversationTime,omitempty"`
+	// IndexStatus says whether the entry is searchable yet; set by GetEntry
+	// when the server supports FeatureIndexStatus.
+	IndexStatus *IndexStatus `json:"indexStatus,omitempty"`
+	// DeletionTime is when the entry was moved to the trash; set only in
+	// trash listings.
+	DeletionTime *time.Time `json:"deletionTime,omitempty"`
+	// CorrectionTime is when the entry was corrected (see
+	// Client.CorrectEntry); CorrectionReason says why.
+	CorrectionTime   *time.Time `json:"correctionTime,omitempty"`
+	CorrectionReason string     `json:"correctionReason,omitempty"`
+}
+
+// IndexStatus is the search indexing state of an entry or context: State is
+// "pending" (possibly retrying), "indexed", "failed" (LastError says why;
+// see Client.RetryIndexing) or "unknown".
+type IndexStatus struct {
+	State      string     `json:"state"`
+	Attempts   int        `json:"attempts"`
+	LastError  string     `json:"lastError,omitempty"`
+	UpdateTime *time.Time `json:"updateTime,omitempty"`
+}
+
+// IndexingCounts counts entries and contexts not searchable yet. Retrying
+// is the part of Pending that has failed at least once.
+type IndexingCounts struct {
+	Pending  int64 `json:"pending"`
+	Retrying int64 `json:"retrying"`
+	Failed   int64 `json:"failed"`
+}
+
+// EntryUsage is the language model usage spent generating an entry's
+// summary and context update, as reported by the client that wrote it.
+type EntryUsage struct {
+	Model        string  `json:"model,omitempty"`
+	InputTokens  int64   `json:"inputTokens,omitempty"`
+	OutputTokens int64   `json:"outputTokens,omitempty"`
+	CostUSD      float64 `json:"costUsd,omitempty"`
+}
+
+// EntryEmbedding is an entry's stored vector in a vector-store-neutral form.
+type EntryEmbedding struct {
+	Model     string    `json:"model"`
+	Dimension int       `json:"dimension"`
+	Vector    []float32 `json:"vector"`
+}
+
+// ExportedEntry is one line of a memory export. Embedding is nil unless
+// embeddings were requested and the entry has been indexed.
+type ExportedEntry struct {
+	Entry
+	Embedding *EntryEmbedding `json:"embedding,omitempty"`
+}
+
+// ObjectCounts counts a memory's entries and contexts in one store.
+type ObjectCounts struct {
+	Entries  int64 `json:"entries"`
+	Contexts int64 `json:"contexts"`
+}
+
+// MemoryStats compares a memory's stored rows with its search index objects.
+// Index is nil when the server's index cannot report counts.
+type MemoryStats struct {
+	MemoryID string         `json:"memoryId"`
+	Title    string         `json:"title"`
+	Postgres ObjectCounts   `json:"postgres"`
+	Index    *ObjectCounts  `json:"index,omitempty"`
+	Indexing IndexingCounts `json:"indexing"`
+}
+
+// VaultStats is returned by GetVaultStats. InSync is false while any memory
+// has an indexing gap; recent writes still in the outbox cause a brief one.
+type VaultStats struct {
+	VaultID  string        `json:"vaultId"`
+	Memories []MemoryStats `json:"memories"`
+	Postgres ObjectCounts  `json:"postgres"`
+	Index    *ObjectCounts `json:"index,omitempty"`
+	InSync   bool          `json:"inSync"`
+	// Indexing totals the memories' IndexingCounts.
+	Indexing IndexingCounts `json:"indexing"`
+}
+
+// EntrySession summarises the entries of one conversation session in a memory.
+type EntrySession struct {
+	SessionID      string    `json:"sessionId"`
+	EntryCount     int       `json:"entryCount"`
+	FirstEntryTime time.Time `json:"firstEntryTime"`
+	LastEntryTime  time.Time `json:"lastEntryTime"`
+}
+
+// Entry quality signals accepted by RecordEntrySignal.
+const (
+	SignalUseful    = "useful"
+	SignalIncorrect = "incorrect"
+	SignalOutdated  = "outdated"
+)
+
+// Search orderings accepted in SearchRequest.RankBy. Recency decays scores by
+// entry age (the server's half-life); hybrid applies half of that decay.
+const (
+	RankByRelevance = "relevance"
+	RankByRecency   = "recency"
+	RankByHybrid    = "hybrid"
+)
+
+// GroupBySession is the SearchRequest.GroupBy that collapses hits of one
+// conversation session into its best hit.
+const GroupBySession = "session"
+
+// ActorSettings holds the caller's preferences. TimeZone is the IANA zone
+// entry timestamps and date filters resolve in when no tz parameter is given.
+type ActorSettings struct {
+	ActorID  string `json:"actorId"`
+	TimeZone string `json:"timeZone"`
+	// DefaultVaultID and DefaultMemoryID are the memory the server uses for
+	// entry, context and search calls that omit it (see SetActorDefaults).
+	DefaultVaultID  string    `json:"defaultVaultId,omitempty"`
+	DefaultMemoryID string    `json:"defaultMemoryId,omitempty"`
+	UpdateTime      time.Time `json:"updateTime"`
+}
+
+// IngestionBatch groups entries written by one import run.
+// Status is "open" or "rolled_back".
+type IngestionBatch struct {
+	BatchID        string     `json:"batchId"`
+	UserID         string     `json:"actorId"`
+	SourceSystem   string     `json:"sourceSystem"`
+	Description    string     `json:"description,omitempty"`
+	Status         string     `json:"status"`
+	EntryCount     int        `json:"entryCount"`
+	CreationTime   time.Time  `json:"creationTime"`
+	RolledBackTime *time.Time `json:"rolledBackTime,omitempty"`
 }
 
 // Context represents a context snapshot
@@ -20,11 +20,72 @@ type CreateVaultRequest struct {
 	Description string `json:"description,omitempty"`
 }
 
+// CreateVaultFromTemplateRequest names the new vault and the server-side
+// template whose memories it starts with.
+type CreateVaultFromTemplateRequest struct {
+	Title    string `json:"title"`
+	Template string `json:"template"`
+}
+
+// CloneVaultRequest names the copy made by CloneVault. Reindex enqueues
+// index upserts of the copied entries and contexts; without it the copies
+// are not searchable until the memories are reindexed.
+type CloneVaultRequest struct {
+	Title   string `json:"title"`
+	Reindex bool   `json:"reindex,omitempty"`
+}
+
+// BootstrapRequest sets up a vault, one memory in it and the memory's first
+// context in a single call. Context defaults to the server's starting
+// context. A retry with the same ClientToken returns the first result
+// instead of creating anything.
+type BootstrapRequest struct {
+	ClientToken string             `json:"clientToken,omitempty"`
+	Vault       CreateVaultRequest `json:"vault"`
+	Memory      BootstrapMemory    `json:"memory"`
+	Context     string             `json:"context,omitempty"`
+}
+
+// BootstrapMemory describes the memory created by Bootstrap.
+type BootstrapMemory struct {
+	Title       string `json:"title"`
+	MemoryType  string `json:"memoryType"`
+	Description string `json:"description,omitempty"`
+}
+
+// CreateWebhookRequest sets up an inbound webhook writing the JSON payloads
+// POSTed to /v0/hooks/{webhookId} as entries. Kind is "generic" (default),
+// whose Secret the server generates when empty, or "slack", whose Secret
+// must be the Slack app's signing secret.
+type CreateWebhookRequest struct {
+	Name    string         `json:"name"`
+	Kind    string         `json:"kind,omitempty"`
+	Secret  string         `json:"secret,omitempty"`
+	Mapping WebhookMapping `json:"mapping"`
+}
+
 // CreateMemoryRequest holds parameters for new memory
 type CreateMemoryRequest struct {
 	Title       string `json:"title"`
 	Description string `json:"description,omitempty"`
 	MemoryType  string `json:"memoryType"`
+	// AppendOnly creates the memory append-only: entries can be added but
+	// not retagged or deleted.
+	AppendOnly bool `json:"appendOnly,omitempty"`
+	// EntryRoles requires every entry to carry Metadata["role"] naming one
+	// of these: user, assistant, system or tool.
+	EntryRoles []string `json:"entryRoles,omitempty"`
+	// EntryTTLSeconds expires entries written without an ExpirationTime
+	// this many seconds after creation (FeatureEntryExpiry). Expired entries
+	// disappear from reads and searches at once and are deleted later.
+	EntryTTLSeconds int `json:"entryTtlSeconds,omitempty"`
+}
+
+// UpdateTitleRequest renames a vault or memory and/or changes its
+// description. Nil fields are left unchanged; an empty Description clears it.
+type UpdateTitleRequest struct {
+	Title       *string `json:"title,omitempty"`
+	Description *string `json:"description,omitempty"`
 }
 
 // AddEntryRequest holds parameters for new entry
@@ -34,6 +95,37 @@ type AddEntryRequest struct {
 	Metadata       map[string]interface{} `json:"metadata,omitempty"`
 	Tags           map[string]string      `json:"tags,omitempty"`
 	ExpirationTime *time.Time             `json:"expirationTime,omitempty"`
+	// Provenance (optional). IngestionBatchID must name an open batch.
+	SourceSystem     string `json:"sourceSystem,omitempty"`
+	SourceID         string `json:"sourceId,omitempty"`
+	IngestionBatchID string `json:"ingestionBatchId,omitempty"`
+	// SessionID groups the entry with the other turns of one conversation.
+	SessionID string `json:"sessionId,omitempty"`
+	// Usage records the language model cost of writing this entry's summary
+	// and context update (optional); see Client.GetUsage.
+	Usage *EntryUsage `json:"usage,omitempty"`
+	// ConversationTime is when the conversation took place, for history added
+	// after the fact; lists can be ordered by it (FeatureConversationTime).
+	ConversationTime *time.Time `json:"conversationTime,omitempty"`
+	// IdempotencyKey makes the server return the entry first written with
+	// the key instead of writing a copy (FeatureIdempotentEntries). The
+	// durable queue sets it on every journaled write.
+	IdempotencyKey string `json:"idempotencyKey,omitempty"`
+}
+
+// CorrectEntryRequest is the entry that corrects another, with an optional
+// Reason stored on the corrected entry.
+type CorrectEntryRequest struct {
+	AddEntryRequest
+	Reason string `json:"reason,omitempty"`
+}
+
+// CreateIngestionBatchRequest registers an ingestion batch. BatchID is
+// optional; the server assigns one when empty.
+type CreateIngestionBatchRequest struct {
+	BatchID      string `json:"batchId,omitempty"`
+	SourceSystem string `json:"sourceSystem"`
+	Description  string `json:"description,omitempty"`
 }
 
 // SearchRequest holds search parameters
@@ -41,6 +133,129 @@ type SearchRequest struct {
 	UserID   string `json:"actorId"`
 	VaultID  string `json:"vaultId,omitempty"`
 	MemoryID string `json:"memoryId"`
-	Query    string `json:"query"`
-	TopK     int    `json:"topK,omitempty"`
+	// MemoryTitles or MemoryPattern, a glob such as "proj-x-*", search the
+	// memories of VaultID with those titles instead of MemoryID (at most 20).
+	// Requires FeatureSearchTitleScopes; not supported by SearchBatch.
+	MemoryTitles  []string `json:"memoryTitles,omitempty"`
+	MemoryPattern string   `json:"memoryPattern,omitempty"`
+	Query         string   `json:"query"`
+	TopK          int      `json:"topK,omitempty"`
+	// SessionID restricts results to one conversation session.
+	SessionID string `json:"sessionId,omitempty"`
+	// Tags restricts results to entries whose tags hold every key with
+	// exactly its value; booleans and numbers match their text, e.g.
+	// "true". Requires FeatureTagFilters.
+	Tags map[string]string `json:"tags,omitempty"`
+	// MustNot excludes results; nil excludes nothing.
+	MustNot *SearchMustNot `json:"mustNot,omitempty"`
+	// RankBy is RankByRelevance (server default), RankByRecency or RankByHybrid.
+	RankBy string `json:"rankBy,omitempty"`
+	// Profile selects a ranking profile the server operator defined; its
+	// rankBy applies when RankBy is empty. Requires FeatureRankingProfiles.
+	Profile string `json:"profile,omitempty"`
+	// Window limits results to a named creation time range the server
+	// resolves in the actor's time zone: today, yesterday, thisWeek,
+	// lastWeek, thisMonth, lastMonth, thisYear, lastYear, lastNh, lastNd,
+	// lastNw (e.g. "last7d") or sinceSessionStart (requires SessionID).
+	Window string `json:"window,omitempty"`
+	// Since and Until bound creation time to [Since, Until) instead of
+	// Window; each is RFC3339, YYYY-MM-DD, "today" or "yesterday".
+	Since string `json:"since,omitempty"`
+	Until string `json:"until,omitempty"`
+	// GroupBy GroupBySession returns one hit per conversation session, its
+	// best, with SearchEntry.SessionHits counting the session's hits; it
+	// cannot be combined with SessionID. Requires FeatureSearchGrouping.
+	GroupBy string `json:"groupBy,omitempty"`
+	// IncludeSuperseded returns hits of corrected entries too, with
+	// SearchEntry.SupersededBy set, instead of their corrections. Requires
+	// FeatureEntryCorrections.
+	IncludeSuperseded bool `json:"includeSuperseded,omitempty"`
+}
+
+// BatchSearchRequest runs every query in Queries with the memory, TopK,
+// filters and ranking of SearchRequest, whose Query must be empty.
+type BatchSearchRequest struct {
+	SearchRequest
+	Queries []string `json:"queries"`
+}
+
+// SearchMustNot drops entries that carry any listed tag, live in a listed
+// memory, or are listed themselves (e.g. entries already cited this turn).
+type SearchMustNot struct {
+	Tags      []string `json:"tags,omitempty"`
+	MemoryIDs []string `json:"memoryIds,omitempty"`
+	EntryIDs  []string `json:"entryIds,omitempty"`
+}
+
+// ScanEntriesRequest finds entries by exact text without the search index.
+// Contains is a case-insensitive substring and Regex a POSIX regular
+// expression (at least one is required); Cursor is the NextCursor of the
+// previous page. Limit <= 0 uses the server default.
+type ScanEntriesRequest struct {
+	Contains string
+	Regex    string
+	Limit    int
+	Cursor   string
+}
+
+// PatchEntryTagsRequest updates the tags of a memory's entries in one
+// transaction. Entries are selected by IDs, or else by SessionID and the
+// creation time range [Since, Until); at least one is required. Set writes
+// tag keys and Unset removes them.
+type PatchEntryTagsRequest struct {
+	IDs       []string
+	SessionID string
+	Since     *time.Time
+	Until     *time.Time
+	Set       map[string]string
+	Unset     []string
+}
+
+// DeleteEntriesRequest selects the memory's entries DeleteEntries deletes:
+// EntryIDs, or else the entries of SessionID and/or those created before
+// Before. At least one is required.
+type DeleteEntriesRequest struct {
+	EntryIDs  []string   `json:"entryIds,omitempty"`
+	SessionID string     `json:"sessionId,omitempty"`
+	Before    *time.Time `json:"before,omitempty"`
+}
+
+// ExplainSearchRequest asks why an entry is or is not returned for Query.
+// VaultID, MemoryID, EntryID and Query are required; TopK <= 0 uses the
+// server default, as do an empty SessionID, RankBy and Profile.
+type ExplainSearchRequest struct {
+	VaultID   string
+	MemoryID  string
+	EntryID   string
+	Query     string
+	TopK      int
+	SessionID string
+	RankBy    string
+	Profile   string
+}
+
+// SummarizeMemoryRequest asks the server to rewrite a memory's context
+// from its LastN newest entries (server default when zero). Preview returns
+// the proposed context without saving it.
+type SummarizeMemoryRequest struct {
+	LastN   int  `json:"lastN,omitempty"`
+	Preview bool `json:"preview,omitempty"`
+}
+
+// ExportSearchLogRequest selects logged searches to export for IR
+// evaluation. Format is "run" (default), "qrels" or "topics"; MemoryID and
+// Since filter, Limit <= 0 uses the server default and RunTag names the run.
+type ExportSearchLogRequest struct {
+	Format   string
+	MemoryID string
+	Since    *time.Time
+	Limit    int
+	RunTag   string
+}
+
+// SearchFeedbackRequest marks which results of a logged search were useful.
+// An empty UsefulEntryIDs records that none were.
+type SearchFeedbackRequest struct {
+	QueryID        string   `json:"queryId"`
+	UsefulEntryIDs []string `json:"usefulEntryIds"`
 }
@@ -13,12 +13,208 @@ import (
 type EnqueueAck struct {
 	MemoryID string `json:"memoryId"`
 	Status   string `json:"status"`
+	// Entry is the created entry when the write completed before returning
+	// (Status "created"; see client.WithSyncWrites).
+	Entry *Entry `json:"entry,omitempty"`
 }
 
 // ListEntriesResponse wraps list endpoint response
 type ListEntriesResponse struct {
 	Entries []Entry `json:"entries"`
 	Count   int     `json:"count"`
+	// NextPageToken is set on a full page; pass it as the "pageToken"
+	// parameter for the next one (FeatureEntriesPagination).
+	NextPageToken string `json:"nextPageToken,omitempty"`
+}
+
+// ScanEntriesResponse is one page of an entries scan. NextCursor is empty on
+// the last page.
+type ScanEntriesResponse struct {
+	Entries    []Entry `json:"entries"`
+	Count      int     `json:"count"`
+	NextCursor string  `json:"nextCursor,omitempty"`
+}
+
+// ListSessionsResponse wraps the session list endpoint response
+type ListSessionsResponse struct {
+	Sessions []EntrySession `json:"sessions"`
+	Count    int            `json:"count"`
+}
+
+// ListEntityAliasesResponse wraps the alias list endpoint response.
+type ListEntityAliasesResponse struct {
+	Aliases []EntityAlias `json:"aliases"`
+	Count   int           `json:"count"`
+}
+
+// ListWebhooksResponse wraps the webhook list endpoint response.
+type ListWebhooksResponse struct {
+	Webhooks []Webhook `json:"webhooks"`
+	Count    int       `json:"count"`
+}
+
+// Trash lists a vault's trashed memories and the trashed entries of its
+// other memories, most recently deleted first.
+type Trash struct {
+	Memories []Memory `json:"memories"`
+	Entries  []Entry  `json:"entries"`
+}
+
+// ListIngestionBatchesResponse wraps the batch list endpoint response
+type ListIngestionBatchesResponse struct {
+	Batches []IngestionBatch `json:"batches"`
+	Count   int              `json:"count"`
+}
+
+// AddEntriesResponse lists the entries a batch create wrote, in request
+// order.
+type AddEntriesResponse struct {
+	Entries []Entry `json:"entries"`
+	Count   int     `json:"count"`
+}
+
+// PatchEntryTagsResponse lists the entries a bulk tag update changed.
+type PatchEntryTagsResponse struct {
+	Updated  int      `json:"updated"`
+	EntryIDs []string `json:"entryIds"`
+}
+
+// SimilarEntries lists the entries nearest to one entry, most similar first.
+// VectorSource is "stored" or "embedded"; Scoring is "cosine" when scores are
+// comparable across memories, or "index" when they are the index's own.
+type SimilarEntries struct {
+	EntryID      string        `json:"entryId"`
+	MemoryID     string        `json:"memoryId"`
+	Scope        string        `json:"scope"`
+	TopK         int           `json:"topK"`
+	VectorSource string        `json:"vectorSource"`
+	Scoring      string        `json:"scoring"`
+	Entries      []SearchEntry `json:"entries"`
+}
+
+// RollbackIngestionBatchResponse reports the entries removed by a rollback
+type RollbackIngestionBatchResponse struct {
+	BatchID         string   `json:"batchId"`
+	Status          string   `json:"status"`
+	DeletedEntryIDs []string `json:"deletedEntryIds"`
+	DeletedCount    int      `json:"deletedCount"`
+}
+
+// ContextFetch is the result of a conditional context fetch. ETag identifies
+// the returned (or unchanged) snapshot; pass it back on the next call.
+type ContextFetch struct {
+	Context     string
+	ETag        string
+	NotModified bool
+}
+
+// ContextDocumentLimits are the server's context size limits, reported when
+// a context document is created.
+type ContextDocumentLimits struct {
+	MaxContextChars  int   `json:"maxContextChars"`
+	MaxPartBytes     int64 `json:"maxPartBytes"`
+	MaxDocumentBytes int64 `json:"maxDocumentBytes"`
+}
+
+// ContextDocument is the stored full version of a context too large to be
+// the active context. SizeBytes and SHA256 describe the text once complete.
+type ContextDocument struct {
+	DocumentID     string                 `json:"documentId"`
+	VaultID        string                 `json:"vaultId"`
+	MemoryID       string                 `json:"memoryId"`
+	Status         string                 `json:"status"`
+	SizeBytes      int64                  `json:"sizeBytes,omitempty"`
+	SHA256         string                 `json:"sha256,omitempty"`
+	CreationTime   time.Time              `json:"creationTime"`
+	CompletionTime *time.Time             `json:"completionTime,omitempty"`
+	Limits         *ContextDocumentLimits `json:"limits,omitempty"`
+}
+
+// PutContextLargeResult reports what PutContextLarge stored: the full
+// document and the enqueued active context, which is abridged when the
+// document exceeds the server's context limit.
+type PutContextLargeResult struct {
+	Document *ContextDocument
+	Abridged bool
+	Ack      *EnqueueAck
+}
+
+// SummarizeMemoryResult is the context the server generated. Saved is set
+// when it became the memory's latest context, identified by ContextID.
+type SummarizeMemoryResult struct {
+	Context      string     `json:"context"`
+	EntryCount   int        `json:"entryCount"`
+	Preview      bool       `json:"preview"`
+	Saved        bool       `json:"saved"`
+	ContextID    string     `json:"contextId,omitempty"`
+	CreationTime *time.Time `json:"creationTime,omitempty"`
+}
+
+// ContextSection is one named part of a structured context with who last
+// changed it and when.
+type ContextSection struct {
+	Name          string    `json:"name"`
+	Content       string    `json:"content"`
+	LastUpdatedBy string    `json:"lastUpdatedBy"`
+	LastUpdatedAt time.Time `json:"lastUpdatedAt"`
+}
+
+// StructuredContext is a context with its sections. Context is the rendered
+// text; Sections is empty for a context stored as plain text.
+type StructuredContext struct {
+	ContextID    string           `json:"contextId"`
+	Context      string           `json:"context"`
+	Sections     []ContextSection `json:"sections"`
+	CreationTime time.Time        `json:"creationTime"`
+	// RecentEntries is set by GetContextWithRecentSummaries, newest first.
+	RecentEntries []EntrySummary `json:"recentEntries,omitempty"`
+}
+
+// EntrySummary is an entry's summary, or the start of its raw text when it
+// has none, as returned next to a context.
+type EntrySummary struct {
+	EntryID      string    `json:"entryId"`
+	Summary      string    `json:"summary"`
+	CreationTime time.Time `json:"creationTime"`
+	SessionID    string    `json:"sessionId,omitempty"`
+}
+
+// CheckedContext is a context saved with PutContextChecked and the check the
+// server ran against recent entries before saving it.
+type CheckedContext struct {
+	ContextID    string       `json:"contextId"`
+	Context      string       `json:"context"`
+	CreationTime time.Time    `json:"creationTime"`
+	Check        ContextCheck `json:"check"`
+}
+
+// ContextCheck lists recent entries a saved context may have left out.
+type ContextCheck struct {
+	EntriesChecked int                 `json:"entriesChecked"`
+	Suggestions    []ContextSuggestion `json:"suggestions"`
+	Warnings       []string            `json:"warnings,omitempty"`
+}
+
+// ContextSuggestion is an entry the checked context does not reflect.
+// Coverage is the share of the entry's keyword terms found in the context;
+// Reason is "new" (written since the previous context) or "dropped" (the
+// previous context reflected it).
+type ContextSuggestion struct {
+	EntryID      string    `json:"entryId"`
+	Text         string    `json:"text"`
+	CreationTime time.Time `json:"creationTime"`
+	Coverage     float64   `json:"coverage"`
+	MissingTerms []string  `json:"missingTerms"`
+	Reason       string    `json:"reason"`
+}
+
+// ContextSectionsOptions apply to a structured context write. UpdatedBy is
+// recorded as the changed sections' lastUpdatedBy (the actor when empty).
+// IfMatch, when set, is the contextId the write is based on; the write fails
+// with a precondition error if another context became latest since.
+type ContextSectionsOptions struct {
+	UpdatedBy string
+	IfMatch   string
 }
 
 // PutContextResponse contains metadata about a stored context
@@ -39,6 +235,28 @@ type GetContextResponse struct {
 type SearchEntry struct {
 	Entry
 	Score float64 `json:"score"`
+	// LocalPending marks an entry written by this client that the server has
+	// not returned from search yet; Score is a local keyword match and ID is
+	// empty until the write reached the server. See client.WithReadYourWrites.
+	LocalPending bool `json:"localPending,omitempty"`
+	// SessionHits, under GroupBySession, is how many hits of the entry's
+	// session it stands for.
+	SessionHits int `json:"sessionHits,omitempty"`
+	// RerankScore is the server reranker's relevance score, when the server
+	// has one (FeatureReranker); Score then derives from it.
+	RerankScore *float64 `json:"rerankScore,omitempty"`
+	// Corrects is the ID of the corrected entry this hit stands in for.
+	Corrects string `json:"corrects,omitempty"`
+	// SupersededBy, under SearchRequest.IncludeSuperseded, is the ID of the
+	// newest correction of the hit's entry.
+	SupersededBy string `json:"supersededBy,omitempty"`
+}
+
+// ScopedMemory is one memory resolved from a search's MemoryTitles or
+// MemoryPattern.
+type ScopedMemory struct {
+	MemoryID string `json:"memoryId"`
+	Title    string `json:"title"`
 }
 
 // SearchResponse wraps the /api/search result
@@ -50,6 +268,114 @@ type SearchResponse struct {
 	BestContext          json.RawMessage `json:"bestContext,omitempty"`
 	BestContextTimestamp *time.Time      `json:"bestContextTimestamp,omitempty"`
 	BestContextScore     *float64        `json:"bestContextScore,omitempty"`
+	// ExpandedQuery is the query actually searched when the memory's entity
+	// aliases expanded it.
+	ExpandedQuery string `json:"expandedQuery,omitempty"`
+	// TimeWindow is the creation time range the server searched when the
+	// request set Window, Since or Until.
+	TimeWindow *SearchTimeWindow `json:"timeWindow,omitempty"`
+	// Profile is the ranking profile the search ran with, if any.
+	Profile string `json:"profile,omitempty"`
+	// Memories lists the memories a title-scoped search covered.
+	Memories []ScopedMemory `json:"memories,omitempty"`
+	// QueryID is set when the server's query log is enabled; pass it to SearchFeedback.
+	QueryID string `json:"queryId,omitempty"`
+	// Contexts maps each memoryId present in Entries to its latest context.
+	Contexts map[string]*Context `json:"contexts,omitempty"`
+	// IndexFreshness reports how far the search index trailed the memory's
+	// newest entry when the search ran; nil when the server does not report it.
+	IndexFreshness *IndexFreshness `json:"indexFreshness,omitempty"`
+	// Stale is set by the client (never the server) when the search failed
+	// and this is an earlier cached result for the same request; CachedAt is
+	// when it was fetched. See client.WithSearchRetries.
+	Stale    bool       `json:"stale,omitempty"`
+	CachedAt *time.Time `json:"cachedAt,omitempty"`
+}
+
+// BatchSearchResult is one query's result in a batch search. It is shaped
+// like a SearchResponse without the latest context; Error is set, and
+// Entries empty, when the query failed.
+type BatchSearchResult struct {
+	SearchResponse
+	Query string `json:"query"`
+	Error string `json:"error,omitempty"`
+}
+
+// BatchSearchResponse wraps POST /v0/search:batch: results in request order
+// and the memory's latest context, shared by all of them.
+type BatchSearchResponse struct {
+	Results          []BatchSearchResult `json:"results"`
+	Count            int                 `json:"count"`
+	LatestContext    json.RawMessage     `json:"latestContext,omitempty"`
+	ContextTimestamp *time.Time          `json:"contextTimestamp,omitempty"`
+	IndexFreshness   *IndexFreshness     `json:"indexFreshness,omitempty"`
+}
+
+// IndexFreshness compares a memory's newest entry with the newest entry the
+// search index holds. When Stale is set, entries written in the last
+// LagSeconds may be missing from the results: call AwaitConsistency and
+// search again, or warn that results may be incomplete.
+type IndexFreshness struct {
+	NewestEntryTime   *time.Time `json:"newestEntryTime,omitempty"`
+	NewestIndexedTime *time.Time `json:"newestIndexedTime,omitempty"`
+	LagSeconds        float64    `json:"lagSeconds"`
+	Stale             bool       `json:"stale"`
+}
+
+// SearchTimeWindow is a resolved [Since, Until) range; a nil bound is open.
+type SearchTimeWindow struct {
+	Since *time.Time `json:"since,omitempty"`
+	Until *time.Time `json:"until,omitempty"`
+}
+
+// Contains reports whether t falls inside the window.
+func (w *SearchTimeWindow) Contains(t time.Time) bool {
+	if w == nil {
+		return true
+	}
+	return (w.Since == nil || !t.Before(*w.Since)) && (w.Until == nil || t.Before(*w.Until))
+}
+
+// SearchMetrics aggregates relevance feedback over logged searches
+type SearchMetrics struct {
+	Queries         int     `json:"queries"`
+	JudgedQueries   int     `json:"judgedQueries"`
+	ReturnedResults int     `json:"returnedResults"`
+	UsefulResults   int     `json:"usefulResults"`
+	Precision       float64 `json:"precision"`
+	MeanPrecision   float64 `json:"meanPrecision"`
+}
+
+// SearchExplanation mirrors GET /v0/search/explain. Rank is the entry's
+// position among Depth ranked candidates, 0 when it is not among them;
+// Indexed and VectorSimilarity are nil when the server's index cannot read
+// stored vectors.
+type SearchExplanation struct {
+	EntryID          string   `json:"entryId"`
+	MemoryID         string   `json:"memoryId"`
+	Query            string   `json:"query"`
+	ExpandedQuery    string   `json:"expandedQuery,omitempty"`
+	TopK             int      `json:"topK"`
+	RankBy           string   `json:"rankBy"`
+	Profile          string   `json:"profile,omitempty"`
+	WouldMatch       bool     `json:"wouldMatch"`
+	Rank             int      `json:"rank"`
+	Depth            int      `json:"depth"`
+	Score            *float64 `json:"score,omitempty"`
+	Alpha            float32  `json:"alpha"`
+	Indexed          *bool    `json:"indexed,omitempty"`
+	VectorSimilarity *float64 `json:"vectorSimilarity,omitempty"`
+	Terms            struct {
+		Matched   []string `json:"matched"`
+		Missing   []string `json:"missing"`
+		Stopwords []string `json:"stopwords,omitempty"`
+	} `json:"terms"`
+	Filters []struct {
+		Filter string `json:"filter"`
+		Value  string `json:"value"`
+		Passed bool   `json:"passed"`
+	} `json:"filters"`
+	Reasons []string `json:"reasons"`
 }
 
 // ListMemoriesResponse mirrors the backend list shape
@@ -63,3 +389,45 @@ type ListVaultsResponse struct {
 	Vaults []Vault `json:"vaults"`
 	Count  int     `json:"count"`
 }
+
+// ListVaultTemplatesResponse mirrors GET /v0/vault-templates
+type ListVaultTemplatesResponse struct {
+	Templates []VaultTemplate `json:"templates"`
+	Count     int             `json:"count"`
+}
+
+// Capabilities mirrors GET /v0/capabilities. Legacy is set by the client
+// when the server predates the endpoint.
+type Capabilities struct {
+	APIVersion    string          `json:"apiVersion"`
+	SchemaVersion string          `json:"schemaVersion,omitempty"`
+	Features      map[string]bool `json:"features"`
+	Legacy        bool            `json:"-"`
+}
+
+// HealthResponse mirrors GET /v0/health
+type HealthResponse struct {
+	Status        string            `json:"status"`
+	Timestamp     time.Time         `json:"timestamp"`
+	SchemaVersion string            `json:"schemaVersion,omitempty"`
+	Components    map[string]string `json:"components,omitempty"`
+}
+
+// UsageTotals sums the usage reported with a group of entries. MemoryID and
+// Model are set when the group is a single memory or model.
+type UsageTotals struct {
+	MemoryID     string  `json:"memoryId,omitempty"`
+	Model        string  `json:"model,omitempty"`
+	Entries      int64   `json:"entries"`
+	InputTokens  int64   `json:"inputTokens"`
+	OutputTokens int64   `json:"outputTokens"`
+	CostUSD      float64 `json:"costUsd"`
+}
+
+// UsageReport is the GET /v0/usage response: the reported language model
+// usage in total, per memory and per model, each list by descending cost.
+type UsageReport struct {
+	Total    UsageTotals   `json:"total"`
+	ByMemory []UsageTotals `json:"byMemory"`
+	ByModel  []UsageTotals `json:"byModel"`
+}
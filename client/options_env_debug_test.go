@@ -8,7 +8,7 @@ import (
 
 func TestNew_AutoEnableDebugViaEnv(t *testing.T) {
 	t.Setenv("MYCELIAN_DEBUG", "true")
-	c, err := New("http://example.com", "test-api-key")
+	c, err := New("http://example.com", WithAPIKey("test-api-key"))
 	if err != nil {
 		t.Fatalf("New: %v", err)
 	}
@@ -25,7 +25,7 @@ func TestDebugTransport_ErrorPath(t *testing.T) {
 	rt := roundTripFunc(func(r *http.Request) (*http.Response, error) {
 		return nil, context.DeadlineExceeded
 	})
-	c, err := New("http://example.com", "test-api-key", WithDebugLogging(true))
+	c, err := New("http://example.com", WithAPIKey("test-api-key"), WithDebugLogging(true))
 	if err != nil {
 		t.Fatalf("New: %v", err)
 	}
@@ -31,7 +31,7 @@ func TestWithHTTPClientAndDebugLogging(t *testing.T) {
 		return &http.Response{StatusCode: 200, Body: http.NoBody, Header: make(http.Header)}, nil
 	})
 	// Create a client with a base transport
-	c2, err := New("http://example.com", "test-api-key", WithHTTPTimeout(2*time.Second), WithDebugLogging(true))
+	c2, err := New("http://example.com", WithAPIKey("test-api-key"), WithHTTPTimeout(2*time.Second), WithDebugLogging(true))
 	if err != nil {
 		t.Fatalf("New: %v", err)
 	}
@@ -23,13 +23,22 @@ type DefaultPromptResponse struct {
 }
 
 // LoadDefaultPrompts returns the embedded prompt templates for the requested
-// memoryType (e.g. "chat", "code"). It fails if the memoryType folder or any
-// required template file is missing.
+// memoryType (e.g. "chat", "code"), rendered with default Vars. It fails if
+// the memoryType folder or any required template file is missing.
 func LoadDefaultPrompts(memoryType string) (*DefaultPromptResponse, error) {
+	return RenderDefaultPrompts(memoryType, Vars{})
+}
+
+// RenderDefaultPrompts is LoadDefaultPrompts with the template variables
+// filled from v. MemoryType defaults to memoryType.
+func RenderDefaultPrompts(memoryType string, v Vars) (*DefaultPromptResponse, error) {
 	if memoryType == "" {
 		return nil, fmt.Errorf("memory type cannot be empty")
 	}
 
+	if v.MemoryType == "" {
+		v.MemoryType = memoryType
+	}
 	files := []string{"context_prompt.md", "entry_capture_prompt.md", "summary_prompt.md"}
 	tmpl := make(map[string]string, len(files))
 
@@ -39,17 +48,23 @@ func LoadDefaultPrompts(memoryType string) (*DefaultPromptResponse, error) {
 			return nil, fmt.Errorf("unknown memory type %q or missing %s: %w", memoryType, name, err)
 		}
 		key := name[:len(name)-3] // strip .md
-		tmpl[key] = string(b)
+		if tmpl[key], err = Render(key, string(b), v); err != nil {
+			return nil, err
+		}
 	}
 
-	rules, err := fs.ReadFile(defaultFS, "system/context_summary_rules.md")
+	b, err := fs.ReadFile(defaultFS, "system/context_summary_rules.md")
 	if err != nil {
 		return nil, fmt.Errorf("context_summary_rules missing: %w", err)
 	}
+	rules, err := Render("context_summary_rules", string(b), v)
+	if err != nil {
+		return nil, err
+	}
 
 	return &DefaultPromptResponse{
 		Version:             Version,
-		ContextSummaryRules: string(rules),
+		ContextSummaryRules: rules,
 		Templates:           tmpl,
 	}, nil
 }
@@ -0,0 +1,72 @@
+package prompts
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Vars are the values prompt assets may reference, e.g. {{.MemoryTitle}},
+// {{.Today}} or {{.ActorTimeZone}}. Zero fields fall back to neutral defaults
+// so assets always render.
+type Vars struct {
+	MemoryTitle string
+	MemoryType  string
+	// ActorTimeZone is an IANA zone name; empty means UTC.
+	ActorTimeZone string
+	// Now is the reference time for Today; zero means time.Now().
+	Now time.Time
+}
+
+// Today is the current date (YYYY-MM-DD) in ActorTimeZone.
+func (v Vars) Today() string {
+	return v.Now.Format(time.DateOnly)
+}
+
+// funcs is deliberately small: string helpers only, nothing that reads files,
+// the environment or the network.
+var funcs = template.FuncMap{
+	"default": func(def, s string) string {
+		if strings.TrimSpace(s) == "" {
+			return def
+		}
+		return s
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"trim":  strings.TrimSpace,
+}
+
+// Render executes text as a template over v. Values are inserted as data and
+// never parsed, so a memory title containing "{{" renders literally.
+func Render(name, text string, v Vars) (string, error) {
+	v, err := v.resolve()
+	if err != nil {
+		return "", err
+	}
+	t, err := template.New(name).Funcs(funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("parse prompt %s: %w", name, err)
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, v); err != nil {
+		return "", fmt.Errorf("render prompt %s: %w", name, err)
+	}
+	return b.String(), nil
+}
+
+func (v Vars) resolve() (Vars, error) {
+	if v.ActorTimeZone == "" {
+		v.ActorTimeZone = "UTC"
+	}
+	loc, err := time.LoadLocation(v.ActorTimeZone)
+	if err != nil {
+		return v, fmt.Errorf("invalid time zone %q: %w", v.ActorTimeZone, err)
+	}
+	if v.Now.IsZero() {
+		v.Now = time.Now()
+	}
+	v.Now = v.Now.In(loc)
+	return v, nil
+}
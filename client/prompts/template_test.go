@@ -0,0 +1,45 @@
+package prompts
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRender(t *testing.T) {
+	now := time.Date(2025, 3, 1, 2, 0, 0, 0, time.UTC)
+	got, err := Render("t", `{{upper .MemoryType}} {{default "untitled" .MemoryTitle}} {{.Today}} {{.ActorTimeZone}}`,
+		Vars{MemoryType: "chat", MemoryTitle: "{{.Now}}", ActorTimeZone: "America/Los_Angeles", Now: now})
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	// 02:00 UTC is still the previous day in Los Angeles; the title is not re-parsed.
+	if want := "CHAT {{.Now}} 2025-02-28 America/Los_Angeles"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+
+	if got, _ := Render("t", `{{default "untitled" .MemoryTitle}} {{.ActorTimeZone}}`, Vars{}); got != "untitled UTC" {
+		t.Fatalf("defaults: got %q", got)
+	}
+	if _, err := Render("t", `{{.Unknown}}`, Vars{}); err == nil {
+		t.Fatal("expected error for unknown variable")
+	}
+	if _, err := Render("t", `{{.Today}}`, Vars{ActorTimeZone: "Mars/Olympus"}); err == nil {
+		t.Fatal("expected error for invalid time zone")
+	}
+}
+
+func TestRenderDefaultPrompts(t *testing.T) {
+	resp, err := RenderDefaultPrompts("chat", Vars{MemoryTitle: "Trip planning", Now: time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatalf("RenderDefaultPrompts: %v", err)
+	}
+	if p := resp.Templates["context_prompt"]; !strings.Contains(p, "Memory: Trip planning (chat). Today is 2025-01-02 (UTC).") {
+		t.Fatalf("context_prompt not personalised:\n%s", p)
+	}
+	for k, v := range resp.Templates {
+		if strings.Contains(v, "{{") {
+			t.Fatalf("%s left unrendered", k)
+		}
+	}
+}
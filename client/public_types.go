@@ -1,6 +1,7 @@
 package client
 
 import (
+	"github.com/mycelian/mycelian-memory/client/internal/shardqueue"
 	"github.com/mycelian/mycelian-memory/client/internal/types"
 	prompts "github.com/mycelian/mycelian-memory/client/prompts"
 )
@@ -15,25 +16,139 @@ import (
 // Re-exported to avoid importing the prompts subpackage in user code.
 type DefaultPromptResponse = prompts.DefaultPromptResponse
 
+// PromptVars fills the template variables of prompts in RenderDefaultPrompts.
+type PromptVars = prompts.Vars
+
 // Request, entity, and response types
 // Note: user-related types are intentionally omitted.
 type (
 	// Requests
-	CreateVaultRequest  = types.CreateVaultRequest
-	CreateMemoryRequest = types.CreateMemoryRequest
-	AddEntryRequest     = types.AddEntryRequest
-	SearchRequest       = types.SearchRequest
+	CreateVaultRequest             = types.CreateVaultRequest
+	CreateVaultFromTemplateRequest = types.CreateVaultFromTemplateRequest
+	CloneVaultRequest              = types.CloneVaultRequest
+	BootstrapRequest               = types.BootstrapRequest
+	BootstrapMemory                = types.BootstrapMemory
+	CreateMemoryRequest            = types.CreateMemoryRequest
+	UpdateTitleRequest             = types.UpdateTitleRequest
+	AddEntryRequest                = types.AddEntryRequest
+	CorrectEntryRequest            = types.CorrectEntryRequest
+	SearchRequest                  = types.SearchRequest
+	SearchMustNot                  = types.SearchMustNot
+	BatchSearchRequest             = types.BatchSearchRequest
+	ScanEntriesRequest             = types.ScanEntriesRequest
+	PatchEntryTagsRequest          = types.PatchEntryTagsRequest
+	SearchFeedbackRequest          = types.SearchFeedbackRequest
+	ExplainSearchRequest           = types.ExplainSearchRequest
+	CreateIngestionBatchRequest    = types.CreateIngestionBatchRequest
+	SummarizeMemoryRequest         = types.SummarizeMemoryRequest
+	ExportSearchLogRequest         = types.ExportSearchLogRequest
+	CreateWebhookRequest           = types.CreateWebhookRequest
+	DeleteEntriesRequest           = types.DeleteEntriesRequest
 
 	// Entities
-	Vault  = types.Vault
-	Memory = types.Memory
-	Entry  = types.Entry
+	Vault             = types.Vault
+	VaultTemplate     = types.VaultTemplate
+	TemplateMemory    = types.TemplateMemory
+	TemplatedVault    = types.TemplatedVault
+	ClonedVault       = types.ClonedVault
+	ClonedMemory      = types.ClonedMemory
+	BootstrapResponse = types.BootstrapResponse
+	Memory            = types.Memory
+	SearchBoost       = types.SearchBoost
+	Entry             = types.Entry
+	IngestionBatch    = types.IngestionBatch
+	EntrySession      = types.EntrySession
+	ExportedEntry     = types.ExportedEntry
+	EntryEmbedding    = types.EntryEmbedding
+	VaultStats        = types.VaultStats
+	MemoryStats       = types.MemoryStats
+	ObjectCounts      = types.ObjectCounts
+	IndexStatus       = types.IndexStatus
+	IndexingCounts    = types.IndexingCounts
+	ActorSettings     = types.ActorSettings
+	EntityAlias       = types.EntityAlias
+	Webhook           = types.Webhook
+	Job               = types.Job
+	WebhookMapping    = types.WebhookMapping
 
 	// Responses
-	EnqueueAck          = types.EnqueueAck
-	ListEntriesResponse = types.ListEntriesResponse
-	SearchEntry         = types.SearchEntry
-	SearchResponse      = types.SearchResponse
+	EnqueueAck                     = types.EnqueueAck
+	SummarizeMemoryResult          = types.SummarizeMemoryResult
+	ListEntriesResponse            = types.ListEntriesResponse
+	ListSessionsResponse           = types.ListSessionsResponse
+	ListEntityAliasesResponse      = types.ListEntityAliasesResponse
+	ListWebhooksResponse           = types.ListWebhooksResponse
+	Trash                          = types.Trash
+	ScanEntriesResponse            = types.ScanEntriesResponse
+	PatchEntryTagsResponse         = types.PatchEntryTagsResponse
+	AddEntriesResponse             = types.AddEntriesResponse
+	SearchEntry                    = types.SearchEntry
+	SimilarEntries                 = types.SimilarEntries
+	SearchResponse                 = types.SearchResponse
+	BatchSearchResult              = types.BatchSearchResult
+	BatchSearchResponse            = types.BatchSearchResponse
+	SearchTimeWindow               = types.SearchTimeWindow
+	ScopedMemory                   = types.ScopedMemory
+	HealthResponse                 = types.HealthResponse
+	Capabilities                   = types.Capabilities
+	SearchMetrics                  = types.SearchMetrics
+	SearchExplanation              = types.SearchExplanation
+	ContextFetch                   = types.ContextFetch
+	IndexFreshness                 = types.IndexFreshness
+	ContextSection                 = types.ContextSection
+	StructuredContext              = types.StructuredContext
+	EntrySummary                   = types.EntrySummary
+	CheckedContext                 = types.CheckedContext
+	ContextCheck                   = types.ContextCheck
+	ContextSuggestion              = types.ContextSuggestion
+	ContextSectionsOptions         = types.ContextSectionsOptions
+	ContextDocument                = types.ContextDocument
+	EntryUsage                     = types.EntryUsage
+	UsageTotals                    = types.UsageTotals
+	UsageReport                    = types.UsageReport
+	ContextDocumentLimits          = types.ContextDocumentLimits
+	PutContextLargeResult          = types.PutContextLargeResult
+	RollbackIngestionBatchResponse = types.RollbackIngestionBatchResponse
+)
+
+// FlushReport is returned by Shutdown: the outcome of async writes (AddEntry,
+// PutContext) keyed by memoryID.
+type (
+	FlushReport = shardqueue.Report
+	MemoryFlush = shardqueue.KeyReport
 )
 
+// Entry quality signals for RecordEntrySignal.
+const (
+	SignalUseful    = types.SignalUseful
+	SignalIncorrect = types.SignalIncorrect
+	SignalOutdated  = types.SignalOutdated
+)
+
+// Search orderings for SearchRequest.RankBy.
+const (
+	RankByRelevance = types.RankByRelevance
+	RankByRecency   = types.RankByRecency
+	RankByHybrid    = types.RankByHybrid
+)
+
+// Job states reported in Job.Status.
+const (
+	JobQueued    = types.JobQueued
+	JobRunning   = types.JobRunning
+	JobSucceeded = types.JobSucceeded
+	JobFailed    = types.JobFailed
+	JobCancelled = types.JobCancelled
+)
+
+// Entry fields weighed by SearchBoost.FieldWeights.
+const (
+	SearchFieldSummary  = types.SearchFieldSummary
+	SearchFieldRawEntry = types.SearchFieldRawEntry
+	SearchFieldTags     = types.SearchFieldTags
+)
+
+// GroupBySession groups search hits by conversation session (SearchRequest.GroupBy).
+const GroupBySession = types.GroupBySession
+
 // See errors.go for exported error variables (e.g., ErrNotFound).
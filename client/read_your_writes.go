@@ -0,0 +1,204 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/mycelian/mycelian-memory/client/internal/api"
+	"github.com/rs/zerolog/log"
+)
+
+// Entries are indexed for search asynchronously, so an agent searching right
+// after AddEntry usually misses what it just wrote. With WithReadYourWrites
+// the client remembers the entries it wrote and merges them into Search
+// results, scored by keyword overlap with the query and flagged
+// LocalPending, until the server returns them itself.
+
+// WithReadYourWrites merges this client's recent AddEntry writes into Search
+// results until a search returns them from the server, the write fails
+// permanently, or maxAge passes (the fallback for entries that never rank).
+func WithReadYourWrites(maxAge time.Duration) Option {
+	return func(c *Client) error {
+		if maxAge <= 0 {
+			return fmt.Errorf("read-your-writes max age must be > 0")
+		}
+		c.pending = &pendingWrites{maxAge: maxAge, byMemory: map[string][]*pendingEntry{}}
+		return nil
+	}
+}
+
+func (c *Client) addEntryTracked(ctx context.Context, vaultID, memID string, req AddEntryRequest) (*EnqueueAck, error) {
+	p := c.pending.track(vaultID, memID, req)
+	ack, err := api.AddEntryNotify(ctx, c.exec, c.http, c.baseURL, vaultID, memID, req, func(created *Entry, err error) {
+		c.pending.settle(p, created, err)
+	})
+	if err != nil {
+		c.pending.settle(p, nil, err)
+	}
+	return ack, err
+}
+
+// pendingWrites holds entries written by this client that search has not
+// returned yet, per memory.
+type pendingWrites struct {
+	mu       sync.Mutex
+	maxAge   time.Duration
+	byMemory map[string][]*pendingEntry
+}
+
+type pendingEntry struct {
+	entry Entry
+	at    time.Time
+}
+
+func (pw *pendingWrites) track(vaultID, memID string, req AddEntryRequest) *pendingEntry {
+	now := time.Now()
+	p := &pendingEntry{at: now, entry: Entry{
+		MemoryID:     memID,
+		VaultID:      vaultID,
+		CreationTime: now.UTC(),
+		RawEntry:     req.RawEntry,
+		Summary:      req.Summary,
+		Tags:         req.Tags,
+		Metadata:     req.Metadata,
+		SourceSystem: req.SourceSystem,
+		SourceID:     req.SourceID,
+		SessionID:    req.SessionID,
+	}}
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+	pw.byMemory[memID] = append(pw.byMemory[memID], p)
+	return p
+}
+
+// settle records the outcome of a write: the server's entry ID, or removal
+// on error.
+func (pw *pendingWrites) settle(p *pendingEntry, created *Entry, err error) {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+	if err == nil {
+		p.entry.ID = created.ID
+		return
+	}
+	pw.removeLocked(p.entry.MemoryID, func(q *pendingEntry) bool { return q == p })
+}
+
+func (pw *pendingWrites) removeLocked(memID string, drop func(*pendingEntry) bool) {
+	list := pw.byMemory[memID]
+	kept := list[:0]
+	for _, q := range list {
+		if !drop(q) {
+			kept = append(kept, q)
+		}
+	}
+	for i := len(kept); i < len(list); i++ {
+		list[i] = nil
+	}
+	if len(kept) == 0 {
+		delete(pw.byMemory, memID)
+		return
+	}
+	pw.byMemory[memID] = kept
+}
+
+// merge drops pending entries the server returned or that expired, then adds
+// the remaining ones matching req to resp, keeping the best TopK by score.
+func (pw *pendingWrites) merge(req SearchRequest, resp *SearchResponse) {
+	seen := make(map[string]bool, len(resp.Entries))
+	for _, e := range resp.Entries {
+		seen[e.ID] = true
+	}
+	terms := queryTerms(req.Query)
+	now := time.Now()
+
+	pw.mu.Lock()
+	pw.removeLocked(req.MemoryID, func(p *pendingEntry) bool {
+		return (p.entry.ID != "" && seen[p.entry.ID]) || now.Sub(p.at) > pw.maxAge
+	})
+	var local []SearchEntry
+	for _, p := range pw.byMemory[req.MemoryID] {
+		if !pendingMatches(p.entry, req) || !resp.TimeWindow.Contains(p.entry.CreationTime) {
+			continue
+		}
+		if score := keywordScore(terms, p.entry); score > 0 {
+			local = append(local, SearchEntry{Entry: p.entry, Score: score, LocalPending: true})
+		}
+	}
+	pw.mu.Unlock()
+
+	if len(local) == 0 {
+		return
+	}
+	log.Debug().Str("memory_id", req.MemoryID).Int("local_pending", len(local)).Msg("merging unindexed writes into search results")
+	resp.Entries = append(resp.Entries, local...)
+	sort.SliceStable(resp.Entries, func(i, j int) bool { return resp.Entries[i].Score > resp.Entries[j].Score })
+	if req.TopK > 0 && len(resp.Entries) > req.TopK {
+		resp.Entries = resp.Entries[:req.TopK]
+	}
+	resp.Count = len(resp.Entries)
+}
+
+// pendingMatches applies the request's session, tag and mustNot filters.
+func pendingMatches(e Entry, req SearchRequest) bool {
+	if req.SessionID != "" && e.SessionID != req.SessionID {
+		return false
+	}
+	for k, v := range req.Tags {
+		if got, ok := e.Tags[k]; !ok || got != v {
+			return false
+		}
+	}
+	if m := req.MustNot; m != nil {
+		for _, id := range m.EntryIDs {
+			if e.ID != "" && e.ID == id {
+				return false
+			}
+		}
+		for _, id := range m.MemoryIDs {
+			if e.MemoryID == id {
+				return false
+			}
+		}
+		for _, tag := range m.Tags {
+			if _, ok := e.Tags[tag]; ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func queryTerms(q string) []string {
+	return strings.FieldsFunc(strings.ToLower(q), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// keywordScore is the fraction of distinct query terms found in the entry's
+// raw text or summary.
+func keywordScore(terms []string, e Entry) float64 {
+	if len(terms) == 0 {
+		return 0
+	}
+	words := map[string]bool{}
+	for _, w := range queryTerms(e.RawEntry + " " + e.Summary) {
+		words[w] = true
+	}
+	distinct := map[string]bool{}
+	hits := 0
+	for _, t := range terms {
+		if distinct[t] {
+			continue
+		}
+		distinct[t] = true
+		if words[t] {
+			hits++
+		}
+	}
+	return float64(hits) / float64(len(distinct))
+}
@@ -0,0 +1,90 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestReadYourWritesMergesUntilIndexed(t *testing.T) {
+	var indexed atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v0/search":
+			if body, _ := io.ReadAll(r.Body); strings.Contains(string(body), `"window":`) {
+				_, _ = w.Write([]byte(`{"entries":[],"count":0,"timeWindow":{"since":"2026-01-01T00:00:00Z","until":"2026-01-02T00:00:00Z"}}`))
+				return
+			}
+			if indexed.Load() {
+				_, _ = w.Write([]byte(`{"entries":[{"entryId":"e-new","memoryId":"m1","rawEntry":"prefers a window seat","score":0.9}],"count":1}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"entries":[{"entryId":"e-old","memoryId":"m1","score":0.4}],"count":1}`))
+		case strings.HasSuffix(r.URL.Path, "/m1/entries"):
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"entryId":"e-new"}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest) // permanent failure for m2
+		}
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, WithAPIKey("k"), WithReadYourWrites(time.Minute))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer func() { _ = c.Close() }()
+	ctx := context.Background()
+
+	if _, err := c.AddEntry(ctx, "v1", "m1", AddEntryRequest{RawEntry: "User prefers a window seat", Summary: "seat preference"}); err != nil {
+		t.Fatalf("AddEntry: %v", err)
+	}
+	if _, err := c.AddEntry(ctx, "v1", "m2", AddEntryRequest{RawEntry: "window seat", Summary: "s"}); err != nil {
+		t.Fatalf("AddEntry: %v", err)
+	}
+	_ = c.AwaitConsistency(ctx, "m1")
+	_ = c.AwaitConsistency(ctx, "m2")
+
+	resp, err := c.Search(ctx, SearchRequest{MemoryID: "m1", Query: "window seat", TopK: 5})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if resp.Count != 2 || !resp.Entries[0].LocalPending || resp.Entries[0].ID != "e-new" || resp.Entries[0].Score != 1 {
+		t.Fatalf("expected pending write merged first: %+v", resp.Entries)
+	}
+	if r, _ := c.Search(ctx, SearchRequest{MemoryID: "m1", Query: "unrelated"}); r.Count != 1 {
+		t.Fatalf("non-matching query should not include the write: %+v", r.Entries)
+	}
+	if r, _ := c.Search(ctx, SearchRequest{MemoryID: "m2", Query: "window"}); r.Count != 1 || r.Entries[0].LocalPending {
+		t.Fatalf("permanently failed write should be dropped: %+v", r.Entries)
+	}
+
+	if r, _ := c.Search(ctx, SearchRequest{MemoryID: "m1", Query: "window seat", Window: "yesterday"}); r.Count != 0 {
+		t.Fatalf("write outside the searched time window should not be merged: %+v", r.Entries)
+	}
+
+	// Once the server returns the entry it is no longer tracked.
+	indexed.Store(true)
+	resp, _ = c.Search(ctx, SearchRequest{MemoryID: "m1", Query: "window seat"})
+	if resp.Count != 1 || resp.Entries[0].LocalPending {
+		t.Fatalf("indexed entry should come from the server only: %+v", resp.Entries)
+	}
+	if n := len(c.pending.byMemory); n != 0 {
+		t.Fatalf("expected no pending writes, got %d memories", n)
+	}
+}
+
+func TestKeywordScore(t *testing.T) {
+	e := Entry{RawEntry: "Flight to Paris, window seat", Summary: "travel"}
+	if s := keywordScore(queryTerms("paris WINDOW aisle"), e); s < 0.66 || s > 0.67 {
+		t.Fatalf("score = %v, want 2/3", s)
+	}
+	if s := keywordScore(queryTerms(""), e); s != 0 {
+		t.Fatalf("empty query score = %v", s)
+	}
+}
@@ -0,0 +1,178 @@
+package client
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mycelian/mycelian-memory/client/internal/api"
+	clienterrors "github.com/mycelian/mycelian-memory/client/internal/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// Search is read-only, so retrying it is always safe. With WithSearchRetries
+// transient failures are retried with exponential backoff; with
+// WithSearchCache the last good response of each request is kept so that a
+// search that still fails degrades to that result, flagged Stale, instead of
+// surfacing an error to the agent mid-conversation.
+
+// WithSearchRetries retries searches that fail with a network error, 408, 429
+// or 5xx up to retries more times, waiting backoff, 2*backoff, 4*backoff, ...
+// between attempts. Client errors (other 4xx) and context cancellation are
+// never retried.
+func WithSearchRetries(retries int, backoff time.Duration) Option {
+	return func(c *Client) error {
+		if retries < 0 {
+			return fmt.Errorf("search retries must be >= 0")
+		}
+		if backoff <= 0 {
+			return fmt.Errorf("search retry backoff must be > 0")
+		}
+		c.searchRetries = retries
+		c.searchBackoff = backoff
+		return nil
+	}
+}
+
+// WithSearchCache keeps the latest successful response of up to entries
+// distinct search requests (least recently used evicted). When a search
+// fails after its retries, a cached response for the identical request no
+// older than maxAge (0 means any age) is returned with Stale set.
+func WithSearchCache(entries int, maxAge time.Duration) Option {
+	return func(c *Client) error {
+		if entries <= 0 {
+			return fmt.Errorf("search cache size must be > 0")
+		}
+		if maxAge < 0 {
+			return fmt.Errorf("search cache max age must be >= 0")
+		}
+		c.searchCache = newSearchCache(entries, maxAge)
+		return nil
+	}
+}
+
+func (c *Client) searchWithFallback(ctx context.Context, req SearchRequest) (*SearchResponse, error) {
+	var key string
+	if c.searchCache != nil {
+		key = searchKey(req)
+	}
+	resp, err := c.searchWithRetries(ctx, req)
+	if err == nil {
+		if c.searchCache != nil {
+			c.searchCache.put(key, resp)
+		}
+		return resp, nil
+	}
+	if c.searchCache == nil || !transientSearchError(ctx, err) {
+		return nil, err
+	}
+	stale, ok := c.searchCache.get(key)
+	if !ok {
+		return nil, err
+	}
+	log.Warn().Err(err).Str("memory_id", req.MemoryID).Time("cached_at", *stale.CachedAt).Msg("search failed; serving cached result")
+	return stale, nil
+}
+
+func (c *Client) searchWithRetries(ctx context.Context, req SearchRequest) (*SearchResponse, error) {
+	wait := c.searchBackoff
+	for attempt := 0; ; attempt++ {
+		resp, err := api.Search(ctx, c.http, c.baseURL, req)
+		if err == nil || attempt >= c.searchRetries || !transientSearchError(ctx, err) {
+			return resp, err
+		}
+		log.Debug().Err(err).Int("attempt", attempt+1).Dur("backoff", wait).Msg("retrying search")
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, err
+		case <-t.C:
+		}
+		wait *= 2
+	}
+}
+
+// transientSearchError reports whether err may succeed on retry: network
+// failures and recoverable HTTP statuses, unless the caller gave up.
+func transientSearchError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var ce *clienterrors.ClassifiedError
+	if errors.As(err, &ce) {
+		return ce.Category == clienterrors.Recoverable
+	}
+	return true
+}
+
+// searchKey hashes the full request so only identical searches share results.
+func searchKey(req SearchRequest) string {
+	b, _ := json.Marshal(req)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// searchCache is a small LRU of successful search responses.
+type searchCache struct {
+	mu     sync.Mutex
+	size   int
+	maxAge time.Duration
+	order  *list.List // front is most recently used
+	items  map[string]*list.Element
+}
+
+type cachedSearch struct {
+	key  string
+	resp SearchResponse
+	at   time.Time
+}
+
+func newSearchCache(size int, maxAge time.Duration) *searchCache {
+	return &searchCache{size: size, maxAge: maxAge, order: list.New(), items: map[string]*list.Element{}}
+}
+
+func (sc *searchCache) put(key string, resp *SearchResponse) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	entry := cachedSearch{key: key, resp: *resp, at: time.Now()}
+	entry.resp.Entries = append([]SearchEntry(nil), resp.Entries...)
+	if el, ok := sc.items[key]; ok {
+		el.Value = entry
+		sc.order.MoveToFront(el)
+		return
+	}
+	sc.items[key] = sc.order.PushFront(entry)
+	if sc.order.Len() > sc.size {
+		oldest := sc.order.Back()
+		sc.order.Remove(oldest)
+		delete(sc.items, oldest.Value.(cachedSearch).key)
+	}
+}
+
+// get returns a copy of the cached response marked stale.
+func (sc *searchCache) get(key string) (*SearchResponse, bool) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	el, ok := sc.items[key]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(cachedSearch)
+	if sc.maxAge > 0 && time.Since(entry.at) > sc.maxAge {
+		return nil, false
+	}
+	sc.order.MoveToFront(el)
+	out := entry.resp
+	out.Entries = append([]SearchEntry(nil), entry.resp.Entries...)
+	out.Stale = true
+	at := entry.at
+	out.CachedAt = &at
+	return &out, true
+}
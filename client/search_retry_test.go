@@ -0,0 +1,87 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSearchRetriesThenServesStaleCache(t *testing.T) {
+	var calls, failing atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if failing.Load() > 0 {
+			failing.Add(-1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"entries":[{"entryId":"e1"}],"count":1}`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, WithAPIKey("k"), WithSearchRetries(2, time.Millisecond), WithSearchCache(8, 0))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer func() { _ = c.Close() }()
+	ctx := context.Background()
+	req := SearchRequest{MemoryID: "m1", Query: "q"}
+
+	// Two failures are absorbed by the retries.
+	failing.Store(2)
+	resp, err := c.Search(ctx, req)
+	if err != nil || resp.Stale || calls.Load() != 3 {
+		t.Fatalf("retried search: resp=%+v err=%v calls=%d", resp, err, calls.Load())
+	}
+
+	// Retries exhausted: the cached result is served, flagged stale.
+	failing.Store(10)
+	resp, err = c.Search(ctx, req)
+	if err != nil || !resp.Stale || resp.CachedAt == nil || len(resp.Entries) != 1 {
+		t.Fatalf("stale fallback: resp=%+v err=%v", resp, err)
+	}
+
+	// A different request has nothing cached and surfaces the error.
+	if _, err := c.Search(ctx, SearchRequest{MemoryID: "m1", Query: "other"}); err == nil {
+		t.Fatal("expected error for uncached query")
+	}
+}
+
+func TestSearchDoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, WithAPIKey("k"), WithSearchRetries(3, time.Millisecond), WithSearchCache(8, 0))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer func() { _ = c.Close() }()
+	if _, err := c.Search(context.Background(), SearchRequest{MemoryID: "m1", Query: "q"}); err == nil || calls.Load() != 1 {
+		t.Fatalf("expected one failed call, got %d err=%v", calls.Load(), err)
+	}
+}
+
+func TestSearchCacheEvictsAndExpires(t *testing.T) {
+	sc := newSearchCache(2, time.Hour)
+	for _, k := range []string{"a", "b", "c"} {
+		sc.put(k, &SearchResponse{Count: 1})
+	}
+	if _, ok := sc.get("a"); ok {
+		t.Fatal("least recently used entry should be evicted")
+	}
+	if r, ok := sc.get("c"); !ok || !r.Stale {
+		t.Fatalf("expected stale copy of c, got %+v", r)
+	}
+	sc.maxAge = time.Nanosecond
+	time.Sleep(time.Millisecond)
+	if _, ok := sc.get("c"); ok {
+		t.Fatal("expired entry should not be served")
+	}
+}
@@ -0,0 +1,69 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mycelian/mycelian-memory/client/internal/api"
+)
+
+// AddEntry normally returns as soon as the write is queued, before the
+// server has assigned an entryId. Integrations that link to or attach to an
+// entry need the ID right away; AddEntrySync, or AddEntry under
+// WithSyncWrites, waits for the created entry instead.
+//
+// The write still goes through the memory's FIFO queue, so it lands after
+// every earlier write to the memory and recoverable failures are retried as
+// usual; only the caller blocks.
+
+// WithSyncWrites makes AddEntry wait until the entry is created and return it
+// in EnqueueAck.Entry with Status "created".
+func WithSyncWrites() Option {
+	return func(c *Client) error {
+		c.syncWrites = true
+		return nil
+	}
+}
+
+// AddEntrySync writes an entry and returns it as created by the server, with
+// its entryId and creationTime. It returns the error of a permanently failed
+// write, or ctx's error if ctx ends first; the queued write may then still
+// be applied later.
+func (c *Client) AddEntrySync(ctx context.Context, vaultID, memID string, req AddEntryRequest) (*Entry, error) {
+	if err := c.validateMetadata(req.Metadata); err != nil {
+		return nil, err
+	}
+	type result struct {
+		entry *Entry
+		err   error
+	}
+	done := make(chan result, 1)
+	var p *pendingEntry
+	if c.pending != nil {
+		p = c.pending.track(vaultID, memID, req)
+	}
+	_, err := api.AddEntryNotify(ctx, c.exec, c.http, c.baseURL, vaultID, memID, req, func(created *Entry, err error) {
+		if p != nil {
+			c.pending.settle(p, created, err)
+		}
+		done <- result{created, err}
+	})
+	if err != nil {
+		if p != nil {
+			c.pending.settle(p, nil, err)
+		}
+		return nil, err
+	}
+	select {
+	case r := <-done:
+		if r.err != nil {
+			return nil, r.err
+		}
+		if r.entry.ID == "" {
+			return nil, fmt.Errorf("add entry: server response carried no entryId")
+		}
+		return r.entry, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
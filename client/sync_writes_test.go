@@ -0,0 +1,43 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSyncWritesReturnCreatedEntry(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/m2/entries") {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"rawEntry is required"}`))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"entryId":"e-42","memoryId":"m1","vaultId":"v1","creationTime":"2026-03-04T09:00:00Z","rawEntry":"hi"}`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, WithAPIKey("k"), WithSyncWrites())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer func() { _ = c.Close() }()
+	ctx := context.Background()
+
+	ack, err := c.AddEntry(ctx, "v1", "m1", AddEntryRequest{RawEntry: "hi", Summary: "s"})
+	if err != nil {
+		t.Fatalf("AddEntry: %v", err)
+	}
+	want := time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC)
+	if ack.Status != "created" || ack.Entry == nil || ack.Entry.ID != "e-42" || !ack.Entry.CreationTime.Equal(want) {
+		t.Fatalf("ack = %+v", ack)
+	}
+
+	if _, err := c.AddEntrySync(ctx, "v1", "m2", AddEntryRequest{RawEntry: "x", Summary: "s"}); err == nil || !strings.Contains(err.Error(), "400") {
+		t.Fatalf("permanent failure: want the 400 error, got %v", err)
+	}
+}
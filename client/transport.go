@@ -0,0 +1,104 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// The client starts on http.DefaultTransport, which keeps only two idle
+// connections per host. Callers issuing many concurrent requests (e.g. a
+// search-heavy benchmark) then open and close a connection for most of them.
+// WithConnectionPool keeps enough connections alive for the expected
+// concurrency, and WithHTTP2 multiplexes requests over a few connections.
+
+// WithConnectionPool keeps up to maxIdlePerHost idle keep-alive connections
+// to the server, each closed after idleTimeout unused (0 keeps the 90s
+// default). maxPerHost > 0 caps open connections; further requests wait for
+// one to free up.
+func WithConnectionPool(maxIdlePerHost, maxPerHost int, idleTimeout time.Duration) Option {
+	return func(c *Client) error {
+		if maxIdlePerHost <= 0 || maxPerHost < 0 || idleTimeout < 0 {
+			return fmt.Errorf("connection pool: maxIdlePerHost must be > 0, maxPerHost and idleTimeout >= 0")
+		}
+		t, err := c.tunableTransport()
+		if err != nil {
+			return err
+		}
+		t.MaxIdleConnsPerHost = maxIdlePerHost
+		if t.MaxIdleConns != 0 && t.MaxIdleConns < maxIdlePerHost {
+			t.MaxIdleConns = maxIdlePerHost
+		}
+		t.MaxConnsPerHost = maxPerHost
+		if idleTimeout > 0 {
+			t.IdleConnTimeout = idleTimeout
+		}
+		return nil
+	}
+}
+
+// WithHTTP2 sends requests over HTTP/2. For https base URLs it is negotiated
+// through TLS; for http base URLs the client speaks unencrypted HTTP/2 with
+// prior knowledge, which needs MEMORY_SERVER_HTTP_H2C on the server. Idle
+// connections are pinged after pingInterval (0 disables pings) so dead ones
+// are dropped before a request uses them.
+func WithHTTP2(pingInterval time.Duration) Option {
+	return func(c *Client) error {
+		if pingInterval < 0 {
+			return fmt.Errorf("http2 ping interval must be >= 0")
+		}
+		t, err := c.tunableTransport()
+		if err != nil {
+			return err
+		}
+		t.ForceAttemptHTTP2 = true
+		if strings.HasPrefix(c.baseURL, "http://") {
+			protocols := new(http.Protocols)
+			protocols.SetUnencryptedHTTP2(true)
+			t.Protocols = protocols
+		}
+		t.HTTP2 = &http.HTTP2Config{SendPingTimeout: pingInterval}
+		return nil
+	}
+}
+
+// WithUnixSocket sends every request over the unix socket at path instead of
+// dialing the base URL's host, e.g. to a local proxy such as the
+// mycelianCli daemon that keeps a warm connection to the server. The base
+// URL still sets the request path and Host header.
+func WithUnixSocket(path string) Option {
+	return func(c *Client) error {
+		if path == "" {
+			return fmt.Errorf("unix socket path cannot be empty")
+		}
+		t, err := c.tunableTransport()
+		if err != nil {
+			return err
+		}
+		var d net.Dialer
+		t.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return d.DialContext(ctx, "unix", path)
+		}
+		return nil
+	}
+}
+
+// tunableTransport returns the *http.Transport beneath the debug wrapper,
+// replacing the shared http.DefaultTransport with a private clone first.
+func (c *Client) tunableTransport() (*http.Transport, error) {
+	rt := &c.http.Transport
+	if dt, ok := (*rt).(*debugTransport); ok {
+		rt = &dt.base
+	}
+	if *rt == http.DefaultTransport {
+		*rt = http.DefaultTransport.(*http.Transport).Clone()
+	}
+	t, ok := (*rt).(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("transport options need an *http.Transport, have %T", *rt)
+	}
+	return t, nil
+}
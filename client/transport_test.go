@@ -0,0 +1,132 @@
+package client
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newH2CServer(handler http.Handler) *httptest.Server {
+	srv := httptest.NewUnstartedServer(handler)
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetUnencryptedHTTP2(true)
+	srv.Config.Protocols = protocols
+	srv.Start()
+	return srv
+}
+
+func TestWithHTTP2UsesH2C(t *testing.T) {
+	var proto atomic.Int32
+	srv := newH2CServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proto.Store(int32(r.ProtoMajor))
+		_, _ = w.Write([]byte(`{"status":"UP"}`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, WithAPIKey("k"), WithConnectionPool(32, 0, time.Minute), WithHTTP2(0), WithDebugLogging(false))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer func() { _ = c.Close() }()
+	if _, err := c.Health(context.Background()); err != nil {
+		t.Fatalf("Health: %v", err)
+	}
+	if proto.Load() != 2 {
+		t.Fatalf("request used HTTP/%d, want HTTP/2", proto.Load())
+	}
+	if http.DefaultTransport.(*http.Transport).MaxIdleConnsPerHost != 0 {
+		t.Fatalf("tuning changed http.DefaultTransport")
+	}
+}
+
+func TestWithConnectionPoolBelowDebugLogging(t *testing.T) {
+	c, err := New("http://localhost:1", WithAPIKey("k"), WithDebugLogging(true), WithConnectionPool(64, 128, 0))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	tr := c.http.Transport.(*apiKeyTransport).base.(*debugTransport).base.(*http.Transport)
+	if tr.MaxIdleConnsPerHost != 64 || tr.MaxConnsPerHost != 128 {
+		t.Fatalf("pool = %d idle / %d max", tr.MaxIdleConnsPerHost, tr.MaxConnsPerHost)
+	}
+	if _, err := New("http://localhost:1", WithAPIKey("k"), WithConnectionPool(0, 0, 0)); err == nil {
+		t.Fatalf("expected error for maxIdlePerHost 0")
+	}
+}
+
+func TestWithUnixSocket(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "s.sock")
+	ln, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	var host atomic.Value
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host.Store(r.Host)
+		_, _ = w.Write([]byte(`{"status":"UP"}`))
+	})}
+	go func() { _ = srv.Serve(ln) }()
+	defer func() { _ = srv.Close() }()
+
+	// Nothing listens on the base URL's port; the request must use the socket.
+	c, err := New("http://memory.internal:1", WithAPIKey("k"), WithUnixSocket(sock))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer func() { _ = c.Close() }()
+	if _, err := c.Health(context.Background()); err != nil {
+		t.Fatalf("Health: %v", err)
+	}
+	if host.Load() != "memory.internal:1" {
+		t.Fatalf("Host = %v, want the base URL's host", host.Load())
+	}
+	if _, err := New("http://localhost:1", WithAPIKey("k"), WithUnixSocket("")); err == nil {
+		t.Fatalf("expected error for empty path")
+	}
+}
+
+// BenchmarkSearchTransport issues concurrent searches, as the benchmarker's
+// search-heavy workload does, with the default transport and the tuned ones:
+//
+//	go test -run ^$ -bench SearchTransport ./client
+//
+// The default transport keeps two idle connections, so most requests dial a
+// new one; the pooled and HTTP/2 transports reuse connections.
+func BenchmarkSearchTransport(b *testing.B) {
+	srv := newH2CServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"entries":[],"count":0}`))
+	}))
+	defer srv.Close()
+
+	for _, bc := range []struct {
+		name string
+		opts []Option
+	}{
+		{"default", nil},
+		{"pooled", []Option{WithConnectionPool(64, 0, 0)}},
+		{"h2c", []Option{WithHTTP2(0)}},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			c, err := New(srv.URL, append(bc.opts, WithAPIKey("k"))...)
+			if err != nil {
+				b.Fatalf("New: %v", err)
+			}
+			defer func() { _ = c.Close() }()
+			b.SetParallelism(16)
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					if _, err := c.Search(context.Background(), SearchRequest{MemoryID: "m1", Query: "what did we decide"}); err != nil {
+						b.Error(err)
+						return
+					}
+				}
+			})
+		})
+	}
+}
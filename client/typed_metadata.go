@@ -0,0 +1,228 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Entry metadata is a free-form JSON object and tags a string map. Go
+// applications storing structured data, such as tool results, can register
+// struct types with WithMetadataType and write and read them with
+// SetTypedMetadata and GetTypedMetadata (SetTypedTags and GetTypedTags for
+// tags) instead of building maps by hand.
+//
+// A registered type's name is stored in the metadata under MetadataTypeKey,
+// so AddEntry validates metadata of that type however it was built, and
+// GetTypedMetadata refuses to decode an entry holding another type.
+
+// MetadataTypeKey is the metadata field naming a registered metadata type.
+const MetadataTypeKey = "_type"
+
+// ErrMetadataType is returned when metadata does not hold the requested
+// registered type or fails its validation.
+var ErrMetadataType = errors.New("metadata type mismatch")
+
+// MetadataCodec converts typed values to and from the JSON objects stored as
+// entry metadata and tags. The default codec uses encoding/json.
+type MetadataCodec interface {
+	// Encode converts v to an object.
+	Encode(v any) (map[string]interface{}, error)
+	// Decode fills the value v points to from m.
+	Decode(m map[string]interface{}, v any) error
+}
+
+type jsonMetadataCodec struct{}
+
+func (jsonMetadataCodec) Encode(v any) (map[string]interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("value must encode to a JSON object: %w", err)
+	}
+	return m, nil
+}
+
+func (jsonMetadataCodec) Decode(m map[string]interface{}, v any) error {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// metadataTypes is the client's codec and registered types; see WithMetadataType.
+type metadataTypes struct {
+	codec  MetadataCodec
+	byName map[string]func(MetadataCodec, map[string]interface{}) error
+	byType map[reflect.Type]metadataType
+}
+
+type metadataType struct {
+	name     string
+	validate func(any) error
+}
+
+func newMetadataTypes() *metadataTypes {
+	return &metadataTypes{
+		codec:  jsonMetadataCodec{},
+		byName: map[string]func(MetadataCodec, map[string]interface{}) error{},
+		byType: map[reflect.Type]metadataType{},
+	}
+}
+
+// noMetadataTypes serves clients not built by New.
+var noMetadataTypes = newMetadataTypes()
+
+func (c *Client) metadataTypes() *metadataTypes {
+	if c.metadata == nil {
+		return noMetadataTypes
+	}
+	return c.metadata
+}
+
+// WithMetadataCodec replaces the encoding/json codec used by the typed
+// metadata and tag helpers.
+func WithMetadataCodec(codec MetadataCodec) Option {
+	return func(c *Client) error {
+		if codec == nil {
+			return fmt.Errorf("metadata codec cannot be nil")
+		}
+		c.metadata.codec = codec
+		return nil
+	}
+}
+
+// WithMetadataType registers T as the metadata type name. validate, if not
+// nil, runs whenever metadata of the type is written.
+func WithMetadataType[T any](name string, validate func(T) error) Option {
+	return func(c *Client) error {
+		if name == "" {
+			return fmt.Errorf("metadata type name cannot be empty")
+		}
+		mt := c.metadata
+		t := reflect.TypeFor[T]()
+		if _, dup := mt.byName[name]; dup {
+			return fmt.Errorf("metadata type %q registered twice", name)
+		}
+		if prev, dup := mt.byType[t]; dup {
+			return fmt.Errorf("metadata type %s already registered as %q", t, prev.name)
+		}
+		check := func(v any) error {
+			if validate == nil {
+				return nil
+			}
+			return validate(v.(T))
+		}
+		mt.byType[t] = metadataType{name: name, validate: check}
+		mt.byName[name] = func(codec MetadataCodec, m map[string]interface{}) error {
+			var v T
+			if err := codec.Decode(m, &v); err != nil {
+				return err
+			}
+			return check(v)
+		}
+		return nil
+	}
+}
+
+// validateMetadata checks metadata naming a registered type against it.
+// Unknown type names are passed through unchecked.
+func (c *Client) validateMetadata(m map[string]interface{}) error {
+	mt := c.metadataTypes()
+	name, _ := m[MetadataTypeKey].(string)
+	check, ok := mt.byName[name]
+	if !ok {
+		return nil
+	}
+	if err := check(mt.codec, m); err != nil {
+		return fmt.Errorf("%w: metadata %q: %v", ErrMetadataType, name, err)
+	}
+	return nil
+}
+
+// registered returns T's registration, validating v against it.
+func registered[T any](c *Client, v T) (metadataType, bool, error) {
+	reg, ok := c.metadataTypes().byType[reflect.TypeFor[T]()]
+	if !ok {
+		return metadataType{}, false, nil
+	}
+	if err := reg.validate(v); err != nil {
+		return reg, true, fmt.Errorf("%w: metadata %q: %v", ErrMetadataType, reg.name, err)
+	}
+	return reg, true, nil
+}
+
+// SetTypedMetadata encodes v as req's metadata. When T is registered, v is
+// validated and tagged with its type name.
+func SetTypedMetadata[T any](c *Client, req *AddEntryRequest, v T) error {
+	reg, ok, err := registered(c, v)
+	if err != nil {
+		return err
+	}
+	m, err := c.metadataTypes().codec.Encode(v)
+	if err != nil {
+		return fmt.Errorf("encode metadata: %w", err)
+	}
+	if ok {
+		m[MetadataTypeKey] = reg.name
+	}
+	req.Metadata = m
+	return nil
+}
+
+// GetTypedMetadata decodes e's metadata into a T. When T is registered, the
+// metadata must carry its type name, otherwise ErrMetadataType is returned.
+func GetTypedMetadata[T any](c *Client, e *Entry) (T, error) {
+	var v T
+	mt := c.metadataTypes()
+	if reg, ok := mt.byType[reflect.TypeFor[T]()]; ok {
+		if name, _ := e.Metadata[MetadataTypeKey].(string); name != reg.name {
+			return v, fmt.Errorf("%w: entry %s holds %q, want %q", ErrMetadataType, e.ID, name, reg.name)
+		}
+	}
+	if err := mt.codec.Decode(e.Metadata, &v); err != nil {
+		return v, fmt.Errorf("decode metadata: %w", err)
+	}
+	return v, nil
+}
+
+// SetTypedTags encodes v as req's tags. Every field must encode to a string;
+// use the `json:",string"` option for numbers and booleans. Registered types
+// are validated but, unlike metadata, not tagged with their name.
+func SetTypedTags[T any](c *Client, req *AddEntryRequest, v T) error {
+	if _, _, err := registered(c, v); err != nil {
+		return err
+	}
+	m, err := c.metadataTypes().codec.Encode(v)
+	if err != nil {
+		return fmt.Errorf("encode tags: %w", err)
+	}
+	tags := make(map[string]string, len(m))
+	for k, val := range m {
+		s, ok := val.(string)
+		if !ok {
+			return fmt.Errorf("encode tags: field %q is %T, want string", k, val)
+		}
+		tags[k] = s
+	}
+	req.Tags = tags
+	return nil
+}
+
+// GetTypedTags decodes e's tags into a T; see SetTypedTags.
+func GetTypedTags[T any](c *Client, e *Entry) (T, error) {
+	var v T
+	m := make(map[string]interface{}, len(e.Tags))
+	for k, s := range e.Tags {
+		m[k] = s
+	}
+	if err := c.metadataTypes().codec.Decode(m, &v); err != nil {
+		return v, fmt.Errorf("decode tags: %w", err)
+	}
+	return v, nil
+}
@@ -0,0 +1,93 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+type toolResult struct {
+	Tool     string `json:"tool"`
+	ExitCode int    `json:"exitCode"`
+}
+
+type toolTags struct {
+	Tool  string `json:"tool"`
+	Retry int    `json:"retry,string"`
+}
+
+func TestTypedMetadata(t *testing.T) {
+	var posts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posts.Add(1)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"entryId":"e1","memoryId":"m1","vaultId":"v1","rawEntry":"ran"}`))
+	}))
+	defer srv.Close()
+
+	validate := func(r toolResult) error {
+		if r.Tool == "" {
+			return fmt.Errorf("tool is required")
+		}
+		return nil
+	}
+	c, err := New(srv.URL, WithAPIKey("k"), WithSyncWrites(), WithMetadataType("tool_result", validate))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer func() { _ = c.Close() }()
+
+	var req AddEntryRequest
+	if err := SetTypedMetadata(c, &req, toolResult{ExitCode: 1}); !errors.Is(err, ErrMetadataType) {
+		t.Fatalf("invalid value: want ErrMetadataType, got %v", err)
+	}
+	if err := SetTypedMetadata(c, &req, toolResult{Tool: "go test", ExitCode: 1}); err != nil {
+		t.Fatalf("SetTypedMetadata: %v", err)
+	}
+	if req.Metadata[MetadataTypeKey] != "tool_result" || req.Metadata["tool"] != "go test" {
+		t.Fatalf("metadata = %v", req.Metadata)
+	}
+	if err := SetTypedTags(c, &req, toolTags{Tool: "go", Retry: 2}); err != nil || req.Tags["retry"] != "2" {
+		t.Fatalf("SetTypedTags: %v, tags %v", err, req.Tags)
+	}
+
+	// Hand-built metadata naming the type is validated on write.
+	raw := AddEntryRequest{RawEntry: "ran", Metadata: map[string]interface{}{MetadataTypeKey: "tool_result", "exitCode": 0}}
+	if _, err := c.AddEntry(context.Background(), "v1", "m1", raw); !errors.Is(err, ErrMetadataType) {
+		t.Fatalf("AddEntry with invalid metadata: want ErrMetadataType, got %v", err)
+	}
+	if posts.Load() != 0 {
+		t.Fatalf("invalid write reached the server")
+	}
+	req.RawEntry = "ran"
+	if _, err := c.AddEntry(context.Background(), "v1", "m1", req); err != nil {
+		t.Fatalf("AddEntry: %v", err)
+	}
+
+	e := &Entry{ID: "e1", Metadata: req.Metadata, Tags: req.Tags}
+	got, err := GetTypedMetadata[toolResult](c, e)
+	if err != nil || got != (toolResult{Tool: "go test", ExitCode: 1}) {
+		t.Fatalf("GetTypedMetadata = %+v, %v", got, err)
+	}
+	tags, err := GetTypedTags[toolTags](c, e)
+	if err != nil || tags != (toolTags{Tool: "go", Retry: 2}) {
+		t.Fatalf("GetTypedTags = %+v, %v", tags, err)
+	}
+	other := &Entry{ID: "e2", Metadata: map[string]interface{}{MetadataTypeKey: "note", "tool": "x"}}
+	if _, err := GetTypedMetadata[toolResult](c, other); !errors.Is(err, ErrMetadataType) {
+		t.Fatalf("other type: want ErrMetadataType, got %v", err)
+	}
+}
+
+func TestWithMetadataTypeRejectsDuplicates(t *testing.T) {
+	if _, err := New("http://x", WithAPIKey("k"), WithMetadataType[toolResult]("a", nil), WithMetadataType[toolTags]("a", nil)); err == nil {
+		t.Fatalf("expected error for duplicate name")
+	}
+	if _, err := New("http://x", WithAPIKey("k"), WithMetadataType[toolResult]("a", nil), WithMetadataType[toolResult]("b", nil)); err == nil {
+		t.Fatalf("expected error for duplicate type")
+	}
+}
@@ -0,0 +1,238 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSetVaultReadOnly_AndIsReadOnly(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == "/v0/vaults/v1/read-only":
+			_, _ = w.Write([]byte(`{"vaultId":"v1","readOnly":true}`))
+		default:
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"error":"vault is read-only: v1"}`))
+		}
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, WithAPIKey("k"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer func() { _ = c.Close() }()
+
+	v, err := c.SetVaultReadOnly(context.Background(), "v1", true)
+	if err != nil || !v.ReadOnly {
+		t.Fatalf("SetVaultReadOnly: v=%+v err=%v", v, err)
+	}
+	_, err = c.SetVaultReadOnly(context.Background(), "v2", true)
+	if !IsReadOnly(err) {
+		t.Fatalf("expected read-only error, got %v", err)
+	}
+	if IsReadOnly(ErrBackPressure) {
+		t.Fatal("back-pressure must not be classified as read-only")
+	}
+}
+
+func TestSetMemoryAppendOnly_AndIsAppendOnly(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == "/v0/vaults/v1/memories/m1/append-only":
+			_, _ = w.Write([]byte(`{"memoryId":"m1","vaultId":"v1","appendOnly":true}`))
+		default:
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"error":"memory is append-only: m1"}`))
+		}
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, WithAPIKey("k"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer func() { _ = c.Close() }()
+
+	m, err := c.SetMemoryAppendOnly(context.Background(), "v1", "m1")
+	if err != nil || !m.AppendOnly {
+		t.Fatalf("SetMemoryAppendOnly: m=%+v err=%v", m, err)
+	}
+	err = c.DeleteEntry(context.Background(), "v1", "m1", "e1")
+	if !IsAppendOnly(err) || IsReadOnly(err) {
+		t.Fatalf("expected append-only error, got %v", err)
+	}
+}
+
+func TestSetMemoryEntryRoles(t *testing.T) {
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/v0/vaults/v1/memories/m1/entry-roles" {
+			t.Fatalf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		_, _ = w.Write([]byte(`{"memoryId":"m1","vaultId":"v1"}`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, WithAPIKey("k"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer func() { _ = c.Close() }()
+
+	// Clearing must send an empty list, not null.
+	if _, err := c.SetMemoryEntryRoles(context.Background(), "v1", "m1", nil); err != nil || body != `{"entryRoles":[]}` {
+		t.Fatalf("SetMemoryEntryRoles: body=%s err=%v", body, err)
+	}
+}
+
+func TestSetMemorySearchBoost(t *testing.T) {
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/v0/vaults/v1/memories/m1/search-boost" {
+			t.Fatalf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		_, _ = w.Write([]byte(`{"memoryId":"m1","vaultId":"v1","searchBoost":{"boost":2,"fieldWeights":{"summary":3}}}`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, WithAPIKey("k"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer func() { _ = c.Close() }()
+
+	m, err := c.SetMemorySearchBoost(context.Background(), "v1", "m1", SearchBoost{Boost: 2, FieldWeights: map[string]float64{SearchFieldSummary: 3}})
+	if err != nil || body != `{"boost":2,"fieldWeights":{"summary":3}}` || m.SearchBoost == nil || m.SearchBoost.FieldWeights["summary"] != 3 {
+		t.Fatalf("SetMemorySearchBoost: m=%+v body=%s err=%v", m, body, err)
+	}
+	// Clearing sends an empty object.
+	if _, err := c.SetMemorySearchBoost(context.Background(), "v1", "m1", SearchBoost{}); err != nil || body != `{}` {
+		t.Fatalf("clear: body=%s err=%v", body, err)
+	}
+}
+
+func TestIndexStatusAndRetry(t *testing.T) {
+	var retryBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v0/vaults/v1/memories/m1/index-status":
+			if got := r.URL.Query()["id"]; len(got) != 2 || got[0] != "e1" || got[1] != "c1" {
+				t.Fatalf("unexpected ids %v", got)
+			}
+			_, _ = w.Write([]byte(`{"statuses":{"e1":{"state":"failed","attempts":5,"lastError":"timeout"},"c1":{"state":"indexed","attempts":0}}}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/v0/vaults/v1/memories/m1/index:retry":
+			b, _ := io.ReadAll(r.Body)
+			retryBody = string(b)
+			_, _ = w.Write([]byte(`{"requeued":1}`))
+		default:
+			t.Fatalf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, WithAPIKey("k"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer func() { _ = c.Close() }()
+
+	st, err := c.GetIndexStatus(context.Background(), "v1", "m1", "e1", "c1")
+	if err != nil || st["e1"].State != "failed" || st["e1"].LastError != "timeout" || st["c1"].State != "indexed" {
+		t.Fatalf("GetIndexStatus: %+v %v", st, err)
+	}
+	if n, err := c.RetryIndexing(context.Background(), "v1", "m1", "e1"); err != nil || n != 1 || retryBody != `{"ids":["e1"]}` {
+		t.Fatalf("RetryIndexing: n=%d body=%s err=%v", n, retryBody, err)
+	}
+}
+
+func TestUpdateVaultAndMemory(t *testing.T) {
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch {
+			t.Fatalf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		switch r.URL.Path {
+		case "/v0/vaults/v1":
+			_, _ = w.Write([]byte(`{"vaultId":"v1","title":"work"}`))
+		case "/v0/vaults/v1/memories/m1":
+			_, _ = w.Write([]byte(`{"memoryId":"m1","vaultId":"v1","title":"notes","description":"meeting notes"}`))
+		default:
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"error":"conflict: vault title \"work\" already exists"}`))
+		}
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, WithAPIKey("k"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer func() { _ = c.Close() }()
+
+	title, desc := "work", "meeting notes"
+	v, err := c.UpdateVault(context.Background(), "v1", UpdateTitleRequest{Title: &title})
+	if err != nil || v.Title != "work" {
+		t.Fatalf("UpdateVault: v=%+v err=%v", v, err)
+	}
+	m, err := c.UpdateMemory(context.Background(), "v1", "m1", UpdateTitleRequest{Description: &desc})
+	if err != nil || m.Description != "meeting notes" {
+		t.Fatalf("UpdateMemory: m=%+v err=%v", m, err)
+	}
+	if bodies[0] != `{"title":"work"}` || bodies[1] != `{"description":"meeting notes"}` {
+		t.Fatalf("unexpected request bodies: %q", bodies)
+	}
+	if _, err := c.UpdateVault(context.Background(), "v2", UpdateTitleRequest{Title: &title}); err == nil {
+		t.Fatal("expected conflict error")
+	}
+}
+
+func TestBootstrap(t *testing.T) {
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v0/bootstrap" {
+			t.Fatalf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		status, replayed := http.StatusCreated, "false"
+		if len(bodies) > 1 {
+			status, replayed = http.StatusOK, "true"
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"vault":{"vaultId":"v1","title":"proj"},"memory":{"memoryId":"m1","vaultId":"v1","title":"chat"},"contextId":"c1","replayed":` + replayed + `}`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, WithAPIKey("k"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer func() { _ = c.Close() }()
+
+	req := BootstrapRequest{
+		ClientToken: "agent-1",
+		Vault:       CreateVaultRequest{Title: "proj"},
+		Memory:      BootstrapMemory{Title: "chat", MemoryType: "conversation"},
+		Context:     "goals",
+	}
+	out, err := c.Bootstrap(context.Background(), req)
+	if err != nil || out.Vault.VaultID != "v1" || out.Memory.ID != "m1" || out.ContextID != "c1" || out.Replayed {
+		t.Fatalf("Bootstrap: out=%+v err=%v", out, err)
+	}
+	if want := `{"clientToken":"agent-1","vault":{"title":"proj"},"memory":{"title":"chat","memoryType":"conversation"},"context":"goals"}`; bodies[0] != want {
+		t.Fatalf("body = %s, want %s", bodies[0], want)
+	}
+	if out, err := c.Bootstrap(context.Background(), req); err != nil || !out.Replayed {
+		t.Fatalf("Bootstrap retry: out=%+v err=%v", out, err)
+	}
+}
@@ -27,11 +27,13 @@ require (
 	github.com/jackc/puddle/v2 v2.2.2 // indirect
 	github.com/josharian/intern v1.0.0 // indirect
 	github.com/kelseyhightower/envconfig v1.4.0 // indirect
+	github.com/klauspost/compress v1.18.0 // indirect
 	github.com/mailru/easyjson v0.7.7 // indirect
 	github.com/mattn/go-colorable v0.1.14 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
 	github.com/mitchellh/mapstructure v1.5.0 // indirect
 	github.com/mycelian/mycelian-memory/pkg/devauth v0.0.0 // indirect
+	github.com/mycelian/mycelian-memory/pkg/schema v0.0.0 // indirect
 	github.com/oklog/ulid v1.3.1 // indirect
 	github.com/opentracing/opentracing-go v1.2.0 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
@@ -56,4 +58,6 @@ require (
 
 replace github.com/mycelian/mycelian-memory/pkg/devauth => ../../pkg/devauth
 
+replace github.com/mycelian/mycelian-memory/pkg/schema => ../../pkg/schema
+
 replace github.com/mycelian/mycelian-memory/server => ../../server
@@ -0,0 +1,23 @@
+// Command mycelian-dev provisions the local development stack in one step:
+//
+//	go run ./cmd/mycelian-dev up
+//
+// starts the docker compose services, applies the Postgres schema, creates
+// the Weaviate classes, pulls the Ollama embedding model, waits for
+// memory-service to be healthy and seeds a sample vault. See
+// server/devstack.Run for the commands and flags.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/mycelian/mycelian-memory/server/devstack"
+)
+
+func main() {
+	if err := devstack.Run(os.Args[1:], os.Stdout); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "mycelian-dev:", err)
+		os.Exit(1)
+	}
+}
@@ -54,4 +54,6 @@ require (
 
 replace github.com/mycelian/mycelian-memory/pkg/devauth => ../../pkg/devauth
 
+replace github.com/mycelian/mycelian-memory/pkg/schema => ../../pkg/schema
+
 replace github.com/mycelian/mycelian-memory/server => ../../server
@@ -0,0 +1,194 @@
+// Package agenttools exposes the Mycelian MCP tools to agent stacks that do
+// not speak MCP. It emits the tool catalogue as OpenAI function-calling or
+// Anthropic tool definitions and dispatches the model's tool calls to the
+// same handlers the MCP server uses, so names, parameters and results stay
+// identical across both integrations.
+package agenttools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mycelian/mycelian-memory/client"
+	"github.com/mycelian/mycelian-memory/mcp/internal/handlers"
+)
+
+// Tool is one tool: its name, description and JSON Schema parameters.
+type Tool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Parameters  json.RawMessage `json:"parameters"`
+}
+
+// OpenAITool is a tool definition for the OpenAI Chat Completions and
+// Assistants "tools" array.
+type OpenAITool struct {
+	Type     string         `json:"type"`
+	Function OpenAIFunction `json:"function"`
+}
+
+// OpenAIFunction is the function of an OpenAITool.
+type OpenAIFunction struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Parameters  json.RawMessage `json:"parameters"`
+}
+
+// AnthropicTool is a tool definition for the Anthropic Messages API.
+type AnthropicTool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	InputSchema json.RawMessage `json:"input_schema"`
+}
+
+// Result is a tool's output. When IsError is set the tool failed and Text
+// says why; it is meant to be returned to the model like any other result.
+type Result struct {
+	Text    string
+	IsError bool
+}
+
+// ErrUnknownTool is returned by Call for a name that is not in the catalogue.
+var ErrUnknownTool = errors.New("unknown tool")
+
+// Option configures an Adapter.
+type Option func(*options)
+
+type options struct {
+	destructive bool
+}
+
+// WithDestructiveOps adds the delete_entry and delete_context tools, as the
+// MCP server's --allow-destructive-ops does.
+func WithDestructiveOps() Option {
+	return func(o *options) { o.destructive = true }
+}
+
+// Adapter holds the tool catalogue and dispatches calls through c.
+type Adapter struct {
+	srv   *server.MCPServer
+	tools []Tool
+	names map[string]bool
+}
+
+// New builds the catalogue backed by c.
+func New(c *client.Client, opts ...Option) (*Adapter, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	srv := server.NewMCPServer("mycelian-agenttools", "dev", server.WithToolCapabilities(true))
+	if err := handlers.RegisterAll(srv, c, o.destructive); err != nil {
+		return nil, err
+	}
+	a := &Adapter{srv: srv, names: map[string]bool{}}
+	var list mcp.ListToolsResult
+	if err := a.rpc(context.Background(), string(mcp.MethodToolsList), map[string]any{}, &list); err != nil {
+		return nil, fmt.Errorf("list tools: %w", err)
+	}
+	for _, t := range list.Tools {
+		params, err := objectSchema(t)
+		if err != nil {
+			return nil, fmt.Errorf("tool %s: %w", t.Name, err)
+		}
+		a.tools = append(a.tools, Tool{Name: t.Name, Description: t.Description, Parameters: params})
+		a.names[t.Name] = true
+	}
+	return a, nil
+}
+
+// objectSchema returns t's input schema with "properties" always present;
+// OpenAI rejects object schemas without it.
+func objectSchema(t mcp.Tool) (json.RawMessage, error) {
+	b, err := json.Marshal(t.InputSchema)
+	if err != nil {
+		return nil, err
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(b, &schema); err != nil {
+		return nil, err
+	}
+	if _, ok := schema["properties"]; !ok {
+		schema["properties"] = map[string]any{}
+	}
+	return json.Marshal(schema)
+}
+
+// Tools returns the catalogue sorted by name.
+func (a *Adapter) Tools() []Tool { return a.tools }
+
+// OpenAITools returns the catalogue as OpenAI function definitions.
+func (a *Adapter) OpenAITools() []OpenAITool {
+	out := make([]OpenAITool, len(a.tools))
+	for i, t := range a.tools {
+		out[i] = OpenAITool{Type: "function", Function: OpenAIFunction{Name: t.Name, Description: t.Description, Parameters: t.Parameters}}
+	}
+	return out
+}
+
+// AnthropicTools returns the catalogue as Anthropic tool definitions.
+func (a *Adapter) AnthropicTools() []AnthropicTool {
+	out := make([]AnthropicTool, len(a.tools))
+	for i, t := range a.tools {
+		out[i] = AnthropicTool{Name: t.Name, Description: t.Description, InputSchema: t.Parameters}
+	}
+	return out
+}
+
+// Call runs the tool name with arguments, a JSON object as sent by the
+// model (OpenAI's function.arguments string or Anthropic's tool_use input).
+// Empty arguments mean none. A tool that fails returns a Result with
+// IsError; err is reserved for unknown tools and malformed arguments.
+func (a *Adapter) Call(ctx context.Context, name string, arguments json.RawMessage) (*Result, error) {
+	if !a.names[name] {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTool, name)
+	}
+	args := map[string]any{}
+	if len(strings.TrimSpace(string(arguments))) > 0 {
+		if err := json.Unmarshal(arguments, &args); err != nil {
+			return nil, fmt.Errorf("%s arguments: %w", name, err)
+		}
+	}
+	var res mcp.CallToolResult
+	if err := a.rpc(ctx, string(mcp.MethodToolsCall), map[string]any{"name": name, "arguments": args}, &res); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	var texts []string
+	for _, c := range res.Content {
+		if tc, ok := c.(mcp.TextContent); ok {
+			texts = append(texts, tc.Text)
+		}
+	}
+	return &Result{Text: strings.Join(texts, "\n"), IsError: res.IsError}, nil
+}
+
+// rpc sends one JSON-RPC request to the in-process MCP server and decodes
+// its result into out.
+func (a *Adapter) rpc(ctx context.Context, method string, params any, out any) error {
+	req, err := json.Marshal(map[string]any{"jsonrpc": mcp.JSONRPC_VERSION, "id": 1, "method": method, "params": params})
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(a.srv.HandleMessage(ctx, req))
+	if err != nil {
+		return err
+	}
+	var resp struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(b, &resp); err != nil {
+		return err
+	}
+	if resp.Error != nil {
+		return errors.New(resp.Error.Message)
+	}
+	return json.Unmarshal(resp.Result, out)
+}
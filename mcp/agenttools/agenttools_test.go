@@ -0,0 +1,110 @@
+package agenttools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mycelian/mycelian-memory/client"
+)
+
+func newAdapter(t *testing.T, url string, opts ...Option) *Adapter {
+	t.Helper()
+	c, err := client.New(url, client.WithDevMode())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	a, err := New(c, opts...)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return a
+}
+
+func TestSchemas(t *testing.T) {
+	a := newAdapter(t, "http://stub")
+
+	byName := map[string]Tool{}
+	for _, tool := range a.Tools() {
+		byName[tool.Name] = tool
+	}
+	if _, ok := byName["delete_entry"]; ok {
+		t.Fatalf("delete_entry listed without WithDestructiveOps")
+	}
+	var params struct {
+		Type       string         `json:"type"`
+		Properties map[string]any `json:"properties"`
+		Required   []string       `json:"required"`
+	}
+	if err := json.Unmarshal(byName["search_memories"].Parameters, &params); err != nil {
+		t.Fatalf("search_memories parameters: %v", err)
+	}
+	if params.Type != "object" || params.Properties["query"] == nil || len(params.Required) == 0 {
+		t.Fatalf("unexpected search_memories parameters: %s", byName["search_memories"].Parameters)
+	}
+	// Tools without arguments still carry an empty properties object.
+	if err := json.Unmarshal(byName["list_vaults"].Parameters, &params); err != nil || params.Properties == nil {
+		t.Fatalf("list_vaults parameters: %s", byName["list_vaults"].Parameters)
+	}
+
+	openai, anthropic := a.OpenAITools(), a.AnthropicTools()
+	if len(openai) != len(a.Tools()) || len(anthropic) != len(a.Tools()) {
+		t.Fatalf("catalogue sizes differ: %d %d %d", len(a.Tools()), len(openai), len(anthropic))
+	}
+	b, _ := json.Marshal(openai[0])
+	var o map[string]map[string]any
+	_ = json.Unmarshal(b, &o)
+	if o["function"]["name"] != a.Tools()[0].Name || o["function"]["parameters"] == nil {
+		t.Fatalf("unexpected OpenAI tool: %s", b)
+	}
+	b, _ = json.Marshal(anthropic[0])
+	var an map[string]any
+	_ = json.Unmarshal(b, &an)
+	if an["name"] != a.Tools()[0].Name || an["input_schema"] == nil {
+		t.Fatalf("unexpected Anthropic tool: %s", b)
+	}
+
+	d := newAdapter(t, "http://stub", WithDestructiveOps())
+	if len(d.Tools()) != len(a.Tools())+2 {
+		t.Fatalf("WithDestructiveOps: %d tools, want %d", len(d.Tools()), len(a.Tools())+2)
+	}
+}
+
+func TestCall(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v0/vaults/v1/memories":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"memories":[{"memoryId":"m1","title":"notes"}],"count":1}`))
+		default:
+			http.Error(w, "boom", http.StatusInternalServerError)
+		}
+	}))
+	defer ts.Close()
+	a := newAdapter(t, ts.URL)
+	ctx := context.Background()
+
+	res, err := a.Call(ctx, "list_memories", json.RawMessage(`{"vault_id":"v1"}`))
+	if err != nil || res.IsError {
+		t.Fatalf("list_memories: %+v %v", res, err)
+	}
+	if res.Text == "" || !json.Valid([]byte(res.Text)) {
+		t.Fatalf("unexpected result text: %q", res.Text)
+	}
+
+	res, err = a.Call(ctx, "list_vaults", nil)
+	if err != nil || !res.IsError {
+		t.Fatalf("failing tool: %+v %v", res, err)
+	}
+
+	if _, err := a.Call(ctx, "drop_database", nil); !errors.Is(err, ErrUnknownTool) {
+		t.Fatalf("expected ErrUnknownTool, got %v", err)
+	}
+	if _, err := a.Call(ctx, "list_memories", json.RawMessage(`not json`)); err == nil {
+		t.Fatalf("expected error for malformed arguments")
+	}
+}
@@ -0,0 +1,57 @@
+// Command tool-schemas prints the Mycelian MCP tools as OpenAI
+// function-calling or Anthropic tool definitions, for agent stacks that
+// register tools from a JSON file:
+//
+//	go run ./cmd/tool-schemas -format openai > mycelian-tools.json
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/mycelian/mycelian-memory/client"
+	"github.com/mycelian/mycelian-memory/mcp/agenttools"
+)
+
+func main() {
+	format := flag.String("format", "openai", "Schema format: openai|anthropic")
+	destructive := flag.Bool("allow-destructive-ops", false, "Include the tools that delete entries and contexts")
+	flag.Parse()
+
+	if err := run(*format, *destructive); err != nil {
+		fmt.Fprintln(os.Stderr, "tool-schemas:", err)
+		os.Exit(1)
+	}
+}
+
+func run(format string, destructive bool) error {
+	// The client is never called; it only backs the tool handlers.
+	c, err := client.New("http://localhost:11545", client.WithDevMode())
+	if err != nil {
+		return err
+	}
+	defer func() { _ = c.Close() }()
+
+	var opts []agenttools.Option
+	if destructive {
+		opts = append(opts, agenttools.WithDestructiveOps())
+	}
+	a, err := agenttools.New(c, opts...)
+	if err != nil {
+		return err
+	}
+	var out any
+	switch format {
+	case "openai":
+		out = a.OpenAITools()
+	case "anthropic":
+		out = a.AnthropicTools()
+	default:
+		return fmt.Errorf("unknown format %q (want openai or anthropic)", format)
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
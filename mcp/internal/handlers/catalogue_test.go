@@ -13,9 +13,9 @@ func TestToolCatalogue(t *testing.T) {
 	// Build minimal MCP server.
 	s := server.NewMCPServer("test", "dev", server.WithToolCapabilities(true))
 
-	stubClient, err := client.NewWithDevMode("http://stub")
+	stubClient, err := client.New("http://stub", client.WithDevMode())
 	if err != nil {
-		t.Fatalf("NewWithDevMode: %v", err)
+		t.Fatalf("New: %v", err)
 	}
 
 	// Register all handlers.
@@ -26,23 +26,14 @@ func TestToolCatalogue(t *testing.T) {
 	_ = NewConsistencyHandler(stubClient).RegisterTools(s)
 	_ = NewSearchHandler(stubClient).RegisterTools(s)
 
-	// Access private field 'tools' via reflection to collect names.
-	v := reflect.ValueOf(s).Elem().FieldByName("tools")
-	if !v.IsValid() {
-		t.Fatalf("failed to access tools map via reflection; server internals changed")
-	}
-	iter := v.MapRange()
-	var got []string
-	for iter.Next() {
-		got = append(got, iter.Key().String())
-	}
-	sort.Strings(got)
+	got := toolNames(t, s)
 
 	want := []string{
 		"add_entry",
 		"await_consistency",
 		"create_memory_in_vault",
 		"create_vault",
+		"flush",
 		"get_context",
 		"get_entry",
 		"get_memory",
@@ -51,9 +42,42 @@ func TestToolCatalogue(t *testing.T) {
 		"list_vaults",
 		"put_context",
 		"search_memories",
+		"signal_entry",
+		"update_memory",
+		"update_vault",
 	}
 
 	if !reflect.DeepEqual(got, want) {
 		t.Fatalf("tool catalogue mismatch\nwant: %v\n got: %v", want, got)
 	}
 }
+
+func TestDeleteToolCatalogue(t *testing.T) {
+	s := server.NewMCPServer("test", "dev", server.WithToolCapabilities(true))
+	stubClient, err := client.New("http://stub", client.WithDevMode())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	_ = NewDeleteHandler(stubClient).RegisterTools(s)
+
+	if got, want := toolNames(t, s), []string{"delete_context", "delete_entry"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("delete tools mismatch\nwant: %v\n got: %v", want, got)
+	}
+}
+
+// toolNames reads the registered tool names from the server's private
+// tools map, sorted.
+func toolNames(t *testing.T, s *server.MCPServer) []string {
+	t.Helper()
+	v := reflect.ValueOf(s).Elem().FieldByName("tools")
+	if !v.IsValid() {
+		t.Fatalf("failed to access tools map via reflection; server internals changed")
+	}
+	iter := v.MapRange()
+	var got []string
+	for iter.Next() {
+		got = append(got, iter.Key().String())
+	}
+	sort.Strings(got)
+	return got
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -9,7 +10,7 @@ import (
 	"github.com/mycelian/mycelian-memory/client"
 )
 
-// ConsistencyHandler exposes await_consistency tool.
+// ConsistencyHandler exposes await_consistency and flush tools.
 type ConsistencyHandler struct {
 	client *client.Client
 }
@@ -34,6 +35,15 @@ Example:
 		mcp.WithString("memory_id", mcp.Required(), mcp.Description("Memory UUID")),
 	)
 	s.AddTool(awaitTool, h.handleAwait)
+
+	flushTool := mcp.NewTool("flush",
+		mcp.WithDescription(`Block until every queued write (add_entry, put_context) across all memories has been sent to the server.
+
+Use it after saving facts to several memories when a following search_memories must see them. For a single memory await_consistency is enough.
+
+Returns {"complete": bool, "memories": {...}}: per memory_id, the submitted, flushed, failed and dropped write counts of each memory that had writes pending; complete is true when all of them reached the server.`),
+	)
+	s.AddTool(flushTool, h.handleFlush)
 	return nil
 }
 
@@ -44,3 +54,19 @@ func (h *ConsistencyHandler) handleAwait(ctx context.Context, req mcp.CallToolRe
 	}
 	return mcp.NewToolResultText("consistent"), nil
 }
+
+func (h *ConsistencyHandler) handleFlush(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	rep, err := h.client.Flush(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("flush failed: %v", err)), nil
+	}
+	memories := make(map[string]map[string]int, len(rep))
+	for memID, r := range rep {
+		memories[memID] = map[string]int{"submitted": r.Submitted, "flushed": r.Flushed, "failed": r.Failed, "dropped": r.Dropped}
+	}
+	b, err := json.Marshal(map[string]interface{}{"complete": rep.Complete(), "memories": memories})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to encode flush report: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(b)), nil
+}
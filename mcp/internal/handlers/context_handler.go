@@ -27,8 +27,8 @@ func (ch *ContextHandler) RegisterTools(s *server.MCPServer) error {
 	// put_context (vault scoped)
 	putCtx := mcp.NewTool("put_context",
 		mcp.WithDescription("Persist the single, plain-text context document for a memory inside a vault"),
-		mcp.WithString("vault_id", mcp.Required(), mcp.Description("Vault UUID")),
-		mcp.WithString("memory_id", mcp.Required(), mcp.Description("Memory UUID")),
+		mcp.WithString("vault_id", mcp.Description("Vault UUID; defaults to the user's default memory")),
+		mcp.WithString("memory_id", mcp.Description("Memory UUID; defaults to the user's default memory")),
 		mcp.WithString("content", mcp.Required(), mcp.Description("Raw context text (entire document)")),
 	)
 	s.AddTool(putCtx, ch.handlePutContext)
@@ -36,8 +36,8 @@ func (ch *ContextHandler) RegisterTools(s *server.MCPServer) error {
 	// get_context (vault scoped)
 	getCtx := mcp.NewTool("get_context",
 		mcp.WithDescription("Fetch the full plain-text context document for a memory inside a vault"),
-		mcp.WithString("vault_id", mcp.Required(), mcp.Description("Vault UUID")),
-		mcp.WithString("memory_id", mcp.Required(), mcp.Description("Memory UUID")),
+		mcp.WithString("vault_id", mcp.Description("Vault UUID; defaults to the user's default memory")),
+		mcp.WithString("memory_id", mcp.Description("Memory UUID; defaults to the user's default memory")),
 	)
 	s.AddTool(getCtx, ch.handleGetContext)
 
@@ -45,8 +45,10 @@ func (ch *ContextHandler) RegisterTools(s *server.MCPServer) error {
 }
 
 func (ch *ContextHandler) handlePutContext(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	vaultID, _ := req.RequireString("vault_id")
-	memID, _ := req.RequireString("memory_id")
+	vaultID, memID, err := memoryIDs(ctx, ch.client, req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 	content, _ := req.RequireString("content")
 
 	log.Debug().
@@ -83,8 +85,10 @@ func (ch *ContextHandler) handlePutContext(ctx context.Context, req mcp.CallTool
 }
 
 func (ch *ContextHandler) handleGetContext(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	vaultID, _ := req.RequireString("vault_id")
-	memID, _ := req.RequireString("memory_id")
+	vaultID, memID, err := memoryIDs(ctx, ch.client, req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
 	log.Debug().
 		Str("vault_id", vaultID).
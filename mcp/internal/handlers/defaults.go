@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mycelian/mycelian-memory/client"
+)
+
+// memoryIDs returns the tool call's vault_id and memory_id, falling back to
+// the user's default memory (client.SetActorDefaults) when either is omitted.
+func memoryIDs(ctx context.Context, c *client.Client, req mcp.CallToolRequest) (string, string, error) {
+	vaultID := req.GetString("vault_id", "")
+	memoryID := req.GetString("memory_id", "")
+	if vaultID != "" && memoryID != "" {
+		return vaultID, memoryID, nil
+	}
+	settings, err := c.GetActorSettings(ctx)
+	if err != nil {
+		return "", "", fmt.Errorf("vault_id and memory_id are required: %w", err)
+	}
+	if settings.DefaultMemoryID == "" {
+		return "", "", fmt.Errorf("vault_id and memory_id are required: no default memory is set")
+	}
+	return settings.DefaultVaultID, settings.DefaultMemoryID, nil
+}
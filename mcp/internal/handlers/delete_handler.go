@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mycelian/mycelian-memory/client"
+	"github.com/rs/zerolog/log"
+)
+
+// DeleteHandler exposes delete_entry and delete_context tools. They remove
+// data for good, so the server registers them only when destructive
+// operations are enabled.
+type DeleteHandler struct {
+	client *client.Client
+}
+
+// NewDeleteHandler returns a new handler.
+func NewDeleteHandler(c *client.Client) *DeleteHandler {
+	return &DeleteHandler{client: c}
+}
+
+// RegisterTools registers the destructive tools with the MCP server.
+func (dh *DeleteHandler) RegisterTools(s *server.MCPServer) error {
+	deleteEntry := mcp.NewTool("delete_entry",
+		mcp.WithDescription("Permanently delete one entry from a memory. Waits for pending writes to the memory first. Cannot be undone."),
+		mcp.WithString("vault_id", mcp.Required(), mcp.Description("The UUID of the vault")),
+		mcp.WithString("memory_id", mcp.Required(), mcp.Description("The UUID of the memory")),
+		mcp.WithString("entry_id", mcp.Required(), mcp.Description("The UUID of the entry")),
+		mcp.WithDestructiveHintAnnotation(true),
+	)
+	s.AddTool(deleteEntry, dh.handleDeleteEntry)
+
+	deleteContext := mcp.NewTool("delete_context",
+		mcp.WithDescription("Permanently delete one context snapshot from a memory. Waits for pending writes to the memory first. Cannot be undone."),
+		mcp.WithString("vault_id", mcp.Required(), mcp.Description("The UUID of the vault")),
+		mcp.WithString("memory_id", mcp.Required(), mcp.Description("The UUID of the memory")),
+		mcp.WithString("context_id", mcp.Required(), mcp.Description("The UUID of the context snapshot")),
+		mcp.WithDestructiveHintAnnotation(true),
+	)
+	s.AddTool(deleteContext, dh.handleDeleteContext)
+
+	return nil
+}
+
+func (dh *DeleteHandler) handleDeleteEntry(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	vaultID, _ := req.RequireString("vault_id")
+	memoryID, _ := req.RequireString("memory_id")
+	entryID, _ := req.RequireString("entry_id")
+
+	start := time.Now()
+	err := dh.client.DeleteEntry(ctx, vaultID, memoryID, entryID)
+	elapsed := time.Since(start)
+	if err != nil {
+		log.Error().Err(err).
+			Str("vault_id", vaultID).
+			Str("memory_id", memoryID).
+			Str("entry_id", entryID).
+			Dur("elapsed", elapsed).
+			Msg("delete_entry failed")
+		return mcp.NewToolResultError(fmt.Sprintf("failed to delete entry: %v", err)), nil
+	}
+	log.Info().Str("vault_id", vaultID).Str("memory_id", memoryID).Str("entry_id", entryID).Msg("entry deleted")
+	return mcp.NewToolResultText("deleted"), nil
+}
+
+func (dh *DeleteHandler) handleDeleteContext(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	vaultID, _ := req.RequireString("vault_id")
+	memoryID, _ := req.RequireString("memory_id")
+	contextID, _ := req.RequireString("context_id")
+
+	start := time.Now()
+	err := dh.client.DeleteContext(ctx, vaultID, memoryID, contextID)
+	elapsed := time.Since(start)
+	if err != nil {
+		log.Error().Err(err).
+			Str("vault_id", vaultID).
+			Str("memory_id", memoryID).
+			Str("context_id", contextID).
+			Dur("elapsed", elapsed).
+			Msg("delete_context failed")
+		return mcp.NewToolResultError(fmt.Sprintf("failed to delete context: %v", err)), nil
+	}
+	log.Info().Str("vault_id", vaultID).Str("memory_id", memoryID).Str("context_id", contextID).Msg("context deleted")
+	return mcp.NewToolResultText("deleted"), nil
+}
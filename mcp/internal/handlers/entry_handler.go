@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"strconv"
 	"time"
 
@@ -13,7 +14,7 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-// EntryHandler exposes add_entry, list_entries, and get_entry tools.
+// EntryHandler exposes add_entry, list_entries, get_entry, and signal_entry tools.
 type EntryHandler struct {
 	client *clientpkg.Client
 }
@@ -30,22 +31,24 @@ func (eh *EntryHandler) RegisterTools(s *server.MCPServer) error {
 	// add_entry (vault scoped)
 	addEntry := mcp.NewTool("add_entry",
 		mcp.WithDescription("Append a new message or note to a memory inside a vault. RawEntry should contain the full text; Summary is a short recap."),
-		mcp.WithString("vault_id", mcp.Required(), mcp.Description("The UUID of the vault")),
-		mcp.WithString("memory_id", mcp.Required(), mcp.Description("The UUID of the memory")),
+		mcp.WithString("vault_id", mcp.Description("The UUID of the vault; defaults to the user's default memory")),
+		mcp.WithString("memory_id", mcp.Description("The UUID of the memory; defaults to the user's default memory")),
 		mcp.WithString("raw_entry", mcp.Required(), mcp.Description("Raw entry text")),
 		mcp.WithString("summary", mcp.Required(), mcp.Description("Short summary of entry")),
 		mcp.WithObject("tags", mcp.Description("Optional JSON object of tags")),
+		mcp.WithString("session_id", mcp.Description("Optional conversation session the entry belongs to; keeps session boundaries for later listing and search")),
 	)
 	s.AddTool(addEntry, eh.handleAddEntry)
 
 	// list_entries (vault scoped)
 	listEntries := mcp.NewTool("list_entries",
 		mcp.WithDescription("List entries for a memory within a vault with pagination cursors"),
-		mcp.WithString("vault_id", mcp.Required(), mcp.Description("The UUID of the vault")),
-		mcp.WithString("memory_id", mcp.Required(), mcp.Description("The UUID of the memory")),
+		mcp.WithString("vault_id", mcp.Description("The UUID of the vault; defaults to the user's default memory")),
+		mcp.WithString("memory_id", mcp.Description("The UUID of the memory; defaults to the user's default memory")),
 		mcp.WithString("limit", mcp.Description("Max rows (1-50), default 25")),
 		mcp.WithString("before", mcp.Description("Return entries created before this RFC3339 timestamp")),
 		mcp.WithString("after", mcp.Description("Return entries created after this RFC3339 timestamp")),
+		mcp.WithString("session_id", mcp.Description("Only return entries of this conversation session")),
 	)
 	s.AddTool(listEntries, eh.handleListEntries)
 
@@ -58,14 +61,28 @@ func (eh *EntryHandler) RegisterTools(s *server.MCPServer) error {
 	)
 	s.AddTool(getEntry, eh.handleGetEntry)
 
+	// signal_entry (vault scoped)
+	signalEntry := mcp.NewTool("signal_entry",
+		mcp.WithDescription("Record a quality signal on an entry: useful when it helped, incorrect when it is wrong, outdated when superseded. Signals can boost or demote the entry in search."),
+		mcp.WithString("vault_id", mcp.Required(), mcp.Description("The UUID of the vault")),
+		mcp.WithString("memory_id", mcp.Required(), mcp.Description("The UUID of the memory")),
+		mcp.WithString("entry_id", mcp.Required(), mcp.Description("The UUID of the entry")),
+		mcp.WithString("signal", mcp.Required(), mcp.Description("One of: useful, incorrect, outdated"),
+			mcp.Enum(clientpkg.SignalUseful, clientpkg.SignalIncorrect, clientpkg.SignalOutdated)),
+	)
+	s.AddTool(signalEntry, eh.handleSignalEntry)
+
 	return nil
 }
 
 func (eh *EntryHandler) handleAddEntry(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	vaultID, _ := req.RequireString("vault_id")
-	memoryID, _ := req.RequireString("memory_id")
+	vaultID, memoryID, err := memoryIDs(ctx, eh.client, req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 	rawEntry, _ := req.RequireString("raw_entry")
 	summary, _ := req.RequireString("summary")
+	sessionID := req.GetString("session_id", "")
 	var tags map[string]string
 	if t, ok := req.GetArguments()["tags"]; ok {
 		_ = mapstructureDecode(t, &tags)
@@ -85,9 +102,10 @@ func (eh *EntryHandler) handleAddEntry(ctx context.Context, req mcp.CallToolRequ
 	// beyond the tool call completion.
 	jobCtx := context.Background()
 	ack, err := eh.client.AddEntry(jobCtx, vaultID, memoryID, clientpkg.AddEntryRequest{
-		RawEntry: rawEntry,
-		Summary:  summary,
-		Tags:     tags,
+		RawEntry:  rawEntry,
+		Summary:   summary,
+		Tags:      tags,
+		SessionID: sessionID,
 	})
 	elapsed := time.Since(start)
 	log.Debug().Err(err).Interface("ack", ack).Dur("elapsed", elapsed).Msg("AddEntry completed")
@@ -113,8 +131,10 @@ func (eh *EntryHandler) handleAddEntry(ctx context.Context, req mcp.CallToolRequ
 }
 
 func (eh *EntryHandler) handleListEntries(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	vaultID, _ := req.RequireString("vault_id")
-	memoryID, _ := req.RequireString("memory_id")
+	vaultID, memoryID, err := memoryIDs(ctx, eh.client, req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
 	limitInt := 25
 	if l, ok := req.GetArguments()["limit"].(float64); ok { // JSON numbers decoded as float64
@@ -145,6 +165,9 @@ func (eh *EntryHandler) handleListEntries(ctx context.Context, req mcp.CallToolR
 	if after != "" {
 		params["after"] = after
 	}
+	if sessionID := req.GetString("session_id", ""); sessionID != "" {
+		params["sessionId"] = url.QueryEscape(sessionID)
+	}
 
 	log.Debug().
 		Str("vault_id", vaultID).
@@ -226,6 +249,27 @@ func (eh *EntryHandler) handleGetEntry(ctx context.Context, req mcp.CallToolRequ
 	return mcp.NewToolResultText(string(b)), nil
 }
 
+func (eh *EntryHandler) handleSignalEntry(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	vaultID, _ := req.RequireString("vault_id")
+	memoryID, _ := req.RequireString("memory_id")
+	entryID, _ := req.RequireString("entry_id")
+	signal, _ := req.RequireString("signal")
+
+	e, err := eh.client.RecordEntrySignal(ctx, vaultID, memoryID, entryID, signal)
+	if err != nil {
+		log.Error().Err(err).
+			Str("vault_id", vaultID).
+			Str("memory_id", memoryID).
+			Str("entry_id", entryID).
+			Str("signal", signal).
+			Msg("signal_entry failed")
+		return mcp.NewToolResultError(fmt.Sprintf("failed to record signal: %v", err)), nil
+	}
+
+	b, _ := json.MarshalIndent(e, "", "  ")
+	return mcp.NewToolResultText(string(b)), nil
+}
+
 // helper to decode generic map into typed struct
 func mapstructureDecode(input interface{}, out interface{}) error {
 	b, err := json.Marshal(input)
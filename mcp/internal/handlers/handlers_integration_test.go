@@ -44,9 +44,9 @@ func TestHandlersEndToEnd(t *testing.T) {
 	}))
 	defer ts.Close()
 
-	sdk, err := client.NewWithDevMode(ts.URL)
+	sdk, err := client.New(ts.URL, client.WithDevMode())
 	if err != nil {
-		t.Fatalf("NewWithDevMode: %v", err)
+		t.Fatalf("New: %v", err)
 	}
 
 	// ----- ContextHandler -----
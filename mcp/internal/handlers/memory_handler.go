@@ -48,6 +48,17 @@ func (mh *MemoryHandler) RegisterTools(s *server.MCPServer) error {
 
 	s.AddTool(createMemInVault, mh.handleCreateMemoryInVault)
 
+	// update_memory – rename and/or re-describe a memory
+	updateMem := mcp.NewTool("update_memory",
+		mcp.WithDescription("CAUTION: Use ONLY after the human has explicitly asked to rename a memory or change its description. Omitted fields are left unchanged."),
+		mcp.WithString("vault_id", mcp.Required(), mcp.Description("Vault UUID")),
+		mcp.WithString("memory_id", mcp.Required(), mcp.Description("Memory UUID")),
+		mcp.WithString("title", mcp.Description("New memory title (≤50 chars, lowercase/hyphen)")),
+		mcp.WithString("description", mcp.Description("New memory description; empty clears it")),
+	)
+
+	s.AddTool(updateMem, mh.handleUpdateMemory)
+
 	return nil
 }
 
@@ -131,3 +142,36 @@ func (mh *MemoryHandler) handleCreateMemoryInVault(ctx context.Context, req mcp.
 	b, _ := json.MarshalIndent(mem, "", "  ")
 	return mcp.NewToolResultText(string(b)), nil
 }
+
+func (mh *MemoryHandler) handleUpdateMemory(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	vaultID, _ := req.RequireString("vault_id")
+	memoryID, _ := req.RequireString("memory_id")
+
+	log.Debug().
+		Str("vault_id", vaultID).
+		Str("memory_id", memoryID).
+		Msg("handling update_memory request")
+
+	start := time.Now()
+	mem, err := mh.client.UpdateMemory(ctx, vaultID, memoryID, titleUpdateArgs(req))
+	elapsed := time.Since(start)
+
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("vault_id", vaultID).
+			Str("memory_id", memoryID).
+			Dur("elapsed", elapsed).
+			Msg("update_memory failed")
+		return mcp.NewToolResultError(fmt.Sprintf("failed to update memory: %v", err)), nil
+	}
+
+	log.Debug().
+		Str("memory_id", mem.ID).
+		Str("title", mem.Title).
+		Dur("elapsed", elapsed).
+		Msg("update_memory completed")
+
+	b, _ := json.MarshalIndent(mem, "", "  ")
+	return mcp.NewToolResultText(string(b)), nil
+}
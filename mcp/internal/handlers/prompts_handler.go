@@ -11,7 +11,8 @@ import (
 )
 
 // PromptsHandler exposes the get_default_prompts tool.
-// It returns embedded default prompt templates for a given memory type.
+// It returns embedded default prompt templates for a given memory type,
+// rendered with the caller's memory title and time zone when given.
 type PromptsHandler struct {
 	client *client.Client
 }
@@ -25,6 +26,10 @@ func (ph *PromptsHandler) RegisterTools(s *server.MCPServer) error {
 	tool := mcp.NewTool("get_default_prompts",
 		mcp.WithDescription("Return default prompt templates for a given memory type"),
 		mcp.WithString("memory_type", mcp.Required(), mcp.Description("Memory type, e.g. chat, code")),
+		mcp.WithString("memory_title", mcp.Description("Title to render into the prompts; looked up from vault_id and memory_id when omitted")),
+		mcp.WithString("vault_id", mcp.Description("Vault of the memory the prompts are for (optional)")),
+		mcp.WithString("memory_id", mcp.Description("Memory the prompts are for (optional)")),
+		mcp.WithString("time_zone", mcp.Description("IANA time zone for today's date in the prompts, default UTC")),
 	)
 	s.AddTool(tool, ph.handleGetPrompts)
 	return nil
@@ -33,7 +38,20 @@ func (ph *PromptsHandler) RegisterTools(s *server.MCPServer) error {
 func (ph *PromptsHandler) handleGetPrompts(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	memType, _ := req.RequireString("memory_type")
 
-	resp, err := ph.client.LoadDefaultPrompts(ctx, memType)
+	vars := client.PromptVars{
+		MemoryTitle:   req.GetString("memory_title", ""),
+		ActorTimeZone: req.GetString("time_zone", ""),
+	}
+	vaultID, memoryID := req.GetString("vault_id", ""), req.GetString("memory_id", "")
+	if vars.MemoryTitle == "" && vaultID != "" && memoryID != "" {
+		mem, err := ph.client.GetMemory(ctx, vaultID, memoryID)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("get_default_prompts failed: %v", err)), nil
+		}
+		vars.MemoryTitle = mem.Title
+	}
+
+	resp, err := ph.client.RenderDefaultPrompts(ctx, memType, vars)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("get_default_prompts failed: %v", err)), nil
 	}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -20,9 +21,9 @@ func TestGetDefaultPromptsTool_MatchesEmbedded(t *testing.T) {
 		t.Fatalf("no memory types found in embedded prompts")
 	}
 
-	sdk, err := client.NewWithDevMode("http://example.com")
+	sdk, err := client.New("http://example.com", client.WithDevMode())
 	if err != nil {
-		t.Fatalf("NewWithDevMode: %v", err)
+		t.Fatalf("New: %v", err)
 	}
 	ph := NewPromptsHandler(sdk)
 
@@ -54,3 +55,32 @@ func TestGetDefaultPromptsTool_MatchesEmbedded(t *testing.T) {
 		})
 	}
 }
+
+func TestGetDefaultPromptsTool_RendersVars(t *testing.T) {
+	sdk, err := client.New("http://example.com", client.WithDevMode())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ph := NewPromptsHandler(sdk)
+	req := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: map[string]any{
+		"memory_type":  "chat",
+		"memory_title": "Trip planning",
+		"time_zone":    "Asia/Tokyo",
+	}}}
+	res, err := ph.handleGetPrompts(context.Background(), req)
+	if err != nil || res.IsError {
+		t.Fatalf("handler: %v %+v", err, res)
+	}
+	var got prompts.DefaultPromptResponse
+	if err := json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &got); err != nil {
+		t.Fatalf("unmarshal result: %v", err)
+	}
+	if p := got.Templates["context_prompt"]; !strings.Contains(p, "Memory: Trip planning (chat).") || !strings.Contains(p, "(Asia/Tokyo)") {
+		t.Fatalf("context_prompt not personalised:\n%s", p)
+	}
+
+	req.Params.Arguments = map[string]any{"memory_type": "chat", "time_zone": "Nowhere/Else"}
+	if res, _ := ph.handleGetPrompts(context.Background(), req); res == nil || !res.IsError {
+		t.Fatal("expected tool error for invalid time zone")
+	}
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mycelian/mycelian-memory/client"
+)
+
+// toolRegisterer is implemented by every handler in this package.
+type toolRegisterer interface {
+	RegisterTools(s *server.MCPServer) error
+}
+
+type namedHandler struct {
+	name string
+	h    toolRegisterer
+}
+
+// RegisterAll registers the full tool catalogue backed by c. The delete
+// tools are included only when destructive is true.
+func RegisterAll(s *server.MCPServer, c *client.Client, destructive bool) error {
+	hs := []namedHandler{
+		{"memory", NewMemoryHandler(c)},
+		{"entry", NewEntryHandler(c)},
+		{"search", NewSearchHandler(c)},
+		{"prompts", NewPromptsHandler(c)},
+		{"vault", NewVaultHandler(c)},
+		{"context", NewContextHandler(c)},
+		{"consistency", NewConsistencyHandler(c)},
+	}
+	if destructive {
+		hs = append(hs, namedHandler{"delete", NewDeleteHandler(c)})
+	}
+	for _, h := range hs {
+		if err := h.h.RegisterTools(s); err != nil {
+			return fmt.Errorf("register %s tools: %w", h.name, err)
+		}
+	}
+	return nil
+}
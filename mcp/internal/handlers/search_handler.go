@@ -23,30 +23,49 @@ func NewSearchHandler(c *client.Client) *SearchHandler {
 func (sh *SearchHandler) RegisterTools(s *server.MCPServer) error {
 	searchTool := mcp.NewTool("search_memories",
 		mcp.WithDescription("Hybrid semantic + keyword search within a memory. Results include:\n • entries – top-K entry hits.\n • latestContext – the most recent consolidated context snapshot (string).\n • bestContext – the context snapshot that most closely matches the query, if found, plus score & timestamp."),
-		mcp.WithString("memory_id", mcp.Required(), mcp.Description("The UUID of the memory")),
+		mcp.WithString("memory_id", mcp.Description("The UUID of the memory; defaults to the user's default memory")),
 		mcp.WithString("query", mcp.Required(), mcp.Description("Search query text")),
-		mcp.WithNumber("top_k", mcp.Description("Number of results to return (1-100, default 10)")),
+		mcp.WithNumber("top_k", mcp.Description("Number of results to return (default 10; the server enforces the maximum)")),
+		mcp.WithString("session_id", mcp.Description("Only search entries of this conversation session")),
+		mcp.WithArray("exclude_entry_ids", mcp.WithStringItems(), mcp.Description("Entry IDs to leave out, e.g. ones already cited this turn, to get results not seen yet")),
+		mcp.WithArray("exclude_tags", mcp.WithStringItems(), mcp.Description("Leave out entries carrying any of these tags")),
+		mcp.WithString("rank_by", mcp.Description("Result order: relevance (default), recency (scores decay with entry age) or hybrid (half the decay); prefer recency when the latest information matters"),
+			mcp.Enum(client.RankByRelevance, client.RankByRecency, client.RankByHybrid)),
+		mcp.WithString("group_by", mcp.Description("Set to session to get one result per conversation session (its best hit, with sessionHits counting the session's hits) so a single long conversation does not fill top_k; not combined with session_id"),
+			mcp.Enum(client.GroupBySession)),
+		mcp.WithString("window", mcp.Description("Only entries created in this time range, resolved in the user's time zone: today, yesterday, thisWeek, lastWeek, thisMonth, lastMonth, thisYear, lastYear, lastNh/lastNd/lastNw (e.g. last7d), or sinceSessionStart (needs session_id)")),
 	)
 	s.AddTool(searchTool, sh.handleSearch)
 	return nil
 }
 
 func (sh *SearchHandler) handleSearch(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	memoryID, _ := req.RequireString("memory_id")
+	memoryID := req.GetString("memory_id", "")
 	query, _ := req.RequireString("query")
 
 	topK := 10
 	if v, ok := req.GetArguments()["top_k"].(float64); ok {
-		if v >= 1 && v <= 100 {
+		if v >= 1 {
 			topK = int(v)
 		}
 	}
 
-	resp, err := sh.client.Search(ctx, client.SearchRequest{
-		MemoryID: memoryID,
-		Query:    query,
-		TopK:     topK,
-	})
+	searchReq := client.SearchRequest{
+		MemoryID:  memoryID,
+		Query:     query,
+		TopK:      topK,
+		SessionID: req.GetString("session_id", ""),
+		RankBy:    req.GetString("rank_by", ""),
+		Window:    req.GetString("window", ""),
+		GroupBy:   req.GetString("group_by", ""),
+	}
+	excludeIDs := req.GetStringSlice("exclude_entry_ids", nil)
+	excludeTags := req.GetStringSlice("exclude_tags", nil)
+	if len(excludeIDs) > 0 || len(excludeTags) > 0 {
+		searchReq.MustNot = &client.SearchMustNot{EntryIDs: excludeIDs, Tags: excludeTags}
+	}
+
+	resp, err := sh.client.Search(ctx, searchReq)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("search failed: %v", err)), nil
 	}
@@ -58,6 +77,19 @@ func (sh *SearchHandler) handleSearch(ctx context.Context, req mcp.CallToolReque
 		"latest_context":    json.RawMessage(resp.LatestContext),
 		"context_timestamp": resp.ContextTimestamp,
 	}
+	if resp.TimeWindow != nil {
+		payload["time_window"] = resp.TimeWindow
+	}
+	if f := resp.IndexFreshness; f != nil && f.Stale {
+		// Recent entries may not be searchable yet; the agent can search
+		// again shortly or say the results may be incomplete.
+		payload["index_freshness"] = f
+	}
+	if resp.Stale {
+		// The live search failed; tell the agent these results may be outdated.
+		payload["stale"] = true
+		payload["cached_at"] = resp.CachedAt
+	}
 	b, _ := json.MarshalIndent(payload, "", "  ")
 	return mcp.NewToolResultText(string(b)), nil
 }
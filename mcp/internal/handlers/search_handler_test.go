@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -21,14 +22,15 @@ func TestSearchMemoriesTool(t *testing.T) {
             "entries": [],
             "count": 0,
             "latestContext": "{}",
-            "contextTimestamp": "2025-07-27T00:00:00Z"
+            "contextTimestamp": "2025-07-27T00:00:00Z",
+            "indexFreshness": {"lagSeconds": 12, "stale": true}
         }`))
 	}))
 	defer ts.Close()
 
-	sdk, err := client.NewWithDevMode(ts.URL)
+	sdk, err := client.New(ts.URL, client.WithDevMode())
 	if err != nil {
-		t.Fatalf("NewWithDevMode: %v", err)
+		t.Fatalf("New: %v", err)
 	}
 	sh := NewSearchHandler(sdk)
 	// Build request
@@ -49,4 +51,7 @@ func TestSearchMemoriesTool(t *testing.T) {
 	if res == nil {
 		t.Fatalf("nil result")
 	}
+	if text := res.Content[0].(mcp.TextContent).Text; !strings.Contains(text, `"index_freshness"`) {
+		t.Fatalf("stale index not reported: %s", text)
+	}
 }
@@ -35,7 +35,14 @@ func (vh *VaultHandler) RegisterTools(s *server.MCPServer) error {
 		mcp.WithDescription("List memories inside a vault (returns id & title)"),
 		mcp.WithString("vault_id", mcp.Required(), mcp.Description("Vault UUID")),
 	)
+	update := mcp.NewTool("update_vault",
+		mcp.WithDescription("CAUTION: Use ONLY after the human has explicitly asked to rename a vault or change its description. Omitted fields are left unchanged."),
+		mcp.WithString("vault_id", mcp.Required(), mcp.Description("Vault UUID")),
+		mcp.WithString("title", mcp.Description("New vault title (≤50 chars, lowercase/hyphen)")),
+		mcp.WithString("description", mcp.Description("New vault description; empty clears it")),
+	)
 	s.AddTool(create, vh.handleCreateVault)
+	s.AddTool(update, vh.handleUpdateVault)
 	s.AddTool(listVaults, vh.handleListVaults)
 	s.AddTool(list, vh.handleListMemories)
 	return nil
@@ -63,6 +70,38 @@ func (vh *VaultHandler) handleCreateVault(ctx context.Context, req mcp.CallToolR
 	return mcp.NewToolResultText(string(b)), nil
 }
 
+func (vh *VaultHandler) handleUpdateVault(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	vaultID, _ := req.RequireString("vault_id")
+
+	log.Debug().Str("vault_id", vaultID).Msg("update_vault invoked")
+
+	start := time.Now()
+	v, err := vh.client.UpdateVault(ctx, vaultID, titleUpdateArgs(req))
+	elapsed := time.Since(start)
+	if err != nil {
+		log.Error().Err(err).Dur("elapsed", elapsed).Msg("update_vault failed")
+		return mcp.NewToolResultError(fmt.Sprintf("failed to update vault: %v", err)), nil
+	}
+
+	out := map[string]any{"vaultId": v.VaultID, "title": v.Title}
+	b, _ := json.Marshal(out)
+	return mcp.NewToolResultText(string(b)), nil
+}
+
+// titleUpdateArgs builds an update from the optional title and description
+// arguments; absent arguments are left unchanged.
+func titleUpdateArgs(req mcp.CallToolRequest) client.UpdateTitleRequest {
+	var u client.UpdateTitleRequest
+	args := req.GetArguments()
+	if v, ok := args["title"].(string); ok {
+		u.Title = &v
+	}
+	if v, ok := args["description"].(string); ok {
+		u.Description = &v
+	}
+	return u
+}
+
 // handleListVaults returns a minimal list of vault identifiers and titles.
 func (vh *VaultHandler) handleListVaults(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	log.Debug().Msg("list_vaults invoked")
@@ -37,7 +37,7 @@ func TestMCPServerTransports(t *testing.T) {
 	)
 
 	// Initialize client SDK pointing to stub backend
-	sdk, err := mclient.New(memSrv.URL, "test-api-key")
+	sdk, err := mclient.New(memSrv.URL, mclient.WithAPIKey("test-api-key"))
 	if err != nil {
 		t.Fatalf("client.New: %v", err)
 	}
@@ -3,15 +3,18 @@ package mcp
 import (
 	"context"
 	"flag"
+	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/mycelian/mycelian-memory/client"
+	"github.com/mycelian/mycelian-memory/client/credentials"
 	"github.com/mycelian/mycelian-memory/mcp/internal/handlers"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
@@ -20,6 +23,10 @@ import (
 // Configuration holds all settings for the MCP server
 type config struct {
 	MemoryServiceURL string
+	// Profile names the stored credentials (mycelianCli login) to
+	// authenticate with; MYCELIAN_API_KEY takes precedence, and without
+	// either MYCELIAN_ACCESS_TOKEN is used.
+	Profile          string
 	ContextDataDir   string
 	LogLevel         zerolog.Level
 	ServerName       string
@@ -28,6 +35,19 @@ type config struct {
 	HTTPReadTimeout  time.Duration
 	HTTPWriteTimeout time.Duration
 	HTTPIdleTimeout  time.Duration
+	// Search resilience: retries of transient failures, then a cached result.
+	SearchRetries     int
+	SearchCacheSize   int
+	SearchCacheMaxAge time.Duration
+	// ReadYourWritesMaxAge bounds how long unindexed writes are merged into search; 0 disables.
+	ReadYourWritesMaxAge time.Duration
+	// AllowDestructiveOps registers the delete_entry and delete_context tools.
+	AllowDestructiveOps bool
+	// DevMode sends the shared dev key when no credentials are configured.
+	DevMode bool
+	// serviceURLSet is true when the service URL was given explicitly, so
+	// it is not replaced by the profile's.
+	serviceURLSet bool
 }
 
 // loadConfig loads configuration from environment variables and flags
@@ -36,12 +56,22 @@ func loadConfig() *config {
 		// Default values
 		MemoryServiceURL: getEnvOrDefault("MEMORY_SERVICE_URL", "http://localhost:11545"),
 		ContextDataDir:   getEnvOrDefault("CONTEXT_DATA_DIR", "./data/context"),
+		Profile:          os.Getenv(credentials.EnvProfile),
 		ServerName:       getEnvOrDefault("MCP_SERVER_NAME", "mycelian-mcp-server"),
 		ServerVersion:    getEnvOrDefault("MCP_SERVER_VERSION", "0.2.0"),
 		ShutdownTimeout:  parseDurationOrDefault("SHUTDOWN_TIMEOUT", "10s"),
 		HTTPReadTimeout:  parseDurationOrDefault("HTTP_READ_TIMEOUT", "5s"),
 		HTTPWriteTimeout: parseDurationOrDefault("HTTP_WRITE_TIMEOUT", "10s"),
 		HTTPIdleTimeout:  parseDurationOrDefault("HTTP_IDLE_TIMEOUT", "120s"),
+
+		SearchRetries:     parseIntOrDefault("SEARCH_RETRIES", 2),
+		SearchCacheSize:   parseIntOrDefault("SEARCH_CACHE_SIZE", 64),
+		SearchCacheMaxAge: parseDurationOrDefault("SEARCH_CACHE_MAX_AGE", "15m"),
+
+		ReadYourWritesMaxAge: parseDurationOrDefault("READ_YOUR_WRITES_MAX_AGE", "5m"),
+
+		AllowDestructiveOps: parseBoolOrDefault("MCP_ALLOW_DESTRUCTIVE_OPS", false),
+		DevMode:             parseBoolOrDefault(client.EnvDevMode, false),
 	}
 
 	// Parse log level from environment
@@ -50,9 +80,18 @@ func loadConfig() *config {
 	// Command line flags (will override env vars)
 	var rawLogLevel string
 	flag.StringVar(&cfg.MemoryServiceURL, "memory-service-url", cfg.MemoryServiceURL, "Base URL of the Mycelian Memory Service")
+	flag.StringVar(&cfg.Profile, "profile", cfg.Profile, "Stored credentials profile to authenticate with")
 	flag.StringVar(&cfg.ContextDataDir, "context-data-dir", cfg.ContextDataDir, "Filesystem directory where context docs are stored")
 	flag.StringVar(&rawLogLevel, "log-level", cfg.LogLevel.String(), "Log level: debug|info|warn|error")
+	flag.BoolVar(&cfg.AllowDestructiveOps, "allow-destructive-ops", cfg.AllowDestructiveOps, "Expose tools that delete entries and contexts")
+	flag.BoolVar(&cfg.DevMode, "dev-mode", cfg.DevMode, "Authenticate with the shared dev key when no credentials are configured")
 	flag.Parse()
+	cfg.serviceURLSet = os.Getenv("MEMORY_SERVICE_URL") != ""
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "memory-service-url" {
+			cfg.serviceURLSet = true
+		}
+	})
 
 	// Override log level from flag if provided
 	if rawLogLevel != "" {
@@ -86,6 +125,40 @@ func parseDurationOrDefault(envKey, defaultValue string) time.Duration {
 	return d
 }
 
+func parseIntOrDefault(envKey string, defaultValue int) int {
+	if value := os.Getenv(envKey); value != "" {
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+	}
+	return defaultValue
+}
+
+func parseBoolOrDefault(envKey string, defaultValue bool) bool {
+	if value := os.Getenv(envKey); value != "" {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return defaultValue
+}
+
+// searchOptions turns the search settings into client options; a zero
+// value disables that feature.
+func (c *config) searchOptions() []client.Option {
+	var opts []client.Option
+	if c.SearchRetries > 0 {
+		opts = append(opts, client.WithSearchRetries(c.SearchRetries, 200*time.Millisecond))
+	}
+	if c.SearchCacheSize > 0 {
+		opts = append(opts, client.WithSearchCache(c.SearchCacheSize, c.SearchCacheMaxAge))
+	}
+	if c.ReadYourWritesMaxAge > 0 {
+		opts = append(opts, client.WithReadYourWrites(c.ReadYourWritesMaxAge))
+	}
+	return opts
+}
+
 func parseLogLevel(levelStr string) zerolog.Level {
 	switch strings.ToLower(levelStr) {
 	case "debug":
@@ -101,14 +174,26 @@ func parseLogLevel(levelStr string) zerolog.Level {
 	}
 }
 
-type toolRegisterer interface {
-	RegisterTools(s *server.MCPServer) error
-}
-
-func registerHandler(s *server.MCPServer, handler toolRegisterer, name string) {
-	if err := handler.RegisterTools(s); err != nil {
-		log.Fatal().Err(err).Msgf("Failed to register %s tools", name)
+// newClient authenticates with MYCELIAN_API_KEY, the key of Profile or
+// MYCELIAN_ACCESS_TOKEN, and uses dev mode only when DevMode asks for it.
+// Without any of these it returns client.ErrNoCredentials.
+func (c *config) newClient() (*client.Client, error) {
+	url, apiKey, err := credentials.Resolve(c.Profile)
+	if err != nil {
+		return nil, err
 	}
+	if url != "" && !c.serviceURLSet {
+		c.MemoryServiceURL = url
+	}
+	if err := credentials.CheckServiceURL(url, c.MemoryServiceURL); err != nil {
+		return nil, err
+	}
+	auth, err := client.Credentials(apiKey, c.DevMode)
+	if err != nil {
+		return nil, fmt.Errorf("%w; set MYCELIAN_API_KEY, log in with mycelianCli or pass --dev-mode", err)
+	}
+	log.Info().Str("memory_service_url", c.MemoryServiceURL).Str("profile", c.Profile).Msg("Creating client")
+	return client.New(c.MemoryServiceURL, append(c.searchOptions(), auth)...)
 }
 
 // RunMCPServer starts the MCP server with the given configuration
@@ -118,8 +203,7 @@ func RunMCPServer() error {
 	cfg.initLogger()
 
 	// Initialize the new Client SDK
-	log.Info().Str("memory_service_url", cfg.MemoryServiceURL).Msg("Creating client with dev mode")
-	mycelianClient, err := client.NewWithDevMode(cfg.MemoryServiceURL)
+	mycelianClient, err := cfg.newClient()
 	if err != nil {
 		log.Error().Stack().Err(err).Msg("Failed to create client")
 		return err
@@ -138,13 +222,12 @@ func RunMCPServer() error {
 	)
 
 	// Initialize and register handlers
-	registerHandler(s, handlers.NewMemoryHandler(mycelianClient), "memory")
-	registerHandler(s, handlers.NewEntryHandler(mycelianClient), "entry")
-	registerHandler(s, handlers.NewSearchHandler(mycelianClient), "search")
-	registerHandler(s, handlers.NewPromptsHandler(mycelianClient), "prompts")
-	registerHandler(s, handlers.NewVaultHandler(mycelianClient), "vault")
-	registerHandler(s, handlers.NewContextHandler(mycelianClient), "context")
-	registerHandler(s, handlers.NewConsistencyHandler(mycelianClient), "consistency")
+	if cfg.AllowDestructiveOps {
+		log.Warn().Msg("Destructive tools enabled: delete_entry, delete_context")
+	}
+	if err := handlers.RegisterAll(s, mycelianClient, cfg.AllowDestructiveOps); err != nil {
+		log.Fatal().Err(err).Msg("Failed to register tools")
+	}
 
 	// Auto-detect transport method
 	if shouldUseStdio() {
@@ -210,10 +293,17 @@ func RunMCPServer() error {
 
 			// Shutdown Mycelian client
 			log.Info().Msg("Shutting down Mycelian client...")
-			if err := mycelianClient.Close(); err != nil {
+			report, err := mycelianClient.Shutdown(shutdownCtx)
+			for memoryID, r := range report {
+				if r.Flushed != r.Submitted {
+					log.Warn().Str("memoryId", memoryID).Int("submitted", r.Submitted).Int("failed", r.Failed).
+						Int("dropped", r.Dropped).Msg("Writes did not reach the memory service before shutdown")
+				}
+			}
+			if err != nil {
 				log.Error().Err(err).Msg("Error closing Mycelian client")
 			} else {
-				log.Info().Msg("Mycelian client shutdown complete")
+				log.Info().Bool("allWritesFlushed", report.Complete()).Msg("Mycelian client shutdown complete")
 			}
 		}()
 
@@ -0,0 +1,3 @@
+module github.com/mycelian/mycelian-memory/pkg/schema
+
+go 1.24.6
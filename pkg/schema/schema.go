@@ -0,0 +1,30 @@
+// Package schema holds the canonical PostgreSQL schema shared by the server,
+// which applies it, and the CLI, which checks deployments against its version.
+package schema
+
+import (
+	_ "embed"
+	"regexp"
+)
+
+// SQL is the canonical PostgreSQL DDL (tables, indexes and the outbox).
+// It is idempotent and safe to apply repeatedly.
+//
+//go:embed schema.sql
+var SQL string
+
+// Version is the schema revision declared by the "-- schema-version:" line
+// of schema.sql.
+var Version = version(SQL)
+
+var versionRe = regexp.MustCompile(`(?m)^-- schema-version: (\d+)$`)
+
+// version returns the schema-version of sql; it panics when there is none,
+// since every build must know which revision it carries.
+func version(sql string) string {
+	m := versionRe.FindStringSubmatch(sql)
+	if m == nil {
+		panic("schema.sql has no schema-version line")
+	}
+	return m[1]
+}
@@ -0,0 +1,346 @@
+-- PostgreSQL schema for Mycelian Memory (parity with ADR 0014)
+-- schema-version: 34
+-- Bump schema-version whenever this file changes shape; servers report it
+-- and `mycelianCli doctor` compares it with the version it was built with.
+-- Users table eliminated - actor_id is now treated as opaque string identifier
+
+-- Vaults
+CREATE TABLE IF NOT EXISTS vaults (
+  actor_id       TEXT NOT NULL,
+  vault_id       TEXT NOT NULL,
+  title          TEXT NOT NULL,
+  description    TEXT,
+  creation_time  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  read_only      BOOLEAN NOT NULL DEFAULT false,
+  PRIMARY KEY (actor_id, vault_id),
+  UNIQUE (actor_id, title)
+);
+ALTER TABLE vaults ADD COLUMN IF NOT EXISTS read_only BOOLEAN NOT NULL DEFAULT false;
+-- slug is the URL form of the title (model.Slug), unique per actor. Titles
+-- from before slugs only used ASCII letters, digits and hyphens, so
+-- legacy_title_slug computes the same slug for them. Old titles whose slugs
+-- collide keep a NULL slug and stay addressable by exact title.
+CREATE OR REPLACE FUNCTION legacy_title_slug(title TEXT) RETURNS TEXT
+  LANGUAGE sql IMMUTABLE AS $$ SELECT lower(trim(both '-' from regexp_replace(title, '-+', '-', 'g'))) $$;
+ALTER TABLE vaults ADD COLUMN IF NOT EXISTS slug TEXT;
+UPDATE vaults v SET slug = legacy_title_slug(title)
+WHERE slug IS NULL AND NOT EXISTS (
+  SELECT 1 FROM vaults o WHERE o.actor_id = v.actor_id AND o.vault_id <> v.vault_id
+    AND legacy_title_slug(v.title) IN (o.slug, legacy_title_slug(o.title))
+);
+CREATE UNIQUE INDEX IF NOT EXISTS vaults_slug_uq ON vaults(actor_id, slug);
+
+-- Memories
+CREATE TABLE IF NOT EXISTS memories (
+  actor_id       TEXT NOT NULL,
+  vault_id       TEXT NOT NULL,
+  memory_id      TEXT NOT NULL,
+  memory_type    TEXT NOT NULL,
+  title          TEXT NOT NULL,
+  description    TEXT,
+  creation_time  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  append_only    BOOLEAN NOT NULL DEFAULT false,
+  entry_roles    JSONB,
+  PRIMARY KEY (actor_id, vault_id, memory_id)
+);
+ALTER TABLE memories ADD COLUMN IF NOT EXISTS append_only BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE memories ADD COLUMN IF NOT EXISTS entry_roles JSONB;
+-- Per-memory boost and field weights for vault-scope searches
+ALTER TABLE memories ADD COLUMN IF NOT EXISTS search_boost JSONB;
+ALTER TABLE memories ADD COLUMN IF NOT EXISTS slug TEXT;
+UPDATE memories m SET slug = legacy_title_slug(title)
+WHERE slug IS NULL AND NOT EXISTS (
+  SELECT 1 FROM memories o WHERE o.vault_id = m.vault_id AND o.memory_id <> m.memory_id
+    AND legacy_title_slug(m.title) IN (o.slug, legacy_title_slug(o.title))
+);
+-- Set while the memory is in the trash (soft deletes); purged after retention
+ALTER TABLE memories ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+-- Titles and slugs are unique among live memories only, so a trashed
+-- memory's title can be reused; restoring it then conflicts.
+ALTER TABLE memories DROP CONSTRAINT IF EXISTS memories_vault_id_title_key;
+DROP INDEX IF EXISTS memories_slug_uq;
+CREATE UNIQUE INDEX IF NOT EXISTS memories_live_title_uq ON memories(vault_id, title) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS memories_live_slug_uq ON memories(vault_id, slug) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS memories_trash_idx ON memories(deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- MemoryEntries
+CREATE TABLE IF NOT EXISTS memory_entries (
+  actor_id       TEXT NOT NULL,
+  vault_id       TEXT NOT NULL,
+  memory_id      TEXT NOT NULL,
+  creation_time  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  entry_id       TEXT NOT NULL,
+  raw_entry      TEXT NOT NULL,
+  summary        TEXT,
+  metadata       JSONB,
+  tags           JSONB,
+  correction_time TIMESTAMPTZ,
+  corrected_entry_memory_id TEXT,
+  corrected_entry_creation_time TIMESTAMPTZ,
+  correction_reason TEXT,
+  last_update_time TIMESTAMPTZ,
+  source_system  TEXT,
+  source_id      TEXT,
+  ingestion_batch_id TEXT,
+  useful_count    INT NOT NULL DEFAULT 0,
+  incorrect_count INT NOT NULL DEFAULT 0,
+  outdated_count  INT NOT NULL DEFAULT 0,
+  last_accessed_time TIMESTAMPTZ,
+  session_id     TEXT,
+  -- NULL: raw_entry holds the text; 'zstd': raw_entry is empty and
+  -- raw_entry_zstd holds the compressed text
+  raw_entry_encoding TEXT,
+  raw_entry_zstd BYTEA,
+  PRIMARY KEY (actor_id, vault_id, memory_id, creation_time, entry_id)
+);
+-- Upgrades for databases created before the columns above existed
+ALTER TABLE memory_entries ADD COLUMN IF NOT EXISTS source_system TEXT;
+ALTER TABLE memory_entries ADD COLUMN IF NOT EXISTS source_id TEXT;
+ALTER TABLE memory_entries ADD COLUMN IF NOT EXISTS ingestion_batch_id TEXT;
+ALTER TABLE memory_entries ADD COLUMN IF NOT EXISTS useful_count INT NOT NULL DEFAULT 0;
+ALTER TABLE memory_entries ADD COLUMN IF NOT EXISTS incorrect_count INT NOT NULL DEFAULT 0;
+ALTER TABLE memory_entries ADD COLUMN IF NOT EXISTS outdated_count INT NOT NULL DEFAULT 0;
+ALTER TABLE memory_entries ADD COLUMN IF NOT EXISTS last_accessed_time TIMESTAMPTZ;
+ALTER TABLE memory_entries ADD COLUMN IF NOT EXISTS session_id TEXT;
+ALTER TABLE memory_entries ADD COLUMN IF NOT EXISTS raw_entry_encoding TEXT;
+ALTER TABLE memory_entries ADD COLUMN IF NOT EXISTS raw_entry_zstd BYTEA;
+ALTER TABLE memory_entries ADD COLUMN IF NOT EXISTS llm_usage JSONB;
+-- When the conversation behind the entry took place, for ingested history
+ALTER TABLE memory_entries ADD COLUMN IF NOT EXISTS conversation_time TIMESTAMPTZ;
+CREATE UNIQUE INDEX IF NOT EXISTS memory_entries_entry_id_uq ON memory_entries(entry_id);
+-- LRU retention scans entries by last access, falling back to creation for never-read entries
+CREATE INDEX IF NOT EXISTS memory_entries_last_access_idx ON memory_entries((COALESCE(last_accessed_time, creation_time)));
+CREATE INDEX IF NOT EXISTS memory_entries_recent_idx ON memory_entries(actor_id, vault_id, memory_id, creation_time DESC);
+CREATE INDEX IF NOT EXISTS memory_entries_session_idx ON memory_entries(actor_id, memory_id, session_id, creation_time) WHERE session_id IS NOT NULL;
+-- orderBy=conversationTime lists entries by conversation time, falling back to creation
+CREATE INDEX IF NOT EXISTS memory_entries_conversation_idx ON memory_entries(actor_id, vault_id, memory_id, (COALESCE(conversation_time, creation_time)));
+CREATE INDEX IF NOT EXISTS memory_entries_batch_idx ON memory_entries(actor_id, ingestion_batch_id) WHERE ingestion_batch_id IS NOT NULL;
+-- Set while the entry is in the trash (soft deletes); purged after retention
+ALTER TABLE memory_entries ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS memory_entries_trash_idx ON memory_entries(deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Ingestion batch registry (provenance; supports atomic rollback of a batch)
+CREATE TABLE IF NOT EXISTS ingestion_batches (
+  actor_id         TEXT NOT NULL,
+  batch_id         TEXT NOT NULL,
+  source_system    TEXT NOT NULL,
+  description      TEXT,
+  status           TEXT NOT NULL DEFAULT 'open',
+  creation_time    TIMESTAMPTZ NOT NULL DEFAULT now(),
+  rolled_back_time TIMESTAMPTZ,
+  PRIMARY KEY (actor_id, batch_id)
+);
+
+-- MemoryContexts
+CREATE TABLE IF NOT EXISTS memory_contexts (
+  actor_id       TEXT NOT NULL,
+  vault_id       TEXT NOT NULL,
+  memory_id      TEXT NOT NULL,
+  context_id     TEXT NOT NULL,
+  context        TEXT NOT NULL,
+  creation_time  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (actor_id, vault_id, memory_id, context_id)
+);
+CREATE INDEX IF NOT EXISTS memory_contexts_latest_idx ON memory_contexts(actor_id, memory_id, creation_time DESC);
+-- Structured contexts: named sections with provenance; context holds their rendering
+ALTER TABLE memory_contexts ADD COLUMN IF NOT EXISTS sections JSONB;
+
+-- Full versions of contexts too large to be the active context, uploaded gzip-compressed in parts
+CREATE TABLE IF NOT EXISTS context_documents (
+  actor_id         TEXT NOT NULL,
+  vault_id         TEXT NOT NULL,
+  memory_id        TEXT NOT NULL,
+  document_id      TEXT NOT NULL,
+  status           TEXT NOT NULL DEFAULT 'uploading',
+  size_bytes       BIGINT,
+  sha256           TEXT,
+  creation_time    TIMESTAMPTZ NOT NULL DEFAULT now(),
+  completion_time  TIMESTAMPTZ,
+  PRIMARY KEY (actor_id, document_id)
+);
+CREATE INDEX IF NOT EXISTS context_documents_memory_idx ON context_documents(actor_id, vault_id, memory_id);
+
+CREATE TABLE IF NOT EXISTS context_document_parts (
+  actor_id       TEXT NOT NULL,
+  document_id    TEXT NOT NULL,
+  part_no        INT NOT NULL,
+  data           BYTEA NOT NULL,
+  PRIMARY KEY (actor_id, document_id, part_no)
+);
+
+-- Per-memory entity aliases ("Bob" -> "Robert Smith") used to expand search queries
+CREATE TABLE IF NOT EXISTS entity_aliases (
+  actor_id       TEXT NOT NULL,
+  vault_id       TEXT NOT NULL,
+  memory_id      TEXT NOT NULL,
+  alias_key      TEXT NOT NULL,
+  alias          TEXT NOT NULL,
+  canonical      TEXT NOT NULL,
+  creation_time  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (actor_id, memory_id, alias_key)
+);
+
+-- Inbound webhooks writing signed JSON payloads as entries of one memory
+CREATE TABLE IF NOT EXISTS webhooks (
+  webhook_id          TEXT PRIMARY KEY,
+  actor_id            TEXT NOT NULL,
+  vault_id            TEXT NOT NULL,
+  memory_id           TEXT NOT NULL,
+  name                TEXT NOT NULL,
+  kind                TEXT NOT NULL,
+  secret              TEXT NOT NULL,
+  mapping             JSONB NOT NULL,
+  creation_time       TIMESTAMPTZ NOT NULL DEFAULT now(),
+  last_delivery_time  TIMESTAMPTZ,
+  delivery_count      BIGINT NOT NULL DEFAULT 0,
+  UNIQUE (actor_id, memory_id, name)
+);
+
+-- Search query log with relevance feedback (written only when SEARCH_QUERY_LOG_ENABLED)
+CREATE TABLE IF NOT EXISTS search_queries (
+  actor_id         TEXT NOT NULL,
+  query_id         TEXT NOT NULL,
+  memory_id        TEXT NOT NULL,
+  query            TEXT NOT NULL,
+  top_k            INT NOT NULL,
+  alpha            REAL NOT NULL,
+  entry_ids        JSONB NOT NULL,
+  scores           JSONB,
+  useful_entry_ids JSONB,
+  feedback_time    TIMESTAMPTZ,
+  creation_time    TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (actor_id, query_id)
+);
+CREATE INDEX IF NOT EXISTS search_queries_memory_idx ON search_queries(actor_id, memory_id, creation_time DESC);
+ALTER TABLE search_queries ADD COLUMN IF NOT EXISTS scores JSONB;
+
+-- Client tokens of POST /v0/bootstrap; a retry with the same token and
+-- request_hash returns the IDs created by the first request
+CREATE TABLE IF NOT EXISTS bootstrap_tokens (
+  actor_id       TEXT NOT NULL,
+  client_token   TEXT NOT NULL,
+  request_hash   TEXT NOT NULL,
+  vault_id       TEXT NOT NULL,
+  memory_id      TEXT NOT NULL,
+  context_id     TEXT NOT NULL,
+  creation_time  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (actor_id, client_token)
+);
+
+-- Per-actor preferences (actor_id is opaque; a row exists once settings are saved)
+CREATE TABLE IF NOT EXISTS actor_settings (
+  actor_id       TEXT PRIMARY KEY,
+  time_zone      TEXT NOT NULL DEFAULT 'UTC',
+  default_vault_id  TEXT,
+  default_memory_id TEXT,
+  update_time    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+ALTER TABLE actor_settings ADD COLUMN IF NOT EXISTS default_vault_id TEXT;
+ALTER TABLE actor_settings ADD COLUMN IF NOT EXISTS default_memory_id TEXT;
+
+-- Outbox for Weaviate sync
+CREATE TABLE IF NOT EXISTS outbox (
+  id             BIGSERIAL PRIMARY KEY,
+  aggregate_id   TEXT NOT NULL,
+  op             TEXT NOT NULL,
+  payload        JSONB NOT NULL,
+  status         TEXT NOT NULL DEFAULT 'pending',
+  attempt_count  INT NOT NULL DEFAULT 0,
+  leased_until   TIMESTAMPTZ,
+  next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  creation_time  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  update_time    TIMESTAMPTZ NOT NULL DEFAULT now(),
+  job_id         TEXT,
+  last_error     TEXT,
+  lease_owner    TEXT
+);
+CREATE INDEX IF NOT EXISTS outbox_ready_idx ON outbox(status, next_attempt_at);
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS job_id TEXT;
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS last_error TEXT;
+-- Worker holding the row's lease until leased_until; see outbox.Worker
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS lease_owner TEXT;
+CREATE INDEX IF NOT EXISTS outbox_job_idx ON outbox(job_id) WHERE job_id IS NOT NULL;
+-- Per-entry index status reads the latest record of each aggregate
+CREATE INDEX IF NOT EXISTS outbox_aggregate_idx ON outbox(aggregate_id, id DESC);
+-- Renames and the upserts of their memory are claimed in order; see outbox.Worker
+CREATE INDEX IF NOT EXISTS outbox_pending_memory_idx ON outbox((payload->>'memoryId'), id) WHERE status = 'pending';
+-- Inserts notify channel outbox_ready (outbox.NotifyChannel) at commit, so
+-- listening workers pick new rows up without waiting for their next poll;
+-- one notification per statement, merged into one per transaction
+CREATE OR REPLACE FUNCTION outbox_notify() RETURNS trigger
+  LANGUAGE plpgsql AS $$ BEGIN PERFORM pg_notify('outbox_ready', ''); RETURN NULL; END $$;
+CREATE OR REPLACE TRIGGER outbox_notify AFTER INSERT ON outbox
+  FOR EACH STATEMENT EXECUTE FUNCTION outbox_notify();
+
+-- Admin reindex jobs; progress is read from the outbox rows tagged with job_id
+CREATE TABLE IF NOT EXISTS reindex_jobs (
+  actor_id       TEXT NOT NULL,
+  job_id         TEXT NOT NULL,
+  vault_id       TEXT NOT NULL,
+  memory_id      TEXT NOT NULL,
+  entry_count    INT NOT NULL,
+  context_count  INT NOT NULL,
+  creation_time  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (actor_id, job_id)
+);
+CREATE INDEX IF NOT EXISTS reindex_jobs_memory_idx ON reindex_jobs(actor_id, memory_id, creation_time DESC);
+
+-- Embedding configuration fingerprint the index was built with (one row);
+-- a change marks every memory in reembed_memories
+CREATE TABLE IF NOT EXISTS embedding_state (
+  singleton      BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (singleton),
+  fingerprint    TEXT NOT NULL,
+  update_time    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Gradual re-embedding per memory; entries are enqueued in creation order
+-- after the cursor, outbox rows carry job_id
+CREATE TABLE IF NOT EXISTS reembed_memories (
+  actor_id        TEXT NOT NULL,
+  memory_id       TEXT NOT NULL,
+  vault_id        TEXT NOT NULL,
+  job_id          TEXT NOT NULL,
+  fingerprint     TEXT NOT NULL,
+  entry_count     INT NOT NULL,
+  context_count   INT NOT NULL DEFAULT 0,
+  enqueued        INT NOT NULL DEFAULT 0,
+  cursor_time     TIMESTAMPTZ,
+  cursor_entry_id TEXT,
+  creation_time   TIMESTAMPTZ NOT NULL DEFAULT now(),
+  start_time      TIMESTAMPTZ,
+  enqueued_time   TIMESTAMPTZ,
+  PRIMARY KEY (actor_id, memory_id)
+);
+CREATE INDEX IF NOT EXISTS reembed_memories_open_idx ON reembed_memories(creation_time) WHERE enqueued_time IS NULL;
+
+-- Background jobs (reindex, bulk entry deletes) run by the jobs worker; a
+-- running job whose update_time goes stale is claimed again
+CREATE TABLE IF NOT EXISTS jobs (
+  job_id           TEXT PRIMARY KEY,
+  actor_id         TEXT NOT NULL,
+  kind             TEXT NOT NULL,
+  vault_id         TEXT,
+  memory_id        TEXT,
+  params           JSONB,
+  status           TEXT NOT NULL DEFAULT 'queued',
+  done             INT NOT NULL DEFAULT 0,
+  total            INT NOT NULL DEFAULT 0,
+  error            TEXT,
+  cancel_requested BOOLEAN NOT NULL DEFAULT FALSE,
+  creation_time    TIMESTAMPTZ NOT NULL DEFAULT now(),
+  update_time      TIMESTAMPTZ NOT NULL DEFAULT now(),
+  finish_time      TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS jobs_open_idx ON jobs(creation_time) WHERE status IN ('queued', 'running');
+
+-- Entry expiry: entries past expiration_time are hidden from reads and
+-- deleted by the retention reaper; a memory's entry_ttl_seconds sets it for
+-- new entries written without one
+ALTER TABLE memories ADD COLUMN IF NOT EXISTS entry_ttl_seconds INT NOT NULL DEFAULT 0;
+ALTER TABLE memory_entries ADD COLUMN IF NOT EXISTS expiration_time TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS memory_entries_expiration_idx ON memory_entries(expiration_time) WHERE expiration_time IS NOT NULL;
+
+-- Idempotent entry writes: a client-chosen idempotency_key makes a repeated
+-- create of the same entry in the memory return the first one
+ALTER TABLE memory_entries ADD COLUMN IF NOT EXISTS idempotency_key TEXT;
+CREATE UNIQUE INDEX IF NOT EXISTS memory_entries_idempotency_idx ON memory_entries(actor_id, memory_id, idempotency_key) WHERE idempotency_key IS NOT NULL;
@@ -0,0 +1,12 @@
+package schema
+
+import "testing"
+
+func TestVersion(t *testing.T) {
+	if Version == "" {
+		t.Fatal("schema.sql must declare its schema-version")
+	}
+	if got := version("-- header\n-- schema-version: 7\nCREATE TABLE t (id INT);\n"); got != "7" {
+		t.Fatalf("version = %q, want 7", got)
+	}
+}
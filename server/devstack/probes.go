@@ -0,0 +1,125 @@
+package devstack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// getJSON fetches url into out, failing on any non-200 response.
+func getJSON(ctx context.Context, url string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("GET %s: %s: %s", url, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// checkHealth passes when memory-service reports itself healthy.
+func checkHealth(ctx context.Context, api string) error {
+	var h struct {
+		Status string `json:"status"`
+	}
+	if err := getJSON(ctx, strings.TrimRight(api, "/")+"/v0/health", &h); err != nil {
+		return err
+	}
+	if h.Status != "healthy" {
+		return fmt.Errorf("memory-service status %q", h.Status)
+	}
+	return nil
+}
+
+// checkWeaviate passes when the index classes memory-service writes exist.
+func checkWeaviate(ctx context.Context, host string) error {
+	var schema struct {
+		Classes []struct {
+			Class string `json:"class"`
+		} `json:"classes"`
+	}
+	if err := getJSON(ctx, "http://"+host+"/v1/schema", &schema); err != nil {
+		return err
+	}
+	have := map[string]bool{}
+	for _, c := range schema.Classes {
+		have[c.Class] = true
+	}
+	for _, want := range []string{"MemoryEntry", "MemoryContext"} {
+		if !have[want] {
+			return fmt.Errorf("class %s missing; run mycelian-dev up", want)
+		}
+	}
+	return nil
+}
+
+// hasModel reports whether Ollama already serves model; names match with or
+// without a tag, so "nomic-embed-text" finds "nomic-embed-text:latest".
+func hasModel(ctx context.Context, ollama, model string) (bool, error) {
+	var tags struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	if err := getJSON(ctx, strings.TrimRight(ollama, "/")+"/api/tags", &tags); err != nil {
+		return false, err
+	}
+	for _, m := range tags.Models {
+		if m.Name == model || strings.TrimSuffix(m.Name, ":latest") == model {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// checkModel passes when Ollama is up and serves model.
+func checkModel(ctx context.Context, ollama, model string) error {
+	ok, err := hasModel(ctx, ollama, model)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("model %s not pulled", model)
+	}
+	return nil
+}
+
+// ensureModel pulls model into Ollama unless it is already there and
+// reports whether it pulled.
+func ensureModel(ctx context.Context, ollama, model string) (bool, error) {
+	ok, err := hasModel(ctx, ollama, model)
+	if err != nil || ok {
+		return false, err
+	}
+	body, _ := json.Marshal(map[string]interface{}{"model": model, "stream": false})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(ollama, "/")+"/api/pull", bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	var res struct {
+		Status string `json:"status"`
+		Error  string `json:"error"`
+	}
+	_ = json.NewDecoder(resp.Body).Decode(&res)
+	if resp.StatusCode != http.StatusOK || res.Error != "" {
+		return false, fmt.Errorf("pull %s: %s %s", model, resp.Status, res.Error)
+	}
+	return true, nil
+}
@@ -0,0 +1,149 @@
+package devstack
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRun_Usage(t *testing.T) {
+	var b strings.Builder
+	if err := Run(nil, &b); err == nil || !strings.Contains(b.String(), "usage:") {
+		t.Fatalf("expected usage error, got %v", err)
+	}
+	if err := Run([]string{"deploy"}, &b); err == nil {
+		t.Fatal("expected unknown command error")
+	}
+	if err := Run([]string{"down", "--compose", ""}, &b); err == nil {
+		t.Fatal("expected down without a compose file to fail")
+	}
+}
+
+func TestStatus_ReportsEveryComponent(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"status":"healthy"}`)
+	}))
+	defer api.Close()
+
+	var b strings.Builder
+	err := Run([]string{"status", "--dsn", "", "--weaviate", "127.0.0.1:1", "--ollama", "http://127.0.0.1:1", "--api", api.URL}, &b)
+	if err == nil || !strings.Contains(err.Error(), "3 of 4") {
+		t.Fatalf("expected 3 of 4 components down, got %v", err)
+	}
+	out := b.String()
+	for _, want := range []string{"postgres        FAIL", "weaviate        FAIL", "ollama          FAIL", "memory-service  ok"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("status output lacks %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestEnsureModel(t *testing.T) {
+	var pulls []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			_, _ = io.WriteString(w, `{"models":[{"name":"nomic-embed-text:latest"}]}`)
+		case "/api/pull":
+			var req struct {
+				Model  string `json:"model"`
+				Stream bool   `json:"stream"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			pulls = append(pulls, req.Model)
+			_, _ = io.WriteString(w, `{"status":"success"}`)
+		}
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+
+	if pulled, err := ensureModel(ctx, srv.URL, "nomic-embed-text"); err != nil || pulled {
+		t.Fatalf("present model: pulled=%v err=%v", pulled, err)
+	}
+	if pulled, err := ensureModel(ctx, srv.URL, "mxbai-embed-large"); err != nil || !pulled {
+		t.Fatalf("missing model: pulled=%v err=%v", pulled, err)
+	}
+	if len(pulls) != 1 || pulls[0] != "mxbai-embed-large" {
+		t.Fatalf("unexpected pulls: %v", pulls)
+	}
+	if err := checkModel(ctx, srv.URL, "all-minilm"); err == nil {
+		t.Fatal("expected checkModel to fail for a model that is not pulled")
+	}
+}
+
+// fakeAPI records the vault, memory, entry and context writes of Seed.
+type fakeAPI struct {
+	vaults   []map[string]string
+	entries  int
+	contexts []string
+}
+
+func (f *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer k" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/v0/vaults":
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"vaults": f.vaults})
+	case r.Method == http.MethodPost && r.URL.Path == "/v0/vaults":
+		var v map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&v)
+		v["vaultId"] = "v1"
+		f.vaults = append(f.vaults, v)
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(v)
+	case r.Method == http.MethodPost && r.URL.Path == "/v0/vaults/v1/memories":
+		w.WriteHeader(http.StatusCreated)
+		_, _ = io.WriteString(w, `{"memoryId":"m1"}`)
+	case r.Method == http.MethodPost && r.URL.Path == "/v0/vaults/v1/memories/m1/entries":
+		f.entries++
+		w.WriteHeader(http.StatusCreated)
+		_, _ = io.WriteString(w, `{}`)
+	case r.Method == http.MethodPut && r.URL.Path == "/v0/vaults/v1/memories/m1/contexts":
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get("Content-Type") != "text/plain" {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+		f.contexts = append(f.contexts, string(body))
+		w.WriteHeader(http.StatusCreated)
+		_, _ = io.WriteString(w, `{}`)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestSeed_IsIdempotent(t *testing.T) {
+	api := &fakeAPI{}
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+	ctx := context.Background()
+
+	res, err := Seed(ctx, srv.URL, "k")
+	if err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	if !res.Created || res.VaultID != "v1" || res.MemoryID != "m1" || res.Entries != len(sampleEntries) {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if api.entries != len(sampleEntries) || len(api.contexts) != 1 || !strings.Contains(api.contexts[0], "## Preferences") {
+		t.Fatalf("unexpected writes: entries=%d contexts=%q", api.entries, api.contexts)
+	}
+
+	res, err = Seed(ctx, srv.URL, "k")
+	if err != nil || res.Created || res.VaultID != "v1" {
+		t.Fatalf("second seed: res=%+v err=%v", res, err)
+	}
+	if len(api.vaults) != 1 || api.entries != len(sampleEntries) {
+		t.Fatalf("second seed wrote data: vaults=%d entries=%d", len(api.vaults), api.entries)
+	}
+
+	if _, err := Seed(ctx, srv.URL, "wrong"); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Fatalf("expected unauthorized error, got %v", err)
+	}
+}
@@ -0,0 +1,141 @@
+package devstack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Titles of the sample data created by Seed.
+const (
+	SampleVaultTitle  = "dev-sample"
+	SampleMemoryTitle = "getting-started"
+)
+
+// sampleEntries are written to the sample memory, oldest first.
+var sampleEntries = []struct {
+	raw, summary string
+	tags         map[string]interface{}
+}{
+	{"User: I'm setting up Mycelian locally to give my coding agent long-term memory.",
+		"User is setting up Mycelian locally for a coding agent", map[string]interface{}{"setup": true}},
+	{"User: We use Postgres for storage and Weaviate for search; embeddings come from Ollama.",
+		"Stack: Postgres storage, Weaviate search, Ollama embeddings", map[string]interface{}{"architecture": true}},
+	{"User: Please keep answers short and always mention which memory you used.",
+		"User prefers short answers that cite the memory used", map[string]interface{}{"preference": true}},
+}
+
+const sampleContext = `## Project
+Local Mycelian setup for a coding agent (Postgres, Weaviate, Ollama).
+
+## Preferences
+Short answers that cite the memory used.`
+
+// SeedResult describes the sample data. Created is false when the sample
+// vault already existed, in which case nothing was written.
+type SeedResult struct {
+	VaultID  string
+	MemoryID string
+	Entries  int
+	Created  bool
+}
+
+// Seed creates the sample vault, memory, entries and context through the
+// memory-service API, unless a vault titled SampleVaultTitle exists.
+func Seed(ctx context.Context, api, apiKey string) (*SeedResult, error) {
+	c := apiClient{base: strings.TrimRight(api, "/"), key: apiKey}
+
+	var list struct {
+		Vaults []struct {
+			VaultID string `json:"vaultId"`
+			Title   string `json:"title"`
+		} `json:"vaults"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/v0/vaults", nil, http.StatusOK, &list); err != nil {
+		return nil, err
+	}
+	for _, v := range list.Vaults {
+		if v.Title == SampleVaultTitle {
+			return &SeedResult{VaultID: v.VaultID}, nil
+		}
+	}
+
+	var vault struct {
+		VaultID string `json:"vaultId"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/v0/vaults", map[string]string{
+		"title": SampleVaultTitle, "description": "Sample data created by mycelian-dev up",
+	}, http.StatusCreated, &vault); err != nil {
+		return nil, err
+	}
+	var mem struct {
+		MemoryID string `json:"memoryId"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/v0/vaults/"+vault.VaultID+"/memories", map[string]string{
+		"title": SampleMemoryTitle, "memoryType": "project", "description": "A first memory to search and extend",
+	}, http.StatusCreated, &mem); err != nil {
+		return nil, err
+	}
+	res := &SeedResult{VaultID: vault.VaultID, MemoryID: mem.MemoryID, Created: true}
+
+	memPath := "/v0/vaults/" + vault.VaultID + "/memories/" + mem.MemoryID
+	for _, e := range sampleEntries {
+		if err := c.do(ctx, http.MethodPost, memPath+"/entries", map[string]interface{}{
+			"rawEntry": e.raw, "summary": e.summary, "tags": e.tags,
+		}, http.StatusCreated, nil); err != nil {
+			return nil, err
+		}
+		res.Entries++
+	}
+	if err := c.do(ctx, http.MethodPut, memPath+"/contexts", sampleContext, http.StatusCreated, nil); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+type apiClient struct {
+	base, key string
+}
+
+// do sends body (JSON, or plain text for a string) and decodes the response
+// into out when it is non-nil.
+func (c apiClient) do(ctx context.Context, method, path string, body interface{}, want int, out interface{}) error {
+	var rd io.Reader
+	contentType := ""
+	switch b := body.(type) {
+	case nil:
+	case string:
+		rd, contentType = strings.NewReader(b), "text/plain"
+	default:
+		raw, err := json.Marshal(b)
+		if err != nil {
+			return err
+		}
+		rd, contentType = bytes.NewReader(raw), "application/json"
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.base+path, rd)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.key)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != want {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -6,12 +6,17 @@ require github.com/mycelian/mycelian-memory/pkg/devauth v0.0.0
 
 replace github.com/mycelian/mycelian-memory/pkg/devauth => ../pkg/devauth
 
+require github.com/mycelian/mycelian-memory/pkg/schema v0.0.0
+
+replace github.com/mycelian/mycelian-memory/pkg/schema => ../pkg/schema
+
 require (
 	github.com/go-openapi/strfmt v0.23.0 // indirect
 	github.com/google/uuid v1.6.0
 	github.com/gorilla/mux v1.8.1
 	github.com/jackc/pgx/v5 v5.7.5
 	github.com/kelseyhightower/envconfig v1.4.0
+	github.com/klauspost/compress v1.18.0
 	github.com/rs/zerolog v1.34.0
 	github.com/weaviate/weaviate v1.31.4
 	github.com/weaviate/weaviate-go-client/v5 v5.2.1
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"net/http"
+
+	respond "github.com/mycelian/mycelian-memory/server/internal/api/respond"
+)
+
+// Feature names reported by GET /v0/capabilities. Clients use them to pick
+// code paths that work against servers of different versions; a name a
+// server does not list is not supported by it.
+const (
+	FeatureSearch             = "search"
+	FeatureSearchExplain      = "searchExplain"
+	FeatureEntriesScan        = "entriesScan"
+	FeatureIngestionBatches   = "ingestionBatches"
+	FeatureConversations      = "conversations"
+	FeatureContextDocuments   = "contextDocuments"
+	FeatureAppendOnlyMemories = "appendOnlyMemories"
+	FeatureEntriesBatch       = "entriesBatch"
+	FeatureConversationTime   = "conversationTime"
+	FeatureVaultSearch        = "vaultSearch"
+	FeatureReranker           = "reranker"
+	FeatureEntityAliases      = "entityAliases"
+	FeatureSearchTimeWindows  = "searchTimeWindows"
+	FeatureActorDefaults      = "actorDefaults"
+	FeatureSummarize          = "summarize"
+	FeatureSearchBatch        = "searchBatch"
+	FeatureContextSections    = "contextSections"
+	FeatureEntryUsage         = "entryUsage"
+	FeatureTitleUpdates       = "titleUpdates"
+	FeatureEntryRoles         = "entryRoles"
+	FeatureRankingProfiles    = "rankingProfiles"
+	FeatureIndexStatus        = "indexStatus"
+	FeatureBulkTagUpdates     = "bulkTagUpdates"
+	FeatureContextCheck       = "contextCheck"
+	FeatureSimilarEntries     = "similarEntries"
+	FeatureVaultClone         = "vaultClone"
+	FeatureSearchTitleScopes  = "searchTitleScopes"
+	FeatureRecentSummaries    = "recentSummaries"
+	FeatureSearchGrouping     = "searchGrouping"
+	FeatureBootstrap          = "bootstrap"
+	FeatureWebhooks           = "webhooks"
+	FeatureEntriesPagination  = "entriesPagination"
+	FeatureTrash              = "trash"
+	FeatureSearchBoost        = "searchBoost"
+	FeatureJobs               = "jobs"
+	FeatureEntryExpiry        = "entryExpiry"
+	FeatureIdempotentEntries  = "idempotentEntries"
+	FeatureEntryCorrections   = "entryCorrections"
+	FeatureTagFilters         = "tagFilters"
+)
+
+var knownFeatures = []string{
+	FeatureSearch, FeatureSearchExplain, FeatureEntriesScan, FeatureIngestionBatches,
+	FeatureConversations, FeatureContextDocuments, FeatureAppendOnlyMemories,
+	FeatureEntriesBatch, FeatureConversationTime, FeatureVaultSearch, FeatureReranker, FeatureEntityAliases,
+	FeatureSearchTimeWindows, FeatureActorDefaults, FeatureSummarize, FeatureSearchBatch, FeatureContextSections,
+	FeatureEntryUsage, FeatureTitleUpdates, FeatureEntryRoles, FeatureRankingProfiles, FeatureIndexStatus,
+	FeatureBulkTagUpdates, FeatureContextCheck, FeatureSimilarEntries, FeatureVaultClone,
+	FeatureSearchTitleScopes, FeatureRecentSummaries, FeatureSearchGrouping, FeatureBootstrap, FeatureWebhooks,
+	FeatureEntriesPagination, FeatureTrash, FeatureSearchBoost, FeatureJobs, FeatureEntryExpiry,
+	FeatureIdempotentEntries, FeatureEntryCorrections, FeatureTagFilters,
+}
+
+// CapabilitiesHandler serves the features enabled while the router was built.
+// Enable must not be called once the server is serving.
+type CapabilitiesHandler struct {
+	features map[string]bool
+}
+
+// NewCapabilitiesHandler reports every known feature as disabled until enabled.
+func NewCapabilitiesHandler() *CapabilitiesHandler {
+	h := &CapabilitiesHandler{features: make(map[string]bool, len(knownFeatures))}
+	for _, f := range knownFeatures {
+		h.features[f] = false
+	}
+	return h
+}
+
+// Enable marks features as available.
+func (h *CapabilitiesHandler) Enable(features ...string) {
+	for _, f := range features {
+		h.features[f] = true
+	}
+}
+
+// GetCapabilities handles GET /v0/capabilities. Like /v0/health it needs no
+// API key, so clients can negotiate before their first authenticated call.
+func (h *CapabilitiesHandler) GetCapabilities(w http.ResponseWriter, r *http.Request) {
+	out := map[string]interface{}{
+		"apiVersion": "v0",
+		"features":   h.features,
+	}
+	if schemaVersion != "" {
+		out["schemaVersion"] = schemaVersion
+	}
+	respond.WriteJSON(w, http.StatusOK, out)
+}
@@ -0,0 +1,32 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetCapabilities(t *testing.T) {
+	h := NewCapabilitiesHandler()
+	h.Enable(FeatureSearch, FeatureConversations)
+
+	w := httptest.NewRecorder()
+	h.GetCapabilities(w, httptest.NewRequest(http.MethodGet, "/v0/capabilities", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	var body struct {
+		APIVersion string          `json:"apiVersion"`
+		Features   map[string]bool `json:"features"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body.APIVersion != "v0" || !body.Features[FeatureSearch] || !body.Features[FeatureConversations] {
+		t.Fatalf("unexpected body: %+v", body)
+	}
+	if v, ok := body.Features[FeatureReranker]; !ok || v {
+		t.Fatalf("known but disabled features must be listed as false: %+v", body.Features)
+	}
+}
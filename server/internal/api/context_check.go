@@ -0,0 +1,41 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/services"
+)
+
+// contextWithCheck is a saved context with the check run before saving it.
+type contextWithCheck struct {
+	*model.MemoryContext
+	Check *model.ContextCheck `json:"check"`
+}
+
+// contextCheckLimit reads ?check=true and ?checkEntries=N from a context put:
+// how many recent entries to check the context against, 0 for no check.
+func contextCheckLimit(r *http.Request) (int, error) {
+	q := r.URL.Query()
+	check := false
+	if raw := q.Get("check"); raw != "" {
+		var err error
+		if check, err = strconv.ParseBool(raw); err != nil {
+			return 0, fmt.Errorf("check must be true or false")
+		}
+	}
+	if !check {
+		return 0, nil
+	}
+	limit := services.DefaultContextCheckEntries
+	if raw := q.Get("checkEntries"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 || n > services.MaxContextCheckEntries {
+			return 0, fmt.Errorf("checkEntries must be between 1 and %d", services.MaxContextCheckEntries)
+		}
+		limit = n
+	}
+	return limit, nil
+}
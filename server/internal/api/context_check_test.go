@@ -0,0 +1,29 @@
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mycelian/mycelian-memory/server/internal/services"
+)
+
+func TestContextCheckLimit(t *testing.T) {
+	cases := map[string]struct {
+		want    int
+		wantErr bool
+	}{
+		"":                                {0, false},
+		"?check=false&checkEntries=10":    {0, false},
+		"?check=true":                     {services.DefaultContextCheckEntries, false},
+		"?check=1&checkEntries=10":        {10, false},
+		"?check=maybe":                    {0, true},
+		"?check=true&checkEntries=0":      {0, true},
+		"?check=true&checkEntries=100000": {0, true},
+	}
+	for query, tc := range cases {
+		got, err := contextCheckLimit(httptest.NewRequest("PUT", "/v0/vaults/v1/memories/m1/contexts"+query, nil))
+		if got != tc.want || (err != nil) != tc.wantErr {
+			t.Errorf("%q: got %d, %v; want %d (error %v)", query, got, err, tc.want, tc.wantErr)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// CORSConfig controls cross-origin access for browser clients.
+type CORSConfig struct {
+	AllowedOrigins   []string // exact origins, or "*" for any; empty disables CORS
+	AllowedHeaders   []string // request headers browsers may send
+	AllowCredentials bool     // allow cookies/Authorization on credentialed requests; ignored for "*"
+	MaxAgeSeconds    int      // preflight cache lifetime (0 omits the header)
+}
+
+// corsExposedHeaders are response headers browser code may read.
+const corsExposedHeaders = "ETag, X-Request-ID"
+
+// corsAllowedMethods covers every method the API routes use.
+const corsAllowedMethods = "GET, POST, PUT, PATCH, DELETE, OPTIONS"
+
+// CORS wraps the whole router (not via Router.Use) so preflight OPTIONS
+// requests are answered before route method matching rejects them.
+func CORS(cfg CORSConfig) func(http.Handler) http.Handler {
+	anyOrigin := false
+	origins := make(map[string]bool, len(cfg.AllowedOrigins))
+	for _, o := range cfg.AllowedOrigins {
+		o = strings.TrimRight(strings.TrimSpace(o), "/")
+		if o == "*" {
+			anyOrigin = true
+		} else if o != "" {
+			origins[o] = true
+		}
+	}
+	headers := strings.Join(cfg.AllowedHeaders, ", ")
+
+	return func(next http.Handler) http.Handler {
+		if !anyOrigin && len(origins) == 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" || (!anyOrigin && !origins[origin]) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			h := w.Header()
+			h.Add("Vary", "Origin")
+			if anyOrigin {
+				h.Set("Access-Control-Allow-Origin", "*")
+			} else {
+				h.Set("Access-Control-Allow-Origin", origin)
+			}
+			if cfg.AllowCredentials && !anyOrigin {
+				h.Set("Access-Control-Allow-Credentials", "true")
+			}
+			h.Set("Access-Control-Expose-Headers", corsExposedHeaders)
+
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				h.Set("Access-Control-Allow-Methods", corsAllowedMethods)
+				if headers != "" {
+					h.Set("Access-Control-Allow-Headers", headers)
+				}
+				if cfg.MaxAgeSeconds > 0 {
+					h.Set("Access-Control-Max-Age", strconv.Itoa(cfg.MaxAgeSeconds))
+				}
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func corsRouter(cfg CORSConfig) http.Handler {
+	r := mux.NewRouter()
+	r.HandleFunc("/v0/search", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) }).Methods("POST")
+	return CORS(cfg)(r)
+}
+
+func TestCORS_Preflight(t *testing.T) {
+	h := corsRouter(CORSConfig{AllowedOrigins: []string{"http://localhost:3000"}, AllowedHeaders: []string{"Authorization", "Content-Type"}, AllowCredentials: true, MaxAgeSeconds: 60})
+
+	req := httptest.NewRequest(http.MethodOptions, "/v0/search", nil)
+	req.Header.Set("Origin", "http://localhost:3000")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204 preflight, got %d", w.Code)
+	}
+	want := map[string]string{
+		"Access-Control-Allow-Origin":      "http://localhost:3000",
+		"Access-Control-Allow-Credentials": "true",
+		"Access-Control-Allow-Headers":     "Authorization, Content-Type",
+		"Access-Control-Max-Age":           "60",
+	}
+	for k, v := range want {
+		if got := w.Header().Get(k); got != v {
+			t.Errorf("%s: want %q, got %q", k, v, got)
+		}
+	}
+}
+
+func TestCORS_Origins(t *testing.T) {
+	cases := []struct {
+		name   string
+		cfg    CORSConfig
+		origin string
+		want   string
+	}{
+		{"disallowed origin", CORSConfig{AllowedOrigins: []string{"http://a.test"}}, "http://b.test", ""},
+		{"wildcard", CORSConfig{AllowedOrigins: []string{"*"}}, "http://b.test", "*"},
+		{"wildcard never echoes origin", CORSConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true}, "http://b.test", "*"},
+		{"disabled", CORSConfig{}, "http://b.test", ""},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/v0/search", nil)
+		req.Header.Set("Origin", tc.origin)
+		w := httptest.NewRecorder()
+		corsRouter(tc.cfg).ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: expected request to reach handler, got %d", tc.name, w.Code)
+		}
+		if got := w.Header().Get("Access-Control-Allow-Origin"); got != tc.want {
+			t.Errorf("%s: Allow-Origin want %q, got %q", tc.name, tc.want, got)
+		}
+	}
+}
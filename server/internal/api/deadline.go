@@ -0,0 +1,157 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	respond "github.com/mycelian/mycelian-memory/server/internal/api/respond"
+)
+
+// RequestTimeoutHeader carries the time a client is willing to wait for a response.
+const RequestTimeoutHeader = "X-Request-Timeout"
+
+// RequestDeadline turns X-Request-Timeout into a context deadline for the
+// handler and every DB and search index call it makes. When the deadline
+// passes before the handler responds, the client gets 504 with diagnostics
+// and the handler's late output is discarded. Requests without the header are
+// untouched. Values above max are capped to max (0 disables the cap).
+func RequestDeadline(max time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			raw := r.Header.Get(RequestTimeoutHeader)
+			if raw == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			timeout, err := parseRequestTimeout(raw)
+			if err != nil {
+				respond.WriteBadRequest(w, err.Error())
+				return
+			}
+			if max > 0 && timeout > max {
+				timeout = max
+			}
+			serveWithDeadline(w, r, next, timeout)
+		})
+	}
+}
+
+// parseRequestTimeout accepts a Go duration ("2s", "1500ms") or a bare number of seconds.
+func parseRequestTimeout(raw string) (time.Duration, error) {
+	raw = strings.TrimSpace(raw)
+	d, err := time.ParseDuration(raw)
+	if err != nil {
+		secs, ferr := strconv.ParseFloat(raw, 64)
+		if ferr != nil {
+			return 0, fmt.Errorf("invalid %s %q: want a duration like 2s or a number of seconds", RequestTimeoutHeader, raw)
+		}
+		d = time.Duration(secs * float64(time.Second))
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("invalid %s %q: must be positive", RequestTimeoutHeader, raw)
+	}
+	return d, nil
+}
+
+func serveWithDeadline(w http.ResponseWriter, r *http.Request, next http.Handler, timeout time.Duration) {
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	tw := &deadlineWriter{header: make(http.Header)}
+	done := make(chan struct{})
+	panicked := make(chan any, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				panicked <- p
+			}
+		}()
+		next.ServeHTTP(tw, r.WithContext(ctx))
+		close(done)
+	}()
+
+	select {
+	case p := <-panicked:
+		panic(p) // re-raise on the serving goroutine so Recover handles it
+	case <-done:
+		tw.mu.Lock()
+		defer tw.mu.Unlock()
+		dst := w.Header()
+		for k, v := range tw.header {
+			dst[k] = v
+		}
+		if tw.status == 0 {
+			tw.status = http.StatusOK
+		}
+		w.WriteHeader(tw.status)
+		_, _ = w.Write(tw.body.Bytes())
+	case <-ctx.Done():
+		tw.mu.Lock()
+		defer tw.mu.Unlock()
+		tw.timedOut = true
+		if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return // client went away; nobody is listening
+		}
+		elapsed := time.Since(start)
+		log.Warn().Str("requestId", RequestIDFrom(r.Context())).Str("method", r.Method).Str("url", r.URL.String()).Dur("timeout", timeout).Dur("elapsed", elapsed).
+			Int("handlerStatus", tw.status).Msg("request deadline exceeded")
+		resp := map[string]interface{}{
+			"error":     http.StatusText(http.StatusGatewayTimeout),
+			"code":      http.StatusGatewayTimeout,
+			"message":   fmt.Sprintf("request exceeded %s of %s", RequestTimeoutHeader, timeout),
+			"timeoutMs": timeout.Milliseconds(),
+			"elapsedMs": elapsed.Milliseconds(),
+		}
+		if id := RequestIDFrom(r.Context()); id != "" {
+			resp["requestId"] = id
+		}
+		if tw.status != 0 {
+			// The handler had started its response; surface what it got to.
+			resp["handlerStatus"] = tw.status
+		}
+		respond.WriteJSON(w, http.StatusGatewayTimeout, resp)
+	}
+}
+
+// deadlineWriter buffers the handler's response so it can be replaced by a
+// 504 if the deadline fires first.
+type deadlineWriter struct {
+	mu       sync.Mutex
+	header   http.Header
+	body     bytes.Buffer
+	status   int
+	timedOut bool
+}
+
+func (tw *deadlineWriter) Header() http.Header { return tw.header }
+
+func (tw *deadlineWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut || tw.status != 0 {
+		return
+	}
+	tw.status = code
+}
+
+func (tw *deadlineWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if tw.status == 0 {
+		tw.status = http.StatusOK
+	}
+	return tw.body.Write(b)
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func serveDeadline(t *testing.T, h http.HandlerFunc, max time.Duration, timeout string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/v0/x", nil)
+	if timeout != "" {
+		req.Header.Set(RequestTimeoutHeader, timeout)
+	}
+	w := httptest.NewRecorder()
+	RequestDeadline(max)(h).ServeHTTP(w, req)
+	return w
+}
+
+func TestRequestDeadline_ExpiryReturns504(t *testing.T) {
+	// Stands in for a DB or index call that honours ctx but whose backend hangs.
+	blocked := func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	w := serveDeadline(t, blocked, time.Minute, "20ms")
+	if w.Code != http.StatusGatewayTimeout {
+		t.Fatalf("expected 504, got %d", w.Code)
+	}
+	var resp struct {
+		TimeoutMs int64 `json:"timeoutMs"`
+		ElapsedMs int64 `json:"elapsedMs"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.TimeoutMs != 20 || resp.ElapsedMs < 20 {
+		t.Fatalf("unexpected diagnostics: %+v", resp)
+	}
+}
+
+func TestRequestDeadline_PassThrough(t *testing.T) {
+	var deadline time.Time
+	var hasDeadline bool
+	ok := func(w http.ResponseWriter, r *http.Request) {
+		deadline, hasDeadline = r.Context().Deadline()
+		w.Header().Set("X-Test", "1")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("done"))
+	}
+
+	if w := serveDeadline(t, ok, time.Minute, ""); w.Code != http.StatusCreated || hasDeadline {
+		t.Fatalf("no header: code=%d hasDeadline=%v", w.Code, hasDeadline)
+	}
+
+	start := time.Now()
+	w := serveDeadline(t, ok, 2*time.Second, "30")
+	if w.Code != http.StatusCreated || w.Body.String() != "done" || w.Header().Get("X-Test") != "1" {
+		t.Fatalf("buffered response not copied: code=%d body=%q", w.Code, w.Body.String())
+	}
+	if !hasDeadline || deadline.Sub(start) > 2*time.Second+100*time.Millisecond {
+		t.Fatalf("expected deadline capped to 2s, got %v", deadline.Sub(start))
+	}
+}
+
+func TestRequestDeadline_InvalidHeader(t *testing.T) {
+	for _, v := range []string{"soon", "-1s", "0"} {
+		if w := serveDeadline(t, func(http.ResponseWriter, *http.Request) {}, 0, v); w.Code != http.StatusBadRequest {
+			t.Errorf("%q: expected 400, got %d", v, w.Code)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	respond "github.com/mycelian/mycelian-memory/server/internal/api/respond"
+	"github.com/mycelian/mycelian-memory/server/internal/auth"
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/services"
+)
+
+// ActorHandler serves the calling actor's settings.
+type ActorHandler struct {
+	svc        *services.ActorService
+	authorizer auth.Authorizer
+}
+
+func NewActorHandler(svc *services.ActorService, authorizer auth.Authorizer) *ActorHandler {
+	return &ActorHandler{svc: svc, authorizer: authorizer}
+}
+
+// GetSettings GET /api/actor/settings
+func (h *ActorHandler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	apiKey, err := auth.ExtractAPIKey(r)
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+	actorInfo, err := h.authorizer.Authorize(r.Context(), apiKey, "actor.read", "default")
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+
+	out, err := h.svc.GetSettings(r.Context(), actorInfo.ActorID)
+	if err != nil {
+		respond.WriteInternalError(w, err.Error())
+		return
+	}
+	respond.WriteJSON(w, http.StatusOK, out)
+}
+
+// PutSettings PUT /api/actor/settings
+// Body: {"timeZone": "America/New_York"} (IANA zone name).
+func (h *ActorHandler) PutSettings(w http.ResponseWriter, r *http.Request) {
+	apiKey, err := auth.ExtractAPIKey(r)
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+	actorInfo, err := h.authorizer.Authorize(r.Context(), apiKey, "actor.update", "default")
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+
+	var req struct {
+		TimeZone string `json:"timeZone"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respond.WriteBadRequest(w, "Invalid JSON")
+		return
+	}
+	out, err := h.svc.SetTimeZone(r.Context(), actorInfo.ActorID, req.TimeZone)
+	if err != nil {
+		writeLocationError(w, err)
+		return
+	}
+	respond.WriteJSON(w, http.StatusOK, out)
+}
+
+// GetDefaults GET /v0/actor/defaults
+func (h *ActorHandler) GetDefaults(w http.ResponseWriter, r *http.Request) {
+	h.GetSettings(w, r)
+}
+
+// PutDefaults PUT /v0/actor/defaults
+// Body: {"vaultId": "...", "memoryId": "..."}; empty IDs clear the defaults.
+// An actor is one API key, so the defaults apply per key.
+func (h *ActorHandler) PutDefaults(w http.ResponseWriter, r *http.Request) {
+	apiKey, err := auth.ExtractAPIKey(r)
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+	actorInfo, err := h.authorizer.Authorize(r.Context(), apiKey, "actor.update", "default")
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+
+	var req struct {
+		VaultID  string `json:"vaultId"`
+		MemoryID string `json:"memoryId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respond.WriteBadRequest(w, "Invalid JSON")
+		return
+	}
+	out, err := h.svc.SetDefaults(r.Context(), actorInfo.ActorID, req.VaultID, req.MemoryID)
+	switch {
+	case errors.Is(err, model.ErrValidation):
+		respond.WriteBadRequest(w, err.Error())
+	case errors.Is(err, model.ErrNotFound):
+		respond.WriteNotFound(w, "vault or memory not found")
+	case err != nil:
+		respond.WriteInternalError(w, err.Error())
+	default:
+		respond.WriteJSON(w, http.StatusOK, out)
+	}
+}
+
+// WithDefaultMemory serves a route without {vaultId} and {memoryId} through
+// next, filling both from the actor's defaults. Without a default memory it
+// responds 400, and 500 when the defaults cannot be read.
+func (h *ActorHandler) WithDefaultMemory(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		apiKey, err := auth.ExtractAPIKey(r)
+		if err != nil {
+			respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+			return
+		}
+		actorInfo, err := h.authorizer.Authorize(r.Context(), apiKey, "actor.read", "default")
+		if err != nil {
+			respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+			return
+		}
+		vaultID, memoryID, err := h.svc.DefaultMemory(r.Context(), actorInfo.ActorID)
+		switch {
+		case errors.Is(err, model.ErrValidation):
+			respond.WriteBadRequest(w, "vaultId and memoryId are required: "+err.Error())
+			return
+		case errors.Is(err, model.ErrNotFound):
+			respond.WriteNotFound(w, err.Error())
+			return
+		case err != nil:
+			respond.WriteInternalError(w, err.Error())
+			return
+		}
+		vars := map[string]string{"vaultId": vaultID, "memoryId": memoryID}
+		for k, v := range mux.Vars(r) {
+			if _, ok := vars[k]; !ok {
+				vars[k] = v
+			}
+		}
+		next(w, mux.SetURLVars(r, vars))
+	}
+}
+
+// writeLocationError maps time zone resolution failures: bad zone names are
+// 400, anything else (store errors) 500.
+func writeLocationError(w http.ResponseWriter, err error) {
+	if errors.Is(err, model.ErrValidation) {
+		respond.WriteBadRequest(w, err.Error())
+		return
+	}
+	respond.WriteInternalError(w, err.Error())
+}
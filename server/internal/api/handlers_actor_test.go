@@ -0,0 +1,104 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/services"
+	"github.com/mycelian/mycelian-memory/server/internal/store"
+)
+
+// defaultsStore keeps one actor's settings in memory.
+type defaultsStore struct {
+	store.Store
+	settings *memActorSettings
+}
+
+func (defaultsStore) Memories() store.Memories             { return memMemories{} }
+func (s defaultsStore) ActorSettings() store.ActorSettings { return s.settings }
+
+type memActorSettings struct {
+	store.ActorSettings
+	cur *model.ActorSettings
+	err error // returned by Get when set
+}
+
+func (m *memActorSettings) Get(context.Context, string) (*model.ActorSettings, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	if m.cur == nil {
+		return nil, model.ErrNotFound
+	}
+	return m.cur, nil
+}
+
+func (m *memActorSettings) PutDefaults(_ context.Context, actorID, vaultID, memoryID string) (*model.ActorSettings, error) {
+	m.cur = &model.ActorSettings{ActorID: actorID, TimeZone: model.DefaultTimeZone, DefaultVaultID: vaultID, DefaultMemoryID: memoryID}
+	return m.cur, nil
+}
+
+func TestWithDefaultMemory(t *testing.T) {
+	settings := &memActorSettings{}
+	h := NewActorHandler(services.NewActorService(defaultsStore{settings: settings}), &mockAuthorizer{})
+	var got map[string]string
+	r := mux.NewRouter()
+	r.HandleFunc("/v0/actor/defaults", h.PutDefaults).Methods("PUT")
+	r.HandleFunc("/v0/entries", h.WithDefaultMemory(func(w http.ResponseWriter, r *http.Request) {
+		got = mux.Vars(r)
+		w.WriteHeader(http.StatusNoContent)
+	})).Methods("GET")
+	do := func(method, path, body string) int {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer test-api-key")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := do("GET", "/v0/entries", ""); code != http.StatusBadRequest {
+		t.Fatalf("without defaults: expected 400, got %d", code)
+	}
+	if code := do("PUT", "/v0/actor/defaults", `{"memoryId":"m1"}`); code != http.StatusBadRequest {
+		t.Fatalf("memory without vault: expected 400, got %d", code)
+	}
+	if code := do("PUT", "/v0/actor/defaults", `{"vaultId":"v1","memoryId":"m1"}`); code != http.StatusOK {
+		t.Fatalf("PutDefaults: expected 200, got %d", code)
+	}
+	if code := do("GET", "/v0/entries", ""); code != http.StatusNoContent || got["vaultId"] != "v1" || got["memoryId"] != "m1" {
+		t.Fatalf("with defaults: code %d vars %v", code, got)
+	}
+
+	settings.err = errors.New("connection refused")
+	if code := do("GET", "/v0/entries", ""); code != http.StatusInternalServerError {
+		t.Fatalf("store failure: expected 500, got %d", code)
+	}
+}
+
+func TestHandleSearch_DefaultMemoryErrors(t *testing.T) {
+	settings := &memActorSettings{}
+	h, _ := NewSearchHandler(&mockEmbedder{}, &mockSearch{}, 0.6, &mockAuthorizer{})
+	h.EnableDefaultMemory(services.NewActorService(defaultsStore{settings: settings}))
+	search := func() int {
+		req := httptest.NewRequest("POST", "/v0/search", bytes.NewBufferString(`{"query":"q"}`))
+		req.Header.Set("Authorization", "Bearer test-api-key")
+		w := httptest.NewRecorder()
+		h.HandleSearch(w, req)
+		return w.Code
+	}
+
+	if code := search(); code != http.StatusBadRequest {
+		t.Fatalf("without defaults: expected 400, got %d", code)
+	}
+	settings.err = errors.New("connection refused")
+	if code := search(); code != http.StatusInternalServerError {
+		t.Fatalf("store failure: expected 500, got %d", code)
+	}
+}
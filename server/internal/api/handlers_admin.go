@@ -0,0 +1,246 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	respond "github.com/mycelian/mycelian-memory/server/internal/api/respond"
+	"github.com/mycelian/mycelian-memory/server/internal/auth"
+	"github.com/mycelian/mycelian-memory/server/internal/logger"
+	"github.com/mycelian/mycelian-memory/server/internal/metrics"
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/services"
+)
+
+// AdminHandler serves operator endpoints under /v0/admin. Every call needs an admin key.
+type AdminHandler struct {
+	memories   *services.MemoryService
+	authorizer auth.Authorizer
+	slo        *metrics.SLOTracker
+	logLevels  *logger.Levels
+}
+
+func NewAdminHandler(memories *services.MemoryService, authorizer auth.Authorizer) *AdminHandler {
+	return &AdminHandler{memories: memories, authorizer: authorizer}
+}
+
+// EnableSLOReport serves GET /v0/admin/slo from tracker.
+func (h *AdminHandler) EnableSLOReport(tracker *metrics.SLOTracker) { h.slo = tracker }
+
+// EnableLogLevels serves /v0/admin/log-levels, reading and changing levels.
+func (h *AdminHandler) EnableLogLevels(levels *logger.Levels) { h.logLevels = levels }
+
+// authorizeAdmin resolves the caller and rejects non-admin keys; it writes
+// the error response itself and returns nil in that case.
+func (h *AdminHandler) authorizeAdmin(w http.ResponseWriter, r *http.Request, operation string) *auth.ActorInfo {
+	apiKey, err := auth.ExtractAPIKey(r)
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return nil
+	}
+	actorInfo, err := h.authorizer.Authorize(r.Context(), apiKey, operation, "default")
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return nil
+	}
+	if actorInfo.KeyType != "admin" {
+		respond.WriteError(w, http.StatusForbidden, "admin key required")
+		return nil
+	}
+	return actorInfo
+}
+
+// ReindexMemory POST /v0/admin/memories/{memoryId}/reindex
+// Body (optional): {"purge": true} deletes the memory's index objects before rebuilding.
+// Responds 202 with the rebuild; poll GET on the same path for progress.
+func (h *AdminHandler) ReindexMemory(w http.ResponseWriter, r *http.Request) {
+	actorInfo, purge, ok := h.reindexRequest(w, r)
+	if !ok {
+		return
+	}
+	job, err := h.memories.ReindexMemory(r.Context(), actorInfo.ActorID, mux.Vars(r)["memoryId"], purge)
+	if err != nil {
+		writeReindexError(w, err)
+		return
+	}
+	respond.WriteJSON(w, http.StatusAccepted, job)
+}
+
+// ReindexMemoryJob POST /v0/admin/memories/{memoryId}/reindex:job
+// Body as for ReindexMemory. Queues the rebuild as a reindex job and
+// responds 202 with it; poll GET /v0/jobs/{jobId}. 404 when jobs are not
+// enabled.
+func (h *AdminHandler) ReindexMemoryJob(w http.ResponseWriter, r *http.Request) {
+	actorInfo, purge, ok := h.reindexRequest(w, r)
+	if !ok {
+		return
+	}
+	if !h.memories.JobsEnabled() {
+		respond.WriteNotFound(w, "jobs are not enabled")
+		return
+	}
+	job, err := h.memories.StartReindexJob(r.Context(), actorInfo.ActorID, mux.Vars(r)["memoryId"], purge)
+	if err != nil {
+		writeReindexError(w, err)
+		return
+	}
+	respond.WriteJSON(w, http.StatusAccepted, job)
+}
+
+// reindexRequest authorizes a reindex and decodes its optional body; it
+// writes the error response and returns false when either fails.
+func (h *AdminHandler) reindexRequest(w http.ResponseWriter, r *http.Request) (*auth.ActorInfo, bool, bool) {
+	actorInfo := h.authorizeAdmin(w, r, "admin.reindex")
+	if actorInfo == nil {
+		return nil, false, false
+	}
+	var req struct {
+		Purge bool `json:"purge"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respond.WriteBadRequest(w, "Invalid JSON")
+			return nil, false, false
+		}
+	}
+	return actorInfo, req.Purge, true
+}
+
+// GetReindexProgress GET /v0/admin/memories/{memoryId}/reindex
+func (h *AdminHandler) GetReindexProgress(w http.ResponseWriter, r *http.Request) {
+	actorInfo := h.authorizeAdmin(w, r, "admin.reindex")
+	if actorInfo == nil {
+		return
+	}
+	job, err := h.memories.ReindexProgress(r.Context(), actorInfo.ActorID, mux.Vars(r)["memoryId"])
+	if err != nil {
+		writeReindexError(w, err)
+		return
+	}
+	respond.WriteJSON(w, http.StatusOK, job)
+}
+
+// GetReembedProgress GET /v0/admin/memories/{memoryId}/reembed
+// Reports the memory's gradual re-embedding after the embedding
+// configuration changed; 404 if it was never marked.
+func (h *AdminHandler) GetReembedProgress(w http.ResponseWriter, r *http.Request) {
+	actorInfo := h.authorizeAdmin(w, r, "admin.reindex")
+	if actorInfo == nil {
+		return
+	}
+	p, err := h.memories.ReembedProgress(r.Context(), actorInfo.ActorID, mux.Vars(r)["memoryId"])
+	if err != nil {
+		writeReindexError(w, err)
+		return
+	}
+	respond.WriteJSON(w, http.StatusOK, p)
+}
+
+// GetReembedOverview GET /v0/admin/reembed
+// Counts the memories marked for the current embedding fingerprint by status.
+func (h *AdminHandler) GetReembedOverview(w http.ResponseWriter, r *http.Request) {
+	if h.authorizeAdmin(w, r, "admin.reindex") == nil {
+		return
+	}
+	o, err := h.memories.ReembedOverview(r.Context())
+	if err != nil {
+		writeReindexError(w, err)
+		return
+	}
+	respond.WriteJSON(w, http.StatusOK, o)
+}
+
+// GetSLO GET /v0/admin/slo
+// Lists every endpoint that served traffic in the last hour with its SLO and
+// error and latency burn rates over the 5m and 1h windows.
+func (h *AdminHandler) GetSLO(w http.ResponseWriter, r *http.Request) {
+	if h.authorizeAdmin(w, r, "admin.slo") == nil {
+		return
+	}
+	if h.slo == nil {
+		respond.WriteNotFound(w, "SLO tracking is disabled")
+		return
+	}
+	endpoints := h.slo.Report()
+	respond.WriteJSON(w, http.StatusOK, map[string]interface{}{"endpoints": endpoints, "count": len(endpoints)})
+}
+
+// GetHealthHistory GET /v0/admin/health
+// Returns recent health transitions of each dependency and of the service,
+// newest first, with which of them are flapping.
+func (h *AdminHandler) GetHealthHistory(w http.ResponseWriter, r *http.Request) {
+	if h.authorizeAdmin(w, r, "admin.health") == nil {
+		return
+	}
+	if healthHistory == nil {
+		respond.WriteNotFound(w, "health monitor is not running")
+		return
+	}
+	respond.WriteJSON(w, http.StatusOK, healthHistory())
+}
+
+// GetLogLevels GET /v0/admin/log-levels
+// Returns the effective level of the default and of every module.
+func (h *AdminHandler) GetLogLevels(w http.ResponseWriter, r *http.Request) {
+	if h.authorizeAdmin(w, r, "admin.logLevels") == nil {
+		return
+	}
+	if h.logLevels == nil {
+		respond.WriteNotFound(w, "runtime log levels are disabled")
+		return
+	}
+	respond.WriteJSON(w, http.StatusOK, map[string]interface{}{"levels": h.logLevels.Snapshot()})
+}
+
+// PutLogLevels PUT /v0/admin/log-levels
+// Body: {"levels": {"default": "info", "store": "debug", "outbox": ""}}; an
+// empty level makes the module follow the default again. Changes apply
+// immediately and last until restart. The request is rejected as a whole
+// when any module or level is invalid.
+func (h *AdminHandler) PutLogLevels(w http.ResponseWriter, r *http.Request) {
+	if h.authorizeAdmin(w, r, "admin.logLevels") == nil {
+		return
+	}
+	if h.logLevels == nil {
+		respond.WriteNotFound(w, "runtime log levels are disabled")
+		return
+	}
+	var req struct {
+		Levels map[string]string `json:"levels"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respond.WriteBadRequest(w, "Invalid JSON")
+		return
+	}
+	if len(req.Levels) == 0 {
+		respond.WriteBadRequest(w, "levels is required")
+		return
+	}
+	// Validate against a copy first so a bad entry changes nothing.
+	check, _ := logger.ParseLevels("info", "")
+	for module, level := range req.Levels {
+		if err := check.Set(module, level); err != nil {
+			respond.WriteBadRequest(w, err.Error())
+			return
+		}
+	}
+	for module, level := range req.Levels {
+		_ = h.logLevels.Set(module, level)
+	}
+	respond.WriteJSON(w, http.StatusOK, map[string]interface{}{"levels": h.logLevels.Snapshot()})
+}
+
+func writeReindexError(w http.ResponseWriter, err error) {
+	if errors.Is(err, model.ErrNotFound) {
+		respond.WriteNotFound(w, err.Error())
+		return
+	}
+	if errors.Is(err, model.ErrConflict) {
+		respond.WriteError(w, http.StatusConflict, err.Error())
+		return
+	}
+	respond.WriteInternalError(w, err.Error())
+}
@@ -0,0 +1,271 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog"
+
+	"github.com/mycelian/mycelian-memory/server/internal/auth"
+	"github.com/mycelian/mycelian-memory/server/internal/logger"
+	"github.com/mycelian/mycelian-memory/server/internal/metrics"
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/services"
+	"github.com/mycelian/mycelian-memory/server/internal/store"
+)
+
+type memReindex struct {
+	started []string
+	running bool
+}
+
+func (m *memReindex) Start(_ context.Context, actorID, memoryID string) (*model.ReindexJob, error) {
+	if memoryID != "m1" {
+		return nil, model.ErrNotFound
+	}
+	m.started = append(m.started, memoryID)
+	return &model.ReindexJob{JobID: "j1", ActorID: actorID, MemoryID: memoryID, EntryCount: 3, ContextCount: 1, Pending: 4, Status: model.ReindexRunning}, nil
+}
+
+func (m *memReindex) Latest(_ context.Context, actorID, memoryID string) (*model.ReindexJob, error) {
+	if len(m.started) == 0 {
+		return nil, model.ErrNotFound
+	}
+	if m.running {
+		return &model.ReindexJob{JobID: "j1", MemoryID: memoryID, EntryCount: 3, ContextCount: 1, Done: 1, Pending: 3, Status: model.ReindexRunning}, nil
+	}
+	return &model.ReindexJob{JobID: "j1", MemoryID: memoryID, EntryCount: 3, ContextCount: 1, Done: 4, Status: model.ReindexCompleted}, nil
+}
+
+type reindexStore struct {
+	store.Store
+	r *memReindex
+	j *oneJob
+}
+
+func (s reindexStore) Reindex() store.Reindex { return s.r }
+func (s reindexStore) Jobs() store.Jobs       { return s.j }
+
+type memReembed struct{ store.Reembed }
+
+func (memReembed) Progress(_ context.Context, actorID, memoryID string) (*model.ReembedProgress, error) {
+	if memoryID != "m1" {
+		return nil, model.ErrNotFound
+	}
+	return &model.ReembedProgress{ActorID: actorID, MemoryID: memoryID, EntryCount: 10, Enqueued: 4, Done: 3, Pending: 1, Status: model.ReembedRunning}, nil
+}
+
+func (memReembed) Overview(context.Context) (*model.ReembedOverview, error) {
+	return &model.ReembedOverview{Fingerprint: "ollama:b", Memories: map[string]int{model.ReembedRunning: 1}, EntryCount: 10, Enqueued: 4}, nil
+}
+
+type reembedStore struct{ store.Store }
+
+func (reembedStore) Reembed() store.Reembed { return memReembed{} }
+
+type standardKeyAuthorizer struct{}
+
+func (standardKeyAuthorizer) Authorize(context.Context, string, string, string) (*auth.ActorInfo, error) {
+	return &auth.ActorInfo{ActorID: "test-user", KeyType: "standard"}, nil
+}
+
+func TestAdminReindexMemory(t *testing.T) {
+	rs := &memReindex{}
+	jobs := &oneJob{}
+	svc := services.NewMemoryService(reindexStore{r: rs, j: jobs}, nil, nil)
+	newRouter := func(a auth.Authorizer) *mux.Router {
+		h := NewAdminHandler(svc, a)
+		r := mux.NewRouter()
+		r.HandleFunc("/v0/admin/memories/{memoryId}/reindex", h.ReindexMemory).Methods("POST")
+		r.HandleFunc("/v0/admin/memories/{memoryId}/reindex", h.GetReindexProgress).Methods("GET")
+		r.HandleFunc("/v0/admin/memories/{memoryId}/reindex:job", h.ReindexMemoryJob).Methods("POST")
+		return r
+	}
+	call := func(r *mux.Router, method, memoryID, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/v0/admin/memories/"+memoryID+"/reindex", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer test-api-key")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	queue := func(r *mux.Router, memoryID, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v0/admin/memories/"+memoryID+"/reindex:job", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer test-api-key")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := call(newRouter(standardKeyAuthorizer{}), http.MethodPost, "m1", ""); w.Code != http.StatusForbidden {
+		t.Fatalf("standard key: expected 403, got %d", w.Code)
+	}
+
+	admin := newRouter(&mockAuthorizer{})
+	if w := call(admin, http.MethodGet, "m1", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("progress before any job: expected 404, got %d", w.Code)
+	}
+	if w := call(admin, http.MethodPost, "missing", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("unknown memory: expected 404, got %d", w.Code)
+	}
+
+	w := call(admin, http.MethodPost, "m1", `{"purge":false}`)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+	var job model.ReindexJob
+	if err := json.NewDecoder(w.Body).Decode(&job); err != nil || job.JobID != "j1" || job.Pending != 4 {
+		t.Fatalf("job: %+v err=%v", job, err)
+	}
+
+	w = call(admin, http.MethodGet, "m1", "")
+	if err := json.NewDecoder(w.Body).Decode(&job); err != nil || w.Code != http.StatusOK || job.Status != model.ReindexCompleted {
+		t.Fatalf("progress: code=%d job=%+v err=%v", w.Code, job, err)
+	}
+
+	// A second job is refused while one is running.
+	rs.running = true
+	if w := call(admin, http.MethodPost, "m1", `{"purge":true}`); w.Code != http.StatusConflict {
+		t.Fatalf("reindex while running: expected 409, got %d", w.Code)
+	}
+	if len(rs.started) != 1 {
+		t.Fatalf("expected one started job, got %v", rs.started)
+	}
+
+	// reindex:job queues the rebuild as a reindex job once jobs are enabled.
+	if w := queue(admin, "m1", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("reindex job without jobs: expected 404, got %d", w.Code)
+	}
+	svc.EnableJobs()
+	if w := queue(admin, "m1", ""); w.Code != http.StatusConflict {
+		t.Fatalf("reindex job while running: expected 409, got %d", w.Code)
+	}
+	rs.running = false
+	w = queue(admin, "m1", `{"purge":true}`)
+	if w.Code != http.StatusAccepted || jobs.job == nil || jobs.job.Kind != model.JobReindex || string(jobs.job.Params) != `{"purge":true}` {
+		t.Fatalf("reindex job: %d %s", w.Code, w.Body.String())
+	}
+	if len(rs.started) != 1 {
+		t.Fatalf("the job must start the rebuild, not the request: %v", rs.started)
+	}
+
+	// The reindex endpoint keeps answering with the rebuild when jobs are on.
+	w = call(admin, http.MethodPost, "m1", "")
+	job = model.ReindexJob{}
+	if err := json.NewDecoder(w.Body).Decode(&job); err != nil || w.Code != http.StatusAccepted || job.JobID != "j1" || job.Pending != 4 {
+		t.Fatalf("reindex with jobs enabled: %d %+v err=%v", w.Code, job, err)
+	}
+}
+
+func TestAdminReembedProgress(t *testing.T) {
+	svc := services.NewMemoryService(reembedStore{}, nil, nil)
+	newRouter := func(a auth.Authorizer) *mux.Router {
+		h := NewAdminHandler(svc, a)
+		r := mux.NewRouter()
+		r.HandleFunc("/v0/admin/memories/{memoryId}/reembed", h.GetReembedProgress).Methods("GET")
+		r.HandleFunc("/v0/admin/reembed", h.GetReembedOverview).Methods("GET")
+		return r
+	}
+	call := func(r *mux.Router, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer test-api-key")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := call(newRouter(standardKeyAuthorizer{}), "/v0/admin/reembed"); w.Code != http.StatusForbidden {
+		t.Fatalf("standard key: expected 403, got %d", w.Code)
+	}
+	admin := newRouter(&mockAuthorizer{})
+	if w := call(admin, "/v0/admin/memories/missing/reembed"); w.Code != http.StatusNotFound {
+		t.Fatalf("unmarked memory: expected 404, got %d", w.Code)
+	}
+
+	w := call(admin, "/v0/admin/memories/m1/reembed")
+	var p model.ReembedProgress
+	if err := json.NewDecoder(w.Body).Decode(&p); err != nil || w.Code != http.StatusOK || p.Enqueued != 4 || p.Status != model.ReembedRunning {
+		t.Fatalf("progress: code=%d p=%+v err=%v", w.Code, p, err)
+	}
+
+	w = call(admin, "/v0/admin/reembed")
+	var o model.ReembedOverview
+	if err := json.NewDecoder(w.Body).Decode(&o); err != nil || w.Code != http.StatusOK || o.Fingerprint != "ollama:b" || o.Memories[model.ReembedRunning] != 1 {
+		t.Fatalf("overview: code=%d o=%+v err=%v", w.Code, o, err)
+	}
+}
+
+func TestAdminGetSLO(t *testing.T) {
+	call := func(h *AdminHandler) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v0/admin/slo", nil)
+		req.Header.Set("Authorization", "Bearer test-api-key")
+		w := httptest.NewRecorder()
+		h.GetSLO(w, req)
+		return w
+	}
+	if w := call(NewAdminHandler(nil, &mockAuthorizer{})); w.Code != http.StatusNotFound {
+		t.Fatalf("tracking disabled: expected 404, got %d", w.Code)
+	}
+
+	objs, _ := metrics.ParseObjectives("*=1s,0.01")
+	tracker := metrics.NewSLOTracker(objs, 14.4, zerolog.Nop())
+	tracker.Observe("GET /v0/vaults", http.StatusOK, 0)
+	h := NewAdminHandler(nil, standardKeyAuthorizer{})
+	h.EnableSLOReport(tracker)
+	if w := call(h); w.Code != http.StatusForbidden {
+		t.Fatalf("standard key: expected 403, got %d", w.Code)
+	}
+
+	h = NewAdminHandler(nil, &mockAuthorizer{})
+	h.EnableSLOReport(tracker)
+	w := call(h)
+	var body struct {
+		Endpoints []metrics.EndpointSLO `json:"endpoints"`
+		Count     int                   `json:"count"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("code=%d err=%v", w.Code, err)
+	}
+	if body.Count != 1 || body.Endpoints[0].Endpoint != "GET /v0/vaults" || body.Endpoints[0].Windows[0].Requests != 1 {
+		t.Fatalf("unexpected report: %+v", body)
+	}
+}
+
+func TestAdminLogLevels(t *testing.T) {
+	levels, _ := logger.ParseLevels("info", "store=debug")
+	h := NewAdminHandler(nil, &mockAuthorizer{})
+	h.EnableLogLevels(levels)
+	call := func(method, body string) (*httptest.ResponseRecorder, map[string]string) {
+		req := httptest.NewRequest(method, "/v0/admin/log-levels", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer test-api-key")
+		w := httptest.NewRecorder()
+		if method == http.MethodGet {
+			h.GetLogLevels(w, req)
+		} else {
+			h.PutLogLevels(w, req)
+		}
+		var resp struct {
+			Levels map[string]string `json:"levels"`
+		}
+		_ = json.NewDecoder(w.Body).Decode(&resp)
+		return w, resp.Levels
+	}
+
+	if w, got := call(http.MethodGet, ""); w.Code != http.StatusOK || got["store"] != "debug" || got["outbox"] != "info" {
+		t.Fatalf("get: code=%d levels=%v", w.Code, got)
+	}
+	if w, _ := call(http.MethodPut, `{"levels":{"outbox":"debug","cache":"warn"}}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("unknown module: expected 400, got %d", w.Code)
+	}
+	if levels.Level("outbox") != zerolog.InfoLevel {
+		t.Fatalf("rejected request changed outbox level to %v", levels.Level("outbox"))
+	}
+	w, got := call(http.MethodPut, `{"levels":{"default":"warn","outbox":"debug","store":""}}`)
+	if w.Code != http.StatusOK || got["default"] != "warn" || got["outbox"] != "debug" || got["store"] != "warn" {
+		t.Fatalf("put: code=%d levels=%v", w.Code, got)
+	}
+}
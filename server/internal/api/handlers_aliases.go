@@ -0,0 +1,114 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	respond "github.com/mycelian/mycelian-memory/server/internal/api/respond"
+	"github.com/mycelian/mycelian-memory/server/internal/auth"
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+// A memory's entity aliases ("Bob" is "Robert Smith") expand search queries
+// over that memory and normalize the entity names recorded on new entries.
+
+// writeEntityAliasError maps service errors to HTTP responses.
+func writeEntityAliasError(w http.ResponseWriter, err error, notFound string) {
+	switch {
+	case errors.Is(err, model.ErrNotFound):
+		respond.WriteNotFound(w, notFound)
+	case errors.Is(err, model.ErrValidation):
+		respond.WriteBadRequest(w, err.Error())
+	case errors.Is(err, model.ErrConflict), errors.Is(err, model.ErrReadOnly):
+		respond.WriteError(w, http.StatusConflict, err.Error())
+	default:
+		respond.WriteInternalError(w, err.Error())
+	}
+}
+
+// ListEntityAliases GET /v0/vaults/{vaultId}/memories/{memoryId}/aliases
+func (h *MemoryHandler) ListEntityAliases(w http.ResponseWriter, r *http.Request) {
+	apiKey, err := auth.ExtractAPIKey(r)
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+	actorInfo, err := h.authorizer.Authorize(r.Context(), apiKey, "memory.read", "default")
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+
+	v := mux.Vars(r)
+	out, err := h.svc.ListEntityAliases(r.Context(), actorInfo.ActorID, v["vaultId"], v["memoryId"])
+	if err != nil {
+		writeEntityAliasError(w, err, "memory not found")
+		return
+	}
+	if out == nil {
+		out = []*model.EntityAlias{}
+	}
+	respond.WriteJSON(w, http.StatusOK, map[string]interface{}{"aliases": out, "count": len(out)})
+}
+
+// PutEntityAlias PUT /v0/vaults/{vaultId}/memories/{memoryId}/aliases
+// Body {"alias":"Bob","canonical":"Robert Smith"} maps the alias to the
+// canonical name, replacing an earlier mapping of the same alias.
+func (h *MemoryHandler) PutEntityAlias(w http.ResponseWriter, r *http.Request) {
+	apiKey, err := auth.ExtractAPIKey(r)
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+	actorInfo, err := h.authorizer.Authorize(r.Context(), apiKey, "memory.write", "default")
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+
+	var req struct {
+		Alias     string `json:"alias"`
+		Canonical string `json:"canonical"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respond.WriteBadRequest(w, "Invalid JSON")
+		return
+	}
+
+	v := mux.Vars(r)
+	out, err := h.svc.PutEntityAlias(r.Context(), actorInfo.ActorID, v["vaultId"], v["memoryId"], req.Alias, req.Canonical)
+	if err != nil {
+		writeEntityAliasError(w, err, "memory not found")
+		return
+	}
+	respond.WriteJSON(w, http.StatusOK, out)
+}
+
+// DeleteEntityAlias DELETE /v0/vaults/{vaultId}/memories/{memoryId}/aliases?alias=Bob
+func (h *MemoryHandler) DeleteEntityAlias(w http.ResponseWriter, r *http.Request) {
+	apiKey, err := auth.ExtractAPIKey(r)
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+	actorInfo, err := h.authorizer.Authorize(r.Context(), apiKey, "memory.write", "default")
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+
+	alias := r.URL.Query().Get("alias")
+	if alias == "" {
+		respond.WriteBadRequest(w, "alias is required")
+		return
+	}
+	v := mux.Vars(r)
+	if err := h.svc.DeleteEntityAlias(r.Context(), actorInfo.ActorID, v["vaultId"], v["memoryId"], alias); err != nil {
+		writeEntityAliasError(w, err, "alias not found")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,194 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+
+	respond "github.com/mycelian/mycelian-memory/server/internal/api/respond"
+	"github.com/mycelian/mycelian-memory/server/internal/auth"
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/services"
+)
+
+// IngestionBatchHandler exposes the ingestion batch registry.
+type IngestionBatchHandler struct {
+	svc        *services.IngestionBatchService
+	authorizer auth.Authorizer
+}
+
+func NewIngestionBatchHandler(svc *services.IngestionBatchService, authorizer auth.Authorizer) *IngestionBatchHandler {
+	return &IngestionBatchHandler{svc: svc, authorizer: authorizer}
+}
+
+// writeBatchError maps service errors to HTTP responses.
+func writeBatchError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, model.ErrNotFound):
+		respond.WriteNotFound(w, "ingestion batch not found")
+	case errors.Is(err, model.ErrValidation):
+		respond.WriteBadRequest(w, err.Error())
+	case errors.Is(err, model.ErrConflict), errors.Is(err, model.ErrReadOnly), errors.Is(err, model.ErrAppendOnly):
+		respond.WriteError(w, http.StatusConflict, err.Error())
+	default:
+		respond.WriteInternalError(w, err.Error())
+	}
+}
+
+// CreateBatch POST /v0/ingestion-batches
+func (h *IngestionBatchHandler) CreateBatch(w http.ResponseWriter, r *http.Request) {
+	apiKey, err := auth.ExtractAPIKey(r)
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+	actorInfo, err := h.authorizer.Authorize(r.Context(), apiKey, "memory.create", "default")
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+
+	var req struct {
+		BatchID      string  `json:"batchId,omitempty"`
+		SourceSystem string  `json:"sourceSystem"`
+		Description  *string `json:"description,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respond.WriteBadRequest(w, "Invalid JSON")
+		return
+	}
+	if err := EntryProvenance(req.SourceSystem, "", req.BatchID); err != nil {
+		respond.WriteBadRequest(w, err.Error())
+		return
+	}
+	if err := MaxLen("description", req.Description, 500); err != nil {
+		respond.WriteBadRequest(w, err.Error())
+		return
+	}
+	out, err := h.svc.CreateBatch(r.Context(), &model.IngestionBatch{
+		ActorID: actorInfo.ActorID, BatchID: req.BatchID, SourceSystem: req.SourceSystem, Description: req.Description,
+	})
+	if err != nil {
+		writeBatchError(w, err)
+		return
+	}
+	respond.WriteJSON(w, http.StatusCreated, out)
+}
+
+// ListBatches GET /v0/ingestion-batches
+func (h *IngestionBatchHandler) ListBatches(w http.ResponseWriter, r *http.Request) {
+	apiKey, err := auth.ExtractAPIKey(r)
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+	actorInfo, err := h.authorizer.Authorize(r.Context(), apiKey, "memory.read", "default")
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+
+	out, err := h.svc.ListBatches(r.Context(), actorInfo.ActorID)
+	if err != nil {
+		respond.WriteInternalError(w, err.Error())
+		return
+	}
+	if out == nil {
+		out = []*model.IngestionBatch{}
+	}
+	respond.WriteJSON(w, http.StatusOK, map[string]interface{}{"batches": out, "count": len(out)})
+}
+
+// GetBatch GET /v0/ingestion-batches/{batchId}
+func (h *IngestionBatchHandler) GetBatch(w http.ResponseWriter, r *http.Request) {
+	apiKey, err := auth.ExtractAPIKey(r)
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+	actorInfo, err := h.authorizer.Authorize(r.Context(), apiKey, "memory.read", "default")
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+
+	out, err := h.svc.GetBatch(r.Context(), actorInfo.ActorID, mux.Vars(r)["batchId"])
+	if err != nil {
+		writeBatchError(w, err)
+		return
+	}
+	respond.WriteJSON(w, http.StatusOK, out)
+}
+
+// ListBatchEntries GET /v0/ingestion-batches/{batchId}/entries?limit=
+func (h *IngestionBatchHandler) ListBatchEntries(w http.ResponseWriter, r *http.Request) {
+	apiKey, err := auth.ExtractAPIKey(r)
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+	actorInfo, err := h.authorizer.Authorize(r.Context(), apiKey, "memory.read", "default")
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+
+	limit := 0
+	if s := r.URL.Query().Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			respond.WriteBadRequest(w, "limit must be a positive integer")
+			return
+		}
+		limit = n
+	}
+	out, err := h.svc.ListBatchEntries(r.Context(), actorInfo.ActorID, mux.Vars(r)["batchId"], limit)
+	if err != nil {
+		writeBatchError(w, err)
+		return
+	}
+	if out == nil {
+		out = []*model.MemoryEntry{}
+	}
+	respond.WriteJSON(w, http.StatusOK, map[string]interface{}{"entries": out, "count": len(out)})
+}
+
+// RollbackBatch POST /v0/ingestion-batches/{batchId}/rollback
+// Deletes every entry written by the batch atomically and marks it rolled back.
+func (h *IngestionBatchHandler) RollbackBatch(w http.ResponseWriter, r *http.Request) {
+	apiKey, err := auth.ExtractAPIKey(r)
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+	actorInfo, err := h.authorizer.Authorize(r.Context(), apiKey, "memory.delete", "default")
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+
+	batchID := mux.Vars(r)["batchId"]
+	ids, err := h.svc.RollbackBatch(r.Context(), actorInfo.ActorID, batchID)
+	if err != nil {
+		if ids != nil {
+			// Storage rollback committed; the outbox will retry index deletes.
+			log.Warn().Err(err).Str("batchId", batchID).Msg("index delete after batch rollback failed")
+		} else {
+			writeBatchError(w, err)
+			return
+		}
+	}
+	if ids == nil {
+		ids = []string{}
+	}
+	respond.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"batchId":         batchID,
+		"status":          model.IngestionBatchRolledBack,
+		"deletedEntryIds": ids,
+		"deletedCount":    len(ids),
+	})
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/services"
+	"github.com/mycelian/mycelian-memory/server/internal/store"
+)
+
+// memBatches is an in-memory store.IngestionBatches.
+type memBatches struct {
+	batches map[string]*model.IngestionBatch
+	entries map[string][]string
+}
+
+func (m *memBatches) Create(_ context.Context, b *model.IngestionBatch) (*model.IngestionBatch, error) {
+	out := *b
+	if out.BatchID == "" {
+		out.BatchID = "b-1"
+	}
+	out.Status = model.IngestionBatchOpen
+	m.batches[out.BatchID] = &out
+	return &out, nil
+}
+func (m *memBatches) Get(_ context.Context, _ string, id string) (*model.IngestionBatch, error) {
+	if b, ok := m.batches[id]; ok {
+		return b, nil
+	}
+	return nil, model.ErrNotFound
+}
+func (m *memBatches) List(context.Context, string) ([]*model.IngestionBatch, error) { return nil, nil }
+func (m *memBatches) ListEntries(_ context.Context, _ string, id string, _ int) ([]*model.MemoryEntry, error) {
+	var out []*model.MemoryEntry
+	for _, e := range m.entries[id] {
+		out = append(out, &model.MemoryEntry{EntryID: e, IngestionBatchID: id})
+	}
+	return out, nil
+}
+func (m *memBatches) Rollback(_ context.Context, _ string, id string) ([]string, error) {
+	b, ok := m.batches[id]
+	if !ok {
+		return nil, model.ErrNotFound
+	}
+	if b.Status == model.IngestionBatchRolledBack {
+		return nil, model.ErrConflict
+	}
+	b.Status = model.IngestionBatchRolledBack
+	ids := m.entries[id]
+	delete(m.entries, id)
+	return ids, nil
+}
+
+// batchOnlyStore satisfies store.Store; only IngestionBatches is used by these tests.
+type batchOnlyStore struct {
+	store.Store
+	b store.IngestionBatches
+}
+
+func (s batchOnlyStore) IngestionBatches() store.IngestionBatches { return s.b }
+
+func TestIngestionBatchHandler_Lifecycle(t *testing.T) {
+	mb := &memBatches{batches: map[string]*model.IngestionBatch{}, entries: map[string][]string{}}
+	h := NewIngestionBatchHandler(services.NewIngestionBatchService(batchOnlyStore{b: mb}, nil), &mockAuthorizer{})
+	r := mux.NewRouter()
+	r.HandleFunc("/v0/ingestion-batches", h.CreateBatch).Methods("POST")
+	r.HandleFunc("/v0/ingestion-batches/{batchId}", h.GetBatch).Methods("GET")
+	r.HandleFunc("/v0/ingestion-batches/{batchId}/entries", h.ListBatchEntries).Methods("GET")
+	r.HandleFunc("/v0/ingestion-batches/{batchId}/rollback", h.RollbackBatch).Methods("POST")
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer test-api-key")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := do("POST", "/v0/ingestion-batches", `{}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("create without sourceSystem: expected 400, got %d", w.Code)
+	}
+	if w := do("POST", "/v0/ingestion-batches", `{"batchId":"b-1","sourceSystem":"mem0"}`); w.Code != http.StatusCreated {
+		t.Fatalf("create: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	mb.entries["b-1"] = []string{"e1", "e2"}
+
+	if w := do("GET", "/v0/ingestion-batches/b-1/entries?limit=0", ""); w.Code != http.StatusBadRequest {
+		t.Fatalf("bad limit: expected 400, got %d", w.Code)
+	}
+	w := do("GET", "/v0/ingestion-batches/b-1/entries", "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"count":2`) {
+		t.Fatalf("list entries: %d %s", w.Code, w.Body.String())
+	}
+
+	w = do("POST", "/v0/ingestion-batches/b-1/rollback", "")
+	var resp struct {
+		Status          string   `json:"status"`
+		DeletedEntryIDs []string `json:"deletedEntryIds"`
+	}
+	_ = json.NewDecoder(w.Body).Decode(&resp)
+	if w.Code != http.StatusOK || resp.Status != model.IngestionBatchRolledBack || len(resp.DeletedEntryIDs) != 2 {
+		t.Fatalf("rollback: %d %+v", w.Code, resp)
+	}
+	if w := do("POST", "/v0/ingestion-batches/b-1/rollback", ""); w.Code != http.StatusConflict {
+		t.Fatalf("second rollback: expected 409, got %d", w.Code)
+	}
+	if w := do("GET", "/v0/ingestion-batches/missing", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("get missing: expected 404, got %d", w.Code)
+	}
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"unicode/utf8"
+
+	respond "github.com/mycelian/mycelian-memory/server/internal/api/respond"
+	"github.com/mycelian/mycelian-memory/server/internal/auth"
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+const maxClientTokenLen = 128
+
+// Bootstrap POST /v0/bootstrap
+// Body: {"clientToken": "...", "vault": {"title", "description"},
+// "memory": {"memoryType", "title", "description"}, "context": "..."}.
+// Creates the vault, its memory and the memory's first context in one
+// transaction, so agents starting up never see a partial setup. Responds 201
+// with {"vault", "memory", "contextId", "replayed": false}; repeating a
+// request with the same clientToken responds 200 with the first result and
+// "replayed": true, while reusing the token for a different request is 409.
+func (h *MemoryHandler) Bootstrap(w http.ResponseWriter, r *http.Request) {
+	apiKey, err := auth.ExtractAPIKey(r)
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+
+	actorInfo, err := h.authorizer.Authorize(r.Context(), apiKey, "vault.create", "default")
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+
+	var req struct {
+		ClientToken string `json:"clientToken,omitempty"`
+		Vault       struct {
+			Title       string  `json:"title"`
+			Description *string `json:"description,omitempty"`
+		} `json:"vault"`
+		Memory struct {
+			MemoryType  string  `json:"memoryType"`
+			Title       string  `json:"title"`
+			Description *string `json:"description,omitempty"`
+		} `json:"memory"`
+		Context string `json:"context,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respond.WriteBadRequest(w, "Invalid JSON")
+		return
+	}
+	if err := MaxLen("clientToken", &req.ClientToken, maxClientTokenLen); err != nil {
+		respond.WriteBadRequest(w, err.Error())
+		return
+	}
+	if err := Title(req.Vault.Title); err != nil {
+		respond.WriteBadRequest(w, "vault: "+err.Error())
+		return
+	}
+	if err := MaxLen("description", req.Vault.Description, 500); err != nil {
+		respond.WriteBadRequest(w, "vault: "+err.Error())
+		return
+	}
+	if err := CreateMemory(req.Memory.MemoryType, req.Memory.Title, req.Memory.Description); err != nil {
+		respond.WriteBadRequest(w, "memory: "+err.Error())
+		return
+	}
+	if err := validateContextText(req.Context); err != nil {
+		respond.WriteBadRequest(w, err.Error())
+		return
+	}
+	if h.cfg != nil && h.cfg.MaxContextChars > 0 && utf8.RuneCountInString(req.Context) > h.cfg.MaxContextChars {
+		respond.WriteError(w, http.StatusRequestEntityTooLarge, "context exceeds maximum size")
+		return
+	}
+
+	out, err := h.svc.Bootstrap(r.Context(), model.Bootstrap{
+		ActorID:     actorInfo.ActorID,
+		ClientToken: req.ClientToken,
+		Vault:       model.Vault{Title: req.Vault.Title, Description: req.Vault.Description},
+		Memory:      model.Memory{MemoryType: req.Memory.MemoryType, Title: req.Memory.Title, Description: req.Memory.Description},
+		Context:     req.Context,
+	})
+	switch {
+	case err == nil && out.Replayed:
+		respond.WriteJSON(w, http.StatusOK, out)
+	case err == nil:
+		respond.WriteJSON(w, http.StatusCreated, out)
+	case errors.Is(err, model.ErrValidation):
+		respond.WriteBadRequest(w, err.Error())
+	case errors.Is(err, model.ErrConflict):
+		respond.WriteError(w, http.StatusConflict, err.Error())
+	default:
+		respond.WriteInternalError(w, err.Error())
+	}
+}
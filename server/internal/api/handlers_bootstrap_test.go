@@ -0,0 +1,102 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mycelian/mycelian-memory/server/internal/config"
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/services"
+	"github.com/mycelian/mycelian-memory/server/internal/store"
+)
+
+// bootstrapVaults replays results by client token like the Postgres store.
+type bootstrapVaults struct {
+	store.Vaults
+	got     []model.Bootstrap
+	byToken map[string]model.Bootstrap
+}
+
+func (v *bootstrapVaults) Bootstrap(_ context.Context, b model.Bootstrap) (*model.BootstrapResult, error) {
+	v.got = append(v.got, b)
+	out := &model.BootstrapResult{
+		Vault:     &model.Vault{ActorID: b.ActorID, VaultID: "v1", Title: b.Vault.Title},
+		Memory:    &model.Memory{ActorID: b.ActorID, VaultID: "v1", MemoryID: "m1", MemoryType: b.Memory.MemoryType, Title: b.Memory.Title},
+		ContextID: "c1",
+	}
+	if b.ClientToken == "" {
+		return out, nil
+	}
+	if first, ok := v.byToken[b.ClientToken]; ok {
+		if first.Vault.Title != b.Vault.Title || first.Memory.Title != b.Memory.Title || first.Context != b.Context {
+			return nil, fmt.Errorf("%w: client token reused", model.ErrConflict)
+		}
+		out.Replayed = true
+		return out, nil
+	}
+	v.byToken[b.ClientToken] = b
+	return out, nil
+}
+
+type bootstrapStore struct {
+	store.Store
+	v *bootstrapVaults
+}
+
+func (s bootstrapStore) Vaults() store.Vaults { return s.v }
+
+func TestBootstrap(t *testing.T) {
+	vs := &bootstrapVaults{byToken: map[string]model.Bootstrap{}}
+	st := bootstrapStore{v: vs}
+	h := NewMemoryHandler(services.NewMemoryService(st, nil, nil), services.NewVaultService(st, nil), &mockAuthorizer{}, &config.Config{MaxContextChars: 20})
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v0/bootstrap", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer test-api-key")
+		w := httptest.NewRecorder()
+		h.Bootstrap(w, req)
+		return w
+	}
+	const body = `{"clientToken":"agent-1","vault":{"title":"My Project"},"memory":{"memoryType":"conversation","title":"chat"},"context":"goals: ship"}`
+
+	w := post(body)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("bootstrap: %d %s", w.Code, w.Body.String())
+	}
+	var res model.BootstrapResult
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil || res.Vault.VaultID != "v1" || res.Memory.MemoryID != "m1" || res.ContextID != "c1" || res.Replayed {
+		t.Fatalf("response: %s (%v)", w.Body.String(), err)
+	}
+	if b := vs.got[0]; b.ActorID == "" || b.ClientToken != "agent-1" || b.Context != "goals: ship" || b.Memory.MemoryType != "conversation" {
+		t.Fatalf("unexpected bootstrap: %+v", b)
+	}
+
+	if w := post(body); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"replayed":true`) {
+		t.Fatalf("retry: expected 200 replay, got %d %s", w.Code, w.Body.String())
+	}
+	if w := post(strings.Replace(body, "goals: ship", "other", 1)); w.Code != http.StatusConflict {
+		t.Fatalf("reused token: expected 409, got %d", w.Code)
+	}
+
+	vs.got = nil
+	for name, bad := range map[string]string{
+		"vault title":  `{"vault":{"title":"a/b"},"memory":{"memoryType":"conversation","title":"chat"}}`,
+		"memory type":  `{"vault":{"title":"p"},"memory":{"title":"chat"}}`,
+		"long token":   `{"clientToken":"` + strings.Repeat("t", maxClientTokenLen+1) + `","vault":{"title":"p"},"memory":{"memoryType":"conversation","title":"chat"}}`,
+		"control char": `{"vault":{"title":"p"},"memory":{"memoryType":"conversation","title":"chat"},"context":"a\u0000b"}`,
+	} {
+		if w := post(bad); w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d %s", name, w.Code, w.Body.String())
+		}
+	}
+	if w := post(`{"vault":{"title":"p"},"memory":{"memoryType":"conversation","title":"chat"},"context":"` + strings.Repeat("x", 21) + `"}`); w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("large context: expected 413, got %d", w.Code)
+	}
+	if len(vs.got) != 0 {
+		t.Fatalf("invalid requests reached the store: %+v", vs.got)
+	}
+}
@@ -0,0 +1,178 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	respond "github.com/mycelian/mycelian-memory/server/internal/api/respond"
+	"github.com/mycelian/mycelian-memory/server/internal/auth"
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/services"
+)
+
+// Contexts longer than MaxContextChars cannot be the active context. Clients
+// upload the full text as a context document instead — gzip-compressed, in
+// numbered parts of at most the part limit — and put an abridged active
+// context that refers to it.
+
+const (
+	defaultContextDocumentMaxBytes  = 16 << 20
+	defaultContextDocumentPartBytes = 1 << 20
+)
+
+// contextDocumentLimits is reported when a document is created so clients can
+// size their uploads without knowing the server configuration.
+type contextDocumentLimits struct {
+	MaxContextChars  int   `json:"maxContextChars"`
+	MaxPartBytes     int64 `json:"maxPartBytes"`
+	MaxDocumentBytes int64 `json:"maxDocumentBytes"`
+}
+
+func (h *MemoryHandler) contextDocumentLimits() contextDocumentLimits {
+	l := contextDocumentLimits{MaxPartBytes: defaultContextDocumentPartBytes, MaxDocumentBytes: defaultContextDocumentMaxBytes}
+	if h.cfg != nil {
+		l.MaxContextChars = h.cfg.MaxContextChars
+		if h.cfg.ContextDocumentPartBytes > 0 {
+			l.MaxPartBytes = h.cfg.ContextDocumentPartBytes
+		}
+		if h.cfg.ContextDocumentMaxBytes > 0 {
+			l.MaxDocumentBytes = h.cfg.ContextDocumentMaxBytes
+		}
+	}
+	return l
+}
+
+// writeContextDocumentError maps service errors to HTTP responses.
+func writeContextDocumentError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, model.ErrNotFound):
+		respond.WriteNotFound(w, "context document not found")
+	case errors.Is(err, model.ErrValidation):
+		respond.WriteBadRequest(w, err.Error())
+	case errors.Is(err, services.ErrContextDocumentTooLarge):
+		respond.WriteError(w, http.StatusRequestEntityTooLarge, err.Error())
+	case errors.Is(err, model.ErrConflict), errors.Is(err, model.ErrReadOnly):
+		respond.WriteError(w, http.StatusConflict, err.Error())
+	default:
+		respond.WriteInternalError(w, err.Error())
+	}
+}
+
+// authorizedMemory authorizes the request and checks that the actor owns
+// the vault and memory in the path. It writes the error response and returns
+// ok=false on failure.
+func (h *MemoryHandler) authorizedMemory(w http.ResponseWriter, r *http.Request, scope string) (actorID, vaultID, memoryID string, ok bool) {
+	apiKey, err := auth.ExtractAPIKey(r)
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return "", "", "", false
+	}
+	actorInfo, err := h.authorizer.Authorize(r.Context(), apiKey, scope, "default")
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return "", "", "", false
+	}
+	v := mux.Vars(r)
+	vaultID, memoryID = v["vaultId"], v["memoryId"]
+
+	// SECURITY: Validate vault exists and actor owns it
+	if h.vaultSv != nil {
+		if _, err := h.vaultSv.GetVault(r.Context(), actorInfo.ActorID, vaultID); err != nil {
+			respond.WriteNotFound(w, "vault not found")
+			return "", "", "", false
+		}
+	}
+	// SECURITY: Validate memory exists in the vault and actor owns it
+	if _, err := h.svc.GetMemory(r.Context(), actorInfo.ActorID, vaultID, memoryID); err != nil {
+		respond.WriteNotFound(w, "memory not found")
+		return "", "", "", false
+	}
+	return actorInfo.ActorID, vaultID, memoryID, true
+}
+
+// CreateContextDocument POST /v0/vaults/{vaultId}/memories/{memoryId}/contexts/documents
+func (h *MemoryHandler) CreateContextDocument(w http.ResponseWriter, r *http.Request) {
+	actorID, vaultID, memoryID, ok := h.authorizedMemory(w, r, "memory.write")
+	if !ok {
+		return
+	}
+	doc, err := h.svc.CreateContextDocument(r.Context(), actorID, vaultID, memoryID)
+	if err != nil {
+		writeContextDocumentError(w, err)
+		return
+	}
+	respond.WriteJSON(w, http.StatusCreated, struct {
+		*model.ContextDocument
+		Limits contextDocumentLimits `json:"limits"`
+	}{doc, h.contextDocumentLimits()})
+}
+
+// PutContextDocumentPart PUT /v0/vaults/{vaultId}/memories/{memoryId}/contexts/documents/{documentId}/parts/{part}
+func (h *MemoryHandler) PutContextDocumentPart(w http.ResponseWriter, r *http.Request) {
+	actorID, vaultID, memoryID, ok := h.authorizedMemory(w, r, "memory.write")
+	if !ok {
+		return
+	}
+	limits := h.contextDocumentLimits()
+	// A compressed document rarely outgrows its text, so the part count is
+	// bounded by the document limit.
+	maxPart := int(limits.MaxDocumentBytes / limits.MaxPartBytes)
+	n, err := strconv.Atoi(mux.Vars(r)["part"])
+	if err != nil || n < 0 || n > maxPart {
+		respond.WriteBadRequest(w, "part must be an integer between 0 and "+strconv.Itoa(maxPart))
+		return
+	}
+	data, err := io.ReadAll(io.LimitReader(r.Body, limits.MaxPartBytes+1))
+	if err != nil {
+		respond.WriteBadRequest(w, "unable to read body")
+		return
+	}
+	if int64(len(data)) > limits.MaxPartBytes {
+		respond.WriteError(w, http.StatusRequestEntityTooLarge, "part exceeds "+strconv.FormatInt(limits.MaxPartBytes, 10)+" bytes")
+		return
+	}
+	if len(data) == 0 {
+		respond.WriteBadRequest(w, "part must not be empty")
+		return
+	}
+	if err := h.svc.PutContextDocumentPart(r.Context(), actorID, vaultID, memoryID, mux.Vars(r)["documentId"], n, data); err != nil {
+		writeContextDocumentError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CompleteContextDocument POST /v0/vaults/{vaultId}/memories/{memoryId}/contexts/documents/{documentId}/complete
+func (h *MemoryHandler) CompleteContextDocument(w http.ResponseWriter, r *http.Request) {
+	actorID, vaultID, memoryID, ok := h.authorizedMemory(w, r, "memory.write")
+	if !ok {
+		return
+	}
+	doc, err := h.svc.CompleteContextDocument(r.Context(), actorID, vaultID, memoryID, mux.Vars(r)["documentId"], h.contextDocumentLimits().MaxDocumentBytes)
+	if err != nil {
+		writeContextDocumentError(w, err)
+		return
+	}
+	respond.WriteJSON(w, http.StatusOK, doc)
+}
+
+// GetContextDocument GET /v0/vaults/{vaultId}/memories/{memoryId}/contexts/documents/{documentId}
+func (h *MemoryHandler) GetContextDocument(w http.ResponseWriter, r *http.Request) {
+	actorID, vaultID, memoryID, ok := h.authorizedMemory(w, r, "memory.read")
+	if !ok {
+		return
+	}
+	doc, text, err := h.svc.ReadContextDocument(r.Context(), actorID, vaultID, memoryID, mux.Vars(r)["documentId"])
+	if err != nil {
+		writeContextDocumentError(w, err)
+		return
+	}
+	w.Header().Set("ETag", `"`+doc.SHA256+`"`)
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(text)
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mycelian/mycelian-memory/server/internal/config"
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/services"
+	"github.com/mycelian/mycelian-memory/server/internal/store"
+)
+
+type memContextDocuments struct {
+	doc   *model.ContextDocument
+	parts map[int][]byte
+}
+
+func (d *memContextDocuments) Create(_ context.Context, in *model.ContextDocument) (*model.ContextDocument, error) {
+	d.doc = &model.ContextDocument{DocumentID: "d1", ActorID: in.ActorID, VaultID: in.VaultID, MemoryID: in.MemoryID, Status: model.ContextDocumentUploading}
+	d.parts = map[int][]byte{}
+	return d.doc, nil
+}
+func (d *memContextDocuments) Get(_ context.Context, _, _, _, documentID string) (*model.ContextDocument, error) {
+	if d.doc == nil || d.doc.DocumentID != documentID {
+		return nil, model.ErrNotFound
+	}
+	return d.doc, nil
+}
+func (d *memContextDocuments) PutPart(_ context.Context, _, _ string, n int, data []byte) error {
+	d.parts[n] = data
+	return nil
+}
+func (d *memContextDocuments) Parts(context.Context, string, string) ([]model.ContextDocumentPart, error) {
+	var out []model.ContextDocumentPart
+	for n, data := range d.parts {
+		out = append(out, model.ContextDocumentPart{Number: n, Data: data})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Number < out[j].Number })
+	return out, nil
+}
+func (d *memContextDocuments) Complete(_ context.Context, _, _ string, size int64, sum string) (*model.ContextDocument, error) {
+	d.doc.Status, d.doc.SizeBytes, d.doc.SHA256 = model.ContextDocumentComplete, size, sum
+	return d.doc, nil
+}
+
+type documentHandlerStore struct {
+	contextStore
+	d *memContextDocuments
+}
+
+func (s documentHandlerStore) ContextDocuments() store.ContextDocuments { return s.d }
+
+func TestContextDocumentUpload(t *testing.T) {
+	st := documentHandlerStore{d: &memContextDocuments{}}
+	cfg := &config.Config{MaxContextChars: 100, ContextDocumentPartBytes: 64, ContextDocumentMaxBytes: 16384}
+	h := NewMemoryHandler(services.NewMemoryService(st, nil, nil), services.NewVaultService(st, nil), &mockAuthorizer{}, cfg)
+	r := mux.NewRouter()
+	r.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/contexts/documents", h.CreateContextDocument).Methods("POST")
+	r.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/contexts/documents/{documentId}", h.GetContextDocument).Methods("GET")
+	r.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/contexts/documents/{documentId}/parts/{part}", h.PutContextDocumentPart).Methods("PUT")
+	r.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/contexts/documents/{documentId}/complete", h.CompleteContextDocument).Methods("POST")
+
+	do := func(method, path string, body []byte) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/v0/vaults/v1/memories/m1/contexts/documents"+path, bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer test-api-key")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do(http.MethodPost, "", nil)
+	var created struct {
+		DocumentID string                `json:"documentId"`
+		Limits     contextDocumentLimits `json:"limits"`
+	}
+	if w.Code != http.StatusCreated || json.Unmarshal(w.Body.Bytes(), &created) != nil || created.DocumentID != "d1" {
+		t.Fatalf("create: %d %s", w.Code, w.Body.String())
+	}
+	if created.Limits != (contextDocumentLimits{MaxContextChars: 100, MaxPartBytes: 64, MaxDocumentBytes: 16384}) {
+		t.Fatalf("unexpected limits: %+v", created.Limits)
+	}
+
+	var sb strings.Builder
+	for i := 0; i < 200; i++ {
+		fmt.Fprintf(&sb, "line %d: %d\n", i, i*i*7919)
+	}
+	text := sb.String()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, _ = zw.Write([]byte(text))
+	_ = zw.Close()
+	data := buf.Bytes()
+
+	if w := do(http.MethodPut, "/d1/parts/0", data); w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("oversized part: expected 413, got %d", w.Code)
+	}
+	if w := do(http.MethodPut, "/d1/parts/257", data[:1]); w.Code != http.StatusBadRequest {
+		t.Fatalf("part number out of range: expected 400, got %d", w.Code)
+	}
+	if w := do(http.MethodPut, "/nope/parts/0", data[:1]); w.Code != http.StatusNotFound {
+		t.Fatalf("unknown document: expected 404, got %d", w.Code)
+	}
+	for i := 0; i*64 < len(data); i++ {
+		part := data[i*64 : min((i+1)*64, len(data))]
+		if w := do(http.MethodPut, "/d1/parts/"+strconv.Itoa(i), part); w.Code != http.StatusNoContent {
+			t.Fatalf("part %d: %d %s", i, w.Code, w.Body.String())
+		}
+	}
+	if w := do(http.MethodGet, "/d1", nil); w.Code != http.StatusConflict {
+		t.Fatalf("read before complete: expected 409, got %d", w.Code)
+	}
+	if w := do(http.MethodPost, "/d1/complete", nil); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"status":"complete"`) {
+		t.Fatalf("complete: %d %s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodGet, "/d1", nil); w.Code != http.StatusOK || w.Body.String() != text {
+		t.Fatalf("get: %d len=%d", w.Code, w.Body.Len())
+	}
+}
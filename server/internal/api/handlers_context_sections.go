@@ -0,0 +1,146 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	respond "github.com/mycelian/mycelian-memory/server/internal/api/respond"
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/services"
+)
+
+// A context can be written as named sections instead of one text: a JSON
+// PUT .../contexts replaces all sections, and PUT .../contexts/sections/{name}
+// rewrites one, so an agent updating its task list cannot clobber the facts
+// another agent maintains. GET .../contexts?format=structured returns the
+// sections with who last updated each and when.
+
+// maxContextSectionsBody bounds the JSON body of a sections put.
+const maxContextSectionsBody = 4 << 20
+
+// contextSectionsRequest is the JSON body of PUT .../contexts.
+type contextSectionsRequest struct {
+	Sections []struct {
+		Name    string `json:"name"`
+		Content string `json:"content"`
+	} `json:"sections"`
+}
+
+// structuredContext is the GET .../contexts?format=structured response.
+type structuredContext struct {
+	ContextID    string                 `json:"contextId"`
+	Context      string                 `json:"context"`
+	Sections     []model.ContextSection `json:"sections"`
+	CreationTime time.Time              `json:"creationTime"`
+	// RecentEntries is set with ?includeRecentSummaries=N, newest first.
+	RecentEntries []model.EntrySummary `json:"recentEntries,omitempty"`
+}
+
+func writeStructuredContext(w http.ResponseWriter, mc *model.MemoryContext, recent []model.EntrySummary) {
+	sections := mc.Sections
+	if sections == nil {
+		sections = []model.ContextSection{}
+	}
+	respond.WriteJSON(w, http.StatusOK, structuredContext{
+		ContextID: mc.ContextID, Context: mc.Context, Sections: sections, CreationTime: mc.CreationTime,
+		RecentEntries: recent,
+	})
+}
+
+// putContextSections handles a JSON PUT .../contexts for PutMemoryContext,
+// which has authorized the request.
+func (h *MemoryHandler) putContextSections(w http.ResponseWriter, r *http.Request, actorID, vaultID, memoryID string) {
+	var req contextSectionsRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxContextSectionsBody)).Decode(&req); err != nil {
+		respond.WriteBadRequest(w, "Invalid JSON")
+		return
+	}
+	u := h.contextSectionsUpdate(r, actorID, vaultID, memoryID)
+	for _, s := range req.Sections {
+		if err := validateContextText(s.Content); err != nil {
+			respond.WriteBadRequest(w, "section "+s.Name+": "+err.Error())
+			return
+		}
+		u.Sections = append(u.Sections, model.ContextSection{Name: s.Name, Content: s.Content})
+	}
+	out, err := h.svc.PutContextSections(r.Context(), u)
+	if err != nil {
+		writeContextSectionsError(w, err)
+		return
+	}
+	respond.WriteJSON(w, http.StatusCreated, out)
+}
+
+// PutMemoryContextSection PUT /v0/vaults/{vaultId}/memories/{memoryId}/contexts/sections/{name}
+func (h *MemoryHandler) PutMemoryContextSection(w http.ResponseWriter, r *http.Request) {
+	actorID, vaultID, memoryID, ok := h.authorizedMemory(w, r, "memory.write")
+	if !ok {
+		return
+	}
+	if ct := r.Header.Get("Content-Type"); ct != "" && ct != "text/plain" && ct != "text/plain; charset=utf-8" {
+		respond.WriteError(w, http.StatusUnsupportedMediaType, "Content-Type must be text/plain")
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxContextSectionsBody))
+	if err != nil {
+		respond.WriteBadRequest(w, "unable to read body")
+		return
+	}
+	if err := validateContextText(string(body)); err != nil {
+		respond.WriteBadRequest(w, err.Error())
+		return
+	}
+	u := h.contextSectionsUpdate(r, actorID, vaultID, memoryID)
+	u.Sections = []model.ContextSection{{Name: mux.Vars(r)["name"], Content: string(body)}}
+	out, err := h.svc.PutContextSection(r.Context(), u)
+	if err != nil {
+		writeContextSectionsError(w, err)
+		return
+	}
+	respond.WriteJSON(w, http.StatusCreated, out)
+}
+
+// contextSectionsUpdate reads the fields shared by both sections puts: the
+// X-Updated-By header naming the writer (the actor by default) and an
+// If-Match header holding the quoted contextId the update was based on.
+func (h *MemoryHandler) contextSectionsUpdate(r *http.Request, actorID, vaultID, memoryID string) services.ContextSectionsUpdate {
+	u := services.ContextSectionsUpdate{ActorID: actorID, VaultID: vaultID, MemoryID: memoryID, UpdatedBy: actorID}
+	if by := strings.TrimSpace(r.Header.Get("X-Updated-By")); by != "" {
+		u.UpdatedBy = by
+	}
+	if m := strings.TrimSpace(r.Header.Get("If-Match")); m != "" && m != "*" {
+		u.IfMatch = strings.Trim(strings.TrimPrefix(m, "W/"), `"`)
+	}
+	if h.cfg != nil {
+		u.MaxChars = h.cfg.MaxContextChars
+	}
+	return u
+}
+
+// writeContextSectionsError maps service errors to HTTP responses.
+func writeContextSectionsError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, model.ErrValidation):
+		respond.WriteBadRequest(w, err.Error())
+	case errors.Is(err, model.ErrPrecondition):
+		respond.WriteError(w, http.StatusPreconditionFailed, err.Error())
+	case errors.Is(err, model.ErrConflict):
+		respond.WriteError(w, http.StatusConflict, err.Error())
+	case writeReadOnlyError(w, err):
+	default:
+		respond.WriteInternalError(w, err.Error())
+	}
+}
+
+// isJSONContentType reports whether a Content-Type header names JSON.
+func isJSONContentType(ct string) bool {
+	mt, _, err := mime.ParseMediaType(ct)
+	return err == nil && mt == "application/json"
+}
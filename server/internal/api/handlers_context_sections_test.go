@@ -0,0 +1,90 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/services"
+)
+
+func (c *memContexts) Put(_ context.Context, mc *model.MemoryContext) (*model.MemoryContext, error) {
+	out := *mc
+	out.ContextID = fmt.Sprintf("c%d", len(out.Sections))
+	c.latest = &out
+	return &out, nil
+}
+
+func (c *memContexts) PutIfLatest(ctx context.Context, mc *model.MemoryContext, latestID string) (*model.MemoryContext, error) {
+	current := ""
+	if c.latest != nil {
+		current = c.latest.ContextID
+	}
+	if current != latestID {
+		return nil, model.ErrPrecondition
+	}
+	return c.Put(ctx, mc)
+}
+
+func TestContextSections(t *testing.T) {
+	st := contextStore{c: &memContexts{}}
+	h := NewMemoryHandler(services.NewMemoryService(st, nil, nil), services.NewVaultService(st, nil), &mockAuthorizer{}, nil)
+	r := mux.NewRouter()
+	r.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/contexts", h.PutMemoryContext).Methods("PUT")
+	r.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/contexts", h.GetLatestMemoryContext).Methods("GET")
+	r.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/contexts/sections/{name}", h.PutMemoryContextSection).Methods("PUT")
+	do := func(method, path, contentType, body string, header ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/v0/vaults/v1/memories/m1/contexts"+path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer test-api-key")
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("PUT", "", "application/json", `{"sections":[{"name":"Facts","content":"CEO = Bob"}]}`, "X-Updated-By", "planner")
+	if w.Code != http.StatusCreated {
+		t.Fatalf("put sections: %d %s", w.Code, w.Body.String())
+	}
+	w = do("PUT", "/sections/Tasks", "text/plain", "ship it", "If-Match", `"c1"`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("put section: %d %s", w.Code, w.Body.String())
+	}
+	if w := do("PUT", "/sections/Tasks", "text/plain", "again", "If-Match", `"c1"`); w.Code != http.StatusPreconditionFailed {
+		t.Fatalf("stale If-Match: expected 412, got %d", w.Code)
+	}
+
+	w = do("GET", "?format=structured", "", "")
+	var got structuredContext
+	if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &got) != nil {
+		t.Fatalf("get structured: %d %s", w.Code, w.Body.String())
+	}
+	if got.ContextID != "c2" || len(got.Sections) != 2 || got.Sections[0].LastUpdatedBy != "planner" || got.Sections[1].LastUpdatedBy != "test-user" {
+		t.Fatalf("unexpected structured context: %+v", got)
+	}
+	if w := do("GET", "", "", ""); w.Body.String() != "## Facts\n\nCEO = Bob\n\n## Tasks\n\nship it" {
+		t.Fatalf("text format: %q", w.Body.String())
+	}
+	if w := do("GET", "?format=yaml", "", ""); w.Code != http.StatusBadRequest {
+		t.Fatalf("bad format: expected 400, got %d", w.Code)
+	}
+
+	if w := do("PUT", "", "text/plain", "free text"); w.Code != http.StatusCreated {
+		t.Fatalf("put text: %d %s", w.Code, w.Body.String())
+	}
+	if w := do("PUT", "/sections/Tasks", "text/plain", "x"); w.Code != http.StatusConflict {
+		t.Fatalf("section over unstructured context: expected 409, got %d", w.Code)
+	}
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	respond "github.com/mycelian/mycelian-memory/server/internal/api/respond"
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/services"
+)
+
+// EnableConversations serves POST .../conversations with svc.
+func (h *MemoryHandler) EnableConversations(svc *services.ConversationService) { h.conversations = svc }
+
+// IngestConversation POST /v0/vaults/{vaultId}/memories/{memoryId}/conversations
+// Body: {"messages": [{"role", "content", "timestamp"}], "windowSize", "sessionId", "tags", "sourceSystem", "ingestionBatchId"}
+// Stores the transcript as summarized entries, windowSize messages per entry,
+// all under one session. Responds 201 with the entries in transcript order.
+func (h *MemoryHandler) IngestConversation(w http.ResponseWriter, r *http.Request) {
+	actorID, vaultID, memoryID, ok := h.authorizedMemory(w, r, "memory.create")
+	if !ok {
+		return
+	}
+	if h.conversations == nil {
+		respond.WriteNotFound(w, "conversation ingestion is not enabled")
+		return
+	}
+	var in struct {
+		Messages         []model.ConversationMessage `json:"messages"`
+		WindowSize       int                         `json:"windowSize,omitempty"`
+		SessionID        string                      `json:"sessionId,omitempty"`
+		Tags             map[string]interface{}      `json:"tags,omitempty"`
+		SourceSystem     string                      `json:"sourceSystem,omitempty"`
+		IngestionBatchID string                      `json:"ingestionBatchId,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		respond.WriteBadRequest(w, "Invalid JSON")
+		return
+	}
+	if err := EntryProvenance(in.SourceSystem, "", in.IngestionBatchID); err != nil {
+		respond.WriteBadRequest(w, err.Error())
+		return
+	}
+	if err := MaxLen("sessionId", &in.SessionID, maxProvenanceLen); err != nil {
+		respond.WriteBadRequest(w, err.Error())
+		return
+	}
+	out, err := h.conversations.Ingest(r.Context(), services.IngestConversationRequest{
+		ActorID: actorID, VaultID: vaultID, MemoryID: memoryID,
+		SessionID: in.SessionID, Messages: in.Messages, WindowSize: in.WindowSize, Tags: in.Tags,
+		SourceSystem: in.SourceSystem, IngestionBatchID: in.IngestionBatchID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrValidation):
+			respond.WriteBadRequest(w, err.Error())
+		case errors.Is(err, model.ErrReadOnly), errors.Is(err, model.ErrConflict):
+			respond.WriteError(w, http.StatusConflict, err.Error())
+		default:
+			respond.WriteInternalError(w, err.Error())
+		}
+		return
+	}
+	respond.WriteJSON(w, http.StatusCreated, out)
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/services"
+	"github.com/mycelian/mycelian-memory/server/internal/store"
+	"github.com/mycelian/mycelian-memory/server/internal/summarizer"
+)
+
+type memCreatedEntries struct {
+	store.Entries
+	created []*model.MemoryEntry
+}
+
+func (e *memCreatedEntries) Create(_ context.Context, me *model.MemoryEntry) (*model.MemoryEntry, error) {
+	out := *me
+	out.EntryID = fmt.Sprintf("e%d", len(e.created))
+	e.created = append(e.created, &out)
+	return &out, nil
+}
+
+type conversationStore struct {
+	contextStore
+	e *memCreatedEntries
+}
+
+func (s conversationStore) Entries() store.Entries { return s.e }
+
+func TestIngestConversation(t *testing.T) {
+	st := conversationStore{e: &memCreatedEntries{}}
+	h := NewMemoryHandler(services.NewMemoryService(st, nil, nil), services.NewVaultService(st, nil), &mockAuthorizer{}, nil)
+	r := mux.NewRouter()
+	r.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/conversations", h.IngestConversation).Methods("POST")
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v0/vaults/v1/memories/m1/conversations", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer test-api-key")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	body := `{"sessionId":"s1","messages":[
+		{"role":"user","content":"Book a table for Friday.","timestamp":"2026-03-01T09:00:00Z"},
+		{"role":"assistant","content":"Done, 7pm at Lume."}]}`
+	if w := post(body); w.Code != http.StatusNotFound {
+		t.Fatalf("disabled: expected 404, got %d", w.Code)
+	}
+
+	h.EnableConversations(services.NewConversationService(st, summarizer.Extractive{}))
+	w := post(body)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var out services.IngestConversationResult
+	if err := json.Unmarshal(w.Body.Bytes(), &out); err != nil || out.Count != 2 || out.SessionID != "s1" {
+		t.Fatalf("unexpected response: %s", w.Body.String())
+	}
+	if e := st.e.created[1]; e.RawEntry != "assistant: Done, 7pm at Lume." || e.SessionID != "s1" || e.VaultID != "v1" || e.MemoryID != "m1" {
+		t.Fatalf("unexpected entry: %+v", e)
+	}
+
+	for _, bad := range []string{`{`, `{"messages":[]}`, `{"messages":[{"role":"user","content":"x"}],"windowSize":-1}`} {
+		if w := post(bad); w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", bad, w.Code)
+		}
+	}
+}
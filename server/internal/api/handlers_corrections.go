@@ -0,0 +1,59 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	respond "github.com/mycelian/mycelian-memory/server/internal/api/respond"
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+// maxCorrectionReasonLen bounds the reason of a correction.
+const maxCorrectionReasonLen = 1024
+
+// CorrectMemoryEntry POST /v0/vaults/{vaultId}/memories/{memoryId}/entries/{entryId}/corrections
+// Writes the body, an entry plus an optional reason, as a new entry of the
+// memory that corrects entryId. Search returns the correction in place of
+// the corrected entry from then on. Responds 201 with the correction; 404
+// for an unknown entry, 409 for one corrected already.
+func (h *MemoryHandler) CorrectMemoryEntry(w http.ResponseWriter, r *http.Request) {
+	actorID, vaultID, memoryID, ok := h.authorizedMemory(w, r, "memory.create")
+	if !ok {
+		return
+	}
+
+	var in struct {
+		entryInput
+		Reason string `json:"reason,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		respond.WriteBadRequest(w, "Invalid JSON")
+		return
+	}
+	if err := in.validate(); err != nil {
+		respond.WriteBadRequest(w, err.Error())
+		return
+	}
+	if err := MaxLen("reason", &in.Reason, maxCorrectionReasonLen); err != nil {
+		respond.WriteBadRequest(w, err.Error())
+		return
+	}
+	out, err := h.svc.CorrectEntry(r.Context(), mux.Vars(r)["entryId"], in.entry(actorID, vaultID, memoryID), in.Reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrValidation):
+			respond.WriteBadRequest(w, err.Error())
+		case errors.Is(err, model.ErrNotFound):
+			respond.WriteNotFound(w, "entry not found")
+		case errors.Is(err, model.ErrReadOnly), errors.Is(err, model.ErrConflict):
+			respond.WriteError(w, http.StatusConflict, err.Error())
+		default:
+			respond.WriteInternalError(w, err.Error())
+		}
+		return
+	}
+	respond.WriteJSON(w, http.StatusCreated, out)
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	respond "github.com/mycelian/mycelian-memory/server/internal/api/respond"
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+// entryInput is the body of POST .../entries and one element of
+// POST .../entries:batch.
+type entryInput struct {
+	RawEntry       string                 `json:"rawEntry"`
+	Summary        *string                `json:"summary,omitempty"`
+	Metadata       map[string]interface{} `json:"metadata,omitempty"`
+	Tags           map[string]interface{} `json:"tags,omitempty"`
+	ExpirationTime *time.Time             `json:"expirationTime,omitempty"`
+	// Provenance (optional)
+	SourceSystem     string `json:"sourceSystem,omitempty"`
+	SourceID         string `json:"sourceId,omitempty"`
+	IngestionBatchID string `json:"ingestionBatchId,omitempty"`
+	// Conversation session the entry belongs to (optional)
+	SessionID string `json:"sessionId,omitempty"`
+	// Language model spend behind the entry (optional)
+	Usage *model.EntryUsage `json:"usage,omitempty"`
+	// When the conversation took place, for ingested history (optional)
+	ConversationTime *time.Time `json:"conversationTime,omitempty"`
+	// Client-chosen key making a retried create return the first entry (optional)
+	IdempotencyKey string `json:"idempotencyKey,omitempty"`
+}
+
+// maxIdempotencyKeyLen bounds idempotencyKey.
+const maxIdempotencyKeyLen = 128
+
+func (in *entryInput) validate() error {
+	if err := EntryProvenance(in.SourceSystem, in.SourceID, in.IngestionBatchID); err != nil {
+		return err
+	}
+	if err := MaxLen("sessionId", &in.SessionID, maxProvenanceLen); err != nil {
+		return err
+	}
+	return MaxLen("idempotencyKey", &in.IdempotencyKey, maxIdempotencyKeyLen)
+}
+
+func (in *entryInput) entry(actorID, vaultID, memoryID string) *model.MemoryEntry {
+	return &model.MemoryEntry{
+		ActorID: actorID, VaultID: vaultID, MemoryID: memoryID,
+		RawEntry: in.RawEntry, Summary: in.Summary, Metadata: in.Metadata, Tags: in.Tags, ExpirationTime: in.ExpirationTime,
+		SourceSystem: in.SourceSystem, SourceID: in.SourceID, IngestionBatchID: in.IngestionBatchID, SessionID: in.SessionID,
+		Usage: in.Usage, ConversationTime: in.ConversationTime, IdempotencyKey: in.IdempotencyKey,
+	}
+}
+
+// CreateMemoryEntriesBatch POST /v0/vaults/{vaultId}/memories/{memoryId}/entries:batch
+// Writes up to model.MaxEntriesBatch entries in one transaction, keeping
+// their order, for backfilling past conversations; each may carry its own
+// conversationTime. Either every entry is stored or none is. Responds 201
+// {"entries": [...], "count": n}.
+func (h *MemoryHandler) CreateMemoryEntriesBatch(w http.ResponseWriter, r *http.Request) {
+	actorID, vaultID, memoryID, ok := h.authorizedMemory(w, r, "memory.create")
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Entries []entryInput `json:"entries"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respond.WriteBadRequest(w, "Invalid JSON")
+		return
+	}
+	if len(req.Entries) == 0 || len(req.Entries) > model.MaxEntriesBatch {
+		respond.WriteBadRequest(w, fmt.Sprintf("entries must hold 1 to %d entries", model.MaxEntriesBatch))
+		return
+	}
+	entries := make([]*model.MemoryEntry, len(req.Entries))
+	for i := range req.Entries {
+		if err := req.Entries[i].validate(); err != nil {
+			respond.WriteBadRequest(w, fmt.Sprintf("entries[%d]: %v", i, err))
+			return
+		}
+		entries[i] = req.Entries[i].entry(actorID, vaultID, memoryID)
+	}
+
+	out, err := h.svc.CreateEntries(r.Context(), entries)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrValidation):
+			respond.WriteBadRequest(w, err.Error())
+		case errors.Is(err, model.ErrReadOnly), errors.Is(err, model.ErrConflict):
+			respond.WriteError(w, http.StatusConflict, err.Error())
+		default:
+			respond.WriteInternalError(w, err.Error())
+		}
+		return
+	}
+	respond.WriteJSON(w, http.StatusCreated, struct {
+		Entries []*model.MemoryEntry `json:"entries"`
+		Count   int                  `json:"count"`
+	}{out, len(out)})
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/services"
+	"github.com/mycelian/mycelian-memory/server/internal/store"
+)
+
+type batchEntries struct {
+	store.Entries
+	got []*model.MemoryEntry
+}
+
+func (e *batchEntries) CreateBatch(_ context.Context, entries []*model.MemoryEntry) ([]*model.MemoryEntry, error) {
+	e.got = entries
+	return entries, nil
+}
+
+type batchStore struct {
+	store.Store
+	e *batchEntries
+}
+
+func (batchStore) Vaults() store.Vaults {
+	return &memVaults{readOnly: map[string]bool{"v1": false, "ro": true}}
+}
+func (batchStore) Memories() store.Memories           { return memMemories{} }
+func (s batchStore) Entries() store.Entries           { return s.e }
+func (batchStore) EntityAliases() store.EntityAliases { return noAliases{} }
+
+func TestCreateMemoryEntriesBatch(t *testing.T) {
+	es := &batchEntries{}
+	st := batchStore{e: es}
+	h := NewMemoryHandler(services.NewMemoryService(st, nil, nil), services.NewVaultService(st, nil), &mockAuthorizer{}, nil)
+	r := mux.NewRouter()
+	r.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/entries:batch", h.CreateMemoryEntriesBatch).Methods("POST")
+	post := func(vaultID, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v0/vaults/"+vaultID+"/memories/m1/entries:batch", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer test-api-key")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post("v1", `{"entries":[
+		{"rawEntry":"first","conversationTime":"2025-03-01T09:00:00Z","sessionId":"s1","idempotencyKey":"k1"},
+		{"rawEntry":"second","conversationTime":"2025-03-01T09:01:00Z","sessionId":"s1"}]}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("batch: %d %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Entries []model.MemoryEntry `json:"entries"`
+		Count   int                 `json:"count"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.Count != 2 || len(resp.Entries) != 2 {
+		t.Fatalf("response: %s (%v)", w.Body.String(), err)
+	}
+	if len(es.got) != 2 || es.got[0].RawEntry != "first" || es.got[0].IdempotencyKey != "k1" || es.got[1].ConversationTime == nil || es.got[1].ActorID == "" || es.got[1].MemoryID != "m1" {
+		t.Fatalf("unexpected entries: %+v", es.got)
+	}
+
+	es.got = nil
+	if w := post("v1", `{"entries":[]}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("empty batch: expected 400, got %d", w.Code)
+	}
+	if w := post("v1", `{"entries":[{"rawEntry":"a"},{"rawEntry":"b","sourceId":"x"}]}`); w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "entries[1]") {
+		t.Fatalf("bad provenance: expected 400 naming entries[1], got %d %s", w.Code, w.Body.String())
+	}
+	if w := post("v1", `{"entries":[{"rawEntry":"a","idempotencyKey":"`+strings.Repeat("k", maxIdempotencyKeyLen+1)+`"}]}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("long idempotency key: expected 400, got %d", w.Code)
+	}
+	if w := post("ro", `{"entries":[{"rawEntry":"a"}]}`); w.Code != http.StatusConflict {
+		t.Fatalf("read-only vault: expected 409, got %d", w.Code)
+	}
+	if es.got != nil {
+		t.Fatalf("rejected batches must not reach the store: %+v", es.got)
+	}
+}
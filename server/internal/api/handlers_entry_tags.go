@@ -0,0 +1,84 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	respond "github.com/mycelian/mycelian-memory/server/internal/api/respond"
+	"github.com/mycelian/mycelian-memory/server/internal/auth"
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+// PatchEntryTagsRequest is the body of PATCH .../entries:tags. Filter selects
+// entries by ids, or else by session and creation time range; Set writes tag
+// keys and Unset removes them.
+type PatchEntryTagsRequest struct {
+	Filter struct {
+		IDs       []string   `json:"ids"`
+		SessionID string     `json:"sessionId"`
+		Since     *time.Time `json:"since"`
+		Until     *time.Time `json:"until"`
+	} `json:"filter"`
+	Set   map[string]interface{} `json:"set"`
+	Unset []string               `json:"unset"`
+}
+
+// PatchMemoryEntryTags PATCH /v0/vaults/{vaultId}/memories/{memoryId}/entries:tags
+// Applies one tag patch to every matching entry in a single transaction and
+// re-indexes them. Responds {"updated": n, "entryIds": [...]}.
+func (h *MemoryHandler) PatchMemoryEntryTags(w http.ResponseWriter, r *http.Request) {
+	apiKey, err := auth.ExtractAPIKey(r)
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+	actorInfo, err := h.authorizer.Authorize(r.Context(), apiKey, "memory.write", "default")
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+
+	v := mux.Vars(r)
+	vaultID := v["vaultId"]
+	memoryID := v["memoryId"]
+	if _, err := h.svc.GetMemory(r.Context(), actorInfo.ActorID, vaultID, memoryID); err != nil {
+		respond.WriteNotFound(w, "memory not found")
+		return
+	}
+
+	var req PatchEntryTagsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respond.WriteBadRequest(w, "Invalid JSON")
+		return
+	}
+	ids, err := h.svc.PatchEntryTags(r.Context(), model.EntryTagPatch{
+		ActorID:   actorInfo.ActorID,
+		VaultID:   vaultID,
+		MemoryID:  memoryID,
+		EntryIDs:  req.Filter.IDs,
+		SessionID: req.Filter.SessionID,
+		Since:     req.Filter.Since,
+		Until:     req.Filter.Until,
+		Set:       req.Set,
+		Unset:     req.Unset,
+	})
+	if err != nil {
+		if writeReadOnlyError(w, err) {
+			return
+		}
+		if errors.Is(err, model.ErrValidation) {
+			respond.WriteBadRequest(w, err.Error())
+			return
+		}
+		respond.WriteInternalError(w, err.Error())
+		return
+	}
+	if ids == nil {
+		ids = []string{}
+	}
+	respond.WriteJSON(w, http.StatusOK, map[string]interface{}{"updated": len(ids), "entryIds": ids})
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/services"
+	"github.com/mycelian/mycelian-memory/server/internal/store"
+)
+
+type tagPatchEntries struct {
+	store.Entries
+	got *model.EntryTagPatch
+}
+
+func (e *tagPatchEntries) PatchTags(_ context.Context, p model.EntryTagPatch) ([]string, error) {
+	e.got = &p
+	return []string{"e1", "e2"}, nil
+}
+
+type tagPatchStore struct {
+	store.Store
+	e *tagPatchEntries
+}
+
+func (tagPatchStore) Vaults() store.Vaults {
+	return &memVaults{readOnly: map[string]bool{"v1": false, "ro": true}}
+}
+func (tagPatchStore) Memories() store.Memories { return memMemories{} }
+func (s tagPatchStore) Entries() store.Entries { return s.e }
+
+func TestPatchMemoryEntryTags(t *testing.T) {
+	es := &tagPatchEntries{}
+	st := tagPatchStore{e: es}
+	h := NewMemoryHandler(services.NewMemoryService(st, nil, nil), services.NewVaultService(st, nil), &mockAuthorizer{}, nil)
+	r := mux.NewRouter()
+	r.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/entries:tags", h.PatchMemoryEntryTags).Methods("PATCH")
+	patch := func(vaultID, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPatch, "/v0/vaults/"+vaultID+"/memories/m1/entries:tags", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer test-api-key")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := patch("v1", `{"filter":{"sessionId":"s1","since":"2026-01-01T00:00:00Z"},"set":{"status":"resolved"},"unset":["todo"]}`)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"updated":2`) {
+		t.Fatalf("patch: %d %s", w.Code, w.Body.String())
+	}
+	if es.got == nil || es.got.SessionID != "s1" || es.got.Since == nil || es.got.Set["status"] != "resolved" || !reflect.DeepEqual(es.got.Unset, []string{"todo"}) {
+		t.Fatalf("unexpected patch: %+v", es.got)
+	}
+
+	es.got = nil
+	if w := patch("v1", `{"set":{"status":"resolved"}}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("patch without filter: expected 400, got %d", w.Code)
+	}
+	if w := patch("ro", `{"filter":{"ids":["e1"]},"unset":["todo"]}`); w.Code != http.StatusConflict {
+		t.Fatalf("patch in read-only vault: expected 409, got %d", w.Code)
+	}
+	if es.got != nil {
+		t.Fatalf("rejected patches must not reach the store: %+v", es.got)
+	}
+}
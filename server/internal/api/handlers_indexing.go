@@ -0,0 +1,80 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	respond "github.com/mycelian/mycelian-memory/server/internal/api/respond"
+	"github.com/mycelian/mycelian-memory/server/internal/auth"
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+// GetIndexStatus GET /v0/vaults/{vaultId}/memories/{memoryId}/index-status?id=...&id=...
+// Reports for each listed entry or context ID whether it is searchable yet.
+func (h *MemoryHandler) GetIndexStatus(w http.ResponseWriter, r *http.Request) {
+	apiKey, err := auth.ExtractAPIKey(r)
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+	actorInfo, err := h.authorizer.Authorize(r.Context(), apiKey, "memory.read", "default")
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+
+	v := mux.Vars(r)
+	out, err := h.svc.IndexStatus(r.Context(), actorInfo.ActorID, v["vaultId"], v["memoryId"], r.URL.Query()["id"])
+	if err != nil {
+		writeIndexingError(w, err)
+		return
+	}
+	respond.WriteJSON(w, http.StatusOK, map[string]interface{}{"statuses": out})
+}
+
+// RetryIndexing POST /v0/vaults/{vaultId}/memories/{memoryId}/index:retry
+// Re-enqueues the memory's entries and contexts whose indexing failed. The
+// optional body {"ids": [...]} limits the retry to those entries and contexts.
+func (h *MemoryHandler) RetryIndexing(w http.ResponseWriter, r *http.Request) {
+	apiKey, err := auth.ExtractAPIKey(r)
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+	actorInfo, err := h.authorizer.Authorize(r.Context(), apiKey, "memory.write", "default")
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+
+	var req struct {
+		IDs []string `json:"ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		respond.WriteBadRequest(w, "Invalid JSON")
+		return
+	}
+
+	v := mux.Vars(r)
+	n, err := h.svc.RetryIndexing(r.Context(), actorInfo.ActorID, v["vaultId"], v["memoryId"], req.IDs)
+	if err != nil {
+		writeIndexingError(w, err)
+		return
+	}
+	respond.WriteJSON(w, http.StatusOK, map[string]int{"requeued": n})
+}
+
+func writeIndexingError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, model.ErrValidation):
+		respond.WriteBadRequest(w, err.Error())
+	case errors.Is(err, model.ErrNotFound):
+		respond.WriteNotFound(w, "memory not found")
+	default:
+		respond.WriteInternalError(w, err.Error())
+	}
+}
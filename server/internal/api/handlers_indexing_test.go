@@ -0,0 +1,81 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/services"
+	"github.com/mycelian/mycelian-memory/server/internal/store"
+)
+
+type memIndexing struct {
+	retried []string
+}
+
+func (*memIndexing) Status(_ context.Context, _ string, ids []string) (map[string]model.IndexStatus, error) {
+	out := map[string]model.IndexStatus{}
+	for _, id := range ids {
+		out[id] = model.IndexStatus{State: model.IndexStateUnknown}
+	}
+	out["e1"] = model.IndexStatus{State: model.IndexStateFailed, Attempts: 5, LastError: "weaviate: 503"}
+	return out, nil
+}
+
+func (ix *memIndexing) Retry(_ context.Context, _, _, _ string, ids []string) (int, error) {
+	ix.retried = ids
+	return 2, nil
+}
+
+type indexingStore struct {
+	store.Store
+	ix *memIndexing
+}
+
+func (indexingStore) Memories() store.Memories   { return memMemories{} }
+func (s indexingStore) Indexing() store.Indexing { return s.ix }
+
+func TestIndexStatusAndRetry(t *testing.T) {
+	ix := &memIndexing{}
+	st := indexingStore{ix: ix}
+	h := NewMemoryHandler(services.NewMemoryService(st, nil, nil), services.NewVaultService(st, nil), &mockAuthorizer{}, nil)
+	r := mux.NewRouter()
+	r.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/index-status", h.GetIndexStatus).Methods("GET")
+	r.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/index:retry", h.RetryIndexing).Methods("POST")
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer test-api-key")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do(http.MethodGet, "/v0/vaults/v1/memories/m1/index-status?id=e1&id=c1", "")
+	var got struct {
+		Statuses map[string]model.IndexStatus `json:"statuses"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("index-status: %d %s", w.Code, w.Body.String())
+	}
+	if got.Statuses["e1"].State != model.IndexStateFailed || got.Statuses["e1"].LastError == "" || got.Statuses["c1"].State != model.IndexStateUnknown {
+		t.Fatalf("unexpected statuses: %+v", got.Statuses)
+	}
+	if w := do(http.MethodGet, "/v0/vaults/v1/memories/m1/index-status", ""); w.Code != http.StatusBadRequest {
+		t.Fatalf("index-status without ids: expected 400, got %d", w.Code)
+	}
+
+	w = do(http.MethodPost, "/v0/vaults/v1/memories/m1/index:retry", `{"ids":["e1"]}`)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"requeued":2`) || !reflect.DeepEqual(ix.retried, []string{"e1"}) {
+		t.Fatalf("retry: %d %s retried=%v", w.Code, w.Body.String(), ix.retried)
+	}
+	if w := do(http.MethodPost, "/v0/vaults/v1/memories/m1/index:retry", ""); w.Code != http.StatusOK || ix.retried != nil {
+		t.Fatalf("retry without body: %d %s retried=%v", w.Code, w.Body.String(), ix.retried)
+	}
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	respond "github.com/mycelian/mycelian-memory/server/internal/api/respond"
+	"github.com/mycelian/mycelian-memory/server/internal/auth"
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+// Long-running operations answer 202 with a job that the jobs worker runs
+// in the background; clients poll GET /v0/jobs/{jobId} for its progress.
+
+// authorizedJobs authorizes the actor for scope; it writes the error
+// response and returns false when that fails or jobs are not enabled.
+func (h *MemoryHandler) authorizedJobs(w http.ResponseWriter, r *http.Request, scope string) (actorID string, ok bool) {
+	apiKey, err := auth.ExtractAPIKey(r)
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return "", false
+	}
+	actorInfo, err := h.authorizer.Authorize(r.Context(), apiKey, scope, "default")
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return "", false
+	}
+	if !h.svc.JobsEnabled() {
+		respond.WriteNotFound(w, "jobs are not enabled")
+		return "", false
+	}
+	return actorInfo.ActorID, true
+}
+
+// writeJobError maps job errors to HTTP responses.
+func writeJobError(w http.ResponseWriter, err error) {
+	switch {
+	case writeReadOnlyError(w, err):
+	case errors.Is(err, model.ErrValidation):
+		respond.WriteBadRequest(w, err.Error())
+	case errors.Is(err, model.ErrNotFound):
+		respond.WriteNotFound(w, err.Error())
+	case errors.Is(err, model.ErrConflict):
+		respond.WriteError(w, http.StatusConflict, err.Error())
+	default:
+		respond.WriteInternalError(w, err.Error())
+	}
+}
+
+// GetJob GET /v0/jobs/{jobId}
+// Responds with the job: its status (queued, running, succeeded, failed or
+// cancelled), done of total units of work and, when it failed, the error.
+func (h *MemoryHandler) GetJob(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := h.authorizedJobs(w, r, "memory.read")
+	if !ok {
+		return
+	}
+	job, err := h.svc.GetJob(r.Context(), actorID, mux.Vars(r)["jobId"])
+	if err != nil {
+		writeJobError(w, err)
+		return
+	}
+	respond.WriteJSON(w, http.StatusOK, job)
+}
+
+// CancelJob POST /v0/jobs/{jobId}:cancel
+// A queued job is cancelled at once; a running one stops at its next
+// progress report. 409 once the job has finished.
+func (h *MemoryHandler) CancelJob(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := h.authorizedJobs(w, r, "memory.write")
+	if !ok {
+		return
+	}
+	job, err := h.svc.CancelJob(r.Context(), actorID, mux.Vars(r)["jobId"])
+	if err != nil {
+		writeJobError(w, err)
+		return
+	}
+	respond.WriteJSON(w, http.StatusOK, job)
+}
+
+// DeleteMemoryEntries POST /v0/vaults/{vaultId}/memories/{memoryId}/entries:delete
+// Body: {"entryIds": [...]}, or {"sessionId": "...", "before": "<RFC3339>"}
+// with either or both. Responds 202 with a deleteEntries job.
+func (h *MemoryHandler) DeleteMemoryEntries(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := h.authorizedJobs(w, r, "memory.write")
+	if !ok {
+		return
+	}
+	var req model.EntryDeletion
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respond.WriteBadRequest(w, "Invalid JSON")
+		return
+	}
+	v := mux.Vars(r)
+	job, err := h.svc.StartDeleteEntriesJob(r.Context(), actorID, v["vaultId"], v["memoryId"], req)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			respond.WriteNotFound(w, "memory not found")
+			return
+		}
+		writeJobError(w, err)
+		return
+	}
+	respond.WriteJSON(w, http.StatusAccepted, job)
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/services"
+	"github.com/mycelian/mycelian-memory/server/internal/store"
+)
+
+// oneJob stores the last created job; Cancel finishes it.
+type oneJob struct {
+	store.Jobs
+	job *model.Job
+}
+
+func (j *oneJob) Create(_ context.Context, in *model.Job) (*model.Job, error) {
+	out := *in
+	out.JobID, out.Status = "j1", model.JobQueued
+	j.job = &out
+	return &out, nil
+}
+
+func (j *oneJob) Get(_ context.Context, actorID, jobID string) (*model.Job, error) {
+	if j.job == nil || jobID != j.job.JobID {
+		return nil, model.ErrNotFound
+	}
+	return j.job, nil
+}
+
+func (j *oneJob) Cancel(ctx context.Context, actorID, jobID string) (*model.Job, error) {
+	job, err := j.Get(ctx, actorID, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.Finished() {
+		return nil, model.ErrConflict
+	}
+	job.Status = model.JobCancelled
+	return job, nil
+}
+
+type jobsStore struct {
+	store.Store
+	j *oneJob
+}
+
+func (jobsStore) Vaults() store.Vaults {
+	return &memVaults{readOnly: map[string]bool{"v1": false, "ro": true}}
+}
+func (jobsStore) Memories() store.Memories { return memMemories{} }
+func (s jobsStore) Jobs() store.Jobs       { return s.j }
+
+func TestJobHandlers(t *testing.T) {
+	st := jobsStore{j: &oneJob{}}
+	svc := services.NewMemoryService(st, nil, nil)
+	h := NewMemoryHandler(svc, services.NewVaultService(st, nil), &mockAuthorizer{}, nil)
+	r := mux.NewRouter()
+	r.HandleFunc("/v0/jobs/{jobId}", h.GetJob).Methods("GET")
+	r.HandleFunc("/v0/jobs/{jobId}:cancel", h.CancelJob).Methods("POST")
+	r.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/entries:delete", h.DeleteMemoryEntries).Methods("POST")
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer test-api-key")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := do(http.MethodPost, "/v0/vaults/v1/memories/m1/entries:delete", `{"entryIds":["e1"]}`); w.Code != http.StatusNotFound {
+		t.Fatalf("jobs disabled: expected 404, got %d", w.Code)
+	}
+	svc.EnableJobs()
+
+	for body, want := range map[string]int{
+		`{}`:                   http.StatusBadRequest,
+		`{"entryIds":`:         http.StatusBadRequest,
+		`{"sessionId":"s1"}`:   http.StatusAccepted,
+		`{"entryIds":["e1"]}`:  http.StatusAccepted,
+		`{"before":"garbage"}`: http.StatusBadRequest,
+	} {
+		if w := do(http.MethodPost, "/v0/vaults/v1/memories/m1/entries:delete", body); w.Code != want {
+			t.Fatalf("%s: expected %d, got %d %s", body, want, w.Code, w.Body.String())
+		}
+	}
+	if w := do(http.MethodPost, "/v0/vaults/ro/memories/m1/entries:delete", `{"entryIds":["e1"]}`); w.Code != http.StatusConflict {
+		t.Fatalf("read-only vault: expected 409, got %d", w.Code)
+	}
+
+	w := do(http.MethodGet, "/v0/jobs/j1", "")
+	var job model.Job
+	if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &job) != nil || job.Kind != model.JobDeleteEntries || job.Status != model.JobQueued || job.MemoryID != "m1" {
+		t.Fatalf("get job: %d %s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodGet, "/v0/jobs/nope", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("unknown job: expected 404, got %d", w.Code)
+	}
+	if w := do(http.MethodPost, "/v0/jobs/j1:cancel", ""); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"status":"cancelled"`) {
+		t.Fatalf("cancel: %d %s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodPost, "/v0/jobs/j1:cancel", ""); w.Code != http.StatusConflict {
+		t.Fatalf("cancel finished job: expected 409, got %d", w.Code)
+	}
+}
@@ -1,16 +1,21 @@
 package api
 
 import (
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"hash/fnv"
 	"io"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 	"unicode"
 	"unicode/utf8"
 
 	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
 
 	respond "github.com/mycelian/mycelian-memory/server/internal/api/respond"
 	"github.com/mycelian/mycelian-memory/server/internal/auth"
@@ -24,12 +29,20 @@ type MemoryHandler struct {
 	vaultSv    *services.VaultService
 	authorizer auth.Authorizer
 	cfg        *config.Config
+	actors     *services.ActorService // nil renders and filters times in UTC unless ?tz= is given
+	// nil leaves POST .../conversations disabled
+	conversations *services.ConversationService
+	summarize     *services.SummarizeService // nil leaves POST .../summarize disabled
+	webhooks      *services.WebhookService   // nil leaves webhooks disabled
 }
 
 func NewMemoryHandler(svc *services.MemoryService, vaultSvc *services.VaultService, authorizer auth.Authorizer, cfg *config.Config) *MemoryHandler {
 	return &MemoryHandler{svc: svc, vaultSv: vaultSvc, authorizer: authorizer, cfg: cfg}
 }
 
+// EnableActorTimeZones makes entry reads default to the actor's saved time zone.
+func (h *MemoryHandler) EnableActorTimeZones(svc *services.ActorService) { h.actors = svc }
+
 // CreateMemory POST /api/vaults/{vaultId}/memories
 func (h *MemoryHandler) CreateMemory(w http.ResponseWriter, r *http.Request) {
 	// Extract API key from Authorization header
@@ -52,14 +65,24 @@ func (h *MemoryHandler) CreateMemory(w http.ResponseWriter, r *http.Request) {
 		MemoryType  string  `json:"memoryType"`
 		Title       string  `json:"title"`
 		Description *string `json:"description,omitempty"`
+		AppendOnly  bool     `json:"appendOnly,omitempty"`
+		EntryRoles  []string `json:"entryRoles,omitempty"`
+		EntryTTLSeconds int  `json:"entryTtlSeconds,omitempty"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		respond.WriteBadRequest(w, "Invalid JSON")
 		return
 	}
-	m := &model.Memory{ActorID: actorInfo.ActorID, VaultID: vaultID, MemoryType: req.MemoryType, Title: req.Title, Description: req.Description}
+	m := &model.Memory{ActorID: actorInfo.ActorID, VaultID: vaultID, MemoryType: req.MemoryType, Title: req.Title, Description: req.Description, AppendOnly: req.AppendOnly, EntryRoles: req.EntryRoles, EntryTTLSeconds: req.EntryTTLSeconds}
 	out, err := h.svc.CreateMemory(r.Context(), m)
 	if err != nil {
+		if errors.Is(err, model.ErrValidation) {
+			respond.WriteBadRequest(w, err.Error())
+			return
+		}
+		if writeReadOnlyError(w, err) {
+			return
+		}
 		respond.WriteInternalError(w, err.Error())
 		return
 	}
@@ -67,6 +90,7 @@ func (h *MemoryHandler) CreateMemory(w http.ResponseWriter, r *http.Request) {
 }
 
 // ListMemories GET /api/vaults/{vaultId}/memories
+// {vaultId} may also be the vault's title or slug.
 func (h *MemoryHandler) ListMemories(w http.ResponseWriter, r *http.Request) {
 	// Extract API key from Authorization header
 	apiKey, err := auth.ExtractAPIKey(r)
@@ -83,7 +107,11 @@ func (h *MemoryHandler) ListMemories(w http.ResponseWriter, r *http.Request) {
 	}
 
 	v := mux.Vars(r)
-	out, err := h.svc.ListMemories(r.Context(), actorInfo.ActorID, v["vaultId"])
+	vaultID, ok := h.resolveVaultRef(w, r, actorInfo.ActorID, v["vaultId"])
+	if !ok {
+		return
+	}
+	out, err := h.svc.ListMemories(r.Context(), actorInfo.ActorID, vaultID)
 	if err != nil {
 		respond.WriteInternalError(w, err.Error())
 		return
@@ -95,6 +123,7 @@ func (h *MemoryHandler) ListMemories(w http.ResponseWriter, r *http.Request) {
 }
 
 // GetMemory GET /api/vaults/{vaultId}/memories/{memoryId}
+// Either path segment may also be a title or slug.
 func (h *MemoryHandler) GetMemory(w http.ResponseWriter, r *http.Request) {
 	// Extract API key from Authorization header
 	apiKey, err := auth.ExtractAPIKey(r)
@@ -111,16 +140,239 @@ func (h *MemoryHandler) GetMemory(w http.ResponseWriter, r *http.Request) {
 	}
 
 	v := mux.Vars(r)
-	out, err := h.svc.GetMemory(r.Context(), actorInfo.ActorID, v["vaultId"], v["memoryId"])
+	vaultID, ok := h.resolveVaultRef(w, r, actorInfo.ActorID, v["vaultId"])
+	if !ok {
+		return
+	}
+	out, err := h.svc.ResolveMemory(r.Context(), actorInfo.ActorID, vaultID, v["memoryId"])
 	if err != nil {
-		respond.WriteNotFound(w, err.Error())
+		writeResolveError(w, err, "memory not found")
+		return
+	}
+	respond.WriteJSON(w, http.StatusOK, out)
+}
+
+// resolveVaultRef returns the ID of the vault ref names by ID, title or slug.
+// Without a vault service ref is taken as an ID. mux has already decoded the
+// path, so "Travel%20Notes" arrives as "Travel Notes".
+func (h *MemoryHandler) resolveVaultRef(w http.ResponseWriter, r *http.Request, actorID, ref string) (string, bool) {
+	if h.vaultSv == nil {
+		return ref, true
+	}
+	vault, err := h.vaultSv.ResolveVault(r.Context(), actorID, ref)
+	if err != nil {
+		writeResolveError(w, err, "vault not found")
+		return "", false
+	}
+	return vault.VaultID, true
+}
+
+// writeResolveError maps a failed ID, title or slug lookup: 400 for an
+// invalid reference, 404 when nothing matches, 500 for store failures.
+func writeResolveError(w http.ResponseWriter, err error, notFound string) {
+	switch {
+	case errors.Is(err, model.ErrValidation):
+		respond.WriteBadRequest(w, err.Error())
+	case errors.Is(err, model.ErrNotFound):
+		respond.WriteNotFound(w, notFound)
+	default:
+		respond.WriteInternalError(w, err.Error())
+	}
+}
+
+// SetMemoryAppendOnly PUT /api/vaults/{vaultId}/memories/{memoryId}/append-only
+// Body: {"appendOnly": true}. Once set, entry tag updates, entry deletes and
+// batch rollbacks touching the memory fail with 409; the flag cannot be
+// cleared, so {"appendOnly": false} is rejected with 409 as well.
+func (h *MemoryHandler) SetMemoryAppendOnly(w http.ResponseWriter, r *http.Request) {
+	apiKey, err := auth.ExtractAPIKey(r)
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+
+	actorInfo, err := h.authorizer.Authorize(r.Context(), apiKey, "memory.write", "default")
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+
+	var req struct {
+		AppendOnly *bool `json:"appendOnly"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respond.WriteBadRequest(w, "Invalid JSON")
+		return
+	}
+	if req.AppendOnly == nil {
+		respond.WriteBadRequest(w, "appendOnly is required")
+		return
+	}
+	if !*req.AppendOnly {
+		respond.WriteError(w, http.StatusConflict, "appendOnly cannot be cleared once set")
+		return
+	}
+
+	v := mux.Vars(r)
+	out, err := h.svc.SetMemoryAppendOnly(r.Context(), actorInfo.ActorID, v["vaultId"], v["memoryId"])
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			respond.WriteNotFound(w, "memory not found")
+			return
+		}
+		if writeReadOnlyError(w, err) {
+			return
+		}
+		respond.WriteInternalError(w, err.Error())
+		return
+	}
+	respond.WriteJSON(w, http.StatusOK, out)
+}
+
+// UpdateMemory PATCH /v0/vaults/{vaultId}/memories/{memoryId}
+// Body: {"title": "...", "description": "..."}; omitted fields are unchanged.
+// A new title reaches the memory's search index objects asynchronously.
+func (h *MemoryHandler) UpdateMemory(w http.ResponseWriter, r *http.Request) {
+	apiKey, err := auth.ExtractAPIKey(r)
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+
+	actorInfo, err := h.authorizer.Authorize(r.Context(), apiKey, "memory.write", "default")
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+
+	var req model.TitleUpdate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respond.WriteBadRequest(w, "Invalid JSON")
+		return
+	}
+	if err := TitleUpdate(req); err != nil {
+		respond.WriteBadRequest(w, err.Error())
+		return
+	}
+
+	v := mux.Vars(r)
+	out, err := h.svc.UpdateMemory(r.Context(), actorInfo.ActorID, v["vaultId"], v["memoryId"], req)
+	if err != nil {
+		writeTitleUpdateError(w, err, "memory not found")
+		return
+	}
+	respond.WriteJSON(w, http.StatusOK, out)
+}
+
+// SetMemoryEntryRoles PUT /v0/vaults/{vaultId}/memories/{memoryId}/entry-roles
+// Body: {"entryRoles": ["user", "assistant"]}. Afterwards entries whose
+// metadata.role is not listed are rejected with 400; an empty list lifts
+// the requirement.
+func (h *MemoryHandler) SetMemoryEntryRoles(w http.ResponseWriter, r *http.Request) {
+	apiKey, err := auth.ExtractAPIKey(r)
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+
+	actorInfo, err := h.authorizer.Authorize(r.Context(), apiKey, "memory.write", "default")
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+
+	var req struct {
+		EntryRoles *[]string `json:"entryRoles"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respond.WriteBadRequest(w, "Invalid JSON")
+		return
+	}
+	if req.EntryRoles == nil {
+		respond.WriteBadRequest(w, "entryRoles is required")
+		return
+	}
+
+	v := mux.Vars(r)
+	out, err := h.svc.SetMemoryEntryRoles(r.Context(), actorInfo.ActorID, v["vaultId"], v["memoryId"], *req.EntryRoles)
+	if err != nil {
+		if errors.Is(err, model.ErrValidation) {
+			respond.WriteBadRequest(w, err.Error())
+			return
+		}
+		if errors.Is(err, model.ErrNotFound) {
+			respond.WriteNotFound(w, "memory not found")
+			return
+		}
+		if writeReadOnlyError(w, err) {
+			return
+		}
+		respond.WriteInternalError(w, err.Error())
+		return
+	}
+	respond.WriteJSON(w, http.StatusOK, out)
+}
+
+// SetMemorySearchBoost PUT /v0/vaults/{vaultId}/memories/{memoryId}/search-boost
+// Body: {"boost": 2, "fieldWeights": {"summary": 2, "rawEntry": 1}}. Applied
+// when the memory is searched as part of a vault-scope search; an empty body
+// object clears it.
+func (h *MemoryHandler) SetMemorySearchBoost(w http.ResponseWriter, r *http.Request) {
+	apiKey, err := auth.ExtractAPIKey(r)
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+
+	actorInfo, err := h.authorizer.Authorize(r.Context(), apiKey, "memory.write", "default")
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+
+	var req model.SearchBoost
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respond.WriteBadRequest(w, "Invalid JSON")
+		return
+	}
+
+	v := mux.Vars(r)
+	out, err := h.svc.SetMemorySearchBoost(r.Context(), actorInfo.ActorID, v["vaultId"], v["memoryId"], &req)
+	if err != nil {
+		if errors.Is(err, model.ErrValidation) {
+			respond.WriteBadRequest(w, err.Error())
+			return
+		}
+		if errors.Is(err, model.ErrNotFound) {
+			respond.WriteNotFound(w, "memory not found")
+			return
+		}
+		if writeReadOnlyError(w, err) {
+			return
+		}
+		respond.WriteInternalError(w, err.Error())
 		return
 	}
 	respond.WriteJSON(w, http.StatusOK, out)
 }
 
 // ListMemoryEntries GET /api/vaults/{vaultId}/memories/{memoryId}/entries
+// Newest first; ?sessionId= restricts the list to one session, repeated
+// ?tag=key=value to entries holding all those tags, and
+// ?orderBy=conversationTime sorts by when the conversation took place.
+// A full page carries nextPageToken; pass it back as ?pageToken= to walk
+// the memory past ?limit= (creation time order only).
 func (h *MemoryHandler) ListMemoryEntries(w http.ResponseWriter, r *http.Request) {
+	h.listEntries(w, r, r.URL.Query().Get("sessionId"), false)
+}
+
+// ListSessionEntries GET /api/vaults/{vaultId}/memories/{memoryId}/sessions/{sessionId}/entries
+// lists a session's entries oldest first, in conversation order.
+func (h *MemoryHandler) ListSessionEntries(w http.ResponseWriter, r *http.Request) {
+	h.listEntries(w, r, mux.Vars(r)["sessionId"], true)
+}
+
+func (h *MemoryHandler) listEntries(w http.ResponseWriter, r *http.Request, sessionID string, ascending bool) {
 	// Extract API key from Authorization header
 	apiKey, err := auth.ExtractAPIKey(r)
 	if err != nil {
@@ -155,23 +407,52 @@ func (h *MemoryHandler) ListMemoryEntries(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	loc, err := requestLocation(r.Context(), r, h.actors, actorInfo.ActorID)
+	if err != nil {
+		writeLocationError(w, err)
+		return
+	}
+
 	q := r.URL.Query()
-	req := model.ListEntriesRequest{ActorID: actorInfo.ActorID, VaultID: vaultID, MemoryID: memoryID}
+	req := model.ListEntriesRequest{ActorID: actorInfo.ActorID, VaultID: vaultID, MemoryID: memoryID, SessionID: sessionID, Ascending: ascending}
+	switch req.OrderBy = q.Get("orderBy"); req.OrderBy {
+	case "", model.EntryOrderCreationTime, model.EntryOrderConversationTime:
+	default:
+		respond.WriteBadRequest(w, "orderBy must be creationTime or conversationTime")
+		return
+	}
+	if req.Tags, err = parseTagParams(q["tag"]); err != nil {
+		respond.WriteBadRequest(w, err.Error())
+		return
+	}
 	if s := q.Get("limit"); s != "" {
 		if n, err := strconv.Atoi(s); err == nil && n > 0 {
 			req.Limit = n
 		}
 	}
+	now := time.Now()
 	if s := q.Get("before"); s != "" {
-		if t, err := time.Parse(time.RFC3339, s); err == nil {
+		if t, err := parseTimeParam(s, loc, now); err == nil {
 			req.Before = &t
 		}
 	}
 	if s := q.Get("after"); s != "" {
-		if t, err := time.Parse(time.RFC3339, s); err == nil {
+		if t, err := parseTimeParam(s, loc, now); err == nil {
 			req.After = &t
 		}
 	}
+	if s := q.Get("pageToken"); s != "" {
+		if req.OrderBy == model.EntryOrderConversationTime {
+			respond.WriteBadRequest(w, "pageToken requires orderBy=creationTime")
+			return
+		}
+		cur, err := decodeEntryCursor(s)
+		if err != nil {
+			respond.WriteBadRequest(w, "invalid pageToken")
+			return
+		}
+		req.Cursor = cur
+	}
 	outs, err := h.svc.ListEntries(r.Context(), req)
 	if err != nil {
 		respond.WriteInternalError(w, err.Error())
@@ -180,7 +461,240 @@ func (h *MemoryHandler) ListMemoryEntries(w http.ResponseWriter, r *http.Request
 	if outs == nil {
 		outs = []*model.MemoryEntry{}
 	}
-	respond.WriteJSON(w, http.StatusOK, map[string]interface{}{"entries": outs, "count": len(outs)})
+	resp := map[string]interface{}{"entries": outs, "count": len(outs)}
+	if req.Limit > 0 && len(outs) == req.Limit && req.OrderBy != model.EntryOrderConversationTime {
+		last := outs[len(outs)-1]
+		resp["nextPageToken"] = encodeEntryCursor(model.EntryCursor{CreationTime: last.CreationTime, EntryID: last.EntryID})
+	}
+	entriesIn(outs, loc)
+	respond.WriteJSON(w, http.StatusOK, resp)
+}
+
+const (
+	defaultScanLimit = 50
+	maxScanLimit     = 500
+)
+
+// ScanMemoryEntries GET /v0/vaults/{vaultId}/memories/{memoryId}/entries:scan
+// finds entries by exact substring (?contains=) or regular expression
+// (?regex=) with a bounded, paginated database scan that does not touch the
+// search index. Pass nextCursor back as ?cursor= for the next page.
+func (h *MemoryHandler) ScanMemoryEntries(w http.ResponseWriter, r *http.Request) {
+	apiKey, err := auth.ExtractAPIKey(r)
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+	actorInfo, err := h.authorizer.Authorize(r.Context(), apiKey, "memory.read", "default")
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+
+	v := mux.Vars(r)
+	vaultID := v["vaultId"]
+	memoryID := v["memoryId"]
+
+	// SECURITY: Validate vault exists and actor owns it
+	if h.vaultSv != nil {
+		_, err := h.vaultSv.GetVault(r.Context(), actorInfo.ActorID, vaultID)
+		if err != nil {
+			respond.WriteNotFound(w, "vault not found")
+			return
+		}
+	}
+
+	// SECURITY: Validate memory exists in the vault and actor owns it
+	_, err = h.svc.GetMemory(r.Context(), actorInfo.ActorID, vaultID, memoryID)
+	if err != nil {
+		respond.WriteNotFound(w, "memory not found")
+		return
+	}
+
+	loc, err := requestLocation(r.Context(), r, h.actors, actorInfo.ActorID)
+	if err != nil {
+		writeLocationError(w, err)
+		return
+	}
+
+	q := r.URL.Query()
+	req := model.ScanEntriesRequest{
+		ActorID:  actorInfo.ActorID,
+		VaultID:  vaultID,
+		MemoryID: memoryID,
+		Contains: q.Get("contains"),
+		Regex:    q.Get("regex"),
+		Limit:    defaultScanLimit,
+	}
+	if s := q.Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 || n > maxScanLimit {
+			respond.WriteBadRequest(w, fmt.Sprintf("limit must be between 1 and %d", maxScanLimit))
+			return
+		}
+		req.Limit = n
+	}
+	if s := q.Get("cursor"); s != "" {
+		cur, err := decodeEntryCursor(s)
+		if err != nil {
+			respond.WriteBadRequest(w, "invalid cursor")
+			return
+		}
+		req.After = cur
+	}
+
+	outs, err := h.svc.ScanEntries(r.Context(), req)
+	if err != nil {
+		if errors.Is(err, model.ErrValidation) {
+			respond.WriteBadRequest(w, err.Error())
+			return
+		}
+		respond.WriteInternalError(w, err.Error())
+		return
+	}
+	if outs == nil {
+		outs = []*model.MemoryEntry{}
+	}
+	resp := map[string]interface{}{"entries": outs, "count": len(outs)}
+	if len(outs) == req.Limit {
+		last := outs[len(outs)-1]
+		resp["nextCursor"] = encodeEntryCursor(model.EntryCursor{CreationTime: last.CreationTime, EntryID: last.EntryID})
+	}
+	entriesIn(outs, loc)
+	respond.WriteJSON(w, http.StatusOK, resp)
+}
+
+// encodeEntryCursor makes an opaque page token from an entry position.
+func encodeEntryCursor(c model.EntryCursor) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(c.CreationTime.UTC().Format(time.RFC3339Nano) + "|" + c.EntryID))
+}
+
+func decodeEntryCursor(s string) (*model.EntryCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	ts, id, ok := strings.Cut(string(b), "|")
+	if !ok || id == "" {
+		return nil, fmt.Errorf("malformed cursor")
+	}
+	t, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return nil, err
+	}
+	return &model.EntryCursor{CreationTime: t, EntryID: id}, nil
+}
+
+// ListSessions GET /api/vaults/{vaultId}/memories/{memoryId}/sessions
+func (h *MemoryHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	// Extract API key from Authorization header
+	apiKey, err := auth.ExtractAPIKey(r)
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+
+	// Authorize the request
+	actorInfo, err := h.authorizer.Authorize(r.Context(), apiKey, "memory.read", "default")
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+
+	v := mux.Vars(r)
+	vaultID := v["vaultId"]
+	memoryID := v["memoryId"]
+
+	// SECURITY: Validate memory exists in the vault and actor owns it
+	if _, err := h.svc.GetMemory(r.Context(), actorInfo.ActorID, vaultID, memoryID); err != nil {
+		respond.WriteNotFound(w, "memory not found")
+		return
+	}
+
+	loc, err := requestLocation(r.Context(), r, h.actors, actorInfo.ActorID)
+	if err != nil {
+		writeLocationError(w, err)
+		return
+	}
+
+	sessions, err := h.svc.ListSessions(r.Context(), actorInfo.ActorID, vaultID, memoryID)
+	if err != nil {
+		respond.WriteInternalError(w, err.Error())
+		return
+	}
+	if sessions == nil {
+		sessions = []model.EntrySession{}
+	}
+	for i := range sessions {
+		sessions[i].FirstEntryTime = sessions[i].FirstEntryTime.In(loc)
+		sessions[i].LastEntryTime = sessions[i].LastEntryTime.In(loc)
+	}
+	respond.WriteJSON(w, http.StatusOK, map[string]interface{}{"sessions": sessions, "count": len(sessions)})
+}
+
+// ExportMemoryEntries GET /api/vaults/{vaultId}/memories/{memoryId}/export?embeddings=
+// streams every entry, oldest first, as JSON Lines. With embeddings=true each
+// line also carries the entry's stored vector so it can be loaded into another
+// vector store without re-embedding.
+func (h *MemoryHandler) ExportMemoryEntries(w http.ResponseWriter, r *http.Request) {
+	// Extract API key from Authorization header
+	apiKey, err := auth.ExtractAPIKey(r)
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+
+	// Authorize the request
+	actorInfo, err := h.authorizer.Authorize(r.Context(), apiKey, "memory.read", "default")
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+
+	v := mux.Vars(r)
+	vaultID := v["vaultId"]
+	memoryID := v["memoryId"]
+
+	var embedModel string
+	if raw := r.URL.Query().Get("embeddings"); raw != "" {
+		with, err := strconv.ParseBool(raw)
+		if err != nil {
+			respond.WriteBadRequest(w, "embeddings must be true or false")
+			return
+		}
+		if with {
+			embedModel = "unknown"
+			if h.cfg != nil && h.cfg.EmbedModel != "" {
+				embedModel = h.cfg.EmbedModel
+			}
+		}
+	}
+
+	// SECURITY: Validate memory exists in the vault and actor owns it
+	if _, err := h.svc.GetMemory(r.Context(), actorInfo.ActorID, vaultID, memoryID); err != nil {
+		respond.WriteNotFound(w, "memory not found")
+		return
+	}
+
+	entries, err := h.svc.ExportEntries(r.Context(), actorInfo.ActorID, vaultID, memoryID, embedModel)
+	if errors.Is(err, services.ErrEmbeddingsUnsupported) {
+		respond.WriteError(w, http.StatusNotImplemented, err.Error())
+		return
+	}
+	if err != nil {
+		respond.WriteInternalError(w, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			log.Warn().Err(err).Str("memoryId", memoryID).Msg("export aborted")
+			return
+		}
+	}
 }
 
 // CreateMemoryEntry POST /api/vaults/{vaultId}/memories/{memoryId}/entries
@@ -219,24 +733,26 @@ func (h *MemoryHandler) CreateMemoryEntry(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	var in struct {
-		RawEntry       string                 `json:"rawEntry"`
-		Summary        *string                `json:"summary,omitempty"`
-		Metadata       map[string]interface{} `json:"metadata,omitempty"`
-		Tags           map[string]interface{} `json:"tags,omitempty"`
-		ExpirationTime *time.Time             `json:"expirationTime,omitempty"`
-	}
+	var in entryInput
 	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
 		respond.WriteBadRequest(w, "Invalid JSON")
 		return
 	}
-	e := &model.MemoryEntry{
-		ActorID: actorInfo.ActorID, VaultID: vaultID, MemoryID: memoryID,
-		RawEntry: in.RawEntry, Summary: in.Summary, Metadata: in.Metadata, Tags: in.Tags, ExpirationTime: in.ExpirationTime,
+	if err := in.validate(); err != nil {
+		respond.WriteBadRequest(w, err.Error())
+		return
 	}
+	e := in.entry(actorInfo.ActorID, vaultID, memoryID)
 	out, err := h.svc.CreateEntry(r.Context(), e)
 	if err != nil {
-		respond.WriteInternalError(w, err.Error())
+		switch {
+		case errors.Is(err, model.ErrValidation):
+			respond.WriteBadRequest(w, err.Error())
+		case errors.Is(err, model.ErrReadOnly), errors.Is(err, model.ErrConflict):
+			respond.WriteError(w, http.StatusConflict, err.Error())
+		default:
+			respond.WriteInternalError(w, err.Error())
+		}
 		return
 	}
 	respond.WriteJSON(w, http.StatusCreated, out)
@@ -259,11 +775,17 @@ func (h *MemoryHandler) GetMemoryEntryByID(w http.ResponseWriter, r *http.Reques
 	}
 
 	v := mux.Vars(r)
+	loc, err := requestLocation(r.Context(), r, h.actors, actorInfo.ActorID)
+	if err != nil {
+		writeLocationError(w, err)
+		return
+	}
 	out, err := h.svc.GetEntryByID(r.Context(), actorInfo.ActorID, v["vaultId"], v["memoryId"], v["entryId"])
 	if err != nil {
 		respond.WriteNotFound(w, err.Error())
 		return
 	}
+	entriesIn([]*model.MemoryEntry{out}, loc)
 	respond.WriteJSON(w, http.StatusOK, out)
 }
 
@@ -313,12 +835,74 @@ func (h *MemoryHandler) UpdateMemoryEntryTags(w http.ResponseWriter, r *http.Req
 	}
 	out, err := h.svc.UpdateEntryTags(r.Context(), actorInfo.ActorID, vaultID, memoryID, entryID, in.Tags)
 	if err != nil {
+		if writeReadOnlyError(w, err) {
+			return
+		}
 		respond.WriteInternalError(w, err.Error())
 		return
 	}
 	respond.WriteJSON(w, http.StatusOK, out)
 }
 
+// RecordEntrySignal POST /api/vaults/{vaultId}/memories/{memoryId}/entries/{entryId}/signals
+// Body: {"signal": "useful"|"incorrect"|"outdated"}. Increments the matching counter.
+func (h *MemoryHandler) RecordEntrySignal(w http.ResponseWriter, r *http.Request) {
+	apiKey, err := auth.ExtractAPIKey(r)
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+
+	actorInfo, err := h.authorizer.Authorize(r.Context(), apiKey, "memory.write", "default")
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+
+	v := mux.Vars(r)
+	vaultID := v["vaultId"]
+	memoryID := v["memoryId"]
+	entryID := v["entryId"]
+
+	// SECURITY: Validate vault exists and actor owns it
+	if h.vaultSv != nil {
+		_, err := h.vaultSv.GetVault(r.Context(), actorInfo.ActorID, vaultID)
+		if err != nil {
+			respond.WriteNotFound(w, "vault not found")
+			return
+		}
+	}
+
+	// SECURITY: Validate memory exists in the vault and actor owns it
+	_, err = h.svc.GetMemory(r.Context(), actorInfo.ActorID, vaultID, memoryID)
+	if err != nil {
+		respond.WriteNotFound(w, "memory not found")
+		return
+	}
+
+	var in struct {
+		Signal string `json:"signal"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		respond.WriteBadRequest(w, "Invalid JSON")
+		return
+	}
+	out, err := h.svc.RecordEntrySignal(r.Context(), actorInfo.ActorID, vaultID, memoryID, entryID, in.Signal)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrValidation):
+			respond.WriteBadRequest(w, err.Error())
+		case errors.Is(err, model.ErrNotFound):
+			respond.WriteNotFound(w, "entry not found")
+		case writeReadOnlyError(w, err):
+		default:
+			respond.WriteInternalError(w, err.Error())
+		}
+		return
+	}
+	respond.WriteJSON(w, http.StatusOK, out)
+}
+
 // PutMemoryContext PUT /api/vaults/{vaultId}/memories/{memoryId}/contexts
 func (h *MemoryHandler) PutMemoryContext(w http.ResponseWriter, r *http.Request) {
 	// Extract API key from Authorization header
@@ -355,8 +939,12 @@ func (h *MemoryHandler) PutMemoryContext(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if isJSONContentType(r.Header.Get("Content-Type")) {
+		h.putContextSections(w, r, actorInfo.ActorID, vaultID, memoryID)
+		return
+	}
 	if ct := r.Header.Get("Content-Type"); ct != "" && ct != "text/plain" && ct != "text/plain; charset=utf-8" {
-		respond.WriteError(w, http.StatusUnsupportedMediaType, "Content-Type must be text/plain")
+		respond.WriteError(w, http.StatusUnsupportedMediaType, "Content-Type must be text/plain or application/json")
 		return
 	}
 	doc, err := io.ReadAll(r.Body)
@@ -368,27 +956,10 @@ func (h *MemoryHandler) PutMemoryContext(w http.ResponseWriter, r *http.Request)
 		respond.WriteBadRequest(w, "context document must not be empty")
 		return
 	}
-	// UTF-8 and character-set validation
-	if !utf8.Valid(doc) {
-		respond.WriteBadRequest(w, "context must be valid UTF-8")
-		return
-	}
 	s := string(doc)
-	for _, r := range s {
-		// Allow common whitespace
-		if r == '\n' || r == '\r' || r == '\t' {
-			continue
-		}
-		// Disallow other control characters
-		if unicode.IsControl(r) {
-			respond.WriteBadRequest(w, fmt.Sprintf("invalid control character: U+%04X", r))
-			return
-		}
-		// Disallow Unicode noncharacters (U+FDD0..U+FDEF and U+..FFFE/FFFF in every plane)
-		if (r&0xFFFE == 0xFFFE) || (r >= 0xFDD0 && r <= 0xFDEF) {
-			respond.WriteBadRequest(w, fmt.Sprintf("invalid noncharacter: U+%04X", r))
-			return
-		}
+	if err := validateContextText(s); err != nil {
+		respond.WriteBadRequest(w, err.Error())
+		return
 	}
 	if h.cfg != nil && h.cfg.MaxContextChars > 0 {
 		if len(doc) > h.cfg.MaxContextChars {
@@ -399,12 +970,33 @@ func (h *MemoryHandler) PutMemoryContext(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
+	checkLimit, err := contextCheckLimit(r)
+	if err != nil {
+		respond.WriteBadRequest(w, err.Error())
+		return
+	}
+	var check *model.ContextCheck
+	if checkLimit > 0 {
+		// Checked before saving: the previous context tells dropped facts apart.
+		if check, err = h.svc.CheckContext(r.Context(), actorInfo.ActorID, vaultID, memoryID, s, checkLimit); err != nil {
+			respond.WriteInternalError(w, err.Error())
+			return
+		}
+	}
+
 	mc := &model.MemoryContext{ActorID: actorInfo.ActorID, VaultID: vaultID, MemoryID: memoryID, Context: s}
 	out, err := h.svc.PutContext(r.Context(), mc)
 	if err != nil {
+		if writeReadOnlyError(w, err) {
+			return
+		}
 		respond.WriteInternalError(w, err.Error())
 		return
 	}
+	if check != nil {
+		respond.WriteJSON(w, http.StatusCreated, contextWithCheck{out, check})
+		return
+	}
 	respond.WriteJSON(w, http.StatusCreated, out)
 }
 
@@ -444,85 +1036,98 @@ func (h *MemoryHandler) GetLatestMemoryContext(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	out, err := h.svc.GetLatestContext(r.Context(), actorInfo.ActorID, vaultID, memoryID)
-	if err != nil {
-		respond.WriteInternalError(w, err.Error())
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "text" && format != "structured" {
+		respond.WriteBadRequest(w, "format must be text or structured")
 		return
 	}
-	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
-	w.WriteHeader(http.StatusOK)
-	_, _ = w.Write([]byte(out.Context))
-}
-
-// GetMemoryByTitle GET /api/vaults/{vaultTitle}/memories/{memoryTitle}
-func (h *MemoryHandler) GetMemoryByTitle(w http.ResponseWriter, r *http.Request) {
-	// Extract API key from Authorization header
-	apiKey, err := auth.ExtractAPIKey(r)
-	if err != nil {
-		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
-		return
+	recentN := 0
+	if s := r.URL.Query().Get("includeRecentSummaries"); s != "" {
+		if recentN, err = strconv.Atoi(s); err != nil || recentN < 0 || recentN > services.MaxRecentSummaries {
+			respond.WriteBadRequest(w, fmt.Sprintf("includeRecentSummaries must be between 0 and %d", services.MaxRecentSummaries))
+			return
+		}
 	}
-
-	// Authorize the request
-	actorInfo, err := h.authorizer.Authorize(r.Context(), apiKey, "memory.read", "default")
+	out, err := h.svc.GetLatestContext(r.Context(), actorInfo.ActorID, vaultID, memoryID)
 	if err != nil {
-		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		respond.WriteInternalError(w, err.Error())
 		return
 	}
-
-	v := mux.Vars(r)
-	if h.vaultSv == nil {
-		respond.WriteInternalError(w, "vault service unavailable")
-		return
+	var recent []model.EntrySummary
+	if recentN > 0 {
+		if recent, err = h.svc.RecentEntrySummaries(r.Context(), actorInfo.ActorID, vaultID, memoryID, recentN); err != nil {
+			respond.WriteInternalError(w, err.Error())
+			return
+		}
 	}
-	vaultObj, err := h.vaultSv.GetVaultByTitle(r.Context(), actorInfo.ActorID, v["vaultTitle"])
-	if err != nil {
-		respond.WriteNotFound(w, err.Error())
+	// Each put creates a new context ID, so it serves as a strong validator.
+	etag := `"` + out.ContextID + recentEntriesTag(recent) + `"`
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
 		return
 	}
-	mem, err := h.svc.GetMemoryByTitle(r.Context(), actorInfo.ActorID, vaultObj.VaultID, v["memoryTitle"])
-	if err != nil {
-		respond.WriteNotFound(w, err.Error())
+	if format == "structured" || recentN > 0 {
+		writeStructuredContext(w, out, recent)
 		return
 	}
-	respond.WriteJSON(w, http.StatusOK, mem)
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(out.Context))
 }
 
-// ListMemoriesByVaultTitle GET /api/vaults/{vaultTitle}/memories
-func (h *MemoryHandler) ListMemoriesByVaultTitle(w http.ResponseWriter, r *http.Request) {
-	// Extract API key from Authorization header
-	apiKey, err := auth.ExtractAPIKey(r)
-	if err != nil {
-		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
-		return
+// validateContextText checks that context text is valid UTF-8 free of
+// control characters other than common whitespace and of Unicode
+// noncharacters.
+func validateContextText(s string) error {
+	if !utf8.ValidString(s) {
+		return errors.New("context must be valid UTF-8")
 	}
-
-	// Authorize the request
-	actorInfo, err := h.authorizer.Authorize(r.Context(), apiKey, "memory.read", "default")
-	if err != nil {
-		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
-		return
+	for _, r := range s {
+		// Allow common whitespace
+		if r == '\n' || r == '\r' || r == '\t' {
+			continue
+		}
+		// Disallow other control characters
+		if unicode.IsControl(r) {
+			return fmt.Errorf("invalid control character: U+%04X", r)
+		}
+		// Disallow Unicode noncharacters (U+FDD0..U+FDEF and U+..FFFE/FFFF in every plane)
+		if (r&0xFFFE == 0xFFFE) || (r >= 0xFDD0 && r <= 0xFDEF) {
+			return fmt.Errorf("invalid noncharacter: U+%04X", r)
+		}
 	}
+	return nil
+}
 
-	v := mux.Vars(r)
-	if h.vaultSv == nil {
-		respond.WriteInternalError(w, "vault service unavailable")
-		return
-	}
-	vaultObj, err := h.vaultSv.GetVaultByTitle(r.Context(), actorInfo.ActorID, v["vaultTitle"])
-	if err != nil {
-		respond.WriteNotFound(w, err.Error())
-		return
-	}
-	mems, err := h.svc.ListMemories(r.Context(), actorInfo.ActorID, vaultObj.VaultID)
-	if err != nil {
-		respond.WriteInternalError(w, err.Error())
-		return
-	}
-	if mems == nil {
-		mems = []*model.Memory{}
+// recentEntriesTag extends a context ETag so it changes with the recent
+// entries returned next to the context; empty when there are none.
+func recentEntriesTag(recent []model.EntrySummary) string {
+	if len(recent) == 0 {
+		return ""
+	}
+	h := fnv.New64a()
+	for _, e := range recent {
+		_, _ = io.WriteString(h, e.EntryID)
+		_, _ = h.Write([]byte{0})
+	}
+	return fmt.Sprintf("-%d-%x", len(recent), h.Sum64())
+}
+
+// etagMatches reports whether an If-None-Match header value matches etag,
+// using weak comparison as RFC 9110 requires for If-None-Match.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
 	}
-	respond.WriteJSON(w, http.StatusOK, map[string]interface{}{"memories": mems, "count": len(mems)})
+	return false
 }
 
 // DeleteMemory DELETE /api/vaults/{vaultId}/memories/{memoryId}
@@ -543,6 +1148,9 @@ func (h *MemoryHandler) DeleteMemory(w http.ResponseWriter, r *http.Request) {
 
 	v := mux.Vars(r)
 	if err := h.svc.DeleteMemory(r.Context(), actorInfo.ActorID, v["vaultId"], v["memoryId"]); err != nil {
+		if writeReadOnlyError(w, err) {
+			return
+		}
 		respond.WriteInternalError(w, err.Error())
 		return
 	}
@@ -567,6 +1175,9 @@ func (h *MemoryHandler) DeleteMemoryEntryByID(w http.ResponseWriter, r *http.Req
 
 	v := mux.Vars(r)
 	if err := h.svc.DeleteEntry(r.Context(), actorInfo.ActorID, v["vaultId"], v["memoryId"], v["entryId"]); err != nil {
+		if writeReadOnlyError(w, err) {
+			return
+		}
 		respond.WriteInternalError(w, err.Error())
 		return
 	}
@@ -591,6 +1202,9 @@ func (h *MemoryHandler) DeleteMemoryContextByID(w http.ResponseWriter, r *http.R
 
 	v := mux.Vars(r)
 	if err := h.svc.DeleteContext(r.Context(), actorInfo.ActorID, v["vaultId"], v["memoryId"], v["contextId"]); err != nil {
+		if writeReadOnlyError(w, err) {
+			return
+		}
 		respond.WriteInternalError(w, err.Error())
 		return
 	}
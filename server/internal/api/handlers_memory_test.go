@@ -0,0 +1,441 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/services"
+	"github.com/mycelian/mycelian-memory/server/internal/store"
+)
+
+type memMemories struct{ store.Memories }
+
+func (memMemories) GetByID(_ context.Context, userID, vaultID, memoryID string) (*model.Memory, error) {
+	return &model.Memory{ActorID: userID, VaultID: vaultID, MemoryID: memoryID}, nil
+}
+
+type memContexts struct {
+	store.Contexts
+	latest *model.MemoryContext
+}
+
+func (c *memContexts) Latest(context.Context, string, string, string) (*model.MemoryContext, error) {
+	return c.latest, nil
+}
+
+// contextStore satisfies store.Store for the context read path.
+type contextStore struct {
+	store.Store
+	c *memContexts
+}
+
+func (contextStore) Vaults() store.Vaults {
+	return &memVaults{readOnly: map[string]bool{"v1": false}}
+}
+func (contextStore) Memories() store.Memories           { return memMemories{} }
+func (s contextStore) Contexts() store.Contexts         { return s.c }
+func (contextStore) EntityAliases() store.EntityAliases { return noAliases{} }
+
+// noAliases is an empty entity alias registry.
+type noAliases struct{ store.EntityAliases }
+
+func (noAliases) List(context.Context, string, string) ([]*model.EntityAlias, error) { return nil, nil }
+
+func TestGetLatestMemoryContext_ETag(t *testing.T) {
+	ctxs := &memContexts{latest: &model.MemoryContext{ContextID: "c1", Context: "hello"}}
+	st := contextStore{c: ctxs}
+	h := NewMemoryHandler(services.NewMemoryService(st, nil, nil), services.NewVaultService(st, nil), &mockAuthorizer{}, nil)
+	r := mux.NewRouter()
+	r.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/contexts", h.GetLatestMemoryContext).Methods("GET")
+
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v0/vaults/v1/memories/m1/contexts", nil)
+		req.Header.Set("Authorization", "Bearer test-api-key")
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("")
+	if w.Code != http.StatusOK || w.Body.String() != "hello" || w.Header().Get("ETag") != `"c1"` {
+		t.Fatalf("unconditional get: %d %q etag=%q", w.Code, w.Body.String(), w.Header().Get("ETag"))
+	}
+	for _, inm := range []string{`"c1"`, `W/"c1"`, `"x", "c1"`, `*`} {
+		if w := get(inm); w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+			t.Fatalf("If-None-Match %s: expected empty 304, got %d %q", inm, w.Code, w.Body.String())
+		}
+	}
+
+	ctxs.latest = &model.MemoryContext{ContextID: "c2", Context: "updated"}
+	if w := get(`"c1"`); w.Code != http.StatusOK || w.Body.String() != "updated" || w.Header().Get("ETag") != `"c2"` {
+		t.Fatalf("changed context: %d %q etag=%q", w.Code, w.Body.String(), w.Header().Get("ETag"))
+	}
+}
+
+// recentEntries lists its entries, newest first, up to the limit.
+type recentEntries struct {
+	store.Entries
+	entries []*model.MemoryEntry
+}
+
+func (e *recentEntries) List(_ context.Context, req model.ListEntriesRequest) ([]*model.MemoryEntry, error) {
+	return e.entries[:min(req.Limit, len(e.entries))], nil
+}
+
+type recentContextStore struct {
+	contextStore
+	e *recentEntries
+}
+
+func (s recentContextStore) Entries() store.Entries { return s.e }
+
+func TestGetLatestMemoryContext_RecentSummaries(t *testing.T) {
+	summary := "agreed to ship on Friday"
+	entries := &recentEntries{entries: []*model.MemoryEntry{
+		{EntryID: "e3", RawEntry: "long discussion", Summary: &summary},
+		{EntryID: "e2", RawEntry: strings.Repeat("x", 300)},
+		{EntryID: "e1", RawEntry: "oldest"},
+	}}
+	st := recentContextStore{contextStore{c: &memContexts{latest: &model.MemoryContext{ContextID: "c1", Context: "hello"}}}, entries}
+	h := NewMemoryHandler(services.NewMemoryService(st, nil, nil), services.NewVaultService(st, nil), &mockAuthorizer{}, nil)
+	r := mux.NewRouter()
+	r.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/contexts", h.GetLatestMemoryContext).Methods("GET")
+	get := func(query, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v0/vaults/v1/memories/m1/contexts"+query, nil)
+		req.Header.Set("Authorization", "Bearer test-api-key")
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("?includeRecentSummaries=2", "")
+	var got structuredContext
+	if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &got) != nil {
+		t.Fatalf("get: %d %s", w.Code, w.Body.String())
+	}
+	if got.Context != "hello" || len(got.RecentEntries) != 2 || got.RecentEntries[0].Summary != summary ||
+		got.RecentEntries[1].EntryID != "e2" || len([]rune(got.RecentEntries[1].Summary)) != 281 {
+		t.Fatalf("unexpected response: %+v", got)
+	}
+	etag := w.Header().Get("ETag")
+	if etag == `"c1"` {
+		t.Fatal("ETag must change with the recent entries")
+	}
+	if w := get("?includeRecentSummaries=2", etag); w.Code != http.StatusNotModified {
+		t.Fatalf("unchanged: expected 304, got %d", w.Code)
+	}
+	entries.entries = entries.entries[1:]
+	if w := get("?includeRecentSummaries=2", etag); w.Code != http.StatusOK {
+		t.Fatalf("entry removed: expected 200, got %d", w.Code)
+	}
+	for _, q := range []string{"?includeRecentSummaries=x", "?includeRecentSummaries=-1", "?includeRecentSummaries=51"} {
+		if w := get(q, ""); w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", q, w.Code)
+		}
+	}
+	if w := get("?includeRecentSummaries=0", ""); w.Body.String() != "hello" {
+		t.Fatalf("0 should keep the text response: %q", w.Body.String())
+	}
+}
+
+type memSignalEntries struct {
+	store.Entries
+	counts map[string]*model.EntrySignals
+}
+
+func (e *memSignalEntries) RecordSignal(_ context.Context, _, _, _, entryID, signal string) (*model.MemoryEntry, error) {
+	c, ok := e.counts[entryID]
+	if !ok {
+		return nil, model.ErrNotFound
+	}
+	if signal == model.SignalUseful {
+		c.UsefulCount++
+	}
+	return &model.MemoryEntry{EntryID: entryID, EntrySignals: *c}, nil
+}
+
+type signalHandlerStore struct {
+	contextStore
+	e *memSignalEntries
+}
+
+func (s signalHandlerStore) Entries() store.Entries { return s.e }
+
+func TestRecordEntrySignal(t *testing.T) {
+	st := signalHandlerStore{e: &memSignalEntries{counts: map[string]*model.EntrySignals{"e1": {}}}}
+	h := NewMemoryHandler(services.NewMemoryService(st, nil, nil), services.NewVaultService(st, nil), &mockAuthorizer{}, nil)
+	r := mux.NewRouter()
+	r.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/entries/{entryId}/signals", h.RecordEntrySignal).Methods("POST")
+
+	post := func(entryID, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v0/vaults/v1/memories/m1/entries/"+entryID+"/signals", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer test-api-key")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := post("e1", `{"signal":"useful"}`); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"usefulCount":1`) {
+		t.Fatalf("useful: %d %s", w.Code, w.Body.String())
+	}
+	if w := post("e1", `{"signal":"meh"}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("unknown signal: expected 400, got %d", w.Code)
+	}
+	if w := post("nope", `{"signal":"useful"}`); w.Code != http.StatusNotFound {
+		t.Fatalf("unknown entry: expected 404, got %d", w.Code)
+	}
+}
+
+type memSessionEntries struct {
+	store.Entries
+	listed model.ListEntriesRequest
+}
+
+func (e *memSessionEntries) Sessions(context.Context, string, string, string) ([]model.EntrySession, error) {
+	t := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	return []model.EntrySession{{SessionID: "s1", EntryCount: 2, FirstEntryTime: t, LastEntryTime: t.Add(time.Minute)}}, nil
+}
+
+func (e *memSessionEntries) List(_ context.Context, req model.ListEntriesRequest) ([]*model.MemoryEntry, error) {
+	e.listed = req
+	return []*model.MemoryEntry{{EntryID: "e1", SessionID: req.SessionID}}, nil
+}
+
+type sessionHandlerStore struct {
+	contextStore
+	e *memSessionEntries
+}
+
+func (s sessionHandlerStore) Entries() store.Entries { return s.e }
+
+func TestSessions(t *testing.T) {
+	st := sessionHandlerStore{e: &memSessionEntries{}}
+	h := NewMemoryHandler(services.NewMemoryService(st, nil, nil), services.NewVaultService(st, nil), &mockAuthorizer{}, nil)
+	r := mux.NewRouter()
+	r.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/entries", h.ListMemoryEntries).Methods("GET")
+	r.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/sessions", h.ListSessions).Methods("GET")
+	r.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/sessions/{sessionId}/entries", h.ListSessionEntries).Methods("GET")
+
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer test-api-key")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := get("/v0/vaults/v1/memories/m1/sessions"); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"sessionId":"s1","entryCount":2`) || !strings.Contains(w.Body.String(), `"count":1`) {
+		t.Fatalf("list sessions: %d %s", w.Code, w.Body.String())
+	}
+	if w := get("/v0/vaults/v1/memories/m1/sessions/s1/entries"); w.Code != http.StatusOK || st.e.listed.SessionID != "s1" || !st.e.listed.Ascending {
+		t.Fatalf("session entries: %d %+v", w.Code, st.e.listed)
+	}
+	if w := get("/v0/vaults/v1/memories/m1/entries?sessionId=s2"); w.Code != http.StatusOK || st.e.listed.SessionID != "s2" || st.e.listed.Ascending {
+		t.Fatalf("filtered list: %d %+v", w.Code, st.e.listed)
+	}
+	if w := get("/v0/vaults/v1/memories/m1/sessions/s1/entries?orderBy=conversationTime"); w.Code != http.StatusOK || st.e.listed.OrderBy != model.EntryOrderConversationTime || !st.e.listed.Ascending {
+		t.Fatalf("conversation order: %d %+v", w.Code, st.e.listed)
+	}
+	if w := get("/v0/vaults/v1/memories/m1/entries?tag=project=alpha&tag=done=true"); w.Code != http.StatusOK ||
+		len(st.e.listed.Tags) != 2 || st.e.listed.Tags["project"] != "alpha" || st.e.listed.Tags["done"] != "true" {
+		t.Fatalf("tag filter: %d %+v", w.Code, st.e.listed)
+	}
+	if w := get("/v0/vaults/v1/memories/m1/entries?tag=project"); w.Code != http.StatusBadRequest {
+		t.Fatalf("tag without value: expected 400, got %d", w.Code)
+	}
+	if w := get("/v0/vaults/v1/memories/m1/entries?orderBy=score"); w.Code != http.StatusBadRequest {
+		t.Fatalf("unknown orderBy: expected 400, got %d", w.Code)
+	}
+}
+
+func TestListMemoryEntriesPageToken(t *testing.T) {
+	st := sessionHandlerStore{e: &memSessionEntries{}}
+	h := NewMemoryHandler(services.NewMemoryService(st, nil, nil), services.NewVaultService(st, nil), &mockAuthorizer{}, nil)
+	r := mux.NewRouter()
+	r.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/entries", h.ListMemoryEntries).Methods("GET")
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer test-api-key")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("/v0/vaults/v1/memories/m1/entries?limit=1")
+	var page struct {
+		NextPageToken string `json:"nextPageToken"`
+	}
+	if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &page) != nil || page.NextPageToken == "" {
+		t.Fatalf("full page without token: %d %s", w.Code, w.Body.String())
+	}
+	if w := get("/v0/vaults/v1/memories/m1/entries?limit=1&pageToken=" + page.NextPageToken); w.Code != http.StatusOK || st.e.listed.Cursor == nil || st.e.listed.Cursor.EntryID != "e1" {
+		t.Fatalf("pageToken not applied: %d %+v", w.Code, st.e.listed)
+	}
+	if w := get("/v0/vaults/v1/memories/m1/entries?limit=2"); w.Code != http.StatusOK || strings.Contains(w.Body.String(), "nextPageToken") {
+		t.Fatalf("last page must not carry a token: %s", w.Body.String())
+	}
+	for _, q := range []string{"pageToken=bm90LWEtY3Vyc29y", "pageToken=" + page.NextPageToken + "&orderBy=conversationTime"} {
+		if w := get("/v0/vaults/v1/memories/m1/entries?limit=1&" + q); w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", q, w.Code)
+		}
+	}
+}
+
+func TestExportMemoryEntries(t *testing.T) {
+	st := sessionHandlerStore{e: &memSessionEntries{}}
+	h := NewMemoryHandler(services.NewMemoryService(st, nil, nil), services.NewVaultService(st, nil), &mockAuthorizer{}, nil)
+	r := mux.NewRouter()
+	r.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/export", h.ExportMemoryEntries).Methods("GET")
+
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer test-api-key")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("/v0/vaults/v1/memories/m1/export")
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/x-ndjson" || !st.e.listed.Ascending {
+		t.Fatalf("export: %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+	if lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n"); len(lines) != 1 || !strings.Contains(lines[0], `"entryId":"e1"`) {
+		t.Fatalf("unexpected body: %q", w.Body.String())
+	}
+	// The nil index cannot read vectors back.
+	if w := get("/v0/vaults/v1/memories/m1/export?embeddings=true"); w.Code != http.StatusNotImplemented {
+		t.Fatalf("embeddings without reader: expected 501, got %d", w.Code)
+	}
+	if w := get("/v0/vaults/v1/memories/m1/export?embeddings=maybe"); w.Code != http.StatusBadRequest {
+		t.Fatalf("bad flag: expected 400, got %d", w.Code)
+	}
+}
+
+type memScanEntries struct {
+	store.Entries
+	scanned model.ScanEntriesRequest
+}
+
+func (e *memScanEntries) Scan(_ context.Context, req model.ScanEntriesRequest) ([]*model.MemoryEntry, error) {
+	e.scanned = req
+	if req.Regex == "(" {
+		return nil, model.ErrValidation
+	}
+	t := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	out := []*model.MemoryEntry{{EntryID: "e2", CreationTime: t.Add(time.Minute)}, {EntryID: "e1", CreationTime: t}}
+	if len(out) > req.Limit {
+		out = out[:req.Limit]
+	}
+	return out, nil
+}
+
+type scanHandlerStore struct {
+	contextStore
+	e *memScanEntries
+}
+
+func (s scanHandlerStore) Entries() store.Entries { return s.e }
+
+func TestScanMemoryEntries(t *testing.T) {
+	st := scanHandlerStore{e: &memScanEntries{}}
+	h := NewMemoryHandler(services.NewMemoryService(st, nil, nil), services.NewVaultService(st, nil), &mockAuthorizer{}, nil)
+	r := mux.NewRouter()
+	r.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/entries:scan", h.ScanMemoryEntries).Methods("GET")
+
+	get := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v0/vaults/v1/memories/m1/entries:scan?"+query, nil)
+		req.Header.Set("Authorization", "Bearer test-api-key")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("contains=ERR-42&limit=1")
+	if w.Code != http.StatusOK || st.e.scanned.Contains != "ERR-42" || st.e.scanned.Limit != 1 {
+		t.Fatalf("scan: %d %s %+v", w.Code, w.Body.String(), st.e.scanned)
+	}
+	var page struct {
+		Count      int    `json:"count"`
+		NextCursor string `json:"nextCursor"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil || page.Count != 1 || page.NextCursor == "" {
+		t.Fatalf("unexpected page: %s", w.Body.String())
+	}
+	if w := get("contains=ERR-42&cursor=" + page.NextCursor); w.Code != http.StatusOK || st.e.scanned.After == nil ||
+		st.e.scanned.After.EntryID != "e2" || !st.e.scanned.After.CreationTime.Equal(time.Date(2025, 1, 1, 12, 1, 0, 0, time.UTC)) {
+		t.Fatalf("cursor not decoded: %d %+v", w.Code, st.e.scanned.After)
+	}
+	if w := get("contains=x"); strings.Contains(w.Body.String(), "nextCursor") || st.e.scanned.Limit != defaultScanLimit {
+		t.Fatalf("last page should have no cursor: %s", w.Body.String())
+	}
+	for _, q := range []string{"", "contains=x&limit=0", "contains=x&limit=501", "contains=x&cursor=bm90LWEtY3Vyc29y", "regex=("} {
+		if w := get(q); w.Code != http.StatusBadRequest {
+			t.Fatalf("%q: expected 400, got %d", q, w.Code)
+		}
+	}
+}
+
+type appendOnlyMemories struct {
+	memMemories
+	appendOnly map[string]bool
+}
+
+func (m *appendOnlyMemories) GetByID(_ context.Context, userID, vaultID, memoryID string) (*model.Memory, error) {
+	return &model.Memory{ActorID: userID, VaultID: vaultID, MemoryID: memoryID, AppendOnly: m.appendOnly[memoryID]}, nil
+}
+
+func (m *appendOnlyMemories) SetAppendOnly(ctx context.Context, userID, vaultID, memoryID string) (*model.Memory, error) {
+	m.appendOnly[memoryID] = true
+	return m.GetByID(ctx, userID, vaultID, memoryID)
+}
+
+type appendOnlyHandlerStore struct {
+	contextStore
+	m *appendOnlyMemories
+}
+
+func (s appendOnlyHandlerStore) Memories() store.Memories { return s.m }
+
+func TestSetMemoryAppendOnly(t *testing.T) {
+	st := appendOnlyHandlerStore{m: &appendOnlyMemories{appendOnly: map[string]bool{}}}
+	h := NewMemoryHandler(services.NewMemoryService(st, nil, nil), services.NewVaultService(st, nil), &mockAuthorizer{}, nil)
+	r := mux.NewRouter()
+	r.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/append-only", h.SetMemoryAppendOnly).Methods("PUT")
+	r.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/entries/{entryId}", h.DeleteMemoryEntryByID).Methods("DELETE")
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer test-api-key")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := do(http.MethodPut, "/v0/vaults/v1/memories/m1/append-only", `{}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("missing flag: expected 400, got %d", w.Code)
+	}
+	if w := do(http.MethodPut, "/v0/vaults/v1/memories/m1/append-only", `{"appendOnly":false}`); w.Code != http.StatusConflict {
+		t.Fatalf("clearing flag: expected 409, got %d", w.Code)
+	}
+	if w := do(http.MethodPut, "/v0/vaults/v1/memories/m1/append-only", `{"appendOnly":true}`); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"appendOnly":true`) {
+		t.Fatalf("set flag: %d %s", w.Code, w.Body.String())
+	}
+	// The entry store is never reached; the service rejects the delete first.
+	if w := do(http.MethodDelete, "/v0/vaults/v1/memories/m1/entries/e1", ""); w.Code != http.StatusConflict {
+		t.Fatalf("delete in append-only memory: expected 409, got %d %s", w.Code, w.Body.String())
+	}
+}
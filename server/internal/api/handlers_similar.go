@@ -0,0 +1,53 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	respond "github.com/mycelian/mycelian-memory/server/internal/api/respond"
+	"github.com/mycelian/mycelian-memory/server/internal/auth"
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/services"
+)
+
+// GetSimilarEntries GET /v0/vaults/{vaultId}/memories/{memoryId}/entries/{entryId}/similar[?topK=10&scope=memory|vault]
+// Lists the entries nearest to the entry's embedding in its memory, or in
+// every memory of the vault with scope=vault, most similar first.
+func (h *MemoryHandler) GetSimilarEntries(w http.ResponseWriter, r *http.Request) {
+	apiKey, err := auth.ExtractAPIKey(r)
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+	actorInfo, err := h.authorizer.Authorize(r.Context(), apiKey, "memory.read", "default")
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+
+	v := mux.Vars(r)
+	q := r.URL.Query()
+	topK := 0
+	if s := q.Get("topK"); s != "" {
+		if topK, err = strconv.Atoi(s); err != nil || topK <= 0 {
+			respond.WriteBadRequest(w, "topK must be a positive integer")
+			return
+		}
+	}
+	out, err := h.svc.SimilarEntries(r.Context(), actorInfo.ActorID, v["vaultId"], v["memoryId"], v["entryId"], q.Get("scope"), topK)
+	switch {
+	case err == nil:
+		respond.WriteJSON(w, http.StatusOK, out)
+	case errors.Is(err, model.ErrValidation):
+		respond.WriteBadRequest(w, err.Error())
+	case errors.Is(err, model.ErrNotFound):
+		respond.WriteNotFound(w, "entry not found")
+	case errors.Is(err, services.ErrSearchUnavailable):
+		respond.WriteError(w, http.StatusServiceUnavailable, err.Error())
+	default:
+		respond.WriteInternalError(w, err.Error())
+	}
+}
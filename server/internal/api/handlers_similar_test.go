@@ -0,0 +1,78 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/services"
+	"github.com/mycelian/mycelian-memory/server/internal/store"
+)
+
+type similarEntries struct{ store.Entries }
+
+func (similarEntries) GetByID(_ context.Context, _, _, memoryID, entryID string) (*model.MemoryEntry, error) {
+	if entryID != "e0" {
+		return nil, model.ErrNotFound
+	}
+	return &model.MemoryEntry{EntryID: entryID, MemoryID: memoryID, RawEntry: "deploys go through staging"}, nil
+}
+
+func (similarEntries) Expired(context.Context, string, []string, time.Time) (map[string]bool, error) {
+	return nil, nil
+}
+
+type similarStore struct{ store.Store }
+
+func (similarStore) Entries() store.Entries { return similarEntries{} }
+
+func TestGetSimilarEntries(t *testing.T) {
+	newRouter := func(svc *services.MemoryService) *mux.Router {
+		h := NewMemoryHandler(svc, nil, &mockAuthorizer{}, nil)
+		r := mux.NewRouter()
+		r.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/entries/{entryId}/similar", h.GetSimilarEntries).Methods("GET")
+		return r
+	}
+	get := func(r *mux.Router, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v0/vaults/v1/memories/m1/entries/"+path, nil)
+		req.Header.Set("Authorization", "Bearer test-api-key")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	emb := &mockEmbedder{}
+	r := newRouter(services.NewMemoryService(similarStore{}, &mockSearch{}, emb))
+	w := get(r, "e0/similar?topK=5")
+	if w.Code != http.StatusOK {
+		t.Fatalf("similar: %d %s", w.Code, w.Body.String())
+	}
+	var out model.SimilarEntries
+	if err := json.NewDecoder(w.Body).Decode(&out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if out.TopK != 5 || out.Scope != "memory" || out.VectorSource != "embedded" || out.Scoring != "index" || len(out.Entries) != 1 || emb.calls != 1 {
+		t.Fatalf("unexpected result: %+v", out)
+	}
+
+	for path, want := range map[string]int{
+		"e0/similar?topK=0":        http.StatusBadRequest,
+		"e0/similar?topK=500":      http.StatusBadRequest,
+		"e0/similar?scope=account": http.StatusBadRequest,
+		"missing/similar":          http.StatusNotFound,
+	} {
+		if w := get(r, path); w.Code != want {
+			t.Fatalf("%s: expected %d, got %d", path, want, w.Code)
+		}
+	}
+
+	if w := get(newRouter(services.NewMemoryService(similarStore{}, nil, nil)), "e0/similar"); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("no index: expected 503, got %d", w.Code)
+	}
+}
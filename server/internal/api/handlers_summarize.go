@@ -0,0 +1,65 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	respond "github.com/mycelian/mycelian-memory/server/internal/api/respond"
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/services"
+)
+
+// EnableSummarize serves POST .../summarize with svc.
+func (h *MemoryHandler) EnableSummarize(svc *services.SummarizeService) { h.summarize = svc }
+
+// SummarizeMemory POST /v0/vaults/{vaultId}/memories/{memoryId}/summarize
+// Body (optional): {"lastN": 20, "preview": false}
+// Regenerates the memory's context from its lastN newest entries and the
+// current context with the configured LLM. With preview the proposed
+// context is returned without saving; otherwise it becomes the latest
+// context and the response is 201.
+func (h *MemoryHandler) SummarizeMemory(w http.ResponseWriter, r *http.Request) {
+	actorID, vaultID, memoryID, ok := h.authorizedMemory(w, r, "memory.create")
+	if !ok {
+		return
+	}
+	if h.summarize == nil {
+		respond.WriteNotFound(w, "summarize is not enabled (set MEMORY_SERVER_SUMMARIZER_PROVIDER=ollama)")
+		return
+	}
+	var in struct {
+		LastN   int  `json:"lastN,omitempty"`
+		Preview bool `json:"preview,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil && !errors.Is(err, io.EOF) {
+		respond.WriteBadRequest(w, "Invalid JSON")
+		return
+	}
+	loc, err := requestLocation(r.Context(), r, h.actors, actorID)
+	if err != nil {
+		writeLocationError(w, err)
+		return
+	}
+	out, err := h.summarize.Summarize(r.Context(), services.SummarizeRequest{
+		ActorID: actorID, VaultID: vaultID, MemoryID: memoryID,
+		LastN: in.LastN, Preview: in.Preview, Location: loc,
+	})
+	if err != nil {
+		if writeReadOnlyError(w, err) {
+			return
+		}
+		if errors.Is(err, model.ErrValidation) {
+			respond.WriteBadRequest(w, err.Error())
+			return
+		}
+		respond.WriteInternalError(w, err.Error())
+		return
+	}
+	status := http.StatusOK
+	if out.Saved {
+		status = http.StatusCreated
+	}
+	respond.WriteJSON(w, status, out)
+}
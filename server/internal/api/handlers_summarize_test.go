@@ -0,0 +1,57 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/services"
+)
+
+type echoGenerator struct{}
+
+func (echoGenerator) Generate(context.Context, string) (string, error) { return "# Facts\n- x", nil }
+
+func (c *memContexts) LatestForMemories(_ context.Context, _ string, memoryIDs []string) (map[string]*model.MemoryContext, error) {
+	out := map[string]*model.MemoryContext{}
+	if c.latest != nil {
+		out[memoryIDs[0]] = c.latest
+	}
+	return out, nil
+}
+
+func TestSummarizeMemory(t *testing.T) {
+	st := sessionHandlerStore{contextStore: contextStore{c: &memContexts{}}, e: &memSessionEntries{}}
+	h := NewMemoryHandler(services.NewMemoryService(st, nil, nil), services.NewVaultService(st, nil), &mockAuthorizer{}, nil)
+	r := mux.NewRouter()
+	r.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/summarize", h.SummarizeMemory).Methods("POST")
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v0/vaults/v1/memories/m1/summarize", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer test-api-key")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := post(`{"preview":true}`); w.Code != http.StatusNotFound {
+		t.Fatalf("disabled: expected 404, got %d", w.Code)
+	}
+	h.EnableSummarize(services.NewSummarizeService(st, echoGenerator{}, 0))
+	w := post(`{"preview":true,"lastN":5}`)
+	var out services.SummarizeResult
+	if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &out) != nil || !out.Preview || out.Saved || out.Context != "# Facts\n- x" {
+		t.Fatalf("preview: %d %s", w.Code, w.Body.String())
+	}
+	if st.e.listed.Limit != 5 {
+		t.Fatalf("expected lastN as the list limit, got %d", st.e.listed.Limit)
+	}
+	if w := post(`{"lastN":-1}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("bad lastN: expected 400, got %d", w.Code)
+	}
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/services"
+	"github.com/mycelian/mycelian-memory/server/internal/store"
+)
+
+// refVaults holds one vault, v1 titled "Travel Notes"; looking up "broken"
+// fails as a store outage would.
+type refVaults struct{ store.Vaults }
+
+var travelVault = &model.Vault{VaultID: "v1", Title: "Travel Notes", Slug: "travel-notes"}
+
+func (refVaults) GetByID(_ context.Context, _, vaultID string) (*model.Vault, error) {
+	switch vaultID {
+	case travelVault.VaultID:
+		return travelVault, nil
+	case "broken":
+		return nil, errors.New("connection refused")
+	}
+	return nil, model.ErrNotFound
+}
+
+func (refVaults) GetByTitle(_ context.Context, _, title string) (*model.Vault, error) {
+	if title == travelVault.Title || model.Slug(title) == travelVault.Slug {
+		return travelVault, nil
+	}
+	return nil, model.ErrNotFound
+}
+
+// refMemories holds one memory, m1 titled "Trip Plan" in v1.
+type refMemories struct{ store.Memories }
+
+var tripMemory = &model.Memory{VaultID: "v1", MemoryID: "m1", Title: "Trip Plan", Slug: "trip-plan"}
+
+func (refMemories) GetByID(_ context.Context, _, vaultID, memoryID string) (*model.Memory, error) {
+	if vaultID == tripMemory.VaultID && memoryID == tripMemory.MemoryID {
+		return tripMemory, nil
+	}
+	return nil, model.ErrNotFound
+}
+
+func (refMemories) GetByTitle(_ context.Context, _, vaultID, title string) (*model.Memory, error) {
+	if vaultID == tripMemory.VaultID && (title == tripMemory.Title || model.Slug(title) == tripMemory.Slug) {
+		return tripMemory, nil
+	}
+	return nil, model.ErrNotFound
+}
+
+func (refMemories) List(_ context.Context, _, vaultID string) ([]*model.Memory, error) {
+	if vaultID != tripMemory.VaultID {
+		return nil, nil
+	}
+	return []*model.Memory{tripMemory}, nil
+}
+
+type refStore struct{ store.Store }
+
+func (refStore) Vaults() store.Vaults     { return refVaults{} }
+func (refStore) Memories() store.Memories { return refMemories{} }
+
+func TestTitleAddressedRoutes(t *testing.T) {
+	st := refStore{}
+	vs := services.NewVaultService(st, nil)
+	mh := NewMemoryHandler(services.NewMemoryService(st, nil, nil), vs, &mockAuthorizer{}, nil)
+	vh := NewVaultHandler(vs, &mockAuthorizer{})
+	r := mux.NewRouter()
+	r.HandleFunc("/v0/vaults/{vaultId}", vh.GetVault).Methods("GET")
+	r.HandleFunc("/v0/vaults/{vaultId}/memories", mh.ListMemories).Methods("GET")
+	r.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}", mh.GetMemory).Methods("GET")
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer test-api-key")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	for path, want := range map[string]string{
+		"/v0/vaults/v1":                                  `"vaultId":"v1"`,
+		"/v0/vaults/Travel%20Notes":                      `"vaultId":"v1"`,
+		"/v0/vaults/travel-notes/memories":               `"memoryId":"m1"`,
+		"/v0/vaults/v1/memories/m1":                      `"memoryId":"m1"`,
+		"/v0/vaults/Travel%20Notes/memories/Trip%20Plan": `"slug":"trip-plan"`,
+		"/v0/vaults/travel-notes/memories/trip-plan":     `"memoryId":"m1"`,
+	} {
+		if w := get(path); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), want) {
+			t.Fatalf("GET %s: %d %s", path, w.Code, w.Body.String())
+		}
+	}
+	for _, path := range []string{"/v0/vaults/nope", "/v0/vaults/nope/memories", "/v0/vaults/v1/memories/nope"} {
+		if w := get(path); w.Code != http.StatusNotFound {
+			t.Fatalf("GET %s: expected 404, got %d", path, w.Code)
+		}
+	}
+	for _, path := range []string{"/v0/vaults/broken", "/v0/vaults/broken/memories", "/v0/vaults/broken/memories/m1"} {
+		if w := get(path); w.Code != http.StatusInternalServerError {
+			t.Fatalf("GET %s: expected 500, got %d", path, w.Code)
+		}
+	}
+}
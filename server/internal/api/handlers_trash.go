@@ -0,0 +1,122 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	respond "github.com/mycelian/mycelian-memory/server/internal/api/respond"
+	"github.com/mycelian/mycelian-memory/server/internal/auth"
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+// With the trash enabled, deletes of memories and entries are soft: the
+// trashed rows stay hidden from reads and search until they are restored or
+// the purge job removes them after the retention period.
+
+// authorizedTrash authorizes the actor for scope on the vault in the path;
+// it writes the error response and returns false when that fails or the
+// trash is not enabled. Trashed memories are not found by GetMemory, so
+// these handlers cannot use authorizedMemory.
+func (h *MemoryHandler) authorizedTrash(w http.ResponseWriter, r *http.Request, scope string) (actorID string, ok bool) {
+	apiKey, err := auth.ExtractAPIKey(r)
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return "", false
+	}
+	actorInfo, err := h.authorizer.Authorize(r.Context(), apiKey, scope, "default")
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return "", false
+	}
+	if !h.svc.TrashEnabled() {
+		respond.WriteNotFound(w, "trash is not enabled (set MEMORY_SERVER_TRASH_RETENTION_DAYS)")
+		return "", false
+	}
+	if h.vaultSv != nil {
+		if _, err := h.vaultSv.GetVault(r.Context(), actorInfo.ActorID, mux.Vars(r)["vaultId"]); err != nil {
+			respond.WriteNotFound(w, "vault not found")
+			return "", false
+		}
+	}
+	return actorInfo.ActorID, true
+}
+
+// writeRestoreError maps restore errors to HTTP responses.
+func writeRestoreError(w http.ResponseWriter, err error, notFound string) {
+	if writeReadOnlyError(w, err) {
+		return
+	}
+	if errors.Is(err, model.ErrNotFound) {
+		respond.WriteNotFound(w, notFound)
+		return
+	}
+	if errors.Is(err, model.ErrConflict) {
+		respond.WriteError(w, http.StatusConflict, err.Error())
+		return
+	}
+	respond.WriteInternalError(w, err.Error())
+}
+
+// ListTrash GET /v0/vaults/{vaultId}/trash
+// Responds {"memories": [...], "entries": [...]}, most recently deleted
+// first, each with its deletionTime. Entries of a trashed memory are not
+// listed; they come back when the memory is restored.
+func (h *MemoryHandler) ListTrash(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := h.authorizedTrash(w, r, "memory.read")
+	if !ok {
+		return
+	}
+	loc, err := requestLocation(r.Context(), r, h.actors, actorID)
+	if err != nil {
+		writeLocationError(w, err)
+		return
+	}
+	out, err := h.svc.ListTrash(r.Context(), actorID, mux.Vars(r)["vaultId"])
+	if err != nil {
+		respond.WriteInternalError(w, err.Error())
+		return
+	}
+	entriesIn(out.Entries, loc)
+	respond.WriteJSON(w, http.StatusOK, out)
+}
+
+// RestoreMemory POST /v0/vaults/{vaultId}/memories/{memoryId}:restore
+// Takes the memory out of the trash and responds with it.
+func (h *MemoryHandler) RestoreMemory(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := h.authorizedTrash(w, r, "memory.write")
+	if !ok {
+		return
+	}
+	v := mux.Vars(r)
+	out, err := h.svc.RestoreMemory(r.Context(), actorID, v["vaultId"], v["memoryId"])
+	if err != nil {
+		writeRestoreError(w, err, "memory not in trash")
+		return
+	}
+	respond.WriteJSON(w, http.StatusOK, out)
+}
+
+// RestoreMemoryEntry POST /v0/vaults/{vaultId}/memories/{memoryId}/entries/{entryId}:restore
+// Takes the entry out of the trash and responds with it. An entry of a
+// trashed memory is restored with the memory instead.
+func (h *MemoryHandler) RestoreMemoryEntry(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := h.authorizedTrash(w, r, "memory.write")
+	if !ok {
+		return
+	}
+	loc, err := requestLocation(r.Context(), r, h.actors, actorID)
+	if err != nil {
+		writeLocationError(w, err)
+		return
+	}
+	v := mux.Vars(r)
+	out, err := h.svc.RestoreEntry(r.Context(), actorID, v["vaultId"], v["memoryId"], v["entryId"])
+	if err != nil {
+		writeRestoreError(w, err, "entry not in trash")
+		return
+	}
+	entriesIn([]*model.MemoryEntry{out}, loc)
+	respond.WriteJSON(w, http.StatusOK, out)
+}
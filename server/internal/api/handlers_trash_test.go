@@ -0,0 +1,101 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/services"
+	"github.com/mycelian/mycelian-memory/server/internal/store"
+)
+
+type trashedEntries struct {
+	store.Entries
+	trashed map[string]bool
+}
+
+func (e *trashedEntries) Restore(_ context.Context, userID, vaultID, memoryID, entryID string) (*model.MemoryEntry, error) {
+	if !e.trashed[entryID] {
+		return nil, model.ErrNotFound
+	}
+	delete(e.trashed, entryID)
+	return &model.MemoryEntry{ActorID: userID, VaultID: vaultID, MemoryID: memoryID, EntryID: entryID}, nil
+}
+
+func (e *trashedEntries) ListTrash(_ context.Context, _, _ string, _ int) ([]*model.MemoryEntry, error) {
+	var out []*model.MemoryEntry
+	deleted := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	for id := range e.trashed {
+		out = append(out, &model.MemoryEntry{EntryID: id, DeletionTime: &deleted})
+	}
+	return out, nil
+}
+
+type trashedMemories struct{ memMemories }
+
+func (trashedMemories) ListTrash(context.Context, string, string) ([]*model.Memory, error) {
+	return nil, nil
+}
+
+func (trashedMemories) Restore(context.Context, string, string, string) (*model.Memory, error) {
+	return nil, fmt.Errorf("%w: memory title \"notes\" already exists", model.ErrConflict)
+}
+
+type trashStore struct {
+	store.Store
+	e *trashedEntries
+}
+
+func (trashStore) Vaults() store.Vaults {
+	return &memVaults{readOnly: map[string]bool{"v1": false, "ro": true}}
+}
+func (trashStore) Memories() store.Memories { return trashedMemories{} }
+func (s trashStore) Entries() store.Entries { return s.e }
+
+func TestTrashHandlers(t *testing.T) {
+	es := &trashedEntries{trashed: map[string]bool{"e1": true}}
+	st := trashStore{e: es}
+	svc := services.NewMemoryService(st, nil, nil)
+	h := NewMemoryHandler(svc, services.NewVaultService(st, nil), &mockAuthorizer{}, nil)
+	r := mux.NewRouter()
+	r.HandleFunc("/v0/vaults/{vaultId}/trash", h.ListTrash).Methods("GET")
+	r.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/entries/{entryId}:restore", h.RestoreMemoryEntry).Methods("POST")
+	r.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}:restore", h.RestoreMemory).Methods("POST")
+	do := func(method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer test-api-key")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := do(http.MethodGet, "/v0/vaults/v1/trash"); w.Code != http.StatusNotFound {
+		t.Fatalf("trash disabled: expected 404, got %d", w.Code)
+	}
+	svc.EnableTrash()
+
+	w := do(http.MethodGet, "/v0/vaults/v1/trash")
+	var trash model.Trash
+	if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &trash) != nil || len(trash.Entries) != 1 || trash.Entries[0].DeletionTime == nil || trash.Memories == nil {
+		t.Fatalf("list trash: %d %s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodPost, "/v0/vaults/ro/memories/m1/entries/e1:restore"); w.Code != http.StatusConflict {
+		t.Fatalf("read-only vault: expected 409, got %d", w.Code)
+	}
+	if w := do(http.MethodPost, "/v0/vaults/v1/memories/m1/entries/e1:restore"); w.Code != http.StatusOK {
+		t.Fatalf("restore: %d %s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodPost, "/v0/vaults/v1/memories/m1/entries/e1:restore"); w.Code != http.StatusNotFound {
+		t.Fatalf("restore of an entry not in the trash: expected 404, got %d", w.Code)
+	}
+	if w := do(http.MethodPost, "/v0/vaults/v1/memories/m1:restore"); w.Code != http.StatusConflict {
+		t.Fatalf("restore of a memory whose title is taken: expected 409, got %d", w.Code)
+	}
+}
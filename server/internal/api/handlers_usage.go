@@ -0,0 +1,49 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	respond "github.com/mycelian/mycelian-memory/server/internal/api/respond"
+	"github.com/mycelian/mycelian-memory/server/internal/auth"
+)
+
+// GetUsage handles GET /v0/usage?memoryId=&since=&tz=
+// It sums the language model usage reported with the actor's entries per
+// memory and per model. since is RFC3339, a date, "today" or "yesterday"
+// (dates resolve in tz or the actor's zone); all filters are optional.
+func (h *MemoryHandler) GetUsage(w http.ResponseWriter, r *http.Request) {
+	apiKey, err := auth.ExtractAPIKey(r)
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+	actorInfo, err := h.authorizer.Authorize(r.Context(), apiKey, "memory.read", "default")
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+
+	q := r.URL.Query()
+	var since *time.Time
+	if v := q.Get("since"); v != "" {
+		loc, err := requestLocation(r.Context(), r, h.actors, actorInfo.ActorID)
+		if err != nil {
+			writeLocationError(w, err)
+			return
+		}
+		t, err := parseTimeParam(v, loc, time.Now())
+		if err != nil {
+			respond.WriteBadRequest(w, "invalid since; expected RFC3339, YYYY-MM-DD, today or yesterday")
+			return
+		}
+		since = &t
+	}
+
+	out, err := h.svc.UsageReport(r.Context(), actorInfo.ActorID, q.Get("memoryId"), since)
+	if err != nil {
+		respond.WriteInternalError(w, err.Error())
+		return
+	}
+	respond.WriteJSON(w, http.StatusOK, out)
+}
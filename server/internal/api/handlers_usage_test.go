@@ -0,0 +1,62 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/services"
+	"github.com/mycelian/mycelian-memory/server/internal/store"
+)
+
+type usageEntries struct {
+	store.Entries
+	memoryID string
+	since    *time.Time
+}
+
+func (e *usageEntries) Usage(_ context.Context, _, memoryID string, since *time.Time) ([]model.UsageTotals, error) {
+	e.memoryID, e.since = memoryID, since
+	return []model.UsageTotals{
+		{MemoryID: "m1", Model: "small", Entries: 2, InputTokens: 200, OutputTokens: 20, CostUSD: 0.02},
+		{MemoryID: "m1", Model: "large", Entries: 1, InputTokens: 50, OutputTokens: 50, CostUSD: 0.1},
+	}, nil
+}
+
+type usageStore struct {
+	store.Store
+	e *usageEntries
+}
+
+func (s usageStore) Entries() store.Entries { return s.e }
+
+func TestGetUsage(t *testing.T) {
+	st := usageStore{e: &usageEntries{}}
+	h := NewMemoryHandler(services.NewMemoryService(st, nil, nil), nil, &mockAuthorizer{}, nil)
+	get := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v0/usage"+query, nil)
+		req.Header.Set("Authorization", "Bearer test-api-key")
+		w := httptest.NewRecorder()
+		h.GetUsage(w, req)
+		return w
+	}
+
+	w := get("?memoryId=m1&since=2025-01-01T00:00:00Z")
+	var out model.UsageReport
+	if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &out) != nil {
+		t.Fatalf("get usage: %d %s", w.Code, w.Body.String())
+	}
+	if st.e.memoryID != "m1" || st.e.since == nil || !st.e.since.Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("filters not passed: memoryId=%q since=%v", st.e.memoryID, st.e.since)
+	}
+	if out.Total.Entries != 3 || out.Total.InputTokens != 250 || len(out.ByModel) != 2 || out.ByModel[0].Model != "large" || len(out.ByMemory) != 1 {
+		t.Fatalf("unexpected report: %+v", out)
+	}
+	if w := get("?since=soon"); w.Code != http.StatusBadRequest {
+		t.Fatalf("bad since: expected 400, got %d", w.Code)
+	}
+}
@@ -2,7 +2,10 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"sort"
 
 	"github.com/gorilla/mux"
 
@@ -16,10 +19,35 @@ import (
 type VaultHandler struct {
 	svc        *services.VaultService
 	authorizer auth.Authorizer
+	templates  map[string]model.VaultTemplate
 }
 
 func NewVaultHandler(svc *services.VaultService, authorizer auth.Authorizer) *VaultHandler {
-	return &VaultHandler{svc: svc, authorizer: authorizer}
+	return &VaultHandler{svc: svc, authorizer: authorizer, templates: services.DefaultVaultTemplates()}
+}
+
+// EnableVaultTemplates replaces the built-in template registry, e.g. with
+// services.LoadVaultTemplates. Templates are checked against the same rules
+// as memory creation so a bad operator file fails at startup.
+func (h *VaultHandler) EnableVaultTemplates(templates map[string]model.VaultTemplate) error {
+	for name, t := range templates {
+		if len(t.Memories) == 0 {
+			return fmt.Errorf("vault template %s has no memories", name)
+		}
+		seen := make(map[string]bool, len(t.Memories))
+		for _, m := range t.Memories {
+			desc := m.Description
+			if err := CreateMemory(m.MemoryType, m.Title, &desc); err != nil {
+				return fmt.Errorf("vault template %s, memory %q: %w", name, m.Title, err)
+			}
+			if seen[m.Title] {
+				return fmt.Errorf("vault template %s lists memory %s twice", name, m.Title)
+			}
+			seen[m.Title] = true
+		}
+	}
+	h.templates = templates
+	return nil
 }
 
 // CreateVault POST /api/vaults
@@ -54,6 +82,115 @@ func (h *VaultHandler) CreateVault(w http.ResponseWriter, r *http.Request) {
 	respond.WriteJSON(w, http.StatusCreated, out)
 }
 
+// CreateVaultFromTemplate POST /v0/vaults:fromTemplate
+// Body: {"title": "...", "template": "project"}. Creates the vault with the
+// template's memories and starting contexts in one call.
+func (h *VaultHandler) CreateVaultFromTemplate(w http.ResponseWriter, r *http.Request) {
+	apiKey, err := auth.ExtractAPIKey(r)
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+
+	actorInfo, err := h.authorizer.Authorize(r.Context(), apiKey, "vault.create", "default")
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+
+	var req struct {
+		Title    string `json:"title"`
+		Template string `json:"template"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respond.WriteBadRequest(w, "Invalid JSON")
+		return
+	}
+	if err := Title(req.Title); err != nil {
+		respond.WriteBadRequest(w, err.Error())
+		return
+	}
+	tpl, ok := h.templates[req.Template]
+	if !ok {
+		respond.WriteBadRequest(w, fmt.Sprintf("unknown template %q", req.Template))
+		return
+	}
+
+	v := &model.Vault{ActorID: actorInfo.ActorID, Title: req.Title}
+	out, err := h.svc.CreateVaultFromTemplate(r.Context(), v, tpl)
+	if err != nil {
+		respond.WriteInternalError(w, err.Error())
+		return
+	}
+	respond.WriteJSON(w, http.StatusCreated, out)
+}
+
+// CloneVault POST /v0/vaults/{vaultId}:clone
+// Body: {"title": "...", "reindex": true}. Copies the vault's memories,
+// entries and latest contexts into a new vault with fresh IDs; reindex
+// enqueues index upserts of the copies.
+func (h *VaultHandler) CloneVault(w http.ResponseWriter, r *http.Request) {
+	apiKey, err := auth.ExtractAPIKey(r)
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+
+	actorInfo, err := h.authorizer.Authorize(r.Context(), apiKey, "vault.create", "default")
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+
+	var req struct {
+		Title   string `json:"title"`
+		Reindex bool   `json:"reindex"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respond.WriteBadRequest(w, "Invalid JSON")
+		return
+	}
+	if err := Title(req.Title); err != nil {
+		respond.WriteBadRequest(w, err.Error())
+		return
+	}
+
+	out, err := h.svc.CloneVault(r.Context(), model.VaultClone{
+		ActorID: actorInfo.ActorID, SourceVaultID: mux.Vars(r)["vaultId"], Title: req.Title, Reindex: req.Reindex,
+	})
+	switch {
+	case err == nil:
+		respond.WriteJSON(w, http.StatusCreated, out)
+	case errors.Is(err, model.ErrNotFound):
+		respond.WriteNotFound(w, "vault not found")
+	case errors.Is(err, model.ErrConflict):
+		respond.WriteError(w, http.StatusConflict, err.Error())
+	default:
+		respond.WriteInternalError(w, err.Error())
+	}
+}
+
+// ListVaultTemplates GET /v0/vault-templates
+func (h *VaultHandler) ListVaultTemplates(w http.ResponseWriter, r *http.Request) {
+	apiKey, err := auth.ExtractAPIKey(r)
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+
+	if _, err := h.authorizer.Authorize(r.Context(), apiKey, "vault.read", "default"); err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+
+	out := make([]model.VaultTemplate, 0, len(h.templates))
+	for _, t := range h.templates {
+		out = append(out, t)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	respond.WriteJSON(w, http.StatusOK, map[string]interface{}{"templates": out, "count": len(out)})
+}
+
 // ListVaults GET /api/vaults
 func (h *VaultHandler) ListVaults(w http.ResponseWriter, r *http.Request) {
 	// Extract API key from Authorization header
@@ -79,6 +216,7 @@ func (h *VaultHandler) ListVaults(w http.ResponseWriter, r *http.Request) {
 }
 
 // GetVault GET /api/vaults/{vaultId}
+// {vaultId} may also be the vault's title or slug.
 func (h *VaultHandler) GetVault(w http.ResponseWriter, r *http.Request) {
 	// Extract API key from Authorization header
 	apiKey, err := auth.ExtractAPIKey(r)
@@ -95,14 +233,44 @@ func (h *VaultHandler) GetVault(w http.ResponseWriter, r *http.Request) {
 	}
 
 	vars := mux.Vars(r)
-	v, err := h.svc.GetVault(r.Context(), actorInfo.ActorID, vars["vaultId"])
+	v, err := h.svc.ResolveVault(r.Context(), actorInfo.ActorID, vars["vaultId"])
 	if err != nil {
-		respond.WriteNotFound(w, err.Error())
+		writeResolveError(w, err, "vault not found")
 		return
 	}
 	respond.WriteJSON(w, http.StatusOK, v)
 }
 
+// GetVaultStats GET /v0/vaults/{vaultId}/stats
+// Per-memory entry and context counts in Postgres next to the search index's
+// object counts; inSync is false while any memory has an indexing gap.
+func (h *VaultHandler) GetVaultStats(w http.ResponseWriter, r *http.Request) {
+	// Extract API key from Authorization header
+	apiKey, err := auth.ExtractAPIKey(r)
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+
+	// Authorize the request
+	actorInfo, err := h.authorizer.Authorize(r.Context(), apiKey, "vault.read", "default")
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+
+	stats, err := h.svc.VaultStats(r.Context(), actorInfo.ActorID, mux.Vars(r)["vaultId"])
+	if errors.Is(err, model.ErrNotFound) {
+		respond.WriteNotFound(w, "vault not found")
+		return
+	}
+	if err != nil {
+		respond.WriteInternalError(w, err.Error())
+		return
+	}
+	respond.WriteJSON(w, http.StatusOK, stats)
+}
+
 // DeleteVault DELETE /api/vaults/{vaultId}
 func (h *VaultHandler) DeleteVault(w http.ResponseWriter, r *http.Request) {
 	// Extract API key from Authorization header
@@ -121,6 +289,9 @@ func (h *VaultHandler) DeleteVault(w http.ResponseWriter, r *http.Request) {
 
 	vars := mux.Vars(r)
 	if err := h.svc.DeleteVault(r.Context(), actorInfo.ActorID, vars["vaultId"]); err != nil {
+		if writeReadOnlyError(w, err) {
+			return
+		}
 		respond.WriteInternalError(w, err.Error())
 		return
 	}
@@ -145,8 +316,107 @@ func (h *VaultHandler) AttachMemoryToVault(w http.ResponseWriter, r *http.Reques
 
 	vars := mux.Vars(r)
 	if err := h.svc.AddMemoryToVault(r.Context(), actorInfo.ActorID, vars["vaultId"], vars["memoryId"]); err != nil {
+		if writeReadOnlyError(w, err) {
+			return
+		}
 		respond.WriteInternalError(w, err.Error())
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// SetVaultReadOnly PUT /api/vaults/{vaultId}/read-only
+// Body: {"readOnly": true|false}. While set, every write to the vault and its
+// memories, entries and contexts fails with 409.
+func (h *VaultHandler) SetVaultReadOnly(w http.ResponseWriter, r *http.Request) {
+	apiKey, err := auth.ExtractAPIKey(r)
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+
+	actorInfo, err := h.authorizer.Authorize(r.Context(), apiKey, "vault.update", "default")
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+
+	var req struct {
+		ReadOnly *bool `json:"readOnly"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respond.WriteBadRequest(w, "Invalid JSON")
+		return
+	}
+	if req.ReadOnly == nil {
+		respond.WriteBadRequest(w, "readOnly is required")
+		return
+	}
+
+	out, err := h.svc.SetVaultReadOnly(r.Context(), actorInfo.ActorID, mux.Vars(r)["vaultId"], *req.ReadOnly)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			respond.WriteNotFound(w, "vault not found")
+			return
+		}
+		respond.WriteInternalError(w, err.Error())
+		return
+	}
+	respond.WriteJSON(w, http.StatusOK, out)
+}
+
+// UpdateVault PATCH /v0/vaults/{vaultId}
+// Body: {"title": "...", "description": "..."}; omitted fields are unchanged.
+func (h *VaultHandler) UpdateVault(w http.ResponseWriter, r *http.Request) {
+	apiKey, err := auth.ExtractAPIKey(r)
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+
+	actorInfo, err := h.authorizer.Authorize(r.Context(), apiKey, "vault.update", "default")
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+
+	var req model.TitleUpdate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respond.WriteBadRequest(w, "Invalid JSON")
+		return
+	}
+	if err := TitleUpdate(req); err != nil {
+		respond.WriteBadRequest(w, err.Error())
+		return
+	}
+
+	out, err := h.svc.UpdateVault(r.Context(), actorInfo.ActorID, mux.Vars(r)["vaultId"], req)
+	if err != nil {
+		writeTitleUpdateError(w, err, "vault not found")
+		return
+	}
+	respond.WriteJSON(w, http.StatusOK, out)
+}
+
+// writeTitleUpdateError maps vault and memory update errors to responses.
+func writeTitleUpdateError(w http.ResponseWriter, err error, notFound string) {
+	switch {
+	case errors.Is(err, model.ErrNotFound):
+		respond.WriteNotFound(w, notFound)
+	case writeReadOnlyError(w, err):
+	case errors.Is(err, model.ErrConflict):
+		respond.WriteError(w, http.StatusConflict, err.Error())
+	default:
+		respond.WriteInternalError(w, err.Error())
+	}
+}
+
+// writeReadOnlyError writes 409 Conflict when err is model.ErrReadOnly or
+// model.ErrAppendOnly and reports whether it did.
+func writeReadOnlyError(w http.ResponseWriter, err error) bool {
+	if !errors.Is(err, model.ErrReadOnly) && !errors.Is(err, model.ErrAppendOnly) {
+		return false
+	}
+	respond.WriteError(w, http.StatusConflict, err.Error())
+	return true
+}
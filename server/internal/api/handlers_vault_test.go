@@ -0,0 +1,239 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/services"
+	"github.com/mycelian/mycelian-memory/server/internal/store"
+)
+
+// memVaults is an in-memory store.Vaults; only the methods used below are implemented.
+type memVaults struct {
+	store.Vaults
+	readOnly map[string]bool
+}
+
+func (m *memVaults) GetByID(_ context.Context, userID, vaultID string) (*model.Vault, error) {
+	ro, ok := m.readOnly[vaultID]
+	if !ok {
+		return nil, model.ErrNotFound
+	}
+	return &model.Vault{ActorID: userID, VaultID: vaultID, ReadOnly: ro}, nil
+}
+func (m *memVaults) SetReadOnly(ctx context.Context, userID, vaultID string, readOnly bool) (*model.Vault, error) {
+	if _, ok := m.readOnly[vaultID]; !ok {
+		return nil, model.ErrNotFound
+	}
+	m.readOnly[vaultID] = readOnly
+	return m.GetByID(ctx, userID, vaultID)
+}
+
+func (m *memVaults) Update(ctx context.Context, userID, vaultID string, u model.TitleUpdate) (*model.Vault, error) {
+	if _, ok := m.readOnly[vaultID]; !ok {
+		return nil, model.ErrNotFound
+	}
+	if u.Title != nil && *u.Title == "taken" {
+		return nil, model.ErrConflict
+	}
+	v, _ := m.GetByID(ctx, userID, vaultID)
+	if u.Title != nil {
+		v.Title = *u.Title
+	}
+	v.Description = u.Description
+	return v, nil
+}
+
+func (m *memVaults) MemoryStats(context.Context, string, string) ([]model.MemoryStats, error) {
+	return []model.MemoryStats{{MemoryID: "m1", Title: "notes", Postgres: model.ObjectCounts{Entries: 2}}}, nil
+}
+
+func (m *memVaults) Clone(_ context.Context, c model.VaultClone) (*model.ClonedVault, error) {
+	if _, ok := m.readOnly[c.SourceVaultID]; !ok {
+		return nil, model.ErrNotFound
+	}
+	if c.Title == "taken" {
+		return nil, model.ErrConflict
+	}
+	return &model.ClonedVault{
+		Vault:         model.Vault{ActorID: c.ActorID, VaultID: "v2", Title: c.Title},
+		SourceVaultID: c.SourceVaultID,
+		Reindex:       c.Reindex,
+	}, nil
+}
+
+// vaultOnlyStore satisfies store.Store; only Vaults is used by these tests.
+type vaultOnlyStore struct {
+	store.Store
+	v store.Vaults
+}
+
+func (s vaultOnlyStore) Vaults() store.Vaults { return s.v }
+
+func TestSetVaultReadOnly_BlocksWrites(t *testing.T) {
+	st := vaultOnlyStore{v: &memVaults{readOnly: map[string]bool{"v1": false}}}
+	vh := NewVaultHandler(services.NewVaultService(st, nil), &mockAuthorizer{})
+	mh := NewMemoryHandler(services.NewMemoryService(st, nil, nil), services.NewVaultService(st, nil), &mockAuthorizer{}, nil)
+	r := mux.NewRouter()
+	r.HandleFunc("/v0/vaults/{vaultId}/read-only", vh.SetVaultReadOnly).Methods("PUT")
+	r.HandleFunc("/v0/vaults/{vaultId}", vh.DeleteVault).Methods("DELETE")
+	r.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/entries/{entryId}", mh.DeleteMemoryEntryByID).Methods("DELETE")
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer test-api-key")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := do("PUT", "/v0/vaults/v1/read-only", `{}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("missing readOnly: expected 400, got %d", w.Code)
+	}
+	if w := do("PUT", "/v0/vaults/nope/read-only", `{"readOnly":true}`); w.Code != http.StatusNotFound {
+		t.Fatalf("unknown vault: expected 404, got %d", w.Code)
+	}
+	w := do("PUT", "/v0/vaults/v1/read-only", `{"readOnly":true}`)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"readOnly":true`) {
+		t.Fatalf("set read-only: %d %s", w.Code, w.Body.String())
+	}
+
+	for _, path := range []string{"/v0/vaults/v1", "/v0/vaults/v1/memories/m1/entries/e1"} {
+		w := do("DELETE", path, "")
+		if w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), "read-only") {
+			t.Fatalf("DELETE %s on read-only vault: expected 409, got %d %s", path, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestUpdateVault(t *testing.T) {
+	st := vaultOnlyStore{v: &memVaults{readOnly: map[string]bool{"v1": false, "frozen": true}}}
+	vh := NewVaultHandler(services.NewVaultService(st, nil), &mockAuthorizer{})
+	r := mux.NewRouter()
+	r.HandleFunc("/v0/vaults/{vaultId}", vh.UpdateVault).Methods("PATCH")
+
+	patch := func(path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("PATCH", path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer test-api-key")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	w := patch("/v0/vaults/v1", `{"title":"renamed","description":"notes for acme"}`)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"title":"renamed"`) || !strings.Contains(w.Body.String(), `"description":"notes for acme"`) {
+		t.Fatalf("update: %d %s", w.Code, w.Body.String())
+	}
+	for body, want := range map[string]int{
+		`{}`:                    http.StatusBadRequest,
+		`{"title":"has/slash"}`: http.StatusBadRequest,
+		`{"title":"taken"}`:     http.StatusConflict,
+		`{"description":"` + strings.Repeat("x", 501) + `"}`: http.StatusBadRequest,
+	} {
+		if w := patch("/v0/vaults/v1", body); w.Code != want {
+			t.Fatalf("PATCH %.40s: expected %d, got %d %s", body, want, w.Code, w.Body.String())
+		}
+	}
+	if w := patch("/v0/vaults/nope", `{"title":"x"}`); w.Code != http.StatusNotFound {
+		t.Fatalf("unknown vault: expected 404, got %d", w.Code)
+	}
+	if w := patch("/v0/vaults/frozen", `{"title":"x"}`); w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), "read-only") {
+		t.Fatalf("read-only vault: expected 409, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestGetVaultStats(t *testing.T) {
+	st := vaultOnlyStore{v: &memVaults{readOnly: map[string]bool{"v1": false}}}
+	vh := NewVaultHandler(services.NewVaultService(st, nil), &mockAuthorizer{})
+	r := mux.NewRouter()
+	r.HandleFunc("/v0/vaults/{vaultId}/stats", vh.GetVaultStats).Methods("GET")
+
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Authorization", "Bearer test-api-key")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	w := get("/v0/vaults/v1/stats")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"postgres":{"entries":2,"contexts":0}`) || !strings.Contains(w.Body.String(), `"inSync":false`) {
+		t.Fatalf("stats: %d %s", w.Code, w.Body.String())
+	}
+	if w := get("/v0/vaults/nope/stats"); w.Code != http.StatusNotFound {
+		t.Fatalf("unknown vault: expected 404, got %d", w.Code)
+	}
+}
+
+func TestEnableVaultTemplatesValidates(t *testing.T) {
+	vh := NewVaultHandler(services.NewVaultService(vaultOnlyStore{}, nil), &mockAuthorizer{})
+	if err := vh.EnableVaultTemplates(services.DefaultVaultTemplates()); err != nil {
+		t.Fatalf("built-in templates rejected: %v", err)
+	}
+	bad := map[string]model.VaultTemplate{
+		"bad-title": {Name: "bad-title", Memories: []model.TemplateMemory{{Title: "project/context", MemoryType: "project"}}},
+	}
+	if err := vh.EnableVaultTemplates(bad); err == nil {
+		t.Fatalf("expected error for invalid memory title")
+	}
+	dup := map[string]model.VaultTemplate{
+		"dup": {Name: "dup", Memories: []model.TemplateMemory{{Title: "notes", MemoryType: "n"}, {Title: "notes", MemoryType: "n"}}},
+	}
+	if err := vh.EnableVaultTemplates(dup); err == nil {
+		t.Fatalf("expected error for duplicate memory title")
+	}
+}
+
+func TestCreateVaultFromTemplate_RejectsBadRequests(t *testing.T) {
+	vh := NewVaultHandler(services.NewVaultService(vaultOnlyStore{}, nil), &mockAuthorizer{})
+	for body, want := range map[string]string{
+		`{"title":"acme","template":"nope"}`:   "unknown template",
+		`{"title":"a/b","template":"project"}`: "invalid characters",
+		`{"template":"project"}`:               "title is required",
+		`{"title":"acme","template":"project"`: "Invalid JSON",
+	} {
+		req := httptest.NewRequest("POST", "/v0/vaults:fromTemplate", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer test-api-key")
+		w := httptest.NewRecorder()
+		vh.CreateVaultFromTemplate(w, req)
+		if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), want) {
+			t.Fatalf("%s: expected 400 %q, got %d %s", body, want, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestCloneVault(t *testing.T) {
+	st := vaultOnlyStore{v: &memVaults{readOnly: map[string]bool{"v1": false}}}
+	vh := NewVaultHandler(services.NewVaultService(st, nil), &mockAuthorizer{})
+	r := mux.NewRouter()
+	r.HandleFunc("/v0/vaults/{vaultId}:clone", vh.CloneVault).Methods("POST")
+
+	post := func(path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer test-api-key")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	w := post("/v0/vaults/v1:clone", `{"title":"v1-copy","reindex":true}`)
+	if w.Code != http.StatusCreated || !strings.Contains(w.Body.String(), `"sourceVaultId":"v1"`) || !strings.Contains(w.Body.String(), `"reindex":true`) {
+		t.Fatalf("clone: %d %s", w.Code, w.Body.String())
+	}
+	for _, tc := range []struct {
+		path, body string
+		want       int
+	}{
+		{"/v0/vaults/v1:clone", `{`, http.StatusBadRequest},
+		{"/v0/vaults/v1:clone", `{"title":"has/slash"}`, http.StatusBadRequest},
+		{"/v0/vaults/nope:clone", `{"title":"copy"}`, http.StatusNotFound},
+		{"/v0/vaults/v1:clone", `{"title":"taken"}`, http.StatusConflict},
+	} {
+		if w := post(tc.path, tc.body); w.Code != tc.want {
+			t.Fatalf("POST %s %s: expected %d, got %d %s", tc.path, tc.body, tc.want, w.Code, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	respond "github.com/mycelian/mycelian-memory/server/internal/api/respond"
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/services"
+)
+
+// Inbound webhooks let external systems push entries into a memory without
+// an API key: each delivery to POST /v0/hooks/{webhookId} is authenticated
+// by an HMAC signature over its body made with the webhook's secret.
+
+const (
+	// maxWebhookBody bounds one inbound delivery.
+	maxWebhookBody = 1 << 20
+	// webhookClockSkew is how far a delivery's signed timestamp may be from
+	// the server clock, which bounds replays of a captured delivery.
+	webhookClockSkew = 5 * time.Minute
+)
+
+// Signature headers of generic webhooks. The signature is
+// "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body)) with the
+// timestamp in unix seconds. Senders that retry may set
+// WebhookDeliveryHeader to an ID that stays the same across attempts.
+const (
+	WebhookTimestampHeader = "X-Mycelian-Timestamp"
+	WebhookSignatureHeader = "X-Mycelian-Signature"
+	WebhookDeliveryHeader  = "X-Mycelian-Delivery"
+)
+
+// EnableWebhooks serves .../webhooks and POST /v0/hooks/{webhookId} with svc.
+func (h *MemoryHandler) EnableWebhooks(svc *services.WebhookService) { h.webhooks = svc }
+
+// writeWebhookError maps service errors to HTTP responses.
+func writeWebhookError(w http.ResponseWriter, err error, notFound string) {
+	switch {
+	case errors.Is(err, model.ErrNotFound):
+		respond.WriteNotFound(w, notFound)
+	case errors.Is(err, model.ErrValidation):
+		respond.WriteBadRequest(w, err.Error())
+	case errors.Is(err, model.ErrConflict), errors.Is(err, model.ErrReadOnly):
+		respond.WriteError(w, http.StatusConflict, err.Error())
+	default:
+		respond.WriteInternalError(w, err.Error())
+	}
+}
+
+// CreateWebhook POST /v0/vaults/{vaultId}/memories/{memoryId}/webhooks
+// Body: {"name", "kind": "generic"|"slack", "secret", "mapping": {...}}
+// Responds 201 with the webhook, including its secret, which later reads
+// never return.
+func (h *MemoryHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	actorID, vaultID, memoryID, ok := h.authorizedMemory(w, r, "memory.create")
+	if !ok {
+		return
+	}
+	if h.webhooks == nil {
+		respond.WriteNotFound(w, "webhooks are not enabled")
+		return
+	}
+	var req struct {
+		Name    string               `json:"name"`
+		Kind    string               `json:"kind,omitempty"`
+		Secret  string               `json:"secret,omitempty"`
+		Mapping model.WebhookMapping `json:"mapping"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respond.WriteBadRequest(w, "Invalid JSON")
+		return
+	}
+	out, err := h.webhooks.CreateWebhook(r.Context(), &model.Webhook{
+		ActorID: actorID, VaultID: vaultID, MemoryID: memoryID,
+		Name: req.Name, Kind: req.Kind, Secret: req.Secret, Mapping: req.Mapping,
+	})
+	if err != nil {
+		writeWebhookError(w, err, "memory not found")
+		return
+	}
+	respond.WriteJSON(w, http.StatusCreated, out)
+}
+
+// ListWebhooks GET /v0/vaults/{vaultId}/memories/{memoryId}/webhooks
+func (h *MemoryHandler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	actorID, vaultID, memoryID, ok := h.authorizedMemory(w, r, "memory.read")
+	if !ok {
+		return
+	}
+	if h.webhooks == nil {
+		respond.WriteNotFound(w, "webhooks are not enabled")
+		return
+	}
+	out, err := h.webhooks.ListWebhooks(r.Context(), actorID, vaultID, memoryID)
+	if err != nil {
+		writeWebhookError(w, err, "memory not found")
+		return
+	}
+	if out == nil {
+		out = []*model.Webhook{}
+	}
+	respond.WriteJSON(w, http.StatusOK, map[string]interface{}{"webhooks": out, "count": len(out)})
+}
+
+// DeleteWebhook DELETE /v0/vaults/{vaultId}/memories/{memoryId}/webhooks/{webhookId}
+func (h *MemoryHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	actorID, vaultID, memoryID, ok := h.authorizedMemory(w, r, "memory.create")
+	if !ok {
+		return
+	}
+	if h.webhooks == nil {
+		respond.WriteNotFound(w, "webhooks are not enabled")
+		return
+	}
+	if err := h.webhooks.DeleteWebhook(r.Context(), actorID, vaultID, memoryID, mux.Vars(r)["webhookId"]); err != nil {
+		writeWebhookError(w, err, "webhook not found")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ReceiveWebhook POST /v0/hooks/{webhookId}
+// Takes no API key: the body must carry the webhook's signature. Slack
+// url_verification challenges are answered; other payloads are mapped to
+// entries. Responds 201 {"webhookId", "entryIds", "count"}, or 200 when the
+// mapping produced no entry.
+func (h *MemoryHandler) ReceiveWebhook(w http.ResponseWriter, r *http.Request) {
+	if h.webhooks == nil {
+		respond.WriteNotFound(w, "webhooks are not enabled")
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBody))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			respond.WriteError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("payload exceeds %d bytes", maxWebhookBody))
+			return
+		}
+		respond.WriteBadRequest(w, "unable to read body")
+		return
+	}
+	hook, err := h.webhooks.Webhook(r.Context(), mux.Vars(r)["webhookId"])
+	if err != nil {
+		writeWebhookError(w, err, "webhook not found")
+		return
+	}
+	if err := verifyWebhookSignature(hook, r.Header, body, time.Now()); err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+	if hook.Kind == model.WebhookSlack {
+		var challenge struct {
+			Type      string `json:"type"`
+			Challenge string `json:"challenge"`
+		}
+		if json.Unmarshal(body, &challenge) == nil && challenge.Type == "url_verification" {
+			respond.WriteJSON(w, http.StatusOK, map[string]string{"challenge": challenge.Challenge})
+			return
+		}
+	}
+	out, err := h.webhooks.Deliver(r.Context(), hook, body, webhookDeliveryID(hook, r.Header, body))
+	if err != nil {
+		writeWebhookError(w, err, "memory not found")
+		return
+	}
+	status := http.StatusCreated
+	if out.Count == 0 {
+		status = http.StatusOK
+	}
+	respond.WriteJSON(w, status, out)
+}
+
+// webhookDeliveryID names a delivery so retries and replays of it are
+// stored once: Slack's event_id, which its retries keep, or the generic
+// delivery header. Without either the signature stands in, which catches
+// replays of the same signed request within webhookClockSkew.
+func webhookDeliveryID(hook *model.Webhook, header http.Header, body []byte) string {
+	switch hook.Kind {
+	case model.WebhookSlack:
+		var event struct {
+			EventID string `json:"event_id"`
+		}
+		if json.Unmarshal(body, &event) == nil && event.EventID != "" {
+			return "slack:" + event.EventID
+		}
+		return header.Get("X-Slack-Signature")
+	default:
+		if id := header.Get(WebhookDeliveryHeader); id != "" {
+			return "id:" + id
+		}
+		return header.Get(WebhookSignatureHeader)
+	}
+}
+
+// verifyWebhookSignature checks the delivery's HMAC-SHA256 signature with
+// the scheme of the webhook's kind and rejects timestamps further than
+// webhookClockSkew from now.
+func verifyWebhookSignature(hook *model.Webhook, header http.Header, body []byte, now time.Time) error {
+	var ts, sig, prefix, base string
+	switch hook.Kind {
+	case model.WebhookSlack:
+		ts, sig = header.Get("X-Slack-Request-Timestamp"), header.Get("X-Slack-Signature")
+		prefix, base = "v0=", "v0:"+ts+":"+string(body)
+	default:
+		ts, sig = header.Get(WebhookTimestampHeader), header.Get(WebhookSignatureHeader)
+		prefix, base = "sha256=", ts+"."+string(body)
+	}
+	if ts == "" || sig == "" {
+		return errors.New("missing signature headers")
+	}
+	secs, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return errors.New("invalid signature timestamp")
+	}
+	if d := now.Sub(time.Unix(secs, 0)); d > webhookClockSkew || d < -webhookClockSkew {
+		return errors.New("signature timestamp is too old or in the future")
+	}
+	mac := hmac.New(sha256.New, []byte(hook.Secret))
+	mac.Write([]byte(base))
+	if !hmac.Equal([]byte(sig), []byte(prefix+hex.EncodeToString(mac.Sum(nil)))) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
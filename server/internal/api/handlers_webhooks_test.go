@@ -0,0 +1,145 @@
+package api
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/services"
+	"github.com/mycelian/mycelian-memory/server/internal/store"
+	"github.com/mycelian/mycelian-memory/server/internal/summarizer"
+)
+
+type fixedWebhooks struct {
+	store.Webhooks
+	hooks map[string]*model.Webhook
+}
+
+func (f fixedWebhooks) Get(_ context.Context, webhookID string) (*model.Webhook, error) {
+	if w, ok := f.hooks[webhookID]; ok {
+		return w, nil
+	}
+	return nil, model.ErrNotFound
+}
+func (fixedWebhooks) RecordDelivery(context.Context, string, time.Time) error { return nil }
+
+type webhookStore struct {
+	batchStore
+	w fixedWebhooks
+}
+
+func (s webhookStore) Webhooks() store.Webhooks { return s.w }
+
+func sign(secret, base string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(base))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestReceiveWebhook(t *testing.T) {
+	es := &batchEntries{}
+	st := webhookStore{batchStore: batchStore{e: es}, w: fixedWebhooks{hooks: map[string]*model.Webhook{
+		"g1": {WebhookID: "g1", ActorID: "u1", VaultID: "v1", MemoryID: "m1", Name: "ci", Kind: model.WebhookGeneric, Secret: "gs",
+			Mapping: model.WebhookMapping{RawEntry: "{{text}}", Summary: "{{title}}"}},
+		"s1": {WebhookID: "s1", ActorID: "u1", VaultID: "v1", MemoryID: "m1", Name: "slack", Kind: model.WebhookSlack, Secret: "ss",
+			Mapping: model.WebhookMapping{RawEntry: "{{event.text}}", Summary: "{{event.user}}"}},
+	}}}
+	mem := services.NewMemoryService(st, nil, nil)
+	h := NewMemoryHandler(mem, services.NewVaultService(st, nil), &mockAuthorizer{}, nil)
+	h.EnableWebhooks(services.NewWebhookService(st, mem, summarizer.Extractive{}))
+	r := mux.NewRouter()
+	r.HandleFunc("/v0/hooks/{webhookId}", h.ReceiveWebhook).Methods("POST")
+	post := func(id, body string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v0/hooks/"+id, strings.NewReader(body))
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	generic := func(ts, body, secret string) map[string]string {
+		return map[string]string{WebhookTimestampHeader: ts, WebhookSignatureHeader: "sha256=" + sign(secret, ts+"."+body)}
+	}
+
+	body := `{"title":"Deploy","text":"Deployed v2 to production."}`
+	w := post("g1", body, generic(now, body, "gs"))
+	if w.Code != http.StatusCreated || len(es.got) != 1 || es.got[0].RawEntry != "Deployed v2 to production." || *es.got[0].Summary != "Deploy" {
+		t.Fatalf("generic delivery: %d %s %+v", w.Code, w.Body.String(), es.got)
+	}
+	if es.got[0].SourceSystem != "webhook:ci" {
+		t.Fatalf("unexpected sourceSystem %q", es.got[0].SourceSystem)
+	}
+	if w := post("g1", `{"text":""}`, generic(now, `{"text":""}`, "gs")); w.Code != http.StatusOK {
+		t.Fatalf("empty mapping result: %d %s", w.Code, w.Body.String())
+	}
+
+	stale := strconv.FormatInt(time.Now().Add(-10*time.Minute).Unix(), 10)
+	for name, tc := range map[string]struct {
+		id      string
+		headers map[string]string
+		code    int
+	}{
+		"unknown":    {"nope", generic(now, body, "gs"), http.StatusNotFound},
+		"no headers": {"g1", nil, http.StatusUnauthorized},
+		"bad secret": {"g1", generic(now, body, "other"), http.StatusUnauthorized},
+		"stale":      {"g1", generic(stale, body, "gs"), http.StatusUnauthorized},
+		"slack sig":  {"s1", generic(now, body, "ss"), http.StatusUnauthorized},
+	} {
+		if w := post(tc.id, body, tc.headers); w.Code != tc.code {
+			t.Fatalf("%s: got %d want %d (%s)", name, w.Code, tc.code, w.Body.String())
+		}
+	}
+
+	slack := func(body string) map[string]string {
+		return map[string]string{"X-Slack-Request-Timestamp": now, "X-Slack-Signature": "v0=" + sign("ss", "v0:"+now+":"+body)}
+	}
+	challenge := `{"type":"url_verification","challenge":"abc"}`
+	w = post("s1", challenge, slack(challenge))
+	var resp map[string]string
+	if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &resp) != nil || resp["challenge"] != "abc" {
+		t.Fatalf("url_verification: %d %s", w.Code, w.Body.String())
+	}
+	event := `{"type":"event_callback","event":{"type":"message","user":"U1","text":"Standup moved to 10am."}}`
+	if w := post("s1", event, slack(event)); w.Code != http.StatusCreated || es.got[0].RawEntry != "Standup moved to 10am." {
+		t.Fatalf("slack event: %d %s", w.Code, w.Body.String())
+	}
+
+	// Slack retries re-sign the same event_id; replays repeat the signature.
+	retried := `{"type":"event_callback","event_id":"Ev1","event":{"type":"message","user":"U1","text":"Retro at 3pm."}}`
+	_ = post("s1", retried, slack(retried))
+	firstKey := es.got[0].IdempotencyKey
+	later := strconv.FormatInt(time.Now().Add(3*time.Second).Unix(), 10)
+	_ = post("s1", retried, map[string]string{"X-Slack-Request-Timestamp": later, "X-Slack-Signature": "v0=" + sign("ss", "v0:"+later+":"+retried), "X-Slack-Retry-Num": "1"})
+	if firstKey == "" || es.got[0].IdempotencyKey != firstKey {
+		t.Fatalf("slack retry: keys %q and %q", firstKey, es.got[0].IdempotencyKey)
+	}
+	_ = post("g1", body, generic(now, body, "gs"))
+	replayKey := es.got[0].IdempotencyKey
+	_ = post("g1", body, generic(now, body, "gs"))
+	if replayKey == "" || es.got[0].IdempotencyKey != replayKey {
+		t.Fatalf("generic replay: keys %q and %q", replayKey, es.got[0].IdempotencyKey)
+	}
+	withID := generic(now, body, "gs")
+	withID[WebhookDeliveryHeader] = "d-1"
+	_ = post("g1", body, withID)
+	if k := es.got[0].IdempotencyKey; k == "" || k == replayKey {
+		t.Fatalf("delivery header must key the delivery: %q", k)
+	}
+
+	if w := post("g1", `not json`, generic(now, `not json`, "gs")); w.Code != http.StatusBadRequest {
+		t.Fatalf("invalid payload: %d %s", w.Code, w.Body.String())
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	respond "github.com/mycelian/mycelian-memory/server/internal/api/respond"
+	"github.com/mycelian/mycelian-memory/server/internal/health"
 )
 
 // HealthHandler handles health check endpoints
@@ -30,6 +31,25 @@ var serviceIsHealthy func() bool = func() bool { return healthyFlag.Load() == 1
 
 func BindServiceHealth(f func() bool) { serviceIsHealthy = f }
 
+// componentHealth reports per-dependency health keyed by checker name.
+var componentHealth func() map[string]bool = func() map[string]bool { return nil }
+
+// BindComponentHealth allows run.go to inject per-dependency health reporting.
+func BindComponentHealth(f func() map[string]bool) { componentHealth = f }
+
+// healthHistory reports the health monitor's transitions and flap state.
+var healthHistory func() health.History
+
+// BindHealthHistory allows run.go to inject the health monitor's history,
+// served by GET /v0/admin/health.
+func BindHealthHistory(f func() health.History) { healthHistory = f }
+
+// schemaVersion is the storage schema revision reported by the health endpoint.
+var schemaVersion string
+
+// BindSchemaVersion sets the storage schema revision reported by /v0/health.
+func BindSchemaVersion(v string) { schemaVersion = v }
+
 // CheckHealth handles GET /v0/health
 // Always returns 200; body reports healthy/unhealthy. 500 indicates handler failure only.
 func (h *HealthHandler) CheckHealth(w http.ResponseWriter, r *http.Request) {
@@ -41,5 +61,19 @@ func (h *HealthHandler) CheckHealth(w http.ResponseWriter, r *http.Request) {
 		"status":    status,
 		"timestamp": time.Now().Format(time.RFC3339),
 	}
+	if comps := componentHealth(); len(comps) > 0 {
+		out := make(map[string]string, len(comps))
+		for name, ok := range comps {
+			if ok {
+				out[name] = "healthy"
+			} else {
+				out[name] = "unhealthy"
+			}
+		}
+		response["components"] = out
+	}
+	if schemaVersion != "" {
+		response["schemaVersion"] = schemaVersion
+	}
 	respond.WriteJSON(w, http.StatusOK, response)
 }
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -16,3 +17,27 @@ func TestHealthHandler_CheckHealth(t *testing.T) {
 		t.Fatalf("unexpected status code: %d", code)
 	}
 }
+
+func TestHealthHandler_ReportsComponentsAndSchema(t *testing.T) {
+	BindComponentHealth(func() map[string]bool { return map[string]bool{"store": true, "embedder": false} })
+	BindSchemaVersion("7")
+	defer BindComponentHealth(func() map[string]bool { return nil })
+	defer BindSchemaVersion("")
+
+	w := httptest.NewRecorder()
+	NewHealthHandler().CheckHealth(w, httptest.NewRequest(http.MethodGet, "/v0/health", nil))
+
+	var body struct {
+		SchemaVersion string            `json:"schemaVersion"`
+		Components    map[string]string `json:"components"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body.SchemaVersion != "7" {
+		t.Fatalf("schemaVersion = %q", body.SchemaVersion)
+	}
+	if body.Components["store"] != "healthy" || body.Components["embedder"] != "unhealthy" {
+		t.Fatalf("unexpected components: %v", body.Components)
+	}
+}
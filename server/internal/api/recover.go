@@ -1,28 +1,43 @@
 package api
 
 import (
+	"expvar"
 	"net/http"
 	"runtime/debug"
 
 	"github.com/rs/zerolog/log"
+
+	respond "github.com/mycelian/mycelian-memory/server/internal/api/respond"
 )
 
+// panicsRecovered counts handler panics turned into 500s (exposed via /debug/vars).
+var panicsRecovered = expvar.NewInt("http_panics_recovered")
+
 // Recover intercepts panics from downstream handlers, logs details, and returns HTTP 500.
 func Recover(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
 			if rec := recover(); rec != nil {
+				if rec == http.ErrAbortHandler {
+					panic(rec) // deliberate abort; let net/http drop the connection
+				}
+				panicsRecovered.Add(1)
+				reqID := RequestIDFrom(r.Context())
 				log.Error().
 					Interface("panic", rec).
+					Str("requestId", reqID).
 					Str("method", r.Method).
 					Str("url", r.URL.String()).
 					Str("remote", r.RemoteAddr).
 					Bytes("stack", debug.Stack()).
 					Msg("panic recovered")
 
-				w.Header().Set("Content-Type", "application/json")
-				w.WriteHeader(http.StatusInternalServerError)
-				_, _ = w.Write([]byte(`{"error":"Internal Server Error","code":500}`))
+				respond.WriteJSON(w, http.StatusInternalServerError, map[string]interface{}{
+					"error":     http.StatusText(http.StatusInternalServerError),
+					"code":      http.StatusInternalServerError,
+					"message":   "unexpected server error",
+					"requestId": reqID,
+				})
 			}
 		}()
 		next.ServeHTTP(w, r)
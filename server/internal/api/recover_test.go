@@ -0,0 +1,59 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRecover_StructuredErrorWithRequestID(t *testing.T) {
+	before := panicsRecovered.Value()
+	h := RequestID(Recover(http.HandlerFunc(func(http.ResponseWriter, *http.Request) { panic("boom") })))
+
+	req := httptest.NewRequest(http.MethodGet, "/v0/x", nil)
+	req.Header.Set(RequestIDHeader, "req-123")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", w.Code)
+	}
+	if got := w.Header().Get(RequestIDHeader); got != "req-123" {
+		t.Fatalf("expected request ID echoed, got %q", got)
+	}
+	var body struct {
+		Code      int    `json:"code"`
+		RequestID string `json:"requestId"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body.Code != 500 || body.RequestID != "req-123" {
+		t.Fatalf("unexpected body: %+v", body)
+	}
+	if panicsRecovered.Value() != before+1 {
+		t.Fatalf("expected panic counter to increment")
+	}
+}
+
+func TestRequestID_Generated(t *testing.T) {
+	var seen string
+	h := RequestID(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) { seen = RequestIDFrom(r.Context()) }))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v0/x", nil))
+	if seen == "" || w.Header().Get(RequestIDHeader) != seen {
+		t.Fatalf("expected generated ID in context and header, got ctx=%q header=%q", seen, w.Header().Get(RequestIDHeader))
+	}
+}
+
+func TestRecover_PanicBehindDeadline(t *testing.T) {
+	h := Recover(RequestDeadline(0)(http.HandlerFunc(func(http.ResponseWriter, *http.Request) { panic("boom") })))
+	req := httptest.NewRequest(http.MethodGet, "/v0/x", nil)
+	req.Header.Set(RequestTimeoutHeader, "1s")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", w.Code)
+	}
+}
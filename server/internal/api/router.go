@@ -9,6 +9,7 @@ func NewRouter() *mux.Router {
 	router := mux.NewRouter()
 
 	// Global middlewares
+	router.Use(RequestID)
 	router.Use(Recover)
 
 	// Create handlers
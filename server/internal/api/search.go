@@ -3,19 +3,39 @@ package api
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
-
 	"strings"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/services"
 )
 
+// maxMustNotValues caps the exclusion values in one request; each becomes an
+// index filter operand.
+const maxMustNotValues = 256
+
+// maxTagFilters caps the tag pairs one search or entry list filters by.
+const maxTagFilters = 32
+
 // SearchRequest represents the payload for POST /api/search
 //
 // Fields:
 //
 
-//	memoryId – required, non-empty string
+//	memoryId – required unless the actor has a default memory or the search is title-scoped
+//	vaultId, memoryTitles | memoryPattern – optional, search the vault's memories with these titles
+//	  or whose title matches the glob pattern instead of memoryId
 //	query – required, non-empty string
-//	topK  – optional, 1-100 (defaults to 10)
+//	topK  – optional, defaults to 10; the handler enforces the actor's maximum
+//	sessionId – optional, only entries of this conversation session
+//	tags – optional map, only entries whose tags hold every key with exactly its value
+//	mustNot – optional tags, memoryIds and entryIds to exclude
+//	rankBy – optional relevance (default), recency or hybrid
+//	profile – optional ranking profile name; its rankBy applies when rankBy is unset
+//	window – optional named time range, e.g. last7d, thisMonth, sinceSessionStart
+//	since, until – optional creation time bounds; not combined with window
+//	groupBy – optional "session": one result per conversation session
 //
 // Validation is done via the Validate method.
 // User identification comes from API key authorization.
@@ -23,15 +43,70 @@ import (
 // This DTO is intentionally small; future versions may add filters.
 type SearchRequest struct {
 	MemoryID string `json:"memoryId"`
-	Query    string `json:"query"`
-	TopK     int    `json:"topK,omitempty"`
+	// VaultID with MemoryTitles or MemoryPattern searches several memories
+	// of the vault, picked by title, instead of MemoryID.
+	VaultID       string   `json:"vaultId,omitempty"`
+	MemoryTitles  []string `json:"memoryTitles,omitempty"`
+	MemoryPattern string   `json:"memoryPattern,omitempty"`
+	Query         string   `json:"query"`
+	TopK          int      `json:"topK,omitempty"`
+	// SessionID restricts results to one conversation session.
+	SessionID string `json:"sessionId,omitempty"`
+	// Tags restricts results to entries whose tags hold every key with
+	// exactly its value; booleans and numbers match their text, e.g. "true".
+	Tags map[string]string `json:"tags,omitempty"`
+	// MustNot excludes results, e.g. entries already cited in the current turn.
+	MustNot model.SearchMustNot `json:"mustNot,omitempty"`
+	// RankBy orders results by relevance, or decays scores by entry age
+	// (recency) or partly so (hybrid).
+	RankBy string `json:"rankBy,omitempty"`
+	// Profile names an operator-defined ranking profile (alpha, signal
+	// re-ranking, recency decay, diversity); the handler resolves it.
+	Profile string `json:"profile,omitempty"`
+	// Window names a creation time range resolved in the actor's time zone
+	// (see services.ResolveTimeWindow); sinceSessionStart needs SessionID.
+	Window string `json:"window,omitempty"`
+	// Since and Until bound creation time to [since, until); each accepts
+	// RFC3339, YYYY-MM-DD, today or yesterday.
+	Since string `json:"since,omitempty"`
+	Until string `json:"until,omitempty"`
+	// GroupBy "session" collapses hits of one session into its best hit,
+	// with sessionHits counting them, so one conversation cannot fill topK.
+	GroupBy string `json:"groupBy,omitempty"`
+	// IncludeSuperseded keeps hits of corrected entries, marked
+	// supersededBy, instead of returning their corrections.
+	IncludeSuperseded bool `json:"includeSuperseded,omitempty"`
 }
 
 // Validate sanitises the struct and applies defaults.
 func (r *SearchRequest) Validate() error {
 	r.Query = strings.TrimSpace(r.Query)
+	r.SessionID = strings.TrimSpace(r.SessionID)
+	r.RankBy = strings.ToLower(strings.TrimSpace(r.RankBy))
+	r.Profile = strings.TrimSpace(r.Profile)
+	r.Window = strings.TrimSpace(r.Window)
+	r.Since = strings.TrimSpace(r.Since)
+	r.Until = strings.TrimSpace(r.Until)
+	r.GroupBy = strings.ToLower(strings.TrimSpace(r.GroupBy))
+	r.VaultID = strings.TrimSpace(r.VaultID)
+	r.MemoryTitles = compactValues(r.MemoryTitles)
+	r.MemoryPattern = strings.TrimSpace(r.MemoryPattern)
 
-	if r.MemoryID == "" {
+	switch {
+	case r.TitleScoped():
+		if r.MemoryID != "" {
+			return errors.New("memoryId cannot be combined with memoryTitles or memoryPattern")
+		}
+		if len(r.MemoryTitles) > 0 && r.MemoryPattern != "" {
+			return errors.New("memoryTitles cannot be combined with memoryPattern")
+		}
+		if r.VaultID == "" {
+			return errors.New("vaultId is required with memoryTitles or memoryPattern")
+		}
+		if strings.EqualFold(r.Window, services.WindowSinceSessionStart) {
+			return fmt.Errorf("window %s requires memoryId", services.WindowSinceSessionStart)
+		}
+	case r.MemoryID == "":
 		return errors.New("memoryId is required")
 	}
 	if r.Query == "" {
@@ -40,20 +115,126 @@ func (r *SearchRequest) Validate() error {
 	if r.TopK <= 0 {
 		r.TopK = 10
 	}
-	if r.TopK > 100 {
-		r.TopK = 100
+	switch r.RankBy {
+	case "":
+		// A profile may supply rankBy; the handler defaults it otherwise.
+		if r.Profile == "" {
+			r.RankBy = model.RankByRelevance
+		}
+	case model.RankByRelevance, model.RankByRecency, model.RankByHybrid:
+	default:
+		return fmt.Errorf("rankBy must be one of %s, %s, %s", model.RankByRelevance, model.RankByRecency, model.RankByHybrid)
+	}
+	if r.Window != "" && (r.Since != "" || r.Until != "") {
+		return errors.New("window cannot be combined with since or until")
+	}
+	switch r.GroupBy {
+	case "":
+	case model.GroupBySession:
+		if r.SessionID != "" {
+			return errors.New("groupBy session cannot be combined with sessionId")
+		}
+	default:
+		return fmt.Errorf("groupBy must be %s", model.GroupBySession)
+	}
+	tags, err := validateTagFilters(r.Tags)
+	if err != nil {
+		return err
+	}
+	r.Tags = tags
+	r.MustNot.Tags = compactValues(r.MustNot.Tags)
+	r.MustNot.MemoryIDs = compactValues(r.MustNot.MemoryIDs)
+	r.MustNot.EntryIDs = compactValues(r.MustNot.EntryIDs)
+	if n := len(r.MustNot.Tags) + len(r.MustNot.MemoryIDs) + len(r.MustNot.EntryIDs); n > maxMustNotValues {
+		return fmt.Errorf("mustNot has %d values; at most %d allowed", n, maxMustNotValues)
+	}
+	for _, id := range r.MustNot.MemoryIDs {
+		if id == r.MemoryID {
+			return errors.New("mustNot.memoryIds cannot contain the searched memoryId")
+		}
 	}
 	return nil
 }
 
+// TitleScoped reports whether the request picks its memories by title.
+func (r *SearchRequest) TitleScoped() bool {
+	return len(r.MemoryTitles) > 0 || r.MemoryPattern != ""
+}
+
+// Filter is the index filter of the request over window, its resolved
+// time bounds.
+func (r *SearchRequest) Filter(window services.TimeWindow) model.SearchFilter {
+	return model.SearchFilter{SessionID: r.SessionID, Tags: r.Tags, MustNot: r.MustNot, Since: window.Since, Until: window.Until}
+}
+
+// compactValues trims values and drops blanks and duplicates.
+func compactValues(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	seen := make(map[string]bool, len(values))
+	out := values[:0]
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		out = append(out, v)
+	}
+	return out
+}
+
+// validateTagFilters trims the keys of a tag filter and checks them; keys
+// cannot be blank or contain "=", which separates key and value in the index.
+func validateTagFilters(tags map[string]string) (map[string]string, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+	if len(tags) > maxTagFilters {
+		return nil, fmt.Errorf("tags has %d pairs; at most %d allowed", len(tags), maxTagFilters)
+	}
+	out := make(map[string]string, len(tags))
+	for k, v := range tags {
+		k = strings.TrimSpace(k)
+		if k == "" || strings.Contains(k, "=") {
+			return nil, fmt.Errorf("tag key %q must be non-empty and cannot contain '='", k)
+		}
+		out[k] = v
+	}
+	return out, nil
+}
+
+// parseTagParams reads repeated key=value tag query parameters into a tag
+// filter.
+func parseTagParams(values []string) (map[string]string, error) {
+	tags := make(map[string]string, len(values))
+	for _, kv := range values {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok {
+			return nil, fmt.Errorf("tag %q must be key=value", kv)
+		}
+		tags[k] = v
+	}
+	return validateTagFilters(tags)
+}
+
 // decodeSearchRequest helper parses JSON into SearchRequest and validates it.
-func decodeSearchRequest(w http.ResponseWriter, r *http.Request) (*SearchRequest, error) {
+// defaultMemory, when non-nil, supplies an omitted memoryId.
+func decodeSearchRequest(w http.ResponseWriter, r *http.Request, defaultMemory func() (string, error)) (*SearchRequest, error) {
 	// w is currently unused but kept for compatibility; mark to avoid linters
 	_ = w
 	var req SearchRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		return nil, err
 	}
+	if req.MemoryID == "" && !req.TitleScoped() && defaultMemory != nil {
+		id, err := defaultMemory()
+		if err != nil {
+			return nil, err
+		}
+		req.MemoryID = id
+	}
 	if err := req.Validate(); err != nil {
 		return nil, err
 	}
@@ -0,0 +1,156 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	respond "github.com/mycelian/mycelian-memory/server/internal/api/respond"
+	"github.com/mycelian/mycelian-memory/server/internal/auth"
+)
+
+// MaxBatchSearchQueries caps the queries of one POST /v0/search:batch.
+const MaxBatchSearchQueries = 50
+
+// batchSearchParallelism is how many queries of a batch run at once.
+const batchSearchParallelism = 8
+
+// BatchSearchRequest is the payload for POST /v0/search:batch: every query
+// runs with the same memoryId, topK, filters and ranking; query is not used.
+type BatchSearchRequest struct {
+	SearchRequest
+	Queries []string `json:"queries"`
+}
+
+// Validate applies SearchRequest validation to the shared fields and checks
+// the queries.
+func (r *BatchSearchRequest) Validate() error {
+	if r.Query != "" {
+		return errors.New("query is not used in a batch; list the queries in queries")
+	}
+	if r.TitleScoped() {
+		return errors.New("memoryTitles and memoryPattern are not supported in a batch")
+	}
+	if len(r.Queries) == 0 {
+		return errors.New("queries is required")
+	}
+	if len(r.Queries) > MaxBatchSearchQueries {
+		return fmt.Errorf("queries has %d items; at most %d allowed", len(r.Queries), MaxBatchSearchQueries)
+	}
+	for i, q := range r.Queries {
+		r.Queries[i] = strings.TrimSpace(q)
+		if r.Queries[i] == "" {
+			return fmt.Errorf("queries[%d] cannot be empty", i)
+		}
+	}
+	r.Query = r.Queries[0]
+	err := r.SearchRequest.Validate()
+	r.Query = ""
+	return err
+}
+
+// HandleBatchSearch handles POST /v0/search:batch. The queries run
+// concurrently and count as one search against the actor's concurrency
+// limit. Results are returned in request order, each shaped like a
+// POST /v0/search response without the latest context and index freshness,
+// which are returned once; a failed query carries an error instead of failing the batch.
+func (h *SearchHandler) HandleBatchSearch(w http.ResponseWriter, r *http.Request) {
+	apiKey, err := auth.ExtractAPIKey(r)
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+	actorInfo, err := h.authorizer.Authorize(r.Context(), apiKey, "memory.search", "default")
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+
+	var req BatchSearchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respond.WriteBadRequest(w, "Invalid JSON")
+		return
+	}
+	if req.MemoryID == "" {
+		if resolve := h.defaultMemory(r, actorInfo.ActorID); resolve != nil {
+			id, err := resolve()
+			if err != nil {
+				respond.WriteBadRequest(w, err.Error())
+				return
+			}
+			req.MemoryID = id
+		}
+	}
+	if err := req.Validate(); err != nil {
+		respond.WriteBadRequest(w, err.Error())
+		return
+	}
+	rk, err := h.ranking(&req.SearchRequest)
+	if err != nil {
+		respond.WriteBadRequest(w, err.Error())
+		return
+	}
+	if max := h.limits.maxTopK(actorInfo.ActorID); max > 0 && req.TopK > max {
+		respond.WriteBadRequest(w, fmt.Sprintf("topK %d exceeds the maximum of %d", req.TopK, max))
+		return
+	}
+	if h.emb == nil || h.idx == nil {
+		respond.WriteError(w, http.StatusServiceUnavailable, "search not configured")
+		return
+	}
+	if !h.inFlight.tryAcquire(actorInfo.ActorID, h.limits.maxConcurrent(actorInfo.ActorID)) {
+		log.Warn().Str("actorId", actorInfo.ActorID).Msg("search concurrency limit reached")
+		w.Header().Set("Retry-After", "1")
+		respond.WriteError(w, http.StatusTooManyRequests, "too many concurrent searches; retry shortly")
+		return
+	}
+	defer h.inFlight.release(actorInfo.ActorID)
+
+	window, err := h.timeWindow(r, actorInfo.ActorID, &req.SearchRequest)
+	if err != nil {
+		writeTimeWindowError(w, err)
+		return
+	}
+
+	log.Info().Str("memoryId", req.MemoryID).Int("queries", len(req.Queries)).Int("topK", req.TopK).Str("actorId", actorInfo.ActorID).Msg("batch search request received")
+
+	results := make([]map[string]interface{}, len(req.Queries))
+	sem := make(chan struct{}, batchSearchParallelism)
+	var wg sync.WaitGroup
+	for i, query := range req.Queries {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			one := req.SearchRequest
+			one.Query = query
+			resp, err := h.search(r, actorInfo.ActorID, &one, rk, window)
+			if err != nil {
+				resp = map[string]interface{}{"error": err.Error()}
+			}
+			resp["query"] = query
+			results[i] = resp
+		}()
+	}
+	wg.Wait()
+
+	ctxStr, ts, err := h.idx.LatestContext(r.Context(), actorInfo.ActorID, req.MemoryID)
+	if err != nil {
+		respond.WriteError(w, http.StatusInternalServerError, "latest context unavailable")
+		return
+	}
+	resp := map[string]interface{}{
+		"results":          results,
+		"count":            len(results),
+		"latestContext":    ctxStr,
+		"contextTimestamp": ts.Format(time.RFC3339),
+	}
+	h.addIndexFreshness(r, actorInfo.ActorID, req.MemoryID, resp)
+	respond.WriteJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+type constEmbedder struct{}
+
+func (constEmbedder) Embed(context.Context, string) ([]float32, error) { return []float32{1, 2}, nil }
+
+// queryEchoSearch returns one hit per search, identified by the query, and
+// fails the query "fail".
+type queryEchoSearch struct {
+	mockSearch
+	mu      sync.Mutex
+	queries []string
+}
+
+func (m *queryEchoSearch) Search(_ context.Context, _, _, q string, _ []float32, _ int, _ float32, _ model.SearchFilter) ([]model.SearchHit, error) {
+	m.mu.Lock()
+	m.queries = append(m.queries, q)
+	m.mu.Unlock()
+	if q == "fail" {
+		return nil, errors.New("index down")
+	}
+	return []model.SearchHit{{EntryID: "e-" + q, Score: 0.5}}, nil
+}
+
+func TestHandleBatchSearch(t *testing.T) {
+	srch := &queryEchoSearch{}
+	h, _ := NewSearchHandler(constEmbedder{}, srch, 0.6, &mockAuthorizer{})
+	call := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v0/search:batch", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer test-api-key")
+		w := httptest.NewRecorder()
+		h.HandleBatchSearch(w, req)
+		return w
+	}
+
+	w := call(`{"memoryId":"m1","topK":3,"queries":["alpha"," beta ","fail"]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Results []struct {
+			Query   string            `json:"query"`
+			Entries []model.SearchHit `json:"entries"`
+			Error   string            `json:"error"`
+		} `json:"results"`
+		Count         int    `json:"count"`
+		LatestContext string `json:"latestContext"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Count != 3 || resp.LatestContext != "ctx" || len(srch.queries) != 3 {
+		t.Fatalf("count=%d latestContext=%q searched=%v", resp.Count, resp.LatestContext, srch.queries)
+	}
+	for i, want := range []string{"alpha", "beta"} {
+		r := resp.Results[i]
+		if r.Query != want || len(r.Entries) != 1 || r.Entries[0].EntryID != "e-"+want {
+			t.Fatalf("result %d = %+v", i, r)
+		}
+	}
+	if r := resp.Results[2]; r.Query != "fail" || r.Error != "search service unavailable" || r.Entries != nil {
+		t.Fatalf("failed query result = %+v", r)
+	}
+
+	tooMany := make([]string, MaxBatchSearchQueries+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("%q", "q")
+	}
+	for _, body := range []string{
+		`{"memoryId":"m1","queries":[]}`,
+		`{"memoryId":"m1","queries":["a",""]}`,
+		`{"memoryId":"m1","query":"a","queries":["b"]}`,
+		`{"queries":["a"]}`,
+		`{"memoryId":"m1","queries":[` + strings.Join(tooMany, ",") + `]}`,
+	} {
+		if w := call(body); w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", body, w.Code)
+		}
+	}
+}
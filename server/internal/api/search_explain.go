@@ -0,0 +1,252 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	respond "github.com/mycelian/mycelian-memory/server/internal/api/respond"
+	"github.com/mycelian/mycelian-memory/server/internal/auth"
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/outbox/payload"
+	"github.com/mycelian/mycelian-memory/server/internal/searchindex"
+	"github.com/mycelian/mycelian-memory/server/internal/services"
+)
+
+// explainDepth is how many candidates an explain search ranks when looking
+// for the entry; an entry below it is reported as not found.
+const explainDepth = 100
+
+// EnableExplain serves GET /v0/search/explain, reading entries through svc.
+func (h *SearchHandler) EnableExplain(svc *services.MemoryService) { h.explain = svc }
+
+// explainFilter is one search filter checked against the entry.
+type explainFilter struct {
+	Filter string `json:"filter"`
+	Value  string `json:"value"`
+	Passed bool   `json:"passed"`
+}
+
+// SearchExplanation says whether and why an entry is returned for a query.
+type SearchExplanation struct {
+	EntryID  string `json:"entryId"`
+	MemoryID string `json:"memoryId"`
+	Query    string `json:"query"`
+	// ExpandedQuery is the query after entity alias expansion, when it changed.
+	ExpandedQuery string `json:"expandedQuery,omitempty"`
+	TopK          int    `json:"topK"`
+	RankBy        string `json:"rankBy"`
+	// Profile is the ranking profile the search ran with, if any.
+	Profile string `json:"profile,omitempty"`
+	// WouldMatch is true when the entry is within the first TopK results.
+	WouldMatch bool `json:"wouldMatch"`
+	// Rank is the entry's 1-based position among the first Depth results; 0
+	// when it is not among them.
+	Rank  int      `json:"rank"`
+	Depth int      `json:"depth"`
+	Score *float64 `json:"score,omitempty"`
+	Alpha float32  `json:"alpha"`
+	// Indexed and VectorSimilarity are omitted when the index cannot read
+	// stored vectors back.
+	Indexed          *bool              `json:"indexed,omitempty"`
+	VectorSimilarity *float64           `json:"vectorSimilarity,omitempty"`
+	Terms            services.TermMatch `json:"terms"`
+	Filters          []explainFilter    `json:"filters"`
+	Reasons          []string           `json:"reasons"`
+}
+
+// HandleExplain GET /v0/search/explain?vaultId=&memoryId=&entryId=&query=[&topK=&sessionId=&rankBy=&profile=&window=&since=&until=&tag=&mustNotTag=&mustNotEntryId=]
+// Runs the search as POST /v0/search would, ranked over explainDepth
+// candidates, and reports the entry's rank together with its keyword term
+// matches, vector similarity to the query and the filters it passes.
+func (h *SearchHandler) HandleExplain(w http.ResponseWriter, r *http.Request) {
+	apiKey, err := auth.ExtractAPIKey(r)
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+	actorInfo, err := h.authorizer.Authorize(r.Context(), apiKey, "memory.search", "default")
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+
+	q := r.URL.Query()
+	vaultID, entryID := q.Get("vaultId"), q.Get("entryId")
+	req := SearchRequest{MemoryID: q.Get("memoryId"), Query: q.Get("query"), SessionID: q.Get("sessionId"), RankBy: q.Get("rankBy"),
+		Profile: q.Get("profile"), Window: q.Get("window"), Since: q.Get("since"), Until: q.Get("until")}
+	if v := q.Get("topK"); v != "" {
+		if req.TopK, err = strconv.Atoi(v); err != nil || req.TopK <= 0 {
+			respond.WriteBadRequest(w, "topK must be a positive integer")
+			return
+		}
+	}
+	if vaultID == "" || entryID == "" {
+		respond.WriteBadRequest(w, "vaultId and entryId are required")
+		return
+	}
+	if req.Tags, err = parseTagParams(q["tag"]); err != nil {
+		respond.WriteBadRequest(w, err.Error())
+		return
+	}
+	req.MustNot = model.SearchMustNot{Tags: q["mustNotTag"], EntryIDs: q["mustNotEntryId"]}
+	if err := req.Validate(); err != nil {
+		respond.WriteBadRequest(w, err.Error())
+		return
+	}
+	rk, err := h.ranking(&req)
+	if err != nil {
+		respond.WriteBadRequest(w, err.Error())
+		return
+	}
+	if max := h.limits.maxTopK(actorInfo.ActorID); max > 0 && req.TopK > max {
+		respond.WriteBadRequest(w, fmt.Sprintf("topK %d exceeds the maximum of %d", req.TopK, max))
+		return
+	}
+	if h.emb == nil || h.idx == nil || h.explain == nil {
+		respond.WriteError(w, http.StatusServiceUnavailable, "search not configured")
+		return
+	}
+
+	window, err := h.timeWindow(r, actorInfo.ActorID, &req)
+	if err != nil {
+		writeTimeWindowError(w, err)
+		return
+	}
+
+	entry, err := h.explain.LookupEntry(r.Context(), actorInfo.ActorID, vaultID, req.MemoryID, entryID)
+	if errors.Is(err, model.ErrNotFound) {
+		respond.WriteNotFound(w, "entry not found")
+		return
+	}
+	if err != nil {
+		respond.WriteInternalError(w, err.Error())
+		return
+	}
+
+	if !h.inFlight.tryAcquire(actorInfo.ActorID, h.limits.maxConcurrent(actorInfo.ActorID)) {
+		w.Header().Set("Retry-After", "1")
+		respond.WriteError(w, http.StatusTooManyRequests, "too many concurrent searches; retry shortly")
+		return
+	}
+	defer h.inFlight.release(actorInfo.ActorID)
+
+	query := h.expandQuery(r, actorInfo.ActorID, &req)
+	vec, err := h.emb.Embed(r.Context(), query)
+	if err != nil {
+		log.Error().Err(err).Str("query", query).Msg("embedding failed")
+		respond.WriteError(w, http.StatusInternalServerError, "embedding service unavailable")
+		return
+	}
+	depth := max(explainDepth, req.TopK)
+	filter := req.Filter(window)
+	hits, err := h.idx.Search(r.Context(), actorInfo.ActorID, req.MemoryID, query, vec, depth, rk.alpha, filter)
+	if err != nil {
+		log.Error().Err(err).Str("memoryId", req.MemoryID).Msg("explain search failed")
+		respond.WriteError(w, http.StatusInternalServerError, "search service unavailable")
+		return
+	}
+	if hits, err = h.rank(r.Context(), actorInfo.ActorID, &req, rk, filter, hits); err != nil {
+		writeSearchError(w, err)
+		return
+	}
+
+	out := SearchExplanation{
+		EntryID: entryID, MemoryID: req.MemoryID, Query: req.Query, TopK: req.TopK, RankBy: req.RankBy,
+		Profile: rk.profile, Depth: depth, Alpha: rk.alpha, Filters: []explainFilter{}, Reasons: []string{},
+	}
+	summary := ""
+	if entry.Summary != nil {
+		summary = *entry.Summary
+	}
+	if query != req.Query {
+		out.ExpandedQuery = query
+	}
+	out.Terms = services.MatchTerms(query, entry.RawEntry+"\n"+summary)
+	for i, hit := range hits {
+		if hit.EntryID == entryID {
+			score := hit.Score
+			out.Rank, out.Score = i+1, &score
+			break
+		}
+	}
+	out.WouldMatch = out.Rank > 0 && out.Rank <= req.TopK
+
+	if reader, ok := h.idx.(searchindex.VectorReader); ok {
+		vecs, err := reader.EntryVectors(r.Context(), actorInfo.ActorID, req.MemoryID, []string{entryID})
+		if err != nil {
+			log.Warn().Err(err).Str("entryId", entryID).Msg("explain vector lookup failed")
+		} else {
+			stored, indexed := vecs[entryID]
+			out.Indexed = &indexed
+			if indexed {
+				sim := services.CosineSimilarity(vec, stored)
+				out.VectorSimilarity = &sim
+			} else {
+				out.Reasons = append(out.Reasons, "the entry is not in the search index yet; it is indexed asynchronously through the outbox")
+			}
+		}
+	}
+	if req.SessionID != "" {
+		passed := entry.SessionID == req.SessionID
+		out.Filters = append(out.Filters, explainFilter{Filter: "sessionId", Value: req.SessionID, Passed: passed})
+		if !passed {
+			out.Reasons = append(out.Reasons, fmt.Sprintf("the entry belongs to session %q, not %q", entry.SessionID, req.SessionID))
+		}
+	}
+	if window.Since != nil {
+		passed := !entry.CreationTime.Before(*window.Since)
+		out.Filters = append(out.Filters, explainFilter{Filter: "since", Value: window.Since.Format(time.RFC3339), Passed: passed})
+		if !passed {
+			out.Reasons = append(out.Reasons, fmt.Sprintf("the entry was created at %s, before the window starts", entry.CreationTime.Format(time.RFC3339)))
+		}
+	}
+	if window.Until != nil {
+		passed := entry.CreationTime.Before(*window.Until)
+		out.Filters = append(out.Filters, explainFilter{Filter: "until", Value: window.Until.Format(time.RFC3339), Passed: passed})
+		if !passed {
+			out.Reasons = append(out.Reasons, fmt.Sprintf("the entry was created at %s, after the window ends", entry.CreationTime.Format(time.RFC3339)))
+		}
+	}
+	if len(req.Tags) > 0 {
+		pairs := payload.TagPairs(entry.Tags)
+		for _, k := range slices.Sorted(maps.Keys(req.Tags)) {
+			pair := k + "=" + req.Tags[k]
+			passed := slices.Contains(pairs, pair)
+			out.Filters = append(out.Filters, explainFilter{Filter: "tag", Value: pair, Passed: passed})
+			if !passed {
+				out.Reasons = append(out.Reasons, fmt.Sprintf("the entry's tags do not hold %s", pair))
+			}
+		}
+	}
+	keys := payload.TagKeys(entry.Tags)
+	for _, tag := range req.MustNot.Tags {
+		passed := !slices.Contains(keys, tag)
+		out.Filters = append(out.Filters, explainFilter{Filter: "mustNot.tags", Value: tag, Passed: passed})
+		if !passed {
+			out.Reasons = append(out.Reasons, fmt.Sprintf("the entry carries the excluded tag %q", tag))
+		}
+	}
+	if slices.Contains(req.MustNot.EntryIDs, entryID) {
+		out.Filters = append(out.Filters, explainFilter{Filter: "mustNot.entryIds", Value: entryID, Passed: false})
+		out.Reasons = append(out.Reasons, "the entry is excluded by mustNot.entryIds")
+	}
+	if len(out.Terms.Matched) == 0 {
+		out.Reasons = append(out.Reasons, "no query term appears in the entry, so only vector similarity can rank it")
+	}
+	switch {
+	case out.WouldMatch:
+		out.Reasons = append(out.Reasons, fmt.Sprintf("the entry ranks %d of topK %d", out.Rank, req.TopK))
+	case out.Rank > 0:
+		out.Reasons = append(out.Reasons, fmt.Sprintf("the entry ranks %d; raise topK to at least %d or refine the query", out.Rank, out.Rank))
+	default:
+		out.Reasons = append(out.Reasons, fmt.Sprintf("the entry is not among the top %d candidates", depth))
+	}
+	respond.WriteJSON(w, http.StatusOK, out)
+}
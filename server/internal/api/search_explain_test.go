@@ -0,0 +1,100 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/services"
+	"github.com/mycelian/mycelian-memory/server/internal/store"
+)
+
+type explainEntries struct{ store.Entries }
+
+func (explainEntries) GetByID(_ context.Context, _, _, _, entryID string) (*model.MemoryEntry, error) {
+	switch entryID {
+	case "e1":
+		return &model.MemoryEntry{EntryID: "e1", RawEntry: "User moved to Lisbon in May"}, nil
+	case "e9":
+		return &model.MemoryEntry{EntryID: "e9", RawEntry: "Favourite colour is green", SessionID: "s2",
+			Tags: map[string]interface{}{"colour": "green", "draft": true}}, nil
+	}
+	return nil, model.ErrNotFound
+}
+
+type explainStore struct{ contextStore }
+
+func (explainStore) Entries() store.Entries { return explainEntries{} }
+
+// vectorSearch is a mockSearch that can read back the vector of e1 only.
+type vectorSearch struct{ mockSearch }
+
+func (*vectorSearch) EntryVectors(_ context.Context, _, _ string, ids []string) (map[string][]float32, error) {
+	out := map[string][]float32{}
+	for _, id := range ids {
+		if id == "e1" {
+			out[id] = []float32{2, 4}
+		}
+	}
+	return out, nil
+}
+
+func TestHandleExplain(t *testing.T) {
+	idx := &vectorSearch{}
+	h, _ := NewSearchHandler(&mockEmbedder{}, idx, 0.6, &mockAuthorizer{})
+	get := func(query string) (*httptest.ResponseRecorder, SearchExplanation) {
+		req := httptest.NewRequest(http.MethodGet, "/v0/search/explain?"+query, nil)
+		req.Header.Set("Authorization", "Bearer test-api-key")
+		w := httptest.NewRecorder()
+		h.HandleExplain(w, req)
+		var out SearchExplanation
+		_ = json.Unmarshal(w.Body.Bytes(), &out)
+		return w, out
+	}
+	if w, _ := get("vaultId=v1&memoryId=m1&entryId=e1&query=lisbon"); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("explain disabled: expected 503, got %d", w.Code)
+	}
+	h.EnableExplain(services.NewMemoryService(explainStore{}, nil, nil))
+
+	w, out := get("vaultId=v1&memoryId=m1&entryId=e1&query=Where+in+Lisbon&topK=5")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if !out.WouldMatch || out.Rank != 1 || out.Score == nil || out.Indexed == nil || !*out.Indexed ||
+		out.VectorSimilarity == nil || *out.VectorSimilarity < 0.999 || out.Depth != explainDepth {
+		t.Fatalf("unexpected explanation: %s", w.Body.String())
+	}
+	if strings.Join(out.Terms.Matched, ",") != "lisbon" || strings.Join(out.Terms.Missing, ",") != "where" {
+		t.Fatalf("unexpected terms: %+v", out.Terms)
+	}
+
+	w, out = get("vaultId=v1&memoryId=m1&entryId=e9&query=lisbon&sessionId=s1")
+	if w.Code != http.StatusOK || out.WouldMatch || out.Rank != 0 || out.Indexed == nil || *out.Indexed || len(out.Filters) != 1 || out.Filters[0].Passed {
+		t.Fatalf("unexpected explanation: %s", w.Body.String())
+	}
+	if idx.filter.SessionID != "s1" || len(out.Reasons) != 4 {
+		t.Fatalf("expected session filter and four reasons, got %+v / %v", idx.filter, out.Reasons)
+	}
+
+	w, out = get("vaultId=v1&memoryId=m1&entryId=e9&query=colour&tag=colour=green&tag=seen=true&mustNotTag=draft&mustNotEntryId=e2")
+	if w.Code != http.StatusOK || len(out.Filters) != 3 || out.Filters[0].Value != "colour=green" || !out.Filters[0].Passed ||
+		out.Filters[1].Value != "seen=true" || out.Filters[1].Passed || out.Filters[2].Filter != "mustNot.tags" || out.Filters[2].Passed {
+		t.Fatalf("unexpected tag filters: %s", w.Body.String())
+	}
+	if idx.filter.Tags["colour"] != "green" || len(idx.filter.MustNot.Tags) != 1 || len(idx.filter.MustNot.EntryIDs) != 1 {
+		t.Fatalf("tags and mustNot must reach the index: %+v", idx.filter)
+	}
+
+	if w, _ := get("vaultId=v1&memoryId=m1&entryId=missing&query=x"); w.Code != http.StatusNotFound {
+		t.Fatalf("unknown entry: expected 404, got %d", w.Code)
+	}
+	for _, q := range []string{"memoryId=m1&entryId=e1&query=x", "vaultId=v1&memoryId=m1&entryId=e1", "vaultId=v1&memoryId=m1&entryId=e1&query=x&topK=0", "vaultId=v1&memoryId=m1&entryId=e1&query=x&tag=novalue"} {
+		if w, _ := get(q); w.Code != http.StatusBadRequest {
+			t.Fatalf("%q: expected 400, got %d", q, w.Code)
+		}
+	}
+}
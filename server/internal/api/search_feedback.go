@@ -0,0 +1,189 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	respond "github.com/mycelian/mycelian-memory/server/internal/api/respond"
+	"github.com/mycelian/mycelian-memory/server/internal/auth"
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/services"
+)
+
+// SearchFeedbackRequest represents the payload for POST /v0/search/feedback.
+//
+//	queryId        – required, as returned by POST /v0/search
+//	usefulEntryIds – entry IDs from that result set the caller found useful;
+//	                 an empty list records that no result was useful
+type SearchFeedbackRequest struct {
+	QueryID        string   `json:"queryId"`
+	UsefulEntryIDs []string `json:"usefulEntryIds"`
+}
+
+// HandleFeedback handles POST /v0/search/feedback
+func (h *SearchHandler) HandleFeedback(w http.ResponseWriter, r *http.Request) {
+	apiKey, err := auth.ExtractAPIKey(r)
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+	actorInfo, err := h.authorizer.Authorize(r.Context(), apiKey, "memory.search", "default")
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+	if h.queryLog == nil {
+		respond.WriteError(w, http.StatusNotImplemented, "search query log is disabled")
+		return
+	}
+
+	var req SearchFeedbackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respond.WriteBadRequest(w, "Invalid JSON")
+		return
+	}
+	req.QueryID = strings.TrimSpace(req.QueryID)
+	if req.QueryID == "" {
+		respond.WriteBadRequest(w, "queryId is required")
+		return
+	}
+
+	if err := h.queryLog.SubmitFeedback(r.Context(), actorInfo.ActorID, req.QueryID, req.UsefulEntryIDs); err != nil {
+		switch {
+		case errors.Is(err, model.ErrNotFound):
+			respond.WriteNotFound(w, "Query not found")
+		case errors.Is(err, model.ErrValidation):
+			respond.WriteBadRequest(w, err.Error())
+		default:
+			log.Error().Err(err).Str("queryId", req.QueryID).Msg("search feedback failed")
+			respond.WriteInternalError(w, err.Error())
+		}
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleMetrics handles GET /v0/search/metrics?memoryId=&since=&tz=
+// since is RFC3339, a date, "today" or "yesterday" (dates resolve in tz or
+// the actor's zone); all filters are optional.
+func (h *SearchHandler) HandleMetrics(w http.ResponseWriter, r *http.Request) {
+	apiKey, err := auth.ExtractAPIKey(r)
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+	actorInfo, err := h.authorizer.Authorize(r.Context(), apiKey, "memory.search", "default")
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+	if h.queryLog == nil {
+		respond.WriteError(w, http.StatusNotImplemented, "search query log is disabled")
+		return
+	}
+
+	q := r.URL.Query()
+	var since *time.Time
+	if v := q.Get("since"); v != "" {
+		loc, err := requestLocation(r.Context(), r, h.actors, actorInfo.ActorID)
+		if err != nil {
+			writeLocationError(w, err)
+			return
+		}
+		t, err := parseTimeParam(v, loc, time.Now())
+		if err != nil {
+			respond.WriteBadRequest(w, "invalid since; expected RFC3339, YYYY-MM-DD, today or yesterday")
+			return
+		}
+		since = &t
+	}
+
+	out, err := h.queryLog.Precision(r.Context(), actorInfo.ActorID, q.Get("memoryId"), since)
+	if err != nil {
+		respond.WriteInternalError(w, err.Error())
+		return
+	}
+	respond.WriteJSON(w, http.StatusOK, out)
+}
+
+// HandleExport handles GET /v0/search/log:export?format=&memoryId=&since=&limit=&runTag=&tz=
+// It writes logged queries, oldest first, as plain text for IR evaluation
+// tools: format=run (the default) is a TREC run of the returned entries
+// with their scores, qrels the judgments recorded through feedback and
+// topics the query texts. Filters are as for HandleMetrics.
+func (h *SearchHandler) HandleExport(w http.ResponseWriter, r *http.Request) {
+	apiKey, err := auth.ExtractAPIKey(r)
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+	actorInfo, err := h.authorizer.Authorize(r.Context(), apiKey, "memory.search", "default")
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+	if h.queryLog == nil {
+		respond.WriteError(w, http.StatusNotImplemented, "search query log is disabled")
+		return
+	}
+
+	q := r.URL.Query()
+	format := q.Get("format")
+	if format == "" {
+		format = services.TRECRun
+	}
+	if !services.ValidTRECFormat(format) {
+		respond.WriteBadRequest(w, "format must be run, qrels or topics")
+		return
+	}
+	runTag := q.Get("runTag")
+	if runTag == "" {
+		runTag = "mycelian"
+	}
+	if strings.ContainsFunc(runTag, func(r rune) bool { return r <= ' ' }) {
+		respond.WriteBadRequest(w, "runTag must not contain whitespace")
+		return
+	}
+	limit := 0
+	if v := q.Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil {
+			respond.WriteBadRequest(w, "invalid limit")
+			return
+		}
+	}
+	var since *time.Time
+	if v := q.Get("since"); v != "" {
+		loc, err := requestLocation(r.Context(), r, h.actors, actorInfo.ActorID)
+		if err != nil {
+			writeLocationError(w, err)
+			return
+		}
+		t, err := parseTimeParam(v, loc, time.Now())
+		if err != nil {
+			respond.WriteBadRequest(w, "invalid since; expected RFC3339, YYYY-MM-DD, today or yesterday")
+			return
+		}
+		since = &t
+	}
+
+	queries, err := h.queryLog.ExportQueries(r.Context(), actorInfo.ActorID, q.Get("memoryId"), since, limit)
+	if err != nil {
+		if errors.Is(err, model.ErrValidation) {
+			respond.WriteBadRequest(w, err.Error())
+			return
+		}
+		respond.WriteInternalError(w, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="search-`+format+`.txt"`)
+	if err := services.WriteTREC(w, format, queries, runTag); err != nil {
+		log.Warn().Err(err).Msg("search log export interrupted")
+	}
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/services"
+	"github.com/mycelian/mycelian-memory/server/internal/store"
+)
+
+// memSearchLog is an in-memory store.SearchLog.
+type memSearchLog struct {
+	queries map[string]*model.SearchQuery
+}
+
+func (m *memSearchLog) RecordQuery(_ context.Context, q *model.SearchQuery) (*model.SearchQuery, error) {
+	out := *q
+	out.QueryID = "q-1"
+	m.queries[out.QueryID] = &out
+	return &out, nil
+}
+func (m *memSearchLog) GetQuery(_ context.Context, _ string, id string) (*model.SearchQuery, error) {
+	if q, ok := m.queries[id]; ok {
+		return q, nil
+	}
+	return nil, model.ErrNotFound
+}
+func (m *memSearchLog) RecordFeedback(_ context.Context, _ string, id string, useful []string) error {
+	m.queries[id].UsefulEntryIDs = useful
+	return nil
+}
+func (m *memSearchLog) Precision(context.Context, string, string, *time.Time) (*model.SearchPrecision, error) {
+	return &model.SearchPrecision{Queries: len(m.queries)}, nil
+}
+func (m *memSearchLog) List(context.Context, string, string, *time.Time, int) ([]*model.SearchQuery, error) {
+	var out []*model.SearchQuery
+	for _, q := range m.queries {
+		out = append(out, q)
+	}
+	return out, nil
+}
+
+// searchLogOnlyStore satisfies store.Store; only SearchLog is used by these tests.
+type searchLogOnlyStore struct {
+	store.Store
+	sl store.SearchLog
+}
+
+func (s searchLogOnlyStore) SearchLog() store.SearchLog { return s.sl }
+
+func doJSON(t *testing.T, h http.HandlerFunc, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Authorization", "Bearer test-api-key")
+	w := httptest.NewRecorder()
+	h(w, req)
+	return w
+}
+
+func TestSearchFeedback_RoundTrip(t *testing.T) {
+	sl := &memSearchLog{queries: map[string]*model.SearchQuery{}}
+	h, _ := NewSearchHandler(&mockEmbedder{}, &mockSearch{}, 0.6, &mockAuthorizer{})
+	h.EnableQueryLog(services.NewSearchLogService(searchLogOnlyStore{sl: sl}))
+
+	w := doJSON(t, h.HandleSearch, http.MethodPost, "/v0/search", `{"memoryId":"m1","query":"hi"}`)
+	var resp struct {
+		QueryID string `json:"queryId"`
+	}
+	_ = json.NewDecoder(w.Body).Decode(&resp)
+	if w.Code != http.StatusOK || resp.QueryID != "q-1" {
+		t.Fatalf("expected queryId in search response, code=%d id=%q", w.Code, resp.QueryID)
+	}
+	if got := sl.queries["q-1"]; got.MemoryID != "m1" || len(got.EntryIDs) != 1 || got.EntryIDs[0] != "e1" {
+		t.Fatalf("unexpected logged query: %+v", got)
+	}
+
+	if w := doJSON(t, h.HandleFeedback, http.MethodPost, "/v0/search/feedback", `{"queryId":"q-1","usefulEntryIds":["e1"]}`); w.Code != http.StatusNoContent {
+		t.Fatalf("feedback: expected 204, got %d", w.Code)
+	}
+	if w := doJSON(t, h.HandleFeedback, http.MethodPost, "/v0/search/feedback", `{"queryId":"q-1","usefulEntryIds":["nope"]}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("feedback with unreturned entry: expected 400, got %d", w.Code)
+	}
+	if w := doJSON(t, h.HandleFeedback, http.MethodPost, "/v0/search/feedback", `{"queryId":"missing"}`); w.Code != http.StatusNotFound {
+		t.Fatalf("feedback for unknown query: expected 404, got %d", w.Code)
+	}
+	if w := doJSON(t, h.HandleMetrics, http.MethodGet, "/v0/search/metrics?since=bad", ""); w.Code != http.StatusBadRequest {
+		t.Fatalf("metrics with bad since: expected 400, got %d", w.Code)
+	}
+	if w := doJSON(t, h.HandleMetrics, http.MethodGet, "/v0/search/metrics?memoryId=m1", ""); w.Code != http.StatusOK {
+		t.Fatalf("metrics: expected 200, got %d", w.Code)
+	}
+
+	if w := doJSON(t, h.HandleExport, http.MethodGet, "/v0/search/log:export?runTag=test", ""); w.Code != http.StatusOK || !strings.HasPrefix(w.Body.String(), "q-1 Q0 e1 1 ") || !strings.HasSuffix(w.Body.String(), " test\n") {
+		t.Fatalf("run export: %d %q", w.Code, w.Body.String())
+	}
+	if w := doJSON(t, h.HandleExport, http.MethodGet, "/v0/search/log:export?format=topics", ""); w.Body.String() != "q-1\thi\n" {
+		t.Fatalf("topics export: %q", w.Body.String())
+	}
+	for _, bad := range []string{"format=trec", "runTag=a%20b", "limit=0x", "limit=-1"} {
+		if w := doJSON(t, h.HandleExport, http.MethodGet, "/v0/search/log:export?"+bad, ""); w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", bad, w.Code)
+		}
+	}
+}
+
+func TestSearchFeedback_DisabledByDefault(t *testing.T) {
+	h, _ := NewSearchHandler(&mockEmbedder{}, &mockSearch{}, 0.6, &mockAuthorizer{})
+	if w := doJSON(t, h.HandleFeedback, http.MethodPost, "/v0/search/feedback", `{"queryId":"q"}`); w.Code != http.StatusNotImplemented {
+		t.Fatalf("expected 501 when query log disabled, got %d", w.Code)
+	}
+	w := doJSON(t, h.HandleSearch, http.MethodPost, "/v0/search", `{"memoryId":"m1","query":"hi"}`)
+	if bytes.Contains(w.Body.Bytes(), []byte("queryId")) {
+		t.Fatalf("queryId must be absent when query log disabled")
+	}
+}
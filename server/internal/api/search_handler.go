@@ -1,8 +1,11 @@
 package api
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/rs/zerolog/log"
@@ -10,24 +13,234 @@ import (
 	respond "github.com/mycelian/mycelian-memory/server/internal/api/respond"
 	"github.com/mycelian/mycelian-memory/server/internal/auth"
 	emb "github.com/mycelian/mycelian-memory/server/internal/embeddings"
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/reranker"
 	"github.com/mycelian/mycelian-memory/server/internal/searchindex"
+	"github.com/mycelian/mycelian-memory/server/internal/services"
 )
 
+// defaultRecencyHalfLife is the age at which rankBy=recency halves a score.
+const defaultRecencyHalfLife = 7 * 24 * time.Hour
+
+// recencyCandidateFactor is how many times topK hits are fetched before
+// recency ranking re-sorts and trims them.
+const recencyCandidateFactor = 3
+
 // SearchHandler handles POST /api/search using native searchindex and embeddings.
 type SearchHandler struct {
 	emb        emb.EmbeddingProvider
 	idx        searchindex.Index
 	alpha      float32
 	authorizer auth.Authorizer
+	queryLog   *services.SearchLogService // nil disables query logging
+	contexts   *services.MemoryService    // nil disables context prefetch
+	signals    *services.MemoryService    // nil disables signal ranking
+	signalW    float64
+	halfLife   time.Duration           // recency decay for rankBy=recency|hybrid
+	actors     *services.ActorService  // nil resolves metrics dates in UTC unless ?tz= is given
+	access     *services.MemoryService // nil disables lastAccessedTime updates for hits
+	explain    *services.MemoryService // nil disables GET /v0/search/explain
+	aliases    *services.MemoryService // nil disables entity alias query expansion
+	sessions   *services.MemoryService // nil rejects window=sinceSessionStart
+	defaults   *services.ActorService  // nil requires memoryId in every search
+	freshness  *services.MemoryService // nil omits indexFreshness
+	trash      *services.MemoryService // nil returns trashed entries still in the index
+	expiry     *services.MemoryService // nil returns expired entries not yet reaped
+	corrected  *services.MemoryService // nil returns corrected entries as ordinary hits
+	reranker   reranker.Reranker       // nil keeps the index's order
+	rerankTime time.Duration
+	profiles   map[string]model.RankingProfile
+	// profileSignals serves profiles that turn on signal ranking when the
+	// server has it off.
+	profileSignals *services.MemoryService
+	limits         SearchLimits
+	inFlight       actorSemaphore
+	scopes         *services.MemoryService // nil rejects memoryTitles and memoryPattern
+	shadow         *ShadowSearch           // nil disables shadow search
+	recordShadow   func(ShadowComparison)
+	shadowRuns     sync.WaitGroup
 }
 
 func NewSearchHandler(emb emb.EmbeddingProvider, idx searchindex.Index, alpha float32, authorizer auth.Authorizer) (*SearchHandler, error) {
 	if alpha < 0.0 || alpha > 1.0 {
 		return nil, fmt.Errorf("alpha parameter must be in the range [0.0, 1.0], got %f", alpha)
 	}
-	return &SearchHandler{emb: emb, idx: idx, alpha: alpha, authorizer: authorizer}, nil
+	return &SearchHandler{emb: emb, idx: idx, alpha: alpha, authorizer: authorizer, limits: SearchLimits{MaxTopK: defaultMaxTopK}, halfLife: defaultRecencyHalfLife}, nil
+}
+
+// EnableSearchLimits replaces the default topK ceiling (100, no concurrency
+// limit). Requests above an actor's topK ceiling get 400; searches beyond its
+// concurrency limit get 429 so one agent cannot saturate the embedder.
+func (h *SearchHandler) EnableSearchLimits(l SearchLimits) { h.limits = l }
+
+// EnableQueryLog records every served query and its result IDs, and returns a
+// queryId clients can reference in POST /v0/search/feedback.
+func (h *SearchHandler) EnableQueryLog(svc *services.SearchLogService) { h.queryLog = svc }
+
+// EnableIndexFreshness adds an "indexFreshness" object reporting how far the
+// search index trails the memory's newest entry, so agents can await
+// consistency or warn that results may be incomplete.
+func (h *SearchHandler) EnableIndexFreshness(svc *services.MemoryService) { h.freshness = svc }
+
+// addIndexFreshness sets resp["indexFreshness"] when enabled (best-effort;
+// never fails the search).
+func (h *SearchHandler) addIndexFreshness(r *http.Request, actorID, memoryID string, resp map[string]interface{}) {
+	if h.freshness == nil {
+		return
+	}
+	f, err := h.freshness.IndexFreshness(r.Context(), actorID, memoryID)
+	if err != nil {
+		log.Warn().Err(err).Str("memoryId", memoryID).Msg("index freshness unavailable")
+		return
+	}
+	if f != nil {
+		resp["indexFreshness"] = f
+	}
+}
+
+// EnableContextPrefetch adds a "contexts" map (memoryId -> latest context) for
+// every memory represented in the hits, loaded with a single batched query.
+func (h *SearchHandler) EnableContextPrefetch(svc *services.MemoryService) { h.contexts = svc }
+
+// EnableActorTimeZones resolves date filters in the actor's saved time zone.
+func (h *SearchHandler) EnableActorTimeZones(svc *services.ActorService) { h.actors = svc }
+
+// EnableAccessTracking stamps lastAccessedTime on every returned hit so LRU
+// retention keeps entries that are still being found.
+func (h *SearchHandler) EnableAccessTracking(svc *services.MemoryService) { h.access = svc }
+
+// EnableTrashFilter drops hits of trashed entries, which stay in the index
+// until the trash is purged.
+func (h *SearchHandler) EnableTrashFilter(svc *services.MemoryService) { h.trash = svc }
+
+// EnableExpiryFilter drops hits of entries past their expiration time, which
+// stay in the index until the retention reaper deletes them.
+func (h *SearchHandler) EnableExpiryFilter(svc *services.MemoryService) { h.expiry = svc }
+
+// EnableCorrectionFilter returns the newest correction in place of a hit of
+// a corrected entry, or with includeSuperseded marks the hit supersededBy.
+func (h *SearchHandler) EnableCorrectionFilter(svc *services.MemoryService) { h.corrected = svc }
+
+// EnableReranker rescores every search's candidates with r before the other
+// ranking stages. A rerank that fails or takes longer than timeout leaves
+// the hits in index order.
+func (h *SearchHandler) EnableReranker(r reranker.Reranker, timeout time.Duration) {
+	h.reranker = r
+	h.rerankTime = timeout
+}
+
+// EnableSignalRanking boosts entries agents marked useful and demotes ones
+// marked incorrect or outdated; weight scales the effect.
+func (h *SearchHandler) EnableSignalRanking(svc *services.MemoryService, weight float64) {
+	h.signals = svc
+	h.signalW = weight
+}
+
+// EnableAliasExpansion adds the other names of every entity a query mentions,
+// per the memory's alias registry, before embedding and keyword search.
+func (h *SearchHandler) EnableAliasExpansion(svc *services.MemoryService) { h.aliases = svc }
+
+// expandQuery returns the query to embed and match; the request's own query
+// when expansion is disabled or fails.
+func (h *SearchHandler) expandQuery(r *http.Request, actorID string, req *SearchRequest) string {
+	if h.aliases == nil {
+		return req.Query
+	}
+	expanded, err := h.aliases.ExpandSearchQuery(r.Context(), actorID, req.MemoryID, req.Query)
+	if err != nil {
+		log.Warn().Err(err).Str("memoryId", req.MemoryID).Msg("alias query expansion failed")
+		return req.Query
+	}
+	return expanded
 }
 
+// EnableDefaultMemory searches the actor's default memory when a request
+// omits memoryId.
+func (h *SearchHandler) EnableDefaultMemory(svc *services.ActorService) { h.defaults = svc }
+
+// defaultMemory returns the resolver decodeSearchRequest uses for an omitted
+// memoryId, or nil when defaults are disabled. Its errors are *searchError:
+// 400 without a default memory, 404 or 500 when it cannot be read.
+func (h *SearchHandler) defaultMemory(r *http.Request, actorID string) func() (string, error) {
+	if h.defaults == nil {
+		return nil
+	}
+	return func() (string, error) {
+		_, memoryID, err := h.defaults.DefaultMemory(r.Context(), actorID)
+		switch {
+		case errors.Is(err, model.ErrValidation):
+			return "", &searchError{http.StatusBadRequest, "memoryId is required: " + err.Error()}
+		case errors.Is(err, model.ErrNotFound):
+			return "", &searchError{http.StatusNotFound, err.Error()}
+		case err != nil:
+			log.Error().Err(err).Str("actorId", actorID).Msg("default memory lookup failed")
+			return "", &searchError{http.StatusInternalServerError, "default memory unavailable"}
+		}
+		return memoryID, nil
+	}
+}
+
+// EnableSessionWindows resolves window=sinceSessionStart to the creation time
+// of the session's first entry.
+func (h *SearchHandler) EnableSessionWindows(svc *services.MemoryService) { h.sessions = svc }
+
+// timeWindow resolves the request's window or since/until bounds in the
+// request location; the zero TimeWindow when none is set. Errors wrap
+// model.ErrValidation unless the location or session lookup fails.
+func (h *SearchHandler) timeWindow(r *http.Request, actorID string, req *SearchRequest) (services.TimeWindow, error) {
+	if req.Window == "" && req.Since == "" && req.Until == "" {
+		return services.TimeWindow{}, nil
+	}
+	loc, err := requestLocation(r.Context(), r, h.actors, actorID)
+	if err != nil {
+		return services.TimeWindow{}, err
+	}
+	now := time.Now()
+	if req.Window != "" {
+		if h.sessions != nil {
+			return h.sessions.ResolveSearchWindow(r.Context(), actorID, req.MemoryID, req.SessionID, req.Window, loc, now)
+		}
+		if strings.EqualFold(req.Window, services.WindowSinceSessionStart) {
+			return services.TimeWindow{}, fmt.Errorf("%w: window %s is not supported", model.ErrValidation, services.WindowSinceSessionStart)
+		}
+		return services.ResolveTimeWindow(req.Window, loc, now)
+	}
+	var tw services.TimeWindow
+	for _, b := range []struct {
+		name, value string
+		dst         **time.Time
+	}{{"since", req.Since, &tw.Since}, {"until", req.Until, &tw.Until}} {
+		if b.value == "" {
+			continue
+		}
+		t, err := parseTimeParam(b.value, loc, now)
+		if err != nil {
+			return services.TimeWindow{}, fmt.Errorf("%w: invalid %s; expected RFC3339, YYYY-MM-DD, today or yesterday", model.ErrValidation, b.name)
+		}
+		*b.dst = &t
+	}
+	if tw.Since != nil && tw.Until != nil && !tw.Since.Before(*tw.Until) {
+		return services.TimeWindow{}, fmt.Errorf("%w: since must be before until", model.ErrValidation)
+	}
+	return tw, nil
+}
+
+// writeTimeWindowError maps timeWindow errors to HTTP responses.
+func writeTimeWindowError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, model.ErrValidation):
+		respond.WriteBadRequest(w, err.Error())
+	case errors.Is(err, model.ErrNotFound):
+		respond.WriteNotFound(w, "session has no entries")
+	default:
+		respond.WriteInternalError(w, err.Error())
+	}
+}
+
+// EnableRecencyRanking replaces the default half-life (one week) of the time
+// decay applied by rankBy=recency and rankBy=hybrid.
+func (h *SearchHandler) EnableRecencyRanking(halfLife time.Duration) { h.halfLife = halfLife }
+
 func (h *SearchHandler) HandleSearch(w http.ResponseWriter, r *http.Request) {
 	// Extract API key from Authorization header
 	apiKey, err := auth.ExtractAPIKey(r)
@@ -43,58 +256,210 @@ func (h *SearchHandler) HandleSearch(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	req, err := decodeSearchRequest(w, r)
+	req, err := decodeSearchRequest(w, r, h.defaultMemory(r, actorInfo.ActorID))
+	if err != nil {
+		var se *searchError
+		if errors.As(err, &se) {
+			respond.WriteError(w, se.status, se.msg)
+			return
+		}
+		respond.WriteBadRequest(w, err.Error())
+		return
+	}
+	rk, err := h.ranking(req)
 	if err != nil {
 		respond.WriteBadRequest(w, err.Error())
 		return
 	}
+	if max := h.limits.maxTopK(actorInfo.ActorID); max > 0 && req.TopK > max {
+		respond.WriteBadRequest(w, fmt.Sprintf("topK %d exceeds the maximum of %d", req.TopK, max))
+		return
+	}
 	if h.emb == nil || h.idx == nil {
 		respond.WriteError(w, http.StatusServiceUnavailable, "search not configured")
 		return
 	}
+	if !h.inFlight.tryAcquire(actorInfo.ActorID, h.limits.maxConcurrent(actorInfo.ActorID)) {
+		log.Warn().Str("actorId", actorInfo.ActorID).Msg("search concurrency limit reached")
+		w.Header().Set("Retry-After", "1")
+		respond.WriteError(w, http.StatusTooManyRequests, "too many concurrent searches; retry shortly")
+		return
+	}
+	defer h.inFlight.release(actorInfo.ActorID)
+
+	window, err := h.timeWindow(r, actorInfo.ActorID, req)
+	if err != nil {
+		writeTimeWindowError(w, err)
+		return
+	}
+
+	if req.TitleScoped() {
+		log.Info().Str("vaultId", req.VaultID).Strs("memoryTitles", req.MemoryTitles).Str("memoryPattern", req.MemoryPattern).Str("query", req.Query).Int("topK", req.TopK).Str("actorId", actorInfo.ActorID).Msg("scoped search request received")
+		resp, err := h.scopedSearch(r, actorInfo.ActorID, req, rk, window)
+		if err != nil {
+			writeSearchError(w, err)
+			return
+		}
+		respond.WriteJSON(w, http.StatusOK, resp)
+		return
+	}
 
 	log.Info().Str("memoryId", req.MemoryID).Str("query", req.Query).Int("topK", req.TopK).Str("actorId", actorInfo.ActorID).Msg("search request received")
 
-	vec, err := h.emb.Embed(r.Context(), req.Query)
+	resp, err := h.search(r, actorInfo.ActorID, req, rk, window)
 	if err != nil {
-		log.Error().Err(err).Str("query", req.Query).Msg("embedding failed")
-		respond.WriteError(w, http.StatusInternalServerError, "embedding service unavailable")
+		writeSearchError(w, err)
+		return
+	}
+
+	// Latest context
+	ctxStr, ts, err := h.idx.LatestContext(r.Context(), actorInfo.ActorID, req.MemoryID)
+	if err != nil {
+		respond.WriteError(w, http.StatusInternalServerError, "latest context unavailable")
+		return
+	}
+	resp["latestContext"] = ctxStr
+	resp["contextTimestamp"] = ts.Format(time.RFC3339)
+	h.addIndexFreshness(r, actorInfo.ActorID, req.MemoryID, resp)
+
+	respond.WriteJSON(w, http.StatusOK, resp)
+}
+
+// searchError is a failed search step and the status it is served with.
+type searchError struct {
+	status int
+	msg    string
+}
+
+func (e *searchError) Error() string { return e.msg }
+
+// writeSearchError serves err with the status of the *searchError it wraps;
+// any other error is a 500.
+func writeSearchError(w http.ResponseWriter, err error) {
+	var se *searchError
+	if errors.As(err, &se) {
+		respond.WriteError(w, se.status, se.msg)
 		return
 	}
+	log.Error().Err(err).Msg("search failed")
+	respond.WriteError(w, http.StatusInternalServerError, "search service unavailable")
+}
+
+// search runs one validated request with ranking rk: embedding, index search,
+// ranking and the best-effort extras. It returns the response fields other
+// than the latest context, or a *searchError.
+func (h *SearchHandler) search(r *http.Request, actorID string, req *SearchRequest, rk searchRanking, window services.TimeWindow) (map[string]interface{}, error) {
+	query := h.expandQuery(r, actorID, req)
+	vec, err := h.emb.Embed(r.Context(), query)
+	if err != nil {
+		log.Error().Err(err).Str("query", query).Msg("embedding failed")
+		return nil, &searchError{http.StatusInternalServerError, "embedding service unavailable"}
+	}
 	log.Debug().Int("vectorLength", len(vec)).Msg("embedding generated")
 
-	hits, err := h.idx.Search(r.Context(), actorInfo.ActorID, req.MemoryID, req.Query, vec, req.TopK, h.alpha)
+	filter := req.Filter(window)
+	hits, err := h.idx.Search(r.Context(), actorID, req.MemoryID, query, vec, rk.candidates(req), rk.alpha, filter)
 	if err != nil {
 		log.Error().Err(err).Str("memoryId", req.MemoryID).Str("query", req.Query).Msg("search failed")
-		respond.WriteError(w, http.StatusInternalServerError, "search service unavailable")
-		return
+		return nil, &searchError{http.StatusInternalServerError, "search service unavailable"}
 	}
 	log.Info().Int("hitCount", len(hits)).Str("memoryId", req.MemoryID).Msg("search completed")
 
+	if hits, err = h.rank(r.Context(), actorID, req, rk, filter, hits); err != nil {
+		return nil, err
+	}
+	if len(hits) > req.TopK {
+		hits = hits[:req.TopK]
+	}
+	h.maybeShadow(r, actorID, req, rk, query, vec, window, hits)
+
+	h.touchHits(r, actorID, req.MemoryID, hits)
+
 	// Build response consistent with previous keys
 	resp := map[string]interface{}{
 		"entries": hits,
 		"count":   len(hits),
 	}
+	if query != req.Query {
+		resp["expandedQuery"] = query
+	}
+	if rk.profile != "" {
+		resp["profile"] = rk.profile
+	}
+	if window.Since != nil || window.Until != nil {
+		resp["timeWindow"] = window
+	}
 
-	// Latest context
-	ctxStr, ts, err := h.idx.LatestContext(r.Context(), actorInfo.ActorID, req.MemoryID)
-	if err != nil {
-		respond.WriteError(w, http.StatusInternalServerError, "latest context unavailable")
-		return
+	// Query log (best-effort; never fails the search)
+	if h.queryLog != nil {
+		ids := make([]string, 0, len(hits))
+		scores := make([]float64, 0, len(hits))
+		for _, hit := range hits {
+			ids = append(ids, hit.EntryID)
+			scores = append(scores, hit.Score)
+		}
+		q, err := h.queryLog.RecordQuery(r.Context(), &model.SearchQuery{
+			ActorID: actorID, MemoryID: req.MemoryID, Query: req.Query, TopK: req.TopK, Alpha: rk.alpha, EntryIDs: ids, Scores: scores,
+		})
+		if err != nil {
+			log.Warn().Err(err).Str("memoryId", req.MemoryID).Msg("search query log failed")
+		} else {
+			resp["queryId"] = q.QueryID
+		}
 	}
-	resp["latestContext"] = ctxStr
-	resp["contextTimestamp"] = ts.Format(time.RFC3339)
+
+	h.prefetchContexts(r, actorID, req.MemoryID, hits, resp)
 
 	// Best-matching context
-	best, bts, score, err := h.idx.BestContext(r.Context(), actorInfo.ActorID, req.MemoryID, req.Query, vec, h.alpha)
+	best, bts, score, err := h.idx.BestContext(r.Context(), actorID, req.MemoryID, query, vec, rk.alpha)
 	if err != nil {
-		respond.WriteError(w, http.StatusInternalServerError, "best context unavailable")
-		return
+		return nil, &searchError{http.StatusInternalServerError, "best context unavailable"}
 	}
 	resp["bestContext"] = best
 	resp["bestContextTimestamp"] = bts.Format(time.RFC3339)
 	resp["bestContextScore"] = score
+	return resp, nil
+}
 
-	respond.WriteJSON(w, http.StatusOK, resp)
+// touchHits stamps lastAccessedTime on the hits when access tracking is
+// enabled (best-effort; never fails the search).
+func (h *SearchHandler) touchHits(r *http.Request, actorID, memoryID string, hits []model.SearchHit) {
+	if h.access == nil || len(hits) == 0 {
+		return
+	}
+	ids := make([]string, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.EntryID
+	}
+	if err := h.access.TouchEntries(r.Context(), actorID, ids); err != nil {
+		log.Warn().Err(err).Str("memoryId", memoryID).Msg("search access tracking failed")
+	}
+}
+
+// prefetchContexts sets resp["contexts"] to the latest context of every
+// memory in the hits when enabled (best-effort; never fails the search).
+func (h *SearchHandler) prefetchContexts(r *http.Request, actorID, memoryID string, hits []model.SearchHit, resp map[string]interface{}) {
+	if h.contexts == nil {
+		return
+	}
+	ctxs, err := h.contexts.GetLatestContexts(r.Context(), actorID, hitMemoryIDs(hits))
+	if err != nil {
+		log.Warn().Err(err).Str("memoryId", memoryID).Msg("context prefetch failed")
+		return
+	}
+	resp["contexts"] = ctxs
+}
+
+// hitMemoryIDs returns the distinct memory IDs of hits in rank order.
+func hitMemoryIDs(hits []model.SearchHit) []string {
+	seen := make(map[string]bool, len(hits))
+	var ids []string
+	for _, hit := range hits {
+		if hit.MemoryID == "" || seen[hit.MemoryID] {
+			continue
+		}
+		seen[hit.MemoryID] = true
+		ids = append(ids, hit.MemoryID)
+	}
+	return ids
 }
@@ -4,12 +4,17 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http/httptest"
+	"reflect"
 	"testing"
 	"time"
 
 	"github.com/mycelian/mycelian-memory/server/internal/auth"
 	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/services"
+	"github.com/mycelian/mycelian-memory/server/internal/store"
 )
 
 type mockEmbedder struct {
@@ -22,12 +27,14 @@ func (m *mockEmbedder) Embed(ctx context.Context, text string) ([]float32, error
 }
 
 type mockSearch struct {
-	calls int
-	empty bool
+	calls  int
+	empty  bool
+	filter model.SearchFilter
 }
 
-func (m *mockSearch) Search(ctx context.Context, uid, mid, q string, v []float32, k int, a float32) ([]model.SearchHit, error) {
+func (m *mockSearch) Search(ctx context.Context, uid, mid, q string, v []float32, k int, a float32, filter model.SearchFilter) ([]model.SearchHit, error) {
 	m.calls++
+	m.filter = filter
 	if m.empty {
 		return []model.SearchHit{}, nil
 	}
@@ -93,6 +100,69 @@ func TestHandleSearch_EmbedsOnce(t *testing.T) {
 	}
 }
 
+func TestHandleSearch_PassesFilter(t *testing.T) {
+	srch := &mockSearch{}
+	h, _ := NewSearchHandler(&mockEmbedder{}, srch, 0.6, &mockAuthorizer{})
+
+	body := bytes.NewBufferString(`{"memoryId":"m1","query":"hello","sessionId":"s1","tags":{" project ":"alpha"},"mustNot":{"tags":["draft"],"entryIds":["e9"]}}`)
+	req := httptest.NewRequest("POST", "/v0/search", body)
+	req.Header.Set("Authorization", "Bearer test-api-key")
+	w := httptest.NewRecorder()
+	h.HandleSearch(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if srch.filter.SessionID != "s1" {
+		t.Fatalf("sessionId not passed to index: %+v", srch.filter)
+	}
+	if got := srch.filter.Tags; len(got) != 1 || got["project"] != "alpha" {
+		t.Fatalf("tags not passed to index: %+v", got)
+	}
+	if got := srch.filter.MustNot; len(got.Tags) != 1 || got.Tags[0] != "draft" || len(got.EntryIDs) != 1 || got.EntryIDs[0] != "e9" {
+		t.Fatalf("mustNot not passed to index: %+v", got)
+	}
+}
+
+func TestHandleSearch_TimeWindow(t *testing.T) {
+	srch := &mockSearch{}
+	h, _ := NewSearchHandler(&mockEmbedder{}, srch, 0.6, &mockAuthorizer{})
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/v0/search?tz=America/New_York", bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer test-api-key")
+		w := httptest.NewRecorder()
+		h.HandleSearch(w, req)
+		return w
+	}
+
+	w := post(`{"memoryId":"m1","query":"hello","since":"2026-03-01","until":"2026-03-02"}`)
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if f := srch.filter; f.Since == nil || f.Until == nil || f.Since.UTC().Format(time.RFC3339) != "2026-03-01T05:00:00Z" || f.Until.Sub(*f.Since) != 24*time.Hour {
+		t.Fatalf("since/until not resolved in the request zone: %+v", f)
+	}
+	var resp map[string]interface{}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if tw, _ := resp["timeWindow"].(map[string]interface{}); tw["since"] != "2026-03-01T00:00:00-05:00" {
+		t.Fatalf("timeWindow = %v", resp["timeWindow"])
+	}
+
+	if w := post(`{"memoryId":"m1","query":"hello","window":"last7d"}`); w.Code != 200 || srch.filter.Since == nil || srch.filter.Until != nil {
+		t.Fatalf("last7d: code %d filter %+v", w.Code, srch.filter)
+	}
+	for _, body := range []string{
+		`{"memoryId":"m1","query":"hello","window":"last7d","since":"today"}`,
+		`{"memoryId":"m1","query":"hello","window":"fortnight"}`,
+		`{"memoryId":"m1","query":"hello","window":"sinceSessionStart"}`,
+		`{"memoryId":"m1","query":"hello","since":"today","until":"yesterday"}`,
+	} {
+		if w := post(body); w.Code != 400 {
+			t.Fatalf("%s: expected 400, got %d", body, w.Code)
+		}
+	}
+}
+
 // Removed legacy hybrid builder test; current handler uses native index directly
 
 func TestHandleSearch_ResponseMapping(t *testing.T) {
@@ -150,3 +220,346 @@ func TestHandleSearch_NoResults(t *testing.T) {
 		t.Fatalf("expected count 0, got %d", resp.Count)
 	}
 }
+
+type multiMemorySearch struct{ mockSearch }
+
+func (m *multiMemorySearch) Search(context.Context, string, string, string, []float32, int, float32, model.SearchFilter) ([]model.SearchHit, error) {
+	return []model.SearchHit{{EntryID: "e1", MemoryID: "m1"}, {EntryID: "e2", MemoryID: "m2"}, {EntryID: "e3", MemoryID: "m1"}}, nil
+}
+
+// agedSearch returns an old, highly relevant hit ahead of newer ones and
+// records the topK it was asked for.
+type agedSearch struct {
+	mockSearch
+	k int
+}
+
+func (m *agedSearch) Search(_ context.Context, _, _, _ string, _ []float32, k int, _ float32, _ model.SearchFilter) ([]model.SearchHit, error) {
+	m.k = k
+	now := time.Now()
+	old, recent := now.Add(-30*24*time.Hour), now.Add(-time.Hour)
+	return []model.SearchHit{
+		{EntryID: "old", Score: 0.9, CreationTime: &old},
+		{EntryID: "recent", Score: 0.6, CreationTime: &recent},
+		{EntryID: "now", Score: 0.5, CreationTime: &now},
+	}, nil
+}
+
+func TestHandleSearch_RankByRecency(t *testing.T) {
+	search := func(rankBy string) (*agedSearch, []string) {
+		srch := &agedSearch{}
+		h, _ := NewSearchHandler(&mockEmbedder{}, srch, 0.6, &mockAuthorizer{})
+		body := bytes.NewBufferString(`{"memoryId":"m1","query":"hello","topK":2,"rankBy":"` + rankBy + `"}`)
+		req := httptest.NewRequest("POST", "/v0/search", body)
+		req.Header.Set("Authorization", "Bearer test-api-key")
+		w := httptest.NewRecorder()
+		h.HandleSearch(w, req)
+		if w.Code != 200 {
+			t.Fatalf("rankBy=%s: expected 200, got %d: %s", rankBy, w.Code, w.Body.String())
+		}
+		var resp struct {
+			Entries []model.SearchHit `json:"entries"`
+		}
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		var ids []string
+		for _, e := range resp.Entries {
+			ids = append(ids, e.EntryID)
+		}
+		return srch, ids
+	}
+
+	srch, ids := search("relevance")
+	if srch.k != 2 || !reflect.DeepEqual(ids, []string{"old", "recent"}) {
+		t.Fatalf("relevance: k=%d ids=%v", srch.k, ids)
+	}
+	srch, ids = search("recency")
+	if srch.k != 6 || !reflect.DeepEqual(ids, []string{"recent", "now"}) {
+		t.Fatalf("recency: k=%d ids=%v", srch.k, ids)
+	}
+}
+
+// sessionSearch returns several turns of one session ahead of other hits
+// and records the topK it was asked for.
+type sessionSearch struct {
+	mockSearch
+	k int
+}
+
+func (m *sessionSearch) Search(_ context.Context, _, _, _ string, _ []float32, k int, _ float32, _ model.SearchFilter) ([]model.SearchHit, error) {
+	m.k = k
+	return []model.SearchHit{
+		{EntryID: "a1", SessionID: "a", Score: 0.9},
+		{EntryID: "a2", SessionID: "a", Score: 0.8},
+		{EntryID: "a3", SessionID: "a", Score: 0.7},
+		{EntryID: "b1", SessionID: "b", Score: 0.6},
+		{EntryID: "x", Score: 0.5},
+	}, nil
+}
+
+func TestHandleSearch_GroupBySession(t *testing.T) {
+	srch := &sessionSearch{}
+	h, _ := NewSearchHandler(&mockEmbedder{}, srch, 0.6, &mockAuthorizer{})
+	w := doJSON(t, h.HandleSearch, "POST", "/v0/search", `{"memoryId":"m1","query":"hi","topK":2,"groupBy":"session"}`)
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Entries []model.SearchHit `json:"entries"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if srch.k != 6 || len(resp.Entries) != 2 {
+		t.Fatalf("k=%d entries=%+v", srch.k, resp.Entries)
+	}
+	if e := resp.Entries[0]; e.EntryID != "a1" || e.SessionHits != 3 || e.SessionID != "a" {
+		t.Fatalf("first group: %+v", e)
+	}
+	if e := resp.Entries[1]; e.EntryID != "b1" || e.SessionHits != 1 {
+		t.Fatalf("second group: %+v", e)
+	}
+
+	for _, body := range []string{
+		`{"memoryId":"m1","query":"hi","groupBy":"memory"}`,
+		`{"memoryId":"m1","query":"hi","groupBy":"session","sessionId":"a"}`,
+	} {
+		if w := doJSON(t, h.HandleSearch, "POST", "/v0/search", body); w.Code != 400 {
+			t.Fatalf("%s: expected 400, got %d", body, w.Code)
+		}
+	}
+}
+
+// reverseReranker scores hits in reverse index order, or fails with err.
+type reverseReranker struct{ err error }
+
+func (r reverseReranker) Rerank(_ context.Context, _ string, docs []string) ([]float64, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	scores := make([]float64, len(docs))
+	for i := range docs {
+		scores[i] = float64(i) / float64(len(docs))
+	}
+	return scores, nil
+}
+
+func TestHandleSearch_Reranker(t *testing.T) {
+	search := func(rr reverseReranker) (int, []model.SearchHit) {
+		srch := &sessionSearch{}
+		h, _ := NewSearchHandler(&mockEmbedder{}, srch, 0.6, &mockAuthorizer{})
+		h.EnableReranker(rr, time.Second)
+		w := doJSON(t, h.HandleSearch, "POST", "/v0/search", `{"memoryId":"m1","query":"hi","topK":2}`)
+		if w.Code != 200 {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var resp struct {
+			Entries []model.SearchHit `json:"entries"`
+		}
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		return srch.k, resp.Entries
+	}
+
+	k, hits := search(reverseReranker{})
+	if k != 6 || len(hits) != 2 || hits[0].EntryID != "x" || hits[1].EntryID != "b1" || hits[0].RerankScore == nil || *hits[0].RerankScore != 0.8 {
+		t.Fatalf("reranked: k=%d hits=%+v", k, hits)
+	}
+	_, hits = search(reverseReranker{err: errors.New("down")})
+	if len(hits) != 2 || hits[0].EntryID != "a1" || hits[0].RerankScore != nil {
+		t.Fatalf("a failed rerank must serve index order: %+v", hits)
+	}
+}
+
+// batchContexts counts LatestForMemories calls to prove contexts load in one query.
+type batchContexts struct {
+	store.Contexts
+	calls int
+	asked []string
+}
+
+func (c *batchContexts) LatestForMemories(_ context.Context, _ string, ids []string) (map[string]*model.MemoryContext, error) {
+	c.calls++
+	c.asked = ids
+	return map[string]*model.MemoryContext{
+		"m1": {MemoryID: "m1", ContextID: "c1", Context: "one"},
+		"m2": {MemoryID: "m2", ContextID: "c2", Context: "two"},
+	}, nil
+}
+
+func TestHandleSearch_ContextPrefetch(t *testing.T) {
+	ctxs := &batchContexts{}
+	h, _ := NewSearchHandler(&mockEmbedder{}, &multiMemorySearch{}, 0.6, &mockAuthorizer{})
+	h.EnableContextPrefetch(services.NewMemoryService(batchContextStore{c: ctxs}, nil, nil))
+
+	w := doJSON(t, h.HandleSearch, "POST", "/v0/search", `{"memoryId":"m1","query":"hi"}`)
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	var resp struct {
+		Contexts map[string]model.MemoryContext `json:"contexts"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if ctxs.calls != 1 || !reflect.DeepEqual(ctxs.asked, []string{"m1", "m2"}) {
+		t.Fatalf("expected one batched lookup for [m1 m2], got calls=%d ids=%v", ctxs.calls, ctxs.asked)
+	}
+	if resp.Contexts["m1"].Context != "one" || resp.Contexts["m2"].Context != "two" {
+		t.Fatalf("unexpected contexts: %+v", resp.Contexts)
+	}
+}
+
+type batchContextStore struct {
+	store.Store
+	c *batchContexts
+}
+
+func (s batchContextStore) Contexts() store.Contexts { return s.c }
+
+// laggingIndex holds entries up to newest.
+type laggingIndex struct {
+	mockSearch
+	newest time.Time
+}
+
+func (i *laggingIndex) NewestEntryTime(context.Context, string, string) (time.Time, error) {
+	return i.newest, nil
+}
+
+type newestEntries struct {
+	store.Entries
+	newest time.Time
+}
+
+func (e newestEntries) Newest(context.Context, string, string) (time.Time, error) {
+	return e.newest, nil
+}
+
+type freshnessStore struct {
+	store.Store
+	e newestEntries
+}
+
+func (s freshnessStore) Entries() store.Entries { return s.e }
+
+func TestHandleSearch_IndexFreshness(t *testing.T) {
+	now := time.Now().UTC()
+	idx := &laggingIndex{newest: now.Add(-30 * time.Second)}
+	h, _ := NewSearchHandler(&mockEmbedder{}, idx, 0.6, &mockAuthorizer{})
+	h.EnableIndexFreshness(services.NewMemoryService(freshnessStore{e: newestEntries{newest: now}}, idx, nil))
+
+	w := doJSON(t, h.HandleSearch, "POST", "/v0/search", `{"memoryId":"m1","query":"hi"}`)
+	var resp struct {
+		IndexFreshness *model.IndexFreshness `json:"indexFreshness"`
+	}
+	if w.Code != 200 || json.NewDecoder(w.Body).Decode(&resp) != nil {
+		t.Fatalf("search: %d", w.Code)
+	}
+	if f := resp.IndexFreshness; f == nil || !f.Stale || f.LagSeconds < 29.9 || f.LagSeconds > 30.1 {
+		t.Fatalf("unexpected freshness: %+v", f)
+	}
+}
+
+// blockingSearch parks every Search call until release is closed.
+type blockingSearch struct {
+	mockSearch
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingSearch) Search(ctx context.Context, uid, mid, q string, v []float32, k int, a float32, filter model.SearchFilter) ([]model.SearchHit, error) {
+	b.entered <- struct{}{}
+	<-b.release
+	return nil, nil
+}
+
+func TestHandleSearch_Limits(t *testing.T) {
+	srch := &blockingSearch{entered: make(chan struct{}, 1), release: make(chan struct{})}
+	h, _ := NewSearchHandler(&mockEmbedder{}, srch, 0.6, &mockAuthorizer{})
+	h.EnableSearchLimits(SearchLimits{MaxTopK: 20, MaxConcurrent: 1, ActorMaxTopK: map[string]int{"test-user": 25}})
+
+	serve := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/v0/search", bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer test-api-key")
+		w := httptest.NewRecorder()
+		h.HandleSearch(w, req)
+		return w
+	}
+
+	// The per-actor override (25) replaces the default ceiling (20).
+	if w := serve(`{"memoryId":"m1","query":"q","topK":26}`); w.Code != 400 {
+		t.Fatalf("topK above limit: expected 400, got %d", w.Code)
+	}
+
+	done := make(chan int)
+	go func() { done <- serve(`{"memoryId":"m1","query":"q","topK":25}`).Code }()
+	<-srch.entered
+	w := serve(`{"memoryId":"m1","query":"q"}`)
+	if w.Code != 429 || w.Header().Get("Retry-After") == "" {
+		t.Fatalf("second concurrent search: expected 429 with Retry-After, got %d", w.Code)
+	}
+	close(srch.release)
+	if code := <-done; code != 200 {
+		t.Fatalf("first search: expected 200, got %d", code)
+	}
+
+	// The slot is released once the search finishes.
+	go func() { <-srch.entered }()
+	if w := serve(`{"memoryId":"m1","query":"q"}`); w.Code != 200 {
+		t.Fatalf("search after release: expected 200, got %d", w.Code)
+	}
+}
+
+func TestWriteSearchError(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want int
+	}{
+		{&searchError{404, "no such memory"}, 404},
+		{fmt.Errorf("scope: %w", &searchError{400, "bad pattern"}), 400},
+		{errors.New("index closed"), 500},
+	} {
+		w := httptest.NewRecorder()
+		writeSearchError(w, tc.err)
+		if w.Code != tc.want {
+			t.Fatalf("%v: expected %d, got %d", tc.err, tc.want, w.Code)
+		}
+	}
+}
+
+type filterEntries struct {
+	store.Entries
+	trashErr, expiryErr error
+}
+
+func (e filterEntries) Trashed(context.Context, string, []string) (map[string]bool, error) {
+	return nil, e.trashErr
+}
+
+func (e filterEntries) Expired(context.Context, string, []string, time.Time) (map[string]bool, error) {
+	return nil, e.expiryErr
+}
+
+type filterStore struct {
+	store.Store
+	e filterEntries
+}
+
+func (s filterStore) Entries() store.Entries { return s.e }
+
+func TestHandleSearch_FilterFailures(t *testing.T) {
+	for name, es := range map[string]filterEntries{
+		"trash":  {trashErr: errors.New("db down")},
+		"expiry": {expiryErr: errors.New("db down")},
+	} {
+		h, _ := NewSearchHandler(&mockEmbedder{}, &mockSearch{}, 0.6, &mockAuthorizer{})
+		svc := services.NewMemoryService(filterStore{e: es}, nil, nil)
+		h.EnableTrashFilter(svc)
+		h.EnableExpiryFilter(svc)
+
+		req := httptest.NewRequest("POST", "/v0/search", bytes.NewBufferString(`{"memoryId":"m1","query":"hello"}`))
+		req.Header.Set("Authorization", "Bearer test-api-key")
+		w := httptest.NewRecorder()
+		h.HandleSearch(w, req)
+		if w.Code != 500 || bytes.Contains(w.Body.Bytes(), []byte(`"e1"`)) {
+			t.Fatalf("%s: expected 500 without hits, got %d %s", name, w.Code, w.Body.String())
+		}
+	}
+}
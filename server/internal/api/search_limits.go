@@ -0,0 +1,60 @@
+package api
+
+import "sync"
+
+// defaultMaxTopK is the topK ceiling when no SearchLimits are configured.
+const defaultMaxTopK = 100
+
+// SearchLimits bounds what one actor may ask of the embedder and search
+// index. The Actor* maps override the defaults for individual actors.
+type SearchLimits struct {
+	MaxTopK            int // largest accepted topK; 0 means no ceiling
+	MaxConcurrent      int // concurrent searches per actor; 0 means unlimited
+	ActorMaxTopK       map[string]int
+	ActorMaxConcurrent map[string]int
+}
+
+func (l SearchLimits) maxTopK(actorID string) int {
+	if n, ok := l.ActorMaxTopK[actorID]; ok {
+		return n
+	}
+	return l.MaxTopK
+}
+
+func (l SearchLimits) maxConcurrent(actorID string) int {
+	if n, ok := l.ActorMaxConcurrent[actorID]; ok {
+		return n
+	}
+	return l.MaxConcurrent
+}
+
+// actorSemaphore counts in-flight searches per actor.
+type actorSemaphore struct {
+	mu    sync.Mutex
+	inUse map[string]int
+}
+
+// tryAcquire takes a slot for actorID unless limit slots are already taken.
+// A limit of 0 or less always succeeds.
+func (s *actorSemaphore) tryAcquire(actorID string, limit int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if limit > 0 && s.inUse[actorID] >= limit {
+		return false
+	}
+	if s.inUse == nil {
+		s.inUse = make(map[string]int)
+	}
+	s.inUse[actorID]++
+	return true
+}
+
+func (s *actorSemaphore) release(actorID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.inUse[actorID] <= 1 {
+		delete(s.inUse, actorID)
+		return
+	}
+	s.inUse[actorID]--
+}
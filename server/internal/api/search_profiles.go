@@ -0,0 +1,154 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/services"
+)
+
+// searchRanking is how one search ranks its hits: the server's settings
+// overridden by the request's profile, if any.
+type searchRanking struct {
+	profile   string
+	alpha     float32
+	signalW   float64 // 0 skips signal ranking
+	halfLife  time.Duration
+	diversity float64
+	rerank    bool
+	// boosts are the per-memory boosts of a title-scoped search, kept
+	// when reranking replaces the scores they scaled.
+	boosts map[string]*model.SearchBoost
+}
+
+// EnableRankingProfiles lets searches select one of profiles by name. svc
+// serves signal ranking for profiles that turn it on when the server has it
+// off.
+func (h *SearchHandler) EnableRankingProfiles(profiles map[string]model.RankingProfile, svc *services.MemoryService) {
+	h.profiles = profiles
+	h.profileSignals = svc
+}
+
+// ranking resolves req's profile and fills in its rankBy when the request
+// left it empty. Unknown profiles are an error for a 400.
+func (h *SearchHandler) ranking(req *SearchRequest) (searchRanking, error) {
+	rk := searchRanking{alpha: h.alpha, halfLife: h.halfLife, rerank: h.reranker != nil}
+	if h.signals != nil {
+		rk.signalW = h.signalW
+	}
+	if req.Profile == "" {
+		if req.RankBy == "" {
+			req.RankBy = model.RankByRelevance
+		}
+		return rk, nil
+	}
+	p, ok := h.profiles[req.Profile]
+	if !ok {
+		if len(h.profiles) == 0 {
+			return rk, fmt.Errorf("unknown profile %q: no ranking profiles are configured", req.Profile)
+		}
+		names := make([]string, 0, len(h.profiles))
+		for name := range h.profiles {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		return rk, fmt.Errorf("unknown profile %q; available: %s", req.Profile, strings.Join(names, ", "))
+	}
+	rk.profile = p.Name
+	if p.Alpha != nil {
+		rk.alpha = *p.Alpha
+	}
+	if p.Rerank != nil {
+		rk.rerank = *p.Rerank && h.reranker != nil
+		rk.signalW = 0
+		if *p.Rerank {
+			rk.signalW = h.signalW
+			if p.SignalWeight > 0 {
+				rk.signalW = p.SignalWeight
+			}
+		}
+	}
+	if p.RecencyHalfLifeHours > 0 {
+		rk.halfLife = time.Duration(p.RecencyHalfLifeHours * float64(time.Hour))
+	}
+	rk.diversity = p.Diversity
+	if req.RankBy == "" {
+		req.RankBy = p.RankBy
+	}
+	if req.RankBy == "" {
+		req.RankBy = model.RankByRelevance
+	}
+	return rk, nil
+}
+
+// candidates is how many hits to fetch for req: reranking, time decay and
+// diversity can promote hits from below the topK cut, and grouping by
+// session merges hits, so they need extra ones.
+func (rk searchRanking) candidates(req *SearchRequest) int {
+	if rk.rerank || req.RankBy != model.RankByRelevance || rk.diversity > 0 || req.GroupBy != "" {
+		return req.TopK * recencyCandidateFactor
+	}
+	return req.TopK
+}
+
+// rank drops trashed and expired hits and swaps corrected ones for their
+// corrections when those filters are on, keeping only corrections that pass
+// filter, the one the hits were searched with. It applies the reranker and
+// signal ranking (both best-effort; unranked hits are still served), recency
+// decay and diversity to hits in place, then groupBy, which may shorten them;
+// it returns the ranked hits. A failed trash or expiry check fails the search
+// with a *searchError rather than serving hits that may be deleted.
+func (h *SearchHandler) rank(ctx context.Context, actorID string, req *SearchRequest, rk searchRanking, filter model.SearchFilter, hits []model.SearchHit) ([]model.SearchHit, error) {
+	if h.trash != nil {
+		var err error
+		if hits, err = h.trash.DropTrashed(ctx, actorID, hits); err != nil {
+			log.Error().Err(err).Str("memoryId", req.MemoryID).Msg("trash filtering failed")
+			return nil, &searchError{http.StatusInternalServerError, "search service unavailable"}
+		}
+	}
+	if h.expiry != nil {
+		var err error
+		if hits, err = h.expiry.DropExpired(ctx, actorID, hits); err != nil {
+			log.Error().Err(err).Str("memoryId", req.MemoryID).Msg("expiry filtering failed")
+			return nil, &searchError{http.StatusInternalServerError, "search service unavailable"}
+		}
+	}
+	if h.corrected != nil {
+		var err error
+		if hits, err = h.corrected.FilterCorrected(ctx, actorID, hits, req.IncludeSuperseded, filter); err != nil {
+			log.Warn().Err(err).Str("memoryId", req.MemoryID).Msg("correction filtering failed")
+		}
+	}
+	if rk.rerank {
+		rctx, cancel := context.WithTimeout(ctx, h.rerankTime)
+		err := services.Rerank(rctx, h.reranker, req.Query, hits, rk.boosts)
+		cancel()
+		if err != nil {
+			log.Warn().Err(err).Str("memoryId", req.MemoryID).Msg("reranking failed")
+		}
+	}
+	if rk.signalW > 0 {
+		svc := h.signals
+		if svc == nil {
+			svc = h.profileSignals
+		}
+		if svc != nil {
+			if err := svc.RankBySignals(ctx, actorID, hits, rk.signalW); err != nil {
+				log.Warn().Err(err).Str("memoryId", req.MemoryID).Msg("signal ranking failed")
+			}
+		}
+	}
+	services.RankByRecency(hits, req.RankBy, rk.halfLife, time.Now())
+	services.Diversify(hits, rk.diversity)
+	if req.GroupBy == model.GroupBySession {
+		hits = services.GroupBySession(hits)
+	}
+	return hits, nil
+}
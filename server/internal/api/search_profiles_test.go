@@ -0,0 +1,101 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+// alphaSearch records the alpha of the last search.
+type alphaSearch struct {
+	agedSearch
+	alpha float32
+}
+
+func (m *alphaSearch) Search(ctx context.Context, uid, mid, q string, v []float32, k int, a float32, f model.SearchFilter) ([]model.SearchHit, error) {
+	m.alpha = a
+	return m.agedSearch.Search(ctx, uid, mid, q, v, k, a, f)
+}
+
+func TestHandleSearch_RankingProfile(t *testing.T) {
+	alpha := float32(0.2)
+	srch := &alphaSearch{}
+	h, _ := NewSearchHandler(&mockEmbedder{}, srch, 0.6, &mockAuthorizer{})
+	h.EnableRankingProfiles(map[string]model.RankingProfile{
+		"fresh": {Name: "fresh", Alpha: &alpha, RankBy: model.RankByRecency},
+	}, nil)
+	search := func(body string) (int, map[string]interface{}, []string) {
+		req := httptest.NewRequest("POST", "/v0/search", bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer test-api-key")
+		w := httptest.NewRecorder()
+		h.HandleSearch(w, req)
+		var resp map[string]interface{}
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		var ids []string
+		entries, _ := resp["entries"].([]interface{})
+		for _, e := range entries {
+			ids = append(ids, e.(map[string]interface{})["entryId"].(string))
+		}
+		return w.Code, resp, ids
+	}
+
+	code, resp, ids := search(`{"memoryId":"m1","query":"hello","topK":2,"profile":"fresh"}`)
+	if code != 200 || resp["profile"] != "fresh" || srch.alpha != 0.2 || srch.k != 6 || !reflect.DeepEqual(ids, []string{"recent", "now"}) {
+		t.Fatalf("profile search: code=%d profile=%v alpha=%v k=%d ids=%v", code, resp["profile"], srch.alpha, srch.k, ids)
+	}
+
+	// The request's own rankBy wins over the profile's.
+	code, _, ids = search(`{"memoryId":"m1","query":"hello","topK":2,"profile":"fresh","rankBy":"relevance"}`)
+	if code != 200 || srch.alpha != 0.2 || !reflect.DeepEqual(ids, []string{"old", "recent"}) {
+		t.Fatalf("rankBy override: code=%d alpha=%v ids=%v", code, srch.alpha, ids)
+	}
+
+	code, resp, _ = search(`{"memoryId":"m1","query":"hello","profile":"nope"}`)
+	if msg, _ := resp["message"].(string); code != 400 || !strings.Contains(msg, "available: fresh") {
+		t.Fatalf("unknown profile: code=%d resp=%v", code, resp)
+	}
+
+	code, resp, _ = search(`{"memoryId":"m1","query":"hello"}`)
+	if _, ok := resp["profile"]; code != 200 || ok || srch.alpha != 0.6 {
+		t.Fatalf("no profile: code=%d alpha=%v resp=%v", code, srch.alpha, resp)
+	}
+}
+
+// countingReranker counts its calls and scores every hit alike.
+type countingReranker struct{ calls int }
+
+func (r *countingReranker) Rerank(_ context.Context, _ string, docs []string) ([]float64, error) {
+	r.calls++
+	return make([]float64, len(docs)), nil
+}
+
+func TestHandleSearch_ProfileRerank(t *testing.T) {
+	off, on := false, true
+	rr := &countingReranker{}
+	h, _ := NewSearchHandler(&mockEmbedder{}, &mockSearch{}, 0.6, &mockAuthorizer{})
+	h.EnableReranker(rr, time.Second)
+	h.EnableRankingProfiles(map[string]model.RankingProfile{
+		"plain": {Name: "plain", Rerank: &off},
+		"tuned": {Name: "tuned", Rerank: &on},
+	}, nil)
+
+	for _, tc := range []struct {
+		body  string
+		calls int
+	}{
+		{`{"memoryId":"m1","query":"hello","profile":"plain"}`, 0},
+		{`{"memoryId":"m1","query":"hello","profile":"tuned"}`, 1},
+		{`{"memoryId":"m1","query":"hello"}`, 2},
+	} {
+		if w := doJSON(t, h.HandleSearch, "POST", "/v0/search", tc.body); w.Code != 200 || rr.calls != tc.calls {
+			t.Fatalf("%s: code=%d reranker calls=%d, want %d", tc.body, w.Code, rr.calls, tc.calls)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/searchindex"
+	"github.com/mycelian/mycelian-memory/server/internal/services"
+)
+
+// scopedMemory names one memory a title-scoped search covered.
+type scopedMemory struct {
+	MemoryID string `json:"memoryId"`
+	Title    string `json:"title"`
+}
+
+// EnableTitleScopes lets a search pick its memories by title: vaultId with
+// memoryTitles, or with a memoryPattern glob such as "proj-x-*".
+func (h *SearchHandler) EnableTitleScopes(svc *services.MemoryService) { h.scopes = svc }
+
+// scopedSearch runs a title-scoped request. The query is embedded once and
+// searched in every resolved memory with its field weights. Hybrid scores
+// of separate searches do not compare, so when several memories are
+// searched and the index can read vectors back, each hit is rescored by
+// cosine similarity to the query ("scoring": "cosine"). The hits are then
+// scaled by their memory's boost (again after reranking), merged by score,
+// ranked and trimmed to topK. Alias expansion ("aliasExpansion": "skipped"),
+// the query log and the per-memory context fields are skipped. Returns a
+// *searchError.
+func (h *SearchHandler) scopedSearch(r *http.Request, actorID string, req *SearchRequest, rk searchRanking, window services.TimeWindow) (map[string]interface{}, error) {
+	if h.scopes == nil {
+		return nil, &searchError{http.StatusBadRequest, "memoryTitles and memoryPattern are not supported"}
+	}
+	mems, err := h.scopes.ResolveMemoryScope(r.Context(), actorID, req.VaultID, req.MemoryTitles, req.MemoryPattern)
+	switch {
+	case errors.Is(err, model.ErrValidation):
+		return nil, &searchError{http.StatusBadRequest, err.Error()}
+	case errors.Is(err, model.ErrNotFound):
+		return nil, &searchError{http.StatusNotFound, err.Error()}
+	case err != nil:
+		log.Error().Err(err).Str("vaultId", req.VaultID).Msg("search scope resolution failed")
+		return nil, &searchError{http.StatusInternalServerError, "search scope unavailable"}
+	}
+	scope := make([]scopedMemory, len(mems))
+	for i, m := range mems {
+		scope[i] = scopedMemory{MemoryID: m.MemoryID, Title: m.Title}
+	}
+
+	hits := []model.SearchHit{}
+	scoring := "index"
+	if len(mems) > 0 {
+		vec, err := h.emb.Embed(r.Context(), req.Query)
+		if err != nil {
+			log.Error().Err(err).Str("query", req.Query).Msg("embedding failed")
+			return nil, &searchError{http.StatusInternalServerError, "embedding service unavailable"}
+		}
+		filter := req.Filter(window)
+		reader, _ := h.idx.(searchindex.VectorReader)
+		if reader != nil && len(mems) > 1 {
+			scoring = "cosine"
+		}
+		rk.boosts = make(map[string]*model.SearchBoost, len(mems))
+		for _, m := range mems {
+			rk.boosts[m.MemoryID] = m.SearchBoost
+			filter.FieldWeights = nil
+			if m.SearchBoost != nil {
+				filter.FieldWeights = m.SearchBoost.FieldWeights
+			}
+			mh, err := h.idx.Search(r.Context(), actorID, m.MemoryID, req.Query, vec, rk.candidates(req), rk.alpha, filter)
+			if err != nil {
+				log.Error().Err(err).Str("memoryId", m.MemoryID).Str("query", req.Query).Msg("search failed")
+				return nil, &searchError{http.StatusInternalServerError, "search service unavailable"}
+			}
+			if scoring == "cosine" && len(mh) > 0 {
+				if err := services.RescoreByCosine(r.Context(), reader, actorID, m.MemoryID, vec, mh); err != nil {
+					log.Error().Err(err).Str("memoryId", m.MemoryID).Msg("scoped search rescoring failed")
+					return nil, &searchError{http.StatusInternalServerError, "search service unavailable"}
+				}
+			}
+			services.BoostHits(mh, m.SearchBoost)
+			hits = append(hits, mh...)
+		}
+		log.Info().Int("hitCount", len(hits)).Int("memories", len(mems)).Str("vaultId", req.VaultID).Msg("scoped search completed")
+		sort.SliceStable(hits, func(i, j int) bool { return hits[i].Score > hits[j].Score })
+		filter.FieldWeights = nil
+		if hits, err = h.rank(r.Context(), actorID, req, rk, filter, hits); err != nil {
+			return nil, err
+		}
+		if len(hits) > req.TopK {
+			hits = hits[:req.TopK]
+		}
+	}
+	h.touchHits(r, actorID, "", hits)
+
+	resp := map[string]interface{}{
+		"entries":  hits,
+		"count":    len(hits),
+		"vaultId":  req.VaultID,
+		"memories": scope,
+		"scoring":  scoring,
+	}
+	if h.aliases != nil {
+		resp["aliasExpansion"] = "skipped"
+	}
+	if rk.profile != "" {
+		resp["profile"] = rk.profile
+	}
+	if window.Since != nil || window.Until != nil {
+		resp["timeWindow"] = window
+	}
+	h.prefetchContexts(r, actorID, "", hits, resp)
+	return resp, nil
+}
@@ -0,0 +1,213 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/services"
+	"github.com/mycelian/mycelian-memory/server/internal/store"
+)
+
+// titledMemories lists a fixed set of memories for every vault.
+type titledMemories struct {
+	store.Memories
+	mems []*model.Memory
+}
+
+func (m titledMemories) List(context.Context, string, string) ([]*model.Memory, error) {
+	return m.mems, nil
+}
+
+type scopeStore struct {
+	store.Store
+	v store.Vaults
+	m store.Memories
+}
+
+func (s scopeStore) Vaults() store.Vaults     { return s.v }
+func (s scopeStore) Memories() store.Memories { return s.m }
+
+// perMemorySearch returns a copy of the hits of the memory searched and
+// records the memories in search order with their field weights.
+type perMemorySearch struct {
+	mockSearch
+	hits     map[string][]model.SearchHit
+	searched []string
+	weights  map[string]map[string]float64
+}
+
+func (p *perMemorySearch) Search(_ context.Context, _, mid, _ string, _ []float32, _ int, _ float32, filter model.SearchFilter) ([]model.SearchHit, error) {
+	p.searched = append(p.searched, mid)
+	if p.weights == nil {
+		p.weights = map[string]map[string]float64{}
+	}
+	p.weights[mid] = filter.FieldWeights
+	return append([]model.SearchHit(nil), p.hits[mid]...), nil
+}
+
+func TestHandleSearch_TitleScopes(t *testing.T) {
+	st := scopeStore{
+		v: &memVaults{readOnly: map[string]bool{"v1": false}},
+		m: titledMemories{mems: []*model.Memory{
+			{MemoryID: "m2", Title: "proj-x-notes"},
+			{MemoryID: "m1", Title: "proj-x-decisions"},
+			{MemoryID: "m3", Title: "proj-y-notes"},
+		}},
+	}
+	idx := &perMemorySearch{hits: map[string][]model.SearchHit{
+		"m1": {{EntryID: "a", MemoryID: "m1", Score: 0.4}},
+		"m2": {{EntryID: "b", MemoryID: "m2", Score: 0.9}, {EntryID: "c", MemoryID: "m2", Score: 0.2}},
+		"m3": {{EntryID: "d", MemoryID: "m3", Score: 1}},
+	}}
+	emb := &mockEmbedder{}
+	h, _ := NewSearchHandler(emb, idx, 0.6, &mockAuthorizer{})
+
+	search := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/v0/search", bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer test-api-key")
+		w := httptest.NewRecorder()
+		h.HandleSearch(w, req)
+		return w
+	}
+	if w := search(`{"vaultId":"v1","memoryPattern":"proj-x-*","query":"q"}`); w.Code != 400 {
+		t.Fatalf("title scopes disabled: expected 400, got %d", w.Code)
+	}
+	h.EnableTitleScopes(services.NewMemoryService(st, idx, emb))
+
+	w := search(`{"vaultId":"v1","memoryPattern":"proj-x-*","query":"q","topK":2}`)
+	if w.Code != 200 {
+		t.Fatalf("pattern search: %d %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Entries  []model.SearchHit `json:"entries"`
+		Memories []scopedMemory    `json:"memories"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	ids := []string{}
+	for _, e := range resp.Entries {
+		ids = append(ids, e.EntryID)
+	}
+	if !reflect.DeepEqual(ids, []string{"b", "a"}) {
+		t.Fatalf("expected hits merged by score and trimmed to topK, got %v", ids)
+	}
+	if len(resp.Memories) != 2 || resp.Memories[0].MemoryID != "m1" || resp.Memories[1].Title != "proj-x-notes" {
+		t.Fatalf("unexpected scope: %+v", resp.Memories)
+	}
+	sort.Strings(idx.searched)
+	if !reflect.DeepEqual(idx.searched, []string{"m1", "m2"}) || emb.calls != 1 {
+		t.Fatalf("searched %v with %d embeddings", idx.searched, emb.calls)
+	}
+
+	if w := search(`{"vaultId":"v1","memoryTitles":["proj-y-notes"],"query":"q"}`); w.Code != 200 || !bytes.Contains(w.Body.Bytes(), []byte(`"entryId":"d"`)) {
+		t.Fatalf("title search: %d %s", w.Code, w.Body.String())
+	}
+	if w := search(`{"vaultId":"v1","memoryPattern":"nothing-*","query":"q"}`); w.Code != 200 || !bytes.Contains(w.Body.Bytes(), []byte(`"count":0`)) {
+		t.Fatalf("empty scope: %d %s", w.Code, w.Body.String())
+	}
+	for body, want := range map[string]int{
+		`{"vaultId":"v1","memoryTitles":["missing"],"query":"q"}`:           404,
+		`{"vaultId":"nope","memoryPattern":"*","query":"q"}`:                404,
+		`{"vaultId":"v1","memoryPattern":"[","query":"q"}`:                  400,
+		`{"memoryPattern":"proj-*","query":"q"}`:                            400,
+		`{"memoryId":"m1","vaultId":"v1","memoryTitles":["a"],"query":"q"}`: 400,
+	} {
+		if w := search(body); w.Code != want {
+			t.Fatalf("%s: expected %d, got %d %s", body, want, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestHandleSearch_TitleScopesApplySearchBoost(t *testing.T) {
+	prefs := &model.SearchBoost{Boost: 3, FieldWeights: map[string]float64{model.SearchFieldSummary: 2}}
+	st := scopeStore{
+		v: &memVaults{readOnly: map[string]bool{"v1": false}},
+		m: titledMemories{mems: []*model.Memory{
+			{MemoryID: "m1", Title: "user-preferences", SearchBoost: prefs},
+			{MemoryID: "m2", Title: "chat-log"},
+		}},
+	}
+	idx := &perMemorySearch{hits: map[string][]model.SearchHit{
+		"m1": {{EntryID: "a", MemoryID: "m1", Score: 0.3}},
+		"m2": {{EntryID: "b", MemoryID: "m2", Score: 0.8}},
+	}}
+	emb := &mockEmbedder{}
+	h, _ := NewSearchHandler(emb, idx, 0.6, &mockAuthorizer{})
+	h.EnableTitleScopes(services.NewMemoryService(st, idx, emb))
+
+	req := httptest.NewRequest("POST", "/v0/search", bytes.NewBufferString(`{"vaultId":"v1","memoryPattern":"*","query":"q"}`))
+	req.Header.Set("Authorization", "Bearer test-api-key")
+	w := httptest.NewRecorder()
+	h.HandleSearch(w, req)
+	var resp struct {
+		Entries []model.SearchHit `json:"entries"`
+	}
+	if w.Code != 200 || json.Unmarshal(w.Body.Bytes(), &resp) != nil || len(resp.Entries) != 2 {
+		t.Fatalf("search: %d %s", w.Code, w.Body.String())
+	}
+	if resp.Entries[0].EntryID != "a" || resp.Entries[0].Score < 0.89 || resp.Entries[1].Score != 0.8 {
+		t.Fatalf("expected the boosted memory's hit first, got %+v", resp.Entries)
+	}
+	if !reflect.DeepEqual(idx.weights["m1"], prefs.FieldWeights) || idx.weights["m2"] != nil {
+		t.Fatalf("unexpected field weights: %v", idx.weights)
+	}
+}
+
+// vectorScopeSearch is a perMemorySearch that can read back hit vectors.
+type vectorScopeSearch struct {
+	perMemorySearch
+	vecs map[string][]float32
+}
+
+func (v *vectorScopeSearch) EntryVectors(_ context.Context, _, _ string, ids []string) (map[string][]float32, error) {
+	out := map[string][]float32{}
+	for _, id := range ids {
+		if vec, ok := v.vecs[id]; ok {
+			out[id] = vec
+		}
+	}
+	return out, nil
+}
+
+func TestHandleSearch_TitleScopesRescoreByCosine(t *testing.T) {
+	st := scopeStore{
+		v: &memVaults{readOnly: map[string]bool{"v1": false}},
+		m: titledMemories{mems: []*model.Memory{{MemoryID: "m1", Title: "a"}, {MemoryID: "m2", Title: "b"}}},
+	}
+	// m2's hybrid score is higher, but m1's hit points the query's way.
+	idx := &vectorScopeSearch{
+		perMemorySearch: perMemorySearch{hits: map[string][]model.SearchHit{
+			"m1": {{EntryID: "a", MemoryID: "m1", Score: 0.2}},
+			"m2": {{EntryID: "b", MemoryID: "m2", Score: 0.9}},
+		}},
+		vecs: map[string][]float32{"a": {2, 4}, "b": {4, -2}},
+	}
+	emb := &mockEmbedder{}
+	h, _ := NewSearchHandler(emb, idx, 0.6, &mockAuthorizer{})
+	h.EnableTitleScopes(services.NewMemoryService(st, idx, emb))
+	h.EnableAliasExpansion(services.NewMemoryService(st, idx, emb))
+
+	req := httptest.NewRequest("POST", "/v0/search", bytes.NewBufferString(`{"vaultId":"v1","memoryPattern":"*","query":"q"}`))
+	req.Header.Set("Authorization", "Bearer test-api-key")
+	w := httptest.NewRecorder()
+	h.HandleSearch(w, req)
+	var resp struct {
+		Entries        []model.SearchHit `json:"entries"`
+		Scoring        string            `json:"scoring"`
+		AliasExpansion string            `json:"aliasExpansion"`
+	}
+	if w.Code != 200 || json.Unmarshal(w.Body.Bytes(), &resp) != nil || len(resp.Entries) != 2 {
+		t.Fatalf("search: %d %s", w.Code, w.Body.String())
+	}
+	if resp.Entries[0].EntryID != "a" || resp.Entries[0].Score < 0.99 || resp.Entries[1].Score > 0.01 {
+		t.Fatalf("expected hits ranked by cosine, got %+v", resp.Entries)
+	}
+	if resp.Scoring != "cosine" || resp.AliasExpansion != "skipped" {
+		t.Fatalf("scoring=%q aliasExpansion=%q", resp.Scoring, resp.AliasExpansion)
+	}
+}
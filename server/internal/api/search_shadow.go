@@ -0,0 +1,178 @@
+package api
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/searchindex"
+	"github.com/mycelian/mycelian-memory/server/internal/services"
+)
+
+// shadowTimeout bounds one shadow search; it runs after the response is
+// built, so it only costs index and store capacity.
+const shadowTimeout = 10 * time.Second
+
+// Shadow search counters (exposed via /debug/vars).
+var (
+	shadowSearches      = expvar.NewInt("search_shadow_runs")
+	shadowSearchErrors  = expvar.NewInt("search_shadow_errors")
+	shadowSearchChanged = expvar.NewInt("search_shadow_top1_changed")
+)
+
+// ShadowSearch configures shadow mode: Percent of served searches are run a
+// second time with Profile and/or against Index, and both result sets are
+// logged for offline comparison. Responses never include shadow results.
+type ShadowSearch struct {
+	// Profile is a ranking profile name; empty ranks like the served search.
+	Profile string
+	// Index is the alternative searcher; nil searches the served index.
+	Index searchindex.Index
+	// Percent of searches shadowed, in (0, 100].
+	Percent float64
+}
+
+// ShadowComparison is the log record of one shadowed search.
+type ShadowComparison struct {
+	ActorID       string    `json:"actorId"`
+	MemoryID      string    `json:"memoryId"`
+	Query         string    `json:"query"`
+	TopK          int       `json:"topK"`
+	Profile       string    `json:"profile,omitempty"`
+	ShadowProfile string    `json:"shadowProfile,omitempty"`
+	PrimaryIDs    []string  `json:"primaryIds"`
+	PrimaryScores []float64 `json:"primaryScores"`
+	ShadowIDs     []string  `json:"shadowIds"`
+	ShadowScores  []float64 `json:"shadowScores"`
+	// Overlap is the share of the larger result set present in both.
+	Overlap       float64 `json:"overlap"`
+	Top1Changed   bool    `json:"top1Changed"`
+	ShadowLatency int64   `json:"shadowLatencyMs"`
+}
+
+// EnableShadowSearch turns on shadow mode. The profile must be one of the
+// ranking profiles enabled before it.
+func (h *SearchHandler) EnableShadowSearch(cfg ShadowSearch) error {
+	if cfg.Percent <= 0 || cfg.Percent > 100 {
+		return fmt.Errorf("shadow search percent must be in (0, 100], got %v", cfg.Percent)
+	}
+	if cfg.Profile == "" && cfg.Index == nil {
+		return fmt.Errorf("shadow search needs a ranking profile or an index")
+	}
+	if cfg.Profile != "" {
+		if _, ok := h.profiles[cfg.Profile]; !ok {
+			return fmt.Errorf("shadow search profile %q is not a configured ranking profile", cfg.Profile)
+		}
+	}
+	h.shadow = &cfg
+	if h.recordShadow == nil {
+		h.recordShadow = logShadowComparison
+	}
+	return nil
+}
+
+// logShadowComparison writes c as one structured log line.
+func logShadowComparison(c ShadowComparison) {
+	log.Info().Interface("shadow", c).Msg("shadow search")
+}
+
+// maybeShadow samples the search and, when chosen, reruns it in the
+// background with the shadow configuration and records the comparison with
+// the served hits. vec is the served query's embedding, reused as is.
+func (h *SearchHandler) maybeShadow(r *http.Request, actorID string, req *SearchRequest, rk searchRanking, query string, vec []float32, window services.TimeWindow, served []model.SearchHit) {
+	cfg := h.shadow
+	if cfg == nil || rand.Float64()*100 >= cfg.Percent {
+		return
+	}
+	sreq := *req
+	srk := rk
+	if cfg.Profile != "" {
+		sreq.Profile, sreq.RankBy = cfg.Profile, ""
+		var err error
+		if srk, err = h.ranking(&sreq); err != nil {
+			return
+		}
+	}
+	idx := cfg.Index
+	if idx == nil {
+		idx = h.idx
+	}
+	c := ShadowComparison{
+		ActorID: actorID, MemoryID: req.MemoryID, Query: req.Query, TopK: req.TopK,
+		Profile: rk.profile, ShadowProfile: srk.profile,
+	}
+	c.PrimaryIDs, c.PrimaryScores = hitIDsAndScores(served)
+
+	ctx := context.WithoutCancel(r.Context())
+	h.shadowRuns.Add(1)
+	go func() {
+		defer h.shadowRuns.Done()
+		ctx, cancel := context.WithTimeout(ctx, shadowTimeout)
+		defer cancel()
+		shadowSearches.Add(1)
+		start := time.Now()
+		filter := sreq.Filter(window)
+		hits, err := idx.Search(ctx, actorID, sreq.MemoryID, query, vec, srk.candidates(&sreq), srk.alpha, filter)
+		if err != nil {
+			shadowSearchErrors.Add(1)
+			log.Warn().Err(err).Str("memoryId", sreq.MemoryID).Msg("shadow search failed")
+			return
+		}
+		if hits, err = h.rank(ctx, actorID, &sreq, srk, filter, hits); err != nil {
+			shadowSearchErrors.Add(1)
+			return
+		}
+		if len(hits) > sreq.TopK {
+			hits = hits[:sreq.TopK]
+		}
+		c.ShadowLatency = time.Since(start).Milliseconds()
+		c.ShadowIDs, c.ShadowScores = hitIDsAndScores(hits)
+		c.Overlap = overlap(c.PrimaryIDs, c.ShadowIDs)
+		c.Top1Changed = firstID(c.PrimaryIDs) != firstID(c.ShadowIDs)
+		if c.Top1Changed {
+			shadowSearchChanged.Add(1)
+		}
+		h.recordShadow(c)
+	}()
+}
+
+func hitIDsAndScores(hits []model.SearchHit) ([]string, []float64) {
+	ids := make([]string, len(hits))
+	scores := make([]float64, len(hits))
+	for i, hit := range hits {
+		ids[i], scores[i] = hit.EntryID, hit.Score
+	}
+	return ids, scores
+}
+
+func firstID(ids []string) string {
+	if len(ids) == 0 {
+		return ""
+	}
+	return ids[0]
+}
+
+// overlap is |a ∩ b| / max(|a|, |b|); 1 when both are empty.
+func overlap(a, b []string) float64 {
+	n := max(len(a), len(b))
+	if n == 0 {
+		return 1
+	}
+	in := make(map[string]bool, len(a))
+	for _, id := range a {
+		in[id] = true
+	}
+	both := 0
+	for _, id := range b {
+		if in[id] {
+			both++
+		}
+	}
+	return float64(both) / float64(n)
+}
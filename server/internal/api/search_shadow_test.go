@@ -0,0 +1,82 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+func TestHandleSearch_ShadowProfile(t *testing.T) {
+	emb := &mockEmbedder{}
+	h, _ := NewSearchHandler(emb, &agedSearch{}, 0.6, &mockAuthorizer{})
+	h.EnableRankingProfiles(map[string]model.RankingProfile{
+		"fresh": {Name: "fresh", RankBy: model.RankByRecency},
+	}, nil)
+	if err := h.EnableShadowSearch(ShadowSearch{Profile: "nope", Percent: 100}); err == nil {
+		t.Fatal("expected error for an unknown shadow profile")
+	}
+	if err := h.EnableShadowSearch(ShadowSearch{Profile: "fresh", Percent: 0}); err == nil {
+		t.Fatal("expected error for percent 0")
+	}
+	var (
+		mu  sync.Mutex
+		got []ShadowComparison
+	)
+	h.recordShadow = func(c ShadowComparison) {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, c)
+	}
+	if err := h.EnableShadowSearch(ShadowSearch{Profile: "fresh", Percent: 100}); err != nil {
+		t.Fatalf("EnableShadowSearch: %v", err)
+	}
+
+	req := httptest.NewRequest("POST", "/v0/search", bytes.NewBufferString(`{"memoryId":"m1","query":"hello","topK":2}`))
+	req.Header.Set("Authorization", "Bearer test-api-key")
+	w := httptest.NewRecorder()
+	h.HandleSearch(w, req)
+	h.shadowRuns.Wait()
+	if w.Code != 200 {
+		t.Fatalf("search: %d %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Entries []model.SearchHit `json:"entries"`
+		Profile string            `json:"profile"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Entries) != 2 || resp.Entries[0].EntryID != "old" || resp.Profile != "" {
+		t.Fatalf("shadow search changed the response: %+v", resp)
+	}
+	if emb.calls != 1 {
+		t.Fatalf("shadow search re-embedded the query: %d calls", emb.calls)
+	}
+	if len(got) != 1 {
+		t.Fatalf("expected one comparison, got %d", len(got))
+	}
+	c := got[0]
+	if c.ShadowProfile != "fresh" || !reflect.DeepEqual(c.PrimaryIDs, []string{"old", "recent"}) ||
+		!reflect.DeepEqual(c.ShadowIDs, []string{"recent", "now"}) || c.Overlap != 0.5 || !c.Top1Changed {
+		t.Fatalf("unexpected comparison: %+v", c)
+	}
+}
+
+func TestOverlap(t *testing.T) {
+	for _, tc := range []struct {
+		a, b []string
+		want float64
+	}{
+		{nil, nil, 1},
+		{[]string{"a", "b"}, []string{"b", "a"}, 1},
+		{[]string{"a", "b", "c", "d"}, []string{"a"}, 0.25},
+		{[]string{"a"}, []string{"b"}, 0},
+	} {
+		if got := overlap(tc.a, tc.b); got != tc.want {
+			t.Fatalf("overlap(%v, %v) = %v, want %v", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
@@ -2,7 +2,9 @@ package api
 
 import (
 	"bytes"
+	"fmt"
 	"net/http/httptest"
+	"reflect"
 	"testing"
 )
 
@@ -11,7 +13,7 @@ func TestSearchRequestValidateDefaults(t *testing.T) {
 	if err := req.Validate(); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if req.Query != "test" || req.TopK != 10 {
+	if req.Query != "test" || req.TopK != 10 || req.RankBy != "relevance" {
 		t.Fatalf("defaults not applied correctly: %+v", req)
 	}
 }
@@ -23,10 +25,21 @@ func TestSearchRequestValidateError(t *testing.T) {
 	}
 }
 
+func TestSearchRequestValidateRankBy(t *testing.T) {
+	req := SearchRequest{MemoryID: "m1", Query: "q", RankBy: " Recency "}
+	if err := req.Validate(); err != nil || req.RankBy != "recency" {
+		t.Fatalf("rankBy not normalised: %q %v", req.RankBy, err)
+	}
+	bad := SearchRequest{MemoryID: "m1", Query: "q", RankBy: "newest"}
+	if err := bad.Validate(); err == nil {
+		t.Fatalf("expected error for unknown rankBy")
+	}
+}
+
 func TestDecodeSearchRequest(t *testing.T) {
 	body := bytes.NewBufferString(`{"memoryId":"m1","query":"foo","topK":5}`)
 	r := httptest.NewRequest("POST", "/v0/search", body)
-	sr, err := decodeSearchRequest(nil, r)
+	sr, err := decodeSearchRequest(nil, r, nil)
 	if err != nil {
 		t.Fatalf("decode error: %v", err)
 	}
@@ -34,3 +47,59 @@ func TestDecodeSearchRequest(t *testing.T) {
 		t.Fatalf("unexpected result: %+v", sr)
 	}
 }
+
+func TestSearchRequestValidateMustNot(t *testing.T) {
+	body := bytes.NewBufferString(`{"memoryId":"m1","query":"foo","mustNot":{"tags":[" seen ","","seen"],"entryIds":["e1","e2"]}}`)
+	sr, err := decodeSearchRequest(nil, httptest.NewRequest("POST", "/v0/search", body), nil)
+	if err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if len(sr.MustNot.Tags) != 1 || sr.MustNot.Tags[0] != "seen" || len(sr.MustNot.EntryIDs) != 2 {
+		t.Fatalf("mustNot not normalised: %+v", sr.MustNot)
+	}
+
+	self := SearchRequest{MemoryID: "m1", Query: "foo"}
+	self.MustNot.MemoryIDs = []string{"m1"}
+	if err := self.Validate(); err == nil {
+		t.Fatal("expected error when excluding the searched memory")
+	}
+
+	big := SearchRequest{MemoryID: "m1", Query: "foo"}
+	for i := 0; i <= maxMustNotValues; i++ {
+		big.MustNot.EntryIDs = append(big.MustNot.EntryIDs, fmt.Sprintf("e%d", i))
+	}
+	if err := big.Validate(); err == nil {
+		t.Fatal("expected error for too many exclusions")
+	}
+}
+
+func TestSearchRequestValidateTags(t *testing.T) {
+	for _, tags := range []map[string]string{{" ": "x"}, {"a=b": "c"}} {
+		if err := (&SearchRequest{MemoryID: "m1", Query: "foo", Tags: tags}).Validate(); err == nil {
+			t.Fatalf("tags %v: expected error", tags)
+		}
+	}
+	big := SearchRequest{MemoryID: "m1", Query: "foo", Tags: map[string]string{}}
+	for i := 0; i <= maxTagFilters; i++ {
+		big.Tags[fmt.Sprintf("k%d", i)] = "v"
+	}
+	if err := big.Validate(); err == nil {
+		t.Fatal("expected error for too many tag pairs")
+	}
+}
+
+func TestSearchRequestValidateTitleScope(t *testing.T) {
+	req := SearchRequest{VaultID: "v1", MemoryTitles: []string{" notes ", "", "notes"}, Query: "q"}
+	if err := req.Validate(); err != nil || !reflect.DeepEqual(req.MemoryTitles, []string{"notes"}) {
+		t.Fatalf("memoryTitles not normalised: %v %v", req.MemoryTitles, err)
+	}
+	for _, bad := range []SearchRequest{
+		{VaultID: "v1", MemoryTitles: []string{"a"}, MemoryPattern: "b-*", Query: "q"},
+		{MemoryPattern: "b-*", Query: "q"},
+		{VaultID: "v1", MemoryPattern: "b-*", Query: "q", Window: "sinceSessionStart"},
+	} {
+		if err := bad.Validate(); err == nil {
+			t.Fatalf("expected error for %+v", bad)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/services"
+)
+
+// requestLocation resolves the zone used to render and filter times: the tz
+// query parameter when present, else the actor's default, else UTC.
+// An invalid tz yields model.ErrValidation.
+func requestLocation(ctx context.Context, r *http.Request, actors *services.ActorService, actorID string) (*time.Location, error) {
+	tz := r.URL.Query().Get("tz")
+	if actors != nil {
+		return actors.Location(ctx, actorID, tz)
+	}
+	if tz != "" {
+		return services.LoadTimeZone(tz)
+	}
+	return time.UTC, nil
+}
+
+// parseTimeParam accepts RFC3339, a date (2006-01-02), "today" or
+// "yesterday"; dates and keywords mean midnight in loc.
+func parseTimeParam(s string, loc *time.Location, now time.Time) (time.Time, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "today":
+		return midnight(now.In(loc)), nil
+	case "yesterday":
+		return midnight(now.In(loc)).AddDate(0, 0, -1), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02", s, loc)
+}
+
+func midnight(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// entriesIn renders entry timestamps in loc so they carry the zone offset.
+func entriesIn(entries []*model.MemoryEntry, loc *time.Location) {
+	for _, e := range entries {
+		e.CreationTime = e.CreationTime.In(loc)
+		if e.ExpirationTime != nil {
+			t := e.ExpirationTime.In(loc)
+			e.ExpirationTime = &t
+		}
+		if e.LastAccessedTime != nil {
+			t := e.LastAccessedTime.In(loc)
+			e.LastAccessedTime = &t
+		}
+		if e.ConversationTime != nil {
+			t := e.ConversationTime.In(loc)
+			e.ConversationTime = &t
+		}
+		if e.DeletionTime != nil {
+			t := e.DeletionTime.In(loc)
+			e.DeletionTime = &t
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/services"
+	"github.com/mycelian/mycelian-memory/server/internal/store"
+)
+
+func TestParseTimeParam(t *testing.T) {
+	ny, _ := time.LoadLocation("America/New_York")
+	// 02:00 UTC on the 10th is still the evening of the 9th in New York.
+	now := time.Date(2025, 3, 10, 2, 0, 0, 0, time.UTC)
+
+	cases := map[string]time.Time{
+		"today":                time.Date(2025, 3, 9, 0, 0, 0, 0, ny),
+		"yesterday":            time.Date(2025, 3, 8, 0, 0, 0, 0, ny),
+		"2025-01-02":           time.Date(2025, 1, 2, 0, 0, 0, 0, ny),
+		"2025-01-02T03:04:05Z": time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	for in, want := range cases {
+		got, err := parseTimeParam(in, ny, now)
+		if err != nil || !got.Equal(want) {
+			t.Errorf("%q: got %v err=%v, want %v", in, got, err, want)
+		}
+	}
+	if _, err := parseTimeParam("last week", ny, now); err == nil {
+		t.Fatalf("expected error for unsupported value")
+	}
+}
+
+type tzEntries struct {
+	store.Entries
+	req model.ListEntriesRequest
+}
+
+func (e *tzEntries) List(_ context.Context, req model.ListEntriesRequest) ([]*model.MemoryEntry, error) {
+	e.req = req
+	return []*model.MemoryEntry{{EntryID: "e1", CreationTime: time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC)}}, nil
+}
+
+type tzStore struct {
+	store.Store
+	e *tzEntries
+}
+
+func (tzStore) Vaults() store.Vaults {
+	return &memVaults{readOnly: map[string]bool{"v1": false}}
+}
+func (tzStore) Memories() store.Memories { return memMemories{} }
+func (s tzStore) Entries() store.Entries { return s.e }
+
+func TestListMemoryEntries_TimeZone(t *testing.T) {
+	es := &tzEntries{}
+	st := tzStore{e: es}
+	h := NewMemoryHandler(services.NewMemoryService(st, nil, nil), services.NewVaultService(st, nil), &mockAuthorizer{}, nil)
+	r := mux.NewRouter()
+	r.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/entries", h.ListMemoryEntries).Methods("GET")
+
+	get := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v0/vaults/v1/memories/m1/entries?"+query, nil)
+		req.Header.Set("Authorization", "Bearer test-api-key")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("tz=Asia/Tokyo&after=2025-01-02")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if es.req.After == nil || !es.req.After.Equal(time.Date(2025, 1, 1, 15, 0, 0, 0, time.UTC)) {
+		t.Fatalf("after not resolved in Tokyo: %v", es.req.After)
+	}
+	var resp struct {
+		Entries []struct {
+			CreationTime string `json:"creationTime"`
+		} `json:"entries"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil || len(resp.Entries) != 1 {
+		t.Fatalf("decode: %v", err)
+	}
+	if got := resp.Entries[0].CreationTime; !strings.HasSuffix(got, "+09:00") {
+		t.Fatalf("creationTime not rendered in Tokyo: %s", got)
+	}
+
+	if w := get("tz=Nowhere/Special"); w.Code != http.StatusBadRequest {
+		t.Fatalf("invalid tz: expected 400, got %d", w.Code)
+	}
+}
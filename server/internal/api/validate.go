@@ -4,31 +4,65 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
 )
 
 var emailRx = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
 
-// titleRx allows ASCII letters, digits, single spaces, hyphen, underscore and apostrophe.
-// We deliberately keep it simple to meet the "English + space" requirement.
-var titleRx = regexp.MustCompile(`^[A-Za-z0-9\-]+$`)
+// maxTitleLen bounds vault and memory titles, in characters.
+const maxTitleLen = 50
+
+// reservedTitles are slugs a title may not have because they read as path
+// segments of the API.
+var reservedTitles = map[string]bool{"new": true, "memories": true, "entries": true, "contexts": true, "search": true}
+
+// uuidRx matches titles shaped like the IDs title-addressed routes also accept.
+var uuidRx = regexp.MustCompile(`^[0-9A-Fa-f]{8}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{12}$`)
 
 // Title validates that a title string conforms to our rules:
-// - 1–50 bytes
-// - ASCII letters/digits/space/hyphen/underscore/apostrophe only
+// - 1–50 characters
+// - letters and digits of any script, spaces, hyphen, underscore, period and apostrophe only
 // - No leading/trailing space
 // - No consecutive spaces
+// - A slug (see model.Slug) that is not empty or reserved, and not shaped like an ID
 // Returns an error describing the first violated rule.
 func Title(v string) error {
 	if v == "" {
 		return fmt.Errorf("title is required")
 	}
 
-	if len(v) > 50 {
-		return fmt.Errorf("title exceeds 50 characters")
+	if !utf8.ValidString(v) {
+		return fmt.Errorf("title is not valid UTF-8")
+	}
+	if utf8.RuneCountInString(v) > maxTitleLen {
+		return fmt.Errorf("title exceeds %d characters", maxTitleLen)
 	}
 
-	if !titleRx.MatchString(v) {
-		return fmt.Errorf("title contains invalid characters; allowed letters, digits, hyphen")
+	for _, r := range v {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.Is(unicode.Mn, r) && !strings.ContainsRune(" -_.'", r) {
+			return fmt.Errorf("title contains invalid characters; allowed letters, digits, spaces, hyphen, underscore, period, apostrophe")
+		}
+	}
+	if strings.TrimSpace(v) != v {
+		return fmt.Errorf("title must not start or end with a space")
+	}
+	if strings.Contains(v, "  ") {
+		return fmt.Errorf("title must not contain consecutive spaces")
+	}
+
+	slug := model.Slug(v)
+	if slug == "" {
+		return fmt.Errorf("title needs at least one letter or digit")
+	}
+	if reservedTitles[slug] {
+		return fmt.Errorf("title %q is reserved", v)
+	}
+	if uuidRx.MatchString(v) {
+		return fmt.Errorf("title must not look like an ID")
 	}
 
 	return nil
@@ -90,6 +124,19 @@ func CreateMemory(memoryType, title string, description *string) error {
 	return nil
 }
 
+// TitleUpdate validates a vault or memory PATCH body.
+func TitleUpdate(u model.TitleUpdate) error {
+	if u.Title == nil && u.Description == nil {
+		return fmt.Errorf("title or description is required")
+	}
+	if u.Title != nil {
+		if err := Title(*u.Title); err != nil {
+			return err
+		}
+	}
+	return MaxLen("description", u.Description, 500)
+}
+
 func CreateMemoryEntry(raw string, summary *string, metadata, tags map[string]interface{}) error {
 	if err := NonEmpty("rawEntry", raw); err != nil {
 		return err
@@ -113,6 +160,25 @@ func CreateMemoryEntry(raw string, summary *string, metadata, tags map[string]in
 	return nil
 }
 
+// maxProvenanceLen bounds sourceSystem, sourceId and ingestionBatchId.
+const maxProvenanceLen = 256
+
+func EntryProvenance(sourceSystem, sourceID, ingestionBatchID string) error {
+	if err := MaxLen("sourceSystem", &sourceSystem, maxProvenanceLen); err != nil {
+		return err
+	}
+	if err := MaxLen("sourceId", &sourceID, maxProvenanceLen); err != nil {
+		return err
+	}
+	if err := MaxLen("ingestionBatchId", &ingestionBatchID, maxProvenanceLen); err != nil {
+		return err
+	}
+	if sourceID != "" && sourceSystem == "" {
+		return fmt.Errorf("sourceId requires sourceSystem")
+	}
+	return nil
+}
+
 func ContextFragments(ctx map[string]interface{}) error {
 	if ctx == nil {
 		return fmt.Errorf("context is required")
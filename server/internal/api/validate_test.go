@@ -24,6 +24,21 @@ func TestContextFragments(t *testing.T) {
 	}
 }
 
+func TestEntryProvenance(t *testing.T) {
+	if err := EntryProvenance("", "", ""); err != nil {
+		t.Fatalf("empty provenance should be valid: %v", err)
+	}
+	if err := EntryProvenance("mem0", "m-1", "batch-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := EntryProvenance("", "m-1", ""); err == nil {
+		t.Fatalf("expected error for sourceId without sourceSystem")
+	}
+	if err := EntryProvenance(strings.Repeat("a", 257), "", ""); err == nil {
+		t.Fatalf("expected length error")
+	}
+}
+
 func TestTitle(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -51,26 +66,23 @@ func TestTitle(t *testing.T) {
 		{
 			name:        "title with spaces",
 			title:       "Title With Spaces",
-			expectError: true,
-			errorMsg:    "title contains invalid characters; allowed letters, digits, hyphen",
+			expectError: false,
 		},
 		{
 			name:        "title with special characters",
 			title:       "Title@Special!",
 			expectError: true,
-			errorMsg:    "title contains invalid characters; allowed letters, digits, hyphen",
+			errorMsg:    "title contains invalid characters; allowed letters, digits, spaces, hyphen, underscore, period, apostrophe",
 		},
 		{
 			name:        "title with underscore",
 			title:       "Title_With_Underscore",
-			expectError: true,
-			errorMsg:    "title contains invalid characters; allowed letters, digits, hyphen",
+			expectError: false,
 		},
 		{
 			name:        "title with apostrophe",
 			title:       "Title's",
-			expectError: true,
-			errorMsg:    "title contains invalid characters; allowed letters, digits, hyphen",
+			expectError: false,
 		},
 		{
 			name:        "valid title with hyphens",
@@ -87,6 +99,52 @@ func TestTitle(t *testing.T) {
 			title:       strings.Repeat("a", 50),
 			expectError: false,
 		},
+		{
+			name:        "unicode title counted in characters",
+			title:       strings.Repeat("é", 50),
+			expectError: false,
+		},
+		{
+			name:        "title in another script",
+			title:       "Заметки о поездке",
+			expectError: false,
+		},
+		{
+			name:        "title with slash",
+			title:       "a/b",
+			expectError: true,
+			errorMsg:    "title contains invalid characters; allowed letters, digits, spaces, hyphen, underscore, period, apostrophe",
+		},
+		{
+			name:        "title with leading space",
+			title:       " Notes",
+			expectError: true,
+			errorMsg:    "title must not start or end with a space",
+		},
+		{
+			name:        "title with consecutive spaces",
+			title:       "Travel  Notes",
+			expectError: true,
+			errorMsg:    "title must not contain consecutive spaces",
+		},
+		{
+			name:        "title without letters or digits",
+			title:       "--",
+			expectError: true,
+			errorMsg:    "title needs at least one letter or digit",
+		},
+		{
+			name:        "reserved title",
+			title:       "Memories",
+			expectError: true,
+			errorMsg:    `title "Memories" is reserved`,
+		},
+		{
+			name:        "title shaped like an ID",
+			title:       "0b6f2c9e-4a43-4c1e-9a57-6d0f7c2b1e88",
+			expectError: true,
+			errorMsg:    "title must not look like an ID",
+		},
 	}
 
 	for _, tt := range tests {
@@ -154,7 +212,7 @@ func TestCreateMemory(t *testing.T) {
 			title:       "Invalid Title!",
 			description: nil,
 			expectError: true,
-			errorMsg:    "title contains invalid characters; allowed letters, digits, hyphen",
+			errorMsg:    "title contains invalid characters; allowed letters, digits, spaces, hyphen, underscore, period, apostrophe",
 		},
 		{
 			name:        "description too long",
@@ -0,0 +1,182 @@
+package auth
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// CachingAuthorizer remembers an Authorizer's successful decisions per
+// (API key, operation, resource) for ttl, so repeated requests with the same
+// key skip the key lookup. Failed authorizations are never cached: a denial
+// may be a transient lookup error, and a new key must work at once. When
+// full it evicts the least recently used decision.
+//
+// Keys are held as SHA-256 digests, never in the clear. Revoke drops every
+// decision of a key. A wrapped authorizer that implements KeyRevoker has its
+// rotated and deleted keys revoked automatically; other server instances
+// see the revocation once their cached decisions expire.
+type CachingAuthorizer struct {
+	next     Authorizer
+	ttl      time.Duration
+	capacity int
+	now      func() time.Time
+
+	mu       sync.Mutex
+	lru      *list.List // of *cachedDecision, most recently used first
+	items    map[decisionKey]*list.Element
+	byDigest map[string]map[authScope]bool // key digest -> cached scopes
+	// epoch counts revocations; a decision is only cached if none happened
+	// while it was looked up, so a lookup racing Revoke cannot re-cache it.
+	epoch uint64
+
+	hits, misses, evictions, revocations uint64
+}
+
+// KeyRevoker is implemented by authorizers whose keys stop working before
+// they expire, through rotation or deletion. OnRevoke registers f to be
+// called with each such key; NewCachingAuthorizer registers Revoke.
+type KeyRevoker interface {
+	OnRevoke(f func(apiKey string))
+}
+
+type authScope struct{ operation, resource string }
+
+type decisionKey struct {
+	digest string
+	scope  authScope
+}
+
+type cachedDecision struct {
+	key     decisionKey
+	actor   ActorInfo
+	expires time.Time
+}
+
+// AuthCacheStats reports the authorization cache's size and hit rate.
+type AuthCacheStats struct {
+	Capacity    int     `json:"capacity"`
+	Size        int     `json:"size"`
+	TTLSeconds  float64 `json:"ttlSeconds"`
+	Hits        uint64  `json:"hits"`
+	Misses      uint64  `json:"misses"`
+	HitRatio    float64 `json:"hitRatio"`
+	Evictions   uint64  `json:"evictions"`
+	Revocations uint64  `json:"revocations"`
+}
+
+// NewCachingAuthorizer caches up to capacity decisions of next for ttl each.
+// When next is a KeyRevoker, keys it revokes are dropped from the cache.
+func NewCachingAuthorizer(next Authorizer, ttl time.Duration, capacity int) *CachingAuthorizer {
+	c := &CachingAuthorizer{
+		next:     next,
+		ttl:      ttl,
+		capacity: capacity,
+		now:      time.Now,
+		lru:      list.New(),
+		items:    map[decisionKey]*list.Element{},
+		byDigest: map[string]map[authScope]bool{},
+	}
+	if r, ok := next.(KeyRevoker); ok {
+		r.OnRevoke(c.Revoke)
+	}
+	return c
+}
+
+func keyDigest(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:])
+}
+
+// Authorize returns the cached decision for the key and scope while it is
+// fresh and asks the wrapped Authorizer otherwise.
+func (c *CachingAuthorizer) Authorize(ctx context.Context, apiKey, operation, resource string) (*ActorInfo, error) {
+	key := decisionKey{keyDigest(apiKey), authScope{operation, resource}}
+	now := c.now()
+
+	c.mu.Lock()
+	if el, ok := c.items[key]; ok {
+		if d := el.Value.(*cachedDecision); now.Before(d.expires) {
+			c.hits++
+			c.lru.MoveToFront(el)
+			actor := d.actor
+			c.mu.Unlock()
+			return &actor, nil
+		}
+		c.remove(el)
+	}
+	c.misses++
+	epoch := c.epoch
+	c.mu.Unlock()
+
+	actor, err := c.next.Authorize(ctx, apiKey, operation, resource)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.epoch != epoch || c.capacity <= 0 {
+		return actor, nil
+	}
+	if el, ok := c.items[key]; ok {
+		c.remove(el)
+	}
+	c.items[key] = c.lru.PushFront(&cachedDecision{key: key, actor: *actor, expires: now.Add(c.ttl)})
+	if c.byDigest[key.digest] == nil {
+		c.byDigest[key.digest] = map[authScope]bool{}
+	}
+	c.byDigest[key.digest][key.scope] = true
+	for c.lru.Len() > c.capacity {
+		c.remove(c.lru.Back())
+		c.evictions++
+	}
+	return actor, nil
+}
+
+// remove drops a cached decision; c.mu must be held.
+func (c *CachingAuthorizer) remove(el *list.Element) {
+	key := c.lru.Remove(el).(*cachedDecision).key
+	delete(c.items, key)
+	if scopes := c.byDigest[key.digest]; scopes != nil {
+		delete(scopes, key.scope)
+		if len(scopes) == 0 {
+			delete(c.byDigest, key.digest)
+		}
+	}
+}
+
+// Revoke drops every cached decision of apiKey, so its next request is
+// authorized afresh.
+func (c *CachingAuthorizer) Revoke(apiKey string) {
+	digest := keyDigest(apiKey)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.epoch++
+	c.revocations++
+	for scope := range c.byDigest[digest] {
+		c.remove(c.items[decisionKey{digest, scope}])
+	}
+}
+
+// Stats returns the cache's current counters.
+func (c *CachingAuthorizer) Stats() AuthCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := AuthCacheStats{
+		Capacity:    c.capacity,
+		Size:        c.lru.Len(),
+		TTLSeconds:  c.ttl.Seconds(),
+		Hits:        c.hits,
+		Misses:      c.misses,
+		Evictions:   c.evictions,
+		Revocations: c.revocations,
+	}
+	if total := c.hits + c.misses; total > 0 {
+		out.HitRatio = float64(c.hits) / float64(total)
+	}
+	return out
+}
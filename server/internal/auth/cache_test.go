@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type countingAuthorizer struct {
+	calls int
+	deny  bool
+}
+
+func (a *countingAuthorizer) Authorize(_ context.Context, apiKey, _, _ string) (*ActorInfo, error) {
+	a.calls++
+	if a.deny {
+		return nil, errors.New("invalid API key")
+	}
+	return &ActorInfo{ActorID: "actor-" + apiKey}, nil
+}
+
+func TestCachingAuthorizer(t *testing.T) {
+	next := &countingAuthorizer{}
+	c := NewCachingAuthorizer(next, time.Minute, 2)
+	now := time.Unix(1000, 0)
+	c.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	authorize := func(key, op string) *ActorInfo {
+		t.Helper()
+		actor, err := c.Authorize(ctx, key, op, "default")
+		if err != nil {
+			t.Fatalf("Authorize(%s, %s): %v", key, op, err)
+		}
+		return actor
+	}
+
+	if a := authorize("k1", "memory.read"); a.ActorID != "actor-k1" {
+		t.Fatalf("unexpected actor %+v", a)
+	}
+	authorize("k1", "memory.read")
+	if next.calls != 1 {
+		t.Fatalf("second call within ttl must be cached, got %d lookups", next.calls)
+	}
+	authorize("k1", "memory.write")
+	if next.calls != 2 {
+		t.Fatalf("each scope is cached separately, got %d lookups", next.calls)
+	}
+
+	c.Revoke("k1")
+	authorize("k1", "memory.read")
+	if next.calls != 3 {
+		t.Fatalf("revoked key must be looked up again, got %d lookups", next.calls)
+	}
+
+	now = now.Add(2 * time.Minute)
+	authorize("k1", "memory.read")
+	if next.calls != 4 {
+		t.Fatalf("expired decision must be looked up again, got %d lookups", next.calls)
+	}
+
+	next.deny = true
+	for i := 0; i < 2; i++ {
+		if _, err := c.Authorize(ctx, "bad", "memory.read", "default"); err == nil {
+			t.Fatal("expected denial")
+		}
+	}
+	if next.calls != 6 {
+		t.Fatalf("denials must not be cached, got %d lookups", next.calls)
+	}
+
+	if s := c.Stats(); s.Size != 1 || s.Hits != 1 || s.Misses != 6 || s.Revocations != 1 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+}
+
+func TestCachingAuthorizerCapacity(t *testing.T) {
+	next := &countingAuthorizer{}
+	c := NewCachingAuthorizer(next, time.Minute, 2)
+	ctx := context.Background()
+	authorize := func(key string) { _, _ = c.Authorize(ctx, key, "memory.read", "default") }
+
+	authorize("k1")
+	authorize("k2")
+	authorize("k1") // k2 is now the least recently used
+	authorize("k3")
+	if next.calls != 3 || c.Stats().Size != 2 || c.Stats().Evictions != 1 {
+		t.Fatalf("a full cache must evict one decision: %d lookups, %+v", next.calls, c.Stats())
+	}
+	authorize("k1")
+	authorize("k3")
+	if next.calls != 3 {
+		t.Fatalf("recently used decisions must stay cached, got %d lookups", next.calls)
+	}
+	authorize("k2")
+	if next.calls != 4 {
+		t.Fatalf("the least recently used decision must be evicted, got %d lookups", next.calls)
+	}
+}
+
+// revokingAuthorizer rotates keys: revoke calls the registered hooks.
+type revokingAuthorizer struct {
+	countingAuthorizer
+	hooks []func(string)
+}
+
+func (a *revokingAuthorizer) OnRevoke(f func(string)) { a.hooks = append(a.hooks, f) }
+
+func (a *revokingAuthorizer) revoke(apiKey string) {
+	for _, f := range a.hooks {
+		f(apiKey)
+	}
+}
+
+func TestCachingAuthorizerFollowsKeyRevocations(t *testing.T) {
+	next := &revokingAuthorizer{}
+	c := NewCachingAuthorizer(next, time.Minute, 10)
+	ctx := context.Background()
+
+	_, _ = c.Authorize(ctx, "k1", "memory.read", "default")
+	_, _ = c.Authorize(ctx, "k1", "memory.write", "default")
+	_, _ = c.Authorize(ctx, "k2", "memory.read", "default")
+	next.revoke("k1")
+	if s := c.Stats(); s.Size != 1 || s.Revocations != 1 {
+		t.Fatalf("a rotated key's decisions must be dropped: %+v", s)
+	}
+	next.deny = true
+	if _, err := c.Authorize(ctx, "k1", "memory.read", "default"); err == nil {
+		t.Fatal("a rotated key must be authorized afresh")
+	}
+	if _, err := c.Authorize(ctx, "k2", "memory.read", "default"); err != nil {
+		t.Fatalf("other keys stay cached: %v", err)
+	}
+}
@@ -2,9 +2,14 @@ package config
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/kelseyhightower/envconfig"
 	"github.com/rs/zerolog/log"
+
+	"github.com/mycelian/mycelian-memory/server/internal/logger"
+	"github.com/mycelian/mycelian-memory/server/internal/searchindex"
 )
 
 // Environment represents different deployment environments
@@ -33,6 +38,14 @@ type Config struct {
 
 	// HTTP Configuration
 	HTTPPort int `envconfig:"HTTP_PORT" default:"11545"`
+	// Connection reuse for high-QPS clients: keep-alive connections are closed
+	// after IDLE_TIMEOUT_SECONDS unused. H2C also accepts unencrypted HTTP/2
+	// (prior knowledge) on HTTP_PORT, multiplexing up to MAX_CONCURRENT_STREAMS
+	// requests per connection.
+	HTTPKeepAlives           bool `envconfig:"HTTP_KEEP_ALIVES" default:"true"`
+	HTTPIdleTimeoutSeconds   int  `envconfig:"HTTP_IDLE_TIMEOUT_SECONDS" default:"60"`
+	HTTPH2C                  bool `envconfig:"HTTP_H2C" default:"false"`
+	HTTPMaxConcurrentStreams int  `envconfig:"HTTP_MAX_CONCURRENT_STREAMS" default:"250"`
 
 	// gRPC Configuration
 	GRPCPort int `envconfig:"GRPC_PORT" default:"9090"`
@@ -44,16 +57,112 @@ type Config struct {
 	EmbedProvider string  `envconfig:"EMBED_PROVIDER" default:"ollama"`
 	EmbedModel    string  `envconfig:"EMBED_MODEL" default:"nomic-embed-text"`
 	SearchAlpha   float32 `envconfig:"SEARCH_ALPHA" default:"0.6"`
+	// Record search queries and result IDs for relevance feedback (POST /v0/search/feedback)
+	SearchQueryLogEnabled bool `envconfig:"SEARCH_QUERY_LOG_ENABLED" default:"false"`
+	// Weight of entry quality signals (useful/incorrect/outdated) in search ranking; 0 disables
+	SearchSignalWeight float64 `envconfig:"SEARCH_SIGNAL_WEIGHT" default:"0"`
+	// Age (hours) at which rankBy=recency halves a search score
+	SearchRecencyHalfLifeHours float64 `envconfig:"SEARCH_RECENCY_HALF_LIFE_HOURS" default:"168"`
+	// JSON file of named ranking profiles searches can select with "profile"; empty defines none
+	SearchProfilesFile string `envconfig:"SEARCH_PROFILES_FILE" default:""`
+	// Shadow search: rerun this percentage of searches with the named ranking
+	// profile and log both result sets; 0 disables
+	SearchShadowProfile string  `envconfig:"SEARCH_SHADOW_PROFILE" default:""`
+	SearchShadowPercent float64 `envconfig:"SEARCH_SHADOW_PERCENT" default:"0"`
+	// Search limits: largest topK and concurrent searches per actor (0 = no limit).
+	// The ACTOR_ maps override them per actor, e.g. "actor-a:200,actor-b:50"
+	SearchMaxTopK            int            `envconfig:"SEARCH_MAX_TOP_K" default:"100"`
+	SearchMaxConcurrent      int            `envconfig:"SEARCH_MAX_CONCURRENT" default:"4"`
+	SearchActorMaxTopK       map[string]int `envconfig:"SEARCH_ACTOR_MAX_TOP_K" default:""`
+	SearchActorMaxConcurrent map[string]int `envconfig:"SEARCH_ACTOR_MAX_CONCURRENT" default:""`
+	// Ollama keep_alive sent with embed requests (e.g. "30m", "-1" keeps the model loaded); empty uses Ollama's default
+	EmbedKeepAlive string `envconfig:"EMBED_KEEP_ALIVE" default:""`
+	// Summaries of server-written entries (conversation ingestion) and
+	// context regeneration: "extractive" keeps each message's first sentence
+	// without an LLM; "ollama", "openai" and "bedrock" ask SUMMARIZER_MODEL
+	SummarizerProvider string `envconfig:"SUMMARIZER_PROVIDER" default:"extractive"`
+	SummarizerModel    string `envconfig:"SUMMARIZER_MODEL" default:"llama3.2"`
+	// API base of openai (any compatible server) or bedrock (overrides the
+	// regional endpoint); empty uses the provider's public endpoint
+	SummarizerURL string `envconfig:"SUMMARIZER_URL" default:""`
+	// OpenAI API key, or Bedrock API key (bearer token); optional for openai
+	// with SUMMARIZER_URL, e.g. a local compatible server without auth
+	SummarizerAPIKey string `envconfig:"SUMMARIZER_API_KEY" default:""`
+	SummarizerRegion string `envconfig:"SUMMARIZER_REGION" default:"us-east-1"`
+	// Entry summaries per LLM call, and LLM calls per minute (0 = no limit)
+	SummarizerBatchSize         int `envconfig:"SUMMARIZER_BATCH_SIZE" default:"8"`
+	SummarizerRequestsPerMinute int `envconfig:"SUMMARIZER_REQUESTS_PER_MINUTE" default:"0"`
+	// JSON object of summary prompts by memory type ("" = all others); empty uses the built-in prompt
+	SummarizerPromptsFile string `envconfig:"SUMMARIZER_PROMPTS_FILE" default:""`
+
+	// Search reranking: "ollama" asks a local model to judge all hits in
+	// one call (seconds on a CPU; raise RERANKER_TIMEOUT_MILLIS), "cohere" and "voyage" call their rerank APIs; empty disables it.
+	// RERANKER_MODEL empty uses the provider's default model
+	RerankerProvider string `envconfig:"RERANKER_PROVIDER" default:""`
+	RerankerModel    string `envconfig:"RERANKER_MODEL" default:""`
+	// API base of cohere or voyage; empty uses the provider's public endpoint
+	RerankerURL    string `envconfig:"RERANKER_URL" default:""`
+	RerankerAPIKey string `envconfig:"RERANKER_API_KEY" default:""`
+	// Time allowed to rerank one search before its hits are served in index order
+	RerankerTimeoutMillis int `envconfig:"RERANKER_TIMEOUT_MILLIS" default:"3000"`
+
+	// JSON file of vault templates for POST /v0/vaults:fromTemplate; they
+	// replace built-in templates of the same name. Empty uses the built-ins
+	VaultTemplatesFile string `envconfig:"VAULT_TEMPLATES_FILE" default:""`
 
 	// Vector search index endpoint (provider-agnostic)
 	SearchIndexURL string `envconfig:"SEARCH_INDEX_URL" default:""`
+	// Clustered Weaviate: replicas that must answer reads and acknowledge
+	// writes (ONE, QUORUM or ALL; empty uses Weaviate's default), and the
+	// replication of classes created at bootstrap (0 keeps Weaviate's default)
+	SearchReadConsistency   string `envconfig:"SEARCH_READ_CONSISTENCY" default:""`
+	SearchWriteConsistency  string `envconfig:"SEARCH_WRITE_CONSISTENCY" default:""`
+	SearchReplicationFactor int    `envconfig:"SEARCH_REPLICATION_FACTOR" default:"0"`
+	SearchAsyncReplication  bool   `envconfig:"SEARCH_ASYNC_REPLICATION" default:"false"`
 
 	// Health checker configuration
 	HealthIntervalSeconds     int `envconfig:"HEALTH_INTERVAL_SECONDS" default:"30"`
 	HealthProbeTimeoutSeconds int `envconfig:"HEALTH_PROBE_TIMEOUT_SECONDS" default:"2"`
+	// A dependency that changes state HEALTH_FLAP_THRESHOLD times within
+	// HEALTH_FLAP_WINDOW_SECONDS is reported as flapping (0 disables)
+	HealthFlapWindowSeconds int `envconfig:"HEALTH_FLAP_WINDOW_SECONDS" default:"600"`
+	HealthFlapThreshold     int `envconfig:"HEALTH_FLAP_THRESHOLD" default:"4"`
+
+	// Upper bound for client-supplied X-Request-Timeout values (0 disables the cap)
+	MaxRequestTimeoutSeconds int `envconfig:"MAX_REQUEST_TIMEOUT_SECONDS" default:"60"`
+
+	// CORS for browser clients; empty origins disables CORS headers entirely
+	CORSAllowedOrigins   []string `envconfig:"CORS_ALLOWED_ORIGINS" default:""`
+	CORSAllowedHeaders   []string `envconfig:"CORS_ALLOWED_HEADERS" default:"Authorization,Content-Type,If-None-Match,X-Request-ID,X-Request-Timeout"`
+	CORSAllowCredentials bool     `envconfig:"CORS_ALLOW_CREDENTIALS" default:"false"`
+	CORSMaxAgeSeconds    int      `envconfig:"CORS_MAX_AGE_SECONDS" default:"600"`
 
 	// Bootstrap timeout configuration (in seconds)
 	BootstrapTimeoutSeconds int `envconfig:"BOOTSTRAP_TIMEOUT_SECONDS" default:"5"`
+	// Apply the schema embedded in the binary at startup, so a release
+	// binary needs no separate migration step.
+	ApplySchema bool `envconfig:"APPLY_SCHEMA" default:"false"`
+	// Store rawEntry bodies of at least this many bytes zstd-compressed;
+	// reads decompress them transparently. 0 stores every body as text.
+	EntryCompressionMinBytes int `envconfig:"ENTRY_COMPRESSION_MIN_BYTES" default:"0"`
+
+	// Identical entry creations (same actor, memory and content) within this
+	// window return the first entry instead of writing a copy; 0 disables.
+	EntryDedupWindowMillis int `envconfig:"ENTRY_DEDUP_WINDOW_MS" default:"2000"`
+
+	// In-process LRU of recent entry lists, entries and latest contexts,
+	// invalidated by this instance's writes; 0 disables. The TTL bounds how
+	// long writes made through other instances can go unseen.
+	HotCacheSize       int `envconfig:"HOT_CACHE_SIZE" default:"0"`
+	HotCacheTTLSeconds int `envconfig:"HOT_CACHE_TTL_SECONDS" default:"10"`
+
+	// Successful authorizations are cached per (API key, scope) for this
+	// long, up to AuthCacheSize decisions; 0 disables the cache.
+	AuthCacheTTLSeconds int `envconfig:"AUTH_CACHE_TTL_SECONDS" default:"30"`
+	AuthCacheSize       int `envconfig:"AUTH_CACHE_SIZE" default:"10000"`
+
+	// Warm-up: prime embedder and search index after start; readiness is gated until warm
+	WarmupEnabled bool `envconfig:"WARMUP_ENABLED" default:"false"`
 
 	// Testing Configuration
 	TestingUseEmulator  bool `envconfig:"TESTING_USE_EMULATOR" default:"true"`
@@ -63,6 +172,88 @@ type Config struct {
 	// Context handling
 	// Maximum allowed size in characters (Unicode code points) for a context document (0 disables limit)
 	MaxContextChars int `envconfig:"MAX_CONTEXT_CHARS" default:"65536"`
+	// Limits for full context documents uploaded when a context exceeds
+	// MAX_CONTEXT_CHARS: decompressed size, and size of each compressed part
+	ContextDocumentMaxBytes  int64 `envconfig:"CONTEXT_DOCUMENT_MAX_BYTES" default:"16777216"`
+	ContextDocumentPartBytes int64 `envconfig:"CONTEXT_DOCUMENT_PART_BYTES" default:"1048576"`
+
+	// Context history compaction: keep every snapshot for KEEP_ALL_DAYS, then one per
+	// day until KEEP_DAILY_DAYS, then one per week
+	ContextCompactionEnabled         bool `envconfig:"CONTEXT_COMPACTION_ENABLED" default:"false"`
+	ContextKeepAllDays               int  `envconfig:"CONTEXT_KEEP_ALL_DAYS" default:"7"`
+	ContextKeepDailyDays             int  `envconfig:"CONTEXT_KEEP_DAILY_DAYS" default:"90"`
+	ContextCompactionIntervalMinutes int  `envconfig:"CONTEXT_COMPACTION_INTERVAL_MINUTES" default:"60"`
+
+	// Entry retention: expire entries older than RETENTION_DAYS (0 keeps everything).
+	// Policy "age" measures from creation; "lru" from the last get/search that returned the entry
+	EntryRetentionDays            int    `envconfig:"ENTRY_RETENTION_DAYS" default:"0"`
+	EntryRetentionPolicy          string `envconfig:"ENTRY_RETENTION_POLICY" default:"lru"`
+	EntryRetentionIntervalMinutes int    `envconfig:"ENTRY_RETENTION_INTERVAL_MINUTES" default:"60"`
+
+	// Trash: with TRASH_RETENTION_DAYS > 0 deleting a memory or entry moves
+	// it to the trash, where it can be restored until it is purged this many
+	// days later; 0 deletes at once.
+	TrashRetentionDays        int `envconfig:"TRASH_RETENTION_DAYS" default:"0"`
+	TrashPurgeIntervalMinutes int `envconfig:"TRASH_PURGE_INTERVAL_MINUTES" default:"60"`
+
+	// Jobs: the worker checks for queued jobs every JOB_POLL_INTERVAL_SECONDS
+	// and claims a running job again once it reported no progress for
+	// JOB_STALE_MINUTES, e.g. after its replica stopped.
+	JobPollIntervalSeconds int `envconfig:"JOB_POLL_INTERVAL_SECONDS" default:"2"`
+	JobStaleMinutes        int `envconfig:"JOB_STALE_MINUTES" default:"10"`
+
+	// Gradual re-embedding: when EMBED_PROVIDER, EMBED_MODEL or REEMBED_VERSION
+	// change, every memory is marked and, when enabled, re-embedded in the
+	// background at up to REEMBED_ENTRIES_PER_MINUTE records. Bump
+	// REEMBED_VERSION after changing summary prompts
+	ReembedEnabled          bool   `envconfig:"REEMBED_ENABLED" default:"false"`
+	ReembedEntriesPerMinute int    `envconfig:"REEMBED_ENTRIES_PER_MINUTE" default:"600"`
+	ReembedVersion          string `envconfig:"REEMBED_VERSION" default:""`
+
+	// Single-binary mode: run the outbox worker inside memory-service. Replicas
+	// elect one leader through a Postgres advisory lock; followers retry every
+	// OUTBOX_LEADER_RETRY_SECONDS. With OUTBOX_ELECT_LEADER=false every
+	// replica drains the outbox, sharing rows through leases.
+	OutboxInProcess          bool `envconfig:"OUTBOX_IN_PROCESS" default:"false"`
+	OutboxElectLeader        bool `envconfig:"OUTBOX_ELECT_LEADER" default:"true"`
+	OutboxBatchSize          int  `envconfig:"OUTBOX_BATCH_SIZE" default:"100"`
+	OutboxIntervalMillis     int  `envconfig:"OUTBOX_INTERVAL_MS" default:"2000"`
+	OutboxLeaderRetrySeconds int  `envconfig:"OUTBOX_LEADER_RETRY_SECONDS" default:"5"`
+
+	// A claimed outbox row stays with its worker this long (renewed per
+	// row); a crashed worker's rows are claimed again afterwards.
+	OutboxLeaseSeconds int `envconfig:"OUTBOX_LEASE_SECONDS" default:"60"`
+
+	// Outbox workers LISTEN for committed outbox rows and process them at
+	// once, still polling every OUTBOX_INTERVAL_MS as a fallback. Used by
+	// the in-process worker and the standalone outbox-worker.
+	OutboxListen bool `envconfig:"OUTBOX_LISTEN" default:"true"`
+
+	// Outbox rows failing this many times (e.g. a delete the search index
+	// did not apply) are dead-lettered with status 'dead'; 0 retries forever.
+	// Used by the in-process worker and the standalone outbox-worker.
+	OutboxMaxAttempts int `envconfig:"OUTBOX_MAX_ATTEMPTS" default:"0"`
+
+	// Per-endpoint SLOs as "METHOD /path/template=p99,errorRate" entries
+	// separated by ";", "*" matching every other endpoint (empty disables).
+	// A warning is logged when both the 5m and 1h burn rates exceed
+	// SLO_BURN_RATE_ALERT.
+	SLOObjectives    string  `envconfig:"SLO_OBJECTIVES" default:"*=1s,0.01"`
+	SLOBurnRateAlert float64 `envconfig:"SLO_BURN_RATE_ALERT" default:"14.4"`
+
+	// Logging: default level plus per-module overrides ("api", "store",
+	// "outbox", "search"), e.g. "store=debug,outbox=warn"; both can be changed
+	// at runtime through /v0/admin/log-levels. Stdout is written as json or
+	// console (LOG_STDOUT=false turns it off); LOG_FILE adds a file sink rotated
+	// at LOG_FILE_MAX_SIZE_MB (0 never rotates), keeping LOG_FILE_MAX_BACKUPS files.
+	LogLevel          string `envconfig:"LOG_LEVEL" default:"info"`
+	LogModuleLevels   string `envconfig:"LOG_MODULE_LEVELS" default:""`
+	LogStdout         bool   `envconfig:"LOG_STDOUT" default:"true"`
+	LogFormat         string `envconfig:"LOG_FORMAT" default:"json"`
+	LogFile           string `envconfig:"LOG_FILE" default:""`
+	LogFileFormat     string `envconfig:"LOG_FILE_FORMAT" default:"json"`
+	LogFileMaxSizeMB  int    `envconfig:"LOG_FILE_MAX_SIZE_MB" default:"100"`
+	LogFileMaxBackups int    `envconfig:"LOG_FILE_MAX_BACKUPS" default:"5"`
 }
 
 // ResolveDefaults validates BuildTarget and derives DBDriver when set to "auto" or empty.
@@ -88,9 +279,139 @@ func (c *Config) ResolveDefaults() error {
 	if !allowedDB[c.DBDriver] {
 		return fmt.Errorf("unsupported DB_DRIVER: %s", c.DBDriver)
 	}
+
+	switch c.EntryRetentionPolicy {
+	case "age", "lru":
+	default:
+		return fmt.Errorf("unsupported ENTRY_RETENTION_POLICY: %s (want age or lru)", c.EntryRetentionPolicy)
+	}
+
+	if c.ReembedEnabled && c.ReembedEntriesPerMinute <= 0 {
+		return fmt.Errorf("REEMBED_ENTRIES_PER_MINUTE must be positive when REEMBED_ENABLED is set")
+	}
+
+	if c.HTTPIdleTimeoutSeconds < 0 || c.HTTPMaxConcurrentStreams < 0 {
+		return fmt.Errorf("HTTP_IDLE_TIMEOUT_SECONDS and HTTP_MAX_CONCURRENT_STREAMS must not be negative")
+	}
+	if c.SearchMaxTopK < 0 || c.SearchMaxConcurrent < 0 {
+		return fmt.Errorf("SEARCH_MAX_TOP_K and SEARCH_MAX_CONCURRENT must not be negative")
+	}
+	if c.HealthFlapWindowSeconds < 0 || c.HealthFlapThreshold < 0 {
+		return fmt.Errorf("HEALTH_FLAP_WINDOW_SECONDS and HEALTH_FLAP_THRESHOLD must not be negative")
+	}
+	if c.AuthCacheTTLSeconds < 0 || c.AuthCacheSize < 0 {
+		return fmt.Errorf("AUTH_CACHE_TTL_SECONDS and AUTH_CACHE_SIZE must not be negative")
+	}
+	if c.TrashRetentionDays < 0 {
+		return fmt.Errorf("TRASH_RETENTION_DAYS must not be negative")
+	}
+	if c.TrashRetentionDays > 0 && c.TrashPurgeIntervalMinutes <= 0 {
+		return fmt.Errorf("TRASH_PURGE_INTERVAL_MINUTES must be positive when TRASH_RETENTION_DAYS is set")
+	}
+	if c.JobPollIntervalSeconds <= 0 || c.JobStaleMinutes <= 0 {
+		return fmt.Errorf("JOB_POLL_INTERVAL_SECONDS and JOB_STALE_MINUTES must be positive")
+	}
+	if c.EntryCompressionMinBytes < 0 {
+		return fmt.Errorf("ENTRY_COMPRESSION_MIN_BYTES must not be negative")
+	}
+	if c.ContextDocumentMaxBytes <= 0 || c.ContextDocumentPartBytes <= 0 {
+		return fmt.Errorf("CONTEXT_DOCUMENT_MAX_BYTES and CONTEXT_DOCUMENT_PART_BYTES must be positive")
+	}
+	if c.SearchShadowPercent < 0 || c.SearchShadowPercent > 100 {
+		return fmt.Errorf("SEARCH_SHADOW_PERCENT must be between 0 and 100")
+	}
+	if c.SearchShadowPercent > 0 && c.SearchShadowProfile == "" {
+		return fmt.Errorf("SEARCH_SHADOW_PERCENT requires SEARCH_SHADOW_PROFILE")
+	}
+	if c.CORSAllowCredentials && slices.ContainsFunc(c.CORSAllowedOrigins, func(o string) bool { return strings.TrimSpace(o) == "*" }) {
+		return fmt.Errorf("CORS_ALLOW_CREDENTIALS requires CORS_ALLOWED_ORIGINS to list origins, not *")
+	}
+	wc := c.WeaviateConfig()
+	if err := wc.Validate(); err != nil {
+		return fmt.Errorf("SEARCH_READ_CONSISTENCY/SEARCH_WRITE_CONSISTENCY/SEARCH_REPLICATION_FACTOR: %w", err)
+	}
+	if c.SearchRecencyHalfLifeHours <= 0 {
+		return fmt.Errorf("SEARCH_RECENCY_HALF_LIFE_HOURS must be positive")
+	}
+	switch c.SummarizerProvider {
+	case "extractive", "ollama":
+	case "openai":
+		if c.SummarizerAPIKey == "" && c.SummarizerURL == "" {
+			return fmt.Errorf("SUMMARIZER_PROVIDER=openai requires SUMMARIZER_API_KEY or SUMMARIZER_URL")
+		}
+	case "bedrock":
+		if c.SummarizerAPIKey == "" {
+			return fmt.Errorf("SUMMARIZER_PROVIDER=bedrock requires SUMMARIZER_API_KEY")
+		}
+	default:
+		return fmt.Errorf("unsupported SUMMARIZER_PROVIDER: %s (want extractive, ollama, openai or bedrock)", c.SummarizerProvider)
+	}
+	switch c.RerankerProvider {
+	case "", "ollama":
+	case "cohere", "voyage":
+		if c.RerankerAPIKey == "" {
+			return fmt.Errorf("RERANKER_PROVIDER=%s requires RERANKER_API_KEY", c.RerankerProvider)
+		}
+	default:
+		return fmt.Errorf("unsupported RERANKER_PROVIDER: %s (want ollama, cohere or voyage)", c.RerankerProvider)
+	}
+	if c.RerankerTimeoutMillis <= 0 {
+		return fmt.Errorf("RERANKER_TIMEOUT_MILLIS must be positive")
+	}
+	if c.SummarizerBatchSize < 1 || c.SummarizerBatchSize > 50 {
+		return fmt.Errorf("SUMMARIZER_BATCH_SIZE must be between 1 and 50")
+	}
+	if c.SummarizerRequestsPerMinute < 0 {
+		return fmt.Errorf("SUMMARIZER_REQUESTS_PER_MINUTE must not be negative")
+	}
+	if c.SLOBurnRateAlert <= 0 {
+		return fmt.Errorf("SLO_BURN_RATE_ALERT must be positive")
+	}
+	if _, err := logger.ParseLevels(c.LogLevel, c.LogModuleLevels); err != nil {
+		return fmt.Errorf("LOG_LEVEL/LOG_MODULE_LEVELS: %w", err)
+	}
+	for _, f := range []string{c.LogFormat, c.LogFileFormat} {
+		if f != logger.FormatJSON && f != logger.FormatConsole {
+			return fmt.Errorf("unsupported LOG_FORMAT/LOG_FILE_FORMAT: %s (want json or console)", f)
+		}
+	}
+	if c.LogFileMaxSizeMB < 0 || c.LogFileMaxBackups < 0 {
+		return fmt.Errorf("LOG_FILE_MAX_SIZE_MB and LOG_FILE_MAX_BACKUPS must not be negative")
+	}
+	if !c.LogStdout && c.LogFile == "" {
+		return fmt.Errorf("LOG_STDOUT=false requires LOG_FILE")
+	}
 	return nil
 }
 
+// LoggerConfig returns the log sinks and levels selected by the LOG_ settings.
+func (c *Config) LoggerConfig() logger.Config {
+	lc := logger.Config{Level: c.LogLevel, ModuleLevels: c.LogModuleLevels}
+	if c.LogStdout {
+		lc.Sinks = append(lc.Sinks, logger.Sink{Format: c.LogFormat})
+	}
+	if c.LogFile != "" {
+		lc.Sinks = append(lc.Sinks, logger.Sink{
+			Path:       c.LogFile,
+			Format:     c.LogFileFormat,
+			MaxSizeMB:  c.LogFileMaxSizeMB,
+			MaxBackups: c.LogFileMaxBackups,
+		})
+	}
+	return lc
+}
+
+// WeaviateConfig returns the search index's consistency and replication
+// settings.
+func (c *Config) WeaviateConfig() searchindex.WeaviateConfig {
+	return searchindex.WeaviateConfig{
+		ReadConsistency:   c.SearchReadConsistency,
+		WriteConsistency:  c.SearchWriteConsistency,
+		ReplicationFactor: c.SearchReplicationFactor,
+		AsyncReplication:  c.SearchAsyncReplication,
+	}
+}
+
 // New creates a new Config by parsing environment variables
 // Environment variables should be prefixed with MEMORY_SERVER_
 // Example: MEMORY_SERVER_HTTP_PORT, MEMORY_SERVER_POSTGRES_DSN
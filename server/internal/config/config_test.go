@@ -58,3 +58,146 @@ func TestConfigLoad_BootstrapTimeoutEnvOverride(t *testing.T) {
 		t.Fatalf("bootstrap timeout env override failed, got %d", cfg.BootstrapTimeoutSeconds)
 	}
 }
+
+func TestConfigLoad_WarmupDefaults(t *testing.T) {
+	_ = os.Unsetenv("MEMORY_SERVER_WARMUP_ENABLED")
+	_ = os.Unsetenv("MEMORY_SERVER_EMBED_KEEP_ALIVE")
+
+	cfg, err := New()
+	if err != nil {
+		t.Fatalf("config load: %v", err)
+	}
+	if cfg.WarmupEnabled || cfg.EmbedKeepAlive != "" {
+		t.Fatalf("unexpected warm-up defaults: enabled=%v keepAlive=%q", cfg.WarmupEnabled, cfg.EmbedKeepAlive)
+	}
+}
+
+func TestConfigLoad_CORS(t *testing.T) {
+	_ = os.Unsetenv("MEMORY_SERVER_CORS_ALLOWED_ORIGINS")
+	cfg, err := New()
+	if err != nil {
+		t.Fatalf("config load: %v", err)
+	}
+	if len(cfg.CORSAllowedOrigins) != 0 || len(cfg.CORSAllowedHeaders) == 0 {
+		t.Fatalf("unexpected CORS defaults: origins=%v headers=%v", cfg.CORSAllowedOrigins, cfg.CORSAllowedHeaders)
+	}
+
+	_ = os.Setenv("MEMORY_SERVER_CORS_ALLOWED_ORIGINS", "http://localhost:3000,https://app.example.com")
+	defer func() { _ = os.Unsetenv("MEMORY_SERVER_CORS_ALLOWED_ORIGINS") }()
+	cfg, err = New()
+	if err != nil {
+		t.Fatalf("config load: %v", err)
+	}
+	if len(cfg.CORSAllowedOrigins) != 2 || cfg.CORSAllowedOrigins[1] != "https://app.example.com" {
+		t.Fatalf("CORS origins env override failed, got %v", cfg.CORSAllowedOrigins)
+	}
+
+	t.Setenv("MEMORY_SERVER_CORS_ALLOW_CREDENTIALS", "true")
+	if _, err := New(); err != nil {
+		t.Fatalf("credentials with listed origins: %v", err)
+	}
+	t.Setenv("MEMORY_SERVER_CORS_ALLOWED_ORIGINS", "http://localhost:3000, *")
+	if _, err := New(); err == nil {
+		t.Fatal("expected error for credentials with a * origin")
+	}
+}
+
+func TestConfigLoad_SearchLimits(t *testing.T) {
+	cfg, err := New()
+	if err != nil {
+		t.Fatalf("config load: %v", err)
+	}
+	if cfg.SearchMaxTopK != 100 || cfg.SearchMaxConcurrent != 4 || len(cfg.SearchActorMaxTopK) != 0 {
+		t.Fatalf("unexpected search limit defaults: %+v", cfg)
+	}
+
+	t.Setenv("MEMORY_SERVER_SEARCH_ACTOR_MAX_TOP_K", "bulk-exporter:500,noisy-agent:20")
+	cfg, err = New()
+	if err != nil {
+		t.Fatalf("config load: %v", err)
+	}
+	if cfg.SearchActorMaxTopK["bulk-exporter"] != 500 || cfg.SearchActorMaxTopK["noisy-agent"] != 20 {
+		t.Fatalf("per-actor topK override failed, got %v", cfg.SearchActorMaxTopK)
+	}
+
+	t.Setenv("MEMORY_SERVER_SEARCH_MAX_CONCURRENT", "-1")
+	if _, err := New(); err == nil {
+		t.Fatal("expected error for negative SEARCH_MAX_CONCURRENT")
+	}
+}
+
+func TestConfigLoad_OutboxInProcess(t *testing.T) {
+	t.Setenv("MEMORY_SERVER_OUTBOX_IN_PROCESS", "true")
+	t.Setenv("MEMORY_SERVER_OUTBOX_INTERVAL_MS", "500")
+	cfg, err := New()
+	if err != nil {
+		t.Fatalf("config load: %v", err)
+	}
+	if !cfg.OutboxInProcess || cfg.OutboxIntervalMillis != 500 || cfg.OutboxBatchSize != 100 || cfg.OutboxLeaderRetrySeconds != 5 {
+		t.Fatalf("unexpected outbox config: %+v", cfg)
+	}
+}
+
+func TestConfigLoad_SearchShadow(t *testing.T) {
+	t.Setenv("MEMORY_SERVER_SEARCH_SHADOW_PERCENT", "5")
+	if _, err := New(); err == nil {
+		t.Fatal("expected error for SEARCH_SHADOW_PERCENT without a profile")
+	}
+	t.Setenv("MEMORY_SERVER_SEARCH_SHADOW_PROFILE", "fresh")
+	cfg, err := New()
+	if err != nil || cfg.SearchShadowPercent != 5 || cfg.SearchShadowProfile != "fresh" {
+		t.Fatalf("shadow config: cfg=%+v err=%v", cfg, err)
+	}
+	t.Setenv("MEMORY_SERVER_SEARCH_SHADOW_PERCENT", "150")
+	if _, err := New(); err == nil {
+		t.Fatal("expected error for SEARCH_SHADOW_PERCENT above 100")
+	}
+}
+
+func TestConfigLoad_SearchConsistency(t *testing.T) {
+	t.Setenv("MEMORY_SERVER_SEARCH_READ_CONSISTENCY", "one")
+	t.Setenv("MEMORY_SERVER_SEARCH_WRITE_CONSISTENCY", "ALL")
+	t.Setenv("MEMORY_SERVER_SEARCH_REPLICATION_FACTOR", "3")
+	cfg, err := New()
+	if err != nil {
+		t.Fatalf("config load: %v", err)
+	}
+	wc := cfg.WeaviateConfig()
+	if wc.ReadConsistency != "one" || wc.WriteConsistency != "ALL" || wc.ReplicationFactor != 3 {
+		t.Fatalf("unexpected weaviate config: %+v", wc)
+	}
+	t.Setenv("MEMORY_SERVER_SEARCH_READ_CONSISTENCY", "most")
+	if _, err := New(); err == nil {
+		t.Fatal("expected error for an unknown consistency level")
+	}
+}
+
+func TestConfigLoad_Reembed(t *testing.T) {
+	cfg, err := New()
+	if err != nil {
+		t.Fatalf("config load: %v", err)
+	}
+	if cfg.ReembedEnabled || cfg.ReembedEntriesPerMinute != 600 || cfg.ReembedVersion != "" {
+		t.Fatalf("unexpected re-embed defaults: %+v", cfg)
+	}
+	t.Setenv("MEMORY_SERVER_REEMBED_ENABLED", "true")
+	t.Setenv("MEMORY_SERVER_REEMBED_ENTRIES_PER_MINUTE", "0")
+	if _, err := New(); err == nil {
+		t.Fatal("expected error for REEMBED_ENTRIES_PER_MINUTE 0")
+	}
+}
+
+func TestConfigLoad_SummarizerAPIKey(t *testing.T) {
+	t.Setenv("MEMORY_SERVER_SUMMARIZER_PROVIDER", "openai")
+	if _, err := New(); err == nil {
+		t.Fatal("expected error for openai without an API key or URL")
+	}
+	t.Setenv("MEMORY_SERVER_SUMMARIZER_URL", "http://localhost:8000/v1")
+	if _, err := New(); err != nil {
+		t.Fatalf("openai with a URL needs no API key: %v", err)
+	}
+	t.Setenv("MEMORY_SERVER_SUMMARIZER_PROVIDER", "bedrock")
+	if _, err := New(); err == nil {
+		t.Fatal("expected error for bedrock without an API key")
+	}
+}
@@ -53,3 +53,23 @@ func TestResolveDefaultsLocal(t *testing.T) {
 		t.Fatalf("unexpected mapping for local: %s", cfg.DBDriver)
 	}
 }
+
+func TestResolveDefaultsRejectsUnknownRetentionPolicy(t *testing.T) {
+	unsetBuildEnv()
+	_ = os.Setenv("MEMORY_SERVER_ENTRY_RETENTION_POLICY", "fifo")
+	defer func() { _ = os.Unsetenv("MEMORY_SERVER_ENTRY_RETENTION_POLICY") }()
+
+	if _, err := New(); err == nil {
+		t.Fatalf("expected error for unknown retention policy")
+	}
+}
+
+func TestResolveDefaultsRejectsUnknownLogModule(t *testing.T) {
+	unsetBuildEnv()
+	_ = os.Setenv("MEMORY_SERVER_LOG_MODULE_LEVELS", "cache=debug")
+	defer func() { _ = os.Unsetenv("MEMORY_SERVER_LOG_MODULE_LEVELS") }()
+
+	if _, err := New(); err == nil {
+		t.Fatalf("expected error for unknown log module")
+	}
+}
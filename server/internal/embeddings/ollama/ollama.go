@@ -11,10 +11,19 @@ import (
 	"time"
 )
 
-type Provider struct{ model string }
+type Provider struct {
+	model     string
+	keepAlive string
+}
 
 func New(model string) *Provider { return &Provider{model: model} }
 
+// NewWithKeepAlive returns a provider that asks Ollama to keep the model
+// loaded for keepAlive (Ollama duration syntax, e.g. "30m"; "-1" = forever).
+func NewWithKeepAlive(model, keepAlive string) *Provider {
+	return &Provider{model: model, keepAlive: keepAlive}
+}
+
 func (p *Provider) Embed(ctx context.Context, text string) ([]float32, error) {
 	// Use Ollama embeddings HTTP API
 	base := os.Getenv("OLLAMA_URL")
@@ -34,15 +43,16 @@ func (p *Provider) Embed(ctx context.Context, text string) ([]float32, error) {
 	}
 
 	type embReq struct {
-		Model  string `json:"model"`
-		Prompt string `json:"prompt"`
+		Model     string `json:"model"`
+		Prompt    string `json:"prompt"`
+		KeepAlive string `json:"keep_alive,omitempty"`
 	}
 	type embResp struct {
 		Embedding []float64 `json:"embedding"`
 		Error     string    `json:"error"`
 	}
 
-	body, _ := json.Marshal(embReq{Model: p.model, Prompt: text})
+	body, _ := json.Marshal(embReq{Model: p.model, Prompt: text, KeepAlive: p.keepAlive})
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, base+"/api/embeddings", bytes.NewBuffer(body))
 	if err != nil {
 		return nil, err
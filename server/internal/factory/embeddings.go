@@ -18,10 +18,10 @@ func NewEmbeddingProvider(ctx context.Context, cfg *config.Config, log zerolog.L
 
 	switch cfg.EmbedProvider {
 	case "", "ollama":
-		provider = ollama.New(cfg.EmbedModel)
+		provider = ollama.NewWithKeepAlive(cfg.EmbedModel, cfg.EmbedKeepAlive)
 	default:
 		log.Warn().Str("provider", cfg.EmbedProvider).Msg("unknown embedding provider; using ollama")
-		provider = ollama.New(cfg.EmbedModel)
+		provider = ollama.NewWithKeepAlive(cfg.EmbedModel, cfg.EmbedKeepAlive)
 	}
 
 	if provider == nil {
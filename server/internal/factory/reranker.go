@@ -0,0 +1,27 @@
+package factory
+
+import (
+	"github.com/rs/zerolog"
+
+	"github.com/mycelian/mycelian-memory/server/internal/config"
+	"github.com/mycelian/mycelian-memory/server/internal/reranker"
+)
+
+// NewReranker creates the configured search reranker, or nil when
+// reranking is off.
+func NewReranker(cfg *config.Config, log zerolog.Logger) reranker.Reranker {
+	var r reranker.Reranker
+	switch cfg.RerankerProvider {
+	case "ollama":
+		r = reranker.NewOllama(cfg.RerankerModel, cfg.EmbedKeepAlive)
+	case "cohere":
+		r = reranker.NewCohere(cfg.RerankerURL, cfg.RerankerAPIKey, cfg.RerankerModel)
+	case "voyage":
+		r = reranker.NewVoyage(cfg.RerankerURL, cfg.RerankerAPIKey, cfg.RerankerModel)
+	default:
+		return nil
+	}
+	log.Info().Str("provider", cfg.RerankerProvider).Str("model", cfg.RerankerModel).
+		Int("timeout_ms", cfg.RerankerTimeoutMillis).Msg("search: reranker")
+	return r
+}
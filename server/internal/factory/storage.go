@@ -8,12 +8,15 @@ import (
 	"github.com/rs/zerolog"
 
 	"github.com/mycelian/mycelian-memory/server/internal/config"
+	schema "github.com/mycelian/mycelian-memory/server/internal/storage/postgres"
 	storepkg "github.com/mycelian/mycelian-memory/server/internal/store"
 	storepg "github.com/mycelian/mycelian-memory/server/internal/store/postgres"
 )
 
 // NewStore returns a Postgres-backed store.Store.
 // Requires cfg.DBDriver == "postgres" and a non-empty cfg.PostgresDSN.
+// With cfg.ApplySchema the embedded schema is applied before returning.
+// Entries are compressed per cfg.EntryCompressionMinBytes.
 // Launches async bootstrap check; returns store immediately for fast startup.
 func NewStore(ctx context.Context, cfg *config.Config, log zerolog.Logger) (storepkg.Store, error) {
 	if cfg.DBDriver != "postgres" {
@@ -29,6 +32,16 @@ func NewStore(ctx context.Context, cfg *config.Config, log zerolog.Logger) (stor
 	if err != nil {
 		return nil, err
 	}
+	if cfg.ApplySchema {
+		applyCtx, cancel := context.WithTimeout(ctx, time.Duration(cfg.BootstrapTimeoutSeconds)*time.Second)
+		err := schema.ApplySchema(applyCtx, db)
+		cancel()
+		if err != nil {
+			_ = db.Close()
+			return nil, err
+		}
+		log.Info().Msg("applied embedded schema")
+	}
 
 	// Async bootstrap check with configurable timeout; don't block startup
 	go func() {
@@ -43,5 +56,5 @@ func NewStore(ctx context.Context, cfg *config.Config, log zerolog.Logger) (stor
 		}
 	}()
 
-	return storepg.NewWithDB(db), nil
+	return storepg.NewWithDB(db, storepg.WithEntryCompression(cfg.EntryCompressionMinBytes)), nil
 }
@@ -0,0 +1,37 @@
+package factory
+
+import (
+	"github.com/rs/zerolog"
+
+	"github.com/mycelian/mycelian-memory/server/internal/config"
+	"github.com/mycelian/mycelian-memory/server/internal/summarizer"
+)
+
+// NewLLMPipeline creates the pipeline all server-side LLM calls go through,
+// or nil when the summarizer provider is not an LLM ("extractive").
+func NewLLMPipeline(cfg *config.Config, log zerolog.Logger) (*summarizer.Pipeline, error) {
+	var gen summarizer.Generator
+	switch cfg.SummarizerProvider {
+	case "ollama":
+		gen = summarizer.NewOllama(cfg.SummarizerModel, cfg.EmbedKeepAlive)
+	case "openai":
+		gen = summarizer.NewOpenAI(cfg.SummarizerURL, cfg.SummarizerAPIKey, cfg.SummarizerModel)
+	case "bedrock":
+		gen = summarizer.NewBedrock(cfg.SummarizerURL, cfg.SummarizerRegion, cfg.SummarizerAPIKey, cfg.SummarizerModel)
+	default:
+		return nil, nil
+	}
+	prompts, err := summarizer.LoadPrompts(cfg.SummarizerPromptsFile)
+	if err != nil {
+		return nil, err
+	}
+	log.Info().Str("provider", cfg.SummarizerProvider).Str("model", cfg.SummarizerModel).
+		Int("batch_size", cfg.SummarizerBatchSize).Int("requests_per_minute", cfg.SummarizerRequestsPerMinute).
+		Int("prompts", len(prompts)).Msg("summarizer: llm pipeline")
+	return summarizer.NewPipeline(gen, summarizer.PipelineConfig{
+		Provider:          cfg.SummarizerProvider,
+		Prompts:           prompts,
+		BatchSize:         cfg.SummarizerBatchSize,
+		RequestsPerMinute: cfg.SummarizerRequestsPerMinute,
+	}, log), nil
+}
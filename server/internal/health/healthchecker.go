@@ -20,17 +20,41 @@ type ServiceHealthChecker struct {
 	healthy atomic.Int32
 	deps    []HealthChecker
 	log     zerolog.Logger
+	hist    *history
+	now     func() time.Time
 }
 
 func NewServiceHealthChecker(log zerolog.Logger, deps ...HealthChecker) *ServiceHealthChecker {
-	h := &ServiceHealthChecker{deps: deps, log: log}
+	h := &ServiceHealthChecker{deps: deps, log: log, hist: newHistory(log), now: time.Now}
 	h.healthy.Store(0)
 	return h
 }
 
+// EnableFlapDetection logs a warning and counts a flap when a dependency or
+// the service changes state at least threshold times within window. Call it
+// before Start; a threshold of zero leaves detection off.
+func (h *ServiceHealthChecker) EnableFlapDetection(window time.Duration, threshold int) {
+	if window > 0 && threshold > 0 {
+		h.hist.window, h.hist.threshold = window, threshold
+	}
+}
+
+// History returns the recorded transitions, newest first, and the flap
+// state of each dependency and of the service.
+func (h *ServiceHealthChecker) History() History { return h.hist.snapshot() }
+
 // IsHealthy returns cached service health.
 func (h *ServiceHealthChecker) IsHealthy() bool { return h.healthy.Load() == 1 }
 
+// Components returns the cached health of each dependency keyed by checker name.
+func (h *ServiceHealthChecker) Components() map[string]bool {
+	out := make(map[string]bool, len(h.deps))
+	for _, c := range h.deps {
+		out[c.Name()] = c.IsHealthy()
+	}
+	return out
+}
+
 // Start periodically evaluates dependency health and updates the service flag.
 func (h *ServiceHealthChecker) Start(ctx context.Context, interval time.Duration) {
 	ticker := time.NewTicker(interval)
@@ -38,12 +62,16 @@ func (h *ServiceHealthChecker) Start(ctx context.Context, interval time.Duration
 
 	prev := int32(0)
 	eval := func() {
+		now := h.now()
 		all := true
 		for _, c := range h.deps {
-			if !c.IsHealthy() {
+			ok := c.IsHealthy()
+			h.hist.observe(c.Name(), ok, now)
+			if !ok {
 				all = false
 			}
 		}
+		h.hist.observe(ServiceComponent, all, now)
 		if all {
 			h.healthy.Store(1)
 		} else {
@@ -43,6 +43,18 @@ func TestServiceHealthChecker_Transitions(t *testing.T) {
 	waitTrue(t, func() bool { return svc.IsHealthy() })
 }
 
+func TestServiceHealthChecker_Components(t *testing.T) {
+	a := &fakeChecker{name: "store"}
+	b := &fakeChecker{name: "embedder"}
+	a.healthy.Store(1)
+
+	svc := NewServiceHealthChecker(zerolog.Nop(), a, b)
+	got := svc.Components()
+	if len(got) != 2 || !got["store"] || got["embedder"] {
+		t.Fatalf("unexpected components: %v", got)
+	}
+}
+
 func waitTrue(t *testing.T, pred func() bool) {
 	t.Helper()
 	deadline := time.Now().Add(500 * time.Millisecond)
@@ -0,0 +1,152 @@
+package health
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// ServiceComponent names the aggregate service flag in the history.
+const ServiceComponent = "service"
+
+// historySize bounds the transitions kept in memory.
+const historySize = 256
+
+// Transition is one change of a component's health.
+type Transition struct {
+	Component string    `json:"component"`
+	Healthy   bool      `json:"healthy"`
+	Time      time.Time `json:"time"`
+}
+
+// ComponentHistory summarizes a component's recent stability.
+type ComponentHistory struct {
+	Component string `json:"component"`
+	Healthy   bool   `json:"healthy"`
+	// RecentTransitions counts changes within the flap window.
+	RecentTransitions int  `json:"recentTransitions"`
+	Flapping          bool `json:"flapping"`
+	// FlapCount counts the times the component started flapping since the
+	// process started.
+	FlapCount int64 `json:"flapCount"`
+	// LastChange is the time of the latest transition, if any.
+	LastChange *time.Time `json:"lastChange,omitempty"`
+}
+
+// History is the health monitor's record of transitions, newest first,
+// with per-component flap state.
+type History struct {
+	Components  []ComponentHistory `json:"components"`
+	Transitions []Transition       `json:"transitions"`
+	// FlapWindowSeconds and FlapThreshold are the detection settings; zero
+	// when flap detection is off.
+	FlapWindowSeconds int `json:"flapWindowSeconds"`
+	FlapThreshold     int `json:"flapThreshold"`
+}
+
+// history records transitions in a ring and flags components that change
+// state at least threshold times within window. Components start unhealthy,
+// as the checkers do, so the first transition is the one to healthy.
+type history struct {
+	window    time.Duration
+	threshold int
+	log       zerolog.Logger
+
+	mu          sync.Mutex
+	transitions []Transition // ring of historySize, next at total%historySize
+	total       int
+	state       map[string]bool
+	recent      map[string][]time.Time
+	flapping    map[string]bool
+	flapCount   map[string]int64
+	lastChange  map[string]time.Time
+}
+
+func newHistory(log zerolog.Logger) *history {
+	return &history{
+		log:        log,
+		state:      map[string]bool{},
+		recent:     map[string][]time.Time{},
+		flapping:   map[string]bool{},
+		flapCount:  map[string]int64{},
+		lastChange: map[string]time.Time{},
+	}
+}
+
+// observe records component's health at now, adding a transition when it
+// changed, and updates its flap state.
+func (h *history) observe(component string, healthy bool, now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.state[component]; !ok {
+		h.state[component] = false
+	}
+	changed := h.state[component] != healthy
+	if changed {
+		h.state[component] = healthy
+		h.lastChange[component] = now
+		t := Transition{Component: component, Healthy: healthy, Time: now}
+		if len(h.transitions) < historySize {
+			h.transitions = append(h.transitions, t)
+		} else {
+			h.transitions[h.total%historySize] = t
+		}
+		h.total++
+	}
+	if h.threshold <= 0 {
+		return
+	}
+	if changed {
+		h.recent[component] = append(h.recent[component], now)
+	}
+	h.recent[component] = pruneBefore(h.recent[component], now.Add(-h.window))
+	n := len(h.recent[component])
+	switch was := h.flapping[component]; {
+	case n >= h.threshold && !was:
+		h.flapping[component] = true
+		h.flapCount[component]++
+		h.log.Warn().
+			Str("component", component).
+			Int("transitions", n).
+			Dur("window", h.window).
+			Int64("flap_count", h.flapCount[component]).
+			Msg("health flapping")
+	case n < h.threshold && was:
+		h.flapping[component] = false
+		h.log.Info().Str("component", component).Bool("healthy", healthy).Msg("health stable again")
+	}
+}
+
+// pruneBefore drops the times before cutoff from the sorted ts.
+func pruneBefore(ts []time.Time, cutoff time.Time) []time.Time {
+	i := sort.Search(len(ts), func(i int) bool { return !ts[i].Before(cutoff) })
+	return ts[i:]
+}
+
+func (h *history) snapshot() History {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	out := History{
+		Components:        make([]ComponentHistory, 0, len(h.state)),
+		Transitions:       make([]Transition, 0, len(h.transitions)),
+		FlapWindowSeconds: int(h.window / time.Second),
+		FlapThreshold:     h.threshold,
+	}
+	for i := 1; i <= len(h.transitions); i++ {
+		out.Transitions = append(out.Transitions, h.transitions[(h.total-i)%historySize])
+	}
+	for name, healthy := range h.state {
+		c := ComponentHistory{
+			Component: name, Healthy: healthy, RecentTransitions: len(h.recent[name]),
+			Flapping: h.flapping[name], FlapCount: h.flapCount[name],
+		}
+		if t, ok := h.lastChange[name]; ok {
+			c.LastChange = &t
+		}
+		out.Components = append(out.Components, c)
+	}
+	sort.Slice(out.Components, func(i, j int) bool { return out.Components[i].Component < out.Components[j].Component })
+	return out
+}
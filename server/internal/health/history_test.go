@@ -0,0 +1,51 @@
+package health
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestHistory_FlapDetection(t *testing.T) {
+	h := newHistory(zerolog.Nop())
+	h.window, h.threshold = time.Minute, 4
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// up, down, up: three transitions stay below the threshold
+	for i, ok := range []bool{true, true, false, true} {
+		h.observe("store", ok, t0.Add(time.Duration(i)*10*time.Second))
+	}
+	if c := h.snapshot().Components[0]; c.Flapping || c.RecentTransitions != 3 {
+		t.Fatalf("three transitions must not flap: %+v", c)
+	}
+	h.observe("store", false, t0.Add(40*time.Second))
+	got := h.snapshot()
+	if c := got.Components[0]; !c.Flapping || c.FlapCount != 1 || c.Healthy {
+		t.Fatalf("fourth transition within the window must flap: %+v", c)
+	}
+	if len(got.Transitions) != 4 || got.Transitions[0].Healthy || !got.Transitions[3].Healthy {
+		t.Fatalf("transitions must be newest first: %+v", got.Transitions)
+	}
+
+	// A minute after the third change the earlier transitions fall out of the window.
+	h.observe("store", false, t0.Add(95*time.Second))
+	if c := h.snapshot().Components[0]; c.Flapping || c.FlapCount != 1 || c.RecentTransitions != 1 {
+		t.Fatalf("expected the component to settle: %+v", c)
+	}
+}
+
+func TestHistory_RingKeepsNewest(t *testing.T) {
+	h := newHistory(zerolog.Nop())
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < historySize+3; i++ {
+		h.observe("index", i%2 == 0, t0.Add(time.Duration(i)*time.Second))
+	}
+	got := h.snapshot()
+	if len(got.Transitions) != historySize || !got.Transitions[0].Time.Equal(t0.Add(time.Duration(historySize+2)*time.Second)) {
+		t.Fatalf("expected the newest %d transitions, first=%v", historySize, got.Transitions[0].Time)
+	}
+	if c := got.Components[0]; c.Flapping || c.RecentTransitions != 0 {
+		t.Fatalf("detection is off without a threshold: %+v", c)
+	}
+}
@@ -0,0 +1,134 @@
+package logger
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+// Modules with their own log level. Loggers of other components use the
+// default level.
+const (
+	ModuleAPI    = "api"
+	ModuleStore  = "store"
+	ModuleOutbox = "outbox"
+	ModuleSearch = "search"
+)
+
+// DefaultModule names the default level in Levels.Snapshot and Levels.Set.
+const DefaultModule = "default"
+
+var knownModules = map[string]bool{ModuleAPI: true, ModuleStore: true, ModuleOutbox: true, ModuleSearch: true}
+
+// Levels holds the default log level and per-module overrides. It is safe
+// for concurrent use, so levels can change while the process runs.
+type Levels struct {
+	mu      sync.RWMutex
+	def     zerolog.Level
+	modules map[string]zerolog.Level
+}
+
+// ParseLevels returns Levels with defaultLevel (e.g. "info") and the
+// overrides in spec, comma-separated module=level pairs such as
+// "store=debug,outbox=warn".
+func ParseLevels(defaultLevel, spec string) (*Levels, error) {
+	def, err := parseLevel(defaultLevel)
+	if err != nil {
+		return nil, err
+	}
+	l := &Levels{def: def, modules: map[string]zerolog.Level{}}
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		module, level, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid module level %q: want module=level", pair)
+		}
+		if err := l.Set(strings.TrimSpace(module), strings.TrimSpace(level)); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+// Level returns module's effective level.
+func (l *Levels) Level(module string) zerolog.Level {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if lvl, ok := l.modules[module]; ok {
+		return lvl
+	}
+	return l.def
+}
+
+// Set changes module's level, or the default level for DefaultModule. An
+// empty level removes the module's override.
+func (l *Levels) Set(module, level string) error {
+	if module != DefaultModule && !knownModules[module] {
+		return fmt.Errorf("unknown log module %q (want %s or one of %s)", module, DefaultModule, strings.Join(moduleNames(), ", "))
+	}
+	if level == "" {
+		if module == DefaultModule {
+			return fmt.Errorf("the default log level cannot be removed")
+		}
+		l.mu.Lock()
+		delete(l.modules, module)
+		l.mu.Unlock()
+		return nil
+	}
+	lvl, err := parseLevel(level)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if module == DefaultModule {
+		l.def = lvl
+	} else {
+		l.modules[module] = lvl
+	}
+	return nil
+}
+
+// Snapshot returns the effective level of the default and every module.
+func (l *Levels) Snapshot() map[string]string {
+	out := map[string]string{DefaultModule: l.Level(DefaultModule).String()}
+	for _, m := range moduleNames() {
+		out[m] = l.Level(m).String()
+	}
+	return out
+}
+
+func parseLevel(s string) (zerolog.Level, error) {
+	lvl, err := zerolog.ParseLevel(strings.ToLower(s))
+	if err != nil || s == "" {
+		return zerolog.NoLevel, fmt.Errorf("invalid log level %q: want trace, debug, info, warn, error, fatal, panic or disabled", s)
+	}
+	return lvl, nil
+}
+
+func moduleNames() []string {
+	names := make([]string, 0, len(knownModules))
+	for m := range knownModules {
+		names = append(names, m)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// levelHook discards events below the current level of its module.
+type levelHook struct {
+	levels *Levels
+	module string
+}
+
+func (h levelHook) Run(e *zerolog.Event, level zerolog.Level, _ string) {
+	if level != zerolog.NoLevel && level < h.levels.Level(h.module) {
+		e.Discard()
+	}
+}
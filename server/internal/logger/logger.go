@@ -2,6 +2,9 @@
 package logger
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"os"
 
 	pkgerrors "github.com/pkg/errors"
@@ -12,6 +15,14 @@ import (
 // New returns a new zerolog.Logger configured for the application.
 // Call sites should use .Stack() on error events to include stacks.
 func New(serviceName string) zerolog.Logger {
+	configureErrors()
+	return zerolog.New(os.Stdout).With().
+		Str("service", serviceName).
+		Timestamp().
+		Logger()
+}
+
+func configureErrors() {
 	// Configure zerolog to work with github.com/pkg/errors:
 	// - Automatically marshal pkg/errors stack traces when present
 	// - Ensure a stack is present even for std errors when .Stack() is used
@@ -31,9 +42,107 @@ func New(serviceName string) zerolog.Logger {
 		// Otherwise, attach a stack so downstream logging can render it.
 		return pkgerrors.WithStack(err)
 	}
+}
 
-	return zerolog.New(os.Stdout).With().
+// Sink formats.
+const (
+	FormatJSON    = "json"
+	FormatConsole = "console"
+)
+
+// Sink is one log destination. An empty Path means stdout. File sinks are
+// rotated once they reach MaxSizeMB (0 disables rotation), keeping
+// MaxBackups old files.
+type Sink struct {
+	Path       string
+	Format     string
+	MaxSizeMB  int
+	MaxBackups int
+}
+
+// Config selects the sinks and levels of a Set. Level is the default level
+// and ModuleLevels the per-module overrides (see ParseLevels).
+type Config struct {
+	Level        string
+	ModuleLevels string
+	Sinks        []Sink
+}
+
+// Set is a process's log output: every sink plus the runtime-adjustable
+// levels. Loggers from Logger and Module write to all sinks.
+type Set struct {
+	out     zerolog.Logger
+	levels  *Levels
+	closers []io.Closer
+}
+
+// Open opens cfg's sinks. With no sinks it logs JSON to stdout.
+func Open(serviceName string, cfg Config) (*Set, error) {
+	configureErrors()
+	levels, err := ParseLevels(cfg.Level, cfg.ModuleLevels)
+	if err != nil {
+		return nil, err
+	}
+	sinks := cfg.Sinks
+	if len(sinks) == 0 {
+		sinks = []Sink{{Format: FormatJSON}}
+	}
+	s := &Set{levels: levels}
+	writers := make([]io.Writer, 0, len(sinks))
+	for _, sink := range sinks {
+		var w io.Writer = os.Stdout
+		if sink.Path != "" {
+			f, err := OpenRotatingFile(sink.Path, int64(sink.MaxSizeMB)<<20, sink.MaxBackups)
+			if err != nil {
+				_ = s.Close()
+				return nil, fmt.Errorf("open log file: %w", err)
+			}
+			s.closers = append(s.closers, f)
+			w = f
+		}
+		switch sink.Format {
+		case "", FormatJSON:
+		case FormatConsole:
+			w = zerolog.ConsoleWriter{Out: w, NoColor: sink.Path != ""}
+		default:
+			_ = s.Close()
+			return nil, fmt.Errorf("invalid log format %q: want json or console", sink.Format)
+		}
+		writers = append(writers, w)
+	}
+	var w io.Writer = writers[0]
+	if len(writers) > 1 {
+		w = zerolog.MultiLevelWriter(writers...)
+	}
+	// Levels are enforced by each logger's hook, so the base logger passes
+	// everything through.
+	s.out = zerolog.New(w).Level(zerolog.TraceLevel).With().
 		Str("service", serviceName).
 		Timestamp().
 		Logger()
+	return s, nil
+}
+
+// Logger returns a logger filtered at the default level.
+func (s *Set) Logger() zerolog.Logger {
+	return s.out.Hook(levelHook{levels: s.levels, module: DefaultModule})
+}
+
+// Module returns a logger tagged with module and filtered at its level.
+func (s *Set) Module(module string) zerolog.Logger {
+	return s.out.With().Str("module", module).Logger().Hook(levelHook{levels: s.levels, module: module})
+}
+
+// Levels returns the levels shared by the Set's loggers; changes apply
+// immediately.
+func (s *Set) Levels() *Levels { return s.levels }
+
+// Close closes the file sinks.
+func (s *Set) Close() error {
+	var errs []error
+	for _, c := range s.closers {
+		errs = append(errs, c.Close())
+	}
+	s.closers = nil
+	return errors.Join(errs...)
 }
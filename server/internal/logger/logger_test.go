@@ -5,8 +5,11 @@ import (
 	"errors"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/rs/zerolog"
 )
 
 // captureStdout runs f with os.Stdout redirected to a pipe and returns the output.
@@ -65,3 +68,87 @@ func TestLogger_IncludesStackAndServiceOnError(t *testing.T) {
 		t.Fatalf("expected stack field in error log: %s", line)
 	}
 }
+
+func TestSet_ModuleLevels(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.log")
+	set, err := Open("svc", Config{Level: "info", ModuleLevels: "store=debug", Sinks: []Sink{{Path: path}}})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	store, outbox := set.Module(ModuleStore), set.Module(ModuleOutbox)
+	store.Debug().Msg("store debug")
+	outbox.Debug().Msg("outbox debug")
+	if err := set.Levels().Set(ModuleOutbox, "debug"); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	outbox.Debug().Msg("outbox debug later")
+	if err := set.Levels().Set(ModuleStore, "warn"); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	store.Info().Msg("store info later")
+	def := set.Logger()
+	def.Info().Msg("default info")
+	_ = set.Close()
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	var got []string
+	for _, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+		var payload map[string]any
+		if err := json.Unmarshal([]byte(line), &payload); err != nil {
+			t.Fatalf("invalid json log: %v\n%s", err, line)
+		}
+		got = append(got, payload["message"].(string))
+	}
+	want := "store debug,outbox debug later,default info"
+	if strings.Join(got, ",") != want {
+		t.Fatalf("logged %v, want %s", got, want)
+	}
+}
+
+func TestParseLevels(t *testing.T) {
+	l, err := ParseLevels("warn", " store=debug , search=error")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	snap := l.Snapshot()
+	if snap[DefaultModule] != "warn" || snap[ModuleStore] != "debug" || snap[ModuleSearch] != "error" || snap[ModuleAPI] != "warn" {
+		t.Fatalf("unexpected levels %v", snap)
+	}
+	if err := l.Set(ModuleStore, ""); err != nil || l.Level(ModuleStore) != zerolog.WarnLevel {
+		t.Fatalf("reset store: %v, level %v", err, l.Level(ModuleStore))
+	}
+	for _, spec := range []string{"store", "cache=debug", "store=loud"} {
+		if _, err := ParseLevels("info", spec); err == nil {
+			t.Fatalf("expected error for %q", spec)
+		}
+	}
+	if _, err := ParseLevels("", ""); err == nil {
+		t.Fatalf("expected error for empty default level")
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.log")
+	f, err := OpenRotatingFile(path, 10, 2)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	for _, s := range []string{"aaaaaa\n", "bbbbbb\n", "cccccc\n", "dddddd\n"} {
+		if _, err := f.Write([]byte(s)); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	_ = f.Close()
+	for name, want := range map[string]string{path: "dddddd\n", path + ".1": "cccccc\n", path + ".2": "bbbbbb\n"} {
+		b, err := os.ReadFile(name)
+		if err != nil || string(b) != want {
+			t.Fatalf("%s = %q (%v), want %q", name, b, err, want)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Fatalf("expected only 2 backups, stat .3: %v", err)
+	}
+}
@@ -0,0 +1,350 @@
+// Package metrics tracks per-endpoint service level objectives. Each request
+// is counted against its endpoint's objective in one-minute buckets kept for
+// an hour, from which the burn rate of the error budget is computed over a
+// short (5 minute) and a long (1 hour) window. An endpoint is alerting while
+// both windows burn faster than the configured threshold, following the
+// multi-window burn-rate alerts of the Google SRE workbook.
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog"
+)
+
+// DefaultEndpoint keys the objective applied to endpoints without their own.
+const DefaultEndpoint = "*"
+
+const (
+	shortWindow = 5 * time.Minute
+	longWindow  = time.Hour
+	// latencyBudget is the fraction of requests a p99 objective lets run slow.
+	latencyBudget = 0.01
+	// minAlertRequests keeps a single failure on an idle endpoint from alerting.
+	minAlertRequests = 10
+)
+
+// Objective is the SLO of one endpoint ("METHOD /path/template"): 99% of
+// requests complete within LatencyP99 and at most ErrorRate of them fail
+// with a 5xx status.
+type Objective struct {
+	Endpoint   string
+	LatencyP99 time.Duration
+	ErrorRate  float64
+}
+
+// ParseObjectives reads "endpoint=p99,errorRate" entries separated by ";",
+// e.g. "*=1s,0.01;POST /v0/search=800ms,0.005". An empty spec has none.
+func ParseObjectives(spec string) (map[string]Objective, error) {
+	out := map[string]Objective{}
+	for _, item := range strings.Split(spec, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		endpoint, target, ok := strings.Cut(item, "=")
+		latency, rate, ok2 := strings.Cut(target, ",")
+		if !ok || !ok2 {
+			return nil, fmt.Errorf("SLO %q: want endpoint=p99,errorRate", item)
+		}
+		endpoint = strings.TrimSpace(endpoint)
+		d, err := time.ParseDuration(strings.TrimSpace(latency))
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("SLO %q: invalid p99 latency", item)
+		}
+		r, err := strconv.ParseFloat(strings.TrimSpace(rate), 64)
+		if err != nil || r <= 0 || r >= 1 {
+			return nil, fmt.Errorf("SLO %q: error rate must be between 0 and 1", item)
+		}
+		if _, dup := out[endpoint]; dup {
+			return nil, fmt.Errorf("SLO for %q defined twice", endpoint)
+		}
+		out[endpoint] = Objective{Endpoint: endpoint, LatencyP99: d, ErrorRate: r}
+	}
+	return out, nil
+}
+
+// latencyBounds are the upper bounds of the latency histogram buckets used to
+// estimate the observed p99.
+var latencyBounds = []time.Duration{
+	5 * time.Millisecond, 10 * time.Millisecond, 25 * time.Millisecond, 50 * time.Millisecond,
+	100 * time.Millisecond, 250 * time.Millisecond, 500 * time.Millisecond, time.Second,
+	2500 * time.Millisecond, 5 * time.Second, 10 * time.Second, 30 * time.Second,
+}
+
+type bucket struct {
+	minute int64
+	counts
+	hist [13]int64 // len(latencyBounds)+1; the last counts slower requests
+}
+
+type counts struct {
+	requests, errors, slow int64
+}
+
+// series holds an endpoint's last hour in one-minute buckets.
+type series struct {
+	obj     Objective
+	buckets [60]bucket
+}
+
+func (s *series) at(minute int64) *bucket {
+	b := &s.buckets[minute%int64(len(s.buckets))]
+	if b.minute != minute {
+		*b = bucket{minute: minute}
+	}
+	return b
+}
+
+// sum adds up the buckets of the window ending at minute now.
+func (s *series) sum(now int64, window time.Duration) (counts, [13]int64) {
+	var c counts
+	var hist [13]int64
+	n := int64(window / time.Minute)
+	for i := range s.buckets {
+		b := &s.buckets[i]
+		if b.minute <= now-n || b.minute > now {
+			continue
+		}
+		c.requests += b.requests
+		c.errors += b.errors
+		c.slow += b.slow
+		for j := range hist {
+			hist[j] += b.hist[j]
+		}
+	}
+	return c, hist
+}
+
+// WindowStats is an endpoint's traffic over one window. Burn rates are the
+// observed bad fraction divided by the objective's budget: 1 spends the
+// budget exactly over the SLO period, 14.4 spends 2% of a 30-day budget in
+// an hour.
+type WindowStats struct {
+	Window          string  `json:"window"`
+	Requests        int64   `json:"requests"`
+	Errors          int64   `json:"errors"`
+	Slow            int64   `json:"slow"`
+	ErrorBurnRate   float64 `json:"errorBurnRate"`
+	LatencyBurnRate float64 `json:"latencyBurnRate"`
+}
+
+// EndpointSLO reports one endpoint against its objective.
+type EndpointSLO struct {
+	Endpoint        string  `json:"endpoint"`
+	TargetP99Ms     int64   `json:"targetP99Ms"`
+	TargetErrorRate float64 `json:"targetErrorRate"`
+	// ObservedP99Ms is the p99 over the long window, see p99.
+	ObservedP99Ms int64         `json:"observedP99Ms"`
+	Windows       []WindowStats `json:"windows"`
+	Alerting      bool          `json:"alerting"`
+}
+
+// SLOTracker records request outcomes per endpoint and evaluates burn rates.
+type SLOTracker struct {
+	mu         sync.Mutex
+	objectives map[string]Objective
+	alertRate  float64
+	series     map[string]*series
+	alerting   map[string]bool
+	log        zerolog.Logger
+	now        func() time.Time
+}
+
+// NewSLOTracker tracks endpoints with an objective, or all endpoints when
+// objectives has a DefaultEndpoint entry. alertBurnRate is the burn rate both
+// windows must exceed for an endpoint to alert.
+func NewSLOTracker(objectives map[string]Objective, alertBurnRate float64, log zerolog.Logger) *SLOTracker {
+	return &SLOTracker{
+		objectives: objectives,
+		alertRate:  alertBurnRate,
+		series:     map[string]*series{},
+		alerting:   map[string]bool{},
+		log:        log,
+		now:        time.Now,
+	}
+}
+
+// Observe records one request to endpoint.
+func (t *SLOTracker) Observe(endpoint string, status int, latency time.Duration) {
+	obj, ok := t.objectives[endpoint]
+	if !ok {
+		if obj, ok = t.objectives[DefaultEndpoint]; !ok {
+			return
+		}
+		obj.Endpoint = endpoint
+	}
+	minute := t.now().Unix() / 60
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := t.series[endpoint]
+	if s == nil {
+		s = &series{obj: obj}
+		t.series[endpoint] = s
+	}
+	b := s.at(minute)
+	b.requests++
+	if status >= 500 {
+		b.errors++
+	}
+	if latency > obj.LatencyP99 {
+		b.slow++
+	}
+	i := sort.Search(len(latencyBounds), func(i int) bool { return latency <= latencyBounds[i] })
+	b.hist[i]++
+}
+
+// Report returns every endpoint seen so far, sorted by endpoint.
+func (t *SLOTracker) Report() []EndpointSLO {
+	minute := t.now().Unix() / 60
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make([]EndpointSLO, 0, len(t.series))
+	for _, s := range t.series {
+		out = append(out, t.evaluate(s, minute))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Endpoint < out[j].Endpoint })
+	return out
+}
+
+func (t *SLOTracker) evaluate(s *series, minute int64) EndpointSLO {
+	r := EndpointSLO{
+		Endpoint:        s.obj.Endpoint,
+		TargetP99Ms:     s.obj.LatencyP99.Milliseconds(),
+		TargetErrorRate: s.obj.ErrorRate,
+	}
+	errBurning, latBurning := true, true
+	for _, w := range []struct {
+		name string
+		d    time.Duration
+	}{{"5m", shortWindow}, {"1h", longWindow}} {
+		c, hist := s.sum(minute, w.d)
+		ws := WindowStats{Window: w.name, Requests: c.requests, Errors: c.errors, Slow: c.slow}
+		if c.requests > 0 {
+			ws.ErrorBurnRate = round(float64(c.errors) / float64(c.requests) / s.obj.ErrorRate)
+			ws.LatencyBurnRate = round(float64(c.slow) / float64(c.requests) / latencyBudget)
+		}
+		errBurning = errBurning && c.requests >= minAlertRequests && ws.ErrorBurnRate > t.alertRate
+		latBurning = latBurning && c.requests >= minAlertRequests && ws.LatencyBurnRate > t.alertRate
+		r.Windows = append(r.Windows, ws)
+		if w.d == longWindow {
+			r.ObservedP99Ms = p99(hist, c.requests)
+		}
+	}
+	r.Alerting = errBurning || latBurning
+	return r
+}
+
+// p99 returns the upper bound in milliseconds of the bucket holding the 99th
+// percentile, -1 when it is beyond the last bound and 0 without requests.
+func p99(hist [13]int64, total int64) int64 {
+	if total == 0 {
+		return 0
+	}
+	rank := int64(math.Ceil(0.99 * float64(total)))
+	var seen int64
+	for i, n := range hist {
+		seen += n
+		if seen >= rank {
+			if i == len(latencyBounds) {
+				return -1
+			}
+			return latencyBounds[i].Milliseconds()
+		}
+	}
+	return -1
+}
+
+func round(f float64) float64 { return math.Round(f*100) / 100 }
+
+// Check evaluates every endpoint and logs a warning when one starts alerting
+// and a notice when it recovers.
+func (t *SLOTracker) Check() {
+	for _, r := range t.Report() {
+		t.mu.Lock()
+		was := t.alerting[r.Endpoint]
+		t.alerting[r.Endpoint] = r.Alerting
+		t.mu.Unlock()
+		switch {
+		case r.Alerting && !was:
+			short, long := r.Windows[0], r.Windows[1]
+			t.log.Warn().
+				Str("endpoint", r.Endpoint).
+				Float64("error_burn_5m", short.ErrorBurnRate).
+				Float64("error_burn_1h", long.ErrorBurnRate).
+				Float64("latency_burn_5m", short.LatencyBurnRate).
+				Float64("latency_burn_1h", long.LatencyBurnRate).
+				Float64("threshold", t.alertRate).
+				Msg("SLO burn rate above threshold")
+		case !r.Alerting && was:
+			t.log.Info().Str("endpoint", r.Endpoint).Msg("SLO burn rate back below threshold")
+		}
+	}
+}
+
+// Start runs Check every interval until ctx is done.
+func (t *SLOTracker) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			t.Check()
+		}
+	}
+}
+
+// Middleware observes every request that matched a route, keyed by its
+// method and path template so IDs in the path do not split endpoints.
+func (t *SLOTracker) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := mux.CurrentRoute(r)
+		if route == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		tpl, err := route.GetPathTemplate()
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		start := t.now()
+		next.ServeHTTP(sw, r)
+		t.Observe(r.Method+" "+tpl, sw.status, t.now().Sub(start))
+	})
+}
+
+// statusWriter remembers the response status for Middleware.
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.status, w.wroteHeader = code, true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *statusWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
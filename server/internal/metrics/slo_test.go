@@ -0,0 +1,123 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog"
+)
+
+func TestParseObjectives(t *testing.T) {
+	objs, err := ParseObjectives(" *=1s,0.01 ; POST /v0/search=800ms,0.005;")
+	if err != nil {
+		t.Fatalf("ParseObjectives: %v", err)
+	}
+	if len(objs) != 2 || objs["POST /v0/search"].LatencyP99 != 800*time.Millisecond || objs["*"].ErrorRate != 0.01 {
+		t.Fatalf("unexpected objectives: %+v", objs)
+	}
+	if objs, err := ParseObjectives(""); err != nil || len(objs) != 0 {
+		t.Fatalf("empty spec: %v %v", objs, err)
+	}
+	for _, bad := range []string{"*=1s", "*=fast,0.01", "*=1s,1", "*=1s,0", "*=1s,0.1;*=2s,0.1"} {
+		if _, err := ParseObjectives(bad); err == nil {
+			t.Fatalf("%q: expected error", bad)
+		}
+	}
+}
+
+func TestSLOTrackerBurnRates(t *testing.T) {
+	var logs bytes.Buffer
+	objs, _ := ParseObjectives("*=100ms,0.01;GET /v0/health=1s,0.5")
+	tr := NewSLOTracker(objs, 14.4, zerolog.New(&logs))
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tr.now = func() time.Time { return now }
+
+	// An hour ago: 100 clean requests, outside the short window.
+	now = now.Add(-50 * time.Minute)
+	for i := 0; i < 100; i++ {
+		tr.Observe("POST /v0/search", http.StatusOK, 20*time.Millisecond)
+	}
+	now = now.Add(50 * time.Minute)
+	// Now: 20 requests, half of them failing, one slow.
+	for i := 0; i < 20; i++ {
+		status := http.StatusOK
+		if i%2 == 0 {
+			status = http.StatusServiceUnavailable
+		}
+		d := 20 * time.Millisecond
+		if i == 0 {
+			d = 3 * time.Second
+		}
+		tr.Observe("POST /v0/search", status, d)
+	}
+	tr.Observe("GET /v0/health", http.StatusInternalServerError, time.Millisecond)
+
+	rep := tr.Report()
+	if len(rep) != 2 || rep[0].Endpoint != "GET /v0/health" || rep[1].Endpoint != "POST /v0/search" {
+		t.Fatalf("unexpected report: %+v", rep)
+	}
+	if rep[0].Alerting {
+		t.Fatalf("a single request must not alert: %+v", rep[0])
+	}
+	search := rep[1]
+	short, long := search.Windows[0], search.Windows[1]
+	if short.Requests != 20 || short.Errors != 10 || short.Slow != 1 || short.ErrorBurnRate != 50 || short.LatencyBurnRate != 5 {
+		t.Fatalf("unexpected 5m window: %+v", short)
+	}
+	if long.Requests != 120 || long.Errors != 10 || long.ErrorBurnRate != 8.33 {
+		t.Fatalf("unexpected 1h window: %+v", long)
+	}
+	if search.Alerting || search.TargetP99Ms != 100 || search.ObservedP99Ms != 25 {
+		t.Fatalf("unexpected endpoint report: %+v", search)
+	}
+
+	// More errors push the long window over the threshold too.
+	for i := 0; i < 10; i++ {
+		tr.Observe("POST /v0/search", http.StatusInternalServerError, 20*time.Millisecond)
+	}
+	tr.Check()
+	if !strings.Contains(logs.String(), "SLO burn rate above threshold") || !strings.Contains(logs.String(), "POST /v0/search") {
+		t.Fatalf("expected a burn-rate warning, got %s", logs.String())
+	}
+
+	// Two hours later every bucket has aged out.
+	now = now.Add(2 * time.Hour)
+	tr.Check()
+	if !strings.Contains(logs.String(), "back below threshold") {
+		t.Fatalf("expected a recovery log, got %s", logs.String())
+	}
+	if got := tr.Report()[1]; got.Windows[1].Requests != 0 || got.Alerting {
+		t.Fatalf("expected empty windows, got %+v", got)
+	}
+}
+
+func TestSLOMiddlewareUsesRouteTemplate(t *testing.T) {
+	objs, _ := ParseObjectives("GET /v0/vaults/{vaultId}=1s,0.01")
+	tr := NewSLOTracker(objs, 14.4, zerolog.Nop())
+	r := mux.NewRouter()
+	r.Use(tr.Middleware)
+	r.HandleFunc("/v0/vaults/{vaultId}", func(w http.ResponseWriter, r *http.Request) {
+		if mux.Vars(r)["vaultId"] == "bad" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}).Methods("GET")
+	r.HandleFunc("/v0/other", func(w http.ResponseWriter, r *http.Request) {}).Methods("GET")
+
+	for _, path := range []string{"/v0/vaults/a", "/v0/vaults/b", "/v0/vaults/bad", "/v0/other"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	rep := tr.Report()
+	if len(rep) != 1 || rep[0].Endpoint != "GET /v0/vaults/{vaultId}" {
+		t.Fatalf("unexpected report: %+v", rep)
+	}
+	if w := rep[0].Windows[0]; w.Requests != 3 || w.Errors != 1 {
+		t.Fatalf("unexpected window: %+v", w)
+	}
+}
@@ -6,4 +6,12 @@ var (
 	ErrNotFound   = errors.New("not found")
 	ErrValidation = errors.New("validation error")
 	ErrConflict   = errors.New("conflict")
+	// ErrReadOnly is returned for writes to a vault marked read-only.
+	ErrReadOnly = errors.New("vault is read-only")
+	// ErrAppendOnly is returned for changes to existing entries of an
+	// append-only memory.
+	ErrAppendOnly = errors.New("memory is append-only")
+	// ErrPrecondition is returned when a conditional write's expected
+	// version is no longer current.
+	ErrPrecondition = errors.New("precondition failed")
 )
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Slug is the URL-friendly form of a vault or memory title: lower case, with
+// each run of characters other than letters and digits replaced by a single
+// hyphen, and apostrophes dropped. "Bob's Travel Notes" becomes
+// "bobs-travel-notes". Title-addressed routes accept either form.
+func Slug(title string) string {
+	var b strings.Builder
+	pendingHyphen := false
+	for _, r := range title {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r):
+			if pendingHyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			pendingHyphen = false
+			b.WriteRune(unicode.ToLower(r))
+		case r == '\'' || r == '’':
+		default:
+			pendingHyphen = true
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,18 @@
+package model
+
+import "testing"
+
+func TestSlug(t *testing.T) {
+	for title, want := range map[string]string{
+		"project-context":    "project-context",
+		"Bob's Travel Notes": "bobs-travel-notes",
+		"  Q3 -- plans ":     "q3-plans",
+		"Заметки о поездке":  "заметки-о-поездке",
+		"snake_case.v2":      "snake-case-v2",
+		"--":                 "",
+	} {
+		if got := Slug(title); got != want {
+			t.Errorf("Slug(%q) = %q, want %q", title, got, want)
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package model
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // User represents an account in the system.
 type User struct {
@@ -13,12 +16,168 @@ type User struct {
 	LastActiveTime *time.Time `json:"lastActiveTime,omitempty"`
 }
 
+// DefaultTimeZone applies to actors that have not set one.
+const DefaultTimeZone = "UTC"
+
+// ActorSettings are per-actor preferences. TimeZone is an IANA zone name used
+// to render timestamps and resolve date-only and relative time filters.
+type ActorSettings struct {
+	ActorID  string `json:"actorId"`
+	TimeZone string `json:"timeZone"`
+	// DefaultVaultID and DefaultMemoryID are used by the routes that omit
+	// them (/v0/entries, /v0/contexts, search without memoryId).
+	DefaultVaultID  string    `json:"defaultVaultId,omitempty"`
+	DefaultMemoryID string    `json:"defaultMemoryId,omitempty"`
+	UpdateTime      time.Time `json:"updateTime"`
+}
+
 // Vault groups memories under an actor.
 type Vault struct {
 	VaultID      string    `json:"vaultId"`
 	ActorID      string    `json:"actorId"`
 	Title        string    `json:"title"`
+	Description  *string   `json:"description,omitempty"`
 	CreationTime time.Time `json:"creationTime"`
+	// ReadOnly rejects writes to the vault and everything in it.
+	ReadOnly bool `json:"readOnly"`
+	// Slug is Slug(Title), unique among the actor's vaults.
+	Slug string `json:"slug,omitempty"`
+}
+
+// TitleUpdate renames a vault or memory and/or changes its description. Nil
+// fields are left unchanged; an empty Description clears it.
+type TitleUpdate struct {
+	Title       *string `json:"title,omitempty"`
+	Description *string `json:"description,omitempty"`
+}
+
+// VaultTemplate is a named set of memories, with optional starting
+// contexts, that POST /v0/vaults:fromTemplate creates in a new vault.
+type VaultTemplate struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Memories    []TemplateMemory `json:"memories"`
+}
+
+// TemplateMemory is one memory of a VaultTemplate.
+type TemplateMemory struct {
+	Title       string `json:"title"`
+	MemoryType  string `json:"memoryType"`
+	Description string `json:"description,omitempty"`
+	// Context becomes the memory's first context snapshot; empty stores none.
+	Context string `json:"context,omitempty"`
+}
+
+// TemplatedVault is a vault created from a template, with its memories.
+type TemplatedVault struct {
+	Vault
+	Template string    `json:"template"`
+	Memories []*Memory `json:"memories"`
+}
+
+// VaultClone asks for a deep copy of SourceVaultID titled Title. Reindex
+// enqueues search index upserts of the copies; without it they are stored
+// but not searchable until reindexed.
+type VaultClone struct {
+	ActorID       string
+	SourceVaultID string
+	Title         string
+	Reindex       bool
+}
+
+// ClonedVault is a vault created by a clone, with its memories.
+type ClonedVault struct {
+	Vault
+	SourceVaultID string          `json:"sourceVaultId"`
+	Memories      []*ClonedMemory `json:"memories"`
+	EntryCount    int             `json:"entryCount"`
+	Reindex       bool            `json:"reindex"`
+}
+
+// ClonedMemory is the copy of SourceMemoryID. ReindexJobID is set when the
+// clone enqueued index upserts; follow it with GET /v0/admin/memories/{memoryId}/reindex.
+type ClonedMemory struct {
+	Memory
+	SourceMemoryID string `json:"sourceMemoryId"`
+	EntryCount     int    `json:"entryCount"`
+	ContextCopied  bool   `json:"contextCopied"`
+	ReindexJobID   string `json:"reindexJobId,omitempty"`
+}
+
+// Bootstrap asks for a new vault holding one memory whose first context is
+// Context, or the default context when empty. A non-empty ClientToken makes
+// the request idempotent for the actor: repeating it returns the first
+// result instead of creating anything.
+type Bootstrap struct {
+	ActorID     string
+	ClientToken string
+	Vault       Vault
+	Memory      Memory
+	Context     string
+}
+
+// BootstrapResult is what a bootstrap created. Replayed is set when the
+// client token had already been used with the same request.
+type BootstrapResult struct {
+	Vault     *Vault  `json:"vault"`
+	Memory    *Memory `json:"memory"`
+	ContextID string  `json:"contextId"`
+	Replayed  bool    `json:"replayed"`
+}
+
+// ObjectCounts counts a memory's objects in one store.
+type ObjectCounts struct {
+	Entries  int64 `json:"entries"`
+	Contexts int64 `json:"contexts"`
+}
+
+// IndexingCounts counts a memory's entries and contexts that are not
+// searchable yet, by the state of their latest outbox record. Retrying is
+// the part of Pending that has failed at least once.
+type IndexingCounts struct {
+	Pending  int64 `json:"pending"`
+	Retrying int64 `json:"retrying"`
+	Failed   int64 `json:"failed"`
+}
+
+// MemoryStats compares a memory's Postgres rows with its search index
+// objects. Index is nil when the index cannot report counts.
+type MemoryStats struct {
+	MemoryID string         `json:"memoryId"`
+	Title    string         `json:"title"`
+	Postgres ObjectCounts   `json:"postgres"`
+	Index    *ObjectCounts  `json:"index,omitempty"`
+	Indexing IndexingCounts `json:"indexing"`
+}
+
+// InSync reports whether the index holds exactly the rows Postgres holds.
+func (m MemoryStats) InSync() bool {
+	return m.Index != nil && *m.Index == m.Postgres
+}
+
+// IndexFreshness compares the creation time of a memory's newest entry with
+// that of the newest entry the search index holds. Entries written since
+// are still in the outbox and missing from search results; LagSeconds is
+// how far the index trails, and Stale is set when it trails at all.
+// NewestIndexedTime is omitted, and LagSeconds 0, when the index holds no
+// entry of the memory.
+type IndexFreshness struct {
+	NewestEntryTime   *time.Time `json:"newestEntryTime,omitempty"`
+	NewestIndexedTime *time.Time `json:"newestIndexedTime,omitempty"`
+	LagSeconds        float64    `json:"lagSeconds"`
+	Stale             bool       `json:"stale"`
+}
+
+// VaultStats aggregates MemoryStats over a vault. Writes still waiting in the
+// outbox show up as a temporary gap.
+type VaultStats struct {
+	VaultID  string        `json:"vaultId"`
+	Memories []MemoryStats `json:"memories"`
+	Postgres ObjectCounts  `json:"postgres"`
+	Index    *ObjectCounts `json:"index,omitempty"`
+	InSync   bool          `json:"inSync"`
+	// Indexing totals the memories' IndexingCounts.
+	Indexing IndexingCounts `json:"indexing"`
 }
 
 // Memory is a container for entries and contexts.
@@ -30,20 +189,194 @@ type Memory struct {
 	Title        string    `json:"title"`
 	Description  *string   `json:"description,omitempty"`
 	CreationTime time.Time `json:"creationTime"`
+	// AppendOnly memories accept new entries but reject tag updates and
+	// deletes of existing ones; retention still expires them.
+	AppendOnly bool `json:"appendOnly"`
+	// EntryRoles, when set, requires every new entry to name one of these
+	// roles in metadata.role.
+	EntryRoles []string `json:"entryRoles,omitempty"`
+	// Slug is Slug(Title), unique within the vault.
+	Slug string `json:"slug,omitempty"`
+	// SearchBoost, when set, weighs the memory's hits in vault-scope searches.
+	SearchBoost *SearchBoost `json:"searchBoost,omitempty"`
+	// EntryTTLSeconds, when positive, expires new entries written without an
+	// expirationTime this many seconds after they are created.
+	EntryTTLSeconds int `json:"entryTtlSeconds,omitempty"`
+	// DeletionTime is when the memory was moved to the trash; set only in
+	// trash listings.
+	DeletionTime *time.Time `json:"deletionTime,omitempty"`
+}
+
+// Entry fields a SearchBoost can weigh.
+const (
+	SearchFieldSummary  = "summary"
+	SearchFieldRawEntry = "rawEntry"
+	SearchFieldTags     = "tags"
+)
+
+// SearchBoost tunes how a memory's entries rank against other memories' in
+// vault-scope searches, so a small curated memory can outrank a verbose
+// chat log.
+type SearchBoost struct {
+	// Boost multiplies the scores of the memory's hits; 0 means 1.
+	Boost float64 `json:"boost,omitempty"`
+	// FieldWeights weigh the keyword half of hybrid search per entry field
+	// (SearchField*); a field left out or at 0 is not keyword-searched.
+	// Empty searches summary and rawEntry at weight 1.
+	FieldWeights map[string]float64 `json:"fieldWeights,omitempty"`
 }
 
 // MemoryEntry is an immutable record of content with optional summary and metadata.
 type MemoryEntry struct {
-	EntryID        string                 `json:"entryId"`
-	ActorID        string                 `json:"actorId"`
-	VaultID        string                 `json:"vaultId"`
-	MemoryID       string                 `json:"memoryId"`
-	RawEntry       string                 `json:"rawEntry"`
-	Summary        *string                `json:"summary,omitempty"`
-	Metadata       map[string]interface{} `json:"metadata,omitempty"`
-	Tags           map[string]interface{} `json:"tags,omitempty"`
-	CreationTime   time.Time              `json:"creationTime"`
-	ExpirationTime *time.Time             `json:"expirationTime,omitempty"`
+	EntryID      string                 `json:"entryId"`
+	ActorID      string                 `json:"actorId"`
+	VaultID      string                 `json:"vaultId"`
+	MemoryID     string                 `json:"memoryId"`
+	RawEntry     string                 `json:"rawEntry"`
+	Summary      *string                `json:"summary,omitempty"`
+	Metadata     map[string]interface{} `json:"metadata,omitempty"`
+	Tags         map[string]interface{} `json:"tags,omitempty"`
+	CreationTime time.Time              `json:"creationTime"`
+	// ExpirationTime, when set, hides the entry from reads and searches once
+	// passed; the retention reaper then deletes it.
+	ExpirationTime *time.Time `json:"expirationTime,omitempty"`
+	// LastAccessedTime is when the entry was last returned by get or search; nil if never read.
+	LastAccessedTime *time.Time `json:"lastAccessedTime,omitempty"`
+	// Provenance: where the entry came from and which ingestion batch wrote it.
+	SourceSystem     string `json:"sourceSystem,omitempty"`
+	SourceID         string `json:"sourceId,omitempty"`
+	IngestionBatchID string `json:"ingestionBatchId,omitempty"`
+	// SessionID groups the entries of one conversation session; optional.
+	SessionID string `json:"sessionId,omitempty"`
+	// Quality signals recorded by agents via POST .../entries/{entryId}/signals.
+	EntrySignals
+	// Usage is the language model spend the client reported for the entry.
+	Usage *EntryUsage `json:"usage,omitempty"`
+	// ConversationTime is when the conversation behind the entry took place,
+	// for history ingested after the fact; nil when it is the creation time.
+	ConversationTime *time.Time `json:"conversationTime,omitempty"`
+	// CorrectionTime is when the entry was corrected by a later entry, which
+	// search then returns in its place; CorrectionReason says why.
+	CorrectionTime   *time.Time `json:"correctionTime,omitempty"`
+	CorrectionReason string     `json:"correctionReason,omitempty"`
+	// IdempotencyKey, chosen by the client, makes a repeated create of the
+	// same entry in the memory return the first one instead of a copy.
+	IdempotencyKey string `json:"idempotencyKey,omitempty"`
+	// IndexStatus says whether the entry is searchable yet; set by GET entry.
+	IndexStatus *IndexStatus `json:"indexStatus,omitempty"`
+	// DeletionTime is when the entry was moved to the trash; set only in
+	// trash listings.
+	DeletionTime *time.Time `json:"deletionTime,omitempty"`
+}
+
+// Trash lists a vault's soft-deleted memories and entries, most recently
+// deleted first. Entries of a trashed memory are restored with it and are
+// not listed on their own.
+type Trash struct {
+	Memories []*Memory      `json:"memories"`
+	Entries  []*MemoryEntry `json:"entries"`
+}
+
+// EntrySummary is the short form of an entry returned next to a context:
+// its summary, or the start of its raw text when it has none.
+type EntrySummary struct {
+	EntryID      string    `json:"entryId"`
+	Summary      string    `json:"summary"`
+	CreationTime time.Time `json:"creationTime"`
+	SessionID    string    `json:"sessionId,omitempty"`
+}
+
+// Index states of an entry or context, read from its latest upsert outbox
+// record.
+const (
+	IndexStatePending = "pending" // waiting for the outbox worker, possibly retrying
+	IndexStateIndexed = "indexed"
+	IndexStateFailed  = "failed"  // dead-lettered after too many attempts
+	IndexStateUnknown = "unknown" // no outbox record, e.g. written before the outbox existed
+)
+
+// IndexStatus is the search indexing state of one entry or context.
+type IndexStatus struct {
+	State string `json:"state"`
+	// Attempts counts failed index writes; LastError is the latest failure.
+	Attempts   int        `json:"attempts"`
+	LastError  string     `json:"lastError,omitempty"`
+	UpdateTime *time.Time `json:"updateTime,omitempty"`
+}
+
+// EntryUsage is the language model usage a client reports for generating an
+// entry's summary and the context update made with it.
+type EntryUsage struct {
+	Model        string  `json:"model,omitempty"`
+	InputTokens  int64   `json:"inputTokens,omitempty"`
+	OutputTokens int64   `json:"outputTokens,omitempty"`
+	CostUSD      float64 `json:"costUsd,omitempty"`
+}
+
+// UsageTotals sums the EntryUsage of Entries entries. MemoryID and Model name
+// the group; they are empty in totals across groups.
+type UsageTotals struct {
+	MemoryID     string  `json:"memoryId,omitempty"`
+	Model        string  `json:"model,omitempty"`
+	Entries      int64   `json:"entries"`
+	InputTokens  int64   `json:"inputTokens"`
+	OutputTokens int64   `json:"outputTokens"`
+	CostUSD      float64 `json:"costUsd"`
+}
+
+// UsageReport attributes the usage reported with an actor's entries to
+// memories and models.
+type UsageReport struct {
+	Total    UsageTotals   `json:"total"`
+	ByMemory []UsageTotals `json:"byMemory"`
+	ByModel  []UsageTotals `json:"byModel"`
+}
+
+// EntryEmbedding is an entry's stored vector in a vector-store-neutral form.
+type EntryEmbedding struct {
+	Model     string    `json:"model"`
+	Dimension int       `json:"dimension"`
+	Vector    []float32 `json:"vector"`
+}
+
+// ExportedEntry is one line of a memory's JSONL export. Embedding is set
+// only when requested and the index holds a vector for the entry.
+type ExportedEntry struct {
+	*MemoryEntry
+	Embedding *EntryEmbedding `json:"embedding,omitempty"`
+}
+
+// Entry quality signals an agent can record against an entry.
+const (
+	SignalUseful    = "useful"
+	SignalIncorrect = "incorrect"
+	SignalOutdated  = "outdated"
+)
+
+// EntrySignals counts how often agents marked an entry useful, incorrect or outdated.
+type EntrySignals struct {
+	UsefulCount    int `json:"usefulCount,omitempty"`
+	IncorrectCount int `json:"incorrectCount,omitempty"`
+	OutdatedCount  int `json:"outdatedCount,omitempty"`
+}
+
+// Ingestion batch statuses.
+const (
+	IngestionBatchOpen       = "open"
+	IngestionBatchRolledBack = "rolled_back"
+)
+
+// IngestionBatch groups entries written by one import run so the whole run
+// can be listed or rolled back together.
+type IngestionBatch struct {
+	BatchID        string     `json:"batchId"`
+	ActorID        string     `json:"actorId"`
+	SourceSystem   string     `json:"sourceSystem"`
+	Description    *string    `json:"description,omitempty"`
+	Status         string     `json:"status"`
+	EntryCount     int        `json:"entryCount"`
+	CreationTime   time.Time  `json:"creationTime"`
+	RolledBackTime *time.Time `json:"rolledBackTime,omitempty"`
 }
 
 // MemoryContext stores the latest context snapshot for a memory.
@@ -54,6 +387,139 @@ type MemoryContext struct {
 	MemoryID     string    `json:"memoryId"`
 	Context      string    `json:"context"`
 	CreationTime time.Time `json:"creationTime"`
+	// Sections is set for structured contexts; Context is then their
+	// rendering (see services.RenderContextSections).
+	Sections []ContextSection `json:"sections,omitempty"`
+}
+
+// ContextCheck lists recent entries a submitted context may have left out,
+// so the agent can fold them back in before its next rewrite.
+type ContextCheck struct {
+	EntriesChecked int                 `json:"entriesChecked"`
+	Suggestions    []ContextSuggestion `json:"suggestions"`
+	Warnings       []string            `json:"warnings,omitempty"`
+}
+
+// ContextSuggestion is an entry the checked context does not reflect.
+// Coverage is the share of the entry's keyword terms found in the context.
+type ContextSuggestion struct {
+	EntryID      string    `json:"entryId"`
+	Text         string    `json:"text"`
+	CreationTime time.Time `json:"creationTime"`
+	Coverage     float64   `json:"coverage"`
+	MissingTerms []string  `json:"missingTerms"`
+	// Reason is ContextSuggestionNew or ContextSuggestionDropped.
+	Reason string `json:"reason"`
+}
+
+// Context suggestion reasons.
+const (
+	// ContextSuggestionNew marks an entry written since the previous context.
+	ContextSuggestionNew = "new"
+	// ContextSuggestionDropped marks an entry the previous context reflected.
+	ContextSuggestionDropped = "dropped"
+)
+
+// ContextSection is one named part of a structured context with the
+// provenance of its last change.
+type ContextSection struct {
+	Name          string    `json:"name"`
+	Content       string    `json:"content"`
+	LastUpdatedBy string    `json:"lastUpdatedBy"`
+	LastUpdatedAt time.Time `json:"lastUpdatedAt"`
+}
+
+// Context document states.
+const (
+	ContextDocumentUploading = "uploading"
+	ContextDocumentComplete  = "complete"
+)
+
+// ContextDocument is the full version of a context too large to be the
+// active context. It is uploaded gzip-compressed in numbered parts; SizeBytes
+// and SHA256 describe the decompressed text and are set on completion.
+type ContextDocument struct {
+	DocumentID     string     `json:"documentId"`
+	ActorID        string     `json:"actorId"`
+	VaultID        string     `json:"vaultId"`
+	MemoryID       string     `json:"memoryId"`
+	Status         string     `json:"status"`
+	SizeBytes      int64      `json:"sizeBytes,omitempty"`
+	SHA256         string     `json:"sha256,omitempty"`
+	CreationTime   time.Time  `json:"creationTime"`
+	CompletionTime *time.Time `json:"completionTime,omitempty"`
+}
+
+// ContextDocumentPart is one numbered slice of a document's gzip stream.
+type ContextDocumentPart struct {
+	Number int
+	Data   []byte
+}
+
+// ContextSnapshot identifies one stored context version without its text.
+type ContextSnapshot struct {
+	ContextID    string
+	CreationTime time.Time
+}
+
+// EntityAlias maps another name of an entity to its canonical name within one
+// memory, e.g. "Bob" to "Robert Smith". Aliases are matched case-insensitively.
+type EntityAlias struct {
+	ActorID      string    `json:"actorId"`
+	VaultID      string    `json:"vaultId"`
+	MemoryID     string    `json:"memoryId"`
+	Alias        string    `json:"alias"`
+	Canonical    string    `json:"canonical"`
+	CreationTime time.Time `json:"creationTime"`
+}
+
+// Inbound webhook kinds. A generic webhook checks the X-Mycelian-Signature
+// header; a slack webhook checks Slack's request signature and answers its
+// url_verification challenge.
+const (
+	WebhookGeneric = "generic"
+	WebhookSlack   = "slack"
+)
+
+// WebhookMapping turns an inbound JSON payload into entries. Every field is
+// a template in which {{path}} is replaced by the payload value at that
+// dotted path ("event.user", "messages.0.text"); objects and arrays render
+// as JSON and missing values as nothing. When Items names an array, each of
+// its elements becomes one entry and paths resolve against the element,
+// with a "$." prefix reaching the whole payload. ConversationTime must
+// render as RFC 3339 or unix seconds.
+type WebhookMapping struct {
+	Items            string            `json:"items,omitempty"`
+	RawEntry         string            `json:"rawEntry"`
+	Summary          string            `json:"summary,omitempty"`
+	SessionID        string            `json:"sessionId,omitempty"`
+	SourceID         string            `json:"sourceId,omitempty"`
+	ConversationTime string            `json:"conversationTime,omitempty"`
+	Tags             map[string]string `json:"tags,omitempty"`
+}
+
+// Webhook is an inbound integration that writes the payloads POSTed to
+// /v0/hooks/{webhookId} as entries of one memory. Secret verifies the
+// payload signatures; it is returned only when the webhook is created.
+type Webhook struct {
+	WebhookID        string         `json:"webhookId"`
+	ActorID          string         `json:"actorId"`
+	VaultID          string         `json:"vaultId"`
+	MemoryID         string         `json:"memoryId"`
+	Name             string         `json:"name"`
+	Kind             string         `json:"kind"`
+	Secret           string         `json:"secret,omitempty"`
+	Mapping          WebhookMapping `json:"mapping"`
+	CreationTime     time.Time      `json:"creationTime"`
+	LastDeliveryTime *time.Time     `json:"lastDeliveryTime,omitempty"`
+	DeliveryCount    int64          `json:"deliveryCount"`
+}
+
+// MemoryRef is the full key of a memory.
+type MemoryRef struct {
+	ActorID  string
+	VaultID  string
+	MemoryID string
 }
 
 // SearchHit represents a search result from the index.
@@ -64,14 +530,356 @@ type SearchHit struct {
 	Summary  string  `json:"summary"`
 	RawEntry string  `json:"rawEntry"`
 	Score    float64 `json:"score"`
+	// CreationTime of the entry when the index stores it; recency ranking needs it.
+	CreationTime *time.Time `json:"creationTime,omitempty"`
+	// SessionID of the entry's conversation session, when it has one.
+	SessionID string `json:"sessionId,omitempty"`
+	// SessionHits is set under groupBy=session: the number of hits of the
+	// session this best-scoring hit stands for, itself included.
+	SessionHits int `json:"sessionHits,omitempty"`
+	// RerankScore is the reranker's relevance score, set when reranking is
+	// configured; Score then starts from it instead of the index's score.
+	RerankScore *float64 `json:"rerankScore,omitempty"`
+	// Corrects is set when the hit is the newest correction of the matched
+	// entry, standing in for it with its score.
+	Corrects string `json:"corrects,omitempty"`
+	// SupersededBy is set, under includeSuperseded, on a hit whose entry was
+	// corrected: the newest entry of its correction chain.
+	SupersededBy string `json:"supersededBy,omitempty"`
+	// Set only when signal ranking is enabled; Score then includes the boost/demotion.
+	EntrySignals
+}
+
+// Scopes of a similar entries lookup.
+const (
+	SimilarScopeMemory = "memory" // the entry's own memory (default)
+	SimilarScopeVault  = "vault"  // every memory of the entry's vault
+)
+
+// SimilarEntries lists the entries nearest to one entry's embedding, most
+// similar first; the entry itself is never listed.
+type SimilarEntries struct {
+	EntryID  string `json:"entryId"`
+	MemoryID string `json:"memoryId"`
+	Scope    string `json:"scope"`
+	TopK     int    `json:"topK"`
+	// VectorSource is "stored" when the entry's indexed vector was reused and
+	// "embedded" when its text was embedded for the lookup.
+	VectorSource string `json:"vectorSource"`
+	// Scoring is "cosine" when scores are cosine similarities of the stored
+	// vectors, "index" when they are the index's own relevance scores.
+	Scoring string      `json:"scoring"`
+	Entries []SearchHit `json:"entries"`
+}
+
+// Search result orderings a request can choose with rankBy.
+const (
+	RankByRelevance = "relevance" // index score only (default)
+	RankByRecency   = "recency"   // score decayed by entry age
+	RankByHybrid    = "hybrid"    // half the score decays with age, half is kept
+)
+
+// GroupBySession collapses search hits of one session into its best hit.
+const GroupBySession = "session"
+
+// RankingProfile is a named set of search ranking settings an operator
+// defines in SEARCH_PROFILES_FILE and a search selects with "profile". Unset
+// fields keep the server's settings.
+type RankingProfile struct {
+	Name string `json:"name"`
+	// Alpha weighs vector against keyword relevance in [0, 1].
+	Alpha *float32 `json:"alpha,omitempty"`
+	// Rerank turns the reranker and signal re-ranking on or off;
+	// SignalWeight replaces the server's weight when it is on.
+	Rerank       *bool   `json:"rerank,omitempty"`
+	SignalWeight float64 `json:"signalWeight,omitempty"`
+	// RankBy is the ordering of searches that do not set rankBy.
+	RankBy string `json:"rankBy,omitempty"`
+	// RecencyHalfLifeHours replaces the recency decay half-life.
+	RecencyHalfLifeHours float64 `json:"recencyHalfLifeHours,omitempty"`
+	// Diversity in [0, 1] trades relevance for results that differ from the
+	// ones ranked above them; 0 ranks by relevance alone.
+	Diversity float64 `json:"diversity,omitempty"`
+}
+
+// SearchFilter narrows a search. The zero value matches every entry of the memory.
+type SearchFilter struct {
+	SessionID string // only entries of this session when set
+	// Tags, when set, keeps entries whose tags hold every key with exactly
+	// its value; booleans and numbers match their text, e.g. "true".
+	Tags    map[string]string
+	MustNot SearchMustNot
+	// Since and Until bound entry creation time to [Since, Until) when set.
+	Since *time.Time
+	Until *time.Time
+	// FieldWeights, when set, weigh the keyword half of hybrid search per
+	// entry field as SearchBoost.FieldWeights does.
+	FieldWeights map[string]float64
+}
+
+// SearchMustNot excludes results from a search. An entry is dropped when it
+// carries any listed tag, lives in a listed memory, or is a listed entry.
+// The zero value excludes nothing.
+type SearchMustNot struct {
+	Tags      []string `json:"tags,omitempty"`
+	MemoryIDs []string `json:"memoryIds,omitempty"`
+	EntryIDs  []string `json:"entryIds,omitempty"`
+}
+
+// Excludes reports whether hit is ruled out by the memory or entry lists.
+// Tags are not on hits, so tag exclusion is left to the index.
+func (m SearchMustNot) Excludes(hit SearchHit) bool {
+	for _, id := range m.MemoryIDs {
+		if hit.MemoryID == id {
+			return true
+		}
+	}
+	for _, id := range m.EntryIDs {
+		if hit.EntryID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// SearchQuery is a logged search request with the entry IDs it returned, in rank order.
+type SearchQuery struct {
+	QueryID  string   `json:"queryId"`
+	ActorID  string   `json:"actorId"`
+	MemoryID string   `json:"memoryId"`
+	Query    string   `json:"query"`
+	TopK     int      `json:"topK"`
+	Alpha    float32  `json:"alpha"`
+	EntryIDs []string `json:"entryIds"`
+	// Scores are the returned entries' scores, parallel to EntryIDs; nil
+	// for queries logged before scores were recorded.
+	Scores         []float64  `json:"scores,omitempty"`
+	UsefulEntryIDs []string   `json:"usefulEntryIds,omitempty"`
+	FeedbackTime   *time.Time `json:"feedbackTime,omitempty"`
+	CreationTime   time.Time  `json:"creationTime"`
+}
+
+// SearchPrecision aggregates relevance feedback over logged queries.
+// Precision is useful/returned across judged queries (micro average);
+// MeanPrecision averages per-query precision (macro average).
+type SearchPrecision struct {
+	Queries         int     `json:"queries"`
+	JudgedQueries   int     `json:"judgedQueries"`
+	ReturnedResults int     `json:"returnedResults"`
+	UsefulResults   int     `json:"usefulResults"`
+	Precision       float64 `json:"precision"`
+	MeanPrecision   float64 `json:"meanPrecision"`
 }
 
 // ListEntriesRequest captures filters used when listing entries.
 type ListEntriesRequest struct {
+	ActorID   string
+	VaultID   string
+	MemoryID  string
+	SessionID string // only entries of this session when set
+	// Tags, when set, keeps entries whose tags hold every key with exactly
+	// its value, as SearchFilter.Tags does.
+	Tags      map[string]string
+	Limit     int
+	Before    *time.Time
+	After     *time.Time
+	Ascending bool // oldest first (conversation order) instead of newest first
+	// OrderBy is EntryOrderCreationTime (default) or EntryOrderConversationTime;
+	// Before and After bound the same time.
+	OrderBy string
+	// Cursor resumes the creation time order after this entry, for paging
+	// past Limit; it cannot be combined with EntryOrderConversationTime.
+	Cursor *EntryCursor
+}
+
+// EntryTagPatch updates the tags of the memory's entries selected by
+// EntryIDs, or else by SessionID and the [Since, Until) creation time range;
+// at least one selector is required. Keys in Set are written over existing
+// values and keys in Unset removed.
+type EntryTagPatch struct {
+	ActorID   string
+	VaultID   string
+	MemoryID  string
+	EntryIDs  []string
+	SessionID string
+	Since     *time.Time
+	Until     *time.Time
+	Set       map[string]interface{}
+	Unset     []string
+}
+
+// MaxTagPatchEntries caps how many entries one EntryTagPatch may update.
+const MaxTagPatchEntries = 5000
+
+// MaxEntriesBatch caps how many entries one batch create may write.
+const MaxEntriesBatch = 500
+
+// Entry list orders.
+const (
+	EntryOrderCreationTime = "creationTime"
+	// EntryOrderConversationTime orders by ConversationTime, falling back to
+	// CreationTime for entries without one.
+	EntryOrderConversationTime = "conversationTime"
+)
+
+// ScanEntriesRequest selects a memory's entries by exact text match in the
+// database, without the search index. Contains is a case-insensitive
+// substring and Regex a POSIX regular expression; each given filter must
+// match the raw entry or the summary. Results are newest first, resuming
+// after the After cursor when set.
+type ScanEntriesRequest struct {
 	ActorID  string
 	VaultID  string
 	MemoryID string
+	Contains string
+	Regex    string
 	Limit    int
-	Before   *time.Time
-	After    *time.Time
+	After    *EntryCursor
+}
+
+// EntryCursor is the position of an entry in creation time order.
+type EntryCursor struct {
+	CreationTime time.Time
+	EntryID      string
+}
+
+// EntrySession summarises the entries of one session in a memory.
+type EntrySession struct {
+	SessionID      string    `json:"sessionId"`
+	EntryCount     int       `json:"entryCount"`
+	FirstEntryTime time.Time `json:"firstEntryTime"`
+	LastEntryTime  time.Time `json:"lastEntryTime"`
+}
+
+// ConversationMessage is one chat message of a transcript posted to
+// .../memories/{memoryId}/conversations.
+type ConversationMessage struct {
+	Role      string     `json:"role"`
+	Content   string     `json:"content"`
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+}
+
+// Reindex job states.
+const (
+	ReindexRunning   = "running"
+	ReindexCompleted = "completed"
+)
+
+// Re-embedding states of a memory marked after the embedding configuration changed.
+const (
+	ReembedQueued    = "queued"    // no entry enqueued yet
+	ReembedRunning   = "running"   // enqueued gradually, or records still pending
+	ReembedCompleted = "completed" // every record enqueued and applied or dead-lettered
+)
+
+// ReembedProgress tracks the gradual re-embedding of one memory. Enqueued
+// counts the entries written to the outbox so far, out of EntryCount; the
+// memory's contexts are enqueued with the first page. Done, Pending and
+// Failed count both like ReindexJob does.
+type ReembedProgress struct {
+	JobID        string     `json:"jobId"`
+	ActorID      string     `json:"actorId"`
+	VaultID      string     `json:"vaultId"`
+	MemoryID     string     `json:"memoryId"`
+	Fingerprint  string     `json:"fingerprint"`
+	EntryCount   int        `json:"entryCount"`
+	ContextCount int        `json:"contextCount"`
+	Enqueued     int        `json:"enqueued"`
+	Done         int        `json:"done"`
+	Pending      int        `json:"pending"`
+	Failed       int        `json:"failed"`
+	Status       string     `json:"status"`
+	CreationTime time.Time  `json:"creationTime"`
+	StartTime    *time.Time `json:"startTime,omitempty"`
+	EnqueuedTime *time.Time `json:"enqueuedTime,omitempty"`
+}
+
+// ReembedOverview summarises re-embedding across all memories.
+type ReembedOverview struct {
+	// Fingerprint is the embedding configuration recorded as current.
+	Fingerprint string `json:"fingerprint"`
+	// Memories counts the memories marked for Fingerprint by status.
+	Memories map[string]int `json:"memories"`
+	// EntryCount and Enqueued sum the marked memories' entries and the
+	// records enqueued for them so far.
+	EntryCount int `json:"entryCount"`
+	Enqueued   int `json:"enqueued"`
+}
+
+// Job kinds run by the jobs worker.
+const (
+	JobReindex       = "reindex"       // ReindexJobParams
+	JobDeleteEntries = "deleteEntries" // EntryDeletion
+)
+
+// Job states. Queued and running jobs are open; the others are final.
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+	JobCancelled = "cancelled"
+)
+
+// Job is a long-running operation run in the background by the jobs worker.
+// Done of Total units of work are complete; Total is 0 until known.
+type Job struct {
+	JobID    string          `json:"jobId"`
+	ActorID  string          `json:"actorId"`
+	Kind     string          `json:"kind"`
+	VaultID  string          `json:"vaultId,omitempty"`
+	MemoryID string          `json:"memoryId,omitempty"`
+	Params   json.RawMessage `json:"params,omitempty"`
+	Status   string          `json:"status"`
+	Done     int             `json:"done"`
+	Total    int             `json:"total"`
+	// Error says why a failed job failed.
+	Error string `json:"error,omitempty"`
+	// CancelRequested is set on a running job asked to stop; it stops at
+	// its next progress report.
+	CancelRequested bool       `json:"cancelRequested,omitempty"`
+	CreationTime    time.Time  `json:"creationTime"`
+	UpdateTime      time.Time  `json:"updateTime"`
+	FinishTime      *time.Time `json:"finishTime,omitempty"`
+}
+
+// Finished reports whether the job reached a final state.
+func (j *Job) Finished() bool {
+	return j.Status != JobQueued && j.Status != JobRunning
+}
+
+// ReindexJobParams are the params of a JobReindex job.
+type ReindexJobParams struct {
+	Purge bool `json:"purge,omitempty"`
+}
+
+// MaxDeleteEntries bounds how many entries one JobDeleteEntries job deletes.
+const MaxDeleteEntries = 10000
+
+// EntryDeletion selects the memory's entries a JobDeleteEntries job deletes:
+// EntryIDs, or else the entries of SessionID and/or those created before
+// Before. At least one selector is required.
+type EntryDeletion struct {
+	EntryIDs  []string   `json:"entryIds,omitempty"`
+	SessionID string     `json:"sessionId,omitempty"`
+	Before    *time.Time `json:"before,omitempty"`
+}
+
+// ReindexJob tracks an admin rebuild of one memory's search index.
+// Progress counts the job's outbox records: Done have been applied, Pending
+// are waiting (Retrying of them have failed at least once) and Failed were
+// dead-lettered after OUTBOX_MAX_ATTEMPTS failures.
+type ReindexJob struct {
+	JobID        string    `json:"jobId"`
+	ActorID      string    `json:"actorId"`
+	VaultID      string    `json:"vaultId"`
+	MemoryID     string    `json:"memoryId"`
+	EntryCount   int       `json:"entryCount"`
+	ContextCount int       `json:"contextCount"`
+	Done         int       `json:"done"`
+	Pending      int       `json:"pending"`
+	Retrying     int       `json:"retrying"`
+	Failed       int       `json:"failed"`
+	Status       string    `json:"status"`
+	CreationTime time.Time `json:"creationTime"`
 }
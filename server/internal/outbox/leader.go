@@ -0,0 +1,90 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// LeaderLockKey is the Postgres advisory lock held by the elected outbox
+// worker. Any replica running RunElected competes for it.
+const LeaderLockKey int64 = 0x6d7963656c6f62 // "mycelob"
+
+// RunElected runs the polling loop only while this process holds the outbox
+// advisory lock, so several memory-service replicas can enable the in-process
+// worker and exactly one of them drains the outbox at a time. Followers retry
+// the lock every retry interval; the leader pings its lock session at the same
+// cadence and steps down when the session is lost. It returns when ctx is done.
+func (w *Worker) RunElected(ctx context.Context, retry time.Duration) error {
+	return elect(ctx, w.db, retry, w.log, w.Run)
+}
+
+// elect runs run while holding LeaderLockKey; see RunElected.
+func elect(ctx context.Context, db *sql.DB, retry time.Duration, log zerolog.Logger, run func(context.Context) error) error {
+	if retry <= 0 {
+		retry = 5 * time.Second
+	}
+	for {
+		led, err := tryLead(ctx, db, retry, log, run)
+		switch {
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case err != nil:
+			log.Warn().Err(err).Msg("outbox leadership lost")
+		case !led:
+			log.Debug().Msg("outbox leader elsewhere; following")
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(retry):
+		}
+	}
+}
+
+// tryLead takes the advisory lock and, when it gets it, runs run until ctx
+// ends or the lock session fails. led reports whether it was leader.
+func tryLead(ctx context.Context, db *sql.DB, ping time.Duration, log zerolog.Logger, run func(context.Context) error) (led bool, err error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		// Session-level advisory locks live as long as the connection, so
+		// never hand a leader's connection back to the pool: discard it.
+		_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+		_ = conn.Close()
+	}()
+
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, LeaderLockKey).Scan(&led); err != nil || !led {
+		return false, err
+	}
+	log.Info().Msg("outbox leadership acquired")
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- run(runCtx) }()
+
+	t := time.NewTicker(ping)
+	defer t.Stop()
+	for {
+		select {
+		case err := <-done:
+			if errors.Is(err, context.Canceled) {
+				err = nil
+			}
+			return true, err
+		case <-t.C:
+			if err := conn.PingContext(runCtx); err != nil && runCtx.Err() == nil {
+				cancel()
+				<-done
+				return true, err
+			}
+		}
+	}
+}
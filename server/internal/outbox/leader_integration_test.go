@@ -0,0 +1,57 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestElect_SingleLeader(t *testing.T) {
+	dsn := os.Getenv("MEMORY_SERVER_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("MEMORY_SERVER_POSTGRES_DSN not set; skipping leader election integration test")
+	}
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		t.Fatalf("postgres open: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	var leaders, maxLeaders atomic.Int32
+	run := func(ctx context.Context) error {
+		n := leaders.Add(1)
+		for {
+			if m := maxLeaders.Load(); n <= m || maxLeaders.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		<-ctx.Done()
+		leaders.Add(-1)
+		return ctx.Err()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{}, 3)
+	for i := 0; i < 3; i++ {
+		go func() {
+			_ = elect(ctx, db, 20*time.Millisecond, zerolog.Nop(), run)
+			done <- struct{}{}
+		}()
+	}
+	time.Sleep(300 * time.Millisecond)
+	if got := leaders.Load(); got != 1 {
+		t.Fatalf("expected exactly one leader, got %d", got)
+	}
+	cancel()
+	for i := 0; i < 3; i++ {
+		<-done
+	}
+	if maxLeaders.Load() != 1 {
+		t.Fatalf("leaders overlapped: max %d", maxLeaders.Load())
+	}
+}
@@ -0,0 +1,346 @@
+// Package payload defines the versioned JSON payloads of outbox rows, shared
+// by the stores that write them and the worker that applies them.
+//
+// Every payload carries its schema version in "v". Rows written before
+// payloads were versioned have none and read as version 0, whose fields are
+// those of version 1. A worker reads every version up to Version and defers
+// rows of newer ones to an upgraded worker, so the service and the worker
+// can be deployed in either order. schema.json describes the same payloads
+// as JSON Schema.
+package payload
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Version is the payload schema version written by this build. Bump it for
+// changes an older worker cannot apply, and read the previous version in
+// Decode; added optional fields do not need a bump.
+const Version = 1
+
+// Operation names stored in outbox.op.
+const (
+	OpUpsertEntry   = "upsert_entry"
+	OpDeleteEntry   = "delete_entry"
+	OpUpsertContext = "upsert_context"
+	OpDeleteContext = "delete_context"
+	// OpRenameMemory rewrites the memory and/or vault title held by a
+	// memory's index objects; aggregate_id is the memory ID.
+	OpRenameMemory = "rename_memory"
+)
+
+// Schema is the JSON Schema of the payloads, one definition per op.
+//
+//go:embed schema.json
+var Schema []byte
+
+var (
+	// ErrInvalid marks a payload that does not match its op's schema.
+	ErrInvalid = errors.New("invalid outbox payload")
+	// ErrNewerVersion marks a payload written by a newer build than this one.
+	ErrNewerVersion = errors.New("outbox payload version not supported")
+)
+
+// Payload is the body of one outbox row.
+type Payload interface {
+	// Op is the outbox op the payload belongs to.
+	Op() string
+	// Validate reports a missing required field as ErrInvalid.
+	Validate() error
+	setVersion(v int)
+}
+
+// Entry is the payload of upsert_entry.
+type Entry struct {
+	V            int       `json:"v,omitempty"`
+	ActorID      string    `json:"actorId"`
+	MemoryID     string    `json:"memoryId"`
+	EntryID      string    `json:"entryId"`
+	RawEntry     string    `json:"rawEntry"`
+	Summary      string    `json:"summary"`
+	Tags         Tags      `json:"tags,omitempty"`
+	TagPairs     []string  `json:"tagPairs,omitempty"`
+	CreationTime time.Time `json:"creationTime"`
+	SessionID    string    `json:"sessionId,omitempty"`
+	MemoryTitle  string    `json:"memoryTitle,omitempty"`
+	VaultTitle   string    `json:"vaultTitle,omitempty"`
+}
+
+// Context is the payload of upsert_context.
+type Context struct {
+	V            int       `json:"v,omitempty"`
+	ActorID      string    `json:"actorId"`
+	MemoryID     string    `json:"memoryId"`
+	ContextID    string    `json:"contextId"`
+	Context      string    `json:"context"`
+	CreationTime time.Time `json:"creationTime"`
+	MemoryTitle  string    `json:"memoryTitle,omitempty"`
+	VaultTitle   string    `json:"vaultTitle,omitempty"`
+}
+
+// Delete is the payload of delete_entry and delete_context.
+type Delete struct {
+	V       int    `json:"v,omitempty"`
+	ActorID string `json:"actorId"`
+	op      string
+}
+
+// Rename is the payload of rename_memory; an empty title is unchanged.
+type Rename struct {
+	V           int    `json:"v,omitempty"`
+	ActorID     string `json:"actorId"`
+	MemoryID    string `json:"memoryId"`
+	MemoryTitle string `json:"memoryTitle,omitempty"`
+	VaultTitle  string `json:"vaultTitle,omitempty"`
+}
+
+// DeleteEntry returns the delete_entry payload for actorID.
+func DeleteEntry(actorID string) *Delete { return &Delete{ActorID: actorID, op: OpDeleteEntry} }
+
+// DeleteContext returns the delete_context payload for actorID.
+func DeleteContext(actorID string) *Delete { return &Delete{ActorID: actorID, op: OpDeleteContext} }
+
+func (*Entry) Op() string    { return OpUpsertEntry }
+func (*Context) Op() string  { return OpUpsertContext }
+func (d *Delete) Op() string { return d.op }
+func (*Rename) Op() string   { return OpRenameMemory }
+
+func (p *Entry) setVersion(v int)   { p.V = v }
+func (p *Context) setVersion(v int) { p.V = v }
+func (p *Delete) setVersion(v int)  { p.V = v }
+func (p *Rename) setVersion(v int)  { p.V = v }
+
+func (p *Entry) Validate() error {
+	return required(OpUpsertEntry, "actorId", p.ActorID, "memoryId", p.MemoryID, "entryId", p.EntryID)
+}
+
+func (p *Context) Validate() error {
+	return required(OpUpsertContext, "actorId", p.ActorID, "memoryId", p.MemoryID, "contextId", p.ContextID)
+}
+
+func (p *Delete) Validate() error { return required(p.op, "actorId", p.ActorID) }
+
+func (p *Rename) Validate() error {
+	if err := required(OpRenameMemory, "actorId", p.ActorID); err != nil {
+		return err
+	}
+	if p.MemoryTitle == "" && p.VaultTitle == "" {
+		return fmt.Errorf("%w: %s: memoryTitle or vaultTitle required", ErrInvalid, OpRenameMemory)
+	}
+	return nil
+}
+
+// required checks name/value pairs for empty values.
+func required(op string, pairs ...string) error {
+	var missing []string
+	for i := 0; i < len(pairs); i += 2 {
+		if pairs[i+1] == "" {
+			missing = append(missing, pairs[i])
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s: missing %s", ErrInvalid, op, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// Properties returns the entry as search index properties.
+func (p *Entry) Properties() map[string]interface{} {
+	props := map[string]interface{}{
+		"actorId":      p.ActorID,
+		"memoryId":     p.MemoryID,
+		"entryId":      p.EntryID,
+		"rawEntry":     p.RawEntry,
+		"summary":      p.Summary,
+		"creationTime": p.CreationTime,
+	}
+	if p.Tags != nil {
+		props["tags"] = []string(p.Tags)
+	}
+	if p.TagPairs != nil {
+		props["tagPairs"] = p.TagPairs
+	}
+	if p.SessionID != "" {
+		props["sessionId"] = p.SessionID
+	}
+	addTitles(props, p.MemoryTitle, p.VaultTitle)
+	return props
+}
+
+// Properties returns the context as search index properties.
+func (p *Context) Properties() map[string]interface{} {
+	props := map[string]interface{}{
+		"actorId":      p.ActorID,
+		"memoryId":     p.MemoryID,
+		"contextId":    p.ContextID,
+		"context":      p.Context,
+		"creationTime": p.CreationTime,
+	}
+	addTitles(props, p.MemoryTitle, p.VaultTitle)
+	return props
+}
+
+func addTitles(props map[string]interface{}, memoryTitle, vaultTitle string) {
+	if memoryTitle != "" {
+		props["memoryTitle"] = memoryTitle
+		props["vaultTitle"] = vaultTitle
+	}
+}
+
+// Text is the text embedded for the entry: its summary, else the raw entry.
+func (p *Entry) Text() string {
+	if p.Summary != "" {
+		return p.Summary
+	}
+	return p.RawEntry
+}
+
+// Tags are an entry's tag keys. They are written as an array and read from
+// an array or from the entry's tag object, whose true-valued keys count.
+type Tags []string
+
+// TagKeys returns the keys of an entry's tag object set to true (or "true"),
+// sorted; nil when there are none.
+func TagKeys(tags map[string]interface{}) Tags {
+	var keys Tags
+	for k, v := range tags {
+		switch t := v.(type) {
+		case bool:
+			if t {
+				keys = append(keys, k)
+			}
+		case string:
+			if strings.EqualFold(t, "true") {
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// TagPairs returns an entry's tag object as sorted "key=value" strings, the
+// form tag filters match exactly; nil when there are none. Booleans and
+// numbers are written as text, as TagPairsSQL writes them, and nested
+// objects, arrays and nulls are left out.
+func TagPairs(tags map[string]interface{}) []string {
+	var pairs []string
+	for k, v := range tags {
+		if s, ok := tagValue(v); ok {
+			pairs = append(pairs, k+"="+s)
+		}
+	}
+	sort.Strings(pairs)
+	return pairs
+}
+
+// tagValue returns a scalar tag value as text; false for other values.
+func tagValue(v interface{}) (string, bool) {
+	switch t := v.(type) {
+	case string:
+		return t, true
+	case bool:
+		return strconv.FormatBool(t), true
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64), true
+	case json.Number:
+		return t.String(), true
+	}
+	return "", false
+}
+
+// TagPairsSQL is a Postgres expression of the tagPairs of the entry tag
+// object in column tags, as TagPairs computes them; NULL when there are none.
+const TagPairsSQL = `(SELECT jsonb_agg(t.key || '=' || (t.value #>> '{}') ORDER BY t.key || '=' || (t.value #>> '{}') COLLATE "C")
+            FROM jsonb_each(CASE WHEN jsonb_typeof(tags) = 'object' THEN tags ELSE '{}'::jsonb END) t
+            WHERE jsonb_typeof(t.value) IN ('string', 'number', 'boolean'))`
+
+func (t *Tags) UnmarshalJSON(b []byte) error {
+	switch b = bytes.TrimSpace(b); {
+	case bytes.Equal(b, []byte("null")):
+		*t = nil
+		return nil
+	case len(b) > 0 && b[0] == '{':
+		var m map[string]interface{}
+		if err := json.Unmarshal(b, &m); err != nil {
+			return err
+		}
+		*t = TagKeys(m)
+		if *t == nil {
+			*t = Tags{}
+		}
+		return nil
+	}
+	var items []interface{}
+	if err := json.Unmarshal(b, &items); err != nil {
+		return fmt.Errorf("tags: want an array or object: %w", err)
+	}
+	keys := make(Tags, 0, len(items))
+	for _, it := range items {
+		if s, ok := it.(string); ok {
+			keys = append(keys, s)
+		}
+	}
+	*t = keys
+	return nil
+}
+
+// Encode validates p and marshals it at the current Version.
+func Encode(p Payload) ([]byte, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	p.setVersion(Version)
+	return json.Marshal(p)
+}
+
+// Decode reads and validates the payload of an op. A payload of a version
+// above Version is ErrNewerVersion; one that does not match the op's schema,
+// or of an unknown op, is ErrInvalid.
+func Decode(op string, raw []byte) (Payload, error) {
+	var head struct {
+		V int `json:"v"`
+	}
+	if err := json.Unmarshal(raw, &head); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalid, op, err)
+	}
+	switch {
+	case head.V > Version:
+		return nil, fmt.Errorf("%w: %s version %d (this build reads up to %d)", ErrNewerVersion, op, head.V, Version)
+	case head.V < 0:
+		return nil, fmt.Errorf("%w: %s: version %d", ErrInvalid, op, head.V)
+	}
+
+	// Versions 0 and 1 share their fields; an upgrade from an older
+	// version goes here once there is one.
+	var p Payload
+	switch op {
+	case OpUpsertEntry:
+		p = &Entry{}
+	case OpUpsertContext:
+		p = &Context{}
+	case OpDeleteEntry:
+		p = DeleteEntry("")
+	case OpDeleteContext:
+		p = DeleteContext("")
+	case OpRenameMemory:
+		p = &Rename{}
+	default:
+		return nil, fmt.Errorf("%w: unknown op %q", ErrInvalid, op)
+	}
+	if err := json.Unmarshal(raw, p); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalid, op, err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
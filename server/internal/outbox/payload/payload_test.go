@@ -0,0 +1,144 @@
+package payload
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEncodeDecode_RoundTrip(t *testing.T) {
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	in := &Entry{ActorID: "a1", MemoryID: "m1", EntryID: "e1", RawEntry: "raw", Tags: Tags{"x"}, CreationTime: created, SessionID: "s1"}
+	b, err := Encode(in)
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if !strings.Contains(string(b), `"v":1`) {
+		t.Fatalf("payload without version: %s", b)
+	}
+	p, err := Decode(OpUpsertEntry, b)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if got := p.(*Entry); !reflect.DeepEqual(got, in) || got.Text() != "raw" {
+		t.Fatalf("round trip: got %+v, want %+v", got, in)
+	}
+
+	if _, err := Encode(&Entry{ActorID: "a1", MemoryID: "m1"}); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("Encode without entryId: expected ErrInvalid, got %v", err)
+	}
+	if b, err := Encode(DeleteContext("a1")); err != nil || string(b) != `{"v":1,"actorId":"a1"}` {
+		t.Fatalf("Encode delete: %s %v", b, err)
+	}
+}
+
+func TestDecode_Versions(t *testing.T) {
+	// Rows written before versioning: no "v", tags as the entry's tag
+	// object and Postgres' timestamp format.
+	legacy := `{"actorId":"a1","memoryId":"m1","entryId":"e1","rawEntry":"raw","summary":null,
+		"tags":{"b":true,"a":"true","off":false},"creationTime":"2026-01-02T03:04:05.123456+00:00","memoryTitle":"notes","vaultTitle":"work"}`
+	p, err := Decode(OpUpsertEntry, []byte(legacy))
+	if err != nil {
+		t.Fatalf("Decode legacy: %v", err)
+	}
+	e := p.(*Entry)
+	if e.V != 0 || !reflect.DeepEqual(e.Tags, Tags{"a", "b"}) || e.CreationTime.Nanosecond() != 123456000 {
+		t.Fatalf("legacy entry: %+v", e)
+	}
+	props := e.Properties()
+	if props["memoryTitle"] != "notes" || !reflect.DeepEqual(props["tags"], []string{"a", "b"}) {
+		t.Fatalf("properties: %v", props)
+	}
+
+	if _, err := Decode(OpUpsertEntry, []byte(`{"v":2,"actorId":"a1"}`)); !errors.Is(err, ErrNewerVersion) {
+		t.Fatalf("newer version: expected ErrNewerVersion, got %v", err)
+	}
+	for op, raw := range map[string]string{
+		OpUpsertContext: `{"actorId":"a1","memoryId":"m1"}`,
+		OpDeleteEntry:   `{}`,
+		OpRenameMemory:  `{"actorId":"a1","memoryId":"m1"}`,
+		"compact":       `{"actorId":"a1"}`,
+		OpUpsertEntry:   `{"actorId":"a1","memoryId":"m1","entryId":"e1","tags":"x"}`,
+	} {
+		if _, err := Decode(op, []byte(raw)); !errors.Is(err, ErrInvalid) {
+			t.Fatalf("%s %s: expected ErrInvalid, got %v", op, raw, err)
+		}
+	}
+	if p, err := Decode(OpDeleteEntry, []byte(`{"actorId":"a1"}`)); err != nil || p.Op() != OpDeleteEntry {
+		t.Fatalf("delete: %+v %v", p, err)
+	}
+}
+
+// TestSchema_MatchesStructs keeps schema.json in step with the Go payloads.
+func TestSchema_MatchesStructs(t *testing.T) {
+	var schema struct {
+		Defs map[string]struct {
+			Ref        string                     `json:"$ref"`
+			Required   []string                   `json:"required"`
+			Properties map[string]json.RawMessage `json:"properties"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(Schema, &schema); err != nil {
+		t.Fatalf("schema.json: %v", err)
+	}
+	for op, p := range map[string]Payload{
+		OpUpsertEntry: &Entry{}, OpUpsertContext: &Context{}, OpDeleteEntry: DeleteEntry(""),
+		OpDeleteContext: DeleteContext(""), OpRenameMemory: &Rename{},
+	} {
+		def, ok := schema.Defs[op]
+		if !ok {
+			t.Fatalf("schema has no definition for %s", op)
+		}
+		if def.Ref != "" {
+			def = schema.Defs[strings.TrimPrefix(def.Ref, "#/$defs/")]
+		}
+		var fields []string
+		typ := reflect.TypeOf(p).Elem()
+		for i := 0; i < typ.NumField(); i++ {
+			if name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ","); name != "" {
+				fields = append(fields, name)
+			}
+		}
+		var props []string
+		for name := range def.Properties {
+			props = append(props, name)
+		}
+		sort.Strings(fields)
+		sort.Strings(props)
+		if !reflect.DeepEqual(fields, props) {
+			t.Fatalf("%s: struct fields %v, schema properties %v", op, fields, props)
+		}
+		// The schema's required fields are exactly those Validate insists on.
+		if err := p.Validate(); err == nil {
+			t.Fatalf("%s: empty payload validated", op)
+		} else {
+			for _, name := range def.Required {
+				if !strings.Contains(err.Error(), name) {
+					t.Fatalf("%s: required %s not checked: %v", op, name, err)
+				}
+			}
+		}
+	}
+}
+
+func TestTagPairs(t *testing.T) {
+	var tags map[string]interface{}
+	if err := json.Unmarshal([]byte(`{"project":"alpha","done":false,"priority":2,"ratio":0.5,"owner":{"id":1},"gone":null}`), &tags); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"done=false", "priority=2", "project=alpha", "ratio=0.5"}
+	if got := TagPairs(tags); !reflect.DeepEqual(got, want) {
+		t.Fatalf("TagPairs: got %v, want %v", got, want)
+	}
+	if got := TagPairs(map[string]interface{}{"nested": []interface{}{"x"}}); got != nil {
+		t.Fatalf("TagPairs without scalars: got %v, want nil", got)
+	}
+	e := &Entry{ActorID: "a1", MemoryID: "m1", EntryID: "e1", TagPairs: want}
+	if props := e.Properties(); !reflect.DeepEqual(props["tagPairs"], want) {
+		t.Fatalf("properties: %v", props)
+	}
+}
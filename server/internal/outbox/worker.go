@@ -3,56 +3,162 @@ package outbox
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
+	"database/sql/driver"
 	"errors"
+	"expvar"
 	"fmt"
-	"strings"
+	"os"
+	"runtime/debug"
+	"sort"
 	"time"
 
-	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/stdlib"
 
 	"github.com/rs/zerolog"
 
 	emb "github.com/mycelian/mycelian-memory/server/internal/embeddings"
+	"github.com/mycelian/mycelian-memory/server/internal/outbox/payload"
 	"github.com/mycelian/mycelian-memory/server/internal/searchindex"
 )
 
 // Operation names stored in outbox.op (idempotent targets)
 const (
-	OpUpsertEntry   = "upsert_entry"
-	OpDeleteEntry   = "delete_entry"
-	OpUpsertContext = "upsert_context"
-	OpDeleteContext = "delete_context"
+	OpUpsertEntry   = payload.OpUpsertEntry
+	OpDeleteEntry   = payload.OpDeleteEntry
+	OpUpsertContext = payload.OpUpsertContext
+	OpDeleteContext = payload.OpDeleteContext
+	OpRenameMemory  = payload.OpRenameMemory
 )
 
 // SQL statements kept as constants for clarity and reuse
 const (
-	selectReadyRowsSQL = `
-SELECT id, op, payload, aggregate_id
-FROM outbox
-WHERE status = 'pending' AND next_attempt_at <= now()
-ORDER BY id ASC
-FOR UPDATE SKIP LOCKED
-LIMIT $1`
+	// claimRowsSQL leases up to $1 ready rows to worker $3 for $2 seconds in
+	// its own short transaction, so no row lock is held while rows are
+	// embedded and indexed. Rows whose lease expired (their worker died) are
+	// ready again. A row waits while an earlier row of the same aggregate is
+	// pending, so two workers never apply one aggregate's ops out of order.
+	// Upserts carry their memory's titles, so a rename_memory row and the
+	// entry and context upserts of its memory also wait for each other's
+	// earlier rows: an upsert written before a rename cannot land after it
+	// with the old titles, nor one written after it before it; the schema's
+	// outbox_pending_memory_idx serves that memoryId lookup.
+	claimRowsSQL = `
+WITH ready AS (
+  SELECT o.id
+  FROM outbox o
+  WHERE o.status = 'pending' AND o.next_attempt_at <= now()
+    AND (o.leased_until IS NULL OR o.leased_until < now())
+    AND NOT EXISTS (
+      SELECT 1 FROM outbox prior
+      WHERE prior.aggregate_id = o.aggregate_id AND prior.status = 'pending' AND prior.id < o.id)
+    AND NOT EXISTS (
+      SELECT 1 FROM outbox prior
+      WHERE prior.payload->>'memoryId' = o.payload->>'memoryId' AND prior.status = 'pending' AND prior.id < o.id
+        AND (prior.op = 'rename_memory') <> (o.op = 'rename_memory'))
+  ORDER BY o.id ASC
+  FOR UPDATE OF o SKIP LOCKED
+  LIMIT $1
+)
+UPDATE outbox SET leased_until = now() + make_interval(secs => $2), lease_owner = $3
+FROM ready
+WHERE outbox.id = ready.id
+RETURNING outbox.id, outbox.op, outbox.payload, outbox.aggregate_id`
+
+	// renewLeaseSQL extends the lease on row $1 if worker $3 still holds it.
+	renewLeaseSQL = `
+UPDATE outbox SET leased_until = now() + make_interval(secs => $2)
+WHERE id=$1 AND lease_owner=$3 AND status='pending'`
+
+	// releaseLeasesSQL hands rows of worker $2 back without counting an attempt.
+	releaseLeasesSQL = `UPDATE outbox SET leased_until=NULL WHERE id = ANY($1) AND lease_owner=$2 AND status='pending'`
+
+	// deferRowSQL hands row $1 back until $2 seconds from now without
+	// counting an attempt, for a newer worker to pick up.
+	deferRowSQL = `
+UPDATE outbox SET leased_until=NULL, next_attempt_at = now() + make_interval(secs => $2), last_error=$3, update_time=now()
+WHERE id=$1 AND lease_owner=$4 AND status='pending'`
 
-	markDoneSQL = `UPDATE outbox SET status='done', update_time=now() WHERE id=$1`
+	markDoneSQL = `UPDATE outbox SET status='done', leased_until=NULL, update_time=now() WHERE id=$1 AND lease_owner=$2`
 
+	// markFailedSQL backs the row off and dead-letters it (status 'dead',
+	// never leased again) once it has failed $3 times; $3 = 0 retries forever.
 	markFailedSQL = `
 UPDATE outbox
 SET attempt_count = attempt_count + 1,
     next_attempt_at = now() + make_interval(secs => LEAST(POWER(2, attempt_count+1), 300)),
+    last_error = $2,
+    status = CASE WHEN $3::int > 0 AND attempt_count + 1 >= $3::int THEN 'dead' ELSE status END,
+    leased_until = NULL,
     update_time = now()
-WHERE id=$1`
+WHERE id=$1 AND lease_owner=$4
+RETURNING status`
 )
 
+var (
+	// panicsRecovered counts outbox rows and cycles whose processing panicked.
+	panicsRecovered = expvar.NewInt("outbox_panics_recovered")
+	// deleteVerifications counts read-after-delete checks against the index;
+	// deleteVerifyFailures those that found the object still indexed.
+	deleteVerifications  = expvar.NewInt("outbox_delete_verifications")
+	deleteVerifyFailures = expvar.NewInt("outbox_delete_verification_failures")
+	// deadLettered counts rows given up on after MaxAttempts failures.
+	deadLettered = expvar.NewInt("outbox_dead_lettered")
+	// leasesLost counts claimed rows another worker took over after the
+	// lease expired; the row is left to that worker.
+	leasesLost = expvar.NewInt("outbox_leases_lost")
+	// invalidPayloads counts rows whose payload does not match its op's
+	// schema; newerPayloads rows deferred because a newer build wrote them.
+	invalidPayloads = expvar.NewInt("outbox_invalid_payloads")
+	newerPayloads   = expvar.NewInt("outbox_newer_payloads")
+	// notifyWakeups counts polls started early by an outbox notification.
+	notifyWakeups = expvar.NewInt("outbox_notify_wakeups")
+)
+
+// NotifyChannel is the Postgres channel the outbox insert trigger notifies
+// when a transaction that wrote outbox rows commits.
+const NotifyChannel = "outbox_ready"
+
+// listenRetry is how long a worker polls only before listening again after
+// its notification connection failed.
+const listenRetry = 5 * time.Second
+
+// newerPayloadDelay is how long a row written by a newer build waits before
+// it is claimed again, giving an upgraded worker the chance to take it.
+const newerPayloadDelay = time.Minute
+
+// errLeaseLost is returned when a row's lease has passed to another worker.
+var errLeaseLost = errors.New("outbox lease lost")
+
+// errDeleteNotPropagated is returned when the index still holds an object
+// after deleting it; the row is retried like any other failure.
+var errDeleteNotPropagated = errors.New("object still in search index after delete")
+
 // Config controls batch size and polling cadence.
 type Config struct {
 	PostgresDSN string        // currently unused here (DB is injected), kept for symmetry with main
 	BatchSize   int           // number of rows to lease per cycle
 	Interval    time.Duration // poll interval
+	// MaxAttempts dead-letters a row after this many failures so it stops
+	// being retried; 0 retries forever.
+	MaxAttempts int
+	// Lease is how long a claimed row stays with this worker; it is renewed
+	// before each row is handled, so it only has to cover one row. A crashed
+	// worker's rows are claimed again once it runs out.
+	Lease time.Duration
+	// WorkerID names this worker in outbox.lease_owner; it must differ
+	// between concurrently running workers.
+	WorkerID string
+	// Listen wakes the worker as soon as outbox rows are committed, through
+	// Postgres LISTEN on one extra connection; Interval polling stays as the
+	// fallback for missed notifications and retries coming due.
+	Listen bool
 }
 
-// Worker processes outbox rows and applies them to the vector store.
+// Worker processes outbox rows and applies them to the vector store. Any
+// number of workers may share one outbox: rows are claimed under a lease
+// with SKIP LOCKED, each row is checkpointed as soon as it is handled, and
+// a worker that lost a row's lease leaves it to the new holder.
 type Worker struct {
 	db       *sql.DB
 	log      zerolog.Logger
@@ -69,14 +175,26 @@ func NewWorker(db *sql.DB, emb emb.EmbeddingProvider, idx searchindex.Index, cfg
 	if cfg.Interval <= 0 {
 		cfg.Interval = 2 * time.Second
 	}
+	if cfg.Lease <= 0 {
+		cfg.Lease = time.Minute
+	}
+	if cfg.WorkerID == "" {
+		cfg.WorkerID = DefaultWorkerID()
+	}
 	return &Worker{db: db, log: log, embedder: emb, index: idx, cfg: cfg}
 }
 
 // Run starts the polling loop until ctx is canceled.
 func (w *Worker) Run(ctx context.Context) error {
-	w.log.Info().Int("batch", w.cfg.BatchSize).Dur("interval", w.cfg.Interval).Msg("outbox worker starting")
+	w.log.Info().Int("batch", w.cfg.BatchSize).Dur("interval", w.cfg.Interval).Dur("lease", w.cfg.Lease).
+		Str("worker", w.cfg.WorkerID).Bool("listen", w.cfg.Listen).Msg("outbox worker starting")
 	ticker := time.NewTicker(w.cfg.Interval)
 	defer ticker.Stop()
+	var wake chan struct{} // nil without Listen: never ready
+	if w.cfg.Listen {
+		wake = make(chan struct{}, 1)
+		go w.listen(ctx, wake)
+	}
 
 	for {
 		select {
@@ -84,38 +202,95 @@ func (w *Worker) Run(ctx context.Context) error {
 			w.log.Info().Msg("outbox worker stopping")
 			return ctx.Err()
 		case <-ticker.C:
-			if err := w.processOnce(ctx); err != nil {
-				// Log and continue; per-row backoff prevents hot-looping
-				w.log.Error().Err(err).Msg("outbox processOnce")
-			}
+		case <-wake:
+			notifyWakeups.Add(1)
+		}
+		if err := w.guard("processOnce", func() error { return w.processOnce(ctx) }); err != nil {
+			// Log and continue; per-row backoff prevents hot-looping
+			w.log.Error().Err(err).Msg("outbox processOnce")
+		}
+	}
+}
+
+// listen signals wake on every NotifyChannel notification until ctx is
+// done, reconnecting after listenRetry when the connection fails; the
+// worker keeps polling meanwhile.
+func (w *Worker) listen(ctx context.Context, wake chan<- struct{}) {
+	for {
+		err := w.waitNotifications(ctx, wake)
+		if ctx.Err() != nil {
+			return
+		}
+		w.log.Warn().Err(err).Dur("retry", listenRetry).Msg("outbox listen failed; polling until it reconnects")
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(listenRetry):
 		}
 	}
 }
 
+// waitNotifications listens on a connection of its own and signals wake
+// once at the start, for rows committed before it listened, then for every
+// notification. It returns when the connection or ctx fails.
+func (w *Worker) waitNotifications(ctx context.Context, wake chan<- struct{}) error {
+	conn, err := w.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// The session stays subscribed, so never hand it back to the pool.
+		_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+		_ = conn.Close()
+	}()
+	return conn.Raw(func(dc any) error {
+		c, ok := dc.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("outbox listen needs the pgx driver, got %T", dc)
+		}
+		if _, err := c.Conn().Exec(ctx, "LISTEN "+NotifyChannel); err != nil {
+			return err
+		}
+		for {
+			select {
+			case wake <- struct{}{}:
+			default: // a poll is already due
+			}
+			if _, err := c.Conn().WaitForNotification(ctx); err != nil {
+				return err
+			}
+		}
+	})
+}
+
 type job struct {
 	id          int64
 	op          string
 	aggregateID string
-	payload     map[string]interface{}
+	payload     payload.Payload
+	err         error // why the payload did not decode
 }
 
 func (w *Worker) processOnce(ctx context.Context) error {
-	tx, err := w.db.BeginTx(ctx, &sql.TxOptions{})
+	jobs, err := w.claimBatch(ctx, w.cfg.BatchSize)
 	if err != nil {
 		return err
 	}
-	defer func() { _ = tx.Rollback() }()
 
-	jobs, err := w.leaseBatch(ctx, tx, w.cfg.BatchSize)
-	if err != nil {
-		return err
-	}
-	if len(jobs) == 0 {
-		return tx.Commit()
-	}
-
-	for _, j := range jobs {
-		if err := w.handle(ctx, j); err != nil {
+	for i, j := range jobs {
+		if ctx.Err() != nil {
+			w.release(jobs[i:])
+			return ctx.Err()
+		}
+		if err := w.renew(ctx, j.id); err != nil {
+			if errors.Is(err, errLeaseLost) {
+				leasesLost.Add(1)
+				w.log.Warn().Int64("id", j.id).Str("op", j.op).Msg("outbox lease lost before handling; skipping")
+				continue
+			}
+			return err
+		}
+		if err := w.guard(j.op, func() error { return w.handle(ctx, j) }); err != nil {
 			// Surface per-row failures with enough context to debug
 			w.log.Error().
 				Err(err).
@@ -124,51 +299,128 @@ func (w *Worker) processOnce(ctx context.Context) error {
 				Str("aggregate_id", j.aggregateID).
 				Msg("outbox handle error; marking failed")
 
-			if e := w.markFailed(ctx, tx, j.id, err); e != nil {
+			dead, e := w.markFailed(ctx, j.id, err)
+			if e != nil {
 				w.log.Error().Err(e).Int64("id", j.id).Msg("markFailed error")
 			}
+			if dead {
+				w.log.Error().Err(err).Int64("id", j.id).Str("op", j.op).Str("aggregate_id", j.aggregateID).
+					Int("maxAttempts", w.cfg.MaxAttempts).Msg("outbox row dead-lettered")
+			}
 			continue
 		}
-		if e := w.markDone(ctx, tx, j.id); e != nil {
+		if e := w.markDone(ctx, j.id); e != nil {
 			w.log.Error().Err(e).Int64("id", j.id).Msg("markDone error")
 		}
 	}
-
-	return tx.Commit()
+	return nil
 }
 
-// leaseBatch locks and returns up to batchSize ready outbox rows.
-func (w *Worker) leaseBatch(ctx context.Context, tx *sql.Tx, batchSize int) ([]job, error) {
-	rows, err := tx.QueryContext(ctx, selectReadyRowsSQL, batchSize)
+// claimBatch leases up to batchSize ready outbox rows to this worker,
+// oldest first.
+func (w *Worker) claimBatch(ctx context.Context, batchSize int) ([]job, error) {
+	rows, err := w.db.QueryContext(ctx, claimRowsSQL, batchSize, w.cfg.Lease.Seconds(), w.cfg.WorkerID)
 	if err != nil {
 		return nil, err
 	}
 	defer func() { _ = rows.Close() }()
 
 	var jobs []job
+	var bad []job
 	for rows.Next() {
 		var j job
 		var raw []byte
 		if err := rows.Scan(&j.id, &j.op, &raw, &j.aggregateID); err != nil {
 			return nil, err
 		}
-		if err := json.Unmarshal(raw, &j.payload); err != nil {
-			// Poison pill: mark failed so it backs off and won’t hot-loop
-			_ = w.markFailed(ctx, tx, j.id, errors.New("bad payload"))
+		if j.payload, err = payload.Decode(j.op, raw); err != nil {
+			j.err = err
+			bad = append(bad, j)
 			continue
 		}
 		jobs = append(jobs, j)
 	}
-	return jobs, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for _, j := range bad {
+		w.rejectPayload(ctx, j)
+	}
+	// UPDATE ... RETURNING does not keep the claim's order.
+	sort.Slice(jobs, func(a, b int) bool { return jobs[a].id < jobs[b].id })
+	return jobs, nil
+}
+
+// rejectPayload handles a row whose payload did not decode. A payload of a
+// newer version is deferred without counting an attempt, so rolling the
+// service ahead of the worker only delays its rows; an invalid one is a
+// poison pill, failed so it backs off and is eventually dead-lettered.
+func (w *Worker) rejectPayload(ctx context.Context, j job) {
+	if errors.Is(j.err, payload.ErrNewerVersion) {
+		newerPayloads.Add(1)
+		w.log.Warn().Err(j.err).Int64("id", j.id).Str("op", j.op).Msg("outbox row written by a newer build; deferring")
+		if _, err := w.db.ExecContext(ctx, deferRowSQL, j.id, newerPayloadDelay.Seconds(), j.err.Error(), w.cfg.WorkerID); err != nil {
+			w.log.Error().Err(err).Int64("id", j.id).Msg("defer outbox row failed")
+		}
+		return
+	}
+	invalidPayloads.Add(1)
+	w.log.Error().Err(j.err).Int64("id", j.id).Str("op", j.op).Str("aggregate_id", j.aggregateID).Msg("invalid outbox payload; marking failed")
+	if _, err := w.markFailed(ctx, j.id, j.err); err != nil {
+		w.log.Error().Err(err).Int64("id", j.id).Msg("markFailed error")
+	}
+}
+
+// renew extends this worker's lease on row id before it is handled;
+// errLeaseLost if another worker has claimed it since.
+func (w *Worker) renew(ctx context.Context, id int64) error {
+	res, err := w.db.ExecContext(ctx, renewLeaseSQL, id, w.cfg.Lease.Seconds(), w.cfg.WorkerID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return errLeaseLost
+	}
+	return nil
+}
+
+// release hands unhandled rows back on shutdown so other workers need not
+// wait for their leases to expire.
+func (w *Worker) release(jobs []job) {
+	if len(jobs) == 0 {
+		return
+	}
+	ids := make([]int64, len(jobs))
+	for i, j := range jobs {
+		ids[i] = j.id
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := w.db.ExecContext(ctx, releaseLeasesSQL, ids, w.cfg.WorkerID); err != nil {
+		w.log.Warn().Err(err).Int("rows", len(ids)).Msg("outbox lease release failed; rows wait for lease expiry")
+	}
+}
+
+// guard runs fn and converts a panic into an error so one bad record cannot
+// crash the worker; the row is then marked failed and backs off like any error.
+func (w *Worker) guard(what string, fn func() error) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			panicsRecovered.Add(1)
+			w.log.Error().Interface("panic", rec).Str("in", what).Bytes("stack", debug.Stack()).Msg("outbox panic recovered")
+			err = fmt.Errorf("panic in %s: %v", what, rec)
+		}
+	}()
+	return fn()
 }
 
 // handle executes the outbox operation.
 func (w *Worker) handle(ctx context.Context, j job) error {
 	w.log.Info().Str("op", j.op).Str("aggregateId", j.aggregateID).Int64("id", j.id).Msg("processing outbox job")
 
-	switch j.op {
-	case OpUpsertEntry:
-		text := preferredText(j.payload, "summary", "rawEntry")
+	switch p := j.payload.(type) {
+	case *payload.Entry:
+		text := p.Text()
 		w.log.Debug().Str("text", text).Str("entryId", j.aggregateID).Msg("upserting entry")
 		vec, err := w.embed(text, ctx)
 		if err != nil {
@@ -176,98 +428,110 @@ func (w *Worker) handle(ctx context.Context, j job) error {
 			return err
 		}
 		w.log.Debug().Int("vectorLength", len(vec)).Str("entryId", j.aggregateID).Msg("embedding generated")
-		normalizeEntryTags(j.payload)
-		err = w.index.UpsertEntry(ctx, j.aggregateID, vec, j.payload)
+		err = w.index.UpsertEntry(ctx, j.aggregateID, vec, p.Properties())
 		if err != nil {
 			w.log.Error().Err(err).Str("entryId", j.aggregateID).Msg("upsert entry failed")
 			return err
 		}
 		w.log.Info().Str("entryId", j.aggregateID).Msg("entry upserted successfully")
 		return nil
-	case OpDeleteEntry:
-		return w.index.DeleteEntry(ctx, stringField(j.payload, "actorId"), j.aggregateID)
-	case OpUpsertContext:
-		text := stringField(j.payload, "context")
-		vec, err := w.embed(text, ctx)
+	case *payload.Context:
+		vec, err := w.embed(p.Context, ctx)
 		if err != nil {
 			return err
 		}
-		return w.index.UpsertContext(ctx, j.aggregateID, vec, j.payload)
-	case OpDeleteContext:
-		return w.index.DeleteContext(ctx, stringField(j.payload, "actorId"), j.aggregateID)
+		return w.index.UpsertContext(ctx, j.aggregateID, vec, p.Properties())
+	case *payload.Delete:
+		if j.op == OpDeleteEntry {
+			if err := w.index.DeleteEntry(ctx, p.ActorID, j.aggregateID); err != nil {
+				return err
+			}
+		} else if err := w.index.DeleteContext(ctx, p.ActorID, j.aggregateID); err != nil {
+			return err
+		}
+		return w.verifyDeleted(ctx, j.op, p.ActorID, j.aggregateID)
+	case *payload.Rename:
+		updater, ok := w.index.(searchindex.TitleUpdater)
+		if !ok {
+			return nil
+		}
+		return updater.UpdateTitles(ctx, p.ActorID, j.aggregateID, p.MemoryTitle, p.VaultTitle)
 	default:
 		return fmt.Errorf("unknown op: %s", j.op)
 	}
 }
 
-func (w *Worker) markDone(ctx context.Context, tx *sql.Tx, id int64) error {
-	_, err := tx.ExecContext(ctx, markDoneSQL, id)
-	return err
-}
-
-func (w *Worker) markFailed(ctx context.Context, tx *sql.Tx, id int64, _ error) error {
-	_, err := tx.ExecContext(ctx, markFailedSQL, id)
-	return err
+// verifyDeleted reads a deleted entry or context back from the index, when
+// the index can check for objects, so deleted user data cannot linger there
+// unnoticed: a lingering object fails the row, which is then retried and
+// eventually dead-lettered.
+func (w *Worker) verifyDeleted(ctx context.Context, op, actorID, id string) error {
+	checker, ok := w.index.(searchindex.ObjectChecker)
+	if !ok {
+		return nil
+	}
+	deleteVerifications.Add(1)
+	var present bool
+	var err error
+	if op == OpDeleteEntry {
+		present, err = checker.EntryExists(ctx, actorID, id)
+	} else {
+		present, err = checker.ContextExists(ctx, actorID, id)
+	}
+	if err != nil {
+		return fmt.Errorf("verify %s: %w", op, err)
+	}
+	if present {
+		deleteVerifyFailures.Add(1)
+		return fmt.Errorf("%w: %s %s", errDeleteNotPropagated, op, id)
+	}
+	return nil
 }
 
-// embed wraps the embedder to keep callers simple.
-// Embedder is guaranteed to be non-nil after startup validation.
-func (w *Worker) embed(text string, ctx context.Context) ([]float32, error) {
-	return w.embedder.Embed(ctx, text)
+// markDone checkpoints a handled row; a row whose lease passed to another
+// worker is left alone, and that worker applies it again (ops are idempotent).
+func (w *Worker) markDone(ctx context.Context, id int64) error {
+	res, err := w.db.ExecContext(ctx, markDoneSQL, id, w.cfg.WorkerID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		leasesLost.Add(1)
+		w.log.Warn().Int64("id", id).Msg("outbox lease lost before checkpoint")
+	}
+	return nil
 }
 
-func stringField(m map[string]interface{}, key string) string {
-	if v, ok := m[key]; ok {
-		switch t := v.(type) {
-		case string:
-			return t
-		}
+// markFailed records the failure and reports whether the row was dead-lettered.
+func (w *Worker) markFailed(ctx context.Context, id int64, cause error) (bool, error) {
+	var status string
+	err := w.db.QueryRowContext(ctx, markFailedSQL, id, cause.Error(), w.cfg.MaxAttempts, w.cfg.WorkerID).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		leasesLost.Add(1)
+		return false, nil
+	}
+	if err != nil {
+		return false, err
 	}
-	return ""
+	if status == "dead" {
+		deadLettered.Add(1)
+		return true, nil
+	}
+	return false, nil
 }
 
-func preferredText(m map[string]interface{}, keys ...string) string {
-	for _, k := range keys {
-		if s := stringField(m, k); s != "" {
-			return s
-		}
+// DefaultWorkerID names a worker by host and process, unique enough for
+// outbox leases.
+func DefaultWorkerID() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "worker"
 	}
-	return ""
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), uuid.NewString()[:8])
 }
 
-// normalizeEntryTags ensures the payload["tags"] is a []string of tag keys
-// so that Weaviate schema (text[]) can store and filter via ContainsAny.
-func normalizeEntryTags(m map[string]interface{}) {
-	v, ok := m["tags"]
-	if !ok || v == nil {
-		return
-	}
-	switch t := v.(type) {
-	case map[string]interface{}:
-		keys := make([]string, 0, len(t))
-		for k, val := range t {
-			switch vv := val.(type) {
-			case bool:
-				if vv {
-					keys = append(keys, k)
-				}
-			case string:
-				if strings.EqualFold(vv, "true") {
-					keys = append(keys, k)
-				}
-			default:
-				// ignore non-bool marker values
-			}
-		}
-		m["tags"] = keys
-	case []interface{}:
-		// convert to []string
-		keys := make([]string, 0, len(t))
-		for _, it := range t {
-			if s, ok := it.(string); ok {
-				keys = append(keys, s)
-			}
-		}
-		m["tags"] = keys
-	}
+// embed wraps the embedder to keep callers simple.
+// Embedder is guaranteed to be non-nil after startup validation.
+func (w *Worker) embed(text string, ctx context.Context) ([]float32, error) {
+	return w.embedder.Embed(ctx, text)
 }
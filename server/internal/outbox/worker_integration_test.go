@@ -0,0 +1,238 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+
+	"github.com/mycelian/mycelian-memory/server/internal/outbox/payload"
+	"github.com/mycelian/mycelian-memory/server/internal/searchindex"
+	pgschema "github.com/mycelian/mycelian-memory/server/internal/storage/postgres"
+)
+
+type constEmbedder struct{}
+
+func (constEmbedder) Embed(context.Context, string) ([]float32, error) { return []float32{1}, nil }
+
+// countingIndex counts entry upserts per ID.
+type countingIndex struct {
+	searchindex.Index
+	mu      sync.Mutex
+	upserts map[string]int
+}
+
+func (c *countingIndex) UpsertEntry(_ context.Context, id string, _ []float32, _ map[string]interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.upserts[id]++
+	return nil
+}
+
+func TestWorkers_ShareOutboxWithoutDoubleIndexing(t *testing.T) {
+	dsn := os.Getenv("MEMORY_SERVER_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("MEMORY_SERVER_POSTGRES_DSN not set; skipping outbox lease integration test")
+	}
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		t.Fatalf("postgres open: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+	ctx := context.Background()
+	if err := pgschema.ApplySchema(ctx, db); err != nil {
+		t.Fatalf("apply schema: %v", err)
+	}
+
+	const rows = 60
+	job := uuid.NewString()
+	want := map[string]bool{}
+	for i := 0; i < rows; i++ {
+		id := fmt.Sprintf("%s-%d", job, i)
+		want[id] = true
+		body, _ := payload.Encode(&payload.Entry{ActorID: "a1", MemoryID: "m1", EntryID: id, RawEntry: "x"})
+		if _, err := db.ExecContext(ctx, `INSERT INTO outbox (aggregate_id, op, payload, job_id) VALUES ($1, $2, $3, $4)`, id, OpUpsertEntry, body, job); err != nil {
+			t.Fatalf("insert outbox row: %v", err)
+		}
+	}
+	defer func() { _, _ = db.ExecContext(ctx, `DELETE FROM outbox WHERE job_id=$1`, job) }()
+
+	idx := &countingIndex{upserts: map[string]int{}}
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		w := NewWorker(db, constEmbedder{}, idx, Config{BatchSize: 7, Lease: 30 * time.Second, WorkerID: fmt.Sprintf("w%d", i)}, zerolog.Nop())
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < rows; j++ {
+				if err := w.processOnce(ctx); err != nil {
+					t.Errorf("processOnce: %v", err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for id := range want {
+		if n := idx.upserts[id]; n != 1 {
+			t.Fatalf("row %s indexed %d times", id, n)
+		}
+	}
+	var pending int
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM outbox WHERE job_id=$1 AND (status<>'done' OR leased_until IS NOT NULL)`, job).Scan(&pending); err != nil || pending != 0 {
+		t.Fatalf("expected every row done with its lease cleared, got %d (err=%v)", pending, err)
+	}
+}
+
+func TestWorker_RejectsPayloads(t *testing.T) {
+	dsn := os.Getenv("MEMORY_SERVER_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("MEMORY_SERVER_POSTGRES_DSN not set; skipping outbox payload integration test")
+	}
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		t.Fatalf("postgres open: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+	ctx := context.Background()
+	if err := pgschema.ApplySchema(ctx, db); err != nil {
+		t.Fatalf("apply schema: %v", err)
+	}
+
+	job := uuid.NewString()
+	for suffix, body := range map[string]string{
+		"newer":   `{"v":99,"actorId":"a1","memoryId":"m1","entryId":"e1"}`,
+		"invalid": `{"rawEntry":"x"}`,
+	} {
+		if _, err := db.ExecContext(ctx, `INSERT INTO outbox (aggregate_id, op, payload, job_id) VALUES ($1, $2, $3, $4)`, job+"-"+suffix, OpUpsertEntry, body, job); err != nil {
+			t.Fatalf("insert outbox row: %v", err)
+		}
+	}
+	defer func() { _, _ = db.ExecContext(ctx, `DELETE FROM outbox WHERE job_id=$1`, job) }()
+
+	idx := &countingIndex{upserts: map[string]int{}}
+	w := NewWorker(db, constEmbedder{}, idx, Config{BatchSize: 1000, WorkerID: "payloads"}, zerolog.Nop())
+	if err := w.processOnce(ctx); err != nil {
+		t.Fatalf("processOnce: %v", err)
+	}
+	for id := range idx.upserts {
+		if strings.HasPrefix(id, job) {
+			t.Fatalf("rejected payload %s was indexed", id)
+		}
+	}
+	// A newer row waits without an attempt; an invalid one counts a failure.
+	for suffix, wantAttempts := range map[string]int{"newer": 0, "invalid": 1} {
+		var attempts int
+		var deferred bool
+		if err := db.QueryRowContext(ctx, `SELECT attempt_count, next_attempt_at > now() FROM outbox WHERE aggregate_id=$1 AND status='pending'`,
+			job+"-"+suffix).Scan(&attempts, &deferred); err != nil || attempts != wantAttempts || !deferred {
+			t.Fatalf("%s row: attempts=%d deferred=%v err=%v", suffix, attempts, deferred, err)
+		}
+	}
+}
+
+func TestWorker_ListenWakesOnInsert(t *testing.T) {
+	dsn := os.Getenv("MEMORY_SERVER_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("MEMORY_SERVER_POSTGRES_DSN not set; skipping outbox listen integration test")
+	}
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		t.Fatalf("postgres open: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := pgschema.ApplySchema(ctx, db); err != nil {
+		t.Fatalf("apply schema: %v", err)
+	}
+
+	// An hour-long poll interval: only the notification can wake the worker.
+	idx := &countingIndex{upserts: map[string]int{}}
+	w := NewWorker(db, constEmbedder{}, idx, Config{Interval: time.Hour, Listen: true, WorkerID: "listener"}, zerolog.Nop())
+	go func() { _ = w.Run(ctx) }()
+	time.Sleep(500 * time.Millisecond) // let it LISTEN
+
+	id := uuid.NewString()
+	body, _ := payload.Encode(&payload.Entry{ActorID: "a1", MemoryID: "m1", EntryID: id, RawEntry: "x"})
+	if _, err := db.ExecContext(ctx, `INSERT INTO outbox (aggregate_id, op, payload, job_id) VALUES ($1, $2, $3, $1)`, id, OpUpsertEntry, body); err != nil {
+		t.Fatalf("insert outbox row: %v", err)
+	}
+	defer func() { _, _ = db.ExecContext(context.Background(), `DELETE FROM outbox WHERE job_id=$1`, id) }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		idx.mu.Lock()
+		n := idx.upserts[id]
+		idx.mu.Unlock()
+		if n == 1 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("row not indexed within 5s of its notification")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestWorker_OrdersRenamesWithTheirMemorysUpserts(t *testing.T) {
+	dsn := os.Getenv("MEMORY_SERVER_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("MEMORY_SERVER_POSTGRES_DSN not set; skipping outbox rename ordering integration test")
+	}
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		t.Fatalf("postgres open: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+	ctx := context.Background()
+	if err := pgschema.ApplySchema(ctx, db); err != nil {
+		t.Fatalf("apply schema: %v", err)
+	}
+
+	memoryID := uuid.NewString()
+	before, _ := payload.Encode(&payload.Entry{ActorID: "a1", MemoryID: memoryID, EntryID: memoryID + "-before", RawEntry: "x", MemoryTitle: "old"})
+	rename, _ := payload.Encode(&payload.Rename{ActorID: "a1", MemoryID: memoryID, MemoryTitle: "new"})
+	after, _ := payload.Encode(&payload.Entry{ActorID: "a1", MemoryID: memoryID, EntryID: memoryID + "-after", RawEntry: "x", MemoryTitle: "new"})
+	for _, row := range []struct {
+		aggregate, op string
+		body          []byte
+	}{{memoryID + "-before", OpUpsertEntry, before}, {memoryID, OpRenameMemory, rename}, {memoryID + "-after", OpUpsertEntry, after}} {
+		if _, err := db.ExecContext(ctx, `INSERT INTO outbox (aggregate_id, op, payload, job_id) VALUES ($1, $2, $3, $4)`, row.aggregate, row.op, row.body, memoryID); err != nil {
+			t.Fatalf("insert outbox row: %v", err)
+		}
+	}
+	defer func() { _, _ = db.ExecContext(ctx, `DELETE FROM outbox WHERE job_id=$1`, memoryID) }()
+
+	// Each claim may only take the next of the three rows, however many
+	// workers ask.
+	w := NewWorker(db, constEmbedder{}, &countingIndex{upserts: map[string]int{}}, Config{BatchSize: 1000, WorkerID: "renames"}, zerolog.Nop())
+	for _, want := range []string{memoryID + "-before", memoryID, memoryID + "-after"} {
+		jobs, err := w.claimBatch(ctx, 1000)
+		if err != nil {
+			t.Fatalf("claimBatch: %v", err)
+		}
+		var claimed []string
+		for _, j := range jobs {
+			if strings.HasPrefix(j.aggregateID, memoryID) {
+				claimed = append(claimed, j.aggregateID)
+				if err := w.markDone(ctx, j.id); err != nil {
+					t.Fatalf("markDone: %v", err)
+				}
+			} else {
+				w.release([]job{j})
+			}
+		}
+		if len(claimed) != 1 || claimed[0] != want {
+			t.Fatalf("claimed %v, want only %s", claimed, want)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"github.com/mycelian/mycelian-memory/server/internal/outbox/payload"
+	"github.com/mycelian/mycelian-memory/server/internal/searchindex"
+)
+
+func TestGuard_RecoversPanic(t *testing.T) {
+	w := &Worker{log: zerolog.Nop()}
+	before := panicsRecovered.Value()
+
+	err := w.guard(OpUpsertEntry, func() error {
+		var m map[string]int
+		m["x"] = 1 // nil map write panics
+		return nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "panic in upsert_entry") {
+		t.Fatalf("expected panic converted to error, got %v", err)
+	}
+	if panicsRecovered.Value() != before+1 {
+		t.Fatalf("expected panic counter to increment")
+	}
+
+	want := errors.New("plain")
+	if err := w.guard(OpDeleteEntry, func() error { return want }); err != want {
+		t.Fatalf("expected plain error passthrough, got %v", err)
+	}
+}
+
+// lingeringIndex ignores deletes of the IDs in stuck, as an index whose
+// delete silently failed would.
+type lingeringIndex struct {
+	searchindex.Index
+	stuck map[string]bool
+}
+
+func (l lingeringIndex) DeleteEntry(context.Context, string, string) error   { return nil }
+func (l lingeringIndex) DeleteContext(context.Context, string, string) error { return nil }
+func (l lingeringIndex) EntryExists(_ context.Context, _, id string) (bool, error) {
+	return l.stuck[id], nil
+}
+func (l lingeringIndex) ContextExists(_ context.Context, _, id string) (bool, error) {
+	return l.stuck[id], nil
+}
+
+func TestHandle_VerifiesDeletes(t *testing.T) {
+	w := &Worker{log: zerolog.Nop(), index: lingeringIndex{stuck: map[string]bool{"e-stuck": true}}}
+	ctx := context.Background()
+	checks, failures := deleteVerifications.Value(), deleteVerifyFailures.Value()
+
+	if err := w.handle(ctx, job{op: OpDeleteEntry, aggregateID: "e-gone", payload: payload.DeleteEntry("a1")}); err != nil {
+		t.Fatalf("delete of a removed entry: %v", err)
+	}
+	err := w.handle(ctx, job{op: OpDeleteEntry, aggregateID: "e-stuck", payload: payload.DeleteEntry("a1")})
+	if !errors.Is(err, errDeleteNotPropagated) {
+		t.Fatalf("expected errDeleteNotPropagated, got %v", err)
+	}
+	if err := w.handle(ctx, job{op: OpDeleteContext, aggregateID: "c1", payload: payload.DeleteContext("a1")}); err != nil {
+		t.Fatalf("delete of a removed context: %v", err)
+	}
+	if got := deleteVerifications.Value() - checks; got != 3 {
+		t.Fatalf("expected 3 verifications, got %d", got)
+	}
+	if got := deleteVerifyFailures.Value() - failures; got != 1 {
+		t.Fatalf("expected 1 verification failure, got %d", got)
+	}
+}
+
+// renamingIndex records title updates.
+type renamingIndex struct {
+	searchindex.Index
+	calls []string
+}
+
+func (r *renamingIndex) UpdateTitles(_ context.Context, actorID, memoryID, memoryTitle, vaultTitle string) error {
+	r.calls = append(r.calls, strings.Join([]string{actorID, memoryID, memoryTitle, vaultTitle}, "|"))
+	return nil
+}
+
+func TestHandle_RenameMemory(t *testing.T) {
+	idx := &renamingIndex{}
+	w := &Worker{log: zerolog.Nop(), index: idx}
+	ctx := context.Background()
+	if err := w.handle(ctx, job{op: OpRenameMemory, aggregateID: "m1", payload: &payload.Rename{ActorID: "a1", MemoryID: "m1", MemoryTitle: "notes"}}); err != nil {
+		t.Fatalf("rename memory: %v", err)
+	}
+	if err := w.handle(ctx, job{op: OpRenameMemory, aggregateID: "m1", payload: &payload.Rename{ActorID: "a1", MemoryID: "m1", VaultTitle: "work"}}); err != nil {
+		t.Fatalf("rename vault: %v", err)
+	}
+	if len(idx.calls) != 2 || idx.calls[0] != "a1|m1|notes|" || idx.calls[1] != "a1|m1||work" {
+		t.Fatalf("unexpected title updates: %q", idx.calls)
+	}
+
+	// Indexes without title support complete the row without doing anything.
+	w.index = lingeringIndex{}
+	if err := w.handle(ctx, job{op: OpRenameMemory, aggregateID: "m1", payload: &payload.Rename{ActorID: "a1", MemoryTitle: "notes"}}); err != nil {
+		t.Fatalf("rename on index without titles: %v", err)
+	}
+}
+
+// recordingIndex keeps the properties of the last entry upsert.
+type recordingIndex struct {
+	searchindex.Index
+	props map[string]interface{}
+}
+
+func (r *recordingIndex) UpsertEntry(_ context.Context, _ string, _ []float32, props map[string]interface{}) error {
+	r.props = props
+	return nil
+}
+
+type textEmbedder struct{ texts []string }
+
+func (e *textEmbedder) Embed(_ context.Context, text string) ([]float32, error) {
+	e.texts = append(e.texts, text)
+	return []float32{1}, nil
+}
+
+func TestHandle_UpsertEntryProperties(t *testing.T) {
+	idx, emb := &recordingIndex{}, &textEmbedder{}
+	w := &Worker{log: zerolog.Nop(), index: idx, embedder: emb}
+	// A row written before payloads were versioned.
+	p, err := payload.Decode(OpUpsertEntry, []byte(`{"actorId":"a1","memoryId":"m1","entryId":"e1","rawEntry":"raw","summary":"sum","tags":{"t":true}}`))
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if err := w.handle(context.Background(), job{op: OpUpsertEntry, aggregateID: "e1", payload: p}); err != nil {
+		t.Fatalf("handle: %v", err)
+	}
+	if len(emb.texts) != 1 || emb.texts[0] != "sum" {
+		t.Fatalf("embedded %q, want the summary", emb.texts)
+	}
+	if _, ok := idx.props["v"]; ok || idx.props["entryId"] != "e1" || !reflect.DeepEqual(idx.props["tags"], []string{"t"}) {
+		t.Fatalf("unexpected index properties: %v", idx.props)
+	}
+}
@@ -0,0 +1,60 @@
+package reranker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Cohere API defaults.
+const (
+	DefaultCohereURL   = "https://api.cohere.com/v2"
+	DefaultCohereModel = "rerank-v3.5"
+)
+
+// Cohere reranks with the rerank API of Cohere.
+type Cohere struct {
+	baseURL string
+	apiKey  string
+	model   string
+	http    *http.Client
+}
+
+// NewCohere returns a reranker calling model (DefaultCohereModel when
+// empty) at baseURL (DefaultCohereURL when empty).
+func NewCohere(baseURL, apiKey, model string) *Cohere {
+	if baseURL == "" {
+		baseURL = DefaultCohereURL
+	}
+	if model == "" {
+		model = DefaultCohereModel
+	}
+	return &Cohere{baseURL: strings.TrimRight(baseURL, "/"), apiKey: apiKey, model: model, http: &http.Client{Timeout: 30 * time.Second}}
+}
+
+func (c *Cohere) Rerank(ctx context.Context, query string, documents []string) ([]float64, error) {
+	if len(documents) == 0 {
+		return nil, nil
+	}
+	var out struct {
+		Results []rankedDocument `json:"results"`
+	}
+	err := postJSON(ctx, c.http, c.baseURL+"/rerank", c.apiKey, "cohere", struct {
+		Model     string   `json:"model"`
+		Query     string   `json:"query"`
+		Documents []string `json:"documents"`
+		TopN      int      `json:"top_n"`
+	}{c.model, query, documents, len(documents)}, &out, func(b []byte) string {
+		var e struct {
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(b, &e)
+		return e.Message
+	})
+	if err != nil {
+		return nil, err
+	}
+	return scoresByIndex(out.Results, len(documents))
+}
@@ -0,0 +1,128 @@
+package reranker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultOllamaModel is the local model judging relevance when none is
+// configured.
+const DefaultOllamaModel = "llama3.2"
+
+// ollamaMaxDocRunes bounds the part of each document sent to the model;
+// all candidates of a search share one prompt.
+const ollamaMaxDocRunes = 500
+
+const ollamaPrompt = `You judge whether stored memories help answer a query.
+Query: %s
+
+Memories:
+%s
+Reply with one relevance score per memory, in the order listed, from 0
+(unrelated) to 1 (answers the query).`
+
+// Ollama reranks with a model served by Ollama at OLLAMA_URL, which reads
+// the query and the documents together and scores their relevance, as a
+// cross-encoder does. All documents are judged in one generate call, so a
+// search costs one model call; that still takes seconds on a CPU, so raise
+// the rerank timeout above its default for local models.
+type Ollama struct {
+	model     string
+	keepAlive string
+	http      *http.Client
+}
+
+// NewOllama returns a reranker using model (DefaultOllamaModel when empty);
+// keepAlive is passed to Ollama as for the embedder.
+func NewOllama(model, keepAlive string) *Ollama {
+	if model == "" {
+		model = DefaultOllamaModel
+	}
+	return &Ollama{model: model, keepAlive: keepAlive, http: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// Rerank asks the model for the scores of all documents at once,
+// constrained to a JSON object holding exactly one score per document so
+// the reply parses.
+func (o *Ollama) Rerank(ctx context.Context, query string, documents []string) ([]float64, error) {
+	if len(documents) == 0 {
+		return nil, nil
+	}
+	base := os.Getenv("OLLAMA_URL")
+	if base == "" {
+		base = "http://localhost:11434"
+	}
+	if !strings.HasPrefix(base, "http://") && !strings.HasPrefix(base, "https://") {
+		base = "http://" + base
+	}
+	var list strings.Builder
+	for i, doc := range documents {
+		if r := []rune(doc); len(r) > ollamaMaxDocRunes {
+			doc = string(r[:ollamaMaxDocRunes])
+		}
+		fmt.Fprintf(&list, "[%d] %s\n", i+1, strings.Join(strings.Fields(doc), " "))
+	}
+	body, _ := json.Marshal(struct {
+		Model     string         `json:"model"`
+		Prompt    string         `json:"prompt"`
+		Stream    bool           `json:"stream"`
+		Format    any            `json:"format"`
+		Options   map[string]any `json:"options"`
+		KeepAlive string         `json:"keep_alive,omitempty"`
+	}{
+		Model:  o.model,
+		Prompt: fmt.Sprintf(ollamaPrompt, query, list.String()),
+		Format: map[string]any{
+			"type": "object",
+			"properties": map[string]any{"scores": map[string]any{
+				"type": "array", "items": map[string]string{"type": "number"},
+				"minItems": len(documents), "maxItems": len(documents),
+			}},
+			"required": []string{"scores"},
+		},
+		Options:   map[string]any{"temperature": 0},
+		KeepAlive: o.keepAlive,
+	})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, base+"/api/generate", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := o.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("ollama rerank status %d", resp.StatusCode)
+	}
+	var out struct {
+		Response string `json:"response"`
+		Error    string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	if out.Error != "" {
+		return nil, fmt.Errorf("ollama rerank error: %s", out.Error)
+	}
+	var judged struct {
+		Scores []float64 `json:"scores"`
+	}
+	if err := json.Unmarshal([]byte(out.Response), &judged); err != nil {
+		return nil, fmt.Errorf("ollama rerank reply %q: %w", out.Response, err)
+	}
+	if len(judged.Scores) != len(documents) {
+		return nil, fmt.Errorf("ollama rerank returned %d scores for %d documents", len(judged.Scores), len(documents))
+	}
+	for i, s := range judged.Scores {
+		judged.Scores[i] = min(max(s, 0), 1)
+	}
+	return judged.Scores, nil
+}
@@ -0,0 +1,72 @@
+// Package reranker rescores search hits against the query with a model
+// that reads query and entry together, which orders candidates better than
+// the index's hybrid score. Providers are a local model served by Ollama
+// and the Cohere and Voyage rerank APIs.
+package reranker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Reranker scores documents for relevance to query. Scores are returned in
+// the order of documents, higher meaning more relevant; their scale is the
+// provider's, usually 0 to 1.
+type Reranker interface {
+	Rerank(ctx context.Context, query string, documents []string) ([]float64, error)
+}
+
+// rankedDocument is one element of the results of the Cohere and Voyage
+// rerank APIs.
+type rankedDocument struct {
+	Index          int     `json:"index"`
+	RelevanceScore float64 `json:"relevance_score"`
+}
+
+// scoresByIndex puts the scores of ranked back into document order.
+func scoresByIndex(ranked []rankedDocument, n int) ([]float64, error) {
+	if len(ranked) != n {
+		return nil, fmt.Errorf("reranker returned %d scores for %d documents", len(ranked), n)
+	}
+	scores := make([]float64, n)
+	for _, r := range ranked {
+		if r.Index < 0 || r.Index >= n {
+			return nil, fmt.Errorf("reranker returned document index %d of %d", r.Index, n)
+		}
+		scores[r.Index] = r.RelevanceScore
+	}
+	return scores, nil
+}
+
+// postJSON sends body to url with apiKey as bearer token and decodes a 2xx
+// reply into out. errMsg extracts the provider's error message from the
+// reply of a failed call.
+func postJSON(ctx context.Context, client *http.Client, url, apiKey, provider string, body, out any, errMsg func([]byte) string) error {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var raw bytes.Buffer
+		_, _ = raw.ReadFrom(resp.Body)
+		if msg := errMsg(raw.Bytes()); msg != "" {
+			return fmt.Errorf("%s rerank status %d: %s", provider, resp.StatusCode, msg)
+		}
+		return fmt.Errorf("%s rerank status %d", provider, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,69 @@
+package reranker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCohereScoresInDocumentOrder(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Model     string   `json:"model"`
+			Documents []string `json:"documents"`
+			TopN      int      `json:"top_n"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if r.URL.Path != "/rerank" || r.Header.Get("Authorization") != "Bearer key" || body.Model != DefaultCohereModel || body.TopN != 2 {
+			t.Errorf("unexpected request %s %+v", r.URL.Path, body)
+		}
+		_, _ = w.Write([]byte(`{"results":[{"index":1,"relevance_score":0.9},{"index":0,"relevance_score":0.2}]}`))
+	}))
+	defer srv.Close()
+
+	scores, err := NewCohere(srv.URL, "key", "").Rerank(context.Background(), "q", []string{"a", "b"})
+	if err != nil || len(scores) != 2 || scores[0] != 0.2 || scores[1] != 0.9 {
+		t.Fatalf("Rerank: %v %v", scores, err)
+	}
+}
+
+func TestVoyageReportsProviderError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"detail":"invalid key"}`))
+	}))
+	defer srv.Close()
+
+	_, err := NewVoyage(srv.URL, "bad", "").Rerank(context.Background(), "q", []string{"a"})
+	if err == nil || !strings.Contains(err.Error(), "invalid key") {
+		t.Fatalf("expected the provider's message, got %v", err)
+	}
+}
+
+func TestOllamaScoresAllDocumentsInOneCall(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		var body struct {
+			Prompt string `json:"prompt"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if calls == 1 && !(strings.Contains(body.Prompt, "[1] likes tea") && strings.Contains(body.Prompt, "[2] Lisbon")) {
+			t.Errorf("prompt does not list the documents: %q", body.Prompt)
+		}
+		_, _ = w.Write([]byte(`{"response":"{\"scores\": [0.1, 1.5]}"}`))
+	}))
+	defer srv.Close()
+	t.Setenv("OLLAMA_URL", srv.URL)
+
+	scores, err := NewOllama("", "").Rerank(context.Background(), "where does the user live?", []string{"likes tea", "Lisbon"})
+	if err != nil || calls != 1 || len(scores) != 2 || scores[0] != 0.1 || scores[1] != 1 {
+		t.Fatalf("Rerank: %v %v (calls %d)", scores, err, calls)
+	}
+	if _, err := NewOllama("", "").Rerank(context.Background(), "q", []string{"a", "b", "c"}); err == nil {
+		t.Fatal("expected an error for a reply with too few scores")
+	}
+}
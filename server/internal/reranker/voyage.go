@@ -0,0 +1,59 @@
+package reranker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Voyage API defaults.
+const (
+	DefaultVoyageURL   = "https://api.voyageai.com/v1"
+	DefaultVoyageModel = "rerank-2"
+)
+
+// Voyage reranks with the rerank API of Voyage AI.
+type Voyage struct {
+	baseURL string
+	apiKey  string
+	model   string
+	http    *http.Client
+}
+
+// NewVoyage returns a reranker calling model (DefaultVoyageModel when
+// empty) at baseURL (DefaultVoyageURL when empty).
+func NewVoyage(baseURL, apiKey, model string) *Voyage {
+	if baseURL == "" {
+		baseURL = DefaultVoyageURL
+	}
+	if model == "" {
+		model = DefaultVoyageModel
+	}
+	return &Voyage{baseURL: strings.TrimRight(baseURL, "/"), apiKey: apiKey, model: model, http: &http.Client{Timeout: 30 * time.Second}}
+}
+
+func (v *Voyage) Rerank(ctx context.Context, query string, documents []string) ([]float64, error) {
+	if len(documents) == 0 {
+		return nil, nil
+	}
+	var out struct {
+		Data []rankedDocument `json:"data"`
+	}
+	err := postJSON(ctx, v.http, v.baseURL+"/rerank", v.apiKey, "voyage", struct {
+		Model     string   `json:"model"`
+		Query     string   `json:"query"`
+		Documents []string `json:"documents"`
+	}{v.model, query, documents}, &out, func(b []byte) string {
+		var e struct {
+			Detail string `json:"detail"`
+		}
+		_ = json.Unmarshal(b, &e)
+		return e.Detail
+	})
+	if err != nil {
+		return nil, err
+	}
+	return scoresByIndex(out.Data, len(documents))
+}
@@ -14,7 +14,9 @@ type Embeddings interface {
 
 // Index provides vector search and index maintenance.
 type Index interface {
-	Search(ctx context.Context, actorID, memoryID, query string, vec []float32, topK int, alpha float32) ([]model.SearchHit, error)
+	// Search returns up to topK entries of the memory that match filter;
+	// filtering is applied before the limit.
+	Search(ctx context.Context, actorID, memoryID, query string, vec []float32, topK int, alpha float32, filter model.SearchFilter) ([]model.SearchHit, error)
 	LatestContext(ctx context.Context, actorID, memoryID string) (text string, ts time.Time, err error)
 	BestContext(ctx context.Context, actorID, memoryID, query string, vec []float32, alpha float32) (best string, ts time.Time, score float64, err error)
 
@@ -34,3 +36,40 @@ type Index interface {
 type HealthPinger interface {
 	HealthPing(ctx context.Context) error
 }
+
+// ObjectCounter is optionally implemented by an Index to report how many
+// entries and contexts it holds for one actor's memory.
+type ObjectCounter interface {
+	CountObjects(ctx context.Context, actorID, memoryID string) (model.ObjectCounts, error)
+}
+
+// VectorReader is optionally implemented by an Index to return the stored
+// embeddings of one actor's memory entries, keyed by entryID. Entries the
+// index does not hold are omitted.
+type VectorReader interface {
+	EntryVectors(ctx context.Context, actorID, memoryID string, entryIDs []string) (map[string][]float32, error)
+}
+
+// ObjectChecker is optionally implemented by an Index to report whether it
+// still holds an entry or context object, e.g. to verify that a delete took
+// effect.
+type ObjectChecker interface {
+	EntryExists(ctx context.Context, actorID, entryID string) (bool, error)
+	ContextExists(ctx context.Context, actorID, contextID string) (bool, error)
+}
+
+// NewestEntryReader is optionally implemented by an Index to report the
+// creation time of the newest entry it holds for one actor's memory, or the
+// zero time when it holds none. Compared with the database it shows how far
+// indexing trails the writes.
+type NewestEntryReader interface {
+	NewestEntryTime(ctx context.Context, actorID, memoryID string) (time.Time, error)
+}
+
+// TitleUpdater is optionally implemented by an Index whose objects carry the
+// titles of their memory and vault ("memoryTitle" and "vaultTitle") to
+// rewrite them on all of one actor's memory objects after a rename. An empty
+// title is left unchanged.
+type TitleUpdater interface {
+	UpdateTitles(ctx context.Context, actorID, memoryID, memoryTitle, vaultTitle string) error
+}
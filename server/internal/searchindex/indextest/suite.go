@@ -0,0 +1,366 @@
+// Package indextest is the contract suite for searchindex.Index adapters.
+// Every adapter (Weaviate today, pgvector or Qdrant later) must pass it so
+// that filters are pushed down correctly and no tenant can read or delete
+// another tenant's data.
+package indextest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/searchindex"
+)
+
+// settle bounds how long the suite waits for writes and deletes to become
+// visible in adapters with near-real-time indexing.
+const settle = 5 * time.Second
+
+// Run exercises the index contract against a clean index returned by
+// makeIndex. dim is the vector dimension the index accepts.
+func Run(t *testing.T, makeIndex func(t *testing.T) searchindex.Index, dim int) {
+	t.Helper()
+	idx := makeIndex(t)
+	ctx := context.Background()
+
+	// Two tenants that share a memory ID: a leak through a memoryId-only
+	// filter would show up as cross-tenant hits.
+	actorA := "actor-a-" + uuid.NewString()
+	actorB := "actor-b-" + uuid.NewString()
+	shared := uuid.NewString()
+	otherMem := uuid.NewString()
+	vaultID := uuid.NewString()
+	vec := unitVector(dim)
+
+	putSessionEntry := func(actorID, memoryID, sessionID, text string, tags ...string) string {
+		t.Helper()
+		id := uuid.NewString()
+		payload := map[string]interface{}{
+			"entryId": id, "actorId": actorID, "memoryId": memoryID, "vaultId": vaultID,
+			"rawEntry": text, "summary": text, "creationTime": time.Now().UTC(),
+		}
+		if len(tags) > 0 {
+			payload["tags"] = tags
+			pairs := make([]string, len(tags))
+			for i, tag := range tags {
+				pairs[i] = tag + "=true"
+			}
+			payload["tagPairs"] = pairs
+		}
+		if sessionID != "" {
+			payload["sessionId"] = sessionID
+		}
+		if err := idx.UpsertEntry(ctx, id, vec, payload); err != nil {
+			t.Fatalf("UpsertEntry: %v", err)
+		}
+		return id
+	}
+	putEntry := func(actorID, memoryID, text string, tags ...string) string {
+		t.Helper()
+		return putSessionEntry(actorID, memoryID, "", text, tags...)
+	}
+	putContext := func(actorID, memoryID, text string, at time.Time) string {
+		t.Helper()
+		id := uuid.NewString()
+		payload := map[string]interface{}{
+			"contextId": id, "actorId": actorID, "memoryId": memoryID,
+			"context": text, "creationTime": at.UTC(),
+		}
+		if err := idx.UpsertContext(ctx, id, vec, payload); err != nil {
+			t.Fatalf("UpsertContext: %v", err)
+		}
+		return id
+	}
+	searchFiltered := func(actorID, memoryID string, topK int, filter model.SearchFilter) []model.SearchHit {
+		t.Helper()
+		hits, err := idx.Search(ctx, actorID, memoryID, "mango", vec, topK, 0.5, filter)
+		if err != nil {
+			t.Fatalf("Search: %v", err)
+		}
+		return hits
+	}
+	searchExcluding := func(actorID, memoryID string, topK int, mustNot model.SearchMustNot) []model.SearchHit {
+		t.Helper()
+		return searchFiltered(actorID, memoryID, topK, model.SearchFilter{MustNot: mustNot})
+	}
+	search := func(actorID, memoryID string, topK int) []model.SearchHit {
+		t.Helper()
+		return searchFiltered(actorID, memoryID, topK, model.SearchFilter{})
+	}
+
+	var aEntries []string
+	for i := 0; i < 4; i++ {
+		aEntries = append(aEntries, putEntry(actorA, shared, fmt.Sprintf("mango note %d from A", i)))
+	}
+	bEntry := putEntry(actorB, shared, "mango note from B")
+	otherEntry := putEntry(actorA, otherMem, "mango note in another memory")
+	taggedMem := uuid.NewString()
+	seenEntry := putEntry(actorA, taggedMem, "mango note already seen", "seen", "fruit")
+	freshEntry := putEntry(actorA, taggedMem, "mango note not seen yet", "fruit")
+	sessionMem := uuid.NewString()
+	session1 := "session one" // contains a space: must match whole, not per word
+	s1Entry := putSessionEntry(actorA, sessionMem, session1, "mango note in session one")
+	putSessionEntry(actorA, sessionMem, "session two", "mango note in session two")
+	putEntry(actorA, sessionMem, "mango note outside any session")
+	now := time.Now()
+	putContext(actorA, shared, "A old context", now.Add(-time.Hour))
+	aLatest := putContext(actorA, shared, "A latest context", now)
+	putContext(actorB, shared, "B context", now.Add(time.Hour))
+
+	eventually(t, "entries visible", func() error {
+		if n := len(search(actorA, shared, 10)); n != len(aEntries) {
+			return fmt.Errorf("actor A sees %d entries, want %d", n, len(aEntries))
+		}
+		if n := len(search(actorA, taggedMem, 10)); n != 2 {
+			return fmt.Errorf("actor A sees %d tagged entries, want 2", n)
+		}
+		if n := len(search(actorA, sessionMem, 10)); n != 3 {
+			return fmt.Errorf("actor A sees %d session entries, want 3", n)
+		}
+		return nil
+	})
+
+	t.Run("TenantIsolation", func(t *testing.T) {
+		for _, h := range search(actorA, shared, 10) {
+			if h.ActorID != actorA || h.EntryID == bEntry {
+				t.Fatalf("actor A received %+v", h)
+			}
+		}
+		hits := search(actorB, shared, 10)
+		if len(hits) != 1 || hits[0].EntryID != bEntry {
+			t.Fatalf("actor B: got %+v, want only its own entry", hits)
+		}
+		if hits := search("actor-nobody-"+uuid.NewString(), shared, 10); len(hits) != 0 {
+			t.Fatalf("unknown actor received %d hits", len(hits))
+		}
+		text, _, err := idx.LatestContext(ctx, actorA, shared)
+		if err != nil || text != "A latest context" {
+			t.Fatalf("LatestContext A: %q err=%v (B's newer context must not leak)", text, err)
+		}
+		best, _, _, err := idx.BestContext(ctx, actorB, shared, "context", vec, 0.5)
+		if err != nil || best != "B context" {
+			t.Fatalf("BestContext B: %q err=%v", best, err)
+		}
+	})
+
+	t.Run("MemoryFilter", func(t *testing.T) {
+		for _, h := range search(actorA, shared, 10) {
+			if h.MemoryID != shared || h.EntryID == otherEntry {
+				t.Fatalf("hit from another memory: %+v", h)
+			}
+		}
+		hits := search(actorA, otherMem, 10)
+		if len(hits) != 1 || hits[0].EntryID != otherEntry {
+			t.Fatalf("other memory: got %+v", hits)
+		}
+	})
+
+	t.Run("TopK", func(t *testing.T) {
+		for _, k := range []int{1, 2, 3} {
+			if n := len(search(actorA, shared, k)); n != k {
+				t.Fatalf("topK=%d returned %d hits", k, n)
+			}
+		}
+	})
+
+	t.Run("CreationTime", func(t *testing.T) {
+		for _, h := range search(actorA, shared, 10) {
+			if h.CreationTime == nil || h.CreationTime.IsZero() {
+				t.Fatalf("hit %s has no creationTime", h.EntryID)
+			}
+		}
+	})
+
+	t.Run("SessionFilter", func(t *testing.T) {
+		hits := searchFiltered(actorA, sessionMem, 10, model.SearchFilter{SessionID: session1})
+		if len(hits) != 1 || hits[0].EntryID != s1Entry {
+			t.Fatalf("session filter: got %+v, want only %s", hits, s1Entry)
+		}
+		if hits := searchFiltered(actorA, sessionMem, 10, model.SearchFilter{SessionID: "session"}); len(hits) != 0 {
+			t.Fatalf("partial session ID matched: %+v", hits)
+		}
+		if hits := searchFiltered(actorB, sessionMem, 10, model.SearchFilter{SessionID: session1}); len(hits) != 0 {
+			t.Fatalf("actor B received session hits: %+v", hits)
+		}
+	})
+
+	t.Run("TimeRange", func(t *testing.T) {
+		hourAgo, inAnHour := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+		if hits := searchFiltered(actorA, shared, 10, model.SearchFilter{Since: &hourAgo, Until: &inAnHour}); len(hits) != len(aEntries) {
+			t.Fatalf("time range: got %d hits, want %d", len(hits), len(aEntries))
+		}
+		if hits := searchFiltered(actorA, shared, 10, model.SearchFilter{Since: &inAnHour}); len(hits) != 0 {
+			t.Fatalf("since the future: got %+v, want none", hits)
+		}
+		if hits := searchFiltered(actorA, shared, 10, model.SearchFilter{Until: &hourAgo}); len(hits) != 0 {
+			t.Fatalf("until an hour ago: got %+v, want none", hits)
+		}
+	})
+
+	t.Run("Tags", func(t *testing.T) {
+		hits := searchFiltered(actorA, taggedMem, 10, model.SearchFilter{Tags: map[string]string{"seen": "true"}})
+		if len(hits) != 1 || hits[0].EntryID != seenEntry {
+			t.Fatalf("tag filter: got %+v, want only %s", hits, seenEntry)
+		}
+		if hits := searchFiltered(actorA, taggedMem, 10, model.SearchFilter{Tags: map[string]string{"fruit": "true"}}); len(hits) != 2 {
+			t.Fatalf("tag filter: got %+v, want both tagged entries", hits)
+		}
+		if hits := searchFiltered(actorA, taggedMem, 10, model.SearchFilter{Tags: map[string]string{"fruit": "true", "seen": "false"}}); len(hits) != 0 {
+			t.Fatalf("tag filter with a wrong value: got %+v, want none", hits)
+		}
+	})
+
+	t.Run("MustNot", func(t *testing.T) {
+		hits := searchExcluding(actorA, taggedMem, 10, model.SearchMustNot{Tags: []string{"seen"}})
+		if len(hits) != 1 || hits[0].EntryID != freshEntry {
+			t.Fatalf("tag exclusion: got %+v, want only %s", hits, freshEntry)
+		}
+		if hits := searchExcluding(actorA, taggedMem, 10, model.SearchMustNot{Tags: []string{"fruit"}}); len(hits) != 0 {
+			t.Fatalf("tag exclusion: got %+v, want none", hits)
+		}
+		hits = searchExcluding(actorA, taggedMem, 10, model.SearchMustNot{EntryIDs: []string{freshEntry}})
+		if len(hits) != 1 || hits[0].EntryID != seenEntry {
+			t.Fatalf("entry exclusion: got %+v, want only %s", hits, seenEntry)
+		}
+		if hits := searchExcluding(actorA, taggedMem, 10, model.SearchMustNot{MemoryIDs: []string{taggedMem}}); len(hits) != 0 {
+			t.Fatalf("memory exclusion: got %+v, want none", hits)
+		}
+
+		// Exclusions apply before topK, so excluded entries do not eat the limit.
+		cited := model.SearchMustNot{EntryIDs: aEntries[:2]}
+		hits = searchExcluding(actorA, shared, 2, cited)
+		if len(hits) != 2 {
+			t.Fatalf("topK with exclusions returned %d hits, want 2", len(hits))
+		}
+		for _, h := range hits {
+			if h.EntryID == aEntries[0] || h.EntryID == aEntries[1] {
+				t.Fatalf("excluded entry returned: %+v", h)
+			}
+		}
+	})
+
+	t.Run("CountObjects", func(t *testing.T) {
+		counter, ok := idx.(searchindex.ObjectCounter)
+		if !ok {
+			t.Skip("index does not implement ObjectCounter")
+		}
+		want := map[string]model.ObjectCounts{
+			actorA + "/" + shared:     {Entries: int64(len(aEntries)), Contexts: 2},
+			actorB + "/" + shared:     {Entries: 1, Contexts: 1},
+			actorA + "/" + sessionMem: {Entries: 3},
+			actorB + "/" + sessionMem: {},
+		}
+		for key, w := range want {
+			actorID, memoryID, _ := strings.Cut(key, "/")
+			if got, err := counter.CountObjects(ctx, actorID, memoryID); err != nil || got != w {
+				t.Fatalf("CountObjects(%s): got %+v err=%v, want %+v", key, got, err, w)
+			}
+		}
+	})
+
+	t.Run("EntryVectors", func(t *testing.T) {
+		reader, ok := idx.(searchindex.VectorReader)
+		if !ok {
+			t.Skip("index does not implement VectorReader")
+		}
+		got, err := reader.EntryVectors(ctx, actorA, shared, []string{aEntries[0], aEntries[1], bEntry, otherEntry})
+		if err != nil {
+			t.Fatalf("EntryVectors: %v", err)
+		}
+		if len(got) != 2 {
+			t.Fatalf("expected vectors of actor A's two entries only, got %d", len(got))
+		}
+		for _, id := range aEntries[:2] {
+			if v := got[id]; len(v) != dim || v[0] != vec[0] {
+				t.Fatalf("vector of %s = %v, want %v", id, v, vec)
+			}
+		}
+	})
+
+	t.Run("DeletePropagation", func(t *testing.T) {
+		if err := idx.DeleteEntry(ctx, actorA, aEntries[0]); err != nil {
+			t.Fatalf("DeleteEntry: %v", err)
+		}
+		eventually(t, "entry delete", func() error {
+			for _, h := range search(actorA, shared, 10) {
+				if h.EntryID == aEntries[0] {
+					return fmt.Errorf("deleted entry %s still returned", h.EntryID)
+				}
+			}
+			return nil
+		})
+
+		if err := idx.DeleteContext(ctx, actorA, aLatest); err != nil {
+			t.Fatalf("DeleteContext: %v", err)
+		}
+		eventually(t, "context delete", func() error {
+			text, _, err := idx.LatestContext(ctx, actorA, shared)
+			if err != nil {
+				return err
+			}
+			if text != "A old context" {
+				return fmt.Errorf("latest context %q, want the older snapshot", text)
+			}
+			return nil
+		})
+
+		// Deleting B's memory must leave A's memory with the same ID intact.
+		if err := idx.DeleteMemory(ctx, actorB, shared); err != nil {
+			t.Fatalf("DeleteMemory B: %v", err)
+		}
+		eventually(t, "memory delete", func() error {
+			if n := len(search(actorB, shared, 10)); n != 0 {
+				return fmt.Errorf("actor B still sees %d entries", n)
+			}
+			if text, _, err := idx.LatestContext(ctx, actorB, shared); err != nil || text != "" {
+				return fmt.Errorf("actor B context %q err=%v", text, err)
+			}
+			return nil
+		})
+		if n := len(search(actorA, shared, 10)); n != len(aEntries)-1 {
+			t.Fatalf("deleting B's memory removed A's entries: %d left", n)
+		}
+
+		if err := idx.DeleteMemory(ctx, actorA, shared); err != nil {
+			t.Fatalf("DeleteMemory A: %v", err)
+		}
+		eventually(t, "memory delete A", func() error {
+			if n := len(search(actorA, shared, 10)); n != 0 {
+				return fmt.Errorf("actor A still sees %d entries", n)
+			}
+			return nil
+		})
+		if n := len(search(actorA, otherMem, 10)); n != 1 {
+			t.Fatalf("deleting one memory removed entries of another: %d left", n)
+		}
+	})
+}
+
+// eventually polls check until it passes or settle elapses.
+func eventually(t *testing.T, what string, check func() error) {
+	t.Helper()
+	deadline := time.Now().Add(settle)
+	for {
+		err := check()
+		if err == nil {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%s: %v", what, err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func unitVector(dim int) []float32 {
+	v := make([]float32, dim)
+	if dim > 0 {
+		v[0] = 1
+	}
+	return v
+}
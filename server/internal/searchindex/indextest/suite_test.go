@@ -0,0 +1,184 @@
+package indextest
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/searchindex"
+)
+
+// memIndex is a minimal reference adapter; it keeps the suite itself honest
+// in environments without a search backend.
+type memIndex struct {
+	mu       sync.Mutex
+	entries  map[string]map[string]interface{}
+	contexts map[string]map[string]interface{}
+	vectors  map[string][]float32
+}
+
+func (m *memIndex) owned(p map[string]interface{}, actorID, memoryID string) bool {
+	return p["actorId"] == actorID && p["memoryId"] == memoryID
+}
+
+func (m *memIndex) CountObjects(_ context.Context, actorID, memoryID string) (model.ObjectCounts, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out model.ObjectCounts
+	for _, p := range m.entries {
+		if m.owned(p, actorID, memoryID) {
+			out.Entries++
+		}
+	}
+	for _, p := range m.contexts {
+		if m.owned(p, actorID, memoryID) {
+			out.Contexts++
+		}
+	}
+	return out, nil
+}
+
+func (m *memIndex) EntryVectors(_ context.Context, actorID, memoryID string, entryIDs []string) (map[string][]float32, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := map[string][]float32{}
+	for _, id := range entryIDs {
+		if p, ok := m.entries[id]; ok && m.owned(p, actorID, memoryID) {
+			out[id] = m.vectors[id]
+		}
+	}
+	return out, nil
+}
+
+func (m *memIndex) Search(_ context.Context, actorID, memoryID, query string, _ []float32, topK int, _ float32, filter model.SearchFilter) ([]model.SearchHit, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []model.SearchHit
+	for id, p := range m.entries {
+		if !m.owned(p, actorID, memoryID) || !strings.Contains(p["rawEntry"].(string), query) {
+			continue
+		}
+		if session, _ := p["sessionId"].(string); filter.SessionID != "" && session != filter.SessionID {
+			continue
+		}
+		hit := model.SearchHit{EntryID: id, ActorID: actorID, MemoryID: memoryID, RawEntry: p["rawEntry"].(string), Score: 1}
+		if ts, ok := p["creationTime"].(time.Time); ok {
+			hit.CreationTime = &ts
+			if (filter.Since != nil && ts.Before(*filter.Since)) || (filter.Until != nil && !ts.Before(*filter.Until)) {
+				continue
+			}
+		}
+		pairs, _ := p["tagPairs"].([]string)
+		if !hasAll(pairs, filter.Tags) {
+			continue
+		}
+		tags, _ := p["tags"].([]string)
+		if !filter.MustNot.Excludes(hit) && !hasAny(tags, filter.MustNot.Tags) {
+			out = append(out, hit)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].EntryID < out[j].EntryID })
+	if len(out) > topK {
+		out = out[:topK]
+	}
+	return out, nil
+}
+
+func hasAny(have, want []string) bool {
+	for _, w := range want {
+		for _, h := range have {
+			if h == w {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func hasAll(pairs []string, tags map[string]string) bool {
+	for k, v := range tags {
+		if !hasAny(pairs, []string{k + "=" + v}) {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *memIndex) latest(actorID, memoryID string) (string, time.Time) {
+	var text string
+	var ts time.Time
+	for _, p := range m.contexts {
+		if at := p["creationTime"].(time.Time); m.owned(p, actorID, memoryID) && at.After(ts) {
+			text, ts = p["context"].(string), at
+		}
+	}
+	return text, ts
+}
+
+func (m *memIndex) LatestContext(_ context.Context, actorID, memoryID string) (string, time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	text, ts := m.latest(actorID, memoryID)
+	return text, ts, nil
+}
+
+func (m *memIndex) BestContext(_ context.Context, actorID, memoryID, _ string, _ []float32, _ float32) (string, time.Time, float64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	text, ts := m.latest(actorID, memoryID)
+	return text, ts, 1, nil
+}
+
+func (m *memIndex) UpsertEntry(_ context.Context, id string, vec []float32, p map[string]interface{}) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries[id] = p
+	m.vectors[id] = vec
+	return nil
+}
+
+func (m *memIndex) UpsertContext(_ context.Context, id string, _ []float32, p map[string]interface{}) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.contexts[id] = p
+	return nil
+}
+
+func (m *memIndex) DeleteEntry(_ context.Context, _, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.entries, id)
+	return nil
+}
+
+func (m *memIndex) DeleteContext(_ context.Context, _, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.contexts, id)
+	return nil
+}
+
+func (m *memIndex) DeleteMemory(_ context.Context, actorID, memoryID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, objs := range []map[string]map[string]interface{}{m.entries, m.contexts} {
+		for id, p := range objs {
+			if m.owned(p, actorID, memoryID) {
+				delete(objs, id)
+			}
+		}
+	}
+	return nil
+}
+
+func (m *memIndex) DeleteVault(context.Context, string, string) error { return nil }
+
+func TestSuite_ReferenceIndex(t *testing.T) {
+	Run(t, func(*testing.T) searchindex.Index {
+		return &memIndex{entries: map[string]map[string]interface{}{}, contexts: map[string]map[string]interface{}{}, vectors: map[string][]float32{}}
+	}, 4)
+}
@@ -0,0 +1,82 @@
+package searchindex
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+// warmupID is used as actor and memory ID for warm-up probes. It never matches
+// real data, so queries exercise the full path but return no hits.
+const warmupID = "__warmup__"
+
+// WarmupChecker primes the embedder and search index after start so the first
+// real search does not pay for model loading or cold connections. It reports
+// unhealthy until one warm-up pass succeeds, which gates service readiness
+// when registered with the service health aggregator.
+type WarmupChecker struct {
+	index Index
+	emb   Embeddings
+	alpha float32
+	log   zerolog.Logger
+	warm  atomic.Int32
+	retry time.Duration
+}
+
+// NewWarmupChecker creates a warm-up checker; retry is the delay between failed passes.
+func NewWarmupChecker(index Index, emb Embeddings, alpha float32, log zerolog.Logger, retry time.Duration) *WarmupChecker {
+	if retry <= 0 {
+		retry = 2 * time.Second
+	}
+	return &WarmupChecker{index: index, emb: emb, alpha: alpha, log: log, retry: retry}
+}
+
+func (w *WarmupChecker) Name() string    { return "warmup" }
+func (w *WarmupChecker) IsHealthy() bool { return w.warm.Load() == 1 }
+
+// Start runs warm-up passes until one succeeds or ctx is cancelled. The
+// interval argument is ignored; retries use the configured retry delay.
+func (w *WarmupChecker) Start(ctx context.Context, _ time.Duration) {
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		err := w.runOnce(ctx)
+		if err == nil {
+			w.warm.Store(1)
+			w.log.Info().Int("attempt", attempt).Dur("elapsed", time.Since(start)).Msg("search warm-up completed")
+			return
+		}
+		w.log.Warn().Err(err).Int("attempt", attempt).Msg("search warm-up pass failed; retrying")
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(w.retry):
+		}
+	}
+}
+
+// runOnce loads the embedding model and issues one query per index class
+// (MemoryEntry via Search, MemoryContext via BestContext/LatestContext).
+func (w *WarmupChecker) runOnce(ctx context.Context) error {
+	vec, err := w.emb.Embed(ctx, "warm-up query")
+	if err != nil {
+		return fmt.Errorf("embed: %w", err)
+	}
+	if len(vec) == 0 {
+		return fmt.Errorf("embed: empty vector")
+	}
+	if _, err := w.index.Search(ctx, warmupID, warmupID, "warm-up query", vec, 1, w.alpha, model.SearchFilter{}); err != nil {
+		return fmt.Errorf("search entries: %w", err)
+	}
+	if _, _, _, err := w.index.BestContext(ctx, warmupID, warmupID, "warm-up query", vec, w.alpha); err != nil {
+		return fmt.Errorf("search contexts: %w", err)
+	}
+	if _, _, err := w.index.LatestContext(ctx, warmupID, warmupID); err != nil {
+		return fmt.Errorf("latest context: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package searchindex
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// flakyEmbedder fails the first `failures` calls, then succeeds.
+type flakyEmbedder struct {
+	failures int32
+	calls    atomic.Int32
+}
+
+func (f *flakyEmbedder) Embed(context.Context, string) ([]float32, error) {
+	if f.calls.Add(1) <= f.failures {
+		return nil, errors.New("model loading")
+	}
+	return []float32{0.1, 0.2}, nil
+}
+
+func TestWarmupChecker_RetriesUntilWarm(t *testing.T) {
+	emb := &flakyEmbedder{failures: 2}
+	w := NewWarmupChecker(fakeIndex{}, emb, 0.6, zerolog.Nop(), time.Millisecond)
+	if w.IsHealthy() {
+		t.Fatal("expected cold before start")
+	}
+
+	done := make(chan struct{})
+	go func() { w.Start(context.Background(), time.Second); close(done) }()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("warm-up did not complete")
+	}
+	if !w.IsHealthy() {
+		t.Fatal("expected warm after successful pass")
+	}
+	if got := emb.calls.Load(); got != 3 {
+		t.Fatalf("expected 3 embed attempts, got %d", got)
+	}
+}
+
+func TestWarmupChecker_StopsOnCancel(t *testing.T) {
+	w := NewWarmupChecker(fakeIndex{}, &flakyEmbedder{failures: 1 << 30}, 0.6, zerolog.Nop(), time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() { w.Start(ctx, time.Second); close(done) }()
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("warm-up did not stop on cancel")
+	}
+	if w.IsHealthy() {
+		t.Fatal("expected cold after cancelled warm-up")
+	}
+}
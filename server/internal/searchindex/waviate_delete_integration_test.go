@@ -17,11 +17,11 @@ func TestWeaviateDeleteSuite(t *testing.T) {
 		t.Skip("WEAVIATE_URL not set; skipping search index delete suite")
 	}
 
-	if err := searchindex.BootstrapWeaviate(context.Background(), host); err != nil {
+	if err := searchindex.BootstrapWeaviate(context.Background(), host, searchindex.WeaviateConfig{}); err != nil {
 		t.Fatalf("bootstrap weaviate: %v", err)
 	}
 
-	idx, err := searchindex.NewWeaviateNativeIndex(host)
+	idx, err := searchindex.NewWeaviateNativeIndex(host, searchindex.WeaviateConfig{})
 	if err != nil {
 		t.Fatalf("new index: %v", err)
 	}
@@ -5,12 +5,14 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/rs/zerolog/log"
 	weaviate "github.com/weaviate/weaviate-go-client/v5/weaviate"
 	"github.com/weaviate/weaviate/entities/models"
 )
 
-// BootstrapWeaviate ensures required classes exist in the search index.
-func BootstrapWeaviate(ctx context.Context, baseURL string) error {
+// BootstrapWeaviate ensures required classes exist in the search index,
+// creating missing ones with wc's replication.
+func BootstrapWeaviate(ctx context.Context, baseURL string, wc WeaviateConfig) error {
 	cfg := weaviate.Config{Scheme: "http", Host: baseURL}
 	cl, err := weaviate.NewClient(cfg)
 	if err != nil {
@@ -21,8 +23,9 @@ func BootstrapWeaviate(ctx context.Context, baseURL string) error {
 	defer cancel()
 
 	entry := &models.Class{
-		Class:      "MemoryEntry",
-		Vectorizer: "none",
+		Class:             "MemoryEntry",
+		Vectorizer:        "none",
+		ReplicationConfig: wc.replicationConfig(),
 		Properties: []*models.Property{
 			{Name: "entryId", DataType: []string{"uuid"}},
 			{Name: "actorId", DataType: []string{"text"}},
@@ -31,38 +34,63 @@ func BootstrapWeaviate(ctx context.Context, baseURL string) error {
 			{Name: "rawEntry", DataType: []string{"text"}},
 			{Name: "summary", DataType: []string{"text"}},
 			{Name: "tags", DataType: []string{"text[]"}},
+			tagPairsProperty(),
+			sessionIDProperty(),
 			{Name: "creationTime", DataType: []string{"date"}},
+			titleProperty("memoryTitle"),
+			titleProperty("vaultTitle"),
 		},
 	}
 
 	ctxCls := &models.Class{
-		Class:      "MemoryContext",
-		Vectorizer: "none",
+		Class:             "MemoryContext",
+		Vectorizer:        "none",
+		ReplicationConfig: wc.replicationConfig(),
 		Properties: []*models.Property{
 			{Name: "contextId", DataType: []string{"uuid"}},
 			{Name: "actorId", DataType: []string{"text"}},
 			{Name: "memoryId", DataType: []string{"uuid"}},
 			{Name: "context", DataType: []string{"text"}},
 			{Name: "creationTime", DataType: []string{"date"}},
+			titleProperty("memoryTitle"),
+			titleProperty("vaultTitle"),
 		},
 	}
 
 	if err := ensureClass(cctx, cl, entry); err != nil {
 		return fmt.Errorf("bootstrap MemoryEntry: %w", err)
 	}
-	if err := ensureEntryTagsProperty(cctx, cl); err != nil {
-		return fmt.Errorf("ensure tags property: %w", err)
+	// Properties added after the class was first created.
+	for _, prop := range []*models.Property{{Name: "tags", DataType: []string{"text[]"}}, tagPairsProperty(), sessionIDProperty(), titleProperty("memoryTitle"), titleProperty("vaultTitle")} {
+		if err := ensureProperty(cctx, cl, entry.Class, prop); err != nil {
+			return fmt.Errorf("ensure %s property: %w", prop.Name, err)
+		}
 	}
 	if err := ensureClass(cctx, cl, ctxCls); err != nil {
 		return fmt.Errorf("bootstrap MemoryContext: %w", err)
 	}
+	for _, prop := range []*models.Property{titleProperty("memoryTitle"), titleProperty("vaultTitle")} {
+		if err := ensureProperty(cctx, cl, ctxCls.Class, prop); err != nil {
+			return fmt.Errorf("ensure context %s property: %w", prop.Name, err)
+		}
+	}
 	return nil
 }
 
 func ensureClass(ctx context.Context, cl *weaviate.Client, desired *models.Class) error {
 	ex, err := cl.Schema().ClassGetter().WithClassName(desired.Class).Do(ctx)
 	if err == nil && ex != nil {
-		return nil // Class already exists
+		// Class already exists; replication is only set at creation.
+		if want := desired.ReplicationConfig; want != nil && want.Factor > 0 &&
+			(ex.ReplicationConfig == nil || ex.ReplicationConfig.Factor != want.Factor) {
+			var have int64
+			if ex.ReplicationConfig != nil {
+				have = ex.ReplicationConfig.Factor
+			}
+			log.Warn().Str("class", desired.Class).Int64("factor", have).Int64("configured", want.Factor).
+				Msg("existing class has a different replication factor; update it in Weaviate")
+		}
+		return nil
 	}
 	if err := cl.Schema().ClassCreator().WithClass(desired).Do(ctx); err != nil {
 		return fmt.Errorf("create class %s: %w", desired.Class, err)
@@ -70,16 +98,32 @@ func ensureClass(ctx context.Context, cl *weaviate.Client, desired *models.Class
 	return nil
 }
 
-func ensureEntryTagsProperty(ctx context.Context, cl *weaviate.Client) error {
-	ex, err := cl.Schema().ClassGetter().WithClassName("MemoryEntry").Do(ctx)
+// sessionIDProperty is matched whole, so session IDs are not split into words.
+func sessionIDProperty() *models.Property {
+	return &models.Property{Name: "sessionId", DataType: []string{"text"}, Tokenization: models.PropertyTokenizationField}
+}
+
+// tagPairsProperty holds an entry's tags as "key=value" strings for tag
+// filters, each matched whole.
+func tagPairsProperty() *models.Property {
+	return &models.Property{Name: "tagPairs", DataType: []string{"text[]"}, Tokenization: models.PropertyTokenizationField}
+}
+
+// titleProperty holds a memory or vault title, matched whole like sessionId
+// so hyphenated titles compare exactly.
+func titleProperty(name string) *models.Property {
+	return &models.Property{Name: name, DataType: []string{"text"}, Tokenization: models.PropertyTokenizationField}
+}
+
+func ensureProperty(ctx context.Context, cl *weaviate.Client, class string, prop *models.Property) error {
+	ex, err := cl.Schema().ClassGetter().WithClassName(class).Do(ctx)
 	if err != nil || ex == nil {
 		return err
 	}
 	for _, p := range ex.Properties {
-		if p.Name == "tags" {
+		if p.Name == prop.Name {
 			return nil
 		}
 	}
-	prop := &models.Property{Name: "tags", DataType: []string{"text[]"}}
-	return cl.Schema().PropertyCreator().WithClassName("MemoryEntry").WithProperty(prop).Do(ctx)
+	return cl.Schema().PropertyCreator().WithClassName(class).WithProperty(prop).Do(ctx)
 }
@@ -0,0 +1,52 @@
+package searchindex
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/weaviate/weaviate-go-client/v5/weaviate/data/replication"
+	"github.com/weaviate/weaviate/entities/models"
+)
+
+// WeaviateConfig tunes the index for a clustered Weaviate. The zero value
+// suits a single node: Weaviate's default consistency (QUORUM) and classes
+// created with its default replication.
+type WeaviateConfig struct {
+	// ReadConsistency is how many replicas must answer searches and other
+	// reads: ONE, QUORUM or ALL. Empty uses the Weaviate default.
+	ReadConsistency string
+	// WriteConsistency is how many replicas must acknowledge upserts and
+	// deletes, including those of the outbox worker.
+	WriteConsistency string
+	// ReplicationFactor is the number of replicas of each class
+	// BootstrapWeaviate creates; 0 keeps the Weaviate default. Existing
+	// classes are not changed.
+	ReplicationFactor int
+	// AsyncReplication repairs diverged replicas in the background.
+	AsyncReplication bool
+}
+
+// Validate checks the consistency levels and normalizes them to upper case.
+func (c *WeaviateConfig) Validate() error {
+	for _, lvl := range []*string{&c.ReadConsistency, &c.WriteConsistency} {
+		*lvl = strings.ToUpper(strings.TrimSpace(*lvl))
+		switch *lvl {
+		case "", replication.ConsistencyLevel.ONE, replication.ConsistencyLevel.QUORUM, replication.ConsistencyLevel.ALL:
+		default:
+			return fmt.Errorf("unsupported consistency level %q (want ONE, QUORUM or ALL)", *lvl)
+		}
+	}
+	if c.ReplicationFactor < 0 {
+		return fmt.Errorf("replication factor must not be negative")
+	}
+	return nil
+}
+
+// replicationConfig is the class replication to create, or nil for the
+// Weaviate default.
+func (c WeaviateConfig) replicationConfig() *models.ReplicationConfig {
+	if c.ReplicationFactor == 0 && !c.AsyncReplication {
+		return nil
+	}
+	return &models.ReplicationConfig{Factor: int64(c.ReplicationFactor), AsyncEnabled: c.AsyncReplication}
+}
@@ -0,0 +1,88 @@
+package searchindex
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+func TestWeaviateConfig_Validate(t *testing.T) {
+	wc := WeaviateConfig{ReadConsistency: " one", WriteConsistency: "Quorum"}
+	if err := wc.Validate(); err != nil || wc.ReadConsistency != "ONE" || wc.WriteConsistency != "QUORUM" {
+		t.Fatalf("Validate: %+v %v", wc, err)
+	}
+	for _, bad := range []WeaviateConfig{{ReadConsistency: "MOST"}, {WriteConsistency: "two"}, {ReplicationFactor: -1}} {
+		if err := bad.Validate(); err == nil {
+			t.Fatalf("expected error for %+v", bad)
+		}
+	}
+	if (WeaviateConfig{}).replicationConfig() != nil {
+		t.Fatal("zero config should keep the Weaviate replication default")
+	}
+	if rc := (WeaviateConfig{ReplicationFactor: 3}).replicationConfig(); rc == nil || rc.Factor != 3 {
+		t.Fatalf("replicationConfig = %+v", rc)
+	}
+}
+
+func TestWeaviateNative_SendsConsistencyLevels(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		graphql  []string
+		writeURL []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.URL.Path == "/v1/graphql":
+			body, _ := io.ReadAll(r.Body)
+			graphql = append(graphql, string(body))
+			_, _ = io.WriteString(w, `{"data":{"Get":{"MemoryEntry":[]}}}`)
+		case r.Method == http.MethodHead:
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == http.MethodGet: // the client's version probe of /v1/meta
+			_, _ = io.WriteString(w, `{}`)
+		default:
+			writeURL = append(writeURL, r.Method+" "+r.URL.String())
+			_, _ = io.WriteString(w, `{}`)
+		}
+	}))
+	defer srv.Close()
+
+	idx, err := NewWeaviateNativeIndex(strings.TrimPrefix(srv.URL, "http://"), WeaviateConfig{ReadConsistency: "all", WriteConsistency: "quorum"})
+	if err != nil {
+		t.Fatalf("NewWeaviateNativeIndex: %v", err)
+	}
+	ctx := context.Background()
+	if _, err := idx.Search(ctx, "a1", "m1", "q", []float32{1}, 3, 0.5, model.SearchFilter{}); err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if err := idx.UpsertEntry(ctx, "e1", []float32{1}, map[string]interface{}{"entryId": "e1"}); err != nil {
+		t.Fatalf("UpsertEntry: %v", err)
+	}
+	if err := idx.DeleteEntry(ctx, "a1", "e1"); err != nil {
+		t.Fatalf("DeleteEntry: %v", err)
+	}
+
+	if len(graphql) != 1 || !strings.Contains(graphql[0], "consistencyLevel: ALL") {
+		t.Fatalf("search did not read at ALL: %v", graphql)
+	}
+	if len(writeURL) != 2 {
+		t.Fatalf("expected a create and a delete, got %v", writeURL)
+	}
+	for _, u := range writeURL {
+		if !strings.Contains(u, "consistency_level=QUORUM") {
+			t.Fatalf("write without QUORUM: %s", u)
+		}
+	}
+
+	if _, err := NewWeaviateNativeIndex("localhost:1", WeaviateConfig{ReadConsistency: "TWO"}); err == nil {
+		t.Fatal("expected error for an unknown consistency level")
+	}
+}
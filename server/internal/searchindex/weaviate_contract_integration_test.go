@@ -0,0 +1,34 @@
+package searchindex_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/mycelian/mycelian-memory/server/internal/searchindex"
+	"github.com/mycelian/mycelian-memory/server/internal/searchindex/indextest"
+)
+
+// contractDim matches the default nomic-embed-text model so the suite can run
+// against a dev Weaviate whose classes already hold real vectors.
+const contractDim = 768
+
+// TestWeaviateContract runs the index contract suite (tenant isolation,
+// memory filtering, topK, delete propagation) against a real Weaviate.
+// Requires WEAVIATE_URL (host:port); skipped otherwise.
+func TestWeaviateContract(t *testing.T) {
+	host := os.Getenv("WEAVIATE_URL")
+	if host == "" {
+		t.Skip("WEAVIATE_URL not set; skipping search index contract suite")
+	}
+	indextest.Run(t, func(t *testing.T) searchindex.Index {
+		if err := searchindex.BootstrapWeaviate(context.Background(), host, searchindex.WeaviateConfig{}); err != nil {
+			t.Fatalf("bootstrap weaviate: %v", err)
+		}
+		idx, err := searchindex.NewWeaviateNativeIndex(host, searchindex.WeaviateConfig{})
+		if err != nil {
+			t.Fatalf("new index: %v", err)
+		}
+		return idx
+	}, contractDim)
+}
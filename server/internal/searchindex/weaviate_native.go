@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -21,20 +22,34 @@ import (
 type weavNative struct {
 	client  *weaviate.Client
 	baseURL string // host:port without scheme
+	readCL  string // consistency level of reads; empty uses the Weaviate default
+	writeCL string // consistency level of writes and deletes
 }
 
 // NewWeaviateNativeIndex constructs an Index backed by Weaviate at baseURL.
 // baseURL should be host:port (without scheme), e.g., "localhost:8081".
-func NewWeaviateNativeIndex(baseURL string) (Index, error) {
+func NewWeaviateNativeIndex(baseURL string, wc WeaviateConfig) (Index, error) {
+	if err := wc.Validate(); err != nil {
+		return nil, err
+	}
 	cfg := weaviate.Config{Scheme: "http", Host: baseURL}
 	cl, err := weaviate.NewClient(cfg)
 	if err != nil {
 		return nil, err
 	}
-	return &weavNative{client: cl, baseURL: baseURL}, nil
+	return &weavNative{client: cl, baseURL: baseURL, readCL: wc.ReadConsistency, writeCL: wc.WriteConsistency}, nil
 }
 
-func (w *weavNative) Search(ctx context.Context, actorID string, memoryID, query string, vec []float32, topK int, alpha float32) ([]model.SearchHit, error) {
+// get starts a GraphQL Get at the configured read consistency.
+func (w *weavNative) get() *gql.GetBuilder {
+	b := w.client.GraphQL().Get()
+	if w.readCL != "" {
+		b = b.WithConsistencyLevel(w.readCL)
+	}
+	return b
+}
+
+func (w *weavNative) Search(ctx context.Context, actorID string, memoryID, query string, vec []float32, topK int, alpha float32, filter model.SearchFilter) ([]model.SearchHit, error) {
 	log.Info().Str("memoryId", memoryID).Str("query", query).Str("actorID", actorID).Int("topK", topK).Float32("alpha", alpha).Int("vectorLength", len(vec)).Msg("weaviate search starting")
 
 	// helper to safely extract strings
@@ -47,11 +62,11 @@ func (w *weavNative) Search(ctx context.Context, actorID string, memoryID, query
 		WithQuery(query).
 		WithVector(vec).
 		WithAlpha(alpha).
-		WithProperties([]string{"summary", "rawEntry"})
+		WithProperties(hybridProperties(filter.FieldWeights))
 
-	where := filters.Where().WithPath([]string{"memoryId"}).WithOperator(filters.Equal).WithValueText(memoryID)
+	where := searchFilter(actorID, memoryID, filter)
 
-	req := w.client.GraphQL().Get().
+	req := w.get().
 		WithClassName("MemoryEntry").
 		WithWhere(where).
 		WithHybrid(hy).
@@ -62,6 +77,8 @@ func (w *weavNative) Search(ctx context.Context, actorID string, memoryID, query
 			gql.Field{Name: "memoryId"},
 			gql.Field{Name: "summary"},
 			gql.Field{Name: "rawEntry"},
+			gql.Field{Name: "creationTime"},
+			gql.Field{Name: "sessionId"},
 			gql.Field{Name: "_additional", Fields: []gql.Field{{Name: "score"}}},
 		)
 
@@ -109,12 +126,24 @@ func (w *weavNative) Search(ctx context.Context, actorID string, memoryID, query
 			}
 		}
 		hit := model.SearchHit{
-			EntryID:  safeString(m["entryId"]),
-			ActorID:  safeString(m["actorId"]),
-			MemoryID: safeString(m["memoryId"]),
-			Summary:  safeString(m["summary"]),
-			RawEntry: safeString(m["rawEntry"]),
-			Score:    score,
+			EntryID:   safeString(m["entryId"]),
+			ActorID:   safeString(m["actorId"]),
+			MemoryID:  safeString(m["memoryId"]),
+			Summary:   safeString(m["summary"]),
+			RawEntry:  safeString(m["rawEntry"]),
+			SessionID: safeString(m["sessionId"]),
+			Score:     score,
+		}
+		if ts, err := time.Parse(time.RFC3339, safeString(m["creationTime"])); err == nil {
+			hit.CreationTime = &ts
+		}
+		if hit.ActorID != actorID {
+			// Never return another tenant's entry, even if the filter was loosened by tokenization.
+			log.Warn().Str("entryId", hit.EntryID).Msg("dropping search hit owned by another actor")
+			continue
+		}
+		if filter.MustNot.Excludes(hit) {
+			continue
 		}
 		log.Debug().Str("entryId", hit.EntryID).Str("summary", hit.Summary).Float64("score", score).Msg("search hit")
 		out = append(out, hit)
@@ -124,8 +153,8 @@ func (w *weavNative) Search(ctx context.Context, actorID string, memoryID, query
 }
 
 func (w *weavNative) LatestContext(ctx context.Context, actorID string, memoryID string) (string, time.Time, error) {
-	where := filters.Where().WithPath([]string{"memoryId"}).WithOperator(filters.Equal).WithValueText(memoryID)
-	req := w.client.GraphQL().Get().
+	where := memoryFilter(actorID, memoryID)
+	req := w.get().
 		WithClassName("MemoryContext").
 		WithWhere(where).
 		WithSort(gql.Sort{Path: []string{"creationTime"}, Order: gql.Desc}).
@@ -175,8 +204,8 @@ func (w *weavNative) BestContext(ctx context.Context, actorID string, memoryID,
 		WithAlpha(alpha).
 		WithProperties([]string{"context"})
 
-	where := filters.Where().WithPath([]string{"memoryId"}).WithOperator(filters.Equal).WithValueText(memoryID)
-	req := w.client.GraphQL().Get().
+	where := memoryFilter(actorID, memoryID)
+	req := w.get().
 		WithClassName("MemoryContext").
 		WithWhere(where).
 		WithHybrid(hy).
@@ -226,7 +255,7 @@ func (w *weavNative) DeleteEntry(ctx context.Context, actorID string, entryID st
 	if w == nil || w.client == nil || entryID == "" {
 		return nil
 	}
-	_ = w.client.Data().Deleter().WithClassName("MemoryEntry").WithID(entryID).Do(ctx)
+	_ = w.client.Data().Deleter().WithClassName("MemoryEntry").WithID(entryID).WithConsistencyLevel(w.writeCL).Do(ctx)
 	return nil
 }
 
@@ -234,17 +263,34 @@ func (w *weavNative) DeleteContext(ctx context.Context, actorID string, contextI
 	if w == nil || w.client == nil || contextID == "" {
 		return nil
 	}
-	_ = w.client.Data().Deleter().WithClassName("MemoryContext").WithID(contextID).Do(ctx)
+	_ = w.client.Data().Deleter().WithClassName("MemoryContext").WithID(contextID).WithConsistencyLevel(w.writeCL).Do(ctx)
 	return nil
 }
 
+// EntryExists implements ObjectChecker.
+func (w *weavNative) EntryExists(ctx context.Context, actorID, entryID string) (bool, error) {
+	return w.exists(ctx, "MemoryEntry", entryID)
+}
+
+// ContextExists implements ObjectChecker.
+func (w *weavNative) ContextExists(ctx context.Context, actorID, contextID string) (bool, error) {
+	return w.exists(ctx, "MemoryContext", contextID)
+}
+
+func (w *weavNative) exists(ctx context.Context, class, id string) (bool, error) {
+	if w == nil || w.client == nil || id == "" {
+		return false, nil
+	}
+	return w.client.Data().Checker().WithClassName(class).WithID(id).Do(ctx)
+}
+
 func (w *weavNative) DeleteMemory(ctx context.Context, actorID string, memoryID string) error {
 	if w == nil || w.client == nil || memoryID == "" {
 		return nil
 	}
 	// List entries for memory and delete by id
-	where := filters.Where().WithPath([]string{"memoryId"}).WithOperator(filters.Equal).WithValueText(memoryID)
-	req := w.client.GraphQL().Get().
+	where := memoryFilter(actorID, memoryID)
+	req := w.get().
 		WithClassName("MemoryEntry").
 		WithWhere(where).
 		WithFields(gql.Field{Name: "entryId"})
@@ -254,14 +300,14 @@ func (w *weavNative) DeleteMemory(ctx context.Context, actorID string, memoryID
 				for _, item := range arr {
 					id, _ := item.(map[string]interface{})["entryId"].(string)
 					if id != "" {
-						_ = w.client.Data().Deleter().WithClassName("MemoryEntry").WithID(id).Do(ctx)
+						_ = w.client.Data().Deleter().WithClassName("MemoryEntry").WithID(id).WithConsistencyLevel(w.writeCL).Do(ctx)
 					}
 				}
 			}
 		}
 	}
 	// List contexts for memory and delete by id
-	req2 := w.client.GraphQL().Get().
+	req2 := w.get().
 		WithClassName("MemoryContext").
 		WithWhere(where).
 		WithFields(gql.Field{Name: "contextId"})
@@ -271,7 +317,7 @@ func (w *weavNative) DeleteMemory(ctx context.Context, actorID string, memoryID
 				for _, item := range arr {
 					id, _ := item.(map[string]interface{})["contextId"].(string)
 					if id != "" {
-						_ = w.client.Data().Deleter().WithClassName("MemoryContext").WithID(id).Do(ctx)
+						_ = w.client.Data().Deleter().WithClassName("MemoryContext").WithID(id).WithConsistencyLevel(w.writeCL).Do(ctx)
 					}
 				}
 			}
@@ -286,21 +332,30 @@ func (w *weavNative) DeleteVault(ctx context.Context, actorID string, vaultID st
 	return nil
 }
 
-// UpsertEntry implements a best-effort upsert using Weaviate Data Creator.
+// UpsertEntry creates or replaces a MemoryEntry object.
 func (w *weavNative) UpsertEntry(ctx context.Context, entryID string, vec []float32, payload map[string]interface{}) error {
-	if w == nil || w.client == nil {
-		return nil
-	}
-	_, err := w.client.Data().Creator().WithClassName("MemoryEntry").WithID(entryID).WithProperties(payload).WithVector(vec).Do(ctx)
-	return err
+	return w.upsert(ctx, "MemoryEntry", entryID, vec, payload)
 }
 
-// UpsertContext implements a best-effort upsert for MemoryContext class.
+// UpsertContext creates or replaces a MemoryContext object.
 func (w *weavNative) UpsertContext(ctx context.Context, contextID string, vec []float32, payload map[string]interface{}) error {
+	return w.upsert(ctx, "MemoryContext", contextID, vec, payload)
+}
+
+// upsert replaces an existing object (e.g. during a reindex) and creates it
+// otherwise; a plain create fails when the ID already exists.
+func (w *weavNative) upsert(ctx context.Context, class, id string, vec []float32, payload map[string]interface{}) error {
 	if w == nil || w.client == nil {
 		return nil
 	}
-	_, err := w.client.Data().Creator().WithClassName("MemoryContext").WithID(contextID).WithProperties(payload).WithVector(vec).Do(ctx)
+	exists, err := w.client.Data().Checker().WithClassName(class).WithID(id).Do(ctx)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return w.client.Data().Updater().WithClassName(class).WithID(id).WithProperties(payload).WithVector(vec).WithConsistencyLevel(w.writeCL).Do(ctx)
+	}
+	_, err = w.client.Data().Creator().WithClassName(class).WithID(id).WithProperties(payload).WithVector(vec).WithConsistencyLevel(w.writeCL).Do(ctx)
 	return err
 }
 
@@ -330,6 +385,255 @@ func (w *weavNative) HealthPing(ctx context.Context) error {
 	return nil
 }
 
+// CountObjects implements ObjectCounter with one meta-count aggregate per class.
+func (w *weavNative) CountObjects(ctx context.Context, actorID, memoryID string) (model.ObjectCounts, error) {
+	var out model.ObjectCounts
+	for class, dst := range map[string]*int64{"MemoryEntry": &out.Entries, "MemoryContext": &out.Contexts} {
+		n, err := w.count(ctx, class, memoryFilter(actorID, memoryID))
+		if err != nil {
+			return model.ObjectCounts{}, err
+		}
+		*dst = n
+	}
+	return out, nil
+}
+
+func (w *weavNative) count(ctx context.Context, class string, where *filters.WhereBuilder) (int64, error) {
+	resp, err := w.client.GraphQL().Aggregate().
+		WithClassName(class).
+		WithWhere(where).
+		WithFields(gql.Field{Name: "meta", Fields: []gql.Field{{Name: "count"}}}).
+		Do(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if len(resp.Errors) > 0 {
+		return 0, fmt.Errorf("weaviate graphql: %s", formatGraphQLErrors(resp.Errors))
+	}
+	agg, _ := resp.Data["Aggregate"].(map[string]interface{})
+	groups, _ := agg[class].([]interface{})
+	if len(groups) == 0 {
+		return 0, nil
+	}
+	group, _ := groups[0].(map[string]interface{})
+	meta, _ := group["meta"].(map[string]interface{})
+	n, _ := meta["count"].(float64)
+	return int64(n), nil
+}
+
+// NewestEntryTime implements NewestEntryReader with a Get sorted by
+// creationTime.
+func (w *weavNative) NewestEntryTime(ctx context.Context, actorID, memoryID string) (time.Time, error) {
+	resp, err := w.get().
+		WithClassName("MemoryEntry").
+		WithWhere(memoryFilter(actorID, memoryID)).
+		WithSort(gql.Sort{Path: []string{"creationTime"}, Order: gql.Desc}).
+		WithLimit(1).
+		WithFields(gql.Field{Name: "creationTime"}).
+		Do(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if len(resp.Errors) > 0 {
+		return time.Time{}, fmt.Errorf("weaviate graphql: %s", formatGraphQLErrors(resp.Errors))
+	}
+	getData, _ := resp.Data["Get"].(map[string]interface{})
+	arr, _ := getData["MemoryEntry"].([]interface{})
+	if len(arr) == 0 {
+		return time.Time{}, nil
+	}
+	item, _ := arr[0].(map[string]interface{})
+	tsStr, _ := item["creationTime"].(string)
+	return time.Parse(time.RFC3339, tsStr)
+}
+
+// EntryVectors implements VectorReader with one filtered Get returning each
+// object's vector.
+func (w *weavNative) EntryVectors(ctx context.Context, actorID, memoryID string, entryIDs []string) (map[string][]float32, error) {
+	out := make(map[string][]float32, len(entryIDs))
+	if len(entryIDs) == 0 {
+		return out, nil
+	}
+	where := filters.Where().WithOperator(filters.And).WithOperands([]*filters.WhereBuilder{
+		memoryFilter(actorID, memoryID),
+		filters.Where().WithPath([]string{"entryId"}).WithOperator(filters.ContainsAny).WithValueText(entryIDs...),
+	})
+	resp, err := w.get().
+		WithClassName("MemoryEntry").
+		WithWhere(where).
+		WithLimit(len(entryIDs)).
+		WithFields(
+			gql.Field{Name: "entryId"},
+			gql.Field{Name: "_additional", Fields: []gql.Field{{Name: "vector"}}},
+		).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Errors) > 0 {
+		return nil, fmt.Errorf("weaviate graphql: %s", formatGraphQLErrors(resp.Errors))
+	}
+	getData, _ := resp.Data["Get"].(map[string]interface{})
+	arr, _ := getData["MemoryEntry"].([]interface{})
+	for _, item := range arr {
+		obj, _ := item.(map[string]interface{})
+		id, _ := obj["entryId"].(string)
+		add, _ := obj["_additional"].(map[string]interface{})
+		raw, _ := add["vector"].([]interface{})
+		if id == "" || len(raw) == 0 {
+			continue
+		}
+		vec := make([]float32, len(raw))
+		for i, v := range raw {
+			f, _ := v.(float64)
+			vec[i] = float32(f)
+		}
+		out[id] = vec
+	}
+	return out, nil
+}
+
+// titleUpdatePage is how many objects UpdateTitles reads per query.
+const titleUpdatePage = 100
+
+// UpdateTitles implements TitleUpdater. Weaviate cannot update by filter, so
+// it repeatedly reads a page of the memory's objects that lack a new title
+// and merges the titles into each, until none are left.
+func (w *weavNative) UpdateTitles(ctx context.Context, actorID, memoryID, memoryTitle, vaultTitle string) error {
+	props := map[string]interface{}{}
+	var stale []*filters.WhereBuilder
+	for name, title := range map[string]string{"memoryTitle": memoryTitle, "vaultTitle": vaultTitle} {
+		if title != "" {
+			props[name] = title
+			stale = append(stale, filters.Where().WithPath([]string{name}).WithOperator(filters.NotEqual).WithValueText(title))
+		}
+	}
+	if len(props) == 0 {
+		return nil
+	}
+	lacksTitle := stale[0]
+	if len(stale) > 1 {
+		lacksTitle = filters.Where().WithOperator(filters.Or).WithOperands(stale)
+	}
+	where := filters.Where().WithOperator(filters.And).WithOperands([]*filters.WhereBuilder{memoryFilter(actorID, memoryID), lacksTitle})
+	for class, idField := range map[string]string{"MemoryEntry": "entryId", "MemoryContext": "contextId"} {
+		updated := map[string]bool{}
+		for {
+			ids, err := w.objectIDs(ctx, class, idField, where, titleUpdatePage)
+			if err != nil {
+				return err
+			}
+			if len(ids) == 0 {
+				break
+			}
+			for _, id := range ids {
+				if updated[id] {
+					return fmt.Errorf("update titles: %s %s still has the old title", class, id)
+				}
+				if err := w.client.Data().Updater().WithMerge().WithClassName(class).WithID(id).WithProperties(props).WithConsistencyLevel(w.writeCL).Do(ctx); err != nil {
+					return err
+				}
+				updated[id] = true
+			}
+		}
+	}
+	return nil
+}
+
+// objectIDs returns the idField of up to limit class objects matching where.
+func (w *weavNative) objectIDs(ctx context.Context, class, idField string, where *filters.WhereBuilder, limit int) ([]string, error) {
+	resp, err := w.get().
+		WithClassName(class).
+		WithWhere(where).
+		WithLimit(limit).
+		WithFields(gql.Field{Name: idField}).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Errors) > 0 {
+		return nil, fmt.Errorf("weaviate graphql: %s", formatGraphQLErrors(resp.Errors))
+	}
+	getData, _ := resp.Data["Get"].(map[string]interface{})
+	arr, _ := getData[class].([]interface{})
+	ids := make([]string, 0, len(arr))
+	for _, item := range arr {
+		obj, _ := item.(map[string]interface{})
+		if id, _ := obj[idField].(string); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// memoryFilter scopes a query to one actor's memory. Both conditions are
+// pushed down so tenants cannot see each other's objects even when memory
+// IDs collide.
+func memoryFilter(actorID, memoryID string) *filters.WhereBuilder {
+	return filters.Where().WithOperator(filters.And).WithOperands([]*filters.WhereBuilder{
+		filters.Where().WithPath([]string{"actorId"}).WithOperator(filters.Equal).WithValueText(actorID),
+		filters.Where().WithPath([]string{"memoryId"}).WithOperator(filters.Equal).WithValueText(memoryID),
+	})
+}
+
+// searchFilter extends memoryFilter with the session, tag, creation time
+// and mustNot conditions. Tags match the tagPairs property, which holds the
+// entry's tags as "key=value" strings, so all pairs must be present.
+// Each excluded value becomes a NotEqual operand; on the tags array NotEqual
+// matches objects holding none of the value, and objects without tags.
+func searchFilter(actorID, memoryID string, filter model.SearchFilter) *filters.WhereBuilder {
+	operands := []*filters.WhereBuilder{memoryFilter(actorID, memoryID)}
+	if filter.SessionID != "" {
+		operands = append(operands, filters.Where().WithPath([]string{"sessionId"}).WithOperator(filters.Equal).WithValueText(filter.SessionID))
+	}
+	if len(filter.Tags) > 0 {
+		pairs := make([]string, 0, len(filter.Tags))
+		for k, v := range filter.Tags {
+			pairs = append(pairs, k+"="+v)
+		}
+		sort.Strings(pairs)
+		operands = append(operands, filters.Where().WithPath([]string{"tagPairs"}).WithOperator(filters.ContainsAll).WithValueText(pairs...))
+	}
+	if filter.Since != nil {
+		operands = append(operands, filters.Where().WithPath([]string{"creationTime"}).WithOperator(filters.GreaterThanEqual).WithValueDate(*filter.Since))
+	}
+	if filter.Until != nil {
+		operands = append(operands, filters.Where().WithPath([]string{"creationTime"}).WithOperator(filters.LessThan).WithValueDate(*filter.Until))
+	}
+	mustNot := filter.MustNot
+	notEqual := func(path string, values []string) {
+		for _, v := range values {
+			operands = append(operands, filters.Where().WithPath([]string{path}).WithOperator(filters.NotEqual).WithValueText(v))
+		}
+	}
+	notEqual("tags", mustNot.Tags)
+	notEqual("memoryId", mustNot.MemoryIDs)
+	notEqual("entryId", mustNot.EntryIDs)
+	if len(operands) == 1 {
+		return operands[0]
+	}
+	return filters.Where().WithOperator(filters.And).WithOperands(operands)
+}
+
+// hybridProperties lists the properties the keyword half of a hybrid search
+// matches, each with its weight as a ^boost. Fields weighted 0 are left out;
+// no weights means summary and rawEntry at weight 1.
+func hybridProperties(weights map[string]float64) []string {
+	if len(weights) == 0 {
+		return []string{model.SearchFieldSummary, model.SearchFieldRawEntry}
+	}
+	var props []string
+	for _, f := range []string{model.SearchFieldSummary, model.SearchFieldRawEntry, model.SearchFieldTags} {
+		switch w := weights[f]; {
+		case w == 1:
+			props = append(props, f)
+		case w > 0:
+			props = append(props, fmt.Sprintf("%s^%g", f, w))
+		}
+	}
+	return props
+}
+
 // formatGraphQLErrors returns compact string with messages extracted for logging.
 func formatGraphQLErrors(errs interface{}) string {
 	if b, err := json.Marshal(errs); err == nil {
@@ -0,0 +1,36 @@
+package searchindex
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+func TestHybridProperties(t *testing.T) {
+	cases := []struct {
+		weights map[string]float64
+		want    []string
+	}{
+		{nil, []string{"summary", "rawEntry"}},
+		{map[string]float64{"tags": 2, "summary": 1.5, "rawEntry": 0}, []string{"summary^1.5", "tags^2"}},
+		{map[string]float64{"rawEntry": 1}, []string{"rawEntry"}},
+	}
+	for _, c := range cases {
+		if got := hybridProperties(c.weights); !slices.Equal(got, c.want) {
+			t.Fatalf("hybridProperties(%v) = %v, want %v", c.weights, got, c.want)
+		}
+	}
+}
+
+func TestSearchFilter_Tags(t *testing.T) {
+	f := searchFilter("a1", "m1", model.SearchFilter{Tags: map[string]string{"project": "alpha", "done": "true"}}).Build()
+	if f.Operator != "And" || len(f.Operands) != 2 {
+		t.Fatalf("filter = %+v, want memory and tag operands", f)
+	}
+	tags := f.Operands[1]
+	if tags.Operator != "ContainsAll" || !slices.Equal(tags.Path, []string{"tagPairs"}) ||
+		!slices.Equal(tags.ValueTextArray, []string{"done=true", "project=alpha"}) {
+		t.Fatalf("tag operand = %+v, want ContainsAll tagPairs [done=true project=alpha]", tags)
+	}
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	_ "time/tzdata" // zone database for images without /usr/share/zoneinfo
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/store"
+)
+
+// ActorService manages per-actor settings such as the default time zone.
+type ActorService struct {
+	store store.Store
+}
+
+func NewActorService(s store.Store) *ActorService {
+	return &ActorService{store: s}
+}
+
+// GetSettings returns the actor's settings, defaulting to UTC when none were saved.
+func (s *ActorService) GetSettings(ctx context.Context, actorID string) (*model.ActorSettings, error) {
+	out, err := s.store.ActorSettings().Get(ctx, actorID)
+	if errors.Is(err, model.ErrNotFound) {
+		return &model.ActorSettings{ActorID: actorID, TimeZone: model.DefaultTimeZone}, nil
+	}
+	return out, err
+}
+
+// SetTimeZone validates an IANA zone name and stores it as the actor default.
+func (s *ActorService) SetTimeZone(ctx context.Context, actorID, timeZone string) (*model.ActorSettings, error) {
+	loc, err := LoadTimeZone(timeZone)
+	if err != nil {
+		return nil, err
+	}
+	return s.store.ActorSettings().PutTimeZone(ctx, actorID, loc.String())
+}
+
+// SetDefaults validates and stores the actor's default vault and memory.
+// A memory needs its vault; both must exist. Empty IDs clear the defaults.
+func (s *ActorService) SetDefaults(ctx context.Context, actorID, vaultID, memoryID string) (*model.ActorSettings, error) {
+	vaultID, memoryID = strings.TrimSpace(vaultID), strings.TrimSpace(memoryID)
+	switch {
+	case memoryID != "" && vaultID == "":
+		return nil, fmt.Errorf("%w: a default memoryId needs its vaultId", model.ErrValidation)
+	case memoryID != "":
+		if _, err := s.store.Memories().GetByID(ctx, actorID, vaultID, memoryID); err != nil {
+			return nil, err
+		}
+	case vaultID != "":
+		if _, err := s.store.Vaults().GetByID(ctx, actorID, vaultID); err != nil {
+			return nil, err
+		}
+	}
+	return s.store.ActorSettings().PutDefaults(ctx, actorID, vaultID, memoryID)
+}
+
+// DefaultMemory returns the actor's default vault and memory, or
+// model.ErrValidation when no default memory is set: the caller then has to
+// name one.
+func (s *ActorService) DefaultMemory(ctx context.Context, actorID string) (vaultID, memoryID string, err error) {
+	settings, err := s.GetSettings(ctx, actorID)
+	if err != nil {
+		return "", "", err
+	}
+	if settings.DefaultMemoryID == "" {
+		return "", "", fmt.Errorf("%w: no default memory is set (PUT /v0/actor/defaults)", model.ErrValidation)
+	}
+	return settings.DefaultVaultID, settings.DefaultMemoryID, nil
+}
+
+// Location resolves the zone for a request: override (e.g. a tz query
+// parameter) when set, otherwise the actor's saved default.
+func (s *ActorService) Location(ctx context.Context, actorID, override string) (*time.Location, error) {
+	if override != "" {
+		return LoadTimeZone(override)
+	}
+	settings, err := s.GetSettings(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	return LoadTimeZone(settings.TimeZone)
+}
+
+// LoadTimeZone loads an IANA zone name, reporting bad names as ErrValidation.
+// "Local" is rejected because it would depend on the server's configuration.
+func LoadTimeZone(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || name == "Local" {
+		return nil, fmt.Errorf("%w: timeZone must be an IANA zone name such as Europe/Berlin", model.ErrValidation)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unknown timeZone %q", model.ErrValidation, name)
+	}
+	return loc, nil
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+type fakeActorSettings struct {
+	tz       map[string]string
+	defaults map[string][2]string
+}
+
+func (f *fakeActorSettings) Get(_ context.Context, actorID string) (*model.ActorSettings, error) {
+	tz, ok := f.tz[actorID]
+	d, hasDefaults := f.defaults[actorID]
+	if !ok && !hasDefaults {
+		return nil, model.ErrNotFound
+	}
+	if tz == "" {
+		tz = model.DefaultTimeZone
+	}
+	return &model.ActorSettings{ActorID: actorID, TimeZone: tz, DefaultVaultID: d[0], DefaultMemoryID: d[1]}, nil
+}
+
+func (f *fakeActorSettings) PutTimeZone(_ context.Context, actorID, tz string) (*model.ActorSettings, error) {
+	f.tz[actorID] = tz
+	return &model.ActorSettings{ActorID: actorID, TimeZone: tz}, nil
+}
+
+func (f *fakeActorSettings) PutDefaults(_ context.Context, actorID, vaultID, memoryID string) (*model.ActorSettings, error) {
+	if f.defaults == nil {
+		f.defaults = map[string][2]string{}
+	}
+	f.defaults[actorID] = [2]string{vaultID, memoryID}
+	return f.Get(context.Background(), actorID)
+}
+
+func TestActorService_TimeZone(t *testing.T) {
+	ctx := context.Background()
+	svc := NewActorService(&fakeStore{actors: &fakeActorSettings{tz: map[string]string{}}})
+
+	got, err := svc.GetSettings(ctx, "a1")
+	if err != nil || got.TimeZone != model.DefaultTimeZone {
+		t.Fatalf("default: got=%+v err=%v", got, err)
+	}
+	for _, bad := range []string{"", "Local", "Mars/Olympus"} {
+		if _, err := svc.SetTimeZone(ctx, "a1", bad); !errors.Is(err, model.ErrValidation) {
+			t.Errorf("%q: expected ErrValidation, got %v", bad, err)
+		}
+	}
+	if _, err := svc.SetTimeZone(ctx, "a1", "America/New_York"); err != nil {
+		t.Fatalf("SetTimeZone: %v", err)
+	}
+
+	loc, err := svc.Location(ctx, "a1", "")
+	if err != nil || loc.String() != "America/New_York" {
+		t.Fatalf("saved default: loc=%v err=%v", loc, err)
+	}
+	loc, err = svc.Location(ctx, "a1", "Asia/Kolkata")
+	if err != nil || loc.String() != "Asia/Kolkata" {
+		t.Fatalf("override: loc=%v err=%v", loc, err)
+	}
+}
+
+func TestActorService_Defaults(t *testing.T) {
+	ctx := context.Background()
+	svc := NewActorService(&fakeStore{actors: &fakeActorSettings{tz: map[string]string{}}})
+
+	if _, _, err := svc.DefaultMemory(ctx, "a1"); !errors.Is(err, model.ErrValidation) {
+		t.Fatalf("unset: expected ErrValidation, got %v", err)
+	}
+	if _, err := svc.SetDefaults(ctx, "a1", "", "m1"); !errors.Is(err, model.ErrValidation) {
+		t.Fatalf("memory without vault: expected ErrValidation, got %v", err)
+	}
+	if _, err := svc.SetDefaults(ctx, "a1", " v1 ", "m1"); err != nil {
+		t.Fatalf("SetDefaults: %v", err)
+	}
+	if v, m, err := svc.DefaultMemory(ctx, "a1"); err != nil || v != "v1" || m != "m1" {
+		t.Fatalf("DefaultMemory = %q, %q, %v", v, m, err)
+	}
+	if _, err := svc.SetDefaults(ctx, "a1", "", ""); err != nil {
+		t.Fatalf("clear: %v", err)
+	}
+	if _, _, err := svc.DefaultMemory(ctx, "a1"); !errors.Is(err, model.ErrValidation) {
+		t.Fatalf("cleared: expected ErrValidation, got %v", err)
+	}
+}
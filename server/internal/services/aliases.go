@@ -0,0 +1,266 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/store"
+)
+
+// Limits of a memory's entity alias registry.
+const (
+	MaxEntityNameLen          = 200
+	MaxEntityAliasesPerMemory = 500
+)
+
+// EntitiesMetadataKey is the entry metadata key listing the canonical names
+// of the registered entities an entry mentions.
+const EntitiesMetadataKey = "entities"
+
+// PutEntityAlias registers alias as another name of canonical in the memory,
+// replacing an earlier mapping of the same alias. A canonical name that is
+// itself an alias resolves to that alias's entity, so aliases never chain.
+func (s *MemoryService) PutEntityAlias(ctx context.Context, actorID, vaultID, memoryID, alias, canonical string) (*model.EntityAlias, error) {
+	alias, canonical = strings.TrimSpace(alias), strings.TrimSpace(canonical)
+	if alias == "" || canonical == "" {
+		return nil, fmt.Errorf("%w: alias and canonical are required", model.ErrValidation)
+	}
+	if len(alias) > MaxEntityNameLen || len(canonical) > MaxEntityNameLen {
+		return nil, fmt.Errorf("%w: alias and canonical must be at most %d bytes", model.ErrValidation, MaxEntityNameLen)
+	}
+	if err := ensureVaultWritable(ctx, s.store, actorID, vaultID); err != nil {
+		return nil, err
+	}
+	if _, err := s.store.Memories().GetByID(ctx, actorID, vaultID, memoryID); err != nil {
+		return nil, err
+	}
+	existing, err := s.store.EntityAliases().List(ctx, actorID, memoryID)
+	if err != nil {
+		return nil, err
+	}
+	replacing := false
+	for _, a := range existing {
+		if strings.EqualFold(a.Alias, canonical) {
+			canonical = a.Canonical
+		}
+		if strings.EqualFold(a.Alias, alias) {
+			replacing = true
+		}
+	}
+	if strings.EqualFold(alias, canonical) {
+		return nil, fmt.Errorf("%w: alias must differ from its canonical name", model.ErrValidation)
+	}
+	for _, a := range existing {
+		if strings.EqualFold(a.Canonical, alias) {
+			return nil, fmt.Errorf("%w: %q is the canonical name of other aliases", model.ErrConflict, alias)
+		}
+	}
+	if !replacing && len(existing) >= MaxEntityAliasesPerMemory {
+		return nil, fmt.Errorf("%w: a memory holds at most %d aliases", model.ErrValidation, MaxEntityAliasesPerMemory)
+	}
+	defer s.aliasMatchers.invalidate(actorID, memoryID)
+	return s.store.EntityAliases().Put(ctx, &model.EntityAlias{ActorID: actorID, VaultID: vaultID, MemoryID: memoryID, Alias: alias, Canonical: canonical})
+}
+
+// ListEntityAliases returns the memory's aliases grouped by canonical name.
+func (s *MemoryService) ListEntityAliases(ctx context.Context, actorID, vaultID, memoryID string) ([]*model.EntityAlias, error) {
+	if _, err := s.store.Memories().GetByID(ctx, actorID, vaultID, memoryID); err != nil {
+		return nil, err
+	}
+	return s.store.EntityAliases().List(ctx, actorID, memoryID)
+}
+
+// DeleteEntityAlias removes one alias; entries already annotated keep their
+// entity names.
+func (s *MemoryService) DeleteEntityAlias(ctx context.Context, actorID, vaultID, memoryID, alias string) error {
+	if err := ensureVaultWritable(ctx, s.store, actorID, vaultID); err != nil {
+		return err
+	}
+	defer s.aliasMatchers.invalidate(actorID, memoryID)
+	return s.store.EntityAliases().Delete(ctx, actorID, vaultID, memoryID, strings.TrimSpace(alias))
+}
+
+// ExpandSearchQuery appends to query the other names of every registered
+// entity it mentions. The query is returned unchanged when it mentions none.
+func (s *MemoryService) ExpandSearchQuery(ctx context.Context, actorID, memoryID, query string) (string, error) {
+	aliases, err := s.store.EntityAliases().List(ctx, actorID, memoryID)
+	if err != nil {
+		return query, err
+	}
+	return s.aliasMatchers.get(actorID, memoryID, aliases).expand(query), nil
+}
+
+// entity is one canonical name with all of its aliases.
+type entity struct {
+	canonical string
+	names     []string         // canonical first
+	patterns  []*regexp.Regexp // one per name, see mentions
+}
+
+// entityMatcher finds the entities of one alias set in text. Building it
+// compiles a pattern per name, so services reuse it per memory.
+type entityMatcher struct {
+	entities []*entity
+}
+
+// newEntityMatcher groups aliases by canonical name, in first-seen order,
+// and compiles their names.
+func newEntityMatcher(aliases []*model.EntityAlias) *entityMatcher {
+	byKey := map[string]*entity{}
+	m := &entityMatcher{}
+	for _, a := range aliases {
+		key := strings.ToLower(a.Canonical)
+		e, ok := byKey[key]
+		if !ok {
+			e = &entity{canonical: a.Canonical, names: []string{a.Canonical}, patterns: []*regexp.Regexp{namePattern(a.Canonical)}}
+			byKey[key] = e
+			m.entities = append(m.entities, e)
+		}
+		e.names = append(e.names, a.Alias)
+		e.patterns = append(e.patterns, namePattern(a.Alias))
+	}
+	return m
+}
+
+// namePattern matches name in text as a whole word, ignoring case.
+func namePattern(name string) *regexp.Regexp {
+	return regexp.MustCompile(`(?i)(^|\W)` + regexp.QuoteMeta(name) + `($|\W)`)
+}
+
+// ExpandQuery appends to query, for every entity it mentions by any name,
+// the entity's names it does not mention, so keyword and vector search also
+// match entries that use another name.
+func ExpandQuery(query string, aliases []*model.EntityAlias) string {
+	return newEntityMatcher(aliases).expand(query)
+}
+
+func (m *entityMatcher) expand(query string) string {
+	var extra []string
+	for _, e := range m.entities {
+		var missing []string
+		mentioned := false
+		for i, p := range e.patterns {
+			if p.MatchString(query) {
+				mentioned = true
+			} else {
+				missing = append(missing, e.names[i])
+			}
+		}
+		if mentioned {
+			extra = append(extra, missing...)
+		}
+	}
+	if len(extra) == 0 {
+		return query
+	}
+	return query + " " + strings.Join(extra, " ")
+}
+
+// MentionedEntities returns, sorted, the canonical names of the entities
+// text mentions by any of their names.
+func MentionedEntities(text string, aliases []*model.EntityAlias) []string {
+	return newEntityMatcher(aliases).mentioned(text)
+}
+
+func (m *entityMatcher) mentioned(text string) []string {
+	var out []string
+	for _, e := range m.entities {
+		for _, p := range e.patterns {
+			if p.MatchString(text) {
+				out = append(out, e.canonical)
+				break
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// maxCachedMatchers bounds aliasMatcherCache; it starts over when full.
+const maxCachedMatchers = 1000
+
+// aliasMatcherCache keeps the compiled entityMatcher of each memory's alias
+// set. Alias writes through the service invalidate the memory's entry; an
+// entry is also rebuilt when the listed aliases differ from the ones it was
+// built from, e.g. after another replica changed them. The zero value is
+// ready to use.
+type aliasMatcherCache struct {
+	mu       sync.Mutex
+	matchers map[string]cachedMatcher
+}
+
+type cachedMatcher struct {
+	signature string
+	matcher   *entityMatcher
+}
+
+// get returns the matcher of the memory's aliases, building it unless the
+// cache holds one built from the same aliases.
+func (c *aliasMatcherCache) get(actorID, memoryID string, aliases []*model.EntityAlias) *entityMatcher {
+	var sig strings.Builder
+	for _, a := range aliases {
+		sig.WriteString(a.Alias)
+		sig.WriteByte(0)
+		sig.WriteString(a.Canonical)
+		sig.WriteByte(0)
+	}
+	key := actorID + "\x00" + memoryID
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cm, ok := c.matchers[key]; ok && cm.signature == sig.String() {
+		return cm.matcher
+	}
+	if c.matchers == nil || len(c.matchers) >= maxCachedMatchers {
+		c.matchers = map[string]cachedMatcher{}
+	}
+	m := newEntityMatcher(aliases)
+	c.matchers[key] = cachedMatcher{signature: sig.String(), matcher: m}
+	return m
+}
+
+// invalidate drops the memory's matcher.
+func (c *aliasMatcherCache) invalidate(actorID, memoryID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.matchers, actorID+"\x00"+memoryID)
+}
+
+// annotateEntities normalizes the entities the entries mention: the
+// canonical names found in each raw entry or summary are listed under
+// EntitiesMetadataKey. All entries must belong to one memory; metadata the
+// caller already set under that key is kept. Matchers come from cache.
+func annotateEntities(ctx context.Context, st store.Store, cache *aliasMatcherCache, entries ...*model.MemoryEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	aliases, err := st.EntityAliases().List(ctx, entries[0].ActorID, entries[0].MemoryID)
+	if err != nil || len(aliases) == 0 {
+		return err
+	}
+	matcher := cache.get(entries[0].ActorID, entries[0].MemoryID, aliases)
+	for _, e := range entries {
+		if _, set := e.Metadata[EntitiesMetadataKey]; set {
+			continue
+		}
+		text := e.RawEntry
+		if e.Summary != nil {
+			text += "\n" + *e.Summary
+		}
+		names := matcher.mentioned(text)
+		if len(names) == 0 {
+			continue
+		}
+		meta := make(map[string]interface{}, len(e.Metadata)+1)
+		for k, v := range e.Metadata {
+			meta[k] = v
+		}
+		meta[EntitiesMetadataKey] = names
+		e.Metadata = meta
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+func TestEntityAliasRegistry(t *testing.T) {
+	fs := &fakeStore{}
+	svc := NewMemoryService(fs, &fakeIndex{}, nil)
+	ctx := context.Background()
+
+	if _, err := svc.PutEntityAlias(ctx, "u1", "v1", "m1", "Bob", "Robert Smith"); err != nil {
+		t.Fatalf("PutEntityAlias: %v", err)
+	}
+	// A canonical name that is an alias resolves to its entity.
+	rob, err := svc.PutEntityAlias(ctx, "u1", "v1", "m1", "Rob", "bob")
+	if err != nil || rob.Canonical != "Robert Smith" {
+		t.Fatalf("PutEntityAlias(Rob) = %+v, %v", rob, err)
+	}
+	if _, err := svc.PutEntityAlias(ctx, "u1", "v1", "m1", "robert smith", "Bobby"); !errors.Is(err, model.ErrConflict) {
+		t.Fatalf("aliasing a canonical name: want ErrConflict, got %v", err)
+	}
+	if _, err := svc.PutEntityAlias(ctx, "u1", "v1", "m1", "Ann", "ann"); !errors.Is(err, model.ErrValidation) {
+		t.Fatalf("alias equal to canonical: want ErrValidation, got %v", err)
+	}
+
+	q := ExpandQuery("where does bob live?", fs.aliases)
+	if q != "where does bob live? Robert Smith Rob" {
+		t.Fatalf("ExpandQuery = %q", q)
+	}
+	if q := ExpandQuery("Bobby tables", fs.aliases); q != "Bobby tables" {
+		t.Fatalf("ExpandQuery matched inside a word: %q", q)
+	}
+
+	e, err := svc.CreateEntry(ctx, &model.MemoryEntry{ActorID: "u1", VaultID: "v1", MemoryID: "m1", RawEntry: "Rob moved to Lisbon"})
+	if err != nil {
+		t.Fatalf("CreateEntry: %v", err)
+	}
+	if got := e.Metadata[EntitiesMetadataKey]; !reflect.DeepEqual(got, []string{"Robert Smith"}) {
+		t.Fatalf("entities metadata = %v", got)
+	}
+
+	if err := svc.DeleteEntityAlias(ctx, "u1", "v1", "m1", "ROB"); err != nil {
+		t.Fatalf("DeleteEntityAlias: %v", err)
+	}
+	if err := svc.DeleteEntityAlias(ctx, "u1", "v1", "m1", "Rob"); !errors.Is(err, model.ErrNotFound) {
+		t.Fatalf("second delete: want ErrNotFound, got %v", err)
+	}
+}
+
+func TestAliasMatcherCache(t *testing.T) {
+	fs := &fakeStore{}
+	svc := NewMemoryService(fs, &fakeIndex{}, nil)
+	ctx := context.Background()
+	if _, err := svc.PutEntityAlias(ctx, "u1", "v1", "m1", "Bob", "Robert Smith"); err != nil {
+		t.Fatalf("PutEntityAlias: %v", err)
+	}
+	if q, _ := svc.ExpandSearchQuery(ctx, "u1", "m1", "bob?"); q != "bob? Robert Smith" {
+		t.Fatalf("ExpandSearchQuery = %q", q)
+	}
+	first := svc.aliasMatchers.matchers["u1\x00m1"].matcher
+	if _, _ = svc.ExpandSearchQuery(ctx, "u1", "m1", "bob?"); svc.aliasMatchers.matchers["u1\x00m1"].matcher != first {
+		t.Fatal("matcher rebuilt for an unchanged alias set")
+	}
+
+	// An alias write invalidates the memory's matcher.
+	if _, err := svc.PutEntityAlias(ctx, "u1", "v1", "m1", "Rob", "Robert Smith"); err != nil {
+		t.Fatalf("PutEntityAlias: %v", err)
+	}
+	if _, ok := svc.aliasMatchers.matchers["u1\x00m1"]; ok {
+		t.Fatal("alias write kept the cached matcher")
+	}
+	if q, _ := svc.ExpandSearchQuery(ctx, "u1", "m1", "bob?"); q != "bob? Robert Smith Rob" {
+		t.Fatalf("after Put: %q", q)
+	}
+
+	// Aliases changed elsewhere, e.g. by another replica, rebuild it too.
+	fs.aliases = fs.aliases[:1]
+	if q, _ := svc.ExpandSearchQuery(ctx, "u1", "m1", "bob?"); q != "bob? Robert Smith" {
+		t.Fatalf("after an outside change: %q", q)
+	}
+}
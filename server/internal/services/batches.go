@@ -0,0 +1,61 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/searchindex"
+	"github.com/mycelian/mycelian-memory/server/internal/store"
+)
+
+// IngestionBatchService manages the ingestion batch registry used for entry
+// provenance and whole-batch rollback.
+type IngestionBatchService struct {
+	store store.Store
+	idx   searchindex.Index
+}
+
+func NewIngestionBatchService(s store.Store, idx searchindex.Index) *IngestionBatchService {
+	return &IngestionBatchService{store: s, idx: idx}
+}
+
+func (s *IngestionBatchService) CreateBatch(ctx context.Context, b *model.IngestionBatch) (*model.IngestionBatch, error) {
+	if b.SourceSystem == "" {
+		return nil, fmt.Errorf("%w: sourceSystem is required", model.ErrValidation)
+	}
+	return s.store.IngestionBatches().Create(ctx, b)
+}
+
+func (s *IngestionBatchService) GetBatch(ctx context.Context, actorID, batchID string) (*model.IngestionBatch, error) {
+	return s.store.IngestionBatches().Get(ctx, actorID, batchID)
+}
+
+func (s *IngestionBatchService) ListBatches(ctx context.Context, actorID string) ([]*model.IngestionBatch, error) {
+	return s.store.IngestionBatches().List(ctx, actorID)
+}
+
+func (s *IngestionBatchService) ListBatchEntries(ctx context.Context, actorID, batchID string, limit int) ([]*model.MemoryEntry, error) {
+	if _, err := s.store.IngestionBatches().Get(ctx, actorID, batchID); err != nil {
+		return nil, err
+	}
+	return s.store.IngestionBatches().ListEntries(ctx, actorID, batchID, limit)
+}
+
+// RollbackBatch deletes all entries written by the batch in one storage
+// transaction, then propagates the deletes to the index synchronously (the
+// outbox rows written by the store repeat them if this fails).
+func (s *IngestionBatchService) RollbackBatch(ctx context.Context, actorID, batchID string) ([]string, error) {
+	ids, err := s.store.IngestionBatches().Rollback(ctx, actorID, batchID)
+	if err != nil {
+		return nil, err
+	}
+	if s.idx != nil {
+		for _, id := range ids {
+			if err := s.idx.DeleteEntry(ctx, actorID, id); err != nil {
+				return ids, err
+			}
+		}
+	}
+	return ids, nil
+}
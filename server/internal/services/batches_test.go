@@ -0,0 +1,70 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+type fakeBatches struct {
+	batches    map[string]*model.IngestionBatch
+	entryIDs   map[string][]string
+	rolledBack []string
+}
+
+func (f *fakeBatches) Create(_ context.Context, b *model.IngestionBatch) (*model.IngestionBatch, error) {
+	out := *b
+	out.Status = model.IngestionBatchOpen
+	f.batches[out.BatchID] = &out
+	return &out, nil
+}
+func (f *fakeBatches) Get(_ context.Context, _ string, batchID string) (*model.IngestionBatch, error) {
+	if b, ok := f.batches[batchID]; ok {
+		return b, nil
+	}
+	return nil, model.ErrNotFound
+}
+func (f *fakeBatches) List(context.Context, string) ([]*model.IngestionBatch, error) { return nil, nil }
+func (f *fakeBatches) ListEntries(context.Context, string, string, int) ([]*model.MemoryEntry, error) {
+	return nil, nil
+}
+func (f *fakeBatches) Rollback(_ context.Context, _ string, batchID string) ([]string, error) {
+	b, ok := f.batches[batchID]
+	if !ok {
+		return nil, model.ErrNotFound
+	}
+	b.Status = model.IngestionBatchRolledBack
+	f.rolledBack = append(f.rolledBack, batchID)
+	return f.entryIDs[batchID], nil
+}
+
+func TestIngestionBatchService_Rollback(t *testing.T) {
+	fb := &fakeBatches{batches: map[string]*model.IngestionBatch{}, entryIDs: map[string][]string{"b1": {"e1", "e2"}}}
+	idx := &fakeIndex{}
+	svc := NewIngestionBatchService(&fakeStore{batches: fb}, idx)
+	ctx := context.Background()
+
+	if _, err := svc.CreateBatch(ctx, &model.IngestionBatch{ActorID: "u1", BatchID: "b1"}); !errors.Is(err, model.ErrValidation) {
+		t.Fatalf("expected validation error without sourceSystem, got %v", err)
+	}
+	if _, err := svc.CreateBatch(ctx, &model.IngestionBatch{ActorID: "u1", BatchID: "b1", SourceSystem: "mem0"}); err != nil {
+		t.Fatalf("CreateBatch: %v", err)
+	}
+
+	ids, err := svc.RollbackBatch(ctx, "u1", "b1")
+	if err != nil {
+		t.Fatalf("RollbackBatch: %v", err)
+	}
+	if !reflect.DeepEqual(ids, []string{"e1", "e2"}) || !reflect.DeepEqual(idx.deletedEntries, []string{"e1", "e2"}) {
+		t.Fatalf("unexpected deletes: ids=%v index=%v", ids, idx.deletedEntries)
+	}
+	if _, err := svc.RollbackBatch(ctx, "u1", "missing"); !errors.Is(err, model.ErrNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+	if _, err := svc.ListBatchEntries(ctx, "u1", "missing", 10); !errors.Is(err, model.ErrNotFound) {
+		t.Fatalf("expected not found listing unknown batch, got %v", err)
+	}
+}
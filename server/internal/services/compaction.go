@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/store"
+)
+
+// ContextRetention controls how context history is thinned. Snapshots newer
+// than KeepAllDays are all kept; between KeepAllDays and KeepDailyDays only
+// the newest snapshot of each UTC day is kept; older history keeps the newest
+// snapshot of each ISO week. The latest context of a memory is always kept.
+type ContextRetention struct {
+	KeepAllDays   int
+	KeepDailyDays int
+}
+
+// compactionPageSize bounds how many memories are loaded per candidate query.
+const compactionPageSize = 200
+
+// ContextCompactor periodically applies a ContextRetention policy to every memory.
+type ContextCompactor struct {
+	store  store.Store
+	policy ContextRetention
+	log    zerolog.Logger
+}
+
+func NewContextCompactor(s store.Store, policy ContextRetention, log zerolog.Logger) *ContextCompactor {
+	if policy.KeepAllDays < 1 {
+		policy.KeepAllDays = 1
+	}
+	if policy.KeepDailyDays < policy.KeepAllDays {
+		policy.KeepDailyDays = policy.KeepAllDays
+	}
+	return &ContextCompactor{store: s, policy: policy, log: log}
+}
+
+// Start runs a compaction pass immediately and then every interval until ctx is done.
+func (c *ContextCompactor) Start(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		start := time.Now()
+		n, err := c.RunOnce(ctx, start)
+		if err != nil && ctx.Err() == nil {
+			c.log.Warn().Err(err).Int("deleted", n).Msg("context compaction pass failed")
+		} else if n > 0 {
+			c.log.Info().Int("deleted", n).Dur("elapsed", time.Since(start)).Msg("context compaction pass completed")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// RunOnce compacts every memory with history older than the keep-all window
+// and returns the number of snapshots deleted.
+func (c *ContextCompactor) RunOnce(ctx context.Context, now time.Time) (int, error) {
+	cutoff := now.AddDate(0, 0, -c.policy.KeepAllDays)
+	deleted := 0
+	var after model.MemoryRef
+	for {
+		refs, err := c.store.Contexts().CompactionCandidates(ctx, cutoff, after, compactionPageSize)
+		if err != nil {
+			return deleted, err
+		}
+		for _, ref := range refs {
+			snaps, err := c.store.Contexts().Snapshots(ctx, ref, cutoff)
+			if err != nil {
+				return deleted, err
+			}
+			drop := contextsToDrop(snaps, now, c.policy)
+			if len(drop) == 0 {
+				continue
+			}
+			n, err := c.store.Contexts().DeleteMany(ctx, ref, drop)
+			deleted += n
+			if err != nil {
+				return deleted, err
+			}
+		}
+		if len(refs) < compactionPageSize {
+			return deleted, nil
+		}
+		after = refs[len(refs)-1]
+	}
+}
+
+// contextsToDrop picks the snapshots the policy does not retain. snaps must
+// be ordered newest first so the first snapshot seen in a bucket is kept.
+func contextsToDrop(snaps []model.ContextSnapshot, now time.Time, p ContextRetention) []string {
+	keepAll := now.AddDate(0, 0, -p.KeepAllDays)
+	daily := now.AddDate(0, 0, -p.KeepDailyDays)
+	seen := make(map[string]bool)
+	var drop []string
+	for _, s := range snaps {
+		t := s.CreationTime.UTC()
+		if !t.Before(keepAll) {
+			continue
+		}
+		var bucket string
+		if !t.Before(daily) {
+			bucket = t.Format("d2006-01-02")
+		} else {
+			y, w := t.ISOWeek()
+			bucket = fmt.Sprintf("w%d-%02d", y, w)
+		}
+		if seen[bucket] {
+			drop = append(drop, s.ContextID)
+			continue
+		}
+		seen[bucket] = true
+	}
+	return drop
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/store"
+)
+
+func TestContextsToDrop(t *testing.T) {
+	now := time.Date(2025, 6, 30, 12, 0, 0, 0, time.UTC)
+	p := ContextRetention{KeepAllDays: 7, KeepDailyDays: 30}
+	at := func(daysAgo, hour int) time.Time {
+		return time.Date(2025, 6, 30-daysAgo, hour, 0, 0, 0, time.UTC)
+	}
+	// Newest first, as returned by the store.
+	snaps := []model.ContextSnapshot{
+		{ContextID: "recent", CreationTime: at(1, 9)},
+		{ContextID: "recent-older", CreationTime: at(1, 8)},
+		{ContextID: "d10-late", CreationTime: at(10, 18)},
+		{ContextID: "d10-early", CreationTime: at(10, 6)},
+		{ContextID: "d11", CreationTime: at(11, 6)},
+		{ContextID: "w-thu", CreationTime: time.Date(2025, 5, 22, 10, 0, 0, 0, time.UTC)},
+		{ContextID: "w-tue", CreationTime: time.Date(2025, 5, 20, 10, 0, 0, 0, time.UTC)},
+		{ContextID: "w-prev", CreationTime: time.Date(2025, 5, 16, 10, 0, 0, 0, time.UTC)},
+	}
+	got := contextsToDrop(snaps, now, p)
+	want := []string{"d10-early", "w-tue"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("drop: got %v, want %v", got, want)
+	}
+}
+
+type compactionContexts struct {
+	store.Contexts
+	snaps   map[string][]model.ContextSnapshot // memoryID -> newest first
+	deleted []string
+}
+
+func (c *compactionContexts) CompactionCandidates(_ context.Context, _ time.Time, after model.MemoryRef, limit int) ([]model.MemoryRef, error) {
+	var ids []string
+	for id := range c.snaps {
+		if id > after.MemoryID {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	out := make([]model.MemoryRef, len(ids))
+	for i, id := range ids {
+		out[i] = model.MemoryRef{ActorID: "a", VaultID: "v", MemoryID: id}
+	}
+	return out, nil
+}
+
+func (c *compactionContexts) Snapshots(_ context.Context, ref model.MemoryRef, before time.Time) ([]model.ContextSnapshot, error) {
+	var out []model.ContextSnapshot
+	for _, s := range c.snaps[ref.MemoryID] {
+		if s.CreationTime.Before(before) {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+func (c *compactionContexts) DeleteMany(_ context.Context, _ model.MemoryRef, ids []string) (int, error) {
+	c.deleted = append(c.deleted, ids...)
+	return len(ids), nil
+}
+
+type compactionStore struct {
+	*fakeStore
+	c *compactionContexts
+}
+
+func (s compactionStore) Contexts() store.Contexts { return s.c }
+
+func TestContextCompactor_RunOnce(t *testing.T) {
+	now := time.Date(2025, 6, 30, 12, 0, 0, 0, time.UTC)
+	old := now.AddDate(0, 0, -20)
+	cc := &compactionContexts{snaps: map[string][]model.ContextSnapshot{
+		"m1": {{ContextID: "keep", CreationTime: old.Add(time.Hour)}, {ContextID: "drop", CreationTime: old}},
+		"m2": {{ContextID: "only", CreationTime: old}},
+	}}
+	c := NewContextCompactor(compactionStore{&fakeStore{}, cc}, ContextRetention{KeepAllDays: 7, KeepDailyDays: 30}, zerolog.Nop())
+	n, err := c.RunOnce(context.Background(), now)
+	if err != nil || n != 1 || len(cc.deleted) != 1 || cc.deleted[0] != "drop" {
+		t.Fatalf("RunOnce: n=%d deleted=%v err=%v", n, cc.deleted, err)
+	}
+}
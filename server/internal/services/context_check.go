@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+// Context check limits.
+const (
+	DefaultContextCheckEntries = 50
+	MaxContextCheckEntries     = 200
+	// contextCoverageThreshold is the share of an entry's terms a context
+	// must contain to count as reflecting it.
+	contextCoverageThreshold = 0.5
+	maxSuggestionText        = 200
+	maxSuggestionTerms       = 10
+)
+
+// CheckContext compares text, a context about to be saved, with the
+// memory's newest entries (at most limit; <= 0 uses the default). An entry
+// is reflected when text holds at least half of its summary's keyword terms
+// (the raw entry's when it has no summary). Unreflected entries written
+// since the previous context are suggested as new; older ones are suggested
+// as dropped only if the previous context reflected them.
+func (s *MemoryService) CheckContext(ctx context.Context, userID, vaultID, memoryID, text string, limit int) (*model.ContextCheck, error) {
+	if limit <= 0 {
+		limit = DefaultContextCheckEntries
+	}
+	if limit > MaxContextCheckEntries {
+		return nil, fmt.Errorf("%w: at most %d entries can be checked", model.ErrValidation, MaxContextCheckEntries)
+	}
+	latest, err := s.store.Contexts().LatestForMemories(ctx, userID, []string{memoryID})
+	if err != nil {
+		return nil, err
+	}
+	prev := latest[memoryID]
+	entries, err := s.store.Entries().List(ctx, model.ListEntriesRequest{ActorID: userID, VaultID: vaultID, MemoryID: memoryID, Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+
+	out := &model.ContextCheck{EntriesChecked: len(entries), Suggestions: []model.ContextSuggestion{}}
+	dropped := 0
+	for _, e := range entries {
+		entryText := e.RawEntry
+		if e.Summary != nil && *e.Summary != "" {
+			entryText = *e.Summary
+		}
+		m := MatchTerms(entryText, text)
+		cov, ok := termCoverage(m)
+		if !ok || cov >= contextCoverageThreshold {
+			continue
+		}
+		reason := model.ContextSuggestionNew
+		if prev != nil && !e.CreationTime.After(prev.CreationTime) {
+			if prevCov, _ := termCoverage(MatchTerms(entryText, prev.Context)); prevCov < contextCoverageThreshold {
+				continue
+			}
+			reason = model.ContextSuggestionDropped
+			dropped++
+		}
+		missing := m.Missing
+		if len(missing) > maxSuggestionTerms {
+			missing = missing[:maxSuggestionTerms]
+		}
+		out.Suggestions = append(out.Suggestions, model.ContextSuggestion{
+			EntryID:      e.EntryID,
+			Text:         truncateRunes(entryText, maxSuggestionText),
+			CreationTime: e.CreationTime,
+			Coverage:     cov,
+			MissingTerms: missing,
+			Reason:       reason,
+		})
+	}
+	if dropped > 0 {
+		out.Warnings = append(out.Warnings, fmt.Sprintf("%d entries reflected in the previous context are missing from this one", dropped))
+	}
+	if prev != nil && len(text) < len(prev.Context)/2 {
+		out.Warnings = append(out.Warnings, fmt.Sprintf("context is %d%% shorter than the previous one", 100-100*len(text)/len(prev.Context)))
+	}
+	return out, nil
+}
+
+// termCoverage is the share of m's keyword terms that matched; false when
+// there are none.
+func termCoverage(m TermMatch) (float64, bool) {
+	n := len(m.Matched) + len(m.Missing)
+	if n == 0 {
+		return 0, false
+	}
+	return float64(len(m.Matched)) / float64(n), true
+}
+
+// truncateRunes cuts s to at most n runes, marking the cut with "…".
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n]) + "…"
+}
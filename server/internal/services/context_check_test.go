@@ -0,0 +1,51 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+func TestCheckContext(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	fs := &fakeStore{
+		entriesByMem: map[string][]*model.MemoryEntry{"m1": {
+			{EntryID: "fresh", RawEntry: "Customer prefers invoices by email", CreationTime: t0.Add(2 * time.Hour)},
+			{EntryID: "kept", RawEntry: "Deadline moved to March", CreationTime: t0.Add(-time.Hour)},
+			{EntryID: "lost", RawEntry: "Budget approved for Kubernetes migration", CreationTime: t0.Add(-2 * time.Hour)},
+			{EntryID: "ignored", RawEntry: "Weather was sunny today", CreationTime: t0.Add(-3 * time.Hour)},
+		}},
+		ctxByMem: map[string]*model.MemoryContext{"m1": {
+			MemoryID:     "m1",
+			Context:      "Deadline moved to March. Budget approved for the Kubernetes migration. Team is small and focused on delivery.",
+			CreationTime: t0,
+		}},
+	}
+	svc := NewMemoryService(fs, nil, nil)
+	ctx := context.Background()
+
+	check, err := svc.CheckContext(ctx, "u1", "v1", "m1", "Deadline moved to March.", 0)
+	if err != nil {
+		t.Fatalf("CheckContext: %v", err)
+	}
+	if check.EntriesChecked != 4 || len(check.Suggestions) != 2 {
+		t.Fatalf("expected 2 suggestions out of 4 entries, got %+v", check)
+	}
+	got := map[string]string{}
+	for _, s := range check.Suggestions {
+		got[s.EntryID] = s.Reason
+	}
+	if got["fresh"] != model.ContextSuggestionNew || got["lost"] != model.ContextSuggestionDropped {
+		t.Fatalf("unexpected suggestions: %+v", check.Suggestions)
+	}
+	if len(check.Warnings) != 2 {
+		t.Fatalf("expected dropped-entry and shrink warnings, got %v", check.Warnings)
+	}
+
+	if _, err := svc.CheckContext(ctx, "u1", "v1", "m1", "x", MaxContextCheckEntries+1); !errors.Is(err, model.ErrValidation) {
+		t.Fatalf("expected ErrValidation above the entry limit, got %v", err)
+	}
+}
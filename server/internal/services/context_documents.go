@@ -0,0 +1,119 @@
+package services
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"unicode/utf8"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+// ErrContextDocumentTooLarge is returned when a context document decompresses
+// to more than the allowed size.
+var ErrContextDocumentTooLarge = errors.New("context document exceeds the maximum size")
+
+// CreateContextDocument starts the upload of a full context version that is
+// too large to be the memory's active context.
+func (s *MemoryService) CreateContextDocument(ctx context.Context, actorID, vaultID, memoryID string) (*model.ContextDocument, error) {
+	if err := ensureVaultWritable(ctx, s.store, actorID, vaultID); err != nil {
+		return nil, err
+	}
+	return s.store.ContextDocuments().Create(ctx, &model.ContextDocument{ActorID: actorID, VaultID: vaultID, MemoryID: memoryID})
+}
+
+// PutContextDocumentPart stores part n of the document's gzip stream,
+// replacing an earlier upload of the same part.
+func (s *MemoryService) PutContextDocumentPart(ctx context.Context, actorID, vaultID, memoryID, documentID string, n int, data []byte) error {
+	if err := ensureVaultWritable(ctx, s.store, actorID, vaultID); err != nil {
+		return err
+	}
+	if _, err := s.store.ContextDocuments().Get(ctx, actorID, vaultID, memoryID, documentID); err != nil {
+		return err
+	}
+	return s.store.ContextDocuments().PutPart(ctx, actorID, documentID, n, data)
+}
+
+// CompleteContextDocument checks that the parts, numbered from 0 without
+// gaps, form a gzip stream of valid UTF-8 no larger than maxBytes once
+// decompressed, and marks the document complete.
+func (s *MemoryService) CompleteContextDocument(ctx context.Context, actorID, vaultID, memoryID, documentID string, maxBytes int64) (*model.ContextDocument, error) {
+	if err := ensureVaultWritable(ctx, s.store, actorID, vaultID); err != nil {
+		return nil, err
+	}
+	doc, err := s.store.ContextDocuments().Get(ctx, actorID, vaultID, memoryID, documentID)
+	if err != nil {
+		return nil, err
+	}
+	if doc.Status != model.ContextDocumentUploading {
+		return nil, fmt.Errorf("%w: context document is already complete", model.ErrConflict)
+	}
+	parts, err := s.store.ContextDocuments().Parts(ctx, actorID, documentID)
+	if err != nil {
+		return nil, err
+	}
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("%w: context document has no parts", model.ErrValidation)
+	}
+	for i, p := range parts {
+		if p.Number != i {
+			return nil, fmt.Errorf("%w: context document part %d is missing", model.ErrValidation, i)
+		}
+	}
+	text, err := decompressParts(parts, maxBytes)
+	if err != nil {
+		return nil, err
+	}
+	if !utf8.Valid(text) {
+		return nil, fmt.Errorf("%w: context document must be valid UTF-8", model.ErrValidation)
+	}
+	sum := sha256.Sum256(text)
+	return s.store.ContextDocuments().Complete(ctx, actorID, documentID, int64(len(text)), hex.EncodeToString(sum[:]))
+}
+
+// ReadContextDocument returns a completed document and its decompressed text.
+func (s *MemoryService) ReadContextDocument(ctx context.Context, actorID, vaultID, memoryID, documentID string) (*model.ContextDocument, []byte, error) {
+	doc, err := s.store.ContextDocuments().Get(ctx, actorID, vaultID, memoryID, documentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if doc.Status != model.ContextDocumentComplete {
+		return nil, nil, fmt.Errorf("%w: context document upload is not complete", model.ErrConflict)
+	}
+	parts, err := s.store.ContextDocuments().Parts(ctx, actorID, documentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	text, err := decompressParts(parts, doc.SizeBytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	return doc, text, nil
+}
+
+// decompressParts gunzips the concatenated parts, failing with
+// ErrContextDocumentTooLarge past maxBytes.
+func decompressParts(parts []model.ContextDocumentPart, maxBytes int64) ([]byte, error) {
+	readers := make([]io.Reader, len(parts))
+	for i, p := range parts {
+		readers[i] = bytes.NewReader(p.Data)
+	}
+	zr, err := gzip.NewReader(io.MultiReader(readers...))
+	if err != nil {
+		return nil, fmt.Errorf("%w: context document is not gzip data: %v", model.ErrValidation, err)
+	}
+	defer func() { _ = zr.Close() }()
+	text, err := io.ReadAll(io.LimitReader(zr, maxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: context document is not valid gzip data: %v", model.ErrValidation, err)
+	}
+	if int64(len(text)) > maxBytes {
+		return nil, ErrContextDocumentTooLarge
+	}
+	return text, nil
+}
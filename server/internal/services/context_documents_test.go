@@ -0,0 +1,115 @@
+package services
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+type fakeDocuments struct {
+	docs  map[string]*model.ContextDocument
+	parts map[string]map[int][]byte
+}
+
+func (f *fakeDocuments) Create(_ context.Context, d *model.ContextDocument) (*model.ContextDocument, error) {
+	out := *d
+	out.DocumentID = "d1"
+	out.Status = model.ContextDocumentUploading
+	f.docs[out.DocumentID] = &out
+	f.parts[out.DocumentID] = map[int][]byte{}
+	return &out, nil
+}
+func (f *fakeDocuments) Get(_ context.Context, _, _, memoryID, documentID string) (*model.ContextDocument, error) {
+	if d, ok := f.docs[documentID]; ok && d.MemoryID == memoryID {
+		return d, nil
+	}
+	return nil, model.ErrNotFound
+}
+func (f *fakeDocuments) PutPart(_ context.Context, _, documentID string, n int, data []byte) error {
+	f.parts[documentID][n] = data
+	return nil
+}
+func (f *fakeDocuments) Parts(_ context.Context, _, documentID string) ([]model.ContextDocumentPart, error) {
+	var out []model.ContextDocumentPart
+	for n, data := range f.parts[documentID] {
+		out = append(out, model.ContextDocumentPart{Number: n, Data: data})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Number < out[j].Number })
+	return out, nil
+}
+func (f *fakeDocuments) Complete(_ context.Context, _, documentID string, size int64, sum string) (*model.ContextDocument, error) {
+	d := f.docs[documentID]
+	d.Status, d.SizeBytes, d.SHA256 = model.ContextDocumentComplete, size, sum
+	return d, nil
+}
+
+func gzipText(t *testing.T, s string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(s)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestContextDocumentUpload(t *testing.T) {
+	ctx := context.Background()
+	newSvc := func() *MemoryService {
+		return NewMemoryService(&fakeStore{docs: &fakeDocuments{docs: map[string]*model.ContextDocument{}, parts: map[string]map[int][]byte{}}}, nil, nil)
+	}
+	text := strings.Repeat("the quick brown fox ", 500)
+	data := gzipText(t, text)
+
+	svc := newSvc()
+	doc, err := svc.CreateContextDocument(ctx, "u1", "v1", "m1")
+	if err != nil {
+		t.Fatalf("CreateContextDocument: %v", err)
+	}
+	if _, _, err := svc.ReadContextDocument(ctx, "u1", "v1", "m1", doc.DocumentID); !errors.Is(err, model.ErrConflict) {
+		t.Fatalf("read before complete: expected conflict, got %v", err)
+	}
+	half := len(data) / 2
+	if err := svc.PutContextDocumentPart(ctx, "u1", "v1", "m1", doc.DocumentID, 1, data[half:]); err != nil {
+		t.Fatalf("PutContextDocumentPart 1: %v", err)
+	}
+	if _, err := svc.CompleteContextDocument(ctx, "u1", "v1", "m1", doc.DocumentID, 1<<20); !errors.Is(err, model.ErrValidation) {
+		t.Fatalf("complete with missing part 0: expected validation error, got %v", err)
+	}
+	if err := svc.PutContextDocumentPart(ctx, "u1", "v1", "m1", doc.DocumentID, 0, data[:half]); err != nil {
+		t.Fatalf("PutContextDocumentPart 0: %v", err)
+	}
+	if err := svc.PutContextDocumentPart(ctx, "u1", "v1", "other", doc.DocumentID, 0, data[:half]); !errors.Is(err, model.ErrNotFound) {
+		t.Fatalf("part for another memory: expected not found, got %v", err)
+	}
+	if _, err := svc.CompleteContextDocument(ctx, "u1", "v1", "m1", doc.DocumentID, int64(len(text)-1)); !errors.Is(err, ErrContextDocumentTooLarge) {
+		t.Fatalf("expected ErrContextDocumentTooLarge, got %v", err)
+	}
+	got, err := svc.CompleteContextDocument(ctx, "u1", "v1", "m1", doc.DocumentID, int64(len(text)))
+	if err != nil || got.SizeBytes != int64(len(text)) || len(got.SHA256) != 64 {
+		t.Fatalf("CompleteContextDocument: got=%+v err=%v", got, err)
+	}
+	if _, out, err := svc.ReadContextDocument(ctx, "u1", "v1", "m1", doc.DocumentID); err != nil || string(out) != text {
+		t.Fatalf("ReadContextDocument: len=%d err=%v", len(out), err)
+	}
+
+	svc = newSvc()
+	doc, _ = svc.CreateContextDocument(ctx, "u1", "v1", "m1")
+	_ = svc.PutContextDocumentPart(ctx, "u1", "v1", "m1", doc.DocumentID, 0, []byte("not gzip"))
+	if _, err := svc.CompleteContextDocument(ctx, "u1", "v1", "m1", doc.DocumentID, 1<<20); !errors.Is(err, model.ErrValidation) {
+		t.Fatalf("non-gzip data: expected validation error, got %v", err)
+	}
+	_ = svc.PutContextDocumentPart(ctx, "u1", "v1", "m1", doc.DocumentID, 0, gzipText(t, "\xff\xfe"))
+	if _, err := svc.CompleteContextDocument(ctx, "u1", "v1", "m1", doc.DocumentID, 1<<20); !errors.Is(err, model.ErrValidation) {
+		t.Fatalf("invalid UTF-8: expected validation error, got %v", err)
+	}
+}
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+// A structured context is a list of named sections, each recording who last
+// changed it and when. Agents can then rewrite one section without touching
+// the others. Its Context text is the rendering of the sections, so search,
+// summaries and plain-text readers keep working.
+
+// Section limits.
+const (
+	MaxContextSections       = 64
+	MaxContextSectionNameLen = 128
+)
+
+// ContextSectionsUpdate writes sections of a memory's structured context.
+// UpdatedBy is recorded as the changed sections' lastUpdatedBy. IfMatch,
+// when set, is the contextId the update was based on; the write fails with
+// model.ErrPrecondition when another context became latest since. MaxChars
+// bounds the rendered context (0 disables the limit).
+type ContextSectionsUpdate struct {
+	ActorID, VaultID, MemoryID string
+	UpdatedBy                  string
+	Sections                   []model.ContextSection
+	IfMatch                    string
+	MaxChars                   int
+}
+
+// RenderContextSections renders sections as the context text: each is a
+// "## name" heading followed by its content.
+func RenderContextSections(sections []model.ContextSection) string {
+	var b strings.Builder
+	for i, s := range sections {
+		if i > 0 {
+			b.WriteString("\n\n")
+		}
+		b.WriteString("## ")
+		b.WriteString(s.Name)
+		b.WriteString("\n\n")
+		b.WriteString(strings.TrimSpace(s.Content))
+	}
+	return b.String()
+}
+
+// PutContextSections replaces the whole structured context with u.Sections.
+// Sections whose content is unchanged from the latest context keep their
+// provenance.
+func (s *MemoryService) PutContextSections(ctx context.Context, u ContextSectionsUpdate) (*model.MemoryContext, error) {
+	if len(u.Sections) == 0 {
+		return nil, fmt.Errorf("%w: sections is required", model.ErrValidation)
+	}
+	if err := validateSections(u.Sections); err != nil {
+		return nil, err
+	}
+	return s.updateSections(ctx, u, func(latest []model.ContextSection) ([]model.ContextSection, error) {
+		previous := make(map[string]model.ContextSection, len(latest))
+		for _, sec := range latest {
+			previous[strings.ToLower(sec.Name)] = sec
+		}
+		now := time.Now().UTC()
+		sections := make([]model.ContextSection, len(u.Sections))
+		for i, sec := range u.Sections {
+			if prev, ok := previous[strings.ToLower(sec.Name)]; ok && prev.Content == sec.Content {
+				sections[i] = prev
+				sections[i].Name = sec.Name
+				continue
+			}
+			sections[i] = model.ContextSection{Name: sec.Name, Content: sec.Content, LastUpdatedBy: u.UpdatedBy, LastUpdatedAt: now}
+		}
+		return sections, nil
+	})
+}
+
+// PutContextSection sets one section of the structured context, adding it at
+// the end when new and leaving the other sections as they are. A memory
+// whose latest context is unstructured text yields model.ErrConflict.
+func (s *MemoryService) PutContextSection(ctx context.Context, u ContextSectionsUpdate) (*model.MemoryContext, error) {
+	if len(u.Sections) != 1 {
+		return nil, fmt.Errorf("%w: exactly one section is required", model.ErrValidation)
+	}
+	if err := validateSections(u.Sections); err != nil {
+		return nil, err
+	}
+	return s.updateSections(ctx, u, func(latest []model.ContextSection) ([]model.ContextSection, error) {
+		update := u.Sections[0]
+		update.LastUpdatedBy, update.LastUpdatedAt = u.UpdatedBy, time.Now().UTC()
+		sections := append([]model.ContextSection(nil), latest...)
+		for i, sec := range sections {
+			if strings.EqualFold(sec.Name, update.Name) {
+				sections[i] = update
+				return sections, nil
+			}
+		}
+		if len(sections) >= MaxContextSections {
+			return nil, fmt.Errorf("%w: a context has at most %d sections", model.ErrValidation, MaxContextSections)
+		}
+		return append(sections, update), nil
+	})
+}
+
+// sectionWriteRetries bounds how often a section update without IfMatch is
+// rebuilt on a newer latest context after losing a race to another writer.
+const sectionWriteRetries = 3
+
+// updateSections writes the sections build derives from the latest context,
+// provided that context is still the latest when they are written. Without
+// IfMatch a concurrent write makes it rebuild on the new latest context, so
+// sections set by another writer are kept rather than overwritten.
+func (s *MemoryService) updateSections(ctx context.Context, u ContextSectionsUpdate, build func(latest []model.ContextSection) ([]model.ContextSection, error)) (*model.MemoryContext, error) {
+	for attempt := 0; ; attempt++ {
+		latest, latestID, err := s.latestForSections(ctx, u)
+		if err != nil {
+			return nil, err
+		}
+		sections, err := build(latest)
+		if err != nil {
+			return nil, err
+		}
+		out, err := s.putSections(ctx, u, sections, latestID)
+		if errors.Is(err, model.ErrPrecondition) && u.IfMatch == "" && attempt < sectionWriteRetries {
+			continue
+		}
+		return out, err
+	}
+}
+
+// latestForSections checks the vault and IfMatch and returns the sections of
+// the latest context, none when the memory has no context yet or only the
+// placeholder written at creation, and that context's ID ("" for none).
+func (s *MemoryService) latestForSections(ctx context.Context, u ContextSectionsUpdate) ([]model.ContextSection, string, error) {
+	if err := ensureVaultWritable(ctx, s.store, u.ActorID, u.VaultID); err != nil {
+		return nil, "", err
+	}
+	byMemory, err := s.store.Contexts().LatestForMemories(ctx, u.ActorID, []string{u.MemoryID})
+	if err != nil {
+		return nil, "", err
+	}
+	latest := byMemory[u.MemoryID]
+	if u.IfMatch != "" && (latest == nil || latest.ContextID != u.IfMatch) {
+		return nil, "", fmt.Errorf("%w: the latest context is no longer %s", model.ErrPrecondition, u.IfMatch)
+	}
+	if latest == nil {
+		return nil, "", nil
+	}
+	if len(latest.Sections) == 0 && !strings.Contains(latest.Context, defaultContextMarker) {
+		return nil, "", fmt.Errorf("%w: the latest context is unstructured; put the full sections first", model.ErrConflict)
+	}
+	return latest.Sections, latest.ContextID, nil
+}
+
+// putSections writes sections as the new context if latestID is still the
+// latest one.
+func (s *MemoryService) putSections(ctx context.Context, u ContextSectionsUpdate, sections []model.ContextSection, latestID string) (*model.MemoryContext, error) {
+	text := RenderContextSections(sections)
+	if u.MaxChars > 0 && utf8.RuneCountInString(text) > u.MaxChars {
+		return nil, fmt.Errorf("%w: rendered context exceeds %d characters", model.ErrValidation, u.MaxChars)
+	}
+	return s.store.Contexts().PutIfLatest(ctx, &model.MemoryContext{
+		ActorID: u.ActorID, VaultID: u.VaultID, MemoryID: u.MemoryID,
+		Context: text, Sections: sections,
+	}, latestID)
+}
+
+// validateSections trims names and checks they are present, single-line,
+// short and unique regardless of case.
+func validateSections(sections []model.ContextSection) error {
+	if len(sections) > MaxContextSections {
+		return fmt.Errorf("%w: a context has at most %d sections", model.ErrValidation, MaxContextSections)
+	}
+	seen := make(map[string]bool, len(sections))
+	for i := range sections {
+		name := strings.TrimSpace(sections[i].Name)
+		switch {
+		case name == "":
+			return fmt.Errorf("%w: section name is required", model.ErrValidation)
+		case strings.ContainsAny(name, "\r\n"):
+			return fmt.Errorf("%w: section name %q must be a single line", model.ErrValidation, name)
+		case utf8.RuneCountInString(name) > MaxContextSectionNameLen:
+			return fmt.Errorf("%w: section name exceeds %d characters", model.ErrValidation, MaxContextSectionNameLen)
+		case seen[strings.ToLower(name)]:
+			return fmt.Errorf("%w: duplicate section %q", model.ErrValidation, name)
+		}
+		seen[strings.ToLower(name)] = true
+		sections[i].Name = name
+	}
+	return nil
+}
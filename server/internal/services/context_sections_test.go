@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/store"
+)
+
+func TestContextSections(t *testing.T) {
+	ctx := context.Background()
+	fs := &fakeStore{ctxByMem: map[string]*model.MemoryContext{"m1": {Context: defaultContextMarker}}}
+	svc := NewMemoryService(fs, nil, nil)
+	base := ContextSectionsUpdate{ActorID: "a", VaultID: "v1", MemoryID: "m1", UpdatedBy: "alice"}
+
+	u := base
+	u.Sections = []model.ContextSection{{Name: " Facts ", Content: "CEO = Bob"}, {Name: "Tasks", Content: "ship"}}
+	mc, err := svc.PutContextSections(ctx, u)
+	if err != nil {
+		t.Fatalf("put sections: %v", err)
+	}
+	if want := "## Facts\n\nCEO = Bob\n\n## Tasks\n\nship"; mc.Context != want {
+		t.Fatalf("rendered %q, want %q", mc.Context, want)
+	}
+	tasksAt := mc.Sections[1].LastUpdatedAt
+
+	u = base
+	u.UpdatedBy, u.IfMatch = "bob", mc.ContextID
+	u.Sections = []model.ContextSection{{Name: "facts", Content: "CEO = Alice"}}
+	mc, err = svc.PutContextSection(ctx, u)
+	if err != nil {
+		t.Fatalf("put section: %v", err)
+	}
+	if len(mc.Sections) != 2 || mc.Sections[0].Content != "CEO = Alice" || mc.Sections[0].LastUpdatedBy != "bob" {
+		t.Fatalf("section not replaced: %+v", mc.Sections)
+	}
+	if got := mc.Sections[1]; got.LastUpdatedBy != "alice" || !got.LastUpdatedAt.Equal(tasksAt) {
+		t.Fatalf("untouched section lost its provenance: %+v", got)
+	}
+
+	u.IfMatch = "stale"
+	if _, err := svc.PutContextSection(ctx, u); !errors.Is(err, model.ErrPrecondition) {
+		t.Fatalf("stale If-Match: expected ErrPrecondition, got %v", err)
+	}
+	u.IfMatch = ""
+	u.Sections = []model.ContextSection{{Name: "a"}, {Name: "A"}}
+	if _, err := svc.PutContextSections(ctx, u); !errors.Is(err, model.ErrValidation) {
+		t.Fatalf("duplicate names: expected validation error, got %v", err)
+	}
+	u.Sections = []model.ContextSection{{Name: "Notes", Content: "long enough"}}
+	u.MaxChars = 10
+	if _, err := svc.PutContextSection(ctx, u); !errors.Is(err, model.ErrValidation) {
+		t.Fatalf("oversized context: expected validation error, got %v", err)
+	}
+
+	fs.ctxByMem["m1"] = &model.MemoryContext{Context: "free text"}
+	u.MaxChars = 0
+	if _, err := svc.PutContextSection(ctx, u); !errors.Is(err, model.ErrConflict) {
+		t.Fatalf("unstructured context: expected ErrConflict, got %v", err)
+	}
+	fs.readOnly = map[string]bool{"v1": true}
+	if _, err := svc.PutContextSections(ctx, u); !errors.Is(err, model.ErrReadOnly) {
+		t.Fatalf("read-only vault: expected ErrReadOnly, got %v", err)
+	}
+}
+
+// racingStore lets another writer put race, once, between a section
+// update's read of the latest context and its write.
+type racingStore struct {
+	*fakeStore
+	race *model.MemoryContext
+}
+
+func (s *racingStore) Contexts() store.Contexts { return racingContexts{&fakeContexts{s.fakeStore}, s} }
+
+type racingContexts struct {
+	*fakeContexts
+	s *racingStore
+}
+
+func (c racingContexts) PutIfLatest(ctx context.Context, mc *model.MemoryContext, latestID string) (*model.MemoryContext, error) {
+	if c.s.race != nil {
+		c.p.ctxByMem[mc.MemoryID], c.s.race = c.s.race, nil
+	}
+	return c.fakeContexts.PutIfLatest(ctx, mc, latestID)
+}
+
+func TestContextSectionRaceKeepsOtherWriter(t *testing.T) {
+	ctx := context.Background()
+	fs := &fakeStore{ctxByMem: map[string]*model.MemoryContext{"m1": {ContextID: "c0", Context: defaultContextMarker}}}
+	other := &model.MemoryContext{ContextID: "c-other", MemoryID: "m1", Sections: []model.ContextSection{{Name: "Facts", Content: "CEO = Bob"}}}
+	rs := &racingStore{fakeStore: fs, race: other}
+	svc := NewMemoryService(rs, nil, nil)
+	u := ContextSectionsUpdate{ActorID: "a", VaultID: "v1", MemoryID: "m1", UpdatedBy: "alice",
+		Sections: []model.ContextSection{{Name: "Tasks", Content: "ship"}}}
+
+	mc, err := svc.PutContextSection(ctx, u)
+	if err != nil {
+		t.Fatalf("put section: %v", err)
+	}
+	if len(mc.Sections) != 2 || mc.Sections[0].Name != "Facts" || mc.Sections[1].Name != "Tasks" {
+		t.Fatalf("concurrent section lost: %+v", mc.Sections)
+	}
+
+	rs.race = &model.MemoryContext{ContextID: "c-newer", MemoryID: "m1", Sections: mc.Sections}
+	u.IfMatch = mc.ContextID
+	if _, err := svc.PutContextSection(ctx, u); !errors.Is(err, model.ErrPrecondition) {
+		t.Fatalf("race with If-Match: expected ErrPrecondition, got %v", err)
+	}
+}
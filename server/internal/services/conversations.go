@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/store"
+	"github.com/mycelian/mycelian-memory/server/internal/summarizer"
+)
+
+// Limits of one conversation ingest.
+const (
+	MaxConversationMessages   = 500
+	MaxConversationWindowSize = 50
+)
+
+var conversationRoles = map[string]bool{"user": true, "assistant": true, "system": true, "tool": true}
+
+// IngestConversationRequest is a transcript to store as entries of one
+// memory. WindowSize messages go into each entry (1 when zero); SessionID is
+// generated when empty.
+type IngestConversationRequest struct {
+	ActorID, VaultID, MemoryID string
+	SessionID                  string
+	Messages                   []model.ConversationMessage
+	WindowSize                 int
+	Tags                       map[string]interface{}
+	SourceSystem               string
+	IngestionBatchID           string
+}
+
+// IngestConversationResult lists the entries written, in transcript order.
+type IngestConversationResult struct {
+	SessionID string               `json:"sessionId"`
+	Entries   []*model.MemoryEntry `json:"entries"`
+	Count     int                  `json:"count"`
+}
+
+// ConversationService turns chat transcripts into summarized entries.
+type ConversationService struct {
+	store      store.Store
+	summarizer summarizer.Summarizer
+	// aliasMatchers caches each memory's compiled entity aliases.
+	aliasMatchers aliasMatcherCache
+}
+
+func NewConversationService(s store.Store, sum summarizer.Summarizer) *ConversationService {
+	return &ConversationService{store: s, summarizer: sum}
+}
+
+// Ingest splits req.Messages into windows, summarizes them with the prompt
+// of the memory's type and writes one entry per window under a shared
+// session. Every summary is computed before the first write, so a
+// summarizer failure stores nothing. Entries are
+// written one by one; when a write fails the error reports how many were
+// stored, and an ingestion batch lets the caller roll them back.
+func (s *ConversationService) Ingest(ctx context.Context, req IngestConversationRequest) (*IngestConversationResult, error) {
+	if len(req.Messages) == 0 || len(req.Messages) > MaxConversationMessages {
+		return nil, fmt.Errorf("%w: messages must hold 1 to %d messages", model.ErrValidation, MaxConversationMessages)
+	}
+	if req.WindowSize == 0 {
+		req.WindowSize = 1
+	}
+	if req.WindowSize < 1 || req.WindowSize > MaxConversationWindowSize {
+		return nil, fmt.Errorf("%w: windowSize must be between 1 and %d", model.ErrValidation, MaxConversationWindowSize)
+	}
+	for i, m := range req.Messages {
+		if !conversationRoles[m.Role] {
+			return nil, fmt.Errorf("%w: messages[%d].role must be user, assistant, system or tool", model.ErrValidation, i)
+		}
+		if strings.TrimSpace(m.Content) == "" {
+			return nil, fmt.Errorf("%w: messages[%d].content is required", model.ErrValidation, i)
+		}
+	}
+	if err := ensureVaultWritable(ctx, s.store, req.ActorID, req.VaultID); err != nil {
+		return nil, err
+	}
+	if req.SessionID == "" {
+		req.SessionID = uuid.New().String()
+	}
+
+	mem, err := s.store.Memories().GetByID(ctx, req.ActorID, req.VaultID, req.MemoryID)
+	if err != nil && !errors.Is(err, model.ErrNotFound) {
+		return nil, err
+	}
+	var memoryType string
+	var roles []string
+	var ttl int
+	if mem != nil {
+		memoryType, roles, ttl = mem.MemoryType, mem.EntryRoles, mem.EntryTTLSeconds
+	}
+
+	var entries []*model.MemoryEntry
+	var raws []string
+	for start := 0; start < len(req.Messages); start += req.WindowSize {
+		window := req.Messages[start:min(start+req.WindowSize, len(req.Messages))]
+		raw := transcript(window)
+		raws = append(raws, raw)
+		entries = append(entries, &model.MemoryEntry{
+			ActorID: req.ActorID, VaultID: req.VaultID, MemoryID: req.MemoryID,
+			RawEntry: raw, Tags: req.Tags,
+			Metadata:     windowMetadata(window, start),
+			SourceSystem: req.SourceSystem, IngestionBatchID: req.IngestionBatchID, SessionID: req.SessionID,
+			ConversationTime: windowStart(window),
+		})
+	}
+	now := time.Now()
+	for _, e := range entries {
+		if err := setEntryExpiry(e, ttl, now); err != nil {
+			return nil, err
+		}
+		if err := checkEntryRole(roles, e); err != nil {
+			if req.WindowSize > 1 {
+				return nil, fmt.Errorf("%w; entries of several messages have no role, use windowSize 1", err)
+			}
+			return nil, err
+		}
+	}
+
+	summaries, err := s.summarizer.Summarize(ctx, memoryType, raws)
+	if err != nil {
+		return nil, fmt.Errorf("summarize conversation: %w", err)
+	}
+	if len(summaries) != len(entries) {
+		return nil, fmt.Errorf("summarize conversation: got %d summaries for %d entries", len(summaries), len(entries))
+	}
+	for i := range entries {
+		entries[i].Summary = &summaries[i]
+	}
+	if err := annotateEntities(ctx, s.store, &s.aliasMatchers, entries...); err != nil {
+		return nil, err
+	}
+
+	out := &IngestConversationResult{SessionID: req.SessionID, Entries: make([]*model.MemoryEntry, 0, len(entries))}
+	for _, e := range entries {
+		created, err := s.store.Entries().Create(ctx, e)
+		if err != nil {
+			if len(out.Entries) == 0 {
+				return nil, err
+			}
+			return nil, fmt.Errorf("conversation stored partially (%d of %d entries): %w", len(out.Entries), len(entries), err)
+		}
+		out.Entries = append(out.Entries, created)
+	}
+	out.Count = len(out.Entries)
+	return out, nil
+}
+
+// windowStart is the earliest message timestamp of the window; nil when no
+// message carries one.
+func windowStart(msgs []model.ConversationMessage) *time.Time {
+	var first *time.Time
+	for _, m := range msgs {
+		if m.Timestamp != nil && (first == nil || m.Timestamp.Before(*first)) {
+			first = m.Timestamp
+		}
+	}
+	return first
+}
+
+// transcript renders messages as "role: content" lines.
+func transcript(msgs []model.ConversationMessage) string {
+	var b strings.Builder
+	for i, m := range msgs {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(m.Role)
+		b.WriteString(": ")
+		b.WriteString(strings.TrimSpace(m.Content))
+	}
+	return b.String()
+}
+
+// windowMetadata records where the window sits in the transcript and when
+// its messages were sent; single-message windows also carry the role.
+func windowMetadata(msgs []model.ConversationMessage, start int) map[string]interface{} {
+	meta := map[string]interface{}{"messageIndex": start, "messageCount": len(msgs)}
+	if len(msgs) == 1 {
+		meta["role"] = msgs[0].Role
+	}
+	var first, last *time.Time
+	for _, m := range msgs {
+		if m.Timestamp == nil {
+			continue
+		}
+		if first == nil || m.Timestamp.Before(*first) {
+			first = m.Timestamp
+		}
+		if last == nil || m.Timestamp.After(*last) {
+			last = m.Timestamp
+		}
+	}
+	if first != nil {
+		meta["startTime"] = first.UTC().Format(time.RFC3339Nano)
+		meta["endTime"] = last.UTC().Format(time.RFC3339Nano)
+	}
+	return meta
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/summarizer"
+)
+
+type failingSummarizer struct{}
+
+func (failingSummarizer) Summarize(context.Context, string, []string) ([]string, error) {
+	return nil, errors.New("model unavailable")
+}
+
+func TestIngestConversation(t *testing.T) {
+	t0 := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	msgs := []model.ConversationMessage{
+		{Role: "user", Content: "I moved to Lisbon in May. The rent is lower.", Timestamp: &t0},
+		{Role: "assistant", Content: "Noted. Anything else?"},
+		{Role: "user", Content: "My sister visits in June."},
+	}
+	fs := &fakeStore{}
+	svc := NewConversationService(fs, summarizer.Extractive{})
+	ctx := context.Background()
+
+	res, err := svc.Ingest(ctx, IngestConversationRequest{ActorID: "u1", VaultID: "v1", MemoryID: "m1", Messages: msgs, WindowSize: 2})
+	if err != nil {
+		t.Fatalf("Ingest: %v", err)
+	}
+	if res.Count != 2 || res.SessionID == "" || len(fs.entriesByMem["m1"]) != 2 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	first := res.Entries[0]
+	if first.RawEntry != "user: I moved to Lisbon in May. The rent is lower.\nassistant: Noted. Anything else?" {
+		t.Fatalf("unexpected raw entry: %q", first.RawEntry)
+	}
+	if first.Summary == nil || *first.Summary != "user: I moved to Lisbon in May. / assistant: Noted." {
+		t.Fatalf("unexpected summary: %v", first.Summary)
+	}
+	if first.SessionID != res.SessionID || first.Metadata["messageCount"] != 2 || first.Metadata["startTime"] != "2026-03-01T09:00:00Z" {
+		t.Fatalf("unexpected entry: %+v", first)
+	}
+	if second := res.Entries[1]; second.Metadata["role"] != "user" || second.Metadata["messageIndex"] != 2 {
+		t.Fatalf("unexpected second entry metadata: %+v", second.Metadata)
+	}
+	if first.ConversationTime == nil || !first.ConversationTime.Equal(t0) || res.Entries[1].ConversationTime != nil {
+		t.Fatalf("conversation times: first %v, second %v", first.ConversationTime, res.Entries[1].ConversationTime)
+	}
+
+	for name, req := range map[string]IngestConversationRequest{
+		"no messages": {},
+		"bad role":    {Messages: []model.ConversationMessage{{Role: "bot", Content: "x"}}},
+		"empty":       {Messages: []model.ConversationMessage{{Role: "user", Content: "  "}}},
+		"window":      {Messages: msgs, WindowSize: MaxConversationWindowSize + 1},
+	} {
+		if _, err := svc.Ingest(ctx, req); !errors.Is(err, model.ErrValidation) {
+			t.Fatalf("%s: expected ErrValidation, got %v", name, err)
+		}
+	}
+
+	fs = &fakeStore{}
+	_, err = NewConversationService(fs, failingSummarizer{}).Ingest(ctx, IngestConversationRequest{MemoryID: "m1", Messages: msgs})
+	if err == nil || !strings.Contains(err.Error(), "model unavailable") || len(fs.entriesByMem["m1"]) != 0 {
+		t.Fatalf("summarizer failure must store nothing: err=%v entries=%d", err, len(fs.entriesByMem["m1"]))
+	}
+
+	fs = &fakeStore{entryRoles: map[string][]string{"m1": {"user", "assistant"}}}
+	_, err = NewConversationService(fs, summarizer.Extractive{}).Ingest(ctx, IngestConversationRequest{MemoryID: "m1", Messages: msgs, WindowSize: 2})
+	if !errors.Is(err, model.ErrValidation) || len(fs.entriesByMem["m1"]) != 0 {
+		t.Fatalf("windows without a role must store nothing in a role-checked memory: err=%v entries=%d", err, len(fs.entriesByMem["m1"]))
+	}
+
+	fs = &fakeStore{readOnly: map[string]bool{"v1": true}}
+	if _, err := NewConversationService(fs, summarizer.Extractive{}).Ingest(ctx, IngestConversationRequest{VaultID: "v1", Messages: msgs}); !errors.Is(err, model.ErrReadOnly) {
+		t.Fatalf("expected ErrReadOnly, got %v", err)
+	}
+}
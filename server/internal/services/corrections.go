@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"slices"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/outbox/payload"
+)
+
+// CorrectEntry writes correction as a new entry of the corrected entry's
+// memory and marks that entry as corrected by it. Search then returns the
+// correction in place of the original. Append-only memories accept
+// corrections: the original stays as written.
+func (s *MemoryService) CorrectEntry(ctx context.Context, entryID string, correction *model.MemoryEntry, reason string) (*model.MemoryEntry, error) {
+	if err := ensureVaultWritable(ctx, s.store, correction.ActorID, correction.VaultID); err != nil {
+		return nil, err
+	}
+	if err := s.prepareEntry(ctx, correction); err != nil {
+		return nil, err
+	}
+	return s.store.Entries().Correct(ctx, entryID, correction, reason)
+}
+
+// FilterCorrected replaces each hit of a corrected entry with the newest
+// live entry of its correction chain, keeping the hit's score, or drops it
+// when that entry is already among the hits or fails filter, the search's
+// own filter. With keepSuperseded the hit stays and is marked SupersededBy
+// instead. A hit whose corrections were all trashed or expired is left as
+// it is.
+func (s *MemoryService) FilterCorrected(ctx context.Context, userID string, hits []model.SearchHit, keepSuperseded bool, filter model.SearchFilter) ([]model.SearchHit, error) {
+	if len(hits) == 0 {
+		return hits, nil
+	}
+	ids := make([]string, len(hits))
+	for i, h := range hits {
+		ids[i] = h.EntryID
+	}
+	current, err := s.store.Entries().Corrections(ctx, userID, ids)
+	if err != nil || len(current) == 0 {
+		return hits, err
+	}
+	seen := make(map[string]bool, len(hits))
+	for _, h := range hits {
+		if current[h.EntryID] == nil {
+			seen[h.EntryID] = true
+		}
+	}
+	out := hits[:0]
+	for _, h := range hits {
+		c := current[h.EntryID]
+		switch {
+		case c == nil:
+			out = append(out, h)
+		case keepSuperseded:
+			h.SupersededBy = c.EntryID
+			out = append(out, h)
+		case !seen[c.EntryID] && matchesFilter(c, filter):
+			seen[c.EntryID] = true
+			out = append(out, correctionHit(h, c))
+		}
+	}
+	return out, nil
+}
+
+// matchesFilter reports whether e passes filter the way the index applies
+// it: session, creation time window, tag pairs and mustNot. FieldWeights
+// only weigh scores and are ignored.
+func matchesFilter(e *model.MemoryEntry, filter model.SearchFilter) bool {
+	if filter.SessionID != "" && e.SessionID != filter.SessionID {
+		return false
+	}
+	if filter.Since != nil && e.CreationTime.Before(*filter.Since) {
+		return false
+	}
+	if filter.Until != nil && !e.CreationTime.Before(*filter.Until) {
+		return false
+	}
+	if len(filter.Tags) > 0 {
+		pairs := payload.TagPairs(e.Tags)
+		for k, v := range filter.Tags {
+			if !slices.Contains(pairs, k+"="+v) {
+				return false
+			}
+		}
+	}
+	keys := payload.TagKeys(e.Tags)
+	for _, t := range filter.MustNot.Tags {
+		if slices.Contains(keys, t) {
+			return false
+		}
+	}
+	return !filter.MustNot.Excludes(model.SearchHit{EntryID: e.EntryID, MemoryID: e.MemoryID})
+}
+
+// correctionHit is the hit standing in for h, whose entry c corrects.
+func correctionHit(h model.SearchHit, c *model.MemoryEntry) model.SearchHit {
+	created := c.CreationTime
+	out := model.SearchHit{
+		EntryID:      c.EntryID,
+		ActorID:      c.ActorID,
+		MemoryID:     c.MemoryID,
+		RawEntry:     c.RawEntry,
+		Score:        h.Score,
+		CreationTime: &created,
+		SessionID:    c.SessionID,
+		Corrects:     h.EntryID,
+	}
+	if c.Summary != nil {
+		out.Summary = *c.Summary
+	}
+	return out
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/store"
+)
+
+type correctionEntries struct {
+	store.Entries
+	current map[string]*model.MemoryEntry
+}
+
+func (e correctionEntries) Corrections(context.Context, string, []string) (map[string]*model.MemoryEntry, error) {
+	return e.current, nil
+}
+
+type correctionStore struct {
+	*fakeStore
+	e correctionEntries
+}
+
+func (s correctionStore) Entries() store.Entries { return s.e }
+
+func TestFilterCorrected(t *testing.T) {
+	summary := "moved to Lisbon"
+	current := map[string]*model.MemoryEntry{
+		"old":       {EntryID: "new", ActorID: "u1", MemoryID: "m1", RawEntry: "lives in Lisbon", Summary: &summary},
+		"older":     {EntryID: "new", ActorID: "u1", MemoryID: "m1", RawEntry: "lives in Lisbon", Summary: &summary},
+		"withdrawn": nil, // its only correction was trashed
+	}
+	svc := NewMemoryService(correctionStore{&fakeStore{}, correctionEntries{current: current}}, nil, nil)
+	hits := func() []model.SearchHit {
+		return []model.SearchHit{{EntryID: "old", Score: 0.9}, {EntryID: "e1", Score: 0.8}, {EntryID: "older", Score: 0.7}, {EntryID: "withdrawn", Score: 0.6}}
+	}
+
+	got, err := svc.FilterCorrected(context.Background(), "u1", hits(), false, model.SearchFilter{})
+	if err != nil || len(got) != 3 {
+		t.Fatalf("FilterCorrected: %+v %v", got, err)
+	}
+	if got[0].EntryID != "new" || got[0].Corrects != "old" || got[0].Score != 0.9 || got[0].Summary != summary {
+		t.Fatalf("correction must stand in for the corrected hit: %+v", got[0])
+	}
+	if got[1].EntryID != "e1" || got[2].EntryID != "withdrawn" || got[2].Corrects != "" {
+		t.Fatalf("an entry is returned once; a withdrawn correction leaves the original: %+v", got)
+	}
+
+	got, err = svc.FilterCorrected(context.Background(), "u1", hits(), true, model.SearchFilter{})
+	if err != nil || len(got) != 4 || got[0].SupersededBy != "new" || got[2].SupersededBy != "new" || got[3].SupersededBy != "" {
+		t.Fatalf("includeSuperseded: %+v %v", got, err)
+	}
+}
+
+func TestFilterCorrected_DropsCorrectionsFailingTheFilter(t *testing.T) {
+	created := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	correction := &model.MemoryEntry{EntryID: "new", ActorID: "u1", MemoryID: "m1", SessionID: "s2", CreationTime: created,
+		RawEntry: "lives in Lisbon", Tags: map[string]interface{}{"topic": "home", "stale": true}}
+	svc := NewMemoryService(correctionStore{&fakeStore{}, correctionEntries{current: map[string]*model.MemoryEntry{"old": correction}}}, nil, nil)
+	before, after := created.Add(-time.Hour), created.Add(time.Hour)
+
+	for name, tc := range map[string]struct {
+		filter model.SearchFilter
+		kept   bool
+	}{
+		"no filter":        {model.SearchFilter{}, true},
+		"matching filter":  {model.SearchFilter{SessionID: "s2", Tags: map[string]string{"topic": "home", "stale": "true"}, Since: &before, Until: &after}, true},
+		"other session":    {model.SearchFilter{SessionID: "s1"}, false},
+		"before window":    {model.SearchFilter{Since: &after}, false},
+		"until exclusive":  {model.SearchFilter{Until: &created}, false},
+		"tag value":        {model.SearchFilter{Tags: map[string]string{"topic": "work"}}, false},
+		"mustNot tag":      {model.SearchFilter{MustNot: model.SearchMustNot{Tags: []string{"stale"}}}, false},
+		"mustNot entry":    {model.SearchFilter{MustNot: model.SearchMustNot{EntryIDs: []string{"new"}}}, false},
+		"mustNot memory":   {model.SearchFilter{MustNot: model.SearchMustNot{MemoryIDs: []string{"m1"}}}, false},
+		"mustNot othertag": {model.SearchFilter{MustNot: model.SearchMustNot{Tags: []string{"topic"}}}, true},
+	} {
+		got, err := svc.FilterCorrected(context.Background(), "u1", []model.SearchHit{{EntryID: "old", Score: 0.9}, {EntryID: "e1", Score: 0.8}}, false, tc.filter)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if kept := len(got) == 2 && got[0].EntryID == "new"; kept != tc.kept || got[len(got)-1].EntryID != "e1" {
+			t.Fatalf("%s: want correction kept=%v, got %+v", name, tc.kept, got)
+		}
+	}
+
+	got, _ := svc.FilterCorrected(context.Background(), "u1", []model.SearchHit{{EntryID: "old"}}, true, model.SearchFilter{SessionID: "s1"})
+	if len(got) != 1 || got[0].SupersededBy != "new" {
+		t.Fatalf("includeSuperseded still marks the hit: %+v", got)
+	}
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+// entryDedup collapses identical entry creations that arrive within window
+// of each other, as sent by agent frameworks that fire the same tool call
+// twice. The first call writes the entry; the duplicates wait for it and
+// return the same entry.
+type entryDedup struct {
+	window time.Duration
+	now    func() time.Time
+
+	mu    sync.Mutex
+	calls map[string]*dedupCall
+}
+
+// dedupCall is one entry creation that later duplicates can join.
+type dedupCall struct {
+	started time.Time
+	done    chan struct{}
+	entry   *model.MemoryEntry
+	err     error
+}
+
+func newEntryDedup(window time.Duration) *entryDedup {
+	return &entryDedup{window: window, now: time.Now, calls: map[string]*dedupCall{}}
+}
+
+// EnableEntryDedup makes CreateEntry return the entry of an identical
+// creation (same actor, memory and content) started less than window
+// earlier instead of writing a second copy. Creations with an
+// IdempotencyKey bypass it. Zero leaves it off.
+func (s *MemoryService) EnableEntryDedup(window time.Duration) {
+	if window > 0 {
+		s.dedup = newEntryDedup(window)
+	}
+}
+
+// do runs create unless an identical creation is in flight or finished
+// within the window, in which case it returns that creation's entry. A
+// failed creation is forgotten, so its duplicates try the write again.
+func (d *entryDedup) do(ctx context.Context, e *model.MemoryEntry, create func() (*model.MemoryEntry, error)) (*model.MemoryEntry, error) {
+	key, err := entryContentKey(e)
+	if err != nil {
+		return create()
+	}
+	now := d.now()
+	d.mu.Lock()
+	for k, c := range d.calls {
+		if now.Sub(c.started) >= d.window && isDone(c) {
+			delete(d.calls, k)
+		}
+	}
+	if c, ok := d.calls[key]; ok && now.Sub(c.started) < d.window {
+		d.mu.Unlock()
+		select {
+		case <-c.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if c.err == nil {
+			return c.entry, nil
+		}
+		return d.do(ctx, e, create)
+	}
+	c := &dedupCall{started: now, done: make(chan struct{})}
+	d.calls[key] = c
+	d.mu.Unlock()
+
+	c.entry, c.err = create()
+	if c.err != nil {
+		d.mu.Lock()
+		if d.calls[key] == c {
+			delete(d.calls, key)
+		}
+		d.mu.Unlock()
+	}
+	close(c.done)
+	return c.entry, c.err
+}
+
+func isDone(c *dedupCall) bool {
+	select {
+	case <-c.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// entryContentKey hashes the fields that make two creations the same write:
+// everything the client sends except its usage report, so entries that
+// differ only in provenance or timing are both written.
+func entryContentKey(e *model.MemoryEntry) (string, error) {
+	b, err := json.Marshal(struct {
+		ActorID, VaultID, MemoryID, RawEntry, SessionID string
+		Summary                                         *string
+		Tags, Metadata                                  map[string]interface{}
+		SourceSystem, SourceID, IngestionBatchID        string
+		ConversationTime, ExpirationTime                *time.Time
+	}{e.ActorID, e.VaultID, e.MemoryID, e.RawEntry, e.SessionID, e.Summary, e.Tags, e.Metadata,
+		e.SourceSystem, e.SourceID, e.IngestionBatchID, e.ConversationTime, e.ExpirationTime})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
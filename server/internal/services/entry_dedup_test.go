@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+func TestEntryDedup_ConcurrentDuplicatesShareFirstEntry(t *testing.T) {
+	d := newEntryDedup(time.Second)
+	release := make(chan struct{})
+	var creates int32
+	create := func() (*model.MemoryEntry, error) {
+		n := atomic.AddInt32(&creates, 1)
+		<-release
+		return &model.MemoryEntry{EntryID: string(rune('a' + n - 1))}, nil
+	}
+
+	var wg sync.WaitGroup
+	got := make([]string, 3)
+	for i := range got {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			e, err := d.do(context.Background(), &model.MemoryEntry{ActorID: "u1", MemoryID: "m1", RawEntry: "same"}, create)
+			if err != nil {
+				t.Errorf("do: %v", err)
+				return
+			}
+			got[i] = e.EntryID
+		}(i)
+	}
+	// Let every goroutine register or join before the first write finishes.
+	for atomic.LoadInt32(&creates) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&creates); n != 1 || got[0] != "a" || got[1] != "a" || got[2] != "a" {
+		t.Fatalf("expected one create shared by all calls, got %d creates and %v", n, got)
+	}
+}
+
+func TestCreateEntry_DedupWindow(t *testing.T) {
+	fs := &fakeStore{}
+	svc := NewMemoryService(fs, nil, nil)
+	svc.EnableEntryDedup(2 * time.Second)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	svc.dedup.now = func() time.Time { return now }
+	ctx := context.Background()
+	entry := func(raw string) *model.MemoryEntry {
+		return &model.MemoryEntry{ActorID: "u1", VaultID: "v1", MemoryID: "m1", RawEntry: raw}
+	}
+
+	first, err := svc.CreateEntry(ctx, entry("likes tea"))
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	now = now.Add(time.Second)
+	dup, err := svc.CreateEntry(ctx, entry("likes tea"))
+	if err != nil || dup.EntryID != first.EntryID {
+		t.Fatalf("duplicate within window: got %+v err=%v, want entry %s", dup, err, first.EntryID)
+	}
+	other, err := svc.CreateEntry(ctx, entry("likes coffee"))
+	if err != nil || other.EntryID == first.EntryID {
+		t.Fatalf("different content must be written: got %+v err=%v", other, err)
+	}
+	sourced := entry("likes tea")
+	sourced.SourceSystem, sourced.SourceID = "slack", "msg-2"
+	fromSource, err := svc.CreateEntry(ctx, sourced)
+	if err != nil || fromSource.EntryID == first.EntryID {
+		t.Fatalf("different provenance must be written: got %+v err=%v", fromSource, err)
+	}
+	keyed := entry("likes tea")
+	keyed.IdempotencyKey = "k-1"
+	withKey, err := svc.CreateEntry(ctx, keyed)
+	if err != nil || withKey.EntryID == first.EntryID {
+		t.Fatalf("a keyed creation must be written: got %+v err=%v", withKey, err)
+	}
+	// A second key within the window is written too, so the store keeps
+	// each key for the client's replays.
+	keyed2 := entry("likes tea")
+	keyed2.IdempotencyKey = "k-2"
+	if withKey2, err := svc.CreateEntry(ctx, keyed2); err != nil || withKey2.EntryID == withKey.EntryID {
+		t.Fatalf("each key must be written: got %+v err=%v", withKey2, err)
+	}
+	now = now.Add(2 * time.Second)
+	later, err := svc.CreateEntry(ctx, entry("likes tea"))
+	if err != nil || later.EntryID == first.EntryID {
+		t.Fatalf("repeat after the window must be written: got %+v err=%v", later, err)
+	}
+	if n := len(fs.entriesByMem["m1"]); n != 6 {
+		t.Fatalf("expected 6 stored entries, got %d", n)
+	}
+}
+
+func TestEntryDedup_RetriesAfterFailedFirstCreate(t *testing.T) {
+	d := newEntryDedup(time.Second)
+	e := &model.MemoryEntry{ActorID: "u1", MemoryID: "m1", RawEntry: "x"}
+	if _, err := d.do(context.Background(), e, func() (*model.MemoryEntry, error) { return nil, errors.New("boom") }); err == nil {
+		t.Fatal("expected the first create's error")
+	}
+	out, err := d.do(context.Background(), e, func() (*model.MemoryEntry, error) { return &model.MemoryEntry{EntryID: "e1"}, nil })
+	if err != nil || out.EntryID != "e1" {
+		t.Fatalf("duplicate of a failed create must write: got %+v err=%v", out, err)
+	}
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+// Entries a JobDeleteEntries job lists per page and deletes between
+// progress reports.
+const (
+	deletionPageSize    = 500
+	deletionReportEvery = 50
+)
+
+// StartDeleteEntriesJob queues a JobDeleteEntries job deleting the
+// memory's entries selected by d, like DeleteEntry deletes one (into the
+// trash when it is enabled).
+func (s *MemoryService) StartDeleteEntriesJob(ctx context.Context, actorID, vaultID, memoryID string, d model.EntryDeletion) (*model.Job, error) {
+	if err := validateEntryDeletion(d); err != nil {
+		return nil, err
+	}
+	if err := ensureVaultWritable(ctx, s.store, actorID, vaultID); err != nil {
+		return nil, err
+	}
+	if err := ensureEntriesMutable(ctx, s.store, actorID, vaultID, memoryID); err != nil {
+		return nil, err
+	}
+	if _, err := s.store.Memories().GetByID(ctx, actorID, vaultID, memoryID); err != nil {
+		return nil, err
+	}
+	params, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	return s.store.Jobs().Create(ctx, &model.Job{ActorID: actorID, Kind: model.JobDeleteEntries, VaultID: vaultID, MemoryID: memoryID, Params: params})
+}
+
+func validateEntryDeletion(d model.EntryDeletion) error {
+	switch {
+	case len(d.EntryIDs) == 0 && d.SessionID == "" && d.Before == nil:
+		return fmt.Errorf("%w: entryIds, sessionId or before is required", model.ErrValidation)
+	case len(d.EntryIDs) > model.MaxDeleteEntries:
+		return fmt.Errorf("%w: at most %d entryIds", model.ErrValidation, model.MaxDeleteEntries)
+	}
+	return nil
+}
+
+// runDeleteEntriesJob runs a JobDeleteEntries job. The selection is
+// resolved when the job runs; more than model.MaxDeleteEntries matches
+// fails it before anything is deleted.
+func (s *MemoryService) runDeleteEntriesJob(ctx context.Context, job *model.Job, progress JobProgress) error {
+	var d model.EntryDeletion
+	if err := json.Unmarshal(job.Params, &d); err != nil {
+		return fmt.Errorf("params: %w", err)
+	}
+	if err := ensureVaultWritable(ctx, s.store, job.ActorID, job.VaultID); err != nil {
+		return err
+	}
+	if err := ensureEntriesMutable(ctx, s.store, job.ActorID, job.VaultID, job.MemoryID); err != nil {
+		return err
+	}
+	ids := d.EntryIDs
+	if len(ids) == 0 {
+		var err error
+		if ids, err = s.selectEntries(ctx, job, d); err != nil {
+			return err
+		}
+	}
+	for i, id := range ids {
+		if i%deletionReportEvery == 0 {
+			if err := progress(ctx, i, len(ids)); err != nil {
+				return err
+			}
+		}
+		if err := s.removeEntry(ctx, job.ActorID, job.VaultID, job.MemoryID, id); err != nil {
+			return fmt.Errorf("entry %s: %w", id, err)
+		}
+	}
+	if err := progress(ctx, len(ids), len(ids)); err != nil && !errors.Is(err, ErrJobCancelled) {
+		return err
+	}
+	return nil
+}
+
+// selectEntries lists the IDs of the job memory's entries in d's session
+// and/or created before d.Before, oldest first.
+func (s *MemoryService) selectEntries(ctx context.Context, job *model.Job, d model.EntryDeletion) ([]string, error) {
+	req := model.ListEntriesRequest{
+		ActorID:   job.ActorID,
+		VaultID:   job.VaultID,
+		MemoryID:  job.MemoryID,
+		SessionID: d.SessionID,
+		Before:    d.Before,
+		Limit:     deletionPageSize,
+		Ascending: true,
+	}
+	var ids []string
+	for {
+		page, err := s.store.Entries().List(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range page {
+			ids = append(ids, e.EntryID)
+		}
+		if len(ids) > model.MaxDeleteEntries {
+			return nil, fmt.Errorf("%w: the selection matches more than %d entries", model.ErrValidation, model.MaxDeleteEntries)
+		}
+		if len(page) < deletionPageSize {
+			return ids, nil
+		}
+		last := page[len(page)-1]
+		req.Cursor = &model.EntryCursor{CreationTime: last.CreationTime, EntryID: last.EntryID}
+	}
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+// validateEntryTTL accepts a memory's EntryTTLSeconds; 0 means entries do
+// not expire unless written with an expiration time.
+func validateEntryTTL(seconds int) error {
+	if seconds < 0 {
+		return fmt.Errorf("%w: entryTtlSeconds must not be negative", model.ErrValidation)
+	}
+	return nil
+}
+
+// setEntryExpiry rejects an expiration time that is not after now, and sets
+// one ttlSeconds from now on an entry written without it.
+func setEntryExpiry(e *model.MemoryEntry, ttlSeconds int, now time.Time) error {
+	if e.ExpirationTime != nil {
+		if !e.ExpirationTime.After(now) {
+			return fmt.Errorf("%w: expirationTime must be in the future", model.ErrValidation)
+		}
+		return nil
+	}
+	if ttlSeconds > 0 {
+		t := now.Add(time.Duration(ttlSeconds) * time.Second)
+		e.ExpirationTime = &t
+	}
+	return nil
+}
+
+// DropExpired removes hits of entries past their expiration time, which
+// stay in the index until the retention reaper deletes them.
+func (s *MemoryService) DropExpired(ctx context.Context, userID string, hits []model.SearchHit) ([]model.SearchHit, error) {
+	if len(hits) == 0 {
+		return hits, nil
+	}
+	ids := make([]string, len(hits))
+	for i, h := range hits {
+		ids[i] = h.EntryID
+	}
+	expired, err := s.store.Entries().Expired(ctx, userID, ids, time.Now())
+	if err != nil || len(expired) == 0 {
+		return hits, err
+	}
+	out := hits[:0]
+	for _, h := range hits {
+		if !expired[h.EntryID] {
+			out = append(out, h)
+		}
+	}
+	return out, nil
+}
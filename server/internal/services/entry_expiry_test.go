@@ -0,0 +1,64 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/store"
+)
+
+func TestCreateEntry_Expiry(t *testing.T) {
+	fs := &fakeStore{entryTTLs: map[string]int{"ttl": 3600}}
+	svc := NewMemoryService(fs, nil, nil)
+	ctx := context.Background()
+
+	before := time.Now()
+	e, err := svc.CreateEntry(ctx, &model.MemoryEntry{ActorID: "u1", VaultID: "v1", MemoryID: "ttl", RawEntry: "x"})
+	if err != nil || e.ExpirationTime == nil || e.ExpirationTime.Before(before.Add(time.Hour)) {
+		t.Fatalf("memory TTL: %+v %v", e, err)
+	}
+	at := time.Now().Add(time.Minute)
+	if e, err := svc.CreateEntry(ctx, &model.MemoryEntry{ActorID: "u1", VaultID: "v1", MemoryID: "ttl", RawEntry: "x", ExpirationTime: &at}); err != nil || !e.ExpirationTime.Equal(at) {
+		t.Fatalf("explicit expiration must win over the TTL: %+v %v", e, err)
+	}
+	if e, err := svc.CreateEntry(ctx, &model.MemoryEntry{ActorID: "u1", VaultID: "v1", MemoryID: "plain", RawEntry: "x"}); err != nil || e.ExpirationTime != nil {
+		t.Fatalf("no TTL: %+v %v", e, err)
+	}
+	past := time.Now().Add(-time.Minute)
+	if _, err := svc.CreateEntries(ctx, []*model.MemoryEntry{
+		{ActorID: "u1", VaultID: "v1", MemoryID: "plain", RawEntry: "x"},
+		{ActorID: "u1", VaultID: "v1", MemoryID: "plain", RawEntry: "y", ExpirationTime: &past},
+	}); !errors.Is(err, model.ErrValidation) {
+		t.Fatalf("past expiration: expected validation error, got %v", err)
+	}
+	if _, err := svc.CreateMemory(ctx, &model.Memory{ActorID: "u1", VaultID: "v1", Title: "m", EntryTTLSeconds: -1}); !errors.Is(err, model.ErrValidation) {
+		t.Fatalf("negative TTL: expected validation error, got %v", err)
+	}
+}
+
+type expiryEntries struct {
+	store.Entries
+	expired map[string]bool
+}
+
+func (e expiryEntries) Expired(context.Context, string, []string, time.Time) (map[string]bool, error) {
+	return e.expired, nil
+}
+
+type expiryStore struct {
+	*fakeStore
+	e expiryEntries
+}
+
+func (s expiryStore) Entries() store.Entries { return s.e }
+
+func TestDropExpired(t *testing.T) {
+	svc := NewMemoryService(expiryStore{&fakeStore{}, expiryEntries{expired: map[string]bool{"e2": true}}}, nil, nil)
+	hits, err := svc.DropExpired(context.Background(), "u1", []model.SearchHit{{EntryID: "e1"}, {EntryID: "e2"}, {EntryID: "e3"}})
+	if err != nil || len(hits) != 2 || hits[0].EntryID != "e1" || hits[1].EntryID != "e3" {
+		t.Fatalf("DropExpired: %+v %v", hits, err)
+	}
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/store"
+)
+
+// SetMemoryEntryRoles requires every entry written to the memory from now on
+// to carry metadata.role naming one of roles, so evaluation and replay
+// tooling can rely on it. Existing entries are not checked; empty roles
+// lift the requirement.
+func (s *MemoryService) SetMemoryEntryRoles(ctx context.Context, userID, vaultID, memoryID string, roles []string) (*model.Memory, error) {
+	if err := validateEntryRoles(roles); err != nil {
+		return nil, err
+	}
+	if err := ensureVaultWritable(ctx, s.store, userID, vaultID); err != nil {
+		return nil, err
+	}
+	return s.store.Memories().SetEntryRoles(ctx, userID, vaultID, memoryID, roles)
+}
+
+// validateEntryRoles accepts the conversation roles, each at most once.
+func validateEntryRoles(roles []string) error {
+	for i, r := range roles {
+		if !conversationRoles[r] {
+			return fmt.Errorf("%w: entryRoles[%d] must be user, assistant, system or tool", model.ErrValidation, i)
+		}
+		if slices.Contains(roles[:i], r) {
+			return fmt.Errorf("%w: entryRoles lists %q twice", model.ErrValidation, r)
+		}
+	}
+	return nil
+}
+
+// entryMemory returns the memory new entries are written to, for the roles
+// and entry TTL it sets. A missing memory is returned empty and left for the
+// store call to report.
+func entryMemory(ctx context.Context, st store.Store, userID, vaultID, memoryID string) (*model.Memory, error) {
+	m, err := st.Memories().GetByID(ctx, userID, vaultID, memoryID)
+	if errors.Is(err, model.ErrNotFound) {
+		return &model.Memory{}, nil
+	}
+	return m, err
+}
+
+// checkEntryRole rejects an entry whose metadata.role is not one of roles.
+// No roles means the memory has no requirement.
+func checkEntryRole(roles []string, e *model.MemoryEntry) error {
+	if len(roles) == 0 {
+		return nil
+	}
+	if role, _ := e.Metadata["role"].(string); slices.Contains(roles, role) {
+		return nil
+	}
+	return fmt.Errorf("%w: memory %s requires metadata.role to be one of %s", model.ErrValidation, e.MemoryID, strings.Join(roles, ", "))
+}
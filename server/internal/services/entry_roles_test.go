@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+func TestCreateEntry_RequiredRoles(t *testing.T) {
+	fs := &fakeStore{}
+	svc := NewMemoryService(fs, nil, nil)
+	ctx := context.Background()
+	entry := func(meta map[string]interface{}) *model.MemoryEntry {
+		return &model.MemoryEntry{ActorID: "u1", VaultID: "v1", MemoryID: "m1", RawEntry: "hi", Metadata: meta}
+	}
+
+	if _, err := svc.CreateEntry(ctx, entry(nil)); err != nil {
+		t.Fatalf("no requirement: %v", err)
+	}
+	if _, err := svc.SetMemoryEntryRoles(ctx, "u1", "v1", "m1", []string{"user", "robot"}); !errors.Is(err, model.ErrValidation) {
+		t.Fatalf("unknown role: expected validation error, got %v", err)
+	}
+	if _, err := svc.SetMemoryEntryRoles(ctx, "u1", "v1", "m1", []string{"user", "user"}); !errors.Is(err, model.ErrValidation) {
+		t.Fatalf("duplicate role: expected validation error, got %v", err)
+	}
+	m, err := svc.SetMemoryEntryRoles(ctx, "u1", "v1", "m1", []string{"user", "assistant"})
+	if err != nil || len(m.EntryRoles) != 2 {
+		t.Fatalf("SetMemoryEntryRoles: m=%+v err=%v", m, err)
+	}
+
+	for _, meta := range []map[string]interface{}{nil, {"role": "tool"}, {"role": 1}} {
+		if _, err := svc.CreateEntry(ctx, entry(meta)); !errors.Is(err, model.ErrValidation) {
+			t.Fatalf("metadata %v: expected validation error, got %v", meta, err)
+		}
+	}
+	if _, err := svc.CreateEntry(ctx, entry(map[string]interface{}{"role": "assistant"})); err != nil {
+		t.Fatalf("allowed role: %v", err)
+	}
+
+	if _, err := svc.SetMemoryEntryRoles(ctx, "u1", "v1", "m1", nil); err != nil {
+		t.Fatalf("clear: %v", err)
+	}
+	if _, err := svc.CreateEntry(ctx, entry(nil)); err != nil {
+		t.Fatalf("after clearing: %v", err)
+	}
+	if n := len(fs.entriesByMem["m1"]); n != 3 {
+		t.Fatalf("expected 3 stored entries, got %d", n)
+	}
+}
+
+func TestCreateEntries_ChecksEveryEntryFirst(t *testing.T) {
+	fs := &fakeStore{}
+	svc := NewMemoryService(fs, nil, nil)
+	ctx := context.Background()
+	entry := func(role string) *model.MemoryEntry {
+		return &model.MemoryEntry{ActorID: "u1", VaultID: "v1", MemoryID: "m1", RawEntry: "hi", Metadata: map[string]interface{}{"role": role}}
+	}
+
+	if _, err := svc.CreateEntries(ctx, nil); !errors.Is(err, model.ErrValidation) {
+		t.Fatalf("empty batch: expected validation error, got %v", err)
+	}
+	if _, err := svc.CreateEntries(ctx, make([]*model.MemoryEntry, model.MaxEntriesBatch+1)); !errors.Is(err, model.ErrValidation) {
+		t.Fatalf("oversized batch: expected validation error, got %v", err)
+	}
+	if _, err := svc.SetMemoryEntryRoles(ctx, "u1", "v1", "m1", []string{"user", "assistant"}); err != nil {
+		t.Fatal(err)
+	}
+	_, err := svc.CreateEntries(ctx, []*model.MemoryEntry{entry("user"), entry("tool")})
+	if !errors.Is(err, model.ErrValidation) || !strings.Contains(err.Error(), "entries[1]") {
+		t.Fatalf("bad role: expected validation error naming entries[1], got %v", err)
+	}
+	if n := len(fs.entriesByMem["m1"]); n != 0 {
+		t.Fatalf("a rejected batch stored %d entries", n)
+	}
+	out, err := svc.CreateEntries(ctx, []*model.MemoryEntry{entry("user"), entry("assistant")})
+	if err != nil || len(out) != 2 {
+		t.Fatalf("CreateEntries: out=%v err=%v", out, err)
+	}
+}
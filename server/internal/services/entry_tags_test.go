@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/store"
+)
+
+type tagPatchEntries struct {
+	store.Entries
+	got []model.EntryTagPatch
+}
+
+func (e *tagPatchEntries) PatchTags(_ context.Context, p model.EntryTagPatch) ([]string, error) {
+	e.got = append(e.got, p)
+	return p.EntryIDs, nil
+}
+
+type tagPatchStore struct {
+	*fakeStore
+	e *tagPatchEntries
+}
+
+func (s tagPatchStore) Entries() store.Entries { return s.e }
+
+func TestPatchEntryTagsValidates(t *testing.T) {
+	es := &tagPatchEntries{}
+	svc := NewMemoryService(tagPatchStore{&fakeStore{}, es}, nil, nil)
+	ctx := context.Background()
+	now := time.Now()
+	base := model.EntryTagPatch{ActorID: "u1", VaultID: "v1", MemoryID: "m1"}
+
+	bad := map[string]func(p *model.EntryTagPatch){
+		"no filter": func(p *model.EntryTagPatch) { p.Set = map[string]interface{}{"k": "v"} },
+		"no patch":  func(p *model.EntryTagPatch) { p.SessionID = "s1" },
+		"empty range": func(p *model.EntryTagPatch) {
+			p.Since, p.Until, p.Unset = &now, &now, []string{"k"}
+		},
+		"set and unset": func(p *model.EntryTagPatch) {
+			p.EntryIDs, p.Set, p.Unset = []string{"e1"}, map[string]interface{}{"k": "v"}, []string{"k"}
+		},
+		"too many ids": func(p *model.EntryTagPatch) {
+			p.EntryIDs, p.Unset = make([]string, model.MaxTagPatchEntries+1), []string{"k"}
+		},
+	}
+	for name, mutate := range bad {
+		p := base
+		mutate(&p)
+		if _, err := svc.PatchEntryTags(ctx, p); !errors.Is(err, model.ErrValidation) {
+			t.Errorf("%s: expected ErrValidation, got %v", name, err)
+		}
+	}
+	if len(es.got) != 0 {
+		t.Fatalf("invalid patches must not reach the store: %+v", es.got)
+	}
+
+	p := base
+	p.EntryIDs = []string{"e1", "e2"}
+	p.Set = map[string]interface{}{"status": "resolved"}
+	if ids, err := svc.PatchEntryTags(ctx, p); err != nil || len(ids) != 2 || len(es.got) != 1 {
+		t.Fatalf("PatchEntryTags: ids=%v err=%v calls=%d", ids, err, len(es.got))
+	}
+}
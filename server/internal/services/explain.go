@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+// TermMatch reports which query terms occur in an entry's text, using the
+// word tokenization and English stopwords the keyword (BM25) search applies.
+type TermMatch struct {
+	Matched   []string `json:"matched"`
+	Missing   []string `json:"missing"`
+	Stopwords []string `json:"stopwords,omitempty"`
+}
+
+// explainStopwords are the common English words keyword search ignores.
+var explainStopwords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "but": true,
+	"by": true, "for": true, "if": true, "in": true, "into": true, "is": true, "it": true, "no": true,
+	"not": true, "of": true, "on": true, "or": true, "such": true, "that": true, "the": true,
+	"their": true, "then": true, "there": true, "these": true, "they": true, "this": true, "to": true,
+	"was": true, "will": true, "with": true,
+}
+
+// tokenize lowercases text and splits it on anything not a letter or digit.
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// MatchTerms compares the distinct terms of query with text.
+func MatchTerms(query, text string) TermMatch {
+	have := map[string]bool{}
+	for _, t := range tokenize(text) {
+		have[t] = true
+	}
+	m := TermMatch{Matched: []string{}, Missing: []string{}}
+	seen := map[string]bool{}
+	for _, t := range tokenize(query) {
+		if seen[t] {
+			continue
+		}
+		seen[t] = true
+		switch {
+		case explainStopwords[t]:
+			m.Stopwords = append(m.Stopwords, t)
+		case have[t]:
+			m.Matched = append(m.Matched, t)
+		default:
+			m.Missing = append(m.Missing, t)
+		}
+	}
+	sort.Strings(m.Matched)
+	sort.Strings(m.Missing)
+	sort.Strings(m.Stopwords)
+	return m
+}
+
+// CosineSimilarity of two vectors; 0 when their lengths differ or either is zero.
+func CosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(na*nb)
+}
+
+// LookupEntry is GetEntryByID without access tracking, for diagnostics that
+// must not keep an entry alive under LRU retention.
+func (s *MemoryService) LookupEntry(ctx context.Context, userID, vaultID, memoryID, entryID string) (*model.MemoryEntry, error) {
+	return s.store.Entries().GetByID(ctx, userID, vaultID, memoryID, entryID)
+}
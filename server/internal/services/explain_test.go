@@ -0,0 +1,24 @@
+package services
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestMatchTerms(t *testing.T) {
+	m := MatchTerms("Where is the Lisbon flat? lisbon", "User moved to Lisbon; the flat-share ends in May.")
+	if !reflect.DeepEqual(m.Matched, []string{"flat", "lisbon"}) || !reflect.DeepEqual(m.Missing, []string{"where"}) ||
+		!reflect.DeepEqual(m.Stopwords, []string{"is", "the"}) {
+		t.Fatalf("unexpected match: %+v", m)
+	}
+}
+
+func TestCosineSimilarity(t *testing.T) {
+	if got := CosineSimilarity([]float32{1, 0}, []float32{1, 1}); math.Abs(got-math.Sqrt2/2) > 1e-9 {
+		t.Fatalf("got %v", got)
+	}
+	if CosineSimilarity([]float32{1}, []float32{1, 0}) != 0 || CosineSimilarity([]float32{0, 0}, []float32{1, 0}) != 0 {
+		t.Fatalf("mismatched or zero vectors must give 0")
+	}
+}
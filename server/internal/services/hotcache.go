@@ -0,0 +1,399 @@
+package services
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/store"
+)
+
+// maxHotListLimit is the largest entry list page the hot cache keeps.
+const maxHotListLimit = 500
+
+// HotCache is an in-process LRU of the reads agents repeat every turn: recent
+// entry lists, single entries and the latest context of a memory. Wrap puts
+// it in front of a store; every write through the wrapped store drops the
+// cached reads of the memory it changes. Writes made by other server
+// instances are not seen, so cached reads expire after ttl.
+//
+// Cached entries keep the lastAccessedTime they were read with: access
+// tracking is not a change the cache invalidates on.
+type HotCache struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	now      func() time.Time
+	lru      *list.List // of *hotItem, most recently used first
+	items    map[string]*list.Element
+	byMemory map[string]map[string]bool // memoryID -> cached keys
+	// epoch counts invalidations; a read is only cached if none happened
+	// while it ran, so a read racing a write cannot cache the old value.
+	epoch uint64
+
+	hits, misses, evictions, invalidations uint64
+}
+
+type hotItem struct {
+	key      string
+	memoryID string
+	value    interface{}
+	expires  time.Time
+}
+
+// HotCacheStats reports the hot cache's size and how well it is serving.
+type HotCacheStats struct {
+	Capacity      int     `json:"capacity"`
+	Size          int     `json:"size"`
+	TTLSeconds    float64 `json:"ttlSeconds"`
+	Hits          uint64  `json:"hits"`
+	Misses        uint64  `json:"misses"`
+	HitRatio      float64 `json:"hitRatio"`
+	Evictions     uint64  `json:"evictions"`
+	Invalidations uint64  `json:"invalidations"`
+}
+
+// NewHotCache returns a cache holding up to capacity reads for ttl each.
+func NewHotCache(capacity int, ttl time.Duration) *HotCache {
+	return &HotCache{
+		capacity: capacity,
+		ttl:      ttl,
+		now:      time.Now,
+		lru:      list.New(),
+		items:    map[string]*list.Element{},
+		byMemory: map[string]map[string]bool{},
+	}
+}
+
+// Stats returns the cache's current counters.
+func (c *HotCache) Stats() HotCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := HotCacheStats{
+		Capacity:      c.capacity,
+		Size:          c.lru.Len(),
+		TTLSeconds:    c.ttl.Seconds(),
+		Hits:          c.hits,
+		Misses:        c.misses,
+		Evictions:     c.evictions,
+		Invalidations: c.invalidations,
+	}
+	if total := c.hits + c.misses; total > 0 {
+		out.HitRatio = float64(c.hits) / float64(total)
+	}
+	return out
+}
+
+// get returns the cached value of key or, on a miss, the epoch to pass to put.
+func (c *HotCache) get(key string) (interface{}, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if ok && c.now().After(el.Value.(*hotItem).expires) {
+		c.remove(el)
+		ok = false
+	}
+	if !ok {
+		c.misses++
+		return nil, c.epoch, false
+	}
+	c.hits++
+	c.lru.MoveToFront(el)
+	return el.Value.(*hotItem).value, 0, true
+}
+
+// put caches value unless something was invalidated since epoch.
+func (c *HotCache) put(memoryID, key string, value interface{}, epoch uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if epoch != c.epoch {
+		return
+	}
+	if el, ok := c.items[key]; ok {
+		c.remove(el)
+	}
+	c.items[key] = c.lru.PushFront(&hotItem{key: key, memoryID: memoryID, value: value, expires: c.now().Add(c.ttl)})
+	if c.byMemory[memoryID] == nil {
+		c.byMemory[memoryID] = map[string]bool{}
+	}
+	c.byMemory[memoryID][key] = true
+	for c.lru.Len() > c.capacity {
+		c.remove(c.lru.Back())
+		c.evictions++
+	}
+}
+
+// remove drops el; the caller holds mu.
+func (c *HotCache) remove(el *list.Element) {
+	it := c.lru.Remove(el).(*hotItem)
+	delete(c.items, it.key)
+	if keys := c.byMemory[it.memoryID]; keys != nil {
+		delete(keys, it.key)
+		if len(keys) == 0 {
+			delete(c.byMemory, it.memoryID)
+		}
+	}
+}
+
+// invalidate drops every cached read of the memory.
+func (c *HotCache) invalidate(memoryID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.byMemory[memoryID] {
+		c.remove(c.items[key])
+	}
+	c.epoch++
+	c.invalidations++
+}
+
+// purge drops everything, for writes whose memories are not known.
+func (c *HotCache) purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lru.Init()
+	c.items = map[string]*list.Element{}
+	c.byMemory = map[string]map[string]bool{}
+	c.epoch++
+	c.invalidations++
+}
+
+// Wrap returns st with its entry and context reads served from the cache.
+// Writes invalidate after they return, so a read racing one is not cached.
+func (c *HotCache) Wrap(st store.Store) store.Store {
+	return hotStore{Store: st, c: c}
+}
+
+type hotStore struct {
+	store.Store
+	c *HotCache
+}
+
+func (s hotStore) Users() store.Users       { return hotUsers{s.Store.Users(), s.c} }
+func (s hotStore) Vaults() store.Vaults     { return hotVaults{s.Store.Vaults(), s.c} }
+func (s hotStore) Memories() store.Memories { return hotMemories{s.Store.Memories(), s.c} }
+func (s hotStore) Entries() store.Entries   { return hotEntries{s.Store.Entries(), s.c} }
+func (s hotStore) Contexts() store.Contexts { return hotContexts{s.Store.Contexts(), s.c} }
+func (s hotStore) IngestionBatches() store.IngestionBatches {
+	return hotBatches{s.Store.IngestionBatches(), s.c}
+}
+
+type hotEntries struct {
+	store.Entries
+	c *HotCache
+}
+
+func (e hotEntries) List(ctx context.Context, req model.ListEntriesRequest) ([]*model.MemoryEntry, error) {
+	if req.Before != nil || req.After != nil || req.Cursor != nil || len(req.Tags) > 0 || req.Limit <= 0 || req.Limit > maxHotListLimit {
+		return e.Entries.List(ctx, req)
+	}
+	key := fmt.Sprintf("list|%s|%s|%s|%s|%d|%t|%s", req.ActorID, req.VaultID, req.MemoryID, req.SessionID, req.Limit, req.Ascending, req.OrderBy)
+	v, epoch, ok := e.c.get(key)
+	if ok {
+		return copyEntries(v.([]*model.MemoryEntry)), nil
+	}
+	out, err := e.Entries.List(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	e.c.put(req.MemoryID, key, copyEntries(out), epoch)
+	return out, nil
+}
+
+func (e hotEntries) GetByID(ctx context.Context, userID, vaultID, memoryID, entryID string) (*model.MemoryEntry, error) {
+	key := "entry|" + userID + "|" + vaultID + "|" + memoryID + "|" + entryID
+	v, epoch, ok := e.c.get(key)
+	if ok {
+		cp := *v.(*model.MemoryEntry)
+		return &cp, nil
+	}
+	out, err := e.Entries.GetByID(ctx, userID, vaultID, memoryID, entryID)
+	if err != nil {
+		return nil, err
+	}
+	cp := *out
+	e.c.put(memoryID, key, &cp, epoch)
+	return out, nil
+}
+
+func (e hotEntries) Create(ctx context.Context, me *model.MemoryEntry) (*model.MemoryEntry, error) {
+	defer e.c.invalidate(me.MemoryID)
+	return e.Entries.Create(ctx, me)
+}
+
+func (e hotEntries) CreateBatch(ctx context.Context, entries []*model.MemoryEntry) ([]*model.MemoryEntry, error) {
+	if len(entries) > 0 {
+		defer e.c.invalidate(entries[0].MemoryID)
+	}
+	return e.Entries.CreateBatch(ctx, entries)
+}
+
+func (e hotEntries) UpdateTags(ctx context.Context, userID, vaultID, memoryID, entryID string, tags map[string]interface{}) (*model.MemoryEntry, error) {
+	defer e.c.invalidate(memoryID)
+	return e.Entries.UpdateTags(ctx, userID, vaultID, memoryID, entryID, tags)
+}
+
+func (e hotEntries) PatchTags(ctx context.Context, p model.EntryTagPatch) ([]string, error) {
+	defer e.c.invalidate(p.MemoryID)
+	return e.Entries.PatchTags(ctx, p)
+}
+
+func (e hotEntries) RecordSignal(ctx context.Context, userID, vaultID, memoryID, entryID, signal string) (*model.MemoryEntry, error) {
+	defer e.c.invalidate(memoryID)
+	return e.Entries.RecordSignal(ctx, userID, vaultID, memoryID, entryID, signal)
+}
+
+func (e hotEntries) Correct(ctx context.Context, entryID string, correction *model.MemoryEntry, reason string) (*model.MemoryEntry, error) {
+	defer e.c.invalidate(correction.MemoryID)
+	return e.Entries.Correct(ctx, entryID, correction, reason)
+}
+
+func (e hotEntries) DeleteByID(ctx context.Context, userID, vaultID, memoryID, entryID string) error {
+	defer e.c.invalidate(memoryID)
+	return e.Entries.DeleteByID(ctx, userID, vaultID, memoryID, entryID)
+}
+
+func (e hotEntries) Expire(ctx context.Context, cutoff time.Time, byAccess bool, limit int) ([]string, error) {
+	ids, err := e.Entries.Expire(ctx, cutoff, byAccess, limit)
+	if len(ids) > 0 {
+		e.c.purge()
+	}
+	return ids, err
+}
+
+func (e hotEntries) ExpireDue(ctx context.Context, now time.Time, limit int) ([]string, error) {
+	ids, err := e.Entries.ExpireDue(ctx, now, limit)
+	if len(ids) > 0 {
+		e.c.purge()
+	}
+	return ids, err
+}
+
+func (e hotEntries) Trash(ctx context.Context, userID, vaultID, memoryID, entryID string) error {
+	defer e.c.invalidate(memoryID)
+	return e.Entries.Trash(ctx, userID, vaultID, memoryID, entryID)
+}
+
+func (e hotEntries) Restore(ctx context.Context, userID, vaultID, memoryID, entryID string) (*model.MemoryEntry, error) {
+	defer e.c.invalidate(memoryID)
+	return e.Entries.Restore(ctx, userID, vaultID, memoryID, entryID)
+}
+
+func (e hotEntries) PurgeTrash(ctx context.Context, cutoff time.Time, limit int) ([]string, error) {
+	ids, err := e.Entries.PurgeTrash(ctx, cutoff, limit)
+	if len(ids) > 0 {
+		e.c.purge()
+	}
+	return ids, err
+}
+
+type hotContexts struct {
+	store.Contexts
+	c *HotCache
+}
+
+func (x hotContexts) Latest(ctx context.Context, userID, vaultID, memoryID string) (*model.MemoryContext, error) {
+	key := "context|" + userID + "|" + vaultID + "|" + memoryID
+	v, epoch, ok := x.c.get(key)
+	if ok {
+		cp := *v.(*model.MemoryContext)
+		return &cp, nil
+	}
+	out, err := x.Contexts.Latest(ctx, userID, vaultID, memoryID)
+	if err != nil {
+		return nil, err
+	}
+	cp := *out
+	x.c.put(memoryID, key, &cp, epoch)
+	return out, nil
+}
+
+func (x hotContexts) Put(ctx context.Context, mc *model.MemoryContext) (*model.MemoryContext, error) {
+	defer x.c.invalidate(mc.MemoryID)
+	return x.Contexts.Put(ctx, mc)
+}
+
+func (x hotContexts) PutIfLatest(ctx context.Context, mc *model.MemoryContext, latestID string) (*model.MemoryContext, error) {
+	defer x.c.invalidate(mc.MemoryID)
+	return x.Contexts.PutIfLatest(ctx, mc, latestID)
+}
+
+func (x hotContexts) DeleteByID(ctx context.Context, userID, vaultID, memoryID, contextID string) error {
+	defer x.c.invalidate(memoryID)
+	return x.Contexts.DeleteByID(ctx, userID, vaultID, memoryID, contextID)
+}
+
+func (x hotContexts) DeleteMany(ctx context.Context, ref model.MemoryRef, contextIDs []string) (int, error) {
+	defer x.c.invalidate(ref.MemoryID)
+	return x.Contexts.DeleteMany(ctx, ref, contextIDs)
+}
+
+type hotMemories struct {
+	store.Memories
+	c *HotCache
+}
+
+func (m hotMemories) Delete(ctx context.Context, userID, vaultID, memoryID string) error {
+	defer m.c.invalidate(memoryID)
+	return m.Memories.Delete(ctx, userID, vaultID, memoryID)
+}
+
+func (m hotMemories) Trash(ctx context.Context, userID, vaultID, memoryID string) error {
+	defer m.c.invalidate(memoryID)
+	return m.Memories.Trash(ctx, userID, vaultID, memoryID)
+}
+
+func (m hotMemories) Restore(ctx context.Context, userID, vaultID, memoryID string) (*model.Memory, error) {
+	defer m.c.invalidate(memoryID)
+	return m.Memories.Restore(ctx, userID, vaultID, memoryID)
+}
+
+func (m hotMemories) PurgeTrash(ctx context.Context, cutoff time.Time, limit int) (int, error) {
+	n, err := m.Memories.PurgeTrash(ctx, cutoff, limit)
+	if n > 0 {
+		m.c.purge()
+	}
+	return n, err
+}
+
+type hotVaults struct {
+	store.Vaults
+	c *HotCache
+}
+
+func (v hotVaults) Delete(ctx context.Context, userID, vaultID string) error {
+	defer v.c.purge()
+	return v.Vaults.Delete(ctx, userID, vaultID)
+}
+
+type hotUsers struct {
+	store.Users
+	c *HotCache
+}
+
+func (u hotUsers) Delete(ctx context.Context, userID string) error {
+	defer u.c.purge()
+	return u.Users.Delete(ctx, userID)
+}
+
+type hotBatches struct {
+	store.IngestionBatches
+	c *HotCache
+}
+
+func (b hotBatches) Rollback(ctx context.Context, actorID, batchID string) ([]string, error) {
+	defer b.c.purge()
+	return b.IngestionBatches.Rollback(ctx, actorID, batchID)
+}
+
+// copyEntries copies the entries so callers cannot change cached ones.
+func copyEntries(in []*model.MemoryEntry) []*model.MemoryEntry {
+	out := make([]*model.MemoryEntry, len(in))
+	for i, e := range in {
+		cp := *e
+		out[i] = &cp
+	}
+	return out
+}
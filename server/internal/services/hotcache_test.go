@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/store"
+)
+
+// countingEntries counts the list reads that reach the store.
+type countingEntries struct {
+	store.Entries
+	lists int
+}
+
+func (e *countingEntries) List(ctx context.Context, req model.ListEntriesRequest) ([]*model.MemoryEntry, error) {
+	e.lists++
+	return e.Entries.List(ctx, req)
+}
+
+type countingStore struct {
+	*fakeStore
+	e *countingEntries
+}
+
+func (s countingStore) Entries() store.Entries { return s.e }
+
+func TestHotCacheServesAndInvalidates(t *testing.T) {
+	fs := &fakeStore{}
+	ce := &countingEntries{Entries: fs.Entries()}
+	hot := NewHotCache(10, time.Minute)
+	st := hot.Wrap(countingStore{fs, ce})
+	ctx := context.Background()
+	req := model.ListEntriesRequest{ActorID: "u1", VaultID: "v1", MemoryID: "m1", Limit: 10}
+
+	if _, err := st.Entries().Create(ctx, &model.MemoryEntry{ActorID: "u1", VaultID: "v1", MemoryID: "m1", RawEntry: "a"}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		got, err := st.Entries().List(ctx, req)
+		if err != nil || len(got) != 1 {
+			t.Fatalf("List: n=%d err=%v", len(got), err)
+		}
+		got[0].RawEntry = "changed by caller"
+	}
+	if ce.lists != 1 {
+		t.Fatalf("expected one store read for repeated lists, got %d", ce.lists)
+	}
+	if got, _ := st.Entries().List(ctx, req); got[0].RawEntry != "a" {
+		t.Fatalf("callers must not change cached entries, got %q", got[0].RawEntry)
+	}
+
+	// A write to the memory drops its cached reads.
+	if _, err := st.Entries().Create(ctx, &model.MemoryEntry{ActorID: "u1", VaultID: "v1", MemoryID: "m1", RawEntry: "b"}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if got, _ := st.Entries().List(ctx, req); len(got) != 2 || ce.lists != 2 {
+		t.Fatalf("expected a fresh read after the write: n=%d reads=%d", len(got), ce.lists)
+	}
+
+	// Time-bounded pages are not cached.
+	since := time.Now()
+	_, _ = st.Entries().List(ctx, model.ListEntriesRequest{ActorID: "u1", VaultID: "v1", MemoryID: "m1", Limit: 10, After: &since})
+	_, _ = st.Entries().List(ctx, model.ListEntriesRequest{ActorID: "u1", VaultID: "v1", MemoryID: "m1", Limit: 10, After: &since})
+	if ce.lists != 4 {
+		t.Fatalf("expected uncached reads for a time range, got %d reads", ce.lists)
+	}
+
+	stats := hot.Stats()
+	if stats.Hits != 3 || stats.Misses != 2 || stats.Size != 1 || stats.Invalidations != 2 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+
+	// Reaping expired entries drops cached reads too.
+	past := time.Now().Add(-time.Minute)
+	fs.entriesByMem["m1"][0].ExpirationTime = &past
+	if ids, err := st.Entries().ExpireDue(ctx, time.Now(), 10); err != nil || len(ids) != 1 {
+		t.Fatalf("ExpireDue: %v %v", ids, err)
+	}
+	if got, _ := st.Entries().List(ctx, req); len(got) != 1 || ce.lists != 5 {
+		t.Fatalf("expected a fresh read after expiry: n=%d reads=%d", len(got), ce.lists)
+	}
+}
+
+func TestHotCacheExpiryEvictionAndRaces(t *testing.T) {
+	hot := NewHotCache(2, time.Minute)
+	now := time.Now()
+	hot.now = func() time.Time { return now }
+
+	_, epoch, _ := hot.get("a")
+	hot.put("m1", "a", 1, epoch)
+	_, epoch, _ = hot.get("b")
+	hot.put("m2", "b", 2, epoch)
+	_, _, _ = hot.get("a") // a is now the most recently used
+	_, epoch, _ = hot.get("c")
+	hot.put("m3", "c", 3, epoch)
+	if _, _, ok := hot.get("b"); ok {
+		t.Fatal("expected the least recently used read to be evicted")
+	}
+	if _, _, ok := hot.get("a"); !ok || hot.Stats().Evictions != 1 {
+		t.Fatalf("expected a kept and one eviction, stats %+v", hot.Stats())
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, _, ok := hot.get("a"); ok {
+		t.Fatal("expected the read to expire after the TTL")
+	}
+
+	// A read that raced an invalidation is not cached.
+	_, epoch, _ = hot.get("d")
+	hot.invalidate("m4")
+	hot.put("m4", "d", 4, epoch)
+	if _, _, ok := hot.get("d"); ok {
+		t.Fatal("a read racing a write must not be cached")
+	}
+}
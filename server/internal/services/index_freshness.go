@@ -0,0 +1,47 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/searchindex"
+)
+
+// freshnessResolution is the precision at which entry times are compared;
+// the index may store creation times less precisely than the database.
+const freshnessResolution = time.Millisecond
+
+// IndexFreshness reports how far the search index trails the memory's
+// entries. It returns nil when the index cannot report its newest entry.
+func (s *MemoryService) IndexFreshness(ctx context.Context, actorID, memoryID string) (*model.IndexFreshness, error) {
+	reader, ok := s.idx.(searchindex.NewestEntryReader)
+	if !ok {
+		return nil, nil
+	}
+	out := &model.IndexFreshness{}
+	newest, err := s.store.Entries().Newest(ctx, actorID, memoryID)
+	if errors.Is(err, model.ErrNotFound) {
+		return out, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	newest = newest.UTC().Truncate(freshnessResolution)
+	out.NewestEntryTime = &newest
+	indexed, err := reader.NewestEntryTime(ctx, actorID, memoryID)
+	if err != nil {
+		return nil, err
+	}
+	if indexed.IsZero() {
+		out.Stale = true
+		return out, nil
+	}
+	indexed = indexed.UTC().Truncate(freshnessResolution)
+	out.NewestIndexedTime = &indexed
+	if lag := newest.Sub(indexed); lag > 0 {
+		out.LagSeconds, out.Stale = lag.Seconds(), true
+	}
+	return out, nil
+}
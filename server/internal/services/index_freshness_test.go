@@ -0,0 +1,45 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+// newestIndex reports a fixed newest indexed entry time.
+type newestIndex struct {
+	fakeIndex
+	newest time.Time
+}
+
+func (i *newestIndex) NewestEntryTime(context.Context, string, string) (time.Time, error) {
+	return i.newest, nil
+}
+
+func TestIndexFreshness(t *testing.T) {
+	ctx := context.Background()
+	t0 := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	fs := &fakeStore{entriesByMem: map[string][]*model.MemoryEntry{"m1": {{CreationTime: t0.Add(90 * time.Second)}, {CreationTime: t0}}}}
+	idx := &newestIndex{}
+
+	if f, err := NewMemoryService(fs, &fakeIndex{}, nil).IndexFreshness(ctx, "a", "m1"); err != nil || f != nil {
+		t.Fatalf("index without NewestEntryReader: f=%+v err=%v", f, err)
+	}
+	svc := NewMemoryService(fs, idx, nil)
+	if f, err := svc.IndexFreshness(ctx, "a", "m1"); err != nil || !f.Stale || f.NewestIndexedTime != nil || f.LagSeconds != 0 {
+		t.Fatalf("nothing indexed: f=%+v err=%v", f, err)
+	}
+	idx.newest = t0
+	if f, err := svc.IndexFreshness(ctx, "a", "m1"); err != nil || !f.Stale || f.LagSeconds != 90 {
+		t.Fatalf("trailing index: f=%+v err=%v", f, err)
+	}
+	idx.newest = t0.Add(90*time.Second + 300*time.Microsecond)
+	if f, err := svc.IndexFreshness(ctx, "a", "m1"); err != nil || f.Stale || f.LagSeconds != 0 {
+		t.Fatalf("caught-up index: f=%+v err=%v", f, err)
+	}
+	if f, err := svc.IndexFreshness(ctx, "a", "empty"); err != nil || f.Stale || f.NewestEntryTime != nil {
+		t.Fatalf("empty memory: f=%+v err=%v", f, err)
+	}
+}
@@ -0,0 +1,36 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+// maxIndexStatusIDs caps the IDs of one index status lookup.
+const maxIndexStatusIDs = 100
+
+// IndexStatus reports whether each listed entry or context of the memory is
+// searchable yet, pending indexing, or failed with the reason.
+func (s *MemoryService) IndexStatus(ctx context.Context, userID, vaultID, memoryID string, ids []string) (map[string]model.IndexStatus, error) {
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%w: at least one id is required", model.ErrValidation)
+	}
+	if len(ids) > maxIndexStatusIDs {
+		return nil, fmt.Errorf("%w: at most %d ids allowed", model.ErrValidation, maxIndexStatusIDs)
+	}
+	if _, err := s.store.Memories().GetByID(ctx, userID, vaultID, memoryID); err != nil {
+		return nil, err
+	}
+	return s.store.Indexing().Status(ctx, userID, ids)
+}
+
+// RetryIndexing re-enqueues the memory's entries and contexts whose index
+// writes were dead-lettered, only those in ids when non-empty, and returns
+// how many were re-enqueued.
+func (s *MemoryService) RetryIndexing(ctx context.Context, userID, vaultID, memoryID string, ids []string) (int, error) {
+	if _, err := s.store.Memories().GetByID(ctx, userID, vaultID, memoryID); err != nil {
+		return 0, err
+	}
+	return s.store.Indexing().Retry(ctx, userID, vaultID, memoryID, ids)
+}
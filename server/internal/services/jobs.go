@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/store"
+)
+
+// ErrJobCancelled is returned by a JobProgress once the job was asked to
+// cancel; a JobFunc returns it to stop, and the job ends cancelled.
+var ErrJobCancelled = errors.New("job cancelled")
+
+// JobProgress records that done of total units of a job's work are complete.
+type JobProgress func(ctx context.Context, done, total int) error
+
+// JobFunc runs one job of a kind. It must report progress more often than
+// the runner's stale timeout, or another worker claims the job again; it
+// may then be run twice, so its work must be safe to repeat.
+type JobFunc func(ctx context.Context, job *model.Job, progress JobProgress) error
+
+// JobRunner claims queued jobs one at a time and runs them with the JobFunc
+// registered for their kind.
+type JobRunner struct {
+	store store.Store
+	funcs map[string]JobFunc
+	stale time.Duration
+	log   zerolog.Logger
+}
+
+// NewJobRunner returns a runner that also claims running jobs whose worker
+// has not reported progress for stale.
+func NewJobRunner(s store.Store, stale time.Duration, log zerolog.Logger) *JobRunner {
+	return &JobRunner{store: s, funcs: map[string]JobFunc{}, stale: stale, log: log}
+}
+
+// Register sets the func that runs jobs of kind. It must not be called once
+// the runner has started.
+func (r *JobRunner) Register(kind string, fn JobFunc) { r.funcs[kind] = fn }
+
+// Start runs jobs until none is left, then checks again every interval
+// until ctx is done.
+func (r *JobRunner) Start(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		ran, err := r.RunOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			r.log.Warn().Err(err).Msg("job run failed")
+		}
+		if ran && ctx.Err() == nil {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// RunOnce claims one job and runs it to a final state, reporting whether
+// there was one. A job interrupted by ctx stays running and is claimed
+// again once stale.
+func (r *JobRunner) RunOnce(ctx context.Context) (bool, error) {
+	job, err := r.store.Jobs().Claim(ctx, time.Now().Add(-r.stale))
+	if err != nil || job == nil {
+		return false, err
+	}
+	log := r.log.With().Str("jobId", job.JobID).Str("kind", job.Kind).Logger()
+	status, msg := model.JobSucceeded, ""
+	fn := r.funcs[job.Kind]
+	switch {
+	case job.CancelRequested:
+		status = model.JobCancelled
+	case fn == nil:
+		status, msg = model.JobFailed, fmt.Sprintf("unknown job kind %q", job.Kind)
+	default:
+		start := time.Now()
+		progress := func(ctx context.Context, done, total int) error {
+			cancel, err := r.store.Jobs().Progress(ctx, job.JobID, done, total)
+			if err == nil && cancel {
+				return ErrJobCancelled
+			}
+			return err
+		}
+		err := fn(ctx, job, progress)
+		switch {
+		case ctx.Err() != nil:
+			return true, ctx.Err()
+		case errors.Is(err, ErrJobCancelled):
+			status = model.JobCancelled
+		case err != nil:
+			status, msg = model.JobFailed, err.Error()
+		}
+		log.Info().Str("status", status).Dur("elapsed", time.Since(start)).Msg("job finished")
+	}
+	return true, r.store.Jobs().Finish(ctx, job.JobID, status, msg)
+}
+
+// EnableJobs makes the service queue long-running operations as jobs for
+// a JobRunner, which RegisterJobs prepares to run them.
+func (s *MemoryService) EnableJobs() { s.jobs = true }
+
+// JobsEnabled reports whether long-running operations are queued as jobs.
+func (s *MemoryService) JobsEnabled() bool { return s.jobs }
+
+// RegisterJobs registers the funcs of the service's job kinds with r:
+// reindexes and bulk entry deletes. Other bulk operations stay outside the
+// jobs subsystem on purpose: ExportEntries streams its response within the
+// request, imports run client-side as idempotent batch writes, and the
+// server has no consolidation operation to queue.
+func (s *MemoryService) RegisterJobs(r *JobRunner) {
+	r.Register(model.JobReindex, s.runReindexJob)
+	r.Register(model.JobDeleteEntries, s.runDeleteEntriesJob)
+}
+
+// GetJob returns one of the actor's jobs with its progress.
+func (s *MemoryService) GetJob(ctx context.Context, actorID, jobID string) (*model.Job, error) {
+	return s.store.Jobs().Get(ctx, actorID, jobID)
+}
+
+// CancelJob cancels a queued job and asks a running one to stop at its next
+// progress report. Work it already did is not undone.
+func (s *MemoryService) CancelJob(ctx context.Context, actorID, jobID string) (*model.Job, error) {
+	return s.store.Jobs().Cancel(ctx, actorID, jobID)
+}
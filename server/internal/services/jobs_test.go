@@ -0,0 +1,253 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+// memJobs keeps jobs in creation order.
+type memJobs struct{ jobs []*model.Job }
+
+func (m *memJobs) Create(_ context.Context, j *model.Job) (*model.Job, error) {
+	out := *j
+	out.JobID = fmt.Sprintf("job-%d", len(m.jobs)+1)
+	out.Status = model.JobQueued
+	out.CreationTime = time.Now()
+	out.UpdateTime = out.CreationTime
+	m.jobs = append(m.jobs, &out)
+	return &out, nil
+}
+
+func (m *memJobs) find(jobID string) *model.Job {
+	for _, j := range m.jobs {
+		if j.JobID == jobID {
+			return j
+		}
+	}
+	return nil
+}
+
+func (m *memJobs) Get(_ context.Context, actorID, jobID string) (*model.Job, error) {
+	if j := m.find(jobID); j != nil && j.ActorID == actorID {
+		out := *j
+		return &out, nil
+	}
+	return nil, model.ErrNotFound
+}
+
+func (m *memJobs) Claim(_ context.Context, staleBefore time.Time) (*model.Job, error) {
+	for _, j := range m.jobs {
+		if j.Status == model.JobQueued || (j.Status == model.JobRunning && j.UpdateTime.Before(staleBefore)) {
+			j.Status = model.JobRunning
+			j.UpdateTime = time.Now()
+			out := *j
+			return &out, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memJobs) Progress(_ context.Context, jobID string, done, total int) (bool, error) {
+	j := m.find(jobID)
+	j.Done, j.Total, j.UpdateTime = done, total, time.Now()
+	return j.CancelRequested, nil
+}
+
+func (m *memJobs) Finish(_ context.Context, jobID, status, errMsg string) error {
+	j := m.find(jobID)
+	j.Status, j.Error = status, errMsg
+	return nil
+}
+
+func (m *memJobs) Cancel(_ context.Context, actorID, jobID string) (*model.Job, error) {
+	j := m.find(jobID)
+	switch {
+	case j == nil || j.ActorID != actorID:
+		return nil, model.ErrNotFound
+	case j.Finished():
+		return nil, model.ErrConflict
+	case j.Status == model.JobQueued:
+		j.Status = model.JobCancelled
+	}
+	j.CancelRequested = true
+	out := *j
+	return &out, nil
+}
+
+func TestJobRunner(t *testing.T) {
+	jobs := &memJobs{}
+	fs := &fakeStore{jobs: jobs}
+	r := NewJobRunner(fs, time.Minute, zerolog.Nop())
+	ctx := context.Background()
+	r.Register("count", func(ctx context.Context, job *model.Job, progress JobProgress) error {
+		for i := 0; i <= 3; i++ {
+			if err := progress(ctx, i, 3); err != nil {
+				return err
+			}
+			if job.MemoryID == "cancel" && i == 1 {
+				jobs.find(job.JobID).CancelRequested = true
+			}
+		}
+		if job.MemoryID == "fail" {
+			return errors.New("boom")
+		}
+		return nil
+	})
+
+	if ran, err := r.RunOnce(ctx); ran || err != nil {
+		t.Fatalf("no jobs: ran=%v err=%v", ran, err)
+	}
+	for _, mid := range []string{"ok", "fail", "cancel"} {
+		_, _ = jobs.Create(ctx, &model.Job{ActorID: "u1", Kind: "count", MemoryID: mid})
+	}
+	_, _ = jobs.Create(ctx, &model.Job{ActorID: "u1", Kind: "nope"})
+	queued, _ := jobs.Create(ctx, &model.Job{ActorID: "u1", Kind: "count"})
+	if j, err := NewMemoryService(fs, nil, nil).CancelJob(ctx, "u1", queued.JobID); err != nil || j.Status != model.JobCancelled {
+		t.Fatalf("cancel queued: %+v %v", j, err)
+	}
+	for {
+		ran, err := r.RunOnce(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ran {
+			break
+		}
+	}
+
+	want := []struct {
+		status, err string
+		done        int
+	}{
+		{model.JobSucceeded, "", 3},
+		{model.JobFailed, "boom", 3},
+		{model.JobCancelled, "", 2},
+		{model.JobFailed, `unknown job kind "nope"`, 0},
+		{model.JobCancelled, "", 0},
+	}
+	for i, w := range want {
+		j := jobs.jobs[i]
+		if j.Status != w.status || j.Error != w.err || j.Done != w.done {
+			t.Fatalf("job %d: status=%s error=%q done=%d, want %+v", i, j.Status, j.Error, j.Done, w)
+		}
+	}
+	if _, err := NewMemoryService(fs, nil, nil).CancelJob(ctx, "u1", "job-1"); !errors.Is(err, model.ErrConflict) {
+		t.Fatalf("cancel finished: expected conflict, got %v", err)
+	}
+}
+
+func TestDeleteEntriesJob(t *testing.T) {
+	jobs := &memJobs{}
+	fs := &fakeStore{jobs: jobs, entriesByMem: map[string][]*model.MemoryEntry{}}
+	for i := 0; i < 120; i++ {
+		fs.entriesByMem["m1"] = append(fs.entriesByMem["m1"], &model.MemoryEntry{EntryID: fmt.Sprintf("e%d", i), MemoryID: "m1"})
+	}
+	svc := NewMemoryService(fs, nil, nil)
+	r := NewJobRunner(fs, time.Minute, zerolog.Nop())
+	svc.RegisterJobs(r)
+	ctx := context.Background()
+
+	if _, err := svc.StartDeleteEntriesJob(ctx, "u1", "v1", "m1", model.EntryDeletion{}); !errors.Is(err, model.ErrValidation) {
+		t.Fatalf("no selector: expected validation error, got %v", err)
+	}
+	if _, err := svc.StartDeleteEntriesJob(ctx, "u1", "v1", "m1", model.EntryDeletion{EntryIDs: make([]string, model.MaxDeleteEntries+1)}); !errors.Is(err, model.ErrValidation) {
+		t.Fatalf("too many ids: expected validation error, got %v", err)
+	}
+	job, err := svc.StartDeleteEntriesJob(ctx, "u1", "v1", "m1", model.EntryDeletion{EntryIDs: []string{"e1", "e2", "missing"}})
+	if err != nil || job.Status != model.JobQueued || job.Kind != model.JobDeleteEntries {
+		t.Fatalf("StartDeleteEntriesJob: %+v %v", job, err)
+	}
+	if len(fs.entriesByMem["m1"]) != 120 {
+		t.Fatal("entries deleted before the job ran")
+	}
+	if _, err := r.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if j, _ := svc.GetJob(ctx, "u1", job.JobID); j.Status != model.JobSucceeded || j.Done != 3 || j.Total != 3 {
+		t.Fatalf("ids job: %+v", j)
+	}
+
+	before := time.Now()
+	job, _ = svc.StartDeleteEntriesJob(ctx, "u1", "v1", "m1", model.EntryDeletion{Before: &before})
+	if _, err := r.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if j, _ := svc.GetJob(ctx, "u1", job.JobID); j.Status != model.JobSucceeded || j.Total != 118 || len(fs.entriesByMem["m1"]) != 0 {
+		t.Fatalf("filter job: %+v, %d entries left", j, len(fs.entriesByMem["m1"]))
+	}
+
+	fs.readOnly = map[string]bool{"v1": true}
+	if _, err := svc.StartDeleteEntriesJob(ctx, "u1", "v1", "m1", model.EntryDeletion{EntryIDs: []string{"e1"}}); !errors.Is(err, model.ErrReadOnly) {
+		t.Fatalf("read-only vault: expected ErrReadOnly, got %v", err)
+	}
+}
+
+// stepReindex runs a rebuild of 4 writes, Failed of them dead-lettered at
+// the start, and applies one more write per Latest call.
+type stepReindex struct {
+	failed int
+	cur    *model.ReindexJob
+}
+
+func (s *stepReindex) Start(_ context.Context, _, memoryID string) (*model.ReindexJob, error) {
+	s.cur = &model.ReindexJob{JobID: "r1", MemoryID: memoryID, EntryCount: 3, ContextCount: 1, Failed: s.failed, Status: model.ReindexRunning}
+	out := *s.cur
+	return &out, nil
+}
+
+func (s *stepReindex) Latest(context.Context, string, string) (*model.ReindexJob, error) {
+	if s.cur == nil {
+		return nil, model.ErrNotFound
+	}
+	if s.cur.Done+s.cur.Failed < 4 {
+		s.cur.Done++
+	}
+	if s.cur.Done+s.cur.Failed == 4 {
+		s.cur.Status = model.ReindexCompleted
+	}
+	out := *s.cur
+	return &out, nil
+}
+
+func TestReindexJob(t *testing.T) {
+	defer func(d time.Duration) { reindexJobPoll = d }(reindexJobPoll)
+	reindexJobPoll = time.Millisecond
+	rs := &stepReindex{}
+	fs := &fakeStore{jobs: &memJobs{}, reindex: rs}
+	svc := NewMemoryService(fs, nil, nil)
+	r := NewJobRunner(fs, time.Minute, zerolog.Nop())
+	svc.RegisterJobs(r)
+	ctx := context.Background()
+
+	job, err := svc.StartReindexJob(ctx, "u1", "m1", false)
+	if err != nil || job.Kind != model.JobReindex {
+		t.Fatalf("StartReindexJob: %+v %v", job, err)
+	}
+	if _, err := r.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if j, _ := svc.GetJob(ctx, "u1", job.JobID); j.Status != model.JobSucceeded || j.Done != 4 || j.Total != 4 {
+		t.Fatalf("reindex job: %+v", j)
+	}
+
+	rs.cur = &model.ReindexJob{EntryCount: 3, ContextCount: 1, Status: model.ReindexRunning}
+	if _, err := svc.StartReindexJob(ctx, "u1", "m1", false); !errors.Is(err, model.ErrConflict) {
+		t.Fatalf("rebuild running: expected conflict, got %v", err)
+	}
+
+	rs.cur, rs.failed = nil, 1
+	job, _ = svc.StartReindexJob(ctx, "u1", "m1", false)
+	if _, err := r.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if j, _ := svc.GetJob(ctx, "u1", job.JobID); j.Status != model.JobFailed || j.Error != "1 of 4 index writes failed" {
+		t.Fatalf("failed writes: %+v", j)
+	}
+}
@@ -2,6 +2,9 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	emb "github.com/mycelian/mycelian-memory/server/internal/embeddings"
 	"github.com/mycelian/mycelian-memory/server/internal/model"
@@ -14,6 +17,14 @@ type MemoryService struct {
 	store store.Store
 	idx   searchindex.Index
 	emb   emb.EmbeddingProvider
+	// dedup is nil unless EnableEntryDedup was called.
+	dedup *entryDedup
+	// trash is set by EnableTrash: deletes become soft deletes.
+	trash bool
+	// jobs is set by EnableJobs: long-running operations are queued.
+	jobs bool
+	// aliasMatchers caches each memory's compiled entity aliases.
+	aliasMatchers aliasMatcherCache
 }
 
 func NewMemoryService(s store.Store, idx searchindex.Index, embProvider emb.EmbeddingProvider) *MemoryService {
@@ -21,6 +32,16 @@ func NewMemoryService(s store.Store, idx searchindex.Index, embProvider emb.Embe
 }
 
 func (s *MemoryService) DeleteMemory(ctx context.Context, userID, vaultID, memoryID string) error {
+	if err := ensureVaultWritable(ctx, s.store, userID, vaultID); err != nil {
+		return err
+	}
+	if err := ensureEntriesMutable(ctx, s.store, userID, vaultID, memoryID); err != nil {
+		return err
+	}
+	if s.trash {
+		// The index keeps the memory's entries until the trash is purged.
+		return s.store.Memories().Trash(ctx, userID, vaultID, memoryID)
+	}
 	if err := s.store.Memories().Delete(ctx, userID, vaultID, memoryID); err != nil {
 		return err
 	}
@@ -32,6 +53,21 @@ func (s *MemoryService) DeleteMemory(ctx context.Context, userID, vaultID, memor
 }
 
 func (s *MemoryService) DeleteEntry(ctx context.Context, userID, vaultID, memoryID, entryID string) error {
+	if err := ensureVaultWritable(ctx, s.store, userID, vaultID); err != nil {
+		return err
+	}
+	if err := ensureEntriesMutable(ctx, s.store, userID, vaultID, memoryID); err != nil {
+		return err
+	}
+	return s.removeEntry(ctx, userID, vaultID, memoryID, entryID)
+}
+
+// removeEntry trashes or deletes the entry once the checks of DeleteEntry
+// passed.
+func (s *MemoryService) removeEntry(ctx context.Context, userID, vaultID, memoryID, entryID string) error {
+	if s.trash {
+		return s.store.Entries().Trash(ctx, userID, vaultID, memoryID, entryID)
+	}
 	if err := s.store.Entries().DeleteByID(ctx, userID, vaultID, memoryID, entryID); err != nil {
 		return err
 	}
@@ -42,6 +78,9 @@ func (s *MemoryService) DeleteEntry(ctx context.Context, userID, vaultID, memory
 }
 
 func (s *MemoryService) DeleteContext(ctx context.Context, userID, vaultID, memoryID, contextID string) error {
+	if err := ensureVaultWritable(ctx, s.store, userID, vaultID); err != nil {
+		return err
+	}
 	if err := s.store.Contexts().DeleteByID(ctx, userID, vaultID, memoryID, contextID); err != nil {
 		return err
 	}
@@ -52,23 +91,226 @@ func (s *MemoryService) DeleteContext(ctx context.Context, userID, vaultID, memo
 }
 
 func (s *MemoryService) CreateEntry(ctx context.Context, e *model.MemoryEntry) (*model.MemoryEntry, error) {
+	if err := ensureVaultWritable(ctx, s.store, e.ActorID, e.VaultID); err != nil {
+		return nil, err
+	}
+	// A keyed creation is deduplicated by the store under its key, which a
+	// creation merged into another in the window would never record.
+	if s.dedup != nil && e.IdempotencyKey == "" {
+		return s.dedup.do(ctx, e, func() (*model.MemoryEntry, error) { return s.createEntry(ctx, e) })
+	}
+	return s.createEntry(ctx, e)
+}
+
+func (s *MemoryService) createEntry(ctx context.Context, e *model.MemoryEntry) (*model.MemoryEntry, error) {
+	if err := s.prepareEntry(ctx, e); err != nil {
+		return nil, err
+	}
 	// For now, delegate to store; indexing is handled out of band for create.
 	return s.store.Entries().Create(ctx, e)
 }
 
+// prepareEntry checks a single new entry against its memory and fills in
+// what the store derives nothing for: usage, expiry and entities.
+func (s *MemoryService) prepareEntry(ctx context.Context, e *model.MemoryEntry) error {
+	if err := normalizeEntryUsage(e); err != nil {
+		return err
+	}
+	mem, err := entryMemory(ctx, s.store, e.ActorID, e.VaultID, e.MemoryID)
+	if err != nil {
+		return err
+	}
+	if err := checkEntryRole(mem.EntryRoles, e); err != nil {
+		return err
+	}
+	if err := setEntryExpiry(e, mem.EntryTTLSeconds, time.Now()); err != nil {
+		return err
+	}
+	return annotateEntities(ctx, s.store, &s.aliasMatchers, e)
+}
+
+// CreateEntries writes up to model.MaxEntriesBatch entries of one memory in
+// a single transaction, in order, for backfills of past conversations. Every
+// entry is checked before the first write, so one invalid entry stores none;
+// its error names the entry's position. Entry dedup does not apply.
+func (s *MemoryService) CreateEntries(ctx context.Context, entries []*model.MemoryEntry) ([]*model.MemoryEntry, error) {
+	if len(entries) == 0 || len(entries) > model.MaxEntriesBatch {
+		return nil, fmt.Errorf("%w: entries must hold 1 to %d entries", model.ErrValidation, model.MaxEntriesBatch)
+	}
+	first := entries[0]
+	if err := ensureVaultWritable(ctx, s.store, first.ActorID, first.VaultID); err != nil {
+		return nil, err
+	}
+	mem, err := entryMemory(ctx, s.store, first.ActorID, first.VaultID, first.MemoryID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for i, e := range entries {
+		if err := normalizeEntryUsage(e); err != nil {
+			return nil, fmt.Errorf("entries[%d]: %w", i, err)
+		}
+		if err := checkEntryRole(mem.EntryRoles, e); err != nil {
+			return nil, fmt.Errorf("entries[%d]: %w", i, err)
+		}
+		if err := setEntryExpiry(e, mem.EntryTTLSeconds, now); err != nil {
+			return nil, fmt.Errorf("entries[%d]: %w", i, err)
+		}
+	}
+	if err := annotateEntities(ctx, s.store, &s.aliasMatchers, entries...); err != nil {
+		return nil, err
+	}
+	return s.store.Entries().CreateBatch(ctx, entries)
+}
+
 func (s *MemoryService) ListEntries(ctx context.Context, req model.ListEntriesRequest) ([]*model.MemoryEntry, error) {
 	return s.store.Entries().List(ctx, req)
 }
 
+// maxScanPatternLen bounds the contains and regex filters of ScanEntries.
+const maxScanPatternLen = 256
+
+// ScanEntries finds entries by exact text in the store, bypassing the search
+// index. At least one of Contains and Regex is required.
+func (s *MemoryService) ScanEntries(ctx context.Context, req model.ScanEntriesRequest) ([]*model.MemoryEntry, error) {
+	if req.Contains == "" && req.Regex == "" {
+		return nil, fmt.Errorf("%w: contains or regex is required", model.ErrValidation)
+	}
+	if len(req.Contains) > maxScanPatternLen || len(req.Regex) > maxScanPatternLen {
+		return nil, fmt.Errorf("%w: contains and regex must be at most %d bytes", model.ErrValidation, maxScanPatternLen)
+	}
+	return s.store.Entries().Scan(ctx, req)
+}
+
+// ErrEmbeddingsUnsupported is returned by ExportEntries when embeddings are
+// requested from an index that cannot read vectors back.
+var ErrEmbeddingsUnsupported = errors.New("search index cannot export embeddings")
+
+// exportVectorBatch bounds the entry IDs sent in one index vector lookup.
+const exportVectorBatch = 100
+
+// ExportEntries returns every entry of the memory, oldest first. With a
+// non-empty embedModel each entry carries its stored vector labelled with
+// that model; entries not yet indexed are exported without one.
+func (s *MemoryService) ExportEntries(ctx context.Context, userID, vaultID, memoryID, embedModel string) ([]model.ExportedEntry, error) {
+	var reader searchindex.VectorReader
+	if embedModel != "" {
+		var ok bool
+		if reader, ok = s.idx.(searchindex.VectorReader); !ok {
+			return nil, ErrEmbeddingsUnsupported
+		}
+	}
+	entries, err := s.store.Entries().List(ctx, model.ListEntriesRequest{
+		ActorID: userID, VaultID: vaultID, MemoryID: memoryID, Ascending: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]model.ExportedEntry, len(entries))
+	for i, e := range entries {
+		out[i].MemoryEntry = e
+	}
+	if reader == nil {
+		return out, nil
+	}
+	for start := 0; start < len(out); start += exportVectorBatch {
+		batch := out[start:min(start+exportVectorBatch, len(out))]
+		ids := make([]string, len(batch))
+		for i, e := range batch {
+			ids[i] = e.EntryID
+		}
+		vectors, err := reader.EntryVectors(ctx, userID, memoryID, ids)
+		if err != nil {
+			return nil, fmt.Errorf("read entry vectors: %w", err)
+		}
+		for i := range batch {
+			if v, ok := vectors[batch[i].EntryID]; ok {
+				batch[i].Embedding = &model.EntryEmbedding{Model: embedModel, Dimension: len(v), Vector: v}
+			}
+		}
+	}
+	return out, nil
+}
+
+// ListSessions summarises the memory's entry sessions, oldest first.
+func (s *MemoryService) ListSessions(ctx context.Context, userID, vaultID, memoryID string) ([]model.EntrySession, error) {
+	return s.store.Entries().Sessions(ctx, userID, vaultID, memoryID)
+}
+
 func (s *MemoryService) GetEntryByID(ctx context.Context, userID, vaultID, memoryID, entryID string) (*model.MemoryEntry, error) {
-	return s.store.Entries().GetByID(ctx, userID, vaultID, memoryID, entryID)
+	out, err := s.store.Entries().GetByID(ctx, userID, vaultID, memoryID, entryID)
+	if err != nil {
+		return nil, err
+	}
+	// Access tracking is best-effort; a failed touch must not fail the read.
+	now := time.Now().UTC()
+	if s.store.Entries().Touch(ctx, userID, []string{entryID}, now) == nil {
+		out.LastAccessedTime = &now
+	}
+	// So is the index status.
+	if ix := s.store.Indexing(); ix != nil {
+		if st, err := ix.Status(ctx, userID, []string{entryID}); err == nil {
+			status := st[entryID]
+			out.IndexStatus = &status
+		}
+	}
+	return out, nil
+}
+
+// TouchEntries records that the listed entries were just returned to the actor
+// (e.g. as search hits) so LRU retention keeps them.
+func (s *MemoryService) TouchEntries(ctx context.Context, userID string, entryIDs []string) error {
+	return s.store.Entries().Touch(ctx, userID, entryIDs, time.Now().UTC())
 }
 
 func (s *MemoryService) UpdateEntryTags(ctx context.Context, userID, vaultID, memoryID, entryID string, tags map[string]interface{}) (*model.MemoryEntry, error) {
+	if err := ensureVaultWritable(ctx, s.store, userID, vaultID); err != nil {
+		return nil, err
+	}
+	if err := ensureEntriesMutable(ctx, s.store, userID, vaultID, memoryID); err != nil {
+		return nil, err
+	}
 	return s.store.Entries().UpdateTags(ctx, userID, vaultID, memoryID, entryID, tags)
 }
 
+// PatchEntryTags applies p to the selected entries in one transaction and
+// returns the IDs of those updated.
+func (s *MemoryService) PatchEntryTags(ctx context.Context, p model.EntryTagPatch) ([]string, error) {
+	if err := validateTagPatch(p); err != nil {
+		return nil, err
+	}
+	if err := ensureVaultWritable(ctx, s.store, p.ActorID, p.VaultID); err != nil {
+		return nil, err
+	}
+	if err := ensureEntriesMutable(ctx, s.store, p.ActorID, p.VaultID, p.MemoryID); err != nil {
+		return nil, err
+	}
+	return s.store.Entries().PatchTags(ctx, p)
+}
+
+func validateTagPatch(p model.EntryTagPatch) error {
+	switch {
+	case len(p.EntryIDs) == 0 && p.SessionID == "" && p.Since == nil && p.Until == nil:
+		return fmt.Errorf("%w: ids, sessionId, since or until is required", model.ErrValidation)
+	case len(p.EntryIDs) > model.MaxTagPatchEntries:
+		return fmt.Errorf("%w: at most %d ids", model.ErrValidation, model.MaxTagPatchEntries)
+	case p.Since != nil && p.Until != nil && !p.Since.Before(*p.Until):
+		return fmt.Errorf("%w: since must be before until", model.ErrValidation)
+	case len(p.Set) == 0 && len(p.Unset) == 0:
+		return fmt.Errorf("%w: set or unset is required", model.ErrValidation)
+	}
+	for _, k := range p.Unset {
+		if _, ok := p.Set[k]; ok {
+			return fmt.Errorf("%w: tag %q is both set and unset", model.ErrValidation, k)
+		}
+	}
+	return nil
+}
+
 func (s *MemoryService) PutContext(ctx context.Context, c *model.MemoryContext) (*model.MemoryContext, error) {
+	if err := ensureVaultWritable(ctx, s.store, c.ActorID, c.VaultID); err != nil {
+		return nil, err
+	}
 	return s.store.Contexts().Put(ctx, c)
 }
 
@@ -76,15 +318,82 @@ func (s *MemoryService) GetLatestContext(ctx context.Context, userID, vaultID, m
 	return s.store.Contexts().Latest(ctx, userID, vaultID, memoryID)
 }
 
+// MaxRecentSummaries caps the entry summaries returned with a context.
+const MaxRecentSummaries = 50
+
+// recentSummaryRunes bounds the raw text used for an entry without a summary.
+const recentSummaryRunes = 280
+
+// RecentEntrySummaries returns the summaries of the memory's n newest
+// entries, newest first, for reading alongside its context.
+func (s *MemoryService) RecentEntrySummaries(ctx context.Context, userID, vaultID, memoryID string, n int) ([]model.EntrySummary, error) {
+	if n <= 0 || n > MaxRecentSummaries {
+		return nil, fmt.Errorf("%w: includeRecentSummaries must be between 1 and %d", model.ErrValidation, MaxRecentSummaries)
+	}
+	entries, err := s.store.Entries().List(ctx, model.ListEntriesRequest{ActorID: userID, VaultID: vaultID, MemoryID: memoryID, Limit: n})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]model.EntrySummary, len(entries))
+	for i, e := range entries {
+		text := e.RawEntry
+		if e.Summary != nil && *e.Summary != "" {
+			text = *e.Summary
+		} else if r := []rune(text); len(r) > recentSummaryRunes {
+			text = string(r[:recentSummaryRunes]) + "…"
+		}
+		out[i] = model.EntrySummary{EntryID: e.EntryID, Summary: text, CreationTime: e.CreationTime, SessionID: e.SessionID}
+	}
+	return out, nil
+}
+
+// GetLatestContexts fetches the latest context of several memories in one batched lookup.
+func (s *MemoryService) GetLatestContexts(ctx context.Context, userID string, memoryIDs []string) (map[string]*model.MemoryContext, error) {
+	return s.store.Contexts().LatestForMemories(ctx, userID, memoryIDs)
+}
+
 // Memory CRUD (container)
 func (s *MemoryService) CreateMemory(ctx context.Context, m *model.Memory) (*model.Memory, error) {
+	if err := validateEntryRoles(m.EntryRoles); err != nil {
+		return nil, err
+	}
+	if err := validateEntryTTL(m.EntryTTLSeconds); err != nil {
+		return nil, err
+	}
+	if err := ensureVaultWritable(ctx, s.store, m.ActorID, m.VaultID); err != nil {
+		return nil, err
+	}
 	return s.store.Memories().Create(ctx, m)
 }
 
+// Bootstrap creates a vault, a memory in it and the memory's first context
+// atomically, replaying the first result for a reused b.ClientToken.
+func (s *MemoryService) Bootstrap(ctx context.Context, b model.Bootstrap) (*model.BootstrapResult, error) {
+	return s.store.Vaults().Bootstrap(ctx, b)
+}
+
 func (s *MemoryService) GetMemory(ctx context.Context, userID, vaultID, memoryID string) (*model.Memory, error) {
 	return s.store.Memories().GetByID(ctx, userID, vaultID, memoryID)
 }
 
+// SetMemoryAppendOnly makes the memory append-only for good: later tag
+// updates, entry deletes and batch rollbacks touching it fail with
+// model.ErrAppendOnly, while retention keeps expiring its entries.
+func (s *MemoryService) SetMemoryAppendOnly(ctx context.Context, userID, vaultID, memoryID string) (*model.Memory, error) {
+	if err := ensureVaultWritable(ctx, s.store, userID, vaultID); err != nil {
+		return nil, err
+	}
+	return s.store.Memories().SetAppendOnly(ctx, userID, vaultID, memoryID)
+}
+
+// UpdateMemory renames the memory and/or changes its description.
+func (s *MemoryService) UpdateMemory(ctx context.Context, userID, vaultID, memoryID string, u model.TitleUpdate) (*model.Memory, error) {
+	if err := ensureVaultWritable(ctx, s.store, userID, vaultID); err != nil {
+		return nil, err
+	}
+	return s.store.Memories().Update(ctx, userID, vaultID, memoryID, u)
+}
+
 func (s *MemoryService) ListMemories(ctx context.Context, userID, vaultID string) ([]*model.Memory, error) {
 	return s.store.Memories().List(ctx, userID, vaultID)
 }
@@ -92,3 +401,13 @@ func (s *MemoryService) ListMemories(ctx context.Context, userID, vaultID string
 func (s *MemoryService) GetMemoryByTitle(ctx context.Context, userID, vaultID, title string) (*model.Memory, error) {
 	return s.store.Memories().GetByTitle(ctx, userID, vaultID, title)
 }
+
+// ResolveMemory finds a memory of the vault by ID, or else by title or slug,
+// for routes whose {memoryId} may name either.
+func (s *MemoryService) ResolveMemory(ctx context.Context, userID, vaultID, ref string) (*model.Memory, error) {
+	m, err := s.store.Memories().GetByID(ctx, userID, vaultID, ref)
+	if errors.Is(err, model.ErrNotFound) {
+		return s.store.Memories().GetByTitle(ctx, userID, vaultID, ref)
+	}
+	return m, err
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+type vectorIndex struct {
+	fakeIndex
+	lookups int
+}
+
+func (v *vectorIndex) EntryVectors(_ context.Context, _, _ string, entryIDs []string) (map[string][]float32, error) {
+	v.lookups++
+	out := map[string][]float32{}
+	for _, id := range entryIDs {
+		if id != "e7" { // not indexed yet
+			out[id] = []float32{1, 0, 0}
+		}
+	}
+	return out, nil
+}
+
+func TestExportEntriesAttachesVectors(t *testing.T) {
+	var entries []*model.MemoryEntry
+	for i := 0; i < 150; i++ {
+		entries = append(entries, &model.MemoryEntry{EntryID: fmt.Sprintf("e%d", i), MemoryID: "m1"})
+	}
+	fs := &fakeStore{entriesByMem: map[string][]*model.MemoryEntry{"m1": entries}}
+	idx := &vectorIndex{}
+
+	out, err := NewMemoryService(fs, idx, nil).ExportEntries(context.Background(), "u1", "v1", "m1", "nomic-embed-text")
+	if err != nil {
+		t.Fatalf("ExportEntries: %v", err)
+	}
+	if len(out) != 150 || idx.lookups != 2 {
+		t.Fatalf("expected 150 entries in 2 lookups, got %d in %d", len(out), idx.lookups)
+	}
+	if e := out[0].Embedding; e == nil || e.Model != "nomic-embed-text" || e.Dimension != 3 {
+		t.Fatalf("unexpected embedding: %+v", e)
+	}
+	if out[7].Embedding != nil {
+		t.Fatalf("unindexed entry should have no embedding")
+	}
+
+	// Without embeddings the index is never consulted.
+	out, err = NewMemoryService(fs, &fakeIndex{}, nil).ExportEntries(context.Background(), "u1", "v1", "m1", "")
+	if err != nil || len(out) != 150 || out[0].Embedding != nil {
+		t.Fatalf("plain export: %d entries err=%v", len(out), err)
+	}
+	if _, err := NewMemoryService(fs, &fakeIndex{}, nil).ExportEntries(context.Background(), "u1", "v1", "m1", "m"); !errors.Is(err, ErrEmbeddingsUnsupported) {
+		t.Fatalf("expected ErrEmbeddingsUnsupported, got %v", err)
+	}
+}
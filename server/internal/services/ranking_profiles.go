@@ -0,0 +1,129 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+// LoadRankingProfiles reads the JSON array of ranking profiles in path, keyed
+// by name. An empty path returns no profiles.
+func LoadRankingProfiles(path string) (map[string]model.RankingProfile, error) {
+	out := map[string]model.RankingProfile{}
+	if path == "" {
+		return out, nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read ranking profiles: %w", err)
+	}
+	var profiles []model.RankingProfile
+	if err := json.Unmarshal(b, &profiles); err != nil {
+		return nil, fmt.Errorf("parse ranking profiles %s: %w", path, err)
+	}
+	for i, p := range profiles {
+		if err := validateRankingProfile(&p); err != nil {
+			return nil, fmt.Errorf("ranking profiles %s: profile %d: %w", path, i, err)
+		}
+		if _, dup := out[p.Name]; dup {
+			return nil, fmt.Errorf("ranking profiles %s: profile %q defined twice", path, p.Name)
+		}
+		out[p.Name] = p
+	}
+	return out, nil
+}
+
+// validateRankingProfile checks p's ranges and normalizes its rankBy.
+func validateRankingProfile(p *model.RankingProfile) error {
+	p.Name = strings.TrimSpace(p.Name)
+	p.RankBy = strings.ToLower(strings.TrimSpace(p.RankBy))
+	switch {
+	case p.Name == "":
+		return fmt.Errorf("name is required")
+	case p.Alpha != nil && (*p.Alpha < 0 || *p.Alpha > 1):
+		return fmt.Errorf("%s: alpha must be in [0, 1]", p.Name)
+	case p.SignalWeight < 0:
+		return fmt.Errorf("%s: signalWeight cannot be negative", p.Name)
+	case p.RecencyHalfLifeHours < 0:
+		return fmt.Errorf("%s: recencyHalfLifeHours cannot be negative", p.Name)
+	case p.Diversity < 0 || p.Diversity > 1:
+		return fmt.Errorf("%s: diversity must be in [0, 1]", p.Name)
+	}
+	switch p.RankBy {
+	case "", model.RankByRelevance, model.RankByRecency, model.RankByHybrid:
+	default:
+		return fmt.Errorf("%s: rankBy must be one of %s, %s, %s", p.Name, model.RankByRelevance, model.RankByRecency, model.RankByHybrid)
+	}
+	return nil
+}
+
+// Diversify reorders hits by maximal marginal relevance: each position goes
+// to the hit with the best (1-lambda)*relevance - lambda*similarity, where
+// relevance is its score relative to the top score and similarity is its
+// largest word overlap (Jaccard) with a hit already placed. Scores are left
+// as they are; lambda 0 keeps the order.
+func Diversify(hits []model.SearchHit, lambda float64) {
+	if len(hits) < 3 || lambda <= 0 {
+		return
+	}
+	top := hits[0].Score
+	for _, h := range hits {
+		top = max(top, h.Score)
+	}
+	words := make([]map[string]bool, len(hits))
+	for i, h := range hits {
+		text := h.Summary
+		if text == "" {
+			text = h.RawEntry
+		}
+		words[i] = wordSet(text)
+	}
+	// maxSim[i] is hit i's largest similarity to the hits placed so far.
+	maxSim := make([]float64, len(hits))
+	for pos := 1; pos < len(hits); pos++ {
+		for i := pos; i < len(hits); i++ {
+			maxSim[i] = max(maxSim[i], jaccard(words[i], words[pos-1]))
+		}
+		best, bestVal := pos, 0.0
+		for i := pos; i < len(hits); i++ {
+			rel := 0.0
+			if top > 0 {
+				rel = hits[i].Score / top
+			}
+			if v := (1-lambda)*rel - lambda*maxSim[i]; i == pos || v > bestVal {
+				best, bestVal = i, v
+			}
+		}
+		hits[pos], hits[best] = hits[best], hits[pos]
+		words[pos], words[best] = words[best], words[pos]
+		maxSim[pos], maxSim[best] = maxSim[best], maxSim[pos]
+	}
+}
+
+// wordSet returns the lower-cased words of s.
+func wordSet(s string) map[string]bool {
+	out := map[string]bool{}
+	for _, w := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		out[w] = true
+	}
+	return out
+}
+
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for w := range a {
+		if b[w] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
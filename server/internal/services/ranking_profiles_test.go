@@ -0,0 +1,81 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+func TestLoadRankingProfiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	write := func(body string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(`[{"name": "support", "alpha": 0.4, "rerank": true, "diversity": 0.3},
+	        {"name": "timeline", "rankBy": "Recency", "recencyHalfLifeHours": 24}]`)
+	profiles, err := LoadRankingProfiles(path)
+	if err != nil {
+		t.Fatalf("LoadRankingProfiles: %v", err)
+	}
+	if p := profiles["support"]; p.Alpha == nil || *p.Alpha != 0.4 || p.Rerank == nil || !*p.Rerank || p.Diversity != 0.3 {
+		t.Fatalf("unexpected support profile: %+v", p)
+	}
+	if p := profiles["timeline"]; p.RankBy != model.RankByRecency || p.RecencyHalfLifeHours != 24 || p.Alpha != nil {
+		t.Fatalf("unexpected timeline profile: %+v", p)
+	}
+
+	for _, body := range []string{
+		`[{"alpha": 0.5}]`,
+		`[{"name": "a", "alpha": 1.5}]`,
+		`[{"name": "a", "diversity": -0.1}]`,
+		`[{"name": "a", "rankBy": "popularity"}]`,
+		`[{"name": "a"}, {"name": "a"}]`,
+	} {
+		write(body)
+		if _, err := LoadRankingProfiles(path); err == nil {
+			t.Fatalf("expected error for %s", body)
+		}
+	}
+
+	if profiles, err := LoadRankingProfiles(""); err != nil || len(profiles) != 0 {
+		t.Fatalf("empty path: %v %v", profiles, err)
+	}
+}
+
+func TestDiversify(t *testing.T) {
+	hits := func() []model.SearchHit {
+		return []model.SearchHit{
+			{EntryID: "a", Summary: "deploy the api to staging", Score: 1.0},
+			{EntryID: "a2", Summary: "deploy the api to staging today", Score: 0.95},
+			{EntryID: "b", Summary: "billing invoice overdue", Score: 0.8},
+		}
+	}
+	ids := func(h []model.SearchHit) string {
+		s := ""
+		for _, hit := range h {
+			s += hit.EntryID + " "
+		}
+		return s
+	}
+
+	h := hits()
+	Diversify(h, 0)
+	if got := ids(h); got != "a a2 b " {
+		t.Fatalf("lambda 0 reordered: %s", got)
+	}
+
+	h = hits()
+	Diversify(h, 0.5)
+	if got := ids(h); got != "a b a2 " {
+		t.Fatalf("unexpected diversified order: %s", got)
+	}
+	if h[2].Score != 0.95 {
+		t.Fatalf("scores changed: %+v", h)
+	}
+}
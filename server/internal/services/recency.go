@@ -0,0 +1,38 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+// RankByRecency applies exponential time decay to hit scores and re-sorts by
+// the adjusted score. An entry halfLife old keeps half its score under
+// model.RankByRecency and three quarters under model.RankByHybrid; hits with
+// no creationTime keep their score. model.RankByRelevance is a no-op.
+func RankByRecency(hits []model.SearchHit, rankBy string, halfLife time.Duration, now time.Time) {
+	if len(hits) == 0 || halfLife <= 0 || (rankBy != model.RankByRecency && rankBy != model.RankByHybrid) {
+		return
+	}
+	for i := range hits {
+		if hits[i].CreationTime == nil {
+			continue
+		}
+		d := recencyDecay(now.Sub(*hits[i].CreationTime), halfLife)
+		if rankBy == model.RankByHybrid {
+			d = (1 + d) / 2
+		}
+		hits[i].Score *= d
+	}
+	sort.SliceStable(hits, func(a, b int) bool { return hits[a].Score > hits[b].Score })
+}
+
+// recencyDecay is 0.5^(age/halfLife); entries from the future count as new.
+func recencyDecay(age, halfLife time.Duration) float64 {
+	if age <= 0 {
+		return 1
+	}
+	return math.Exp2(-float64(age) / float64(halfLife))
+}
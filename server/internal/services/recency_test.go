@@ -0,0 +1,40 @@
+package services
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+func TestRankByRecency(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	at := func(age time.Duration) *time.Time { ts := now.Add(-age); return &ts }
+	hits := func() []model.SearchHit {
+		return []model.SearchHit{
+			{EntryID: "old", Score: 0.9, CreationTime: at(14 * 24 * time.Hour)},
+			{EntryID: "new", Score: 0.5, CreationTime: at(0)},
+			{EntryID: "undated", Score: 0.4},
+		}
+	}
+	week := 7 * 24 * time.Hour
+
+	h := hits()
+	RankByRecency(h, model.RankByRelevance, week, now)
+	if h[0].EntryID != "old" || h[0].Score != 0.9 {
+		t.Fatalf("relevance changed ranking: %+v", h)
+	}
+
+	h = hits()
+	RankByRecency(h, model.RankByRecency, week, now)
+	if h[0].EntryID != "new" || h[1].EntryID != "undated" || math.Abs(h[2].Score-0.225) > 1e-9 {
+		t.Fatalf("unexpected recency ranking: %+v", h)
+	}
+
+	h = hits()
+	RankByRecency(h, model.RankByHybrid, week, now)
+	if h[0].EntryID != "old" || math.Abs(h[0].Score-0.5625) > 1e-9 || h[1].EntryID != "new" {
+		t.Fatalf("unexpected hybrid ranking: %+v", h)
+	}
+}
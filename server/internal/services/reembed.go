@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/store"
+)
+
+// EmbeddingFingerprint identifies the configuration the index is embedded
+// with: provider and model, plus version, an operator-chosen label bumped
+// when summary prompts or other inputs to the embeddings change.
+func EmbeddingFingerprint(provider, model, version string) string {
+	fp := provider + ":" + model
+	if version != "" {
+		fp += "@" + version
+	}
+	return fp
+}
+
+// Reembedder re-embeds memories gradually after the embedding fingerprint
+// changed, instead of a disruptive reindex of everything at once. Records go
+// through the outbox at no more than PerMinute, and no more than PerMinute
+// are left waiting for the worker.
+type Reembedder struct {
+	store       store.Store
+	fingerprint string
+	perMinute   int
+	log         zerolog.Logger
+}
+
+func NewReembedder(s store.Store, fingerprint string, perMinute int, log zerolog.Logger) *Reembedder {
+	return &Reembedder{store: s, fingerprint: fingerprint, perMinute: perMinute, log: log}
+}
+
+// MarkDrift records the fingerprint and, when it differs from the recorded
+// one, marks every memory for re-embedding. The first fingerprint recorded
+// is taken to be the one the existing index was built with.
+func (r *Reembedder) MarkDrift(ctx context.Context) (int, error) {
+	n, err := r.store.Reembed().MarkDrift(ctx, r.fingerprint)
+	if err == nil && n > 0 {
+		r.log.Info().Str("fingerprint", r.fingerprint).Int("memories", n).Msg("embedding configuration changed; memories marked for re-embedding")
+	}
+	return n, err
+}
+
+// Start enqueues a batch immediately and then every interval until ctx is done.
+func (r *Reembedder) Start(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		n, err := r.RunOnce(ctx, interval)
+		if err != nil && ctx.Err() == nil {
+			r.log.Warn().Err(err).Msg("re-embedding pass failed")
+		} else if n > 0 {
+			r.log.Debug().Int("enqueued", n).Msg("re-embedding pass completed")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// RunOnce enqueues the share of PerMinute that falls in interval, at least one
+// record, and returns how many were enqueued.
+func (r *Reembedder) RunOnce(ctx context.Context, interval time.Duration) (int, error) {
+	if r.perMinute <= 0 {
+		return 0, nil
+	}
+	limit := int(math.Ceil(float64(r.perMinute) * interval.Minutes()))
+	return r.store.Reembed().EnqueueNext(ctx, max(limit, 1))
+}
+
+// ReembedProgress reports the memory's gradual re-embedding.
+func (s *MemoryService) ReembedProgress(ctx context.Context, actorID, memoryID string) (*model.ReembedProgress, error) {
+	return s.store.Reembed().Progress(ctx, actorID, memoryID)
+}
+
+// ReembedOverview summarises re-embedding across all memories.
+func (s *MemoryService) ReembedOverview(ctx context.Context) (*model.ReembedOverview, error) {
+	return s.store.Reembed().Overview(ctx)
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/store"
+)
+
+type fakeReembed struct {
+	store.Reembed
+	recorded string
+	limits   []int
+}
+
+func (f *fakeReembed) MarkDrift(_ context.Context, fingerprint string) (int, error) {
+	if f.recorded == "" || f.recorded == fingerprint {
+		f.recorded = fingerprint
+		return 0, nil
+	}
+	f.recorded = fingerprint
+	return 3, nil
+}
+
+func (f *fakeReembed) EnqueueNext(_ context.Context, limit int) (int, error) {
+	f.limits = append(f.limits, limit)
+	return limit, nil
+}
+
+func (f *fakeReembed) Progress(_ context.Context, _, memoryID string) (*model.ReembedProgress, error) {
+	return &model.ReembedProgress{MemoryID: memoryID, Status: model.ReembedRunning}, nil
+}
+
+func TestEmbeddingFingerprint(t *testing.T) {
+	if fp := EmbeddingFingerprint("ollama", "nomic-embed-text", ""); fp != "ollama:nomic-embed-text" {
+		t.Fatalf("fingerprint = %q", fp)
+	}
+	if fp := EmbeddingFingerprint("openai", "text-embedding-3-small", "prompts-v2"); fp != "openai:text-embedding-3-small@prompts-v2" {
+		t.Fatalf("fingerprint = %q", fp)
+	}
+}
+
+func TestReembedder(t *testing.T) {
+	ctx := context.Background()
+	rs := &fakeReembed{}
+	st := &fakeStore{reembed: rs}
+
+	if n, err := NewReembedder(st, "ollama:a", 600, zerolog.Nop()).MarkDrift(ctx); n != 0 || err != nil {
+		t.Fatalf("baseline: n=%d err=%v", n, err)
+	}
+	r := NewReembedder(st, "ollama:b", 600, zerolog.Nop())
+	if n, err := r.MarkDrift(ctx); n != 3 || err != nil {
+		t.Fatalf("drift: n=%d err=%v", n, err)
+	}
+
+	// The per-minute rate is spread over the interval, at least one record.
+	for _, interval := range []time.Duration{10 * time.Second, time.Minute, time.Millisecond} {
+		if _, err := r.RunOnce(ctx, interval); err != nil {
+			t.Fatalf("RunOnce: %v", err)
+		}
+	}
+	if want := []int{100, 600, 1}; len(rs.limits) != 3 || rs.limits[0] != want[0] || rs.limits[1] != want[1] || rs.limits[2] != want[2] {
+		t.Fatalf("limits = %v, want %v", rs.limits, want)
+	}
+
+	if n, err := NewReembedder(st, "ollama:b", 0, zerolog.Nop()).RunOnce(ctx, time.Minute); n != 0 || err != nil || len(rs.limits) != 3 {
+		t.Fatalf("zero rate enqueued: n=%d err=%v", n, err)
+	}
+}
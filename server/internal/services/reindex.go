@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+// ReindexMemory enqueues a rebuild of one memory's search index from the
+// store. With purge the memory's current index objects are deleted first so
+// strays left by corruption disappear; search on the memory is then
+// incomplete until the job completes.
+func (s *MemoryService) ReindexMemory(ctx context.Context, actorID, memoryID string, purge bool) (*model.ReindexJob, error) {
+	// Refuse before purging: the running job would not restore what the
+	// purge deletes from under it. Start enforces this across replicas.
+	if job, err := s.store.Reindex().Latest(ctx, actorID, memoryID); err == nil && job.Status == model.ReindexRunning {
+		return nil, fmt.Errorf("%w: a reindex of memory %s is still running", model.ErrConflict, memoryID)
+	}
+	if purge && s.idx != nil {
+		if err := s.idx.DeleteMemory(ctx, actorID, memoryID); err != nil {
+			return nil, err
+		}
+	}
+	return s.store.Reindex().Start(ctx, actorID, memoryID)
+}
+
+// ReindexProgress reports the memory's most recent reindex job.
+func (s *MemoryService) ReindexProgress(ctx context.Context, actorID, memoryID string) (*model.ReindexJob, error) {
+	return s.store.Reindex().Latest(ctx, actorID, memoryID)
+}
+
+// reindexJobPoll is how often a reindex job checks its rebuild's progress.
+var reindexJobPoll = 2 * time.Second
+
+// StartReindexJob queues a JobReindex job: the jobs worker runs
+// ReindexMemory and follows the rebuild until the index has applied it.
+// Cancelling the job stops following it; writes already enqueued still apply.
+func (s *MemoryService) StartReindexJob(ctx context.Context, actorID, memoryID string, purge bool) (*model.Job, error) {
+	if job, err := s.store.Reindex().Latest(ctx, actorID, memoryID); err == nil && job.Status == model.ReindexRunning {
+		return nil, fmt.Errorf("%w: a reindex of memory %s is still running", model.ErrConflict, memoryID)
+	}
+	params, err := json.Marshal(model.ReindexJobParams{Purge: purge})
+	if err != nil {
+		return nil, err
+	}
+	return s.store.Jobs().Create(ctx, &model.Job{ActorID: actorID, Kind: model.JobReindex, MemoryID: memoryID, Params: params})
+}
+
+// runReindexJob runs a JobReindex job. Progress counts the rebuild's index
+// writes applied or dead-lettered; any dead-lettered write fails the job.
+func (s *MemoryService) runReindexJob(ctx context.Context, job *model.Job, progress JobProgress) error {
+	var p model.ReindexJobParams
+	if len(job.Params) > 0 {
+		if err := json.Unmarshal(job.Params, &p); err != nil {
+			return fmt.Errorf("params: %w", err)
+		}
+	}
+	rj, err := s.ReindexMemory(ctx, job.ActorID, job.MemoryID, p.Purge)
+	if errors.Is(err, model.ErrConflict) {
+		// Claimed again after its worker stopped: follow the running rebuild.
+		rj, err = s.ReindexProgress(ctx, job.ActorID, job.MemoryID)
+	}
+	for err == nil {
+		total := rj.EntryCount + rj.ContextCount
+		if rj.Status != model.ReindexRunning {
+			if err := progress(ctx, rj.Done+rj.Failed, total); err != nil && !errors.Is(err, ErrJobCancelled) {
+				return err
+			}
+			if rj.Failed > 0 {
+				return fmt.Errorf("%d of %d index writes failed", rj.Failed, total)
+			}
+			return nil
+		}
+		if err := progress(ctx, rj.Done+rj.Failed, total); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(reindexJobPoll):
+		}
+		rj, err = s.ReindexProgress(ctx, job.ActorID, job.MemoryID)
+	}
+	return err
+}
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"context"
+	"sort"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/reranker"
+)
+
+// Rerank scores hits against query with r, sets their RerankScore to it and
+// Score to it times the boost of their memory in boosts (nil for none), so
+// per-memory boosts survive reranking, and re-sorts them. Each hit is judged
+// on its raw entry, or its summary when the index stored no raw text. On
+// error the hits are left as they were.
+func Rerank(ctx context.Context, r reranker.Reranker, query string, hits []model.SearchHit, boosts map[string]*model.SearchBoost) error {
+	if len(hits) == 0 {
+		return nil
+	}
+	docs := make([]string, len(hits))
+	for i, h := range hits {
+		docs[i] = h.RawEntry
+		if docs[i] == "" {
+			docs[i] = h.Summary
+		}
+	}
+	scores, err := r.Rerank(ctx, query, docs)
+	if err != nil {
+		return err
+	}
+	for i := range hits {
+		s := scores[i]
+		hits[i].RerankScore = &s
+		hits[i].Score = s
+		if b := boosts[hits[i].MemoryID]; b != nil && b.Boost != 0 {
+			hits[i].Score *= b.Boost
+		}
+	}
+	sort.SliceStable(hits, func(a, b int) bool { return hits[a].Score > hits[b].Score })
+	return nil
+}
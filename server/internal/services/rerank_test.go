@@ -0,0 +1,54 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+type rerankFunc func(query string, documents []string) ([]float64, error)
+
+func (f rerankFunc) Rerank(_ context.Context, query string, documents []string) ([]float64, error) {
+	return f(query, documents)
+}
+
+func TestRerank(t *testing.T) {
+	hits := []model.SearchHit{
+		{EntryID: "e1", RawEntry: "likes tea", Score: 0.9},
+		{EntryID: "e2", Summary: "lives in Lisbon", Score: 0.5},
+	}
+	var judged []string
+	err := Rerank(context.Background(), rerankFunc(func(_ string, docs []string) ([]float64, error) {
+		judged = docs
+		return []float64{0.1, 0.8}, nil
+	}), "where does the user live?", hits, nil)
+	if err != nil || hits[0].EntryID != "e2" || hits[0].Score != 0.8 || *hits[0].RerankScore != 0.8 || hits[1].Score != 0.1 {
+		t.Fatalf("Rerank: %+v %v", hits, err)
+	}
+	if judged[1] != "lives in Lisbon" {
+		t.Fatalf("hits without raw text are judged on their summary: %q", judged)
+	}
+
+	before := []model.SearchHit{{EntryID: "e1", Score: 0.9}}
+	if err := Rerank(context.Background(), rerankFunc(func(string, []string) ([]float64, error) {
+		return nil, errors.New("down")
+	}), "q", before, nil); err == nil || before[0].Score != 0.9 || before[0].RerankScore != nil {
+		t.Fatalf("a failed rerank must leave hits alone: %+v %v", before, err)
+	}
+}
+
+func TestRerankKeepsMemoryBoosts(t *testing.T) {
+	hits := []model.SearchHit{
+		{EntryID: "e1", MemoryID: "m1", RawEntry: "a", Score: 0.9},
+		{EntryID: "e2", MemoryID: "m2", RawEntry: "b", Score: 0.4},
+	}
+	boosts := map[string]*model.SearchBoost{"m2": {Boost: 3}}
+	err := Rerank(context.Background(), rerankFunc(func(string, []string) ([]float64, error) {
+		return []float64{0.5, 0.3}, nil
+	}), "q", hits, boosts)
+	if err != nil || hits[0].EntryID != "e2" || hits[0].Score < 0.89 || hits[0].Score > 0.91 || *hits[0].RerankScore != 0.3 {
+		t.Fatalf("boosted memory lost its boost after reranking: %+v %v", hits, err)
+	}
+}
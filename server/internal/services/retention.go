@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/mycelian/mycelian-memory/server/internal/store"
+)
+
+// EntryRetention expires entries after Days. With ByAccess the clock starts
+// at the entry's last get or search hit instead of its creation, so entries
+// that keep being used are retained and least-recently-accessed ones go first.
+type EntryRetention struct {
+	Days     int
+	ByAccess bool
+}
+
+// retentionBatchSize bounds how many entries are deleted per transaction.
+const retentionBatchSize = 500
+
+// EntryReaper periodically deletes entries past their expiration time and
+// entries that fall outside an EntryRetention policy.
+type EntryReaper struct {
+	store  store.Store
+	policy EntryRetention
+	log    zerolog.Logger
+}
+
+func NewEntryReaper(s store.Store, policy EntryRetention, log zerolog.Logger) *EntryReaper {
+	return &EntryReaper{store: s, policy: policy, log: log}
+}
+
+// Start runs a retention pass immediately and then every interval until ctx is done.
+func (r *EntryReaper) Start(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		start := time.Now()
+		n, err := r.RunOnce(ctx, start)
+		if err != nil && ctx.Err() == nil {
+			r.log.Warn().Err(err).Int("deleted", n).Msg("entry retention pass failed")
+		} else if n > 0 {
+			r.log.Info().Int("deleted", n).Bool("by_access", r.policy.ByAccess).Dur("elapsed", time.Since(start)).Msg("entry retention pass completed")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// RunOnce deletes every entry past its expiration time, then every entry
+// outside the policy, in batches and returns how many were removed.
+func (r *EntryReaper) RunOnce(ctx context.Context, now time.Time) (int, error) {
+	deleted, err := drainExpired(func(limit int) ([]string, error) {
+		return r.store.Entries().ExpireDue(ctx, now, limit)
+	})
+	if err != nil || r.policy.Days <= 0 {
+		return deleted, err
+	}
+	cutoff := now.AddDate(0, 0, -r.policy.Days)
+	n, err := drainExpired(func(limit int) ([]string, error) {
+		return r.store.Entries().Expire(ctx, cutoff, r.policy.ByAccess, limit)
+	})
+	return deleted + n, err
+}
+
+// drainExpired calls expire with retentionBatchSize until it deletes fewer,
+// and returns how many it deleted.
+func drainExpired(expire func(limit int) ([]string, error)) (int, error) {
+	deleted := 0
+	for {
+		ids, err := expire(retentionBatchSize)
+		deleted += len(ids)
+		if err != nil || len(ids) < retentionBatchSize {
+			return deleted, err
+		}
+	}
+}
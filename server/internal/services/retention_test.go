@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/store"
+)
+
+type retentionEntries struct {
+	store.Entries
+	remaining int
+	due       int
+	cutoff    time.Time
+	byAccess  bool
+	touched   []string
+}
+
+func (e *retentionEntries) Expire(_ context.Context, cutoff time.Time, byAccess bool, limit int) ([]string, error) {
+	e.cutoff, e.byAccess = cutoff, byAccess
+	n := min(limit, e.remaining)
+	e.remaining -= n
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("e%d", i)
+	}
+	return ids, nil
+}
+
+func (e *retentionEntries) ExpireDue(_ context.Context, _ time.Time, limit int) ([]string, error) {
+	n := min(limit, e.due)
+	e.due -= n
+	return make([]string, n), nil
+}
+
+func (e *retentionEntries) GetByID(_ context.Context, _, _, _, entryID string) (*model.MemoryEntry, error) {
+	return &model.MemoryEntry{EntryID: entryID}, nil
+}
+
+func (e *retentionEntries) Touch(_ context.Context, _ string, ids []string, _ time.Time) error {
+	e.touched = append(e.touched, ids...)
+	return nil
+}
+
+type retentionStore struct {
+	*fakeStore
+	e *retentionEntries
+}
+
+func (s retentionStore) Entries() store.Entries { return s.e }
+
+func TestEntryReaper_RunOnce(t *testing.T) {
+	now := time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+	es := &retentionEntries{remaining: retentionBatchSize + 7, due: 3}
+	r := NewEntryReaper(retentionStore{&fakeStore{}, es}, EntryRetention{Days: 30, ByAccess: true}, zerolog.Nop())
+	n, err := r.RunOnce(context.Background(), now)
+	if err != nil || n != retentionBatchSize+10 {
+		t.Fatalf("RunOnce: n=%d err=%v", n, err)
+	}
+	if !es.byAccess || !es.cutoff.Equal(now.AddDate(0, 0, -30)) {
+		t.Fatalf("unexpected expire args: cutoff=%v byAccess=%v", es.cutoff, es.byAccess)
+	}
+
+	es = &retentionEntries{remaining: 5, due: 2}
+	disabled := NewEntryReaper(retentionStore{&fakeStore{}, es}, EntryRetention{}, zerolog.Nop())
+	if n, err := disabled.RunOnce(context.Background(), now); n != 2 || err != nil || es.remaining != 5 {
+		t.Fatalf("disabled policy: deleted %d, %d left (err=%v); want only the 2 due", n, es.remaining, err)
+	}
+}
+
+func TestGetEntryByID_TouchesEntry(t *testing.T) {
+	es := &retentionEntries{}
+	svc := NewMemoryService(retentionStore{&fakeStore{}, es}, nil, nil)
+	e, err := svc.GetEntryByID(context.Background(), "a", "v", "m", "e1")
+	if err != nil || e.LastAccessedTime == nil {
+		t.Fatalf("GetEntryByID: e=%+v err=%v", e, err)
+	}
+	if len(es.touched) != 1 || es.touched[0] != "e1" {
+		t.Fatalf("touched: %v", es.touched)
+	}
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+// maxSearchBoost bounds a memory's boost and each of its field weights.
+const maxSearchBoost = 10
+
+// SetMemorySearchBoost stores how the memory's hits are weighed in
+// vault-scope searches. A boost with neither Boost nor FieldWeights set, or
+// nil, clears it.
+func (s *MemoryService) SetMemorySearchBoost(ctx context.Context, userID, vaultID, memoryID string, b *model.SearchBoost) (*model.Memory, error) {
+	if b != nil && b.Boost == 0 && len(b.FieldWeights) == 0 {
+		b = nil
+	}
+	if err := validateSearchBoost(b); err != nil {
+		return nil, err
+	}
+	if err := ensureVaultWritable(ctx, s.store, userID, vaultID); err != nil {
+		return nil, err
+	}
+	return s.store.Memories().SetSearchBoost(ctx, userID, vaultID, memoryID, b)
+}
+
+// validateSearchBoost accepts a boost in (0, 10] and weights in [0, 10] of
+// known fields, at least one of them positive.
+func validateSearchBoost(b *model.SearchBoost) error {
+	if b == nil {
+		return nil
+	}
+	if b.Boost < 0 || b.Boost > maxSearchBoost {
+		return fmt.Errorf("%w: boost must be between 0 and %d", model.ErrValidation, maxSearchBoost)
+	}
+	if len(b.FieldWeights) == 0 {
+		return nil
+	}
+	positive := false
+	for field, w := range b.FieldWeights {
+		switch field {
+		case model.SearchFieldSummary, model.SearchFieldRawEntry, model.SearchFieldTags:
+		default:
+			return fmt.Errorf("%w: fieldWeights: unknown field %q (want summary, rawEntry or tags)", model.ErrValidation, field)
+		}
+		if w < 0 || w > maxSearchBoost {
+			return fmt.Errorf("%w: fieldWeights.%s must be between 0 and %d", model.ErrValidation, field, maxSearchBoost)
+		}
+		positive = positive || w > 0
+	}
+	if !positive {
+		return fmt.Errorf("%w: fieldWeights must give at least one field a positive weight", model.ErrValidation)
+	}
+	return nil
+}
+
+// BoostHits multiplies the scores of one memory's hits by its boost.
+func BoostHits(hits []model.SearchHit, b *model.SearchBoost) {
+	if b == nil || b.Boost == 0 || b.Boost == 1 {
+		return
+	}
+	for i := range hits {
+		hits[i].Score *= b.Boost
+	}
+}
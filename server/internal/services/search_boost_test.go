@@ -0,0 +1,52 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+func TestSetMemorySearchBoost(t *testing.T) {
+	fs := &fakeStore{}
+	svc := NewMemoryService(fs, nil, nil)
+	ctx := context.Background()
+
+	for _, b := range []*model.SearchBoost{
+		{Boost: -1},
+		{Boost: 11},
+		{FieldWeights: map[string]float64{"title": 1}},
+		{FieldWeights: map[string]float64{model.SearchFieldSummary: 20}},
+		{FieldWeights: map[string]float64{model.SearchFieldSummary: 0, model.SearchFieldTags: 0}},
+	} {
+		if _, err := svc.SetMemorySearchBoost(ctx, "u1", "v1", "m1", b); !errors.Is(err, model.ErrValidation) {
+			t.Fatalf("%+v: expected validation error, got %v", b, err)
+		}
+	}
+	m, err := svc.SetMemorySearchBoost(ctx, "u1", "v1", "m1", &model.SearchBoost{Boost: 2, FieldWeights: map[string]float64{model.SearchFieldSummary: 3, model.SearchFieldRawEntry: 0}})
+	if err != nil || m.SearchBoost == nil || m.SearchBoost.Boost != 2 {
+		t.Fatalf("SetMemorySearchBoost: m=%+v err=%v", m, err)
+	}
+	if m, err := svc.SetMemorySearchBoost(ctx, "u1", "v1", "m1", &model.SearchBoost{}); err != nil || m.SearchBoost != nil {
+		t.Fatalf("clear: m=%+v err=%v", m, err)
+	}
+
+	fs.readOnly = map[string]bool{"v1": true}
+	if _, err := svc.SetMemorySearchBoost(ctx, "u1", "v1", "m1", &model.SearchBoost{Boost: 2}); !errors.Is(err, model.ErrReadOnly) {
+		t.Fatalf("read-only vault: expected ErrReadOnly, got %v", err)
+	}
+}
+
+func TestBoostHits(t *testing.T) {
+	hits := []model.SearchHit{{EntryID: "a", Score: 0.5}, {EntryID: "b", Score: 0.25}}
+	BoostHits(hits, nil)
+	BoostHits(hits, &model.SearchBoost{FieldWeights: map[string]float64{model.SearchFieldTags: 1}})
+	if hits[0].Score != 0.5 {
+		t.Fatalf("no boost changed the score to %v", hits[0].Score)
+	}
+	BoostHits(hits, &model.SearchBoost{Boost: 2})
+	if hits[0].Score != 1 || hits[1].Score != 0.5 {
+		t.Fatalf("boosted scores: %+v", hits)
+	}
+}
@@ -0,0 +1,30 @@
+package services
+
+import "github.com/mycelian/mycelian-memory/server/internal/model"
+
+// GroupBySession collapses hits of the same session into the first one, so
+// many turns of one conversation take a single result. Hits must be in rank
+// order; the kept hit's SessionHits counts the session's hits. Hits without a
+// session stay as they are, with SessionHits 1.
+func GroupBySession(hits []model.SearchHit) []model.SearchHit {
+	if len(hits) == 0 {
+		return hits
+	}
+	first := make(map[string]int, len(hits))
+	out := hits[:0]
+	for _, h := range hits {
+		if h.SessionID == "" {
+			h.SessionHits = 1
+			out = append(out, h)
+			continue
+		}
+		if i, ok := first[h.SessionID]; ok {
+			out[i].SessionHits++
+			continue
+		}
+		first[h.SessionID] = len(out)
+		h.SessionHits = 1
+		out = append(out, h)
+	}
+	return out
+}
@@ -0,0 +1,33 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+func TestGroupBySession(t *testing.T) {
+	hits := GroupBySession([]model.SearchHit{
+		{EntryID: "a1", SessionID: "a", Score: 0.9},
+		{EntryID: "b1", SessionID: "b", Score: 0.8},
+		{EntryID: "a2", SessionID: "a", Score: 0.7},
+		{EntryID: "x", Score: 0.6},
+		{EntryID: "a3", SessionID: "a", Score: 0.5},
+		{EntryID: "y", Score: 0.4},
+	})
+	want := []struct {
+		id    string
+		count int
+	}{{"a1", 3}, {"b1", 1}, {"x", 1}, {"y", 1}}
+	if len(hits) != len(want) {
+		t.Fatalf("got %d groups, want %d: %+v", len(hits), len(want), hits)
+	}
+	for i, w := range want {
+		if hits[i].EntryID != w.id || hits[i].SessionHits != w.count {
+			t.Fatalf("group %d: got %s x%d, want %s x%d", i, hits[i].EntryID, hits[i].SessionHits, w.id, w.count)
+		}
+	}
+	if got := GroupBySession(nil); len(got) != 0 {
+		t.Fatalf("nil hits: %+v", got)
+	}
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+// MaxScopeMemories caps how many memories one title-scoped search fans out to.
+const MaxScopeMemories = 20
+
+// ResolveMemoryScope returns the vault's memories named in titles, or whose
+// title matches pattern, sorted by title. pattern uses glob syntax ("*", "?",
+// "[...]", e.g. "proj-x-*"); exactly one of titles and pattern is set. An
+// unknown title is model.ErrNotFound; a pattern matching nothing is not an
+// error. More than MaxScopeMemories matches is model.ErrValidation.
+func (s *MemoryService) ResolveMemoryScope(ctx context.Context, userID, vaultID string, titles []string, pattern string) ([]*model.Memory, error) {
+	if (len(titles) == 0) == (pattern == "") {
+		return nil, fmt.Errorf("%w: set one of memoryTitles and memoryPattern", model.ErrValidation)
+	}
+	if pattern != "" {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("%w: invalid memoryPattern %q", model.ErrValidation, pattern)
+		}
+	}
+	if _, err := s.store.Vaults().GetByID(ctx, userID, vaultID); err != nil {
+		return nil, err
+	}
+	mems, err := s.store.Memories().List(ctx, userID, vaultID)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []*model.Memory
+	if pattern != "" {
+		for _, m := range mems {
+			if ok, _ := path.Match(pattern, m.Title); ok {
+				out = append(out, m)
+			}
+		}
+	} else {
+		byTitle := make(map[string]*model.Memory, len(mems))
+		for _, m := range mems {
+			byTitle[m.Title] = m
+		}
+		var missing []string
+		for _, t := range titles {
+			if m, ok := byTitle[t]; ok {
+				out = append(out, m)
+			} else {
+				missing = append(missing, t)
+			}
+		}
+		if len(missing) > 0 {
+			return nil, fmt.Errorf("%w: no memory titled %s in vault %s", model.ErrNotFound, strings.Join(missing, ", "), vaultID)
+		}
+	}
+	if len(out) > MaxScopeMemories {
+		return nil, fmt.Errorf("%w: scope matches %d memories; at most %d allowed", model.ErrValidation, len(out), MaxScopeMemories)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Title < out[j].Title })
+	return out, nil
+}
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+func TestResolveMemoryScope(t *testing.T) {
+	fs := &fakeStore{mems: []*model.Memory{
+		{MemoryID: "m2", Title: "proj-x-notes"},
+		{MemoryID: "m1", Title: "proj-x-decisions"},
+		{MemoryID: "m3", Title: "people"},
+	}}
+	svc := NewMemoryService(fs, nil, nil)
+	ctx := context.Background()
+
+	got, err := svc.ResolveMemoryScope(ctx, "u1", "v1", nil, "proj-x-*")
+	if err != nil || len(got) != 2 || got[0].MemoryID != "m1" || got[1].MemoryID != "m2" {
+		t.Fatalf("pattern scope: %+v %v", got, err)
+	}
+	if got, err := svc.ResolveMemoryScope(ctx, "u1", "v1", []string{"people"}, ""); err != nil || len(got) != 1 || got[0].MemoryID != "m3" {
+		t.Fatalf("title scope: %+v %v", got, err)
+	}
+	if _, err := svc.ResolveMemoryScope(ctx, "u1", "v1", []string{"people", "nope"}, ""); !errors.Is(err, model.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for an unknown title, got %v", err)
+	}
+	if _, err := svc.ResolveMemoryScope(ctx, "u1", "v1", nil, "[a-"); !errors.Is(err, model.ErrValidation) {
+		t.Fatalf("expected ErrValidation for a bad pattern, got %v", err)
+	}
+
+	for i := 0; i < MaxScopeMemories; i++ {
+		fs.mems = append(fs.mems, &model.Memory{MemoryID: fmt.Sprintf("x%d", i), Title: fmt.Sprintf("proj-x-%d", i)})
+	}
+	if _, err := svc.ResolveMemoryScope(ctx, "u1", "v1", nil, "proj-x-*"); !errors.Is(err, model.ErrValidation) {
+		t.Fatalf("expected ErrValidation above %d memories, got %v", MaxScopeMemories, err)
+	}
+}
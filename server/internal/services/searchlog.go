@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/store"
+)
+
+// SearchLogService records search queries and relevance feedback so ranking
+// parameters (alpha, reranking) can be tuned against real usage.
+type SearchLogService struct {
+	store store.Store
+}
+
+func NewSearchLogService(s store.Store) *SearchLogService {
+	return &SearchLogService{store: s}
+}
+
+// RecordQuery logs a served search and returns it with its assigned queryId.
+func (s *SearchLogService) RecordQuery(ctx context.Context, q *model.SearchQuery) (*model.SearchQuery, error) {
+	return s.store.SearchLog().RecordQuery(ctx, q)
+}
+
+// SubmitFeedback marks which results of a logged query were useful. Entry IDs
+// must be among the query's returned results. Resubmitting replaces earlier feedback.
+func (s *SearchLogService) SubmitFeedback(ctx context.Context, actorID, queryID string, usefulEntryIDs []string) error {
+	q, err := s.store.SearchLog().GetQuery(ctx, actorID, queryID)
+	if err != nil {
+		return err
+	}
+	returned := make(map[string]bool, len(q.EntryIDs))
+	for _, id := range q.EntryIDs {
+		returned[id] = true
+	}
+	seen := make(map[string]bool, len(usefulEntryIDs))
+	deduped := make([]string, 0, len(usefulEntryIDs))
+	for _, id := range usefulEntryIDs {
+		if !returned[id] {
+			return fmt.Errorf("%w: entryId %s was not returned by query %s", model.ErrValidation, id, queryID)
+		}
+		if !seen[id] {
+			seen[id] = true
+			deduped = append(deduped, id)
+		}
+	}
+	return s.store.SearchLog().RecordFeedback(ctx, actorID, queryID, deduped)
+}
+
+// Precision aggregates feedback for an actor, optionally scoped to a memory and time window.
+func (s *SearchLogService) Precision(ctx context.Context, actorID, memoryID string, since *time.Time) (*model.SearchPrecision, error) {
+	return s.store.SearchLog().Precision(ctx, actorID, memoryID, since)
+}
+
+// Bounds of one search log export.
+const (
+	DefaultSearchLogExportLimit = 10000
+	MaxSearchLogExportLimit     = 100000
+)
+
+// ExportQueries returns logged queries oldest first for evaluation with IR
+// tooling (see WriteTRECRun and WriteTRECQrels). limit 0 uses
+// DefaultSearchLogExportLimit.
+func (s *SearchLogService) ExportQueries(ctx context.Context, actorID, memoryID string, since *time.Time, limit int) ([]*model.SearchQuery, error) {
+	if limit == 0 {
+		limit = DefaultSearchLogExportLimit
+	}
+	if limit < 1 || limit > MaxSearchLogExportLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", model.ErrValidation, MaxSearchLogExportLimit)
+	}
+	return s.store.SearchLog().List(ctx, actorID, memoryID, since, limit)
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+type fakeSearchLog struct {
+	queries  map[string]*model.SearchQuery
+	feedback map[string][]string
+}
+
+func (f *fakeSearchLog) RecordQuery(_ context.Context, q *model.SearchQuery) (*model.SearchQuery, error) {
+	out := *q
+	out.QueryID = "q1"
+	f.queries[out.QueryID] = &out
+	return &out, nil
+}
+func (f *fakeSearchLog) GetQuery(_ context.Context, _ string, queryID string) (*model.SearchQuery, error) {
+	if q, ok := f.queries[queryID]; ok {
+		return q, nil
+	}
+	return nil, model.ErrNotFound
+}
+func (f *fakeSearchLog) RecordFeedback(_ context.Context, _ string, queryID string, ids []string) error {
+	f.feedback[queryID] = ids
+	return nil
+}
+func (f *fakeSearchLog) Precision(context.Context, string, string, *time.Time) (*model.SearchPrecision, error) {
+	return &model.SearchPrecision{}, nil
+}
+func (f *fakeSearchLog) List(context.Context, string, string, *time.Time, int) ([]*model.SearchQuery, error) {
+	var out []*model.SearchQuery
+	for _, q := range f.queries {
+		out = append(out, q)
+	}
+	return out, nil
+}
+
+func TestSearchLogService_SubmitFeedback(t *testing.T) {
+	sl := &fakeSearchLog{queries: map[string]*model.SearchQuery{}, feedback: map[string][]string{}}
+	svc := NewSearchLogService(&fakeStore{searchLog: sl})
+	ctx := context.Background()
+
+	q, err := svc.RecordQuery(ctx, &model.SearchQuery{ActorID: "u1", MemoryID: "m1", Query: "q", EntryIDs: []string{"e1", "e2", "e3"}})
+	if err != nil {
+		t.Fatalf("RecordQuery: %v", err)
+	}
+
+	if err := svc.SubmitFeedback(ctx, "u1", q.QueryID, []string{"e2", "e1", "e2"}); err != nil {
+		t.Fatalf("SubmitFeedback: %v", err)
+	}
+	if got := sl.feedback[q.QueryID]; !reflect.DeepEqual(got, []string{"e2", "e1"}) {
+		t.Fatalf("feedback not deduplicated: %v", got)
+	}
+
+	if err := svc.SubmitFeedback(ctx, "u1", q.QueryID, []string{"e9"}); !errors.Is(err, model.ErrValidation) {
+		t.Fatalf("expected validation error for unreturned entry, got %v", err)
+	}
+	if err := svc.SubmitFeedback(ctx, "u1", "missing", nil); !errors.Is(err, model.ErrNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+}
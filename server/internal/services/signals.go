@@ -0,0 +1,54 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+// RecordEntrySignal records an agent's quality judgement (useful, incorrect,
+// outdated) against an entry and returns the entry with updated counters.
+func (s *MemoryService) RecordEntrySignal(ctx context.Context, userID, vaultID, memoryID, entryID, signal string) (*model.MemoryEntry, error) {
+	switch signal {
+	case model.SignalUseful, model.SignalIncorrect, model.SignalOutdated:
+	default:
+		return nil, fmt.Errorf("%w: signal must be one of %s, %s, %s", model.ErrValidation, model.SignalUseful, model.SignalIncorrect, model.SignalOutdated)
+	}
+	if err := ensureVaultWritable(ctx, s.store, userID, vaultID); err != nil {
+		return nil, err
+	}
+	return s.store.Entries().RecordSignal(ctx, userID, vaultID, memoryID, entryID, signal)
+}
+
+// RankBySignals loads the quality signals of hits in one query, scales each
+// score by signalFactor and re-sorts by the adjusted score.
+func (s *MemoryService) RankBySignals(ctx context.Context, userID string, hits []model.SearchHit, weight float64) error {
+	if len(hits) == 0 || weight <= 0 {
+		return nil
+	}
+	ids := make([]string, len(hits))
+	for i, h := range hits {
+		ids[i] = h.EntryID
+	}
+	sig, err := s.store.Entries().Signals(ctx, userID, ids)
+	if err != nil {
+		return err
+	}
+	for i := range hits {
+		hits[i].EntrySignals = sig[hits[i].EntryID]
+		hits[i].Score *= signalFactor(hits[i].EntrySignals, weight)
+	}
+	sort.SliceStable(hits, func(a, b int) bool { return hits[a].Score > hits[b].Score })
+	return nil
+}
+
+// signalFactor is 1 + weight*(ln(1+useful) - ln(1+incorrect+outdated)),
+// floored at 0. Logarithms keep a handful of votes meaningful while stopping
+// a heavily-voted entry from dominating relevance.
+func signalFactor(s model.EntrySignals, weight float64) float64 {
+	f := 1 + weight*(math.Log1p(float64(s.UsefulCount))-math.Log1p(float64(s.IncorrectCount+s.OutdatedCount)))
+	return math.Max(f, 0)
+}
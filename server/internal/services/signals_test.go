@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/store"
+)
+
+type signalEntries struct {
+	store.Entries
+	sig      map[string]model.EntrySignals
+	recorded []string
+}
+
+func (e *signalEntries) Signals(_ context.Context, _ string, ids []string) (map[string]model.EntrySignals, error) {
+	return e.sig, nil
+}
+func (e *signalEntries) RecordSignal(_ context.Context, _, _, _, entryID, signal string) (*model.MemoryEntry, error) {
+	e.recorded = append(e.recorded, signal)
+	return &model.MemoryEntry{EntryID: entryID, EntrySignals: model.EntrySignals{UsefulCount: 1}}, nil
+}
+
+type signalStore struct {
+	*fakeStore
+	e *signalEntries
+}
+
+func (s signalStore) Entries() store.Entries { return s.e }
+
+func TestRankBySignals(t *testing.T) {
+	es := &signalEntries{sig: map[string]model.EntrySignals{
+		"good": {UsefulCount: 3},
+		"bad":  {IncorrectCount: 2, OutdatedCount: 1},
+	}}
+	svc := NewMemoryService(signalStore{&fakeStore{}, es}, nil, nil)
+	hits := []model.SearchHit{{EntryID: "bad", Score: 0.9}, {EntryID: "plain", Score: 0.8}, {EntryID: "good", Score: 0.7}}
+
+	if err := svc.RankBySignals(context.Background(), "u1", hits, 0.2); err != nil {
+		t.Fatalf("RankBySignals: %v", err)
+	}
+	order := []string{hits[0].EntryID, hits[1].EntryID, hits[2].EntryID}
+	if order[0] != "good" || order[2] != "bad" {
+		t.Fatalf("expected useful entry first and demoted entry last, got %v", order)
+	}
+	if hits[1].Score != 0.8 || hits[0].UsefulCount != 3 {
+		t.Fatalf("unexpected scores/signals: %+v", hits)
+	}
+
+	// weight 0 leaves ranking untouched
+	hits = []model.SearchHit{{EntryID: "bad", Score: 0.9}, {EntryID: "good", Score: 0.7}}
+	_ = svc.RankBySignals(context.Background(), "u1", hits, 0)
+	if hits[0].EntryID != "bad" || hits[0].Score != 0.9 {
+		t.Fatalf("expected no-op with weight 0, got %+v", hits)
+	}
+}
+
+func TestRecordEntrySignal(t *testing.T) {
+	es := &signalEntries{}
+	fs := &fakeStore{readOnly: map[string]bool{"ro": true}}
+	svc := NewMemoryService(signalStore{fs, es}, nil, nil)
+	ctx := context.Background()
+
+	if _, err := svc.RecordEntrySignal(ctx, "u1", "v1", "m1", "e1", "great"); !errors.Is(err, model.ErrValidation) {
+		t.Fatalf("expected ErrValidation for unknown signal, got %v", err)
+	}
+	if _, err := svc.RecordEntrySignal(ctx, "u1", "ro", "m1", "e1", model.SignalUseful); !errors.Is(err, model.ErrReadOnly) {
+		t.Fatalf("expected ErrReadOnly, got %v", err)
+	}
+	if out, err := svc.RecordEntrySignal(ctx, "u1", "v1", "m1", "e1", model.SignalOutdated); err != nil || out.EntryID != "e1" {
+		t.Fatalf("RecordEntrySignal: out=%+v err=%v", out, err)
+	}
+	if len(es.recorded) != 1 || es.recorded[0] != model.SignalOutdated {
+		t.Fatalf("expected one outdated signal recorded, got %v", es.recorded)
+	}
+}
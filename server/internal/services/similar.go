@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/searchindex"
+)
+
+// Similar entries limits.
+const (
+	DefaultSimilarEntries = 10
+	MaxSimilarEntries     = 100
+)
+
+// ErrSearchUnavailable is returned by lookups that need the search index and
+// embedder when the service runs without them.
+var ErrSearchUnavailable = errors.New("search not configured")
+
+// SimilarEntries returns up to topK entries nearest to the entry's embedding
+// (topK <= 0 uses the default), from its memory or, with scope "vault", from
+// every memory of the vault. The entry's stored vector is reused when the
+// index can read it back; otherwise its summary (raw entry when it has none)
+// is embedded as the outbox would. Results are then scored by cosine
+// similarity so hits from different memories compare. The lookup does not
+// count as access for LRU retention.
+func (s *MemoryService) SimilarEntries(ctx context.Context, userID, vaultID, memoryID, entryID, scope string, topK int) (*model.SimilarEntries, error) {
+	if scope == "" {
+		scope = model.SimilarScopeMemory
+	}
+	if scope != model.SimilarScopeMemory && scope != model.SimilarScopeVault {
+		return nil, fmt.Errorf("%w: scope must be %q or %q", model.ErrValidation, model.SimilarScopeMemory, model.SimilarScopeVault)
+	}
+	if topK <= 0 {
+		topK = DefaultSimilarEntries
+	}
+	if topK > MaxSimilarEntries {
+		return nil, fmt.Errorf("%w: topK must be at most %d", model.ErrValidation, MaxSimilarEntries)
+	}
+	if s.idx == nil || s.emb == nil {
+		return nil, ErrSearchUnavailable
+	}
+	entry, err := s.store.Entries().GetByID(ctx, userID, vaultID, memoryID, entryID)
+	if err != nil {
+		return nil, err
+	}
+	text := entry.RawEntry
+	if entry.Summary != nil && *entry.Summary != "" {
+		text = *entry.Summary
+	}
+
+	out := &model.SimilarEntries{EntryID: entryID, MemoryID: memoryID, Scope: scope, TopK: topK,
+		VectorSource: "embedded", Scoring: "index", Entries: []model.SearchHit{}}
+	reader, _ := s.idx.(searchindex.VectorReader)
+	var vec []float32
+	if reader != nil {
+		out.Scoring = "cosine"
+		vecs, err := reader.EntryVectors(ctx, userID, memoryID, []string{entryID})
+		if err != nil {
+			return nil, fmt.Errorf("read entry vector: %w", err)
+		}
+		if v, ok := vecs[entryID]; ok {
+			vec, out.VectorSource = v, "stored"
+		}
+	}
+	if vec == nil {
+		if vec, err = s.emb.Embed(ctx, text); err != nil {
+			return nil, fmt.Errorf("embed entry: %w", err)
+		}
+	}
+
+	memoryIDs := []string{memoryID}
+	if scope == model.SimilarScopeVault {
+		mems, err := s.store.Memories().List(ctx, userID, vaultID)
+		if err != nil {
+			return nil, err
+		}
+		memoryIDs = memoryIDs[:0]
+		for _, m := range mems {
+			memoryIDs = append(memoryIDs, m.MemoryID)
+		}
+	}
+	filter := model.SearchFilter{MustNot: model.SearchMustNot{EntryIDs: []string{entryID}}}
+	for _, mid := range memoryIDs {
+		// Alpha 1 ranks by vector alone; the text only feeds the unused keyword half.
+		hits, err := s.idx.Search(ctx, userID, mid, text, vec, topK, 1, filter)
+		if err != nil {
+			return nil, fmt.Errorf("search memory %s: %w", mid, err)
+		}
+		if reader != nil && len(hits) > 0 {
+			if err := RescoreByCosine(ctx, reader, userID, mid, vec, hits); err != nil {
+				return nil, err
+			}
+		}
+		out.Entries = append(out.Entries, hits...)
+	}
+	if s.trash {
+		if out.Entries, err = s.DropTrashed(ctx, userID, out.Entries); err != nil {
+			return nil, err
+		}
+	}
+	// The index keeps an expired entry until the reaper deletes it.
+	if out.Entries, err = s.DropExpired(ctx, userID, out.Entries); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(out.Entries, func(i, j int) bool { return out.Entries[i].Score > out.Entries[j].Score })
+	if len(out.Entries) > topK {
+		out.Entries = out.Entries[:topK]
+	}
+	return out, nil
+}
+
+// RescoreByCosine replaces the index scores of hits, which are only
+// comparable within one search, with their cosine similarity to vec.
+func RescoreByCosine(ctx context.Context, reader searchindex.VectorReader, userID, memoryID string, vec []float32, hits []model.SearchHit) error {
+	ids := make([]string, len(hits))
+	for i, h := range hits {
+		ids[i] = h.EntryID
+	}
+	vecs, err := reader.EntryVectors(ctx, userID, memoryID, ids)
+	if err != nil {
+		return fmt.Errorf("read hit vectors: %w", err)
+	}
+	for i := range hits {
+		if v, ok := vecs[hits[i].EntryID]; ok {
+			hits[i].Score = CosineSimilarity(vec, v)
+		}
+	}
+	return nil
+}
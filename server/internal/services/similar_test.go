@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+// similarIndex returns the entries of hits for each memory searched and
+// reads vectors from vecs.
+type similarIndex struct {
+	fakeIndex
+	hits     map[string][]model.SearchHit // memoryID -> hits
+	vecs     map[string][]float32
+	searched []string
+	excluded []string
+}
+
+func (x *similarIndex) Search(_ context.Context, _, memoryID, _ string, _ []float32, topK int, alpha float32, f model.SearchFilter) ([]model.SearchHit, error) {
+	x.searched = append(x.searched, memoryID)
+	x.excluded = f.MustNot.EntryIDs
+	if alpha != 1 {
+		return nil, errors.New("expected a pure vector search")
+	}
+	out := append([]model.SearchHit(nil), x.hits[memoryID]...)
+	if len(out) > topK {
+		out = out[:topK]
+	}
+	return out, nil
+}
+
+func (x *similarIndex) EntryVectors(_ context.Context, _, _ string, ids []string) (map[string][]float32, error) {
+	out := map[string][]float32{}
+	for _, id := range ids {
+		if v, ok := x.vecs[id]; ok {
+			out[id] = v
+		}
+	}
+	return out, nil
+}
+
+type countingEmbedder struct{ calls int }
+
+func (c *countingEmbedder) Embed(context.Context, string) ([]float32, error) {
+	c.calls++
+	return []float32{0, 1}, nil
+}
+
+func TestSimilarEntries(t *testing.T) {
+	summary := "deploys go through staging"
+	fs := &fakeStore{
+		mems: []*model.Memory{{MemoryID: "m1"}, {MemoryID: "m2"}},
+		entriesByMem: map[string][]*model.MemoryEntry{
+			"m1": {{EntryID: "e1", MemoryID: "m1", RawEntry: "we deploy via staging", Summary: &summary}},
+		},
+	}
+	idx := &similarIndex{
+		hits: map[string][]model.SearchHit{
+			"m1": {{EntryID: "e2", MemoryID: "m1", Score: 0.9}, {EntryID: "e3", MemoryID: "m1", Score: 0.5}},
+			"m2": {{EntryID: "e4", MemoryID: "m2", Score: 1}},
+		},
+		vecs: map[string][]float32{"e1": {1, 0}, "e2": {0.6, 0.8}, "e3": {1, 0.1}, "e4": {0, 1}},
+	}
+	emb := &countingEmbedder{}
+	svc := NewMemoryService(fs, idx, emb)
+	ctx := context.Background()
+
+	out, err := svc.SimilarEntries(ctx, "u1", "v1", "m1", "e1", "", 0)
+	if err != nil {
+		t.Fatalf("SimilarEntries: %v", err)
+	}
+	if out.Scope != model.SimilarScopeMemory || out.TopK != DefaultSimilarEntries || out.VectorSource != "stored" || out.Scoring != "cosine" || emb.calls != 0 {
+		t.Fatalf("unexpected lookup: %+v (embeds=%d)", out, emb.calls)
+	}
+	// Rescored by cosine, e3 (nearly parallel to e1) now ranks above e2.
+	if len(out.Entries) != 2 || out.Entries[0].EntryID != "e3" || out.Entries[1].EntryID != "e2" {
+		t.Fatalf("unexpected order: %+v", out.Entries)
+	}
+	if len(idx.excluded) != 1 || idx.excluded[0] != "e1" {
+		t.Fatalf("the entry itself must be excluded, got %v", idx.excluded)
+	}
+
+	idx.searched = nil
+	out, err = svc.SimilarEntries(ctx, "u1", "v1", "m1", "e1", model.SimilarScopeVault, 2)
+	if err != nil || len(idx.searched) != 2 {
+		t.Fatalf("vault scope: out=%+v searched=%v err=%v", out, idx.searched, err)
+	}
+	if len(out.Entries) != 2 || out.Entries[0].EntryID != "e3" || out.Entries[1].EntryID != "e2" {
+		t.Fatalf("vault scope: unexpected entries %+v", out.Entries)
+	}
+
+	// An expired entry the reaper has not deleted yet is left out.
+	past := time.Now().Add(-time.Minute)
+	fs.entriesByMem["m1"] = append(fs.entriesByMem["m1"], &model.MemoryEntry{EntryID: "e3", MemoryID: "m1", ExpirationTime: &past})
+	if out, err = svc.SimilarEntries(ctx, "u1", "v1", "m1", "e1", "", 0); err != nil || len(out.Entries) != 1 || out.Entries[0].EntryID != "e2" {
+		t.Fatalf("expired entry: out=%+v err=%v", out, err)
+	}
+	fs.entriesByMem["m1"] = fs.entriesByMem["m1"][:1]
+
+	// Not yet indexed: the summary is embedded instead.
+	delete(idx.vecs, "e1")
+	if out, err = svc.SimilarEntries(ctx, "u1", "v1", "m1", "e1", "", 1); err != nil || out.VectorSource != "embedded" || emb.calls != 1 || len(out.Entries) != 1 {
+		t.Fatalf("unindexed entry: out=%+v embeds=%d err=%v", out, emb.calls, err)
+	}
+
+	if _, err := svc.SimilarEntries(ctx, "u1", "v1", "m1", "e1", "actor", 0); !errors.Is(err, model.ErrValidation) {
+		t.Fatalf("bad scope: expected ErrValidation, got %v", err)
+	}
+	if _, err := svc.SimilarEntries(ctx, "u1", "v1", "m1", "e1", "", MaxSimilarEntries+1); !errors.Is(err, model.ErrValidation) {
+		t.Fatalf("topK too large: expected ErrValidation, got %v", err)
+	}
+	if _, err := svc.SimilarEntries(ctx, "u1", "v1", "m1", "missing", "", 0); !errors.Is(err, model.ErrNotFound) {
+		t.Fatalf("unknown entry: expected ErrNotFound, got %v", err)
+	}
+	if _, err := NewMemoryService(fs, nil, nil).SimilarEntries(ctx, "u1", "v1", "m1", "e1", "", 0); !errors.Is(err, ErrSearchUnavailable) {
+		t.Fatalf("no index: expected ErrSearchUnavailable, got %v", err)
+	}
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/store"
+	"github.com/mycelian/mycelian-memory/server/internal/summarizer"
+)
+
+// Bounds of the entries one summarize request reads.
+const (
+	DefaultSummarizeEntries = 20
+	MaxSummarizeEntries     = 200
+)
+
+// defaultContextMarker identifies the placeholder context written when a
+// memory is created; it is treated as no context.
+const defaultContextMarker = "This is default context that's created with the memory."
+
+// SummarizeRequest regenerates a memory's context from its LastN newest
+// entries (DefaultSummarizeEntries when zero). Preview returns the proposed
+// context without saving it. Location sets the date the prompt reports as
+// today (UTC when nil).
+type SummarizeRequest struct {
+	ActorID, VaultID, MemoryID string
+	LastN                      int
+	Preview                    bool
+	Location                   *time.Location
+}
+
+// SummarizeResult is the regenerated context. Saved is set when it was
+// stored as the memory's latest context; ContextID and CreationTime then
+// identify the snapshot.
+type SummarizeResult struct {
+	Context      string     `json:"context"`
+	EntryCount   int        `json:"entryCount"`
+	Preview      bool       `json:"preview"`
+	Saved        bool       `json:"saved"`
+	ContextID    string     `json:"contextId,omitempty"`
+	CreationTime *time.Time `json:"creationTime,omitempty"`
+}
+
+// SummarizeService rewrites context documents with an LLM out of band, for
+// agents that write entries but rarely put context.
+type SummarizeService struct {
+	store    store.Store
+	gen      summarizer.Generator
+	maxChars int
+}
+
+// NewSummarizeService returns a service generating with gen. Contexts
+// longer than maxChars runes are rejected (0 means no limit).
+func NewSummarizeService(s store.Store, gen summarizer.Generator, maxChars int) *SummarizeService {
+	return &SummarizeService{store: s, gen: gen, maxChars: maxChars}
+}
+
+// Summarize merges the memory's latest context with its newest entries
+// into a new context document and, unless req.Preview, stores it.
+// model.ErrValidation reports a bad LastN, a memory without entries or a
+// generated document exceeding the size limit.
+func (s *SummarizeService) Summarize(ctx context.Context, req SummarizeRequest) (*SummarizeResult, error) {
+	if req.LastN == 0 {
+		req.LastN = DefaultSummarizeEntries
+	}
+	if req.LastN < 1 || req.LastN > MaxSummarizeEntries {
+		return nil, fmt.Errorf("%w: lastN must be between 1 and %d", model.ErrValidation, MaxSummarizeEntries)
+	}
+	if req.Location == nil {
+		req.Location = time.UTC
+	}
+	mem, err := s.store.Memories().GetByID(ctx, req.ActorID, req.VaultID, req.MemoryID)
+	if err != nil {
+		return nil, err
+	}
+	if !req.Preview {
+		if err := ensureVaultWritable(ctx, s.store, req.ActorID, req.VaultID); err != nil {
+			return nil, err
+		}
+	}
+	entries, err := s.store.Entries().List(ctx, model.ListEntriesRequest{
+		ActorID: req.ActorID, VaultID: req.VaultID, MemoryID: req.MemoryID, Limit: req.LastN,
+		OrderBy: model.EntryOrderConversationTime,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("%w: memory has no entries to summarize", model.ErrValidation)
+	}
+	latest, err := s.store.Contexts().LatestForMemories(ctx, req.ActorID, []string{req.MemoryID})
+	if err != nil {
+		return nil, err
+	}
+	var current string
+	if c := latest[req.MemoryID]; c != nil && !strings.Contains(c.Context, defaultContextMarker) {
+		current = c.Context
+	}
+
+	// List is newest first by conversation time; the prompt reads entries in
+	// the order they happened, which for ingested history is not the order
+	// they were written.
+	raw := make([]string, len(entries))
+	for i, e := range entries {
+		raw[len(entries)-1-i] = e.RawEntry
+	}
+	doc, err := s.gen.Generate(ctx, summarizer.ContextPrompt(summarizer.ContextPromptInput{
+		MemoryTitle: mem.Title,
+		MemoryType:  mem.MemoryType,
+		Today:       time.Now().In(req.Location).Format(time.DateOnly),
+		Current:     current,
+		Entries:     raw,
+		MaxChars:    s.maxChars,
+	}))
+	if err != nil {
+		return nil, fmt.Errorf("generate context: %w", err)
+	}
+	if doc == "" {
+		return nil, fmt.Errorf("generate context: empty response")
+	}
+	if s.maxChars > 0 && utf8.RuneCountInString(doc) > s.maxChars {
+		return nil, fmt.Errorf("%w: generated context exceeds %d characters", model.ErrValidation, s.maxChars)
+	}
+
+	out := &SummarizeResult{Context: doc, EntryCount: len(entries), Preview: req.Preview}
+	if req.Preview {
+		return out, nil
+	}
+	saved, err := s.store.Contexts().Put(ctx, &model.MemoryContext{
+		ActorID: req.ActorID, VaultID: req.VaultID, MemoryID: req.MemoryID, Context: doc,
+	})
+	if err != nil {
+		return nil, err
+	}
+	out.Saved = true
+	out.ContextID = saved.ContextID
+	out.CreationTime = &saved.CreationTime
+	return out, nil
+}
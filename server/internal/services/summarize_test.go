@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+type fakeGenerator struct{ prompt, reply string }
+
+func (g *fakeGenerator) Generate(_ context.Context, prompt string) (string, error) {
+	g.prompt = prompt
+	return g.reply, nil
+}
+
+func TestSummarizeService(t *testing.T) {
+	ctx := context.Background()
+	fs := &fakeStore{
+		entriesByMem: map[string][]*model.MemoryEntry{"m1": {{RawEntry: "newer"}, {RawEntry: "older"}}},
+		ctxByMem:     map[string]*model.MemoryContext{"m1": {Context: "# Facts\n- CEO = Bob"}},
+	}
+	gen := &fakeGenerator{reply: "# Facts\n- CEO = Alice"}
+	svc := NewSummarizeService(fs, gen, 100)
+	req := SummarizeRequest{ActorID: "a", VaultID: "v1", MemoryID: "m1", Preview: true}
+
+	out, err := svc.Summarize(ctx, req)
+	if err != nil || out.Saved || out.Context != gen.reply || out.EntryCount != 2 {
+		t.Fatalf("preview: out=%+v err=%v", out, err)
+	}
+	if fs.ctxByMem["m1"].Context != "# Facts\n- CEO = Bob" {
+		t.Fatalf("preview must not save")
+	}
+	if !strings.Contains(gen.prompt, "CEO = Bob") || strings.Index(gen.prompt, "older") > strings.Index(gen.prompt, "newer") {
+		t.Fatalf("prompt must hold the current context and entries oldest first:\n%s", gen.prompt)
+	}
+
+	req.Preview = false
+	if out, err = svc.Summarize(ctx, req); err != nil || !out.Saved || out.ContextID == "" {
+		t.Fatalf("save: out=%+v err=%v", out, err)
+	}
+	if fs.ctxByMem["m1"].Context != gen.reply {
+		t.Fatalf("context not saved: %q", fs.ctxByMem["m1"].Context)
+	}
+
+	gen.reply = strings.Repeat("x", 101)
+	if _, err := svc.Summarize(ctx, req); !errors.Is(err, model.ErrValidation) {
+		t.Fatalf("oversized context: expected validation error, got %v", err)
+	}
+	if _, err := svc.Summarize(ctx, SummarizeRequest{ActorID: "a", VaultID: "v1", MemoryID: "empty"}); !errors.Is(err, model.ErrValidation) {
+		t.Fatalf("no entries: expected validation error, got %v", err)
+	}
+	if _, err := svc.Summarize(ctx, SummarizeRequest{MemoryID: "m1", LastN: MaxSummarizeEntries + 1}); !errors.Is(err, model.ErrValidation) {
+		t.Fatalf("lastN: expected validation error, got %v", err)
+	}
+	fs.readOnly = map[string]bool{"v1": true}
+	if _, err := svc.Summarize(ctx, req); !errors.Is(err, model.ErrReadOnly) {
+		t.Fatalf("read-only vault: expected ErrReadOnly, got %v", err)
+	}
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+// builtinVaultTemplates are available unless an operator's template file
+// replaces them by name.
+var builtinVaultTemplates = []model.VaultTemplate{
+	{
+		Name:        "project",
+		Description: "Working memory for a software or research project",
+		Memories: []model.TemplateMemory{
+			{
+				Title:       "project-context",
+				MemoryType:  "project",
+				Description: "Goals, scope, constraints and current status",
+				Context:     "# Project context\n\n## Goals\n\n## Constraints\n\n## Status\n",
+			},
+			{
+				Title:       "decisions",
+				MemoryType:  "decisions",
+				Description: "Decisions taken, with their rationale and alternatives",
+				Context:     "# Decisions\n\nOne entry per decision: what, why, alternatives considered.\n",
+			},
+			{
+				Title:       "people",
+				MemoryType:  "people",
+				Description: "Stakeholders, their roles and preferences",
+				Context:     "# People\n\n## Stakeholders\n",
+			},
+		},
+	},
+	{
+		Name:        "personal-assistant",
+		Description: "Long-lived memory for an assistant working with one person",
+		Memories: []model.TemplateMemory{
+			{
+				Title:       "preferences",
+				MemoryType:  "preferences",
+				Description: "Stated likes, dislikes and standing instructions",
+				Context:     "# Preferences\n",
+			},
+			{
+				Title:       "people",
+				MemoryType:  "people",
+				Description: "People the user mentions and how they relate",
+				Context:     "# People\n",
+			},
+			{
+				Title:       "conversations",
+				MemoryType:  "conversation",
+				Description: "Conversation history",
+			},
+		},
+	},
+}
+
+// DefaultVaultTemplates returns the built-in templates keyed by name.
+func DefaultVaultTemplates() map[string]model.VaultTemplate {
+	out := make(map[string]model.VaultTemplate, len(builtinVaultTemplates))
+	for _, t := range builtinVaultTemplates {
+		out[t.Name] = t
+	}
+	return out
+}
+
+// LoadVaultTemplates returns the built-in templates overlaid with the JSON
+// array of templates in path; a file template replaces the built-in of the
+// same name. An empty path returns the built-ins.
+func LoadVaultTemplates(path string) (map[string]model.VaultTemplate, error) {
+	out := DefaultVaultTemplates()
+	if path == "" {
+		return out, nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read vault templates: %w", err)
+	}
+	var tpls []model.VaultTemplate
+	if err := json.Unmarshal(b, &tpls); err != nil {
+		return nil, fmt.Errorf("parse vault templates %s: %w", path, err)
+	}
+	for _, t := range tpls {
+		if t.Name == "" {
+			return nil, fmt.Errorf("vault templates %s: template without a name", path)
+		}
+		out[t.Name] = t
+	}
+	return out, nil
+}
+
+// CreateVaultFromTemplate creates v and the template's memories and starting
+// contexts. If any step fails the partly created vault is deleted again.
+func (s *VaultService) CreateVaultFromTemplate(ctx context.Context, v *model.Vault, tpl model.VaultTemplate) (*model.TemplatedVault, error) {
+	vault, err := s.store.Vaults().Create(ctx, v)
+	if err != nil {
+		return nil, err
+	}
+	out := &model.TemplatedVault{Vault: *vault, Template: tpl.Name, Memories: make([]*model.Memory, 0, len(tpl.Memories))}
+	if err := s.populateFromTemplate(ctx, out, tpl); err != nil {
+		if derr := s.DeleteVault(context.WithoutCancel(ctx), vault.ActorID, vault.VaultID); derr != nil {
+			err = errors.Join(err, fmt.Errorf("remove partly created vault %s: %w", vault.VaultID, derr))
+		}
+		return nil, err
+	}
+	return out, nil
+}
+
+func (s *VaultService) populateFromTemplate(ctx context.Context, out *model.TemplatedVault, tpl model.VaultTemplate) error {
+	for _, tm := range tpl.Memories {
+		m := &model.Memory{ActorID: out.ActorID, VaultID: out.VaultID, MemoryType: tm.MemoryType, Title: tm.Title}
+		if tm.Description != "" {
+			desc := tm.Description
+			m.Description = &desc
+		}
+		mem, err := s.store.Memories().Create(ctx, m)
+		if err != nil {
+			return fmt.Errorf("create memory %s: %w", tm.Title, err)
+		}
+		out.Memories = append(out.Memories, mem)
+		if tm.Context == "" {
+			continue
+		}
+		if _, err := s.store.Contexts().Put(ctx, &model.MemoryContext{ActorID: out.ActorID, VaultID: out.VaultID, MemoryID: mem.MemoryID, Context: tm.Context}); err != nil {
+			return fmt.Errorf("put context of memory %s: %w", tm.Title, err)
+		}
+	}
+	return nil
+}
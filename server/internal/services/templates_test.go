@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/store"
+)
+
+// templateStore records what CreateVaultFromTemplate writes; memory creation
+// fails for failTitle.
+type templateStore struct {
+	*fakeStore
+	failTitle string
+	created   []*model.Memory
+	contexts  []*model.MemoryContext
+}
+
+type templateVaults struct {
+	*fakeVaults
+}
+
+func (v templateVaults) Create(_ context.Context, in *model.Vault) (*model.Vault, error) {
+	out := *in
+	out.VaultID = "v1"
+	return &out, nil
+}
+
+type templateMemories struct {
+	*fakeMemories
+	s *templateStore
+}
+
+func (m templateMemories) Create(_ context.Context, in *model.Memory) (*model.Memory, error) {
+	if in.Title == m.s.failTitle {
+		return nil, errors.New("boom")
+	}
+	out := *in
+	out.MemoryID = "m-" + in.Title
+	m.s.created = append(m.s.created, &out)
+	return &out, nil
+}
+
+type templateContexts struct {
+	*fakeContexts
+	s *templateStore
+}
+
+func (c templateContexts) Put(_ context.Context, in *model.MemoryContext) (*model.MemoryContext, error) {
+	c.s.contexts = append(c.s.contexts, in)
+	return in, nil
+}
+
+func (s *templateStore) Vaults() store.Vaults {
+	return templateVaults{&fakeVaults{s.fakeStore}}
+}
+func (s *templateStore) Memories() store.Memories {
+	return templateMemories{&fakeMemories{s.fakeStore}, s}
+}
+func (s *templateStore) Contexts() store.Contexts {
+	return templateContexts{&fakeContexts{s.fakeStore}, s}
+}
+
+func TestCreateVaultFromTemplate(t *testing.T) {
+	ts := &templateStore{fakeStore: &fakeStore{}}
+	svc := NewVaultService(ts, &fakeIndex{})
+	tpl := DefaultVaultTemplates()["project"]
+
+	out, err := svc.CreateVaultFromTemplate(context.Background(), &model.Vault{ActorID: "u1", Title: "acme"}, tpl)
+	if err != nil {
+		t.Fatalf("CreateVaultFromTemplate: %v", err)
+	}
+	if out.VaultID != "v1" || out.Template != "project" || len(out.Memories) != 3 {
+		t.Fatalf("unexpected result: %+v", out)
+	}
+	if m := ts.created[1]; m.Title != "decisions" || m.VaultID != "v1" || m.ActorID != "u1" || m.Description == nil {
+		t.Fatalf("unexpected memory: %+v", m)
+	}
+	if len(ts.contexts) != 3 || ts.contexts[0].MemoryID != "m-project-context" {
+		t.Fatalf("unexpected contexts: %+v", ts.contexts)
+	}
+	if ts.vaultDeleted.called {
+		t.Fatalf("vault deleted after success")
+	}
+}
+
+func TestCreateVaultFromTemplateRemovesVaultOnFailure(t *testing.T) {
+	ts := &templateStore{fakeStore: &fakeStore{}, failTitle: "people"}
+	svc := NewVaultService(ts, &fakeIndex{})
+
+	_, err := svc.CreateVaultFromTemplate(context.Background(), &model.Vault{ActorID: "u1", Title: "acme"}, DefaultVaultTemplates()["project"])
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+	if !ts.vaultDeleted.called || ts.vaultDeleted.vaultID != "v1" {
+		t.Fatalf("partly created vault not removed: %+v", ts.vaultDeleted)
+	}
+}
+
+func TestLoadVaultTemplates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "templates.json")
+	body := `[{"name": "project", "memories": [{"title": "notes", "memoryType": "notes"}]},
+	          {"name": "support", "memories": [{"title": "tickets", "memoryType": "tickets"}]}]`
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tpls, err := LoadVaultTemplates(path)
+	if err != nil {
+		t.Fatalf("LoadVaultTemplates: %v", err)
+	}
+	if len(tpls["project"].Memories) != 1 || tpls["support"].Name != "support" || tpls["personal-assistant"].Name == "" {
+		t.Fatalf("unexpected templates: %+v", tpls)
+	}
+
+	if err := os.WriteFile(path, []byte(`[{"memories": []}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadVaultTemplates(path); err == nil {
+		t.Fatalf("expected error for unnamed template")
+	}
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+// Named search windows. Calendar windows follow the actor's time zone and
+// weeks start on Monday; lastNh, lastNd and lastNw (e.g. last7d) reach back
+// N hours, days or weeks from now.
+const (
+	WindowToday             = "today"
+	WindowYesterday         = "yesterday"
+	WindowThisWeek          = "thisWeek"
+	WindowLastWeek          = "lastWeek"
+	WindowThisMonth         = "thisMonth"
+	WindowLastMonth         = "lastMonth"
+	WindowThisYear          = "thisYear"
+	WindowLastYear          = "lastYear"
+	WindowSinceSessionStart = "sinceSessionStart"
+)
+
+// maxWindowUnits bounds N in lastN{h,d,w}.
+const maxWindowUnits = 10000
+
+var lastNWindow = regexp.MustCompile(`^last(\d+)([hdw])$`)
+
+// TimeWindow is a resolved [Since, Until) range; a nil bound is open.
+type TimeWindow struct {
+	Since *time.Time `json:"since,omitempty"`
+	Until *time.Time `json:"until,omitempty"`
+}
+
+// ResolveTimeWindow turns a named window into absolute bounds in loc at
+// now. WindowSinceSessionStart needs the store; see ResolveSearchWindow.
+func ResolveTimeWindow(window string, loc *time.Location, now time.Time) (TimeWindow, error) {
+	now = now.In(loc)
+	day := startOfDay(now)
+	span := func(since, until time.Time) (TimeWindow, error) {
+		return TimeWindow{Since: &since, Until: &until}, nil
+	}
+	switch w := strings.ToLower(strings.TrimSpace(window)); w {
+	case strings.ToLower(WindowToday):
+		return TimeWindow{Since: &day}, nil
+	case strings.ToLower(WindowYesterday):
+		return span(day.AddDate(0, 0, -1), day)
+	case strings.ToLower(WindowThisWeek):
+		week := startOfWeek(day)
+		return TimeWindow{Since: &week}, nil
+	case strings.ToLower(WindowLastWeek):
+		week := startOfWeek(day)
+		return span(week.AddDate(0, 0, -7), week)
+	case strings.ToLower(WindowThisMonth):
+		month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc)
+		return TimeWindow{Since: &month}, nil
+	case strings.ToLower(WindowLastMonth):
+		month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc)
+		return span(month.AddDate(0, -1, 0), month)
+	case strings.ToLower(WindowThisYear):
+		year := time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, loc)
+		return TimeWindow{Since: &year}, nil
+	case strings.ToLower(WindowLastYear):
+		year := time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, loc)
+		return span(year.AddDate(-1, 0, 0), year)
+	default:
+		m := lastNWindow.FindStringSubmatch(w)
+		if m == nil {
+			return TimeWindow{}, fmt.Errorf("%w: unknown window %q", model.ErrValidation, window)
+		}
+		n, err := strconv.Atoi(m[1])
+		if err != nil || n < 1 || n > maxWindowUnits {
+			return TimeWindow{}, fmt.Errorf("%w: window %q must count 1 to %d units", model.ErrValidation, window, maxWindowUnits)
+		}
+		var since time.Time
+		switch m[2] {
+		case "h":
+			since = now.Add(-time.Duration(n) * time.Hour)
+		case "d":
+			since = now.AddDate(0, 0, -n)
+		case "w":
+			since = now.AddDate(0, 0, -7*n)
+		}
+		return TimeWindow{Since: &since}, nil
+	}
+}
+
+// ResolveSearchWindow resolves window like ResolveTimeWindow and also
+// WindowSinceSessionStart, which starts at the first entry of sessionID.
+func (s *MemoryService) ResolveSearchWindow(ctx context.Context, actorID, memoryID, sessionID, window string, loc *time.Location, now time.Time) (TimeWindow, error) {
+	if !strings.EqualFold(strings.TrimSpace(window), WindowSinceSessionStart) {
+		return ResolveTimeWindow(window, loc, now)
+	}
+	if sessionID == "" {
+		return TimeWindow{}, fmt.Errorf("%w: window %s requires sessionId", model.ErrValidation, WindowSinceSessionStart)
+	}
+	start, err := s.store.Entries().SessionStart(ctx, actorID, memoryID, sessionID)
+	if err != nil {
+		return TimeWindow{}, err
+	}
+	start = start.In(loc)
+	return TimeWindow{Since: &start}, nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// startOfWeek returns the Monday of day's week.
+func startOfWeek(day time.Time) time.Time {
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+func TestResolveTimeWindow(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	// Wednesday 2026-03-04 01:30 in Berlin, still March 3 in UTC.
+	now := time.Date(2026, 3, 4, 0, 30, 0, 0, time.UTC)
+	at := func(s string) string {
+		if s == "" {
+			return ""
+		}
+		tm, err := time.ParseInLocation("2006-01-02 15:04", s, loc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tm.Format(time.RFC3339)
+	}
+	cases := []struct{ window, since, until string }{
+		{"today", "2026-03-04 00:00", ""},
+		{"yesterday", "2026-03-03 00:00", "2026-03-04 00:00"},
+		{"thisWeek", "2026-03-02 00:00", ""},
+		{"lastWeek", "2026-02-23 00:00", "2026-03-02 00:00"},
+		{"thisMonth", "2026-03-01 00:00", ""},
+		{"LASTMONTH", "2026-02-01 00:00", "2026-03-01 00:00"},
+		{"lastYear", "2025-01-01 00:00", "2026-01-01 00:00"},
+		{"last7d", "2026-02-25 01:30", ""},
+		{"last2w", "2026-02-18 01:30", ""},
+		{"last3h", "2026-03-03 22:30", ""},
+	}
+	for _, c := range cases {
+		tw, err := ResolveTimeWindow(c.window, loc, now)
+		if err != nil {
+			t.Fatalf("%s: %v", c.window, err)
+		}
+		var since, until string
+		if tw.Since != nil {
+			since = tw.Since.Format(time.RFC3339)
+		}
+		if tw.Until != nil {
+			until = tw.Until.Format(time.RFC3339)
+		}
+		if since != at(c.since) || until != at(c.until) {
+			t.Fatalf("%s = [%s, %s), want [%s, %s)", c.window, since, until, at(c.since), at(c.until))
+		}
+	}
+	for _, bad := range []string{"", "fortnight", "last0d", "last7m", "sinceSessionStart"} {
+		if _, err := ResolveTimeWindow(bad, loc, now); !errors.Is(err, model.ErrValidation) {
+			t.Fatalf("%q: want ErrValidation, got %v", bad, err)
+		}
+	}
+}
+
+func TestResolveSearchWindowSinceSessionStart(t *testing.T) {
+	fs := &fakeStore{}
+	svc := NewMemoryService(fs, &fakeIndex{}, nil)
+	ctx := context.Background()
+	start := time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC)
+	fs.entriesByMem = map[string][]*model.MemoryEntry{"m1": {{MemoryID: "m1", SessionID: "s1", CreationTime: start}}}
+
+	tw, err := svc.ResolveSearchWindow(ctx, "u1", "m1", "s1", "sinceSessionStart", time.UTC, time.Now())
+	if err != nil || tw.Since == nil || !tw.Since.Equal(start) || tw.Until != nil {
+		t.Fatalf("ResolveSearchWindow = %+v, %v", tw, err)
+	}
+	if _, err := svc.ResolveSearchWindow(ctx, "u1", "m1", "", "sinceSessionStart", time.UTC, time.Now()); !errors.Is(err, model.ErrValidation) {
+		t.Fatalf("without sessionId: want ErrValidation, got %v", err)
+	}
+	if _, err := svc.ResolveSearchWindow(ctx, "u1", "m1", "s2", "sinceSessionStart", time.UTC, time.Now()); !errors.Is(err, model.ErrNotFound) {
+		t.Fatalf("unknown session: want ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/store"
+)
+
+// maxTrashEntries bounds how many trashed entries one trash listing returns.
+const maxTrashEntries = 500
+
+// EnableTrash makes DeleteMemory and DeleteEntry move to the trash instead of
+// deleting: the rows stay, hidden from reads, and their index objects stay
+// until a TrashPurger removes them, so RestoreMemory and RestoreEntry can
+// bring them back.
+func (s *MemoryService) EnableTrash() { s.trash = true }
+
+// TrashEnabled reports whether deletes go to the trash.
+func (s *MemoryService) TrashEnabled() bool { return s.trash }
+
+// RestoreMemory takes a trashed memory, with the entries that were not
+// trashed on their own, out of the trash.
+func (s *MemoryService) RestoreMemory(ctx context.Context, userID, vaultID, memoryID string) (*model.Memory, error) {
+	if err := ensureVaultWritable(ctx, s.store, userID, vaultID); err != nil {
+		return nil, err
+	}
+	return s.store.Memories().Restore(ctx, userID, vaultID, memoryID)
+}
+
+// RestoreEntry takes a trashed entry out of the trash. An entry of a trashed
+// memory comes back with its memory instead: model.ErrNotFound.
+func (s *MemoryService) RestoreEntry(ctx context.Context, userID, vaultID, memoryID, entryID string) (*model.MemoryEntry, error) {
+	if err := ensureVaultWritable(ctx, s.store, userID, vaultID); err != nil {
+		return nil, err
+	}
+	if _, err := s.store.Memories().GetByID(ctx, userID, vaultID, memoryID); err != nil {
+		return nil, err
+	}
+	return s.store.Entries().Restore(ctx, userID, vaultID, memoryID, entryID)
+}
+
+// ListTrash lists the vault's trashed memories and the trashed entries of
+// its other memories, most recently deleted first.
+func (s *MemoryService) ListTrash(ctx context.Context, userID, vaultID string) (*model.Trash, error) {
+	mems, err := s.store.Memories().ListTrash(ctx, userID, vaultID)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.store.Entries().ListTrash(ctx, userID, vaultID, maxTrashEntries)
+	if err != nil {
+		return nil, err
+	}
+	out := &model.Trash{Memories: mems, Entries: entries}
+	if out.Memories == nil {
+		out.Memories = []*model.Memory{}
+	}
+	if out.Entries == nil {
+		out.Entries = []*model.MemoryEntry{}
+	}
+	return out, nil
+}
+
+// DropTrashed removes the hits of trashed entries, which stay in the search
+// index until they are purged.
+func (s *MemoryService) DropTrashed(ctx context.Context, userID string, hits []model.SearchHit) ([]model.SearchHit, error) {
+	if len(hits) == 0 {
+		return hits, nil
+	}
+	ids := make([]string, len(hits))
+	for i, h := range hits {
+		ids[i] = h.EntryID
+	}
+	trashed, err := s.store.Entries().Trashed(ctx, userID, ids)
+	if err != nil || len(trashed) == 0 {
+		return hits, err
+	}
+	out := hits[:0]
+	for _, h := range hits {
+		if !trashed[h.EntryID] {
+			out = append(out, h)
+		}
+	}
+	return out, nil
+}
+
+// TrashPurger periodically deletes memories and entries that have been in
+// the trash longer than the retention period.
+type TrashPurger struct {
+	store     store.Store
+	retention time.Duration
+	log       zerolog.Logger
+}
+
+func NewTrashPurger(s store.Store, retention time.Duration, log zerolog.Logger) *TrashPurger {
+	return &TrashPurger{store: s, retention: retention, log: log}
+}
+
+// Start runs a purge pass immediately and then every interval until ctx is done.
+func (p *TrashPurger) Start(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		start := time.Now()
+		n, err := p.RunOnce(ctx, start)
+		if err != nil && ctx.Err() == nil {
+			p.log.Warn().Err(err).Int("purged", n).Msg("trash purge pass failed")
+		} else if n > 0 {
+			p.log.Info().Int("purged", n).Dur("elapsed", time.Since(start)).Msg("trash purge pass completed")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// RunOnce deletes, in batches, every memory and entry trashed before
+// now minus the retention period and returns how many it removed.
+func (p *TrashPurger) RunOnce(ctx context.Context, now time.Time) (int, error) {
+	if p.retention <= 0 {
+		return 0, nil
+	}
+	cutoff := now.Add(-p.retention)
+	purged := 0
+	for {
+		n, err := p.store.Memories().PurgeTrash(ctx, cutoff, retentionBatchSize)
+		purged += n
+		if err != nil {
+			return purged, err
+		}
+		if n < retentionBatchSize {
+			break
+		}
+	}
+	for {
+		ids, err := p.store.Entries().PurgeTrash(ctx, cutoff, retentionBatchSize)
+		purged += len(ids)
+		if err != nil || len(ids) < retentionBatchSize {
+			return purged, err
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/store"
+)
+
+type trashEntries struct {
+	store.Entries
+	trashed   map[string]bool
+	remaining int
+	cutoff    time.Time
+}
+
+func (e *trashEntries) Trash(_ context.Context, _, _, _, entryID string) error {
+	e.trashed[entryID] = true
+	return nil
+}
+
+func (e *trashEntries) Trashed(_ context.Context, _ string, ids []string) (map[string]bool, error) {
+	out := map[string]bool{}
+	for _, id := range ids {
+		if e.trashed[id] {
+			out[id] = true
+		}
+	}
+	return out, nil
+}
+
+func (e *trashEntries) PurgeTrash(_ context.Context, cutoff time.Time, limit int) ([]string, error) {
+	e.cutoff = cutoff
+	n := min(limit, e.remaining)
+	e.remaining -= n
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("e%d", i)
+	}
+	return ids, nil
+}
+
+type trashMemories struct {
+	store.Memories
+	remaining int
+}
+
+func (m *trashMemories) GetByID(_ context.Context, userID, vaultID, memoryID string) (*model.Memory, error) {
+	return &model.Memory{ActorID: userID, VaultID: vaultID, MemoryID: memoryID}, nil
+}
+
+func (m *trashMemories) PurgeTrash(_ context.Context, _ time.Time, limit int) (int, error) {
+	n := min(limit, m.remaining)
+	m.remaining -= n
+	return n, nil
+}
+
+type trashStore struct {
+	*fakeStore
+	e *trashEntries
+	m *trashMemories
+}
+
+func (s trashStore) Entries() store.Entries   { return s.e }
+func (s trashStore) Memories() store.Memories { return s.m }
+
+func TestDeleteEntry_Trash(t *testing.T) {
+	es := &trashEntries{trashed: map[string]bool{}}
+	idx := &fakeIndex{}
+	svc := NewMemoryService(trashStore{&fakeStore{}, es, &trashMemories{}}, idx, nil)
+	svc.EnableTrash()
+	if err := svc.DeleteEntry(context.Background(), "a", "v", "m", "e1"); err != nil {
+		t.Fatalf("DeleteEntry: %v", err)
+	}
+	if !es.trashed["e1"] || len(idx.deletedEntries) != 0 {
+		t.Fatalf("entry must be trashed and kept in the index: trashed=%v index deletes=%v", es.trashed, idx.deletedEntries)
+	}
+
+	hits, err := svc.DropTrashed(context.Background(), "a", []model.SearchHit{{EntryID: "e0"}, {EntryID: "e1"}, {EntryID: "e2"}})
+	if err != nil || len(hits) != 2 || hits[0].EntryID != "e0" || hits[1].EntryID != "e2" {
+		t.Fatalf("DropTrashed: %+v err=%v", hits, err)
+	}
+}
+
+func TestTrashPurger_RunOnce(t *testing.T) {
+	now := time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+	es := &trashEntries{remaining: retentionBatchSize + 3}
+	ms := &trashMemories{remaining: 2}
+	p := NewTrashPurger(trashStore{&fakeStore{}, es, ms}, 7*24*time.Hour, zerolog.Nop())
+	n, err := p.RunOnce(context.Background(), now)
+	if err != nil || n != retentionBatchSize+5 {
+		t.Fatalf("RunOnce: n=%d err=%v", n, err)
+	}
+	if !es.cutoff.Equal(now.AddDate(0, 0, -7)) {
+		t.Fatalf("unexpected cutoff %v", es.cutoff)
+	}
+
+	disabled := NewTrashPurger(trashStore{&fakeStore{}, es, ms}, 0, zerolog.Nop())
+	if n, err := disabled.RunOnce(context.Background(), now); n != 0 || err != nil {
+		t.Fatalf("disabled purger deleted %d (err=%v)", n, err)
+	}
+}
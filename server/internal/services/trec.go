@@ -0,0 +1,73 @@
+package services
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+// Search log export formats. Query IDs serve as TREC topic IDs and entry
+// IDs as document IDs, so the three files of one export join up.
+const (
+	// TRECRun is a run file: "qid Q0 entryId rank score runTag" per result.
+	TRECRun = "run"
+	// TRECQrels are judgments from search feedback: "qid 0 entryId rel"
+	// with rel 1 for useful and 0 for returned but not useful results.
+	// Queries without feedback are left out.
+	TRECQrels = "qrels"
+	// TRECTopics lists "qid<TAB>query" per query, as read by most IR toolkits.
+	TRECTopics = "topics"
+)
+
+// ValidTRECFormat reports whether format is one of the export formats.
+func ValidTRECFormat(format string) bool {
+	return format == TRECRun || format == TRECQrels || format == TRECTopics
+}
+
+// WriteTREC writes queries in the given export format. runTag names the run
+// in TRECRun output and must not contain whitespace.
+func WriteTREC(w io.Writer, format string, queries []*model.SearchQuery, runTag string) error {
+	bw := bufio.NewWriter(w)
+	for _, q := range queries {
+		switch format {
+		case TRECRun:
+			for i, id := range q.EntryIDs {
+				fmt.Fprintf(bw, "%s Q0 %s %d %s %s\n", q.QueryID, id, i+1, runScore(q, i), runTag)
+			}
+		case TRECQrels:
+			if q.FeedbackTime == nil {
+				continue
+			}
+			useful := make(map[string]bool, len(q.UsefulEntryIDs))
+			for _, id := range q.UsefulEntryIDs {
+				useful[id] = true
+			}
+			for _, id := range q.EntryIDs {
+				rel := 0
+				if useful[id] {
+					rel = 1
+				}
+				fmt.Fprintf(bw, "%s 0 %s %d\n", q.QueryID, id, rel)
+			}
+		case TRECTopics:
+			fmt.Fprintf(bw, "%s\t%s\n", q.QueryID, strings.Join(strings.Fields(q.Query), " "))
+		default:
+			return fmt.Errorf("%w: unknown export format %q", model.ErrValidation, format)
+		}
+	}
+	return bw.Flush()
+}
+
+// runScore is the logged score of result i. Queries logged before scores
+// were recorded get descending rank-based scores, since evaluation tools
+// order a run by score.
+func runScore(q *model.SearchQuery, i int) string {
+	if len(q.Scores) == len(q.EntryIDs) {
+		return strconv.FormatFloat(q.Scores[i], 'f', -1, 64)
+	}
+	return strconv.Itoa(len(q.EntryIDs) - i)
+}
@@ -0,0 +1,33 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+func TestWriteTREC(t *testing.T) {
+	judged := time.Now()
+	queries := []*model.SearchQuery{
+		{QueryID: "q1", Query: "where\tdid  we\nmeet", EntryIDs: []string{"e1", "e2"}, Scores: []float64{0.75, 0.5},
+			UsefulEntryIDs: []string{"e2"}, FeedbackTime: &judged},
+		{QueryID: "q2", Query: "legacy", EntryIDs: []string{"e3", "e4"}},
+	}
+	cases := map[string]string{
+		TRECRun:    "q1 Q0 e1 1 0.75 r1\nq1 Q0 e2 2 0.5 r1\nq2 Q0 e3 1 2 r1\nq2 Q0 e4 2 1 r1\n",
+		TRECQrels:  "q1 0 e1 0\nq1 0 e2 1\n",
+		TRECTopics: "q1\twhere did we meet\nq2\tlegacy\n",
+	}
+	for format, want := range cases {
+		var buf bytes.Buffer
+		if err := WriteTREC(&buf, format, queries, "r1"); err != nil || buf.String() != want {
+			t.Fatalf("%s: got %q err=%v, want %q", format, buf.String(), err, want)
+		}
+	}
+	if err := WriteTREC(&bytes.Buffer{}, "csv", queries, "r1"); !errors.Is(err, model.ErrValidation) {
+		t.Fatalf("unknown format: expected validation error, got %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+// Clients report the language model usage behind an entry (its summary and
+// the context update made with it) so teams can attribute spend to memory
+// maintenance. UsageReport adds it up per memory and per model.
+
+// maxUsageModelLen bounds EntryUsage.Model.
+const maxUsageModelLen = 128
+
+// normalizeEntryUsage validates e.Usage and drops it when it reports nothing.
+func normalizeEntryUsage(e *model.MemoryEntry) error {
+	u := e.Usage
+	if u == nil {
+		return nil
+	}
+	u.Model = strings.TrimSpace(u.Model)
+	switch {
+	case len(u.Model) > maxUsageModelLen:
+		return fmt.Errorf("%w: usage.model exceeds %d characters", model.ErrValidation, maxUsageModelLen)
+	case u.InputTokens < 0 || u.OutputTokens < 0:
+		return fmt.Errorf("%w: usage token counts must be >= 0", model.ErrValidation)
+	case u.CostUSD < 0 || math.IsNaN(u.CostUSD) || math.IsInf(u.CostUSD, 0):
+		return fmt.Errorf("%w: usage.costUsd must be a non-negative number", model.ErrValidation)
+	}
+	if *u == (model.EntryUsage{}) {
+		e.Usage = nil
+	}
+	return nil
+}
+
+// UsageReport sums the usage reported with the actor's entries, optionally
+// only for one memory and for entries created since a time.
+func (s *MemoryService) UsageReport(ctx context.Context, actorID, memoryID string, since *time.Time) (*model.UsageReport, error) {
+	groups, err := s.store.Entries().Usage(ctx, actorID, memoryID, since)
+	if err != nil {
+		return nil, err
+	}
+	out := &model.UsageReport{ByMemory: []model.UsageTotals{}, ByModel: []model.UsageTotals{}}
+	byMemory := map[string]*model.UsageTotals{}
+	byModel := map[string]*model.UsageTotals{}
+	for _, g := range groups {
+		addUsage(&out.Total, g)
+		if byMemory[g.MemoryID] == nil {
+			byMemory[g.MemoryID] = &model.UsageTotals{MemoryID: g.MemoryID}
+		}
+		addUsage(byMemory[g.MemoryID], g)
+		if byModel[g.Model] == nil {
+			byModel[g.Model] = &model.UsageTotals{Model: g.Model}
+		}
+		addUsage(byModel[g.Model], g)
+	}
+	for _, t := range byMemory {
+		out.ByMemory = append(out.ByMemory, *t)
+	}
+	for _, t := range byModel {
+		out.ByModel = append(out.ByModel, *t)
+	}
+	sortByCost(out.ByMemory)
+	sortByCost(out.ByModel)
+	return out, nil
+}
+
+func addUsage(dst *model.UsageTotals, g model.UsageTotals) {
+	dst.Entries += g.Entries
+	dst.InputTokens += g.InputTokens
+	dst.OutputTokens += g.OutputTokens
+	dst.CostUSD += g.CostUSD
+}
+
+// sortByCost orders totals by cost, then tokens, highest first.
+func sortByCost(ts []model.UsageTotals) {
+	sort.SliceStable(ts, func(i, j int) bool {
+		if ts[i].CostUSD != ts[j].CostUSD {
+			return ts[i].CostUSD > ts[j].CostUSD
+		}
+		if a, b := ts[i].InputTokens+ts[i].OutputTokens, ts[j].InputTokens+ts[j].OutputTokens; a != b {
+			return a > b
+		}
+		return ts[i].MemoryID+ts[i].Model < ts[j].MemoryID+ts[j].Model
+	})
+}
@@ -0,0 +1,46 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+func TestUsageReport(t *testing.T) {
+	fs := &fakeStore{entriesByMem: map[string][]*model.MemoryEntry{
+		"m1": {
+			{Usage: &model.EntryUsage{Model: "small", InputTokens: 100, OutputTokens: 10, CostUSD: 0.01}},
+			{Usage: &model.EntryUsage{Model: "large", InputTokens: 50, OutputTokens: 50, CostUSD: 0.2}},
+			{RawEntry: "no usage"},
+		},
+		"m2": {{Usage: &model.EntryUsage{Model: "small", InputTokens: 10, OutputTokens: 1, CostUSD: 0.001}}},
+	}}
+	svc := NewMemoryService(fs, nil, nil)
+
+	r, err := svc.UsageReport(context.Background(), "a", "", nil)
+	if err != nil {
+		t.Fatalf("UsageReport: %v", err)
+	}
+	if r.Total.Entries != 3 || r.Total.InputTokens != 160 || r.Total.OutputTokens != 61 {
+		t.Fatalf("unexpected total: %+v", r.Total)
+	}
+	if len(r.ByMemory) != 2 || r.ByMemory[0].MemoryID != "m1" || r.ByMemory[0].Entries != 2 {
+		t.Fatalf("unexpected byMemory: %+v", r.ByMemory)
+	}
+	if len(r.ByModel) != 2 || r.ByModel[0].Model != "large" || r.ByModel[1].Entries != 2 || r.ByModel[1].InputTokens != 110 {
+		t.Fatalf("unexpected byModel: %+v", r.ByModel)
+	}
+
+	for _, u := range []model.EntryUsage{{InputTokens: -1}, {CostUSD: -0.5}} {
+		e := &model.MemoryEntry{Usage: &u}
+		if err := normalizeEntryUsage(e); !errors.Is(err, model.ErrValidation) {
+			t.Fatalf("usage %+v: expected validation error, got %v", u, err)
+		}
+	}
+	e := &model.MemoryEntry{Usage: &model.EntryUsage{Model: "  "}}
+	if err := normalizeEntryUsage(e); err != nil || e.Usage != nil {
+		t.Fatalf("empty usage should be dropped: usage=%+v err=%v", e.Usage, err)
+	}
+}
@@ -2,6 +2,8 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/mycelian/mycelian-memory/server/internal/model"
 	"github.com/mycelian/mycelian-memory/server/internal/searchindex"
@@ -26,10 +28,83 @@ func (s *VaultService) GetVault(ctx context.Context, userID, vaultID string) (*m
 func (s *VaultService) GetVaultByTitle(ctx context.Context, userID, title string) (*model.Vault, error) {
 	return s.store.Vaults().GetByTitle(ctx, userID, title)
 }
+
+// ResolveVault finds a vault by ID, or else by title or slug, for routes
+// whose {vaultId} may name either.
+func (s *VaultService) ResolveVault(ctx context.Context, userID, ref string) (*model.Vault, error) {
+	v, err := s.store.Vaults().GetByID(ctx, userID, ref)
+	if errors.Is(err, model.ErrNotFound) {
+		return s.store.Vaults().GetByTitle(ctx, userID, ref)
+	}
+	return v, err
+}
 func (s *VaultService) ListVaults(ctx context.Context, userID string) ([]*model.Vault, error) {
 	return s.store.Vaults().List(ctx, userID)
 }
+// UpdateVault renames the vault and/or changes its description.
+func (s *VaultService) UpdateVault(ctx context.Context, userID, vaultID string, u model.TitleUpdate) (*model.Vault, error) {
+	if err := ensureVaultWritable(ctx, s.store, userID, vaultID); err != nil {
+		return nil, err
+	}
+	return s.store.Vaults().Update(ctx, userID, vaultID, u)
+}
+func (s *VaultService) SetVaultReadOnly(ctx context.Context, userID, vaultID string, readOnly bool) (*model.Vault, error) {
+	return s.store.Vaults().SetReadOnly(ctx, userID, vaultID, readOnly)
+}
+
+// CloneVault copies a vault's memories, entries and latest contexts into a
+// new vault titled c.Title. With c.Reindex the copies are enqueued for
+// indexing as one reindex job per memory.
+func (s *VaultService) CloneVault(ctx context.Context, c model.VaultClone) (*model.ClonedVault, error) {
+	return s.store.Vaults().Clone(ctx, c)
+}
+
+// VaultStats compares each memory's Postgres row counts with the objects the
+// search index holds for it, to spot indexing gaps. Index counts are omitted
+// when the index cannot report them.
+func (s *VaultService) VaultStats(ctx context.Context, userID, vaultID string) (*model.VaultStats, error) {
+	if _, err := s.store.Vaults().GetByID(ctx, userID, vaultID); err != nil {
+		return nil, err
+	}
+	mems, err := s.store.Vaults().MemoryStats(ctx, userID, vaultID)
+	if err != nil {
+		return nil, err
+	}
+	out := &model.VaultStats{VaultID: vaultID, Memories: mems, InSync: true}
+	if out.Memories == nil {
+		out.Memories = []model.MemoryStats{}
+	}
+	counter, _ := s.idx.(searchindex.ObjectCounter)
+	if counter != nil {
+		out.Index = &model.ObjectCounts{}
+	}
+	for i := range out.Memories {
+		m := &out.Memories[i]
+		out.Postgres.Entries += m.Postgres.Entries
+		out.Postgres.Contexts += m.Postgres.Contexts
+		out.Indexing.Pending += m.Indexing.Pending
+		out.Indexing.Retrying += m.Indexing.Retrying
+		out.Indexing.Failed += m.Indexing.Failed
+		if counter == nil {
+			continue
+		}
+		n, err := counter.CountObjects(ctx, userID, m.MemoryID)
+		if err != nil {
+			return nil, fmt.Errorf("count index objects of memory %s: %w", m.MemoryID, err)
+		}
+		m.Index = &n
+		out.Index.Entries += n.Entries
+		out.Index.Contexts += n.Contexts
+		out.InSync = out.InSync && m.InSync()
+	}
+	out.InSync = out.InSync && counter != nil
+	return out, nil
+}
+
 func (s *VaultService) DeleteVault(ctx context.Context, userID, vaultID string) error {
+	if err := ensureVaultWritable(ctx, s.store, userID, vaultID); err != nil {
+		return err
+	}
 	// Enumerate affected objects first so we can update the index even if
 	// storage delete succeeds and data becomes unavailable for listing.
 	memories, err := s.store.Memories().List(ctx, userID, vaultID)
@@ -84,5 +159,41 @@ func (s *VaultService) DeleteVault(ctx context.Context, userID, vaultID string)
 	return s.store.Vaults().Delete(ctx, userID, vaultID)
 }
 func (s *VaultService) AddMemoryToVault(ctx context.Context, userID, vaultID, memoryID string) error {
+	if err := ensureVaultWritable(ctx, s.store, userID, vaultID); err != nil {
+		return err
+	}
 	return s.store.Vaults().AddMemory(ctx, userID, vaultID, memoryID)
 }
+
+// ensureVaultWritable returns model.ErrReadOnly when the vault is marked
+// read-only. Unknown vaults pass through so callers keep their own
+// not-found handling.
+func ensureVaultWritable(ctx context.Context, st store.Store, userID, vaultID string) error {
+	v, err := st.Vaults().GetByID(ctx, userID, vaultID)
+	if errors.Is(err, model.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if v.ReadOnly {
+		return fmt.Errorf("%w: %s", model.ErrReadOnly, vaultID)
+	}
+	return nil
+}
+
+// ensureEntriesMutable returns model.ErrAppendOnly when the memory is marked
+// append-only. A missing memory is left for the store call to report.
+func ensureEntriesMutable(ctx context.Context, st store.Store, userID, vaultID, memoryID string) error {
+	m, err := st.Memories().GetByID(ctx, userID, vaultID, memoryID)
+	if errors.Is(err, model.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if m.AppendOnly {
+		return fmt.Errorf("%w: %s", model.ErrAppendOnly, memoryID)
+	}
+	return nil
+}
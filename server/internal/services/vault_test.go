@@ -2,8 +2,11 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"reflect"
 	"sort"
+	"strings"
 	"testing"
 	"time"
 
@@ -19,7 +22,7 @@ type fakeIndex struct {
 	deleteVaultArgs []struct{ userID, vaultID string }
 }
 
-func (f *fakeIndex) Search(ctx context.Context, userID, memoryID, query string, vec []float32, topK int, alpha float32) ([]model.SearchHit, error) {
+func (f *fakeIndex) Search(ctx context.Context, userID, memoryID, query string, vec []float32, topK int, alpha float32, _ model.SearchFilter) ([]model.SearchHit, error) {
 	return nil, nil
 }
 func (f *fakeIndex) LatestContext(ctx context.Context, userID, memoryID string) (string, time.Time, error) {
@@ -56,13 +59,69 @@ type fakeStore struct {
 		userID, vaultID string
 		called          bool
 	}
+	searchLog  store.SearchLog
+	batches    store.IngestionBatches
+	readOnly   map[string]bool               // vaultID -> read-only flag
+	appendOnly map[string]bool               // memoryID -> append-only flag
+	entryRoles map[string][]string           // memoryID -> required entry roles
+	boosts     map[string]*model.SearchBoost // memoryID -> search boost
+	entryTTLs  map[string]int                // memoryID -> entry TTL seconds
+	stats      []model.MemoryStats
+	actors     store.ActorSettings
+	reindex    store.Reindex
+	reembed    store.Reembed
+	indexing   store.Indexing
+	docs       store.ContextDocuments
+	aliases    []*model.EntityAlias
+	webhooks   store.Webhooks
+	jobs       store.Jobs
 }
 
-func (f *fakeStore) Users() store.Users       { return fakeUsers{} }
-func (f *fakeStore) Vaults() store.Vaults     { return &fakeVaults{f} }
-func (f *fakeStore) Memories() store.Memories { return &fakeMemories{f} }
-func (f *fakeStore) Entries() store.Entries   { return &fakeEntries{f} }
-func (f *fakeStore) Contexts() store.Contexts { return &fakeContexts{f} }
+func (f *fakeStore) Users() store.Users         { return fakeUsers{} }
+func (f *fakeStore) Vaults() store.Vaults       { return &fakeVaults{f} }
+func (f *fakeStore) Memories() store.Memories   { return &fakeMemories{f} }
+func (f *fakeStore) Entries() store.Entries     { return &fakeEntries{f} }
+func (f *fakeStore) Contexts() store.Contexts   { return &fakeContexts{f} }
+func (f *fakeStore) SearchLog() store.SearchLog { return f.searchLog }
+func (f *fakeStore) IngestionBatches() store.IngestionBatches {
+	return f.batches
+}
+func (f *fakeStore) ActorSettings() store.ActorSettings { return f.actors }
+func (f *fakeStore) Reindex() store.Reindex             { return f.reindex }
+func (f *fakeStore) Reembed() store.Reembed             { return f.reembed }
+func (f *fakeStore) Indexing() store.Indexing           { return f.indexing }
+func (f *fakeStore) ContextDocuments() store.ContextDocuments {
+	return f.docs
+}
+func (f *fakeStore) EntityAliases() store.EntityAliases { return &fakeAliases{f} }
+func (f *fakeStore) Webhooks() store.Webhooks           { return f.webhooks }
+func (f *fakeStore) Jobs() store.Jobs                   { return f.jobs }
+
+type fakeAliases struct{ p *fakeStore }
+
+func (a *fakeAliases) Put(_ context.Context, in *model.EntityAlias) (*model.EntityAlias, error) {
+	out := *in
+	for i, e := range a.p.aliases {
+		if strings.EqualFold(e.Alias, in.Alias) {
+			a.p.aliases[i] = &out
+			return &out, nil
+		}
+	}
+	a.p.aliases = append(a.p.aliases, &out)
+	return &out, nil
+}
+func (a *fakeAliases) List(context.Context, string, string) ([]*model.EntityAlias, error) {
+	return a.p.aliases, nil
+}
+func (a *fakeAliases) Delete(_ context.Context, _, _, _, alias string) error {
+	for i, e := range a.p.aliases {
+		if strings.EqualFold(e.Alias, alias) {
+			a.p.aliases = append(a.p.aliases[:i], a.p.aliases[i+1:]...)
+			return nil
+		}
+	}
+	return model.ErrNotFound
+}
 
 type fakeUsers struct{}
 
@@ -72,8 +131,10 @@ func (fakeUsers) Delete(context.Context, string) error                     { pan
 
 type fakeVaults struct{ p *fakeStore }
 
-func (v *fakeVaults) Create(context.Context, *model.Vault) (*model.Vault, error)    { panic("unused") }
-func (v *fakeVaults) GetByID(context.Context, string, string) (*model.Vault, error) { panic("unused") }
+func (v *fakeVaults) Create(context.Context, *model.Vault) (*model.Vault, error) { panic("unused") }
+func (v *fakeVaults) GetByID(_ context.Context, userID, vaultID string) (*model.Vault, error) {
+	return &model.Vault{ActorID: userID, VaultID: vaultID, ReadOnly: v.p.readOnly[vaultID]}, nil
+}
 func (v *fakeVaults) GetByTitle(context.Context, string, string) (*model.Vault, error) {
 	panic("unused")
 }
@@ -85,12 +146,32 @@ func (v *fakeVaults) Delete(_ context.Context, userID, vaultID string) error {
 	return nil
 }
 func (v *fakeVaults) AddMemory(context.Context, string, string, string) error { panic("unused") }
+func (v *fakeVaults) Update(context.Context, string, string, model.TitleUpdate) (*model.Vault, error) {
+	panic("unused")
+}
+func (v *fakeVaults) SetReadOnly(_ context.Context, userID, vaultID string, readOnly bool) (*model.Vault, error) {
+	if v.p.readOnly == nil {
+		v.p.readOnly = map[string]bool{}
+	}
+	v.p.readOnly[vaultID] = readOnly
+	return &model.Vault{ActorID: userID, VaultID: vaultID, ReadOnly: readOnly}, nil
+}
+
+func (v *fakeVaults) MemoryStats(context.Context, string, string) ([]model.MemoryStats, error) {
+	return v.p.stats, nil
+}
+func (v *fakeVaults) Clone(context.Context, model.VaultClone) (*model.ClonedVault, error) {
+	panic("unused")
+}
+func (v *fakeVaults) Bootstrap(context.Context, model.Bootstrap) (*model.BootstrapResult, error) {
+	panic("unused")
+}
 
 type fakeMemories struct{ p *fakeStore }
 
 func (m *fakeMemories) Create(context.Context, *model.Memory) (*model.Memory, error) { panic("unused") }
-func (m *fakeMemories) GetByID(context.Context, string, string, string) (*model.Memory, error) {
-	panic("unused")
+func (m *fakeMemories) GetByID(_ context.Context, userID, vaultID, memoryID string) (*model.Memory, error) {
+	return &model.Memory{ActorID: userID, VaultID: vaultID, MemoryID: memoryID, AppendOnly: m.p.appendOnly[memoryID], EntryRoles: m.p.entryRoles[memoryID], SearchBoost: m.p.boosts[memoryID], EntryTTLSeconds: m.p.entryTTLs[memoryID]}, nil
 }
 func (m *fakeMemories) GetByTitle(context.Context, string, string, string) (*model.Memory, error) {
 	panic("unused")
@@ -98,30 +179,206 @@ func (m *fakeMemories) GetByTitle(context.Context, string, string, string) (*mod
 func (m *fakeMemories) List(context.Context, string, string) ([]*model.Memory, error) {
 	return m.p.mems, nil
 }
+func (m *fakeMemories) SetAppendOnly(ctx context.Context, userID, vaultID, memoryID string) (*model.Memory, error) {
+	if m.p.appendOnly == nil {
+		m.p.appendOnly = map[string]bool{}
+	}
+	m.p.appendOnly[memoryID] = true
+	return m.GetByID(ctx, userID, vaultID, memoryID)
+}
+func (m *fakeMemories) SetEntryRoles(ctx context.Context, userID, vaultID, memoryID string, roles []string) (*model.Memory, error) {
+	if m.p.entryRoles == nil {
+		m.p.entryRoles = map[string][]string{}
+	}
+	m.p.entryRoles[memoryID] = roles
+	return m.GetByID(ctx, userID, vaultID, memoryID)
+}
+func (m *fakeMemories) SetSearchBoost(ctx context.Context, userID, vaultID, memoryID string, b *model.SearchBoost) (*model.Memory, error) {
+	if m.p.boosts == nil {
+		m.p.boosts = map[string]*model.SearchBoost{}
+	}
+	m.p.boosts[memoryID] = b
+	return m.GetByID(ctx, userID, vaultID, memoryID)
+}
+func (m *fakeMemories) Update(context.Context, string, string, string, model.TitleUpdate) (*model.Memory, error) {
+	panic("unused")
+}
 func (m *fakeMemories) Delete(context.Context, string, string, string) error { panic("unused") }
+func (m *fakeMemories) Trash(context.Context, string, string, string) error  { panic("unused") }
+func (m *fakeMemories) Restore(context.Context, string, string, string) (*model.Memory, error) {
+	panic("unused")
+}
+func (m *fakeMemories) ListTrash(context.Context, string, string) ([]*model.Memory, error) {
+	panic("unused")
+}
+func (m *fakeMemories) PurgeTrash(context.Context, time.Time, int) (int, error) { panic("unused") }
 
 type fakeEntries struct{ p *fakeStore }
 
-func (e *fakeEntries) Create(context.Context, *model.MemoryEntry) (*model.MemoryEntry, error) {
-	panic("unused")
+func (e *fakeEntries) Create(_ context.Context, me *model.MemoryEntry) (*model.MemoryEntry, error) {
+	if e.p.entriesByMem == nil {
+		e.p.entriesByMem = map[string][]*model.MemoryEntry{}
+	}
+	out := *me
+	out.EntryID = fmt.Sprintf("e%d", len(e.p.entriesByMem[me.MemoryID]))
+	e.p.entriesByMem[me.MemoryID] = append(e.p.entriesByMem[me.MemoryID], &out)
+	return &out, nil
+}
+func (e *fakeEntries) CreateBatch(ctx context.Context, batch []*model.MemoryEntry) ([]*model.MemoryEntry, error) {
+	out := make([]*model.MemoryEntry, len(batch))
+	for i, me := range batch {
+		out[i], _ = e.Create(ctx, me)
+	}
+	return out, nil
 }
 func (e *fakeEntries) List(_ context.Context, req model.ListEntriesRequest) ([]*model.MemoryEntry, error) {
 	return e.p.entriesByMem[req.MemoryID], nil
 }
-func (e *fakeEntries) GetByID(context.Context, string, string, string, string) (*model.MemoryEntry, error) {
+func (e *fakeEntries) GetByID(_ context.Context, _, _, memoryID, entryID string) (*model.MemoryEntry, error) {
+	for _, me := range e.p.entriesByMem[memoryID] {
+		if me.EntryID == entryID {
+			return me, nil
+		}
+	}
+	return nil, model.ErrNotFound
+}
+func (e *fakeEntries) Scan(context.Context, model.ScanEntriesRequest) ([]*model.MemoryEntry, error) {
 	panic("unused")
 }
 func (e *fakeEntries) UpdateTags(context.Context, string, string, string, string, map[string]interface{}) (*model.MemoryEntry, error) {
 	panic("unused")
 }
-func (e *fakeEntries) DeleteByID(context.Context, string, string, string, string) error {
+func (e *fakeEntries) PatchTags(context.Context, model.EntryTagPatch) ([]string, error) {
+	panic("unused")
+}
+func (e *fakeEntries) RecordSignal(context.Context, string, string, string, string, string) (*model.MemoryEntry, error) {
+	panic("unused")
+}
+func (e *fakeEntries) Signals(context.Context, string, []string) (map[string]model.EntrySignals, error) {
+	panic("unused")
+}
+func (e *fakeEntries) DeleteByID(_ context.Context, _, _, memoryID, entryID string) error {
+	kept := e.p.entriesByMem[memoryID][:0]
+	for _, me := range e.p.entriesByMem[memoryID] {
+		if me.EntryID != entryID {
+			kept = append(kept, me)
+		}
+	}
+	e.p.entriesByMem[memoryID] = kept
+	return nil
+}
+func (e *fakeEntries) Sessions(context.Context, string, string, string) ([]model.EntrySession, error) {
+	panic("unused")
+}
+func (e *fakeEntries) SessionStart(_ context.Context, _, memoryID, sessionID string) (time.Time, error) {
+	for _, me := range e.p.entriesByMem[memoryID] {
+		if me.SessionID == sessionID {
+			return me.CreationTime, nil
+		}
+	}
+	return time.Time{}, model.ErrNotFound
+}
+func (e *fakeEntries) Newest(_ context.Context, _, memoryID string) (time.Time, error) {
+	var newest time.Time
+	for _, me := range e.p.entriesByMem[memoryID] {
+		if me.CreationTime.After(newest) {
+			newest = me.CreationTime
+		}
+	}
+	if newest.IsZero() {
+		return time.Time{}, model.ErrNotFound
+	}
+	return newest, nil
+}
+func (e *fakeEntries) Usage(_ context.Context, _, memoryID string, _ *time.Time) ([]model.UsageTotals, error) {
+	var out []model.UsageTotals
+	for id, entries := range e.p.entriesByMem {
+		if memoryID != "" && id != memoryID {
+			continue
+		}
+		for _, me := range entries {
+			if me.Usage != nil {
+				out = append(out, model.UsageTotals{MemoryID: id, Model: me.Usage.Model, Entries: 1,
+					InputTokens: me.Usage.InputTokens, OutputTokens: me.Usage.OutputTokens, CostUSD: me.Usage.CostUSD})
+			}
+		}
+	}
+	return out, nil
+}
+func (e *fakeEntries) Touch(context.Context, string, []string, time.Time) error { return nil }
+func (e *fakeEntries) Expire(context.Context, time.Time, bool, int) ([]string, error) {
+	panic("unused")
+}
+func (e *fakeEntries) ExpireDue(_ context.Context, now time.Time, _ int) ([]string, error) {
+	var ids []string
+	for memoryID, entries := range e.p.entriesByMem {
+		kept := entries[:0]
+		for _, me := range entries {
+			if me.ExpirationTime != nil && !me.ExpirationTime.After(now) {
+				ids = append(ids, me.EntryID)
+			} else {
+				kept = append(kept, me)
+			}
+		}
+		e.p.entriesByMem[memoryID] = kept
+	}
+	return ids, nil
+}
+func (e *fakeEntries) Expired(_ context.Context, _ string, ids []string, now time.Time) (map[string]bool, error) {
+	want := map[string]bool{}
+	for _, id := range ids {
+		want[id] = true
+	}
+	out := map[string]bool{}
+	for _, entries := range e.p.entriesByMem {
+		for _, me := range entries {
+			if want[me.EntryID] && me.ExpirationTime != nil && !me.ExpirationTime.After(now) {
+				out[me.EntryID] = true
+			}
+		}
+	}
+	return out, nil
+}
+func (e *fakeEntries) Trash(context.Context, string, string, string, string) error { panic("unused") }
+func (e *fakeEntries) Restore(context.Context, string, string, string, string) (*model.MemoryEntry, error) {
+	panic("unused")
+}
+func (e *fakeEntries) ListTrash(context.Context, string, string, int) ([]*model.MemoryEntry, error) {
+	panic("unused")
+}
+func (e *fakeEntries) Trashed(context.Context, string, []string) (map[string]bool, error) {
+	panic("unused")
+}
+func (e *fakeEntries) PurgeTrash(context.Context, time.Time, int) ([]string, error) {
+	panic("unused")
+}
+func (e *fakeEntries) Correct(context.Context, string, *model.MemoryEntry, string) (*model.MemoryEntry, error) {
+	panic("unused")
+}
+func (e *fakeEntries) Corrections(context.Context, string, []string) (map[string]*model.MemoryEntry, error) {
 	panic("unused")
 }
 
 type fakeContexts struct{ p *fakeStore }
 
-func (c *fakeContexts) Put(context.Context, *model.MemoryContext) (*model.MemoryContext, error) {
-	panic("unused")
+func (c *fakeContexts) Put(_ context.Context, mc *model.MemoryContext) (*model.MemoryContext, error) {
+	if c.p.ctxByMem == nil {
+		c.p.ctxByMem = map[string]*model.MemoryContext{}
+	}
+	out := *mc
+	out.ContextID = fmt.Sprintf("c-%s", mc.MemoryID)
+	c.p.ctxByMem[mc.MemoryID] = &out
+	return &out, nil
+}
+func (c *fakeContexts) PutIfLatest(ctx context.Context, mc *model.MemoryContext, latestID string) (*model.MemoryContext, error) {
+	current := ""
+	if cur, ok := c.p.ctxByMem[mc.MemoryID]; ok {
+		current = cur.ContextID
+	}
+	if current != latestID {
+		return nil, model.ErrPrecondition
+	}
+	return c.Put(ctx, mc)
 }
 func (c *fakeContexts) Latest(_ context.Context, _ string, _ string, memoryID string) (*model.MemoryContext, error) {
 	if mc, ok := c.p.ctxByMem[memoryID]; ok {
@@ -129,9 +386,27 @@ func (c *fakeContexts) Latest(_ context.Context, _ string, _ string, memoryID st
 	}
 	return nil, model.ErrNotFound
 }
+func (c *fakeContexts) LatestForMemories(_ context.Context, _ string, memoryIDs []string) (map[string]*model.MemoryContext, error) {
+	out := map[string]*model.MemoryContext{}
+	for _, id := range memoryIDs {
+		if mc, ok := c.p.ctxByMem[id]; ok {
+			out[id] = mc
+		}
+	}
+	return out, nil
+}
 func (c *fakeContexts) DeleteByID(context.Context, string, string, string, string) error {
 	panic("unused")
 }
+func (c *fakeContexts) CompactionCandidates(context.Context, time.Time, model.MemoryRef, int) ([]model.MemoryRef, error) {
+	panic("unused")
+}
+func (c *fakeContexts) Snapshots(context.Context, model.MemoryRef, time.Time) ([]model.ContextSnapshot, error) {
+	panic("unused")
+}
+func (c *fakeContexts) DeleteMany(context.Context, model.MemoryRef, []string) (int, error) {
+	panic("unused")
+}
 
 // --- Test ---
 
@@ -181,3 +456,107 @@ func TestVaultDeletePropagatesToIndex(t *testing.T) {
 		t.Fatalf("storage vault delete not invoked correctly: %+v", fs.vaultDeleted)
 	}
 }
+
+func TestReadOnlyVaultRejectsWrites(t *testing.T) {
+	idx := &fakeIndex{}
+	fs := &fakeStore{}
+	ctx := context.Background()
+	vaults := NewVaultService(fs, idx)
+	mems := NewMemoryService(fs, idx, nil)
+
+	if v, err := vaults.SetVaultReadOnly(ctx, "u1", "v1", true); err != nil || !v.ReadOnly {
+		t.Fatalf("SetVaultReadOnly: v=%+v err=%v", v, err)
+	}
+
+	// Store write methods on the fakes panic, so reaching them fails the test.
+	checks := map[string]error{
+		"DeleteVault": vaults.DeleteVault(ctx, "u1", "v1"),
+		"AddMemory":   vaults.AddMemoryToVault(ctx, "u1", "v1", "m1"),
+		"DeleteEntry": mems.DeleteEntry(ctx, "u1", "v1", "m1", "e1"),
+	}
+	_, checks["CreateEntry"] = mems.CreateEntry(ctx, &model.MemoryEntry{ActorID: "u1", VaultID: "v1", MemoryID: "m1", RawEntry: "x"})
+	_, checks["PutContext"] = mems.PutContext(ctx, &model.MemoryContext{ActorID: "u1", VaultID: "v1", MemoryID: "m1", Context: "x"})
+	_, checks["UpdateEntryTags"] = mems.UpdateEntryTags(ctx, "u1", "v1", "m1", "e1", nil)
+	_, checks["PatchEntryTags"] = mems.PatchEntryTags(ctx, model.EntryTagPatch{ActorID: "u1", VaultID: "v1", MemoryID: "m1", SessionID: "s1", Unset: []string{"k"}})
+	for name, err := range checks {
+		if !errors.Is(err, model.ErrReadOnly) {
+			t.Errorf("%s: expected ErrReadOnly, got %v", name, err)
+		}
+	}
+	if len(idx.deletedEntries) != 0 || len(idx.deleteVaultArgs) != 0 {
+		t.Fatalf("index must not be touched for a read-only vault: %+v", idx)
+	}
+}
+
+func TestAppendOnlyMemoryRejectsEntryChanges(t *testing.T) {
+	idx := &fakeIndex{}
+	fs := &fakeStore{}
+	ctx := context.Background()
+	mems := NewMemoryService(fs, idx, nil)
+
+	if m, err := mems.SetMemoryAppendOnly(ctx, "u1", "v1", "m1"); err != nil || !m.AppendOnly {
+		t.Fatalf("SetMemoryAppendOnly: m=%+v err=%v", m, err)
+	}
+
+	// Store write methods on the fakes panic, so reaching them fails the test.
+	checks := map[string]error{
+		"DeleteEntry":  mems.DeleteEntry(ctx, "u1", "v1", "m1", "e1"),
+		"DeleteMemory": mems.DeleteMemory(ctx, "u1", "v1", "m1"),
+	}
+	_, checks["UpdateEntryTags"] = mems.UpdateEntryTags(ctx, "u1", "v1", "m1", "e1", nil)
+	_, checks["PatchEntryTags"] = mems.PatchEntryTags(ctx, model.EntryTagPatch{ActorID: "u1", VaultID: "v1", MemoryID: "m1", SessionID: "s1", Unset: []string{"k"}})
+	for name, err := range checks {
+		if !errors.Is(err, model.ErrAppendOnly) {
+			t.Errorf("%s: expected ErrAppendOnly, got %v", name, err)
+		}
+	}
+	if len(idx.deletedEntries) != 0 {
+		t.Fatalf("index must not be touched for an append-only memory: %+v", idx)
+	}
+
+	// New entries are still accepted.
+	if _, err := mems.CreateEntry(ctx, &model.MemoryEntry{ActorID: "u1", VaultID: "v1", MemoryID: "m1", RawEntry: "x"}); err != nil {
+		t.Fatalf("CreateEntry: %v", err)
+	}
+}
+
+// countingIndex reports fixed per-memory object counts.
+type countingIndex struct {
+	fakeIndex
+	counts map[string]model.ObjectCounts
+}
+
+func (c *countingIndex) CountObjects(_ context.Context, _, memoryID string) (model.ObjectCounts, error) {
+	return c.counts[memoryID], nil
+}
+
+func TestVaultStatsComparesIndex(t *testing.T) {
+	rows := func() []model.MemoryStats {
+		return []model.MemoryStats{
+			{MemoryID: "m1", Postgres: model.ObjectCounts{Entries: 3, Contexts: 1}},
+			{MemoryID: "m2", Postgres: model.ObjectCounts{Entries: 2}},
+		}
+	}
+	fs := &fakeStore{stats: rows()}
+	idx := &countingIndex{counts: map[string]model.ObjectCounts{
+		"m1": {Entries: 3, Contexts: 1},
+		"m2": {Entries: 1},
+	}}
+	st, err := NewVaultService(fs, idx).VaultStats(context.Background(), "u1", "v1")
+	if err != nil {
+		t.Fatalf("VaultStats: %v", err)
+	}
+	if st.InSync || !st.Memories[0].InSync() || st.Memories[1].InSync() {
+		t.Fatalf("expected only m2 out of sync: %+v", st)
+	}
+	if st.Postgres != (model.ObjectCounts{Entries: 5, Contexts: 1}) || *st.Index != (model.ObjectCounts{Entries: 4, Contexts: 1}) {
+		t.Fatalf("unexpected totals: pg=%+v index=%+v", st.Postgres, st.Index)
+	}
+
+	// An index without counts reports Postgres only and never claims sync.
+	fs.stats = rows()
+	st, err = NewVaultService(fs, &fakeIndex{}).VaultStats(context.Background(), "u1", "v1")
+	if err != nil || st.Index != nil || st.Memories[0].Index != nil || st.InSync {
+		t.Fatalf("uncounted index: %+v err=%v", st, err)
+	}
+}
@@ -0,0 +1,390 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/store"
+	"github.com/mycelian/mycelian-memory/server/internal/summarizer"
+)
+
+// Limits of inbound webhooks.
+const (
+	MaxWebhooksPerMemory  = 20
+	MaxWebhookNameLen     = 100
+	MaxWebhookSecretLen   = 256
+	MaxWebhookTemplateLen = 2000
+	MaxWebhookTags        = 20
+	// MaxWebhookEntryLen matches the rawEntry limit of POST .../entries.
+	MaxWebhookEntryLen = 9000
+	// webhookFieldLen bounds the rendered sourceId and sessionId, like the
+	// provenance fields of POST .../entries.
+	webhookFieldLen = 256
+)
+
+// WebhookSourcePrefix prefixes the sourceSystem of entries written by a
+// webhook; the webhook's name follows.
+const WebhookSourcePrefix = "webhook:"
+
+// WebhookDelivery lists the entries one payload was stored as, in payload
+// order; Count is 0 when no item rendered a rawEntry.
+type WebhookDelivery struct {
+	WebhookID string   `json:"webhookId"`
+	EntryIDs  []string `json:"entryIds"`
+	Count     int      `json:"count"`
+}
+
+// WebhookService manages the inbound webhooks of memories and writes their
+// deliveries as entries.
+type WebhookService struct {
+	store      store.Store
+	mem        *MemoryService
+	summarizer summarizer.Summarizer
+}
+
+// NewWebhookService writes deliveries through mem and summarizes entries
+// whose mapping has no summary with sum.
+func NewWebhookService(s store.Store, mem *MemoryService, sum summarizer.Summarizer) *WebhookService {
+	return &WebhookService{store: s, mem: mem, summarizer: sum}
+}
+
+// CreateWebhook validates and stores w. Generic webhooks get a generated
+// secret unless one is given; Slack webhooks need the app's signing secret.
+// The result carries the secret, which is never returned again.
+func (s *WebhookService) CreateWebhook(ctx context.Context, w *model.Webhook) (*model.Webhook, error) {
+	w.Name = strings.TrimSpace(w.Name)
+	if w.Name == "" || len(w.Name) > MaxWebhookNameLen {
+		return nil, fmt.Errorf("%w: name must hold 1 to %d bytes", model.ErrValidation, MaxWebhookNameLen)
+	}
+	switch w.Kind {
+	case "":
+		w.Kind = model.WebhookGeneric
+	case model.WebhookGeneric, model.WebhookSlack:
+	default:
+		return nil, fmt.Errorf("%w: kind must be %s or %s", model.ErrValidation, model.WebhookGeneric, model.WebhookSlack)
+	}
+	if len(w.Secret) > MaxWebhookSecretLen {
+		return nil, fmt.Errorf("%w: secret must be at most %d bytes", model.ErrValidation, MaxWebhookSecretLen)
+	}
+	if w.Secret == "" {
+		if w.Kind == model.WebhookSlack {
+			return nil, fmt.Errorf("%w: slack webhooks need the app's signing secret", model.ErrValidation)
+		}
+		secret, err := newWebhookSecret()
+		if err != nil {
+			return nil, err
+		}
+		w.Secret = secret
+	}
+	if err := validateWebhookMapping(w.Mapping); err != nil {
+		return nil, err
+	}
+	if err := ensureVaultWritable(ctx, s.store, w.ActorID, w.VaultID); err != nil {
+		return nil, err
+	}
+	if _, err := s.store.Memories().GetByID(ctx, w.ActorID, w.VaultID, w.MemoryID); err != nil {
+		return nil, err
+	}
+	existing, err := s.store.Webhooks().List(ctx, w.ActorID, w.MemoryID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= MaxWebhooksPerMemory {
+		return nil, fmt.Errorf("%w: a memory holds at most %d webhooks", model.ErrValidation, MaxWebhooksPerMemory)
+	}
+	w.WebhookID = uuid.New().String()
+	return s.store.Webhooks().Create(ctx, w)
+}
+
+// ListWebhooks returns the memory's webhooks without their secrets.
+func (s *WebhookService) ListWebhooks(ctx context.Context, actorID, vaultID, memoryID string) ([]*model.Webhook, error) {
+	if _, err := s.store.Memories().GetByID(ctx, actorID, vaultID, memoryID); err != nil {
+		return nil, err
+	}
+	out, err := s.store.Webhooks().List(ctx, actorID, memoryID)
+	if err != nil {
+		return nil, err
+	}
+	for _, w := range out {
+		w.Secret = ""
+	}
+	return out, nil
+}
+
+// DeleteWebhook removes a webhook; later deliveries to it are not found.
+func (s *WebhookService) DeleteWebhook(ctx context.Context, actorID, vaultID, memoryID, webhookID string) error {
+	if err := ensureVaultWritable(ctx, s.store, actorID, vaultID); err != nil {
+		return err
+	}
+	return s.store.Webhooks().Delete(ctx, actorID, vaultID, memoryID, webhookID)
+}
+
+// Webhook returns a webhook, secret included, for verifying a delivery.
+func (s *WebhookService) Webhook(ctx context.Context, webhookID string) (*model.Webhook, error) {
+	return s.store.Webhooks().Get(ctx, webhookID)
+}
+
+// Deliver maps a verified JSON payload to entries of the webhook's memory
+// and writes them in one batch. Items whose rawEntry renders empty are
+// skipped, so events a mapping does not cover are acknowledged without
+// writing anything. Entries without a mapped summary are summarized with
+// the prompt of the memory's type before the first write.
+//
+// deliveryID identifies the delivery at its sender, such as Slack's
+// event_id; a redelivery with the same ID returns the entries of the first
+// instead of writing copies. Empty disables the check.
+func (s *WebhookService) Deliver(ctx context.Context, w *model.Webhook, payload []byte, deliveryID string) (*WebhookDelivery, error) {
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	dec.UseNumber()
+	var root interface{}
+	if err := dec.Decode(&root); err != nil {
+		return nil, fmt.Errorf("%w: payload is not JSON: %v", model.ErrValidation, err)
+	}
+	items := []interface{}{root}
+	if w.Mapping.Items != "" {
+		v, _ := lookupWebhookPath(w.Mapping.Items, root, root)
+		arr, ok := v.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%w: items path %q is not an array in the payload", model.ErrValidation, w.Mapping.Items)
+		}
+		items = arr
+	}
+	if len(items) > model.MaxEntriesBatch {
+		return nil, fmt.Errorf("%w: payload holds more than %d items", model.ErrValidation, model.MaxEntriesBatch)
+	}
+
+	var entries []*model.MemoryEntry
+	var unsummarized []int
+	for i, item := range items {
+		e, err := webhookEntry(w, item, root)
+		if err != nil {
+			return nil, fmt.Errorf("%w: items[%d]: %v", model.ErrValidation, i, err)
+		}
+		if e == nil {
+			continue
+		}
+		if deliveryID != "" {
+			e.IdempotencyKey = webhookIdempotencyKey(w.WebhookID, deliveryID, i)
+		}
+		if e.Summary == nil {
+			unsummarized = append(unsummarized, len(entries))
+		}
+		entries = append(entries, e)
+	}
+	out := &WebhookDelivery{WebhookID: w.WebhookID, EntryIDs: []string{}}
+	if len(entries) == 0 {
+		return out, nil
+	}
+
+	if len(unsummarized) > 0 {
+		mem, err := s.store.Memories().GetByID(ctx, w.ActorID, w.VaultID, w.MemoryID)
+		if err != nil {
+			return nil, err
+		}
+		raws := make([]string, len(unsummarized))
+		for i, k := range unsummarized {
+			raws[i] = entries[k].RawEntry
+		}
+		summaries, err := s.summarizer.Summarize(ctx, mem.MemoryType, raws)
+		if err != nil {
+			return nil, fmt.Errorf("summarize webhook delivery: %w", err)
+		}
+		if len(summaries) != len(raws) {
+			return nil, fmt.Errorf("summarize webhook delivery: got %d summaries for %d entries", len(summaries), len(raws))
+		}
+		for i, k := range unsummarized {
+			entries[k].Summary = &summaries[i]
+		}
+	}
+
+	created, err := s.mem.CreateEntries(ctx, entries)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range created {
+		out.EntryIDs = append(out.EntryIDs, e.EntryID)
+	}
+	out.Count = len(created)
+	// The delivery counters are informational; the entries are stored either way.
+	_ = s.store.Webhooks().RecordDelivery(ctx, w.WebhookID, time.Now())
+	return out, nil
+}
+
+// webhookIdempotencyKey is the idempotency key of item i of a delivery,
+// hashed to fit the entry key limit whatever the sender's ID looks like.
+func webhookIdempotencyKey(webhookID, deliveryID string, i int) string {
+	sum := sha256.Sum256([]byte(webhookID + "\x00" + deliveryID))
+	return "webhook:" + hex.EncodeToString(sum[:16]) + ":" + strconv.Itoa(i)
+}
+
+// webhookEntry renders one payload item through the mapping; nil when its
+// rawEntry is empty.
+func webhookEntry(w *model.Webhook, item, root interface{}) (*model.MemoryEntry, error) {
+	m := w.Mapping
+	raw := strings.TrimSpace(renderWebhookTemplate(m.RawEntry, item, root))
+	if raw == "" {
+		return nil, nil
+	}
+	if len(raw) > MaxWebhookEntryLen {
+		return nil, fmt.Errorf("rawEntry exceeds %d characters", MaxWebhookEntryLen)
+	}
+	e := &model.MemoryEntry{
+		ActorID: w.ActorID, VaultID: w.VaultID, MemoryID: w.MemoryID,
+		RawEntry:     raw,
+		Metadata:     map[string]interface{}{"webhookId": w.WebhookID},
+		SourceSystem: WebhookSourcePrefix + w.Name,
+		SourceID:     strings.TrimSpace(renderWebhookTemplate(m.SourceID, item, root)),
+		SessionID:    strings.TrimSpace(renderWebhookTemplate(m.SessionID, item, root)),
+	}
+	if len(e.SourceID) > webhookFieldLen || len(e.SessionID) > webhookFieldLen {
+		return nil, fmt.Errorf("sourceId and sessionId must be at most %d bytes", webhookFieldLen)
+	}
+	if sum := strings.TrimSpace(renderWebhookTemplate(m.Summary, item, root)); sum != "" {
+		e.Summary = &sum
+	}
+	if ts := strings.TrimSpace(renderWebhookTemplate(m.ConversationTime, item, root)); ts != "" {
+		t, err := parseWebhookTime(ts)
+		if err != nil {
+			return nil, err
+		}
+		e.ConversationTime = &t
+	}
+	for k, tmpl := range m.Tags {
+		if v := strings.TrimSpace(renderWebhookTemplate(tmpl, item, root)); v != "" {
+			if e.Tags == nil {
+				e.Tags = map[string]interface{}{}
+			}
+			e.Tags[k] = v
+		}
+	}
+	return e, nil
+}
+
+// parseWebhookTime reads RFC 3339 or unix seconds with an optional
+// fraction, as in Slack's "ts".
+func parseWebhookTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+	whole, frac, _ := strings.Cut(s, ".")
+	secs, err := strconv.ParseInt(whole, 10, 64)
+	nanos, ferr := strconv.ParseInt((frac + "000000000")[:9], 10, 64)
+	if err != nil || ferr != nil || secs <= 0 || len(frac) > 9 {
+		return time.Time{}, fmt.Errorf("conversationTime %q is neither RFC 3339 nor unix seconds", s)
+	}
+	return time.Unix(secs, nanos).UTC(), nil
+}
+
+var webhookPlaceholder = regexp.MustCompile(`\{\{\s*([^{}]*?)\s*\}\}`)
+
+// renderWebhookTemplate replaces each {{path}} of tmpl with the value at
+// that path.
+func renderWebhookTemplate(tmpl string, item, root interface{}) string {
+	if tmpl == "" {
+		return ""
+	}
+	return webhookPlaceholder.ReplaceAllStringFunc(tmpl, func(ph string) string {
+		path := webhookPlaceholder.FindStringSubmatch(ph)[1]
+		v, ok := lookupWebhookPath(path, item, root)
+		if !ok {
+			return ""
+		}
+		switch v := v.(type) {
+		case nil:
+			return ""
+		case string:
+			return v
+		case json.Number:
+			return v.String()
+		case bool:
+			return strconv.FormatBool(v)
+		default:
+			b, _ := json.Marshal(v)
+			return string(b)
+		}
+	})
+}
+
+// lookupWebhookPath walks a dotted path of object keys and array indexes
+// from item, or from root when the path starts with "$". "." and "" name
+// item itself.
+func lookupWebhookPath(path string, item, root interface{}) (interface{}, bool) {
+	cur := item
+	if path == "$" || strings.HasPrefix(path, "$.") {
+		cur, path = root, strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	}
+	if path == "" || path == "." {
+		return cur, true
+	}
+	for _, key := range strings.Split(path, ".") {
+		switch c := cur.(type) {
+		case map[string]interface{}:
+			v, ok := c[key]
+			if !ok {
+				return nil, false
+			}
+			cur = v
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(c) {
+				return nil, false
+			}
+			cur = c[i]
+		default:
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+func validateWebhookMapping(m model.WebhookMapping) error {
+	if strings.TrimSpace(m.RawEntry) == "" {
+		return fmt.Errorf("%w: mapping.rawEntry is required", model.ErrValidation)
+	}
+	if len(m.Tags) > MaxWebhookTags {
+		return fmt.Errorf("%w: mapping.tags holds at most %d tags", model.ErrValidation, MaxWebhookTags)
+	}
+	fields := map[string]string{
+		"items": m.Items, "rawEntry": m.RawEntry, "summary": m.Summary, "sessionId": m.SessionID,
+		"sourceId": m.SourceID, "conversationTime": m.ConversationTime,
+	}
+	for k, v := range m.Tags {
+		if strings.TrimSpace(k) == "" {
+			return fmt.Errorf("%w: mapping.tags keys must not be empty", model.ErrValidation)
+		}
+		fields["tags."+k] = v
+	}
+	for name, tmpl := range fields {
+		if len(tmpl) > MaxWebhookTemplateLen {
+			return fmt.Errorf("%w: mapping.%s must be at most %d bytes", model.ErrValidation, name, MaxWebhookTemplateLen)
+		}
+		if strings.Count(tmpl, "{{") != len(webhookPlaceholder.FindAllString(tmpl, -1)) {
+			return fmt.Errorf("%w: mapping.%s has an unterminated {{placeholder}}", model.ErrValidation, name)
+		}
+	}
+	if strings.Contains(m.Items, "{{") {
+		return fmt.Errorf("%w: mapping.items is a path, not a template", model.ErrValidation)
+	}
+	return nil
+}
+
+// newWebhookSecret returns 32 random bytes, hex encoded.
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
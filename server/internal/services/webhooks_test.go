@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/summarizer"
+)
+
+type memWebhooks struct {
+	hooks      map[string]*model.Webhook
+	deliveries int
+}
+
+func (m *memWebhooks) Create(_ context.Context, w *model.Webhook) (*model.Webhook, error) {
+	if m.hooks == nil {
+		m.hooks = map[string]*model.Webhook{}
+	}
+	for _, h := range m.hooks {
+		if h.MemoryID == w.MemoryID && h.Name == w.Name {
+			return nil, model.ErrConflict
+		}
+	}
+	out := *w
+	m.hooks[w.WebhookID] = &out
+	return &out, nil
+}
+func (m *memWebhooks) Get(_ context.Context, webhookID string) (*model.Webhook, error) {
+	if h, ok := m.hooks[webhookID]; ok {
+		out := *h
+		return &out, nil
+	}
+	return nil, model.ErrNotFound
+}
+func (m *memWebhooks) List(_ context.Context, _, memoryID string) ([]*model.Webhook, error) {
+	var out []*model.Webhook
+	for _, h := range m.hooks {
+		if h.MemoryID == memoryID {
+			c := *h
+			out = append(out, &c)
+		}
+	}
+	return out, nil
+}
+func (m *memWebhooks) Delete(_ context.Context, _, _, _, webhookID string) error {
+	if _, ok := m.hooks[webhookID]; !ok {
+		return model.ErrNotFound
+	}
+	delete(m.hooks, webhookID)
+	return nil
+}
+func (m *memWebhooks) RecordDelivery(context.Context, string, time.Time) error {
+	m.deliveries++
+	return nil
+}
+
+func TestWebhookCreateAndList(t *testing.T) {
+	hooks := &memWebhooks{}
+	fs := &fakeStore{webhooks: hooks}
+	svc := NewWebhookService(fs, NewMemoryService(fs, nil, nil), summarizer.Extractive{})
+	ctx := context.Background()
+
+	w, err := svc.CreateWebhook(ctx, &model.Webhook{ActorID: "u1", VaultID: "v1", MemoryID: "m1", Name: " ci ", Mapping: model.WebhookMapping{RawEntry: "{{text}}"}})
+	if err != nil || w.Kind != model.WebhookGeneric || w.Name != "ci" || len(w.Secret) != 64 || w.WebhookID == "" {
+		t.Fatalf("CreateWebhook: got=%+v err=%v", w, err)
+	}
+	if list, err := svc.ListWebhooks(ctx, "u1", "v1", "m1"); err != nil || len(list) != 1 || list[0].Secret != "" {
+		t.Fatalf("ListWebhooks must hide secrets: got=%+v err=%v", list, err)
+	}
+
+	for name, bad := range map[string]model.Webhook{
+		"no name":        {Mapping: model.WebhookMapping{RawEntry: "x"}},
+		"kind":           {Name: "a", Kind: "teams", Mapping: model.WebhookMapping{RawEntry: "x"}},
+		"slack secret":   {Name: "a", Kind: model.WebhookSlack, Mapping: model.WebhookMapping{RawEntry: "x"}},
+		"no rawEntry":    {Name: "a"},
+		"unterminated":   {Name: "a", Mapping: model.WebhookMapping{RawEntry: "{{text"}},
+		"templated path": {Name: "a", Mapping: model.WebhookMapping{RawEntry: "x", Items: "{{items}}"}},
+	} {
+		bad.ActorID, bad.VaultID, bad.MemoryID = "u1", "v1", "m1"
+		if _, err := svc.CreateWebhook(ctx, &bad); !errors.Is(err, model.ErrValidation) {
+			t.Fatalf("%s: expected validation error, got %v", name, err)
+		}
+	}
+
+	fs.readOnly = map[string]bool{"v1": true}
+	if _, err := svc.CreateWebhook(ctx, &model.Webhook{ActorID: "u1", VaultID: "v1", MemoryID: "m1", Name: "b", Mapping: model.WebhookMapping{RawEntry: "x"}}); !errors.Is(err, model.ErrReadOnly) {
+		t.Fatalf("expected read-only error, got %v", err)
+	}
+}
+
+func TestWebhookDeliver(t *testing.T) {
+	hooks := &memWebhooks{}
+	fs := &fakeStore{webhooks: hooks}
+	svc := NewWebhookService(fs, NewMemoryService(fs, nil, nil), summarizer.Extractive{})
+	ctx := context.Background()
+
+	w, err := svc.CreateWebhook(ctx, &model.Webhook{
+		ActorID: "u1", VaultID: "v1", MemoryID: "m1", Name: "slack", Kind: model.WebhookSlack, Secret: "s3cret",
+		Mapping: model.WebhookMapping{
+			Items:            "messages",
+			RawEntry:         "{{text}}",
+			SessionID:        "{{$.channel}}",
+			SourceID:         "{{ts}}",
+			ConversationTime: "{{ts}}",
+			Tags:             map[string]string{"channel": "{{$.channel}}", "user": "{{user}}", "thread": "{{thread_ts}}"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateWebhook: %v", err)
+	}
+
+	payload := `{"channel":"C1","messages":[
+		{"user":"ana","text":"Ship it on Friday.","ts":"1767261600.000200"},
+		{"user":"bot","ts":"1767261601"},
+		{"user":"bo","text":"Agreed.","ts":"2026-01-01T10:00:05Z","thread_ts":"1767261600.000200"}]}`
+	res, err := svc.Deliver(ctx, w, []byte(payload), "")
+	if err != nil || res.Count != 2 || len(res.EntryIDs) != 2 || hooks.deliveries != 1 {
+		t.Fatalf("Deliver: got=%+v err=%v deliveries=%d", res, err, hooks.deliveries)
+	}
+	stored := fs.entriesByMem["m1"]
+	first := stored[0]
+	if first.RawEntry != "Ship it on Friday." || first.Tags["user"] != "ana" || first.Summary == nil || *first.Summary == "" {
+		t.Fatalf("unexpected first entry: %+v", first)
+	}
+	if first.SourceSystem != "webhook:slack" || first.SourceID != "1767261600.000200" || first.SessionID != "C1" || first.Tags["channel"] != "C1" {
+		t.Fatalf("unexpected provenance: %+v", first)
+	}
+	if _, ok := first.Tags["thread"]; ok || stored[1].Tags["thread"] != "1767261600.000200" {
+		t.Fatalf("empty tags must be dropped: %v / %v", first.Tags, stored[1].Tags)
+	}
+	if want := time.Date(2026, 1, 1, 10, 0, 0, 200000, time.UTC); first.ConversationTime == nil || !first.ConversationTime.Equal(want) {
+		t.Fatalf("conversation time: got %v want %v", first.ConversationTime, want)
+	}
+
+	if first.IdempotencyKey != "" {
+		t.Fatalf("delivery without an ID must not set an idempotency key: %q", first.IdempotencyKey)
+	}
+
+	// A redelivery carries the same keys, so the store returns the first entries.
+	keys := func() []string {
+		before := len(fs.entriesByMem["m1"])
+		if _, err := svc.Deliver(ctx, w, []byte(payload), "evt-1"); err != nil {
+			t.Fatalf("Deliver with ID: %v", err)
+		}
+		var out []string
+		for _, e := range fs.entriesByMem["m1"][before:] {
+			out = append(out, e.IdempotencyKey)
+		}
+		return out
+	}
+	k1, k2 := keys(), keys()
+	if len(k1) != 2 || k1[0] == "" || k1[0] == k1[1] || strings.Join(k1, ",") != strings.Join(k2, ",") {
+		t.Fatalf("idempotency keys: first=%v redelivery=%v", k1, k2)
+	}
+
+	if res, err := svc.Deliver(ctx, w, []byte(`{"channel":"C1","messages":[]}`), ""); err != nil || res.Count != 0 {
+		t.Fatalf("empty delivery: got=%+v err=%v", res, err)
+	}
+	for name, bad := range map[string]string{
+		"not json":   `{`,
+		"not array":  `{"messages":{"text":"x"}}`,
+		"bad time":   `{"messages":[{"text":"x","ts":"yesterday"}]}`,
+		"long entry": `{"messages":[{"text":"` + strings.Repeat("a", MaxWebhookEntryLen+1) + `"}]}`,
+	} {
+		if _, err := svc.Deliver(ctx, w, []byte(bad), ""); !errors.Is(err, model.ErrValidation) {
+			t.Fatalf("%s: expected validation error, got %v", name, err)
+		}
+	}
+}
+
+func TestRenderWebhookTemplate(t *testing.T) {
+	root := map[string]interface{}{"a": map[string]interface{}{"b": []interface{}{"x", true}}, "n": nil}
+	for tmpl, want := range map[string]string{
+		"{{a.b.0}}-{{ a.b.1 }}": "x-true",
+		"{{a.b}}":               `["x",true]`,
+		"{{missing}}{{n}}!":     "!",
+		"{{$.a.b.5}}":           "",
+	} {
+		if got := renderWebhookTemplate(tmpl, root, root); got != want {
+			t.Fatalf("%s: got %q want %q", tmpl, got, want)
+		}
+	}
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/google/uuid"
 
+	"github.com/mycelian/mycelian-memory/server/internal/outbox/payload"
 	"github.com/mycelian/mycelian-memory/server/internal/storage"
 )
 
@@ -179,12 +180,12 @@ func (s *PostgresStorage) DeleteVault(ctx context.Context, actorID string, vault
 
 	// Enqueue outbox deletes (idempotent)
 	for _, id := range entryIDs {
-		if err := writeOutbox(ctx, tx, "delete_entry", id, map[string]interface{}{"actorId": actorID}); err != nil {
+		if err := writeOutbox(ctx, tx, id, payload.DeleteEntry(actorID)); err != nil {
 			return err
 		}
 	}
 	for _, id := range ctxIDs {
-		if err := writeOutbox(ctx, tx, "delete_context", id, map[string]interface{}{"actorId": actorID}); err != nil {
+		if err := writeOutbox(ctx, tx, id, payload.DeleteContext(actorID)); err != nil {
 			return err
 		}
 	}
@@ -232,14 +233,8 @@ func (s *PostgresStorage) CreateMemory(ctx context.Context, req storage.CreateMe
 	}
 
 	// outbox upsert_context
-	payload := map[string]interface{}{
-		"actorId":      mem.ActorID,
-		"memoryId":     mem.MemoryID,
-		"contextId":    ctxID,
-		"context":      defaultCtx,
-		"creationTime": ctxCreated,
-	}
-	if err := writeOutbox(ctx, tx, "upsert_context", ctxID, payload); err != nil {
+	cp := &payload.Context{ActorID: mem.ActorID, MemoryID: mem.MemoryID, ContextID: ctxID, Context: defaultCtx, CreationTime: ctxCreated}
+	if err := writeOutbox(ctx, tx, ctxID, cp); err != nil {
 		return nil, err
 	}
 
@@ -353,12 +348,12 @@ func (s *PostgresStorage) DeleteMemory(ctx context.Context, actorID string, vaul
 
 	// Enqueue outbox deletes (idempotent)
 	for _, id := range entryIDs {
-		if err := writeOutbox(ctx, tx, "delete_entry", id, map[string]interface{}{"actorId": actorID}); err != nil {
+		if err := writeOutbox(ctx, tx, id, payload.DeleteEntry(actorID)); err != nil {
 			return err
 		}
 	}
 	for _, id := range ctxIDs {
-		if err := writeOutbox(ctx, tx, "delete_context", id, map[string]interface{}{"actorId": actorID}); err != nil {
+		if err := writeOutbox(ctx, tx, id, payload.DeleteContext(actorID)); err != nil {
 			return err
 		}
 	}
@@ -388,16 +383,9 @@ func (s *PostgresStorage) CreateMemoryEntry(ctx context.Context, req storage.Cre
 		return nil, err
 	}
 
-	payload := map[string]interface{}{
-		"actorId":      req.ActorID,
-		"memoryId":     req.MemoryID,
-		"entryId":      entryID,
-		"rawEntry":     req.RawEntry,
-		"summary":      req.Summary,
-		"tags":         req.Tags,
-		"creationTime": creation,
-	}
-	if err := writeOutbox(ctx, tx, "upsert_entry", entryID, payload); err != nil {
+	ep := &payload.Entry{ActorID: req.ActorID, MemoryID: req.MemoryID, EntryID: entryID, RawEntry: req.RawEntry,
+		Summary: derefString(req.Summary), Tags: payload.TagKeys(req.Tags), TagPairs: payload.TagPairs(req.Tags), CreationTime: creation}
+	if err := writeOutbox(ctx, tx, entryID, ep); err != nil {
 		return nil, err
 	}
 
@@ -631,16 +619,9 @@ func (s *PostgresStorage) CorrectMemoryEntry(ctx context.Context, req storage.Co
 	}
 
 	// Outbox for correction entry upsert
-	payload := map[string]interface{}{
-		"actorId":      req.ActorID,
-		"memoryId":     req.MemoryID,
-		"entryId":      req.CorrectedEntryID,
-		"rawEntry":     req.CorrectedContent,
-		"summary":      req.CorrectedSummary,
-		"tags":         req.Tags,
-		"creationTime": created,
-	}
-	if err := writeOutbox(ctx, tx, "upsert_entry", req.CorrectedEntryID, payload); err != nil {
+	ep := &payload.Entry{ActorID: req.ActorID, MemoryID: req.MemoryID, EntryID: req.CorrectedEntryID, RawEntry: req.CorrectedContent,
+		Summary: derefString(req.CorrectedSummary), Tags: payload.TagKeys(req.Tags), TagPairs: payload.TagPairs(req.Tags), CreationTime: created}
+	if err := writeOutbox(ctx, tx, req.CorrectedEntryID, ep); err != nil {
 		return nil, err
 	}
 
@@ -689,7 +670,7 @@ func (s *PostgresStorage) DeleteMemoryEntryByID(ctx context.Context, actorID str
 		return err
 	}
 	if n, _ := res.RowsAffected(); n > 0 {
-		if err := writeOutbox(ctx, tx, "delete_entry", entryID, map[string]interface{}{"actorId": actorID}); err != nil {
+		if err := writeOutbox(ctx, tx, entryID, payload.DeleteEntry(actorID)); err != nil {
 			return err
 		}
 	}
@@ -721,14 +702,8 @@ func (s *PostgresStorage) CreateMemoryContext(ctx context.Context, req storage.C
 		return nil, err
 	}
 
-	payload := map[string]interface{}{
-		"actorId":      req.ActorID,
-		"memoryId":     req.MemoryID,
-		"contextId":    ctxID,
-		"context":      string(req.Context),
-		"creationTime": created,
-	}
-	if err := writeOutbox(ctx, tx, "upsert_context", ctxID, payload); err != nil {
+	cp := &payload.Context{ActorID: req.ActorID, MemoryID: req.MemoryID, ContextID: ctxID, Context: string(req.Context), CreationTime: created}
+	if err := writeOutbox(ctx, tx, ctxID, cp); err != nil {
 		return nil, err
 	}
 
@@ -767,7 +742,7 @@ func (s *PostgresStorage) DeleteMemoryContextByID(ctx context.Context, actorID s
 		return err
 	}
 	if n, _ := res.RowsAffected(); n > 0 {
-		if err := writeOutbox(ctx, tx, "delete_context", contextID, map[string]interface{}{"actorId": actorID}); err != nil {
+		if err := writeOutbox(ctx, tx, contextID, payload.DeleteContext(actorID)); err != nil {
 			return err
 		}
 	}
@@ -836,15 +811,22 @@ func (s *PostgresStorage) DeleteMemoryFromVault(ctx context.Context, req storage
 
 // --- helpers ---
 
-func writeOutbox(ctx context.Context, tx *sql.Tx, op string, aggregateID string, payload map[string]interface{}) error {
-	b, err := json.Marshal(payload)
+func writeOutbox(ctx context.Context, tx *sql.Tx, aggregateID string, p payload.Payload) error {
+	b, err := payload.Encode(p)
 	if err != nil {
 		return err
 	}
-	_, err = tx.ExecContext(ctx, `INSERT INTO outbox (aggregate_id, op, payload) VALUES ($1,$2,$3)`, aggregateID, op, b)
+	_, err = tx.ExecContext(ctx, `INSERT INTO outbox (aggregate_id, op, payload) VALUES ($1,$2,$3)`, aggregateID, p.Op(), b)
 	return err
 }
 
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
 func nullIfEmpty(b []byte) interface{} {
 	if len(b) == 0 {
 		return nil
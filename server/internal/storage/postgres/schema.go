@@ -0,0 +1,178 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mycelian/mycelian-memory/pkg/schema"
+)
+
+// Schema is the canonical PostgreSQL DDL (tables, indexes and the outbox),
+// pkg/schema's schema.sql. It is idempotent and safe to apply repeatedly.
+var Schema = schema.SQL
+
+// SchemaSpec is the set of tables, columns and indexes declared by a schema.
+type SchemaSpec struct {
+	Tables  map[string][]string // table -> columns in declaration order
+	Indexes []string
+}
+
+// SchemaDiff lists objects declared in the canonical schema that are missing
+// from a target database.
+type SchemaDiff struct {
+	MissingTables  []string
+	MissingColumns []string // "table.column"
+	MissingIndexes []string
+}
+
+// Empty reports whether the target database matches the canonical schema.
+func (d SchemaDiff) Empty() bool {
+	return len(d.MissingTables) == 0 && len(d.MissingColumns) == 0 && len(d.MissingIndexes) == 0
+}
+
+var (
+	createTableRe = regexp.MustCompile(`(?is)CREATE\s+TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?([a-z_][a-z0-9_]*)\s*\((.*?)\n\);`)
+	addColumnRe   = regexp.MustCompile(`(?i)ALTER\s+TABLE\s+(?:IF\s+EXISTS\s+)?([a-z_][a-z0-9_]*)\s+ADD\s+COLUMN\s+(?:IF\s+NOT\s+EXISTS\s+)?([a-z_][a-z0-9_]*)`)
+	createIndexRe = regexp.MustCompile(`(?i)CREATE\s+(?:UNIQUE\s+)?INDEX\s+(?:IF\s+NOT\s+EXISTS\s+)?([a-z_][a-z0-9_]*)\s+ON`)
+	columnRe      = regexp.MustCompile(`^([a-z_][a-z0-9_]*)\s+\S`)
+)
+
+// ParseSchema extracts the tables, columns and indexes declared in DDL.
+// It understands the subset of syntax used by schema.sql: one column or
+// table constraint per line, closing parenthesis on its own line, and
+// ALTER TABLE ... ADD COLUMN upgrades.
+func ParseSchema(ddl string) SchemaSpec {
+	spec := SchemaSpec{Tables: map[string][]string{}}
+	for _, m := range createTableRe.FindAllStringSubmatch(ddl, -1) {
+		table := strings.ToLower(m[1])
+		var cols []string
+		for _, line := range strings.Split(m[2], "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "--") {
+				continue
+			}
+			upper := strings.ToUpper(line)
+			if strings.HasPrefix(upper, "PRIMARY KEY") || strings.HasPrefix(upper, "UNIQUE") ||
+				strings.HasPrefix(upper, "CONSTRAINT") || strings.HasPrefix(upper, "CHECK") ||
+				strings.HasPrefix(upper, "FOREIGN KEY") {
+				continue
+			}
+			if cm := columnRe.FindStringSubmatch(line); cm != nil {
+				cols = append(cols, cm[1])
+			}
+		}
+		spec.Tables[table] = cols
+	}
+	for _, m := range addColumnRe.FindAllStringSubmatch(ddl, -1) {
+		table, col := strings.ToLower(m[1]), strings.ToLower(m[2])
+		present := false
+		for _, c := range spec.Tables[table] {
+			if c == col {
+				present = true
+				break
+			}
+		}
+		if !present {
+			spec.Tables[table] = append(spec.Tables[table], col)
+		}
+	}
+	for _, m := range createIndexRe.FindAllStringSubmatch(ddl, -1) {
+		spec.Indexes = append(spec.Indexes, strings.ToLower(m[1]))
+	}
+	return spec
+}
+
+// Diff compares the expected spec against the actual database layout.
+func (s SchemaSpec) Diff(actual SchemaSpec) SchemaDiff {
+	var d SchemaDiff
+	tables := make([]string, 0, len(s.Tables))
+	for t := range s.Tables {
+		tables = append(tables, t)
+	}
+	sort.Strings(tables)
+	for _, t := range tables {
+		have, ok := actual.Tables[t]
+		if !ok {
+			d.MissingTables = append(d.MissingTables, t)
+			continue
+		}
+		present := make(map[string]bool, len(have))
+		for _, c := range have {
+			present[c] = true
+		}
+		for _, c := range s.Tables[t] {
+			if !present[c] {
+				d.MissingColumns = append(d.MissingColumns, t+"."+c)
+			}
+		}
+	}
+	present := make(map[string]bool, len(actual.Indexes))
+	for _, i := range actual.Indexes {
+		present[i] = true
+	}
+	for _, i := range s.Indexes {
+		if !present[i] {
+			d.MissingIndexes = append(d.MissingIndexes, i)
+		}
+	}
+	return d
+}
+
+// ApplySchema executes the canonical schema against db.
+func ApplySchema(ctx context.Context, db *sql.DB) error {
+	if _, err := db.ExecContext(ctx, Schema); err != nil {
+		return fmt.Errorf("apply schema: %w", err)
+	}
+	return nil
+}
+
+// ValidateSchema reports objects from the canonical schema missing in db.
+func ValidateSchema(ctx context.Context, db *sql.DB) (SchemaDiff, error) {
+	actual, err := inspectSchema(ctx, db)
+	if err != nil {
+		return SchemaDiff{}, err
+	}
+	return ParseSchema(Schema).Diff(actual), nil
+}
+
+// inspectSchema reads tables, columns and indexes from the current schema.
+func inspectSchema(ctx context.Context, db *sql.DB) (SchemaSpec, error) {
+	spec := SchemaSpec{Tables: map[string][]string{}}
+
+	rows, err := db.QueryContext(ctx, `SELECT table_name, column_name FROM information_schema.columns
+		WHERE table_schema = current_schema() ORDER BY table_name, ordinal_position`)
+	if err != nil {
+		return spec, fmt.Errorf("inspect columns: %w", err)
+	}
+	for rows.Next() {
+		var table, col string
+		if err := rows.Scan(&table, &col); err != nil {
+			_ = rows.Close()
+			return spec, err
+		}
+		spec.Tables[table] = append(spec.Tables[table], col)
+	}
+	err = rows.Err()
+	_ = rows.Close()
+	if err != nil {
+		return spec, err
+	}
+
+	rows, err = db.QueryContext(ctx, `SELECT indexname FROM pg_indexes WHERE schemaname = current_schema()`)
+	if err != nil {
+		return spec, fmt.Errorf("inspect indexes: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return spec, err
+		}
+		spec.Indexes = append(spec.Indexes, name)
+	}
+	return spec, rows.Err()
+}
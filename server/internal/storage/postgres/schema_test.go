@@ -0,0 +1,71 @@
+package postgres
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseSchema_Canonical(t *testing.T) {
+	spec := ParseSchema(Schema)
+
+	for _, table := range []string{"vaults", "memories", "memory_entries", "memory_contexts", "outbox"} {
+		if _, ok := spec.Tables[table]; !ok {
+			t.Fatalf("expected table %s in canonical schema, got %v", table, spec.Tables)
+		}
+	}
+	wantOutbox := []string{"id", "aggregate_id", "op", "payload", "status", "attempt_count", "leased_until", "next_attempt_at", "creation_time", "update_time", "job_id", "last_error", "lease_owner"}
+	if !reflect.DeepEqual(spec.Tables["outbox"], wantOutbox) {
+		t.Fatalf("outbox columns mismatch:\nwant %v\ngot  %v", wantOutbox, spec.Tables["outbox"])
+	}
+	have := map[string]bool{}
+	for _, i := range spec.Indexes {
+		have[i] = true
+	}
+	for _, i := range []string{"memory_entries_entry_id_uq", "memory_entries_recent_idx", "outbox_ready_idx", "outbox_pending_memory_idx"} {
+		if !have[i] {
+			t.Fatalf("expected index %s in canonical schema, got %v", i, spec.Indexes)
+		}
+	}
+	// The outbox claim query looks up earlier pending rows of a memory by the
+	// payload's memoryId; the partial expression index must match it.
+	if !strings.Contains(Schema, "ON outbox((payload->>'memoryId'), id) WHERE status = 'pending';") {
+		t.Fatal("outbox_pending_memory_idx must index (payload->>'memoryId') of pending rows")
+	}
+}
+
+func TestParseSchema_AlterAddColumn(t *testing.T) {
+	spec := ParseSchema(`CREATE TABLE IF NOT EXISTS t (
+  a TEXT NOT NULL,
+  PRIMARY KEY (a)
+);
+ALTER TABLE t ADD COLUMN IF NOT EXISTS b TEXT;
+ALTER TABLE t ADD COLUMN IF NOT EXISTS a TEXT;
+`)
+	if !reflect.DeepEqual(spec.Tables["t"], []string{"a", "b"}) {
+		t.Fatalf("unexpected columns: %v", spec.Tables["t"])
+	}
+}
+
+func TestSchemaSpec_Diff(t *testing.T) {
+	want := SchemaSpec{
+		Tables:  map[string][]string{"a": {"x", "y"}, "b": {"z"}},
+		Indexes: []string{"a_idx", "b_idx"},
+	}
+	have := SchemaSpec{
+		Tables:  map[string][]string{"a": {"x"}},
+		Indexes: []string{"a_idx"},
+	}
+	d := want.Diff(have)
+	if d.Empty() {
+		t.Fatal("expected non-empty diff")
+	}
+	if !reflect.DeepEqual(d.MissingTables, []string{"b"}) ||
+		!reflect.DeepEqual(d.MissingColumns, []string{"a.y"}) ||
+		!reflect.DeepEqual(d.MissingIndexes, []string{"b_idx"}) {
+		t.Fatalf("unexpected diff: %+v", d)
+	}
+	if !want.Diff(want).Empty() {
+		t.Fatal("expected empty diff against itself")
+	}
+}
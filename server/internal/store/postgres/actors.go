@@ -0,0 +1,47 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+// --- Actor settings ---
+type actorSettings struct{ db *sql.DB }
+
+func (a *actorSettings) Get(ctx context.Context, actorID string) (*model.ActorSettings, error) {
+	return scanActorSettings(actorID, a.db.QueryRowContext(ctx, `SELECT `+actorSettingsColumns+` FROM actor_settings WHERE actor_id=$1`, actorID))
+}
+
+func (a *actorSettings) PutTimeZone(ctx context.Context, actorID, timeZone string) (*model.ActorSettings, error) {
+	return scanActorSettings(actorID, a.db.QueryRowContext(ctx, `
+        INSERT INTO actor_settings (actor_id, time_zone) VALUES ($1,$2)
+        ON CONFLICT (actor_id) DO UPDATE SET time_zone=EXCLUDED.time_zone, update_time=now()
+        RETURNING `+actorSettingsColumns, actorID, timeZone))
+}
+
+func (a *actorSettings) PutDefaults(ctx context.Context, actorID, vaultID, memoryID string) (*model.ActorSettings, error) {
+	return scanActorSettings(actorID, a.db.QueryRowContext(ctx, `
+        INSERT INTO actor_settings (actor_id, default_vault_id, default_memory_id) VALUES ($1,$2,$3)
+        ON CONFLICT (actor_id) DO UPDATE SET default_vault_id=EXCLUDED.default_vault_id,
+            default_memory_id=EXCLUDED.default_memory_id, update_time=now()
+        RETURNING `+actorSettingsColumns, actorID, nullString(vaultID), nullString(memoryID)))
+}
+
+const actorSettingsColumns = `time_zone, default_vault_id, default_memory_id, update_time`
+
+func scanActorSettings(actorID string, row *sql.Row) (*model.ActorSettings, error) {
+	out := model.ActorSettings{ActorID: actorID}
+	var vaultID, memoryID sql.NullString
+	err := row.Scan(&out.TimeZone, &vaultID, &memoryID, &out.UpdateTime)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, model.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	out.DefaultVaultID, out.DefaultMemoryID = vaultID.String, memoryID.String
+	return &out, nil
+}
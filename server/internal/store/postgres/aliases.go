@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+// --- Entity aliases ---
+type entityAliases struct{ db *sql.DB }
+
+// aliasKey is the case-insensitive key an alias is unique under.
+func aliasKey(alias string) string { return strings.ToLower(alias) }
+
+func (r *entityAliases) Put(ctx context.Context, a *model.EntityAlias) (*model.EntityAlias, error) {
+	out := *a
+	if err := r.db.QueryRowContext(ctx, `
+        INSERT INTO entity_aliases (actor_id, vault_id, memory_id, alias_key, alias, canonical)
+        VALUES ($1,$2,$3,$4,$5,$6)
+        ON CONFLICT (actor_id, memory_id, alias_key) DO UPDATE SET alias=EXCLUDED.alias, canonical=EXCLUDED.canonical, creation_time=now()
+        RETURNING creation_time
+    `, a.ActorID, a.VaultID, a.MemoryID, aliasKey(a.Alias), a.Alias, a.Canonical).Scan(&out.CreationTime); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *entityAliases) List(ctx context.Context, actorID, memoryID string) ([]*model.EntityAlias, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT vault_id, alias, canonical, creation_time FROM entity_aliases
+        WHERE actor_id=$1 AND memory_id=$2 ORDER BY lower(canonical), alias_key`, actorID, memoryID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var out []*model.EntityAlias
+	for rows.Next() {
+		a := model.EntityAlias{ActorID: actorID, MemoryID: memoryID}
+		if err := rows.Scan(&a.VaultID, &a.Alias, &a.Canonical, &a.CreationTime); err != nil {
+			return nil, err
+		}
+		out = append(out, &a)
+	}
+	return out, rows.Err()
+}
+
+func (r *entityAliases) Delete(ctx context.Context, actorID, vaultID, memoryID, alias string) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM entity_aliases WHERE actor_id=$1 AND vault_id=$2 AND memory_id=$3 AND alias_key=$4`,
+		actorID, vaultID, memoryID, aliasKey(alias))
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return model.ErrNotFound
+	}
+	return nil
+}
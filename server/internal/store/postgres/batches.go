@@ -0,0 +1,179 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/outbox/payload"
+)
+
+// --- Ingestion batches ---
+type ingestionBatches struct{ db *sql.DB }
+
+const batchColumns = `b.batch_id, b.actor_id, b.source_system, b.description, b.status, b.creation_time, b.rolled_back_time,
+               (SELECT COUNT(*) FROM memory_entries e WHERE e.actor_id=b.actor_id AND e.ingestion_batch_id=b.batch_id AND e.deleted_at IS NULL)`
+
+func scanBatch(row interface{ Scan(dest ...any) error }) (*model.IngestionBatch, error) {
+	var b model.IngestionBatch
+	var rolledBack sql.NullTime
+	if err := row.Scan(&b.BatchID, &b.ActorID, &b.SourceSystem, &b.Description, &b.Status, &b.CreationTime, &rolledBack, &b.EntryCount); err != nil {
+		return nil, err
+	}
+	if rolledBack.Valid {
+		t := rolledBack.Time
+		b.RolledBackTime = &t
+	}
+	return &b, nil
+}
+
+func (r *ingestionBatches) Create(ctx context.Context, b *model.IngestionBatch) (*model.IngestionBatch, error) {
+	out := *b
+	if out.BatchID == "" {
+		out.BatchID = uuid.New().String()
+	}
+	out.Status = model.IngestionBatchOpen
+	out.EntryCount = 0
+	out.RolledBackTime = nil
+	row := r.db.QueryRowContext(ctx, `
+        INSERT INTO ingestion_batches (actor_id, batch_id, source_system, description, status)
+        VALUES ($1,$2,$3,$4,$5)
+        ON CONFLICT (actor_id, batch_id) DO NOTHING
+        RETURNING creation_time
+    `, out.ActorID, out.BatchID, out.SourceSystem, out.Description, out.Status)
+	if err := row.Scan(&out.CreationTime); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: ingestion batch %s already exists", model.ErrConflict, out.BatchID)
+		}
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *ingestionBatches) Get(ctx context.Context, actorID, batchID string) (*model.IngestionBatch, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+batchColumns+`
+        FROM ingestion_batches b WHERE b.actor_id=$1 AND b.batch_id=$2`, actorID, batchID)
+	b, err := scanBatch(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, model.ErrNotFound
+	}
+	return b, err
+}
+
+func (r *ingestionBatches) List(ctx context.Context, actorID string) ([]*model.IngestionBatch, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+batchColumns+`
+        FROM ingestion_batches b WHERE b.actor_id=$1 ORDER BY b.creation_time DESC`, actorID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var out []*model.IngestionBatch
+	for rows.Next() {
+		b, err := scanBatch(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, b)
+	}
+	return out, rows.Err()
+}
+
+func (r *ingestionBatches) ListEntries(ctx context.Context, actorID, batchID string, limit int) ([]*model.MemoryEntry, error) {
+	query := `SELECT ` + entryColumns + `
+               FROM memory_entries WHERE actor_id=$1 AND ingestion_batch_id=$2 AND deleted_at IS NULL ORDER BY creation_time DESC`
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+	rows, err := r.db.QueryContext(ctx, query, actorID, batchID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var out []*model.MemoryEntry
+	for rows.Next() {
+		m, err := scanEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
+
+func (r *ingestionBatches) Rollback(ctx context.Context, actorID, batchID string) ([]string, error) {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// FOR UPDATE blocks concurrent entry inserts into this batch (they take FOR SHARE).
+	var status string
+	err = tx.QueryRowContext(ctx, `SELECT status FROM ingestion_batches WHERE actor_id=$1 AND batch_id=$2 FOR UPDATE`, actorID, batchID).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, model.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if status == model.IngestionBatchRolledBack {
+		return nil, fmt.Errorf("%w: ingestion batch %s already rolled back", model.ErrConflict, batchID)
+	}
+
+	var readOnly bool
+	if err := tx.QueryRowContext(ctx, `SELECT EXISTS (
+            SELECT 1 FROM memory_entries e JOIN vaults v ON v.actor_id=e.actor_id AND v.vault_id=e.vault_id
+            WHERE e.actor_id=$1 AND e.ingestion_batch_id=$2 AND v.read_only)`, actorID, batchID).Scan(&readOnly); err != nil {
+		return nil, err
+	}
+	if readOnly {
+		return nil, fmt.Errorf("%w: ingestion batch %s has entries in a read-only vault", model.ErrReadOnly, batchID)
+	}
+
+	var appendOnly bool
+	if err := tx.QueryRowContext(ctx, `SELECT EXISTS (
+            SELECT 1 FROM memory_entries e JOIN memories m ON m.actor_id=e.actor_id AND m.vault_id=e.vault_id AND m.memory_id=e.memory_id
+            WHERE e.actor_id=$1 AND e.ingestion_batch_id=$2 AND m.append_only)`, actorID, batchID).Scan(&appendOnly); err != nil {
+		return nil, err
+	}
+	if appendOnly {
+		return nil, fmt.Errorf("%w: ingestion batch %s has entries in an append-only memory", model.ErrAppendOnly, batchID)
+	}
+
+	rows, err := tx.QueryContext(ctx, `DELETE FROM memory_entries WHERE actor_id=$1 AND ingestion_batch_id=$2 RETURNING entry_id`, actorID, batchID)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	err = rows.Err()
+	_ = rows.Close()
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		if err := writeOutbox(ctx, tx, id, payload.DeleteEntry(actorID)); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE ingestion_batches SET status=$1, rolled_back_time=$2 WHERE actor_id=$3 AND batch_id=$4`,
+		model.IngestionBatchRolledBack, time.Now().UTC(), actorID, batchID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+func (v *vaults) Bootstrap(ctx context.Context, b model.Bootstrap) (*model.BootstrapResult, error) {
+	tx, err := v.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	vaultID, memoryID, contextID := uuid.New().String(), uuid.New().String(), uuid.New().String()
+	if b.ClientToken != "" {
+		hash, err := bootstrapHash(b)
+		if err != nil {
+			return nil, err
+		}
+		// A concurrent request with the same token waits here until the
+		// first one commits or rolls back.
+		res, err := tx.ExecContext(ctx, `
+            INSERT INTO bootstrap_tokens (actor_id, client_token, request_hash, vault_id, memory_id, context_id)
+            VALUES ($1,$2,$3,$4,$5,$6) ON CONFLICT (actor_id, client_token) DO NOTHING
+        `, b.ActorID, b.ClientToken, hash, vaultID, memoryID, contextID)
+		if err != nil {
+			return nil, err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			_ = tx.Rollback()
+			return v.replayBootstrap(ctx, b.ActorID, b.ClientToken, hash)
+		}
+	}
+
+	out := &model.BootstrapResult{
+		Vault:     &model.Vault{ActorID: b.ActorID, VaultID: vaultID, Title: b.Vault.Title, Slug: model.Slug(b.Vault.Title), Description: b.Vault.Description},
+		ContextID: contextID,
+	}
+	var created time.Time
+	if err := tx.QueryRowContext(ctx, `
+        INSERT INTO vaults (actor_id, vault_id, title, description, slug) VALUES ($1,$2,$3,$4,$5)
+        RETURNING creation_time
+    `, b.ActorID, vaultID, b.Vault.Title, b.Vault.Description, out.Vault.Slug).Scan(&created); err != nil {
+		return nil, titleConflict(err, "vault", b.Vault.Title)
+	}
+	out.Vault.CreationTime = created
+
+	first := b.Context
+	if first == "" {
+		first = defaultContext
+	}
+	m := b.Memory
+	m.ActorID, m.VaultID, m.MemoryID = b.ActorID, vaultID, memoryID
+	if out.Memory, err = insertMemory(ctx, tx, &m, contextID, first); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// replayBootstrap returns the result of the bootstrap that first used token.
+func (v *vaults) replayBootstrap(ctx context.Context, actorID, token, hash string) (*model.BootstrapResult, error) {
+	var storedHash, vaultID, memoryID string
+	out := &model.BootstrapResult{Replayed: true}
+	if err := v.db.QueryRowContext(ctx, `
+        SELECT request_hash, vault_id, memory_id, context_id FROM bootstrap_tokens WHERE actor_id=$1 AND client_token=$2
+    `, actorID, token).Scan(&storedHash, &vaultID, &memoryID, &out.ContextID); err != nil {
+		return nil, err
+	}
+	if storedHash != hash {
+		return nil, fmt.Errorf("%w: client token %q was already used with a different request", model.ErrConflict, token)
+	}
+	var err error
+	if out.Vault, err = v.GetByID(ctx, actorID, vaultID); err == nil {
+		out.Memory, err = (&memories{db: v.db}).GetByID(ctx, actorID, vaultID, memoryID)
+	}
+	if errors.Is(err, model.ErrNotFound) {
+		return nil, fmt.Errorf("%w: client token %q: the vault or memory it created was deleted", model.ErrConflict, token)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// bootstrapHash fingerprints what a bootstrap asks for, so a reused client
+// token can be told apart from a retry.
+func bootstrapHash(b model.Bootstrap) (string, error) {
+	raw, err := json.Marshal([]interface{}{
+		b.Vault.Title, b.Vault.Description,
+		b.Memory.MemoryType, b.Memory.Title, b.Memory.Description, b.Context,
+	})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,140 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+// cloneEntriesSQL copies a memory's entries, compressed text and quality
+// counters included, keeping their creation times. Corrections that point
+// into the source memory are repointed at the copy; ingestion batch links
+// are dropped so a rollback of the batch leaves the copy alone.
+const cloneEntriesSQL = `
+        INSERT INTO memory_entries (actor_id, vault_id, memory_id, creation_time, entry_id, raw_entry, summary,
+                                    metadata, tags, correction_time, corrected_entry_memory_id,
+                                    corrected_entry_creation_time, correction_reason, last_update_time,
+                                    source_system, source_id, useful_count, incorrect_count, outdated_count,
+                                    last_accessed_time, session_id, raw_entry_encoding, raw_entry_zstd,
+                                    llm_usage, conversation_time, expiration_time)
+        SELECT actor_id, $4, $5, creation_time, gen_random_uuid()::text, raw_entry, summary,
+               metadata, tags, correction_time,
+               CASE WHEN corrected_entry_memory_id = $3 THEN $5 ELSE corrected_entry_memory_id END,
+               corrected_entry_creation_time, correction_reason, last_update_time,
+               source_system, source_id, useful_count, incorrect_count, outdated_count,
+               last_accessed_time, session_id, raw_entry_encoding, raw_entry_zstd,
+               llm_usage, conversation_time, expiration_time
+        FROM memory_entries WHERE actor_id=$1 AND vault_id=$2 AND memory_id=$3 AND deleted_at IS NULL AND `+notExpired
+
+// cloneLatestContextSQL copies a memory's latest context.
+const cloneLatestContextSQL = `
+        INSERT INTO memory_contexts (actor_id, vault_id, memory_id, context_id, context, sections)
+        SELECT actor_id, $4, $5, $6, context, sections
+        FROM memory_contexts WHERE actor_id=$1 AND vault_id=$2 AND memory_id=$3
+        ORDER BY creation_time DESC LIMIT 1`
+
+func (v *vaults) Clone(ctx context.Context, c model.VaultClone) (*model.ClonedVault, error) {
+	tx, err := v.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	out := &model.ClonedVault{
+		Vault:         model.Vault{ActorID: c.ActorID, VaultID: uuid.New().String(), Title: c.Title, Slug: model.Slug(c.Title)},
+		SourceVaultID: c.SourceVaultID,
+		Memories:      []*model.ClonedMemory{},
+		Reindex:       c.Reindex,
+	}
+	// The share lock keeps the source vault from being deleted mid-copy.
+	err = tx.QueryRowContext(ctx, `SELECT description FROM vaults WHERE actor_id=$1 AND vault_id=$2 FOR SHARE`,
+		c.ActorID, c.SourceVaultID).Scan(&out.Description)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: vault %s", model.ErrNotFound, c.SourceVaultID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.QueryRowContext(ctx, `
+        INSERT INTO vaults (actor_id, vault_id, title, description, slug) VALUES ($1,$2,$3,$4,$5)
+        RETURNING creation_time
+    `, c.ActorID, out.VaultID, c.Title, out.Description, out.Slug).Scan(&out.CreationTime); err != nil {
+		return nil, titleConflict(err, "vault", c.Title)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+        SELECT memory_id, memory_type, title, COALESCE(slug, ''), description, append_only, entry_roles, search_boost, entry_ttl_seconds
+        FROM memories WHERE actor_id=$1 AND vault_id=$2 AND deleted_at IS NULL ORDER BY creation_time
+    `, c.ActorID, c.SourceVaultID)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		m := &model.ClonedMemory{Memory: model.Memory{ActorID: c.ActorID, VaultID: out.VaultID, MemoryID: uuid.New().String()}}
+		var roles, boost sql.NullString
+		if err := rows.Scan(&m.SourceMemoryID, &m.MemoryType, &m.Title, &m.Slug, &m.Description, &m.AppendOnly, &roles, &boost, &m.EntryTTLSeconds); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		m.EntryRoles = decodeEntryRoles(roles)
+		m.SearchBoost = decodeSearchBoost(boost)
+		out.Memories = append(out.Memories, m)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, m := range out.Memories {
+		if err := cloneMemory(ctx, tx, c, m); err != nil {
+			return nil, fmt.Errorf("clone memory %s: %w", m.SourceMemoryID, err)
+		}
+		out.EntryCount += m.EntryCount
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// cloneMemory creates m in the clone's vault with the entries and latest
+// context of m.SourceMemoryID, and enqueues their index upserts as a reindex
+// job when c.Reindex is set.
+func cloneMemory(ctx context.Context, tx *sql.Tx, c model.VaultClone, m *model.ClonedMemory) error {
+	if err := tx.QueryRowContext(ctx, `
+        INSERT INTO memories (actor_id, vault_id, memory_id, memory_type, title, description, append_only, entry_roles, slug, search_boost, entry_ttl_seconds)
+        VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
+        RETURNING creation_time
+    `, m.ActorID, m.VaultID, m.MemoryID, m.MemoryType, m.Title, m.Description, m.AppendOnly, entryRolesJSON(m.EntryRoles), nullString(m.Slug), searchBoostJSON(m.SearchBoost), m.EntryTTLSeconds).Scan(&m.CreationTime); err != nil {
+		return err
+	}
+	res, err := tx.ExecContext(ctx, cloneEntriesSQL, c.ActorID, c.SourceVaultID, m.SourceMemoryID, m.VaultID, m.MemoryID)
+	if err != nil {
+		return err
+	}
+	n, _ := res.RowsAffected()
+	m.EntryCount = int(n)
+	res, err = tx.ExecContext(ctx, cloneLatestContextSQL, c.ActorID, c.SourceVaultID, m.SourceMemoryID, m.VaultID, m.MemoryID, uuid.New().String())
+	if err != nil {
+		return err
+	}
+	n, _ = res.RowsAffected()
+	m.ContextCopied = n > 0
+
+	if !c.Reindex {
+		return nil
+	}
+	job := model.ReindexJob{JobID: uuid.New().String(), ActorID: m.ActorID, VaultID: m.VaultID, MemoryID: m.MemoryID}
+	if err := enqueueReindex(ctx, tx, &job); err != nil {
+		return err
+	}
+	m.ReindexJobID = job.JobID
+	return nil
+}
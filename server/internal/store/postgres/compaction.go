@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/outbox/payload"
+)
+
+func (c *contexts) CompactionCandidates(ctx context.Context, cutoff time.Time, after model.MemoryRef, limit int) ([]model.MemoryRef, error) {
+	rows, err := c.db.QueryContext(ctx, `
+        SELECT actor_id, vault_id, memory_id FROM memory_contexts
+        WHERE creation_time < $1 AND (actor_id, vault_id, memory_id) > ($2, $3, $4)
+        GROUP BY actor_id, vault_id, memory_id
+        HAVING count(*) > 1
+        ORDER BY actor_id, vault_id, memory_id
+        LIMIT $5
+    `, cutoff, after.ActorID, after.VaultID, after.MemoryID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var out []model.MemoryRef
+	for rows.Next() {
+		var r model.MemoryRef
+		if err := rows.Scan(&r.ActorID, &r.VaultID, &r.MemoryID); err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+func (c *contexts) Snapshots(ctx context.Context, ref model.MemoryRef, before time.Time) ([]model.ContextSnapshot, error) {
+	rows, err := c.db.QueryContext(ctx, `
+        SELECT context_id, creation_time FROM memory_contexts
+        WHERE actor_id=$1 AND vault_id=$2 AND memory_id=$3 AND creation_time < $4
+        ORDER BY creation_time DESC
+    `, ref.ActorID, ref.VaultID, ref.MemoryID, before)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var out []model.ContextSnapshot
+	for rows.Next() {
+		var s model.ContextSnapshot
+		if err := rows.Scan(&s.ContextID, &s.CreationTime); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+func (c *contexts) DeleteMany(ctx context.Context, ref model.MemoryRef, contextIDs []string) (int, error) {
+	if len(contextIDs) == 0 {
+		return 0, nil
+	}
+	tx, err := c.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	rows, err := tx.QueryContext(ctx, `
+        DELETE FROM memory_contexts
+        WHERE actor_id=$1 AND vault_id=$2 AND memory_id=$3 AND context_id = ANY($4)
+        RETURNING context_id
+    `, ref.ActorID, ref.VaultID, ref.MemoryID, contextIDs)
+	if err != nil {
+		return 0, err
+	}
+	var deleted []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			_ = rows.Close()
+			return 0, err
+		}
+		deleted = append(deleted, id)
+	}
+	if err := rows.Err(); err != nil {
+		_ = rows.Close()
+		return 0, err
+	}
+	_ = rows.Close()
+	for _, id := range deleted {
+		if err := writeOutbox(ctx, tx, id, payload.DeleteContext(ref.ActorID)); err != nil {
+			return 0, err
+		}
+	}
+	return len(deleted), tx.Commit()
+}
@@ -0,0 +1,96 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+// encodingZstd marks a memory_entries row whose text is in raw_entry_zstd;
+// raw_entry is then empty. A NULL raw_entry_encoding means plain text.
+const encodingZstd = "zstd"
+
+// Option configures a store built by NewWithDB.
+type Option func(*pgStore)
+
+// WithEntryCompression stores rawEntry bodies of at least minBytes bytes
+// zstd-compressed; 0 disables compression. Reads decompress transparently,
+// so rows written either way can be mixed.
+func WithEntryCompression(minBytes int) Option {
+	return func(s *pgStore) { s.compressMin = minBytes }
+}
+
+// The encoder and decoder are safe for concurrent EncodeAll/DecodeAll calls.
+var (
+	zstdEncoder, _ = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedDefault))
+	zstdDecoder, _ = zstd.NewReader(nil, zstd.WithDecoderConcurrency(0))
+)
+
+// encodeRawEntry returns the raw_entry, raw_entry_encoding and raw_entry_zstd
+// values to store for raw. Bodies below minBytes, or that do not shrink, stay
+// plain text.
+func encodeRawEntry(raw string, minBytes int) (string, sql.NullString, []byte) {
+	if minBytes <= 0 || len(raw) < minBytes {
+		return raw, sql.NullString{}, nil
+	}
+	blob := zstdEncoder.EncodeAll([]byte(raw), nil)
+	if len(blob) >= len(raw) {
+		return raw, sql.NullString{}, nil
+	}
+	return "", sql.NullString{String: encodingZstd, Valid: true}, blob
+}
+
+// decodeRawEntry reverses encodeRawEntry.
+func decodeRawEntry(raw string, encoding sql.NullString, blob []byte) (string, error) {
+	if !encoding.Valid {
+		return raw, nil
+	}
+	if encoding.String != encodingZstd {
+		return "", fmt.Errorf("raw entry: unknown encoding %q", encoding.String)
+	}
+	text, err := zstdDecoder.DecodeAll(blob, nil)
+	if err != nil {
+		return "", fmt.Errorf("raw entry: %w", err)
+	}
+	return string(text), nil
+}
+
+// scanMatcher applies a scan's text filters to compressed entries, whose
+// text the SQL filter cannot read. Regex uses Go's RE2 syntax here; a
+// pattern RE2 rejects matches no compressed entry.
+type scanMatcher struct {
+	contains string
+	re       *regexp.Regexp
+	badRegex bool
+}
+
+func newScanMatcher(req model.ScanEntriesRequest) *scanMatcher {
+	m := &scanMatcher{contains: strings.ToLower(req.Contains)}
+	if req.Regex != "" {
+		re, err := regexp.Compile(req.Regex)
+		m.re, m.badRegex = re, err != nil
+	}
+	return m
+}
+
+func (m *scanMatcher) match(e *model.MemoryEntry) bool {
+	summary := ""
+	if e.Summary != nil {
+		summary = *e.Summary
+	}
+	if m.contains != "" && !strings.Contains(strings.ToLower(e.RawEntry), m.contains) && !strings.Contains(strings.ToLower(summary), m.contains) {
+		return false
+	}
+	if m.badRegex {
+		return false
+	}
+	if m.re != nil && !m.re.MatchString(e.RawEntry) && !m.re.MatchString(summary) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,58 @@
+package postgres
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+func TestEncodeRawEntry(t *testing.T) {
+	long := strings.Repeat("the agent repeated the same tool call output. ", 200)
+
+	raw, encoding, blob := encodeRawEntry(long, 1024)
+	if raw != "" || encoding.String != encodingZstd || len(blob) == 0 || len(blob) >= len(long)/10 {
+		t.Fatalf("long entry: raw=%d bytes encoding=%v blob=%d bytes", len(raw), encoding, len(blob))
+	}
+	if got, err := decodeRawEntry(raw, encoding, blob); err != nil || got != long {
+		t.Fatalf("round trip: err=%v equal=%v", err, got == long)
+	}
+
+	for _, c := range []struct {
+		name string
+		text string
+		min  int
+	}{
+		{"disabled", long, 0},
+		{"below threshold", "short note", 1024},
+		{"incompressible", "x", 1},
+	} {
+		raw, encoding, blob := encodeRawEntry(c.text, c.min)
+		if raw != c.text || encoding.Valid || blob != nil {
+			t.Fatalf("%s: expected plain text, got encoding=%v", c.name, encoding)
+		}
+		if got, _ := decodeRawEntry(raw, encoding, blob); got != c.text {
+			t.Fatalf("%s: decode = %q", c.name, got)
+		}
+	}
+}
+
+func TestScanMatcher(t *testing.T) {
+	summary := "Travel plans"
+	e := &model.MemoryEntry{RawEntry: "Booked a Flight to Lisbon", Summary: &summary}
+	for _, c := range []struct {
+		req  model.ScanEntriesRequest
+		want bool
+	}{
+		{model.ScanEntriesRequest{Contains: "flight"}, true},
+		{model.ScanEntriesRequest{Contains: "TRAVEL"}, true},
+		{model.ScanEntriesRequest{Contains: "hotel"}, false},
+		{model.ScanEntriesRequest{Regex: "Lis(bon|boa)$"}, true},
+		{model.ScanEntriesRequest{Contains: "flight", Regex: "^Hotel"}, false},
+		{model.ScanEntriesRequest{Regex: `(?<=a)b`}, false}, // not RE2
+	} {
+		if got := newScanMatcher(c.req).match(e); got != c.want {
+			t.Fatalf("%+v: match = %v, want %v", c.req, got, c.want)
+		}
+	}
+}
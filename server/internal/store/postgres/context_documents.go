@@ -0,0 +1,126 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+// --- Context documents ---
+type contextDocuments struct{ db *sql.DB }
+
+const contextDocumentColumns = `document_id, actor_id, vault_id, memory_id, status, COALESCE(size_bytes, 0), COALESCE(sha256, ''), creation_time, completion_time`
+
+func scanContextDocument(row interface{ Scan(...any) error }) (*model.ContextDocument, error) {
+	var d model.ContextDocument
+	var completed sql.NullTime
+	if err := row.Scan(&d.DocumentID, &d.ActorID, &d.VaultID, &d.MemoryID, &d.Status, &d.SizeBytes, &d.SHA256, &d.CreationTime, &completed); err != nil {
+		return nil, err
+	}
+	if completed.Valid {
+		d.CompletionTime = &completed.Time
+	}
+	return &d, nil
+}
+
+func (r *contextDocuments) Create(ctx context.Context, d *model.ContextDocument) (*model.ContextDocument, error) {
+	row := r.db.QueryRowContext(ctx, `
+        INSERT INTO context_documents (actor_id, vault_id, memory_id, document_id, status)
+        VALUES ($1,$2,$3,$4,$5)
+        RETURNING `+contextDocumentColumns,
+		d.ActorID, d.VaultID, d.MemoryID, uuid.New().String(), model.ContextDocumentUploading)
+	return scanContextDocument(row)
+}
+
+func (r *contextDocuments) Get(ctx context.Context, actorID, vaultID, memoryID, documentID string) (*model.ContextDocument, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+contextDocumentColumns+`
+        FROM context_documents WHERE actor_id=$1 AND vault_id=$2 AND memory_id=$3 AND document_id=$4`,
+		actorID, vaultID, memoryID, documentID)
+	d, err := scanContextDocument(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, model.ErrNotFound
+	}
+	return d, err
+}
+
+func (r *contextDocuments) PutPart(ctx context.Context, actorID, documentID string, n int, data []byte) error {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// FOR SHARE keeps Complete from finishing the document mid-upload.
+	var status string
+	err = tx.QueryRowContext(ctx, `SELECT status FROM context_documents WHERE actor_id=$1 AND document_id=$2 FOR SHARE`, actorID, documentID).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if status != model.ContextDocumentUploading {
+		return fmt.Errorf("%w: context document %s is %s", model.ErrConflict, documentID, status)
+	}
+	if _, err := tx.ExecContext(ctx, `
+        INSERT INTO context_document_parts (actor_id, document_id, part_no, data) VALUES ($1,$2,$3,$4)
+        ON CONFLICT (actor_id, document_id, part_no) DO UPDATE SET data=EXCLUDED.data`,
+		actorID, documentID, n, data); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *contextDocuments) Parts(ctx context.Context, actorID, documentID string) ([]model.ContextDocumentPart, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT part_no, data FROM context_document_parts
+        WHERE actor_id=$1 AND document_id=$2 ORDER BY part_no`, actorID, documentID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var out []model.ContextDocumentPart
+	for rows.Next() {
+		var p model.ContextDocumentPart
+		if err := rows.Scan(&p.Number, &p.Data); err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+func (r *contextDocuments) Complete(ctx context.Context, actorID, documentID string, sizeBytes int64, sha256 string) (*model.ContextDocument, error) {
+	row := r.db.QueryRowContext(ctx, `
+        UPDATE context_documents SET status=$3, size_bytes=$4, sha256=$5, completion_time=now()
+        WHERE actor_id=$1 AND document_id=$2 AND status=$6
+        RETURNING `+contextDocumentColumns,
+		actorID, documentID, model.ContextDocumentComplete, sizeBytes, sha256, model.ContextDocumentUploading)
+	d, err := scanContextDocument(row)
+	if !errors.Is(err, sql.ErrNoRows) {
+		return d, err
+	}
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM context_documents WHERE actor_id=$1 AND document_id=$2)`, actorID, documentID).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, model.ErrNotFound
+	}
+	return nil, fmt.Errorf("%w: context document %s is already complete", model.ErrConflict, documentID)
+}
+
+// deleteContextDocuments removes the documents matching where (over
+// context_documents, with args) together with their parts.
+func deleteContextDocuments(ctx context.Context, tx *sql.Tx, where string, args ...any) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM context_document_parts WHERE (actor_id, document_id) IN (
+            SELECT actor_id, document_id FROM context_documents WHERE `+where+`)`, args...); err != nil {
+		return err
+	}
+	_, err := tx.ExecContext(ctx, `DELETE FROM context_documents WHERE `+where, args...)
+	return err
+}
@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+// maxCorrectionDepth bounds how far Corrections follows a correction chain.
+const maxCorrectionDepth = 16
+
+// correctionRow scans a row selected with entryColumns plus trailing
+// start_id and depth columns.
+type correctionRow struct {
+	row     interface{ Scan(dest ...any) error }
+	startID *string
+	depth   *int
+}
+
+func (r correctionRow) Scan(dest ...any) error {
+	return r.row.Scan(append(dest, r.startID, r.depth)...)
+}
+
+func (e *entries) Correct(ctx context.Context, entryID string, correction *model.MemoryEntry, reason string) (*model.MemoryEntry, error) {
+	tx, err := e.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var corrected sql.NullTime
+	err = tx.QueryRowContext(ctx, `
+        SELECT correction_time FROM memory_entries
+        WHERE actor_id=$1 AND vault_id=$2 AND memory_id=$3 AND entry_id=$4 AND deleted_at IS NULL
+        FOR UPDATE
+    `, correction.ActorID, correction.VaultID, correction.MemoryID, entryID).Scan(&corrected)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, model.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if corrected.Valid {
+		return nil, fmt.Errorf("%w: entry %s is already corrected", model.ErrConflict, entryID)
+	}
+
+	memoryTitle, vaultTitle, err := indexTitles(ctx, tx, correction.ActorID, correction.MemoryID)
+	if err != nil {
+		return nil, err
+	}
+	created, err := e.insert(ctx, tx, correction, memoryTitle, vaultTitle)
+	if err != nil {
+		return nil, err
+	}
+	if created.EntryID == entryID {
+		return nil, fmt.Errorf("%w: an entry cannot correct itself", model.ErrValidation)
+	}
+	if _, err := tx.ExecContext(ctx, `
+        UPDATE memory_entries
+        SET correction_time=now(), corrected_entry_memory_id=$5, corrected_entry_creation_time=$6,
+            correction_reason=$7, last_update_time=now()
+        WHERE actor_id=$1 AND vault_id=$2 AND memory_id=$3 AND entry_id=$4
+    `, correction.ActorID, correction.VaultID, correction.MemoryID, entryID,
+		created.MemoryID, created.CreationTime, nullString(reason)); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (e *entries) Corrections(ctx context.Context, userID string, entryIDs []string) (map[string]*model.MemoryEntry, error) {
+	out := map[string]*model.MemoryEntry{}
+	if len(entryIDs) == 0 {
+		return out, nil
+	}
+	// Each chain starts at a listed corrected entry and follows the
+	// corrections still live within its vault; its deepest node is the
+	// current version.
+	rows, err := e.db.QueryContext(ctx, `
+        WITH RECURSIVE chain AS (
+            SELECT entry_id AS start_id, entry_id AS node_id, vault_id AS node_vault,
+                   corrected_entry_memory_id AS next_mem, corrected_entry_creation_time AS next_time, 0 AS depth
+            FROM memory_entries
+            WHERE actor_id=$1 AND entry_id = ANY($2) AND correction_time IS NOT NULL
+          UNION ALL
+            SELECT c.start_id, n.entry_id, n.vault_id, n.corrected_entry_memory_id, n.corrected_entry_creation_time, c.depth+1
+            FROM chain c
+            JOIN memory_entries n ON n.actor_id=$1 AND n.vault_id=c.node_vault AND n.memory_id=c.next_mem AND n.creation_time=c.next_time
+            WHERE c.depth < $3 AND n.deleted_at IS NULL AND (n.expiration_time IS NULL OR n.expiration_time > now())
+        )
+        SELECT DISTINCT ON (start_id) `+entryColumns+`, start_id, depth
+        FROM chain JOIN memory_entries ON actor_id=$1 AND vault_id=node_vault AND entry_id=node_id
+        ORDER BY start_id, depth DESC
+    `, userID, entryIDs, maxCorrectionDepth)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var startID string
+		var depth int
+		me, err := scanEntry(correctionRow{row: rows, startID: &startID, depth: &depth})
+		if err != nil {
+			return nil, err
+		}
+		if depth == 0 {
+			me = nil // every correction is trashed or expired
+		}
+		out[startID] = me
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,75 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+// --- Index status ---
+type indexing struct{ db *sql.DB }
+
+func (ix *indexing) Status(ctx context.Context, actorID string, ids []string) (map[string]model.IndexStatus, error) {
+	out := make(map[string]model.IndexStatus, len(ids))
+	for _, id := range ids {
+		out[id] = model.IndexStatus{State: model.IndexStateUnknown}
+	}
+	if len(ids) == 0 {
+		return out, nil
+	}
+	rows, err := ix.db.QueryContext(ctx, `
+        SELECT DISTINCT ON (aggregate_id) aggregate_id, status, attempt_count, COALESCE(last_error, ''), update_time
+        FROM outbox
+        WHERE aggregate_id = ANY($1) AND op IN ('upsert_entry','upsert_context') AND payload->>'actorId' = $2
+        ORDER BY aggregate_id, id DESC
+    `, ids, actorID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var (
+			id, status string
+			st         model.IndexStatus
+			updated    time.Time
+		)
+		if err := rows.Scan(&id, &status, &st.Attempts, &st.LastError, &updated); err != nil {
+			return nil, err
+		}
+		switch status {
+		case "done":
+			st.State = model.IndexStateIndexed
+		case "dead":
+			st.State = model.IndexStateFailed
+		default:
+			st.State = model.IndexStatePending
+		}
+		st.UpdateTime = &updated
+		out[id] = st
+	}
+	return out, rows.Err()
+}
+
+func (ix *indexing) Retry(ctx context.Context, actorID, vaultID, memoryID string, ids []string) (int, error) {
+	res, err := ix.db.ExecContext(ctx, `
+        WITH items AS (
+            SELECT entry_id AS id FROM memory_entries WHERE actor_id=$1 AND vault_id=$2 AND memory_id=$3
+            UNION ALL
+            SELECT context_id FROM memory_contexts WHERE actor_id=$1 AND vault_id=$2 AND memory_id=$3
+        ), latest AS (
+            SELECT DISTINCT ON (o.aggregate_id) o.id, o.status
+            FROM outbox o JOIN items i ON o.aggregate_id = i.id
+            WHERE o.op IN ('upsert_entry','upsert_context') AND (COALESCE(cardinality($4::text[]), 0) = 0 OR o.aggregate_id = ANY($4))
+            ORDER BY o.aggregate_id, o.id DESC
+        )
+        UPDATE outbox SET status='pending', attempt_count=0, next_attempt_at=now(), update_time=now()
+        WHERE id IN (SELECT id FROM latest WHERE status='dead')
+    `, actorID, vaultID, memoryID, ids)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
@@ -0,0 +1,115 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+// --- Background jobs ---
+type jobs struct{ db *sql.DB }
+
+const jobColumns = `job_id, actor_id, kind, COALESCE(vault_id, ''), COALESCE(memory_id, ''), params, status, done, total,
+               COALESCE(error, ''), cancel_requested, creation_time, update_time, finish_time`
+
+func scanJob(row interface{ Scan(dest ...any) error }) (*model.Job, error) {
+	var j model.Job
+	var params []byte
+	var finished sql.NullTime
+	if err := row.Scan(&j.JobID, &j.ActorID, &j.Kind, &j.VaultID, &j.MemoryID, &params, &j.Status, &j.Done, &j.Total,
+		&j.Error, &j.CancelRequested, &j.CreationTime, &j.UpdateTime, &finished); err != nil {
+		return nil, err
+	}
+	j.Params = params
+	if finished.Valid {
+		j.FinishTime = &finished.Time
+	}
+	return &j, nil
+}
+
+func (r *jobs) Create(ctx context.Context, j *model.Job) (*model.Job, error) {
+	id := j.JobID
+	if id == "" {
+		id = uuid.New().String()
+	}
+	var params any
+	if len(j.Params) > 0 {
+		params = []byte(j.Params)
+	}
+	return scanJob(r.db.QueryRowContext(ctx, `
+        INSERT INTO jobs (job_id, actor_id, kind, vault_id, memory_id, params)
+        VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), $6)
+        RETURNING `+jobColumns,
+		id, j.ActorID, j.Kind, j.VaultID, j.MemoryID, params))
+}
+
+func (r *jobs) Get(ctx context.Context, actorID, jobID string) (*model.Job, error) {
+	j, err := scanJob(r.db.QueryRowContext(ctx, `SELECT `+jobColumns+` FROM jobs WHERE actor_id=$1 AND job_id=$2`, actorID, jobID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: job %s", model.ErrNotFound, jobID)
+	}
+	return j, err
+}
+
+func (r *jobs) Claim(ctx context.Context, staleBefore time.Time) (*model.Job, error) {
+	j, err := scanJob(r.db.QueryRowContext(ctx, `
+        WITH next AS (
+            SELECT job_id FROM jobs
+            WHERE status='queued' OR (status='running' AND update_time < $1)
+            ORDER BY creation_time
+            LIMIT 1
+            FOR UPDATE SKIP LOCKED
+        )
+        UPDATE jobs j SET status='running', update_time=now()
+        FROM next WHERE j.job_id = next.job_id
+        RETURNING `+jobColumns, staleBefore))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return j, err
+}
+
+func (r *jobs) Progress(ctx context.Context, jobID string, done, total int) (bool, error) {
+	var cancel bool
+	err := r.db.QueryRowContext(ctx, `
+        UPDATE jobs SET done=$2, total=$3, update_time=now()
+        WHERE job_id=$1
+        RETURNING cancel_requested
+    `, jobID, done, total).Scan(&cancel)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, fmt.Errorf("%w: job %s", model.ErrNotFound, jobID)
+	}
+	return cancel, err
+}
+
+func (r *jobs) Finish(ctx context.Context, jobID, status, errMsg string) error {
+	_, err := r.db.ExecContext(ctx, `
+        UPDATE jobs SET status=$2, error=NULLIF($3, ''), update_time=now(), finish_time=now()
+        WHERE job_id=$1 AND status='running'
+    `, jobID, status, errMsg)
+	return err
+}
+
+func (r *jobs) Cancel(ctx context.Context, actorID, jobID string) (*model.Job, error) {
+	j, err := scanJob(r.db.QueryRowContext(ctx, `
+        UPDATE jobs SET
+            status = CASE WHEN status='queued' THEN 'cancelled' ELSE status END,
+            finish_time = CASE WHEN status='queued' THEN now() ELSE finish_time END,
+            cancel_requested = TRUE,
+            update_time = CASE WHEN status='queued' THEN now() ELSE update_time END
+        WHERE actor_id=$1 AND job_id=$2 AND status IN ('queued', 'running')
+        RETURNING `+jobColumns, actorID, jobID))
+	if !errors.Is(err, sql.ErrNoRows) {
+		return j, err
+	}
+	if _, err := r.Get(ctx, actorID, jobID); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("%w: job %s already finished", model.ErrConflict, jobID)
+}
@@ -6,12 +6,15 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
 	_ "github.com/jackc/pgx/v5/stdlib"
 
 	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/outbox/payload"
 	"github.com/mycelian/mycelian-memory/server/internal/store"
 )
 
@@ -32,15 +35,38 @@ func Open(dsn string) (*sql.DB, error) {
 }
 
 // NewWithDB constructs a native Postgres store backed directly by database/sql.
-func NewWithDB(db *sql.DB) store.Store { return &pgStore{db: db} }
+func NewWithDB(db *sql.DB, opts ...Option) store.Store {
+	s := &pgStore{db: db}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
 
-type pgStore struct{ db *sql.DB }
+type pgStore struct {
+	db          *sql.DB
+	compressMin int // see WithEntryCompression
+}
 
 func (s *pgStore) Users() store.Users       { return &users{db: s.db} }
 func (s *pgStore) Vaults() store.Vaults     { return &vaults{db: s.db} }
 func (s *pgStore) Memories() store.Memories { return &memories{db: s.db} }
-func (s *pgStore) Entries() store.Entries   { return &entries{db: s.db} }
+func (s *pgStore) Entries() store.Entries   { return &entries{db: s.db, compressMin: s.compressMin} }
 func (s *pgStore) Contexts() store.Contexts { return &contexts{db: s.db} }
+func (s *pgStore) ContextDocuments() store.ContextDocuments {
+	return &contextDocuments{db: s.db}
+}
+func (s *pgStore) EntityAliases() store.EntityAliases { return &entityAliases{db: s.db} }
+func (s *pgStore) Webhooks() store.Webhooks           { return &webhooks{db: s.db} }
+func (s *pgStore) SearchLog() store.SearchLog         { return &searchLog{db: s.db} }
+func (s *pgStore) IngestionBatches() store.IngestionBatches {
+	return &ingestionBatches{db: s.db}
+}
+func (s *pgStore) ActorSettings() store.ActorSettings { return &actorSettings{db: s.db} }
+func (s *pgStore) Reindex() store.Reindex             { return &reindex{db: s.db} }
+func (s *pgStore) Reembed() store.Reembed             { return &reembed{db: s.db} }
+func (s *pgStore) Indexing() store.Indexing           { return &indexing{db: s.db} }
+func (s *pgStore) Jobs() store.Jobs                   { return &jobs{db: s.db} }
 
 // HealthPing implements health.HealthPinger for Postgres-backed store.
 func (s *pgStore) HealthPing(ctx context.Context) error {
@@ -110,15 +136,16 @@ func (v *vaults) Create(ctx context.Context, mv *model.Vault) (*model.Vault, err
 		id = uuid.New().String()
 	}
 	var created time.Time
+	slug := model.Slug(mv.Title)
 	row := v.db.QueryRowContext(ctx, `
-        INSERT INTO vaults (actor_id, vault_id, title, description)
-        VALUES ($1,$2,$3,$4)
+        INSERT INTO vaults (actor_id, vault_id, title, description, slug)
+        VALUES ($1,$2,$3,$4,$5)
         RETURNING creation_time
-    `, mv.ActorID, id, mv.Title, nil)
+    `, mv.ActorID, id, mv.Title, mv.Description, slug)
 	if err := row.Scan(&created); err != nil {
-		return nil, err
+		return nil, titleConflict(err, "vault", mv.Title)
 	}
-	return &model.Vault{VaultID: id, ActorID: mv.ActorID, Title: mv.Title, CreationTime: created}, nil
+	return &model.Vault{VaultID: id, ActorID: mv.ActorID, Title: mv.Title, Slug: slug, Description: mv.Description, CreationTime: created}, nil
 }
 
 func (v *vaults) GetByID(ctx context.Context, userID, vaultID string) (*model.Vault, error) {
@@ -126,27 +153,32 @@ func (v *vaults) GetByID(ctx context.Context, userID, vaultID string) (*model.Va
 	out.ActorID = userID
 	out.VaultID = vaultID
 	row := v.db.QueryRowContext(ctx, `
-        SELECT title, description, creation_time FROM vaults WHERE actor_id=$1 AND vault_id=$2
+        SELECT title, COALESCE(slug, ''), description, creation_time, read_only FROM vaults WHERE actor_id=$1 AND vault_id=$2
     `, userID, vaultID)
 	var created time.Time
-	var desc *string
-	if err := row.Scan(&out.Title, &desc, &created); err != nil {
+	if err := row.Scan(&out.Title, &out.Slug, &out.Description, &created, &out.ReadOnly); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, model.ErrNotFound
+		}
 		return nil, err
 	}
 	out.CreationTime = created
 	return &out, nil
 }
 
+// GetByTitle finds a vault by exact title, or else by slug.
 func (v *vaults) GetByTitle(ctx context.Context, userID, title string) (*model.Vault, error) {
 	var out model.Vault
 	out.ActorID = userID
-	out.Title = title
 	row := v.db.QueryRowContext(ctx, `
-        SELECT vault_id, description, creation_time FROM vaults WHERE actor_id=$1 AND title=$2
-    `, userID, title)
+        SELECT vault_id, title, COALESCE(slug, ''), description, creation_time, read_only FROM vaults
+        WHERE actor_id=$1 AND (title=$2 OR slug=$3) ORDER BY title=$2 DESC LIMIT 1
+    `, userID, title, model.Slug(title))
 	var created time.Time
-	var desc *string
-	if err := row.Scan(&out.VaultID, &desc, &created); err != nil {
+	if err := row.Scan(&out.VaultID, &out.Title, &out.Slug, &out.Description, &created, &out.ReadOnly); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, model.ErrNotFound
+		}
 		return nil, err
 	}
 	out.CreationTime = created
@@ -155,7 +187,7 @@ func (v *vaults) GetByTitle(ctx context.Context, userID, title string) (*model.V
 
 func (v *vaults) List(ctx context.Context, userID string) ([]*model.Vault, error) {
 	rows, err := v.db.QueryContext(ctx, `
-        SELECT vault_id, title, description, creation_time
+        SELECT vault_id, title, COALESCE(slug, ''), description, creation_time, read_only
         FROM vaults WHERE actor_id=$1 ORDER BY creation_time DESC
     `, userID)
 	if err != nil {
@@ -164,17 +196,109 @@ func (v *vaults) List(ctx context.Context, userID string) ([]*model.Vault, error
 	defer func() { _ = rows.Close() }()
 	var res []*model.Vault
 	for rows.Next() {
-		var id, title string
+		var id, title, slug string
 		var desc *string
 		var created time.Time
-		if err := rows.Scan(&id, &title, &desc, &created); err != nil {
+		var readOnly bool
+		if err := rows.Scan(&id, &title, &slug, &desc, &created, &readOnly); err != nil {
 			return nil, err
 		}
-		res = append(res, &model.Vault{VaultID: id, ActorID: userID, Title: title, CreationTime: created})
+		res = append(res, &model.Vault{VaultID: id, ActorID: userID, Title: title, Slug: slug, Description: desc, CreationTime: created, ReadOnly: readOnly})
 	}
 	return res, rows.Err()
 }
 
+func (v *vaults) Update(ctx context.Context, userID, vaultID string, u model.TitleUpdate) (*model.Vault, error) {
+	tx, err := v.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var title string
+	err = tx.QueryRowContext(ctx, `SELECT title FROM vaults WHERE actor_id=$1 AND vault_id=$2 FOR UPDATE`, userID, vaultID).Scan(&title)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, model.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if u.Title != nil && *u.Title != title {
+		if _, err := tx.ExecContext(ctx, `UPDATE vaults SET title=$1, slug=$2 WHERE actor_id=$3 AND vault_id=$4`, *u.Title, model.Slug(*u.Title), userID, vaultID); err != nil {
+			return nil, titleConflict(err, "vault", *u.Title)
+		}
+		// Every memory's index objects carry the vault title.
+		if _, err := tx.ExecContext(ctx, `
+            INSERT INTO outbox (aggregate_id, op, payload)
+            SELECT memory_id, 'rename_memory', jsonb_build_object('v', $4::int, 'actorId', actor_id, 'memoryId', memory_id, 'vaultTitle', $3::text)
+            FROM memories WHERE actor_id=$1 AND vault_id=$2
+        `, userID, vaultID, *u.Title, payload.Version); err != nil {
+			return nil, err
+		}
+	}
+	if u.Description != nil {
+		if _, err := tx.ExecContext(ctx, `UPDATE vaults SET description=$1 WHERE actor_id=$2 AND vault_id=$3`, nullString(*u.Description), userID, vaultID); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return v.GetByID(ctx, userID, vaultID)
+}
+
+func (v *vaults) SetReadOnly(ctx context.Context, userID, vaultID string, readOnly bool) (*model.Vault, error) {
+	res, err := v.db.ExecContext(ctx, `UPDATE vaults SET read_only=$1 WHERE actor_id=$2 AND vault_id=$3`, readOnly, userID, vaultID)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, model.ErrNotFound
+	}
+	return v.GetByID(ctx, userID, vaultID)
+}
+
+func (v *vaults) MemoryStats(ctx context.Context, userID, vaultID string) ([]model.MemoryStats, error) {
+	rows, err := v.db.QueryContext(ctx, `
+        WITH items AS (
+            SELECT memory_id, entry_id AS id FROM memory_entries WHERE actor_id=$1 AND vault_id=$2 AND deleted_at IS NULL
+            UNION ALL
+            SELECT memory_id, context_id FROM memory_contexts WHERE actor_id=$1 AND vault_id=$2
+        ), backlog AS (
+            SELECT i.memory_id,
+                   count(*) FILTER (WHERE o.status='pending') AS pending,
+                   count(*) FILTER (WHERE o.status='pending' AND o.attempt_count > 0) AS retrying,
+                   count(*) FILTER (WHERE o.status='dead') AS failed
+            FROM items i
+            JOIN LATERAL (SELECT status, attempt_count FROM outbox
+                          WHERE aggregate_id=i.id AND op IN ('upsert_entry','upsert_context')
+                          ORDER BY id DESC LIMIT 1) o ON true
+            WHERE o.status <> 'done'
+            GROUP BY i.memory_id
+        )
+        SELECT m.memory_id, m.title,
+               (SELECT count(*) FROM memory_entries e WHERE e.actor_id=m.actor_id AND e.vault_id=m.vault_id AND e.memory_id=m.memory_id AND e.deleted_at IS NULL),
+               (SELECT count(*) FROM memory_contexts c WHERE c.actor_id=m.actor_id AND c.vault_id=m.vault_id AND c.memory_id=m.memory_id),
+               COALESCE(b.pending, 0), COALESCE(b.retrying, 0), COALESCE(b.failed, 0)
+        FROM memories m LEFT JOIN backlog b ON b.memory_id=m.memory_id
+        WHERE m.actor_id=$1 AND m.vault_id=$2 AND m.deleted_at IS NULL ORDER BY m.title
+    `, userID, vaultID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var out []model.MemoryStats
+	for rows.Next() {
+		var ms model.MemoryStats
+		if err := rows.Scan(&ms.MemoryID, &ms.Title, &ms.Postgres.Entries, &ms.Postgres.Contexts,
+			&ms.Indexing.Pending, &ms.Indexing.Retrying, &ms.Indexing.Failed); err != nil {
+			return nil, err
+		}
+		out = append(out, ms)
+	}
+	return out, rows.Err()
+}
+
 func (v *vaults) Delete(ctx context.Context, userID, vaultID string) error {
 	tx, err := v.db.BeginTx(ctx, &sql.TxOptions{})
 	if err != nil {
@@ -219,6 +343,15 @@ func (v *vaults) Delete(ctx context.Context, userID, vaultID string) error {
 	if _, err := tx.ExecContext(ctx, `DELETE FROM memory_contexts WHERE actor_id=$1 AND vault_id=$2`, userID, vaultID); err != nil {
 		return err
 	}
+	if err := deleteContextDocuments(ctx, tx, `actor_id=$1 AND vault_id=$2`, userID, vaultID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM entity_aliases WHERE actor_id=$1 AND vault_id=$2`, userID, vaultID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM webhooks WHERE actor_id=$1 AND vault_id=$2`, userID, vaultID); err != nil {
+		return err
+	}
 	if _, err := tx.ExecContext(ctx, `DELETE FROM memories WHERE actor_id=$1 AND vault_id=$2`, userID, vaultID); err != nil {
 		return err
 	}
@@ -227,12 +360,12 @@ func (v *vaults) Delete(ctx context.Context, userID, vaultID string) error {
 	}
 
 	for _, id := range entryIDs {
-		if err := writeOutbox(ctx, tx, "delete_entry", id, map[string]interface{}{"actorId": userID}); err != nil {
+		if err := writeOutbox(ctx, tx, id, payload.DeleteEntry(userID)); err != nil {
 			return err
 		}
 	}
 	for _, id := range ctxIDs {
-		if err := writeOutbox(ctx, tx, "delete_context", id, map[string]interface{}{"actorId": userID}); err != nil {
+		if err := writeOutbox(ctx, tx, id, payload.DeleteContext(userID)); err != nil {
 			return err
 		}
 	}
@@ -254,16 +387,18 @@ func (v *vaults) AddMemory(ctx context.Context, userID, vaultID, memoryID string
 
 	// Locate current vault and title for the memory
 	var currentVaultID, title string
-	if err := tx.QueryRowContext(ctx, `SELECT vault_id, title FROM memories WHERE actor_id=$1 AND memory_id=$2`, userID, memoryID).Scan(&currentVaultID, &title); err != nil {
+	var slug sql.NullString
+	if err := tx.QueryRowContext(ctx, `SELECT vault_id, title, slug FROM memories WHERE actor_id=$1 AND memory_id=$2 AND deleted_at IS NULL`, userID, memoryID).Scan(&currentVaultID, &title, &slug); err != nil {
 		return fmt.Errorf("MEMORY_NOT_FOUND: %w", err)
 	}
 	if currentVaultID == vaultID {
 		return tx.Commit()
 	}
 
-	// Enforce unique (vault_id, title) in target
+	// Enforce unique (vault_id, title) and (vault_id, slug) in target
 	var conflict int
-	err = tx.QueryRowContext(ctx, `SELECT 1 FROM memories WHERE actor_id=$1 AND vault_id=$2 AND title=$3`, userID, vaultID, title).Scan(&conflict)
+	err = tx.QueryRowContext(ctx, `SELECT 1 FROM memories WHERE actor_id=$1 AND vault_id=$2 AND (title=$3 OR slug=$4) AND deleted_at IS NULL LIMIT 1`,
+		userID, vaultID, title, slug).Scan(&conflict)
 	if err == nil {
 		return fmt.Errorf("MEMORY_TITLE_CONFLICT: title already exists in target vault")
 	}
@@ -277,8 +412,18 @@ func (v *vaults) AddMemory(ctx context.Context, userID, vaultID, memoryID string
 	if _, err := tx.ExecContext(ctx, `UPDATE memory_entries SET vault_id=$1 WHERE actor_id=$2 AND vault_id=$3 AND memory_id=$4`, vaultID, userID, currentVaultID, memoryID); err != nil {
 		return err
 	}
-	if _, err := tx.ExecContext(ctx, `UPDATE memory_contexts SET vault_id=$1 WHERE actor_id=$2 AND vault_id=$3 AND memory_id=$4`, vaultID, userID, currentVaultID, memoryID); err != nil {
+	// Everything else keyed by the memory moves with it.
+	for _, table := range []string{"memory_contexts", "context_documents", "entity_aliases", "webhooks"} {
+		if _, err := tx.ExecContext(ctx, `UPDATE `+table+` SET vault_id=$1 WHERE actor_id=$2 AND vault_id=$3 AND memory_id=$4`, vaultID, userID, currentVaultID, memoryID); err != nil {
+			return err
+		}
+	}
+	if _, vaultTitle, err := indexTitles(ctx, tx, userID, memoryID); err != nil {
 		return err
+	} else if vaultTitle != "" {
+		if err := writeOutbox(ctx, tx, memoryID, &payload.Rename{ActorID: userID, MemoryID: memoryID, VaultTitle: vaultTitle}); err != nil {
+			return err
+		}
 	}
 	return tx.Commit()
 }
@@ -293,43 +438,54 @@ func (m *memories) Create(ctx context.Context, mm *model.Memory) (*model.Memory,
 	}
 	defer func() { _ = tx.Rollback() }()
 
-	memID := uuid.New().String()
+	out, err := insertMemory(ctx, tx, mm, uuid.New().String(), defaultContext)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// defaultContext is the first context of a memory created without one
+// (store JSON-shaped string in TEXT column).
+const defaultContext = `{"activeContext":"This is default context that's created with the memory. Instructions for AI Agent: Provide relevant context as soon as it's available."}`
+
+// insertMemory creates mm, under a new ID unless mm.MemoryID is set, with
+// first as its first context snapshot contextID, and enqueues the context's
+// index upsert.
+func insertMemory(ctx context.Context, tx *sql.Tx, mm *model.Memory, contextID, first string) (*model.Memory, error) {
+	memID := mm.MemoryID
+	if memID == "" {
+		memID = uuid.New().String()
+	}
 	var created time.Time
 	if err := tx.QueryRowContext(ctx, `
-        INSERT INTO memories (actor_id, vault_id, memory_id, memory_type, title, description)
-        VALUES ($1,$2,$3,$4,$5,$6)
+        INSERT INTO memories (actor_id, vault_id, memory_id, memory_type, title, description, append_only, entry_roles, slug, entry_ttl_seconds)
+        VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
         RETURNING creation_time
-    `, mm.ActorID, mm.VaultID, memID, mm.MemoryType, mm.Title, mm.Description).Scan(&created); err != nil {
-		return nil, err
+    `, mm.ActorID, mm.VaultID, memID, mm.MemoryType, mm.Title, mm.Description, mm.AppendOnly, entryRolesJSON(mm.EntryRoles), model.Slug(mm.Title), mm.EntryTTLSeconds).Scan(&created); err != nil {
+		return nil, titleConflict(err, "memory", mm.Title)
 	}
 
-	// default context snapshot (store JSON-shaped string in TEXT column)
-	ctxID := uuid.New().String()
-	defaultCtx := `{"activeContext":"This is default context that's created with the memory. Instructions for AI Agent: Provide relevant context as soon as it's available."}`
 	var ctxCreated time.Time
 	if err := tx.QueryRowContext(ctx, `
         INSERT INTO memory_contexts (actor_id, vault_id, memory_id, context_id, context)
         VALUES ($1,$2,$3,$4,$5)
         RETURNING creation_time
-    `, mm.ActorID, mm.VaultID, memID, ctxID, defaultCtx).Scan(&ctxCreated); err != nil {
+    `, mm.ActorID, mm.VaultID, memID, contextID, first).Scan(&ctxCreated); err != nil {
 		return nil, err
 	}
 
-	payload := map[string]interface{}{
-		"actorId":      mm.ActorID,
-		"memoryId":     memID,
-		"contextId":    ctxID,
-		"context":      defaultCtx,
-		"creationTime": ctxCreated,
-	}
-	if err := writeOutbox(ctx, tx, "upsert_context", ctxID, payload); err != nil {
+	cp := &payload.Context{ActorID: mm.ActorID, MemoryID: memID, ContextID: contextID, Context: first, CreationTime: ctxCreated}
+	if err := addContextTitles(ctx, tx, cp); err != nil {
 		return nil, err
 	}
-
-	if err := tx.Commit(); err != nil {
+	if err := writeOutbox(ctx, tx, contextID, cp); err != nil {
 		return nil, err
 	}
-	return &model.Memory{MemoryID: memID, ActorID: mm.ActorID, VaultID: mm.VaultID, MemoryType: mm.MemoryType, Title: mm.Title, Description: mm.Description, CreationTime: created}, nil
+	return &model.Memory{MemoryID: memID, ActorID: mm.ActorID, VaultID: mm.VaultID, MemoryType: mm.MemoryType, Title: mm.Title, Slug: model.Slug(mm.Title), Description: mm.Description, CreationTime: created, AppendOnly: mm.AppendOnly, EntryRoles: mm.EntryRoles, EntryTTLSeconds: mm.EntryTTLSeconds}, nil
 }
 
 func (m *memories) GetByID(ctx context.Context, userID, vaultID, memoryID string) (*model.Memory, error) {
@@ -338,34 +494,46 @@ func (m *memories) GetByID(ctx context.Context, userID, vaultID, memoryID string
 	out.VaultID = vaultID
 	out.MemoryID = memoryID
 	row := m.db.QueryRowContext(ctx, `
-        SELECT memory_type, title, description, creation_time
-        FROM memories WHERE actor_id=$1 AND vault_id=$2 AND memory_id=$3
+        SELECT memory_type, title, COALESCE(slug, ''), description, creation_time, append_only, entry_roles, search_boost, entry_ttl_seconds
+        FROM memories WHERE actor_id=$1 AND vault_id=$2 AND memory_id=$3 AND deleted_at IS NULL
     `, userID, vaultID, memoryID)
-	if err := row.Scan(&out.MemoryType, &out.Title, &out.Description, &out.CreationTime); err != nil {
+	var roles, boost sql.NullString
+	if err := row.Scan(&out.MemoryType, &out.Title, &out.Slug, &out.Description, &out.CreationTime, &out.AppendOnly, &roles, &boost, &out.EntryTTLSeconds); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, model.ErrNotFound
+		}
 		return nil, err
 	}
+	out.EntryRoles = decodeEntryRoles(roles)
+	out.SearchBoost = decodeSearchBoost(boost)
 	return &out, nil
 }
 
+// GetByTitle finds a memory by exact title, or else by slug.
 func (m *memories) GetByTitle(ctx context.Context, userID, vaultID, title string) (*model.Memory, error) {
 	var out model.Memory
 	out.ActorID = userID
 	out.VaultID = vaultID
-	out.Title = title
 	row := m.db.QueryRowContext(ctx, `
-        SELECT memory_id, memory_type, description, creation_time
-        FROM memories WHERE actor_id=$1 AND vault_id=$2 AND title=$3
-    `, userID, vaultID, title)
-	if err := row.Scan(&out.MemoryID, &out.MemoryType, &out.Description, &out.CreationTime); err != nil {
+        SELECT memory_id, memory_type, title, COALESCE(slug, ''), description, creation_time, append_only, entry_roles, search_boost, entry_ttl_seconds
+        FROM memories WHERE actor_id=$1 AND vault_id=$2 AND (title=$3 OR slug=$4) AND deleted_at IS NULL ORDER BY title=$3 DESC LIMIT 1
+    `, userID, vaultID, title, model.Slug(title))
+	var roles, boost sql.NullString
+	if err := row.Scan(&out.MemoryID, &out.MemoryType, &out.Title, &out.Slug, &out.Description, &out.CreationTime, &out.AppendOnly, &roles, &boost, &out.EntryTTLSeconds); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, model.ErrNotFound
+		}
 		return nil, err
 	}
+	out.EntryRoles = decodeEntryRoles(roles)
+	out.SearchBoost = decodeSearchBoost(boost)
 	return &out, nil
 }
 
 func (m *memories) List(ctx context.Context, userID, vaultID string) ([]*model.Memory, error) {
 	rows, err := m.db.QueryContext(ctx, `
-        SELECT memory_id, memory_type, title, description, creation_time
-        FROM memories WHERE actor_id=$1 AND vault_id=$2 ORDER BY creation_time DESC
+        SELECT memory_id, memory_type, title, COALESCE(slug, ''), description, creation_time, append_only, entry_roles, search_boost, entry_ttl_seconds
+        FROM memories WHERE actor_id=$1 AND vault_id=$2 AND deleted_at IS NULL ORDER BY creation_time DESC
     `, userID, vaultID)
 	if err != nil {
 		return nil, err
@@ -376,14 +544,127 @@ func (m *memories) List(ctx context.Context, userID, vaultID string) ([]*model.M
 		var mm model.Memory
 		mm.ActorID = userID
 		mm.VaultID = vaultID
-		if err := rows.Scan(&mm.MemoryID, &mm.MemoryType, &mm.Title, &mm.Description, &mm.CreationTime); err != nil {
+		var roles, boost sql.NullString
+		if err := rows.Scan(&mm.MemoryID, &mm.MemoryType, &mm.Title, &mm.Slug, &mm.Description, &mm.CreationTime, &mm.AppendOnly, &roles, &boost, &mm.EntryTTLSeconds); err != nil {
 			return nil, err
 		}
+		mm.EntryRoles = decodeEntryRoles(roles)
+		mm.SearchBoost = decodeSearchBoost(boost)
 		out = append(out, &mm)
 	}
 	return out, rows.Err()
 }
 
+func (m *memories) SetAppendOnly(ctx context.Context, userID, vaultID, memoryID string) (*model.Memory, error) {
+	res, err := m.db.ExecContext(ctx, `UPDATE memories SET append_only=true WHERE actor_id=$1 AND vault_id=$2 AND memory_id=$3`, userID, vaultID, memoryID)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, model.ErrNotFound
+	}
+	return m.GetByID(ctx, userID, vaultID, memoryID)
+}
+
+func (m *memories) SetEntryRoles(ctx context.Context, userID, vaultID, memoryID string, roles []string) (*model.Memory, error) {
+	res, err := m.db.ExecContext(ctx, `UPDATE memories SET entry_roles=$4 WHERE actor_id=$1 AND vault_id=$2 AND memory_id=$3`,
+		userID, vaultID, memoryID, entryRolesJSON(roles))
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, model.ErrNotFound
+	}
+	return m.GetByID(ctx, userID, vaultID, memoryID)
+}
+
+// entryRolesJSON encodes a memory's entry roles; none is stored as NULL.
+func entryRolesJSON(roles []string) interface{} {
+	if len(roles) == 0 {
+		return nil
+	}
+	b, _ := json.Marshal(roles)
+	return string(b)
+}
+
+func decodeEntryRoles(s sql.NullString) []string {
+	if !s.Valid {
+		return nil
+	}
+	var roles []string
+	_ = json.Unmarshal([]byte(s.String), &roles)
+	return roles
+}
+
+func (m *memories) SetSearchBoost(ctx context.Context, userID, vaultID, memoryID string, b *model.SearchBoost) (*model.Memory, error) {
+	res, err := m.db.ExecContext(ctx, `UPDATE memories SET search_boost=$4 WHERE actor_id=$1 AND vault_id=$2 AND memory_id=$3 AND deleted_at IS NULL`,
+		userID, vaultID, memoryID, searchBoostJSON(b))
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, model.ErrNotFound
+	}
+	return m.GetByID(ctx, userID, vaultID, memoryID)
+}
+
+// searchBoostJSON encodes a memory's search boost; none is stored as NULL.
+func searchBoostJSON(b *model.SearchBoost) interface{} {
+	if b == nil {
+		return nil
+	}
+	out, _ := json.Marshal(b)
+	return string(out)
+}
+
+func decodeSearchBoost(s sql.NullString) *model.SearchBoost {
+	if !s.Valid {
+		return nil
+	}
+	var b model.SearchBoost
+	if err := json.Unmarshal([]byte(s.String), &b); err != nil {
+		return nil
+	}
+	return &b
+}
+
+func (m *memories) Update(ctx context.Context, userID, vaultID, memoryID string, u model.TitleUpdate) (*model.Memory, error) {
+	tx, err := m.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var title string
+	err = tx.QueryRowContext(ctx, `SELECT title FROM memories WHERE actor_id=$1 AND vault_id=$2 AND memory_id=$3 FOR UPDATE`,
+		userID, vaultID, memoryID).Scan(&title)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, model.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if u.Title != nil && *u.Title != title {
+		if _, err := tx.ExecContext(ctx, `UPDATE memories SET title=$1, slug=$2 WHERE actor_id=$3 AND vault_id=$4 AND memory_id=$5`,
+			*u.Title, model.Slug(*u.Title), userID, vaultID, memoryID); err != nil {
+			return nil, titleConflict(err, "memory", *u.Title)
+		}
+		if err := writeOutbox(ctx, tx, memoryID, &payload.Rename{ActorID: userID, MemoryID: memoryID, MemoryTitle: *u.Title}); err != nil {
+			return nil, err
+		}
+	}
+	if u.Description != nil {
+		if _, err := tx.ExecContext(ctx, `UPDATE memories SET description=$1 WHERE actor_id=$2 AND vault_id=$3 AND memory_id=$4`,
+			nullString(*u.Description), userID, vaultID, memoryID); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return m.GetByID(ctx, userID, vaultID, memoryID)
+}
+
 func (m *memories) Delete(ctx context.Context, userID, vaultID, memoryID string) error {
 	tx, err := m.db.BeginTx(ctx, &sql.TxOptions{})
 	if err != nil {
@@ -427,17 +708,26 @@ func (m *memories) Delete(ctx context.Context, userID, vaultID, memoryID string)
 	if _, err := tx.ExecContext(ctx, `DELETE FROM memory_contexts WHERE actor_id=$1 AND vault_id=$2 AND memory_id=$3`, userID, vaultID, memoryID); err != nil {
 		return err
 	}
+	if err := deleteContextDocuments(ctx, tx, `actor_id=$1 AND vault_id=$2 AND memory_id=$3`, userID, vaultID, memoryID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM entity_aliases WHERE actor_id=$1 AND vault_id=$2 AND memory_id=$3`, userID, vaultID, memoryID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM webhooks WHERE actor_id=$1 AND vault_id=$2 AND memory_id=$3`, userID, vaultID, memoryID); err != nil {
+		return err
+	}
 	if _, err := tx.ExecContext(ctx, `DELETE FROM memories WHERE actor_id=$1 AND vault_id=$2 AND memory_id=$3`, userID, vaultID, memoryID); err != nil {
 		return err
 	}
 
 	for _, id := range entryIDs {
-		if err := writeOutbox(ctx, tx, "delete_entry", id, map[string]interface{}{"actorId": userID}); err != nil {
+		if err := writeOutbox(ctx, tx, id, payload.DeleteEntry(userID)); err != nil {
 			return err
 		}
 	}
 	for _, id := range ctxIDs {
-		if err := writeOutbox(ctx, tx, "delete_context", id, map[string]interface{}{"actorId": userID}); err != nil {
+		if err := writeOutbox(ctx, tx, id, payload.DeleteContext(userID)); err != nil {
 			return err
 		}
 	}
@@ -445,41 +735,123 @@ func (m *memories) Delete(ctx context.Context, userID, vaultID, memoryID string)
 }
 
 // --- Entries ---
-type entries struct{ db *sql.DB }
+type entries struct {
+	db          *sql.DB
+	compressMin int
+}
 
 func (e *entries) Create(ctx context.Context, me *model.MemoryEntry) (*model.MemoryEntry, error) {
+	out, err := e.CreateBatch(ctx, []*model.MemoryEntry{me})
+	if err != nil {
+		return nil, err
+	}
+	return out[0], nil
+}
+
+func (e *entries) CreateBatch(ctx context.Context, batch []*model.MemoryEntry) ([]*model.MemoryEntry, error) {
+	if len(batch) == 0 {
+		return nil, nil
+	}
 	tx, err := e.db.BeginTx(ctx, &sql.TxOptions{})
 	if err != nil {
 		return nil, err
 	}
 	defer func() { _ = tx.Rollback() }()
 
+	first := batch[0]
+	memoryTitle, vaultTitle, err := indexTitles(ctx, tx, first.ActorID, first.MemoryID)
+	if err != nil {
+		return nil, err
+	}
+	locked := map[string]bool{}
+	out := make([]*model.MemoryEntry, 0, len(batch))
+	for _, me := range batch {
+		if me.ActorID != first.ActorID || me.VaultID != first.VaultID || me.MemoryID != first.MemoryID {
+			return nil, fmt.Errorf("%w: a batch must write to one memory", model.ErrValidation)
+		}
+		if me.IngestionBatchID != "" && !locked[me.IngestionBatchID] {
+			if err := lockOpenIngestionBatch(ctx, tx, me.ActorID, me.IngestionBatchID); err != nil {
+				return nil, err
+			}
+			locked[me.IngestionBatchID] = true
+		}
+		created, err := e.insert(ctx, tx, me, memoryTitle, vaultTitle)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, created)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// lockOpenIngestionBatch locks the batch row so a concurrent rollback cannot
+// miss entries written in tx, and checks the batch is still open.
+func lockOpenIngestionBatch(ctx context.Context, tx *sql.Tx, actorID, batchID string) error {
+	var status string
+	err := tx.QueryRowContext(ctx, `SELECT status FROM ingestion_batches WHERE actor_id=$1 AND batch_id=$2 FOR SHARE`,
+		actorID, batchID).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: ingestion batch %s not found", model.ErrValidation, batchID)
+	}
+	if err != nil {
+		return err
+	}
+	if status != model.IngestionBatchOpen {
+		return fmt.Errorf("%w: ingestion batch %s is %s", model.ErrConflict, batchID, status)
+	}
+	return nil
+}
+
+// insert writes one entry and its index upsert in tx. creation_time is the
+// clock time rather than the transaction start, so entries of one batch keep
+// their order.
+func (e *entries) insert(ctx context.Context, tx *sql.Tx, me *model.MemoryEntry, memoryTitle, vaultTitle string) (*model.MemoryEntry, error) {
 	entryID := uuid.New().String()
 	var created time.Time
 	metaJSON, _ := json.Marshal(me.Metadata)
 	tagsJSON, _ := json.Marshal(me.Tags)
+	var usageJSON []byte
+	if me.Usage != nil {
+		usageJSON, _ = json.Marshal(me.Usage)
+	}
+	raw, encoding, blob := encodeRawEntry(me.RawEntry, e.compressMin)
 	row := tx.QueryRowContext(ctx, `
-        INSERT INTO memory_entries (actor_id, vault_id, memory_id, raw_entry, summary, metadata, tags, entry_id)
-        VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+        INSERT INTO memory_entries (actor_id, vault_id, memory_id, raw_entry, summary, metadata, tags, entry_id,
+                                    source_system, source_id, ingestion_batch_id, session_id, raw_entry_encoding, raw_entry_zstd, llm_usage,
+                                    conversation_time, expiration_time, idempotency_key, creation_time)
+        VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,clock_timestamp())
+        ON CONFLICT (actor_id, memory_id, idempotency_key) WHERE idempotency_key IS NOT NULL DO NOTHING
         RETURNING creation_time
-    `, me.ActorID, me.VaultID, me.MemoryID, me.RawEntry, me.Summary, nullIfEmpty(metaJSON), nullIfEmpty(tagsJSON), entryID)
-	if err := row.Scan(&created); err != nil {
-		return nil, err
-	}
-
-	payload := map[string]interface{}{
-		"actorId":      me.ActorID,
-		"memoryId":     me.MemoryID,
-		"entryId":      entryID,
-		"rawEntry":     me.RawEntry,
-		"summary":      me.Summary,
-		"tags":         me.Tags,
-		"creationTime": created,
+    `, me.ActorID, me.VaultID, me.MemoryID, raw, me.Summary, nullIfEmpty(metaJSON), nullIfEmpty(tagsJSON), entryID,
+		nullString(me.SourceSystem), nullString(me.SourceID), nullString(me.IngestionBatchID), nullString(me.SessionID), encoding, blob, nullIfEmpty(usageJSON),
+		me.ConversationTime, me.ExpirationTime, nullString(me.IdempotencyKey))
+	err := row.Scan(&created)
+	if errors.Is(err, sql.ErrNoRows) && me.IdempotencyKey != "" {
+		return idempotentEntry(ctx, tx, me)
 	}
-	if err := writeOutbox(ctx, tx, "upsert_entry", entryID, payload); err != nil {
+	if err != nil {
 		return nil, err
 	}
-	if err := tx.Commit(); err != nil {
+
+	ep := &payload.Entry{
+		ActorID:      me.ActorID,
+		MemoryID:     me.MemoryID,
+		EntryID:      entryID,
+		RawEntry:     me.RawEntry,
+		Tags:         payload.TagKeys(me.Tags),
+		TagPairs:     payload.TagPairs(me.Tags),
+		CreationTime: created,
+		SessionID:    me.SessionID,
+		MemoryTitle:  memoryTitle,
+		VaultTitle:   vaultTitle,
+	}
+	if me.Summary != nil {
+		ep.Summary = *me.Summary
+	}
+	if err := writeOutbox(ctx, tx, entryID, ep); err != nil {
 		return nil, err
 	}
 	out := *me
@@ -488,21 +860,57 @@ func (e *entries) Create(ctx context.Context, me *model.MemoryEntry) (*model.Mem
 	return &out, nil
 }
 
+// idempotentEntry returns the entry an earlier create with me's idempotency
+// key wrote, even if it has since been trashed or expired. The replay writes
+// nothing, so no outbox event either.
+func idempotentEntry(ctx context.Context, tx *sql.Tx, me *model.MemoryEntry) (*model.MemoryEntry, error) {
+	out, err := scanEntry(tx.QueryRowContext(ctx, `SELECT `+entryColumns+`
+               FROM memory_entries WHERE actor_id=$1 AND memory_id=$2 AND idempotency_key=$3`,
+		me.ActorID, me.MemoryID, me.IdempotencyKey))
+	if err != nil {
+		return nil, err
+	}
+	out.IdempotencyKey = me.IdempotencyKey
+	return out, nil
+}
+
 func (e *entries) List(ctx context.Context, req model.ListEntriesRequest) ([]*model.MemoryEntry, error) {
-	query := `SELECT actor_id, vault_id, memory_id, creation_time, entry_id, raw_entry, summary, metadata, tags,
-                      correction_time, corrected_entry_memory_id, corrected_entry_creation_time,
-                      correction_reason, last_update_time
-               FROM memory_entries WHERE actor_id=$1 AND vault_id=$2 AND memory_id=$3`
+	query := `SELECT ` + entryColumns + `
+               FROM memory_entries WHERE actor_id=$1 AND vault_id=$2 AND memory_id=$3 AND deleted_at IS NULL AND ` + notExpired
 	args := []interface{}{req.ActorID, req.VaultID, req.MemoryID}
+	if req.SessionID != "" {
+		args = append(args, req.SessionID)
+		query += fmt.Sprintf(" AND session_id = $%d", len(args))
+	}
+	if len(req.Tags) > 0 {
+		// Every pair must match a scalar tag's text, as in the search index's
+		// tagPairs (payload.TagPairsSQL).
+		tagsJSON, _ := json.Marshal(req.Tags)
+		args = append(args, string(tagsJSON))
+		query += fmt.Sprintf(` AND NOT EXISTS (SELECT 1 FROM jsonb_each_text($%d::jsonb) f
+                WHERE NOT COALESCE(jsonb_typeof(tags->f.key) IN ('string', 'number', 'boolean') AND tags->f.key #>> '{}' = f.value, false))`, len(args))
+	}
+	orderCol := "creation_time"
+	if req.OrderBy == model.EntryOrderConversationTime {
+		orderCol = "COALESCE(conversation_time, creation_time)"
+	}
 	if req.Before != nil {
-		query += " AND creation_time < $4"
 		args = append(args, *req.Before)
+		query += fmt.Sprintf(" AND %s < $%d", orderCol, len(args))
 	}
 	if req.After != nil && req.Before == nil {
-		query += " AND creation_time > $4"
 		args = append(args, *req.After)
+		query += fmt.Sprintf(" AND %s > $%d", orderCol, len(args))
+	}
+	dir, cmp := "DESC", "<"
+	if req.Ascending {
+		dir, cmp = "ASC", ">"
+	}
+	if req.Cursor != nil {
+		args = append(args, req.Cursor.CreationTime, req.Cursor.EntryID)
+		query += fmt.Sprintf(" AND (creation_time, entry_id) %s ($%d, $%d)", cmp, len(args)-1, len(args))
 	}
-	query += " ORDER BY creation_time DESC"
+	query += fmt.Sprintf(" ORDER BY %[1]s %[2]s, creation_time %[2]s, entry_id %[2]s", orderCol, dir)
 	if req.Limit > 0 {
 		query += fmt.Sprintf(" LIMIT %d", req.Limit)
 	}
@@ -513,57 +921,403 @@ func (e *entries) List(ctx context.Context, req model.ListEntriesRequest) ([]*mo
 	defer func() { _ = rows.Close() }()
 	var out []*model.MemoryEntry
 	for rows.Next() {
-		var m model.MemoryEntry
-		var meta, tags sql.NullString
-		var corrTime, corrEntryTime, lastUpd sql.NullTime
-		var corrMemID sql.NullString
-		if err := rows.Scan(&m.ActorID, &m.VaultID, &m.MemoryID, &m.CreationTime, &m.EntryID, &m.RawEntry, &m.Summary, &meta, &tags,
-			&corrTime, &corrMemID, &corrEntryTime, &corrMemID, &lastUpd); err != nil {
+		m, err := scanEntry(rows)
+		if err != nil {
 			return nil, err
 		}
-		if meta.Valid {
-			_ = json.Unmarshal([]byte(meta.String), &m.Metadata)
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
+
+// invalidRegexSQLState is Postgres' invalid_regular_expression error code.
+const invalidRegexSQLState = "2201B"
+
+// Scan filters compressed rows in Go after decompressing them (see
+// scanMatcher), so with a text filter the page limit is applied while reading
+// rows rather than in SQL.
+func (e *entries) Scan(ctx context.Context, req model.ScanEntriesRequest) ([]*model.MemoryEntry, error) {
+	query := `SELECT ` + entryColumns + `
+               FROM memory_entries WHERE actor_id=$1 AND vault_id=$2 AND memory_id=$3 AND deleted_at IS NULL AND ` + notExpired
+	args := []interface{}{req.ActorID, req.VaultID, req.MemoryID}
+	if req.Contains != "" {
+		args = append(args, strings.ToLower(req.Contains))
+		query += fmt.Sprintf(" AND (raw_entry_encoding IS NOT NULL OR strpos(lower(raw_entry), $%[1]d) > 0 OR strpos(lower(summary), $%[1]d) > 0)", len(args))
+	}
+	if req.Regex != "" {
+		args = append(args, req.Regex)
+		query += fmt.Sprintf(" AND (raw_entry_encoding IS NOT NULL OR raw_entry ~ $%[1]d OR summary ~ $%[1]d)", len(args))
+	}
+	if req.After != nil {
+		args = append(args, req.After.CreationTime, req.After.EntryID)
+		query += fmt.Sprintf(" AND (creation_time, entry_id) < ($%d, $%d)", len(args)-1, len(args))
+	}
+	query += " ORDER BY creation_time DESC, entry_id DESC"
+	filtered := req.Contains != "" || req.Regex != ""
+	if req.Limit > 0 && !filtered {
+		query += fmt.Sprintf(" LIMIT %d", req.Limit)
+	}
+	rows, err := e.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == invalidRegexSQLState {
+			return nil, fmt.Errorf("%w: invalid regex: %s", model.ErrValidation, pgErr.Message)
 		}
-		if tags.Valid {
-			_ = json.Unmarshal([]byte(tags.String), &m.Tags)
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var matcher *scanMatcher
+	if filtered {
+		matcher = newScanMatcher(req)
+	}
+	var out []*model.MemoryEntry
+	for rows.Next() {
+		m, compressed, err := scanEntryEncoded(rows)
+		if err != nil {
+			return nil, err
+		}
+		if compressed && matcher != nil && !matcher.match(m) {
+			continue
+		}
+		out = append(out, m)
+		if req.Limit > 0 && len(out) == req.Limit {
+			break
 		}
-		out = append(out, &m)
 	}
 	return out, rows.Err()
 }
 
 func (e *entries) GetByID(ctx context.Context, userID, vaultID, memoryID, entryID string) (*model.MemoryEntry, error) {
-	var m model.MemoryEntry
-	var meta, tags sql.NullString
-	var corrTime, corrEntryTime, lastUpd sql.NullTime
-	var corrMemID sql.NullString
 	row := e.db.QueryRowContext(ctx, `
-        SELECT actor_id, vault_id, memory_id, creation_time, entry_id, raw_entry, summary, metadata, tags,
-               correction_time, corrected_entry_memory_id, corrected_entry_creation_time,
-               correction_reason, last_update_time
-        FROM memory_entries WHERE actor_id=$1 AND vault_id=$2 AND memory_id=$3 AND entry_id=$4
+        SELECT `+entryColumns+`
+        FROM memory_entries WHERE actor_id=$1 AND vault_id=$2 AND memory_id=$3 AND entry_id=$4 AND deleted_at IS NULL AND `+notExpired+`
     `, userID, vaultID, memoryID, entryID)
-	if err := row.Scan(&m.ActorID, &m.VaultID, &m.MemoryID, &m.CreationTime, &m.EntryID, &m.RawEntry, &m.Summary, &meta, &tags,
-		&corrTime, &corrMemID, &corrEntryTime, &corrMemID, &lastUpd); err != nil {
+	return scanEntry(row)
+}
+
+func (e *entries) Sessions(ctx context.Context, userID, vaultID, memoryID string) ([]model.EntrySession, error) {
+	rows, err := e.db.QueryContext(ctx, `
+        SELECT session_id, count(*), min(creation_time), max(creation_time)
+        FROM memory_entries
+        WHERE actor_id=$1 AND vault_id=$2 AND memory_id=$3 AND session_id IS NOT NULL AND deleted_at IS NULL AND `+notExpired+`
+        GROUP BY session_id
+        ORDER BY min(creation_time), session_id
+    `, userID, vaultID, memoryID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var out []model.EntrySession
+	for rows.Next() {
+		var s model.EntrySession
+		if err := rows.Scan(&s.SessionID, &s.EntryCount, &s.FirstEntryTime, &s.LastEntryTime); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+func (e *entries) SessionStart(ctx context.Context, userID, memoryID, sessionID string) (time.Time, error) {
+	var start sql.NullTime
+	err := e.db.QueryRowContext(ctx, `
+        SELECT min(creation_time) FROM memory_entries
+        WHERE actor_id=$1 AND memory_id=$2 AND session_id=$3 AND deleted_at IS NULL
+    `, userID, memoryID, sessionID).Scan(&start)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !start.Valid {
+		return time.Time{}, model.ErrNotFound
+	}
+	return start.Time, nil
+}
+
+func (e *entries) Newest(ctx context.Context, userID, memoryID string) (time.Time, error) {
+	var newest sql.NullTime
+	err := e.db.QueryRowContext(ctx, `
+        SELECT max(creation_time) FROM memory_entries
+        WHERE actor_id=$1 AND memory_id=$2 AND deleted_at IS NULL
+    `, userID, memoryID).Scan(&newest)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !newest.Valid {
+		return time.Time{}, model.ErrNotFound
+	}
+	return newest.Time, nil
+}
+
+func (e *entries) Usage(ctx context.Context, userID, memoryID string, since *time.Time) ([]model.UsageTotals, error) {
+	query := `SELECT memory_id, COALESCE(llm_usage->>'model', ''), count(*),
+                     COALESCE(sum((llm_usage->>'inputTokens')::bigint), 0),
+                     COALESCE(sum((llm_usage->>'outputTokens')::bigint), 0),
+                     COALESCE(sum((llm_usage->>'costUsd')::double precision), 0)
+              FROM memory_entries WHERE actor_id=$1 AND llm_usage IS NOT NULL`
+	args := []interface{}{userID}
+	if memoryID != "" {
+		args = append(args, memoryID)
+		query += fmt.Sprintf(" AND memory_id = $%d", len(args))
+	}
+	if since != nil {
+		args = append(args, *since)
+		query += fmt.Sprintf(" AND creation_time >= $%d", len(args))
+	}
+	query += " GROUP BY 1, 2 ORDER BY 1, 2"
+	rows, err := e.db.QueryContext(ctx, query, args...)
+	if err != nil {
 		return nil, err
 	}
+	defer func() { _ = rows.Close() }()
+	var out []model.UsageTotals
+	for rows.Next() {
+		var t model.UsageTotals
+		if err := rows.Scan(&t.MemoryID, &t.Model, &t.Entries, &t.InputTokens, &t.OutputTokens, &t.CostUSD); err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+// entryColumns lists the memory_entries columns read by scanEntry, in order.
+const entryColumns = `actor_id, vault_id, memory_id, creation_time, entry_id, raw_entry, summary, metadata, tags,
+               correction_time, corrected_entry_memory_id, corrected_entry_creation_time,
+               correction_reason, last_update_time, source_system, source_id, ingestion_batch_id,
+               useful_count, incorrect_count, outdated_count, last_accessed_time, session_id,
+               raw_entry_encoding, raw_entry_zstd, llm_usage, conversation_time, expiration_time`
+
+// notExpired matches memory_entries rows not past their expiration time.
+const notExpired = `(expiration_time IS NULL OR expiration_time > now())`
+
+// scanEntry reads one memory_entries row selected with entryColumns.
+func scanEntry(row interface{ Scan(dest ...any) error }) (*model.MemoryEntry, error) {
+	m, _, err := scanEntryEncoded(row)
+	return m, err
+}
+
+// scanEntryEncoded is scanEntry that also reports whether the raw entry was
+// stored compressed.
+func scanEntryEncoded(row interface{ Scan(dest ...any) error }) (*model.MemoryEntry, bool, error) {
+	var m model.MemoryEntry
+	var meta, tags, usage sql.NullString
+	var corrTime, corrEntryTime, lastUpd, lastAccess, convTime, expires sql.NullTime
+	var corrMemID, corrReason sql.NullString
+	var sourceSystem, sourceID, batchID, sessionID, encoding sql.NullString
+	var blob []byte
+	if err := row.Scan(&m.ActorID, &m.VaultID, &m.MemoryID, &m.CreationTime, &m.EntryID, &m.RawEntry, &m.Summary, &meta, &tags,
+		&corrTime, &corrMemID, &corrEntryTime, &corrReason, &lastUpd, &sourceSystem, &sourceID, &batchID,
+		&m.UsefulCount, &m.IncorrectCount, &m.OutdatedCount, &lastAccess, &sessionID, &encoding, &blob, &usage, &convTime, &expires); err != nil {
+		return nil, false, err
+	}
+	raw, err := decodeRawEntry(m.RawEntry, encoding, blob)
+	if err != nil {
+		return nil, false, fmt.Errorf("entry %s: %w", m.EntryID, err)
+	}
+	m.RawEntry = raw
 	if meta.Valid {
 		_ = json.Unmarshal([]byte(meta.String), &m.Metadata)
 	}
 	if tags.Valid {
 		_ = json.Unmarshal([]byte(tags.String), &m.Tags)
 	}
-	return &m, nil
+	m.SourceSystem = sourceSystem.String
+	m.SourceID = sourceID.String
+	m.IngestionBatchID = batchID.String
+	m.SessionID = sessionID.String
+	if corrTime.Valid {
+		m.CorrectionTime = &corrTime.Time
+		m.CorrectionReason = corrReason.String
+	}
+	if lastAccess.Valid {
+		m.LastAccessedTime = &lastAccess.Time
+	}
+	if convTime.Valid {
+		m.ConversationTime = &convTime.Time
+	}
+	if expires.Valid {
+		m.ExpirationTime = &expires.Time
+	}
+	if usage.Valid {
+		m.Usage = &model.EntryUsage{}
+		_ = json.Unmarshal([]byte(usage.String), m.Usage)
+	}
+	return &m, encoding.Valid, nil
 }
 
 func (e *entries) UpdateTags(ctx context.Context, userID, vaultID, memoryID, entryID string, tags map[string]interface{}) (*model.MemoryEntry, error) {
 	tagsJSON, _ := json.Marshal(tags)
-	if _, err := e.db.ExecContext(ctx, `UPDATE memory_entries SET tags=$1, last_update_time=now() WHERE actor_id=$2 AND vault_id=$3 AND memory_id=$4 AND entry_id=$5`, nullIfEmpty(tagsJSON), userID, vaultID, memoryID, entryID); err != nil {
+	tx, err := e.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// The index holds the tags too, so the update re-indexes the entry.
+	var raw string
+	var encoding, summary, sessionID sql.NullString
+	var blob []byte
+	var created time.Time
+	err = tx.QueryRowContext(ctx, `
+        UPDATE memory_entries SET tags=$1, last_update_time=now()
+        WHERE actor_id=$2 AND vault_id=$3 AND memory_id=$4 AND entry_id=$5 AND deleted_at IS NULL
+        RETURNING raw_entry, raw_entry_encoding, raw_entry_zstd, summary, creation_time, session_id
+    `, nullIfEmpty(tagsJSON), userID, vaultID, memoryID, entryID).Scan(&raw, &encoding, &blob, &summary, &created, &sessionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return e.GetByID(ctx, userID, vaultID, memoryID, entryID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if raw, err = decodeRawEntry(raw, encoding, blob); err != nil {
+		return nil, fmt.Errorf("entry %s: %w", entryID, err)
+	}
+	memoryTitle, vaultTitle, err := indexTitles(ctx, tx, userID, memoryID)
+	if err != nil {
+		return nil, err
+	}
+	ep := &payload.Entry{ActorID: userID, MemoryID: memoryID, EntryID: entryID, RawEntry: raw, Summary: summary.String,
+		Tags: payload.TagKeys(tags), TagPairs: payload.TagPairs(tags), CreationTime: created, SessionID: sessionID.String,
+		MemoryTitle: memoryTitle, VaultTitle: vaultTitle}
+	if err := writeOutbox(ctx, tx, entryID, ep); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return e.GetByID(ctx, userID, vaultID, memoryID, entryID)
+}
+
+func (e *entries) PatchTags(ctx context.Context, p model.EntryTagPatch) ([]string, error) {
+	setJSON, _ := json.Marshal(p.Set)
+	if p.Set == nil {
+		setJSON = []byte("{}")
+	}
+	unset := p.Unset
+	if unset == nil {
+		unset = []string{}
+	}
+	tx, err := e.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Tags written as JSON null count as no tags.
+	rows, err := tx.QueryContext(ctx, `
+        UPDATE memory_entries
+        SET tags = (CASE WHEN jsonb_typeof(tags) = 'object' THEN tags ELSE '{}'::jsonb END || $4::jsonb) - $5::text[],
+            last_update_time = now()
+        WHERE actor_id=$1 AND vault_id=$2 AND memory_id=$3 AND deleted_at IS NULL
+          AND (COALESCE(cardinality($6::text[]), 0) = 0 OR entry_id = ANY($6::text[]))
+          AND ($7::text IS NULL OR session_id = $7)
+          AND ($8::timestamptz IS NULL OR creation_time >= $8)
+          AND ($9::timestamptz IS NULL OR creation_time < $9)
+        RETURNING entry_id, raw_entry, raw_entry_encoding, raw_entry_zstd, summary, tags, creation_time, session_id
+    `, p.ActorID, p.VaultID, p.MemoryID, string(setJSON), unset, p.EntryIDs, nullString(p.SessionID), p.Since, p.Until)
+	if err != nil {
+		return nil, err
+	}
+	var payloads []*payload.Entry
+	for rows.Next() {
+		var id, raw string
+		var encoding, summary, tags, sessionID sql.NullString
+		var blob []byte
+		var created time.Time
+		if err := rows.Scan(&id, &raw, &encoding, &blob, &summary, &tags, &created, &sessionID); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		if raw, err = decodeRawEntry(raw, encoding, blob); err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("entry %s: %w", id, err)
+		}
+		ep := &payload.Entry{ActorID: p.ActorID, MemoryID: p.MemoryID, EntryID: id, RawEntry: raw,
+			Summary: summary.String, CreationTime: created, SessionID: sessionID.String}
+		if tags.Valid {
+			var m map[string]interface{}
+			if err := json.Unmarshal([]byte(tags.String), &m); err != nil {
+				_ = rows.Close()
+				return nil, fmt.Errorf("entry %s tags: %w", id, err)
+			}
+			ep.Tags, ep.TagPairs = payload.TagKeys(m), payload.TagPairs(m)
+		}
+		payloads = append(payloads, ep)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(payloads) > model.MaxTagPatchEntries {
+		return nil, fmt.Errorf("%w: filter matches %d entries; at most %d can be patched at once", model.ErrValidation, len(payloads), model.MaxTagPatchEntries)
+	}
+
+	memoryTitle, vaultTitle, err := indexTitles(ctx, tx, p.ActorID, p.MemoryID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(payloads))
+	for _, ep := range payloads {
+		ep.MemoryTitle, ep.VaultTitle = memoryTitle, vaultTitle
+		if err := writeOutbox(ctx, tx, ep.EntryID, ep); err != nil {
+			return nil, err
+		}
+		ids = append(ids, ep.EntryID)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// signalColumns maps model.Signal* names to their counter column.
+var signalColumns = map[string]string{
+	model.SignalUseful:    "useful_count",
+	model.SignalIncorrect: "incorrect_count",
+	model.SignalOutdated:  "outdated_count",
+}
+
+func (e *entries) RecordSignal(ctx context.Context, userID, vaultID, memoryID, entryID, signal string) (*model.MemoryEntry, error) {
+	col, ok := signalColumns[signal]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown signal %q", model.ErrValidation, signal)
+	}
+	res, err := e.db.ExecContext(ctx, `UPDATE memory_entries SET `+col+`=`+col+`+1 WHERE actor_id=$1 AND vault_id=$2 AND memory_id=$3 AND entry_id=$4 AND deleted_at IS NULL`, userID, vaultID, memoryID, entryID)
+	if err != nil {
 		return nil, err
 	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, model.ErrNotFound
+	}
 	return e.GetByID(ctx, userID, vaultID, memoryID, entryID)
 }
 
+func (e *entries) Signals(ctx context.Context, userID string, entryIDs []string) (map[string]model.EntrySignals, error) {
+	out := make(map[string]model.EntrySignals, len(entryIDs))
+	if len(entryIDs) == 0 {
+		return out, nil
+	}
+	rows, err := e.db.QueryContext(ctx, `
+        SELECT entry_id, useful_count, incorrect_count, outdated_count
+        FROM memory_entries WHERE actor_id=$1 AND entry_id = ANY($2)
+    `, userID, entryIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var id string
+		var s model.EntrySignals
+		if err := rows.Scan(&id, &s.UsefulCount, &s.IncorrectCount, &s.OutdatedCount); err != nil {
+			return nil, err
+		}
+		out[id] = s
+	}
+	return out, rows.Err()
+}
+
 func (e *entries) DeleteByID(ctx context.Context, userID, vaultID, memoryID, entryID string) error {
 	tx, err := e.db.BeginTx(ctx, &sql.TxOptions{})
 	if err != nil {
@@ -575,7 +1329,7 @@ func (e *entries) DeleteByID(ctx context.Context, userID, vaultID, memoryID, ent
 		return err
 	}
 	if n, _ := res.RowsAffected(); n > 0 {
-		if err := writeOutbox(ctx, tx, "delete_entry", entryID, map[string]interface{}{"actorId": userID}); err != nil {
+		if err := writeOutbox(ctx, tx, entryID, payload.DeleteEntry(userID)); err != nil {
 			return err
 		}
 	}
@@ -586,32 +1340,67 @@ func (e *entries) DeleteByID(ctx context.Context, userID, vaultID, memoryID, ent
 type contexts struct{ db *sql.DB }
 
 func (c *contexts) Put(ctx context.Context, mc *model.MemoryContext) (*model.MemoryContext, error) {
+	return c.put(ctx, mc, nil)
+}
+
+func (c *contexts) PutIfLatest(ctx context.Context, mc *model.MemoryContext, latestID string) (*model.MemoryContext, error) {
+	return c.put(ctx, mc, &latestID)
+}
+
+// put inserts mc with its index upsert. Writers of a memory's contexts are
+// serialized on its row, so when latestID is set the latest context checked
+// against it is still the latest at insert.
+func (c *contexts) put(ctx context.Context, mc *model.MemoryContext, latestID *string) (*model.MemoryContext, error) {
 	tx, err := c.db.BeginTx(ctx, &sql.TxOptions{})
 	if err != nil {
 		return nil, err
 	}
 	defer func() { _ = tx.Rollback() }()
+	var locked int
+	err = tx.QueryRowContext(ctx, `SELECT 1 FROM memories WHERE actor_id=$1 AND vault_id=$2 AND memory_id=$3 FOR UPDATE`,
+		mc.ActorID, mc.VaultID, mc.MemoryID).Scan(&locked)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	if latestID != nil {
+		var current string
+		err := tx.QueryRowContext(ctx, `
+            SELECT context_id FROM memory_contexts WHERE actor_id=$1 AND vault_id=$2 AND memory_id=$3
+            ORDER BY creation_time DESC LIMIT 1
+        `, mc.ActorID, mc.VaultID, mc.MemoryID).Scan(&current)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		if current != *latestID {
+			return nil, fmt.Errorf("%w: the latest context is no longer %s", model.ErrPrecondition, *latestID)
+		}
+	}
 	ctxID := mc.ContextID
 	if ctxID == "" {
 		ctxID = uuid.New().String()
 	}
+	var sectionsJSON []byte
+	if len(mc.Sections) > 0 {
+		if sectionsJSON, err = json.Marshal(mc.Sections); err != nil {
+			return nil, err
+		}
+	}
+	// clock_timestamp rather than the transaction start, so a writer that
+	// waited for the lock still orders after the one it waited for.
 	var created time.Time
 	row := tx.QueryRowContext(ctx, `
-        INSERT INTO memory_contexts (actor_id, vault_id, memory_id, context_id, context)
-        VALUES ($1,$2,$3,$4,$5)
+        INSERT INTO memory_contexts (actor_id, vault_id, memory_id, context_id, context, sections, creation_time)
+        VALUES ($1,$2,$3,$4,$5,$6,clock_timestamp())
         RETURNING creation_time
-    `, mc.ActorID, mc.VaultID, mc.MemoryID, ctxID, mc.Context)
+    `, mc.ActorID, mc.VaultID, mc.MemoryID, ctxID, mc.Context, sectionsJSON)
 	if err := row.Scan(&created); err != nil {
 		return nil, err
 	}
-	payload := map[string]interface{}{
-		"actorId":      mc.ActorID,
-		"memoryId":     mc.MemoryID,
-		"contextId":    ctxID,
-		"context":      mc.Context,
-		"creationTime": created,
+	cp := &payload.Context{ActorID: mc.ActorID, MemoryID: mc.MemoryID, ContextID: ctxID, Context: mc.Context, CreationTime: created}
+	if err := addContextTitles(ctx, tx, cp); err != nil {
+		return nil, err
 	}
-	if err := writeOutbox(ctx, tx, "upsert_context", ctxID, payload); err != nil {
+	if err := writeOutbox(ctx, tx, ctxID, cp); err != nil {
 		return nil, err
 	}
 	if err := tx.Commit(); err != nil {
@@ -629,18 +1418,58 @@ func (c *contexts) Latest(ctx context.Context, userID, vaultID, memoryID string)
 	out.VaultID = vaultID
 	out.MemoryID = memoryID
 	var ctxText string
+	var sections sql.NullString
 	row := c.db.QueryRowContext(ctx, `
-        SELECT context_id, context, creation_time
+        SELECT context_id, context, creation_time, sections
         FROM memory_contexts WHERE actor_id=$1 AND vault_id=$2 AND memory_id=$3
         ORDER BY creation_time DESC LIMIT 1
     `, userID, vaultID, memoryID)
-	if err := row.Scan(&out.ContextID, &ctxText, &out.CreationTime); err != nil {
+	if err := row.Scan(&out.ContextID, &ctxText, &out.CreationTime, &sections); err != nil {
 		return nil, err
 	}
 	out.Context = ctxText
+	if err := scanContextSections(sections, &out); err != nil {
+		return nil, err
+	}
 	return &out, nil
 }
 
+func (c *contexts) LatestForMemories(ctx context.Context, userID string, memoryIDs []string) (map[string]*model.MemoryContext, error) {
+	out := make(map[string]*model.MemoryContext, len(memoryIDs))
+	if len(memoryIDs) == 0 {
+		return out, nil
+	}
+	rows, err := c.db.QueryContext(ctx, `
+        SELECT DISTINCT ON (memory_id) vault_id, memory_id, context_id, context, creation_time, sections
+        FROM memory_contexts WHERE actor_id=$1 AND memory_id = ANY($2)
+        ORDER BY memory_id, creation_time DESC
+    `, userID, memoryIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		mc := model.MemoryContext{ActorID: userID}
+		var sections sql.NullString
+		if err := rows.Scan(&mc.VaultID, &mc.MemoryID, &mc.ContextID, &mc.Context, &mc.CreationTime, &sections); err != nil {
+			return nil, err
+		}
+		if err := scanContextSections(sections, &mc); err != nil {
+			return nil, err
+		}
+		out[mc.MemoryID] = &mc
+	}
+	return out, rows.Err()
+}
+
+// scanContextSections decodes the sections column of a structured context.
+func scanContextSections(sections sql.NullString, mc *model.MemoryContext) error {
+	if !sections.Valid {
+		return nil
+	}
+	return json.Unmarshal([]byte(sections.String), &mc.Sections)
+}
+
 func (c *contexts) DeleteByID(ctx context.Context, userID, vaultID, memoryID, contextID string) error {
 	tx, err := c.db.BeginTx(ctx, &sql.TxOptions{})
 	if err != nil {
@@ -652,7 +1481,7 @@ func (c *contexts) DeleteByID(ctx context.Context, userID, vaultID, memoryID, co
 		return err
 	}
 	if n, _ := res.RowsAffected(); n > 0 {
-		if err := writeOutbox(ctx, tx, "delete_context", contextID, map[string]interface{}{"actorId": userID}); err != nil {
+		if err := writeOutbox(ctx, tx, contextID, payload.DeleteContext(userID)); err != nil {
 			return err
 		}
 	}
@@ -660,15 +1489,61 @@ func (c *contexts) DeleteByID(ctx context.Context, userID, vaultID, memoryID, co
 }
 
 // helpers
-func writeOutbox(ctx context.Context, tx *sql.Tx, op string, aggregateID string, payload map[string]interface{}) error {
-	b, err := json.Marshal(payload)
+// writeOutbox validates p and enqueues it at the current payload version.
+func writeOutbox(ctx context.Context, tx *sql.Tx, aggregateID string, p payload.Payload) error {
+	b, err := payload.Encode(p)
 	if err != nil {
 		return err
 	}
-	_, err = tx.ExecContext(ctx, `INSERT INTO outbox (aggregate_id, op, payload) VALUES ($1,$2,$3)`, aggregateID, op, b)
+	_, err = tx.ExecContext(ctx, `INSERT INTO outbox (aggregate_id, op, payload) VALUES ($1,$2,$3)`, aggregateID, p.Op(), b)
+	return err
+}
+
+// indexTitles returns the titles of a memory and its vault, which its search
+// index objects carry; both are empty when the memory does not exist.
+func indexTitles(ctx context.Context, tx *sql.Tx, actorID, memoryID string) (memoryTitle, vaultTitle string, err error) {
+	err = tx.QueryRowContext(ctx, `
+        SELECT m.title, v.title FROM memories m
+        JOIN vaults v ON v.actor_id = m.actor_id AND v.vault_id = m.vault_id
+        WHERE m.actor_id=$1 AND m.memory_id=$2
+    `, actorID, memoryID).Scan(&memoryTitle, &vaultTitle)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", "", nil
+	}
+	return memoryTitle, vaultTitle, err
+}
+
+// addContextTitles adds the memory and vault titles to a context payload.
+func addContextTitles(ctx context.Context, tx *sql.Tx, cp *payload.Context) error {
+	var err error
+	cp.MemoryTitle, cp.VaultTitle, err = indexTitles(ctx, tx, cp.ActorID, cp.MemoryID)
+	return err
+}
+
+// uniqueViolationSQLState is Postgres' unique_violation error code.
+const uniqueViolationSQLState = "23505"
+
+// titleConflict maps a unique violation on a title or slug to
+// model.ErrConflict.
+func titleConflict(err error, kind, title string) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationSQLState {
+		if strings.HasSuffix(pgErr.ConstraintName, "_slug_uq") {
+			return fmt.Errorf("%w: %s title %q has the same slug %q as an existing title", model.ErrConflict, kind, title, model.Slug(title))
+		}
+		return fmt.Errorf("%w: %s title %q already exists", model.ErrConflict, kind, title)
+	}
 	return err
 }
 
+// nullString maps "" to SQL NULL for optional text columns.
+func nullString(v string) interface{} {
+	if v == "" {
+		return nil
+	}
+	return v
+}
+
 func nullIfEmpty(b []byte) interface{} {
 	if len(b) == 0 {
 		return nil
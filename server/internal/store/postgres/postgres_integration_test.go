@@ -9,6 +9,11 @@ import (
 )
 
 func makePGStore(t *testing.T) store.Store {
+	t.Helper()
+	return makePGStoreWith(t)
+}
+
+func makePGStoreWith(t *testing.T, opts ...Option) store.Store {
 	t.Helper()
 	dsn := os.Getenv("MEMORY_SERVER_POSTGRES_DSN")
 	if dsn == "" {
@@ -18,9 +23,14 @@ func makePGStore(t *testing.T) store.Store {
 	if err != nil {
 		t.Fatalf("postgres open: %v", err)
 	}
-	return NewWithDB(db)
+	return NewWithDB(db, opts...)
 }
 
 func TestPostgresStore_Compliance(t *testing.T) {
 	storetest.Run(t, makePGStore)
 }
+
+// Entries that compress are stored as zstd; the contract must not change.
+func TestPostgresStore_CompressedEntriesCompliance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) store.Store { return makePGStoreWith(t, WithEntryCompression(1)) })
+}
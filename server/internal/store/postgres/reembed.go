@@ -0,0 +1,290 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/outbox/payload"
+)
+
+// --- Gradual re-embedding ---
+type reembed struct{ db *sql.DB }
+
+func (r *reembed) MarkDrift(ctx context.Context, fingerprint string) (int, error) {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// The first fingerprint is the baseline the existing index was built with.
+	res, err := tx.ExecContext(ctx, `INSERT INTO embedding_state (fingerprint) VALUES ($1) ON CONFLICT (singleton) DO NOTHING`, fingerprint)
+	if err != nil {
+		return 0, err
+	}
+	if n, _ := res.RowsAffected(); n == 1 {
+		return 0, tx.Commit()
+	}
+	// The row lock serialises replicas starting with the same new fingerprint.
+	var recorded string
+	if err := tx.QueryRowContext(ctx, `SELECT fingerprint FROM embedding_state FOR UPDATE`).Scan(&recorded); err != nil {
+		return 0, err
+	}
+	if recorded == fingerprint {
+		return 0, nil
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE embedding_state SET fingerprint=$1, update_time=now()`, fingerprint); err != nil {
+		return 0, err
+	}
+	// A memory still being re-embedded for an older fingerprint starts over.
+	res, err = tx.ExecContext(ctx, `
+        INSERT INTO reembed_memories (actor_id, memory_id, vault_id, job_id, fingerprint, entry_count)
+        SELECT m.actor_id, m.memory_id, m.vault_id, gen_random_uuid()::text, $1,
+               (SELECT count(*) FROM memory_entries e
+                WHERE e.actor_id = m.actor_id AND e.vault_id = m.vault_id AND e.memory_id = m.memory_id)
+        FROM memories m
+        ON CONFLICT (actor_id, memory_id) DO UPDATE SET
+            vault_id=EXCLUDED.vault_id, job_id=EXCLUDED.job_id, fingerprint=EXCLUDED.fingerprint,
+            entry_count=EXCLUDED.entry_count, context_count=0, enqueued=0, cursor_time=NULL,
+            cursor_entry_id=NULL, creation_time=now(), start_time=NULL, enqueued_time=NULL
+    `, fingerprint)
+	if err != nil {
+		return 0, err
+	}
+	n, _ := res.RowsAffected()
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return int(n), nil
+}
+
+func (r *reembed) EnqueueNext(ctx context.Context, limit int) (int, error) {
+	if limit <= 0 {
+		return 0, nil
+	}
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var pending int
+	if err := tx.QueryRowContext(ctx, `
+        SELECT count(*) FROM reembed_memories m JOIN outbox o ON o.job_id = m.job_id
+        WHERE o.status='pending'
+    `).Scan(&pending); err != nil {
+		return 0, err
+	}
+	budget := limit - pending
+	total := 0
+	for budget > 0 {
+		n, err := enqueueReembedPage(ctx, tx, budget)
+		if err != nil {
+			return 0, err
+		}
+		if n < 0 {
+			break
+		}
+		total += n
+		budget -= n
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// enqueueReembedPage enqueues up to limit records of the oldest memory not
+// fully enqueued and returns how many, or -1 when no memory is left. Entries
+// go in creation order after the memory's cursor; those created after it was
+// marked were embedded with the new configuration already.
+func enqueueReembedPage(ctx context.Context, tx *sql.Tx, limit int) (int, error) {
+	var (
+		actorID, memoryID, jobID string
+		marked                   time.Time
+		cursorTime, startTime    sql.NullTime
+		cursorEntryID            sql.NullString
+	)
+	err := tx.QueryRowContext(ctx, `
+        SELECT actor_id, memory_id, job_id, cursor_time, cursor_entry_id, creation_time, start_time
+        FROM reembed_memories WHERE enqueued_time IS NULL
+        ORDER BY creation_time, memory_id
+        LIMIT 1 FOR UPDATE SKIP LOCKED
+    `).Scan(&actorID, &memoryID, &jobID, &cursorTime, &cursorEntryID, &marked, &startTime)
+	if errors.Is(err, sql.ErrNoRows) {
+		return -1, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	// The memory may have moved vaults or been deleted since it was marked.
+	var vaultID string
+	err = tx.QueryRowContext(ctx, `SELECT vault_id FROM memories WHERE actor_id=$1 AND memory_id=$2`, actorID, memoryID).Scan(&vaultID)
+	if errors.Is(err, sql.ErrNoRows) {
+		_, err = tx.ExecContext(ctx, `
+            UPDATE reembed_memories SET start_time=COALESCE(start_time, now()), enqueued_time=now()
+            WHERE actor_id=$1 AND memory_id=$2
+        `, actorID, memoryID)
+		return 0, err
+	}
+	if err != nil {
+		return 0, err
+	}
+	memoryTitle, vaultTitle, err := indexTitles(ctx, tx, actorID, memoryID)
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	if !startTime.Valid {
+		res, err := tx.ExecContext(ctx, enqueueContextUpsertsSQL, actorID, vaultID, memoryID, jobID, memoryTitle, vaultTitle, payload.Version)
+		if err != nil {
+			return 0, err
+		}
+		contexts, _ := res.RowsAffected()
+		if _, err := tx.ExecContext(ctx, `
+            UPDATE reembed_memories SET start_time=now(), context_count=$3 WHERE actor_id=$1 AND memory_id=$2
+        `, actorID, memoryID, contexts); err != nil {
+			return 0, err
+		}
+		n = int(contexts)
+	}
+	if n >= limit {
+		return n, nil
+	}
+	pageSize := limit - n
+
+	rows, err := tx.QueryContext(ctx, `
+        SELECT entry_id, creation_time FROM memory_entries
+        WHERE actor_id=$1 AND vault_id=$2 AND memory_id=$3 AND creation_time <= $4
+          AND ($5::timestamptz IS NULL OR (creation_time, entry_id) > ($5::timestamptz, $6::text))
+        ORDER BY creation_time, entry_id
+        LIMIT $7
+    `, actorID, vaultID, memoryID, marked, cursorTime, cursorEntryID, pageSize)
+	if err != nil {
+		return 0, err
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		var ct time.Time
+		if err := rows.Scan(&id, &ct); err != nil {
+			_ = rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+		cursorTime, cursorEntryID = sql.NullTime{Time: ct, Valid: true}, sql.NullString{String: id, Valid: true}
+	}
+	if err := rows.Close(); err != nil {
+		return 0, err
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	if len(ids) > 0 {
+		if _, err := tx.ExecContext(ctx, enqueueEntryUpsertsSQL, actorID, vaultID, memoryID, jobID, memoryTitle, vaultTitle, ids, payload.Version); err != nil {
+			return 0, err
+		}
+		if err := fillCompressedRawEntries(ctx, tx, actorID, vaultID, memoryID, jobID, ids); err != nil {
+			return 0, err
+		}
+	}
+	// A short page was the last one.
+	if _, err := tx.ExecContext(ctx, `
+        UPDATE reembed_memories SET enqueued=enqueued+$3, cursor_time=$4, cursor_entry_id=$5,
+            enqueued_time=CASE WHEN $6::boolean THEN now() END
+        WHERE actor_id=$1 AND memory_id=$2
+    `, actorID, memoryID, len(ids), cursorTime, cursorEntryID, len(ids) < pageSize); err != nil {
+		return 0, err
+	}
+	return n + len(ids), nil
+}
+
+func (r *reembed) Progress(ctx context.Context, actorID, memoryID string) (*model.ReembedProgress, error) {
+	p := model.ReembedProgress{ActorID: actorID, MemoryID: memoryID}
+	var startTime, enqueuedTime sql.NullTime
+	err := r.db.QueryRowContext(ctx, `
+        SELECT m.job_id, m.vault_id, m.fingerprint, m.entry_count, m.context_count, m.enqueued,
+               m.creation_time, m.start_time, m.enqueued_time,
+               COUNT(o.id) FILTER (WHERE o.status='done'),
+               COUNT(o.id) FILTER (WHERE o.status='pending'),
+               COUNT(o.id) FILTER (WHERE o.status='dead')
+        FROM reembed_memories m LEFT JOIN outbox o ON o.job_id = m.job_id
+        WHERE m.actor_id=$1 AND m.memory_id=$2
+        GROUP BY m.job_id, m.vault_id, m.fingerprint, m.entry_count, m.context_count, m.enqueued,
+                 m.creation_time, m.start_time, m.enqueued_time
+    `, actorID, memoryID).Scan(&p.JobID, &p.VaultID, &p.Fingerprint, &p.EntryCount, &p.ContextCount, &p.Enqueued,
+		&p.CreationTime, &startTime, &enqueuedTime, &p.Done, &p.Pending, &p.Failed)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: memory %s was not marked for re-embedding", model.ErrNotFound, memoryID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if startTime.Valid {
+		p.StartTime = &startTime.Time
+	}
+	if enqueuedTime.Valid {
+		p.EnqueuedTime = &enqueuedTime.Time
+	}
+	p.Status = reembedStatus(startTime.Valid, enqueuedTime.Valid, p.Pending > 0)
+	return &p, nil
+}
+
+func (r *reembed) Overview(ctx context.Context) (*model.ReembedOverview, error) {
+	o := model.ReembedOverview{Memories: map[string]int{}}
+	err := r.db.QueryRowContext(ctx, `SELECT fingerprint FROM embedding_state`).Scan(&o.Fingerprint)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &o, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT started, enqueued, pending, count(*), sum(entry_count), sum(entries_enqueued)
+        FROM (
+            SELECT m.start_time IS NOT NULL AS started, m.enqueued_time IS NOT NULL AS enqueued,
+                   EXISTS (SELECT 1 FROM outbox o WHERE o.job_id = m.job_id AND o.status='pending') AS pending,
+                   m.entry_count, m.enqueued AS entries_enqueued
+            FROM reembed_memories m WHERE m.fingerprint=$1
+        ) s
+        GROUP BY started, enqueued, pending
+    `, o.Fingerprint)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var started, enqueued, pending bool
+		var memories, entries, done int
+		if err := rows.Scan(&started, &enqueued, &pending, &memories, &entries, &done); err != nil {
+			return nil, err
+		}
+		status := reembedStatus(started, enqueued, pending)
+		o.Memories[status] += memories
+		o.EntryCount += entries
+		o.Enqueued += done
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+// reembedStatus derives a memory's re-embedding status.
+func reembedStatus(started, enqueued, pending bool) string {
+	switch {
+	case !started:
+		return model.ReembedQueued
+	case enqueued && !pending:
+		return model.ReembedCompleted
+	default:
+		return model.ReembedRunning
+	}
+}
@@ -0,0 +1,200 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/outbox/payload"
+)
+
+// --- Reindex jobs ---
+type reindex struct{ db *sql.DB }
+
+func (r *reindex) Start(ctx context.Context, actorID, memoryID string) (*model.ReindexJob, error) {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	job := model.ReindexJob{JobID: uuid.New().String(), ActorID: actorID, MemoryID: memoryID, Status: model.ReindexRunning}
+	err = tx.QueryRowContext(ctx, `SELECT vault_id FROM memories WHERE actor_id=$1 AND memory_id=$2`, actorID, memoryID).Scan(&job.VaultID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: memory %s", model.ErrNotFound, memoryID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// One reindex per memory at a time, across replicas: a second job would
+	// enqueue (and index) every entry again. The lock serialises starts; the
+	// check rejects a start while an earlier job still has pending rows.
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtextextended('reindex:' || $1 || ':' || $2, 0))`, actorID, memoryID); err != nil {
+		return nil, err
+	}
+	var running bool
+	err = tx.QueryRowContext(ctx, `
+        SELECT EXISTS (
+            SELECT 1 FROM reindex_jobs j JOIN outbox o ON o.job_id = j.job_id
+            WHERE j.actor_id=$1 AND j.memory_id=$2 AND o.status='pending')
+    `, actorID, memoryID).Scan(&running)
+	if err != nil {
+		return nil, err
+	}
+	if running {
+		return nil, fmt.Errorf("%w: a reindex of memory %s is still running", model.ErrConflict, memoryID)
+	}
+
+	if err := enqueueReindex(ctx, tx, &job); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	job.Pending = job.EntryCount + job.ContextCount
+	if job.Pending == 0 {
+		job.Status = model.ReindexCompleted
+	}
+	return &job, nil
+}
+
+func (r *reindex) Latest(ctx context.Context, actorID, memoryID string) (*model.ReindexJob, error) {
+	job := model.ReindexJob{ActorID: actorID, MemoryID: memoryID}
+	err := r.db.QueryRowContext(ctx, `
+        SELECT j.job_id, j.vault_id, j.entry_count, j.context_count, j.creation_time,
+               COUNT(o.id) FILTER (WHERE o.status='done'),
+               COUNT(o.id) FILTER (WHERE o.status='pending'),
+               COUNT(o.id) FILTER (WHERE o.status='pending' AND o.attempt_count > 0),
+               COUNT(o.id) FILTER (WHERE o.status='dead')
+        FROM reindex_jobs j LEFT JOIN outbox o ON o.job_id = j.job_id
+        WHERE j.actor_id=$1 AND j.memory_id=$2
+        GROUP BY j.job_id, j.vault_id, j.entry_count, j.context_count, j.creation_time
+        ORDER BY j.creation_time DESC
+        LIMIT 1
+    `, actorID, memoryID).Scan(&job.JobID, &job.VaultID, &job.EntryCount, &job.ContextCount, &job.CreationTime,
+		&job.Done, &job.Pending, &job.Retrying, &job.Failed)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: no reindex job for memory %s", model.ErrNotFound, memoryID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	job.Status = model.ReindexRunning
+	if job.Pending == 0 {
+		job.Status = model.ReindexCompleted
+	}
+	return &job, nil
+}
+
+// enqueueEntryUpsertsSQL enqueues upserts of a memory's entries under a job:
+// $1-$3 actor, vault and memory, $4 the job, $5-$6 the memory and vault
+// titles, $7 the entry IDs, NULL or empty for all, and $8 payload.Version.
+const enqueueEntryUpsertsSQL = `
+        INSERT INTO outbox (aggregate_id, op, payload, job_id)
+        SELECT entry_id, 'upsert_entry', jsonb_build_object(
+                   'v', $8::int, 'actorId', actor_id, 'memoryId', memory_id, 'entryId', entry_id, 'rawEntry', raw_entry,
+                   'summary', summary, 'tags', tags, 'tagPairs', ` + payload.TagPairsSQL + `, 'creationTime', creation_time,
+                   'memoryTitle', $5::text, 'vaultTitle', $6::text)
+                   || CASE WHEN session_id IS NULL THEN '{}'::jsonb ELSE jsonb_build_object('sessionId', session_id) END, $4
+        FROM memory_entries WHERE actor_id=$1 AND vault_id=$2 AND memory_id=$3
+          AND (COALESCE(cardinality($7::text[]), 0) = 0 OR entry_id = ANY($7::text[]))
+        ORDER BY creation_time, entry_id`
+
+// enqueueContextUpsertsSQL enqueues upserts of every context of a memory,
+// with the parameters of enqueueEntryUpsertsSQL but the entry IDs; $7 is
+// payload.Version.
+const enqueueContextUpsertsSQL = `
+        INSERT INTO outbox (aggregate_id, op, payload, job_id)
+        SELECT context_id, 'upsert_context', jsonb_build_object(
+                   'v', $7::int, 'actorId', actor_id, 'memoryId', memory_id, 'contextId', context_id, 'context', context,
+                   'creationTime', creation_time, 'memoryTitle', $5::text, 'vaultTitle', $6::text), $4
+        FROM memory_contexts WHERE actor_id=$1 AND vault_id=$2 AND memory_id=$3
+        ORDER BY creation_time`
+
+// enqueueReindex enqueues upserts of every entry and context of job's memory
+// under job.JobID, sets its counts and records the job.
+func enqueueReindex(ctx context.Context, tx *sql.Tx, job *model.ReindexJob) error {
+	actorID, memoryID := job.ActorID, job.MemoryID
+	memoryTitle, vaultTitle, err := indexTitles(ctx, tx, actorID, memoryID)
+	if err != nil {
+		return err
+	}
+
+	// Payloads mirror what entries.Create and contexts.Put enqueue.
+	res, err := tx.ExecContext(ctx, enqueueEntryUpsertsSQL, actorID, job.VaultID, memoryID, job.JobID, memoryTitle, vaultTitle, nil, payload.Version)
+	if err != nil {
+		return err
+	}
+	n, _ := res.RowsAffected()
+	job.EntryCount = int(n)
+	if err := fillCompressedRawEntries(ctx, tx, actorID, job.VaultID, memoryID, job.JobID, nil); err != nil {
+		return err
+	}
+
+	res, err = tx.ExecContext(ctx, enqueueContextUpsertsSQL, actorID, job.VaultID, memoryID, job.JobID, memoryTitle, vaultTitle, payload.Version)
+	if err != nil {
+		return err
+	}
+	n, _ = res.RowsAffected()
+	job.ContextCount = int(n)
+
+	err = tx.QueryRowContext(ctx, `
+        INSERT INTO reindex_jobs (actor_id, job_id, vault_id, memory_id, entry_count, context_count)
+        VALUES ($1,$2,$3,$4,$5,$6)
+        RETURNING creation_time
+    `, actorID, job.JobID, job.VaultID, memoryID, job.EntryCount, job.ContextCount).Scan(&job.CreationTime)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// fillCompressedRawEntries sets rawEntry in the job's entry payloads for
+// entries stored compressed, which the SQL payload builder reads as "".
+// entryIDs limits it to those entries; nil covers the whole memory.
+func fillCompressedRawEntries(ctx context.Context, tx *sql.Tx, actorID, vaultID, memoryID, jobID string, entryIDs []string) error {
+	rows, err := tx.QueryContext(ctx, `
+        SELECT entry_id, raw_entry, raw_entry_encoding, raw_entry_zstd FROM memory_entries
+        WHERE actor_id=$1 AND vault_id=$2 AND memory_id=$3 AND raw_entry_encoding IS NOT NULL
+          AND (COALESCE(cardinality($4::text[]), 0) = 0 OR entry_id = ANY($4::text[]))
+    `, actorID, vaultID, memoryID, entryIDs)
+	if err != nil {
+		return err
+	}
+	type compressedEntry struct{ entryID, raw string }
+	var entries []compressedEntry
+	for rows.Next() {
+		var id, raw string
+		var encoding sql.NullString
+		var blob []byte
+		if err := rows.Scan(&id, &raw, &encoding, &blob); err != nil {
+			_ = rows.Close()
+			return err
+		}
+		if raw, err = decodeRawEntry(raw, encoding, blob); err != nil {
+			_ = rows.Close()
+			return fmt.Errorf("entry %s: %w", id, err)
+		}
+		entries = append(entries, compressedEntry{id, raw})
+	}
+	if err := rows.Close(); err != nil {
+		return err
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if _, err := tx.ExecContext(ctx, `
+            UPDATE outbox SET payload = jsonb_set(payload, '{rawEntry}', to_jsonb($1::text))
+            WHERE job_id=$2 AND aggregate_id=$3 AND op='upsert_entry'
+        `, e.raw, jobID, e.entryID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
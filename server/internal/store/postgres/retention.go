@@ -0,0 +1,104 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/mycelian/mycelian-memory/server/internal/outbox/payload"
+)
+
+func (e *entries) Touch(ctx context.Context, userID string, entryIDs []string, at time.Time) error {
+	if len(entryIDs) == 0 {
+		return nil
+	}
+	_, err := e.db.ExecContext(ctx, `
+        UPDATE memory_entries SET last_accessed_time=$3
+        WHERE actor_id=$1 AND entry_id = ANY($2) AND (last_accessed_time IS NULL OR last_accessed_time < $3)
+    `, userID, entryIDs, at)
+	return err
+}
+
+func (e *entries) Expire(ctx context.Context, cutoff time.Time, byAccess bool, limit int) ([]string, error) {
+	age := "e.creation_time"
+	if byAccess {
+		age = "COALESCE(e.last_accessed_time, e.creation_time)"
+	}
+	return e.deleteOldest(ctx, age+" < $1", age, cutoff, limit)
+}
+
+func (e *entries) ExpireDue(ctx context.Context, now time.Time, limit int) ([]string, error) {
+	return e.deleteOldest(ctx, "e.expiration_time <= $1", "e.expiration_time", now, limit)
+}
+
+func (e *entries) Expired(ctx context.Context, userID string, entryIDs []string, now time.Time) (map[string]bool, error) {
+	out := map[string]bool{}
+	if len(entryIDs) == 0 {
+		return out, nil
+	}
+	rows, err := e.db.QueryContext(ctx, `
+        SELECT entry_id FROM memory_entries
+        WHERE actor_id=$1 AND entry_id = ANY($2) AND expiration_time <= $3
+    `, userID, entryIDs, now)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		out[id] = true
+	}
+	return out, rows.Err()
+}
+
+// deleteOldest deletes up to limit entries of writable vaults matching
+// where, in which $1 is bound to arg, in order of orderBy, and enqueues
+// their index deletes.
+func (e *entries) deleteOldest(ctx context.Context, where, orderBy string, arg time.Time, limit int) ([]string, error) {
+	tx, err := e.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	rows, err := tx.QueryContext(ctx, `
+        WITH doomed AS (
+            SELECT e.entry_id FROM memory_entries e
+            JOIN vaults v ON v.actor_id=e.actor_id AND v.vault_id=e.vault_id
+            WHERE NOT v.read_only AND `+where+`
+            ORDER BY `+orderBy+`
+            LIMIT $2
+            FOR UPDATE OF e SKIP LOCKED
+        )
+        DELETE FROM memory_entries m USING doomed d WHERE m.entry_id=d.entry_id
+        RETURNING m.entry_id, m.actor_id
+    `, arg, limit)
+	if err != nil {
+		return nil, err
+	}
+	type gone struct{ entryID, actorID string }
+	var deleted []gone
+	for rows.Next() {
+		var g gone
+		if err := rows.Scan(&g.entryID, &g.actorID); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		deleted = append(deleted, g)
+	}
+	if err := rows.Err(); err != nil {
+		_ = rows.Close()
+		return nil, err
+	}
+	_ = rows.Close()
+	ids := make([]string, 0, len(deleted))
+	for _, g := range deleted {
+		if err := writeOutbox(ctx, tx, g.entryID, payload.DeleteEntry(g.actorID)); err != nil {
+			return nil, err
+		}
+		ids = append(ids, g.entryID)
+	}
+	return ids, tx.Commit()
+}
@@ -0,0 +1,147 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+// --- Search log ---
+type searchLog struct{ db *sql.DB }
+
+func (l *searchLog) RecordQuery(ctx context.Context, q *model.SearchQuery) (*model.SearchQuery, error) {
+	out := *q
+	if out.QueryID == "" {
+		out.QueryID = uuid.New().String()
+	}
+	if out.EntryIDs == nil {
+		out.EntryIDs = []string{}
+	}
+	idsJSON, err := json.Marshal(out.EntryIDs)
+	if err != nil {
+		return nil, err
+	}
+	var scoresJSON []byte
+	if out.Scores != nil {
+		if scoresJSON, err = json.Marshal(out.Scores); err != nil {
+			return nil, err
+		}
+	}
+	row := l.db.QueryRowContext(ctx, `
+        INSERT INTO search_queries (actor_id, query_id, memory_id, query, top_k, alpha, entry_ids, scores)
+        VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+        RETURNING creation_time
+    `, out.ActorID, out.QueryID, out.MemoryID, out.Query, out.TopK, out.Alpha, idsJSON, scoresJSON)
+	if err := row.Scan(&out.CreationTime); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+const searchQueryColumns = `query_id, memory_id, query, top_k, alpha, entry_ids, scores, useful_entry_ids, feedback_time, creation_time`
+
+func scanSearchQuery(row interface{ Scan(...any) error }, actorID string) (*model.SearchQuery, error) {
+	out := model.SearchQuery{ActorID: actorID}
+	var ids []byte
+	var scores, useful sql.NullString
+	var fbTime sql.NullTime
+	if err := row.Scan(&out.QueryID, &out.MemoryID, &out.Query, &out.TopK, &out.Alpha, &ids, &scores, &useful, &fbTime, &out.CreationTime); err != nil {
+		return nil, err
+	}
+	_ = json.Unmarshal(ids, &out.EntryIDs)
+	if scores.Valid {
+		_ = json.Unmarshal([]byte(scores.String), &out.Scores)
+	}
+	if useful.Valid {
+		_ = json.Unmarshal([]byte(useful.String), &out.UsefulEntryIDs)
+	}
+	if fbTime.Valid {
+		t := fbTime.Time
+		out.FeedbackTime = &t
+	}
+	return &out, nil
+}
+
+func (l *searchLog) GetQuery(ctx context.Context, actorID, queryID string) (*model.SearchQuery, error) {
+	row := l.db.QueryRowContext(ctx, `
+        SELECT `+searchQueryColumns+`
+        FROM search_queries WHERE actor_id=$1 AND query_id=$2
+    `, actorID, queryID)
+	out, err := scanSearchQuery(row, actorID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, model.ErrNotFound
+	}
+	return out, err
+}
+
+func (l *searchLog) List(ctx context.Context, actorID, memoryID string, since *time.Time, limit int) ([]*model.SearchQuery, error) {
+	rows, err := l.db.QueryContext(ctx, `
+        SELECT `+searchQueryColumns+`
+        FROM search_queries
+        WHERE actor_id=$1 AND ($2 = '' OR memory_id=$2) AND ($3::timestamptz IS NULL OR creation_time >= $3)
+        ORDER BY creation_time, query_id
+        LIMIT $4
+    `, actorID, memoryID, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var out []*model.SearchQuery
+	for rows.Next() {
+		q, err := scanSearchQuery(rows, actorID)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, q)
+	}
+	return out, rows.Err()
+}
+
+func (l *searchLog) RecordFeedback(ctx context.Context, actorID, queryID string, usefulEntryIDs []string) error {
+	if usefulEntryIDs == nil {
+		usefulEntryIDs = []string{}
+	}
+	b, err := json.Marshal(usefulEntryIDs)
+	if err != nil {
+		return err
+	}
+	res, err := l.db.ExecContext(ctx, `UPDATE search_queries SET useful_entry_ids=$1, feedback_time=now() WHERE actor_id=$2 AND query_id=$3`, b, actorID, queryID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return model.ErrNotFound
+	}
+	return nil
+}
+
+func (l *searchLog) Precision(ctx context.Context, actorID, memoryID string, since *time.Time) (*model.SearchPrecision, error) {
+	var out model.SearchPrecision
+	var mean sql.NullFloat64
+	row := l.db.QueryRowContext(ctx, `
+        SELECT count(*),
+               count(feedback_time),
+               coalesce(sum(jsonb_array_length(entry_ids)) FILTER (WHERE feedback_time IS NOT NULL), 0),
+               coalesce(sum(jsonb_array_length(useful_entry_ids)) FILTER (WHERE feedback_time IS NOT NULL), 0),
+               avg(jsonb_array_length(useful_entry_ids)::float8 / jsonb_array_length(entry_ids))
+                   FILTER (WHERE feedback_time IS NOT NULL AND jsonb_array_length(entry_ids) > 0)
+        FROM search_queries
+        WHERE actor_id=$1 AND ($2 = '' OR memory_id=$2) AND ($3::timestamptz IS NULL OR creation_time >= $3)
+    `, actorID, memoryID, since)
+	if err := row.Scan(&out.Queries, &out.JudgedQueries, &out.ReturnedResults, &out.UsefulResults, &mean); err != nil {
+		return nil, err
+	}
+	if out.ReturnedResults > 0 {
+		out.Precision = float64(out.UsefulResults) / float64(out.ReturnedResults)
+	}
+	if mean.Valid {
+		out.MeanPrecision = mean.Float64
+	}
+	return &out, nil
+}
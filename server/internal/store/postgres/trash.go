@@ -0,0 +1,230 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/outbox/payload"
+)
+
+// deletionRow scans a row selected with one extra trailing deleted_at
+// column, so the usual scanners can read trash listings.
+type deletionRow struct {
+	row       interface{ Scan(dest ...any) error }
+	deletedAt *time.Time
+}
+
+func (r deletionRow) Scan(dest ...any) error {
+	return r.row.Scan(append(dest, r.deletedAt)...)
+}
+
+func (m *memories) Trash(ctx context.Context, userID, vaultID, memoryID string) error {
+	_, err := m.db.ExecContext(ctx, `
+        UPDATE memories SET deleted_at=now()
+        WHERE actor_id=$1 AND vault_id=$2 AND memory_id=$3 AND deleted_at IS NULL
+    `, userID, vaultID, memoryID)
+	return err
+}
+
+// Restore fails with model.ErrConflict when a live memory of the vault has
+// since taken the title or slug.
+func (m *memories) Restore(ctx context.Context, userID, vaultID, memoryID string) (*model.Memory, error) {
+	var title string
+	err := m.db.QueryRowContext(ctx, `
+        SELECT title FROM memories
+        WHERE actor_id=$1 AND vault_id=$2 AND memory_id=$3 AND deleted_at IS NOT NULL
+    `, userID, vaultID, memoryID).Scan(&title)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, model.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	res, err := m.db.ExecContext(ctx, `
+        UPDATE memories SET deleted_at=NULL
+        WHERE actor_id=$1 AND vault_id=$2 AND memory_id=$3 AND deleted_at IS NOT NULL
+    `, userID, vaultID, memoryID)
+	if err != nil {
+		return nil, titleConflict(err, "memory", title)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, model.ErrNotFound
+	}
+	return m.GetByID(ctx, userID, vaultID, memoryID)
+}
+
+func (m *memories) ListTrash(ctx context.Context, userID, vaultID string) ([]*model.Memory, error) {
+	rows, err := m.db.QueryContext(ctx, `
+        SELECT memory_id, memory_type, title, COALESCE(slug, ''), description, creation_time, append_only, entry_roles, search_boost, entry_ttl_seconds, deleted_at
+        FROM memories WHERE actor_id=$1 AND vault_id=$2 AND deleted_at IS NOT NULL ORDER BY deleted_at DESC
+    `, userID, vaultID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var out []*model.Memory
+	for rows.Next() {
+		var mm model.Memory
+		mm.ActorID = userID
+		mm.VaultID = vaultID
+		var roles, boost sql.NullString
+		var deleted time.Time
+		if err := rows.Scan(&mm.MemoryID, &mm.MemoryType, &mm.Title, &mm.Slug, &mm.Description, &mm.CreationTime, &mm.AppendOnly, &roles, &boost, &mm.EntryTTLSeconds, &deleted); err != nil {
+			return nil, err
+		}
+		mm.EntryRoles = decodeEntryRoles(roles)
+		mm.SearchBoost = decodeSearchBoost(boost)
+		mm.DeletionTime = &deleted
+		out = append(out, &mm)
+	}
+	return out, rows.Err()
+}
+
+func (m *memories) PurgeTrash(ctx context.Context, cutoff time.Time, limit int) (int, error) {
+	rows, err := m.db.QueryContext(ctx, `
+        SELECT actor_id, vault_id, memory_id FROM memories
+        WHERE deleted_at < $1 ORDER BY deleted_at LIMIT $2
+    `, cutoff, limit)
+	if err != nil {
+		return 0, err
+	}
+	type doomed struct{ actorID, vaultID, memoryID string }
+	var purge []doomed
+	for rows.Next() {
+		var d doomed
+		if err := rows.Scan(&d.actorID, &d.vaultID, &d.memoryID); err != nil {
+			_ = rows.Close()
+			return 0, err
+		}
+		purge = append(purge, d)
+	}
+	if err := rows.Err(); err != nil {
+		_ = rows.Close()
+		return 0, err
+	}
+	_ = rows.Close()
+	for i, d := range purge {
+		if err := m.Delete(ctx, d.actorID, d.vaultID, d.memoryID); err != nil {
+			return i, err
+		}
+	}
+	return len(purge), nil
+}
+
+func (e *entries) Trash(ctx context.Context, userID, vaultID, memoryID, entryID string) error {
+	_, err := e.db.ExecContext(ctx, `
+        UPDATE memory_entries SET deleted_at=now()
+        WHERE actor_id=$1 AND vault_id=$2 AND memory_id=$3 AND entry_id=$4 AND deleted_at IS NULL
+    `, userID, vaultID, memoryID, entryID)
+	return err
+}
+
+func (e *entries) Restore(ctx context.Context, userID, vaultID, memoryID, entryID string) (*model.MemoryEntry, error) {
+	res, err := e.db.ExecContext(ctx, `
+        UPDATE memory_entries SET deleted_at=NULL
+        WHERE actor_id=$1 AND vault_id=$2 AND memory_id=$3 AND entry_id=$4 AND deleted_at IS NOT NULL
+    `, userID, vaultID, memoryID, entryID)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, model.ErrNotFound
+	}
+	return e.GetByID(ctx, userID, vaultID, memoryID, entryID)
+}
+
+func (e *entries) ListTrash(ctx context.Context, userID, vaultID string, limit int) ([]*model.MemoryEntry, error) {
+	rows, err := e.db.QueryContext(ctx, `
+        SELECT `+entryColumns+`, deleted_at FROM memory_entries
+        WHERE actor_id=$1 AND vault_id=$2 AND deleted_at IS NOT NULL
+          AND memory_id IN (SELECT memory_id FROM memories WHERE actor_id=$1 AND vault_id=$2 AND deleted_at IS NULL)
+        ORDER BY deleted_at DESC LIMIT $3
+    `, userID, vaultID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var out []*model.MemoryEntry
+	for rows.Next() {
+		var deleted time.Time
+		me, err := scanEntry(deletionRow{row: rows, deletedAt: &deleted})
+		if err != nil {
+			return nil, err
+		}
+		me.DeletionTime = &deleted
+		out = append(out, me)
+	}
+	return out, rows.Err()
+}
+
+func (e *entries) Trashed(ctx context.Context, userID string, entryIDs []string) (map[string]bool, error) {
+	out := map[string]bool{}
+	if len(entryIDs) == 0 {
+		return out, nil
+	}
+	rows, err := e.db.QueryContext(ctx, `
+        SELECT e.entry_id FROM memory_entries e
+        JOIN memories m ON m.actor_id=e.actor_id AND m.vault_id=e.vault_id AND m.memory_id=e.memory_id
+        WHERE e.actor_id=$1 AND e.entry_id = ANY($2) AND (e.deleted_at IS NOT NULL OR m.deleted_at IS NOT NULL)
+    `, userID, entryIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		out[id] = true
+	}
+	return out, rows.Err()
+}
+
+func (e *entries) PurgeTrash(ctx context.Context, cutoff time.Time, limit int) ([]string, error) {
+	tx, err := e.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	rows, err := tx.QueryContext(ctx, `
+        WITH doomed AS (
+            SELECT entry_id FROM memory_entries
+            WHERE deleted_at < $1
+            ORDER BY deleted_at
+            LIMIT $2
+            FOR UPDATE SKIP LOCKED
+        )
+        DELETE FROM memory_entries m USING doomed d WHERE m.entry_id=d.entry_id
+        RETURNING m.entry_id, m.actor_id
+    `, cutoff, limit)
+	if err != nil {
+		return nil, err
+	}
+	type gone struct{ entryID, actorID string }
+	var deleted []gone
+	for rows.Next() {
+		var g gone
+		if err := rows.Scan(&g.entryID, &g.actorID); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		deleted = append(deleted, g)
+	}
+	if err := rows.Err(); err != nil {
+		_ = rows.Close()
+		return nil, err
+	}
+	_ = rows.Close()
+	ids := make([]string, 0, len(deleted))
+	for _, g := range deleted {
+		if err := writeOutbox(ctx, tx, g.entryID, payload.DeleteEntry(g.actorID)); err != nil {
+			return nil, err
+		}
+		ids = append(ids, g.entryID)
+	}
+	return ids, tx.Commit()
+}
@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+// --- Inbound webhooks ---
+type webhooks struct{ db *sql.DB }
+
+const webhookColumns = `webhook_id, actor_id, vault_id, memory_id, name, kind, secret, mapping, creation_time, last_delivery_time, delivery_count`
+
+func (r *webhooks) Create(ctx context.Context, w *model.Webhook) (*model.Webhook, error) {
+	out := *w
+	mappingJSON, err := json.Marshal(w.Mapping)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.db.QueryRowContext(ctx, `
+        INSERT INTO webhooks (webhook_id, actor_id, vault_id, memory_id, name, kind, secret, mapping)
+        VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+        RETURNING creation_time
+    `, w.WebhookID, w.ActorID, w.VaultID, w.MemoryID, w.Name, w.Kind, w.Secret, mappingJSON).Scan(&out.CreationTime); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationSQLState {
+			return nil, fmt.Errorf("%w: webhook %q already exists in the memory", model.ErrConflict, w.Name)
+		}
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *webhooks) Get(ctx context.Context, webhookID string) (*model.Webhook, error) {
+	w, err := scanWebhook(r.db.QueryRowContext(ctx, `SELECT `+webhookColumns+` FROM webhooks WHERE webhook_id=$1`, webhookID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, model.ErrNotFound
+	}
+	return w, err
+}
+
+func (r *webhooks) List(ctx context.Context, actorID, memoryID string) ([]*model.Webhook, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+webhookColumns+` FROM webhooks WHERE actor_id=$1 AND memory_id=$2 ORDER BY name`, actorID, memoryID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var out []*model.Webhook
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, w)
+	}
+	return out, rows.Err()
+}
+
+func (r *webhooks) Delete(ctx context.Context, actorID, vaultID, memoryID, webhookID string) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM webhooks WHERE actor_id=$1 AND vault_id=$2 AND memory_id=$3 AND webhook_id=$4`,
+		actorID, vaultID, memoryID, webhookID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return model.ErrNotFound
+	}
+	return nil
+}
+
+func (r *webhooks) RecordDelivery(ctx context.Context, webhookID string, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+        UPDATE webhooks SET delivery_count = delivery_count + 1,
+            last_delivery_time = GREATEST(COALESCE(last_delivery_time, $2), $2)
+        WHERE webhook_id=$1`, webhookID, at)
+	return err
+}
+
+func scanWebhook(row interface{ Scan(...any) error }) (*model.Webhook, error) {
+	var w model.Webhook
+	var mappingJSON []byte
+	var last sql.NullTime
+	if err := row.Scan(&w.WebhookID, &w.ActorID, &w.VaultID, &w.MemoryID, &w.Name, &w.Kind, &w.Secret, &mappingJSON,
+		&w.CreationTime, &last, &w.DeliveryCount); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(mappingJSON, &w.Mapping); err != nil {
+		return nil, fmt.Errorf("webhook %s mapping: %w", w.WebhookID, err)
+	}
+	if last.Valid {
+		w.LastDeliveryTime = &last.Time
+	}
+	return &w, nil
+}
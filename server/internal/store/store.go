@@ -2,10 +2,17 @@ package store
 
 import (
 	"context"
+	"time"
 
+	"github.com/mycelian/mycelian-memory/pkg/schema"
 	"github.com/mycelian/mycelian-memory/server/internal/model"
 )
 
+// SchemaVersion identifies the storage schema revision this build expects:
+// the schema-version line of pkg/schema/schema.sql, which clients (e.g.
+// `mycelianCli doctor`) compare to detect mismatched deployments.
+var SchemaVersion = schema.Version
+
 // Store defines the persistence surface used by the application services.
 // It provides typed accessors for each resource area (users, vaults, memories,
 // entries, contexts) and hides concrete database details behind simple
@@ -23,6 +30,16 @@ type Store interface {
 	Memories() Memories
 	Entries() Entries
 	Contexts() Contexts
+	ContextDocuments() ContextDocuments
+	EntityAliases() EntityAliases
+	Webhooks() Webhooks
+	SearchLog() SearchLog
+	IngestionBatches() IngestionBatches
+	ActorSettings() ActorSettings
+	Reindex() Reindex
+	Reembed() Reembed
+	Indexing() Indexing
+	Jobs() Jobs
 }
 
 type Users interface {
@@ -34,30 +51,302 @@ type Users interface {
 type Vaults interface {
 	Create(ctx context.Context, v *model.Vault) (*model.Vault, error)
 	GetByID(ctx context.Context, userID, vaultID string) (*model.Vault, error)
+	// GetByTitle finds a vault by exact title, or else by model.Slug(title);
+	// model.ErrNotFound if neither matches.
 	GetByTitle(ctx context.Context, userID, title string) (*model.Vault, error)
 	List(ctx context.Context, userID string) ([]*model.Vault, error)
 	Delete(ctx context.Context, userID, vaultID string) error
 	AddMemory(ctx context.Context, userID, vaultID, memoryID string) error
+	// Update changes the vault's title and/or description; a new title is
+	// also written to the search index objects of its memories.
+	// model.ErrNotFound if absent, model.ErrConflict if the title or its
+	// slug is taken.
+	Update(ctx context.Context, userID, vaultID string, u model.TitleUpdate) (*model.Vault, error)
+	// SetReadOnly toggles the vault's read-only flag; model.ErrNotFound if absent.
+	SetReadOnly(ctx context.Context, userID, vaultID string, readOnly bool) (*model.Vault, error)
+	// MemoryStats lists the vault's memories with their entry and context row
+	// counts and indexing backlog (Postgres only), ordered by title.
+	MemoryStats(ctx context.Context, userID, vaultID string) ([]model.MemoryStats, error)
+	// Clone copies the vault's memories, their entries and latest contexts
+	// into a new vault with fresh IDs, in one transaction.
+	// model.ErrNotFound if the source is absent, model.ErrConflict if the
+	// title is taken.
+	Clone(ctx context.Context, c model.VaultClone) (*model.ClonedVault, error)
+	// Bootstrap creates a vault, its memory and the memory's first context
+	// in one transaction. A client token already used with the same
+	// request returns the stored result with Replayed set; used with a
+	// different request, or once its vault or memory is gone, it fails with
+	// model.ErrConflict, as does a taken title.
+	Bootstrap(ctx context.Context, b model.Bootstrap) (*model.BootstrapResult, error)
 }
 
 type Memories interface {
 	Create(ctx context.Context, m *model.Memory) (*model.Memory, error)
 	GetByID(ctx context.Context, userID, vaultID, memoryID string) (*model.Memory, error)
+	// GetByTitle finds a memory by exact title, or else by model.Slug(title);
+	// model.ErrNotFound if neither matches.
 	GetByTitle(ctx context.Context, userID, vaultID, title string) (*model.Memory, error)
 	List(ctx context.Context, userID, vaultID string) ([]*model.Memory, error)
+	// SetAppendOnly marks the memory append-only; the flag cannot be
+	// cleared. model.ErrNotFound if absent.
+	SetAppendOnly(ctx context.Context, userID, vaultID, memoryID string) (*model.Memory, error)
+	// SetEntryRoles replaces the roles new entries must carry; empty
+	// lifts the requirement. model.ErrNotFound if absent.
+	SetEntryRoles(ctx context.Context, userID, vaultID, memoryID string, roles []string) (*model.Memory, error)
+	// SetSearchBoost replaces the memory's search boost; nil clears it.
+	// model.ErrNotFound if absent.
+	SetSearchBoost(ctx context.Context, userID, vaultID, memoryID string, b *model.SearchBoost) (*model.Memory, error)
+	// Update changes the memory's title and/or description; a new title is
+	// also written to its search index objects. model.ErrNotFound if absent,
+	// model.ErrConflict if the title is taken in the vault.
+	Update(ctx context.Context, userID, vaultID, memoryID string, u model.TitleUpdate) (*model.Memory, error)
 	Delete(ctx context.Context, userID, vaultID, memoryID string) error
+	// Trash moves the memory to the trash, hiding it and its entries from
+	// reads until Restore; like Delete it is a no-op for an absent memory.
+	// Its title stays taken until it is purged.
+	Trash(ctx context.Context, userID, vaultID, memoryID string) error
+	// Restore takes the memory out of the trash; model.ErrNotFound if it is
+	// not there.
+	Restore(ctx context.Context, userID, vaultID, memoryID string) (*model.Memory, error)
+	// ListTrash lists the vault's trashed memories, most recently deleted first.
+	ListTrash(ctx context.Context, userID, vaultID string) ([]*model.Memory, error)
+	// PurgeTrash deletes, as Delete does, up to limit memories trashed
+	// before cutoff and returns how many it deleted.
+	PurgeTrash(ctx context.Context, cutoff time.Time, limit int) (int, error)
 }
 
 type Entries interface {
 	Create(ctx context.Context, e *model.MemoryEntry) (*model.MemoryEntry, error)
+	// CreateBatch writes entries of one memory and enqueues their index
+	// upserts in a single transaction: all are stored or none. Creation
+	// times increase in slice order.
+	CreateBatch(ctx context.Context, entries []*model.MemoryEntry) ([]*model.MemoryEntry, error)
 	List(ctx context.Context, req model.ListEntriesRequest) ([]*model.MemoryEntry, error)
 	GetByID(ctx context.Context, userID, vaultID, memoryID, entryID string) (*model.MemoryEntry, error)
+	// Scan returns entries matching req's text filters, newest first. An
+	// invalid regular expression yields model.ErrValidation.
+	Scan(ctx context.Context, req model.ScanEntriesRequest) ([]*model.MemoryEntry, error)
 	UpdateTags(ctx context.Context, userID, vaultID, memoryID, entryID string, tags map[string]interface{}) (*model.MemoryEntry, error)
+	// PatchTags applies p to every matching entry in one transaction and
+	// enqueues their index upserts, returning the updated entry IDs. More
+	// than model.MaxTagPatchEntries matches is model.ErrValidation.
+	PatchTags(ctx context.Context, p model.EntryTagPatch) ([]string, error)
+	// RecordSignal increments one of the entry's quality counters (model.Signal*).
+	RecordSignal(ctx context.Context, userID, vaultID, memoryID, entryID, signal string) (*model.MemoryEntry, error)
+	// Signals returns the quality counters of the listed entries keyed by entryID.
+	Signals(ctx context.Context, userID string, entryIDs []string) (map[string]model.EntrySignals, error)
 	DeleteByID(ctx context.Context, userID, vaultID, memoryID, entryID string) error
+	// Sessions lists the memory's entry sessions, oldest first by first entry.
+	Sessions(ctx context.Context, userID, vaultID, memoryID string) ([]model.EntrySession, error)
+	// SessionStart returns the creation time of the session's first entry in
+	// the memory, or model.ErrNotFound when the session has none.
+	SessionStart(ctx context.Context, userID, memoryID, sessionID string) (time.Time, error)
+	// Newest returns the creation time of the memory's newest entry, or
+	// model.ErrNotFound when it has none.
+	Newest(ctx context.Context, userID, memoryID string) (time.Time, error)
+	// Usage sums the usage reported with the actor's entries per memory and
+	// model, ordered by memory then model; empty memoryID and nil since mean
+	// no filter.
+	Usage(ctx context.Context, userID, memoryID string, since *time.Time) ([]model.UsageTotals, error)
+	// Touch sets lastAccessedTime of the listed entries to at (never moving it backwards).
+	Touch(ctx context.Context, userID string, entryIDs []string, at time.Time) error
+	// Expire deletes up to limit entries, oldest first, whose creation time
+	// (or last access when byAccess) is before cutoff, skipping read-only
+	// vaults. Index deletes are enqueued; the deleted entry IDs are returned.
+	Expire(ctx context.Context, cutoff time.Time, byAccess bool, limit int) ([]string, error)
+	// ExpireDue deletes up to limit entries whose expiration time is not
+	// after now, soonest first, skipping read-only vaults. Index deletes are
+	// enqueued; the deleted entry IDs are returned.
+	ExpireDue(ctx context.Context, now time.Time, limit int) ([]string, error)
+	// Expired returns which of the listed entries are past their expiration
+	// time at now.
+	Expired(ctx context.Context, userID string, entryIDs []string, now time.Time) (map[string]bool, error)
+	// Trash moves the entry to the trash: reads skip it while its index
+	// object stays until it is purged. Like DeleteByID it is a no-op for an
+	// absent entry.
+	Trash(ctx context.Context, userID, vaultID, memoryID, entryID string) error
+	// Restore takes the entry out of the trash; model.ErrNotFound if it is
+	// not there.
+	Restore(ctx context.Context, userID, vaultID, memoryID, entryID string) (*model.MemoryEntry, error)
+	// ListTrash lists up to limit trashed entries of the vault's memories
+	// that are not trashed themselves, most recently deleted first.
+	ListTrash(ctx context.Context, userID, vaultID string, limit int) ([]*model.MemoryEntry, error)
+	// Trashed returns which of the listed entries are in the trash, on their
+	// own or with their memory.
+	Trashed(ctx context.Context, userID string, entryIDs []string) (map[string]bool, error)
+	// Correct writes correction as a new entry of the memory and marks the
+	// entry it corrects, in one transaction. model.ErrNotFound for an absent
+	// entry, model.ErrConflict for one corrected already.
+	Correct(ctx context.Context, entryID string, correction *model.MemoryEntry, reason string) (*model.MemoryEntry, error)
+	// Corrections maps each listed entry that was corrected to the newest
+	// entry of its correction chain that is not trashed or expired, or nil
+	// when none is left.
+	Corrections(ctx context.Context, userID string, entryIDs []string) (map[string]*model.MemoryEntry, error)
+	// PurgeTrash deletes up to limit entries trashed before cutoff, oldest
+	// first, enqueues their index deletes and returns their IDs.
+	PurgeTrash(ctx context.Context, cutoff time.Time, limit int) ([]string, error)
 }
 
 type Contexts interface {
 	Put(ctx context.Context, c *model.MemoryContext) (*model.MemoryContext, error)
+	// PutIfLatest writes c like Put, but only while the memory's latest
+	// context is still latestID ("" for none); the check and the write are
+	// atomic. It fails with model.ErrPrecondition otherwise.
+	PutIfLatest(ctx context.Context, c *model.MemoryContext, latestID string) (*model.MemoryContext, error)
 	Latest(ctx context.Context, userID, vaultID, memoryID string) (*model.MemoryContext, error)
+	// LatestForMemories returns the latest context of each listed memory in one
+	// query, keyed by memoryID. Memories without a context are omitted.
+	LatestForMemories(ctx context.Context, userID string, memoryIDs []string) (map[string]*model.MemoryContext, error)
 	DeleteByID(ctx context.Context, userID, vaultID, memoryID, contextID string) error
+	// CompactionCandidates pages through memories holding more than one
+	// snapshot created before cutoff, ordered by key and starting after the
+	// given ref (zero value for the first page).
+	CompactionCandidates(ctx context.Context, cutoff time.Time, after model.MemoryRef, limit int) ([]model.MemoryRef, error)
+	// Snapshots lists a memory's snapshots created before cutoff, newest first.
+	Snapshots(ctx context.Context, ref model.MemoryRef, before time.Time) ([]model.ContextSnapshot, error)
+	// DeleteMany removes the listed snapshots and enqueues their index deletes,
+	// returning how many existed.
+	DeleteMany(ctx context.Context, ref model.MemoryRef, contextIDs []string) (int, error)
+}
+
+// ContextDocuments stores full versions of contexts too large to be the
+// active context, as gzip data uploaded in numbered parts. Methods return
+// model.ErrNotFound for unknown documents.
+type ContextDocuments interface {
+	// Create starts an uploading document in the memory.
+	Create(ctx context.Context, d *model.ContextDocument) (*model.ContextDocument, error)
+	Get(ctx context.Context, actorID, vaultID, memoryID, documentID string) (*model.ContextDocument, error)
+	// PutPart stores or replaces part n; model.ErrConflict once the document is complete.
+	PutPart(ctx context.Context, actorID, documentID string, n int, data []byte) error
+	// Parts returns the stored parts ordered by number.
+	Parts(ctx context.Context, actorID, documentID string) ([]model.ContextDocumentPart, error)
+	// Complete records the decompressed size and checksum and marks the
+	// document complete; model.ErrConflict if it already is.
+	Complete(ctx context.Context, actorID, documentID string, sizeBytes int64, sha256 string) (*model.ContextDocument, error)
+}
+
+// EntityAliases is the per-memory registry of entity aliases. An alias is
+// unique within a memory regardless of case.
+type EntityAliases interface {
+	// Put maps the alias to its canonical name, replacing an earlier mapping
+	// of the same alias.
+	Put(ctx context.Context, a *model.EntityAlias) (*model.EntityAlias, error)
+	// List returns the memory's aliases ordered by canonical name, then alias.
+	List(ctx context.Context, actorID, memoryID string) ([]*model.EntityAlias, error)
+	// Delete removes the alias; model.ErrNotFound if absent.
+	Delete(ctx context.Context, actorID, vaultID, memoryID, alias string) error
+}
+
+// Webhooks holds the inbound webhooks of memories. A name is unique within
+// a memory.
+type Webhooks interface {
+	// Create stores the webhook; model.ErrConflict if the memory already has
+	// one of that name.
+	Create(ctx context.Context, w *model.Webhook) (*model.Webhook, error)
+	// Get returns a webhook, secret included, by its ID alone so deliveries
+	// can be verified before any actor is known; model.ErrNotFound if absent.
+	Get(ctx context.Context, webhookID string) (*model.Webhook, error)
+	// List returns the memory's webhooks ordered by name.
+	List(ctx context.Context, actorID, memoryID string) ([]*model.Webhook, error)
+	// Delete removes the webhook; model.ErrNotFound if absent.
+	Delete(ctx context.Context, actorID, vaultID, memoryID, webhookID string) error
+	// RecordDelivery counts one stored delivery at the given time.
+	RecordDelivery(ctx context.Context, webhookID string, at time.Time) error
+}
+
+// Jobs persists the background jobs run by the jobs worker. A worker owns a
+// job from Claim until Finish and keeps its update time fresh with Progress.
+type Jobs interface {
+	// Create stores j as a queued job and returns it with its times set.
+	Create(ctx context.Context, j *model.Job) (*model.Job, error)
+	// Get returns one of the actor's jobs; model.ErrNotFound if absent.
+	Get(ctx context.Context, actorID, jobID string) (*model.Job, error)
+	// Claim marks the oldest queued job running and returns it, or else a
+	// running job last updated before staleBefore, whose worker stopped.
+	// It returns nil when there is none.
+	Claim(ctx context.Context, staleBefore time.Time) (*model.Job, error)
+	// Progress records done of total units and refreshes the job's update
+	// time; it reports whether the job was asked to cancel.
+	Progress(ctx context.Context, jobID string, done, total int) (bool, error)
+	// Finish moves the job to a final status, with errMsg when it failed.
+	Finish(ctx context.Context, jobID, status, errMsg string) error
+	// Cancel cancels a queued job at once and asks a running one to stop,
+	// returning the job; model.ErrNotFound if absent, model.ErrConflict if
+	// it already finished.
+	Cancel(ctx context.Context, actorID, jobID string) (*model.Job, error)
+}
+
+// SearchLog records search queries and relevance feedback for tuning.
+type SearchLog interface {
+	RecordQuery(ctx context.Context, q *model.SearchQuery) (*model.SearchQuery, error)
+	GetQuery(ctx context.Context, actorID, queryID string) (*model.SearchQuery, error)
+	// RecordFeedback replaces the useful entry IDs for a logged query.
+	RecordFeedback(ctx context.Context, actorID, queryID string, usefulEntryIDs []string) error
+	// Precision aggregates feedback; empty memoryID and nil since mean no filter.
+	Precision(ctx context.Context, actorID, memoryID string, since *time.Time) (*model.SearchPrecision, error)
+	// List returns up to limit logged queries, oldest first, with the same filters.
+	List(ctx context.Context, actorID, memoryID string, since *time.Time, limit int) ([]*model.SearchQuery, error)
+}
+
+// IngestionBatches is the registry of ingestion batches referenced by
+// entries' ingestionBatchId. Get returns model.ErrNotFound for unknown batches.
+type IngestionBatches interface {
+	Create(ctx context.Context, b *model.IngestionBatch) (*model.IngestionBatch, error)
+	Get(ctx context.Context, actorID, batchID string) (*model.IngestionBatch, error)
+	List(ctx context.Context, actorID string) ([]*model.IngestionBatch, error)
+	ListEntries(ctx context.Context, actorID, batchID string, limit int) ([]*model.MemoryEntry, error)
+	// Rollback atomically deletes every entry in the batch (enqueueing index
+	// deletes) and marks it rolled back. It returns the deleted entry IDs.
+	Rollback(ctx context.Context, actorID, batchID string) ([]string, error)
+}
+
+// ActorSettings holds per-actor preferences. Get returns model.ErrNotFound
+// when the actor has never saved settings.
+type ActorSettings interface {
+	Get(ctx context.Context, actorID string) (*model.ActorSettings, error)
+	PutTimeZone(ctx context.Context, actorID, timeZone string) (*model.ActorSettings, error)
+	// PutDefaults sets the default vault and memory; empty IDs clear them.
+	PutDefaults(ctx context.Context, actorID, vaultID, memoryID string) (*model.ActorSettings, error)
+}
+
+// Indexing reads and repairs the search indexing of entries and contexts
+// from their outbox records.
+type Indexing interface {
+	// Status returns the index status of each listed entry or context ID
+	// of the actor; IDs without an outbox record are IndexStateUnknown.
+	Status(ctx context.Context, actorID string, ids []string) (map[string]model.IndexStatus, error)
+	// Retry re-enqueues the failed index writes of the memory's entries and
+	// contexts, only of ids when non-empty, and returns how many.
+	Retry(ctx context.Context, actorID, vaultID, memoryID string, ids []string) (int, error)
+}
+
+// Reembed tracks the gradual re-embedding of memories after the embedding
+// configuration changed, identified by a fingerprint.
+type Reembed interface {
+	// MarkDrift records fingerprint as the current configuration. When it
+	// differs from the recorded one, every memory is marked for
+	// re-embedding and the number marked is returned; the first call only
+	// records it.
+	MarkDrift(ctx context.Context, fingerprint string) (int, error)
+	// EnqueueNext writes upsert outbox records for entries of the oldest
+	// marked memories not fully enqueued yet (and their contexts, with the
+	// first page) and returns how many. It writes at most limit records and
+	// fewer while earlier ones are still pending, so at most limit wait.
+	EnqueueNext(ctx context.Context, limit int) (int, error)
+	// Progress returns the memory's re-embedding; model.ErrNotFound if it
+	// was never marked.
+	Progress(ctx context.Context, actorID, memoryID string) (*model.ReembedProgress, error)
+	// Overview summarises the memories marked for the current fingerprint.
+	Overview(ctx context.Context) (*model.ReembedOverview, error)
+}
+
+// Reindex enqueues index rebuilds for a single memory. Start returns
+// model.ErrNotFound for unknown memories; Latest when no job exists.
+type Reindex interface {
+	// Start writes one upsert outbox record per entry and context of the
+	// memory, tagged with a new job ID, and returns the job;
+	// model.ErrConflict while an earlier job of the memory is running.
+	Start(ctx context.Context, actorID, memoryID string) (*model.ReindexJob, error)
+	// Latest returns the memory's most recent job with current progress.
+	Latest(ctx context.Context, actorID, memoryID string) (*model.ReindexJob, error)
 }
@@ -3,6 +3,9 @@ package storetest
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -33,6 +36,26 @@ func Run(t *testing.T, makeStore func(t *testing.T) store.Store) {
 		t.Fatalf("GetUser: got=%v err=%v", got, err)
 	}
 
+	// Actor settings
+	if _, err := s.ActorSettings().Get(ctx, userID); !errors.Is(err, model.ErrNotFound) {
+		t.Fatalf("ActorSettings.Get unset: expected ErrNotFound, got %v", err)
+	}
+	if _, err := s.ActorSettings().PutTimeZone(ctx, userID, "Europe/Berlin"); err != nil {
+		t.Fatalf("ActorSettings.PutTimeZone: %v", err)
+	}
+	if got, err := s.ActorSettings().PutDefaults(ctx, userID, "v-default", "m-default"); err != nil || got.TimeZone != "Europe/Berlin" || got.DefaultMemoryID != "m-default" {
+		t.Fatalf("ActorSettings.PutDefaults: got=%+v err=%v", got, err)
+	}
+	if got, err := s.ActorSettings().PutTimeZone(ctx, userID, "Asia/Tokyo"); err != nil || got.TimeZone != "Asia/Tokyo" || got.DefaultVaultID != "v-default" {
+		t.Fatalf("ActorSettings.PutTimeZone overwrite: got=%+v err=%v", got, err)
+	}
+	if got, err := s.ActorSettings().Get(ctx, userID); err != nil || got.TimeZone != "Asia/Tokyo" {
+		t.Fatalf("ActorSettings.Get: got=%+v err=%v", got, err)
+	}
+	if got, err := s.ActorSettings().PutDefaults(ctx, userID, "", ""); err != nil || got.DefaultVaultID != "" || got.DefaultMemoryID != "" {
+		t.Fatalf("ActorSettings.PutDefaults clear: got=%+v err=%v", got, err)
+	}
+
 	// Vaults
 	v, err := s.Vaults().Create(ctx, &model.Vault{ActorID: userID, Title: "test-vault"})
 	if err != nil {
@@ -59,16 +82,38 @@ func Run(t *testing.T, makeStore func(t *testing.T) store.Store) {
 	if got, err := s.Memories().GetByTitle(ctx, userID, v.VaultID, "m1"); err != nil || got == nil || got.MemoryID != m.MemoryID {
 		t.Fatalf("GetMemoryByTitle: got=%v err=%v", got, err)
 	}
+	// Titles with spaces resolve by title or slug; a second title with the same slug conflicts
+	tm, err := s.Memories().Create(ctx, &model.Memory{ActorID: userID, VaultID: v.VaultID, MemoryType: "text", Title: "Trip Plan"})
+	if err != nil || tm.Slug != "trip-plan" {
+		t.Fatalf("CreateMemory with spaces: got=%+v err=%v", tm, err)
+	}
+	if got, err := s.Memories().GetByTitle(ctx, userID, v.VaultID, "trip-plan"); err != nil || got.MemoryID != tm.MemoryID || got.Title != "Trip Plan" {
+		t.Fatalf("GetMemoryByTitle slug: got=%+v err=%v", got, err)
+	}
+	if _, err := s.Memories().Create(ctx, &model.Memory{ActorID: userID, VaultID: v.VaultID, MemoryType: "text", Title: "trip plan"}); !errors.Is(err, model.ErrConflict) {
+		t.Fatalf("CreateMemory slug clash: expected conflict, got %v", err)
+	}
+	if _, err := s.Memories().GetByTitle(ctx, userID, v.VaultID, "no such memory"); !errors.Is(err, model.ErrNotFound) {
+		t.Fatalf("GetMemoryByTitle missing: expected not found, got %v", err)
+	}
 
 	// Entries
 	e1, err := s.Entries().Create(ctx, &model.MemoryEntry{ActorID: userID, VaultID: v.VaultID, MemoryID: m.MemoryID, RawEntry: "hello"})
 	if err != nil {
 		t.Fatalf("CreateEntry e1: %v", err)
 	}
-	e2, err := s.Entries().Create(ctx, &model.MemoryEntry{ActorID: userID, VaultID: v.VaultID, MemoryID: m.MemoryID, RawEntry: "world"})
+	usage := &model.EntryUsage{Model: "gpt-x", InputTokens: 120, OutputTokens: 30, CostUSD: 0.002}
+	e2, err := s.Entries().Create(ctx, &model.MemoryEntry{ActorID: userID, VaultID: v.VaultID, MemoryID: m.MemoryID, RawEntry: "world", Usage: usage})
 	if err != nil {
 		t.Fatalf("CreateEntry e2: %v", err)
 	}
+	if got, err := s.Entries().GetByID(ctx, userID, v.VaultID, m.MemoryID, e2.EntryID); err != nil || got.Usage == nil || *got.Usage != *usage {
+		t.Fatalf("GetEntry usage: got=%+v err=%v", got, err)
+	}
+	if totals, err := s.Entries().Usage(ctx, userID, m.MemoryID, nil); err != nil || len(totals) != 1 || totals[0].Entries != 1 ||
+		totals[0].Model != "gpt-x" || totals[0].InputTokens != 120 || totals[0].OutputTokens != 30 || totals[0].CostUSD != 0.002 {
+		t.Fatalf("Usage: got=%+v err=%v", totals, err)
+	}
 
 	// ListEntries
 	lst, err := s.Entries().List(ctx, model.ListEntriesRequest{ActorID: userID, VaultID: v.VaultID, MemoryID: m.MemoryID})
@@ -76,6 +121,119 @@ func Run(t *testing.T, makeStore func(t *testing.T) store.Store) {
 		t.Fatalf("ListEntries: n=%d err=%v", len(lst), err)
 	}
 
+	// Scan: exact text filters with keyset paging
+	scanReq := model.ScanEntriesRequest{ActorID: userID, VaultID: v.VaultID, MemoryID: m.MemoryID, Contains: "WOR"}
+	if got, err := s.Entries().Scan(ctx, scanReq); err != nil || len(got) != 1 || got[0].EntryID != e2.EntryID {
+		t.Fatalf("Scan contains: got=%v err=%v", got, err)
+	}
+	scanReq = model.ScanEntriesRequest{ActorID: userID, VaultID: v.VaultID, MemoryID: m.MemoryID, Regex: "^(hello|world)$", Limit: 1}
+	page, err := s.Entries().Scan(ctx, scanReq)
+	if err != nil || len(page) != 1 || page[0].EntryID != e2.EntryID {
+		t.Fatalf("Scan regex page 1: got=%v err=%v", page, err)
+	}
+	scanReq.After = &model.EntryCursor{CreationTime: page[0].CreationTime, EntryID: page[0].EntryID}
+	if page, err = s.Entries().Scan(ctx, scanReq); err != nil || len(page) != 1 || page[0].EntryID != e1.EntryID {
+		t.Fatalf("Scan regex page 2: got=%v err=%v", page, err)
+	}
+	if _, err := s.Entries().Scan(ctx, model.ScanEntriesRequest{ActorID: userID, VaultID: v.VaultID, MemoryID: m.MemoryID, Regex: "("}); !errors.Is(err, model.ErrValidation) {
+		t.Fatalf("Scan invalid regex: expected validation error, got %v", err)
+	}
+
+	// CreateBatch writes entries in order, keeping their conversation times
+	bm, err := s.Memories().Create(ctx, &model.Memory{ActorID: userID, VaultID: v.VaultID, MemoryType: "text", Title: "backfill"})
+	if err != nil {
+		t.Fatalf("CreateMemory backfill: %v", err)
+	}
+	convTime := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	batch, err := s.Entries().CreateBatch(ctx, []*model.MemoryEntry{
+		{ActorID: userID, VaultID: v.VaultID, MemoryID: bm.MemoryID, RawEntry: "first", ConversationTime: &convTime},
+		{ActorID: userID, VaultID: v.VaultID, MemoryID: bm.MemoryID, RawEntry: "second"},
+	})
+	if err != nil || len(batch) != 2 || batch[0].EntryID == "" || !batch[0].CreationTime.Before(batch[1].CreationTime) {
+		t.Fatalf("CreateBatch: got=%+v err=%v", batch, err)
+	}
+	if got, err := s.Entries().GetByID(ctx, userID, v.VaultID, bm.MemoryID, batch[0].EntryID); err != nil || got.ConversationTime == nil || !got.ConversationTime.Equal(convTime) {
+		t.Fatalf("GetEntry conversation time: got=%+v err=%v", got, err)
+	}
+	if _, err := s.Entries().CreateBatch(ctx, []*model.MemoryEntry{
+		{ActorID: userID, VaultID: v.VaultID, MemoryID: bm.MemoryID, RawEntry: "a"},
+		{ActorID: userID, VaultID: v.VaultID, MemoryID: m.MemoryID, RawEntry: "b"},
+	}); !errors.Is(err, model.ErrValidation) {
+		t.Fatalf("CreateBatch across memories: expected validation error, got %v", err)
+	}
+
+	// Long bodies round-trip intact and stay scannable (stores may compress them)
+	lm, err := s.Memories().Create(ctx, &model.Memory{ActorID: userID, VaultID: v.VaultID, MemoryType: "text", Title: "long"})
+	if err != nil {
+		t.Fatalf("CreateMemory long: %v", err)
+	}
+	long := strings.Repeat("tool output line\n", 500) + "needle at the end"
+	le, err := s.Entries().Create(ctx, &model.MemoryEntry{ActorID: userID, VaultID: v.VaultID, MemoryID: lm.MemoryID, RawEntry: long})
+	if err != nil {
+		t.Fatalf("CreateEntry long: %v", err)
+	}
+	if got, err := s.Entries().GetByID(ctx, userID, v.VaultID, lm.MemoryID, le.EntryID); err != nil || got.RawEntry != long {
+		t.Fatalf("GetEntry long: intact=%v err=%v", got != nil && got.RawEntry == long, err)
+	}
+	for _, req := range []model.ScanEntriesRequest{{Contains: "NEEDLE"}, {Regex: "needle at the end$"}, {Contains: "haystack"}} {
+		req.ActorID, req.VaultID, req.MemoryID = userID, v.VaultID, lm.MemoryID
+		want := 1
+		if req.Contains == "haystack" {
+			want = 0
+		}
+		if got, err := s.Entries().Scan(ctx, req); err != nil || len(got) != want || (want == 1 && got[0].RawEntry != long) {
+			t.Fatalf("Scan long %+v: n=%d err=%v", req, len(got), err)
+		}
+	}
+
+	// Sessions
+	sm, err := s.Memories().Create(ctx, &model.Memory{ActorID: userID, VaultID: v.VaultID, MemoryType: "text", Title: "sessions"})
+	if err != nil {
+		t.Fatalf("CreateMemory sessions: %v", err)
+	}
+	if _, err := s.Entries().Newest(ctx, userID, sm.MemoryID); !errors.Is(err, model.ErrNotFound) {
+		t.Fatalf("Newest of an empty memory: expected not found, got %v", err)
+	}
+	var sessionIDs []string
+	var last *model.MemoryEntry
+	for i, sid := range []string{"s1", "s2", "s1", ""} {
+		e, err := s.Entries().Create(ctx, &model.MemoryEntry{ActorID: userID, VaultID: v.VaultID, MemoryID: sm.MemoryID, RawEntry: fmt.Sprintf("turn %d", i), SessionID: sid})
+		if err != nil {
+			t.Fatalf("CreateEntry session %q: %v", sid, err)
+		}
+		if sid == "s1" {
+			sessionIDs = append(sessionIDs, e.EntryID)
+		}
+		last = e
+	}
+	if newest, err := s.Entries().Newest(ctx, userID, sm.MemoryID); err != nil || !newest.Equal(last.CreationTime) {
+		t.Fatalf("Newest: got=%v want=%v err=%v", newest, last.CreationTime, err)
+	}
+	if ms, err := s.Vaults().MemoryStats(ctx, userID, v.VaultID); err != nil || len(ms) != 2 || ms[1].MemoryID != sm.MemoryID || ms[1].Postgres.Entries != 4 || ms[0].Postgres.Entries != 2 {
+		t.Fatalf("MemoryStats: got=%+v err=%v", ms, err)
+	}
+	// Fresh entries have an outbox record; whether it is processed yet
+	// depends on a worker, which the suite does not run.
+	if st, err := s.Indexing().Status(ctx, userID, []string{last.EntryID, "no-such-entry"}); err != nil ||
+		st[last.EntryID].State == model.IndexStateUnknown || st["no-such-entry"].State != model.IndexStateUnknown {
+		t.Fatalf("Indexing.Status: got=%+v err=%v", st, err)
+	}
+	if n, err := s.Indexing().Retry(ctx, userID, v.VaultID, sm.MemoryID, nil); err != nil || n != 0 {
+		t.Fatalf("Indexing.Retry with nothing failed: n=%d err=%v", n, err)
+	}
+	if ss, err := s.Entries().Sessions(ctx, userID, v.VaultID, sm.MemoryID); err != nil || len(ss) != 2 || ss[0].SessionID != "s1" || ss[0].EntryCount != 2 || ss[1].SessionID != "s2" {
+		t.Fatalf("Sessions: got=%+v err=%v", ss, err)
+	} else if start, err := s.Entries().SessionStart(ctx, userID, sm.MemoryID, "s1"); err != nil || !start.Equal(ss[0].FirstEntryTime) {
+		t.Fatalf("SessionStart: got=%v err=%v", start, err)
+	}
+	if _, err := s.Entries().SessionStart(ctx, userID, sm.MemoryID, "s9"); !errors.Is(err, model.ErrNotFound) {
+		t.Fatalf("SessionStart unknown session: expected not found, got %v", err)
+	}
+	if lst, err := s.Entries().List(ctx, model.ListEntriesRequest{ActorID: userID, VaultID: v.VaultID, MemoryID: sm.MemoryID, SessionID: "s1", Ascending: true}); err != nil || len(lst) != 2 ||
+		lst[0].EntryID != sessionIDs[0] || lst[1].EntryID != sessionIDs[1] || lst[0].SessionID != "s1" {
+		t.Fatalf("List session s1: n=%d err=%v", len(lst), err)
+	}
+
 	// UpdateTags
 	tags := map[string]interface{}{"k": "v", "num": 42}
 	if _, err := s.Entries().UpdateTags(ctx, userID, v.VaultID, m.MemoryID, e1.EntryID, tags); err != nil {
@@ -85,6 +243,52 @@ func Run(t *testing.T, makeStore func(t *testing.T) store.Store) {
 		b, _ := json.Marshal(got)
 		t.Fatalf("GetByID after UpdateTags: got=%s err=%v", string(b), err)
 	}
+	for _, c := range []struct {
+		tags map[string]string
+		want int
+	}{{map[string]string{"k": "v", "num": "42"}, 1}, {map[string]string{"k": "x"}, 0}, {map[string]string{"missing": ""}, 0}} {
+		if lst, err := s.Entries().List(ctx, model.ListEntriesRequest{ActorID: userID, VaultID: v.VaultID, MemoryID: m.MemoryID, Tags: c.tags}); err != nil || len(lst) != c.want ||
+			(c.want == 1 && lst[0].EntryID != e1.EntryID) {
+			t.Fatalf("List tags %v: n=%d err=%v", c.tags, len(lst), err)
+		}
+	}
+	if ids, err := s.Entries().PatchTags(ctx, model.EntryTagPatch{ActorID: userID, VaultID: v.VaultID, MemoryID: m.MemoryID, EntryIDs: []string{e1.EntryID},
+		Set: map[string]interface{}{"status": "resolved"}, Unset: []string{"num"}}); err != nil || len(ids) != 1 || ids[0] != e1.EntryID {
+		t.Fatalf("PatchTags: ids=%v err=%v", ids, err)
+	}
+	if got, err := s.Entries().GetByID(ctx, userID, v.VaultID, m.MemoryID, e1.EntryID); err != nil || got.Tags["status"] != "resolved" || got.Tags["k"] != "v" || got.Tags["num"] != nil {
+		t.Fatalf("GetByID after PatchTags: got=%+v err=%v", got, err)
+	}
+
+	// Quality signals
+	if got, err := s.Entries().RecordSignal(ctx, userID, v.VaultID, m.MemoryID, e1.EntryID, model.SignalUseful); err != nil || got.UsefulCount != 1 {
+		t.Fatalf("RecordSignal: got=%+v err=%v", got, err)
+	}
+	if _, err := s.Entries().RecordSignal(ctx, userID, v.VaultID, m.MemoryID, e1.EntryID, model.SignalOutdated); err != nil {
+		t.Fatalf("RecordSignal outdated: %v", err)
+	}
+	if _, err := s.Entries().RecordSignal(ctx, userID, v.VaultID, m.MemoryID, "missing", model.SignalUseful); !errors.Is(err, model.ErrNotFound) {
+		t.Fatalf("RecordSignal unknown entry: expected ErrNotFound, got %v", err)
+	}
+	if sig, err := s.Entries().Signals(ctx, userID, []string{e1.EntryID, e2.EntryID}); err != nil || sig[e1.EntryID].UsefulCount != 1 || sig[e1.EntryID].OutdatedCount != 1 || sig[e2.EntryID] != (model.EntrySignals{}) {
+		t.Fatalf("Signals: got=%+v err=%v", sig, err)
+	}
+
+	// Access tracking and retention
+	accessed := time.Now().UTC().Truncate(time.Millisecond)
+	if err := s.Entries().Touch(ctx, userID, []string{e1.EntryID}, accessed); err != nil {
+		t.Fatalf("Touch: %v", err)
+	}
+	if err := s.Entries().Touch(ctx, userID, []string{e1.EntryID}, accessed.Add(-time.Hour)); err != nil {
+		t.Fatalf("Touch older: %v", err)
+	}
+	if got, err := s.Entries().GetByID(ctx, userID, v.VaultID, m.MemoryID, e1.EntryID); err != nil || got.LastAccessedTime == nil || !got.LastAccessedTime.Equal(accessed) {
+		t.Fatalf("LastAccessedTime: got=%+v err=%v", got, err)
+	}
+	// The cutoff predates any test data so shared databases are left intact.
+	if ids, err := s.Entries().Expire(ctx, time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC), true, 10); err != nil || containsString(ids, e1.EntryID) || containsString(ids, e2.EntryID) {
+		t.Fatalf("Expire must keep fresh entries: ids=%v err=%v", ids, err)
+	}
 
 	// Contexts
 	ctxBody := `{"foo":"bar"}`
@@ -96,10 +300,155 @@ func Run(t *testing.T, makeStore func(t *testing.T) store.Store) {
 	if latest, err := s.Contexts().Latest(ctx, userID, v.VaultID, m.MemoryID); err != nil || latest == nil || latest.ContextID == "" {
 		t.Fatalf("LatestContext: got=%v err=%v", latest, err)
 	}
+	if byMem, err := s.Contexts().LatestForMemories(ctx, userID, []string{m.MemoryID, "00000000-0000-0000-0000-000000000000"}); err != nil || len(byMem) != 1 || byMem[m.MemoryID].ContextID != c.ContextID {
+		t.Fatalf("LatestForMemories: got=%v err=%v", byMem, err)
+	}
+	// Context documents: parts are replaceable until completion
+	doc, err := s.ContextDocuments().Create(ctx, &model.ContextDocument{ActorID: userID, VaultID: v.VaultID, MemoryID: m.MemoryID})
+	if err != nil || doc.DocumentID == "" || doc.Status != model.ContextDocumentUploading {
+		t.Fatalf("ContextDocuments.Create: got=%+v err=%v", doc, err)
+	}
+	for _, p := range []struct {
+		n    int
+		data string
+	}{{1, "b"}, {0, "x"}, {0, "a"}} {
+		if err := s.ContextDocuments().PutPart(ctx, userID, doc.DocumentID, p.n, []byte(p.data)); err != nil {
+			t.Fatalf("PutPart %d: %v", p.n, err)
+		}
+	}
+	if parts, err := s.ContextDocuments().Parts(ctx, userID, doc.DocumentID); err != nil || len(parts) != 2 || parts[0].Number != 0 || string(parts[0].Data) != "a" || string(parts[1].Data) != "b" {
+		t.Fatalf("Parts: got=%v err=%v", parts, err)
+	}
+	if got, err := s.ContextDocuments().Complete(ctx, userID, doc.DocumentID, 2, "abc"); err != nil || got.Status != model.ContextDocumentComplete || got.SizeBytes != 2 || got.CompletionTime == nil {
+		t.Fatalf("Complete: got=%+v err=%v", got, err)
+	}
+	if _, err := s.ContextDocuments().Complete(ctx, userID, doc.DocumentID, 2, "abc"); !errors.Is(err, model.ErrConflict) {
+		t.Fatalf("Complete twice: expected conflict, got %v", err)
+	}
+	if err := s.ContextDocuments().PutPart(ctx, userID, doc.DocumentID, 2, []byte("c")); !errors.Is(err, model.ErrConflict) {
+		t.Fatalf("PutPart after complete: expected conflict, got %v", err)
+	}
+	if got, err := s.ContextDocuments().Get(ctx, userID, v.VaultID, m.MemoryID, doc.DocumentID); err != nil || got.SHA256 != "abc" {
+		t.Fatalf("ContextDocuments.Get: got=%+v err=%v", got, err)
+	}
+	if _, err := s.ContextDocuments().Get(ctx, userID, v.VaultID, m.MemoryID, "00000000-0000-0000-0000-000000000000"); !errors.Is(err, model.ErrNotFound) {
+		t.Fatalf("ContextDocuments.Get unknown: expected not found, got %v", err)
+	}
+	// Reindex: one outbox record per entry and context, progress from the outbox
+	if _, err := s.Reindex().Latest(ctx, userID, m.MemoryID); !errors.Is(err, model.ErrNotFound) {
+		t.Fatalf("Reindex.Latest before start: expected ErrNotFound, got %v", err)
+	}
+	if _, err := s.Reindex().Start(ctx, userID, "00000000-0000-0000-0000-000000000000"); !errors.Is(err, model.ErrNotFound) {
+		t.Fatalf("Reindex.Start unknown memory: expected ErrNotFound, got %v", err)
+	}
+	if job, err := s.Reindex().Start(ctx, userID, m.MemoryID); err != nil || job.ContextCount < 1 || job.EntryCount < 1 || job.VaultID != v.VaultID {
+		t.Fatalf("Reindex.Start: job=%+v err=%v", job, err)
+	} else if got, err := s.Reindex().Latest(ctx, userID, m.MemoryID); err != nil || got.JobID != job.JobID || got.Done+got.Pending != job.EntryCount+job.ContextCount {
+		t.Fatalf("Reindex.Latest: got=%+v err=%v", got, err)
+	} else if _, err := s.Reindex().Start(ctx, userID, m.MemoryID); got.Pending > 0 && !errors.Is(err, model.ErrConflict) {
+		t.Fatalf("Reindex.Start while running: expected ErrConflict, got %v", err)
+	}
+	// Reembed: a new fingerprint marks every memory; entries are enqueued in pages
+	baseline := "storetest:" + uuid.New().String()
+	if _, err := s.Reembed().MarkDrift(ctx, baseline); err != nil {
+		t.Fatalf("Reembed.MarkDrift baseline: %v", err)
+	}
+	if n, err := s.Reembed().MarkDrift(ctx, baseline); err != nil || n != 0 {
+		t.Fatalf("Reembed.MarkDrift unchanged: n=%d err=%v", n, err)
+	}
+	if n, err := s.Reembed().MarkDrift(ctx, baseline+"@v2"); err != nil || n < 1 {
+		t.Fatalf("Reembed.MarkDrift changed: n=%d err=%v", n, err)
+	}
+	if p, err := s.Reembed().Progress(ctx, userID, m.MemoryID); err != nil || p.Status != model.ReembedQueued || p.EntryCount < 1 || p.Enqueued != 0 {
+		t.Fatalf("Reembed.Progress queued: p=%+v err=%v", p, err)
+	}
+	if n, err := s.Reembed().EnqueueNext(ctx, 1_000_000); err != nil || n < 1 {
+		t.Fatalf("Reembed.EnqueueNext: n=%d err=%v", n, err)
+	}
+	if p, err := s.Reembed().Progress(ctx, userID, m.MemoryID); err != nil || p.Enqueued != p.EntryCount || p.EnqueuedTime == nil || p.Done+p.Pending != p.EntryCount+p.ContextCount {
+		t.Fatalf("Reembed.Progress enqueued: p=%+v err=%v", p, err)
+	}
+	if o, err := s.Reembed().Overview(ctx); err != nil || o.Fingerprint != baseline+"@v2" || o.Memories[model.ReembedQueued] != 0 {
+		t.Fatalf("Reembed.Overview: o=%+v err=%v", o, err)
+	}
+
+	section := model.ContextSection{Name: "Decisions", Content: "ship", LastUpdatedBy: "agent-a", LastUpdatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)}
+	structured := &model.MemoryContext{ActorID: userID, VaultID: v.VaultID, MemoryID: m.MemoryID, Context: "## Decisions\n\nship", Sections: []model.ContextSection{section}}
+	if _, err := s.Contexts().PutIfLatest(ctx, structured, "stale"); !errors.Is(err, model.ErrPrecondition) {
+		t.Fatalf("PutIfLatest stale: expected ErrPrecondition, got %v", err)
+	}
+	c2, err := s.Contexts().PutIfLatest(ctx, structured, c.ContextID)
+	if err != nil {
+		t.Fatalf("PutIfLatest c2: %v", err)
+	}
+	if latest, err := s.Contexts().Latest(ctx, userID, v.VaultID, m.MemoryID); err != nil || len(latest.Sections) != 1 || latest.Sections[0].LastUpdatedBy != "agent-a" || !latest.Sections[0].LastUpdatedAt.Equal(section.LastUpdatedAt) {
+		t.Fatalf("Latest structured context: got=%+v err=%v", latest, err)
+	}
+	ref := model.MemoryRef{ActorID: userID, VaultID: v.VaultID, MemoryID: m.MemoryID}
+	future := time.Now().Add(time.Hour)
+	if refs, err := s.Contexts().CompactionCandidates(ctx, future, model.MemoryRef{ActorID: userID}, 1); err != nil || len(refs) != 1 || refs[0] != ref {
+		t.Fatalf("CompactionCandidates: got=%v err=%v", refs, err)
+	}
+	if snaps, err := s.Contexts().Snapshots(ctx, ref, future); err != nil || len(snaps) != 2 || snaps[0].ContextID != c2.ContextID {
+		t.Fatalf("Snapshots: got=%v err=%v", snaps, err)
+	}
+	if n, err := s.Contexts().DeleteMany(ctx, ref, []string{c2.ContextID, "missing"}); err != nil || n != 1 {
+		t.Fatalf("DeleteMany: n=%d err=%v", n, err)
+	}
 	if err := s.Contexts().DeleteByID(ctx, userID, v.VaultID, m.MemoryID, c.ContextID); err != nil {
 		t.Fatalf("DeleteContextByID: %v", err)
 	}
 
+	// Trash: trashed rows are hidden from reads until restored
+	if err := s.Entries().Trash(ctx, userID, v.VaultID, m.MemoryID, e2.EntryID); err != nil {
+		t.Fatalf("TrashEntry: %v", err)
+	}
+	if _, err := s.Entries().GetByID(ctx, userID, v.VaultID, m.MemoryID, e2.EntryID); !errors.Is(err, model.ErrNotFound) {
+		t.Fatalf("GetByID of a trashed entry: expected ErrNotFound, got %v", err)
+	}
+	if trashed, err := s.Entries().Trashed(ctx, userID, []string{e1.EntryID, e2.EntryID}); err != nil || len(trashed) != 1 || !trashed[e2.EntryID] {
+		t.Fatalf("Trashed: got=%v err=%v", trashed, err)
+	}
+	if list, err := s.Entries().ListTrash(ctx, userID, v.VaultID, 10); err != nil || len(list) != 1 || list[0].EntryID != e2.EntryID || list[0].DeletionTime == nil {
+		t.Fatalf("ListTrash entries: got=%+v err=%v", list, err)
+	}
+	if got, err := s.Entries().Restore(ctx, userID, v.VaultID, m.MemoryID, e2.EntryID); err != nil || got.EntryID != e2.EntryID {
+		t.Fatalf("RestoreEntry: got=%+v err=%v", got, err)
+	}
+	if _, err := s.Entries().Restore(ctx, userID, v.VaultID, m.MemoryID, e2.EntryID); !errors.Is(err, model.ErrNotFound) {
+		t.Fatalf("RestoreEntry not in trash: expected ErrNotFound, got %v", err)
+	}
+	if err := s.Memories().Trash(ctx, userID, v.VaultID, tm.MemoryID); err != nil {
+		t.Fatalf("TrashMemory: %v", err)
+	}
+	if _, err := s.Memories().GetByID(ctx, userID, v.VaultID, tm.MemoryID); !errors.Is(err, model.ErrNotFound) {
+		t.Fatalf("GetByID of a trashed memory: expected ErrNotFound, got %v", err)
+	}
+	if list, err := s.Memories().ListTrash(ctx, userID, v.VaultID); err != nil || len(list) != 1 || list[0].MemoryID != tm.MemoryID || list[0].DeletionTime == nil {
+		t.Fatalf("ListTrash memories: got=%+v err=%v", list, err)
+	}
+	// A trashed memory's title is free; restoring it while taken conflicts
+	taker, err := s.Memories().Create(ctx, &model.Memory{ActorID: userID, VaultID: v.VaultID, MemoryType: "text", Title: "Trip Plan"})
+	if err != nil {
+		t.Fatalf("CreateMemory with a trashed title: %v", err)
+	}
+	if _, err := s.Memories().Restore(ctx, userID, v.VaultID, tm.MemoryID); !errors.Is(err, model.ErrConflict) {
+		t.Fatalf("RestoreMemory with its title taken: expected ErrConflict, got %v", err)
+	}
+	if err := s.Memories().Delete(ctx, userID, v.VaultID, taker.MemoryID); err != nil {
+		t.Fatalf("DeleteMemory: %v", err)
+	}
+	if got, err := s.Memories().Restore(ctx, userID, v.VaultID, tm.MemoryID); err != nil || got.MemoryID != tm.MemoryID {
+		t.Fatalf("RestoreMemory: got=%+v err=%v", got, err)
+	}
+	// The cutoff predates any test data so shared databases are left intact.
+	if ids, err := s.Entries().PurgeTrash(ctx, time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC), 10); err != nil || len(ids) != 0 {
+		t.Fatalf("PurgeTrash entries: ids=%v err=%v", ids, err)
+	}
+	if n, err := s.Memories().PurgeTrash(ctx, time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC), 10); err != nil || n != 0 {
+		t.Fatalf("PurgeTrash memories: n=%d err=%v", n, err)
+	}
+
 	// Delete entry
 	if err := s.Entries().DeleteByID(ctx, userID, v.VaultID, m.MemoryID, e2.EntryID); err != nil {
 		t.Fatalf("DeleteEntryByID: %v", err)
@@ -120,6 +469,33 @@ func Run(t *testing.T, makeStore func(t *testing.T) store.Store) {
 		t.Fatalf("ListEntries limit: n=%d err=%v", len(lst2), err)
 	}
 
+	// Cursor pages walk every entry once, in either direction
+	for _, asc := range []bool{false, true} {
+		all, err := s.Entries().List(ctx, model.ListEntriesRequest{ActorID: userID, VaultID: v.VaultID, MemoryID: m.MemoryID, Ascending: asc})
+		if err != nil {
+			t.Fatalf("ListEntries all: %v", err)
+		}
+		req := model.ListEntriesRequest{ActorID: userID, VaultID: v.VaultID, MemoryID: m.MemoryID, Ascending: asc, Limit: 2}
+		var walked []string
+		for {
+			page, err := s.Entries().List(ctx, req)
+			if err != nil {
+				t.Fatalf("ListEntries page: %v", err)
+			}
+			for _, e := range page {
+				walked = append(walked, e.EntryID)
+			}
+			if len(page) < req.Limit {
+				break
+			}
+			last := page[len(page)-1]
+			req.Cursor = &model.EntryCursor{CreationTime: last.CreationTime, EntryID: last.EntryID}
+		}
+		if len(walked) != len(all) || walked[0] != all[0].EntryID || walked[len(walked)-1] != all[len(all)-1].EntryID {
+			t.Fatalf("ListEntries pages (ascending=%t): walked %v, want %d entries", asc, walked, len(all))
+		}
+	}
+
 	// Before filter should exclude the newest item
 	if all, err := s.Entries().List(ctx, model.ListEntriesRequest{ActorID: userID, VaultID: v.VaultID, MemoryID: m.MemoryID}); err == nil && len(all) >= 2 {
 		bf := all[0].CreationTime
@@ -136,11 +512,389 @@ func Run(t *testing.T, makeStore func(t *testing.T) store.Store) {
 		}
 	}
 
+	// Search log and feedback
+	q, err := s.SearchLog().RecordQuery(ctx, &model.SearchQuery{ActorID: userID, MemoryID: m.MemoryID, Query: "hello", TopK: 5, Alpha: 0.6, EntryIDs: []string{e1.EntryID, "other"}, Scores: []float64{0.9, 0.4}})
+	if err != nil || q.QueryID == "" {
+		t.Fatalf("RecordQuery: q=%v err=%v", q, err)
+	}
+	if err := s.SearchLog().RecordFeedback(ctx, userID, q.QueryID, []string{e1.EntryID}); err != nil {
+		t.Fatalf("RecordFeedback: %v", err)
+	}
+	if got, err := s.SearchLog().GetQuery(ctx, userID, q.QueryID); err != nil || len(got.UsefulEntryIDs) != 1 || got.FeedbackTime == nil {
+		t.Fatalf("GetQuery after feedback: got=%v err=%v", got, err)
+	}
+	if p, err := s.SearchLog().Precision(ctx, userID, m.MemoryID, nil); err != nil || p.JudgedQueries != 1 || p.Precision != 0.5 {
+		t.Fatalf("Precision: got=%+v err=%v", p, err)
+	}
+	if qs, err := s.SearchLog().List(ctx, userID, m.MemoryID, nil, 10); err != nil || len(qs) != 1 || qs[0].QueryID != q.QueryID || len(qs[0].Scores) != 2 || qs[0].Scores[0] != 0.9 {
+		t.Fatalf("List: got=%v err=%v", qs, err)
+	}
+
+	// Ingestion batches: provenance round-trip and atomic rollback
+	if _, err := s.Entries().Create(ctx, &model.MemoryEntry{ActorID: userID, VaultID: v.VaultID, MemoryID: m.MemoryID, RawEntry: "orphan", IngestionBatchID: "no-such-batch"}); !errors.Is(err, model.ErrValidation) {
+		t.Fatalf("CreateEntry with unknown batch: expected validation error, got %v", err)
+	}
+	b, err := s.IngestionBatches().Create(ctx, &model.IngestionBatch{ActorID: userID, SourceSystem: "mem0"})
+	if err != nil || b.BatchID == "" || b.Status != model.IngestionBatchOpen {
+		t.Fatalf("CreateBatch: b=%v err=%v", b, err)
+	}
+	be, err := s.Entries().Create(ctx, &model.MemoryEntry{ActorID: userID, VaultID: v.VaultID, MemoryID: m.MemoryID, RawEntry: "imported",
+		SourceSystem: "mem0", SourceID: "m-1", IngestionBatchID: b.BatchID})
+	if err != nil {
+		t.Fatalf("CreateEntry in batch: %v", err)
+	}
+	if got, err := s.Entries().GetByID(ctx, userID, v.VaultID, m.MemoryID, be.EntryID); err != nil || got.SourceSystem != "mem0" || got.SourceID != "m-1" || got.IngestionBatchID != b.BatchID {
+		t.Fatalf("GetByID provenance: got=%+v err=%v", got, err)
+	}
+	if got, err := s.IngestionBatches().Get(ctx, userID, b.BatchID); err != nil || got.EntryCount != 1 {
+		t.Fatalf("GetBatch: got=%+v err=%v", got, err)
+	}
+	if ids, err := s.IngestionBatches().Rollback(ctx, userID, b.BatchID); err != nil || len(ids) != 1 || ids[0] != be.EntryID {
+		t.Fatalf("Rollback: ids=%v err=%v", ids, err)
+	}
+	if _, err := s.Entries().GetByID(ctx, userID, v.VaultID, m.MemoryID, be.EntryID); err == nil {
+		t.Fatalf("entry should be gone after rollback")
+	}
+	if _, err := s.Entries().Create(ctx, &model.MemoryEntry{ActorID: userID, VaultID: v.VaultID, MemoryID: m.MemoryID, RawEntry: "late", IngestionBatchID: b.BatchID}); !errors.Is(err, model.ErrConflict) {
+		t.Fatalf("CreateEntry in rolled back batch: expected conflict, got %v", err)
+	}
+	if _, err := s.IngestionBatches().Get(ctx, userID, "no-such-batch"); !errors.Is(err, model.ErrNotFound) {
+		t.Fatalf("GetBatch unknown: expected not found, got %v", err)
+	}
+
+	// Vault read-only flag
+	if got, err := s.Vaults().SetReadOnly(ctx, userID, v.VaultID, true); err != nil || !got.ReadOnly {
+		t.Fatalf("SetReadOnly(true): got=%v err=%v", got, err)
+	}
+	if got, err := s.Vaults().GetByID(ctx, userID, v.VaultID); err != nil || !got.ReadOnly {
+		t.Fatalf("GetByID after SetReadOnly: got=%v err=%v", got, err)
+	}
+	if _, err := s.Vaults().SetReadOnly(ctx, userID, v.VaultID, false); err != nil {
+		t.Fatalf("SetReadOnly(false): %v", err)
+	}
+	if _, err := s.Vaults().SetReadOnly(ctx, userID, "no-such-vault", true); !errors.Is(err, model.ErrNotFound) {
+		t.Fatalf("SetReadOnly unknown vault: expected not found, got %v", err)
+	}
+
+	// Memory append-only flag: set once, blocks batch rollback
+	if got, err := s.Memories().SetAppendOnly(ctx, userID, v.VaultID, m.MemoryID); err != nil || !got.AppendOnly {
+		t.Fatalf("SetAppendOnly: got=%v err=%v", got, err)
+	}
+	if got, err := s.Memories().GetByID(ctx, userID, v.VaultID, m.MemoryID); err != nil || !got.AppendOnly {
+		t.Fatalf("GetByID after SetAppendOnly: got=%v err=%v", got, err)
+	}
+	ab, err := s.IngestionBatches().Create(ctx, &model.IngestionBatch{ActorID: userID, SourceSystem: "audit"})
+	if err != nil {
+		t.Fatalf("CreateBatch: %v", err)
+	}
+	if _, err := s.Entries().Create(ctx, &model.MemoryEntry{ActorID: userID, VaultID: v.VaultID, MemoryID: m.MemoryID, RawEntry: "audited", IngestionBatchID: ab.BatchID}); err != nil {
+		t.Fatalf("CreateEntry in append-only memory: %v", err)
+	}
+	if _, err := s.IngestionBatches().Rollback(ctx, userID, ab.BatchID); !errors.Is(err, model.ErrAppendOnly) {
+		t.Fatalf("Rollback into append-only memory: expected ErrAppendOnly, got %v", err)
+	}
+	if _, err := s.Memories().SetAppendOnly(ctx, userID, v.VaultID, "no-such-memory"); !errors.Is(err, model.ErrNotFound) {
+		t.Fatalf("SetAppendOnly unknown memory: expected not found, got %v", err)
+	}
+
+	// Memory entry roles: replaced as a whole, cleared with none
+	if got, err := s.Memories().SetEntryRoles(ctx, userID, v.VaultID, m.MemoryID, []string{"user", "assistant"}); err != nil || len(got.EntryRoles) != 2 || got.EntryRoles[1] != "assistant" {
+		t.Fatalf("SetEntryRoles: got=%v err=%v", got, err)
+	}
+	if got, err := s.Memories().SetEntryRoles(ctx, userID, v.VaultID, m.MemoryID, nil); err != nil || got.EntryRoles != nil {
+		t.Fatalf("SetEntryRoles(nil): got=%v err=%v", got, err)
+	}
+	if _, err := s.Memories().SetEntryRoles(ctx, userID, v.VaultID, "no-such-memory", []string{"user"}); !errors.Is(err, model.ErrNotFound) {
+		t.Fatalf("SetEntryRoles unknown memory: expected not found, got %v", err)
+	}
+
+	// Jobs: queued, claimed, reported, asked to cancel and finished
+	job, err := s.Jobs().Create(ctx, &model.Job{ActorID: userID, Kind: model.JobDeleteEntries, VaultID: v.VaultID, MemoryID: m.MemoryID, Params: []byte(`{"entryIds":["e1"]}`)})
+	if err != nil || job.JobID == "" || job.Status != model.JobQueued || job.CreationTime.IsZero() {
+		t.Fatalf("CreateJob: got=%+v err=%v", job, err)
+	}
+	if _, err := s.Jobs().Get(ctx, "someone-else", job.JobID); !errors.Is(err, model.ErrNotFound) {
+		t.Fatalf("GetJob of another actor: expected not found, got %v", err)
+	}
+	for {
+		claimed, err := s.Jobs().Claim(ctx, time.Now().Add(-time.Hour))
+		if err != nil || claimed == nil {
+			t.Fatalf("ClaimJob: got=%v err=%v", claimed, err)
+		}
+		if claimed.JobID == job.JobID {
+			if claimed.Status != model.JobRunning || claimed.MemoryID != m.MemoryID || string(claimed.Params) != `{"entryIds": ["e1"]}` {
+				t.Fatalf("claimed job: %+v params=%s", claimed, claimed.Params)
+			}
+			break
+		}
+	}
+	if cancel, err := s.Jobs().Progress(ctx, job.JobID, 1, 4); err != nil || cancel {
+		t.Fatalf("JobProgress: cancel=%v err=%v", cancel, err)
+	}
+	if got, err := s.Jobs().Cancel(ctx, userID, job.JobID); err != nil || got.Status != model.JobRunning || !got.CancelRequested {
+		t.Fatalf("CancelJob running: got=%+v err=%v", got, err)
+	}
+	if cancel, err := s.Jobs().Progress(ctx, job.JobID, 2, 4); err != nil || !cancel {
+		t.Fatalf("JobProgress after cancel: cancel=%v err=%v", cancel, err)
+	}
+	if err := s.Jobs().Finish(ctx, job.JobID, model.JobCancelled, ""); err != nil {
+		t.Fatalf("FinishJob: %v", err)
+	}
+	if got, err := s.Jobs().Get(ctx, userID, job.JobID); err != nil || got.Status != model.JobCancelled || got.Done != 2 || got.Total != 4 || got.FinishTime == nil {
+		t.Fatalf("GetJob: got=%+v err=%v", got, err)
+	}
+	if _, err := s.Jobs().Cancel(ctx, userID, job.JobID); !errors.Is(err, model.ErrConflict) {
+		t.Fatalf("CancelJob finished: expected conflict, got %v", err)
+	}
+
+	// Memory search boost: stored as given, cleared with nil
+	boost := &model.SearchBoost{Boost: 2, FieldWeights: map[string]float64{model.SearchFieldSummary: 1.5}}
+	if got, err := s.Memories().SetSearchBoost(ctx, userID, v.VaultID, m.MemoryID, boost); err != nil || got.SearchBoost == nil || got.SearchBoost.Boost != 2 || got.SearchBoost.FieldWeights[model.SearchFieldSummary] != 1.5 {
+		t.Fatalf("SetSearchBoost: got=%v err=%v", got, err)
+	}
+	ms, err := s.Memories().List(ctx, userID, v.VaultID)
+	if err != nil {
+		t.Fatalf("List after SetSearchBoost: %v", err)
+	}
+	for _, lm := range ms {
+		if lm.MemoryID == m.MemoryID && lm.SearchBoost == nil {
+			t.Fatalf("List after SetSearchBoost dropped the boost: %+v", lm)
+		}
+	}
+	if got, err := s.Memories().SetSearchBoost(ctx, userID, v.VaultID, m.MemoryID, nil); err != nil || got.SearchBoost != nil {
+		t.Fatalf("SetSearchBoost(nil): got=%v err=%v", got, err)
+	}
+	if _, err := s.Memories().SetSearchBoost(ctx, userID, v.VaultID, "no-such-memory", boost); !errors.Is(err, model.ErrNotFound) {
+		t.Fatalf("SetSearchBoost unknown memory: expected not found, got %v", err)
+	}
+
+	// Entry expiry: the memory keeps its entry TTL; entries past their
+	// expiration time are hidden from reads until ExpireDue deletes them
+	em, err := s.Memories().Create(ctx, &model.Memory{ActorID: userID, VaultID: v.VaultID, MemoryType: "NOTES", Title: "expiring-memory", EntryTTLSeconds: 60})
+	if err != nil || em.EntryTTLSeconds != 60 {
+		t.Fatalf("Create memory with entry TTL: got=%v err=%v", em, err)
+	}
+	if got, err := s.Memories().GetByID(ctx, userID, v.VaultID, em.MemoryID); err != nil || got.EntryTTLSeconds != 60 {
+		t.Fatalf("GetByID entry TTL: got=%v err=%v", got, err)
+	}
+	past, future := time.Now().Add(-time.Minute), time.Now().Add(time.Hour)
+	gone, err := s.Entries().Create(ctx, &model.MemoryEntry{ActorID: userID, VaultID: v.VaultID, MemoryID: em.MemoryID, RawEntry: "gone", ExpirationTime: &past})
+	if err != nil {
+		t.Fatalf("Create expired entry: %v", err)
+	}
+	kept, err := s.Entries().Create(ctx, &model.MemoryEntry{ActorID: userID, VaultID: v.VaultID, MemoryID: em.MemoryID, RawEntry: "kept", ExpirationTime: &future})
+	if err != nil {
+		t.Fatalf("Create expiring entry: %v", err)
+	}
+	if es, err := s.Entries().List(ctx, model.ListEntriesRequest{ActorID: userID, VaultID: v.VaultID, MemoryID: em.MemoryID, Limit: 10}); err != nil || len(es) != 1 || es[0].EntryID != kept.EntryID || es[0].ExpirationTime == nil {
+		t.Fatalf("List must hide expired entries: got=%v err=%v", es, err)
+	}
+	if _, err := s.Entries().GetByID(ctx, userID, v.VaultID, em.MemoryID, gone.EntryID); err == nil {
+		t.Fatal("GetByID must not return an expired entry")
+	}
+	if ex, err := s.Entries().Expired(ctx, userID, []string{gone.EntryID, kept.EntryID}, time.Now()); err != nil || !ex[gone.EntryID] || ex[kept.EntryID] {
+		t.Fatalf("Expired: got=%v err=%v", ex, err)
+	}
+	if ids, err := s.Entries().ExpireDue(ctx, time.Now(), 100); err != nil || !containsString(ids, gone.EntryID) || containsString(ids, kept.EntryID) {
+		t.Fatalf("ExpireDue: ids=%v err=%v", ids, err)
+	}
+	if ex, err := s.Entries().Expired(ctx, userID, []string{gone.EntryID}, time.Now()); err != nil || len(ex) != 0 {
+		t.Fatalf("Expired after ExpireDue: got=%v err=%v", ex, err)
+	}
+
+	// Idempotent entry writes: a repeated key returns the first entry
+	first, err := s.Entries().Create(ctx, &model.MemoryEntry{ActorID: userID, VaultID: v.VaultID, MemoryID: em.MemoryID, RawEntry: "once", IdempotencyKey: "k-1"})
+	if err != nil {
+		t.Fatalf("Create with idempotency key: %v", err)
+	}
+	again, err := s.Entries().CreateBatch(ctx, []*model.MemoryEntry{
+		{ActorID: userID, VaultID: v.VaultID, MemoryID: em.MemoryID, RawEntry: "once", IdempotencyKey: "k-1"},
+		{ActorID: userID, VaultID: v.VaultID, MemoryID: em.MemoryID, RawEntry: "twice", IdempotencyKey: "k-2"},
+	})
+	if err != nil || len(again) != 2 || again[0].EntryID != first.EntryID || again[1].EntryID == first.EntryID {
+		t.Fatalf("CreateBatch replaying an idempotency key: got=%v err=%v", again, err)
+	}
+	if es, err := s.Entries().List(ctx, model.ListEntriesRequest{ActorID: userID, VaultID: v.VaultID, MemoryID: em.MemoryID, Limit: 10}); err != nil || len(es) != 3 {
+		t.Fatalf("List after idempotent replay: got=%d entries err=%v", len(es), err)
+	}
+
+	// Corrections: chains resolve to their newest live entry
+	fix1, err := s.Entries().Correct(ctx, first.EntryID, &model.MemoryEntry{ActorID: userID, VaultID: v.VaultID, MemoryID: em.MemoryID, RawEntry: "fixed"}, "typo")
+	if err != nil || fix1.EntryID == first.EntryID {
+		t.Fatalf("Correct: got=%v err=%v", fix1, err)
+	}
+	if _, err := s.Entries().Correct(ctx, first.EntryID, &model.MemoryEntry{ActorID: userID, VaultID: v.VaultID, MemoryID: em.MemoryID, RawEntry: "again"}, ""); !errors.Is(err, model.ErrConflict) {
+		t.Fatalf("Correct a corrected entry: expected ErrConflict, got %v", err)
+	}
+	if _, err := s.Entries().Correct(ctx, "no-such-entry", &model.MemoryEntry{ActorID: userID, VaultID: v.VaultID, MemoryID: em.MemoryID, RawEntry: "x"}, ""); !errors.Is(err, model.ErrNotFound) {
+		t.Fatalf("Correct a missing entry: expected ErrNotFound, got %v", err)
+	}
+	if got, err := s.Entries().GetByID(ctx, userID, v.VaultID, em.MemoryID, first.EntryID); err != nil || got.CorrectionTime == nil || got.CorrectionReason != "typo" {
+		t.Fatalf("GetByID corrected entry: got=%+v err=%v", got, err)
+	}
+	fix2, err := s.Entries().Correct(ctx, fix1.EntryID, &model.MemoryEntry{ActorID: userID, VaultID: v.VaultID, MemoryID: em.MemoryID, RawEntry: "fixed again"}, "")
+	if err != nil {
+		t.Fatalf("Correct a correction: %v", err)
+	}
+	if cur, err := s.Entries().Corrections(ctx, userID, []string{first.EntryID, fix1.EntryID, fix2.EntryID}); err != nil || len(cur) != 2 ||
+		cur[first.EntryID] == nil || cur[first.EntryID].EntryID != fix2.EntryID || cur[fix1.EntryID] == nil || cur[fix1.EntryID].EntryID != fix2.EntryID {
+		t.Fatalf("Corrections: got=%v err=%v", cur, err)
+	}
+	if err := s.Entries().Trash(ctx, userID, v.VaultID, em.MemoryID, fix2.EntryID); err != nil {
+		t.Fatalf("Trash correction: %v", err)
+	}
+	if cur, err := s.Entries().Corrections(ctx, userID, []string{first.EntryID, fix1.EntryID}); err != nil ||
+		cur[first.EntryID] == nil || cur[first.EntryID].EntryID != fix1.EntryID || cur[fix1.EntryID] != nil {
+		t.Fatalf("Corrections after trashing the newest: got=%v err=%v", cur, err)
+	}
+	if err := s.Memories().Delete(ctx, userID, v.VaultID, em.MemoryID); err != nil {
+		t.Fatalf("Delete expiring memory: %v", err)
+	}
+
+	// Entity aliases: case-insensitive upsert, removed with the memory
+	if _, err := s.EntityAliases().Put(ctx, &model.EntityAlias{ActorID: userID, VaultID: v.VaultID, MemoryID: m.MemoryID, Alias: "Bob", Canonical: "Robert"}); err != nil {
+		t.Fatalf("PutAlias: %v", err)
+	}
+	if _, err := s.EntityAliases().Put(ctx, &model.EntityAlias{ActorID: userID, VaultID: v.VaultID, MemoryID: m.MemoryID, Alias: "BOB", Canonical: "Robert Smith"}); err != nil {
+		t.Fatalf("PutAlias replace: %v", err)
+	}
+	if as, err := s.EntityAliases().List(ctx, userID, m.MemoryID); err != nil || len(as) != 1 || as[0].Alias != "BOB" || as[0].Canonical != "Robert Smith" {
+		t.Fatalf("ListAliases: got=%v err=%v", as, err)
+	}
+	if err := s.EntityAliases().Delete(ctx, userID, v.VaultID, m.MemoryID, "no-such-alias"); !errors.Is(err, model.ErrNotFound) {
+		t.Fatalf("DeleteAlias unknown: expected not found, got %v", err)
+	}
+
+	// Webhooks: unique name per memory, deliveries counted, removed with the memory
+	hook := &model.Webhook{WebhookID: "test-webhook", ActorID: userID, VaultID: v.VaultID, MemoryID: m.MemoryID, Name: "ci", Kind: model.WebhookGeneric,
+		Secret: "s3cret", Mapping: model.WebhookMapping{RawEntry: "{{text}}", Tags: map[string]string{"repo": "{{repo}}"}}}
+	if _, err := s.Webhooks().Create(ctx, hook); err != nil {
+		t.Fatalf("CreateWebhook: %v", err)
+	}
+	dup := *hook
+	dup.WebhookID = "test-webhook-2"
+	if _, err := s.Webhooks().Create(ctx, &dup); !errors.Is(err, model.ErrConflict) {
+		t.Fatalf("CreateWebhook duplicate name: expected conflict, got %v", err)
+	}
+	if err := s.Webhooks().RecordDelivery(ctx, hook.WebhookID, time.Now()); err != nil {
+		t.Fatalf("RecordDelivery: %v", err)
+	}
+	if got, err := s.Webhooks().Get(ctx, hook.WebhookID); err != nil || got.Secret != "s3cret" || got.Mapping.Tags["repo"] != "{{repo}}" || got.DeliveryCount != 1 || got.LastDeliveryTime == nil {
+		t.Fatalf("GetWebhook: got=%+v err=%v", got, err)
+	}
+	if ws, err := s.Webhooks().List(ctx, userID, m.MemoryID); err != nil || len(ws) != 1 {
+		t.Fatalf("ListWebhooks: got=%v err=%v", ws, err)
+	}
+	if err := s.Webhooks().Delete(ctx, userID, v.VaultID, m.MemoryID, "no-such-webhook"); !errors.Is(err, model.ErrNotFound) {
+		t.Fatalf("DeleteWebhook unknown: expected not found, got %v", err)
+	}
+
+	// Moving the memory to another vault takes its aliases and webhooks along
+	other, err := s.Vaults().Create(ctx, &model.Vault{ActorID: userID, Title: "test-vault-move"})
+	if err != nil {
+		t.Fatalf("CreateVault for move: %v", err)
+	}
+	if err := s.Vaults().AddMemory(ctx, userID, other.VaultID, m.MemoryID); err != nil {
+		t.Fatalf("AddMemory: %v", err)
+	}
+	if got, err := s.Webhooks().Get(ctx, hook.WebhookID); err != nil || got.VaultID != other.VaultID {
+		t.Fatalf("GetWebhook after move: got=%+v err=%v", got, err)
+	}
+	if as, err := s.EntityAliases().List(ctx, userID, m.MemoryID); err != nil || len(as) != 1 || as[0].VaultID != other.VaultID {
+		t.Fatalf("ListAliases after move: got=%v err=%v", as, err)
+	}
+	if err := s.Vaults().AddMemory(ctx, userID, v.VaultID, m.MemoryID); err != nil {
+		t.Fatalf("AddMemory back: %v", err)
+	}
+
+	// Vault clone: fresh IDs, same entries and latest context, reindex job per memory
+	srcEntries, err := s.Entries().List(ctx, model.ListEntriesRequest{ActorID: userID, VaultID: v.VaultID, MemoryID: m.MemoryID})
+	if err != nil {
+		t.Fatalf("ListEntries before clone: %v", err)
+	}
+	cl, err := s.Vaults().Clone(ctx, model.VaultClone{ActorID: userID, SourceVaultID: v.VaultID, Title: "test-vault-clone", Reindex: true})
+	if err != nil {
+		t.Fatalf("Clone: %v", err)
+	}
+	if cl.VaultID == v.VaultID || len(cl.Memories) != 1 || cl.EntryCount != len(srcEntries) {
+		t.Fatalf("Clone: got=%+v want %d entries", cl, len(srcEntries))
+	}
+	cm := cl.Memories[0]
+	if cm.MemoryID == m.MemoryID || cm.SourceMemoryID != m.MemoryID || cm.Title != "m1" || !cm.AppendOnly || !cm.ContextCopied || cm.ReindexJobID == "" {
+		t.Fatalf("Clone memory: got=%+v", cm)
+	}
+	cloned, err := s.Entries().List(ctx, model.ListEntriesRequest{ActorID: userID, VaultID: cl.VaultID, MemoryID: cm.MemoryID})
+	if err != nil || len(cloned) != len(srcEntries) {
+		t.Fatalf("ListEntries of clone: n=%d err=%v", len(cloned), err)
+	}
+	for i := range cloned {
+		if cloned[i].EntryID == srcEntries[i].EntryID || cloned[i].RawEntry != srcEntries[i].RawEntry || !cloned[i].CreationTime.Equal(srcEntries[i].CreationTime) {
+			t.Fatalf("cloned entry %d: got=%+v source=%+v", i, cloned[i], srcEntries[i])
+		}
+	}
+	if job, err := s.Reindex().Latest(ctx, userID, cm.MemoryID); err != nil || job.JobID != cm.ReindexJobID || job.EntryCount != len(srcEntries) || job.ContextCount != 1 {
+		t.Fatalf("reindex job of clone: got=%+v err=%v", job, err)
+	}
+	if _, err := s.Vaults().Clone(ctx, model.VaultClone{ActorID: userID, SourceVaultID: v.VaultID, Title: "test-vault-clone"}); !errors.Is(err, model.ErrConflict) {
+		t.Fatalf("Clone onto a taken title: expected conflict, got %v", err)
+	}
+	if _, err := s.Vaults().Clone(ctx, model.VaultClone{ActorID: userID, SourceVaultID: "no-such-vault", Title: "x"}); !errors.Is(err, model.ErrNotFound) {
+		t.Fatalf("Clone unknown vault: expected not found, got %v", err)
+	}
+	if err := s.Vaults().Delete(ctx, userID, cl.VaultID); err != nil {
+		t.Fatalf("Delete clone: %v", err)
+	}
+
+	// Bootstrap: vault, memory and first context at once; the client token replays
+	boot := model.Bootstrap{
+		ActorID: userID, ClientToken: "test-bootstrap",
+		Vault:   model.Vault{Title: "test-vault-bootstrap"},
+		Memory:  model.Memory{MemoryType: "conversation", Title: "chat"},
+		Context: "starting context",
+	}
+	br, err := s.Vaults().Bootstrap(ctx, boot)
+	if err != nil || br.Replayed || br.Vault.Slug != "test-vault-bootstrap" || br.Memory.VaultID != br.Vault.VaultID || br.ContextID == "" {
+		t.Fatalf("Bootstrap: got=%+v err=%v", br, err)
+	}
+	if lc, err := s.Contexts().Latest(ctx, userID, br.Vault.VaultID, br.Memory.MemoryID); err != nil || lc.ContextID != br.ContextID || lc.Context != "starting context" {
+		t.Fatalf("context of bootstrap: got=%+v err=%v", lc, err)
+	}
+	if again, err := s.Vaults().Bootstrap(ctx, boot); err != nil || !again.Replayed || again.Vault.VaultID != br.Vault.VaultID || again.Memory.MemoryID != br.Memory.MemoryID || again.ContextID != br.ContextID {
+		t.Fatalf("Bootstrap retry: got=%+v err=%v", again, err)
+	}
+	changed := boot
+	changed.Context = "other"
+	if _, err := s.Vaults().Bootstrap(ctx, changed); !errors.Is(err, model.ErrConflict) {
+		t.Fatalf("Bootstrap reusing a token: expected conflict, got %v", err)
+	}
+	boot.ClientToken = "test-bootstrap-2"
+	if _, err := s.Vaults().Bootstrap(ctx, boot); !errors.Is(err, model.ErrConflict) {
+		t.Fatalf("Bootstrap onto a taken title: expected conflict, got %v", err)
+	}
+	if err := s.Vaults().Delete(ctx, userID, br.Vault.VaultID); err != nil {
+		t.Fatalf("Delete bootstrap vault: %v", err)
+	}
+
 	// Delete memory and vault
 	if err := s.Memories().Delete(ctx, userID, v.VaultID, m.MemoryID); err != nil {
 		t.Fatalf("DeleteMemory: %v", err)
 	}
+	if as, err := s.EntityAliases().List(ctx, userID, m.MemoryID); err != nil || len(as) != 0 {
+		t.Fatalf("ListAliases after memory delete: got=%v err=%v", as, err)
+	}
+	if _, err := s.Webhooks().Get(ctx, "test-webhook"); !errors.Is(err, model.ErrNotFound) {
+		t.Fatalf("GetWebhook after memory delete: expected not found, got %v", err)
+	}
 	if err := s.Vaults().Delete(ctx, userID, v.VaultID); err != nil {
 		t.Fatalf("DeleteVault: %v", err)
 	}
 }
+
+func containsString(xs []string, s string) bool {
+	for _, x := range xs {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
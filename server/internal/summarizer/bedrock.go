@@ -0,0 +1,79 @@
+package summarizer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Bedrock generates with the Converse API of Amazon Bedrock, authenticated
+// with a Bedrock API key (the bearer token AWS issues for Bedrock), which
+// avoids request signing.
+type Bedrock struct {
+	baseURL string
+	apiKey  string
+	model   string
+	http    *http.Client
+}
+
+// NewBedrock returns a generator calling model (a model or inference
+// profile ID) in region. baseURL overrides the regional endpoint, e.g. for
+// a VPC endpoint.
+func NewBedrock(baseURL, region, apiKey, model string) *Bedrock {
+	if baseURL == "" {
+		baseURL = fmt.Sprintf("https://bedrock-runtime.%s.amazonaws.com", region)
+	}
+	return &Bedrock{baseURL: strings.TrimRight(baseURL, "/"), apiKey: apiKey, model: model, http: &http.Client{Timeout: 60 * time.Second}}
+}
+
+type bedrockMessage struct {
+	Role    string        `json:"role"`
+	Content []bedrockText `json:"content"`
+}
+
+type bedrockText struct {
+	Text string `json:"text"`
+}
+
+// Generate returns the model's trimmed reply to prompt sent as one user message.
+func (b *Bedrock) Generate(ctx context.Context, prompt string) (string, error) {
+	body, _ := json.Marshal(struct {
+		Messages []bedrockMessage `json:"messages"`
+	}{Messages: []bedrockMessage{{Role: "user", Content: []bedrockText{{Text: prompt}}}}})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.baseURL+"/model/"+url.PathEscape(b.model)+"/converse", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+b.apiKey)
+	resp, err := b.http.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	var out struct {
+		Output struct {
+			Message bedrockMessage `json:"message"`
+		} `json:"output"`
+		Message string `json:"message"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil && resp.StatusCode/100 == 2 {
+		return "", err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		if out.Message != "" {
+			return "", fmt.Errorf("bedrock converse status %d: %s", resp.StatusCode, out.Message)
+		}
+		return "", fmt.Errorf("bedrock converse status %d", resp.StatusCode)
+	}
+	var text strings.Builder
+	for _, c := range out.Output.Message.Content {
+		text.WriteString(c.Text)
+	}
+	return strings.TrimSpace(text.String()), nil
+}
@@ -0,0 +1,64 @@
+package summarizer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Generator completes a free-form prompt with an LLM. Only LLM-backed
+// summarizers implement it; Extractive does not.
+type Generator interface {
+	Generate(ctx context.Context, prompt string) (string, error)
+}
+
+// contextRules condense the client's context maintenance prompt
+// (client/prompts/default/chat/context_prompt.md).
+const contextRules = `You maintain the single context document of a memory: a concise Markdown
+document with only the durable information needed for long-horizon reasoning.
+
+Rules
+- Capture durable facts, preferences, decisions, key topics and important entities.
+- Do not copy chat history; summarize only what matters to future reasoning.
+- Prefer terse bullets and one-liners; revise items only when clearly superseded.
+- Keep dates when helpful (YYYY-MM-DD). Omit redundant phrasing.
+- Use these headings and omit any that would be empty: # Description, # Facts,
+  # Preferences, # Decisions, # Topics, # Entities (subjects, objects), # Notes, # Timeline.
+- Build the document from the raw entries and the current context only.
+`
+
+// ContextPromptInput is what ContextPrompt renders. Entries are raw entry
+// texts, oldest first; Current is the context being updated, empty when the
+// memory has none.
+type ContextPromptInput struct {
+	MemoryTitle string
+	MemoryType  string
+	Today       string
+	Current     string
+	Entries     []string
+	MaxChars    int
+}
+
+// ContextPrompt builds the prompt that asks a Generator to rewrite a
+// memory's context document from its recent entries.
+func ContextPrompt(in ContextPromptInput) string {
+	var b strings.Builder
+	b.WriteString(contextRules)
+	if in.MaxChars > 0 {
+		fmt.Fprintf(&b, "- The document must not exceed %d characters; when trimming, keep recent information.\n", in.MaxChars)
+	}
+	fmt.Fprintf(&b, "\nMemory: %s (%s). Today is %s.\n", in.MemoryTitle, in.MemoryType, in.Today)
+	b.WriteString("\nCurrent context:\n")
+	if strings.TrimSpace(in.Current) == "" {
+		b.WriteString("(empty)\n")
+	} else {
+		b.WriteString(in.Current)
+		b.WriteString("\n")
+	}
+	b.WriteString("\nNew raw entries, oldest first:\n")
+	for i, e := range in.Entries {
+		fmt.Fprintf(&b, "[%d] %s\n", i+1, strings.TrimSpace(e))
+	}
+	b.WriteString("\nReply with the full updated context document as plain-text Markdown only.\n")
+	return b.String()
+}
@@ -0,0 +1,66 @@
+package summarizer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Ollama generates with a model served by Ollama at OLLAMA_URL.
+type Ollama struct {
+	model     string
+	keepAlive string
+	http      *http.Client
+}
+
+// NewOllama returns a generator using model; keepAlive is passed to Ollama
+// as for the embedder (empty uses Ollama's default).
+func NewOllama(model, keepAlive string) *Ollama {
+	return &Ollama{model: model, keepAlive: keepAlive, http: &http.Client{Timeout: 60 * time.Second}}
+}
+
+// Generate returns the model's trimmed completion of prompt.
+func (o *Ollama) Generate(ctx context.Context, prompt string) (string, error) {
+	base := os.Getenv("OLLAMA_URL")
+	if base == "" {
+		base = "http://localhost:11434"
+	}
+	if !strings.HasPrefix(base, "http://") && !strings.HasPrefix(base, "https://") {
+		base = "http://" + base
+	}
+	body, _ := json.Marshal(struct {
+		Model     string `json:"model"`
+		Prompt    string `json:"prompt"`
+		Stream    bool   `json:"stream"`
+		KeepAlive string `json:"keep_alive,omitempty"`
+	}{Model: o.model, Prompt: prompt, KeepAlive: o.keepAlive})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, base+"/api/generate", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := o.http.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("ollama generate status %d", resp.StatusCode)
+	}
+	var out struct {
+		Response string `json:"response"`
+		Error    string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", err
+	}
+	if out.Error != "" {
+		return "", fmt.Errorf("ollama generate error: %s", out.Error)
+	}
+	return strings.TrimSpace(out.Response), nil
+}
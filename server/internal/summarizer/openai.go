@@ -0,0 +1,79 @@
+package summarizer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultOpenAIURL is the API base used when none is configured.
+const DefaultOpenAIURL = "https://api.openai.com/v1"
+
+// OpenAI generates with the chat completions API of OpenAI or of a
+// compatible server (vLLM, LiteLLM, Azure OpenAI behind a proxy).
+type OpenAI struct {
+	baseURL string
+	apiKey  string
+	model   string
+	http    *http.Client
+}
+
+// NewOpenAI returns a generator calling model at baseURL (DefaultOpenAIURL
+// when empty) with apiKey as bearer token.
+func NewOpenAI(baseURL, apiKey, model string) *OpenAI {
+	if baseURL == "" {
+		baseURL = DefaultOpenAIURL
+	}
+	return &OpenAI{baseURL: strings.TrimRight(baseURL, "/"), apiKey: apiKey, model: model, http: &http.Client{Timeout: 60 * time.Second}}
+}
+
+type openAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Generate returns the model's trimmed reply to prompt sent as one user message.
+func (o *OpenAI) Generate(ctx context.Context, prompt string) (string, error) {
+	body, _ := json.Marshal(struct {
+		Model    string          `json:"model"`
+		Messages []openAIMessage `json:"messages"`
+	}{Model: o.model, Messages: []openAIMessage{{Role: "user", Content: prompt}}})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.baseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if o.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+o.apiKey)
+	}
+	resp, err := o.http.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	var out struct {
+		Choices []struct {
+			Message openAIMessage `json:"message"`
+		} `json:"choices"`
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil && resp.StatusCode/100 == 2 {
+		return "", err
+	}
+	if out.Error != nil {
+		return "", fmt.Errorf("openai chat completion status %d: %s", resp.StatusCode, out.Error.Message)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("openai chat completion status %d", resp.StatusCode)
+	}
+	if len(out.Choices) == 0 {
+		return "", fmt.Errorf("openai chat completion returned no choices")
+	}
+	return strings.TrimSpace(out.Choices[0].Message.Content), nil
+}
@@ -0,0 +1,234 @@
+package summarizer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// DefaultPrompt asks for an entry summary when no prompt is configured for
+// the memory type.
+const DefaultPrompt = "Summarize the following conversation excerpt in one or two sentences. " +
+	"Keep names, dates, numbers and decisions. Reply with the summary only."
+
+// PipelineConfig tunes a Pipeline. Prompts maps memory types to summary
+// prompts; types without one use Prompts[""], or DefaultPrompt. Up to
+// BatchSize texts are summarized per LLM call (1 or less sends one call per
+// text). RequestsPerMinute spaces calls out evenly; 0 does not limit them.
+type PipelineConfig struct {
+	Provider          string
+	Prompts           map[string]string
+	BatchSize         int
+	RequestsPerMinute int
+}
+
+// PipelineStats counts a Pipeline's work since startup. Fallbacks counts
+// batches whose reply could not be split and were resent one text per call.
+type PipelineStats struct {
+	Provider       string `json:"provider"`
+	Calls          int64  `json:"calls"`
+	Failures       int64  `json:"failures"`
+	Texts          int64  `json:"texts"`
+	Fallbacks      int64  `json:"fallbacks"`
+	CallMillis     int64  `json:"callMillis"`
+	ThrottleMillis int64  `json:"throttleMillis"`
+}
+
+// Pipeline is the one path server-side LLM calls take: it implements both
+// Summarizer and Generator on top of a provider, batching summaries,
+// selecting their prompt per memory type, rate limiting calls and counting
+// them for GET /debug/vars.
+type Pipeline struct {
+	gen     Generator
+	cfg     PipelineConfig
+	log     zerolog.Logger
+	limiter *limiter
+
+	calls, failures, texts, fallbacks atomic.Int64
+	callMillis, throttleMillis        atomic.Int64
+}
+
+// NewPipeline wraps gen.
+func NewPipeline(gen Generator, cfg PipelineConfig, log zerolog.Logger) *Pipeline {
+	p := &Pipeline{gen: gen, cfg: cfg, log: log}
+	if cfg.RequestsPerMinute > 0 {
+		p.limiter = &limiter{interval: time.Minute / time.Duration(cfg.RequestsPerMinute)}
+	}
+	return p
+}
+
+// Stats returns the pipeline's counters.
+func (p *Pipeline) Stats() PipelineStats {
+	return PipelineStats{
+		Provider: p.cfg.Provider, Calls: p.calls.Load(), Failures: p.failures.Load(), Texts: p.texts.Load(),
+		Fallbacks: p.fallbacks.Load(), CallMillis: p.callMillis.Load(), ThrottleMillis: p.throttleMillis.Load(),
+	}
+}
+
+// Generate sends prompt to the provider once the rate limit allows.
+func (p *Pipeline) Generate(ctx context.Context, prompt string) (string, error) {
+	if p.limiter != nil {
+		waited, err := p.limiter.wait(ctx)
+		p.throttleMillis.Add(waited.Milliseconds())
+		if err != nil {
+			return "", err
+		}
+	}
+	start := time.Now()
+	out, err := p.gen.Generate(ctx, prompt)
+	elapsed := time.Since(start)
+	p.calls.Add(1)
+	p.callMillis.Add(elapsed.Milliseconds())
+	if err != nil {
+		p.failures.Add(1)
+		p.log.Warn().Err(err).Str("provider", p.cfg.Provider).Dur("elapsed", elapsed).Msg("llm call failed")
+		return "", err
+	}
+	p.log.Debug().Str("provider", p.cfg.Provider).Dur("elapsed", elapsed).Int("prompt_chars", len(prompt)).Msg("llm call")
+	return out, nil
+}
+
+// Summarize condenses texts in batches of cfg.BatchSize with the prompt
+// of memoryType. A batch whose reply is not one summary per text is resent
+// one text per call; any failed call fails the whole request.
+func (p *Pipeline) Summarize(ctx context.Context, memoryType string, texts []string) ([]string, error) {
+	prompt := p.prompt(memoryType)
+	size := max(p.cfg.BatchSize, 1)
+	out := make([]string, 0, len(texts))
+	for start := 0; start < len(texts); start += size {
+		batch := texts[start:min(start+size, len(texts))]
+		sums, err := p.summarizeBatch(ctx, prompt, batch)
+		if err != nil {
+			return nil, fmt.Errorf("texts %d-%d: %w", start, start+len(batch)-1, err)
+		}
+		out = append(out, sums...)
+	}
+	p.texts.Add(int64(len(texts)))
+	return out, nil
+}
+
+func (p *Pipeline) prompt(memoryType string) string {
+	if s, ok := p.cfg.Prompts[memoryType]; ok {
+		return s
+	}
+	if s, ok := p.cfg.Prompts[""]; ok {
+		return s
+	}
+	return DefaultPrompt
+}
+
+func (p *Pipeline) summarizeBatch(ctx context.Context, prompt string, texts []string) ([]string, error) {
+	if len(texts) > 1 {
+		reply, err := p.Generate(ctx, batchPrompt(prompt, texts))
+		if err != nil {
+			return nil, err
+		}
+		if sums, ok := splitBatchReply(reply, len(texts)); ok {
+			return sums, nil
+		}
+		p.fallbacks.Add(1)
+		p.log.Warn().Str("provider", p.cfg.Provider).Int("texts", len(texts)).Msg("llm batch reply unusable; summarizing one by one")
+	}
+	out := make([]string, len(texts))
+	for i, text := range texts {
+		sum, err := p.Generate(ctx, prompt+"\n\n"+text)
+		if err != nil {
+			return nil, err
+		}
+		if sum == "" {
+			return nil, fmt.Errorf("%s returned an empty summary", p.cfg.Provider)
+		}
+		out[i] = sum
+	}
+	return out, nil
+}
+
+// batchPrompt asks for the summaries of several texts as a JSON array.
+func batchPrompt(prompt string, texts []string) string {
+	var b strings.Builder
+	b.WriteString(prompt)
+	fmt.Fprintf(&b, "\n\nApply this to each of the %d numbered excerpts below separately. "+
+		"Reply with a JSON array of exactly %d strings, the summaries in excerpt order, and nothing else.\n", len(texts), len(texts))
+	for i, t := range texts {
+		fmt.Fprintf(&b, "\n[%d]\n%s\n", i+1, strings.TrimSpace(t))
+	}
+	return b.String()
+}
+
+// splitBatchReply extracts n non-empty summaries from a batch reply,
+// tolerating text or code fences around the JSON array.
+func splitBatchReply(reply string, n int) ([]string, bool) {
+	i, j := strings.IndexByte(reply, '['), strings.LastIndexByte(reply, ']')
+	if i < 0 || j < i {
+		return nil, false
+	}
+	var sums []string
+	if err := json.Unmarshal([]byte(reply[i:j+1]), &sums); err != nil || len(sums) != n {
+		return nil, false
+	}
+	for k, s := range sums {
+		if sums[k] = strings.TrimSpace(s); sums[k] == "" {
+			return nil, false
+		}
+	}
+	return sums, true
+}
+
+// limiter spaces calls at least interval apart.
+type limiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// wait blocks until the caller's slot and reports how long it waited.
+func (l *limiter) wait(ctx context.Context) (time.Duration, error) {
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	d := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	l.mu.Unlock()
+	if d <= 0 {
+		return 0, nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return d, nil
+	case <-ctx.Done():
+		return time.Since(now), ctx.Err()
+	}
+}
+
+// LoadPrompts reads summary prompts by memory type from a JSON object, e.g.
+// {"": "default prompt", "project": "..."}. An empty path yields none.
+func LoadPrompts(path string) (map[string]string, error) {
+	out := map[string]string{}
+	if path == "" {
+		return out, nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read summarizer prompts: %w", err)
+	}
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, fmt.Errorf("parse summarizer prompts %s: %w", path, err)
+	}
+	for memoryType, prompt := range out {
+		if strings.TrimSpace(prompt) == "" {
+			return nil, fmt.Errorf("summarizer prompts %s: empty prompt for memory type %q", path, memoryType)
+		}
+	}
+	return out, nil
+}
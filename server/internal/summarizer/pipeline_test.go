@@ -0,0 +1,135 @@
+package summarizer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// scriptedGenerator records prompts and answers each with reply(prompt).
+type scriptedGenerator struct {
+	prompts []string
+	reply   func(prompt string) (string, error)
+}
+
+func (g *scriptedGenerator) Generate(_ context.Context, prompt string) (string, error) {
+	g.prompts = append(g.prompts, prompt)
+	return g.reply(prompt)
+}
+
+func TestPipelineBatchesAndSelectsPrompt(t *testing.T) {
+	g := &scriptedGenerator{reply: func(prompt string) (string, error) {
+		n := strings.Count(prompt, "\n[")
+		if n == 0 {
+			return "single", nil
+		}
+		sums := make([]string, n)
+		for i := range sums {
+			sums[i] = fmt.Sprintf("s%d", i+1)
+		}
+		b, _ := json.Marshal(sums)
+		return "```json\n" + string(b) + "\n```", nil
+	}}
+	p := NewPipeline(g, PipelineConfig{Provider: "test", BatchSize: 2, Prompts: map[string]string{"project": "PROJECT PROMPT"}}, zerolog.Nop())
+
+	got, err := p.Summarize(context.Background(), "project", []string{"a", "b", "c"})
+	if err != nil || strings.Join(got, ",") != "s1,s2,single" {
+		t.Fatalf("Summarize: got=%q err=%v", got, err)
+	}
+	if len(g.prompts) != 2 || !strings.HasPrefix(g.prompts[0], "PROJECT PROMPT") || !strings.Contains(g.prompts[0], "[2]\nb") {
+		t.Fatalf("unexpected prompts: %q", g.prompts)
+	}
+	if _, err := p.Summarize(context.Background(), "conversation", []string{"x", "y"}); err != nil || !strings.HasPrefix(g.prompts[2], DefaultPrompt) {
+		t.Fatalf("default prompt not used: err=%v prompt=%q", err, g.prompts[2])
+	}
+	if st := p.Stats(); st.Calls != 3 || st.Texts != 5 || st.Fallbacks != 0 || st.Provider != "test" {
+		t.Fatalf("unexpected stats: %+v", st)
+	}
+}
+
+func TestPipelineFallsBackOnUnusableBatchReply(t *testing.T) {
+	g := &scriptedGenerator{reply: func(prompt string) (string, error) {
+		if strings.Contains(prompt, "JSON array") {
+			return `["only one"]`, nil
+		}
+		return "one by one", nil
+	}}
+	p := NewPipeline(g, PipelineConfig{BatchSize: 8}, zerolog.Nop())
+	got, err := p.Summarize(context.Background(), "", []string{"a", "b"})
+	if err != nil || len(got) != 2 || got[1] != "one by one" || len(g.prompts) != 3 {
+		t.Fatalf("fallback: got=%q err=%v calls=%d", got, err, len(g.prompts))
+	}
+	if st := p.Stats(); st.Fallbacks != 1 {
+		t.Fatalf("unexpected stats: %+v", st)
+	}
+
+	g.reply = func(string) (string, error) { return "", fmt.Errorf("model unavailable") }
+	if _, err := p.Summarize(context.Background(), "", []string{"a"}); err == nil || !strings.Contains(err.Error(), "model unavailable") {
+		t.Fatalf("expected the provider error, got %v", err)
+	}
+	if st := p.Stats(); st.Failures != 1 {
+		t.Fatalf("failure not counted: %+v", st)
+	}
+}
+
+func TestPipelineRateLimit(t *testing.T) {
+	g := &scriptedGenerator{reply: func(string) (string, error) { return "ok", nil }}
+	p := NewPipeline(g, PipelineConfig{RequestsPerMinute: 1200}, zerolog.Nop()) // one call per 50ms
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := p.Generate(context.Background(), "x"); err != nil {
+			t.Fatalf("Generate: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Fatalf("3 calls at 1200/min took %v", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	p = NewPipeline(g, PipelineConfig{RequestsPerMinute: 1}, zerolog.Nop())
+	_, _ = p.Generate(context.Background(), "first")
+	if _, err := p.Generate(ctx, "throttled"); err == nil {
+		t.Fatal("expected the canceled context to end the wait")
+	}
+}
+
+func TestProviders(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer k" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":{"message":"bad key"},"message":"bad key"}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/chat/completions":
+			_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":" from openai "}}]}`))
+		case "/model/anthropic.claude-3-haiku:0/converse":
+			_, _ = w.Write([]byte(`{"output":{"message":{"role":"assistant","content":[{"text":"from bedrock"}]}}}`))
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+
+	if out, err := NewOpenAI(srv.URL+"/v1/", "k", "gpt-4o-mini").Generate(ctx, "hi"); err != nil || out != "from openai" {
+		t.Fatalf("openai: %q %v", out, err)
+	}
+	if out, err := NewBedrock(srv.URL, "us-east-1", "k", "anthropic.claude-3-haiku:0").Generate(ctx, "hi"); err != nil || out != "from bedrock" {
+		t.Fatalf("bedrock: %q %v", out, err)
+	}
+	if _, err := NewOpenAI(srv.URL+"/v1", "wrong", "m").Generate(ctx, "hi"); err == nil || !strings.Contains(err.Error(), "bad key") {
+		t.Fatalf("openai error: %v", err)
+	}
+	if _, err := NewBedrock(srv.URL, "", "wrong", "m").Generate(ctx, "hi"); err == nil || !strings.Contains(err.Error(), "bad key") {
+		t.Fatalf("bedrock error: %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+// Package summarizer produces the short summaries stored with entries the
+// server writes on an agent's behalf, e.g. from an ingested conversation,
+// and the context documents regenerated by POST .../summarize. Every
+// server-side LLM call goes through a Pipeline wrapping one provider
+// (Ollama, OpenAI or Bedrock).
+package summarizer
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+)
+
+// Summarizer condenses texts (each one message or several "role: content"
+// lines) into short summaries, returned in the same order. memoryType is
+// the type of the memory the summaries are written to and selects the
+// prompt of LLM-backed summarizers.
+type Summarizer interface {
+	Summarize(ctx context.Context, memoryType string, texts []string) ([]string, error)
+}
+
+// DefaultMaxChars bounds extractive summaries.
+const DefaultMaxChars = 280
+
+// Extractive summarizes without a model: the first sentence of every line,
+// joined and truncated to MaxChars at a word boundary. It is the fallback
+// when no LLM is configured.
+type Extractive struct {
+	MaxChars int
+}
+
+func (e Extractive) Summarize(_ context.Context, _ string, texts []string) ([]string, error) {
+	limit := e.MaxChars
+	if limit <= 0 {
+		limit = DefaultMaxChars
+	}
+	out := make([]string, len(texts))
+	for i, text := range texts {
+		out[i] = extract(text, limit)
+	}
+	return out, nil
+}
+
+func extract(text string, limit int) string {
+	var parts []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.Join(strings.Fields(line), " ")
+		if line != "" {
+			parts = append(parts, firstSentence(line))
+		}
+	}
+	return truncate(strings.Join(parts, " / "), limit)
+}
+
+func firstSentence(s string) string {
+	for i, r := range s {
+		if (r == '.' || r == '!' || r == '?') && (i+1 == len(s) || s[i+1] == ' ') {
+			return s[:i+1]
+		}
+	}
+	return s
+}
+
+func truncate(s string, limit int) string {
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	r := []rune(s)[:limit-1]
+	cut := string(r)
+	if i := strings.LastIndexByte(cut, ' '); i > len(cut)/2 {
+		cut = cut[:i]
+	}
+	return cut + "…"
+}
@@ -0,0 +1,26 @@
+package summarizer
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestExtractive(t *testing.T) {
+	got, _ := Extractive{}.Summarize(context.Background(), "conversation", []string{
+		"user: Hi there!  How are you?\n\nassistant: Fine. Thanks for asking.",
+		"version 1.2 is out",
+	})
+	if len(got) != 2 || got[0] != "user: Hi there! / assistant: Fine." {
+		t.Fatalf("unexpected summaries: %q", got)
+	}
+	if got[1] != "version 1.2 is out" {
+		t.Fatalf("decimal point split the sentence: %q", got[1])
+	}
+	long := strings.Repeat("word ", 100)
+	got, _ = Extractive{MaxChars: 40}.Summarize(context.Background(), "", []string{long})
+	if utf8.RuneCountInString(got[0]) > 40 || !strings.HasSuffix(got[0], "…") || strings.Contains(got[0], "wor…") {
+		t.Fatalf("unexpected truncation: %q", got[0])
+	}
+}
@@ -2,6 +2,7 @@ package memoryservice
 
 import (
 	"context"
+	"expvar"
 	"fmt"
 	"net"
 	"net/http"
@@ -17,10 +18,15 @@ import (
 	"github.com/mycelian/mycelian-memory/server/internal/factory"
 	"github.com/mycelian/mycelian-memory/server/internal/health"
 	"github.com/mycelian/mycelian-memory/server/internal/logger"
+	"github.com/mycelian/mycelian-memory/server/internal/metrics"
+	"github.com/mycelian/mycelian-memory/server/internal/outbox"
 	"github.com/mycelian/mycelian-memory/server/internal/searchindex"
 	"github.com/mycelian/mycelian-memory/server/internal/services"
 	"github.com/mycelian/mycelian-memory/server/internal/store"
+	"github.com/mycelian/mycelian-memory/server/internal/store/postgres"
+	"github.com/mycelian/mycelian-memory/server/internal/summarizer"
 	"github.com/rs/zerolog"
+	zlog "github.com/rs/zerolog/log"
 )
 
 // Run starts the memory service HTTP server and blocks until shutdown or error.
@@ -32,6 +38,15 @@ func Run() error {
 		log.Error().Err(err).Msg("Failed to load configuration")
 		return err
 	}
+	logs, err := logger.Open("memory-service", cfg.LoggerConfig())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to open log sinks")
+		return err
+	}
+	defer func() { _ = logs.Close() }()
+	log = logs.Logger()
+	// HTTP handlers log through the global logger.
+	zlog.Logger = logs.Module(logger.ModuleAPI)
 
 	log.Info().
 		Str("build_target", cfg.BuildTarget).
@@ -47,16 +62,36 @@ func Run() error {
 	defer stop()
 
 	// Initialize dependencies (store, index, embedder)
-	st, idx, embedProvider, err := initDependencies(ctx, cfg, log)
+	st, idx, embedProvider, err := initDependencies(ctx, cfg, logs)
 	if err != nil {
 		return err
 	}
 
-	// Build router
-	router := buildRouter(st, idx, embedProvider, cfg, log)
+	slo, err := newSLOTracker(cfg, log)
+	if err != nil {
+		log.Error().Err(err).Msg("Invalid SLO configuration")
+		return err
+	}
+
+	// Health checks probe the database itself; everything else may read
+	// through the hot cache.
+	dbStore := st
+	if cfg.HotCacheSize > 0 {
+		hot := services.NewHotCache(cfg.HotCacheSize, time.Duration(cfg.HotCacheTTLSeconds)*time.Second)
+		expvar.Publish("hot_cache", expvar.Func(func() any { return hot.Stats() }))
+		st = hot.Wrap(st)
+	}
+
+	// Build router; it registers the job kinds with the runner
+	jobs := services.NewJobRunner(st, time.Duration(cfg.JobStaleMinutes)*time.Minute, log)
+	router, err := buildRouter(st, idx, embedProvider, slo, cfg, logs, jobs)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to build router")
+		return err
+	}
 
 	// Start health checkers and bind service health
-	svcHealth := startHealthCheckers(ctx, cfg, log, st, idx, embedProvider)
+	svcHealth := startHealthCheckers(ctx, cfg, logs, dbStore, idx, embedProvider)
 
 	// Block startup until dependencies report healthy; fail fast otherwise
 	if err := waitUntilHealthy(ctx, cfg, svcHealth); err != nil {
@@ -64,8 +99,32 @@ func Run() error {
 		return err
 	}
 
+	if cfg.ContextCompactionEnabled {
+		startContextCompaction(ctx, cfg, log, st)
+	}
+	startEntryRetention(ctx, cfg, log, st)
+	if cfg.TrashRetentionDays > 0 {
+		startTrashPurge(ctx, cfg, log, st)
+	}
+	startReembedding(ctx, cfg, log, st)
+	go jobs.Start(ctx, time.Duration(cfg.JobPollIntervalSeconds)*time.Second)
+	if slo != nil {
+		go slo.Start(ctx, time.Minute)
+	}
+	if cfg.OutboxInProcess {
+		if err := startOutboxWorker(ctx, cfg, logs.Module(logger.ModuleOutbox), idx, embedProvider); err != nil {
+			log.Error().Err(err).Msg("in-process outbox worker unavailable")
+			return err
+		}
+	}
+
 	// HTTP server and serve
-	server := newHTTPServer(ctx, cfg, router)
+	server := newHTTPServer(ctx, cfg, api.CORS(api.CORSConfig{
+		AllowedOrigins:   cfg.CORSAllowedOrigins,
+		AllowedHeaders:   cfg.CORSAllowedHeaders,
+		AllowCredentials: cfg.CORSAllowCredentials,
+		MaxAgeSeconds:    cfg.CORSMaxAgeSeconds,
+	})(router))
 	errCh := serveHTTP(server, log, cfg)
 
 	// Graceful shutdown on context cancel or server error
@@ -87,14 +146,15 @@ func Run() error {
 }
 
 // initDependencies constructs required components and enforces fail-fast on missing deps.
-func initDependencies(ctx context.Context, cfg *config.Config, log zerolog.Logger) (store.Store, searchindex.Index, emb.EmbeddingProvider, error) {
-	st, err := factory.NewStore(ctx, cfg, log)
+func initDependencies(ctx context.Context, cfg *config.Config, logs *logger.Set) (store.Store, searchindex.Index, emb.EmbeddingProvider, error) {
+	log := logs.Logger()
+	st, err := factory.NewStore(ctx, cfg, logs.Module(logger.ModuleStore))
 	if err != nil {
 		log.Error().Stack().Err(err).Msg("Store adapter unavailable")
 		return nil, nil, nil, err
 	}
 
-	idx, err := factory.NewSearchIndex(ctx, cfg, log)
+	idx, err := factory.NewSearchIndex(ctx, cfg, logs.Module(logger.ModuleSearch))
 	if err != nil {
 		log.Error().Stack().Err(err).Msg("Search index adapter unavailable")
 		return nil, nil, nil, err
@@ -108,42 +168,177 @@ func initDependencies(ctx context.Context, cfg *config.Config, log zerolog.Logge
 }
 
 // buildRouter wires HTTP routes to handlers.
-func buildRouter(st store.Store, idx searchindex.Index, embProvider emb.EmbeddingProvider, cfg *config.Config, log zerolog.Logger) *mux.Router {
+func buildRouter(st store.Store, idx searchindex.Index, embProvider emb.EmbeddingProvider, slo *metrics.SLOTracker, cfg *config.Config, logs *logger.Set, jobs *services.JobRunner) (*mux.Router, error) {
+	log := logs.Logger()
 	root := mux.NewRouter()
+	root.Use(api.RequestID)
+	if slo != nil {
+		// Outside Recover so recovered panics count as 500s.
+		root.Use(slo.Middleware)
+	}
 	root.Use(api.Recover)
+	root.Use(api.RequestDeadline(time.Duration(cfg.MaxRequestTimeoutSeconds) * time.Second))
 
 	// Create Authorizer
 	authorizerFactory := auth.NewAuthorizerFactory(cfg)
 	authorizer := authorizerFactory.CreateAuthorizer()
+	if cfg.AuthCacheTTLSeconds > 0 && cfg.AuthCacheSize > 0 {
+		cached := auth.NewCachingAuthorizer(authorizer, time.Duration(cfg.AuthCacheTTLSeconds)*time.Second, cfg.AuthCacheSize)
+		expvar.Publish("auth_cache", expvar.Func(func() any { return cached.Stats() }))
+		authorizer = cached
+	}
+
+	// Capabilities: features are enabled below as their routes are wired.
+	caps := api.NewCapabilitiesHandler()
+	root.HandleFunc("/v0/capabilities", caps.GetCapabilities).Methods("GET")
 
 	// Vaults
 	vaultSvc := services.NewVaultService(st, idx)
 	vault := api.NewVaultHandler(vaultSvc, authorizer)
+	templates, err := services.LoadVaultTemplates(cfg.VaultTemplatesFile)
+	if err != nil {
+		return nil, err
+	}
+	if err := vault.EnableVaultTemplates(templates); err != nil {
+		return nil, err
+	}
 	root.HandleFunc("/v0/vaults", vault.CreateVault).Methods("POST")
+	root.HandleFunc("/v0/vaults:fromTemplate", vault.CreateVaultFromTemplate).Methods("POST")
+	root.HandleFunc("/v0/vault-templates", vault.ListVaultTemplates).Methods("GET")
 	root.HandleFunc("/v0/vaults", vault.ListVaults).Methods("GET")
 	root.HandleFunc("/v0/vaults/{vaultId}", vault.GetVault).Methods("GET")
 	root.HandleFunc("/v0/vaults/{vaultId}", vault.DeleteVault).Methods("DELETE")
+	root.HandleFunc("/v0/vaults/{vaultId}", vault.UpdateVault).Methods("PATCH")
+	root.HandleFunc("/v0/vaults/{vaultId}/read-only", vault.SetVaultReadOnly).Methods("PUT")
+	root.HandleFunc("/v0/vaults/{vaultId}/stats", vault.GetVaultStats).Methods("GET")
+	root.HandleFunc("/v0/vaults/{vaultId}:clone", vault.CloneVault).Methods("POST")
 	root.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/attach", vault.AttachMemoryToVault).Methods("POST")
 
+	// Actor settings (default time zone)
+	actorSvc := services.NewActorService(st)
+	actor := api.NewActorHandler(actorSvc, authorizer)
+	root.HandleFunc("/v0/actor/settings", actor.GetSettings).Methods("GET")
+	root.HandleFunc("/v0/actor/settings", actor.PutSettings).Methods("PUT")
+
 	// Memories
 	memorySvc := services.NewMemoryService(st, idx, embProvider)
+	memorySvc.EnableEntryDedup(time.Duration(cfg.EntryDedupWindowMillis) * time.Millisecond)
+	if cfg.TrashRetentionDays > 0 {
+		memorySvc.EnableTrash()
+	}
+	memorySvc.EnableJobs()
+	memorySvc.RegisterJobs(jobs)
 	memory := api.NewMemoryHandler(memorySvc, vaultSvc, authorizer, cfg)
+	memory.EnableActorTimeZones(actorSvc)
+	llm, err := factory.NewLLMPipeline(cfg, log)
+	if err != nil {
+		return nil, err
+	}
+	var sum summarizer.Summarizer = summarizer.Extractive{}
+	if llm != nil {
+		sum = llm
+		expvar.Publish("summarizer", expvar.Func(func() any { return llm.Stats() }))
+	}
+	memory.EnableConversations(services.NewConversationService(st, sum))
+	memory.EnableWebhooks(services.NewWebhookService(st, memorySvc, sum))
 	root.HandleFunc("/v0/vaults/{vaultId}/memories", memory.CreateMemory).Methods("POST")
 	root.HandleFunc("/v0/vaults/{vaultId}/memories", memory.ListMemories).Methods("GET")
 	root.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}", memory.GetMemory).Methods("GET")
 	root.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}", memory.DeleteMemory).Methods("DELETE")
+	root.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}", memory.UpdateMemory).Methods("PATCH")
+	root.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}:restore", memory.RestoreMemory).Methods("POST")
+	root.HandleFunc("/v0/vaults/{vaultId}/trash", memory.ListTrash).Methods("GET")
+	root.HandleFunc("/v0/jobs/{jobId}", memory.GetJob).Methods("GET")
+	root.HandleFunc("/v0/jobs/{jobId}:cancel", memory.CancelJob).Methods("POST")
+	root.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/append-only", memory.SetMemoryAppendOnly).Methods("PUT")
+	root.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/entry-roles", memory.SetMemoryEntryRoles).Methods("PUT")
+	root.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/search-boost", memory.SetMemorySearchBoost).Methods("PUT")
 	root.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/entries", memory.ListMemoryEntries).Methods("GET")
 	root.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/entries", memory.CreateMemoryEntry).Methods("POST")
+	root.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/entries:batch", memory.CreateMemoryEntriesBatch).Methods("POST")
+	root.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/conversations", memory.IngestConversation).Methods("POST")
+	root.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/summarize", memory.SummarizeMemory).Methods("POST")
+	root.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/entries:scan", memory.ScanMemoryEntries).Methods("GET")
 	root.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/entries/{entryId}", memory.GetMemoryEntryByID).Methods("GET")
+	root.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/index-status", memory.GetIndexStatus).Methods("GET")
+	root.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/index:retry", memory.RetryIndexing).Methods("POST")
 	root.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/entries/{entryId}", memory.DeleteMemoryEntryByID).Methods("DELETE")
+	root.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/entries/{entryId}:restore", memory.RestoreMemoryEntry).Methods("POST")
 	root.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/entries/{entryId}/tags", memory.UpdateMemoryEntryTags).Methods("PATCH")
+	root.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/entries:tags", memory.PatchMemoryEntryTags).Methods("PATCH")
+	root.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/entries:delete", memory.DeleteMemoryEntries).Methods("POST")
+	root.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/entries/{entryId}/signals", memory.RecordEntrySignal).Methods("POST")
+	root.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/entries/{entryId}/corrections", memory.CorrectMemoryEntry).Methods("POST")
+	root.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/entries/{entryId}/similar", memory.GetSimilarEntries).Methods("GET")
+	root.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/export", memory.ExportMemoryEntries).Methods("GET")
+	root.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/sessions", memory.ListSessions).Methods("GET")
+	root.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/sessions/{sessionId}/entries", memory.ListSessionEntries).Methods("GET")
 	root.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/contexts", memory.PutMemoryContext).Methods("PUT")
 	root.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/contexts", memory.GetLatestMemoryContext).Methods("GET")
 	root.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/contexts/{contextId}", memory.DeleteMemoryContextByID).Methods("DELETE")
+	root.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/contexts/sections/{name}", memory.PutMemoryContextSection).Methods("PUT")
+	root.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/contexts/documents", memory.CreateContextDocument).Methods("POST")
+	root.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/contexts/documents/{documentId}", memory.GetContextDocument).Methods("GET")
+	root.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/contexts/documents/{documentId}/parts/{part}", memory.PutContextDocumentPart).Methods("PUT")
+	root.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/contexts/documents/{documentId}/complete", memory.CompleteContextDocument).Methods("POST")
+	root.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/aliases", memory.ListEntityAliases).Methods("GET")
+	root.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/aliases", memory.PutEntityAlias).Methods("PUT")
+	root.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/aliases", memory.DeleteEntityAlias).Methods("DELETE")
+	root.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/webhooks", memory.CreateWebhook).Methods("POST")
+	root.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/webhooks", memory.ListWebhooks).Methods("GET")
+	root.HandleFunc("/v0/vaults/{vaultId}/memories/{memoryId}/webhooks/{webhookId}", memory.DeleteWebhook).Methods("DELETE")
+	root.HandleFunc("/v0/hooks/{webhookId}", memory.ReceiveWebhook).Methods("POST")
+	root.HandleFunc("/v0/usage", memory.GetUsage).Methods("GET")
+	root.HandleFunc("/v0/bootstrap", memory.Bootstrap).Methods("POST")
+	caps.Enable(api.FeatureAppendOnlyMemories, api.FeatureConversations, api.FeatureEntriesScan, api.FeatureEntriesBatch, api.FeatureContextDocuments, api.FeatureEntityAliases, api.FeatureContextSections, api.FeatureEntryUsage, api.FeatureTitleUpdates, api.FeatureConversationTime, api.FeatureEntryRoles, api.FeatureIndexStatus, api.FeatureBulkTagUpdates, api.FeatureContextCheck, api.FeatureVaultClone, api.FeatureRecentSummaries, api.FeatureBootstrap, api.FeatureWebhooks, api.FeatureEntriesPagination, api.FeatureSearchBoost, api.FeatureJobs, api.FeatureEntryExpiry, api.FeatureIdempotentEntries, api.FeatureEntryCorrections, api.FeatureTagFilters)
+	if idx != nil && embProvider != nil {
+		caps.Enable(api.FeatureSimilarEntries)
+	}
+	if memorySvc.TrashEnabled() {
+		caps.Enable(api.FeatureTrash)
+	}
+	if llm != nil {
+		memory.EnableSummarize(services.NewSummarizeService(st, llm, cfg.MaxContextChars))
+		caps.Enable(api.FeatureSummarize)
+	}
 
-	// Title-based
-	root.HandleFunc("/v0/vaults/{vaultTitle}/memories", memory.ListMemoriesByVaultTitle).Methods("GET")
-	root.HandleFunc("/v0/vaults/{vaultTitle}/memories/{memoryTitle}", memory.GetMemoryByTitle).Methods("GET")
+	// Default memory: the same entry and context routes without IDs
+	root.HandleFunc("/v0/actor/defaults", actor.GetDefaults).Methods("GET")
+	root.HandleFunc("/v0/actor/defaults", actor.PutDefaults).Methods("PUT")
+	root.HandleFunc("/v0/entries", actor.WithDefaultMemory(memory.ListMemoryEntries)).Methods("GET")
+	root.HandleFunc("/v0/entries", actor.WithDefaultMemory(memory.CreateMemoryEntry)).Methods("POST")
+	root.HandleFunc("/v0/contexts", actor.WithDefaultMemory(memory.PutMemoryContext)).Methods("PUT")
+	root.HandleFunc("/v0/contexts", actor.WithDefaultMemory(memory.GetLatestMemoryContext)).Methods("GET")
+	root.HandleFunc("/v0/contexts/sections/{name}", actor.WithDefaultMemory(memory.PutMemoryContextSection)).Methods("PUT")
+	caps.Enable(api.FeatureActorDefaults)
+
+	// Admin: rebuild one memory's search index through the outbox
+	admin := api.NewAdminHandler(memorySvc, authorizer)
+	root.HandleFunc("/v0/admin/memories/{memoryId}/reindex", admin.ReindexMemory).Methods("POST")
+	root.HandleFunc("/v0/admin/memories/{memoryId}/reindex", admin.GetReindexProgress).Methods("GET")
+	root.HandleFunc("/v0/admin/memories/{memoryId}/reindex:job", admin.ReindexMemoryJob).Methods("POST")
+	root.HandleFunc("/v0/admin/memories/{memoryId}/reembed", admin.GetReembedProgress).Methods("GET")
+	root.HandleFunc("/v0/admin/reembed", admin.GetReembedOverview).Methods("GET")
+	if slo != nil {
+		admin.EnableSLOReport(slo)
+	}
+	root.HandleFunc("/v0/admin/slo", admin.GetSLO).Methods("GET")
+	root.HandleFunc("/v0/admin/health", admin.GetHealthHistory).Methods("GET")
+	admin.EnableLogLevels(logs.Levels())
+	root.HandleFunc("/v0/admin/log-levels", admin.GetLogLevels).Methods("GET")
+	root.HandleFunc("/v0/admin/log-levels", admin.PutLogLevels).Methods("PUT")
+
+	// Ingestion batches (entry provenance)
+	batches := api.NewIngestionBatchHandler(services.NewIngestionBatchService(st, idx), authorizer)
+	root.HandleFunc("/v0/ingestion-batches", batches.CreateBatch).Methods("POST")
+	root.HandleFunc("/v0/ingestion-batches", batches.ListBatches).Methods("GET")
+	root.HandleFunc("/v0/ingestion-batches/{batchId}", batches.GetBatch).Methods("GET")
+	root.HandleFunc("/v0/ingestion-batches/{batchId}/entries", batches.ListBatchEntries).Methods("GET")
+	root.HandleFunc("/v0/ingestion-batches/{batchId}/rollback", batches.RollbackBatch).Methods("POST")
+	caps.Enable(api.FeatureIngestionBatches)
+
+	// Process counters (expvar), e.g. http_panics_recovered
+	root.Handle("/debug/vars", expvar.Handler()).Methods("GET")
 
 	// Health
 	healthHandler := api.NewHealthHandler()
@@ -155,22 +350,73 @@ func buildRouter(st store.Store, idx searchindex.Index, embProvider emb.Embeddin
 		log.Error().Stack().Err(err).Msg("Failed to create search handler")
 		// Handle gracefully - skip search endpoint registration
 	} else {
+		if cfg.SearchQueryLogEnabled {
+			search.EnableQueryLog(services.NewSearchLogService(st))
+		}
+		search.EnableContextPrefetch(memorySvc)
+		search.EnableIndexFreshness(memorySvc)
+		search.EnableActorTimeZones(actorSvc)
+		search.EnableAccessTracking(memorySvc)
+		search.EnableExplain(memorySvc)
+		search.EnableAliasExpansion(memorySvc)
+		search.EnableSessionWindows(memorySvc)
+		search.EnableDefaultMemory(actorSvc)
+		search.EnableTitleScopes(memorySvc)
+		if memorySvc.TrashEnabled() {
+			search.EnableTrashFilter(memorySvc)
+		}
+		search.EnableExpiryFilter(memorySvc)
+		search.EnableCorrectionFilter(memorySvc)
+		search.EnableSearchLimits(api.SearchLimits{
+			MaxTopK:            cfg.SearchMaxTopK,
+			MaxConcurrent:      cfg.SearchMaxConcurrent,
+			ActorMaxTopK:       cfg.SearchActorMaxTopK,
+			ActorMaxConcurrent: cfg.SearchActorMaxConcurrent,
+		})
+		if cfg.SearchSignalWeight > 0 {
+			search.EnableSignalRanking(memorySvc, cfg.SearchSignalWeight)
+		}
+		search.EnableRecencyRanking(time.Duration(cfg.SearchRecencyHalfLifeHours * float64(time.Hour)))
+		if rr := factory.NewReranker(cfg, log); rr != nil {
+			search.EnableReranker(rr, time.Duration(cfg.RerankerTimeoutMillis)*time.Millisecond)
+			caps.Enable(api.FeatureReranker)
+		}
+		profiles, err := services.LoadRankingProfiles(cfg.SearchProfilesFile)
+		if err != nil {
+			return nil, err
+		}
+		if len(profiles) > 0 {
+			search.EnableRankingProfiles(profiles, memorySvc)
+			caps.Enable(api.FeatureRankingProfiles)
+		}
+		if cfg.SearchShadowPercent > 0 {
+			if err := search.EnableShadowSearch(api.ShadowSearch{Profile: cfg.SearchShadowProfile, Percent: cfg.SearchShadowPercent}); err != nil {
+				return nil, err
+			}
+		}
 		root.HandleFunc("/v0/search", search.HandleSearch).Methods("POST")
+		root.HandleFunc("/v0/search:batch", search.HandleBatchSearch).Methods("POST")
+		root.HandleFunc("/v0/search/feedback", search.HandleFeedback).Methods("POST")
+		root.HandleFunc("/v0/search/metrics", search.HandleMetrics).Methods("GET")
+		root.HandleFunc("/v0/search/log:export", search.HandleExport).Methods("GET")
+		root.HandleFunc("/v0/search/explain", search.HandleExplain).Methods("GET")
+		caps.Enable(api.FeatureSearch, api.FeatureSearchExplain, api.FeatureSearchTimeWindows, api.FeatureSearchBatch, api.FeatureSearchTitleScopes, api.FeatureSearchGrouping)
 	}
-	return root
+	return root, nil
 }
 
 // startHealthCheckers starts component checkers and service-level aggregator; binds health.
-func startHealthCheckers(ctx context.Context, cfg *config.Config, log zerolog.Logger, st store.Store, idx searchindex.Index, embProvider emb.EmbeddingProvider) *health.ServiceHealthChecker {
+func startHealthCheckers(ctx context.Context, cfg *config.Config, logs *logger.Set, st store.Store, idx searchindex.Index, embProvider emb.EmbeddingProvider) *health.ServiceHealthChecker {
+	log := logs.Logger()
 	var checkers []health.HealthChecker
 	probeTimeout := time.Duration(cfg.HealthProbeTimeoutSeconds) * time.Second
 	interval := time.Duration(cfg.HealthIntervalSeconds) * time.Second
 
-	storeChecker := store.NewStoreHealthChecker(st, log, probeTimeout)
+	storeChecker := store.NewStoreHealthChecker(st, logs.Module(logger.ModuleStore), probeTimeout)
 	go storeChecker.Start(ctx, interval)
 	checkers = append(checkers, storeChecker)
 
-	idxChecker := searchindex.NewSearchIndexHealthChecker(idx, log, probeTimeout)
+	idxChecker := searchindex.NewSearchIndexHealthChecker(idx, logs.Module(logger.ModuleSearch), probeTimeout)
 	go idxChecker.Start(ctx, interval)
 	checkers = append(checkers, idxChecker)
 
@@ -178,22 +424,137 @@ func startHealthCheckers(ctx context.Context, cfg *config.Config, log zerolog.Lo
 	go embChecker.Start(ctx, interval)
 	checkers = append(checkers, embChecker)
 
+	// Optional warm-up gates readiness until the first search path is primed
+	if cfg.WarmupEnabled {
+		warmChecker := searchindex.NewWarmupChecker(idx, embProvider, cfg.SearchAlpha, logs.Module(logger.ModuleSearch), probeTimeout)
+		go warmChecker.Start(ctx, interval)
+		checkers = append(checkers, warmChecker)
+	}
+
 	svcHealth := health.NewServiceHealthChecker(log, checkers...)
+	svcHealth.EnableFlapDetection(time.Duration(cfg.HealthFlapWindowSeconds)*time.Second, cfg.HealthFlapThreshold)
 	go svcHealth.Start(ctx, interval)
 	api.BindServiceHealth(svcHealth.IsHealthy)
+	api.BindComponentHealth(svcHealth.Components)
+	api.BindHealthHistory(svcHealth.History)
+	api.BindSchemaVersion(store.SchemaVersion)
 	return svcHealth
 }
 
+// newSLOTracker builds the per-endpoint SLO tracker from SLO_OBJECTIVES; it
+// returns nil when no objectives are configured.
+func newSLOTracker(cfg *config.Config, log zerolog.Logger) (*metrics.SLOTracker, error) {
+	objectives, err := metrics.ParseObjectives(cfg.SLOObjectives)
+	if err != nil {
+		return nil, fmt.Errorf("SLO_OBJECTIVES: %w", err)
+	}
+	if len(objectives) == 0 {
+		return nil, nil
+	}
+	return metrics.NewSLOTracker(objectives, cfg.SLOBurnRateAlert, log.With().Str("component", "slo").Logger()), nil
+}
+
+// startContextCompaction thins old context snapshots in the background.
+func startContextCompaction(ctx context.Context, cfg *config.Config, log zerolog.Logger, st store.Store) {
+	policy := services.ContextRetention{KeepAllDays: cfg.ContextKeepAllDays, KeepDailyDays: cfg.ContextKeepDailyDays}
+	interval := time.Duration(cfg.ContextCompactionIntervalMinutes) * time.Minute
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	log.Info().Int("keep_all_days", policy.KeepAllDays).Int("keep_daily_days", policy.KeepDailyDays).Dur("interval", interval).Msg("context compaction enabled")
+	go services.NewContextCompactor(st, policy, log).Start(ctx, interval)
+}
+
+// startEntryRetention deletes entries past their expiration time and, with
+// ENTRY_RETENTION_DAYS, old or least-recently-accessed entries in the background.
+func startEntryRetention(ctx context.Context, cfg *config.Config, log zerolog.Logger, st store.Store) {
+	policy := services.EntryRetention{Days: cfg.EntryRetentionDays, ByAccess: cfg.EntryRetentionPolicy == "lru"}
+	interval := time.Duration(cfg.EntryRetentionIntervalMinutes) * time.Minute
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	log.Info().Int("days", policy.Days).Str("policy", cfg.EntryRetentionPolicy).Dur("interval", interval).Msg("entry retention started")
+	go services.NewEntryReaper(st, policy, log).Start(ctx, interval)
+}
+
+// startTrashPurge deletes memories and entries that have been in the trash
+// longer than TRASH_RETENTION_DAYS.
+func startTrashPurge(ctx context.Context, cfg *config.Config, log zerolog.Logger, st store.Store) {
+	retention := time.Duration(cfg.TrashRetentionDays) * 24 * time.Hour
+	interval := time.Duration(cfg.TrashPurgeIntervalMinutes) * time.Minute
+	log.Info().Int("days", cfg.TrashRetentionDays).Dur("interval", interval).Msg("trash enabled")
+	go services.NewTrashPurger(st, retention, log).Start(ctx, interval)
+}
+
+// startReembedding records the embedding fingerprint, marking every memory
+// when it changed, and re-embeds marked memories in the background when
+// enabled. Marking happens either way so enabling it later catches up.
+func startReembedding(ctx context.Context, cfg *config.Config, log zerolog.Logger, st store.Store) {
+	fp := services.EmbeddingFingerprint(cfg.EmbedProvider, cfg.EmbedModel, cfg.ReembedVersion)
+	r := services.NewReembedder(st, fp, cfg.ReembedEntriesPerMinute, log)
+	if _, err := r.MarkDrift(ctx); err != nil {
+		log.Warn().Err(err).Str("fingerprint", fp).Msg("could not record embedding fingerprint")
+	}
+	if !cfg.ReembedEnabled {
+		return
+	}
+	log.Info().Str("fingerprint", fp).Int("entries_per_minute", cfg.ReembedEntriesPerMinute).Msg("gradual re-embedding enabled")
+	go r.Start(ctx, 10*time.Second)
+}
+
+// startOutboxWorker runs the outbox worker in this process on its own small
+// connection pool. Unless election is off, only the replica holding the
+// leader lock drains the outbox.
+func startOutboxWorker(ctx context.Context, cfg *config.Config, log zerolog.Logger, idx searchindex.Index, embProvider emb.EmbeddingProvider) error {
+	db, err := postgres.Open(cfg.PostgresDSN)
+	if err != nil {
+		return err
+	}
+	db.SetMaxOpenConns(4) // leader lock session + notification listener + one lease transaction, with headroom
+	w := outbox.NewWorker(db, embProvider, idx, outbox.Config{
+		BatchSize:   cfg.OutboxBatchSize,
+		Interval:    time.Duration(cfg.OutboxIntervalMillis) * time.Millisecond,
+		MaxAttempts: cfg.OutboxMaxAttempts,
+		Lease:       time.Duration(cfg.OutboxLeaseSeconds) * time.Second,
+		Listen:      cfg.OutboxListen,
+	}, log)
+	if !cfg.OutboxElectLeader {
+		log.Info().Msg("in-process outbox worker enabled without leader election")
+		go func() {
+			defer func() { _ = db.Close() }()
+			_ = w.Run(ctx)
+		}()
+		return nil
+	}
+	retry := time.Duration(cfg.OutboxLeaderRetrySeconds) * time.Second
+	log.Info().Dur("leader_retry", retry).Msg("in-process outbox worker enabled")
+	go func() {
+		defer func() { _ = db.Close() }()
+		_ = w.RunElected(ctx, retry)
+	}()
+	return nil
+}
+
 func newHTTPServer(ctx context.Context, cfg *config.Config, handler http.Handler) *http.Server {
-	return &http.Server{
+	// HTTP/1.1 always; HTTP/2 over TLS when a TLS terminator hands the
+	// connection through, and with prior knowledge on plain TCP when h2c is enabled.
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP2(true)
+	protocols.SetUnencryptedHTTP2(cfg.HTTPH2C)
+	server := &http.Server{
 		Addr:              fmt.Sprintf(":%d", cfg.HTTPPort),
 		Handler:           handler,
 		ReadTimeout:       15 * time.Second,
 		ReadHeaderTimeout: 10 * time.Second,
 		WriteTimeout:      15 * time.Second,
-		IdleTimeout:       60 * time.Second,
+		IdleTimeout:       time.Duration(cfg.HTTPIdleTimeoutSeconds) * time.Second,
 		BaseContext:       func(net.Listener) context.Context { return ctx },
+		Protocols:         protocols,
+		HTTP2:             &http.HTTP2Config{MaxConcurrentStreams: cfg.HTTPMaxConcurrentStreams},
 	}
+	server.SetKeepAlivesEnabled(cfg.HTTPKeepAlives)
+	return server
 }
 
 func serveHTTP(server *http.Server, log zerolog.Logger, cfg *config.Config) <-chan error {
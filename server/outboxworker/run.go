@@ -15,6 +15,7 @@ import (
 
 	"github.com/mycelian/mycelian-memory/server/internal/config"
 	"github.com/mycelian/mycelian-memory/server/internal/embeddings/ollama"
+	"github.com/mycelian/mycelian-memory/server/internal/logger"
 	"github.com/mycelian/mycelian-memory/server/internal/outbox"
 	"github.com/mycelian/mycelian-memory/server/internal/searchindex"
 )
@@ -25,6 +26,12 @@ func Run() error {
 	if err != nil {
 		log.Fatal().Err(err).Msg("config")
 	}
+	logs, err := logger.Open("outbox-worker", cfg.LoggerConfig())
+	if err != nil {
+		log.Fatal().Err(err).Msg("logger")
+	}
+	defer func() { _ = logs.Close() }()
+	log.Logger = logs.Module(logger.ModuleOutbox)
 
 	db, err := sql.Open("pgx", cfg.PostgresDSN)
 	if err != nil {
@@ -38,7 +45,7 @@ func Run() error {
 		Embed(context.Context, string) ([]float32, error)
 	}
 	if cfg.EmbedProvider == "ollama" || cfg.EmbedProvider == "" {
-		emb = ollama.New(cfg.EmbedModel)
+		emb = ollama.NewWithKeepAlive(cfg.EmbedModel, cfg.EmbedKeepAlive)
 	}
 	// Critical dependency check - fail fast if embedder is missing
 	if emb == nil {
@@ -50,8 +57,8 @@ func Run() error {
 	}
 
 	// Ensure schema exists in dev/e2e; safe to call repeatedly.
-	_ = searchindex.BootstrapWeaviate(context.Background(), cfg.SearchIndexURL)
-	idx, err := searchindex.NewWeaviateNativeIndex(cfg.SearchIndexURL)
+	_ = searchindex.BootstrapWeaviate(context.Background(), cfg.SearchIndexURL, cfg.WeaviateConfig())
+	idx, err := searchindex.NewWeaviateNativeIndex(cfg.SearchIndexURL, cfg.WeaviateConfig())
 	if err != nil {
 		log.Fatal().Err(err).Msg("search index")
 	}
@@ -60,6 +67,9 @@ func Run() error {
 		PostgresDSN: cfg.PostgresDSN,
 		BatchSize:   100,
 		Interval:    2 * time.Second,
+		MaxAttempts: cfg.OutboxMaxAttempts,
+		Lease:       time.Duration(cfg.OutboxLeaseSeconds) * time.Second,
+		Listen:      cfg.OutboxListen,
 	}, log.Logger)
 
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
@@ -0,0 +1,96 @@
+// Package schemamanager applies and validates the canonical PostgreSQL schema
+// (including the outbox table) against a target database.
+package schemamanager
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mycelian/mycelian-memory/server/internal/storage/postgres"
+)
+
+const usage = `usage: schema-manager <command> [--dsn DSN]
+
+commands:
+  apply      apply the canonical schema (idempotent)
+  validate   report tables, columns and indexes missing from the target
+  print      print the canonical schema DDL
+
+The DSN defaults to MEMORY_SERVER_POSTGRES_DSN.
+`
+
+// Run executes a schema-manager command. validate returns an error when the
+// target database drifts from the canonical schema.
+func Run(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		_, _ = fmt.Fprint(out, usage)
+		return fmt.Errorf("missing command")
+	}
+	cmd := args[0]
+
+	fs := flag.NewFlagSet(cmd, flag.ContinueOnError)
+	fs.SetOutput(out)
+	dsn := fs.String("dsn", os.Getenv("MEMORY_SERVER_POSTGRES_DSN"), "Postgres DSN")
+	timeout := fs.Duration("timeout", 30*time.Second, "overall operation timeout")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	switch cmd {
+	case "print":
+		_, _ = fmt.Fprint(out, postgres.Schema)
+		return nil
+	case "apply", "validate":
+	default:
+		_, _ = fmt.Fprint(out, usage)
+		return fmt.Errorf("unknown command %q", cmd)
+	}
+
+	db, err := postgres.Open(*dsn)
+	if err != nil {
+		return fmt.Errorf("connect: %w", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	if cmd == "apply" {
+		if err := postgres.ApplySchema(ctx, db); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintln(out, "schema applied")
+	}
+
+	diff, err := postgres.ValidateSchema(ctx, db)
+	if err != nil {
+		return err
+	}
+	WriteDiff(out, diff)
+	if !diff.Empty() {
+		return fmt.Errorf("schema drift detected")
+	}
+	return nil
+}
+
+// WriteDiff prints a human-readable drift report.
+func WriteDiff(out io.Writer, d postgres.SchemaDiff) {
+	if d.Empty() {
+		_, _ = fmt.Fprintln(out, "schema OK: no missing tables, columns or indexes")
+		return
+	}
+	section := func(title string, items []string) {
+		if len(items) == 0 {
+			return
+		}
+		_, _ = fmt.Fprintf(out, "missing %s (%d):\n  %s\n", title, len(items), strings.Join(items, "\n  "))
+	}
+	section("tables", d.MissingTables)
+	section("columns", d.MissingColumns)
+	section("indexes", d.MissingIndexes)
+}
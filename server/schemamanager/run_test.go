@@ -0,0 +1,48 @@
+package schemamanager
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mycelian/mycelian-memory/server/internal/storage/postgres"
+)
+
+func TestRun_PrintAndUsage(t *testing.T) {
+	var b strings.Builder
+	if err := Run([]string{"print"}, &b); err != nil {
+		t.Fatalf("print: %v", err)
+	}
+	if !strings.Contains(b.String(), "CREATE TABLE IF NOT EXISTS outbox") {
+		t.Fatalf("print did not emit the outbox DDL")
+	}
+
+	b.Reset()
+	if err := Run(nil, &b); err == nil || !strings.Contains(b.String(), "usage:") {
+		t.Fatalf("expected usage error, got %v", err)
+	}
+	if err := Run([]string{"migrate-spanner"}, &b); err == nil {
+		t.Fatal("expected unknown command error")
+	}
+}
+
+func TestRun_RequiresDSN(t *testing.T) {
+	var b strings.Builder
+	if err := Run([]string{"validate", "--dsn", ""}, &b); err == nil {
+		t.Fatal("expected error for empty DSN")
+	}
+}
+
+func TestWriteDiff(t *testing.T) {
+	var b strings.Builder
+	WriteDiff(&b, postgres.SchemaDiff{})
+	if !strings.Contains(b.String(), "schema OK") {
+		t.Fatalf("unexpected output: %s", b.String())
+	}
+
+	b.Reset()
+	WriteDiff(&b, postgres.SchemaDiff{MissingColumns: []string{"outbox.leased_until"}, MissingIndexes: []string{"outbox_ready_idx"}})
+	out := b.String()
+	if !strings.Contains(out, "missing columns (1)") || !strings.Contains(out, "outbox.leased_until") || !strings.Contains(out, "outbox_ready_idx") {
+		t.Fatalf("unexpected output: %s", out)
+	}
+}
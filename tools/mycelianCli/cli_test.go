@@ -1,14 +1,24 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"sort"
 	"strings"
 	"testing"
+
+	"github.com/mycelian/mycelian-memory/client"
 )
 
+// The fake services below accept the dev key, which commands only send when
+// dev mode is requested.
+func init() { _ = os.Setenv(client.EnvDevMode, "1") }
+
 func TestCLI_CreateVaultMemoryEntry_ListEntries(t *testing.T) {
 	// Test updated to work with dev mode auth (no --user-id flags needed)
 	// Stub backend for dev mode auth
@@ -98,3 +108,292 @@ func TestCLI_CreateVaultMemoryEntry_ListEntries(t *testing.T) {
 		t.Fatalf("list-entries cmd failed: %v", err)
 	}
 }
+
+func TestCLI_SetVaultReadOnly(t *testing.T) {
+	var got map[string]bool
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v0/vaults/vault-1/read-only", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"vaultId": "vault-1", "readOnly": got["readOnly"]})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	root := NewRootCmd()
+	root.SetArgs([]string{"set-vault-readonly", "--service-url", srv.URL, "--vault-id", "vault-1"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("set-vault-readonly failed: %v", err)
+	}
+	if !got["readOnly"] {
+		t.Fatalf("expected readOnly=true by default, got %v", got)
+	}
+
+	root = NewRootCmd()
+	root.SetArgs([]string{"set-vault-readonly", "--service-url", srv.URL, "--vault-id", "vault-1", "--read-only=false"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("set-vault-readonly --read-only=false failed: %v", err)
+	}
+	if got["readOnly"] {
+		t.Fatalf("expected readOnly=false, got %v", got)
+	}
+}
+
+func TestCLI_UpdateMemory(t *testing.T) {
+	var got map[string]interface{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v0/vaults/vault-1/memories/mem-1", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		got = nil
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"memoryId": "mem-1", "vaultId": "vault-1", "title": "renamed"})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	root := NewRootCmd()
+	root.SetArgs([]string{"update-memory", "--service-url", srv.URL, "--vault-id", "vault-1", "--memory-id", "mem-1", "--title", "renamed"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("update-memory failed: %v", err)
+	}
+	if len(got) != 1 || got["title"] != "renamed" {
+		t.Fatalf("expected only the title to be sent, got %v", got)
+	}
+
+	root = NewRootCmd()
+	root.SetArgs([]string{"update-memory", "--service-url", srv.URL, "--vault-id", "vault-1", "--memory-id", "mem-1", "--description", ""})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("update-memory --description failed: %v", err)
+	}
+	if d, ok := got["description"]; len(got) != 1 || !ok || d != "" {
+		t.Fatalf("expected an empty description to be sent, got %v", got)
+	}
+}
+
+func TestCLI_DeleteEntryAsksForConfirmation(t *testing.T) {
+	deletes := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v0/vaults/vault-1/memories/mem-1/entries/e-1", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		deletes++
+		w.WriteHeader(http.StatusNoContent)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	run := func(stdin string, extra ...string) string {
+		var out bytes.Buffer
+		root := NewRootCmd()
+		root.SetIn(strings.NewReader(stdin))
+		root.SetOut(&out)
+		root.SetErr(io.Discard)
+		root.SetArgs(append([]string{"delete-entry", "--service-url", srv.URL, "--vault-id", "vault-1", "--memory-id", "mem-1", "--entry-id", "e-1"}, extra...))
+		if err := root.Execute(); err != nil {
+			t.Fatalf("delete-entry failed: %v", err)
+		}
+		return out.String()
+	}
+
+	if out := run("n\n"); deletes != 0 || !strings.Contains(out, "Aborted") {
+		t.Fatalf("declined prompt must not delete: deletes=%d out=%q", deletes, out)
+	}
+	if out := run(""); deletes != 0 || !strings.Contains(out, "Aborted") {
+		t.Fatalf("no answer must not delete: deletes=%d out=%q", deletes, out)
+	}
+	if out := run("y\n"); deletes != 1 || !strings.Contains(out, "Entry deleted") {
+		t.Fatalf("confirmed prompt must delete: deletes=%d out=%q", deletes, out)
+	}
+	if out := run("", "--yes"); deletes != 2 || !strings.Contains(out, "Entry deleted") {
+		t.Fatalf("--yes must delete without asking: deletes=%d out=%q", deletes, out)
+	}
+}
+
+func TestCLI_CreateVaultFromTemplate(t *testing.T) {
+	var got map[string]string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v0/vaults:fromTemplate", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"vaultId": "v1", "title": got["title"], "template": got["template"],
+			"memories": []map[string]string{{"memoryId": "m1", "title": "project-context"}, {"memoryId": "m2", "title": "decisions"}},
+		})
+	})
+	mux.HandleFunc("/v0/vault-templates", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"templates": []map[string]interface{}{{"name": "project", "memories": []map[string]string{{"title": "project-context"}, {"title": "decisions"}}}},
+			"count":     1,
+		})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	b := &strings.Builder{}
+	root := NewRootCmd()
+	root.SetOut(b)
+	root.SetArgs([]string{"create-vault", "--service-url", srv.URL, "--title", "acme", "--template", "project"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("create-vault --template failed: %v", err)
+	}
+	if got["template"] != "project" || !strings.Contains(b.String(), "from template project") || !strings.Contains(b.String(), "m2\tdecisions") {
+		t.Fatalf("unexpected request %v or output:\n%s", got, b.String())
+	}
+
+	b.Reset()
+	root = NewRootCmd()
+	root.SetOut(b)
+	root.SetArgs([]string{"list-vault-templates", "--service-url", srv.URL})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("list-vault-templates failed: %v", err)
+	}
+	if !strings.Contains(b.String(), "project\tproject-context,decisions") {
+		t.Fatalf("unexpected output:\n%s", b.String())
+	}
+}
+
+func TestCLI_ListEntriesAllPages(t *testing.T) {
+	var tokens []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v0/vaults/vault-1/memories/mem-1/entries", func(w http.ResponseWriter, r *http.Request) {
+		tok := r.URL.Query().Get("pageToken")
+		tokens = append(tokens, tok)
+		resp := map[string]interface{}{"entries": []map[string]string{{"entryId": "e1"}, {"entryId": "e2"}}, "count": 2, "nextPageToken": "p2"}
+		if tok == "p2" {
+			resp = map[string]interface{}{"entries": []map[string]string{{"entryId": "e3"}}, "count": 1}
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	run := func(extra ...string) map[string]interface{} {
+		var out bytes.Buffer
+		root := NewRootCmd()
+		root.SetOut(&out)
+		root.SetArgs(append([]string{"list-entries", "--service-url", srv.URL, "--vault-id", "vault-1", "--memory-id", "mem-1", "--limit", "2"}, extra...))
+		if err := root.Execute(); err != nil {
+			t.Fatalf("list-entries %v failed: %v", extra, err)
+		}
+		var got map[string]interface{}
+		if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+			t.Fatalf("output is not JSON: %v\n%s", err, out.String())
+		}
+		return got
+	}
+
+	if got := run("--all"); got["count"] != float64(3) || got["nextPageToken"] != nil || strings.Join(tokens, ",") != ",p2" {
+		t.Fatalf("--all: tokens %q output %v", tokens, got)
+	}
+	tokens = nil
+	if got := run("--page-token", "p2"); got["count"] != float64(1) || strings.Join(tokens, ",") != "p2" {
+		t.Fatalf("--page-token: tokens %q output %v", tokens, got)
+	}
+}
+
+func TestCLI_ListEntriesTags(t *testing.T) {
+	var got []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v0/vaults/vault-1/memories/mem-1/entries", func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.Query()["tag"]
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"entries": []map[string]string{}, "count": 0})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	root := NewRootCmd()
+	root.SetOut(io.Discard)
+	root.SetArgs([]string{"list-entries", "--service-url", srv.URL, "--vault-id", "vault-1", "--memory-id", "mem-1",
+		"--tag", "project=alpha", "--tag", "note=a b&c"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("list-entries --tag failed: %v", err)
+	}
+	sort.Strings(got)
+	if strings.Join(got, "|") != "note=a b&c|project=alpha" {
+		t.Fatalf("tag params = %q", got)
+	}
+
+	root = NewRootCmd()
+	root.SetOut(io.Discard)
+	root.SetArgs([]string{"list-entries", "--service-url", srv.URL, "--vault-id", "vault-1", "--memory-id", "mem-1", "--tag", "project"})
+	if err := root.Execute(); err == nil {
+		t.Fatal("expected an error for a tag without a value")
+	}
+}
+
+func TestCLI_VerifyPipeline(t *testing.T) {
+	var rawEntry, deleted string
+	searches := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v0/vaults/vault-1/memories/mem-1/entries", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"entries": []map[string]string{{"entryId": "e-1", "rawEntry": rawEntry}}, "count": 1})
+			return
+		}
+		var req map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		rawEntry, _ = req["rawEntry"].(string)
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]string{"entryId": "e-1", "rawEntry": rawEntry})
+	})
+	mux.HandleFunc("/v0/search", func(w http.ResponseWriter, r *http.Request) {
+		searches++
+		entries := []map[string]string{}
+		if searches > 1 {
+			entries = append(entries, map[string]string{"entryId": "e-1", "rawEntry": rawEntry})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"entries": entries, "count": len(entries)})
+	})
+	mux.HandleFunc("/v0/vaults/vault-1/memories/mem-1/entries/e-1", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			deleted = "e-1"
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	var out bytes.Buffer
+	root := NewRootCmd()
+	root.SetOut(&out)
+	root.SetArgs([]string{"verify-pipeline", "--service-url", srv.URL, "--vault-id", "vault-1", "--memory-id", "mem-1", "--search-timeout", "5s"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("verify-pipeline failed: %v\n%s", err, out.String())
+	}
+	if !strings.Contains(rawEntry, "mycelian-verify-") || searches != 2 || deleted != "e-1" {
+		t.Fatalf("unexpected run: rawEntry %q, %d searches, deleted %q", rawEntry, searches, deleted)
+	}
+	for _, stage := range []string{"write", "consistency", "search", "delete", "total"} {
+		if !strings.Contains(out.String(), stage) {
+			t.Fatalf("report lacks %s:\n%s", stage, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "[FAIL]") {
+		t.Fatalf("unexpected failure:\n%s", out.String())
+	}
+}
+
+func TestCLI_NoCredentialsWithoutDevMode(t *testing.T) {
+	t.Setenv("MYCELIAN_CONFIG_DIR", t.TempDir())
+	t.Setenv("MYCELIAN_KEYRING", "file")
+	t.Setenv("MYCELIAN_API_KEY", "")
+	t.Setenv("MYCELIAN_PROFILE", "")
+	t.Setenv(client.EnvAccessToken, "")
+	t.Setenv(client.EnvDevMode, "")
+
+	root := NewRootCmd()
+	root.SetOut(io.Discard)
+	root.SetErr(io.Discard)
+	root.SetArgs([]string{"list-vaults", "--service-url", "http://127.0.0.1:1"})
+	if err := root.Execute(); !errors.Is(err, client.ErrNoCredentials) {
+		t.Fatalf("list-vaults without credentials: err = %v, want ErrNoCredentials", err)
+	}
+}
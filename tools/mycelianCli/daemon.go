@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/mycelian/mycelian-memory/client"
+	"github.com/mycelian/mycelian-memory/client/credentials"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// Every CLI invocation builds a client and dials the service, paying TCP,
+// TLS and connection setup for a single request. Shell-scripted ingestion
+// runs thousands of them. The daemon proxies requests from a unix socket
+// over one shared pool of keep-alive connections, so later invocations that
+// set --daemon-socket only pay for a local socket connect.
+
+// daemonSocket is where the daemon listens and where other commands send
+// their requests when it is set.
+var daemonSocket string
+
+// clientAuth picks the credential option for apiKey, explaining how to
+// configure one when there is none.
+func clientAuth(apiKey string) (client.Option, error) {
+	auth, err := client.Credentials(apiKey, devMode)
+	if err != nil {
+		return nil, fmt.Errorf("%w; run mycelianCli login, set MYCELIAN_API_KEY or pass --dev-mode", err)
+	}
+	return auth, nil
+}
+
+// newClient returns a client for serviceURL authenticated with the resolved
+// API key or access token, or in dev mode with --dev-mode, routed through
+// the daemon when --daemon-socket is set. Without credentials it returns
+// client.ErrNoCredentials.
+func newClient(opts ...client.Option) (*client.Client, error) {
+	apiKey, err := resolveAPIKey()
+	if err != nil {
+		return nil, err
+	}
+	auth, err := clientAuth(apiKey)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(append([]client.Option(nil), opts...), auth)
+	if daemonSocket != "" {
+		opts = append(opts, client.WithUnixSocket(daemonSocket))
+	}
+	return client.New(serviceURL, opts...)
+}
+
+// defaultDaemonSocket is the daemon's socket path when --daemon-socket is
+// unset: in XDG_RUNTIME_DIR if set, otherwise a per-user file in the temp dir.
+func defaultDaemonSocket() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "mycelianCli.sock")
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("mycelianCli-%d.sock", os.Getuid()))
+}
+
+func newDaemonCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "daemon",
+		Short: "Keep warm connections to the service for other CLI calls on a unix socket",
+		Long: `Daemon runs in the foreground until interrupted, proxying requests made on
+its unix socket to --service-url over a shared pool of keep-alive connections.
+Point later invocations at it to skip per-call connection and TLS setup:
+
+  mycelianCli daemon --daemon-socket /tmp/mycelian.sock &
+  export MYCELIAN_DAEMON_SOCKET=/tmp/mycelian.sock
+  mycelianCli create-entry ...
+
+Requests for a different service URL than the daemon's are rejected with 421.
+The socket is only accessible to the current user.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Follow the profile's service URL; callers send their own keys.
+			if !serviceURLSet {
+				profileURL, _, err := credentials.Resolve(profile)
+				if err != nil {
+					return err
+				}
+				if profileURL != "" {
+					serviceURL = profileURL
+				}
+			}
+			socket := daemonSocket
+			if socket == "" {
+				socket = defaultDaemonSocket()
+			}
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			return runDaemon(ctx, serviceURL, socket, func() {
+				fmt.Fprintf(cmd.OutOrStdout(), "Proxying %s on %s\nexport MYCELIAN_DAEMON_SOCKET=%s\n", serviceURL, socket, socket)
+			})
+		},
+	}
+	return cmd
+}
+
+// runDaemon serves the proxy on socket until ctx is done, calling ready once
+// it is listening.
+func runDaemon(ctx context.Context, target, socket string, ready func()) error {
+	u, err := url.Parse(target)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid service URL %q", target)
+	}
+	ln, err := listenDaemonSocket(socket)
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{Handler: newDaemonProxy(u), ReadHeaderTimeout: 10 * time.Second}
+	errc := make(chan error, 1)
+	go func() { errc <- srv.Serve(ln) }()
+	log.Debug().Str("socket", socket).Str("service_url", target).Msg("daemon listening")
+	if ready != nil {
+		ready()
+	}
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return srv.Shutdown(shutdownCtx)
+}
+
+// listenDaemonSocket listens on socket, replacing a stale socket file left by
+// a daemon that did not exit cleanly but refusing to take over a live one.
+func listenDaemonSocket(socket string) (net.Listener, error) {
+	if _, err := os.Stat(socket); err == nil {
+		if conn, err := net.DialTimeout("unix", socket, time.Second); err == nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("a daemon is already listening on %s", socket)
+		}
+		if err := os.Remove(socket); err != nil {
+			return nil, fmt.Errorf("remove stale socket: %w", err)
+		}
+	}
+	ln, err := net.Listen("unix", socket)
+	if err != nil {
+		return nil, err
+	}
+	// Requests on the socket carry the caller's credentials to the service.
+	if err := os.Chmod(socket, 0o600); err != nil {
+		_ = ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
+
+// newDaemonProxy forwards requests addressed to target's host over one
+// shared transport. It is safe for concurrent use, so parallel CLI calls
+// share the warm connections.
+func newDaemonProxy(target *url.URL) http.Handler {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = 64
+	transport.MaxIdleConnsPerHost = 64
+	transport.IdleConnTimeout = 5 * time.Minute
+	proxy := &httputil.ReverseProxy{
+		Rewrite:   func(pr *httputil.ProxyRequest) { pr.SetURL(target) },
+		Transport: transport,
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			log.Debug().Err(err).Str("path", r.URL.Path).Msg("daemon proxy error")
+			if errors.Is(err, context.Canceled) {
+				return
+			}
+			http.Error(w, "mycelianCli daemon: "+err.Error(), http.StatusBadGateway)
+		},
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Host != target.Host {
+			http.Error(w, fmt.Sprintf("mycelianCli daemon proxies %s, not %s", target.Host, r.Host), http.StatusMisdirectedRequest)
+			return
+		}
+		proxy.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mycelian/mycelian-memory/client"
+)
+
+func TestDaemon_ReusesConnectionAcrossClients(t *testing.T) {
+	var conns atomic.Int32
+	upstream := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"healthy"}`))
+	}))
+	upstream.Config.ConnState = func(_ net.Conn, s http.ConnState) {
+		if s == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	upstream.Start()
+	defer upstream.Close()
+
+	socket := filepath.Join(t.TempDir(), "d.sock")
+	ctx, cancel := context.WithCancel(context.Background())
+	ready := make(chan struct{})
+	done := make(chan error, 1)
+	go func() { done <- runDaemon(ctx, upstream.URL, socket, func() { close(ready) }) }()
+	select {
+	case <-ready:
+	case err := <-done:
+		t.Fatalf("runDaemon: %v", err)
+	}
+
+	// Each client stands in for a separate CLI invocation.
+	for i := 0; i < 3; i++ {
+		c, err := client.New(upstream.URL, client.WithDevMode(), client.WithUnixSocket(socket))
+		if err != nil {
+			t.Fatalf("client: %v", err)
+		}
+		if _, err := c.Health(context.Background()); err != nil {
+			t.Fatalf("health via daemon: %v", err)
+		}
+		_ = c.Close()
+	}
+	if n := conns.Load(); n != 1 {
+		t.Fatalf("upstream saw %d connections, want 1", n)
+	}
+
+	if err := runDaemon(context.Background(), upstream.URL, socket, nil); err == nil || !strings.Contains(err.Error(), "already listening") {
+		t.Fatalf("second daemon: %v", err)
+	}
+
+	other, err := client.New("http://elsewhere:11545", client.WithDevMode(), client.WithUnixSocket(socket))
+	if err != nil {
+		t.Fatalf("client: %v", err)
+	}
+	if _, err := other.Health(context.Background()); err == nil {
+		t.Fatalf("expected request for another service URL to fail")
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+}
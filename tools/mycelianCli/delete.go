@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// confirm asks question on stderr and reports whether the answer read from
+// in was yes. Anything else, including end of input, declines.
+func confirm(in io.Reader, out io.Writer, question string) bool {
+	_, _ = fmt.Fprintf(out, "%s [y/N] ", question)
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
+
+func newDeleteEntryCmd() *cobra.Command {
+	var vaultID, memoryID, entryID string
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "delete-entry",
+		Short: "Delete an entry from a memory (asks for confirmation unless --yes)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !yes && !confirm(cmd.InOrStdin(), cmd.ErrOrStderr(), fmt.Sprintf("Delete entry %s from memory %s?", entryID, memoryID)) {
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), "Aborted")
+				return nil
+			}
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := context.WithTimeout(cmd.Context(), 15*time.Second)
+			defer cancel()
+
+			if err := c.DeleteEntry(ctx, vaultID, memoryID, entryID); err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintln(cmd.OutOrStdout(), "Entry deleted")
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&vaultID, "vault-id", "", "Vault ID (required)")
+	cmd.Flags().StringVar(&memoryID, "memory-id", "", "Memory ID (required)")
+	cmd.Flags().StringVar(&entryID, "entry-id", "", "Entry ID (required)")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Delete without asking for confirmation")
+
+	_ = cmd.MarkFlagRequired("vault-id")
+	_ = cmd.MarkFlagRequired("memory-id")
+	_ = cmd.MarkFlagRequired("entry-id")
+	return cmd
+}
+
+func newDeleteContextCmd() *cobra.Command {
+	var vaultID, memoryID, contextID string
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "delete-context",
+		Short: "Delete a context snapshot from a memory (asks for confirmation unless --yes)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !yes && !confirm(cmd.InOrStdin(), cmd.ErrOrStderr(), fmt.Sprintf("Delete context %s from memory %s?", contextID, memoryID)) {
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), "Aborted")
+				return nil
+			}
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := context.WithTimeout(cmd.Context(), 15*time.Second)
+			defer cancel()
+
+			if err := c.DeleteContext(ctx, vaultID, memoryID, contextID); err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintln(cmd.OutOrStdout(), "Context deleted")
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&vaultID, "vault-id", "", "Vault ID (required)")
+	cmd.Flags().StringVar(&memoryID, "memory-id", "", "Memory ID (required)")
+	cmd.Flags().StringVar(&contextID, "context-id", "", "Context ID (required)")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Delete without asking for confirmation")
+
+	_ = cmd.MarkFlagRequired("vault-id")
+	_ = cmd.MarkFlagRequired("memory-id")
+	_ = cmd.MarkFlagRequired("context-id")
+	return cmd
+}
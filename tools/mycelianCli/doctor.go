@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/mycelian/mycelian-memory/client"
+	"github.com/mycelian/mycelian-memory/pkg/schema"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// expectedSchemaVersion is the storage schema revision this CLI was built
+// against, read from the schema-version line of the shared schema.sql.
+var expectedSchemaVersion = schema.Version
+
+// maxClockSkew is the largest tolerated difference between local and server clocks.
+const maxClockSkew = 30 * time.Second
+
+// componentFixes maps health component names to remediation hints.
+var componentFixes = map[string]string{
+	"store":       "Postgres is unreachable: check MEMORY_SERVER_POSTGRES_DSN and that the postgres container is running (make backend-status)",
+	"searchindex": "Weaviate is unreachable: check MEMORY_SERVER_SEARCH_INDEX_URL and that the weaviate container is running",
+	"embedder":    "Embedding provider is down: ensure Ollama is running and the model is pulled (ollama pull $MEMORY_SERVER_EMBED_MODEL)",
+	"warmup":      "Search warm-up has not completed yet: wait for the service to become ready, or fix the embedder/searchindex failures above",
+}
+
+// doctorCheck is the outcome of a single diagnostic.
+type doctorCheck struct {
+	name   string
+	ok     bool
+	detail string
+	fix    string
+}
+
+func newDoctorCmd() *cobra.Command {
+	var apiKey string
+
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose connectivity, auth, dependency health, schema version and clock skew",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if apiKey == "" {
+				key, err := resolveAPIKey()
+				if err != nil {
+					return err
+				}
+				apiKey = key
+			}
+			log.Debug().
+				Str("service_url", serviceURL).
+				Bool("api_key_set", apiKey != "").
+				Msg("running doctor")
+
+			auth, err := clientAuth(apiKey)
+			if err != nil {
+				return err
+			}
+			c, err := client.New(serviceURL, auth)
+			if err != nil {
+				return err
+			}
+			defer func() { _ = c.Close() }()
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), 15*time.Second)
+			defer cancel()
+
+			checks := runDoctorChecks(ctx, c)
+			if failed := printDoctorReport(cmd.OutOrStdout(), checks); failed > 0 {
+				return fmt.Errorf("doctor: %d check(s) failed", failed)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&apiKey, "api-key", "", "API key to validate (defaults to MYCELIAN_API_KEY, then the --profile key; --dev-mode checks the dev-mode key)")
+
+	return cmd
+}
+
+// runDoctorChecks executes the diagnostics in dependency order. When the
+// service is unreachable the remaining checks are skipped since they would
+// only repeat the same failure.
+func runDoctorChecks(ctx context.Context, c *client.Client) []doctorCheck {
+	var checks []doctorCheck
+
+	sent := time.Now()
+	h, err := c.Health(ctx)
+	received := time.Now()
+	if err != nil {
+		return append(checks, doctorCheck{
+			name:   "reachability",
+			detail: err.Error(),
+			fix:    fmt.Sprintf("start the backend (make start-dev-mycelian-server) or point --service-url/MEMORY_SERVICE_URL at a running service (current: %s)", serviceURL),
+		})
+	}
+	checks = append(checks, doctorCheck{name: "reachability", ok: true, detail: serviceURL})
+
+	// Auth: any authenticated read exercises the authorizer.
+	if _, err := c.ListVaults(ctx); err != nil {
+		fix := "inspect the service logs; the request failed before authorization could be confirmed"
+		if client.IsUnauthorized(err) {
+			fix = "store a valid key with mycelianCli login (or set --api-key/MYCELIAN_API_KEY); dev-mode keys only work when the server runs with MEMORY_SERVER_DEV_MODE=true"
+		}
+		checks = append(checks, doctorCheck{name: "auth", detail: err.Error(), fix: fix})
+	} else {
+		checks = append(checks, doctorCheck{name: "auth", ok: true, detail: "API key accepted"})
+	}
+
+	// Dependencies reported by the structured health endpoint.
+	if len(h.Components) == 0 {
+		ok := h.Status == "healthy"
+		checks = append(checks, doctorCheck{
+			name:   "health",
+			ok:     ok,
+			detail: h.Status,
+			fix:    "upgrade the service to report per-dependency health, then re-run doctor",
+		})
+	} else {
+		names := make([]string, 0, len(h.Components))
+		for name := range h.Components {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			status := h.Components[name]
+			fix := componentFixes[name]
+			if fix == "" {
+				fix = "inspect the service logs for " + name
+			}
+			checks = append(checks, doctorCheck{name: "health/" + name, ok: status == "healthy", detail: status, fix: fix})
+		}
+	}
+
+	// Schema version
+	switch h.SchemaVersion {
+	case expectedSchemaVersion:
+		checks = append(checks, doctorCheck{name: "schema", ok: true, detail: "version " + h.SchemaVersion})
+	case "":
+		checks = append(checks, doctorCheck{name: "schema", detail: "service did not report a schema version", fix: "upgrade the memory service to a release that reports schemaVersion"})
+	default:
+		checks = append(checks, doctorCheck{
+			name:   "schema",
+			detail: fmt.Sprintf("service reports version %s, CLI expects %s", h.SchemaVersion, expectedSchemaVersion),
+			fix:    "upgrade whichever side is older so the CLI and service schema versions match",
+		})
+	}
+
+	// Clock skew: compare against the midpoint of the health round trip.
+	if h.Timestamp.IsZero() {
+		checks = append(checks, doctorCheck{name: "clock", detail: "service did not report its time", fix: "upgrade the memory service"})
+	} else {
+		local := sent.Add(received.Sub(sent) / 2)
+		skew := h.Timestamp.Sub(local)
+		if skew < 0 {
+			skew = -skew
+		}
+		// Server timestamps have one-second resolution.
+		skew = skew.Truncate(time.Second)
+		if skew > maxClockSkew {
+			checks = append(checks, doctorCheck{
+				name:   "clock",
+				detail: fmt.Sprintf("skew %s exceeds %s", skew, maxClockSkew),
+				fix:    "enable NTP time sync on this machine and the service host; large skew breaks time-range queries",
+			})
+		} else {
+			checks = append(checks, doctorCheck{name: "clock", ok: true, detail: "skew " + skew.String()})
+		}
+	}
+
+	return checks
+}
+
+// printDoctorReport writes one line per check plus a fix hint for failures and
+// returns the number of failed checks.
+func printDoctorReport(w io.Writer, checks []doctorCheck) int {
+	failed := 0
+	for _, ch := range checks {
+		if ch.ok {
+			_, _ = fmt.Fprintf(w, "[OK]   %-20s %s\n", ch.name, ch.detail)
+			continue
+		}
+		failed++
+		_, _ = fmt.Fprintf(w, "[FAIL] %-20s %s\n", ch.name, ch.detail)
+		_, _ = fmt.Fprintf(w, "       fix: %s\n", ch.fix)
+	}
+	return failed
+}
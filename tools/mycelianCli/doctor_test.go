@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newDoctorStub(t *testing.T, health map[string]interface{}, vaultStatus int) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v0/health", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(health)
+	})
+	mux.HandleFunc("/v0/vaults", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(vaultStatus)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"vaults": []interface{}{}, "count": 0})
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestCLI_Doctor_AllHealthy(t *testing.T) {
+	srv := newDoctorStub(t, map[string]interface{}{
+		"status":        "healthy",
+		"timestamp":     time.Now().Format(time.RFC3339),
+		"schemaVersion": expectedSchemaVersion,
+		"components":    map[string]string{"store": "healthy", "searchindex": "healthy", "embedder": "healthy"},
+	}, http.StatusOK)
+	defer srv.Close()
+
+	b := &strings.Builder{}
+	root := NewRootCmd()
+	root.SetOut(b)
+	root.SetArgs([]string{"doctor", "--service-url", srv.URL})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("doctor failed: %v\n%s", err, b.String())
+	}
+	if strings.Contains(b.String(), "[FAIL]") {
+		t.Fatalf("unexpected failure in report:\n%s", b.String())
+	}
+}
+
+func TestCLI_Doctor_ReportsFixes(t *testing.T) {
+	srv := newDoctorStub(t, map[string]interface{}{
+		"status":        "unhealthy",
+		"timestamp":     time.Now().Add(-5 * time.Minute).Format(time.RFC3339),
+		"schemaVersion": "0",
+		"components":    map[string]string{"store": "healthy", "searchindex": "unhealthy", "embedder": "healthy"},
+	}, http.StatusUnauthorized)
+	defer srv.Close()
+
+	b := &strings.Builder{}
+	root := NewRootCmd()
+	root.SetOut(b)
+	root.SetArgs([]string{"doctor", "--service-url", srv.URL, "--api-key", "bad"})
+	if err := root.Execute(); err == nil {
+		t.Fatalf("expected doctor to fail")
+	}
+	out := b.String()
+	for _, want := range []string{"[FAIL] auth", "MYCELIAN_API_KEY", "[FAIL] health/searchindex", "MEMORY_SERVER_SEARCH_INDEX_URL", "[FAIL] schema", "[FAIL] clock"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("report missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestCLI_Doctor_Unreachable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+
+	b := &strings.Builder{}
+	root := NewRootCmd()
+	root.SetOut(b)
+	root.SetArgs([]string{"doctor", "--service-url", url})
+	if err := root.Execute(); err == nil {
+		t.Fatalf("expected doctor to fail")
+	}
+	if !strings.Contains(b.String(), "[FAIL] reachability") {
+		t.Fatalf("expected reachability failure:\n%s", b.String())
+	}
+}
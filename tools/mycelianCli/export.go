@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/mycelian/mycelian-memory/client"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+func newExportCmd() *cobra.Command {
+	var vaultID, memoryID, out, manifest, keyFile string
+	var embeddings bool
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export entries as JSON Lines, optionally with their embeddings",
+		Long: `Export writes one JSON object per entry, oldest first per memory. Without
+--memory-id every memory of the vault is exported.
+
+With --embeddings each line also holds "embedding": {"model", "dimension",
+"vector"} taken from the search index, so the data can be loaded into another
+vector store or analysed offline without re-embedding. Entries the index has
+not caught up with yet are written without an embedding.
+
+With --out, a manifest holding the entry counts per memory, SHA-256 digests
+and the service's schema version is written next to the file (see
+--manifest); "import --format mycelian" checks it before writing anything.
+--key-file encrypts the export with AES-256-GCM under a 32-byte key, e.g.
+one generated with "openssl rand -hex 32", and binds the manifest to it, so
+a changed manifest fails decryption. A plaintext export's manifest only
+detects accidental damage.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Debug().
+				Str("vault_id", vaultID).
+				Str("memory_id", memoryID).
+				Bool("embeddings", embeddings).
+				Bool("encrypted", keyFile != "").
+				Str("service_url", serviceURL).
+				Msg("exporting entries")
+
+			var key []byte
+			if keyFile != "" {
+				var err error
+				if key, err = readExportKey(keyFile); err != nil {
+					return err
+				}
+			}
+			toFile := out != "" && out != "-"
+			if manifest == "" && toFile {
+				manifest = manifestPath(out)
+			}
+
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := context.WithTimeout(cmd.Context(), 10*time.Minute)
+			defer cancel()
+
+			var mems []client.Memory
+			if memoryID == "" {
+				if mems, err = c.ListMemories(ctx, vaultID); err != nil {
+					return err
+				}
+			} else if manifest != "" {
+				mem, err := c.GetMemory(ctx, vaultID, memoryID)
+				if err != nil {
+					return err
+				}
+				mems = []client.Memory{*mem}
+			} else {
+				mems = []client.Memory{{ID: memoryID}}
+			}
+
+			w := cmd.OutOrStdout()
+			if toFile {
+				f, err := os.Create(out)
+				if err != nil {
+					return err
+				}
+				defer func() { _ = f.Close() }()
+				w = f
+			}
+			var plain bytes.Buffer
+			file := sha256.New()
+			dst := io.MultiWriter(w, file)
+			if key != nil {
+				dst = &plain
+			}
+			m, err := exportEntries(ctx, c, dst, vaultID, mems, embeddings)
+			if err != nil {
+				return err
+			}
+			if manifest != "" {
+				if h, err := c.Health(ctx); err == nil {
+					m.SchemaVersion = h.SchemaVersion
+				} else {
+					fmt.Fprintf(cmd.ErrOrStderr(), "Warning: schema version unavailable (%v); import will not check it\n", err)
+				}
+			}
+			if key != nil {
+				m.Encryption = exportEncryption
+				aad, err := manifestAAD(m)
+				if err != nil {
+					return err
+				}
+				sealed, err := sealExport(key, plain.Bytes(), aad)
+				if err != nil {
+					return err
+				}
+				if _, err := io.MultiWriter(w, file).Write(sealed); err != nil {
+					return err
+				}
+			}
+			if manifest != "" {
+				m.FileSHA256 = hex.EncodeToString(file.Sum(nil))
+				if err := writeManifest(manifest, m); err != nil {
+					return err
+				}
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "Exported %d entries from %d memories\n", m.Entries, len(mems))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&vaultID, "vault-id", "", "Vault ID (required)")
+	cmd.Flags().StringVar(&memoryID, "memory-id", "", "Memory ID (default: every memory in the vault)")
+	cmd.Flags().StringVar(&out, "out", "", "Output file (default: stdout)")
+	cmd.Flags().BoolVar(&embeddings, "embeddings", false, "Include each entry's stored embedding")
+	cmd.Flags().StringVar(&manifest, "manifest", "", "Manifest file (default: <out>.manifest.json; none for stdout)")
+	cmd.Flags().StringVar(&keyFile, "key-file", "", "Encrypt with the AES-256 key in this file (32 bytes or 64 hex characters)")
+
+	_ = cmd.MarkFlagRequired("vault-id")
+
+	return cmd
+}
+
+// exportEntries writes the memories' entries as JSON Lines and returns a
+// manifest with their counts and the digest of everything written.
+func exportEntries(ctx context.Context, c *client.Client, w io.Writer, vaultID string, mems []client.Memory, embeddings bool) (*exportManifest, error) {
+	sum := sha256.New()
+	enc := json.NewEncoder(io.MultiWriter(w, sum))
+	m := &exportManifest{Version: exportManifestVersion, VaultID: vaultID, CreatedAt: time.Now().UTC(), Memories: []manifestMemory{}}
+	for _, mem := range mems {
+		entries, err := c.ExportEntries(ctx, vaultID, mem.ID, embeddings)
+		if err != nil {
+			return nil, fmt.Errorf("memory %s: %w", mem.ID, err)
+		}
+		for _, e := range entries {
+			if err := enc.Encode(e); err != nil {
+				return nil, err
+			}
+		}
+		m.Memories = append(m.Memories, manifestMemory{MemoryID: mem.ID, Title: mem.Title, Entries: len(entries)})
+		m.Entries += len(entries)
+	}
+	m.SHA256 = hex.EncodeToString(sum.Sum(nil))
+	return m, nil
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mycelian/mycelian-memory/client"
+)
+
+// exportManifestVersion is bumped when the manifest layout changes.
+// Version 2 binds the manifest to an encrypted export, see manifestAAD;
+// version 1 manifests are still read.
+const exportManifestVersion = 2
+
+// exportEncryption names the cipher of encrypted exports: a 12-byte nonce
+// followed by the AES-256-GCM sealed JSON Lines.
+const exportEncryption = "aes-256-gcm"
+
+// mycelianFormat is the import format of this CLI's own exports.
+const mycelianFormat = "mycelian"
+
+// exportManifest describes one export file so a copy that went through
+// untrusted storage can be checked before it is imported.
+type exportManifest struct {
+	Version   int       `json:"version"`
+	VaultID   string    `json:"vaultId"`
+	CreatedAt time.Time `json:"createdAt"`
+	// SchemaVersion is the storage schema the service reported, if any.
+	SchemaVersion string           `json:"schemaVersion,omitempty"`
+	Entries       int              `json:"entries"`
+	Memories      []manifestMemory `json:"memories"`
+	// SHA256 is the hex digest of the JSON Lines before encryption.
+	SHA256 string `json:"sha256"`
+	// Encryption is exportEncryption for encrypted files, else empty.
+	Encryption string `json:"encryption,omitempty"`
+	// FileSHA256 is the hex digest of the file as written.
+	FileSHA256 string `json:"fileSha256"`
+}
+
+// manifestMemory counts the entries exported from one memory.
+type manifestMemory struct {
+	MemoryID string `json:"memoryId"`
+	Title    string `json:"title,omitempty"`
+	Entries  int    `json:"entries"`
+}
+
+func writeManifest(path string, m *exportManifest) error {
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0o644)
+}
+
+func readManifest(path string) (*exportManifest, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var m exportManifest
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("manifest %s: %w", path, err)
+	}
+	if m.Version < 1 || m.Version > exportManifestVersion {
+		return nil, fmt.Errorf("manifest %s: unsupported version %d (this CLI reads up to %d)", path, m.Version, exportManifestVersion)
+	}
+	// Entries of a newer schema may carry fields this CLI would drop. An
+	// export made while the server's health was unavailable has no version,
+	// which leaves it unchecked.
+	if m.SchemaVersion == "" {
+		return &m, nil
+	}
+	schema, err := strconv.Atoi(m.SchemaVersion)
+	if err != nil {
+		return nil, fmt.Errorf("manifest %s: unknown schemaVersion %q", path, m.SchemaVersion)
+	}
+	if expected, _ := strconv.Atoi(expectedSchemaVersion); schema > expected {
+		return nil, fmt.Errorf("manifest %s: exported from schema %d, newer than this CLI's %d; upgrade mycelianCli", path, schema, expected)
+	}
+	return &m, nil
+}
+
+// manifestAAD is the additional data an encrypted export is sealed with:
+// the manifest without FileSHA256, which depends on the sealed bytes. Any
+// change to the manifest then fails decryption.
+func manifestAAD(m *exportManifest) ([]byte, error) {
+	bound := *m
+	bound.FileSHA256 = ""
+	return json.Marshal(&bound)
+}
+
+// manifestPath is where a manifest lives when --manifest is not given.
+func manifestPath(file string) string {
+	return file + ".manifest.json"
+}
+
+// readExportKey loads an AES-256 key stored as 32 raw bytes or 64 hex
+// characters, e.g. from `openssl rand -hex 32`.
+func readExportKey(path string) ([]byte, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if s := strings.TrimSpace(string(b)); len(s) == 64 {
+		if key, err := hex.DecodeString(s); err == nil {
+			return key, nil
+		}
+	}
+	if len(b) == 32 {
+		return b, nil
+	}
+	return nil, fmt.Errorf("key file %s must hold 32 bytes or 64 hex characters", path)
+}
+
+func sealExport(key, plaintext, aad []byte) ([]byte, error) {
+	gcm, err := newExportGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+func openExport(key, sealed, aad []byte) ([]byte, error) {
+	gcm, err := newExportGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, fmt.Errorf("encrypted export is truncated")
+	}
+	plain, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], aad)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt export: wrong key, or the file or its manifest was changed")
+	}
+	return plain, nil
+}
+
+func newExportGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func sha256Hex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// openVerifiedExport checks data, the bytes of an export file, against its
+// manifest and returns the JSON Lines, decrypted with the key at keyFile
+// when the manifest says the file is encrypted. Only an encrypted export
+// authenticates its manifest; a plaintext one can be rewritten along with it.
+func openVerifiedExport(data []byte, m *exportManifest, keyFile string) ([]byte, error) {
+	if got := sha256Hex(data); got != m.FileSHA256 {
+		return nil, fmt.Errorf("export file does not match its manifest (sha256 %s, manifest %s)", got, m.FileSHA256)
+	}
+	switch m.Encryption {
+	case "":
+	case exportEncryption:
+		if keyFile == "" {
+			return nil, fmt.Errorf("export is encrypted; --key-file is required")
+		}
+		key, err := readExportKey(keyFile)
+		if err != nil {
+			return nil, err
+		}
+		var aad []byte
+		if m.Version >= 2 {
+			if aad, err = manifestAAD(m); err != nil {
+				return nil, err
+			}
+		}
+		if data, err = openExport(key, data, aad); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported export encryption %q", m.Encryption)
+	}
+	if got := sha256Hex(data); got != m.SHA256 {
+		return nil, fmt.Errorf("decrypted export does not match its manifest (sha256 %s, manifest %s)", got, m.SHA256)
+	}
+	return data, nil
+}
+
+// checkManifestCounts compares the parsed records with the manifest's
+// per-memory entry counts.
+func checkManifestCounts(m *exportManifest, recs []importRecord) error {
+	got := map[string]int{}
+	for _, r := range recs {
+		got[r.memoryKey]++
+	}
+	total := 0
+	for _, mm := range m.Memories {
+		if got[mm.MemoryID] != mm.Entries {
+			return fmt.Errorf("memory %s: manifest lists %d entries, export holds %d", mm.MemoryID, mm.Entries, got[mm.MemoryID])
+		}
+		total += mm.Entries
+	}
+	if total != len(recs) || m.Entries != len(recs) {
+		return fmt.Errorf("manifest lists %d entries, export holds %d", m.Entries, len(recs))
+	}
+	return nil
+}
+
+// parseMycelian reads this CLI's own JSON Lines export. Records are grouped
+// by their source memory ID; embeddings and signal counts are not imported.
+func parseMycelian(data []byte, rep *importReport) ([]importRecord, error) {
+	var out []importRecord
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; sc.Scan(); line++ {
+		if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+			continue
+		}
+		var e client.ExportedEntry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("mycelian: line %d: %w", line, err)
+		}
+		if e.Embedding != nil {
+			rep.dropped["embedding"]++
+		}
+		if e.UsefulCount+e.IncorrectCount+e.OutdatedCount > 0 {
+			rep.dropped["signals"]++
+		}
+		out = append(out, importRecord{memoryKey: e.MemoryID, entry: client.AddEntryRequest{
+			RawEntry:         e.RawEntry,
+			Summary:          e.Summary,
+			Metadata:         e.Metadata,
+			Tags:             e.Tags,
+			ExpirationTime:   e.ExpirationTime,
+			SourceID:         e.ID,
+			SessionID:        e.SessionID,
+			Usage:            e.Usage,
+			ConversationTime: e.ConversationTime,
+		}})
+	}
+	return out, sc.Err()
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCLI_ExportVault(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v0/vaults/v1/memories", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"memories": []map[string]string{{"memoryId": "m1"}, {"memoryId": "m2"}},
+		})
+	})
+	mux.HandleFunc("/v0/vaults/v1/memories/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("embeddings") != "true" {
+			t.Errorf("embeddings not requested: %s", r.URL)
+		}
+		memID := strings.Split(r.URL.Path, "/")[5]
+		_, _ = w.Write([]byte(`{"entryId":"` + memID + `-e1","memoryId":"` + memID + `","embedding":{"model":"m","dimension":1,"vector":[1]}}` + "\n"))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	b := &strings.Builder{}
+	root := NewRootCmd()
+	root.SetOut(b)
+	root.SetErr(&strings.Builder{})
+	root.SetArgs([]string{"export", "--service-url", srv.URL, "--vault-id", "v1", "--embeddings"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[1], `"entryId":"m2-e1"`) || !strings.Contains(lines[0], `"dimension":1`) {
+		t.Fatalf("unexpected output:\n%s", b.String())
+	}
+}
+
+func TestCLI_EncryptedExportVerifiesOnImport(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v0/vaults/v1/memories", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"memories": []map[string]string{{"memoryId": "m1", "title": "notes"}},
+		})
+	})
+	mux.HandleFunc("/v0/vaults/v1/memories/m1/export", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"entryId":"e1","memoryId":"m1","rawEntry":"likes tea","summary":"tea"}` + "\n" +
+			`{"entryId":"e2","memoryId":"m1","rawEntry":"likes jazz","summary":"jazz"}` + "\n"))
+	})
+	mux.HandleFunc("/v0/health", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"UP","schemaVersion":"10"}`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	dir := t.TempDir()
+	out, keyFile := filepath.Join(dir, "vault.jsonl"), filepath.Join(dir, "key")
+	if err := os.WriteFile(keyFile, []byte(strings.Repeat("ab", 32)+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	root := NewRootCmd()
+	root.SetErr(&strings.Builder{})
+	root.SetArgs([]string{"export", "--service-url", srv.URL, "--vault-id", "v1", "--out", out, "--key-file", keyFile})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	data, _ := os.ReadFile(out)
+	if strings.Contains(string(data), "likes tea") {
+		t.Fatal("export was written in plaintext")
+	}
+	m, err := readManifest(manifestPath(out))
+	if err != nil {
+		t.Fatalf("manifest: %v", err)
+	}
+	if m.Entries != 2 || len(m.Memories) != 1 || m.Memories[0].Title != "notes" || m.Encryption != exportEncryption || m.SchemaVersion != "10" {
+		t.Fatalf("unexpected manifest: %+v", m)
+	}
+
+	dryRun := func(args ...string) (string, error) {
+		b := &strings.Builder{}
+		root := NewRootCmd()
+		root.SetOut(b)
+		root.SetErr(&strings.Builder{})
+		root.SetArgs(append([]string{"import", "--format", "mycelian", "--file", out, "--dry-run"}, args...))
+		err := root.Execute()
+		return b.String(), err
+	}
+	got, err := dryRun("--key-file", keyFile)
+	if err != nil || !strings.Contains(got, "notes") || !strings.Contains(got, "2 entries") {
+		t.Fatalf("verified dry run: %v\n%s", err, got)
+	}
+	if _, err := dryRun(); err == nil || !strings.Contains(err.Error(), "--key-file") {
+		t.Fatalf("expected a missing key error, got %v", err)
+	}
+
+	// A manifest rewritten to match, e.g. with a changed title, fails
+	// decryption since it is bound to the export.
+	forged := *m
+	forged.Memories = []manifestMemory{{MemoryID: "m1", Title: "other", Entries: 2}}
+	if err := writeManifest(manifestPath(out), &forged); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dryRun("--key-file", keyFile); err == nil || !strings.Contains(err.Error(), "manifest was changed") {
+		t.Fatalf("expected a forged manifest to be detected, got %v", err)
+	}
+	for version, want := range map[string]string{"x": "unknown schemaVersion", "999": "newer than this CLI"} {
+		forged = *m
+		forged.SchemaVersion = version
+		if err := writeManifest(manifestPath(out), &forged); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := dryRun("--key-file", keyFile); err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("schemaVersion %q: expected %q, got %v", version, want, err)
+		}
+	}
+	if err := writeManifest(manifestPath(out), m); err != nil {
+		t.Fatal(err)
+	}
+
+	data[len(data)-1] ^= 1
+	if err := os.WriteFile(out, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dryRun("--key-file", keyFile); err == nil || !strings.Contains(err.Error(), "does not match its manifest") {
+		t.Fatalf("expected tampering to be detected, got %v", err)
+	}
+}
+
+func TestCLI_ExportWithoutHealthImports(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v0/vaults/v1/memories", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"memories": []map[string]string{{"memoryId": "m1", "title": "notes"}},
+		})
+	})
+	mux.HandleFunc("/v0/vaults/v1/memories/m1/export", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"entryId":"e1","memoryId":"m1","rawEntry":"likes tea","summary":"tea"}` + "\n"))
+	})
+	mux.HandleFunc("/v0/health", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	dir := t.TempDir()
+	out, keyFile := filepath.Join(dir, "vault.jsonl"), filepath.Join(dir, "key")
+	if err := os.WriteFile(keyFile, []byte(strings.Repeat("cd", 32)+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	stderr := &strings.Builder{}
+	root := NewRootCmd()
+	root.SetErr(stderr)
+	root.SetArgs([]string{"export", "--service-url", srv.URL, "--vault-id", "v1", "--out", out, "--key-file", keyFile})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	if !strings.Contains(stderr.String(), "schema version unavailable") {
+		t.Fatalf("expected a warning about the missing schema version:\n%s", stderr.String())
+	}
+
+	b := &strings.Builder{}
+	root = NewRootCmd()
+	root.SetOut(b)
+	root.SetErr(&strings.Builder{})
+	root.SetArgs([]string{"import", "--format", "mycelian", "--file", out, "--dry-run", "--key-file", keyFile})
+	if err := root.Execute(); err != nil || !strings.Contains(b.String(), "1 entries") {
+		t.Fatalf("an export without a schema version must import: %v\n%s", err, b.String())
+	}
+}
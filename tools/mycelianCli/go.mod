@@ -4,6 +4,7 @@ go 1.24.6
 
 require (
 	github.com/mycelian/mycelian-memory/client v0.0.0
+	github.com/mycelian/mycelian-memory/pkg/schema v0.0.0
 	github.com/rs/zerolog v1.34.0
 	github.com/spf13/cobra v1.9.1
 )
@@ -27,3 +28,5 @@ require (
 )
 
 replace github.com/mycelian/mycelian-memory/client => ../../client
+
+replace github.com/mycelian/mycelian-memory/pkg/schema => ../../pkg/schema
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mycelian/mycelian-memory/client"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// importPlan is the parsed export grouped into memories, in first-seen order.
+type importPlan struct {
+	titles  []string
+	entries map[string][]client.AddEntryRequest
+}
+
+func planImport(format, memoryTitle string, recs []importRecord) importPlan {
+	p := importPlan{entries: map[string][]client.AddEntryRequest{}}
+	for _, r := range recs {
+		title := memoryTitle
+		if title == "" && format == mycelianFormat {
+			title = r.memoryKey // Set to the source memory's title by verifyMycelianImport
+		}
+		if title == "" {
+			title = importTitle(format, r.memoryKey)
+		}
+		if _, ok := p.entries[title]; !ok {
+			p.titles = append(p.titles, title)
+		}
+		p.entries[title] = append(p.entries[title], r.entry)
+	}
+	return p
+}
+
+func newImportCmd() *cobra.Command {
+	var format, file, vaultID, memoryTitle, memoryType, manifest, keyFile string
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Import a Mem0, Zep, LangChain or mycelianCli export into a vault",
+		Long: `Import maps a foreign memory export onto memories and entries in a vault.
+
+Records are grouped into one memory per source user (Zep falls back to the
+session) unless --memory-title puts them all in one memory; existing memories
+with the same title are reused. Fields without a Mycelian equivalent are
+listed in the report. Entries are written under a new ingestion batch so the
+whole import can be rolled back.
+
+--format mycelian restores a file written by "export". Its manifest (see
+--manifest) must match: the file digest, the digest after decrypting with
+--key-file, and the entry count of every memory are checked before anything
+is written, so --dry-run validates a backup. A manifest of an unknown format
+or one exported from a newer storage schema than this CLI knows is refused.
+Memories keep their titles.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			parse, ok := importers[format]
+			if !ok {
+				return fmt.Errorf("unknown --format %q (want one of %s)", format, strings.Join(importFormats(), ", "))
+			}
+			data, err := readImportFile(file)
+			if err != nil {
+				return err
+			}
+			var m *exportManifest
+			if format == mycelianFormat {
+				if manifest == "" && file == "-" {
+					return fmt.Errorf("--manifest is required when reading a mycelian export from stdin")
+				}
+				if manifest == "" {
+					manifest = manifestPath(file)
+				}
+				if m, err = readManifest(manifest); err != nil {
+					return err
+				}
+				if data, err = openVerifiedExport(data, m, keyFile); err != nil {
+					return err
+				}
+			}
+			rep := newImportReport()
+			recs, err := parse(data, rep)
+			if err != nil {
+				return err
+			}
+			if m != nil {
+				if err := verifyMycelianImport(m, recs); err != nil {
+					return err
+				}
+			}
+			plan := planImport(format, memoryTitle, checkLengths(recs, rep))
+
+			log.Debug().
+				Str("format", format).
+				Str("file", file).
+				Str("vault_id", vaultID).
+				Int("records", len(recs)).
+				Int("memories", len(plan.titles)).
+				Bool("dry_run", dryRun).
+				Msg("importing")
+
+			out := cmd.OutOrStdout()
+			if dryRun {
+				for _, t := range plan.titles {
+					fmt.Fprintf(out, "%-50s %d entries\n", t, len(plan.entries[t]))
+				}
+				printImportReport(out, rep)
+				return nil
+			}
+
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := context.WithTimeout(cmd.Context(), 10*time.Minute)
+			defer cancel()
+			defer func() { _ = c.Close() }() // No-op once flushWrites has run
+
+			batchID, written, err := runImport(ctx, c, format, file, vaultID, memoryType, plan)
+			if batchID != "" {
+				fmt.Fprintf(out, "Ingestion batch: %s\n", batchID)
+			}
+			if err != nil {
+				return err
+			}
+			if err := flushWrites(ctx, c); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "Imported %d entries into %d memories\n", written, len(plan.titles))
+			printImportReport(out, rep)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "", "Export format: "+strings.Join(importFormats(), ", ")+" (required)")
+	cmd.Flags().StringVar(&file, "file", "", "Export file, or - for stdin (required)")
+	cmd.Flags().StringVar(&vaultID, "vault-id", "", "Vault ID (required unless --dry-run)")
+	cmd.Flags().StringVar(&memoryTitle, "memory-title", "", "Import everything into this memory instead of one per source user")
+	cmd.Flags().StringVar(&memoryType, "memory-type", "conversation", "Memory type of memories created by the import")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Parse and report without writing")
+	cmd.Flags().StringVar(&manifest, "manifest", "", "Manifest of a mycelian export (default: <file>.manifest.json)")
+	cmd.Flags().StringVar(&keyFile, "key-file", "", "AES-256 key file of an encrypted mycelian export")
+
+	_ = cmd.MarkFlagRequired("format")
+	_ = cmd.MarkFlagRequired("file")
+
+	return cmd
+}
+
+// verifyMycelianImport checks the parsed records against the manifest and
+// regroups them under their source memory's title.
+func verifyMycelianImport(m *exportManifest, recs []importRecord) error {
+	if err := checkManifestCounts(m, recs); err != nil {
+		return err
+	}
+	titles := make(map[string]string, len(m.Memories))
+	for _, mm := range m.Memories {
+		titles[mm.MemoryID] = mm.Title
+	}
+	for i := range recs {
+		recs[i].memoryKey = titles[recs[i].memoryKey]
+	}
+	return nil
+}
+
+func readImportFile(file string) ([]byte, error) {
+	if file == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(file)
+}
+
+// runImport creates the ingestion batch and any missing memories, then
+// enqueues every entry. It returns the batch ID and the number of entries enqueued.
+func runImport(ctx context.Context, c *client.Client, format, file, vaultID, memoryType string, plan importPlan) (string, int, error) {
+	if vaultID == "" {
+		return "", 0, fmt.Errorf("--vault-id is required")
+	}
+	existing, err := c.ListMemories(ctx, vaultID)
+	if err != nil {
+		return "", 0, err
+	}
+	memoryIDs := make(map[string]string, len(existing))
+	for _, m := range existing {
+		memoryIDs[m.Title] = m.ID
+	}
+
+	batch, err := c.CreateIngestionBatch(ctx, client.CreateIngestionBatchRequest{
+		SourceSystem: format,
+		Description:  "import of " + file,
+	})
+	if err != nil {
+		return "", 0, err
+	}
+
+	written := 0
+	for _, title := range plan.titles {
+		memID, ok := memoryIDs[title]
+		if !ok {
+			mem, err := c.CreateMemory(ctx, vaultID, client.CreateMemoryRequest{
+				Title:       title,
+				MemoryType:  memoryType,
+				Description: "Imported from " + format,
+			})
+			if err != nil {
+				return batch.BatchID, written, fmt.Errorf("create memory %s: %w", title, err)
+			}
+			memID = mem.ID
+		}
+		for _, e := range plan.entries[title] {
+			e.SourceSystem = format
+			e.IngestionBatchID = batch.BatchID
+			if _, err := c.AddEntry(ctx, vaultID, memID, e); err != nil {
+				return batch.BatchID, written, fmt.Errorf("memory %s: %w", title, err)
+			}
+			written++
+		}
+	}
+	return batch.BatchID, written, nil
+}
+
+func printImportReport(out io.Writer, rep *importReport) {
+	if fields := rep.droppedFields(); len(fields) > 0 {
+		fmt.Fprintln(out, "Dropped fields:")
+		for _, f := range fields {
+			fmt.Fprintf(out, "  %-40s %d records\n", f, rep.dropped[f])
+		}
+	}
+	if len(rep.skipped) > 0 {
+		fmt.Fprintf(out, "Skipped %d records:\n", len(rep.skipped))
+		for _, s := range rep.skipped {
+			fmt.Fprintf(out, "  %s\n", s)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const mem0Export = `{"results": [
+  {"id": "m1", "memory": "Prefers window seats", "hash": "abc", "user_id": "alice@example.com",
+   "metadata": {"source": "chat"}, "categories": ["travel", "preferences"],
+   "created_at": "2024-07-01T10:00:00Z", "score": 0.9},
+  {"id": "m2", "memory": "", "user_id": "alice@example.com"},
+  {"id": "m3", "memory": "Allergic to peanuts", "user_id": "bob"}
+]}`
+
+const zepExport = `{"sessions": [{
+  "session_id": "s-1", "user_id": "alice", "summary": {"content": "travel chat"},
+  "messages": [
+    {"uuid": "u1", "role": "Alice", "role_type": "user", "content": "Book me a flight", "token_count": 5},
+    {"uuid": "u2", "role_type": "assistant", "content": "Where to?", "metadata": {"lang": "en"}}
+  ]
+}]}`
+
+const langchainExport = `[
+  {"type": "human", "data": {"content": "hi", "additional_kwargs": {}, "type": "human", "example": false, "id": null}},
+  {"type": "ai", "data": {"content": "hello", "additional_kwargs": {"tool_calls": [1]}, "response_metadata": {}}},
+  {"type": "ai", "data": {"content": [{"type": "image_url"}]}}
+]`
+
+func TestParseMem0(t *testing.T) {
+	rep := newImportReport()
+	recs, err := parseMem0([]byte(mem0Export), rep)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(recs) != 2 || len(rep.skipped) != 1 {
+		t.Fatalf("expected 2 records and 1 skip, got %d and %v", len(recs), rep.skipped)
+	}
+	e := recs[0].entry
+	if e.RawEntry != "Prefers window seats" || e.Summary != e.RawEntry || e.SourceID != "m1" {
+		t.Fatalf("unexpected entry: %+v", e)
+	}
+	if e.Tags["categories"] != "travel,preferences" || e.Metadata["source"] != "chat" || e.Metadata["createdAt"] != "2024-07-01T10:00:00Z" {
+		t.Fatalf("unexpected tags/metadata: %+v %+v", e.Tags, e.Metadata)
+	}
+	if rep.dropped["score"] != 1 || rep.dropped["hash"] != 0 {
+		t.Fatalf("unexpected dropped: %v", rep.dropped)
+	}
+	if got := importTitle("mem0", recs[0].memoryKey); got != "mem0-alice-example-com" {
+		t.Fatalf("title = %q", got)
+	}
+}
+
+func TestParseZep(t *testing.T) {
+	rep := newImportReport()
+	recs, err := parseZep([]byte(zepExport), rep)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(recs) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(recs))
+	}
+	if e := recs[0].entry; e.RawEntry != "Alice: Book me a flight" || e.SessionID != "s-1" || e.SourceID != "u1" || e.Tags["role"] != "user" {
+		t.Fatalf("unexpected entry: %+v", e)
+	}
+	if e := recs[1].entry; e.RawEntry != "assistant: Where to?" || e.Metadata["lang"] != "en" {
+		t.Fatalf("unexpected entry: %+v", e)
+	}
+	if recs[0].memoryKey != "alice" || rep.dropped["session.summary"] != 1 || len(rep.dropped) != 1 {
+		t.Fatalf("unexpected key/dropped: %q %v", recs[0].memoryKey, rep.dropped)
+	}
+}
+
+func TestParseLangChain(t *testing.T) {
+	rep := newImportReport()
+	recs, err := parseLangChain([]byte(`{"chat_memory": {"messages": `+langchainExport+`}}`), rep)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(recs) != 2 || len(rep.skipped) != 1 {
+		t.Fatalf("expected 2 records and 1 skip, got %d and %v", len(recs), rep.skipped)
+	}
+	if e := recs[1].entry; e.RawEntry != "ai: hello" || e.Tags["role"] != "ai" {
+		t.Fatalf("unexpected entry: %+v", e)
+	}
+	if rep.dropped["data.additional_kwargs"] != 1 || len(rep.dropped) != 1 {
+		t.Fatalf("unexpected dropped: %v", rep.dropped)
+	}
+}
+
+func TestImportTitle(t *testing.T) {
+	if got := importTitle("langchain", ""); got != "langchain-import" {
+		t.Fatalf("title = %q", got)
+	}
+	if got := importTitle("zep", strings.Repeat("x", 80)); len(got) != 50 {
+		t.Fatalf("title not truncated: %q", got)
+	}
+}
+
+func TestCLI_Import(t *testing.T) {
+	var mu sync.Mutex
+	var entries []map[string]interface{}
+	created := map[string]bool{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v0/vaults/v1/memories", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"memories": []map[string]string{{"memoryId": "existing", "title": "mem0-bob", "vaultId": "v1"}},
+			})
+			return
+		}
+		var req map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		mu.Lock()
+		created[req["title"]] = true
+		mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]string{"memoryId": "new", "title": req["title"], "vaultId": "v1"})
+	})
+	mux.HandleFunc("/v0/ingestion-batches", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]string{"batchId": "b-1", "sourceSystem": "mem0", "status": "open"})
+	})
+	mux.HandleFunc("/v0/vaults/v1/memories/", func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		req["path"] = r.URL.Path
+		mu.Lock()
+		entries = append(entries, req)
+		mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]string{"entryId": "e"})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	file := filepath.Join(t.TempDir(), "mem0.json")
+	if err := os.WriteFile(file, []byte(mem0Export), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	b := &strings.Builder{}
+	root := NewRootCmd()
+	root.SetOut(b)
+	root.SetArgs([]string{"import", "--service-url", srv.URL, "--format", "mem0", "--file", file, "--vault-id", "v1"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("import failed: %v\n%s", err, b.String())
+	}
+	out := b.String()
+	if !strings.Contains(out, "Ingestion batch: b-1") || !strings.Contains(out, "Imported 2 entries into 2 memories") ||
+		!strings.Contains(out, "score") || !strings.Contains(out, "Skipped 1 records") {
+		t.Fatalf("unexpected output:\n%s", out)
+	}
+	if !created["mem0-alice-example-com"] || created["mem0-bob"] {
+		t.Fatalf("unexpected memories created: %v", created)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	for _, e := range entries {
+		if e["ingestionBatchId"] != "b-1" || e["sourceSystem"] != "mem0" {
+			t.Fatalf("entry missing provenance: %v", e)
+		}
+	}
+}
@@ -0,0 +1,395 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mycelian/mycelian-memory/client"
+)
+
+// maxRawEntryLen mirrors the server's rawEntry limit; longer records are skipped.
+const maxRawEntryLen = 9000
+
+// maxImportSummaryLen bounds the summary derived from a record's text.
+const maxImportSummaryLen = 200
+
+// importRecord is one foreign memory record mapped onto an entry. memoryKey
+// groups records into memories (the source user or session); empty when the
+// export carries none.
+type importRecord struct {
+	memoryKey string
+	entry     client.AddEntryRequest
+}
+
+// importReport tallies what the adapter could not carry over.
+type importReport struct {
+	// dropped counts non-empty source fields with no Mycelian equivalent, keyed by field path.
+	dropped map[string]int
+	// skipped lists records that could not be mapped, with the reason.
+	skipped []string
+}
+
+func newImportReport() *importReport {
+	return &importReport{dropped: map[string]int{}}
+}
+
+// drop records every non-empty field left in m under prefix.
+func (r *importReport) drop(prefix string, m map[string]interface{}) {
+	for k, v := range m {
+		if isEmptyValue(v) {
+			continue
+		}
+		r.dropped[prefix+k]++
+	}
+}
+
+func (r *importReport) skip(format string, args ...interface{}) {
+	r.skipped = append(r.skipped, fmt.Sprintf(format, args...))
+}
+
+// droppedFields returns the dropped field paths sorted by name.
+func (r *importReport) droppedFields() []string {
+	fields := make([]string, 0, len(r.dropped))
+	for f := range r.dropped {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// importer parses one export format.
+type importer func(data []byte, rep *importReport) ([]importRecord, error)
+
+// importers maps --format values to their adapters.
+var importers = map[string]importer{
+	"mem0":         parseMem0,
+	"zep":          parseZep,
+	"langchain":    parseLangChain,
+	mycelianFormat: parseMycelian,
+}
+
+func importFormats() []string {
+	names := make([]string, 0, len(importers))
+	for n := range importers {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// parseMem0 reads the output of Mem0's get_all: either {"results": [...]} or
+// a bare array of memories. Each memory becomes one entry whose raw text and
+// summary are the extracted fact; records are grouped by user_id.
+func parseMem0(data []byte, rep *importReport) ([]importRecord, error) {
+	items, err := decodeList(data, "results", "memories")
+	if err != nil {
+		return nil, fmt.Errorf("mem0: %w", err)
+	}
+	var out []importRecord
+	for i, m := range items {
+		text := takeString(m, "memory")
+		if text == "" {
+			text = takeString(m, "text")
+		}
+		if text == "" {
+			rep.skip("mem0 record %d: no memory text", i)
+			rep.drop("", m)
+			continue
+		}
+		rec := importRecord{memoryKey: takeString(m, "user_id")}
+		e := &rec.entry
+		e.RawEntry = text
+		e.Summary = summaryOf(text)
+		e.SourceID = takeString(m, "id")
+		e.SessionID = takeString(m, "run_id")
+		e.Metadata = takeObject(m, "metadata")
+		setMeta(&e.Metadata, "agentId", takeString(m, "agent_id"))
+		setMeta(&e.Metadata, "createdAt", takeString(m, "created_at"))
+		setMeta(&e.Metadata, "updatedAt", takeString(m, "updated_at"))
+		if cats := takeStrings(m, "categories"); len(cats) > 0 {
+			e.Tags = map[string]string{"categories": strings.Join(cats, ",")}
+		}
+		delete(m, "hash") // Mem0's dedupe hash of the text
+		rep.drop("", m)
+		out = append(out, rec)
+	}
+	return out, nil
+}
+
+// parseZep reads exported Zep sessions: {"sessions": [...]}, a bare array of
+// sessions or a single session object, each holding "messages". Every message
+// becomes one entry in the session's conversation; records are grouped by
+// user_id, falling back to session_id.
+func parseZep(data []byte, rep *importReport) ([]importRecord, error) {
+	sessions, err := decodeList(data, "sessions")
+	if err != nil {
+		return nil, fmt.Errorf("zep: %w", err)
+	}
+	var out []importRecord
+	for si, s := range sessions {
+		sessionID := takeString(s, "session_id")
+		key := takeString(s, "user_id")
+		if key == "" {
+			key = sessionID
+		}
+		msgs := takeList(s, "messages")
+		if msgs == nil {
+			rep.skip("zep session %d: no messages", si)
+		}
+		for mi, m := range msgs {
+			content := takeString(m, "content")
+			if content == "" {
+				rep.skip("zep session %q message %d: no content", sessionID, mi)
+				rep.drop("messages.", m)
+				continue
+			}
+			roleType := takeString(m, "role_type")
+			role := takeString(m, "role")
+			if role == "" {
+				role = roleType
+			}
+			rec := importRecord{memoryKey: key}
+			e := &rec.entry
+			e.RawEntry = labelled(role, content)
+			e.Summary = summaryOf(e.RawEntry)
+			e.SourceID = takeString(m, "uuid")
+			e.SessionID = sessionID
+			e.Metadata = takeObject(m, "metadata")
+			setMeta(&e.Metadata, "createdAt", takeString(m, "created_at"))
+			if roleType != "" {
+				e.Tags = map[string]string{"role": roleType}
+			}
+			delete(m, "token_count")
+			rep.drop("messages.", m)
+			out = append(out, rec)
+		}
+		delete(s, "uuid")
+		rep.drop("session.", s)
+	}
+	return out, nil
+}
+
+// parseLangChain reads messages serialised by LangChain's messages_to_dict:
+// a bare array of {"type", "data"} objects, or the same under "messages" or
+// "chat_memory.messages". LangChain exports carry no user, so all records
+// share one memory.
+func parseLangChain(data []byte, rep *importReport) ([]importRecord, error) {
+	var root interface{}
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("langchain: %w", err)
+	}
+	if obj, ok := root.(map[string]interface{}); ok {
+		if cm, ok := obj["chat_memory"].(map[string]interface{}); ok {
+			root = cm
+		}
+	}
+	msgs, err := objectList(root, "messages")
+	if err != nil {
+		return nil, fmt.Errorf("langchain: %w", err)
+	}
+	var out []importRecord
+	for i, m := range msgs {
+		typ := takeString(m, "type")
+		body := takeObject(m, "data")
+		flat := body == nil
+		if flat {
+			body = m // message already flattened
+		}
+		content := takeString(body, "content")
+		if content == "" {
+			rep.skip("langchain message %d: content missing or not text", i)
+			continue
+		}
+		if t := takeString(body, "type"); typ == "" {
+			typ = t
+		}
+		var rec importRecord
+		e := &rec.entry
+		e.RawEntry = labelled(typ, content)
+		e.Summary = summaryOf(e.RawEntry)
+		e.SourceID = takeString(body, "id")
+		setMeta(&e.Metadata, "name", takeString(body, "name"))
+		if typ != "" {
+			e.Tags = map[string]string{"role": typ}
+		}
+		delete(body, "example")
+		if !flat {
+			rep.drop("data.", body)
+		}
+		rep.drop("", m)
+		out = append(out, rec)
+	}
+	return out, nil
+}
+
+// decodeList accepts a JSON array of objects, an object holding the array
+// under one of keys, or a single object.
+func decodeList(data []byte, keys ...string) ([]map[string]interface{}, error) {
+	var root interface{}
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	return objectList(root, keys...)
+}
+
+func objectList(root interface{}, keys ...string) ([]map[string]interface{}, error) {
+	if obj, ok := root.(map[string]interface{}); ok {
+		root = obj
+		for _, k := range keys {
+			if v, ok := obj[k]; ok {
+				root = v
+				break
+			}
+		}
+	}
+	switch v := root.(type) {
+	case []interface{}:
+		out := make([]map[string]interface{}, 0, len(v))
+		for i, item := range v {
+			m, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("item %d is not an object", i)
+			}
+			out = append(out, m)
+		}
+		return out, nil
+	case map[string]interface{}:
+		return []map[string]interface{}{v}, nil
+	default:
+		return nil, fmt.Errorf("expected an object or array, got %T", root)
+	}
+}
+
+func takeString(m map[string]interface{}, key string) string {
+	v, ok := m[key]
+	if !ok {
+		return ""
+	}
+	switch s := v.(type) {
+	case string:
+		delete(m, key)
+		return s
+	case nil:
+		delete(m, key)
+	case float64, bool:
+		delete(m, key)
+		return fmt.Sprint(s)
+	}
+	return "" // left in place so the report shows it
+}
+
+func takeObject(m map[string]interface{}, key string) map[string]interface{} {
+	if obj, ok := m[key].(map[string]interface{}); ok {
+		delete(m, key)
+		if len(obj) == 0 {
+			return nil
+		}
+		return obj
+	}
+	return nil
+}
+
+func takeList(m map[string]interface{}, key string) []map[string]interface{} {
+	arr, ok := m[key].([]interface{})
+	if !ok {
+		return nil
+	}
+	delete(m, key)
+	out := make([]map[string]interface{}, 0, len(arr))
+	for _, item := range arr {
+		if obj, ok := item.(map[string]interface{}); ok {
+			out = append(out, obj)
+		}
+	}
+	return out
+}
+
+func takeStrings(m map[string]interface{}, key string) []string {
+	arr, ok := m[key].([]interface{})
+	if !ok {
+		return nil
+	}
+	delete(m, key)
+	var out []string
+	for _, item := range arr {
+		if s, ok := item.(string); ok && s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func setMeta(meta *map[string]interface{}, key, val string) {
+	if val == "" {
+		return
+	}
+	if *meta == nil {
+		*meta = map[string]interface{}{}
+	}
+	(*meta)[key] = val
+}
+
+func isEmptyValue(v interface{}) bool {
+	switch x := v.(type) {
+	case nil:
+		return true
+	case string:
+		return x == ""
+	case bool:
+		return !x
+	case []interface{}:
+		return len(x) == 0
+	case map[string]interface{}:
+		return len(x) == 0
+	}
+	return false
+}
+
+// checkLengths skips records whose text exceeds the server's rawEntry limit.
+func checkLengths(recs []importRecord, rep *importReport) []importRecord {
+	out := recs[:0]
+	for _, r := range recs {
+		if len(r.entry.RawEntry) > maxRawEntryLen {
+			rep.skip("record %q: text exceeds %d characters", r.entry.SourceID, maxRawEntryLen)
+			continue
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// labelled prefixes conversation turns with their speaker.
+func labelled(role, content string) string {
+	if role == "" {
+		return content
+	}
+	return role + ": " + content
+}
+
+// summaryOf derives the required entry summary from the record text.
+func summaryOf(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	r := []rune(text)
+	if len(r) <= maxImportSummaryLen {
+		return text
+	}
+	return string(r[:maxImportSummaryLen-3]) + "..."
+}
+
+var nonTitleRx = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// importTitle builds a memory title the server accepts (letters, digits and
+// hyphens, at most 50 bytes) from the format and grouping key.
+func importTitle(format, key string) string {
+	if key == "" {
+		key = "import"
+	}
+	t := strings.Trim(nonTitleRx.ReplaceAllString(format+"-"+key, "-"), "-")
+	if len(t) > 50 {
+		t = strings.TrimRight(t[:50], "-")
+	}
+	return t
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mycelian/mycelian-memory/client"
+	"github.com/mycelian/mycelian-memory/client/credentials"
+	"github.com/spf13/cobra"
+)
+
+// profile names the stored credentials to use (--profile or MYCELIAN_PROFILE);
+// empty means the default profile.
+var profile string
+
+// serviceURLSet reports whether --service-url or MEMORY_SERVICE_URL was given,
+// in which case it takes precedence over the profile's service URL.
+var serviceURLSet bool
+
+// resolveAPIKey returns the API key for requests, from MYCELIAN_API_KEY or
+// the selected profile, and points serviceURL at the profile's service unless
+// one was given explicitly. It refuses to send a profile's key to a service
+// given explicitly that is not the profile's. An empty key means no key is
+// configured.
+func resolveAPIKey() (string, error) {
+	url, key, err := credentials.Resolve(profile)
+	if err != nil {
+		return "", err
+	}
+	if url != "" && !serviceURLSet {
+		serviceURL = url
+	}
+	if err := credentials.CheckServiceURL(url, serviceURL); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+func newLoginCmd() *cobra.Command {
+	var apiKey string
+	var noVerify, makeDefault bool
+
+	cmd := &cobra.Command{
+		Use:   "login",
+		Short: "Store an API key for --service-url under a profile",
+		Long: `Login verifies an API key against the service and stores it under --profile
+(MYCELIAN_PROFILE or the default profile when unset): in the OS keyring when one is available, otherwise
+encrypted in the config directory. Later commands use it in place of
+MYCELIAN_API_KEY. The key is read from stdin unless --api-key is given, so it
+stays out of shell history:
+
+  mycelianCli login --profile work --service-url https://memory.example.com < key.txt`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := credentials.Open("")
+			if err != nil {
+				return err
+			}
+			name, err := s.ProfileName(profile)
+			if err != nil {
+				return err
+			}
+			// Replacing an unnamed profile's service could hand its next
+			// commands to the wrong server; that takes an explicit --profile.
+			if profile == "" {
+				if p, err := s.Load(name); err == nil {
+					if err := credentials.CheckServiceURL(p.ServiceURL, serviceURL); err != nil {
+						return fmt.Errorf("%w (pass --profile %s to re-point it)", err, name)
+					}
+				}
+			}
+			key, err := readAPIKey(cmd, apiKey)
+			if err != nil {
+				return err
+			}
+			if !noVerify {
+				if err := verifyAPIKey(cmd.Context(), key); err != nil {
+					return err
+				}
+			}
+			if err := s.Save(credentials.Profile{Name: name, ServiceURL: serviceURL, APIKey: key}); err != nil {
+				return err
+			}
+			if makeDefault {
+				if err := s.SetDefault(name); err != nil {
+					return err
+				}
+			}
+			p, err := s.Load(name)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Saved profile %s for %s (%s)\n", name, serviceURL, p.Backend)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&apiKey, "api-key", "", "API key to store (read from stdin when unset)")
+	cmd.Flags().BoolVar(&noVerify, "no-verify", false, "Store the key without checking it against the service")
+	cmd.Flags().BoolVar(&makeDefault, "default", false, "Make the profile the default")
+
+	return cmd
+}
+
+func newRotateKeyCmd() *cobra.Command {
+	var apiKey string
+	var noVerify bool
+
+	cmd := &cobra.Command{
+		Use:   "rotate-key",
+		Short: "Replace the API key stored in a profile",
+		Long: `Rotate-key verifies a new API key against the profile's service and only then
+replaces the stored key, so a bad key never locks the profile out. Revoke the
+old key on the service afterwards.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := credentials.Open("")
+			if err != nil {
+				return err
+			}
+			name, err := s.ProfileName(profile)
+			if err != nil {
+				return err
+			}
+			p, err := s.Load(name)
+			if err != nil {
+				return err
+			}
+			if p.ServiceURL != "" && !serviceURLSet {
+				serviceURL = p.ServiceURL
+			}
+			if err := credentials.CheckServiceURL(p.ServiceURL, serviceURL); err != nil {
+				return err
+			}
+			key, err := readAPIKey(cmd, apiKey)
+			if err != nil {
+				return err
+			}
+			if key == p.APIKey {
+				return errors.New("the new API key is the one already stored")
+			}
+			if !noVerify {
+				if err := verifyAPIKey(cmd.Context(), key); err != nil {
+					return err
+				}
+			}
+			if err := s.Rotate(name, key); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Rotated API key of profile %s\n", name)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&apiKey, "api-key", "", "New API key (read from stdin when unset)")
+	cmd.Flags().BoolVar(&noVerify, "no-verify", false, "Store the key without checking it against the service")
+
+	return cmd
+}
+
+func newLogoutCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "logout",
+		Short: "Delete a stored profile and its API key",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := credentials.Open("")
+			if err != nil {
+				return err
+			}
+			name, err := s.ProfileName(profile)
+			if err != nil {
+				return err
+			}
+			if err := s.Delete(name); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Deleted profile %s\n", name)
+			return nil
+		},
+	}
+}
+
+func newListProfilesCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list-profiles",
+		Short: "List stored profiles (without their keys)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := credentials.Open("")
+			if err != nil {
+				return err
+			}
+			profiles, def, err := s.List()
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			if len(profiles) == 0 {
+				fmt.Fprintln(out, "No profiles; store one with mycelianCli login")
+				return nil
+			}
+			for _, p := range profiles {
+				mark := " "
+				if p.Name == def {
+					mark = "*"
+				}
+				rotated := "never"
+				if p.RotatedAt != nil {
+					rotated = p.RotatedAt.Local().Format(time.DateTime)
+				}
+				fmt.Fprintf(out, "%s %s\t%s\t%s\trotated %s\n", mark, p.Name, p.ServiceURL, p.Backend, rotated)
+			}
+			return nil
+		},
+	}
+}
+
+func newUseProfileCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "use-profile NAME",
+		Short: "Make a stored profile the default",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := credentials.Open("")
+			if err != nil {
+				return err
+			}
+			if err := s.SetDefault(args[0]); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Default profile is now %s\n", args[0])
+			return nil
+		},
+	}
+}
+
+// readAPIKey returns flagValue, or the first line of stdin.
+func readAPIKey(cmd *cobra.Command, flagValue string) (string, error) {
+	if flagValue != "" {
+		return flagValue, nil
+	}
+	in := cmd.InOrStdin()
+	if f, ok := in.(*os.File); ok {
+		if st, err := f.Stat(); err == nil && st.Mode()&os.ModeCharDevice != 0 {
+			fmt.Fprint(cmd.ErrOrStderr(), "API key: ")
+		}
+	}
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	key := strings.TrimSpace(line)
+	if key == "" {
+		return "", errors.New("no API key given: pass --api-key or write it to stdin")
+	}
+	return key, nil
+}
+
+// verifyAPIKey checks that the service at serviceURL accepts key.
+func verifyAPIKey(ctx context.Context, key string) error {
+	c, err := client.New(serviceURL, client.WithAPIKey(key))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = c.Close() }()
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	if _, err := c.ListVaults(ctx); err != nil {
+		if client.IsUnauthorized(err) {
+			return fmt.Errorf("%s rejected the API key (use --no-verify to store it anyway)", serviceURL)
+		}
+		return fmt.Errorf("verify API key against %s: %w", serviceURL, err)
+	}
+	return nil
+}
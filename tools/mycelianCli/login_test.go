@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestCLI_LoginRotateLogout(t *testing.T) {
+	t.Setenv("MYCELIAN_CONFIG_DIR", t.TempDir())
+	t.Setenv("MYCELIAN_KEYRING", "file")
+	t.Setenv("MYCELIAN_API_KEY", "")
+	t.Setenv("MYCELIAN_PROFILE", "")
+	t.Setenv("MEMORY_SERVICE_URL", "")
+
+	var mu sync.Mutex
+	valid := map[string]bool{"Bearer sk_one": true, "Bearer sk_two": true}
+	var lastAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		lastAuth = r.Header.Get("Authorization")
+		ok := valid[lastAuth]
+		mu.Unlock()
+		if !ok {
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "unauthorized"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"vaults": []interface{}{}, "count": 0})
+	}))
+	defer srv.Close()
+
+	run := func(stdin string, args ...string) (string, error) {
+		b := &strings.Builder{}
+		root := NewRootCmd()
+		root.SetOut(b)
+		root.SetErr(b)
+		root.SetIn(strings.NewReader(stdin))
+		root.SetArgs(args)
+		err := root.Execute()
+		return b.String(), err
+	}
+
+	if _, err := run("sk_bad\n", "login", "--profile", "work", "--service-url", srv.URL); err == nil || !strings.Contains(err.Error(), "rejected") {
+		t.Fatalf("login with a bad key: %v", err)
+	}
+	if out, err := run("sk_one\n", "login", "--profile", "work", "--service-url", srv.URL); err != nil || !strings.Contains(out, "Saved profile work") {
+		t.Fatalf("login: %v\n%s", err, out)
+	}
+	// Later commands use the default profile's key and service URL.
+	if _, err := run("", "list-vaults"); err != nil || lastAuth != "Bearer sk_one" {
+		t.Fatalf("list-vaults with profile: %v (auth %q)", err, lastAuth)
+	}
+	if out, err := run("", "list-profiles"); err != nil || !strings.Contains(out, "* work\t"+srv.URL+"\tfile\trotated never") {
+		t.Fatalf("list-profiles: %v\n%s", err, out)
+	}
+
+	if _, err := run("", "rotate-key", "--profile", "work", "--api-key", "sk_bad"); err == nil {
+		t.Fatal("rotate-key accepted a rejected key")
+	}
+	if _, err := run("", "list-vaults"); err != nil || lastAuth != "Bearer sk_one" {
+		t.Fatalf("failed rotation replaced the key: %v (auth %q)", err, lastAuth)
+	}
+	// Without --profile the stored default profile is the one rotated.
+	if _, err := run("sk_two\n", "rotate-key"); err != nil {
+		t.Fatalf("rotate-key: %v", err)
+	}
+	if _, err := run("", "list-vaults"); err != nil || lastAuth != "Bearer sk_two" {
+		t.Fatalf("list-vaults after rotation: %v (auth %q)", err, lastAuth)
+	}
+
+	// The profile's key is not sent to a service other than its own.
+	if _, err := run("", "list-vaults", "--service-url", "http://127.0.0.1:1"); err == nil || !strings.Contains(err.Error(), "not http://127.0.0.1:1") {
+		t.Fatalf("list-vaults with another service URL: %v", err)
+	}
+	if _, err := run("sk_three\n", "login", "--service-url", "http://127.0.0.1:1", "--no-verify"); err == nil {
+		t.Fatal("login re-pointed the default profile without --profile")
+	}
+
+	if out, err := run("", "logout"); err != nil || !strings.Contains(out, "Deleted profile work") {
+		t.Fatalf("logout: %v\n%s", err, out)
+	}
+	if _, err := run("", "list-vaults", "--profile", "work"); err == nil {
+		t.Fatal("expected a deleted profile to fail")
+	}
+}
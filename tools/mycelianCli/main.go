@@ -20,6 +20,7 @@ import (
 
 var serviceURL string
 var debug bool
+var devMode bool
 
 const maxClientLimit = 50
 const defaultTopK = 10
@@ -61,28 +62,52 @@ func NewRootCmd() *cobra.Command {
 			} else {
 				zerolog.SetGlobalLevel(zerolog.InfoLevel)
 			}
+			serviceURLSet = cmd.Flags().Changed("service-url") || os.Getenv("MEMORY_SERVICE_URL") != ""
 		},
 	}
 
 	defaultURL := getEnv("MEMORY_SERVICE_URL", "http://localhost:11545")
 	rootCmd.PersistentFlags().StringVar(&serviceURL, "service-url", defaultURL, "Base URL of Mycelian memory service")
 	rootCmd.PersistentFlags().BoolVarP(&debug, "debug", "d", false, "Enable verbose debug output")
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", getEnv("MYCELIAN_PROFILE", ""), "Stored credentials profile to use (see login); defaults to the default profile")
+	rootCmd.PersistentFlags().BoolVar(&devMode, "dev-mode", client.DevModeRequested(), "Authenticate with the shared dev key when no API key is configured (or set MYCELIAN_DEV_MODE=1); only a dev-mode server accepts it")
+	rootCmd.PersistentFlags().StringVar(&daemonSocket, "daemon-socket", getEnv("MYCELIAN_DAEMON_SOCKET", ""), "Unix socket of a running mycelianCli daemon to send requests through")
 
 	// Sub-commands
 	rootCmd.AddCommand(newCreateMemoryCmd())
 	rootCmd.AddCommand(newCreateVaultCmd())
 	rootCmd.AddCommand(newListVaultsCmd())
+	rootCmd.AddCommand(newListVaultTemplatesCmd())
 	rootCmd.AddCommand(newGetVaultCmd())
 	rootCmd.AddCommand(newListMemoriesCmd())
 	rootCmd.AddCommand(newDeleteVaultCmd())
+	rootCmd.AddCommand(newSetVaultReadOnlyCmd())
+	rootCmd.AddCommand(newUpdateVaultCmd())
+	rootCmd.AddCommand(newUpdateMemoryCmd())
+	rootCmd.AddCommand(newVaultStatsCmd())
 	rootCmd.AddCommand(newCreateEntryCmd())
 	rootCmd.AddCommand(newListEntriesCmd())
+	rootCmd.AddCommand(newScanEntriesCmd())
+	rootCmd.AddCommand(newDeleteEntryCmd())
 	rootCmd.AddCommand(newGetPromptsCmd())
 	rootCmd.AddCommand(newPutContextCmd())
 	rootCmd.AddCommand(newGetContextCmd())
+	rootCmd.AddCommand(newDeleteContextCmd())
 	rootCmd.AddCommand(newSearchCmd())
+	rootCmd.AddCommand(newExplainSearchCmd())
+	rootCmd.AddCommand(newExportSearchLogCmd())
 	rootCmd.AddCommand(newGetToolsSchemaCmd())
 	rootCmd.AddCommand(newAwaitConsistencyCmd())
+	rootCmd.AddCommand(newExportCmd())
+	rootCmd.AddCommand(newImportCmd())
+	rootCmd.AddCommand(newDoctorCmd())
+	rootCmd.AddCommand(newVerifyPipelineCmd())
+	rootCmd.AddCommand(newDaemonCmd())
+	rootCmd.AddCommand(newLoginCmd())
+	rootCmd.AddCommand(newRotateKeyCmd())
+	rootCmd.AddCommand(newLogoutCmd())
+	rootCmd.AddCommand(newListProfilesCmd())
+	rootCmd.AddCommand(newUseProfileCmd())
 
 	return rootCmd
 }
@@ -104,7 +129,7 @@ func newCreateMemoryCmd() *cobra.Command {
 				Str("service_url", serviceURL).
 				Msg("creating memory")
 
-			c, err := client.NewWithDevMode(serviceURL)
+			c, err := newClient()
 			if err != nil {
 				return err
 			}
@@ -157,7 +182,7 @@ func newCreateMemoryCmd() *cobra.Command {
 }
 
 func newCreateEntryCmd() *cobra.Command {
-	var vaultID, memoryID, rawEntry, summary string
+	var vaultID, memoryID, rawEntry, summary, sessionID string
 
 	cmd := &cobra.Command{
 		Use:   "create-entry",
@@ -173,18 +198,19 @@ func newCreateEntryCmd() *cobra.Command {
 				Str("service_url", serviceURL).
 				Msg("creating entry")
 
-			c, err := client.NewWithDevMode(serviceURL)
+			c, err := newClient()
 			if err != nil {
 				return err
 			}
 			ctx, cancel := context.WithTimeout(cmd.Context(), 15*time.Second)
 			defer cancel()
-			defer func() { _ = c.Close() }() // Ensure queues are drained before context is cancelled
+			defer func() { _ = c.Close() }() // No-op once flushWrites has run
 
 			start := time.Now()
 			ack, err := c.AddEntry(ctx, vaultID, memoryID, client.AddEntryRequest{
-				RawEntry: rawEntry,
-				Summary:  summary,
+				RawEntry:  rawEntry,
+				Summary:   summary,
+				SessionID: sessionID,
 			})
 			elapsed := time.Since(start)
 
@@ -208,7 +234,7 @@ func newCreateEntryCmd() *cobra.Command {
 			dbg(ack)
 			fmt.Println("Entry enqueued")
 
-			return nil
+			return flushWrites(ctx, c)
 		},
 	}
 
@@ -216,6 +242,7 @@ func newCreateEntryCmd() *cobra.Command {
 	cmd.Flags().StringVar(&memoryID, "memory-id", "", "Memory ID (required)")
 	cmd.Flags().StringVar(&rawEntry, "raw-entry", "", "Raw entry text (required)")
 	cmd.Flags().StringVar(&summary, "summary", "", "Summary (required)")
+	cmd.Flags().StringVar(&sessionID, "session-id", "", "Conversation session the entry belongs to (optional)")
 
 	_ = cmd.MarkFlagRequired("vault-id")
 	_ = cmd.MarkFlagRequired("memory-id")
@@ -226,44 +253,79 @@ func newCreateEntryCmd() *cobra.Command {
 }
 
 func newListEntriesCmd() *cobra.Command {
-	var vaultID, memoryID string
+	var vaultID, memoryID, pageToken string
 	var limit int
+	var all bool
+	var tagFlags []string
 
 	cmd := &cobra.Command{
 		Use:   "list-entries",
 		Short: "List entries for a memory",
+		Long: `List entries for a memory, newest first.
+
+A full page prints nextPageToken; pass it with --page-token for the next page,
+or use --all to walk every page and print the entries together. Repeat
+--tag key=value to list only entries holding all those tags.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Client-side validation removed; rely on server-side validation
+			tags, err := parseTagFlags(tagFlags)
+			if err != nil {
+				return err
+			}
 
 			log.Debug().
 				Str("vault_id", vaultID).
 				Str("memory_id", memoryID).
 				Int("limit", limit).
+				Bool("all", all).
 				Str("service_url", serviceURL).
 				Msg("listing entries")
 
-			c, err := client.NewWithDevMode(serviceURL)
+			c, err := newClient()
 			if err != nil {
 				return err
 			}
-			ctx, cancel := context.WithTimeout(cmd.Context(), 15*time.Second)
-			defer cancel()
 
 			limit = applyUpperBoundToLimit(limit)
 
 			start := time.Now()
-			resp, err := c.ListEntries(ctx, vaultID, memoryID, map[string]string{"limit": strconv.Itoa(limit)})
-			elapsed := time.Since(start)
-
-			if err != nil {
-				log.Error().
-					Err(err).
-					Str("vault_id", vaultID).
-					Str("memory_id", memoryID).
-					Dur("elapsed", elapsed).
-					Msg("list entries failed")
-				return err
+			var resp *client.ListEntriesResponse
+			for pages := 0; ; pages++ {
+				params := map[string]string{"limit": strconv.Itoa(limit)}
+				if pageToken != "" {
+					params["pageToken"] = pageToken
+				}
+				ctx, cancel := context.WithTimeout(cmd.Context(), 15*time.Second)
+				var page *client.ListEntriesResponse
+				if len(tags) > 0 {
+					page, err = c.ListTaggedEntries(ctx, vaultID, memoryID, tags, params)
+				} else {
+					page, err = c.ListEntries(ctx, vaultID, memoryID, params)
+				}
+				cancel()
+				if err != nil {
+					log.Error().
+						Err(err).
+						Str("vault_id", vaultID).
+						Str("memory_id", memoryID).
+						Int("pages", pages).
+						Dur("elapsed", time.Since(start)).
+						Msg("list entries failed")
+					return err
+				}
+				if resp == nil {
+					resp = page
+				} else {
+					resp.Entries = append(resp.Entries, page.Entries...)
+					resp.Count = len(resp.Entries)
+					resp.NextPageToken = page.NextPageToken
+				}
+				if !all || page.NextPageToken == "" {
+					break
+				}
+				pageToken = page.NextPageToken
 			}
+			elapsed := time.Since(start)
 
 			log.Debug().
 				Str("vault_id", vaultID).
@@ -278,23 +340,165 @@ func newListEntriesCmd() *cobra.Command {
 			// Output full JSON so automated callers (benchmark harness, CI scripts)
 			// can parse the response without needing the Go client types.
 			b, _ := json.MarshalIndent(resp, "", "  ")
-			fmt.Println(string(b))
+			fmt.Fprintln(cmd.OutOrStdout(), string(b))
 			return nil
 		},
 	}
 
 	cmd.Flags().StringVar(&vaultID, "vault-id", "", "Vault ID (required)")
 	cmd.Flags().StringVar(&memoryID, "memory-id", "", "Memory ID (required)")
-	cmd.Flags().IntVar(&limit, "limit", 25, "Number of entries to return (max 50)")
+	cmd.Flags().IntVar(&limit, "limit", 25, "Number of entries to return per page (max 50)")
+	cmd.Flags().StringVar(&pageToken, "page-token", "", "nextPageToken of the previous page")
+	cmd.Flags().BoolVar(&all, "all", false, "Follow nextPageToken and print every entry of the memory")
+	cmd.Flags().StringArrayVar(&tagFlags, "tag", nil, "Only entries with this tag, as key=value (repeatable)")
+
+	_ = cmd.MarkFlagRequired("vault-id")
+	_ = cmd.MarkFlagRequired("memory-id")
+
+	return cmd
+}
+
+func newScanEntriesCmd() *cobra.Command {
+	var vaultID, memoryID string
+	var req client.ScanEntriesRequest
+
+	cmd := &cobra.Command{
+		Use:   "scan-entries",
+		Short: "Find entries by exact substring or regex, bypassing the search index",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Debug().
+				Str("vault_id", vaultID).
+				Str("memory_id", memoryID).
+				Str("contains", req.Contains).
+				Str("regex", req.Regex).
+				Msg("scanning entries")
+
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := context.WithTimeout(cmd.Context(), 30*time.Second)
+			defer cancel()
+
+			resp, err := c.ScanEntries(ctx, vaultID, memoryID, req)
+			if err != nil {
+				return err
+			}
+			b, _ := json.MarshalIndent(resp, "", "  ")
+			fmt.Fprintln(cmd.OutOrStdout(), string(b))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&vaultID, "vault-id", "", "Vault ID (required)")
+	cmd.Flags().StringVar(&memoryID, "memory-id", "", "Memory ID (required)")
+	cmd.Flags().StringVar(&req.Contains, "contains", "", "Case-insensitive substring to find")
+	cmd.Flags().StringVar(&req.Regex, "regex", "", "POSIX regular expression to match")
+	cmd.Flags().IntVar(&req.Limit, "limit", 0, "Entries per page (server default 50, max 500)")
+	cmd.Flags().StringVar(&req.Cursor, "cursor", "", "nextCursor of the previous page")
+
+	_ = cmd.MarkFlagRequired("vault-id")
+	_ = cmd.MarkFlagRequired("memory-id")
+
+	return cmd
+}
+
+func newExplainSearchCmd() *cobra.Command {
+	var req client.ExplainSearchRequest
+
+	cmd := &cobra.Command{
+		Use:   "explain-search",
+		Short: "Explain whether and why an entry is returned for a search query",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := context.WithTimeout(cmd.Context(), 30*time.Second)
+			defer cancel()
+
+			resp, err := c.ExplainSearch(ctx, req)
+			if err != nil {
+				return err
+			}
+			b, _ := json.MarshalIndent(resp, "", "  ")
+			fmt.Fprintln(cmd.OutOrStdout(), string(b))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&req.VaultID, "vault-id", "", "Vault ID (required)")
+	cmd.Flags().StringVar(&req.MemoryID, "memory-id", "", "Memory ID (required)")
+	cmd.Flags().StringVar(&req.EntryID, "entry-id", "", "Entry ID to explain (required)")
+	cmd.Flags().StringVar(&req.Query, "query", "", "Search query (required)")
+	cmd.Flags().IntVar(&req.TopK, "top-k", 0, "topK the search would use (server default 10)")
+	cmd.Flags().StringVar(&req.SessionID, "session-id", "", "Session filter the search would use")
+	cmd.Flags().StringVar(&req.RankBy, "rank-by", "", "relevance, recency or hybrid")
 
 	_ = cmd.MarkFlagRequired("vault-id")
 	_ = cmd.MarkFlagRequired("memory-id")
+	_ = cmd.MarkFlagRequired("entry-id")
+	_ = cmd.MarkFlagRequired("query")
+
+	return cmd
+}
+
+func newExportSearchLogCmd() *cobra.Command {
+	var req client.ExportSearchLogRequest
+	var since, out string
+
+	cmd := &cobra.Command{
+		Use:   "export-search-log",
+		Short: "Export logged searches as a TREC run, qrels or topics file",
+		Long: `Export-search-log writes the server's search query log in TREC formats so
+retrieval quality can be scored with standard IR tooling, e.g.
+
+  mycelianCli export-search-log --format run --out run.txt
+  mycelianCli export-search-log --format qrels --out qrels.txt
+  trec_eval qrels.txt run.txt
+
+Query IDs are the topic IDs and entry IDs the document IDs. Qrels come from
+search feedback, so only queries with feedback are judged. The server must
+run with MEMORY_SERVER_SEARCH_QUERY_LOG_ENABLED=true.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if since != "" {
+				t, err := time.Parse(time.RFC3339, since)
+				if err != nil {
+					return fmt.Errorf("invalid --since: %w", err)
+				}
+				req.Since = &t
+			}
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := context.WithTimeout(cmd.Context(), 5*time.Minute)
+			defer cancel()
+
+			data, err := c.ExportSearchLog(ctx, req)
+			if err != nil {
+				return err
+			}
+			if out == "" || out == "-" {
+				_, err = cmd.OutOrStdout().Write(data)
+				return err
+			}
+			return os.WriteFile(out, data, 0o644)
+		},
+	}
+
+	cmd.Flags().StringVar(&req.Format, "format", "run", "run, qrels or topics")
+	cmd.Flags().StringVar(&req.MemoryID, "memory-id", "", "Only searches of this memory")
+	cmd.Flags().StringVar(&since, "since", "", "Only searches since this RFC3339 time")
+	cmd.Flags().IntVar(&req.Limit, "limit", 0, "Maximum searches (server default 10000)")
+	cmd.Flags().StringVar(&req.RunTag, "run-tag", "", "Run name in run files (server default mycelian)")
+	cmd.Flags().StringVar(&out, "out", "", "Output file (default: stdout)")
 
 	return cmd
 }
 
 func newGetPromptsCmd() *cobra.Command {
-	var memoryType string
+	var memoryType, memoryTitle, timeZone string
 
 	cmd := &cobra.Command{
 		Use:   "get-prompts",
@@ -302,14 +506,14 @@ func newGetPromptsCmd() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Client-side validation removed; rely on server-side validation
 
-			c, err := client.NewWithDevMode(serviceURL)
+			c, err := newClient()
 			if err != nil {
 				return err
 			}
 			ctx, cancel := context.WithTimeout(cmd.Context(), 5*time.Second)
 			defer cancel()
 
-			resp, err := c.LoadDefaultPrompts(ctx, memoryType)
+			resp, err := c.RenderDefaultPrompts(ctx, memoryType, client.PromptVars{MemoryTitle: memoryTitle, ActorTimeZone: timeZone})
 			if err != nil {
 				return err
 			}
@@ -321,6 +525,8 @@ func newGetPromptsCmd() *cobra.Command {
 	}
 
 	cmd.Flags().StringVar(&memoryType, "memory-type", "", "Memory type (chat, code, …)")
+	cmd.Flags().StringVar(&memoryTitle, "memory-title", "", "Memory title rendered into the prompts (optional)")
+	cmd.Flags().StringVar(&timeZone, "time-zone", "", "IANA time zone for today's date in the prompts (default UTC)")
 	_ = cmd.MarkFlagRequired("memory-type")
 
 	return cmd
@@ -342,13 +548,13 @@ func newPutContextCmd() *cobra.Command {
 				Str("service_url", serviceURL).
 				Msg("putting context")
 
-			c, err := client.NewWithDevMode(serviceURL)
+			c, err := newClient()
 			if err != nil {
 				return err
 			}
 			ctx, cancel := context.WithTimeout(cmd.Context(), 15*time.Second)
 			defer cancel()
-			defer func() { _ = c.Close() }() // Ensure queues are drained before process exits
+			defer func() { _ = c.Close() }() // No-op once flushWrites has run
 
 			start := time.Now()
 			ack, err := c.PutContext(ctx, vaultID, memoryID, content)
@@ -373,7 +579,7 @@ func newPutContextCmd() *cobra.Command {
 
 			dbg(ack)
 			fmt.Println("Context enqueued")
-			return nil
+			return flushWrites(ctx, c)
 		},
 	}
 
@@ -403,7 +609,7 @@ func newGetContextCmd() *cobra.Command {
 				Str("service_url", serviceURL).
 				Msg("getting context")
 
-			c, err := client.NewWithDevMode(serviceURL)
+			c, err := newClient()
 			if err != nil {
 				return err
 			}
@@ -456,6 +662,7 @@ func newGetContextCmd() *cobra.Command {
 func newSearchCmd() *cobra.Command {
 	var memoryID, query string
 	var topK int
+	var tagFlags []string
 
 	cmd := &cobra.Command{
 		Use:   "search",
@@ -468,8 +675,12 @@ func newSearchCmd() *cobra.Command {
 				return fmt.Errorf("--query is required")
 			}
 
-			if topK <= 0 || topK > 100 {
-				return fmt.Errorf("--top-k must be between 1 and 100")
+			if topK <= 0 {
+				return fmt.Errorf("--top-k must be positive")
+			}
+			tags, err := parseTagFlags(tagFlags)
+			if err != nil {
+				return err
 			}
 
 			log.Debug().
@@ -479,7 +690,7 @@ func newSearchCmd() *cobra.Command {
 				Str("service_url", serviceURL).
 				Msg("searching memories")
 
-			c, err := client.NewWithDevMode(serviceURL)
+			c, err := newClient()
 			if err != nil {
 				return err
 			}
@@ -491,6 +702,7 @@ func newSearchCmd() *cobra.Command {
 				MemoryID: memoryID,
 				Query:    query,
 				TopK:     topK,
+				Tags:     tags,
 			})
 			elapsed := time.Since(start)
 
@@ -517,7 +729,8 @@ func newSearchCmd() *cobra.Command {
 
 	cmd.Flags().StringVar(&memoryID, "memory-id", "", "Memory ID (required)")
 	cmd.Flags().StringVar(&query, "query", "", "Search query (required)")
-	cmd.Flags().IntVar(&topK, "top-k", defaultTopK, "Number of results to return (1-100)")
+	cmd.Flags().IntVar(&topK, "top-k", defaultTopK, "Number of results to return (the server enforces the maximum)")
+	cmd.Flags().StringArrayVar(&tagFlags, "tag", nil, "Only entries with this tag, as key=value (repeatable)")
 
 	_ = cmd.MarkFlagRequired("memory-id")
 	_ = cmd.MarkFlagRequired("query")
@@ -631,7 +844,7 @@ func newAwaitConsistencyCmd() *cobra.Command {
 				Str("service_url", serviceURL).
 				Msg("awaiting consistency")
 
-			c, err := client.NewWithDevMode(serviceURL)
+			c, err := newClient()
 			if err != nil {
 				return err
 			}
@@ -665,7 +878,7 @@ func newAwaitConsistencyCmd() *cobra.Command {
 // ------------------ Vault Commands -------------------
 
 func newCreateVaultCmd() *cobra.Command {
-	var title, description string
+	var title, description, template string
 
 	cmd := &cobra.Command{
 		Use:   "create-vault",
@@ -673,30 +886,73 @@ func newCreateVaultCmd() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Client-side validation removed; rely on server-side validation
 
-			c, err := client.NewWithDevMode(serviceURL)
+			c, err := newClient()
 			if err != nil {
 				return err
 			}
 			ctx, cancel := context.WithTimeout(cmd.Context(), 15*time.Second)
 			defer cancel()
 
+			out := cmd.OutOrStdout()
+			if template != "" {
+				v, err := c.CreateVaultFromTemplate(ctx, client.CreateVaultFromTemplateRequest{Title: title, Template: template})
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(out, "Vault created: %s (%s) from template %s\n", v.VaultID, v.Title, v.Template)
+				for _, m := range v.Memories {
+					fmt.Fprintf(out, "  %s\t%s\n", m.ID, m.Title)
+				}
+				return nil
+			}
+
 			v, err := c.CreateVault(ctx, client.CreateVaultRequest{Title: title, Description: description})
 			if err != nil {
 				return err
 			}
-			fmt.Printf("Vault created: %s (%s)\n", v.VaultID, v.Title)
+			fmt.Fprintf(out, "Vault created: %s (%s)\n", v.VaultID, v.Title)
 			return nil
 		},
 	}
 
 	cmd.Flags().StringVar(&title, "title", "", "Vault title (required)")
 	cmd.Flags().StringVar(&description, "description", "", "Description (optional)")
+	cmd.Flags().StringVar(&template, "template", "", "Pre-populate the vault from this server template (see list-vault-templates)")
 
 	_ = cmd.MarkFlagRequired("title")
 
 	return cmd
 }
 
+func newListVaultTemplatesCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list-vault-templates",
+		Short: "List the templates create-vault --template accepts",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := context.WithTimeout(cmd.Context(), 15*time.Second)
+			defer cancel()
+
+			tpls, err := c.ListVaultTemplates(ctx)
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			for _, t := range tpls {
+				titles := make([]string, len(t.Memories))
+				for i, m := range t.Memories {
+					titles[i] = m.Title
+				}
+				fmt.Fprintf(out, "%s\t%s\t%s\n", t.Name, strings.Join(titles, ","), t.Description)
+			}
+			return nil
+		},
+	}
+}
+
 func newListVaultsCmd() *cobra.Command {
 
 	cmd := &cobra.Command{
@@ -705,7 +961,7 @@ func newListVaultsCmd() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Client-side validation removed; rely on server-side validation
 
-			c, err := client.NewWithDevMode(serviceURL)
+			c, err := newClient()
 			if err != nil {
 				return err
 			}
@@ -735,7 +991,7 @@ func newGetVaultCmd() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Client-side validation removed; rely on server-side validation
 
-			c, err := client.NewWithDevMode(serviceURL)
+			c, err := newClient()
 			if err != nil {
 				return err
 			}
@@ -767,7 +1023,7 @@ func newDeleteVaultCmd() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Client-side validation removed; rely on server-side validation
 
-			c, err := client.NewWithDevMode(serviceURL)
+			c, err := newClient()
 			if err != nil {
 				return err
 			}
@@ -788,6 +1044,179 @@ func newDeleteVaultCmd() *cobra.Command {
 	return cmd
 }
 
+func newSetVaultReadOnlyCmd() *cobra.Command {
+	var vaultID string
+	var readOnly bool
+
+	cmd := &cobra.Command{
+		Use:   "set-vault-readonly",
+		Short: "Mark a vault read-only (or writable again with --read-only=false)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := context.WithTimeout(cmd.Context(), 15*time.Second)
+			defer cancel()
+
+			v, err := c.SetVaultReadOnly(ctx, vaultID, readOnly)
+			if err != nil {
+				return err
+			}
+			state := "writable"
+			if v.ReadOnly {
+				state = "read-only"
+			}
+			fmt.Printf("Vault %s is now %s\n", v.VaultID, state)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&vaultID, "vault-id", "", "Vault ID (required)")
+	cmd.Flags().BoolVar(&readOnly, "read-only", true, "Read-only flag to set")
+
+	_ = cmd.MarkFlagRequired("vault-id")
+	return cmd
+}
+
+func newUpdateVaultCmd() *cobra.Command {
+	var vaultID, title, description string
+
+	cmd := &cobra.Command{
+		Use:   "update-vault",
+		Short: "Rename a vault and/or change its description",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := context.WithTimeout(cmd.Context(), 15*time.Second)
+			defer cancel()
+
+			v, err := c.UpdateVault(ctx, vaultID, titleUpdate(cmd, title, description))
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Vault %s updated: %s\n", v.VaultID, v.Title)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&vaultID, "vault-id", "", "Vault ID (required)")
+	cmd.Flags().StringVar(&title, "title", "", "New vault title")
+	cmd.Flags().StringVar(&description, "description", "", "New vault description (empty clears it)")
+
+	_ = cmd.MarkFlagRequired("vault-id")
+	return cmd
+}
+
+func newUpdateMemoryCmd() *cobra.Command {
+	var vaultID, memoryID, title, description string
+
+	cmd := &cobra.Command{
+		Use:   "update-memory",
+		Short: "Rename a memory and/or change its description",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := context.WithTimeout(cmd.Context(), 15*time.Second)
+			defer cancel()
+
+			m, err := c.UpdateMemory(ctx, vaultID, memoryID, titleUpdate(cmd, title, description))
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Memory %s updated: %s\n", m.ID, m.Title)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&vaultID, "vault-id", "", "Vault ID (required)")
+	cmd.Flags().StringVar(&memoryID, "memory-id", "", "Memory ID (required)")
+	cmd.Flags().StringVar(&title, "title", "", "New memory title")
+	cmd.Flags().StringVar(&description, "description", "", "New memory description (empty clears it)")
+
+	_ = cmd.MarkFlagRequired("vault-id")
+	_ = cmd.MarkFlagRequired("memory-id")
+	return cmd
+}
+
+// titleUpdate sends only the --title and --description flags that were set.
+func titleUpdate(cmd *cobra.Command, title, description string) client.UpdateTitleRequest {
+	var req client.UpdateTitleRequest
+	if cmd.Flags().Changed("title") {
+		req.Title = &title
+	}
+	if cmd.Flags().Changed("description") {
+		req.Description = &description
+	}
+	return req
+}
+
+func newVaultStatsCmd() *cobra.Command {
+	var vaultID string
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "vault-stats",
+		Short: "Compare Postgres and search index object counts per memory to spot indexing gaps",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := context.WithTimeout(cmd.Context(), 30*time.Second)
+			defer cancel()
+
+			st, err := c.GetVaultStats(ctx, vaultID)
+			if err != nil {
+				return err
+			}
+			if asJSON {
+				b, _ := json.MarshalIndent(st, "", "  ")
+				fmt.Println(string(b))
+				return nil
+			}
+			printVaultStats(st)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&vaultID, "vault-id", "", "Vault ID (required)")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print the raw JSON response")
+
+	_ = cmd.MarkFlagRequired("vault-id")
+	return cmd
+}
+
+func printVaultStats(st *client.VaultStats) {
+	counts := func(pg int64, idx *client.ObjectCounts, pick func(client.ObjectCounts) int64) string {
+		if idx == nil {
+			return fmt.Sprintf("%d/?", pg)
+		}
+		return fmt.Sprintf("%d/%d", pg, pick(*idx))
+	}
+	entries := func(c client.ObjectCounts) int64 { return c.Entries }
+	contexts := func(c client.ObjectCounts) int64 { return c.Contexts }
+
+	fmt.Printf("%-36s  %-24s  %-15s  %-15s  %s\n", "MEMORY", "TITLE", "ENTRIES pg/idx", "CONTEXTS pg/idx", "STATUS")
+	for _, m := range st.Memories {
+		status := "ok"
+		switch {
+		case m.Index == nil:
+			status = "unknown"
+		case *m.Index != m.Postgres:
+			status = "GAP"
+		}
+		fmt.Printf("%-36s  %-24.24s  %-15s  %-15s  %s\n", m.MemoryID, m.Title,
+			counts(m.Postgres.Entries, m.Index, entries), counts(m.Postgres.Contexts, m.Index, contexts), status)
+	}
+	fmt.Printf("total: entries %s, contexts %s, in sync: %v\n",
+		counts(st.Postgres.Entries, st.Index, entries), counts(st.Postgres.Contexts, st.Index, contexts), st.InSync)
+}
+
 // ------------------ Memory Listing Command -------------------
 
 func newListMemoriesCmd() *cobra.Command {
@@ -808,7 +1237,7 @@ func newListMemoriesCmd() *cobra.Command {
 
 			// Client-side validation removed; rely on server-side validation
 
-			c, err := client.NewWithDevMode(serviceURL)
+			c, err := newClient()
 			if err != nil {
 				return err
 			}
@@ -848,6 +1277,21 @@ func newListMemoriesCmd() *cobra.Command {
 	return cmd
 }
 
+// flushWrites shuts the client down, waiting until ctx's deadline for queued
+// writes, and fails unless every write reached the server.
+func flushWrites(ctx context.Context, c *client.Client) error {
+	rep, err := c.Shutdown(ctx)
+	for memoryID, r := range rep {
+		log.Debug().Str("memory_id", memoryID).Int("submitted", r.Submitted).Int("flushed", r.Flushed).
+			Int("failed", r.Failed).Int("dropped", r.Dropped).Msg("flush report")
+		if r.Flushed != r.Submitted {
+			return fmt.Errorf("memory %s: %d of %d writes reached the server (%d failed, %d dropped)",
+				memoryID, r.Flushed, r.Submitted, r.Failed, r.Dropped)
+		}
+	}
+	return err
+}
+
 func getEnv(key, fallback string) string {
 	if v := os.Getenv(key); v != "" {
 		return v
@@ -855,6 +1299,22 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+// parseTagFlags reads repeated --tag key=value flags into a tag filter.
+func parseTagFlags(flags []string) (map[string]string, error) {
+	if len(flags) == 0 {
+		return nil, nil
+	}
+	tags := make(map[string]string, len(flags))
+	for _, f := range flags {
+		k, v, ok := strings.Cut(f, "=")
+		if !ok || strings.TrimSpace(k) == "" {
+			return nil, fmt.Errorf("--tag %q must be key=value", f)
+		}
+		tags[strings.TrimSpace(k)] = v
+	}
+	return tags, nil
+}
+
 func applyUpperBoundToLimit(l int) int {
 	if l <= 0 {
 		return 25
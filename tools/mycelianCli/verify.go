@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/mycelian/mycelian-memory/client"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// verifySourceSystem marks the sentinel entries verify-pipeline writes, so a
+// leftover from an interrupted run can be recognised.
+const verifySourceSystem = "mycelianCli:verify-pipeline"
+
+// verifyPollInterval is how often verify-pipeline repeats the search while
+// waiting for the sentinel to be indexed.
+const verifyPollInterval = 250 * time.Millisecond
+
+// verifyStage is the outcome and latency of one step of verify-pipeline.
+type verifyStage struct {
+	name    string
+	ok      bool
+	elapsed time.Duration
+	detail  string
+}
+
+func newVerifyPipelineCmd() *cobra.Command {
+	var vaultID, memoryID string
+	var searchTimeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "verify-pipeline",
+		Short: "Write, search for and delete a sentinel entry, reporting each stage's latency",
+		Long: `Verify-pipeline smoke tests a deployment end to end: it writes a sentinel
+entry to the memory, waits until the write is acknowledged, searches for the
+sentinel until the search index returns it, then deletes it. Each stage's
+latency is printed; any failed stage makes the command fail. The sentinel is
+deleted even when the search stage times out.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Debug().
+				Str("vault_id", vaultID).
+				Str("memory_id", memoryID).
+				Dur("search_timeout", searchTimeout).
+				Str("service_url", serviceURL).
+				Msg("verifying pipeline")
+
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+			defer func() { _ = c.Close() }()
+
+			stages := runVerifyPipeline(cmd.Context(), c, vaultID, memoryID, searchTimeout)
+			if failed := printVerifyReport(cmd.OutOrStdout(), stages); failed > 0 {
+				return fmt.Errorf("verify-pipeline: %d stage(s) failed", failed)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&vaultID, "vault-id", "", "Vault ID (required)")
+	cmd.Flags().StringVar(&memoryID, "memory-id", "", "Memory ID to write the sentinel to (required)")
+	cmd.Flags().DurationVar(&searchTimeout, "search-timeout", 30*time.Second, "How long to wait for search to return the sentinel")
+
+	_ = cmd.MarkFlagRequired("vault-id")
+	_ = cmd.MarkFlagRequired("memory-id")
+
+	return cmd
+}
+
+// runVerifyPipeline runs the write, consistency, search and delete stages in
+// order. The run stops at a failed write or consistency stage; once the
+// sentinel is known to be stored it is always deleted.
+func runVerifyPipeline(ctx context.Context, c *client.Client, vaultID, memoryID string, searchTimeout time.Duration) []verifyStage {
+	var stages []verifyStage
+	stage := func(name string, start time.Time, err error, detail string) bool {
+		s := verifyStage{name: name, ok: err == nil, elapsed: time.Since(start), detail: detail}
+		if err != nil {
+			s.detail = err.Error()
+		}
+		stages = append(stages, s)
+		return err == nil
+	}
+
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	sentinel := "mycelian-verify-" + hex.EncodeToString(b)
+
+	// The queued write runs under writeCtx, so it must outlive the
+	// consistency stage.
+	writeCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	_, err := c.AddEntry(writeCtx, vaultID, memoryID, client.AddEntryRequest{
+		RawEntry:     "Pipeline verification sentinel " + sentinel,
+		Summary:      "Pipeline verification sentinel " + sentinel,
+		SourceSystem: verifySourceSystem,
+		SourceID:     sentinel,
+	})
+	if !stage("write", start, err, sentinel) {
+		return stages
+	}
+
+	start = time.Now()
+	var entryID string
+	err = c.AwaitConsistency(writeCtx, memoryID)
+	if err == nil {
+		entryID, err = listSentinel(writeCtx, c, vaultID, memoryID, sentinel)
+	}
+	if !stage("consistency", start, err, "stored as entry "+entryID) {
+		return stages
+	}
+
+	start = time.Now()
+	err = searchSentinel(ctx, c, memoryID, sentinel, entryID, searchTimeout)
+	stage("search", start, err, "entry returned by search")
+
+	start = time.Now()
+	delCtx, cancelDel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancelDel()
+	err = c.DeleteEntry(delCtx, vaultID, memoryID, entryID)
+	stage("delete", start, err, "entry "+entryID+" deleted")
+	return stages
+}
+
+// searchSentinel searches the memory for sentinel until entryID comes back
+// or timeout passes.
+func searchSentinel(ctx context.Context, c *client.Client, memoryID, sentinel, entryID string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	attempts := 0
+	for {
+		attempts++
+		resp, err := c.Search(ctx, client.SearchRequest{MemoryID: memoryID, Query: sentinel, TopK: 10})
+		if err == nil {
+			for _, e := range resp.Entries {
+				if e.ID == entryID {
+					return nil
+				}
+			}
+		}
+		select {
+		case <-ctx.Done():
+			if err != nil {
+				return fmt.Errorf("not found after %d searches: %w", attempts, err)
+			}
+			return fmt.Errorf("not found after %d searches within %s", attempts, timeout)
+		case <-time.After(verifyPollInterval):
+		}
+	}
+}
+
+// listSentinel finds the sentinel among the memory's newest entries and
+// returns its ID.
+func listSentinel(ctx context.Context, c *client.Client, vaultID, memoryID, sentinel string) (string, error) {
+	resp, err := c.ListEntries(ctx, vaultID, memoryID, map[string]string{"limit": "50"})
+	if err != nil {
+		return "", err
+	}
+	for _, e := range resp.Entries {
+		if strings.Contains(e.RawEntry, sentinel) {
+			return e.ID, nil
+		}
+	}
+	return "", fmt.Errorf("write of %s acknowledged but not among the memory's newest entries; check the service logs", sentinel)
+}
+
+// printVerifyReport writes one line per stage and the total latency, and
+// returns the number of failed stages.
+func printVerifyReport(w io.Writer, stages []verifyStage) int {
+	failed := 0
+	var total time.Duration
+	for _, s := range stages {
+		total += s.elapsed
+		status := "[OK]  "
+		if !s.ok {
+			status = "[FAIL]"
+			failed++
+		}
+		_, _ = fmt.Fprintf(w, "%s %-12s %8s  %s\n", status, s.name, s.elapsed.Round(time.Millisecond), s.detail)
+	}
+	_, _ = fmt.Fprintf(w, "       %-12s %8s\n", "total", total.Round(time.Millisecond))
+	return failed
+}
@@ -0,0 +1,26 @@
+module github.com/mycelian/mycelian-memory/tools/schema-manager
+
+go 1.24.6
+
+require github.com/mycelian/mycelian-memory/server v0.0.0
+
+require (
+	github.com/davecgh/go-spew v1.1.2-0.20180830191138-d8f796af33cc // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/jackc/pgpassfile v1.0.0 // indirect
+	github.com/jackc/pgservicefile v0.0.0-20240606120523-5a60cdf6a761 // indirect
+	github.com/jackc/pgx/v5 v5.7.5 // indirect
+	github.com/jackc/puddle/v2 v2.2.2 // indirect
+	github.com/mycelian/mycelian-memory/pkg/schema v0.0.0 // indirect
+	github.com/pmezard/go-difflib v1.0.1-0.20181226105442-5d4384ee4fb2 // indirect
+	github.com/stretchr/testify v1.10.0 // indirect
+	golang.org/x/crypto v0.39.0 // indirect
+	golang.org/x/sync v0.15.0 // indirect
+	golang.org/x/text v0.26.0 // indirect
+)
+
+replace github.com/mycelian/mycelian-memory/pkg/devauth => ../../pkg/devauth
+
+replace github.com/mycelian/mycelian-memory/pkg/schema => ../../pkg/schema
+
+replace github.com/mycelian/mycelian-memory/server => ../../server
@@ -0,0 +1,15 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/mycelian/mycelian-memory/server/schemamanager"
+)
+
+func main() {
+	if err := schemamanager.Run(os.Args[1:], os.Stdout); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "schema-manager:", err)
+		os.Exit(1)
+	}
+}